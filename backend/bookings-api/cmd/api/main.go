@@ -13,12 +13,18 @@ import (
 	"bookings-api/internal/controller"
 	"bookings-api/internal/database"
 	"bookings-api/internal/messaging"
+	"bookings-api/internal/middleware"
+	"bookings-api/internal/policy"
 	"bookings-api/internal/publisher"
 	"bookings-api/internal/repository"
 	"bookings-api/internal/routes"
+	"bookings-api/internal/schema"
 	"bookings-api/internal/service"
+	"bookings-api/internal/tracing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -89,6 +95,22 @@ func main() {
 		Str("environment", cfg.Environment).
 		Msg("✅ Configuration loaded successfully")
 
+	// ============================================================================
+	// OPENTELEMETRY TRACING
+	// ============================================================================
+	// Installs the W3C trace context propagator and, when OTEL_EXPORTER_OTLP_ENDPOINT
+	// is set, exports spans over OTLP/HTTP. A booking flow is traced end to end:
+	//   HTTP request → reservation.created (AMQP headers) → trips-api → search-api
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("❌ Failed to initialize OpenTelemetry")
+	}
+	log.Info().
+		Bool("exporting", tracing.Enabled()).
+		Msg("✅ Tracing initialized")
+
 	// ============================================================================
 	// DATABASE INITIALIZATION
 	// ============================================================================
@@ -105,6 +127,19 @@ func main() {
 	}
 	log.Info().Msg("✅ Database connection established")
 
+	// Register the query metrics plugin BEFORE migrations so every statement is measured
+	// Records duration histograms by operation/table, logs slow queries with sanitized SQL
+	// and counts deadlocks / lock wait timeouts (GET /api/v1/admin/db-metrics)
+	queryMetrics := database.NewQueryMetrics(time.Duration(cfg.DBSlowQueryThresholdMs) * time.Millisecond)
+	if err := db.Use(queryMetrics); err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("❌ Failed to register query metrics plugin")
+	}
+	log.Info().
+		Int("slow_query_threshold_ms", cfg.DBSlowQueryThresholdMs).
+		Msg("✅ Query metrics plugin registered")
+
 	// Run auto-migrations to create/update database tables
 	// This creates tables if they don't exist and adds new columns
 	// Safe to run on every startup (won't delete existing data)
@@ -125,9 +160,12 @@ func main() {
 	//
 	// Publisher features:
 	//   - Persistent messages (survive RabbitMQ restart)
-	//   - Topic exchange: "bookings.events"
+	//   - Exchange and routing keys from the topology config ("bookings.events" topic exchange by default)
 	//   - Structured logging with zerolog
 	//   - Graceful error handling (no panics)
+	//   - Channel re-establishment and jittered retries of failed publishes
+	//   - Publisher confirms, reconnect on NotifyClose and a retry buffer for broker outages
+	//   - Pool of publishing channels, one per concurrent publish (safe to share across requests)
 	reservationPublisher, err := publisher.NewReservationPublisher(cfg, log.Logger)
 	if err != nil {
 		log.Fatal().
@@ -135,7 +173,8 @@ func main() {
 			Msg("❌ Failed to initialize RabbitMQ publisher")
 	}
 	log.Info().
-		Str("exchange", "bookings.events").
+		Str("exchange", cfg.Topology.Publisher.Exchange).
+		Int("max_attempts", cfg.PublishMaxAttempts).
 		Msg("✅ RabbitMQ publisher initialized")
 
 	// ============================================================================
@@ -145,17 +184,54 @@ func main() {
 	// Repositories abstract database operations and provide a clean interface
 	bookingRepo := repository.NewBookingRepository(db)
 	eventRepo := repository.NewEventRepository(db)
+	retentionRepo := repository.NewRetentionRepository(db)
+	quarantineRepo := repository.NewQuarantineRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	approvalRepo := repository.NewAdminApprovalRepository(db)
 	log.Info().Msg("✅ Repositories initialized")
 
 	// ============================================================================
 	// HTTP CLIENT INITIALIZATION (External Dependencies)
 	// ============================================================================
 	// Create HTTP clients for calling other microservices
-	tripsClient := clients.NewTripsClient(cfg.TripsAPIURL)
+	tripsClient := clients.NewTripsClient(cfg.TripsAPIURL, cfg.InternalAPISecret)
+	usersClient := clients.NewUsersClient(cfg.UsersAPIURL)
 	log.Info().
 		Str("trips_api_url", cfg.TripsAPIURL).
+		Str("users_api_url", cfg.UsersAPIURL).
 		Msg("✅ HTTP clients initialized")
 
+	// Payment provider (nil when PAYMENT_PROVIDER is empty: bookings are confirmed without a payment)
+	paymentProvider, err := clients.NewPaymentProvider(cfg.PaymentProvider, cfg.PaymentProviderURL, cfg.PaymentProviderAPIKey, time.Duration(cfg.PaymentProviderTimeoutSeconds)*time.Second)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("❌ Invalid payment provider configuration")
+	}
+	if paymentProvider != nil {
+		log.Info().
+			Str("provider", paymentProvider.Name()).
+			Str("url", cfg.PaymentProviderURL).
+			Msg("✅ Payment provider initialized")
+	}
+
+	// ============================================================================
+	// COUNTRY POLICY REGISTRY
+	// ============================================================================
+	// Load per-country booking policies (cancellation window, fees, max seats)
+	// Source: COUNTRY_POLICIES_FILE (JSON) or built-in defaults when not set
+	// Fail-fast: an invalid policy file must never silently change fees
+	policies, err := policy.LoadRegistry(cfg.CountryPoliciesFile, cfg.DefaultCountry)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("❌ Failed to load country policies")
+	}
+	log.Info().
+		Str("policies_file", cfg.CountryPoliciesFile).
+		Str("default_country", cfg.DefaultCountry).
+		Msg("✅ Country policies loaded")
+
 	// ============================================================================
 	// SERVICE INITIALIZATION (Business Logic Layer)
 	// ============================================================================
@@ -165,15 +241,91 @@ func main() {
 	// AuthService: JWT token validation for authentication middleware
 	authService := service.NewAuthService(cfg.JWTSecret)
 
+	// SessionService: Checks JWTs against users-api (revoked sessions, deactivated or banned accounts), cached per user
+	sessionService := service.NewSessionService(usersClient, time.Duration(cfg.SessionStatusCacheSeconds)*time.Second)
+
 	// IdempotencyService: Used by RabbitMQ consumer to prevent duplicate event processing
 	idempotencyService := service.NewIdempotencyService(eventRepo)
 
+	// SeatHoldService: Holds seats in trips-api before a booking is created (optimistic flow when disabled)
+	seatHoldService := service.NewSeatHoldService(tripsClient, service.SeatHoldConfig{
+		Enabled: cfg.SeatHoldsEnabled,
+		TTL:     time.Duration(cfg.SeatHoldTTLSeconds) * time.Second,
+	})
+
+	// BookingStatusHub: Fans out status transitions to the live booking streams (SSE) of this instance
+	bookingStatusHub := service.NewBookingStatusHub(cfg.StreamMaxPerUser)
+
+	// CreditsService: Applies the passenger's promotional credits (users-api) at confirmation
+	// and releases them on cancellations within the refund window
+	creditsService := service.NewCreditsService(usersClient, policies, cfg.PromotionalCreditsEnabled)
+
+	// PaymentService: Authorizes, captures and refunds the fare through the payment provider
+	// (bookings are confirmed once the authorization succeeds)
+	paymentService := service.NewPaymentService(paymentProvider, bookingRepo, paymentRepo, reservationPublisher, bookingStatusHub, creditsService, service.PaymentConfig{
+		AuthorizationTimeout: time.Duration(cfg.PaymentAuthorizationTimeoutMinutes) * time.Minute,
+		BatchSize:            cfg.ExpirationBatchSize,
+	})
+
 	// BookingService: Handles business logic for booking operations
-	// Injected dependencies: repository, trips-api client, RabbitMQ publisher
-	bookingService := service.NewBookingService(bookingRepo, tripsClient, reservationPublisher)
+	// Injected dependencies: repository, trips-api and users-api clients, RabbitMQ publisher, country policies, seat holds, payments, credits, status hub
+	bookingService := service.NewBookingService(bookingRepo, tripsClient, usersClient, reservationPublisher, policies, seatHoldService, paymentService, creditsService, bookingStatusHub)
+
+	// RetentionService: Archives old bookings in terminal statuses (deletes PII),
+	// keeping an anonymized analytics record of each one
+	retentionService := service.NewRetentionService(retentionRepo, service.RetentionConfig{
+		BookingRetention:   time.Duration(cfg.BookingRetentionDays) * 24 * time.Hour,
+		AnalyticsEnabled:   cfg.AnalyticsRetentionEnabled,
+		AnalyticsRetention: time.Duration(cfg.AnalyticsRetentionDays) * 24 * time.Hour,
+		PriceBuckets:       cfg.AnalyticsPriceBuckets,
+		BatchSize:          cfg.RetentionBatchSize,
+	})
+
+	// ExpirationService: Expires bookings stuck in pending (lost trips-api events) and releases their seats
+	expirationService := service.NewExpirationService(bookingRepo, reservationPublisher, seatHoldService, bookingStatusHub, service.ExpirationConfig{
+		PendingTimeout: time.Duration(cfg.BookingPendingTimeoutMinutes) * time.Minute,
+		BatchSize:      cfg.ExpirationBatchSize,
+	})
+
+	// GuardianApprovalService: Publishes the bookings of dependent accounts once their guardian approves them (users-api)
+	guardianApprovalService := service.NewGuardianApprovalService(bookingRepo, usersClient, reservationPublisher, bookingStatusHub, cfg.ExpirationBatchSize)
+
+	// PaymentSplitService: Payment shares of split-payment bookings and the deadline after which the organizer covers the rest
+	paymentSplitService := service.NewPaymentSplitService(bookingRepo, paymentRepo, reservationPublisher, bookingStatusHub, service.PaymentSplitConfig{
+		ShareTimeout: time.Duration(cfg.PaymentShareTimeoutMinutes) * time.Minute,
+		LinkBaseURL:  cfg.PaymentLinkBaseURL,
+		BatchSize:    cfg.ExpirationBatchSize,
+	})
+
+	// AdminApprovalService: Force-cancel and manual payment only run once a second admin approves them
+	adminApprovalService := service.NewAdminApprovalService(approvalRepo, bookingRepo, bookingService, paymentSplitService, time.Duration(cfg.AdminApprovalTTLMinutes)*time.Minute)
+
+	// SmokeTestService: Startup end-to-end check (SMOKE_TEST=true) reported by exit code and GET /health/smoke
+	smokeTestService := service.NewSmokeTestService(bookingService, bookingStatusHub, service.SmokeTestConfig{
+		Enabled:            cfg.SmokeTest,
+		TripID:             cfg.SmokeTestTripID,
+		PassengerID:        cfg.SmokeTestPassengerID,
+		PaymentMethodToken: cfg.SmokeTestPaymentToken,
+		Timeout:            time.Duration(cfg.SmokeTestTimeoutSeconds) * time.Second,
+	})
 
 	log.Info().Msg("✅ Services initialized (ready for controllers and consumers)")
 
+	// ============================================================================
+	// EVENT SCHEMAS
+	// ============================================================================
+	// Versioned JSON Schemas of the consumed events (embedded in the binary)
+	// Fail-fast: a broken schema would quarantine every message of its event type
+	eventSchemas, err := schema.LoadRegistry()
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("❌ Failed to load event schemas")
+	}
+	log.Info().
+		Interface("versions", eventSchemas.Versions()).
+		Msg("✅ Event schemas loaded")
+
 	// ============================================================================
 	// RABBITMQ CONSUMER INITIALIZATION
 	// ============================================================================
@@ -183,14 +335,25 @@ func main() {
 	//   - reservation.failed: Marks bookings as failed when seat reservation fails
 	//
 	// Consumer features:
+	//   - Schema validation: Payloads that don't match their JSON Schema go to quarantined_messages
 	//   - Idempotency: Prevents duplicate event processing using event_id
 	//   - Manual ACK: Only acknowledges after successful processing
 	//   - Prefetch: Processes 10 messages concurrently for better throughput
+	//   - Batch mode (RABBITMQ_CONSUMER_BATCH_SIZE > 1): Cancellation storms are applied in one transaction per batch
 	//   - Graceful shutdown: Stops cleanly on SIGINT/SIGTERM
 	consumer, err := messaging.NewTripsConsumer(
 		cfg.RabbitMQURL,
+		cfg.Topology.Consumer,
 		bookingRepo,
+		repository.NewTransactor(db),
 		idempotencyService,
+		seatHoldService,
+		paymentSplitService,
+		paymentService,
+		creditsService,
+		bookingStatusHub,
+		eventSchemas,
+		quarantineRepo,
 	)
 	if err != nil {
 		log.Fatal().
@@ -201,6 +364,9 @@ func main() {
 		Str("rabbitmq_url", cfg.RabbitMQURL).
 		Msg("✅ RabbitMQ consumer initialized")
 
+	// QuarantineService: Admin inspection and reprocessing of schema-invalid messages (runs the consumer handlers)
+	quarantineService := service.NewQuarantineService(quarantineRepo, eventSchemas, consumer)
+
 	// Start consumer in background goroutine
 	// This allows the consumer to process messages concurrently with HTTP requests
 	consumerCtx, consumerCancel := context.WithCancel(context.Background())
@@ -215,6 +381,69 @@ func main() {
 		}
 	}()
 
+	// ============================================================================
+	// DATA RETENTION JOB
+	// ============================================================================
+	// Runs only when BOOKING_RETENTION_DAYS or ANALYTICS_RETENTION_DAYS is set
+	// Stops together with the consumer on shutdown
+	if cfg.BookingRetentionDays > 0 || cfg.AnalyticsRetentionDays > 0 {
+		go retentionService.Start(consumerCtx, time.Duration(cfg.RetentionJobIntervalMinutes)*time.Minute)
+		log.Info().
+			Int("booking_retention_days", cfg.BookingRetentionDays).
+			Bool("analytics_enabled", cfg.AnalyticsRetentionEnabled).
+			Int("analytics_retention_days", cfg.AnalyticsRetentionDays).
+			Msg("✅ Data retention job started")
+	}
+
+	// ============================================================================
+	// BOOKING EXPIRATION JOB
+	// ============================================================================
+	// Runs unless BOOKING_PENDING_TIMEOUT_MINUTES is 0
+	// Stops together with the consumer on shutdown
+	if cfg.BookingPendingTimeoutMinutes > 0 {
+		go expirationService.Start(consumerCtx, time.Duration(cfg.ExpirationJobIntervalSeconds)*time.Second)
+		log.Info().
+			Int("pending_timeout_minutes", cfg.BookingPendingTimeoutMinutes).
+			Int("interval_seconds", cfg.ExpirationJobIntervalSeconds).
+			Msg("✅ Booking expiration job started")
+	}
+
+	// ============================================================================
+	// GUARDIAN APPROVAL JOB
+	// ============================================================================
+	// Resolves the bookings of dependent accounts awaiting their guardian's answer
+	// Stops together with the consumer on shutdown
+	go guardianApprovalService.Start(consumerCtx, time.Duration(cfg.GuardianApprovalJobIntervalSeconds)*time.Second)
+	log.Info().
+		Int("interval_seconds", cfg.GuardianApprovalJobIntervalSeconds).
+		Msg("✅ Guardian approval job started")
+
+	// ============================================================================
+	// PAYMENT DEADLINE JOB
+	// ============================================================================
+	// Runs unless PAYMENT_SHARE_TIMEOUT_MINUTES is 0
+	// Stops together with the consumer on shutdown
+	if cfg.PaymentShareTimeoutMinutes > 0 {
+		go paymentSplitService.Start(consumerCtx, time.Duration(cfg.PaymentJobIntervalSeconds)*time.Second)
+		log.Info().
+			Int("share_timeout_minutes", cfg.PaymentShareTimeoutMinutes).
+			Int("interval_seconds", cfg.PaymentJobIntervalSeconds).
+			Msg("✅ Payment deadline job started")
+	}
+
+	// ============================================================================
+	// PAYMENT PROVIDER JOB
+	// ============================================================================
+	// Retries authorizations and captures that did not reach the provider and cancels the
+	// bookings whose authorization did not arrive in time. Runs when PAYMENT_PROVIDER is set
+	if paymentService.Enabled() {
+		go paymentService.Start(consumerCtx, time.Duration(cfg.PaymentJobIntervalSeconds)*time.Second)
+		log.Info().
+			Int("authorization_timeout_minutes", cfg.PaymentAuthorizationTimeoutMinutes).
+			Int("interval_seconds", cfg.PaymentJobIntervalSeconds).
+			Msg("✅ Payment provider job started")
+	}
+
 	// ============================================================================
 	// GIN ROUTER INITIALIZATION
 	// ============================================================================
@@ -241,14 +470,56 @@ func main() {
 	// Add built-in middleware
 	router.Use(gin.Recovery()) // Recover from panics
 
+	// ============================================================================
+	// LOAD SHEDDING
+	// ============================================================================
+	// Rejects low-priority requests (list queries) with 503 + Retry-After when
+	// p99 latency or DB pool saturation exceed their thresholds
+	// LOAD_SHEDDING_MODE=on|off overrides the automatic detection
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("❌ Failed to access database connection pool")
+	}
+	loadShedder := middleware.NewLoadShedder(middleware.LoadSheddingConfig{
+		Mode:             cfg.LoadSheddingMode,
+		P99Threshold:     time.Duration(cfg.LoadSheddingP99Ms) * time.Millisecond,
+		DBPoolSaturation: cfg.LoadSheddingDBPoolSaturation,
+		RetryAfter:       time.Duration(cfg.LoadSheddingRetryAfterSecs) * time.Second,
+	}, sqlDB.Stats)
+	log.Info().
+		Str("mode", cfg.LoadSheddingMode).
+		Int("p99_threshold_ms", cfg.LoadSheddingP99Ms).
+		Float64("db_pool_saturation", cfg.LoadSheddingDBPoolSaturation).
+		Msg("✅ Load shedder initialized")
+
+	// ============================================================================
+	// PROMETHEUS COLLECTORS
+	// ============================================================================
+	// GET /metrics also exports the query metrics plugin histograms (same data as
+	// GET /api/v1/admin/db-metrics) and the MySQL connection pool stats
+	prometheus.MustRegister(queryMetrics, collectors.NewDBStatsCollector(sqlDB, "bookings"))
+
 	// ============================================================================
 	// CONTROLLER INITIALIZATION
 	// ============================================================================
 	// Create controller instances
 	// Controllers handle HTTP requests and responses
 	// Each controller is responsible for a specific domain (health, bookings, etc.)
-	healthController := controller.NewHealthController("bookings-api", cfg.ServerPort)
+	healthController := controller.NewHealthController("bookings-api", cfg.ServerPort, smokeTestService)
 	bookingController := controller.NewBookingController(bookingService)
+	bookingStreamController := controller.NewBookingStreamController(bookingService, bookingStatusHub, controller.BookingStreamConfig{
+		Heartbeat:   time.Duration(cfg.StreamHeartbeatSeconds) * time.Second,
+		MaxDuration: time.Duration(cfg.StreamMaxMinutes) * time.Minute,
+	})
+	loadSheddingController := controller.NewLoadSheddingController(loadShedder)
+	publisherController := controller.NewPublisherController(reservationPublisher)
+	topologyController := controller.NewTopologyController(cfg.Topology)
+	dbMetricsController := controller.NewDBMetricsController(queryMetrics)
+	quarantineController := controller.NewQuarantineController(quarantineService)
+	paymentController := controller.NewPaymentController(paymentSplitService, paymentService)
+	approvalController := controller.NewAdminApprovalController(adminApprovalService)
 	log.Info().Msg("✅ Controllers initialized")
 
 	// ============================================================================
@@ -259,7 +530,7 @@ func main() {
 	// This includes:
 	//   - Health check endpoint (GET /health)
 	//   - Booking management endpoints (protected by JWT authentication)
-	routes.SetupRoutes(router, healthController, bookingController, authService)
+	routes.SetupRoutes(router, healthController, bookingController, bookingStreamController, loadSheddingController, publisherController, topologyController, dbMetricsController, quarantineController, paymentController, approvalController, authService, sessionService, cfg.PaymentWebhookSecret, loadShedder)
 	log.Info().Msg("✅ Routes registered")
 
 	// ============================================================================
@@ -323,11 +594,45 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-	// Block until we receive a shutdown signal
-	sig := <-quit
-	log.Info().
-		Str("signal", sig.String()).
-		Msg("⚠️  Shutdown signal received, starting graceful shutdown...")
+	// ============================================================================
+	// STARTUP SMOKE TEST
+	// ============================================================================
+	// Runs once the server and the consumer are up (the confirmation arrives through
+	// reservation.confirmed). With SMOKE_TEST_EXIT the process shuts down afterwards
+	// and exits with 0 (passed) or 1 (failed); otherwise the server keeps running and
+	// the result stays available at GET /health/smoke
+	exitCode := 0
+	smokeDone := make(chan bool, 1)
+	if smokeTestService.Enabled() {
+		go func() {
+			smokeDone <- smokeTestService.Run(consumerCtx).Passed()
+		}()
+	}
+
+	// Block until we receive a shutdown signal (or the smoke test finishes in exit mode)
+	for waiting := true; waiting; {
+		select {
+		case sig := <-quit:
+			log.Info().
+				Str("signal", sig.String()).
+				Msg("⚠️  Shutdown signal received, starting graceful shutdown...")
+			if smokeTestService.Enabled() && cfg.SmokeTestExit && !smokeTestService.Result().Passed() {
+				exitCode = 1
+			}
+			waiting = false
+		case passed := <-smokeDone:
+			if !cfg.SmokeTestExit {
+				continue
+			}
+			if !passed {
+				exitCode = 1
+			}
+			log.Info().
+				Bool("passed", passed).
+				Msg("⚠️  Smoke test finished, starting graceful shutdown...")
+			waiting = false
+		}
+	}
 
 	// Create a context with timeout for graceful shutdown
 	// If shutdown takes longer than 15 seconds, force exit
@@ -383,5 +688,16 @@ func main() {
 		log.Info().Msg("✅ Database connection closed")
 	}
 
+	// Flush the spans still buffered by the batch exporter
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().
+			Err(err).
+			Msg("⚠️  Error shutting down OpenTelemetry")
+	}
+
 	log.Info().Msg("✅ Server gracefully stopped")
+
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
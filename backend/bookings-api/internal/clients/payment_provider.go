@@ -0,0 +1,273 @@
+package clients
+
+import (
+	"bookings-api/internal/domain"
+	"bookings-api/internal/tracing"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PaymentProvider defines the operations of a payment provider (card authorization and capture)
+//
+// The fare is authorized when trips-api confirms the seats and captured once the booking is confirmed;
+// cancellations release the authorization or refund the captured amount.
+type PaymentProvider interface {
+	// Name identifies the provider; stored in payments.provider
+	Name() string
+
+	// Authorize reserves the amount on the passenger's payment method (no charge yet)
+	// The result is authorized, declined or pending (completed later through the webhook)
+	Authorize(ctx context.Context, request domain.PaymentAuthorizationRequest) (*domain.ProviderPaymentResult, error)
+
+	// Capture charges an authorized payment
+	Capture(ctx context.Context, providerPaymentID string, amount float64) error
+
+	// Refund returns part or all of a captured payment
+	Refund(ctx context.Context, providerPaymentID string, amount float64) error
+}
+
+// MockDeclinedToken is the payment method token the mock provider always declines
+const MockDeclinedToken = "tok_declined"
+
+// NewPaymentProvider creates the payment provider configured in PAYMENT_PROVIDER
+// Returns nil when provider is empty (bookings are confirmed without a payment, as before)
+func NewPaymentProvider(provider, baseURL, apiKey string, timeout time.Duration) (PaymentProvider, error) {
+	switch provider {
+	case "":
+		return nil, nil
+	case "mock":
+		return &mockPaymentProvider{checkoutBaseURL: strings.TrimRight(baseURL, "/")}, nil
+	case "http":
+		if baseURL == "" {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER_URL is required for the http payment provider")
+		}
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		return &httpPaymentProvider{
+			baseURL: strings.TrimRight(baseURL, "/"),
+			apiKey:  apiKey,
+			httpClient: &http.Client{
+				Timeout:   timeout,
+				Transport: tracing.Transport(nil),
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q (expected mock or http)", provider)
+	}
+}
+
+// ==================== Mock provider ====================
+
+// mockPaymentProvider authorizes every payment locally (development and tests)
+//   - token "tok_declined": declined
+//   - empty token: pending, with a checkout URL (confirm it calling the webhook)
+//   - any other token: authorized
+type mockPaymentProvider struct {
+	checkoutBaseURL string
+}
+
+func (p *mockPaymentProvider) Name() string {
+	return "mock"
+}
+
+func (p *mockPaymentProvider) Authorize(ctx context.Context, request domain.PaymentAuthorizationRequest) (*domain.ProviderPaymentResult, error) {
+	id, err := mockPaymentID()
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.PaymentMethodToken {
+	case MockDeclinedToken:
+		return &domain.ProviderPaymentResult{ProviderPaymentID: id, Status: domain.PaymentStatusDeclined, DeclineReason: "card_declined"}, nil
+	case "":
+		return &domain.ProviderPaymentResult{
+			ProviderPaymentID: id,
+			Status:            domain.PaymentStatusPending,
+			CheckoutURL:       p.checkoutBaseURL + "/checkout/" + id,
+		}, nil
+	default:
+		return &domain.ProviderPaymentResult{ProviderPaymentID: id, Status: domain.PaymentStatusAuthorized}, nil
+	}
+}
+
+func (p *mockPaymentProvider) Capture(ctx context.Context, providerPaymentID string, amount float64) error {
+	return nil
+}
+
+func (p *mockPaymentProvider) Refund(ctx context.Context, providerPaymentID string, amount float64) error {
+	return nil
+}
+
+// mockPaymentID generates a provider-like payment ID (mock_ + 12 random bytes)
+func mockPaymentID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate payment id: %w", err)
+	}
+	return "mock_" + hex.EncodeToString(b), nil
+}
+
+// ==================== HTTP provider ====================
+
+// httpPaymentProvider talks to a Mercado Pago/Stripe-style payments API
+//
+//	POST {base}/v1/payments                 authorize (capture: false)
+//	POST {base}/v1/payments/{id}/capture    capture
+//	POST {base}/v1/payments/{id}/refunds    refund
+//
+// Requests carry the API key as Bearer token and an Idempotency-Key, so retries never charge twice.
+type httpPaymentProvider struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// httpPaymentRequest is the body of POST /v1/payments
+type httpPaymentRequest struct {
+	Amount            float64 `json:"amount"`
+	Currency          string  `json:"currency"`
+	PaymentMethod     string  `json:"payment_method,omitempty"`
+	Capture           bool    `json:"capture"`
+	ExternalReference string  `json:"external_reference"`
+	Description       string  `json:"description,omitempty"`
+}
+
+// httpPaymentResponse is the provider's payment object
+type httpPaymentResponse struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	StatusDetail  string `json:"status_detail"`
+	CheckoutURL   string `json:"checkout_url"`
+	FailureReason string `json:"failure_reason"`
+}
+
+// httpAmountRequest is the body of capture and refund requests
+type httpAmountRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+func (p *httpPaymentProvider) Name() string {
+	return "http"
+}
+
+func (p *httpPaymentProvider) Authorize(ctx context.Context, request domain.PaymentAuthorizationRequest) (*domain.ProviderPaymentResult, error) {
+	var payment httpPaymentResponse
+	err := p.post(ctx, "/v1/payments", "authorize-"+request.Reference, httpPaymentRequest{
+		Amount:            request.Amount,
+		Currency:          request.Currency,
+		PaymentMethod:     request.PaymentMethodToken,
+		Capture:           false,
+		ExternalReference: request.Reference,
+		Description:       request.Description,
+	}, &payment)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ProviderPaymentResult{
+		ProviderPaymentID: payment.ID,
+		Status:            mapProviderStatus(payment.Status),
+		CheckoutURL:       payment.CheckoutURL,
+	}
+	if result.Status == domain.PaymentStatusDeclined {
+		result.DeclineReason = payment.FailureReason
+		if result.DeclineReason == "" {
+			result.DeclineReason = payment.StatusDetail
+		}
+	}
+	return result, nil
+}
+
+func (p *httpPaymentProvider) Capture(ctx context.Context, providerPaymentID string, amount float64) error {
+	path := "/v1/payments/" + url.PathEscape(providerPaymentID) + "/capture"
+	return p.post(ctx, path, "capture-"+providerPaymentID, httpAmountRequest{Amount: amount}, nil)
+}
+
+func (p *httpPaymentProvider) Refund(ctx context.Context, providerPaymentID string, amount float64) error {
+	path := "/v1/payments/" + url.PathEscape(providerPaymentID) + "/refunds"
+	key := fmt.Sprintf("refund-%s-%.2f", providerPaymentID, amount)
+	return p.post(ctx, path, key, httpAmountRequest{Amount: amount}, nil)
+}
+
+// post sends a JSON request to the provider and decodes the answer into out (nil ignores it)
+//
+// Status codes:
+//   - 2xx: success
+//   - 4xx: rejected request (error with the provider's body)
+//   - 5xx or network error: ErrPaymentProviderUnavailable
+func (p *httpPaymentProvider) post(ctx context.Context, path, idempotencyKey string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", idempotencyKey)
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("Failed to call payment provider")
+		return domain.ErrPaymentProviderUnavailable.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode >= 500:
+		log.Error().Int("status_code", resp.StatusCode).Str("path", path).Msg("Payment provider error")
+		return domain.ErrPaymentProviderUnavailable.WithDetails(map[string]interface{}{
+			"status_code": resp.StatusCode,
+		})
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("payment provider rejected %s with status %d: %s", path, resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse payment provider response: %w", err)
+	}
+	return nil
+}
+
+// mapProviderStatus maps the provider's payment statuses to ours
+// Mercado Pago: approved/authorized, rejected, pending/in_process
+// Stripe: requires_capture, requires_payment_method, requires_action/processing
+func mapProviderStatus(status string) string {
+	switch status {
+	case "authorized", "approved", "requires_capture":
+		return domain.PaymentStatusAuthorized
+	case "captured", "succeeded":
+		return domain.PaymentStatusCaptured
+	case "rejected", "declined", "cancelled", "canceled", "requires_payment_method":
+		return domain.PaymentStatusDeclined
+	default:
+		return domain.PaymentStatusPending
+	}
+}
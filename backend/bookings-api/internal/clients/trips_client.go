@@ -2,11 +2,14 @@ package clients
 
 import (
 	"bookings-api/internal/domain"
+	"bookings-api/internal/tracing"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -15,20 +18,34 @@ import (
 // TripsClient defines the interface for interacting with trips-api
 type TripsClient interface {
 	GetTrip(ctx context.Context, tripID string) (*domain.Trip, error)
+
+	// CreateSeatHold holds seats on a trip before the booking is created (POST /trips/:id/holds)
+	CreateSeatHold(ctx context.Context, tripID string, req domain.SeatHoldRequest) (*domain.SeatHold, error)
+
+	// ReleaseSeatHold releases the seat hold of a booking (DELETE /trips/:id/holds/:hold_id)
+	// reservationID must be the booking the hold was taken for: trips-api rejects other reservations
+	ReleaseSeatHold(ctx context.Context, tripID, holdID, reservationID string) (domain.SeatHoldRelease, error)
 }
 
+// internalSecretHeader carries the shared secret trips-api requires on its service-to-service routes
+const internalSecretHeader = "X-Internal-Secret"
+
 // tripsHTTPClient implements TripsClient using HTTP calls
 type tripsHTTPClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL        string
+	internalSecret string
+	httpClient     *http.Client
 }
 
 // NewTripsClient creates a new HTTP client for trips-api
-func NewTripsClient(baseURL string) TripsClient {
+// internalSecret is sent on the seat hold routes (INTERNAL_API_SECRET, the same value as in trips-api)
+func NewTripsClient(baseURL, internalSecret string) TripsClient {
 	return &tripsHTTPClient{
-		baseURL: baseURL,
+		baseURL:        baseURL,
+		internalSecret: internalSecret,
 		httpClient: &http.Client{
-			Timeout: 5 * time.Second, // 5 second timeout for external calls
+			Timeout:   5 * time.Second,        // 5 second timeout for external calls
+			Transport: tracing.Transport(nil), // Propagates the traceparent to trips-api
 		},
 	}
 }
@@ -152,3 +169,127 @@ func (c *tripsHTTPClient) GetTrip(ctx context.Context, tripID string) (*domain.T
 		return nil, fmt.Errorf("trips-api returned unexpected status %d: %s", resp.StatusCode, string(body))
 	}
 }
+
+// CreateSeatHold asks trips-api to hold seats for a booking
+//
+// Status codes:
+//   - 201: hold created
+//   - 409: not enough available seats (ErrInsufficientSeats)
+//   - 404 with a JSON body: trip not found (ErrTripNotFound)
+//   - 404 without a JSON body: trips-api does not expose seat holds yet (ErrTripsAPIUnavailable)
+//   - 5xx or network error: ErrTripsAPIUnavailable
+func (c *tripsHTTPClient) CreateSeatHold(ctx context.Context, tripID string, holdReq domain.SeatHoldRequest) (*domain.SeatHold, error) {
+	url := fmt.Sprintf("%s/trips/%s/holds", c.baseURL, tripID)
+
+	payload, err := json.Marshal(holdReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal seat hold request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(internalSecretHeader, c.internalSecret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to call trips-api to hold seats")
+		return nil, domain.ErrTripsAPIUnavailable.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp tripsAPIResponse
+	parseErr := json.Unmarshal(body, &apiResp)
+
+	switch {
+	case resp.StatusCode == http.StatusCreated || resp.StatusCode == http.StatusOK:
+		if parseErr != nil || !apiResp.Success {
+			return nil, fmt.Errorf("unexpected seat hold response from trips-api: %s", string(body))
+		}
+		var hold domain.SeatHold
+		if err := json.Unmarshal(apiResp.Data, &hold); err != nil {
+			return nil, fmt.Errorf("failed to parse seat hold: %w", err)
+		}
+
+		log.Info().
+			Str("trip_id", tripID).
+			Str("hold_id", hold.ID).
+			Int("seats", hold.Seats).
+			Time("expires_at", hold.ExpiresAt).
+			Msg("Seats held in trips-api")
+
+		return &hold, nil
+
+	case resp.StatusCode == http.StatusConflict:
+		return nil, domain.ErrInsufficientSeats.WithDetails(map[string]interface{}{
+			"trip_id":         tripID,
+			"seats_requested": holdReq.Seats,
+		})
+
+	case resp.StatusCode == http.StatusNotFound && parseErr == nil:
+		return nil, domain.ErrTripNotFound.WithDetails(map[string]interface{}{
+			"trip_id": tripID,
+		})
+
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode >= http.StatusInternalServerError:
+		log.Error().
+			Int("status_code", resp.StatusCode).
+			Str("trip_id", tripID).
+			Str("body", string(body)).
+			Msg("trips-api could not hold seats")
+		return nil, domain.ErrTripsAPIUnavailable.WithDetails(map[string]interface{}{
+			"status_code": resp.StatusCode,
+			"trip_id":     tripID,
+		})
+
+	default:
+		return nil, fmt.Errorf("trips-api returned unexpected status %d holding seats: %s", resp.StatusCode, string(body))
+	}
+}
+
+// ReleaseSeatHold returns the held seats to the trip
+//
+// Status codes: 200/204 released, 404 already released or expired, 409 already confirmed
+func (c *tripsHTTPClient) ReleaseSeatHold(ctx context.Context, tripID, holdID, reservationID string) (domain.SeatHoldRelease, error) {
+	endpoint := fmt.Sprintf("%s/trips/%s/holds/%s?reservation_id=%s", c.baseURL, tripID, holdID, url.QueryEscape(reservationID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(internalSecretHeader, c.internalSecret)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", domain.ErrTripsAPIUnavailable.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return domain.SeatHoldReleased, nil
+	case http.StatusNotFound:
+		return domain.SeatHoldGone, nil
+	case http.StatusConflict:
+		return domain.SeatHoldConfirmed, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return "", domain.ErrTripsAPIUnavailable.WithDetails(map[string]interface{}{
+			"status_code": resp.StatusCode,
+			"trip_id":     tripID,
+			"hold_id":     holdID,
+			"body":        string(body),
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package clients
+
+import (
+	"bookings-api/internal/domain"
+	"bookings-api/internal/tracing"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// UsersClient defines the interface for interacting with users-api
+type UsersClient interface {
+	// GetUserContacts retrieves the contact details of several users in one request (GET /internal/users?ids=)
+	// Users that do not exist are missing from the map
+	GetUserContacts(ctx context.Context, userIDs []int64) (map[int64]*domain.PassengerContact, error)
+
+	// ConsumeCredits applies the passenger's promotional credits to a booking (POST /internal/credits/consume)
+	// Idempotent by booking: repeating it returns the original consumption
+	ConsumeCredits(ctx context.Context, consumeReq domain.CreditConsumeRequest) (*domain.CreditConsumption, error)
+
+	// ReleaseCredits returns the credits applied to a cancelled booking (POST /internal/credits/release)
+	// Idempotent by booking: repeating it returns the original release
+	ReleaseCredits(ctx context.Context, bookingID string) (*domain.CreditRelease, error)
+
+	// GetSessionStatus retrieves the account state the JWT middleware enforces (GET /internal/users/:id/session-status)
+	GetSessionStatus(ctx context.Context, userID int64) (*domain.SessionStatus, error)
+
+	// RequestGuardianApproval asks the guardian of a dependent account to approve an action (POST /internal/guardian-approvals)
+	// Idempotent by (action, resource_id): repeating it returns the existing approval
+	RequestGuardianApproval(ctx context.Context, approvalReq domain.GuardianApprovalRequest) (*domain.GuardianApproval, error)
+
+	// GetGuardianApproval retrieves the current state of an approval (GET /internal/guardian-approvals/:id)
+	GetGuardianApproval(ctx context.Context, approvalID int64) (*domain.GuardianApproval, error)
+}
+
+// usersHTTPClient implements UsersClient using HTTP calls
+type usersHTTPClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewUsersClient creates a new HTTP client for users-api
+func NewUsersClient(baseURL string) UsersClient {
+	return &usersHTTPClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout:   5 * time.Second,        // 5 second timeout for external calls
+			Transport: tracing.Transport(nil), // Propagates the traceparent to users-api
+		},
+	}
+}
+
+// usersAPIResponse represents the standardized API response wrapper from users-api
+type usersAPIResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// GetUserContacts retrieves the contact details of several users from users-api in one request
+// Uses the internal route (no auth required); only name, email and phone are kept
+//
+// Status codes:
+//   - 200: the users found (missing ones are not listed)
+//   - 5xx or network error: ErrUsersAPIUnavailable
+func (c *usersHTTPClient) GetUserContacts(ctx context.Context, userIDs []int64) (map[int64]*domain.PassengerContact, error) {
+	contacts := make(map[int64]*domain.PassengerContact, len(userIDs))
+	if len(userIDs) == 0 {
+		return contacts, nil
+	}
+
+	ids := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	url := fmt.Sprintf("%s/internal/users?ids=%s", c.baseURL, strings.Join(ids, ","))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Error().Err(err).Int("users", len(userIDs)).Msg("Failed to call users-api")
+		return nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var apiResp usersAPIResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if !apiResp.Success {
+			return nil, fmt.Errorf("users-api returned success=false: %s", apiResp.Error)
+		}
+
+		var list []domain.PassengerContact
+		if err := json.Unmarshal(apiResp.Data, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse users data: %w", err)
+		}
+		for i := range list {
+			contacts[list[i].ID] = &list[i]
+		}
+		return contacts, nil
+
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		log.Error().
+			Int("status_code", resp.StatusCode).
+			Int("users", len(userIDs)).
+			Str("body", string(body)).
+			Msg("users-api returned server error")
+		return nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"status_code": resp.StatusCode,
+		})
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// GetSessionStatus retrieves whether a user's tokens are still usable from users-api
+//
+// Status codes:
+//   - 200: status found
+//   - 404: user deleted (ErrSessionRevoked)
+//   - 5xx or network error: ErrUsersAPIUnavailable
+func (c *usersHTTPClient) GetSessionStatus(ctx context.Context, userID int64) (*domain.SessionStatus, error) {
+	url := fmt.Sprintf("%s/internal/users/%d/session-status", c.baseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var apiResp usersAPIResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		var status domain.SessionStatus
+		if err := json.Unmarshal(apiResp.Data, &status); err != nil {
+			return nil, fmt.Errorf("failed to parse session status: %w", err)
+		}
+		return &status, nil
+
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, domain.ErrSessionRevoked
+
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"status_code": resp.StatusCode,
+		})
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// ConsumeCredits asks users-api to apply the passenger's credits to a booking
+//
+// Status codes:
+//   - 201: credits applied (AppliedAmount may be 0 without balance)
+//   - 200: the booking had already consumed credits (Replayed)
+//   - 404: passenger not found (ErrUserNotFound)
+//   - 5xx or network error: ErrUsersAPIUnavailable
+func (c *usersHTTPClient) ConsumeCredits(ctx context.Context, consumeReq domain.CreditConsumeRequest) (*domain.CreditConsumption, error) {
+	var consumption domain.CreditConsumption
+	status, body, err := c.postInternal(ctx, "/internal/credits/consume", consumeReq, &consumption)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusCreated, http.StatusOK:
+		return &consumption, nil
+
+	case http.StatusNotFound:
+		return nil, domain.ErrUserNotFound.WithDetails(map[string]interface{}{
+			"user_id": consumeReq.UserID,
+		})
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d consuming credits: %s", status, string(body))
+	}
+}
+
+// ReleaseCredits asks users-api to return the credits applied to a booking
+//
+// Status codes:
+//   - 201: credits released
+//   - 200: the booking's credits had already been released (Replayed)
+//   - 404: the booking did not consume credits (nothing released, no error)
+//   - 5xx or network error: ErrUsersAPIUnavailable
+func (c *usersHTTPClient) ReleaseCredits(ctx context.Context, bookingID string) (*domain.CreditRelease, error) {
+	var release domain.CreditRelease
+	status, body, err := c.postInternal(ctx, "/internal/credits/release", domain.CreditReleaseRequest{BookingID: bookingID}, &release)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusCreated, http.StatusOK:
+		return &release, nil
+
+	case http.StatusNotFound:
+		return &domain.CreditRelease{}, nil
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d releasing credits: %s", status, string(body))
+	}
+}
+
+// RequestGuardianApproval asks users-api for the guardian's approval of a dependent's action
+//
+// Status codes:
+//   - 201: approval created (the guardian is notified)
+//   - 200: the approval already existed for (action, resource_id)
+//   - 400: the user is not a dependent account (ErrNotDependentAccount)
+//   - 404: dependent not found (ErrUserNotFound)
+//   - 5xx or network error: ErrUsersAPIUnavailable
+func (c *usersHTTPClient) RequestGuardianApproval(ctx context.Context, approvalReq domain.GuardianApprovalRequest) (*domain.GuardianApproval, error) {
+	var approval domain.GuardianApproval
+	status, body, err := c.postInternal(ctx, "/internal/guardian-approvals", approvalReq, &approval)
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusCreated, http.StatusOK:
+		return &approval, nil
+
+	case http.StatusBadRequest:
+		return nil, domain.ErrNotDependentAccount.WithDetails(map[string]interface{}{
+			"user_id": approvalReq.DependentID,
+		})
+
+	case http.StatusNotFound:
+		return nil, domain.ErrUserNotFound.WithDetails(map[string]interface{}{
+			"user_id": approvalReq.DependentID,
+		})
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d requesting guardian approval: %s", status, string(body))
+	}
+}
+
+// GetGuardianApproval retrieves the state of a guardian approval from users-api
+// Pending approvals past their deadline come back as expired
+//
+// Status codes:
+//   - 200: approval found
+//   - 404: approval not found (ErrGuardianApprovalNotFound)
+//   - 5xx or network error: ErrUsersAPIUnavailable
+func (c *usersHTTPClient) GetGuardianApproval(ctx context.Context, approvalID int64) (*domain.GuardianApproval, error) {
+	url := fmt.Sprintf("%s/internal/guardian-approvals/%d", c.baseURL, approvalID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		var apiResp usersAPIResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		var approval domain.GuardianApproval
+		if err := json.Unmarshal(apiResp.Data, &approval); err != nil {
+			return nil, fmt.Errorf("failed to parse guardian approval: %w", err)
+		}
+		return &approval, nil
+
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, domain.ErrGuardianApprovalNotFound.WithDetails(map[string]interface{}{
+			"approval_id": approvalID,
+		})
+
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"status_code": resp.StatusCode,
+		})
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+// postInternal sends payload to an internal users-api route and decodes the data of a 200/201 response into out
+// 5xx and network errors are returned as ErrUsersAPIUnavailable; other statuses are left to the caller
+func (c *usersHTTPClient) postInternal(ctx context.Context, path string, payload interface{}, out interface{}) (int, []byte, error) {
+	url := c.baseURL + path
+
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("Failed to call users-api")
+		return 0, nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		log.Error().
+			Int("status_code", resp.StatusCode).
+			Str("path", path).
+			Str("body", string(body)).
+			Msg("users-api returned server error")
+		return 0, nil, domain.ErrUsersAPIUnavailable.WithDetails(map[string]interface{}{
+			"status_code": resp.StatusCode,
+		})
+	}
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated {
+		var apiResp usersAPIResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return 0, nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if !apiResp.Success {
+			return 0, nil, fmt.Errorf("users-api returned success=false: %s", apiResp.Error)
+		}
+		if err := json.Unmarshal(apiResp.Data, out); err != nil {
+			return 0, nil, fmt.Errorf("failed to parse response data: %w", err)
+		}
+	}
+	return resp.StatusCode, body, nil
+}
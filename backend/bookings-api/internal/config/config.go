@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +16,91 @@ type Config struct {
 	RabbitMQURL string
 	Environment string
 	TripsAPIURL string
+	UsersAPIURL string
+
+	// RabbitMQ exchanges, queues, routing keys, prefetch and dead-lettering (see topology.go)
+	Topology RabbitTopology
+
+	// Country policies (cancellation windows, fees, max seats per market)
+	CountryPoliciesFile string // Optional JSON file; built-in defaults when empty
+	DefaultCountry      string // Policy used for trips without a known country
+
+	// Load shedding (reject low-priority requests under overload)
+	LoadSheddingMode             string  // auto, on (force) or off (disable)
+	LoadSheddingP99Ms            int     // p99 latency threshold in milliseconds
+	LoadSheddingDBPoolSaturation float64 // DB pool in-use ratio threshold (0-1)
+	LoadSheddingRetryAfterSecs   int     // Retry-After sent with 503 responses
+
+	// Event publishing retries (jittered exponential backoff)
+	PublishMaxAttempts int // Total attempts per event before it is reported as failed
+	PublishRetryBaseMs int // Backoff before the first retry in milliseconds (doubles each retry)
+	PublishRetryMaxMs  int // Upper bound of a single backoff in milliseconds
+
+	// Events that still fail are kept in memory and re-published once the broker is back
+	PublishBufferSize         int // Buffered events; when full, further failed events are reported as failed
+	PublishBufferFlushSeconds int // How often the buffer is retried while the channel is healthy
+
+	// Publishing channels; each concurrent publish checks out its own channel
+	PublishChannelPoolSize int // Channels opened on the publishing connection
+
+	// Database query metrics
+	DBSlowQueryThresholdMs int // Queries slower than this are logged in WARN (0 disables the log)
+
+	// Data retention (archival of old bookings keeping anonymized analytics)
+	BookingRetentionDays        int       // Days a booking in a terminal status is kept after its last update (0 disables archival)
+	AnalyticsRetentionEnabled   bool      // Write an anonymized analytics record for every archived booking
+	AnalyticsRetentionDays      int       // Days anonymized records are kept (0 = forever)
+	AnalyticsPriceBuckets       []float64 // Upper bounds of the price buckets (empty = built-in defaults)
+	RetentionJobIntervalMinutes int       // How often the retention job runs
+	RetentionBatchSize          int       // Bookings archived per transaction
+
+	// Expiration of bookings trips-api never answered (lost reservation.confirmed / reservation.failed)
+	BookingPendingTimeoutMinutes int // Minutes a booking may stay pending before it is expired (0 disables the job)
+	ExpirationJobIntervalSeconds int // How often the expiration job runs
+	ExpirationBatchSize          int // Pending bookings read per query
+
+	// Bookings of dependent accounts waiting for their guardian's approval (users-api)
+	GuardianApprovalJobIntervalSeconds int // How often the approvals of awaiting_guardian bookings are looked up
+
+	// Seat holds taken synchronously in trips-api before creating a booking
+	SeatHoldsEnabled   bool   // Hold seats before creating the booking (requires trips-api seat holds)
+	SeatHoldTTLSeconds int    // How long trips-api keeps an unconfirmed hold
+	InternalAPISecret  string // Shared secret trips-api requires on its seat hold routes (INTERNAL_API_SECRET)
+
+	// Account state checked by the JWT middleware (users-api session status)
+	SessionStatusCacheSeconds int // How long a user's session status is reused; the maximum delay to enforce a revocation, deactivation or ban
+
+	// Promotional credits (users-api ledger) applied to the fare when trips-api confirms the seats
+	PromotionalCreditsEnabled bool // Consume the passenger's credits at confirmation (already applied credits are always released)
+
+	// Live booking status stream (Server-Sent Events)
+	StreamHeartbeatSeconds int // Interval of keep-alive comments (the booking state is re-read on each one)
+	StreamMaxMinutes       int // Maximum duration of a stream before the server closes it
+	StreamMaxPerUser       int // Streams a user can keep open at once in one instance (0 = unlimited)
+
+	// Split payments (one payment share per passenger of a group booking)
+	PaymentShareTimeoutMinutes int    // Minutes to pay the shares before the organizer covers the rest (0 disables the deadline)
+	PaymentJobIntervalSeconds  int    // How often the payment deadline job runs
+	PaymentLinkBaseURL         string // Payment page; a share's link is <base>/<token>
+	PaymentWebhookSecret       string // Shared secret of the payment provider webhook (empty disables it)
+
+	// Payment provider (authorization gates the confirmation of bookings that are not split)
+	PaymentProvider                    string // "" (disabled, bookings confirmed without payment), "mock" or "http"
+	PaymentProviderURL                 string // Base URL of the http provider API (checkout base URL for mock)
+	PaymentProviderAPIKey              string // Secret key sent as Bearer token to the http provider
+	PaymentProviderTimeoutSeconds      int    // Timeout of each call to the provider
+	PaymentAuthorizationTimeoutMinutes int    // Minutes to complete a pending authorization before the booking is cancelled (0 disables)
+
+	// Two-admin approval of destructive admin actions (force-cancel, mark paid)
+	AdminApprovalTTLMinutes int // Minutes a second admin has to approve a requested action
+
+	// Startup smoke test (deployment pipelines): book, confirm and cancel a seat on a sandbox trip
+	SmokeTest               bool   // Run the smoke test once the server is listening
+	SmokeTestExit           bool   // Exit after the smoke test (code 0 passed, 1 failed) instead of keeping the server up
+	SmokeTestTripID         string // Sandbox trip with free seats
+	SmokeTestPassengerID    int64  // Synthetic passenger that books and cancels
+	SmokeTestPaymentToken   string // Sandbox payment method token (used when PAYMENT_PROVIDER is set)
+	SmokeTestTimeoutSeconds int    // Maximum duration of the whole run
 }
 
 func LoadConfig() (*Config, error) {
@@ -26,7 +114,78 @@ func LoadConfig() (*Config, error) {
 		// Variables NO CRÍTICAS - Con defaults razonables
 		ServerPort:  getEnv("SERVER_PORT", "8003"),
 		TripsAPIURL: getEnv("TRIPS_API_URL", "http://localhost:8002"),
+		UsersAPIURL: getEnv("USERS_API_URL", "http://localhost:8001"),
 		Environment: getEnv("ENVIRONMENT", "development"),
+
+		CountryPoliciesFile: getEnv("COUNTRY_POLICIES_FILE", ""),
+		DefaultCountry:      getEnv("DEFAULT_COUNTRY", "AR"),
+
+		LoadSheddingMode:             getEnv("LOAD_SHEDDING_MODE", "auto"),
+		LoadSheddingP99Ms:            getEnvInt("LOAD_SHEDDING_P99_MS", 1500),
+		LoadSheddingDBPoolSaturation: getEnvFloat("LOAD_SHEDDING_DB_POOL_SATURATION", 0.9),
+		LoadSheddingRetryAfterSecs:   getEnvInt("LOAD_SHEDDING_RETRY_AFTER_SECONDS", 5),
+
+		PublishMaxAttempts: getEnvInt("PUBLISH_MAX_ATTEMPTS", 4),
+		PublishRetryBaseMs: getEnvInt("PUBLISH_RETRY_BASE_MS", 200),
+		PublishRetryMaxMs:  getEnvInt("PUBLISH_RETRY_MAX_MS", 2000),
+
+		PublishBufferSize:         getEnvInt("PUBLISH_BUFFER_SIZE", 1000),
+		PublishBufferFlushSeconds: getEnvInt("PUBLISH_BUFFER_FLUSH_SECONDS", 5),
+
+		PublishChannelPoolSize: getEnvInt("PUBLISH_CHANNEL_POOL_SIZE", 4),
+
+		DBSlowQueryThresholdMs: getEnvInt("DB_SLOW_QUERY_THRESHOLD_MS", 200),
+
+		BookingRetentionDays:        getEnvInt("BOOKING_RETENTION_DAYS", 0),
+		AnalyticsRetentionEnabled:   getEnv("ANALYTICS_RETENTION_ENABLED", "true") == "true",
+		AnalyticsRetentionDays:      getEnvInt("ANALYTICS_RETENTION_DAYS", 0),
+		AnalyticsPriceBuckets:       getEnvFloatSlice("ANALYTICS_PRICE_BUCKETS"),
+		RetentionJobIntervalMinutes: getEnvInt("RETENTION_JOB_INTERVAL_MINUTES", 60),
+		RetentionBatchSize:          getEnvInt("RETENTION_BATCH_SIZE", 500),
+
+		BookingPendingTimeoutMinutes: getEnvInt("BOOKING_PENDING_TIMEOUT_MINUTES", 15),
+		ExpirationJobIntervalSeconds: getEnvInt("EXPIRATION_JOB_INTERVAL_SECONDS", 60),
+		ExpirationBatchSize:          getEnvInt("EXPIRATION_BATCH_SIZE", 100),
+
+		GuardianApprovalJobIntervalSeconds: getEnvInt("GUARDIAN_APPROVAL_JOB_INTERVAL_SECONDS", 30),
+
+		SeatHoldsEnabled:   getEnv("SEAT_HOLDS_ENABLED", "false") == "true",
+		SeatHoldTTLSeconds: getEnvInt("SEAT_HOLD_TTL_SECONDS", 300),
+		InternalAPISecret:  getEnv("INTERNAL_API_SECRET", ""),
+
+		SessionStatusCacheSeconds: getEnvInt("SESSION_STATUS_CACHE_SECONDS", 30),
+
+		PromotionalCreditsEnabled: getEnv("PROMOTIONAL_CREDITS_ENABLED", "true") == "true",
+
+		StreamHeartbeatSeconds: getEnvInt("STREAM_HEARTBEAT_SECONDS", 15),
+		StreamMaxMinutes:       getEnvInt("STREAM_MAX_MINUTES", 10),
+		StreamMaxPerUser:       getEnvInt("STREAM_MAX_PER_USER", 5),
+
+		PaymentShareTimeoutMinutes: getEnvInt("PAYMENT_SHARE_TIMEOUT_MINUTES", 120),
+		PaymentJobIntervalSeconds:  getEnvInt("PAYMENT_JOB_INTERVAL_SECONDS", 60),
+		PaymentLinkBaseURL:         getEnv("PAYMENT_LINK_BASE_URL", "http://localhost:3000/pay"),
+		PaymentWebhookSecret:       getEnv("PAYMENT_WEBHOOK_SECRET", ""),
+
+		PaymentProvider:                    getEnv("PAYMENT_PROVIDER", ""),
+		PaymentProviderURL:                 getEnv("PAYMENT_PROVIDER_URL", ""),
+		PaymentProviderAPIKey:              getEnv("PAYMENT_PROVIDER_API_KEY", ""),
+		PaymentProviderTimeoutSeconds:      getEnvInt("PAYMENT_PROVIDER_TIMEOUT_SECONDS", 10),
+		PaymentAuthorizationTimeoutMinutes: getEnvInt("PAYMENT_AUTHORIZATION_TIMEOUT_MINUTES", 30),
+
+		AdminApprovalTTLMinutes: getEnvInt("ADMIN_APPROVAL_TTL_MINUTES", 60),
+
+		SmokeTest:               getEnv("SMOKE_TEST", "false") == "true",
+		SmokeTestExit:           getEnv("SMOKE_TEST_EXIT", "true") == "true",
+		SmokeTestTripID:         getEnv("SMOKE_TEST_TRIP_ID", ""),
+		SmokeTestPassengerID:    int64(getEnvInt("SMOKE_TEST_PASSENGER_ID", 0)),
+		SmokeTestPaymentToken:   getEnv("SMOKE_TEST_PAYMENT_TOKEN", "tok_smoke"),
+		SmokeTestTimeoutSeconds: getEnvInt("SMOKE_TEST_TIMEOUT_SECONDS", 120),
+
+		Topology: loadTopology(),
+	}
+
+	if err := cfg.Topology.Validate(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
@@ -53,6 +212,46 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// getEnvInt retrieves an integer environment variable with a fallback default value
+// Invalid values fall back to the default
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat retrieves a float environment variable with a fallback default value
+// Invalid values fall back to the default
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloatSlice retrieves a comma-separated list of floats, sorted ascending
+// Invalid entries are skipped; returns nil when the variable is not set
+func getEnvFloatSlice(key string) []float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var values []float64
+	for _, part := range strings.Split(value, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			continue
+		}
+		values = append(values, f)
+	}
+	sort.Float64s(values)
+	return values
+}
+
 // mustGetEnv obtiene variable REQUERIDA o hace panic (fail-fast)
 // Use for critical configuration that must be present
 func mustGetEnv(key string) string {
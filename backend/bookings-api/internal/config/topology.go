@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RabbitTopology is the effective RabbitMQ topology: the exchange bookings-api consumes trips-api
+// events from and the exchange it publishes its own events to
+// Every name comes from the environment so staging and production can diverge without code changes
+type RabbitTopology struct {
+	Consumer  ConsumerTopology  `json:"consumer"`
+	Publisher PublisherTopology `json:"publisher"`
+}
+
+// ConsumerTopology is the queue bound to the trips-api exchange
+type ConsumerTopology struct {
+	Exchange     string              `json:"exchange"`
+	ExchangeType string              `json:"exchange_type"`
+	Queue        string              `json:"queue"`
+	Prefetch     int                 `json:"prefetch"`
+	RoutingKeys  ConsumerRoutingKeys `json:"routing_keys"`
+
+	// Batch mode: up to BatchSize consecutive events of the same type (trip.cancelled, reservation.failed)
+	// are applied in a single DB transaction and ACKed together; 1 processes every event on its own
+	// A batch is flushed when full, after BatchWaitMs without filling it, or when a different event arrives
+	BatchSize   int `json:"batch_size"`
+	BatchWaitMs int `json:"batch_wait_ms"`
+
+	// Dead-lettering of the queue (x-dead-letter-* arguments); an empty exchange disables it
+	// RabbitMQ rejects a queue re-declared with different arguments: changing these settings
+	// on an existing queue requires deleting it first (or moving them to a broker policy)
+	DeadLetterExchange     string `json:"dead_letter_exchange,omitempty"`
+	DeadLetterExchangeType string `json:"dead_letter_exchange_type,omitempty"`
+	DeadLetterRoutingKey   string `json:"dead_letter_routing_key,omitempty"` // Empty keeps the original routing key
+	DeadLetterQueue        string `json:"dead_letter_queue,omitempty"`       // Declared and bound to the DLX when set
+}
+
+// ConsumerRoutingKeys are the routing keys bound to the consumer queue, one per handled event type
+type ConsumerRoutingKeys struct {
+	TripCancelled                 string `json:"trip.cancelled"`
+	ReservationFailed             string `json:"reservation.failed"`
+	ReservationConfirmed          string `json:"reservation.confirmed"`
+	ReservationModificationFailed string `json:"reservation.modification_failed"`
+}
+
+// PublisherTopology is the exchange bookings-api publishes its events to
+type PublisherTopology struct {
+	Exchange     string               `json:"exchange"`
+	ExchangeType string               `json:"exchange_type"`
+	RoutingKeys  PublisherRoutingKeys `json:"routing_keys"`
+}
+
+// PublisherRoutingKeys are the routing keys of the published events, one per event type
+type PublisherRoutingKeys struct {
+	ReservationCreated   string `json:"reservation.created"`
+	ReservationCancelled string `json:"reservation.cancelled"`
+	ReservationModified  string `json:"reservation.modified"`
+	PaymentSharePaid     string `json:"payment.share_paid"`
+	PaymentCompleted     string `json:"payment.completed"`
+}
+
+// exchangeTypes are the exchange types supported by RabbitMQ without plugins
+var exchangeTypes = map[string]bool{"direct": true, "topic": true, "fanout": true, "headers": true}
+
+// loadTopology reads the RabbitMQ topology; the defaults are the names used before it was configurable
+func loadTopology() RabbitTopology {
+	return RabbitTopology{
+		Consumer: ConsumerTopology{
+			Exchange:     getEnv("RABBITMQ_CONSUMER_EXCHANGE", "trips.events"),
+			ExchangeType: getEnv("RABBITMQ_CONSUMER_EXCHANGE_TYPE", "topic"),
+			Queue:        getEnv("RABBITMQ_CONSUMER_QUEUE", "bookings.trip-events"),
+			Prefetch:     getEnvInt("RABBITMQ_CONSUMER_PREFETCH", 10),
+			BatchSize:    getEnvInt("RABBITMQ_CONSUMER_BATCH_SIZE", 1),
+			BatchWaitMs:  getEnvInt("RABBITMQ_CONSUMER_BATCH_WAIT_MS", 100),
+			RoutingKeys: ConsumerRoutingKeys{
+				TripCancelled:                 getEnv("RABBITMQ_RK_TRIP_CANCELLED", "trip.cancelled"),
+				ReservationFailed:             getEnv("RABBITMQ_RK_RESERVATION_FAILED", "reservation.failed"),
+				ReservationConfirmed:          getEnv("RABBITMQ_RK_RESERVATION_CONFIRMED", "reservation.confirmed"),
+				ReservationModificationFailed: getEnv("RABBITMQ_RK_RESERVATION_MODIFICATION_FAILED", "reservation.modification_failed"),
+			},
+			DeadLetterExchange:     getEnv("RABBITMQ_CONSUMER_DLX", ""),
+			DeadLetterExchangeType: getEnv("RABBITMQ_CONSUMER_DLX_TYPE", "topic"),
+			DeadLetterRoutingKey:   getEnv("RABBITMQ_CONSUMER_DLX_ROUTING_KEY", ""),
+			DeadLetterQueue:        getEnv("RABBITMQ_CONSUMER_DLQ", ""),
+		},
+		Publisher: PublisherTopology{
+			Exchange:     getEnv("RABBITMQ_PUBLISHER_EXCHANGE", "bookings.events"),
+			ExchangeType: getEnv("RABBITMQ_PUBLISHER_EXCHANGE_TYPE", "topic"),
+			RoutingKeys: PublisherRoutingKeys{
+				ReservationCreated:   getEnv("RABBITMQ_RK_RESERVATION_CREATED", "reservation.created"),
+				ReservationCancelled: getEnv("RABBITMQ_RK_RESERVATION_CANCELLED", "reservation.cancelled"),
+				ReservationModified:  getEnv("RABBITMQ_RK_RESERVATION_MODIFIED", "reservation.modified"),
+				PaymentSharePaid:     getEnv("RABBITMQ_RK_PAYMENT_SHARE_PAID", "payment.share_paid"),
+				PaymentCompleted:     getEnv("RABBITMQ_RK_PAYMENT_COMPLETED", "payment.completed"),
+			},
+		},
+	}
+}
+
+// Validate checks the topology at startup so a typo fails the deployment instead of
+// silently consuming from (or publishing to) the wrong place
+// Returns every problem found, not only the first one
+func (t RabbitTopology) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	c := t.Consumer
+	if c.Exchange == "" {
+		add("consumer exchange is required")
+	}
+	if !exchangeTypes[c.ExchangeType] {
+		add("consumer exchange type %q is not one of direct, topic, fanout, headers", c.ExchangeType)
+	}
+	if c.Queue == "" {
+		add("consumer queue is required")
+	}
+	if c.Prefetch < 1 {
+		add("consumer prefetch must be at least 1 (got %d)", c.Prefetch)
+	}
+	if c.BatchSize < 1 {
+		add("consumer batch size must be at least 1 (got %d)", c.BatchSize)
+	} else if c.BatchSize > 1 {
+		// RabbitMQ never delivers more than prefetch unacknowledged messages: a larger batch could never fill
+		if c.BatchSize > c.Prefetch {
+			add("consumer batch size (%d) must not exceed the prefetch (%d)", c.BatchSize, c.Prefetch)
+		}
+		if c.BatchWaitMs < 1 {
+			add("consumer batch wait must be at least 1ms (got %d)", c.BatchWaitMs)
+		}
+	}
+	checkRoutingKeys("consumer", map[string]string{
+		"trip.cancelled":                  c.RoutingKeys.TripCancelled,
+		"reservation.failed":              c.RoutingKeys.ReservationFailed,
+		"reservation.confirmed":           c.RoutingKeys.ReservationConfirmed,
+		"reservation.modification_failed": c.RoutingKeys.ReservationModificationFailed,
+	}, add)
+
+	if c.DeadLetterExchange != "" {
+		if c.DeadLetterExchange == c.Exchange {
+			add("consumer dead-letter exchange must differ from the consumer exchange")
+		}
+		if !exchangeTypes[c.DeadLetterExchangeType] {
+			add("consumer dead-letter exchange type %q is not one of direct, topic, fanout, headers", c.DeadLetterExchangeType)
+		}
+		if c.DeadLetterQueue != "" && c.DeadLetterQueue == c.Queue {
+			add("consumer dead-letter queue must differ from the consumer queue")
+		}
+	} else if c.DeadLetterRoutingKey != "" || c.DeadLetterQueue != "" {
+		add("consumer dead-letter routing key and queue require a dead-letter exchange")
+	}
+
+	p := t.Publisher
+	if p.Exchange == "" {
+		add("publisher exchange is required")
+	}
+	if !exchangeTypes[p.ExchangeType] {
+		add("publisher exchange type %q is not one of direct, topic, fanout, headers", p.ExchangeType)
+	}
+	checkRoutingKeys("publisher", map[string]string{
+		"reservation.created":   p.RoutingKeys.ReservationCreated,
+		"reservation.cancelled": p.RoutingKeys.ReservationCancelled,
+		"reservation.modified":  p.RoutingKeys.ReservationModified,
+		"payment.share_paid":    p.RoutingKeys.PaymentSharePaid,
+		"payment.completed":     p.RoutingKeys.PaymentCompleted,
+	}, add)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid RabbitMQ topology: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkRoutingKeys requires a routing key per event type and no key shared by two event types
+// (consumed events are dispatched by routing key, published ones are told apart by it)
+func checkRoutingKeys(side string, keys map[string]string, add func(string, ...interface{})) {
+	owners := make(map[string]string, len(keys))
+	for _, eventType := range sortedKeys(keys) {
+		key := keys[eventType]
+		if key == "" {
+			add("%s routing key of %s is required", side, eventType)
+			continue
+		}
+		if strings.ContainsAny(key, "*#") {
+			add("%s routing key of %s must not contain wildcards (got %q)", side, eventType, key)
+		}
+		if owner, taken := owners[key]; taken {
+			add("%s routing key %q is used by both %s and %s", side, key, owner, eventType)
+			continue
+		}
+		owners[key] = eventType
+	}
+}
+
+// sortedKeys returns the map keys in order, so validation errors are stable
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
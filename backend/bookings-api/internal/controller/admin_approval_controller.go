@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"bookings-api/internal/domain"
+	"bookings-api/internal/repository"
+	"bookings-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminApprovalController exposes the two-admin approval of destructive admin actions (admin only)
+type AdminApprovalController struct {
+	approvalService service.AdminApprovalService
+}
+
+// NewAdminApprovalController creates a new instance of AdminApprovalController
+func NewAdminApprovalController(approvalService service.AdminApprovalService) *AdminApprovalController {
+	return &AdminApprovalController{
+		approvalService: approvalService,
+	}
+}
+
+// CreateApproval handles POST /api/v1/admin/approvals
+// Requests force_cancel_booking or mark_booking_paid; nothing runs until a different admin approves it
+func (ac *AdminApprovalController) CreateApproval(c *gin.Context) {
+	var req domain.CreateAdminApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Invalid request body", err.Error()))
+		return
+	}
+
+	approval, err := ac.approvalService.Request(c.Request.Context(), req, c.GetInt64("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// ListApprovals handles GET /api/v1/admin/approvals?status=pending&action=&booking_id=&page=1&limit=20
+// Returns approvals newest first
+func (ac *AdminApprovalController) ListApprovals(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := ac.approvalService.List(repository.AdminApprovalFilter{
+		Status:      c.Query("status"),
+		Action:      c.Query("action"),
+		BookingUUID: c.Query("booking_id"),
+		Page:        page,
+		Limit:       limit,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetApproval handles GET /api/v1/admin/approvals/:id
+// Returns the approval with its audit trail
+func (ac *AdminApprovalController) GetApproval(c *gin.Context) {
+	id, ok := parseApprovalID(c)
+	if !ok {
+		return
+	}
+
+	approval, err := ac.approvalService.Get(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// ApproveApproval handles POST /api/v1/admin/approvals/:id/approve
+// Runs the action; 403 if the approver is the admin who requested it, 409 if it is no longer pending
+func (ac *AdminApprovalController) ApproveApproval(c *gin.Context) {
+	id, ok := parseApprovalID(c)
+	if !ok {
+		return
+	}
+
+	approval, err := ac.approvalService.Approve(c.Request.Context(), id, c.GetInt64("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// RejectApproval handles POST /api/v1/admin/approvals/:id/reject
+// Closes a pending approval without running it (optional body {"note": "..."})
+func (ac *AdminApprovalController) RejectApproval(c *gin.Context) {
+	id, ok := parseApprovalID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.RejectAdminApprovalRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(domain.NewAppError("VALIDATION_ERROR", "Invalid request body", err.Error()))
+			return
+		}
+	}
+
+	approval, err := ac.approvalService.Reject(id, c.GetInt64("user_id"), req.Note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// parseApprovalID reads the :id path parameter (adds a validation error if it is not a positive integer)
+func parseApprovalID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Approval ID must be a positive integer", nil))
+		return 0, false
+	}
+	return uint(id), true
+}
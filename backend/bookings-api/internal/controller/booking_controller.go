@@ -3,6 +3,7 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"bookings-api/internal/domain"
 	"bookings-api/internal/service"
@@ -46,6 +47,9 @@ func (bc *BookingController) CreateBooking(c *gin.Context) {
 		return
 	}
 
+	// Dependent accounts need their guardian's approval before the booking holds seats
+	req.RequiresGuardianApproval = domain.HasRestrictionInContext(c, domain.RestrictionBookingRequiresApproval)
+
 	// Call service to create booking
 	booking, err := bc.bookingService.CreateBooking(c.Request.Context(), req)
 	if err != nil {
@@ -102,6 +106,70 @@ func (bc *BookingController) GetBooking(c *gin.Context) {
 	})
 }
 
+// GetBookingState handles GET /api/v1/bookings/:id/state
+// Returns the saga state of a booking (pending/confirmed/failed/cancelled/completed) and its transitions
+func (bc *BookingController) GetBookingState(c *gin.Context) {
+	// Extract authenticated user ID from JWT context
+	userID, err := domain.GetUserIDFromContext(c)
+	if err != nil {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	// Extract booking ID from URL path
+	bookingID := c.Param("id")
+	if bookingID == "" {
+		c.Error(domain.NewAppError("INVALID_BOOKING_ID", "Booking ID is required", nil))
+		return
+	}
+
+	// Authorization (passenger, driver or admin) is checked by the service
+	role, _ := domain.GetRoleFromContext(c)
+	state, err := bc.bookingService.GetBookingState(c.Request.Context(), bookingID, userID, role == "admin")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    state,
+	})
+}
+
+// GetTripBookings handles GET /api/v1/trips/:trip_id/bookings
+// Lists the bookings of a trip with passenger contact details (driver of the trip only)
+// Optional query parameter: status (pending, confirmed, cancelled, completed, failed, expired)
+func (bc *BookingController) GetTripBookings(c *gin.Context) {
+	// Extract authenticated user ID from JWT context
+	userID, err := domain.GetUserIDFromContext(c)
+	if err != nil {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	// Extract trip ID from URL path
+	tripID := c.Param("trip_id")
+	if tripID == "" {
+		c.Error(domain.NewAppError("INVALID_INPUT", "Trip ID is required", nil))
+		return
+	}
+
+	// Authorization (driver of the trip, checked against trips-api) is done by the service
+	bookings, err := bc.bookingService.GetTripBookings(c.Request.Context(), tripID, userID, c.Query("status"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    bookings,
+	})
+}
+
 // ListBookings handles GET /api/v1/bookings
 // Lists all bookings for the authenticated user with pagination
 func (bc *BookingController) ListBookings(c *gin.Context) {
@@ -168,6 +236,46 @@ func (bc *BookingController) CancelBooking(c *gin.Context) {
 	})
 }
 
+// ModifyBookingSeats handles PATCH /api/v1/bookings/:id/seats
+// Changes the number of seats of a confirmed booking (e.g., from 3 to 1)
+// Authorization: Only the booking's passenger
+func (bc *BookingController) ModifyBookingSeats(c *gin.Context) {
+	// Extract authenticated user ID from JWT context
+	userID, err := domain.GetUserIDFromContext(c)
+	if err != nil {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	// Extract booking ID from URL path
+	bookingID := c.Param("id")
+	if bookingID == "" {
+		c.Error(domain.NewAppError("INVALID_BOOKING_ID", "Booking ID is required", nil))
+		return
+	}
+
+	// Bind and validate request body
+	var req domain.ModifyBookingSeatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Invalid request body", err.Error()))
+		return
+	}
+
+	// Call service to modify the booking
+	// Service layer handles authorization (passenger check)
+	booking, err := bc.bookingService.ModifyBookingSeats(c.Request.Context(), bookingID, userID, req.Seats)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Return success response with the recalculated booking
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    booking,
+	})
+}
+
 // GetAllBookings handles GET /api/v1/admin/bookings
 // Lists all bookings in the system with pagination and filters (admin only)
 func (bc *BookingController) GetAllBookings(c *gin.Context) {
@@ -202,3 +310,103 @@ func (bc *BookingController) GetAllBookings(c *gin.Context) {
 		},
 	})
 }
+
+// GetBookingAsOf handles GET /api/v1/admin/bookings/:id/as-of?ts=<RFC3339>
+// Reconstructs the booking's state at a past moment from its status history (admin only)
+func (bc *BookingController) GetBookingAsOf(c *gin.Context) {
+	// Extract booking ID from URL path
+	bookingID := c.Param("id")
+	if bookingID == "" {
+		c.Error(domain.NewAppError("INVALID_BOOKING_ID", "Booking ID is required", nil))
+		return
+	}
+
+	// Parse the requested moment (RFC3339, e.g. 2025-01-15T10:30:00Z)
+	ts := c.Query("ts")
+	if ts == "" {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Query parameter 'ts' is required", nil))
+		return
+	}
+	asOf, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Query parameter 'ts' must be RFC3339", err.Error()))
+		return
+	}
+
+	// Call service to reconstruct the booking
+	result, err := bc.bookingService.GetBookingAsOf(c.Request.Context(), bookingID, asOf)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetTripPolicy handles GET /api/v1/admin/trips/:id/policy
+// Returns the country policy (cancellation window, fees, max seats) that applies to a trip
+// Admin only - used to answer "why was I charged this fee?" support tickets
+func (bc *BookingController) GetTripPolicy(c *gin.Context) {
+	// Extract trip ID from URL path
+	tripID := c.Param("id")
+	if tripID == "" {
+		c.Error(domain.NewAppError("INVALID_TRIP_ID", "Trip ID is required", nil))
+		return
+	}
+
+	// Call service to resolve the effective policy
+	result, err := bc.bookingService.GetEffectivePolicy(c.Request.Context(), tripID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetUserCO2Savings handles GET /api/v1/users/:id/co2-savings
+// Returns the estimated CO2 saved by a user's confirmed and completed bookings
+// Authorization: Only the user themselves or an admin
+func (bc *BookingController) GetUserCO2Savings(c *gin.Context) {
+	// Extract authenticated user ID from JWT context
+	authUserID, err := domain.GetUserIDFromContext(c)
+	if err != nil {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	// Extract target user ID from URL path
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Error(domain.NewAppError("INVALID_INPUT", "User ID must be a number", nil))
+		return
+	}
+
+	// Authorization: users can only see their own savings unless they are admin
+	role, _ := domain.GetRoleFromContext(c)
+	if userID != authUserID && role != "admin" {
+		c.Error(domain.ErrUnauthorized.WithMessage("You can only view your own CO2 savings"))
+		return
+	}
+
+	// Call service to aggregate savings
+	result, err := bc.bookingService.GetUserCO2Savings(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// Return success response
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
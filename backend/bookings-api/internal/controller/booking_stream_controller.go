@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"bookings-api/internal/domain"
+	"bookings-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// BookingStreamConfig holds the timing of the live booking status stream
+type BookingStreamConfig struct {
+	Heartbeat   time.Duration // Interval of keep-alive comments; the booking state is also re-read on each one
+	MaxDuration time.Duration // The stream is closed after this long (EventSource clients reconnect on their own)
+}
+
+// BookingStreamController pushes booking status transitions to clients over Server-Sent Events
+type BookingStreamController struct {
+	bookingService service.BookingService
+	statusHub      service.BookingStatusHub
+	cfg            BookingStreamConfig
+}
+
+// NewBookingStreamController creates a new instance of BookingStreamController
+func NewBookingStreamController(bookingService service.BookingService, statusHub service.BookingStatusHub, cfg BookingStreamConfig) *BookingStreamController {
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = 15 * time.Second
+	}
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = 10 * time.Minute
+	}
+	return &BookingStreamController{
+		bookingService: bookingService,
+		statusHub:      statusHub,
+		cfg:            cfg,
+	}
+}
+
+// StreamBookingStatus handles GET /api/v1/bookings/:id/stream
+// Sends the current status as a first "status" event, then one "status" event per transition,
+// with a ": heartbeat" comment every Heartbeat. The stream ends once the booking reaches a terminal status.
+//
+// Transitions applied by this instance are pushed as they happen (consumer, cancellation, expiration job);
+// the state re-read on every heartbeat catches the ones applied by other instances.
+func (sc *BookingStreamController) StreamBookingStatus(c *gin.Context) {
+	// Extract authenticated user ID from JWT context
+	userID, err := domain.GetUserIDFromContext(c)
+	if err != nil {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	// Extract booking ID from URL path
+	bookingID := c.Param("id")
+	if bookingID == "" {
+		c.Error(domain.NewAppError("INVALID_BOOKING_ID", "Booking ID is required", nil))
+		return
+	}
+
+	// Streams are exempt from load shedding: the per-user cap keeps one client from holding every connection
+	release, err := sc.statusHub.OpenStream(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer release()
+
+	// Subscribe before reading the state so no transition is lost in between
+	events, unsubscribe := sc.statusHub.Subscribe(bookingID)
+	defer unsubscribe()
+
+	// Authorization (passenger, driver or admin) is checked by the service, same as GET /bookings/:id/state
+	role, _ := domain.GetRoleFromContext(c)
+	isAdmin := role == "admin"
+	state, err := sc.bookingService.GetBookingState(c.Request.Context(), bookingID, userID, isAdmin)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// The server WriteTimeout would cut the stream: this response has its own limit (MaxDuration)
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn().
+			Err(err).
+			Str("booking_id", bookingID).
+			Msg("Could not clear write deadline for booking stream")
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	c.Status(http.StatusOK)
+
+	lastStatus := state.Status
+	sc.sendStatus(c, domain.BookingStatusEventFromState(state, ""))
+	if state.Terminal {
+		return
+	}
+
+	heartbeat := time.NewTicker(sc.cfg.Heartbeat)
+	defer heartbeat.Stop()
+	maxDuration := time.NewTimer(sc.cfg.MaxDuration)
+	defer maxDuration.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case <-maxDuration.C:
+			return
+
+		case event := <-events:
+			if event.Status == lastStatus {
+				continue
+			}
+			event.PreviousStatus = lastStatus
+			lastStatus = event.Status
+			sc.sendStatus(c, event)
+			if event.Terminal {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
+			state, err := sc.bookingService.GetBookingState(c.Request.Context(), bookingID, userID, isAdmin)
+			if err != nil {
+				log.Warn().
+					Err(err).
+					Str("booking_id", bookingID).
+					Msg("Could not refresh booking state for stream")
+				continue
+			}
+			if state.Status == lastStatus {
+				continue
+			}
+			event := domain.BookingStatusEventFromState(state, lastStatus)
+			lastStatus = event.Status
+			sc.sendStatus(c, event)
+			if event.Terminal {
+				return
+			}
+		}
+	}
+}
+
+// sendStatus writes a "status" event and flushes it to the client
+func (sc *BookingStreamController) sendStatus(c *gin.Context, event domain.BookingStatusEvent) {
+	c.SSEvent("status", event)
+	c.Writer.Flush()
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"net/http"
+
+	"bookings-api/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DBMetricsController exposes the per-query database metrics (admin only)
+type DBMetricsController struct {
+	metrics *database.QueryMetrics
+}
+
+// NewDBMetricsController creates a new instance of DBMetricsController
+func NewDBMetricsController(metrics *database.QueryMetrics) *DBMetricsController {
+	return &DBMetricsController{
+		metrics: metrics,
+	}
+}
+
+// GetStats handles GET /api/v1/admin/db-metrics
+// Returns query duration histograms by operation/table, slow query count and lock error counters
+func (dc *DBMetricsController) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dc.metrics.Stats(),
+	})
+}
@@ -3,6 +3,9 @@ package controller
 import (
 	"net/http"
 
+	"bookings-api/internal/domain"
+	"bookings-api/internal/service"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,6 +14,7 @@ import (
 type HealthController struct {
 	serviceName string
 	servicePort string
+	smokeTest   service.SmokeTestService
 }
 
 // NewHealthController creates a new HealthController instance
@@ -18,13 +22,15 @@ type HealthController struct {
 // Parameters:
 //   - serviceName: Name of the service (e.g., "bookings-api")
 //   - servicePort: Port the service is running on (e.g., "8003")
+//   - smokeTest: Startup smoke test reported by GET /health/smoke
 //
 // Returns:
 //   - *HealthController: Initialized health controller
-func NewHealthController(serviceName, servicePort string) *HealthController {
+func NewHealthController(serviceName, servicePort string, smokeTest service.SmokeTestService) *HealthController {
 	return &HealthController{
 		serviceName: serviceName,
 		servicePort: servicePort,
+		smokeTest:   smokeTest,
 	}
 }
 
@@ -53,3 +59,27 @@ func (h *HealthController) HealthCheck(c *gin.Context) {
 		"port":    h.servicePort,
 	})
 }
+
+// SmokeCheck handles GET /health/smoke requests
+// Returns the result of the startup smoke test (SMOKE_TEST=true), used by deployment pipelines
+//
+// HTTP Status:
+//   - 200 OK: passed
+//   - 202 Accepted: still running (poll again)
+//   - 503 Service Unavailable: failed (see the failing step)
+//   - 404 Not Found: smoke test disabled
+func (h *HealthController) SmokeCheck(c *gin.Context) {
+	result := h.smokeTest.Result()
+
+	status := http.StatusOK
+	switch result.Status {
+	case domain.SmokeStatusRunning:
+		status = http.StatusAccepted
+	case domain.SmokeStatusFailed:
+		status = http.StatusServiceUnavailable
+	case domain.SmokeStatusDisabled:
+		status = http.StatusNotFound
+	}
+
+	c.JSON(status, result)
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"net/http"
+
+	"bookings-api/internal/domain"
+	"bookings-api/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoadSheddingController exposes the load shedder state and its manual override (admin only)
+type LoadSheddingController struct {
+	shedder *middleware.LoadShedder
+}
+
+// NewLoadSheddingController creates a new instance of LoadSheddingController
+func NewLoadSheddingController(shedder *middleware.LoadShedder) *LoadSheddingController {
+	return &LoadSheddingController{
+		shedder: shedder,
+	}
+}
+
+// SetLoadSheddingModeRequest represents the request body for changing the override flag
+type SetLoadSheddingModeRequest struct {
+	Mode string `json:"mode" binding:"required"` // auto, on, off
+}
+
+// GetStatus handles GET /api/v1/admin/load-shedding
+// Returns the current overload state, thresholds and shed counters
+func (lc *LoadSheddingController) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lc.shedder.Stats(),
+	})
+}
+
+// SetMode handles PUT /api/v1/admin/load-shedding
+// Manually forces load shedding on/off or returns it to automatic mode
+func (lc *LoadSheddingController) SetMode(c *gin.Context) {
+	var req SetLoadSheddingModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Invalid request body", err.Error()))
+		return
+	}
+
+	if err := lc.shedder.SetMode(req.Mode); err != nil {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", err.Error(), nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    lc.shedder.Stats(),
+	})
+}
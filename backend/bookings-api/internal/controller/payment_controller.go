@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"bookings-api/internal/domain"
+	"bookings-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentController handles the payment shares of split-payment bookings
+// and the payments processed by the payment provider
+type PaymentController struct {
+	paymentSplitService service.PaymentSplitService
+	paymentService      service.PaymentService
+}
+
+// NewPaymentController creates a new instance of PaymentController
+func NewPaymentController(paymentSplitService service.PaymentSplitService, paymentService service.PaymentService) *PaymentController {
+	return &PaymentController{
+		paymentSplitService: paymentSplitService,
+		paymentService:      paymentService,
+	}
+}
+
+// GetBookingPayment handles GET /api/v1/bookings/:id/payment
+// Returns the payment progress of a split-payment booking (organizer, driver or admin)
+// Only the organizer and admins get the payment link of each pending share
+// Other bookings return their provider payment (authorization, capture, refund)
+func (pc *PaymentController) GetBookingPayment(c *gin.Context) {
+	userID, err := domain.GetUserIDFromContext(c)
+	if err != nil {
+		c.Error(domain.ErrUnauthorized)
+		return
+	}
+
+	role, _ := domain.GetRoleFromContext(c)
+	var payment interface{}
+	payment, err = pc.paymentSplitService.GetBookingPayment(c.Request.Context(), c.Param("id"), userID, role == "admin")
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) && appErr.Code == domain.ErrBookingNotSplitPayment.Code {
+		payment, err = pc.paymentService.GetBookingPayment(c.Request.Context(), c.Param("id"), userID, role == "admin")
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    payment,
+	})
+}
+
+// GetShare handles GET /api/v1/payments/:token
+// Returns the share behind a payment link (public: the token is the credential)
+func (pc *PaymentController) GetShare(c *gin.Context) {
+	share, err := pc.paymentSplitService.GetShare(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    share,
+	})
+}
+
+// MarkSharePaid handles POST /api/v1/payments/:token/paid
+// Called by the payment provider webhook once the share is paid
+func (pc *PaymentController) MarkSharePaid(c *gin.Context) {
+	var req domain.MarkSharePaidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Invalid request body", err.Error()))
+		return
+	}
+
+	share, err := pc.paymentSplitService.MarkSharePaid(c.Request.Context(), c.Param("token"), req.PaymentReference)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    share,
+	})
+}
+
+// Webhook handles POST /api/v1/payments/webhook
+// Called by the payment provider when an authorization completes asynchronously (checkout),
+// is declined, or a payment is captured or refunded on its side
+func (pc *PaymentController) Webhook(c *gin.Context) {
+	var req domain.PaymentWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Invalid request body", err.Error()))
+		return
+	}
+
+	payment, err := pc.paymentService.HandleWebhook(c.Request.Context(), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    payment,
+	})
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"net/http"
+
+	"bookings-api/internal/publisher"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PublisherController exposes the event publisher counters (admin only)
+type PublisherController struct {
+	publisher *publisher.ReservationPublisher
+}
+
+// NewPublisherController creates a new instance of PublisherController
+func NewPublisherController(pub *publisher.ReservationPublisher) *PublisherController {
+	return &PublisherController{
+		publisher: pub,
+	}
+}
+
+// GetStats handles GET /api/v1/admin/publisher
+// Returns published/retried/failed counters and the last event that failed after all retries
+func (pc *PublisherController) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    pc.publisher.Stats(),
+	})
+}
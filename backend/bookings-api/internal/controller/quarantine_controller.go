@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"bookings-api/internal/domain"
+	"bookings-api/internal/repository"
+	"bookings-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuarantineController exposes the messages that failed schema validation (admin only)
+type QuarantineController struct {
+	quarantineService service.QuarantineService
+}
+
+// NewQuarantineController creates a new instance of QuarantineController
+func NewQuarantineController(quarantineService service.QuarantineService) *QuarantineController {
+	return &QuarantineController{
+		quarantineService: quarantineService,
+	}
+}
+
+// ReprocessQuarantinedRequest represents the optional body of a reprocess request
+type ReprocessQuarantinedRequest struct {
+	// Payload replaces the stored payload (e.g., a message with a missing field filled in by hand)
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// ListMessages handles GET /api/v1/admin/quarantine?status=quarantined&routing_key=&page=1&limit=20
+// Returns quarantined messages oldest first, with the count of messages per status
+func (qc *QuarantineController) ListMessages(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := qc.quarantineService.List(repository.QuarantineFilter{
+		Status:     c.Query("status"),
+		RoutingKey: c.Query("routing_key"),
+		Page:       page,
+		Limit:      limit,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetMessage handles GET /api/v1/admin/quarantine/:id
+// Returns the raw payload and the validation errors of a quarantined message
+func (qc *QuarantineController) GetMessage(c *gin.Context) {
+	id, ok := parseQuarantineID(c)
+	if !ok {
+		return
+	}
+
+	msg, err := qc.quarantineService.Get(id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    msg,
+	})
+}
+
+// ReprocessMessage handles POST /api/v1/admin/quarantine/:id/reprocess
+// Re-validates the message (with an optional corrected payload) and runs its handler
+// 422 with the validation errors if it still does not match its schema
+func (qc *QuarantineController) ReprocessMessage(c *gin.Context) {
+	id, ok := parseQuarantineID(c)
+	if !ok {
+		return
+	}
+
+	var req ReprocessQuarantinedRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(domain.NewAppError("VALIDATION_ERROR", "Invalid request body", err.Error()))
+			return
+		}
+	}
+
+	msg, err := qc.quarantineService.Reprocess(c.Request.Context(), id, req.Payload, c.GetInt64("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    msg,
+	})
+}
+
+// DiscardMessage handles POST /api/v1/admin/quarantine/:id/discard
+// Drops a quarantined message without processing it
+func (qc *QuarantineController) DiscardMessage(c *gin.Context) {
+	id, ok := parseQuarantineID(c)
+	if !ok {
+		return
+	}
+
+	msg, err := qc.quarantineService.Discard(id, c.GetInt64("user_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    msg,
+	})
+}
+
+// parseQuarantineID reads the :id path parameter (adds a validation error if it is not a positive integer)
+func parseQuarantineID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.Error(domain.NewAppError("VALIDATION_ERROR", "Quarantine ID must be a positive integer", nil))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"net/http"
+
+	"bookings-api/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TopologyController exposes the effective RabbitMQ topology (admin only)
+type TopologyController struct {
+	topology config.RabbitTopology
+}
+
+// NewTopologyController creates a new instance of TopologyController
+func NewTopologyController(topology config.RabbitTopology) *TopologyController {
+	return &TopologyController{
+		topology: topology,
+	}
+}
+
+// GetTopology handles GET /api/v1/admin/rabbitmq/topology
+// Returns the exchanges, queues, routing keys (by event type), prefetch and dead-letter settings
+// the service declared at startup, after environment overrides and validation
+func (tc *TopologyController) GetTopology(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tc.topology,
+	})
+}
@@ -0,0 +1,130 @@
+package dao
+
+import (
+	"time"
+)
+
+// AdminApproval is a destructive admin action on a booking waiting for a second admin
+//
+// Force-cancelling a confirmed booking or marking a booking as paid by hand cannot be
+// undone by the system, so a single admin cannot run them: the first admin creates the
+// approval (pending) and a DIFFERENT admin approves it before ExpiresAt. Only then the
+// action runs, with both admins recorded here, in admin_approval_events and in the
+// reason of the booking_status_history entry it writes.
+//
+// Indexes:
+//   - status + created_at (composite): "pending approvals, oldest first" listing
+//   - booking_uuid + status (composite): one pending approval per booking and action
+type AdminApproval struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// Action is the operation to run once approved (see AdminAction* constants)
+	Action string `gorm:"type:varchar(40);not null" json:"action"`
+
+	// BookingUUID is the booking the action applies to (external UUID, same as Booking.BookingUUID)
+	BookingUUID string `gorm:"type:varchar(36);not null;index:idx_admin_approval_booking_status,priority:1" json:"booking_id"`
+
+	// Reason is the justification given by the requesting admin (stored in the booking history)
+	Reason string `gorm:"type:text;not null" json:"reason"`
+
+	// PaymentReference is the external payment reference (mark_booking_paid only)
+	PaymentReference string `gorm:"type:varchar(100)" json:"payment_reference,omitempty"`
+
+	// Status: pending → approved → executed | failed, or pending → rejected | expired
+	Status string `gorm:"type:varchar(20);not null;default:'pending';index:idx_admin_approval_status_created_at,priority:1;index:idx_admin_approval_booking_status,priority:2" json:"status"`
+
+	// RequestedBy is the admin who created the approval; it can never approve it
+	RequestedBy int64 `gorm:"not null" json:"requested_by"`
+
+	// DecidedBy is the admin who approved or rejected it, DecisionNote the optional rejection note
+	DecidedBy    *int64     `json:"decided_by,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	DecisionNote string     `gorm:"type:text" json:"decision_note,omitempty"`
+
+	// ExpiresAt is the deadline for the second admin; a pending approval past it can only expire
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+
+	// ExecutedAt is set when the action ran, LastError when it failed
+	ExecutedAt *time.Time `json:"executed_at,omitempty"`
+	LastError  string     `gorm:"type:text" json:"last_error,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_admin_approval_status_created_at,priority:2" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the custom table name for the AdminApproval model
+func (AdminApproval) TableName() string {
+	return "admin_approvals"
+}
+
+// Admin actions that require a second approver
+const (
+	// AdminActionForceCancelBooking - Cancel a confirmed booking without fee, regardless of who booked it
+	AdminActionForceCancelBooking = "force_cancel_booking"
+
+	// AdminActionMarkBookingPaid - Mark the pending shares of a booking awaiting payment as paid and confirm it
+	AdminActionMarkBookingPaid = "mark_booking_paid"
+)
+
+// Admin approval status constants for the Status field
+const (
+	// AdminApprovalStatusPending - Waiting for a second admin
+	AdminApprovalStatusPending = "pending"
+
+	// AdminApprovalStatusApproved - Approved, the action is running
+	AdminApprovalStatusApproved = "approved"
+
+	// AdminApprovalStatusExecuted - Approved and the action ran successfully
+	AdminApprovalStatusExecuted = "executed"
+
+	// AdminApprovalStatusFailed - Approved but the action failed (e.g., the booking changed status meanwhile)
+	AdminApprovalStatusFailed = "failed"
+
+	// AdminApprovalStatusRejected - Rejected by an admin (or withdrawn by the requester)
+	AdminApprovalStatusRejected = "rejected"
+
+	// AdminApprovalStatusExpired - Nobody approved it before ExpiresAt
+	AdminApprovalStatusExpired = "expired"
+)
+
+// AdminApprovalEvent records one step of an admin approval (append-only audit trail)
+//
+// The repository writes it in the same transaction as the status change of the approval,
+// so every approval has the full list of who did what and when.
+//
+// Indexes:
+//   - approval_id: events of an approval
+type AdminApprovalEvent struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"-"`
+
+	// ApprovalID references the AdminApproval
+	ApprovalID uint `gorm:"not null;index" json:"approval_id"`
+
+	// Event is the step (see AdminApprovalEvent* constants)
+	Event string `gorm:"type:varchar(20);not null" json:"event"`
+
+	// AdminID is the admin who triggered the step (0 for steps taken by the service, like expiration)
+	AdminID int64 `gorm:"not null;default:0" json:"admin_id"`
+
+	// Note is a free-form detail (rejection note, execution error)
+	Note string `gorm:"type:text" json:"note,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the custom table name for the AdminApprovalEvent model
+func (AdminApprovalEvent) TableName() string {
+	return "admin_approval_events"
+}
+
+// Admin approval audit events
+const (
+	AdminApprovalEventRequested = "requested"
+	AdminApprovalEventApproved  = "approved"
+	AdminApprovalEventExecuted  = "executed"
+	AdminApprovalEventFailed    = "failed"
+	AdminApprovalEventRejected  = "rejected"
+	AdminApprovalEventExpired   = "expired"
+)
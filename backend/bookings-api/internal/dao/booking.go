@@ -24,6 +24,17 @@ const (
 
 	// BookingStatusFailed - Reservation failed (e.g., no seats available)
 	BookingStatusFailed = "failed"
+
+	// BookingStatusExpired - trips-api never answered while pending (lost event), seats released
+	BookingStatusExpired = "expired"
+
+	// BookingStatusAwaitingPayment - Seats reserved by trips-api, waiting for the payment shares of a split-payment booking
+	// or for the payment provider to authorize the fare
+	BookingStatusAwaitingPayment = "awaiting_payment"
+
+	// BookingStatusAwaitingGuardian - Booking of a dependent (minor) account waiting for the guardian's approval
+	// in users-api; reservation.created is only published once the guardian approves
+	BookingStatusAwaitingGuardian = "awaiting_guardian"
 )
 
 // Booking represents a passenger's reservation for a trip in the database
@@ -82,6 +93,10 @@ type Booking struct {
 	// Example: 2 seats * $5000.00 = $10000.00
 	TotalPrice float64 `gorm:"type:decimal(10,2);not null" json:"total_price"`
 
+	// CreditsApplied is the part of TotalPrice paid with the passenger's promotional credits (users-api)
+	// Consumed when trips-api confirms the seats; the payment provider charges only the rest
+	CreditsApplied float64 `gorm:"type:decimal(10,2);not null;default:0" json:"credits_applied"`
+
 	// Status is the current state of the booking
 	// Indexed for efficient filtering (e.g., "show only confirmed bookings")
 	// Possible values: pending, confirmed, cancelled, completed, failed
@@ -96,6 +111,51 @@ type Booking struct {
 	// Examples: "Change of plans", "Trip cancelled by driver", etc.
 	CancellationReason string `gorm:"type:text" json:"cancellation_reason,omitempty"`
 
+	// CancellationFee is the fee charged for a late passenger cancellation
+	// Calculated from the country policy in effect for the booking (0 when free)
+	CancellationFee float64 `gorm:"type:decimal(10,2);not null;default:0" json:"cancellation_fee"`
+
+	// Country is the ISO code of the market whose policy applies to this booking
+	// Resolved from the trip's country when the booking is created
+	Country string `gorm:"type:varchar(2);not null;default:''" json:"country"`
+
+	// OriginCity and DestinationCity are the trip's route captured at booking creation
+	// Kept so the anonymized analytics record survives the trip (empty if the snapshot was unavailable)
+	OriginCity      string `gorm:"type:varchar(100);not null;default:''" json:"origin_city,omitempty"`
+	DestinationCity string `gorm:"type:varchar(100);not null;default:''" json:"destination_city,omitempty"`
+
+	// DepartureAt is the trip's departure time captured at booking creation
+	// Used to evaluate the cancellation window without calling trips-api (nullable)
+	DepartureAt *time.Time `json:"departure_at,omitempty"`
+
+	// PickupPointID is the trip pickup point chosen by the passenger (empty if none)
+	// Validated against the trip by trips-api when processing reservation.created
+	PickupPointID string `gorm:"type:varchar(24);not null;default:''" json:"pickup_point_id,omitempty"`
+
+	// SeatHoldID is the trips-api seat hold taken before the booking was created (empty if none)
+	// The hold is confirmed by reservation.created and released if the booking fails or expires
+	SeatHoldID string `gorm:"type:varchar(64);not null;default:''" json:"seat_hold_id,omitempty"`
+
+	// DistanceKm is the estimated road distance of the trip route captured at booking creation
+	// 0 when the trip snapshot had no coordinates
+	DistanceKm float64 `gorm:"type:decimal(10,2);not null;default:0" json:"distance_km"`
+
+	// CO2SavedKg is the estimated CO2 saved by sharing the booked seats (see domain.EstimateCO2SavedKg)
+	CO2SavedKg float64 `gorm:"column:co2_saved_kg;type:decimal(10,2);not null;default:0" json:"co2_saved_kg"`
+
+	// SplitPayment marks a group booking whose fare is paid in shares, one per passenger (see PaymentShare)
+	SplitPayment bool `gorm:"not null;default:false" json:"split_payment"`
+
+	// GuardianApprovalID is the users-api approval requested for a dependent's booking (nil if none was needed)
+	GuardianApprovalID *int64 `gorm:"index" json:"guardian_approval_id,omitempty"`
+
+	// GuardianApprovedAt is when the guardian approved the booking; the pending timeout counts from it
+	GuardianApprovedAt *time.Time `json:"guardian_approved_at,omitempty"`
+
+	// PaymentDueAt is the deadline of the payment shares, set when the booking starts awaiting payment
+	// Shares still pending at the deadline are covered by the organizer (nullable)
+	PaymentDueAt *time.Time `gorm:"index" json:"payment_due_at,omitempty"`
+
 	// CreatedAt is automatically managed by GORM (timestamp when row inserted)
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 
@@ -174,8 +234,23 @@ func (b *Booking) IsFailed() bool {
 	return b.Status == BookingStatusFailed
 }
 
+// IsExpired checks if the booking expired while waiting for trips-api
+func (b *Booking) IsExpired() bool {
+	return b.Status == BookingStatusExpired
+}
+
+// IsAwaitingPayment checks if the seats are reserved but the payment (shares or provider authorization) is not settled yet
+func (b *Booking) IsAwaitingPayment() bool {
+	return b.Status == BookingStatusAwaitingPayment
+}
+
+// IsAwaitingGuardian checks if the booking of a dependent is waiting for the guardian's approval
+func (b *Booking) IsAwaitingGuardian() bool {
+	return b.Status == BookingStatusAwaitingGuardian
+}
+
 // CanBeCancelled checks if booking can be cancelled by user
-// Rules: Can only cancel if status is 'pending' or 'confirmed'
+// Rules: Can only cancel if status is 'awaiting_guardian', 'pending', 'awaiting_payment' or 'confirmed'
 func (b *Booking) CanBeCancelled() bool {
-	return b.IsPending() || b.IsConfirmed()
+	return b.IsAwaitingGuardian() || b.IsPending() || b.IsAwaitingPayment() || b.IsConfirmed()
 }
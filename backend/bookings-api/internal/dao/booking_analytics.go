@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"time"
+)
+
+// BookingAnalytics is the anonymized analytical record of an archived booking
+//
+// When the retention job deletes a booking (and its status history) it writes one row
+// to this table first, so long-term business reporting survives PII deletion.
+// The record holds no user or booking identifiers: no passenger_id, driver_id,
+// booking_uuid or trip_id (a trip identifies its driver), and no exact price
+// (only a bucket). Timestamps are truncated to the hour.
+//
+// Indexes:
+//   - country + booked_at (composite): Reports per market and period
+//   - origin_city + destination_city (composite): Route reports
+type BookingAnalytics struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"-"`
+
+	// Route and market of the trip
+	Country         string  `gorm:"type:varchar(2);not null;default:'';index:idx_booking_analytics_country_booked_at,priority:1" json:"country"`
+	OriginCity      string  `gorm:"type:varchar(100);not null;default:'';index:idx_booking_analytics_route,priority:1" json:"origin_city"`
+	DestinationCity string  `gorm:"type:varchar(100);not null;default:'';index:idx_booking_analytics_route,priority:2" json:"destination_city"`
+	DistanceKm      float64 `gorm:"type:decimal(10,2);not null;default:0" json:"distance_km"`
+
+	// Seats and price bucket (e.g. "2500-5000") of the booking
+	Seats       int    `gorm:"not null" json:"seats"`
+	PriceBucket string `gorm:"type:varchar(32);not null" json:"price_bucket"`
+
+	// FinalStatus is the terminal status of the booking (completed, cancelled or failed)
+	FinalStatus string `gorm:"type:varchar(20);not null;index" json:"final_status"`
+
+	// CO2SavedKg is the estimated CO2 saved by the booking
+	CO2SavedKg float64 `gorm:"column:co2_saved_kg;type:decimal(10,2);not null;default:0" json:"co2_saved_kg"`
+
+	// Timestamps truncated to the hour
+	BookedAt    time.Time  `gorm:"not null;index:idx_booking_analytics_country_booked_at,priority:2" json:"booked_at"`
+	DepartureAt *time.Time `json:"departure_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+
+	// ArchivedAt is when the booking was archived (used to purge old records)
+	ArchivedAt time.Time `gorm:"not null;index" json:"archived_at"`
+}
+
+// TableName specifies the custom table name for the BookingAnalytics model
+func (BookingAnalytics) TableName() string {
+	return "booking_analytics"
+}
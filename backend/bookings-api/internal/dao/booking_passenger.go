@@ -0,0 +1,38 @@
+package dao
+
+import (
+	"time"
+)
+
+// BookingPassenger stores who travels in one seat of a booking
+//
+// A booking for several seats may list one passenger per seat, so the driver knows
+// who is coming. Rows are written together with the booking and never updated;
+// they hold personal data, so the retention job deletes them with their booking.
+//
+// Indexes:
+//   - booking_uuid + seat_number (unique): One passenger per seat
+type BookingPassenger struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"-"`
+
+	// BookingUUID references the booking (external UUID, same as Booking.BookingUUID)
+	BookingUUID string `gorm:"type:varchar(36);not null;uniqueIndex:idx_booking_passengers_uuid_seat,priority:1" json:"-"`
+
+	// SeatNumber is the position of the passenger in the booking (1..seats_requested)
+	SeatNumber int `gorm:"not null;uniqueIndex:idx_booking_passengers_uuid_seat,priority:2" json:"seat_number"`
+
+	// Name is the full name of the passenger
+	Name string `gorm:"type:varchar(100);not null" json:"name"`
+
+	// Document is the identity document number of the passenger
+	Document string `gorm:"type:varchar(32);not null" json:"document"`
+
+	// CreatedAt is automatically managed by GORM (timestamp when row inserted)
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"-"`
+}
+
+// TableName specifies the custom table name for the BookingPassenger model
+func (BookingPassenger) TableName() string {
+	return "booking_passengers"
+}
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"time"
+)
+
+// BookingStatusHistory records a single status transition of a booking
+//
+// Every time a booking changes status (pending → confirmed, confirmed → cancelled, etc.)
+// the repository appends one row to this table inside the same transaction as the
+// booking update. The table is append-only: rows are never updated, and only deleted
+// together with their booking by the retention job (see BookingAnalytics).
+// Seat changes on a booking are recorded too, as entries with FromStatus == ToStatus.
+//
+// Why a history table?
+// The bookings table only stores the CURRENT state. When a customer disputes
+// "I cancelled before the driver cancelled the trip", the current row cannot answer
+// the question. With the full list of transitions we can reconstruct the booking
+// exactly as it looked at any moment in the past.
+//
+// Each row stores a snapshot of the mutable booking fields AFTER the transition
+// (price, driver, cancellation info), so reconstruction never needs to guess values.
+//
+// Indexes:
+//   - booking_uuid + changed_at (composite): Fast "latest transition before T" lookups
+type BookingStatusHistory struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"-"`
+
+	// BookingUUID references the booking (external UUID, same as Booking.BookingUUID)
+	BookingUUID string `gorm:"type:varchar(36);not null;index:idx_booking_history_uuid_changed_at,priority:1" json:"booking_id"`
+
+	// FromStatus is the status before the transition (empty for the initial creation)
+	FromStatus string `gorm:"type:varchar(20)" json:"from_status,omitempty"`
+
+	// ToStatus is the status after the transition
+	ToStatus string `gorm:"type:varchar(20);not null" json:"to_status"`
+
+	// Reason is an optional explanation (e.g., the cancellation reason)
+	Reason string `gorm:"type:text" json:"reason,omitempty"`
+
+	// Snapshot of the mutable booking fields after the transition
+	SeatsRequested     int        `gorm:"not null" json:"seats_requested"`
+	TotalPrice         float64    `gorm:"type:decimal(10,2);not null" json:"total_price"`
+	CreditsApplied     float64    `gorm:"type:decimal(10,2);not null;default:0" json:"credits_applied"`
+	DriverID           int64      `gorm:"default:0" json:"driver_id"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
+	CancellationReason string     `gorm:"type:text" json:"cancellation_reason,omitempty"`
+	CancellationFee    float64    `gorm:"type:decimal(10,2);not null;default:0" json:"cancellation_fee"`
+	CO2SavedKg         float64    `gorm:"column:co2_saved_kg;type:decimal(10,2);not null;default:0" json:"co2_saved_kg"`
+	PaymentDueAt       *time.Time `json:"payment_due_at,omitempty"`
+
+	// ChangedAt is the moment the transition was committed
+	ChangedAt time.Time `gorm:"not null;index:idx_booking_history_uuid_changed_at,priority:2" json:"changed_at"`
+}
+
+// TableName specifies the custom table name for the BookingStatusHistory model
+func (BookingStatusHistory) TableName() string {
+	return "booking_status_history"
+}
+
+// NewBookingStatusHistory builds a history entry from the booking's state after a transition
+//
+// Parameters:
+//   - booking: The booking AFTER the status change has been applied
+//   - fromStatus: The status before the change ("" when the booking is being created)
+//   - reason: Optional explanation for the transition
+func NewBookingStatusHistory(booking *Booking, fromStatus, reason string) *BookingStatusHistory {
+	return &BookingStatusHistory{
+		BookingUUID:        booking.BookingUUID,
+		FromStatus:         fromStatus,
+		ToStatus:           booking.Status,
+		Reason:             reason,
+		SeatsRequested:     booking.SeatsRequested,
+		TotalPrice:         booking.TotalPrice,
+		CreditsApplied:     booking.CreditsApplied,
+		DriverID:           booking.DriverID,
+		CancelledAt:        booking.CancelledAt,
+		CancellationReason: booking.CancellationReason,
+		CancellationFee:    booking.CancellationFee,
+		CO2SavedKg:         booking.CO2SavedKg,
+		PaymentDueAt:       booking.PaymentDueAt,
+		ChangedAt:          time.Now(),
+	}
+}
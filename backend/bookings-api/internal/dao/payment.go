@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"time"
+)
+
+// Payment status constants
+const (
+	// PaymentStatusPending - Created with the booking; not authorized yet (or the provider is still processing it)
+	PaymentStatusPending = "pending"
+
+	// PaymentStatusAuthorized - The provider reserved the amount on the passenger's payment method
+	PaymentStatusAuthorized = "authorized"
+
+	// PaymentStatusCaptured - The authorized amount was charged
+	PaymentStatusCaptured = "captured"
+
+	// PaymentStatusDeclined - The provider rejected the authorization (the booking is cancelled)
+	PaymentStatusDeclined = "declined"
+
+	// PaymentStatusRefunded - Fully or partially refunded after a cancellation (see AmountRefunded)
+	PaymentStatusRefunded = "refunded"
+
+	// PaymentStatusCancelled - The booking ended before anything was charged
+	PaymentStatusCancelled = "cancelled"
+)
+
+// Payment is the charge of a booking through the payment provider (PAYMENT_PROVIDER)
+//
+// The row is created with the booking (pending, amount 0). When trips-api confirms the seats the
+// amount is set to the confirmed fare and the provider authorizes it; the booking is only confirmed
+// once the authorization succeeds (synchronously or through the provider webhook), and the amount is
+// captured right after. Split-payment bookings have no Payment: they are paid through PaymentShare links.
+//
+// Indexes:
+//   - booking_uuid (unique): One payment per booking
+//   - provider_payment_id: Lookup from the provider webhook
+//   - (status, updated_at): Retry of authorizations and captures that did not reach the provider
+type Payment struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"-"`
+
+	// BookingUUID references the booking (external UUID, same as Booking.BookingUUID)
+	BookingUUID string `gorm:"type:varchar(36);uniqueIndex;not null" json:"booking_id"`
+
+	// Provider is the payment provider that processes the payment (mock, http)
+	Provider string `gorm:"type:varchar(20);not null" json:"provider"`
+
+	// ProviderPaymentID is the provider's identifier of the payment (empty until the provider answers)
+	ProviderPaymentID string `gorm:"type:varchar(100);index;not null;default:''" json:"provider_payment_id,omitempty"`
+
+	// PaymentMethodToken is the tokenized payment method sent by the client (never the card itself)
+	// Empty when the passenger completes the payment on the provider's checkout page (CheckoutURL)
+	PaymentMethodToken string `gorm:"type:varchar(255);not null;default:''" json:"-"`
+
+	// Amount is the fare authorized and captured (0 until trips-api confirms the seats)
+	Amount float64 `gorm:"type:decimal(10,2);not null;default:0" json:"amount"`
+
+	// AmountRefunded is the part of the captured amount returned after a cancellation
+	AmountRefunded float64 `gorm:"type:decimal(10,2);not null;default:0" json:"amount_refunded"`
+
+	// Currency is the ISO 4217 code of the booking's country policy
+	Currency string `gorm:"type:varchar(3);not null;default:''" json:"currency"`
+
+	// Status is pending, authorized, captured, declined, refunded or cancelled (see PaymentStatus constants)
+	Status string `gorm:"type:varchar(20);not null;default:pending;index:idx_payments_status_updated,priority:1" json:"status"`
+
+	// CheckoutURL is the provider page where the passenger completes an asynchronous authorization
+	CheckoutURL string `gorm:"type:varchar(500);not null;default:''" json:"checkout_url,omitempty"`
+
+	// FailureReason is the provider's decline reason or the last error talking to the provider
+	FailureReason string `gorm:"type:varchar(255);not null;default:''" json:"failure_reason,omitempty"`
+
+	// Attempts counts the authorization requests sent to the provider
+	Attempts int `gorm:"not null;default:0" json:"attempts"`
+
+	// AuthorizedAt, CapturedAt and RefundedAt are set on each step (nullable)
+	AuthorizedAt *time.Time `json:"authorized_at,omitempty"`
+	CapturedAt   *time.Time `json:"captured_at,omitempty"`
+	RefundedAt   *time.Time `json:"refunded_at,omitempty"`
+
+	// CreatedAt is automatically managed by GORM (timestamp when row inserted)
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// UpdatedAt is automatically managed by GORM (timestamp when row updated)
+	UpdatedAt time.Time `gorm:"autoUpdateTime;index:idx_payments_status_updated,priority:2" json:"updated_at"`
+}
+
+// TableName specifies the custom table name for the Payment model
+func (Payment) TableName() string {
+	return "payments"
+}
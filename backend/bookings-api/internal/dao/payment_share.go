@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"time"
+)
+
+// Payment share status constants
+const (
+	// PaymentShareStatusPending - Waiting for the passenger to pay through the payment link
+	PaymentShareStatusPending = "pending"
+
+	// PaymentShareStatusPaid - Paid, confirmed by the payment provider webhook
+	PaymentShareStatusPaid = "paid"
+
+	// PaymentShareStatusCovered - Not paid before the deadline, the organizer pays it instead
+	PaymentShareStatusCovered = "covered"
+)
+
+// PaymentShare is the part of a split-payment booking's fare owed by one seat
+//
+// Shares are created when trips-api confirms the seats of a booking created with
+// split_payment (one per passenger, the fare divided in equal parts). Each share has
+// its own payment link, identified by Token. The booking is confirmed once no share
+// is pending: all paid, or the unpaid ones covered by the organizer after the deadline.
+//
+// Indexes:
+//   - token (unique): Lookup from the payment link and the provider webhook
+//   - booking_uuid + seat_number (unique): One share per seat
+type PaymentShare struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"-"`
+
+	// BookingUUID references the booking (external UUID, same as Booking.BookingUUID)
+	BookingUUID string `gorm:"type:varchar(36);not null;uniqueIndex:idx_payment_shares_uuid_seat,priority:1" json:"booking_id"`
+
+	// SeatNumber is the seat the share pays for (matches BookingPassenger.SeatNumber)
+	// Seat 1 is the organizer's own share
+	SeatNumber int `gorm:"not null;uniqueIndex:idx_payment_shares_uuid_seat,priority:2" json:"seat_number"`
+
+	// PassengerName is copied from the booking passenger so the payment page needs no join
+	PassengerName string `gorm:"type:varchar(100);not null" json:"passenger_name"`
+
+	// Token is the random identifier of the payment link (64 hex characters)
+	Token string `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+
+	// Amount is the part of the total price owed by this seat
+	Amount float64 `gorm:"type:decimal(10,2);not null" json:"amount"`
+
+	// Status is pending, paid or covered (see PaymentShareStatus constants)
+	Status string `gorm:"type:varchar(20);not null;default:pending" json:"status"`
+
+	// PaymentReference is the payment provider's identifier of the payment (empty until paid)
+	PaymentReference string `gorm:"type:varchar(100);not null;default:''" json:"payment_reference,omitempty"`
+
+	// PaidAt is when the provider confirmed the payment (nullable)
+	PaidAt *time.Time `json:"paid_at,omitempty"`
+
+	// CreatedAt is automatically managed by GORM (timestamp when row inserted)
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// UpdatedAt is automatically managed by GORM (timestamp when row updated)
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the custom table name for the PaymentShare model
+func (PaymentShare) TableName() string {
+	return "payment_shares"
+}
@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// QuarantinedMessage is a consumed event that did not match its JSON Schema
+//
+// Instead of failing deep inside the handler (unmarshal errors, zero-valued fields) the
+// consumer validates every payload first. Invalid messages are ACKed and stored here with
+// the validation errors, so the queue keeps flowing and nothing is lost: once the producer
+// or the schema is fixed, an admin reprocesses them (optionally with a corrected payload).
+//
+// Indexes:
+//   - status + created_at (composite): "oldest quarantined messages first" listing
+//   - routing_key: filter by event type
+type QuarantinedMessage struct {
+	// ID is the internal database primary key (auto-increment)
+	ID uint `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	// RoutingKey is the RabbitMQ routing key the message was delivered with (the event type)
+	RoutingKey string `gorm:"type:varchar(64);not null;index" json:"routing_key"`
+
+	// EventID is the event_id of the payload when it could be read (empty for unparseable payloads)
+	EventID string `gorm:"type:varchar(64);index" json:"event_id,omitempty"`
+
+	// CorrelationID is the AMQP correlation id of the delivery, for tracing
+	CorrelationID string `gorm:"type:varchar(64)" json:"correlation_id,omitempty"`
+
+	// SchemaVersion is the schema version the payload was validated against (0 if it could not be determined)
+	SchemaVersion int `gorm:"not null;default:0" json:"schema_version"`
+
+	// Payload is the raw message body (replaced when an admin reprocesses with a corrected payload)
+	Payload string `gorm:"type:mediumtext;not null" json:"payload"`
+
+	// ValidationErrors is a JSON array with one message per violation ("/trip_id: is required")
+	ValidationErrors json.RawMessage `gorm:"type:text;not null" json:"validation_errors"`
+
+	// Status: quarantined → reprocessed | discarded
+	Status string `gorm:"type:varchar(20);not null;default:'quarantined';index:idx_quarantine_status_created_at,priority:1" json:"status"`
+
+	// ReprocessAttempts counts the reprocess requests, LastError is the error of the last failed one
+	ReprocessAttempts int    `gorm:"not null;default:0" json:"reprocess_attempts"`
+	LastError         string `gorm:"type:text" json:"last_error,omitempty"`
+
+	// ResolvedBy is the admin who reprocessed or discarded the message
+	ResolvedBy *int64     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_quarantine_status_created_at,priority:2" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the custom table name for the QuarantinedMessage model
+func (QuarantinedMessage) TableName() string {
+	return "quarantined_messages"
+}
+
+// Quarantine status constants for the Status field
+const (
+	// QuarantineStatusQuarantined - Waiting for a fix (reprocess or discard)
+	QuarantineStatusQuarantined = "quarantined"
+
+	// QuarantineStatusReprocessed - Passed validation and was handled after a reprocess request
+	QuarantineStatusReprocessed = "reprocessed"
+
+	// QuarantineStatusDiscarded - Dropped by an admin (e.g., a test message)
+	QuarantineStatusDiscarded = "discarded"
+)
@@ -174,6 +174,20 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 //     - Indexes: booking_uuid (unique), trip_id, passenger_id, status, cancelled_at
 //  2. processed_events - Event idempotency tracking
 //     - Indexes: event_id (unique), event_type, processed_at
+//  3. booking_status_history - Append-only log of booking status transitions
+//     - Indexes: (booking_uuid, changed_at)
+//  4. booking_analytics - Anonymized records of archived bookings
+//     - Indexes: (country, booked_at), (origin_city, destination_city), final_status, archived_at
+//  5. booking_passengers - Name and document of each passenger of a booking
+//     - Indexes: (booking_uuid, seat_number) (unique)
+//  6. payment_shares - Per-passenger shares of the fare of split-payment bookings
+//     - Indexes: token (unique), (booking_uuid, seat_number) (unique)
+//  7. admin_approvals - Destructive admin actions waiting for (or decided by) a second admin
+//     - Indexes: (status, created_at), (booking_uuid, status)
+//  8. admin_approval_events - Append-only audit trail of each admin approval
+//     - Indexes: approval_id
+//  9. payments - Payment of each booking processed by the payment provider
+//     - Indexes: booking_uuid (unique), provider_payment_id, (status, updated_at)
 //
 // Migration Safety:
 //   - AutoMigrate is safe for existing databases
@@ -199,8 +213,16 @@ func AutoMigrate(db *gorm.DB) error {
 	// Order doesn't matter since we have no foreign key constraints
 	// between these tables (they're referenced by external IDs)
 	err := db.AutoMigrate(
-		&dao.Booking{},        // bookings table
-		&dao.ProcessedEvent{}, // processed_events table
+		&dao.Booking{},              // bookings table
+		&dao.ProcessedEvent{},       // processed_events table
+		&dao.BookingStatusHistory{}, // booking_status_history table
+		&dao.BookingAnalytics{},     // booking_analytics table
+		&dao.BookingPassenger{},     // booking_passengers table
+		&dao.QuarantinedMessage{},   // quarantined_messages table
+		&dao.PaymentShare{},         // payment_shares table
+		&dao.AdminApproval{},        // admin_approvals table
+		&dao.AdminApprovalEvent{},   // admin_approval_events table
+		&dao.Payment{},              // payments table
 	)
 
 	if err != nil {
@@ -208,13 +230,14 @@ func AutoMigrate(db *gorm.DB) error {
 	}
 
 	log.Info().
-		Strs("tables", []string{"bookings", "processed_events"}).
+		Strs("tables", []string{"bookings", "processed_events", "booking_status_history", "booking_analytics", "booking_passengers"}).
 		Msg("✅ Database tables migrated successfully")
 
 	// Log created indexes for verification
 	log.Info().
 		Strs("booking_indexes", []string{"booking_uuid", "trip_id", "passenger_id", "status", "cancelled_at"}).
 		Strs("event_indexes", []string{"event_id (UNIQUE)", "event_type", "processed_at"}).
+		Strs("history_indexes", []string{"booking_uuid + changed_at"}).
 		Msg("📊 Database indexes created")
 
 	return nil
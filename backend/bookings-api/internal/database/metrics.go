@@ -0,0 +1,281 @@
+package database
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// MySQL error numbers counted separately (lock contention)
+const (
+	mysqlErrLockWaitTimeout = 1205 // ER_LOCK_WAIT_TIMEOUT
+	mysqlErrDeadlock        = 1213 // ER_LOCK_DEADLOCK
+)
+
+// queryMetricsStartKey is the statement instance key holding the query start time
+const queryMetricsStartKey = "query_metrics:started_at"
+
+// maxLoggedSQLLength truncates very long statements in slow query logs
+const maxLoggedSQLLength = 1000
+
+// queryDurationBucketsMs are the upper bounds (inclusive) of the duration histogram buckets
+// Queries slower than the last bucket are only counted in the total
+var queryDurationBucketsMs = []int64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// Patterns used to strip literal values from SQL before logging it
+var (
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	sqlNumberLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlPlaceholders  = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+)
+
+// QueryHistogram is a snapshot of the durations of one operation on one table
+type QueryHistogram struct {
+	Operation string           `json:"operation"`
+	Table     string           `json:"table"`
+	Count     int64            `json:"count"`
+	Errors    int64            `json:"errors"`
+	TotalMs   float64          `json:"total_ms"`
+	MaxMs     float64          `json:"max_ms"`
+	Buckets   []BucketSnapshot `json:"buckets"`
+}
+
+// BucketSnapshot is a cumulative histogram bucket (queries that took <= LeMs)
+type BucketSnapshot struct {
+	LeMs  int64 `json:"le_ms"`
+	Count int64 `json:"count"`
+}
+
+// QueryMetricsStats is a snapshot of the query metrics plugin counters
+type QueryMetricsStats struct {
+	SlowQueryThresholdMs int64            `json:"slow_query_threshold_ms"`
+	SlowQueries          int64            `json:"slow_queries"`
+	Deadlocks            int64            `json:"deadlocks"`
+	LockWaitTimeouts     int64            `json:"lock_wait_timeouts"`
+	Queries              []QueryHistogram `json:"queries"`
+}
+
+// queryKey identifies a histogram (operation + table)
+type queryKey struct {
+	operation string
+	table     string
+}
+
+// queryHistogram accumulates the durations of one operation on one table
+type queryHistogram struct {
+	count   int64
+	errors  int64
+	total   time.Duration
+	max     time.Duration
+	buckets []int64 // Non-cumulative counts, same length as queryDurationBucketsMs
+}
+
+// QueryMetrics is a GORM plugin that measures every statement executed through GORM
+//
+// For each query it:
+//   - Records its duration in a histogram labeled by operation (create, query, update,
+//     delete, row, raw) and table
+//   - Logs it in WARN with its SQL stripped of literal values when it exceeds the slow
+//     query threshold (bound values are never logged)
+//   - Counts MySQL deadlocks (1213) and lock wait timeouts (1205)
+//
+// Register it with db.Use(database.NewQueryMetrics(threshold)).
+type QueryMetrics struct {
+	slowThreshold time.Duration
+
+	mu         sync.Mutex
+	histograms map[queryKey]*queryHistogram
+
+	slowQueries      atomic.Int64
+	deadlocks        atomic.Int64
+	lockWaitTimeouts atomic.Int64
+}
+
+// NewQueryMetrics creates the plugin; slowThreshold <= 0 disables slow query logging
+func NewQueryMetrics(slowThreshold time.Duration) *QueryMetrics {
+	return &QueryMetrics{
+		slowThreshold: slowThreshold,
+		histograms:    make(map[queryKey]*queryHistogram),
+	}
+}
+
+// Name implements gorm.Plugin
+func (m *QueryMetrics) Name() string {
+	return "query_metrics"
+}
+
+// Initialize implements gorm.Plugin registering before/after callbacks on every processor
+func (m *QueryMetrics) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("gorm:create").Register, callbacks.Create().After("gorm:create").Register},
+		{"query", callbacks.Query().Before("gorm:query").Register, callbacks.Query().After("gorm:query").Register},
+		{"update", callbacks.Update().Before("gorm:update").Register, callbacks.Update().After("gorm:update").Register},
+		{"delete", callbacks.Delete().Before("gorm:delete").Register, callbacks.Delete().After("gorm:delete").Register},
+		{"row", callbacks.Row().Before("gorm:row").Register, callbacks.Row().After("gorm:row").Register},
+		{"raw", callbacks.Raw().Before("gorm:raw").Register, callbacks.Raw().After("gorm:raw").Register},
+	}
+
+	for _, p := range processors {
+		operation := p.operation
+		if err := p.before("query_metrics:before_"+operation, m.before); err != nil {
+			return err
+		}
+		if err := p.after("query_metrics:after_"+operation, func(tx *gorm.DB) { m.after(tx, operation) }); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stats returns a snapshot of the histograms and counters, sorted by operation and table
+func (m *QueryMetrics) Stats() QueryMetricsStats {
+	stats := QueryMetricsStats{
+		SlowQueryThresholdMs: m.slowThreshold.Milliseconds(),
+		SlowQueries:          m.slowQueries.Load(),
+		Deadlocks:            m.deadlocks.Load(),
+		LockWaitTimeouts:     m.lockWaitTimeouts.Load(),
+	}
+
+	m.mu.Lock()
+	for key, h := range m.histograms {
+		buckets := make([]BucketSnapshot, len(queryDurationBucketsMs))
+		var cumulative int64
+		for i, le := range queryDurationBucketsMs {
+			cumulative += h.buckets[i]
+			buckets[i] = BucketSnapshot{LeMs: le, Count: cumulative}
+		}
+		stats.Queries = append(stats.Queries, QueryHistogram{
+			Operation: key.operation,
+			Table:     key.table,
+			Count:     h.count,
+			Errors:    h.errors,
+			TotalMs:   durationMs(h.total),
+			MaxMs:     durationMs(h.max),
+			Buckets:   buckets,
+		})
+	}
+	m.mu.Unlock()
+
+	sort.Slice(stats.Queries, func(i, j int) bool {
+		if stats.Queries[i].Operation != stats.Queries[j].Operation {
+			return stats.Queries[i].Operation < stats.Queries[j].Operation
+		}
+		return stats.Queries[i].Table < stats.Queries[j].Table
+	})
+
+	return stats
+}
+
+// before stores the start time in the statement instance
+func (m *QueryMetrics) before(tx *gorm.DB) {
+	tx.InstanceSet(queryMetricsStartKey, time.Now())
+}
+
+// after records the duration, logs slow queries and counts lock errors
+func (m *QueryMetrics) after(tx *gorm.DB, operation string) {
+	value, ok := tx.InstanceGet(queryMetricsStartKey)
+	if !ok {
+		return
+	}
+	startedAt, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(startedAt)
+
+	table := tx.Statement.Table
+	if table == "" {
+		table = "unknown"
+	}
+
+	failed := tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound)
+	m.record(queryKey{operation: operation, table: table}, elapsed, failed)
+
+	if failed {
+		var mysqlErr *mysqldriver.MySQLError
+		if errors.As(tx.Error, &mysqlErr) {
+			switch mysqlErr.Number {
+			case mysqlErrDeadlock:
+				m.deadlocks.Add(1)
+			case mysqlErrLockWaitTimeout:
+				m.lockWaitTimeouts.Add(1)
+			}
+		}
+	}
+
+	if m.slowThreshold > 0 && elapsed >= m.slowThreshold {
+		m.slowQueries.Add(1)
+		log.Warn().
+			Str("operation", operation).
+			Str("table", table).
+			Dur("duration", elapsed).
+			Int64("threshold_ms", m.slowThreshold.Milliseconds()).
+			Int64("rows_affected", tx.RowsAffected).
+			Str("sql", SanitizeSQL(tx.Statement.SQL.String())).
+			Msg("🐢 Slow query")
+	}
+}
+
+// record adds a sample to the histogram of the operation/table
+func (m *QueryMetrics) record(key queryKey, elapsed time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.histograms[key]
+	if !ok {
+		h = &queryHistogram{buckets: make([]int64, len(queryDurationBucketsMs))}
+		m.histograms[key] = h
+	}
+
+	h.count++
+	h.total += elapsed
+	if elapsed > h.max {
+		h.max = elapsed
+	}
+	if failed {
+		h.errors++
+	}
+
+	elapsedMs := elapsed.Milliseconds()
+	for i, le := range queryDurationBucketsMs {
+		if elapsedMs <= le {
+			h.buckets[i]++
+			break
+		}
+	}
+}
+
+// SanitizeSQL replaces string and numeric literals with ? and collapses IN lists
+// so slow query logs never contain user data
+//
+// Example:
+//
+//	SELECT * FROM bookings WHERE passenger_id = 42 AND status IN ('confirmed','pending')
+//	-> SELECT * FROM bookings WHERE passenger_id = ? AND status IN (?)
+func SanitizeSQL(sql string) string {
+	sanitized := sqlStringLiteral.ReplaceAllString(sql, "?")
+	sanitized = sqlNumberLiteral.ReplaceAllString(sanitized, "?")
+	sanitized = sqlPlaceholders.ReplaceAllString(sanitized, "(?)")
+	if len(sanitized) > maxLoggedSQLLength {
+		sanitized = sanitized[:maxLoggedSQLLength] + "..."
+	}
+	return sanitized
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus descriptors of the query metrics (see QueryMetrics.Collect)
+var (
+	dbCallDurationDesc = prometheus.NewDesc(
+		"db_call_duration_seconds",
+		"Duration of the statements executed through GORM by operation and table",
+		[]string{"operation", "table"}, nil,
+	)
+	dbCallErrorsDesc = prometheus.NewDesc(
+		"db_call_errors_total",
+		"Statements that failed (record not found excluded) by operation and table",
+		[]string{"operation", "table"}, nil,
+	)
+	dbSlowQueriesDesc = prometheus.NewDesc(
+		"db_slow_queries_total",
+		"Statements slower than DB_SLOW_QUERY_THRESHOLD_MS",
+		nil, nil,
+	)
+	dbDeadlocksDesc = prometheus.NewDesc(
+		"db_deadlocks_total",
+		"MySQL deadlocks (1213)",
+		nil, nil,
+	)
+	dbLockWaitTimeoutsDesc = prometheus.NewDesc(
+		"db_lock_wait_timeouts_total",
+		"MySQL lock wait timeouts (1205)",
+		nil, nil,
+	)
+)
+
+// Describe implements prometheus.Collector
+func (m *QueryMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dbCallDurationDesc
+	ch <- dbCallErrorsDesc
+	ch <- dbSlowQueriesDesc
+	ch <- dbDeadlocksDesc
+	ch <- dbLockWaitTimeoutsDesc
+}
+
+// Collect implements prometheus.Collector exporting the same snapshot as Stats,
+// so /metrics and GET /api/v1/admin/db-metrics never disagree
+func (m *QueryMetrics) Collect(ch chan<- prometheus.Metric) {
+	stats := m.Stats()
+
+	for _, q := range stats.Queries {
+		buckets := make(map[float64]uint64, len(q.Buckets))
+		for _, b := range q.Buckets {
+			buckets[float64(b.LeMs)/1000] = uint64(b.Count)
+		}
+		ch <- prometheus.MustNewConstHistogram(dbCallDurationDesc, uint64(q.Count), q.TotalMs/1000, buckets, q.Operation, q.Table)
+		ch <- prometheus.MustNewConstMetric(dbCallErrorsDesc, prometheus.CounterValue, float64(q.Errors), q.Operation, q.Table)
+	}
+
+	ch <- prometheus.MustNewConstMetric(dbSlowQueriesDesc, prometheus.CounterValue, float64(stats.SlowQueries))
+	ch <- prometheus.MustNewConstMetric(dbDeadlocksDesc, prometheus.CounterValue, float64(stats.Deadlocks))
+	ch <- prometheus.MustNewConstMetric(dbLockWaitTimeoutsDesc, prometheus.CounterValue, float64(stats.LockWaitTimeouts))
+}
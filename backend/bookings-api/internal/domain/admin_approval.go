@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"bookings-api/internal/dao"
+	"fmt"
+)
+
+// Admin actions that require a second approver (mirror DAO constants for clarity)
+const (
+	AdminActionForceCancelBooking = dao.AdminActionForceCancelBooking
+	AdminActionMarkBookingPaid    = dao.AdminActionMarkBookingPaid
+)
+
+// CreateAdminApprovalRequest is the body of POST /api/v1/admin/approvals
+// PaymentReference is required for mark_booking_paid (the reference of the payment made outside the provider)
+type CreateAdminApprovalRequest struct {
+	Action           string `json:"action" binding:"required,oneof=force_cancel_booking mark_booking_paid"`
+	BookingID        string `json:"booking_id" binding:"required,uuid"`
+	Reason           string `json:"reason" binding:"required,max=500"`
+	PaymentReference string `json:"payment_reference" binding:"max=100"`
+}
+
+// RejectAdminApprovalRequest is the optional body of POST /api/v1/admin/approvals/:id/reject
+type RejectAdminApprovalRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+// AdminApprovalResponse is an approval with its audit trail
+type AdminApprovalResponse struct {
+	dao.AdminApproval
+	Events []dao.AdminApprovalEvent `json:"events"`
+}
+
+// AdminApprovalList is a page of approvals, newest first
+type AdminApprovalList struct {
+	Approvals []dao.AdminApproval `json:"approvals"`
+	Total     int64               `json:"total"`
+	Page      int                 `json:"page"`
+	Limit     int                 `json:"limit"`
+}
+
+// AdminOverrideReason builds the reason stored in the booking status history of an approved admin action,
+// so the history alone tells which approval ran it and who were the two admins
+func AdminOverrideReason(approval *dao.AdminApproval, approvedBy int64) string {
+	return fmt.Sprintf("Admin override #%d (%s) requested by admin %d, approved by admin %d: %s",
+		approval.ID, approval.Action, approval.RequestedBy, approvedBy, approval.Reason)
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"bookings-api/internal/dao"
+	"math"
 	"time"
 )
 
@@ -10,20 +11,54 @@ type CreateBookingRequest struct {
 	TripID        string `json:"trip_id" binding:"required"`
 	PassengerID   int64  `json:"passenger_id" binding:"required"`
 	SeatsReserved int    `json:"seats_reserved" binding:"required,min=1"`
+	PickupPointID string `json:"pickup_point_id"` // Optional pickup point chosen from the trip
+
+	// Passengers optionally lists who travels in each seat (must have exactly SeatsReserved entries)
+	Passengers []BookingPassenger `json:"passengers" binding:"omitempty,dive"`
+
+	// SplitPayment divides the fare in one payment share per passenger (requires Passengers, 2 seats or more)
+	SplitPayment bool `json:"split_payment"`
+
+	// PaymentMethodToken is the payment method tokenized by the payment provider's client SDK (optional)
+	// Without it the passenger completes the payment on the provider's checkout page
+	PaymentMethodToken string `json:"payment_method_token" binding:"omitempty,max=255"`
+
+	// RequiresGuardianApproval is set from the JWT restrictions of dependent accounts (never from the body)
+	// The booking waits in awaiting_guardian, without holding seats, until the guardian answers
+	RequiresGuardianApproval bool `json:"-"`
+}
+
+// BookingPassenger is the passenger travelling in one seat of a booking
+// SeatNumber is assigned from the order of the passengers array (ignored on input)
+type BookingPassenger struct {
+	SeatNumber int    `json:"seat_number"`
+	Name       string `json:"name" binding:"required,max=100"`
+	Document   string `json:"document,omitempty" binding:"required,max=32"` // Omitted in the driver's view of bookings not confirmed
 }
 
 // BookingResponse represents a booking in API responses
 type BookingResponse struct {
-	ID                 string     `json:"id"`
-	TripID             string     `json:"trip_id"`
-	PassengerID        int64      `json:"passenger_id"`
-	SeatsRequested     int        `json:"seats_requested"`
-	TotalPrice         float64    `json:"total_price"`
-	Status             string     `json:"status"`
-	CancelledAt        *time.Time `json:"cancelled_at,omitempty"`
-	CancellationReason string     `json:"cancellation_reason,omitempty"`
-	CreatedAt          time.Time  `json:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at"`
+	ID                 string             `json:"id"`
+	TripID             string             `json:"trip_id"`
+	PassengerID        int64              `json:"passenger_id"`
+	SeatsRequested     int                `json:"seats_requested"`
+	TotalPrice         float64            `json:"total_price"`
+	Fare               FareBreakdown      `json:"fare"`
+	Status             string             `json:"status"`
+	CancelledAt        *time.Time         `json:"cancelled_at,omitempty"`
+	CancellationReason string             `json:"cancellation_reason,omitempty"`
+	CancellationFee    float64            `json:"cancellation_fee,omitempty"`
+	Country            string             `json:"country,omitempty"`
+	PickupPointID      string             `json:"pickup_point_id,omitempty"`
+	SeatHoldID         string             `json:"seat_hold_id,omitempty"`
+	GuardianApprovalID *int64             `json:"guardian_approval_id,omitempty"` // Set while a dependent's booking needs the guardian
+	SplitPayment       bool               `json:"split_payment,omitempty"`
+	PaymentDueAt       *time.Time         `json:"payment_due_at,omitempty"`
+	Passengers         []BookingPassenger `json:"passengers,omitempty"` // Only in single-booking responses
+	DistanceKm         float64            `json:"distance_km"`
+	CO2SavedKg         float64            `json:"co2_saved_kg"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
 }
 
 // CancelBookingRequest represents the request to cancel a booking
@@ -31,6 +66,11 @@ type CancelBookingRequest struct {
 	Reason string `json:"reason"`
 }
 
+// ModifyBookingSeatsRequest represents the request to change the seats of a booking
+type ModifyBookingSeatsRequest struct {
+	Seats int `json:"seats" binding:"required,min=1"`
+}
+
 // BookingListResponse represents a paginated list of bookings
 type BookingListResponse struct {
 	Bookings   []BookingResponse `json:"bookings"`
@@ -40,6 +80,23 @@ type BookingListResponse struct {
 	TotalPages int               `json:"total_pages"`
 }
 
+// BookingStatusChange represents a single status transition in API responses
+type BookingStatusChange struct {
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// BookingAsOfResponse represents a booking reconstructed at a past moment
+// Used by admins to resolve disputes about when a status change actually happened
+type BookingAsOfResponse struct {
+	AsOf     time.Time             `json:"as_of"`
+	Booking  *BookingResponse      `json:"booking"`
+	DriverID int64                 `json:"driver_id"` // Trip driver at that moment (0 if not yet known)
+	History  []BookingStatusChange `json:"history"`
+}
+
 // Booking status constants (mirror DAO constants for clarity)
 const (
 	BookingStatusPending   = dao.BookingStatusPending
@@ -47,6 +104,10 @@ const (
 	BookingStatusCancelled = dao.BookingStatusCancelled
 	BookingStatusCompleted = dao.BookingStatusCompleted
 	BookingStatusFailed    = dao.BookingStatusFailed
+	BookingStatusExpired   = dao.BookingStatusExpired
+
+	BookingStatusAwaitingPayment  = dao.BookingStatusAwaitingPayment
+	BookingStatusAwaitingGuardian = dao.BookingStatusAwaitingGuardian
 )
 
 // ToBookingResponse converts a DAO Booking to a BookingResponse DTO
@@ -61,14 +122,34 @@ func ToBookingResponse(b *dao.Booking) *BookingResponse {
 		PassengerID:        b.PassengerID,
 		SeatsRequested:     b.SeatsRequested,
 		TotalPrice:         b.TotalPrice,
+		Fare:               NewFareBreakdown(b.TotalPrice, b.CreditsApplied),
 		Status:             b.Status,
 		CancelledAt:        b.CancelledAt,
 		CancellationReason: b.CancellationReason,
+		CancellationFee:    b.CancellationFee,
+		Country:            b.Country,
+		PickupPointID:      b.PickupPointID,
+		SeatHoldID:         b.SeatHoldID,
+		GuardianApprovalID: b.GuardianApprovalID,
+		SplitPayment:       b.SplitPayment,
+		PaymentDueAt:       b.PaymentDueAt,
+		DistanceKm:         b.DistanceKm,
+		CO2SavedKg:         b.CO2SavedKg,
 		CreatedAt:          b.CreatedAt,
 		UpdatedAt:          b.UpdatedAt,
 	}
 }
 
+// RecalculateTotalPrice scales a booking's total price to a new number of seats
+// The per-seat price is derived from the confirmed total (set by trips-api), rounded to cents
+func RecalculateTotalPrice(totalPrice float64, previousSeats, newSeats int) float64 {
+	if previousSeats <= 0 {
+		return totalPrice
+	}
+	pricePerSeat := totalPrice / float64(previousSeats)
+	return math.Round(pricePerSeat*float64(newSeats)*100) / 100
+}
+
 // ToBookingResponseList converts a slice of DAO Bookings to BookingResponse DTOs
 func ToBookingResponseList(bookings []dao.Booking) []BookingResponse {
 	responses := make([]BookingResponse, 0, len(bookings))
@@ -78,6 +159,19 @@ func ToBookingResponseList(bookings []dao.Booking) []BookingResponse {
 	return responses
 }
 
+// ToBookingPassengers converts the DAO passengers of a booking to DTOs
+func ToBookingPassengers(passengers []dao.BookingPassenger) []BookingPassenger {
+	result := make([]BookingPassenger, 0, len(passengers))
+	for _, p := range passengers {
+		result = append(result, BookingPassenger{
+			SeatNumber: p.SeatNumber,
+			Name:       p.Name,
+			Document:   p.Document,
+		})
+	}
+	return result
+}
+
 // CalculateTotalPages calculates the total number of pages for pagination
 func CalculateTotalPages(total int64, limit int) int {
 	if limit <= 0 {
@@ -100,3 +194,54 @@ func NewBookingListResponse(bookings []dao.Booking, total int64, page, limit int
 		TotalPages: CalculateTotalPages(total, limit),
 	}
 }
+
+// ToBookingAsOfResponse reconstructs a booking at a past moment from its status history
+//
+// Only the fields set at creation and never changed (trip, passenger, country, pickup point,
+// seat hold, route distance, creation time) come from the current booking row; every mutable
+// field comes from the snapshot of the last history entry at or before asOf, so no current
+// value leaks into the past state.
+// history must be ordered oldest first and must not be empty.
+func ToBookingAsOfResponse(b *dao.Booking, history []dao.BookingStatusHistory, asOf time.Time) *BookingAsOfResponse {
+	last := history[len(history)-1]
+
+	booking := &BookingResponse{
+		ID:                 b.BookingUUID,
+		TripID:             b.TripID,
+		PassengerID:        b.PassengerID,
+		SeatsRequested:     last.SeatsRequested,
+		TotalPrice:         last.TotalPrice,
+		Fare:               NewFareBreakdown(last.TotalPrice, last.CreditsApplied),
+		Status:             last.ToStatus,
+		CancelledAt:        last.CancelledAt,
+		CancellationReason: last.CancellationReason,
+		CancellationFee:    last.CancellationFee,
+		Country:            b.Country,
+		PickupPointID:      b.PickupPointID,
+		SeatHoldID:         b.SeatHoldID,
+		GuardianApprovalID: b.GuardianApprovalID,
+		SplitPayment:       b.SplitPayment,
+		PaymentDueAt:       last.PaymentDueAt,
+		DistanceKm:         b.DistanceKm,
+		CO2SavedKg:         last.CO2SavedKg,
+		CreatedAt:          b.CreatedAt,
+		UpdatedAt:          last.ChangedAt,
+	}
+
+	changes := make([]BookingStatusChange, 0, len(history))
+	for _, entry := range history {
+		changes = append(changes, BookingStatusChange{
+			FromStatus: entry.FromStatus,
+			ToStatus:   entry.ToStatus,
+			Reason:     entry.Reason,
+			ChangedAt:  entry.ChangedAt,
+		})
+	}
+
+	return &BookingAsOfResponse{
+		AsOf:     asOf,
+		Booking:  booking,
+		DriverID: last.DriverID,
+		History:  changes,
+	}
+}
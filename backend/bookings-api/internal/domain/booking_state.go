@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"bookings-api/internal/dao"
+	"time"
+)
+
+// bookingTransitions is the booking saga state machine: allowed next statuses per status
+//
+//	awaiting_guardian → pending (guardian approved, reservation.created published), cancelled (guardian
+//	                   rejected, or passenger), expired (the approval expired without an answer)
+//	pending          → confirmed (reservation.confirmed), awaiting_payment (reservation.confirmed of a
+//	                   split-payment booking or with a payment provider), failed (reservation.failed),
+//	                   cancelled (passenger), expired (no answer from trips-api within the pending timeout)
+//	awaiting_payment → confirmed (no payment share left pending, or payment authorized), cancelled (passenger,
+//	                   driver, trip.cancelled, payment declined or not authorized before the deadline)
+//	confirmed        → cancelled (passenger, driver or trip.cancelled), completed
+//	failed, cancelled, completed, expired are terminal
+var bookingTransitions = map[string][]string{
+	BookingStatusAwaitingGuardian: {BookingStatusPending, BookingStatusCancelled, BookingStatusExpired},
+	BookingStatusPending:          {BookingStatusConfirmed, BookingStatusAwaitingPayment, BookingStatusFailed, BookingStatusCancelled, BookingStatusExpired},
+	BookingStatusAwaitingPayment:  {BookingStatusConfirmed, BookingStatusCancelled},
+	BookingStatusConfirmed:        {BookingStatusCancelled, BookingStatusCompleted},
+	BookingStatusFailed:           {},
+	BookingStatusCancelled:        {},
+	BookingStatusCompleted:        {},
+	BookingStatusExpired:          {},
+}
+
+// AllowedTransitions returns the statuses a booking can move to from the given status
+func AllowedTransitions(status string) []string {
+	next := bookingTransitions[status]
+	allowed := make([]string, len(next))
+	copy(allowed, next)
+	return allowed
+}
+
+// CanTransition reports whether a booking can move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range bookingTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminalStatus reports whether no further transitions are possible from the status
+func IsTerminalStatus(status string) bool {
+	return len(bookingTransitions[status]) == 0
+}
+
+// BookingStateResponse is the derived saga state of a booking in API responses
+type BookingStateResponse struct {
+	BookingID          string                `json:"booking_id"`
+	Status             string                `json:"status"`
+	Terminal           bool                  `json:"terminal"`
+	AllowedTransitions []string              `json:"allowed_transitions"`
+	AwaitingTripsAPI   bool                  `json:"awaiting_trips_api"` // pending: waiting for reservation.confirmed / reservation.failed
+	AwaitingPayment    bool                  `json:"awaiting_payment"`   // awaiting_payment: waiting for the payment shares or authorization
+	History            []BookingStatusChange `json:"history"`
+	UpdatedAt          time.Time             `json:"updated_at"`
+}
+
+// ToBookingStateResponse derives the saga state of a booking from its current row and status history
+// history must be ordered oldest first (it may be empty for bookings created before the history table)
+func ToBookingStateResponse(b *dao.Booking, history []dao.BookingStatusHistory) *BookingStateResponse {
+	changes := make([]BookingStatusChange, 0, len(history))
+	for _, entry := range history {
+		// Seat changes keep the status: not part of the state machine
+		if entry.FromStatus == entry.ToStatus {
+			continue
+		}
+		changes = append(changes, BookingStatusChange{
+			FromStatus: entry.FromStatus,
+			ToStatus:   entry.ToStatus,
+			Reason:     entry.Reason,
+			ChangedAt:  entry.ChangedAt,
+		})
+	}
+
+	return &BookingStateResponse{
+		BookingID:          b.BookingUUID,
+		Status:             b.Status,
+		Terminal:           IsTerminalStatus(b.Status),
+		AllowedTransitions: AllowedTransitions(b.Status),
+		AwaitingTripsAPI:   b.Status == BookingStatusPending,
+		AwaitingPayment:    b.Status == BookingStatusAwaitingPayment,
+		History:            changes,
+		UpdatedAt:          b.UpdatedAt,
+	}
+}
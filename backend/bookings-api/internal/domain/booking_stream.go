@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// BookingStatusEvent is a status transition pushed to the live booking stream (GET /bookings/:id/stream)
+type BookingStatusEvent struct {
+	BookingID      string    `json:"booking_id"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	Terminal       bool      `json:"terminal"`
+	ChangedAt      time.Time `json:"changed_at"`
+}
+
+// NewBookingStatusEvent builds the stream event of a transition from one status to another
+func NewBookingStatusEvent(bookingID, from, to, reason string) BookingStatusEvent {
+	return BookingStatusEvent{
+		BookingID:      bookingID,
+		Status:         to,
+		PreviousStatus: from,
+		Reason:         reason,
+		Terminal:       IsTerminalStatus(to),
+		ChangedAt:      time.Now(),
+	}
+}
+
+// BookingStatusEventFromState builds the stream event of the current status of a booking
+// (initial snapshot, or a transition found by polling that happened in another instance)
+func BookingStatusEventFromState(state *BookingStateResponse, previousStatus string) BookingStatusEvent {
+	event := BookingStatusEvent{
+		BookingID:      state.BookingID,
+		Status:         state.Status,
+		PreviousStatus: previousStatus,
+		Terminal:       state.Terminal,
+		ChangedAt:      state.UpdatedAt,
+	}
+	if n := len(state.History); n > 0 && state.History[n-1].ToStatus == state.Status {
+		event.Reason = state.History[n-1].Reason
+		event.ChangedAt = state.History[n-1].ChangedAt
+	}
+	return event
+}
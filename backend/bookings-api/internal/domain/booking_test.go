@@ -0,0 +1,27 @@
+package domain
+
+import "testing"
+
+func TestRecalculateTotalPrice(t *testing.T) {
+	tests := []struct {
+		name          string
+		totalPrice    float64
+		previousSeats int
+		newSeats      int
+		want          float64
+	}{
+		{name: "more seats", totalPrice: 3000, previousSeats: 2, newSeats: 3, want: 4500},
+		{name: "fewer seats", totalPrice: 4500, previousSeats: 3, newSeats: 1, want: 1500},
+		{name: "rounded to cents", totalPrice: 100, previousSeats: 3, newSeats: 2, want: 66.67},
+		{name: "round trip keeps the fare", totalPrice: 66.67, previousSeats: 2, newSeats: 2, want: 66.67},
+		{name: "unknown previous seats keeps the total", totalPrice: 1200, previousSeats: 0, newSeats: 2, want: 1200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RecalculateTotalPrice(tt.totalPrice, tt.previousSeats, tt.newSeats); got != tt.want {
+				t.Errorf("RecalculateTotalPrice(%v, %d, %d) = %v, want %v", tt.totalPrice, tt.previousSeats, tt.newSeats, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import "math"
+
+// CreditConsumeRequest is the body of POST /internal/credits/consume in users-api
+// users-api applies up to Amount of the passenger's balance in Currency, once per BookingID
+type CreditConsumeRequest struct {
+	UserID    int64   `json:"user_id"`
+	BookingID string  `json:"booking_id"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency"`
+}
+
+// CreditConsumption is the credits users-api applied to a booking
+// AppliedAmount may be lower than the fare (or 0) when the balance does not cover it
+type CreditConsumption struct {
+	AppliedAmount float64 `json:"applied_amount"`
+	Balance       float64 `json:"balance"`
+	Replayed      bool    `json:"replayed"` // The booking had already consumed credits (same AppliedAmount)
+}
+
+// CreditReleaseRequest is the body of POST /internal/credits/release in users-api
+type CreditReleaseRequest struct {
+	BookingID string `json:"booking_id"`
+}
+
+// CreditRelease is the credits users-api returned to the passenger for a cancelled booking
+// ReleasedAmount may be lower than AppliedAmount when some of the credits expired meanwhile
+type CreditRelease struct {
+	AppliedAmount  float64 `json:"applied_amount"`
+	ReleasedAmount float64 `json:"released_amount"`
+	Replayed       bool    `json:"replayed"`
+}
+
+// FareBreakdown splits the fare of a booking between promotional credits and what the passenger pays
+type FareBreakdown struct {
+	Subtotal       float64 `json:"subtotal"`        // TotalPrice
+	CreditsApplied float64 `json:"credits_applied"` // Promotional credits consumed at confirmation
+	AmountDue      float64 `json:"amount_due"`      // Charged to the passenger (Subtotal minus CreditsApplied)
+}
+
+// NewFareBreakdown builds the fare breakdown of a booking
+func NewFareBreakdown(totalPrice, creditsApplied float64) FareBreakdown {
+	return FareBreakdown{
+		Subtotal:       totalPrice,
+		CreditsApplied: creditsApplied,
+		AmountDue:      AmountDue(totalPrice, creditsApplied),
+	}
+}
+
+// AmountDue is the part of the fare not covered by promotional credits, rounded to cents
+// A seat reduction may leave the fare below the credits applied, in which case nothing is due
+func AmountDue(totalPrice, creditsApplied float64) float64 {
+	return math.Max(math.Round((totalPrice-creditsApplied)*100)/100, 0)
+}
+
+// CashRefund is the part of a cancelled booking returned to the passenger's payment method
+// The promotional credits are returned to the passenger's balance instead, or kept on late
+// cancellations: the fee is then taken from them first, and only what they do not cover from the cash
+func CashRefund(totalPrice, fee, creditsApplied float64) float64 {
+	feeOnCash := math.Max(fee-creditsApplied, 0)
+	return math.Max(math.Round((AmountDue(totalPrice, creditsApplied)-feeOnCash)*100)/100, 0)
+}
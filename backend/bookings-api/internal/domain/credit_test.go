@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestCashRefund(t *testing.T) {
+	tests := []struct {
+		name           string
+		totalPrice     float64
+		fee            float64
+		creditsApplied float64
+		want           float64
+	}{
+		{"free cancellation without credits", 100, 0, 0, 100},
+		{"free cancellation with credits", 100, 0, 30, 70},
+		{"late cancellation without credits", 100, 20, 0, 80},
+		{"fee covered by the kept credits", 100, 20, 30, 70},
+		{"fee equal to the kept credits", 100, 30, 30, 70},
+		{"fee above the kept credits", 100, 50, 30, 50},
+		{"fare fully paid with credits", 100, 20, 100, 0},
+		{"fee above the whole fare", 100, 150, 30, 0},
+		{"fares with cents", 40.1, 12.5, 10.25, 27.6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CashRefund(tt.totalPrice, tt.fee, tt.creditsApplied); got != tt.want {
+				t.Errorf("CashRefund(%v, %v, %v) = %v, want %v", tt.totalPrice, tt.fee, tt.creditsApplied, got, tt.want)
+			}
+		})
+	}
+}
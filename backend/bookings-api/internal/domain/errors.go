@@ -45,6 +45,66 @@ var (
 		Code:    "BOOKING_ALREADY_CANCELLED",
 		Message: "Booking has already been cancelled",
 	}
+	ErrBookingExpired = &AppError{
+		Code:    "BOOKING_EXPIRED",
+		Message: "Booking expired before the trip confirmed it",
+	}
+	ErrBookingNotYetCreated = &AppError{
+		Code:    "BOOKING_NOT_YET_CREATED",
+		Message: "Booking did not exist at the requested time",
+	}
+	ErrStatusHistoryUnavailable = &AppError{
+		Code:    "STATUS_HISTORY_UNAVAILABLE",
+		Message: "No status history recorded for this booking at the requested time",
+	}
+	ErrBookingNotModifiable = &AppError{
+		Code:    "BOOKING_NOT_MODIFIABLE",
+		Message: "Only confirmed bookings for trips that have not departed can be modified",
+	}
+	ErrSeatsUnchanged = &AppError{
+		Code:    "SEATS_UNCHANGED",
+		Message: "The booking already has the requested number of seats",
+	}
+	ErrBookingModifiedConcurrently = &AppError{
+		Code:    "BOOKING_MODIFIED_CONCURRENTLY",
+		Message: "The booking was modified by another request, please retry",
+	}
+	ErrPassengerCountMismatch = &AppError{
+		Code:    "PASSENGER_COUNT_MISMATCH",
+		Message: "The number of passengers must match the seats reserved",
+	}
+	ErrSeatsFixedByPassengers = &AppError{
+		Code:    "SEATS_FIXED_BY_PASSENGERS",
+		Message: "Bookings with passenger details cannot change seats",
+	}
+
+	// Split payment errors
+	ErrSplitPaymentRequiresPassengers = &AppError{
+		Code:    "SPLIT_PAYMENT_REQUIRES_PASSENGERS",
+		Message: "Split payment requires at least 2 seats and one passenger per seat",
+	}
+	ErrBookingNotSplitPayment = &AppError{
+		Code:    "BOOKING_NOT_SPLIT_PAYMENT",
+		Message: "The booking is not paid in shares",
+	}
+	ErrPaymentShareNotFound = &AppError{
+		Code:    "PAYMENT_SHARE_NOT_FOUND",
+		Message: "Payment share not found",
+	}
+	ErrPaymentShareNotPayable = &AppError{
+		Code:    "PAYMENT_SHARE_NOT_PAYABLE",
+		Message: "The payment share can no longer be paid",
+	}
+
+	// Payment provider errors
+	ErrPaymentNotFound = &AppError{
+		Code:    "PAYMENT_NOT_FOUND",
+		Message: "Payment not found",
+	}
+	ErrPaymentStatusConflict = &AppError{
+		Code:    "PAYMENT_STATUS_CONFLICT",
+		Message: "The payment can no longer change to the requested status",
+	}
 
 	// Trip validation errors
 	ErrTripNotFound = &AppError{
@@ -63,12 +123,104 @@ var (
 		Code:    "CANNOT_BOOK_OWN_TRIP",
 		Message: "Cannot book your own trip",
 	}
+	ErrInvalidPickupPoint = &AppError{
+		Code:    "INVALID_PICKUP_POINT",
+		Message: "The pickup point does not belong to this trip",
+	}
+	ErrMaxSeatsExceeded = &AppError{
+		Code:    "MAX_SEATS_EXCEEDED",
+		Message: "Requested seats exceed the maximum allowed per booking",
+	}
+
+	// User errors (users-api)
+	ErrUserNotFound = &AppError{
+		Code:    "USER_NOT_FOUND",
+		Message: "User not found",
+	}
+	ErrNotDependentAccount = &AppError{
+		Code:    "NOT_DEPENDENT_ACCOUNT",
+		Message: "The account is not a dependent account, log in again to refresh its restrictions",
+	}
+	ErrGuardianApprovalNotFound = &AppError{
+		Code:    "GUARDIAN_APPROVAL_NOT_FOUND",
+		Message: "Guardian approval not found",
+	}
+
+	// Session errors (JWT middleware, checked against users-api)
+	ErrSessionRevoked = &AppError{
+		Code:    "SESSION_REVOKED",
+		Message: "Session was revoked, log in again",
+	}
+	ErrAccountInactive = &AppError{
+		Code:    "ACCOUNT_INACTIVE",
+		Message: "Account is deactivated",
+	}
+	ErrAccountSuspended = &AppError{
+		Code:    "ACCOUNT_SUSPENDED",
+		Message: "Account is suspended",
+	}
+
+	// Quarantined message errors (consumer schema validation)
+	ErrQuarantinedMessageNotFound = &AppError{
+		Code:    "QUARANTINED_MESSAGE_NOT_FOUND",
+		Message: "Quarantined message not found",
+	}
+	ErrQuarantinedMessageResolved = &AppError{
+		Code:    "QUARANTINED_MESSAGE_RESOLVED",
+		Message: "The message has already been reprocessed or discarded",
+	}
+	ErrSchemaValidationFailed = &AppError{
+		Code:    "SCHEMA_VALIDATION_FAILED",
+		Message: "The payload does not match the event schema",
+	}
+
+	// Admin approval errors (destructive admin actions need a second admin)
+	ErrAdminApprovalNotFound = &AppError{
+		Code:    "ADMIN_APPROVAL_NOT_FOUND",
+		Message: "Admin approval not found",
+	}
+	ErrAdminApprovalNotPending = &AppError{
+		Code:    "ADMIN_APPROVAL_NOT_PENDING",
+		Message: "The approval has already been decided",
+	}
+	ErrAdminApprovalExpired = &AppError{
+		Code:    "ADMIN_APPROVAL_EXPIRED",
+		Message: "The approval expired before a second admin approved it",
+	}
+	ErrAdminApprovalAlreadyPending = &AppError{
+		Code:    "ADMIN_APPROVAL_ALREADY_PENDING",
+		Message: "There is already a pending approval for this action on the booking",
+	}
+	ErrAdminApprovalSelfApproval = &AppError{
+		Code:    "ADMIN_APPROVAL_SELF_APPROVAL",
+		Message: "The approval must come from a different admin than the one who requested it",
+	}
+	ErrAdminActionNotApplicable = &AppError{
+		Code:    "ADMIN_ACTION_NOT_APPLICABLE",
+		Message: "The action does not apply to the booking in its current status",
+	}
 
 	// External service errors
 	ErrTripsAPIUnavailable = &AppError{
 		Code:    "TRIPS_API_UNAVAILABLE",
 		Message: "Trips service is temporarily unavailable",
 	}
+	ErrUsersAPIUnavailable = &AppError{
+		Code:    "USERS_API_UNAVAILABLE",
+		Message: "Users service is temporarily unavailable",
+	}
+	ErrPaymentProviderUnavailable = &AppError{
+		Code:    "PAYMENT_PROVIDER_UNAVAILABLE",
+		Message: "Payment provider is temporarily unavailable",
+	}
+	ErrServiceOverloaded = &AppError{
+		Code:    "SERVICE_OVERLOADED",
+		Message: "Service is under heavy load, please retry later",
+	}
+	ErrTooManyStreams = &AppError{
+		Code:    "TOO_MANY_STREAMS",
+		Message: "Too many live booking streams open, close one before opening another",
+	}
 )
 
 // WithDetails returns a new AppError with additional details
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// ExpirationReason is stored in the status history of bookings expired by the expiration job
+const ExpirationReason = "No answer from trips-api within the pending timeout"
+
+// ExpirationRunResult summarizes one run of the booking expiration job
+type ExpirationRunResult struct {
+	BookingsExpired  int64         `json:"bookings_expired"`
+	PublishFailures  int64         `json:"publish_failures"`   // Expired but reservation.cancelled could not be published
+	HoldsReleased    int64         `json:"holds_released"`     // Expired with an unconfirmed seat hold, released in trips-api
+	StatusChanged    int64         `json:"status_changed"`     // Resolved by trips-api while the job was running
+	OldestPendingAge time.Duration `json:"oldest_pending_age"` // Age of the oldest booking expired in this run
+	Cutoff           time.Time     `json:"cutoff"`
+	Duration         string        `json:"duration"`
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// RestrictionBookingRequiresApproval is the users-api restriction (JWT claim "restrictions") of dependent
+// (minor) accounts whose bookings wait for their guardian's approval
+const RestrictionBookingRequiresApproval = "booking_requires_approval"
+
+// Guardian approval actions and statuses, as defined by users-api (/internal/guardian-approvals)
+const (
+	GuardianApprovalActionBooking = "booking"
+
+	GuardianApprovalPending  = "pending"
+	GuardianApprovalApproved = "approved"
+	GuardianApprovalRejected = "rejected"
+	GuardianApprovalExpired  = "expired" // Pending past its deadline (users-api derives it on read)
+)
+
+// Reasons stored in the status history when a guardian approval is resolved
+const (
+	GuardianApprovedReason = "Approved by guardian"
+	GuardianRejectedReason = "Rejected by guardian"
+	GuardianExpiredReason  = "Guardian approval expired"
+)
+
+// GuardianApprovalRequest asks users-api for the guardian's approval of a dependent's booking
+// Idempotent by (action, resource_id): repeating it returns the existing approval
+type GuardianApprovalRequest struct {
+	DependentID int64  `json:"dependent_id"`
+	Action      string `json:"action"`
+	ResourceID  string `json:"resource_id"` // Booking UUID
+	TripID      string `json:"trip_id,omitempty"`
+	Details     string `json:"details,omitempty"` // Shown to the guardian (route and departure)
+}
+
+// GuardianApproval is the state of an approval in users-api
+type GuardianApproval struct {
+	ID           int64     `json:"id"`
+	Status       string    `json:"status"`
+	DecisionNote string    `json:"decision_note,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// GuardianApprovalRunResult summarizes one run of the guardian approval job
+type GuardianApprovalRunResult struct {
+	Checked         int64  `json:"checked"`          // Bookings awaiting approval looked up in users-api
+	Approved        int64  `json:"approved"`         // Moved to pending, reservation.created published
+	Rejected        int64  `json:"rejected"`         // Cancelled by the guardian
+	Expired         int64  `json:"expired"`          // Approval expired without an answer
+	PublishFailures int64  `json:"publish_failures"` // Approved but reservation.created could not be published
+	LookupFailures  int64  `json:"lookup_failures"`  // users-api could not be reached (retried in the next run)
+	StatusChanged   int64  `json:"status_changed"`   // Cancelled by the passenger while the job was running
+	Duration        string `json:"duration"`
+}
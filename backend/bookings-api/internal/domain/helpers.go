@@ -59,3 +59,24 @@ func GetRoleFromContext(c *gin.Context) (string, error) {
 
 	return role, nil
 }
+
+// HasRestrictionInContext indica si el JWT del usuario trae la restricción (claim "restrictions")
+// Sin el claim (cuentas que no son dependientes) retorna false
+func HasRestrictionInContext(c *gin.Context, restriction string) bool {
+	value, exists := c.Get("restrictions")
+	if !exists {
+		return false
+	}
+
+	restrictions, ok := value.([]string)
+	if !ok {
+		return false
+	}
+
+	for _, r := range restrictions {
+		if r == restriction {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"bookings-api/internal/dao"
+	"time"
+)
+
+// Payment status constants (mirror DAO constants for clarity)
+const (
+	PaymentStatusPending    = dao.PaymentStatusPending
+	PaymentStatusAuthorized = dao.PaymentStatusAuthorized
+	PaymentStatusCaptured   = dao.PaymentStatusCaptured
+	PaymentStatusDeclined   = dao.PaymentStatusDeclined
+	PaymentStatusRefunded   = dao.PaymentStatusRefunded
+	PaymentStatusCancelled  = dao.PaymentStatusCancelled
+)
+
+// Reasons stored in the status history of bookings paid through the payment provider
+const (
+	PaymentAuthorizationReason = "Seats reserved by trips-api, waiting for the payment authorization"
+	PaymentAuthorizedReason    = "Payment authorized by the payment provider"
+	PaymentDeclinedReason      = "Payment declined by the payment provider"
+	PaymentTimeoutReason       = "Payment not authorized before the deadline"
+)
+
+// PaymentAuthorizationRequest is what a payment provider needs to authorize a booking's fare
+type PaymentAuthorizationRequest struct {
+	// Reference identifies the payment on our side (booking UUID); providers use it as idempotency key
+	Reference string
+
+	Amount   float64
+	Currency string
+
+	// PaymentMethodToken is the tokenized payment method; empty asks the provider for a checkout page
+	PaymentMethodToken string
+
+	Description string
+}
+
+// ProviderPaymentResult is the answer of a payment provider to an authorization
+// Status is authorized, declined or pending (the passenger still has to complete the checkout)
+type ProviderPaymentResult struct {
+	ProviderPaymentID string
+	Status            string
+	DeclineReason     string
+	CheckoutURL       string
+}
+
+// PaymentWebhookRequest is the payment provider webhook payload for an asynchronous status change
+type PaymentWebhookRequest struct {
+	PaymentID string `json:"payment_id" binding:"required,max=100"`
+	Status    string `json:"status" binding:"required,oneof=authorized declined captured refunded"`
+	Reason    string `json:"reason" binding:"omitempty,max=255"`
+}
+
+// PaymentResponse represents the provider payment of a booking in API responses
+type PaymentResponse struct {
+	BookingID         string     `json:"booking_id"`
+	BookingStatus     string     `json:"booking_status"`
+	Provider          string     `json:"provider"`
+	ProviderPaymentID string     `json:"provider_payment_id,omitempty"`
+	Status            string     `json:"status"`
+	Amount            float64    `json:"amount"`
+	AmountRefunded    float64    `json:"amount_refunded"`
+	Currency          string     `json:"currency"`
+	CheckoutURL       string     `json:"checkout_url,omitempty"` // Only while the authorization is pending
+	FailureReason     string     `json:"failure_reason,omitempty"`
+	DueAt             *time.Time `json:"due_at,omitempty"`
+	AuthorizedAt      *time.Time `json:"authorized_at,omitempty"`
+	CapturedAt        *time.Time `json:"captured_at,omitempty"`
+	RefundedAt        *time.Time `json:"refunded_at,omitempty"`
+}
+
+// ToPaymentResponse converts a DAO Payment (and its booking) to a PaymentResponse DTO
+func ToPaymentResponse(p *dao.Payment, b *dao.Booking) *PaymentResponse {
+	response := &PaymentResponse{
+		BookingID:         b.BookingUUID,
+		BookingStatus:     b.Status,
+		Provider:          p.Provider,
+		ProviderPaymentID: p.ProviderPaymentID,
+		Status:            p.Status,
+		Amount:            p.Amount,
+		AmountRefunded:    p.AmountRefunded,
+		Currency:          p.Currency,
+		FailureReason:     p.FailureReason,
+		DueAt:             b.PaymentDueAt,
+		AuthorizedAt:      p.AuthorizedAt,
+		CapturedAt:        p.CapturedAt,
+		RefundedAt:        p.RefundedAt,
+	}
+	if p.Status == PaymentStatusPending && b.IsAwaitingPayment() {
+		response.CheckoutURL = p.CheckoutURL
+	}
+	return response
+}
+
+// PaymentRunResult summarizes one run of the payment provider job
+type PaymentRunResult struct {
+	AuthorizationsRetried int64     `json:"authorizations_retried"` // Authorizations that had not reached the provider
+	CapturesRetried       int64     `json:"captures_retried"`
+	BookingsExpired       int64     `json:"bookings_expired"` // Cancelled because the authorization did not arrive in time
+	Failed                int64     `json:"failed"`
+	Cutoff                time.Time `json:"cutoff"`
+	Duration              string    `json:"duration"`
+}
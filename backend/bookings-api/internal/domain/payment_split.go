@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"bookings-api/internal/dao"
+	"math"
+	"time"
+)
+
+// Payment share status constants (mirror DAO constants for clarity)
+const (
+	PaymentShareStatusPending = dao.PaymentShareStatusPending
+	PaymentShareStatusPaid    = dao.PaymentShareStatusPaid
+	PaymentShareStatusCovered = dao.PaymentShareStatusCovered
+)
+
+// Reasons stored in the status history of split-payment bookings
+const (
+	PaymentAwaitingReason = "Seats reserved by trips-api, waiting for the payment shares"
+	PaymentPaidReason     = "All payment shares paid"
+	PaymentCoveredReason  = "Payment deadline passed, the organizer covers the unpaid shares"
+)
+
+// MinSplitPaymentSeats is the minimum number of seats of a split-payment booking
+const MinSplitPaymentSeats = 2
+
+// SplitFare divides a total price in equal shares rounded to cents
+// The rounding remainder goes to the first share (the organizer's)
+func SplitFare(totalPrice float64, shares int) []float64 {
+	if shares <= 0 {
+		return nil
+	}
+
+	totalCents := int64(math.Round(totalPrice * 100))
+	baseCents := totalCents / int64(shares)
+	amounts := make([]float64, shares)
+	for i := range amounts {
+		amounts[i] = float64(baseCents) / 100
+	}
+	amounts[0] = float64(totalCents-baseCents*int64(shares-1)) / 100
+	return amounts
+}
+
+// MarkSharePaidRequest is the payment provider webhook payload for a paid share
+type MarkSharePaidRequest struct {
+	PaymentReference string `json:"payment_reference" binding:"required,max=100"`
+}
+
+// PaymentShareResponse represents one payment share in API responses
+// PaymentURL is only included for the organizer of the booking
+type PaymentShareResponse struct {
+	SeatNumber       int        `json:"seat_number"`
+	PassengerName    string     `json:"passenger_name"`
+	Amount           float64    `json:"amount"`
+	Status           string     `json:"status"`
+	PaymentURL       string     `json:"payment_url,omitempty"`
+	PaymentReference string     `json:"payment_reference,omitempty"`
+	PaidAt           *time.Time `json:"paid_at,omitempty"`
+}
+
+// PublicPaymentShareResponse is what the payment page shows to whoever opens a payment link
+// It identifies the share without exposing the other passengers of the booking
+type PublicPaymentShareResponse struct {
+	BookingID     string     `json:"booking_id"`
+	TripID        string     `json:"trip_id"`
+	SeatNumber    int        `json:"seat_number"`
+	PassengerName string     `json:"passenger_name"`
+	Amount        float64    `json:"amount"`
+	Status        string     `json:"status"`
+	Payable       bool       `json:"payable"` // Pending share of a booking still awaiting payment
+	DueAt         *time.Time `json:"due_at,omitempty"`
+}
+
+// BookingPaymentResponse is the payment progress of a split-payment booking
+type BookingPaymentResponse struct {
+	BookingID     string                 `json:"booking_id"`
+	Status        string                 `json:"status"`
+	TotalPrice    float64                `json:"total_price"`
+	AmountPaid    float64                `json:"amount_paid"`
+	AmountCovered float64                `json:"amount_covered"` // Unpaid shares covered by the organizer after the deadline
+	AmountPending float64                `json:"amount_pending"`
+	SharesPaid    int                    `json:"shares_paid"`
+	SharesTotal   int                    `json:"shares_total"`
+	DueAt         *time.Time             `json:"due_at,omitempty"`
+	Shares        []PaymentShareResponse `json:"shares"`
+}
+
+// ToBookingPaymentResponse summarizes the payment shares of a booking
+// paymentURL builds the link of a share from its token; nil omits the links
+func ToBookingPaymentResponse(b *dao.Booking, shares []dao.PaymentShare, paymentURL func(token string) string) *BookingPaymentResponse {
+	response := &BookingPaymentResponse{
+		BookingID:   b.BookingUUID,
+		Status:      b.Status,
+		TotalPrice:  b.TotalPrice,
+		SharesTotal: len(shares),
+		DueAt:       b.PaymentDueAt,
+		Shares:      make([]PaymentShareResponse, 0, len(shares)),
+	}
+
+	for _, share := range shares {
+		switch share.Status {
+		case PaymentShareStatusPaid:
+			response.AmountPaid += share.Amount
+			response.SharesPaid++
+		case PaymentShareStatusCovered:
+			response.AmountCovered += share.Amount
+		default:
+			response.AmountPending += share.Amount
+		}
+
+		item := PaymentShareResponse{
+			SeatNumber:       share.SeatNumber,
+			PassengerName:    share.PassengerName,
+			Amount:           share.Amount,
+			Status:           share.Status,
+			PaymentReference: share.PaymentReference,
+			PaidAt:           share.PaidAt,
+		}
+		if paymentURL != nil && share.Status == PaymentShareStatusPending {
+			item.PaymentURL = paymentURL(share.Token)
+		}
+		response.Shares = append(response.Shares, item)
+	}
+
+	response.AmountPaid = math.Round(response.AmountPaid*100) / 100
+	response.AmountCovered = math.Round(response.AmountCovered*100) / 100
+	response.AmountPending = math.Round(response.AmountPending*100) / 100
+	return response
+}
+
+// PaymentSplitRunResult summarizes one run of the payment deadline job
+type PaymentSplitRunResult struct {
+	BookingsSettled int64     `json:"bookings_settled"` // Confirmed with the unpaid shares covered by the organizer
+	SharesCovered   int64     `json:"shares_covered"`
+	StatusChanged   int64     `json:"status_changed"` // Paid or cancelled while the job was running
+	Cutoff          time.Time `json:"cutoff"`
+	Duration        string    `json:"duration"`
+}
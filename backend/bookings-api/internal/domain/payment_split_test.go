@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSplitFare(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalPrice float64
+		shares     int
+		want       []float64
+	}{
+		{name: "even split", totalPrice: 90, shares: 3, want: []float64{30, 30, 30}},
+		{name: "remainder goes to the organizer", totalPrice: 100, shares: 3, want: []float64{33.34, 33.33, 33.33}},
+		{name: "cents", totalPrice: 10.01, shares: 2, want: []float64{5.01, 5}},
+		{name: "single share", totalPrice: 42.5, shares: 1, want: []float64{42.5}},
+		{name: "no shares", totalPrice: 100, shares: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitFare(tt.totalPrice, tt.shares)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SplitFare(%v, %d) = %v, want %v", tt.totalPrice, tt.shares, got, tt.want)
+			}
+
+			sum := 0.0
+			for _, amount := range got {
+				sum += amount
+			}
+			if len(got) > 0 && math.Abs(sum-tt.totalPrice) > 0.001 {
+				t.Errorf("shares add up to %v, want %v", sum, tt.totalPrice)
+			}
+		})
+	}
+}
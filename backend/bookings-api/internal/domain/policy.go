@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// CountryPolicy holds the booking rules that apply in a given country/market
+//
+// Policies are resolved per booking from the trip's country (see policy.Registry).
+// Trips without a country fall back to the registry's default country.
+type CountryPolicy struct {
+	// Country is the ISO 3166-1 alpha-2 code (e.g., "AR", "UY")
+	Country string `json:"country"`
+
+	// Currency is the ISO 4217 code prices are expressed in (informational)
+	Currency string `json:"currency"`
+
+	// FreeCancellationHours is how long before departure a passenger can still cancel for free
+	// Cancellations closer to departure pay LateCancellationFeePercent of the total price
+	FreeCancellationHours int `json:"free_cancellation_hours"`
+
+	// LateCancellationFeePercent is the fee charged for late cancellations (0-100)
+	LateCancellationFeePercent float64 `json:"late_cancellation_fee_percent"`
+
+	// MaxSeatsPerBooking is the maximum number of seats a single booking can request
+	MaxSeatsPerBooking int `json:"max_seats_per_booking"`
+}
+
+// Validate checks that the policy values are within sane bounds
+func (p CountryPolicy) Validate() error {
+	if len(p.Country) != 2 {
+		return fmt.Errorf("country must be a 2-letter ISO code, got %q", p.Country)
+	}
+	if p.FreeCancellationHours < 0 {
+		return fmt.Errorf("%s: free_cancellation_hours must be non-negative", p.Country)
+	}
+	if p.LateCancellationFeePercent < 0 || p.LateCancellationFeePercent > 100 {
+		return fmt.Errorf("%s: late_cancellation_fee_percent must be between 0 and 100", p.Country)
+	}
+	if p.MaxSeatsPerBooking < 1 {
+		return fmt.Errorf("%s: max_seats_per_booking must be at least 1", p.Country)
+	}
+	return nil
+}
+
+// IsLateCancellation reports whether a cancellation at cancelledAt falls inside the paid window
+func (p CountryPolicy) IsLateCancellation(departure, cancelledAt time.Time) bool {
+	window := time.Duration(p.FreeCancellationHours) * time.Hour
+	return departure.Sub(cancelledAt) < window
+}
+
+// CancellationFee calculates the fee for a passenger cancellation, rounded to cents
+// Returns 0 when the cancellation happens before the free cancellation window closes
+func (p CountryPolicy) CancellationFee(totalPrice float64, departure, cancelledAt time.Time) float64 {
+	if !p.IsLateCancellation(departure, cancelledAt) {
+		return 0
+	}
+	fee := totalPrice * p.LateCancellationFeePercent / 100
+	return math.Round(fee*100) / 100
+}
+
+// CancellationQuote is the outcome of applying a country policy to a cancellation
+type CancellationQuote struct {
+	Fee          float64    `json:"fee"`
+	RefundAmount float64    `json:"refund_amount"` // TotalPrice minus Fee
+	Currency     string     `json:"currency"`
+	FreeUntil    *time.Time `json:"free_until,omitempty"` // Nil when the departure is unknown
+}
+
+// QuoteCancellation calculates the fee and the refund for a cancellation at cancelledAt
+// A nil departure means it is unknown, in which case the cancellation is free
+func (p CountryPolicy) QuoteCancellation(totalPrice float64, departure *time.Time, cancelledAt time.Time) CancellationQuote {
+	quote := CancellationQuote{
+		RefundAmount: totalPrice,
+		Currency:     p.Currency,
+	}
+	if departure == nil {
+		return quote
+	}
+
+	freeUntil := departure.Add(-time.Duration(p.FreeCancellationHours) * time.Hour)
+	quote.FreeUntil = &freeUntil
+	quote.Fee = p.CancellationFee(totalPrice, *departure, cancelledAt)
+	quote.RefundAmount = math.Round((totalPrice-quote.Fee)*100) / 100
+	return quote
+}
+
+// Policy resolution sources (how the effective policy was chosen)
+const (
+	PolicySourceTripCountry    = "trip_country"    // Trip country has an explicit policy
+	PolicySourceDefaultCountry = "default_country" // Trip has no country or the country has no policy
+)
+
+// EffectivePolicyResponse represents the policy that applies to a trip (admin inspection)
+type EffectivePolicyResponse struct {
+	TripID       string        `json:"trip_id"`
+	TripCountry  string        `json:"trip_country,omitempty"`
+	ResolvedFrom string        `json:"resolved_from"`
+	Policy       CountryPolicy `json:"policy"`
+}
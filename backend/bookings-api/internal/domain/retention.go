@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"bookings-api/internal/dao"
+	"strconv"
+	"time"
+)
+
+// AnalyticsTimeGranularity is the precision kept for the timestamps of anonymized records
+const AnalyticsTimeGranularity = time.Hour
+
+// DefaultPriceBuckets are the upper bounds of the analytics price buckets when none are configured
+var DefaultPriceBuckets = []float64{1000, 2500, 5000, 10000, 20000}
+
+// ArchivableStatuses are the booking statuses the retention job may archive (terminal only)
+var ArchivableStatuses = []string{BookingStatusCompleted, BookingStatusCancelled, BookingStatusFailed, BookingStatusExpired}
+
+// RetentionRunResult summarizes one run of the retention job
+type RetentionRunResult struct {
+	BookingsArchived int64     `json:"bookings_archived"`
+	AnalyticsWritten int64     `json:"analytics_written"`
+	AnalyticsPurged  int64     `json:"analytics_purged"`
+	ArchiveCutoff    time.Time `json:"archive_cutoff"`
+	Duration         string    `json:"duration"`
+}
+
+// PriceBucket returns the label of the bucket a price falls in
+// bounds must be sorted ascending; each bound is the exclusive upper limit of its bucket
+//
+// Example with bounds [1000, 5000]:
+//
+//	500   -> "0-1000"
+//	1000  -> "1000-5000"
+//	7500  -> "5000+"
+func PriceBucket(price float64, bounds []float64) string {
+	lower := 0.0
+	for _, upper := range bounds {
+		if price < upper {
+			return formatPrice(lower) + "-" + formatPrice(upper)
+		}
+		lower = upper
+	}
+	return formatPrice(lower) + "+"
+}
+
+// ToBookingAnalytics builds the anonymized analytical record of a booking
+// No user, booking or trip identifier is copied and timestamps are truncated
+func ToBookingAnalytics(b *dao.Booking, priceBuckets []float64, archivedAt time.Time) dao.BookingAnalytics {
+	return dao.BookingAnalytics{
+		Country:         b.Country,
+		OriginCity:      b.OriginCity,
+		DestinationCity: b.DestinationCity,
+		DistanceKm:      b.DistanceKm,
+		Seats:           b.SeatsRequested,
+		PriceBucket:     PriceBucket(b.TotalPrice, priceBuckets),
+		FinalStatus:     b.Status,
+		CO2SavedKg:      b.CO2SavedKg,
+		BookedAt:        b.CreatedAt.UTC().Truncate(AnalyticsTimeGranularity),
+		DepartureAt:     truncateAnalyticsTime(b.DepartureAt),
+		CancelledAt:     truncateAnalyticsTime(b.CancelledAt),
+		ArchivedAt:      archivedAt.UTC().Truncate(AnalyticsTimeGranularity),
+	}
+}
+
+func truncateAnalyticsTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	truncated := t.UTC().Truncate(AnalyticsTimeGranularity)
+	return &truncated
+}
+
+func formatPrice(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
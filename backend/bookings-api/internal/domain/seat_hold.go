@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// SeatHoldRequest is the body of POST /trips/:id/holds in trips-api
+// The held seats are subtracted from the trip until the hold is confirmed by reservation.created,
+// released, or expires after TTLSeconds
+type SeatHoldRequest struct {
+	ReservationID string `json:"reservation_id"`
+	PassengerID   int64  `json:"passenger_id"`
+	Seats         int    `json:"seats"`
+	TTLSeconds    int    `json:"ttl_seconds"`
+}
+
+// SeatHold is a seat hold created in trips-api for a booking
+type SeatHold struct {
+	ID        string    `json:"hold_id"`
+	TripID    string    `json:"trip_id"`
+	Seats     int       `json:"seats"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SeatHoldRelease is the outcome of releasing a seat hold in trips-api
+type SeatHoldRelease string
+
+const (
+	// SeatHoldReleased means the held seats were returned to the trip
+	SeatHoldReleased SeatHoldRelease = "released"
+	// SeatHoldGone means the hold was already released or expired (its seats are already back)
+	SeatHoldGone SeatHoldRelease = "gone"
+	// SeatHoldConfirmed means trips-api already turned the hold into a reservation:
+	// the seats must be released with reservation.cancelled
+	SeatHoldConfirmed SeatHoldRelease = "confirmed"
+)
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// SessionStatus is the account state users-api enforces on every request (GET /internal/users/:id/session-status)
+// The JWT middleware applies the same rules: a token issued before SessionsRevokedAt is no longer valid,
+// and a deactivated or banned account cannot operate while its token has not expired
+type SessionStatus struct {
+	UserID            int64      `json:"user_id"`
+	Active            bool       `json:"active"`
+	Banned            bool       `json:"banned"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at,omitempty"`
+}
+
+// CheckToken returns nil if a token issued at issuedAt (Unix seconds, "iat" claim) is still usable
+func (s *SessionStatus) CheckToken(issuedAt int64) error {
+	if s.SessionsRevokedAt != nil && issuedAt < s.SessionsRevokedAt.Unix() {
+		return ErrSessionRevoked
+	}
+	if !s.Active {
+		return ErrAccountInactive
+	}
+	if s.Banned {
+		return ErrAccountSuspended
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Smoke test statuses (GET /health/smoke)
+const (
+	SmokeStatusDisabled = "disabled" // SMOKE_TEST is not set
+	SmokeStatusRunning  = "running"
+	SmokeStatusPassed   = "passed"
+	SmokeStatusFailed   = "failed"
+)
+
+// Smoke test steps, in the order they run
+const (
+	SmokeStepCreateBooking     = "create_booking"     // POST path: seat hold, booking row, reservation.created
+	SmokeStepAwaitConfirmation = "await_confirmation" // trips-api answers with reservation.confirmed (and the payment is authorized)
+	SmokeStepCancelBooking     = "cancel_booking"     // Passenger cancellation: refund and reservation.cancelled
+)
+
+// SmokeCancellationReason is stored as the cancellation reason of the synthetic booking
+const SmokeCancellationReason = "Smoke test booking (automated environment check)"
+
+// SmokeStepResult is the outcome of one step of the smoke test
+type SmokeStepResult struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// SmokeTestResult is the report of the startup smoke test
+type SmokeTestResult struct {
+	Status     string            `json:"status"` // disabled, running, passed or failed
+	TripID     string            `json:"trip_id,omitempty"`
+	BookingID  string            `json:"booking_id,omitempty"` // Synthetic booking (cancelled at the end)
+	Steps      []SmokeStepResult `json:"steps"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	Duration   string            `json:"duration,omitempty"`
+}
+
+// Passed reports whether every step of the smoke test succeeded
+func (r *SmokeTestResult) Passed() bool {
+	return r.Status == SmokeStatusPassed
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "math"
+
+// CO2 estimation parameters
+//
+// The estimate assumes every shared seat replaces a solo car journey over the same route:
+//
+//	co2_saved_kg = route_distance_km * CarEmissionKgPerKm * seats
+//
+// Route distance is the great-circle distance between origin and destination
+// scaled by RoadDistanceFactor to approximate the real road distance.
+const (
+	// CarEmissionKgPerKm is the average CO2 emitted by a passenger car (kg per km)
+	CarEmissionKgPerKm = 0.12
+
+	// RoadDistanceFactor converts straight-line distance into an approximate road distance
+	RoadDistanceFactor = 1.3
+
+	earthRadiusKm = 6371.0
+)
+
+// Coordinates represents a geographic point from trips-api
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// CO2SavingsResponse represents the aggregate CO2 savings of a user's bookings
+// Only confirmed and completed bookings are counted
+type CO2SavingsResponse struct {
+	UserID          int64   `json:"user_id"`
+	BookingsCount   int64   `json:"bookings_count"`
+	SeatsShared     int64   `json:"seats_shared"`
+	TotalDistanceKm float64 `json:"total_distance_km"`
+	TotalCO2SavedKg float64 `json:"total_co2_saved_kg"`
+}
+
+// EstimateRouteDistanceKm estimates the road distance between two points in kilometers
+// Returns 0 if either point is missing
+func EstimateRouteDistanceKm(origin, destination *Coordinates) float64 {
+	if origin == nil || destination == nil {
+		return 0
+	}
+
+	lat1 := toRadians(origin.Lat)
+	lat2 := toRadians(destination.Lat)
+	dLat := lat2 - lat1
+	dLng := toRadians(destination.Lng - origin.Lng)
+
+	// Haversine formula
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	greatCircleKm := 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return roundTo2(greatCircleKm * RoadDistanceFactor)
+}
+
+// EstimateCO2SavedKg estimates the CO2 saved by sharing seats over a route distance
+func EstimateCO2SavedKg(distanceKm float64, seats int) float64 {
+	if distanceKm <= 0 || seats <= 0 {
+		return 0
+	}
+	return roundTo2(distanceKm * CarEmissionKgPerKm * float64(seats))
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -1,13 +1,38 @@
 package domain
 
+import "time"
+
 // Trip represents trip information from trips-api
 // Used for HTTP responses when validating bookings
 type Trip struct {
-	ID             string  `json:"id"`
-	DriverID       int64   `json:"driver_id"`
-	AvailableSeats int     `json:"available_seats"`
-	PricePerSeat   float64 `json:"price_per_seat"`
-	Status         string  `json:"status"`
+	ID                string            `json:"id"`
+	DriverID          int64             `json:"driver_id"`
+	Origin            TripLocation      `json:"origin"`
+	Destination       TripLocation      `json:"destination"`
+	PickupPoints      []TripPickupPoint `json:"pickup_points"`
+	DepartureDatetime time.Time         `json:"departure_datetime"`
+	AvailableSeats    int               `json:"available_seats"`
+	PricePerSeat      float64           `json:"price_per_seat"`
+	Status            string            `json:"status"`
+}
+
+// TripLocation represents the subset of a trips-api location used by bookings
+// Country is optional: trips without it resolve to the default country policy
+type TripLocation struct {
+	City     string `json:"city"`
+	Province string `json:"province"`
+	Country  string `json:"country,omitempty"`
+
+	// Coordinates are used to estimate the route distance (nil if trips-api omits them)
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+}
+
+// TripPickupPoint represents a driver-defined pickup point of a trip
+// Bookings store only the chosen point's ID
+type TripPickupPoint struct {
+	ID                string `json:"id"`
+	Label             string `json:"label"`
+	TimeOffsetMinutes int    `json:"time_offset_minutes"`
 }
 
 // Trip status constants
@@ -28,6 +53,22 @@ func (t *Trip) HasAvailableSeats(requested int) bool {
 	return t.AvailableSeats >= requested
 }
 
+// RouteDistanceKm estimates the road distance between origin and destination
+// Returns 0 if the trip has no coordinates
+func (t *Trip) RouteDistanceKm() float64 {
+	return EstimateRouteDistanceKm(t.Origin.Coordinates, t.Destination.Coordinates)
+}
+
+// HasPickupPoint checks if the trip defines a pickup point with the given ID
+func (t *Trip) HasPickupPoint(id string) bool {
+	for _, point := range t.PickupPoints {
+		if point.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // CalculateTotalPrice calculates the total price for requested seats
 func (t *Trip) CalculateTotalPrice(seats int) float64 {
 	return t.PricePerSeat * float64(seats)
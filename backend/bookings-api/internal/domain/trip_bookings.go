@@ -0,0 +1,67 @@
+package domain
+
+import "bookings-api/internal/dao"
+
+// PassengerContact holds the contact details of a passenger, as returned by users-api
+type PassengerContact struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Lastname string `json:"lastname"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+}
+
+// TripBooking is a booking as seen by the driver of its trip
+// Contact is only filled for bookings holding seats (see ExposesPassengerContact);
+// ContactUnavailable is set when users-api could not provide it. The passengers' documents
+// are only listed for confirmed bookings (see ExposesPassengerDocuments)
+type TripBooking struct {
+	BookingResponse
+	Contact            *PassengerContact `json:"contact,omitempty"`
+	ContactUnavailable bool              `json:"contact_unavailable,omitempty"`
+}
+
+// TripBookingsResponse lists the bookings of a trip for its driver, oldest first
+type TripBookingsResponse struct {
+	TripID         string         `json:"trip_id"`
+	Bookings       []TripBooking  `json:"bookings"`
+	Total          int            `json:"total"`
+	SeatsConfirmed int            `json:"seats_confirmed"`
+	Counts         map[string]int `json:"counts"` // Bookings per status (before the status filter)
+}
+
+// IsValidBookingStatus checks if a status is one of the booking status constants
+func IsValidBookingStatus(status string) bool {
+	switch status {
+	case BookingStatusPending, BookingStatusConfirmed, BookingStatusCancelled,
+		BookingStatusCompleted, BookingStatusFailed, BookingStatusExpired, BookingStatusAwaitingPayment:
+		return true
+	}
+	return false
+}
+
+// ExposesPassengerContact reports whether the driver may see the passenger's contact details
+// Only bookings that hold (or held) seats on the trip: a pending booking may still fail,
+// and passengers who cancelled no longer share their data with the driver
+func ExposesPassengerContact(status string) bool {
+	return status == BookingStatusConfirmed || status == BookingStatusCompleted
+}
+
+// ExposesPassengerDocuments reports whether the driver may see the ID documents of the seat passengers
+// Only confirmed bookings: the driver checks them at boarding, and once the trip is completed they are
+// no longer needed
+func ExposesPassengerDocuments(status string) bool {
+	return status == BookingStatusConfirmed
+}
+
+// ToTripBookingPassengers converts the passengers of a booking for its driver, without their
+// documents unless the booking exposes them
+func ToTripBookingPassengers(passengers []dao.BookingPassenger, status string) []BookingPassenger {
+	result := ToBookingPassengers(passengers)
+	if !ExposesPassengerDocuments(status) {
+		for i := range result {
+			result[i].Document = ""
+		}
+	}
+	return result
+}
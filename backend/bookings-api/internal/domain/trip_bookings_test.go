@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"bookings-api/internal/dao"
+)
+
+func TestToTripBookingPassengers_DocumentsOnlyForConfirmed(t *testing.T) {
+	passengers := []dao.BookingPassenger{
+		{SeatNumber: 1, Name: "Ana Pérez", Document: "30111222"},
+		{SeatNumber: 2, Name: "Luis Pérez", Document: "45111222"},
+	}
+
+	tests := []struct {
+		status        string
+		wantDocuments bool
+	}{
+		{BookingStatusConfirmed, true},
+		{BookingStatusPending, false},
+		{BookingStatusAwaitingPayment, false},
+		{BookingStatusCancelled, false},
+		{BookingStatusCompleted, false},
+		{BookingStatusExpired, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			got := ToTripBookingPassengers(passengers, tt.status)
+			if len(got) != len(passengers) {
+				t.Fatalf("got %d passengers, want %d", len(got), len(passengers))
+			}
+			for i, p := range got {
+				if p.Name != passengers[i].Name || p.SeatNumber != passengers[i].SeatNumber {
+					t.Errorf("passenger %d = %+v, want name and seat of %+v", i, p, passengers[i])
+				}
+				if hasDocument := p.Document != ""; hasDocument != tt.wantDocuments {
+					t.Errorf("passenger %d document = %q, want documents exposed: %v", i, p.Document, tt.wantDocuments)
+				}
+			}
+		})
+	}
+
+	// The passengers' own DAO rows are not modified
+	if passengers[0].Document != "30111222" {
+		t.Errorf("source passengers were modified: %+v", passengers[0])
+	}
+}
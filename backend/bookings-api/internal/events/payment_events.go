@@ -0,0 +1,48 @@
+package events
+
+// ============================================================================
+// PAYMENT EVENT TYPE CONSTANTS (Published by bookings-api)
+// ============================================================================
+// Progress of split-payment bookings, for notifications and the payment provider.
+// trips-api does not consume them (it only binds reservation.*).
+const (
+	// EventTypePaymentSharePaid - Published each time a passenger pays their share
+	EventTypePaymentSharePaid = "payment.share_paid"
+
+	// EventTypePaymentCompleted - Published when no share is left pending and the booking is confirmed
+	// AmountCovered > 0 means the organizer has to pay the shares that were not paid before the deadline
+	EventTypePaymentCompleted = "payment.completed"
+)
+
+// PaymentProgress is the payment state of a split-payment booking carried by payment events
+type PaymentProgress struct {
+	// ReservationID is the booking UUID from bookings-api
+	ReservationID string `json:"reservation_id"`
+
+	// TripID identifies the trip of the booking (MongoDB ObjectID from trips-api)
+	TripID string `json:"trip_id"`
+
+	// OrganizerID is the passenger who created the booking (and covers unpaid shares)
+	OrganizerID int64 `json:"organizer_id"`
+
+	// SeatNumber is the seat of the share just paid (payment.share_paid only)
+	SeatNumber int `json:"seat_number,omitempty"`
+
+	// SharesTotal, SharesPaid and SharesCovered count the shares of the booking
+	SharesTotal   int `json:"shares_total"`
+	SharesPaid    int `json:"shares_paid"`
+	SharesCovered int `json:"shares_covered"`
+
+	// TotalPrice is the fare of the booking; AmountPaid and AmountCovered add up to it once completed
+	TotalPrice    float64 `json:"total_price"`
+	AmountPaid    float64 `json:"amount_paid"`
+	AmountCovered float64 `json:"amount_covered"`
+}
+
+// PaymentProgressEvent is published on payment.share_paid and payment.completed
+type PaymentProgressEvent struct {
+	// Embed BaseEvent to inherit EventID, EventType, Timestamp
+	BaseEvent
+
+	PaymentProgress
+}
@@ -15,7 +15,8 @@ import (
 // Event Flow:
 //   1. User creates booking → reservation.created event published
 //   2. User cancels booking → reservation.cancelled event published
-//   3. trips-api consumes events and updates available seats
+//   3. User changes the seats of a booking → reservation.modified event published
+//   4. trips-api consumes events and updates available seats
 //
 // Event naming convention: <resource>.<action>
 const (
@@ -26,6 +27,10 @@ const (
 	// EventTypeReservationCancelled - Published when a booking is cancelled
 	// trips-api will increment available seats upon receiving this event
 	EventTypeReservationCancelled = "reservation.cancelled"
+
+	// EventTypeReservationModified - Published when the seats of a confirmed booking change
+	// trips-api will apply SeatsDelta to available seats upon receiving this event
+	EventTypeReservationModified = "reservation.modified"
 )
 
 // ============================================================================
@@ -96,6 +101,26 @@ type ReservationCreatedEvent struct {
 	// ReservationID is the booking UUID from bookings-api
 	// Used for tracking and debugging (links event to booking record)
 	ReservationID string `json:"reservation_id"`
+
+	// PickupPointID is the trip pickup point chosen by the passenger (optional)
+	// trips-api rejects the reservation if the point does not belong to the trip
+	PickupPointID string `json:"pickup_point_id,omitempty"`
+
+	// SeatHoldID is the seat hold taken in trips-api before the booking was created (optional)
+	// trips-api confirms the hold instead of decrementing available_seats again
+	SeatHoldID string `json:"seat_hold_id,omitempty"`
+
+	// Passengers lists who travels in each seat, so the driver can see who is coming (optional)
+	// When present it has exactly SeatsReserved entries
+	Passengers []ReservationPassenger `json:"passengers,omitempty"`
+}
+
+// ReservationPassenger is the passenger travelling in one seat of a reservation
+// The document number is not included: events are fanned out to every consumer of the exchange,
+// so it stays in bookings-api (the driver sees it in GET /trips/:trip_id/bookings)
+type ReservationPassenger struct {
+	SeatNumber int    `json:"seat_number"`
+	Name       string `json:"name"`
 }
 
 // ============================================================================
@@ -130,6 +155,59 @@ type ReservationCancelledEvent struct {
 	// ReservationID is the booking UUID from bookings-api
 	// Used for tracking and debugging (links event to booking record)
 	ReservationID string `json:"reservation_id"`
+
+	// CancellationFee is the late cancellation fee charged to the passenger
+	// 0 for free cancellations, driver cancellations and expired bookings
+	CancellationFee float64 `json:"cancellation_fee"`
+
+	// RefundAmount is the part of the total price refunded to the passenger
+	RefundAmount float64 `json:"refund_amount"`
+
+	// Currency is the ISO 4217 code of the fee and refund (omitted if unknown)
+	Currency string `json:"currency,omitempty"`
+}
+
+// ============================================================================
+// RESERVATION MODIFIED EVENT (Outbound from bookings-api)
+// ============================================================================
+
+// ReservationModifiedEvent is published when the seats of a confirmed booking change
+//
+// This event informs trips-api of a partial change to a reservation
+// (e.g., a passenger going from 3 seats to 1) without cancelling it.
+// trips-api will:
+//   1. Validate the event (check for duplicates using EventID)
+//   2. Apply SeatsDelta to available_seats (negative delta releases seats)
+//   3. Publish reservation.modification_failed if an increase cannot be satisfied
+//   4. ACK the message if successful
+//
+// Idempotency:
+// If trips-api receives the same EventID twice (e.g., due to retry),
+// it will skip processing to prevent applying the delta twice.
+type ReservationModifiedEvent struct {
+	// Embed BaseEvent to inherit EventID, EventType, Timestamp
+	BaseEvent
+
+	// TripID identifies which trip the reservation belongs to
+	// This is a MongoDB ObjectID (string) from trips-api
+	TripID string `json:"trip_id"`
+
+	// PassengerID identifies the passenger who modified the reservation
+	PassengerID int64 `json:"passenger_id"`
+
+	// ReservationID is the booking UUID from bookings-api
+	// Used for tracking and debugging (links event to booking record)
+	ReservationID string `json:"reservation_id"`
+
+	// PreviousSeats is the number of seats reserved before the change
+	PreviousSeats int `json:"previous_seats"`
+
+	// NewSeats is the number of seats reserved after the change
+	NewSeats int `json:"new_seats"`
+
+	// SeatsDelta is NewSeats - PreviousSeats
+	// Negative values release seats, positive values reserve additional seats
+	SeatsDelta int `json:"seats_delta"`
 }
 
 // ============================================================================
@@ -0,0 +1,277 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+
+	"bookings-api/internal/dao"
+	"bookings-api/internal/metrics"
+	"bookings-api/internal/repository"
+	"bookings-api/internal/service"
+	"bookings-api/internal/tracing"
+)
+
+// batchableEventTypes are the events grouped by the batch mode: they only write to the database,
+// and their side effects (status stream, refunds, seat holds) can run after the commit
+// reservation.confirmed charges payments while it is processed and reservation.modification_failed
+// is rare, so both are always processed on their own
+var batchableEventTypes = map[string]bool{
+	eventTypeTripCancelled:     true,
+	eventTypeReservationFailed: true,
+}
+
+// eventBatch is a run of consecutive deliveries of the same event type, in delivery order
+type eventBatch struct {
+	eventType  string
+	deliveries []amqp.Delivery
+}
+
+// consumeBatches is the consume loop of the batch mode (BatchSize > 1)
+// Events of a batchable type are buffered until the batch is full, BatchWaitMs have passed since its
+// first event or an event of another type arrives. Any other event flushes the pending batch and is
+// processed on its own, so events are still applied in delivery order
+func (c *TripsConsumer) consumeBatches(ctx context.Context, messages <-chan amqp.Delivery) error {
+	wait := time.Duration(c.topology.BatchWaitMs) * time.Millisecond
+
+	var batch eventBatch
+	var deadline <-chan time.Time // nil (never fires) while the batch is empty
+
+	flush := func() {
+		if len(batch.deliveries) > 0 {
+			c.handleBatch(ctx, batch)
+		}
+		batch = eventBatch{}
+		deadline = nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The pending batch is not ACKed: RabbitMQ redelivers it when the channel is closed
+			log.Info().
+				Int("pending_events", len(batch.deliveries)).
+				Msg("Consumer context cancelled, shutting down")
+			return nil
+
+		case <-deadline:
+			flush()
+
+		case msg, ok := <-messages:
+			if !ok {
+				log.Warn().Msg("Message channel closed, stopping consumer")
+				return fmt.Errorf("message channel closed")
+			}
+
+			eventType := c.EventType(msg.RoutingKey)
+			if !batchableEventTypes[eventType] {
+				flush()
+				c.handleMessage(ctx, msg)
+				continue
+			}
+
+			// Invalid payloads never join a batch: they are quarantined and ACKed on their own
+			if version, err := c.schemas.Validate(eventType, msg.Body); err != nil {
+				c.quarantine(msg, version, err)
+				continue
+			}
+
+			if batch.eventType != eventType {
+				flush()
+			}
+			if len(batch.deliveries) == 0 {
+				batch.eventType = eventType
+				deadline = time.After(wait)
+			}
+			batch.deliveries = append(batch.deliveries, msg)
+			if len(batch.deliveries) >= c.topology.BatchSize {
+				flush()
+			}
+		}
+	}
+}
+
+// handleBatch applies a batch in one transaction and ACKs all its deliveries together
+// When the transaction fails nothing of the batch is stored, and its events are processed one by one
+// (handleMessage), so an event that cannot be processed does not hold back the rest of the batch
+func (c *TripsConsumer) handleBatch(ctx context.Context, batch eventBatch) {
+	err := c.applyBatch(ctx, batch)
+	metrics.ObserveBatch(batch.eventType, len(batch.deliveries), err)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("event_type", batch.eventType).
+			Int("events", len(batch.deliveries)).
+			Msg("Batch transaction failed, processing its events one by one")
+		for _, msg := range batch.deliveries {
+			c.handleMessage(ctx, msg)
+		}
+		return
+	}
+
+	// A multiple ACK of the last delivery acknowledges the whole batch: the deliveries before it that
+	// are not part of the batch were already ACKed (or NACKed) on their own
+	// If the ACK is lost, the redelivered events are skipped by the idempotency check
+	last := batch.deliveries[len(batch.deliveries)-1]
+	if err := last.Ack(true); err != nil {
+		log.Error().
+			Err(err).
+			Str("event_type", batch.eventType).
+			Int("events", len(batch.deliveries)).
+			Msg("Failed to acknowledge batch")
+		return
+	}
+	for _, msg := range batch.deliveries {
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
+	}
+
+	log.Info().
+		Str("event_type", batch.eventType).
+		Int("events", len(batch.deliveries)).
+		Msg("Batch of events applied and acknowledged")
+}
+
+// applyBatch applies the events of a batch in a single database transaction with per-event idempotency:
+// the processed_events marks are written in the same transaction, so a rollback forgets them too
+// The side effects outside the database run only after the commit
+// Each event keeps its own consumer span, continuing the trace of its publish
+func (c *TripsConsumer) applyBatch(ctx context.Context, batch eventBatch) (err error) {
+	eventCtxs := make([]context.Context, len(batch.deliveries))
+	spans := make([]trace.Span, len(batch.deliveries))
+	for i, msg := range batch.deliveries {
+		eventCtxs[i], spans[i] = tracing.StartConsume(ctx, c.topology.Queue, msg)
+	}
+	defer func() {
+		for _, span := range spans {
+			tracing.End(span, err)
+		}
+	}()
+
+	var afterCommit []func()
+	err = c.transactor.Transaction(func(repos repository.TxRepositories) error {
+		afterCommit = nil
+		idempotency := service.NewIdempotencyService(repos.Events)
+
+		for i, msg := range batch.deliveries {
+			var effects []func()
+			var err error
+			switch batch.eventType {
+			case eventTypeTripCancelled:
+				effects, err = c.batchTripCancelled(eventCtxs[i], repos.Bookings, idempotency, msg.Body)
+			case eventTypeReservationFailed:
+				effects, err = c.batchReservationFailed(eventCtxs[i], repos.Bookings, idempotency, msg.Body)
+			default:
+				err = fmt.Errorf("%w: %s", ErrUnknownRoutingKey, msg.RoutingKey)
+			}
+			if err != nil {
+				return err
+			}
+			afterCommit = append(afterCommit, effects...)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, effect := range afterCommit {
+		effect()
+	}
+	return nil
+}
+
+// batchTripCancelled applies a trip.cancelled event inside a batch transaction
+// Unlike HandleTripCancelled, a booking that cannot be cancelled fails the whole batch
+// Returns the side effects to run once the batch is committed
+func (c *TripsConsumer) batchTripCancelled(ctx context.Context, bookingRepo repository.BookingRepository, idempotency service.IdempotencyService, body []byte) ([]func(), error) {
+	var event TripCancelledEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Error().
+			Err(err).
+			Str("raw_body", string(body)).
+			Msg("Failed to unmarshal trip.cancelled event")
+		// ACKed with the batch - malformed JSON can't be reprocessed
+		return nil, nil
+	}
+
+	shouldProcess, err := idempotency.CheckAndMarkEvent(event.EventID, event.EventType)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency check failed: %w", err)
+	}
+	if !shouldProcess {
+		return nil, nil
+	}
+
+	bookings, err := bookingRepo.FindByTripID(event.TripID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bookings of trip %s: %w", event.TripID, err)
+	}
+
+	cancellationReason := fmt.Sprintf("Trip cancelled by driver: %s", event.CancellationReason)
+	var effects []func()
+	for _, booking := range seatHoldingBookings(bookings) {
+		// Driver-initiated cancellations never charge the passenger a fee
+		if err := bookingRepo.CancelBooking(booking.BookingUUID, cancellationReason, 0); err != nil {
+			return nil, fmt.Errorf("failed to cancel booking %s: %w", booking.BookingUUID, err)
+		}
+		effects = append(effects, func() {
+			c.afterTripBookingCancelled(ctx, event.TripID, booking, cancellationReason)
+		})
+	}
+
+	log.Info().
+		Str("event_id", event.EventID).
+		Str("trip_id", event.TripID).
+		Str("correlation_id", event.CorrelationID).
+		Int("cancelled", len(effects)).
+		Msg("Applied trip.cancelled event in batch")
+
+	return effects, nil
+}
+
+// batchReservationFailed applies a reservation.failed event inside a batch transaction
+// Returns the side effects to run once the batch is committed
+func (c *TripsConsumer) batchReservationFailed(ctx context.Context, bookingRepo repository.BookingRepository, idempotency service.IdempotencyService, body []byte) ([]func(), error) {
+	var event ReservationFailedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Error().
+			Err(err).
+			Str("raw_body", string(body)).
+			Msg("Failed to unmarshal reservation.failed event")
+		// ACKed with the batch - malformed JSON can't be reprocessed
+		return nil, nil
+	}
+
+	shouldProcess, err := idempotency.CheckAndMarkEvent(event.EventID, event.EventType)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency check failed: %w", err)
+	}
+	if !shouldProcess {
+		return nil, nil
+	}
+
+	booking, err := findFailedReservationBooking(bookingRepo, &event)
+	if booking == nil || err != nil {
+		return nil, err
+	}
+
+	// The transition runs in a savepoint: if the booking changed concurrently only it is rolled back
+	err = bookingRepo.TransitionStatus(booking.BookingUUID, booking.Status, dao.BookingStatusFailed, nil, event.Reason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Msg("Booking status changed concurrently, ignoring reservation.failed")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update booking %s status: %w", booking.BookingUUID, err)
+	}
+
+	return []func(){func() { c.afterBookingFailed(ctx, &event, booking) }}, nil
+}
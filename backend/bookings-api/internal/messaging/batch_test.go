@@ -0,0 +1,256 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"gorm.io/gorm"
+
+	"bookings-api/internal/config"
+	"bookings-api/internal/dao"
+	"bookings-api/internal/repository"
+	"bookings-api/internal/schema"
+	"bookings-api/internal/service"
+)
+
+// fakeBookingRepo keeps bookings in memory and records the transitions to failed
+// Only the methods used by the reservation.failed handlers are implemented
+type fakeBookingRepo struct {
+	repository.BookingRepository
+	bookings map[string]dao.Booking
+	failed   []string // booking UUIDs moved to failed, in order
+}
+
+func (r *fakeBookingRepo) FindByID(id string) (*dao.Booking, error) {
+	booking, ok := r.bookings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &booking, nil
+}
+
+func (r *fakeBookingRepo) TransitionStatus(bookingUUID, fromStatus, toStatus string, fields map[string]interface{}, reason string) error {
+	booking, ok := r.bookings[bookingUUID]
+	if !ok || booking.Status != fromStatus {
+		return repository.ErrStatusChanged
+	}
+	booking.Status = toStatus
+	r.bookings[bookingUUID] = booking
+	r.failed = append(r.failed, bookingUUID)
+	return nil
+}
+
+// clone copies the repository so a transaction can be discarded
+func (r *fakeBookingRepo) clone() *fakeBookingRepo {
+	bookings := make(map[string]dao.Booking, len(r.bookings))
+	for id, booking := range r.bookings {
+		bookings[id] = booking
+	}
+	return &fakeBookingRepo{bookings: bookings, failed: append([]string(nil), r.failed...)}
+}
+
+// fakeEventRepo is an in-memory processed_events table
+type fakeEventRepo struct {
+	processed map[string]bool
+}
+
+func (r *fakeEventRepo) IsEventProcessed(eventID string) (bool, error) {
+	return r.processed[eventID], nil
+}
+
+func (r *fakeEventRepo) MarkEventAsSuccess(eventID, eventType string) error {
+	r.processed[eventID] = true
+	return nil
+}
+
+func (r *fakeEventRepo) MarkEventAsFailed(eventID, eventType, errorMsg string) error {
+	r.processed[eventID] = true
+	return nil
+}
+
+func (r *fakeEventRepo) clone() *fakeEventRepo {
+	processed := make(map[string]bool, len(r.processed))
+	for id := range r.processed {
+		processed[id] = true
+	}
+	return &fakeEventRepo{processed: processed}
+}
+
+// fakeTransactor runs the transaction on copies of the repositories and keeps them only on commit
+// commitErr makes every transaction fail at commit time, after fn ran
+type fakeTransactor struct {
+	bookings     *fakeBookingRepo
+	events       *fakeEventRepo
+	commitErr    error
+	transactions int
+}
+
+func (t *fakeTransactor) Transaction(fn func(repos repository.TxRepositories) error) error {
+	t.transactions++
+	txBookings, txEvents := t.bookings.clone(), t.events.clone()
+	if err := fn(repository.TxRepositories{Bookings: txBookings, Events: txEvents}); err != nil {
+		return err
+	}
+	if t.commitErr != nil {
+		return t.commitErr
+	}
+	*t.bookings, *t.events = *txBookings, *txEvents
+	return nil
+}
+
+// fakeAcknowledger records the ACKs and NACKs of the deliveries
+type fakeAcknowledger struct {
+	acks  []string // "<tag>" or "<tag>+multiple"
+	nacks []uint64
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	if multiple {
+		a.acks = append(a.acks, fmt.Sprintf("%d+multiple", tag))
+	} else {
+		a.acks = append(a.acks, fmt.Sprintf("%d", tag))
+	}
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacks = append(a.nacks, tag)
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.nacks = append(a.nacks, tag)
+	return nil
+}
+
+// Booking UUIDs of the fixtures (reservation_id must be a UUID to pass the schema)
+const (
+	bookingA = "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a0a"
+	bookingB = "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a0b"
+	bookingC = "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a0c"
+)
+
+func newBatchTestConsumer(t *testing.T, bookingIDs ...string) (*TripsConsumer, *fakeTransactor) {
+	t.Helper()
+
+	registry, err := schema.LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	bookings := &fakeBookingRepo{bookings: make(map[string]dao.Booking)}
+	for _, id := range bookingIDs {
+		bookings.bookings[id] = dao.Booking{BookingUUID: id, TripID: "656f1c2a9e3b4d4e5f8a9b0c", Status: dao.BookingStatusPending}
+	}
+	events := &fakeEventRepo{processed: make(map[string]bool)}
+	transactor := &fakeTransactor{bookings: bookings, events: events}
+
+	consumer := &TripsConsumer{
+		topology:           config.ConsumerTopology{Queue: "bookings.trips", BatchSize: 10},
+		eventTypes:         map[string]string{eventTypeReservationFailed: eventTypeReservationFailed},
+		bookingRepo:        bookings,
+		transactor:         transactor,
+		idempotencyService: service.NewIdempotencyService(events),
+		seatHolds:          service.NewSeatHoldService(nil, service.SeatHoldConfig{}),
+		statusHub:          service.NewBookingStatusHub(0),
+		schemas:            registry,
+	}
+	return consumer, transactor
+}
+
+func reservationFailedDelivery(ack amqp.Acknowledger, tag uint64, eventID, bookingID string) amqp.Delivery {
+	body := fmt.Sprintf(`{"event_id": %q, "event_type": "reservation.failed", "trip_id": "656f1c2a9e3b4d4e5f8a9b0c", "reservation_id": %q, "reason": "No seats available", "timestamp": "2025-12-15T12:00:00Z"}`, eventID, bookingID)
+	return amqp.Delivery{
+		Acknowledger: ack,
+		DeliveryTag:  tag,
+		RoutingKey:   eventTypeReservationFailed,
+		Body:         []byte(body),
+	}
+}
+
+func TestHandleBatch_AppliesAndAcksTogether(t *testing.T) {
+	consumer, transactor := newBatchTestConsumer(t, bookingA, bookingB)
+	ack := &fakeAcknowledger{}
+
+	consumer.handleBatch(context.Background(), eventBatch{
+		eventType: eventTypeReservationFailed,
+		deliveries: []amqp.Delivery{
+			reservationFailedDelivery(ack, 1, "e-1", bookingA),
+			reservationFailedDelivery(ack, 2, "e-2", bookingB),
+		},
+	})
+
+	if want := []string{bookingA, bookingB}; !reflect.DeepEqual(transactor.bookings.failed, want) {
+		t.Errorf("failed bookings = %v, want %v", transactor.bookings.failed, want)
+	}
+	if want := []string{"2+multiple"}; !reflect.DeepEqual(ack.acks, want) {
+		t.Errorf("acks = %v, want %v", ack.acks, want)
+	}
+	if transactor.transactions != 1 {
+		t.Errorf("transactions = %d, want 1", transactor.transactions)
+	}
+}
+
+func TestHandleBatch_FailedTransactionReplaysEachEventOnce(t *testing.T) {
+	consumer, transactor := newBatchTestConsumer(t, bookingA, bookingB, bookingC)
+	transactor.commitErr = errors.New("deadlock found when trying to get lock")
+	ack := &fakeAcknowledger{}
+
+	consumer.handleBatch(context.Background(), eventBatch{
+		eventType: eventTypeReservationFailed,
+		deliveries: []amqp.Delivery{
+			reservationFailedDelivery(ack, 1, "e-1", bookingA),
+			reservationFailedDelivery(ack, 2, "e-2", bookingB),
+			reservationFailedDelivery(ack, 3, "e-3", bookingC),
+		},
+	})
+
+	// The rolled back batch left nothing behind: each event was applied once, by the per-event path
+	if want := []string{bookingA, bookingB, bookingC}; !reflect.DeepEqual(transactor.bookings.failed, want) {
+		t.Errorf("failed bookings = %v, want %v", transactor.bookings.failed, want)
+	}
+	for _, eventID := range []string{"e-1", "e-2", "e-3"} {
+		if !transactor.events.processed[eventID] {
+			t.Errorf("event %s not marked as processed", eventID)
+		}
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(ack.acks, want) {
+		t.Errorf("acks = %v, want %v (one per event, no batch ACK)", ack.acks, want)
+	}
+	if len(ack.nacks) != 0 {
+		t.Errorf("nacks = %v, want none", ack.nacks)
+	}
+	if transactor.transactions != 1 {
+		t.Errorf("transactions = %d, want 1 (no batch retry)", transactor.transactions)
+	}
+}
+
+func TestHandleBatch_SkipsDuplicateEvents(t *testing.T) {
+	consumer, transactor := newBatchTestConsumer(t, bookingA, bookingB, bookingC)
+	transactor.events.processed["e-0"] = true // Processed before the batch (redelivery)
+	ack := &fakeAcknowledger{}
+
+	consumer.handleBatch(context.Background(), eventBatch{
+		eventType: eventTypeReservationFailed,
+		deliveries: []amqp.Delivery{
+			reservationFailedDelivery(ack, 1, "e-0", bookingC),
+			reservationFailedDelivery(ack, 2, "e-1", bookingA),
+			reservationFailedDelivery(ack, 3, "e-1", bookingA), // Same event twice in the batch
+			reservationFailedDelivery(ack, 4, "e-2", bookingB),
+		},
+	})
+
+	if want := []string{bookingA, bookingB}; !reflect.DeepEqual(transactor.bookings.failed, want) {
+		t.Errorf("failed bookings = %v, want %v", transactor.bookings.failed, want)
+	}
+	if status := transactor.bookings.bookings[bookingC].Status; status != dao.BookingStatusPending {
+		t.Errorf("bookingC status = %s, want %s (its event was already processed)", status, dao.BookingStatusPending)
+	}
+	if want := []string{"4+multiple"}; !reflect.DeepEqual(ack.acks, want) {
+		t.Errorf("acks = %v, want %v", ack.acks, want)
+	}
+}
@@ -2,38 +2,65 @@ package messaging
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 
+	"bookings-api/internal/config"
+	"bookings-api/internal/dao"
+	"bookings-api/internal/metrics"
 	"bookings-api/internal/repository"
+	"bookings-api/internal/schema"
 	"bookings-api/internal/service"
+	"bookings-api/internal/tracing"
 )
 
+// Event types handled by the consumer; the routing key of each one comes from the topology
 const (
-	exchangeName        = "trips.events"
-	exchangeType        = "topic"
-	queueName           = "bookings.trip-events"
-	prefetchCount       = 10
-	routingKeyCancelled = "trip.cancelled"
-	routingKeyFailed    = "reservation.failed"
-	routingKeyConfirmed = "reservation.confirmed"
+	eventTypeTripCancelled                 = "trip.cancelled"
+	eventTypeReservationFailed             = "reservation.failed"
+	eventTypeReservationConfirmed          = "reservation.confirmed"
+	eventTypeReservationModificationFailed = "reservation.modification_failed"
 )
 
+// ErrUnknownRoutingKey is returned by Dispatch for routing keys without a handler
+var ErrUnknownRoutingKey = errors.New("unknown routing key")
+
 // TripsConsumer handles RabbitMQ messages from trips-api
 type TripsConsumer struct {
 	conn               *amqp.Connection
 	channel            *amqp.Channel
+	topology           config.ConsumerTopology
+	eventTypes         map[string]string // routing key -> event type
 	bookingRepo        repository.BookingRepository
+	transactor         repository.Transactor // Batch mode: one transaction per batch
 	idempotencyService service.IdempotencyService
+	seatHolds          service.SeatHoldService
+	paymentSplits      service.PaymentSplitService
+	payments           service.PaymentService
+	credits            service.CreditsService
+	statusHub          service.BookingStatusHub
+	schemas            *schema.Registry
+	quarantineRepo     repository.QuarantineRepository
 }
 
 // NewTripsConsumer creates a new RabbitMQ consumer for trips events
 func NewTripsConsumer(
 	rabbitMQURL string,
+	topology config.ConsumerTopology,
 	bookingRepo repository.BookingRepository,
+	transactor repository.Transactor,
 	idempotencyService service.IdempotencyService,
+	seatHolds service.SeatHoldService,
+	paymentSplits service.PaymentSplitService,
+	payments service.PaymentService,
+	credits service.CreditsService,
+	statusHub service.BookingStatusHub,
+	schemas *schema.Registry,
+	quarantineRepo repository.QuarantineRepository,
 ) (*TripsConsumer, error) {
 	// Connect to RabbitMQ
 	conn, err := amqp.Dial(rabbitMQURL)
@@ -48,102 +75,35 @@ func NewTripsConsumer(
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
-	// Declare exchange (idempotent)
-	err = channel.ExchangeDeclare(
-		exchangeName, // name
-		exchangeType, // type
-		true,         // durable
-		false,        // auto-deleted
-		false,        // internal
-		false,        // no-wait
-		nil,          // arguments
-	)
-	if err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to declare exchange: %w", err)
-	}
-
-	// Declare queue (idempotent)
-	queue, err := channel.QueueDeclare(
-		queueName, // name
-		true,      // durable
-		false,     // delete when unused
-		false,     // exclusive
-		false,     // no-wait
-		nil,       // arguments
-	)
-	if err != nil {
+	if err := declareConsumerTopology(channel, topology); err != nil {
 		channel.Close()
 		conn.Close()
-		return nil, fmt.Errorf("failed to declare queue: %w", err)
-	}
-
-	// Bind queue to exchange for trip.cancelled events
-	err = channel.QueueBind(
-		queue.Name,          // queue name
-		routingKeyCancelled, // routing key
-		exchangeName,        // exchange
-		false,               // no-wait
-		nil,                 // arguments
-	)
-	if err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to bind queue for trip.cancelled: %w", err)
-	}
-
-	// Bind queue to exchange for reservation.failed events
-	err = channel.QueueBind(
-		queue.Name,       // queue name
-		routingKeyFailed, // routing key
-		exchangeName,     // exchange
-		false,            // no-wait
-		nil,              // arguments
-	)
-	if err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to bind queue for reservation.failed: %w", err)
-	}
-
-	// Bind queue to exchange for reservation.confirmed events
-	err = channel.QueueBind(
-		queue.Name,          // queue name
-		routingKeyConfirmed, // routing key
-		exchangeName,        // exchange
-		false,               // no-wait
-		nil,                 // arguments
-	)
-	if err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to bind queue for reservation.confirmed: %w", err)
-	}
-
-	// Set QoS prefetch count
-	err = channel.Qos(
-		prefetchCount, // prefetch count
-		0,             // prefetch size
-		false,         // global
-	)
-	if err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to set QoS: %w", err)
+		return nil, err
 	}
 
 	log.Info().
-		Str("exchange", exchangeName).
-		Str("queue", queueName).
-		Int("prefetch", prefetchCount).
+		Str("exchange", topology.Exchange).
+		Str("queue", topology.Queue).
+		Int("prefetch", topology.Prefetch).
+		Int("batch_size", topology.BatchSize).
+		Str("dead_letter_exchange", topology.DeadLetterExchange).
 		Msg("RabbitMQ consumer initialized successfully")
 
 	return &TripsConsumer{
 		conn:               conn,
 		channel:            channel,
+		topology:           topology,
+		eventTypes:         consumerEventTypes(topology.RoutingKeys),
 		bookingRepo:        bookingRepo,
+		transactor:         transactor,
 		idempotencyService: idempotencyService,
+		seatHolds:          seatHolds,
+		paymentSplits:      paymentSplits,
+		payments:           payments,
+		credits:            credits,
+		statusHub:          statusHub,
+		schemas:            schemas,
+		quarantineRepo:     quarantineRepo,
 	}, nil
 }
 
@@ -152,22 +112,26 @@ func NewTripsConsumer(
 func (c *TripsConsumer) Start(ctx context.Context) error {
 	// Start consuming messages
 	messages, err := c.channel.Consume(
-		queueName, // queue
-		"",        // consumer tag (auto-generated)
-		false,     // auto-ack (manual ACK for reliability)
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
+		c.topology.Queue, // queue
+		"",               // consumer tag (auto-generated)
+		false,            // auto-ack (manual ACK for reliability)
+		false,            // exclusive
+		false,            // no-local
+		false,            // no-wait
+		nil,              // args
 	)
 	if err != nil {
 		return fmt.Errorf("failed to start consuming: %w", err)
 	}
 
 	log.Info().
-		Str("queue", queueName).
+		Str("queue", c.topology.Queue).
 		Msg("Started consuming messages from RabbitMQ")
 
+	if c.topology.BatchSize > 1 {
+		return c.consumeBatches(ctx, messages)
+	}
+
 	// Process messages until context is cancelled
 	for {
 		select {
@@ -182,32 +146,40 @@ func (c *TripsConsumer) Start(ctx context.Context) error {
 			}
 
 			// Process message
-			c.handleMessage(msg)
+			c.handleMessage(ctx, msg)
 		}
 	}
 }
 
 // handleMessage processes a single RabbitMQ message
-func (c *TripsConsumer) handleMessage(msg amqp.Delivery) {
+// The consumer span continues the trace of the trips-api publish (traceparent header)
+func (c *TripsConsumer) handleMessage(ctx context.Context, msg amqp.Delivery) {
+	ctx, span := tracing.StartConsume(ctx, c.topology.Queue, msg)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
 	log.Debug().
 		Str("routing_key", msg.RoutingKey).
 		Str("correlation_id", msg.CorrelationId).
 		Msg("Received message")
 
+	// Validate the payload against its versioned JSON Schema before touching any handler
+	// Invalid messages are quarantined and ACKed: redelivering them would fail the same way
+	var version int
+	if version, err = c.schemas.Validate(c.EventType(msg.RoutingKey), msg.Body); err != nil {
+		c.quarantine(msg, version, err)
+		return
+	}
+
 	// Route to appropriate handler based on routing key
-	var err error
-	switch msg.RoutingKey {
-	case routingKeyCancelled:
-		err = c.HandleTripCancelled(msg.Body)
-	case routingKeyFailed:
-		err = c.HandleReservationFailed(msg.Body)
-	case routingKeyConfirmed:
-		err = c.HandleReservationConfirmed(msg.Body)
-	default:
+	err = c.Dispatch(ctx, msg.RoutingKey, msg.Body)
+	if errors.Is(err, ErrUnknownRoutingKey) {
 		log.Warn().
 			Str("routing_key", msg.RoutingKey).
 			Msg("Unknown routing key, acknowledging message")
 		msg.Ack(false) // ACK unknown messages to avoid blocking queue
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
+		err = nil // Not a processing failure for the span
 		return
 	}
 
@@ -221,11 +193,16 @@ func (c *TripsConsumer) handleMessage(msg amqp.Delivery) {
 
 		// NACK with requeue for system errors
 		// This allows retry in case of temporary failures (DB connection, etc.)
-		msg.Nack(false, true)
+		// With a dead-letter exchange, a redelivered message that fails again is dead-lettered
+		// instead, so a message that can never be processed does not loop forever
+		requeue := c.topology.DeadLetterExchange == "" || !msg.Redelivered
+		msg.Nack(false, requeue)
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeNack)
 		return
 	}
 
 	// ACK successful processing
+	metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
 	if err := msg.Ack(false); err != nil {
 		log.Error().
 			Err(err).
@@ -234,6 +211,87 @@ func (c *TripsConsumer) handleMessage(msg amqp.Delivery) {
 	}
 }
 
+// Dispatch runs the handler of a routing key
+// Used by handleMessage and by the admin reprocessing of quarantined messages
+func (c *TripsConsumer) Dispatch(ctx context.Context, routingKey string, body []byte) error {
+	switch c.EventType(routingKey) {
+	case eventTypeTripCancelled:
+		return c.HandleTripCancelled(ctx, body)
+	case eventTypeReservationFailed:
+		return c.HandleReservationFailed(ctx, body)
+	case eventTypeReservationConfirmed:
+		return c.HandleReservationConfirmed(ctx, body)
+	case eventTypeReservationModificationFailed:
+		return c.HandleReservationModificationFailed(ctx, body)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownRoutingKey, routingKey)
+	}
+}
+
+// EventType returns the event type bound to a routing key in the topology
+// (the schemas are registered by event type). Unknown routing keys are returned as is
+func (c *TripsConsumer) EventType(routingKey string) string {
+	if eventType, ok := c.eventTypes[routingKey]; ok {
+		return eventType
+	}
+	return routingKey
+}
+
+// quarantine stores a schema-invalid message and ACKs it
+// If the quarantine table cannot be written the message is requeued, so it is never lost
+func (c *TripsConsumer) quarantine(msg amqp.Delivery, version int, validationErr error) {
+	errorsJSON := "[]"
+	var schemaErr *schema.ValidationError
+	if errors.As(validationErr, &schemaErr) {
+		errorsJSON = schemaErr.ErrorsJSON()
+	}
+
+	// Best effort: the event_id helps finding the message, but the payload may not even be JSON
+	var envelope struct {
+		EventID string `json:"event_id"`
+	}
+	_ = json.Unmarshal(msg.Body, &envelope)
+	if len(envelope.EventID) > 64 {
+		envelope.EventID = envelope.EventID[:64]
+	}
+
+	record := &dao.QuarantinedMessage{
+		RoutingKey:       msg.RoutingKey,
+		EventID:          envelope.EventID,
+		CorrelationID:    msg.CorrelationId,
+		SchemaVersion:    version,
+		Payload:          string(msg.Body),
+		ValidationErrors: json.RawMessage(errorsJSON),
+		Status:           dao.QuarantineStatusQuarantined,
+	}
+	if err := c.quarantineRepo.Create(record); err != nil {
+		log.Error().
+			Err(err).
+			Str("routing_key", msg.RoutingKey).
+			Str("event_id", envelope.EventID).
+			Msg("Failed to quarantine invalid message, negative acknowledging")
+		msg.Nack(false, true)
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeNack)
+		return
+	}
+	metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeQuarantined)
+
+	log.Warn().
+		Err(validationErr).
+		Uint("quarantine_id", record.ID).
+		Str("routing_key", msg.RoutingKey).
+		Str("event_id", envelope.EventID).
+		Str("correlation_id", msg.CorrelationId).
+		Msg("Message failed schema validation, quarantined")
+
+	if err := msg.Ack(false); err != nil {
+		log.Error().
+			Err(err).
+			Str("routing_key", msg.RoutingKey).
+			Msg("Failed to acknowledge quarantined message")
+	}
+}
+
 // Close gracefully shuts down the consumer
 func (c *TripsConsumer) Close() error {
 	log.Info().Msg("Closing RabbitMQ consumer")
@@ -253,3 +311,85 @@ func (c *TripsConsumer) Close() error {
 
 	return nil
 }
+
+// consumerEventTypes maps every configured routing key to its event type
+func consumerEventTypes(keys config.ConsumerRoutingKeys) map[string]string {
+	return map[string]string{
+		keys.TripCancelled:                 eventTypeTripCancelled,
+		keys.ReservationFailed:             eventTypeReservationFailed,
+		keys.ReservationConfirmed:          eventTypeReservationConfirmed,
+		keys.ReservationModificationFailed: eventTypeReservationModificationFailed,
+	}
+}
+
+// declareConsumerTopology declares the exchange, the dead-letter exchange and queue (when configured)
+// and the consumer queue bound to every routing key, and sets the prefetch (all idempotent)
+func declareConsumerTopology(channel *amqp.Channel, topology config.ConsumerTopology) error {
+	err := channel.ExchangeDeclare(
+		topology.Exchange,     // name
+		topology.ExchangeType, // type
+		true,                  // durable
+		false,                 // auto-deleted
+		false,                 // internal
+		false,                 // no-wait
+		nil,                   // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange '%s': %w", topology.Exchange, err)
+	}
+
+	var queueArgs amqp.Table
+	if topology.DeadLetterExchange != "" {
+		err = channel.ExchangeDeclare(topology.DeadLetterExchange, topology.DeadLetterExchangeType, true, false, false, false, nil)
+		if err != nil {
+			return fmt.Errorf("failed to declare dead-letter exchange '%s': %w", topology.DeadLetterExchange, err)
+		}
+
+		if topology.DeadLetterQueue != "" {
+			if _, err := channel.QueueDeclare(topology.DeadLetterQueue, true, false, false, false, nil); err != nil {
+				return fmt.Errorf("failed to declare dead-letter queue '%s': %w", topology.DeadLetterQueue, err)
+			}
+			// Without a fixed routing key dead-lettered messages keep their original one
+			bindingKey := topology.DeadLetterRoutingKey
+			if bindingKey == "" {
+				bindingKey = "#"
+			}
+			if err := channel.QueueBind(topology.DeadLetterQueue, bindingKey, topology.DeadLetterExchange, false, nil); err != nil {
+				return fmt.Errorf("failed to bind dead-letter queue '%s': %w", topology.DeadLetterQueue, err)
+			}
+		}
+
+		queueArgs = amqp.Table{"x-dead-letter-exchange": topology.DeadLetterExchange}
+		if topology.DeadLetterRoutingKey != "" {
+			queueArgs["x-dead-letter-routing-key"] = topology.DeadLetterRoutingKey
+		}
+	}
+
+	queue, err := channel.QueueDeclare(
+		topology.Queue, // name
+		true,           // durable
+		false,          // delete when unused
+		false,          // exclusive
+		false,          // no-wait
+		queueArgs,      // arguments (dead-lettering)
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue '%s': %w", topology.Queue, err)
+	}
+
+	for _, routingKey := range []string{
+		topology.RoutingKeys.TripCancelled,
+		topology.RoutingKeys.ReservationFailed,
+		topology.RoutingKeys.ReservationConfirmed,
+		topology.RoutingKeys.ReservationModificationFailed,
+	} {
+		if err := channel.QueueBind(queue.Name, routingKey, topology.Exchange, false, nil); err != nil {
+			return fmt.Errorf("failed to bind queue for %s: %w", routingKey, err)
+		}
+	}
+
+	if err := channel.Qos(topology.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set QoS: %w", err)
+	}
+	return nil
+}
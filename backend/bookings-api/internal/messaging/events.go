@@ -49,3 +49,20 @@ type ReservationConfirmedEvent struct {
 	CorrelationID  string    `json:"correlation_id"`   // For request tracing
 	Timestamp      time.Time `json:"timestamp"`        // Event creation time
 }
+
+// ReservationModificationFailedEvent represents a seat increase that trips-api could not satisfy
+// Published in response to reservation.modified when the trip has no seats left for the delta
+type ReservationModificationFailedEvent struct {
+	EventID             string    `json:"event_id"`              // UUID for idempotency
+	EventType           string    `json:"event_type"`            // "reservation.modification_failed"
+	ModificationEventID string    `json:"modification_event_id"` // event_id of the rejected reservation.modified
+	ReservationID       string    `json:"reservation_id"`        // Booking UUID from bookings-api
+	TripID              string    `json:"trip_id"`               // MongoDB ObjectID
+	PreviousSeats       int       `json:"previous_seats"`        // Seats before the rejected change
+	RequestedSeats      int       `json:"requested_seats"`       // Seats the passenger asked for
+	Reason              string    `json:"reason"`                // Failure reason (e.g., "No seats available")
+	AvailableSeats      int       `json:"available_seats"`       // Current available seats
+	SourceService       string    `json:"source_service"`        // "trips-api"
+	CorrelationID       string    `json:"correlation_id"`        // For request tracing
+	Timestamp           time.Time `json:"timestamp"`             // Event creation time
+}
@@ -1,17 +1,22 @@
 package messaging
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/rs/zerolog/log"
 
 	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/repository"
+	"bookings-api/internal/service"
 )
 
 // HandleTripCancelled processes trip.cancelled events
-// Cancels all confirmed bookings for the cancelled trip
-func (c *TripsConsumer) HandleTripCancelled(body []byte) error {
+// Cancels all confirmed bookings (and those awaiting their payment shares) for the cancelled trip
+func (c *TripsConsumer) HandleTripCancelled(ctx context.Context, body []byte) error {
 	var event TripCancelledEvent
 	if err := json.Unmarshal(body, &event); err != nil {
 		log.Error().
@@ -59,13 +64,8 @@ func (c *TripsConsumer) HandleTripCancelled(body []byte) error {
 		return fmt.Errorf("failed to find bookings: %w", err)
 	}
 
-	// Filter only confirmed bookings (others might already be cancelled/failed)
-	confirmedBookings := make([]dao.Booking, 0)
-	for _, booking := range bookings {
-		if booking.Status == dao.BookingStatusConfirmed {
-			confirmedBookings = append(confirmedBookings, booking)
-		}
-	}
+	// Filter only bookings holding seats (others might already be cancelled/failed)
+	confirmedBookings := seatHoldingBookings(bookings)
 
 	if len(confirmedBookings) == 0 {
 		log.Info().
@@ -81,7 +81,8 @@ func (c *TripsConsumer) HandleTripCancelled(body []byte) error {
 	cancellationReason := fmt.Sprintf("Trip cancelled by driver: %s", event.CancellationReason)
 
 	for _, booking := range confirmedBookings {
-		err := c.bookingRepo.CancelBooking(booking.BookingUUID, cancellationReason)
+		// Driver-initiated cancellations never charge the passenger a fee
+		err := c.bookingRepo.CancelBooking(booking.BookingUUID, cancellationReason, 0)
 		if err != nil {
 			log.Error().
 				Err(err).
@@ -92,12 +93,7 @@ func (c *TripsConsumer) HandleTripCancelled(body []byte) error {
 			continue
 		}
 
-		log.Info().
-			Str("booking_id", booking.BookingUUID).
-			Str("trip_id", event.TripID).
-			Int64("passenger_id", booking.PassengerID).
-			Msg("Booking cancelled due to trip cancellation")
-
+		c.afterTripBookingCancelled(ctx, event.TripID, booking, cancellationReason)
 		cancelledCount++
 	}
 
@@ -117,9 +113,43 @@ func (c *TripsConsumer) HandleTripCancelled(body []byte) error {
 	return nil
 }
 
+// seatHoldingBookings filters the bookings a trip cancellation applies to: confirmed ones and those
+// awaiting their payment shares (others might already be cancelled/failed)
+func seatHoldingBookings(bookings []dao.Booking) []dao.Booking {
+	holding := make([]dao.Booking, 0, len(bookings))
+	for _, booking := range bookings {
+		if booking.Status == dao.BookingStatusConfirmed || booking.Status == dao.BookingStatusAwaitingPayment {
+			holding = append(holding, booking)
+		}
+	}
+	return holding
+}
+
+// afterTripBookingCancelled runs the side effects of a booking cancelled by its trip, once the
+// cancellation is stored: status stream and full refund
+// booking is the booking as it was before the cancellation
+func (c *TripsConsumer) afterTripBookingCancelled(ctx context.Context, tripID string, booking dao.Booking, reason string) {
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Str("trip_id", tripID).
+		Int64("passenger_id", booking.PassengerID).
+		Msg("Booking cancelled due to trip cancellation")
+	c.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, booking.Status, dao.BookingStatusCancelled, reason))
+
+	// Full refund: the booking is already cancelled, a failed refund is only logged
+	// The promotional credits go back to the passenger's balance, the rest of the fare to the payment method
+	c.credits.Release(ctx, &booking)
+	if err := c.payments.ReleasePayment(ctx, &booking, domain.CashRefund(booking.TotalPrice, 0, booking.CreditsApplied)); err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Msg("⚠️  Booking cancelled but failed to release its payment")
+	}
+}
+
 // HandleReservationFailed processes reservation.failed events
-// Updates booking status from pending to failed
-func (c *TripsConsumer) HandleReservationFailed(body []byte) error {
+// Updates booking status from pending to failed (bookings that are no longer pending are left untouched)
+func (c *TripsConsumer) HandleReservationFailed(ctx context.Context, body []byte) error {
 	var event ReservationFailedEvent
 	if err := json.Unmarshal(body, &event); err != nil {
 		log.Error().
@@ -157,8 +187,37 @@ func (c *TripsConsumer) HandleReservationFailed(body []byte) error {
 		return nil
 	}
 
-	// Find booking by reservation_id (booking_uuid)
-	booking, err := c.bookingRepo.FindByID(event.ReservationID)
+	// Find booking by reservation_id (booking_uuid); nil when it does not exist or is no longer pending
+	booking, err := findFailedReservationBooking(c.bookingRepo, &event)
+	if booking == nil || err != nil {
+		return err
+	}
+
+	// Update booking status to failed (pending → failed)
+	err = c.bookingRepo.TransitionStatus(booking.BookingUUID, booking.Status, dao.BookingStatusFailed, nil, event.Reason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Msg("Booking status changed concurrently, ignoring reservation.failed")
+		return nil
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", event.TripID).
+			Msg("Failed to update booking status to failed")
+		return fmt.Errorf("failed to update booking status: %w", err)
+	}
+
+	c.afterBookingFailed(ctx, &event, booking)
+	return nil
+}
+
+// findFailedReservationBooking returns the booking a reservation.failed event applies to,
+// or nil when there is none or it is no longer pending (the event is then just acknowledged)
+func findFailedReservationBooking(bookingRepo repository.BookingRepository, event *ReservationFailedEvent) (*dao.Booking, error) {
+	booking, err := bookingRepo.FindByID(event.ReservationID)
 	if err != nil {
 		// Idempotent behavior: if booking doesn't exist, ACK silently
 		// This handles race conditions where event arrives before booking creation
@@ -167,20 +226,24 @@ func (c *TripsConsumer) HandleReservationFailed(body []byte) error {
 			Str("reservation_id", event.ReservationID).
 			Str("trip_id", event.TripID).
 			Msg("Booking not found for failed reservation, acknowledging")
-		return nil
+		return nil, nil
 	}
 
-	// Update booking status to failed
-	err = c.bookingRepo.UpdateStatus(booking.BookingUUID, dao.BookingStatusFailed)
-	if err != nil {
-		log.Error().
-			Err(err).
+	// Saga: only a pending booking can fail (a cancelled booking stays cancelled)
+	if !domain.CanTransition(booking.Status, dao.BookingStatusFailed) {
+		log.Warn().
 			Str("booking_id", booking.BookingUUID).
-			Str("trip_id", event.TripID).
-			Msg("Failed to update booking status to failed")
-		return fmt.Errorf("failed to update booking status: %w", err)
+			Str("status", booking.Status).
+			Msg("Booking is no longer pending, ignoring reservation.failed")
+		return nil, nil
 	}
+	return booking, nil
+}
 
+// afterBookingFailed runs the side effects of a booking marked as failed by a reservation.failed event,
+// once the transition is stored: status stream and seat hold release
+// booking is the booking as it was before the transition
+func (c *TripsConsumer) afterBookingFailed(ctx context.Context, event *ReservationFailedEvent, booking *dao.Booking) {
 	log.Info().
 		Str("event_id", event.EventID).
 		Str("booking_id", booking.BookingUUID).
@@ -188,13 +251,24 @@ func (c *TripsConsumer) HandleReservationFailed(body []byte) error {
 		Int64("passenger_id", booking.PassengerID).
 		Str("reason", event.Reason).
 		Msg("Booking marked as failed due to reservation failure")
+	c.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, booking.Status, dao.BookingStatusFailed, event.Reason))
 
-	return nil
+	// trips-api normally drops the hold of a rejected reservation itself; releasing it here is idempotent
+	// and covers holds that were never linked to the reservation (best-effort, the hold TTL is the fallback)
+	if _, err := c.seatHolds.Release(ctx, booking); err != nil {
+		log.Warn().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Msg("Could not release seat hold of failed booking, trips-api will expire it")
+	}
 }
 
 // HandleReservationConfirmed processes reservation.confirmed events
-// Updates booking status from pending to confirmed and sets total price
-func (c *TripsConsumer) HandleReservationConfirmed(body []byte) error {
+// Updates booking status from pending to confirmed and sets total price and driver
+// Split-payment bookings go to awaiting_payment instead, with one payment share per passenger;
+// with a payment provider configured the booking also waits in awaiting_payment for the authorization
+// Bookings that are no longer pending are left untouched (see domain.CanTransition)
+func (c *TripsConsumer) HandleReservationConfirmed(ctx context.Context, body []byte) error {
 	var event ReservationConfirmedEvent
 	if err := json.Unmarshal(body, &event); err != nil {
 		log.Error().
@@ -246,13 +320,88 @@ func (c *TripsConsumer) HandleReservationConfirmed(body []byte) error {
 		return nil
 	}
 
-	// Update booking status to confirmed, set total price, and store driver_id
-	booking.Status = dao.BookingStatusConfirmed
-	booking.TotalPrice = event.TotalPrice
-	booking.DriverID = event.DriverID // Store driver for local authorization checks
+	// Saga: only a pending booking can be confirmed
+	// e.g. a booking cancelled while pending stays cancelled (its reservation.cancelled releases the seats)
+	if !domain.CanTransition(booking.Status, dao.BookingStatusConfirmed) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("status", booking.Status).
+			Msg("Booking is no longer pending, ignoring reservation.confirmed")
+		return nil
+	}
 
-	err = c.bookingRepo.Update(booking)
+	// Split payment: the booking is confirmed later, once its payment shares are settled
+	if booking.SplitPayment {
+		err := c.paymentSplits.StartSplitPayment(ctx, booking, event.TotalPrice, event.DriverID)
+		if errors.Is(err, repository.ErrStatusChanged) {
+			log.Warn().
+				Str("booking_id", booking.BookingUUID).
+				Msg("Booking status changed concurrently, ignoring reservation.confirmed")
+			return nil
+		}
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("booking_id", booking.BookingUUID).
+				Str("trip_id", event.TripID).
+				Float64("total_price", event.TotalPrice).
+				Msg("Failed to start split payment")
+			return fmt.Errorf("failed to start split payment: %w", err)
+		}
+		return nil
+	}
+
+	// Promotional credits pay part of the fare (idempotent by booking UUID in users-api)
+	// The event is already marked as processed, so they are released on every path that does not confirm the booking
+	creditsApplied := c.credits.Apply(ctx, booking, event.TotalPrice)
+	releaseCredits := func() {
+		booking.CreditsApplied = creditsApplied
+		c.credits.Release(ctx, booking)
+	}
+
+	// Payment provider: the booking is confirmed once the fare is authorized
+	// Bookings created before the provider was configured have no payment and are confirmed right away,
+	// as are bookings whose fare the credits cover entirely (the pending payment is voided below)
+	if c.payments.Enabled() && domain.AmountDue(event.TotalPrice, creditsApplied) > 0 {
+		err := c.payments.StartPayment(ctx, booking, event.TotalPrice, creditsApplied, event.DriverID)
+		switch {
+		case errors.Is(err, repository.ErrStatusChanged):
+			log.Warn().
+				Str("booking_id", booking.BookingUUID).
+				Msg("Booking status changed concurrently, ignoring reservation.confirmed")
+			releaseCredits()
+			return nil
+		case errors.Is(err, service.ErrBookingWithoutPayment):
+		case err != nil:
+			releaseCredits()
+			log.Error().
+				Err(err).
+				Str("booking_id", booking.BookingUUID).
+				Str("trip_id", event.TripID).
+				Float64("total_price", event.TotalPrice).
+				Msg("Failed to start payment")
+			return fmt.Errorf("failed to start payment: %w", err)
+		default:
+			return nil
+		}
+	}
+
+	// Update booking status to confirmed (pending → confirmed), set total price, and store driver_id
+	// driver_id is stored for local authorization checks
+	err = c.bookingRepo.TransitionStatus(booking.BookingUUID, booking.Status, dao.BookingStatusConfirmed, map[string]interface{}{
+		"total_price":     event.TotalPrice,
+		"credits_applied": creditsApplied,
+		"driver_id":       event.DriverID,
+	}, "Seats reserved by trips-api")
+	if errors.Is(err, repository.ErrStatusChanged) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Msg("Booking status changed concurrently, ignoring reservation.confirmed")
+		releaseCredits()
+		return nil
+	}
 	if err != nil {
+		releaseCredits()
 		log.Error().
 			Err(err).
 			Str("booking_id", booking.BookingUUID).
@@ -269,7 +418,108 @@ func (c *TripsConsumer) HandleReservationConfirmed(body []byte) error {
 		Int64("passenger_id", booking.PassengerID).
 		Int("seats_reserved", event.SeatsReserved).
 		Float64("total_price", event.TotalPrice).
+		Float64("credits_applied", creditsApplied).
 		Msg("✅ Booking confirmed successfully with price")
+	c.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, booking.Status, dao.BookingStatusConfirmed, "Seats reserved by trips-api"))
+
+	// Credits covered the whole fare: there is nothing to charge, void the pending provider payment
+	if c.payments.Enabled() && creditsApplied > 0 {
+		if err := c.payments.ReleasePayment(ctx, booking, 0); err != nil {
+			log.Error().
+				Err(err).
+				Str("booking_id", booking.BookingUUID).
+				Msg("⚠️  Booking paid with credits but failed to void its pending payment")
+		}
+	}
+
+	return nil
+}
+
+// HandleReservationModificationFailed processes reservation.modification_failed events
+// Reverts a seat increase that trips-api could not satisfy, restoring the previous seats and fare
+func (c *TripsConsumer) HandleReservationModificationFailed(ctx context.Context, body []byte) error {
+	var event ReservationModificationFailedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Error().
+			Err(err).
+			Str("raw_body", string(body)).
+			Msg("Failed to unmarshal reservation.modification_failed event")
+		// Return nil to ACK - malformed JSON can't be reprocessed
+		return nil
+	}
+
+	log.Info().
+		Str("event_id", event.EventID).
+		Str("event_type", event.EventType).
+		Str("modification_event_id", event.ModificationEventID).
+		Str("reservation_id", event.ReservationID).
+		Str("trip_id", event.TripID).
+		Str("correlation_id", event.CorrelationID).
+		Int("previous_seats", event.PreviousSeats).
+		Int("requested_seats", event.RequestedSeats).
+		Str("reason", event.Reason).
+		Msg("Processing reservation.modification_failed event")
+
+	// Check idempotency - skip if already processed
+	shouldProcess, err := c.idempotencyService.CheckAndMarkEvent(event.EventID, event.EventType)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("event_id", event.EventID).
+			Msg("Idempotency check failed")
+		return fmt.Errorf("idempotency check failed: %w", err)
+	}
+
+	if !shouldProcess {
+		log.Info().
+			Str("event_id", event.EventID).
+			Str("reservation_id", event.ReservationID).
+			Msg("Event already processed, skipping")
+		return nil
+	}
+
+	// Find booking by reservation_id (booking_uuid)
+	booking, err := c.bookingRepo.FindByID(event.ReservationID)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("reservation_id", event.ReservationID).
+			Str("trip_id", event.TripID).
+			Msg("Booking not found for failed modification, acknowledging")
+		return nil
+	}
+
+	// Revert to the previous seats, recalculating fare and CO2 savings
+	// Uses the requested seats as optimistic lock: if the booking changed again since, leave it as is
+	totalPrice := domain.RecalculateTotalPrice(booking.TotalPrice, booking.SeatsRequested, event.PreviousSeats)
+	co2SavedKg := domain.EstimateCO2SavedKg(booking.DistanceKm, event.PreviousSeats)
+	reason := fmt.Sprintf("Seat increase rejected by trips-api: %s", event.Reason)
+
+	err = c.bookingRepo.UpdateSeats(booking.BookingUUID, event.RequestedSeats, event.PreviousSeats, totalPrice, co2SavedKg, reason)
+	if errors.Is(err, repository.ErrSeatsChanged) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Int("current_seats", booking.SeatsRequested).
+			Int("requested_seats", event.RequestedSeats).
+			Msg("Booking seats changed since the rejected modification, not reverting")
+		return nil
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", event.TripID).
+			Msg("Failed to revert booking seats")
+		return fmt.Errorf("failed to revert booking seats: %w", err)
+	}
+
+	log.Info().
+		Str("event_id", event.EventID).
+		Str("booking_id", booking.BookingUUID).
+		Str("trip_id", event.TripID).
+		Int("seats", event.PreviousSeats).
+		Float64("total_price", totalPrice).
+		Msg("✅ Booking seats reverted after rejected modification")
 
 	return nil
 }
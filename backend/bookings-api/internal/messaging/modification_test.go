@@ -0,0 +1,72 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bookings-api/internal/dao"
+	"bookings-api/internal/repository"
+)
+
+// UpdateSeats applies a seat change if the booking still has expectedSeats (see BookingRepository.UpdateSeats)
+func (r *fakeBookingRepo) UpdateSeats(bookingUUID string, expectedSeats, newSeats int, totalPrice, co2SavedKg float64, reason string) error {
+	booking, ok := r.bookings[bookingUUID]
+	if !ok || booking.SeatsRequested != expectedSeats {
+		return repository.ErrSeatsChanged
+	}
+	booking.SeatsRequested = newSeats
+	booking.TotalPrice = totalPrice
+	booking.CO2SavedKg = co2SavedKg
+	r.bookings[bookingUUID] = booking
+	return nil
+}
+
+func modificationFailedBody(eventID, bookingID string, previousSeats, requestedSeats int) []byte {
+	return []byte(fmt.Sprintf(`{"event_id": %q, "event_type": "reservation.modification_failed", "reservation_id": %q, "trip_id": "656f1c2a9e3b4d4e5f8a9b0c", "previous_seats": %d, "requested_seats": %d, "reason": "No seats available"}`,
+		eventID, bookingID, previousSeats, requestedSeats))
+}
+
+func TestHandleReservationModificationFailed_RevertsTheIncrease(t *testing.T) {
+	consumer, transactor := newBatchTestConsumer(t)
+	transactor.bookings.bookings[bookingA] = dao.Booking{BookingUUID: bookingA, Status: dao.BookingStatusConfirmed, SeatsRequested: 3, TotalPrice: 4500}
+
+	if err := consumer.HandleReservationModificationFailed(context.Background(), modificationFailedBody("e-1", bookingA, 2, 3)); err != nil {
+		t.Fatalf("HandleReservationModificationFailed() error = %v", err)
+	}
+
+	booking := transactor.bookings.bookings[bookingA]
+	if booking.SeatsRequested != 2 || booking.TotalPrice != 3000 {
+		t.Errorf("booking = {seats %d, total %v}, want the previous {2, 3000}", booking.SeatsRequested, booking.TotalPrice)
+	}
+
+	// A redelivery of the same event is skipped
+	transactor.bookings.bookings[bookingA] = dao.Booking{BookingUUID: bookingA, Status: dao.BookingStatusConfirmed, SeatsRequested: 3, TotalPrice: 4500}
+	if err := consumer.HandleReservationModificationFailed(context.Background(), modificationFailedBody("e-1", bookingA, 2, 3)); err != nil {
+		t.Fatalf("redelivery error = %v", err)
+	}
+	if seats := transactor.bookings.bookings[bookingA].SeatsRequested; seats != 3 {
+		t.Errorf("seats = %d after a redelivery, want 3 (already processed)", seats)
+	}
+}
+
+func TestHandleReservationModificationFailed_KeepsALaterChange(t *testing.T) {
+	consumer, transactor := newBatchTestConsumer(t)
+	// The passenger changed the seats again (3 → 1) before trips-api rejected the 2 → 3 increase
+	transactor.bookings.bookings[bookingA] = dao.Booking{BookingUUID: bookingA, Status: dao.BookingStatusConfirmed, SeatsRequested: 1, TotalPrice: 1500}
+
+	if err := consumer.HandleReservationModificationFailed(context.Background(), modificationFailedBody("e-1", bookingA, 2, 3)); err != nil {
+		t.Fatalf("HandleReservationModificationFailed() error = %v", err)
+	}
+	if booking := transactor.bookings.bookings[bookingA]; booking.SeatsRequested != 1 || booking.TotalPrice != 1500 {
+		t.Errorf("booking = {seats %d, total %v}, want the later change kept", booking.SeatsRequested, booking.TotalPrice)
+	}
+}
+
+func TestHandleReservationModificationFailed_UnknownBookingIsAcked(t *testing.T) {
+	consumer, _ := newBatchTestConsumer(t)
+
+	if err := consumer.HandleReservationModificationFailed(context.Background(), modificationFailedBody("e-1", bookingB, 2, 3)); err != nil {
+		t.Errorf("HandleReservationModificationFailed(unknown booking) = %v, want nil (ACK)", err)
+	}
+}
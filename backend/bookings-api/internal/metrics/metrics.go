@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Consume outcomes of a RabbitMQ message
+const (
+	ConsumeAck         = "ack"         // Processed (or unknown routing key) and acknowledged
+	ConsumeNack        = "nack"        // Failed and requeued
+	ConsumeQuarantined = "quarantined" // Failed schema validation, stored and acknowledged
+)
+
+// unmatchedRoute groups requests that match no route, so unknown paths do not create series
+const unmatchedRoute = "unmatched"
+
+// Prometheus metrics of the service, exposed on GET /metrics
+// Names are shared by the four services; Prometheus tells them apart by job
+// Database latencies come from the QueryMetrics GORM plugin (see database.QueryMetrics.Collect)
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by route",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	rabbitPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_messages_published_total",
+		Help: "Events published to RabbitMQ by routing key and result (after retries)",
+	}, []string{"routing_key", "result"})
+
+	rabbitConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_messages_consumed_total",
+		Help: "Messages consumed from RabbitMQ by routing key and outcome",
+	}, []string{"routing_key", "result"})
+
+	rabbitBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rabbitmq_consumer_batch_size",
+		Help:    "Events per consumer batch by event type and result (committed / fallback to one by one)",
+		Buckets: []float64{1, 2, 5, 10, 20, 50, 100},
+	}, []string{"event_type", "result"})
+)
+
+// Middleware records the duration of every request labeled by route template (/api/v1/bookings/:id),
+// never the concrete path, so there is no series per booking
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		httpRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// ObservePublish counts an event published to RabbitMQ (result: ok / error)
+func ObservePublish(routingKey string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	rabbitPublished.WithLabelValues(routingKey, result).Inc()
+}
+
+// ObserveConsume counts a consumed RabbitMQ message with its outcome (ConsumeAck, ConsumeNack, ConsumeQuarantined)
+func ObserveConsume(routingKey, outcome string) {
+	rabbitConsumed.WithLabelValues(routingKey, outcome).Inc()
+}
+
+// ObserveBatch records the size of a consumer batch (result: committed / fallback when its transaction failed)
+func ObserveBatch(eventType string, size int, err error) {
+	result := "committed"
+	if err != nil {
+		result = "fallback"
+	}
+	rabbitBatchSize.WithLabelValues(eventType, result).Observe(float64(size))
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"bookings-api/internal/domain"
 	"bookings-api/internal/service"
+	"errors"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -10,7 +13,8 @@ import (
 )
 
 // AuthMiddleware valida el token JWT y extrae los claims al contexto
-func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+// Besides the signature, the account is checked against users-api (revoked sessions, deactivated or banned)
+func AuthMiddleware(authService service.AuthService, sessionService service.SessionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Obtener el header Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -108,10 +112,38 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 				return
 			}
 
+			// Tokens issued before the iat claim was added count as issued at the epoch
+			issuedAt, _ := claims["iat"].(float64)
+			if err := sessionService.CheckSession(c.Request.Context(), int64(userIDFloat), int64(issuedAt)); err != nil {
+				log.Warn().
+					Err(err).
+					Int64("user_id", int64(userIDFloat)).
+					Str("path", c.Request.URL.Path).
+					Msg("Session check failed")
+				status, message := sessionErrorResponse(err)
+				c.JSON(status, gin.H{
+					"success": false,
+					"error":   message,
+				})
+				c.Abort()
+				return
+			}
+
+			// Dependent accounts carry their restrictions (ej: booking_requires_approval)
+			var restrictions []string
+			if rawRestrictions, ok := claims["restrictions"].([]interface{}); ok {
+				for _, r := range rawRestrictions {
+					if restriction, ok := r.(string); ok {
+						restrictions = append(restrictions, restriction)
+					}
+				}
+			}
+
 			// Guardar claims en el contexto
 			c.Set("user_id", int64(userIDFloat))
 			c.Set("email", email)
 			c.Set("role", role)
+			c.Set("restrictions", restrictions)
 
 			log.Debug().
 				Int64("user_id", int64(userIDFloat)).
@@ -134,3 +166,17 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// sessionErrorResponse maps a SessionService error to the status and message users-api uses
+func sessionErrorResponse(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrSessionRevoked):
+		return http.StatusUnauthorized, "la sesión fue cerrada, inicia sesión nuevamente"
+	case errors.Is(err, domain.ErrAccountInactive):
+		return http.StatusForbidden, "la cuenta está desactivada"
+	case errors.Is(err, domain.ErrAccountSuspended):
+		return http.StatusForbidden, "la cuenta está suspendida"
+	default:
+		return http.StatusServiceUnavailable, "no se pudo verificar la sesión, intenta nuevamente"
+	}
+}
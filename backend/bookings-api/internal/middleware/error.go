@@ -61,15 +61,21 @@ func ErrorHandler() gin.HandlerFunc {
 // mapErrorCodeToHTTPStatus maps AppError codes to HTTP status codes
 func mapErrorCodeToHTTPStatus(code string) int {
 	switch code {
-	case "BOOKING_NOT_FOUND", "TRIP_NOT_FOUND":
+	case "BOOKING_NOT_FOUND", "TRIP_NOT_FOUND", "BOOKING_NOT_YET_CREATED", "STATUS_HISTORY_UNAVAILABLE", "QUARANTINED_MESSAGE_NOT_FOUND", "USER_NOT_FOUND", "PAYMENT_SHARE_NOT_FOUND", "PAYMENT_NOT_FOUND", "ADMIN_APPROVAL_NOT_FOUND", "GUARDIAN_APPROVAL_NOT_FOUND":
 		return http.StatusNotFound
 	case "UNAUTHORIZED":
 		return http.StatusUnauthorized // 401
-	case "DUPLICATE_BOOKING":
+	case "ADMIN_APPROVAL_SELF_APPROVAL", "NOT_DEPENDENT_ACCOUNT":
+		return http.StatusForbidden
+	case "DUPLICATE_BOOKING", "BOOKING_MODIFIED_CONCURRENTLY", "QUARANTINED_MESSAGE_RESOLVED", "PAYMENT_SHARE_NOT_PAYABLE", "PAYMENT_STATUS_CONFLICT", "ADMIN_APPROVAL_NOT_PENDING", "ADMIN_APPROVAL_EXPIRED", "ADMIN_APPROVAL_ALREADY_PENDING":
 		return http.StatusConflict
-	case "VALIDATION_ERROR", "INSUFFICIENT_SEATS", "CANNOT_BOOK_OWN_TRIP", "INVALID_INPUT", "TRIP_NOT_PUBLISHED", "CANNOT_CANCEL_COMPLETED", "BOOKING_ALREADY_CANCELLED":
+	case "SCHEMA_VALIDATION_FAILED":
+		return http.StatusUnprocessableEntity
+	case "VALIDATION_ERROR", "INSUFFICIENT_SEATS", "CANNOT_BOOK_OWN_TRIP", "INVALID_INPUT", "TRIP_NOT_PUBLISHED", "CANNOT_CANCEL_COMPLETED", "BOOKING_ALREADY_CANCELLED", "BOOKING_EXPIRED", "MAX_SEATS_EXCEEDED", "INVALID_PICKUP_POINT", "BOOKING_NOT_MODIFIABLE", "SEATS_UNCHANGED", "PASSENGER_COUNT_MISMATCH", "SEATS_FIXED_BY_PASSENGERS", "SPLIT_PAYMENT_REQUIRES_PASSENGERS", "BOOKING_NOT_SPLIT_PAYMENT", "ADMIN_ACTION_NOT_APPLICABLE":
 		return http.StatusBadRequest
-	case "TRIPS_API_UNAVAILABLE":
+	case "TOO_MANY_STREAMS":
+		return http.StatusTooManyRequests
+	case "TRIPS_API_UNAVAILABLE", "USERS_API_UNAVAILABLE", "PAYMENT_PROVIDER_UNAVAILABLE", "SERVICE_OVERLOADED":
 		return http.StatusServiceUnavailable
 	default:
 		return http.StatusInternalServerError
@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bookings-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Load shedding modes (manual override flag)
+const (
+	LoadSheddingAuto = "auto" // Shed based on p99 latency and DB pool saturation
+	LoadSheddingOn   = "on"   // Always shed low-priority requests
+	LoadSheddingOff  = "off"  // Never shed
+)
+
+// Request priorities used by the load shedder
+const (
+	PriorityLow      = "low"      // List queries - shed first under overload
+	PriorityNormal   = "normal"   // Single-resource reads and admin diagnostics
+	PriorityCritical = "critical" // Booking creation, cancellation and seat changes - never shed
+)
+
+// StreamRoute is the live booking status stream (SSE): never shed, and excluded from the latency window
+const StreamRoute = "/api/v1/bookings/:id/stream"
+
+// latencyWindowSize is the number of recent request latencies used to estimate p99
+const latencyWindowSize = 512
+
+// latencyMaxAge discards old samples so a past spike does not keep the service shedding
+// (while shedding, low-priority requests add no new samples)
+const latencyMaxAge = 30 * time.Second
+
+// latencySample is a request latency and the moment it was recorded
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// LoadSheddingConfig holds the thresholds of the load shedder
+type LoadSheddingConfig struct {
+	Mode                 string        // auto, on or off
+	P99Threshold         time.Duration // Overloaded when the p99 of recent requests exceeds this
+	DBPoolSaturation     float64       // Overloaded when in-use / max open connections exceeds this (0-1)
+	RetryAfter           time.Duration // Value sent in the Retry-After header
+	EvaluationInterval   time.Duration // How often the overload state is recomputed
+	MinSamplesForLatency int           // Minimum samples before p99 is taken into account
+}
+
+// LoadSheddingStats is a snapshot of the load shedder state and counters
+type LoadSheddingStats struct {
+	Mode             string  `json:"mode"`
+	Overloaded       bool    `json:"overloaded"`
+	Reason           string  `json:"reason,omitempty"`
+	P99LatencyMs     int64   `json:"p99_latency_ms"`
+	P99ThresholdMs   int64   `json:"p99_threshold_ms"`
+	DBPoolSaturation float64 `json:"db_pool_saturation"`
+	DBPoolThreshold  float64 `json:"db_pool_threshold"`
+	RequestsTotal    int64   `json:"requests_total"`
+	RequestsShed     int64   `json:"requests_shed"`
+	RetryAfterSec    int     `json:"retry_after_seconds"`
+}
+
+// LoadShedder rejects low-priority requests with 503 + Retry-After when the service is overloaded
+//
+// Overload is detected from:
+//   - p99 latency of the last requests (rolling window)
+//   - DB connection pool saturation (sql.DBStats)
+//
+// Booking creation, cancellation and seat changes are never shed. The mode can be overridden
+// manually (on/off) through config or the admin endpoint.
+type LoadShedder struct {
+	cfg     LoadSheddingConfig
+	dbStats func() sql.DBStats
+
+	mu         sync.Mutex
+	mode       string
+	latencies  []latencySample
+	next       int
+	overloaded bool
+	reason     string
+	p99        time.Duration
+	saturation float64
+	lastEval   time.Time
+
+	requestsTotal int64
+	requestsShed  int64
+}
+
+// NewLoadShedder creates a new LoadShedder
+// dbStats may be nil (DB pool saturation is then ignored)
+func NewLoadShedder(cfg LoadSheddingConfig, dbStats func() sql.DBStats) *LoadShedder {
+	if cfg.EvaluationInterval <= 0 {
+		cfg.EvaluationInterval = time.Second
+	}
+	if cfg.MinSamplesForLatency <= 0 {
+		cfg.MinSamplesForLatency = 50
+	}
+	if !isValidLoadSheddingMode(cfg.Mode) {
+		cfg.Mode = LoadSheddingAuto
+	}
+
+	return &LoadShedder{
+		cfg:       cfg,
+		dbStats:   dbStats,
+		mode:      cfg.Mode,
+		latencies: make([]latencySample, 0, latencyWindowSize),
+	}
+}
+
+// Middleware returns the Gin middleware that classifies and sheds requests
+// Must be registered after ErrorHandler so the 503 is rendered as a standard error
+func (ls *LoadShedder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		atomic.AddInt64(&ls.requestsTotal, 1)
+
+		priority := RequestPriority(c.Request.Method, c.FullPath())
+		if priority == PriorityLow && ls.shouldShed() {
+			atomic.AddInt64(&ls.requestsShed, 1)
+
+			retryAfter := int(ls.cfg.RetryAfter.Seconds())
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.Error(domain.ErrServiceOverloaded.WithDetails(gin.H{
+				"retry_after_seconds": retryAfter,
+			}))
+			c.Abort()
+
+			log.Warn().
+				Str("path", c.Request.URL.Path).
+				Str("method", c.Request.Method).
+				Msg("Request shed due to overload")
+			return
+		}
+
+		// Long-lived streams would push p99 over any threshold: their duration is not a latency
+		if c.FullPath() == StreamRoute {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+		ls.recordLatency(time.Since(start))
+	}
+}
+
+// RequestPriority classifies a request by method and route template
+func RequestPriority(method, route string) string {
+	switch {
+	case method == http.MethodPost && route == "/api/v1/bookings":
+		return PriorityCritical
+	case method == http.MethodPatch && (route == "/api/v1/bookings/:id/cancel" || route == "/api/v1/bookings/:id/seats"):
+		return PriorityCritical
+	case method == http.MethodGet && (route == "/api/v1/bookings" || route == "/api/v1/admin/bookings"):
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+// SetMode changes the manual override flag (auto, on, off)
+func (ls *LoadShedder) SetMode(mode string) error {
+	if !isValidLoadSheddingMode(mode) {
+		return fmt.Errorf("invalid load shedding mode %q (expected auto, on or off)", mode)
+	}
+
+	ls.mu.Lock()
+	ls.mode = mode
+	ls.lastEval = time.Time{} // force re-evaluation
+	ls.mu.Unlock()
+
+	log.Info().Str("mode", mode).Msg("Load shedding mode changed")
+	return nil
+}
+
+// Stats returns a snapshot of the load shedder state and counters
+func (ls *LoadShedder) Stats() LoadSheddingStats {
+	ls.mu.Lock()
+	ls.evaluateLocked(time.Now())
+	stats := LoadSheddingStats{
+		Mode:             ls.mode,
+		Overloaded:       ls.overloaded,
+		Reason:           ls.reason,
+		P99LatencyMs:     ls.p99.Milliseconds(),
+		P99ThresholdMs:   ls.cfg.P99Threshold.Milliseconds(),
+		DBPoolSaturation: ls.saturation,
+		DBPoolThreshold:  ls.cfg.DBPoolSaturation,
+		RetryAfterSec:    int(ls.cfg.RetryAfter.Seconds()),
+	}
+	ls.mu.Unlock()
+
+	stats.RequestsTotal = atomic.LoadInt64(&ls.requestsTotal)
+	stats.RequestsShed = atomic.LoadInt64(&ls.requestsShed)
+	return stats
+}
+
+// shouldShed reports whether low-priority requests must be rejected right now
+func (ls *LoadShedder) shouldShed() bool {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.evaluateLocked(time.Now())
+	return ls.overloaded
+}
+
+// recordLatency adds a request latency to the rolling window
+func (ls *LoadShedder) recordLatency(d time.Duration) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	sample := latencySample{at: time.Now(), duration: d}
+	if len(ls.latencies) < latencyWindowSize {
+		ls.latencies = append(ls.latencies, sample)
+		return
+	}
+	ls.latencies[ls.next] = sample
+	ls.next = (ls.next + 1) % latencyWindowSize
+}
+
+// evaluateLocked recomputes the overload state at most once per EvaluationInterval
+// Caller must hold ls.mu
+func (ls *LoadShedder) evaluateLocked(now time.Time) {
+	if now.Sub(ls.lastEval) < ls.cfg.EvaluationInterval {
+		return
+	}
+	ls.lastEval = now
+
+	recent := make([]time.Duration, 0, len(ls.latencies))
+	for _, sample := range ls.latencies {
+		if now.Sub(sample.at) <= latencyMaxAge {
+			recent = append(recent, sample.duration)
+		}
+	}
+	ls.p99 = percentile(recent, 0.99)
+	ls.saturation = 0
+	if ls.dbStats != nil {
+		stats := ls.dbStats()
+		if stats.MaxOpenConnections > 0 {
+			ls.saturation = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+		}
+	}
+
+	wasOverloaded := ls.overloaded
+	switch ls.mode {
+	case LoadSheddingOn:
+		ls.overloaded, ls.reason = true, "manual override"
+	case LoadSheddingOff:
+		ls.overloaded, ls.reason = false, ""
+	default:
+		switch {
+		case ls.cfg.P99Threshold > 0 && len(recent) >= ls.cfg.MinSamplesForLatency && ls.p99 > ls.cfg.P99Threshold:
+			ls.overloaded, ls.reason = true, "p99 latency above threshold"
+		case ls.cfg.DBPoolSaturation > 0 && ls.saturation >= ls.cfg.DBPoolSaturation:
+			ls.overloaded, ls.reason = true, "database pool saturated"
+		default:
+			ls.overloaded, ls.reason = false, ""
+		}
+	}
+
+	if ls.overloaded != wasOverloaded {
+		log.Warn().
+			Bool("overloaded", ls.overloaded).
+			Str("reason", ls.reason).
+			Dur("p99", ls.p99).
+			Float64("db_pool_saturation", ls.saturation).
+			Msg("Load shedding state changed")
+	}
+}
+
+// percentile returns the p-th percentile (0-1) of the given latencies (sorts the slice in place)
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(p*float64(len(sorted))+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func isValidLoadSheddingMode(mode string) bool {
+	return mode == LoadSheddingAuto || mode == LoadSheddingOn || mode == LoadSheddingOff
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentWebhookSecretHeader is the header the payment provider sends the shared secret in
+const PaymentWebhookSecretHeader = "X-Payment-Webhook-Secret"
+
+// RequirePaymentWebhookSecret valida que la llamada venga del proveedor de pagos
+// Sin secreto configurado el webhook queda deshabilitado (503)
+func RequirePaymentWebhookSecret(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "webhook de pagos no configurado",
+			})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader(PaymentWebhookSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "secreto del webhook de pagos inválido",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
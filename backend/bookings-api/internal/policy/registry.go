@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"bookings-api/internal/domain"
+)
+
+// Registry resolves the booking policy for a country
+//
+// Policies are loaded once at startup (from a JSON file or the built-in defaults)
+// and are read-only afterwards, so the registry is safe for concurrent use.
+type Registry interface {
+	// Resolve returns the policy for a country and the resolution source
+	// Unknown or empty countries resolve to the default country's policy
+	Resolve(country string) (domain.CountryPolicy, string)
+}
+
+// registry implements Registry with an in-memory map
+type registry struct {
+	policies       map[string]domain.CountryPolicy
+	defaultCountry string
+}
+
+// NewRegistry creates a registry from a list of policies
+// Returns an error if a policy is invalid, duplicated, or the default country has no policy
+func NewRegistry(defaultCountry string, policies []domain.CountryPolicy) (Registry, error) {
+	r := &registry{
+		policies:       make(map[string]domain.CountryPolicy, len(policies)),
+		defaultCountry: normalizeCountry(defaultCountry),
+	}
+
+	for _, p := range policies {
+		p.Country = normalizeCountry(p.Country)
+		if err := p.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid country policy: %w", err)
+		}
+		if _, exists := r.policies[p.Country]; exists {
+			return nil, fmt.Errorf("duplicate policy for country %s", p.Country)
+		}
+		r.policies[p.Country] = p
+	}
+
+	if _, ok := r.policies[r.defaultCountry]; !ok {
+		return nil, fmt.Errorf("no policy defined for default country %q", r.defaultCountry)
+	}
+
+	return r, nil
+}
+
+// LoadRegistry builds the registry from a JSON file, or from DefaultPolicies when path is empty
+//
+// File format: a JSON array of domain.CountryPolicy objects, e.g.
+//
+//	[{"country": "AR", "currency": "ARS", "free_cancellation_hours": 24,
+//	  "late_cancellation_fee_percent": 20, "max_seats_per_booking": 4}]
+func LoadRegistry(path, defaultCountry string) (Registry, error) {
+	if path == "" {
+		return NewRegistry(defaultCountry, DefaultPolicies())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read country policies file: %w", err)
+	}
+
+	var policies []domain.CountryPolicy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse country policies file: %w", err)
+	}
+
+	return NewRegistry(defaultCountry, policies)
+}
+
+// DefaultPolicies returns the built-in policies used when no file is configured
+func DefaultPolicies() []domain.CountryPolicy {
+	return []domain.CountryPolicy{
+		{
+			Country:                    "AR",
+			Currency:                   "ARS",
+			FreeCancellationHours:      24,
+			LateCancellationFeePercent: 20,
+			MaxSeatsPerBooking:         4,
+		},
+		{
+			Country:                    "UY",
+			Currency:                   "UYU",
+			FreeCancellationHours:      12,
+			LateCancellationFeePercent: 15,
+			MaxSeatsPerBooking:         4,
+		},
+		{
+			Country:                    "CL",
+			Currency:                   "CLP",
+			FreeCancellationHours:      48,
+			LateCancellationFeePercent: 25,
+			MaxSeatsPerBooking:         3,
+		},
+	}
+}
+
+// Resolve returns the policy for a country, falling back to the default country
+func (r *registry) Resolve(country string) (domain.CountryPolicy, string) {
+	if p, ok := r.policies[normalizeCountry(country)]; ok {
+		return p, domain.PolicySourceTripCountry
+	}
+	return r.policies[r.defaultCountry], domain.PolicySourceDefaultCountry
+}
+
+// normalizeCountry upper-cases and trims a country code
+func normalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
@@ -0,0 +1,130 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"bookings-api/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ============================================================================
+// PUBLISHING CHANNEL POOL
+// ============================================================================
+// An AMQP channel is not meant to be shared by concurrent publishers: the
+// delivery tags of publisher confirms and the channel state are per channel.
+// Booking requests publish concurrently, so instead of sharing one channel:
+//
+//   1. The publisher opens PUBLISH_CHANNEL_POOL_SIZE channels on its connection
+//   2. Each publish checks out a channel, publishes and returns it right away
+//      (the broker confirm is awaited after returning it)
+//   3. A checked-out channel is used by a single goroutine, so publishes never
+//      interleave on a channel and a channel exception only affects the
+//      publish that caused it
+//   4. A channel closed by the broker is re-opened (re-dialing the connection
+//      if needed) the next time it is checked out
+//
+// When every channel is in use a publish waits for one to be returned, up to
+// publishTimeout (the attempt then fails and is retried like any other).
+// ============================================================================
+
+// pooledChannel is a publishing channel of the pool; nil channel means not opened yet
+type pooledChannel struct {
+	channel *amqp.Channel
+}
+
+// acquireChannel checks out a healthy channel, re-opening it if the broker closed it
+// The channel must be returned with releaseChannel
+func (p *ReservationPublisher) acquireChannel(ctx context.Context) (*pooledChannel, error) {
+	var pc *pooledChannel
+	select {
+	case pc = <-p.pool:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no publishing channel available: %w", ctx.Err())
+	}
+
+	if pc.channel != nil && !pc.channel.IsClosed() {
+		return pc, nil
+	}
+	if err := p.reopenChannel(pc); err != nil {
+		p.releaseChannel(pc)
+		return nil, err
+	}
+	return pc, nil
+}
+
+// releaseChannel returns a checked-out channel to the pool (never blocks: the pool holds every channel)
+func (p *ReservationPublisher) releaseChannel(pc *pooledChannel) {
+	p.pool <- pc
+}
+
+// reopenChannel replaces a closed pooled channel, re-dialing the connection when the broker closed it
+func (p *ReservationPublisher) reopenChannel(pc *pooledChannel) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return fmt.Errorf("publisher is closed")
+	}
+	if err := p.ensureConnLocked(); err != nil {
+		return err
+	}
+
+	channel, err := p.newChannel(p.conn)
+	if err != nil {
+		return fmt.Errorf("failed to re-open RabbitMQ channel: %w", err)
+	}
+	pc.channel = channel
+
+	// Only a channel: the connection itself is counted in reconnects by ensureConnLocked
+	atomic.AddInt64(&p.channelReopens, 1)
+	p.logger.Info().Msg("🔄 RabbitMQ publishing channel re-established")
+	return nil
+}
+
+// closePool closes every pooled channel, waiting for the ones checked out by publishes in flight
+// The closed channels go back to the pool, so a late publish fails right away instead of waiting
+func (p *ReservationPublisher) closePool() {
+	channels := make([]*pooledChannel, 0, cap(p.pool))
+	for len(channels) < cap(p.pool) {
+		channels = append(channels, <-p.pool)
+	}
+
+	closed := 0
+	for _, pc := range channels {
+		if pc.channel != nil && !pc.channel.IsClosed() {
+			if err := pc.channel.Close(); err != nil {
+				p.logger.Error().
+					Err(err).
+					Msg("⚠️  Error closing RabbitMQ channel")
+			} else {
+				closed++
+			}
+		}
+		p.releaseChannel(pc)
+	}
+
+	p.logger.Info().
+		Int("channels", closed).
+		Msg("✅ RabbitMQ channels closed")
+}
+
+// channelsInUse returns the number of channels checked out by publishes in flight
+func (p *ReservationPublisher) channelsInUse() int {
+	return cap(p.pool) - len(p.pool)
+}
+
+// openChannel opens a publishing channel with the exchange declared and publisher confirms enabled
+func openChannel(conn *amqp.Connection, topology config.PublisherTopology) (*amqp.Channel, error) {
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	if err := setupChannel(channel, topology); err != nil {
+		channel.Close()
+		return nil, err
+	}
+	return channel, nil
+}
@@ -0,0 +1,132 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+)
+
+// newPoolPublisher builds a publisher with size pooled channels and no RabbitMQ behind it
+// The zero amqp.Connection and amqp.Channel report themselves open
+func newPoolPublisher(size int, conn *amqp.Connection) (*ReservationPublisher, *int, *int) {
+	dials, opens := 0, 0
+	p := &ReservationPublisher{
+		conn:   conn,
+		pool:   make(chan *pooledChannel, size),
+		logger: zerolog.Nop(),
+	}
+	p.dial = func(url string) (*amqp.Connection, error) {
+		dials++
+		return &amqp.Connection{}, nil
+	}
+	p.newChannel = func(conn *amqp.Connection) (*amqp.Channel, error) {
+		opens++
+		return &amqp.Channel{}, nil
+	}
+	for i := 0; i < size; i++ {
+		p.pool <- &pooledChannel{}
+	}
+	return p, &dials, &opens
+}
+
+func TestAcquireChannel_ReopenOnLiveConnectionIsNotAReconnect(t *testing.T) {
+	p, dials, opens := newPoolPublisher(1, &amqp.Connection{})
+
+	pc, err := p.acquireChannel(context.Background())
+	if err != nil {
+		t.Fatalf("acquireChannel() error = %v", err)
+	}
+	if pc.channel == nil {
+		t.Fatal("the closed pooled channel was not re-opened")
+	}
+	p.releaseChannel(pc)
+
+	stats := p.Stats()
+	if stats.ChannelReopens != 1 || stats.Reconnects != 0 {
+		t.Errorf("channel_reopens = %d, reconnects = %d; want 1 and 0", stats.ChannelReopens, stats.Reconnects)
+	}
+	if *dials != 0 || *opens != 1 {
+		t.Errorf("dials = %d, opens = %d; want 0 and 1", *dials, *opens)
+	}
+
+	// An open channel is handed out as is
+	pc, err = p.acquireChannel(context.Background())
+	if err != nil {
+		t.Fatalf("acquireChannel() error = %v", err)
+	}
+	p.releaseChannel(pc)
+	if stats := p.Stats(); stats.ChannelReopens != 1 {
+		t.Errorf("channel_reopens = %d after checking out an open channel, want 1", stats.ChannelReopens)
+	}
+}
+
+func TestAcquireChannel_ClosedConnectionCountsBoth(t *testing.T) {
+	p, dials, _ := newPoolPublisher(2, nil)
+
+	for i := 0; i < 2; i++ {
+		pc, err := p.acquireChannel(context.Background())
+		if err != nil {
+			t.Fatalf("acquireChannel() error = %v", err)
+		}
+		defer p.releaseChannel(pc)
+	}
+
+	// The connection is re-dialed once; each pooled channel is re-opened on it
+	stats := p.Stats()
+	if stats.Reconnects != 1 || stats.ChannelReopens != 2 {
+		t.Errorf("reconnects = %d, channel_reopens = %d; want 1 and 2", stats.Reconnects, stats.ChannelReopens)
+	}
+	if *dials != 1 {
+		t.Errorf("dials = %d, want 1", *dials)
+	}
+}
+
+func TestAcquireChannel_WaitsForAFreeChannel(t *testing.T) {
+	p, _, _ := newPoolPublisher(1, &amqp.Connection{})
+
+	pc, err := p.acquireChannel(context.Background())
+	if err != nil {
+		t.Fatalf("acquireChannel() error = %v", err)
+	}
+	if got := p.channelsInUse(); got != 1 {
+		t.Errorf("channelsInUse() = %d, want 1", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.acquireChannel(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquireChannel() with every channel in use = %v, want the context deadline", err)
+	}
+
+	p.releaseChannel(pc)
+	if got := p.channelsInUse(); got != 0 {
+		t.Errorf("channelsInUse() = %d after release, want 0", got)
+	}
+}
+
+func TestAcquireChannel_FailedReopenReturnsTheChannel(t *testing.T) {
+	p, _, _ := newPoolPublisher(1, &amqp.Connection{})
+	p.newChannel = func(conn *amqp.Connection) (*amqp.Channel, error) {
+		return nil, errors.New("channel.open refused")
+	}
+
+	if _, err := p.acquireChannel(context.Background()); err == nil {
+		t.Fatal("acquireChannel() succeeded with a failing channel open")
+	}
+	if got := p.channelsInUse(); got != 0 {
+		t.Errorf("channelsInUse() = %d, want the channel back in the pool", got)
+	}
+	if stats := p.Stats(); stats.ChannelReopens != 0 {
+		t.Errorf("channel_reopens = %d after a failed re-open, want 0", stats.ChannelReopens)
+	}
+
+	// A closed publisher does not re-open channels
+	p.closed = true
+	if _, err := p.acquireChannel(context.Background()); err == nil {
+		t.Error("acquireChannel() succeeded on a closed publisher")
+	}
+}
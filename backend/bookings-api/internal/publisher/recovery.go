@@ -0,0 +1,225 @@
+package publisher
+
+import (
+	"sync/atomic"
+	"time"
+
+	"bookings-api/internal/config"
+	"bookings-api/internal/metrics"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ============================================================================
+// CONNECTION RECOVERY AND RETRY BUFFER
+// ============================================================================
+// The inline retries of retry.go cover short hiccups. For longer broker outages:
+//
+//   1. A watcher goroutine listens to NotifyClose on the connection and
+//      re-dials it with exponential backoff, without waiting for the next
+//      publish (pooled channels are re-opened when checked out)
+//   2. Events that failed all inline attempts are kept in an in-memory buffer
+//      (PUBLISH_BUFFER_SIZE) and re-published in order by a flusher goroutine
+//      once the channel is back (and every PUBLISH_BUFFER_FLUSH_SECONDS)
+//   3. While the buffer holds events, new events queue behind them so trips-api
+//      never sees a reservation.cancelled before its reservation.created
+//
+// The buffer lives in memory: events still buffered when the process stops are
+// reported as failed (logged with their body for replay), as before.
+// ============================================================================
+
+const (
+	// reconnectBaseDelay is the wait before the second reconnect attempt (doubles each attempt)
+	reconnectBaseDelay = 1 * time.Second
+
+	// reconnectMaxDelay bounds the wait between reconnect attempts
+	reconnectMaxDelay = 30 * time.Second
+)
+
+// bufferedEvent is an event waiting in the retry buffer for the broker to come back
+type bufferedEvent struct {
+	eventID    string
+	eventType  string
+	routingKey string
+	msg        amqp.Publishing
+	bufferedAt time.Time
+	lastErr    string
+}
+
+// setupChannel declares the exchange and puts the channel in confirm mode
+// (the broker acks every publish once it took responsibility for the message)
+func setupChannel(channel *amqp.Channel, topology config.PublisherTopology) error {
+	if err := declareExchange(channel, topology); err != nil {
+		return err
+	}
+	if err := channel.Confirm(false); err != nil {
+		return err
+	}
+	return nil
+}
+
+// watchConnection re-establishes the connection as soon as the broker closes it
+func (p *ReservationPublisher) watchConnection() {
+	defer p.wg.Done()
+
+	for {
+		p.mu.Lock()
+		conn := p.conn
+		p.mu.Unlock()
+
+		// A listener registered on an already closed connection is closed right away
+		connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+		var reason *amqp.Error
+		select {
+		case <-p.done:
+			return
+		case reason = <-connClosed:
+		}
+
+		event := p.logger.Warn()
+		if reason != nil {
+			event = event.Int("code", reason.Code).Str("reason", reason.Reason)
+		}
+		event.Msg("⚠️  RabbitMQ publishing connection closed, reconnecting")
+
+		if !p.reconnect() {
+			return
+		}
+		p.triggerFlush()
+	}
+}
+
+// reconnect retries until the connection is healthy again; false if the publisher was closed meanwhile
+func (p *ReservationPublisher) reconnect() bool {
+	delay := reconnectBaseDelay
+	for attempt := 1; ; attempt++ {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return false
+		}
+		err := p.ensureConnLocked()
+		p.mu.Unlock()
+		if err == nil {
+			return true
+		}
+
+		p.logger.Warn().
+			Err(err).
+			Int("attempt", attempt).
+			Dur("backoff", delay).
+			Msg("⚠️  RabbitMQ reconnect failed")
+
+		select {
+		case <-p.done:
+			return false
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
+
+// runFlusher re-publishes the buffered events when woken up and periodically
+func (p *ReservationPublisher) runFlusher() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-p.flushNow:
+		case <-ticker.C:
+		}
+		p.flushBuffer()
+	}
+}
+
+// triggerFlush wakes the flusher without blocking
+func (p *ReservationPublisher) triggerFlush() {
+	select {
+	case p.flushNow <- struct{}{}:
+	default:
+	}
+}
+
+// flushBuffer publishes the buffered events oldest first and stops at the first failure
+// (the broker is still unavailable: the order is kept for the next flush)
+// Only the flusher (and Close, once the flusher stopped) removes events from the buffer
+func (p *ReservationPublisher) flushBuffer() {
+	for {
+		p.bufMu.Lock()
+		if len(p.buffer) == 0 {
+			p.bufMu.Unlock()
+			return
+		}
+		event := p.buffer[0]
+		p.bufMu.Unlock()
+
+		if err := p.send(event.routingKey, event.msg); err != nil {
+			p.bufMu.Lock()
+			p.buffer[0].lastErr = err.Error()
+			p.bufMu.Unlock()
+			return
+		}
+
+		p.bufMu.Lock()
+		p.buffer[0] = bufferedEvent{}
+		p.buffer = p.buffer[1:]
+		p.bufMu.Unlock()
+
+		atomic.AddInt64(&p.published, 1)
+		metrics.ObservePublish(event.routingKey, nil)
+		p.logger.Info().
+			Str("event_id", event.eventID).
+			Str("event_type", event.eventType).
+			Dur("buffered_for", time.Since(event.bufferedAt)).
+			Msg("📤 Buffered event published")
+	}
+}
+
+// enqueue appends an event to the retry buffer; false when the buffer is full (or disabled)
+func (p *ReservationPublisher) enqueue(event bufferedEvent) bool {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+
+	if len(p.buffer) >= p.bufferSize {
+		return false
+	}
+	p.buffer = append(p.buffer, event)
+	atomic.AddInt64(&p.buffered, 1)
+	return true
+}
+
+// bufferLen returns the number of events waiting in the retry buffer
+func (p *ReservationPublisher) bufferLen() int {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+	return len(p.buffer)
+}
+
+// failBuffered reports every event still buffered as failed (used on Close)
+func (p *ReservationPublisher) failBuffered() {
+	p.bufMu.Lock()
+	pending := p.buffer
+	p.buffer = nil
+	p.bufMu.Unlock()
+
+	for _, event := range pending {
+		p.recordFailure(FailedEvent{
+			EventID:    event.eventID,
+			EventType:  event.eventType,
+			RoutingKey: event.routingKey,
+			Body:       event.msg.Body,
+			Attempts:   p.retryPolicy.MaxAttempts,
+			Error:      "publisher closed with the event still buffered: " + event.lastErr,
+			FailedAt:   time.Now(),
+		})
+	}
+}
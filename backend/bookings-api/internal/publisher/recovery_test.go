@@ -0,0 +1,199 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+)
+
+// fakeBroker records the message IDs it accepted; while down every publish fails,
+// and a publish of failOn fails once even when up
+type fakeBroker struct {
+	mu       sync.Mutex
+	down     bool
+	failOn   string
+	attempts int
+	sent     []string
+}
+
+func (b *fakeBroker) send(routingKey string, msg amqp.Publishing) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attempts++
+	if b.down {
+		return errors.New("channel/connection is not open")
+	}
+	if msg.MessageId == b.failOn {
+		b.failOn = ""
+		return errors.New("broker nacked the message")
+	}
+	b.sent = append(b.sent, msg.MessageId)
+	return nil
+}
+
+func (b *fakeBroker) setDown(down bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.down = down
+}
+
+// newTestPublisher builds a publisher without a RabbitMQ connection, publishing through broker
+func newTestPublisher(broker *fakeBroker, bufferSize int) *ReservationPublisher {
+	p := &ReservationPublisher{
+		retryPolicy: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		bufferSize:  bufferSize,
+		flushNow:    make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		logger:      zerolog.Nop(),
+	}
+	p.send = broker.send
+	return p
+}
+
+func publish(p *ReservationPublisher, eventID string) error {
+	return p.publishWithRetry(context.Background(), "reservation.created", "reservation.created", eventID, time.Now(), []byte(`{"event_id":"`+eventID+`"}`))
+}
+
+func bufferedIDs(p *ReservationPublisher) []string {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+
+	ids := make([]string, 0, len(p.buffer))
+	for _, event := range p.buffer {
+		ids = append(ids, event.eventID)
+	}
+	return ids
+}
+
+func TestPublishWithRetry_ReportsBufferedEvents(t *testing.T) {
+	broker := &fakeBroker{down: true}
+	p := newTestPublisher(broker, 10)
+
+	err := publish(p, "evt-1")
+	if !errors.Is(err, ErrEventBuffered) {
+		t.Fatalf("publish while RabbitMQ is down = %v, want ErrEventBuffered", err)
+	}
+	if broker.attempts != p.retryPolicy.MaxAttempts {
+		t.Errorf("attempts = %d, want %d before buffering", broker.attempts, p.retryPolicy.MaxAttempts)
+	}
+	if got := bufferedIDs(p); !reflect.DeepEqual(got, []string{"evt-1"}) {
+		t.Errorf("buffer = %v, want [evt-1]", got)
+	}
+	if stats := p.Stats(); stats.Published != 0 || stats.Failed != 0 {
+		t.Errorf("a buffered event is neither published nor failed, got %+v", stats)
+	}
+}
+
+func TestPublishWithRetry_QueuesBehindBufferedEvents(t *testing.T) {
+	broker := &fakeBroker{down: true}
+	p := newTestPublisher(broker, 10)
+
+	if err := publish(p, "created"); !errors.Is(err, ErrEventBuffered) {
+		t.Fatalf("first publish = %v, want ErrEventBuffered", err)
+	}
+
+	// The broker is back, but a later event must not overtake the buffered one
+	broker.setDown(false)
+	if err := publish(p, "cancelled"); !errors.Is(err, ErrEventBuffered) {
+		t.Fatalf("publish behind a buffered event = %v, want ErrEventBuffered", err)
+	}
+	if len(broker.sent) != 0 {
+		t.Fatalf("sent %v before the buffer was flushed", broker.sent)
+	}
+
+	p.flushBuffer()
+
+	if want := []string{"created", "cancelled"}; !reflect.DeepEqual(broker.sent, want) {
+		t.Errorf("sent = %v, want %v", broker.sent, want)
+	}
+	if n := len(bufferedIDs(p)); n != 0 {
+		t.Errorf("buffer still holds %d events after the flush", n)
+	}
+	if stats := p.Stats(); stats.Published != 2 {
+		t.Errorf("published = %d, want 2", stats.Published)
+	}
+
+	// With the buffer empty events are published directly again
+	if err := publish(p, "modified"); err != nil {
+		t.Fatalf("publish with an empty buffer = %v, want nil", err)
+	}
+}
+
+func TestFlushBuffer_StopsAtFirstFailureKeepingOrder(t *testing.T) {
+	broker := &fakeBroker{down: true}
+	p := newTestPublisher(broker, 10)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := publish(p, id); !errors.Is(err, ErrEventBuffered) {
+			t.Fatalf("publish %s = %v, want ErrEventBuffered", id, err)
+		}
+	}
+
+	broker.setDown(false)
+	broker.failOn = "b"
+	p.flushBuffer()
+
+	if want := []string{"a"}; !reflect.DeepEqual(broker.sent, want) {
+		t.Fatalf("sent = %v, want %v", broker.sent, want)
+	}
+	if want := []string{"b", "c"}; !reflect.DeepEqual(bufferedIDs(p), want) {
+		t.Fatalf("buffer = %v, want %v", bufferedIDs(p), want)
+	}
+	p.bufMu.Lock()
+	lastErr := p.buffer[0].lastErr
+	p.bufMu.Unlock()
+	if lastErr == "" {
+		t.Error("the event that failed to flush keeps no error")
+	}
+
+	p.flushBuffer()
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(broker.sent, want) {
+		t.Errorf("sent = %v, want %v", broker.sent, want)
+	}
+}
+
+func TestPublishWithRetry_FullBufferFails(t *testing.T) {
+	broker := &fakeBroker{down: true}
+	p := newTestPublisher(broker, 1)
+	var hooked []FailedEvent
+	p.SetFailureHook(func(event FailedEvent) { hooked = append(hooked, event) })
+
+	if err := publish(p, "kept"); !errors.Is(err, ErrEventBuffered) {
+		t.Fatalf("first publish = %v, want ErrEventBuffered", err)
+	}
+	err := publish(p, "dropped")
+	if err == nil || errors.Is(err, ErrEventBuffered) {
+		t.Fatalf("publish with a full buffer = %v, want a publish error", err)
+	}
+	if len(hooked) != 1 || hooked[0].EventID != "dropped" {
+		t.Fatalf("failure hook got %+v, want the dropped event", hooked)
+	}
+	if stats := p.Stats(); stats.Failed != 1 || stats.Buffered != 1 {
+		t.Errorf("stats = %+v, want 1 failed and 1 buffered", stats)
+	}
+}
+
+func TestFailBuffered_ReportsEventsInOrder(t *testing.T) {
+	broker := &fakeBroker{down: true}
+	p := newTestPublisher(broker, 10)
+	var hooked []string
+	p.SetFailureHook(func(event FailedEvent) { hooked = append(hooked, event.EventID) })
+
+	for _, id := range []string{"a", "b"} {
+		_ = publish(p, id)
+	}
+	p.failBuffered()
+
+	if want := []string{"a", "b"}; !reflect.DeepEqual(hooked, want) {
+		t.Errorf("failed events = %v, want %v", hooked, want)
+	}
+	if n := len(bufferedIDs(p)); n != 0 {
+		t.Errorf("buffer still holds %d events", n)
+	}
+}
@@ -0,0 +1,315 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"bookings-api/internal/metrics"
+	"bookings-api/internal/tracing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ============================================================================
+// PUBLISH RETRIES AND CHANNEL RE-ESTABLISHMENT
+// ============================================================================
+// A publish can fail when the channel was closed by the broker (restart,
+// connection reset, channel-level exception). Instead of dropping the event:
+//
+//   1. The pooled channel (and the connection if needed) is re-established
+//      lazily before each attempt (see channel_pool.go)
+//   2. Failed attempts are retried up to RetryPolicy.MaxAttempts times with
+//      exponential backoff and full jitter
+//   3. Every publish waits for the broker confirm (publisher confirms), so a
+//      message lost by the broker or nacked counts as a failed attempt
+//   4. Events that still fail go to the retry buffer (see recovery.go) and are
+//      re-published once the broker is back; the publish returns ErrEventBuffered
+//      so callers do not treat them as published (the buffer is lost on a crash)
+//   5. Events that cannot be buffered (buffer full) are counted, logged with
+//      their full payload and handed to the FailureHook so operations can replay them
+// ============================================================================
+
+// publishTimeout bounds a single publish attempt
+const publishTimeout = 5 * time.Second
+
+// ErrEventBuffered is returned by the Publish methods when the event was not published yet but
+// kept in the retry buffer: it is published once RabbitMQ is back, unless the process stops first.
+// Callers keep the booking in a state the expiration and reconciliation jobs still cover.
+var ErrEventBuffered = errors.New("event buffered until RabbitMQ is available")
+
+// RetryPolicy configures the bounded retries of a failed publish
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts per event (1 = no retries)
+	BaseDelay   time.Duration // Backoff before the second attempt (doubles each retry)
+	MaxDelay    time.Duration // Upper bound of a single backoff
+}
+
+// DefaultRetryPolicy is used when the configuration does not provide valid values
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// FailedEvent is an event that could not be published after all retries
+// Body is the exact JSON message, ready to be re-published to the publisher exchange with RoutingKey
+type FailedEvent struct {
+	EventID    string          `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	RoutingKey string          `json:"routing_key"`
+	Body       json.RawMessage `json:"body"`
+	Attempts   int             `json:"attempts"`
+	Error      string          `json:"error"`
+	FailedAt   time.Time       `json:"failed_at"`
+}
+
+// FailureHook is called once for every event that ultimately failed to publish
+// (e.g. to page on-call, push to an alerting system or persist for replay).
+// It runs synchronously in the publishing goroutine, so it must not block.
+type FailureHook func(event FailedEvent)
+
+// PublisherStats is a snapshot of the publisher counters
+type PublisherStats struct {
+	Published      int64        `json:"published"`
+	Retries        int64        `json:"retries"`
+	Reconnects     int64        `json:"reconnects"`      // Connections re-dialed
+	ChannelReopens int64        `json:"channel_reopens"` // Pooled channels re-opened (on a live or a re-dialed connection)
+	Failed         int64        `json:"failed"`
+	LastFailure    *FailedEvent `json:"last_failure,omitempty"`
+	MaxAttempts    int          `json:"max_attempts"`
+	ChannelHealthy bool         `json:"channel_healthy"` // The connection is open (closed pooled channels are re-opened on use)
+
+	// Retry buffer and publisher confirms
+	Nacked         int64 `json:"nacked"`
+	Buffered       int   `json:"buffered"`
+	BufferCapacity int   `json:"buffer_capacity"`
+
+	// Publishing channel pool
+	ChannelPoolSize int `json:"channel_pool_size"`
+	ChannelsInUse   int `json:"channels_in_use"`
+}
+
+// normalize fills invalid values with the defaults
+func (rp RetryPolicy) normalize() RetryPolicy {
+	if rp.MaxAttempts <= 0 {
+		rp.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if rp.BaseDelay <= 0 {
+		rp.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if rp.MaxDelay < rp.BaseDelay {
+		rp.MaxDelay = rp.BaseDelay
+	}
+	return rp
+}
+
+// backoff returns the jittered delay before the given retry (1 = first retry)
+// Full jitter: a random duration in [0, min(MaxDelay, BaseDelay * 2^(retry-1))]
+func (rp RetryPolicy) backoff(retry int) time.Duration {
+	delay := rp.BaseDelay
+	for i := 1; i < retry && delay < rp.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > rp.MaxDelay {
+		delay = rp.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// SetFailureHook registers the hook invoked for events that ultimately failed
+func (p *ReservationPublisher) SetFailureHook(hook FailureHook) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.failureHook = hook
+}
+
+// Stats returns a snapshot of the publish counters and the last failed event
+func (p *ReservationPublisher) Stats() PublisherStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PublisherStats{
+		Published:       atomic.LoadInt64(&p.published),
+		Retries:         atomic.LoadInt64(&p.retries),
+		Reconnects:      atomic.LoadInt64(&p.reconnects),
+		ChannelReopens:  atomic.LoadInt64(&p.channelReopens),
+		Failed:          atomic.LoadInt64(&p.failed),
+		LastFailure:     p.lastFailure,
+		MaxAttempts:     p.retryPolicy.MaxAttempts,
+		ChannelHealthy:  p.conn != nil && !p.conn.IsClosed(),
+		Nacked:          atomic.LoadInt64(&p.nacked),
+		Buffered:        p.bufferLen(),
+		BufferCapacity:  p.bufferSize,
+		ChannelPoolSize: cap(p.pool),
+		ChannelsInUse:   p.channelsInUse(),
+	}
+}
+
+// publishWithRetry publishes a message, re-establishing the channel and retrying
+// with jittered backoff on failure. Once all attempts failed the event is kept in the
+// retry buffer and ErrEventBuffered is returned; the last error is only returned when the buffer is full.
+// All attempts share one producer span; its traceparent travels in the message headers.
+func (p *ReservationPublisher) publishWithRetry(ctx context.Context, routingKey, eventType, eventID string, timestamp time.Time, body []byte) error {
+	_, span, headers := tracing.StartPublish(ctx, p.exchangeName, routingKey)
+
+	msg := amqp.Publishing{
+		Headers:      headers,
+		ContentType:  "application/json",
+		Body:         body,
+		DeliveryMode: amqp.Persistent, // 2 = persistent (survives broker restart)
+		Timestamp:    timestamp,
+		MessageId:    eventID, // Use event_id as message_id for tracing
+	}
+
+	pending := bufferedEvent{
+		eventID:    eventID,
+		eventType:  eventType,
+		routingKey: routingKey,
+		msg:        msg,
+		bufferedAt: time.Now(),
+	}
+
+	// Events already waiting for the broker go first: queue behind them to keep the order
+	if p.bufferLen() > 0 && p.enqueue(pending) {
+		p.logger.Warn().
+			Str("event_id", eventID).
+			Str("event_type", eventType).
+			Msg("⏳ Event queued behind buffered events")
+		tracing.End(span, nil)
+		p.triggerFlush()
+		return ErrEventBuffered
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= p.retryPolicy.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			atomic.AddInt64(&p.retries, 1)
+			delay := p.retryPolicy.backoff(attempt - 1)
+			p.logger.Warn().
+				Err(lastErr).
+				Str("event_id", eventID).
+				Str("event_type", eventType).
+				Int("attempt", attempt).
+				Dur("backoff", delay).
+				Msg("⚠️  Retrying event publish")
+			time.Sleep(delay)
+		}
+
+		if lastErr = p.send(routingKey, msg); lastErr == nil {
+			atomic.AddInt64(&p.published, 1)
+			metrics.ObservePublish(routingKey, nil)
+			tracing.End(span, nil)
+			return nil
+		}
+	}
+
+	metrics.ObservePublish(routingKey, lastErr)
+	tracing.End(span, lastErr)
+
+	pending.lastErr = lastErr.Error()
+	if p.enqueue(pending) {
+		p.logger.Warn().
+			Err(lastErr).
+			Str("event_id", eventID).
+			Str("event_type", eventType).
+			Msg("⏳ Event buffered until RabbitMQ is available")
+		return ErrEventBuffered
+	}
+
+	p.recordFailure(FailedEvent{
+		EventID:    eventID,
+		EventType:  eventType,
+		RoutingKey: routingKey,
+		Body:       json.RawMessage(body),
+		Attempts:   p.retryPolicy.MaxAttempts,
+		Error:      lastErr.Error(),
+		FailedAt:   time.Now(),
+	})
+	return fmt.Errorf("failed to publish event after %d attempts: %w", p.retryPolicy.MaxAttempts, lastErr)
+}
+
+// publishOnce makes a single publish attempt on a pooled channel and waits for the broker confirm
+func (p *ReservationPublisher) publishOnce(routingKey string, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	pc, err := p.acquireChannel(ctx)
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := pc.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		p.exchangeName, // exchange
+		routingKey,     // routing key
+		false,          // mandatory (don't return if no queue is bound)
+		false,          // immediate (don't wait for consumer confirmation)
+		msg,
+	)
+	p.releaseChannel(pc)
+	if err != nil {
+		return err
+	}
+
+	// The confirm is awaited after returning the channel so it can carry other publishes meanwhile
+	// A channel closed before the confirm arrives resolves it as not acked
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("broker did not confirm the message: %w", err)
+	}
+	if !acked {
+		atomic.AddInt64(&p.nacked, 1)
+		return fmt.Errorf("broker nacked the message (delivery tag %d)", confirmation.DeliveryTag)
+	}
+	return nil
+}
+
+// ensureConnLocked re-dials the connection when the broker closed it
+// The pooled channels opened on the old connection are re-opened as they are checked out
+// Caller must hold p.mu
+func (p *ReservationPublisher) ensureConnLocked() error {
+	if p.conn != nil && !p.conn.IsClosed() {
+		return nil
+	}
+
+	conn, err := p.dial(p.url)
+	if err != nil {
+		return fmt.Errorf("failed to reconnect to RabbitMQ at %s: %w", sanitizeRabbitMQURL(p.url), err)
+	}
+	p.conn = conn
+
+	atomic.AddInt64(&p.reconnects, 1)
+	p.logger.Info().Msg("🔄 RabbitMQ publishing connection re-established")
+	return nil
+}
+
+// recordFailure counts an event that ultimately failed, logs it with the full
+// payload (so it can be replayed) and notifies the failure hook
+func (p *ReservationPublisher) recordFailure(event FailedEvent) {
+	atomic.AddInt64(&p.failed, 1)
+
+	p.mu.Lock()
+	p.lastFailure = &event
+	hook := p.failureHook
+	p.mu.Unlock()
+
+	p.logger.Error().
+		Str("event_id", event.EventID).
+		Str("event_type", event.EventType).
+		Str("routing_key", event.RoutingKey).
+		RawJSON("body", event.Body).
+		Int("attempts", event.Attempts).
+		Str("error", event.Error).
+		Bool("alert", true).
+		Msg("🚨 Event publish failed after all retries - replay required")
+
+	if hook != nil {
+		hook(event)
+	}
+}
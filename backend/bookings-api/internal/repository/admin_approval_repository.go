@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"time"
+
+	"bookings-api/internal/dao"
+
+	"gorm.io/gorm"
+)
+
+// AdminApprovalFilter selects admin approvals (empty fields are not filtered)
+type AdminApprovalFilter struct {
+	Status      string
+	Action      string
+	BookingUUID string
+	Page        int
+	Limit       int
+}
+
+// AdminApprovalRepository defines the data access for admin actions that need a second approver
+type AdminApprovalRepository interface {
+	// Create stores a pending approval together with its "requested" audit event
+	Create(approval *dao.AdminApproval) error
+
+	// FindByID returns an approval (gorm.ErrRecordNotFound if it does not exist)
+	FindByID(id uint) (*dao.AdminApproval, error)
+
+	// FindPending returns the pending approval of an action on a booking (gorm.ErrRecordNotFound if there is none)
+	FindPending(action, bookingUUID string) (*dao.AdminApproval, error)
+
+	// FindPendingExpiredBefore returns pending approvals whose deadline passed, oldest deadline first
+	FindPendingExpiredBefore(before time.Time, limit int) ([]dao.AdminApproval, error)
+
+	// List returns the approvals matching the filter (newest first) and the total count
+	List(filter AdminApprovalFilter) ([]dao.AdminApproval, int64, error)
+
+	// FindEvents returns the audit trail of an approval, oldest first
+	FindEvents(approvalID uint) ([]dao.AdminApprovalEvent, error)
+
+	// Transition moves an approval from one status to another, also setting the given fields,
+	// and appends the audit event in the same transaction
+	// Returns false if the approval was no longer in fromStatus (changed by another request)
+	Transition(id uint, fromStatus, toStatus string, fields map[string]interface{}, event *dao.AdminApprovalEvent) (bool, error)
+}
+
+// adminApprovalRepository implements AdminApprovalRepository using GORM
+type adminApprovalRepository struct {
+	db *gorm.DB
+}
+
+// NewAdminApprovalRepository creates a new instance of AdminApprovalRepository
+func NewAdminApprovalRepository(db *gorm.DB) AdminApprovalRepository {
+	return &adminApprovalRepository{db: db}
+}
+
+// Create stores a pending approval and its "requested" audit event in one transaction
+func (r *adminApprovalRepository) Create(approval *dao.AdminApproval) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(approval).Error; err != nil {
+			return err
+		}
+		return tx.Create(&dao.AdminApprovalEvent{
+			ApprovalID: approval.ID,
+			Event:      dao.AdminApprovalEventRequested,
+			AdminID:    approval.RequestedBy,
+			Note:       approval.Reason,
+		}).Error
+	})
+}
+
+// FindByID returns an approval by its ID
+func (r *adminApprovalRepository) FindByID(id uint) (*dao.AdminApproval, error) {
+	var approval dao.AdminApproval
+	if err := r.db.First(&approval, id).Error; err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// FindPending returns the pending approval of an action on a booking
+func (r *adminApprovalRepository) FindPending(action, bookingUUID string) (*dao.AdminApproval, error) {
+	var approval dao.AdminApproval
+	err := r.db.
+		Where("booking_uuid = ? AND status = ? AND action = ?", bookingUUID, dao.AdminApprovalStatusPending, action).
+		First(&approval).Error
+	if err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// FindPendingExpiredBefore returns pending approvals whose deadline passed
+func (r *adminApprovalRepository) FindPendingExpiredBefore(before time.Time, limit int) ([]dao.AdminApproval, error) {
+	var approvals []dao.AdminApproval
+	err := r.db.Where("status = ? AND expires_at < ?", dao.AdminApprovalStatusPending, before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&approvals).Error
+	if err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// List returns the approvals matching the filter, newest first
+func (r *adminApprovalRepository) List(filter AdminApprovalFilter) ([]dao.AdminApproval, int64, error) {
+	query := r.db.Model(&dao.AdminApproval{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.BookingUUID != "" {
+		query = query.Where("booking_uuid = ?", filter.BookingUUID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var approvals []dao.AdminApproval
+	err := query.
+		Order("created_at DESC, id DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&approvals).Error
+	return approvals, total, err
+}
+
+// FindEvents returns the audit trail of an approval, oldest first
+func (r *adminApprovalRepository) FindEvents(approvalID uint) ([]dao.AdminApprovalEvent, error) {
+	var events []dao.AdminApprovalEvent
+	err := r.db.Where("approval_id = ?", approvalID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Transition applies a status change with the current status as optimistic lock
+// The status condition makes concurrent approvals/rejections of the same approval safe:
+// only one of them moves it out of pending, the others get false
+func (r *adminApprovalRepository) Transition(id uint, fromStatus, toStatus string, fields map[string]interface{}, event *dao.AdminApprovalEvent) (bool, error) {
+	applied := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"status": toStatus}
+		for column, value := range fields {
+			updates[column] = value
+		}
+
+		result := tx.Model(&dao.AdminApproval{}).
+			Where("id = ? AND status = ?", id, fromStatus).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		applied = true
+
+		event.ApprovalID = id
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return applied, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"bookings-api/internal/dao"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,12 +10,19 @@ import (
 
 // BookingRepository defines the interface for booking data access operations
 type BookingRepository interface {
-	// Create creates a new booking in the database
-	Create(booking *dao.Booking) error
+	// Create creates a new booking in the database, with the details of its passengers (may be empty)
+	// and its provider payment (nil when no payment provider is configured or for split payment)
+	Create(booking *dao.Booking, passengers []dao.BookingPassenger, payment *dao.Payment) error
 
 	// FindByID finds a booking by its UUID
 	FindByID(id string) (*dao.Booking, error)
 
+	// FindPassengers returns the passengers listed in a booking, ordered by seat
+	FindPassengers(bookingUUID string) ([]dao.BookingPassenger, error)
+
+	// FindPassengersByBookings returns the passengers of several bookings grouped by booking UUID, ordered by seat
+	FindPassengersByBookings(bookingUUIDs []string) (map[string][]dao.BookingPassenger, error)
+
 	// FindByPassengerID finds all bookings for a passenger with pagination
 	// Returns bookings slice, total count, and error
 	FindByPassengerID(passengerID int64, page, limit int) ([]dao.Booking, int64, error)
@@ -28,11 +36,50 @@ type BookingRepository interface {
 	// UpdateStatus updates only the status of a booking
 	UpdateStatus(bookingUUID string, status string) error
 
-	// CancelBooking cancels a booking with a reason
-	CancelBooking(bookingUUID string, reason string) error
+	// TransitionStatus moves a booking from fromStatus to toStatus, also setting the given fields
+	// Only applies if the booking is still in fromStatus; otherwise returns ErrStatusChanged
+	TransitionStatus(bookingUUID, fromStatus, toStatus string, fields map[string]interface{}, reason string) error
+
+	// CancelBooking cancels a booking with a reason and the cancellation fee charged (0 if free)
+	CancelBooking(bookingUUID string, reason string, fee float64) error
+
+	// UpdateSeats changes the seats (and dependent fare/CO2 figures) of a booking
+	// Only applies if the booking still has expectedSeats; otherwise returns ErrSeatsChanged
+	UpdateSeats(bookingUUID string, expectedSeats, newSeats int, totalPrice, co2SavedKg float64, reason string) error
 
 	// FindAllWithPagination finds all bookings with pagination and filters (admin only)
 	FindAllWithPagination(page, limit int, statusFilter, tripIDFilter string, passengerIDFilter int64) ([]*dao.Booking, int64, error)
+
+	// FindStatusHistory returns the status transitions of a booking up to (and including) a moment
+	// Ordered oldest first, so the last element is the state in effect at that moment
+	FindStatusHistory(bookingUUID string, until time.Time) ([]dao.BookingStatusHistory, error)
+
+	// SumCO2Savings aggregates the CO2 savings of a passenger's bookings in the given statuses
+	SumCO2Savings(passengerID int64, statuses []string) (*CO2SavingsTotals, error)
+
+	// FindPendingCreatedBefore returns up to limit pending bookings created before the cutoff, oldest first
+	// Bookings of dependents count from the guardian's approval instead of their creation
+	FindPendingCreatedBefore(createdBefore time.Time, limit int) ([]dao.Booking, error)
+
+	// FindAwaitingGuardianAfterID returns up to limit bookings awaiting the guardian's approval with an ID
+	// greater than afterID, ordered by ID (keyset pagination: bookings left awaiting are not read again)
+	FindAwaitingGuardianAfterID(afterID uint, limit int) ([]dao.Booking, error)
+}
+
+// ErrStatusChanged is returned by TransitionStatus when the booking is no longer in the expected status
+// (a concurrent transition, e.g. the passenger cancelled before trips-api confirmed)
+var ErrStatusChanged = errors.New("booking status changed concurrently")
+
+// ErrSeatsChanged is returned by UpdateSeats when the booking no longer has the expected seats
+// (another modification or a compensation was applied concurrently)
+var ErrSeatsChanged = errors.New("booking seats changed concurrently")
+
+// CO2SavingsTotals holds the aggregated sustainability figures of a set of bookings
+type CO2SavingsTotals struct {
+	Bookings   int64   `gorm:"column:bookings"`
+	Seats      int64   `gorm:"column:seats"`
+	DistanceKm float64 `gorm:"column:distance_km"`
+	CO2SavedKg float64 `gorm:"column:co2_saved_kg"`
 }
 
 // bookingRepository implements BookingRepository using GORM
@@ -46,8 +93,63 @@ func NewBookingRepository(db *gorm.DB) BookingRepository {
 }
 
 // Create creates a new booking in the database
-func (r *bookingRepository) Create(booking *dao.Booking) error {
-	return r.db.Create(booking).Error
+// The initial status, the passengers and the payment are recorded in the same transaction.
+// The initial history entry is stamped with the booking's CreatedAt, so the booking has a
+// state at every moment from its creation on (GetBookingAsOf rejects earlier moments)
+func (r *bookingRepository) Create(booking *dao.Booking, passengers []dao.BookingPassenger, payment *dao.Payment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		if len(passengers) > 0 {
+			for i := range passengers {
+				passengers[i].BookingUUID = booking.BookingUUID
+			}
+			if err := tx.Create(&passengers).Error; err != nil {
+				return err
+			}
+		}
+		if payment != nil {
+			payment.BookingUUID = booking.BookingUUID
+			if err := tx.Create(payment).Error; err != nil {
+				return err
+			}
+		}
+		entry := dao.NewBookingStatusHistory(booking, "", "")
+		entry.ChangedAt = booking.CreatedAt
+		return tx.Create(entry).Error
+	})
+}
+
+// FindPassengers returns the passengers listed in a booking, ordered by seat
+func (r *bookingRepository) FindPassengers(bookingUUID string) ([]dao.BookingPassenger, error) {
+	var passengers []dao.BookingPassenger
+	err := r.db.Where("booking_uuid = ?", bookingUUID).
+		Order("seat_number ASC").
+		Find(&passengers).Error
+	return passengers, err
+}
+
+// FindPassengersByBookings returns the passengers of several bookings grouped by booking UUID
+// One query for all the bookings (used by the driver view of a trip)
+func (r *bookingRepository) FindPassengersByBookings(bookingUUIDs []string) (map[string][]dao.BookingPassenger, error) {
+	result := make(map[string][]dao.BookingPassenger)
+	if len(bookingUUIDs) == 0 {
+		return result, nil
+	}
+
+	var passengers []dao.BookingPassenger
+	err := r.db.Where("booking_uuid IN ?", bookingUUIDs).
+		Order("booking_uuid ASC, seat_number ASC").
+		Find(&passengers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range passengers {
+		result[p.BookingUUID] = append(result[p.BookingUUID], p)
+	}
+	return result, nil
 }
 
 // FindByID finds a booking by its UUID
@@ -104,27 +206,128 @@ func (r *bookingRepository) FindByTripID(tripID string) ([]dao.Booking, error) {
 }
 
 // Update updates an existing booking
+// If the status changed, the transition is recorded in booking_status_history
 func (r *bookingRepository) Update(booking *dao.Booking) error {
-	return r.db.Save(booking).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var previous dao.Booking
+		if err := tx.Select("status").Where("id = ?", booking.ID).First(&previous).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Save(booking).Error; err != nil {
+			return err
+		}
+
+		if previous.Status == booking.Status {
+			return nil
+		}
+		return tx.Create(dao.NewBookingStatusHistory(booking, previous.Status, "")).Error
+	})
 }
 
 // UpdateStatus updates only the status of a booking
+// The transition is recorded in booking_status_history
 func (r *bookingRepository) UpdateStatus(bookingUUID string, status string) error {
-	return r.db.Model(&dao.Booking{}).
-		Where("booking_uuid = ?", bookingUUID).
-		Update("status", status).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var booking dao.Booking
+		if err := tx.Where("booking_uuid = ?", bookingUUID).First(&booking).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&booking).Update("status", status).Error; err != nil {
+			return err
+		}
+
+		previousStatus := booking.Status
+		booking.Status = status
+		return tx.Create(dao.NewBookingStatusHistory(&booking, previousStatus, "")).Error
+	})
+}
+
+// TransitionStatus applies a state machine transition using the current status as an optimistic lock
+// The transition is recorded in booking_status_history in the same transaction
+func (r *bookingRepository) TransitionStatus(bookingUUID, fromStatus, toStatus string, fields map[string]interface{}, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"status": toStatus}
+		for column, value := range fields {
+			updates[column] = value
+		}
+
+		result := tx.Model(&dao.Booking{}).
+			Where("booking_uuid = ? AND status = ?", bookingUUID, fromStatus).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		var booking dao.Booking
+		if err := tx.Where("booking_uuid = ?", bookingUUID).First(&booking).Error; err != nil {
+			return err
+		}
+		return tx.Create(dao.NewBookingStatusHistory(&booking, fromStatus, reason)).Error
+	})
 }
 
 // CancelBooking cancels a booking with a reason
-func (r *bookingRepository) CancelBooking(bookingUUID string, reason string) error {
-	now := time.Now()
-	return r.db.Model(&dao.Booking{}).
-		Where("booking_uuid = ?", bookingUUID).
-		Updates(map[string]interface{}{
-			"status":              dao.BookingStatusCancelled,
-			"cancelled_at":        &now,
-			"cancellation_reason": reason,
-		}).Error
+// The cancellation (with its exact timestamp) is recorded in booking_status_history
+func (r *bookingRepository) CancelBooking(bookingUUID string, reason string, fee float64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var booking dao.Booking
+		if err := tx.Where("booking_uuid = ?", bookingUUID).First(&booking).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&booking).
+			Updates(map[string]interface{}{
+				"status":              dao.BookingStatusCancelled,
+				"cancelled_at":        &now,
+				"cancellation_reason": reason,
+				"cancellation_fee":    fee,
+			}).Error; err != nil {
+			return err
+		}
+
+		previousStatus := booking.Status
+		booking.Status = dao.BookingStatusCancelled
+		booking.CancelledAt = &now
+		booking.CancellationReason = reason
+		booking.CancellationFee = fee
+
+		entry := dao.NewBookingStatusHistory(&booking, previousStatus, reason)
+		entry.ChangedAt = now
+		return tx.Create(entry).Error
+	})
+}
+
+// UpdateSeats changes the seats of a booking using the current seats as an optimistic lock
+// The change is recorded in booking_status_history (same status, new seats/price snapshot)
+// so the booking can still be reconstructed at any past moment
+func (r *bookingRepository) UpdateSeats(bookingUUID string, expectedSeats, newSeats int, totalPrice, co2SavedKg float64, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.Booking{}).
+			Where("booking_uuid = ? AND seats_requested = ?", bookingUUID, expectedSeats).
+			Updates(map[string]interface{}{
+				"seats_requested": newSeats,
+				"total_price":     totalPrice,
+				"co2_saved_kg":    co2SavedKg,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrSeatsChanged
+		}
+
+		var booking dao.Booking
+		if err := tx.Where("booking_uuid = ?", bookingUUID).First(&booking).Error; err != nil {
+			return err
+		}
+		return tx.Create(dao.NewBookingStatusHistory(&booking, booking.Status, reason)).Error
+	})
 }
 
 // FindAllWithPagination finds all bookings with pagination and filters (admin only)
@@ -166,3 +369,66 @@ func (r *bookingRepository) FindAllWithPagination(page, limit int, statusFilter,
 
 	return bookings, total, nil
 }
+
+// FindStatusHistory returns the status transitions of a booking up to (and including) a moment
+func (r *bookingRepository) FindStatusHistory(bookingUUID string, until time.Time) ([]dao.BookingStatusHistory, error) {
+	var history []dao.BookingStatusHistory
+	err := r.db.Where("booking_uuid = ? AND changed_at <= ?", bookingUUID, until).
+		Order("changed_at ASC, id ASC").
+		Find(&history).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// SumCO2Savings aggregates the CO2 savings of a passenger's bookings in the given statuses
+func (r *bookingRepository) SumCO2Savings(passengerID int64, statuses []string) (*CO2SavingsTotals, error) {
+	var totals CO2SavingsTotals
+	err := r.db.Model(&dao.Booking{}).
+		Select("COUNT(*) AS bookings, COALESCE(SUM(seats_requested), 0) AS seats, "+
+			"COALESCE(SUM(distance_km), 0) AS distance_km, COALESCE(SUM(co2_saved_kg), 0) AS co2_saved_kg").
+		Where("passenger_id = ? AND status IN ?", passengerID, statuses).
+		Scan(&totals).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &totals, nil
+}
+
+// FindPendingCreatedBefore returns the oldest pending bookings created before the cutoff
+// Used by the expiration job to find bookings trips-api never answered
+// A dependent's booking only reaches trips-api once approved, so its timeout starts at guardian_approved_at
+func (r *bookingRepository) FindPendingCreatedBefore(createdBefore time.Time, limit int) ([]dao.Booking, error) {
+	var bookings []dao.Booking
+	err := r.db.Where("status = ? AND COALESCE(guardian_approved_at, created_at) < ?", dao.BookingStatusPending, createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&bookings).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// FindAwaitingGuardianAfterID returns the bookings awaiting the guardian's approval after afterID
+// Used by the guardian approval job to look up the approvals in users-api
+func (r *bookingRepository) FindAwaitingGuardianAfterID(afterID uint, limit int) ([]dao.Booking, error) {
+	var bookings []dao.Booking
+	err := r.db.Where("status = ? AND id > ?", dao.BookingStatusAwaitingGuardian, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&bookings).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"bookings-api/internal/dao"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrShareNotPending is returned by MarkSharePaid when the share was already paid or covered
+var ErrShareNotPending = errors.New("payment share is no longer pending")
+
+// ErrPaymentStatusChanged is returned by UpdatePayment when the payment is no longer in the expected status
+// (e.g. the webhook and the synchronous authorization answer arriving at the same time)
+var ErrPaymentStatusChanged = errors.New("payment status changed concurrently")
+
+// PaymentRepository defines the data access operations of split-payment bookings
+// and of the payments processed by the payment provider
+type PaymentRepository interface {
+	// StartSplitPayment moves a pending booking to awaiting_payment, also setting the given fields,
+	// and creates its payment shares in the same transaction
+	// Only applies if the booking is still pending; otherwise returns ErrStatusChanged
+	StartSplitPayment(bookingUUID string, fields map[string]interface{}, shares []dao.PaymentShare, reason string) error
+
+	// FindSharesByBooking returns the payment shares of a booking, ordered by seat
+	FindSharesByBooking(bookingUUID string) ([]dao.PaymentShare, error)
+
+	// FindShareByToken finds a payment share by the token of its payment link
+	FindShareByToken(token string) (*dao.PaymentShare, error)
+
+	// MarkSharePaid moves a pending share to paid; returns ErrShareNotPending if it is not pending anymore
+	MarkSharePaid(shareID uint, reference string, paidAt time.Time) error
+
+	// CoverPendingShares marks the pending shares of a booking as covered by the organizer
+	// and confirms the booking (awaiting_payment → confirmed) in the same transaction
+	// Returns the number of covered shares, or ErrStatusChanged if the booking is no longer awaiting payment
+	CoverPendingShares(bookingUUID, reason string) (int64, error)
+
+	// PayPendingShares marks the pending shares of a booking as paid with the given reference
+	// and confirms the booking (awaiting_payment → confirmed) in the same transaction (manual payment by an admin)
+	// Returns the number of paid shares, or ErrStatusChanged if the booking is no longer awaiting payment
+	PayPendingShares(bookingUUID, reference string, paidAt time.Time, reason string) (int64, error)
+
+	// FindAwaitingPaymentDueBefore returns the bookings awaiting payment whose deadline passed, oldest deadline first
+	// splitPayment selects split-payment bookings (shares) or bookings paid through the payment provider
+	FindAwaitingPaymentDueBefore(dueBefore time.Time, splitPayment bool, limit int) ([]dao.Booking, error)
+
+	// StartPayment moves a pending booking to awaiting_payment, also setting the given fields,
+	// and sets the amount of its payment in the same transaction
+	// Only applies if the booking is still pending; otherwise returns ErrStatusChanged
+	StartPayment(bookingUUID string, fields map[string]interface{}, amount float64, reason string) error
+
+	// FindPaymentByBooking finds the provider payment of a booking
+	FindPaymentByBooking(bookingUUID string) (*dao.Payment, error)
+
+	// FindPaymentByProviderID finds a payment by the provider's payment ID (webhook)
+	FindPaymentByProviderID(providerPaymentID string) (*dao.Payment, error)
+
+	// UpdatePayment applies fields to a payment still in fromStatus; otherwise returns ErrPaymentStatusChanged
+	UpdatePayment(paymentID uint, fromStatus string, fields map[string]interface{}) error
+
+	// FindStalledPayments returns up to limit payments not updated since updatedBefore whose
+	// authorization never reached the provider or whose capture failed, oldest first
+	FindStalledPayments(updatedBefore time.Time, limit int) ([]dao.Payment, error)
+}
+
+// paymentRepository implements PaymentRepository using GORM
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// StartSplitPayment applies pending → awaiting_payment with the current status as optimistic lock
+// The transition is recorded in booking_status_history like any other
+func (r *paymentRepository) StartSplitPayment(bookingUUID string, fields map[string]interface{}, shares []dao.PaymentShare, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"status": dao.BookingStatusAwaitingPayment}
+		for column, value := range fields {
+			updates[column] = value
+		}
+
+		result := tx.Model(&dao.Booking{}).
+			Where("booking_uuid = ? AND status = ?", bookingUUID, dao.BookingStatusPending).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		if len(shares) > 0 {
+			if err := tx.Create(&shares).Error; err != nil {
+				return err
+			}
+		}
+
+		var booking dao.Booking
+		if err := tx.Where("booking_uuid = ?", bookingUUID).First(&booking).Error; err != nil {
+			return err
+		}
+		return tx.Create(dao.NewBookingStatusHistory(&booking, dao.BookingStatusPending, reason)).Error
+	})
+}
+
+// FindSharesByBooking returns the payment shares of a booking, ordered by seat
+func (r *paymentRepository) FindSharesByBooking(bookingUUID string) ([]dao.PaymentShare, error) {
+	var shares []dao.PaymentShare
+	err := r.db.Where("booking_uuid = ?", bookingUUID).
+		Order("seat_number ASC").
+		Find(&shares).Error
+	if err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// FindShareByToken finds a payment share by the token of its payment link
+func (r *paymentRepository) FindShareByToken(token string) (*dao.PaymentShare, error) {
+	var share dao.PaymentShare
+	if err := r.db.Where("token = ?", token).First(&share).Error; err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// MarkSharePaid moves a pending share to paid using the current status as optimistic lock
+func (r *paymentRepository) MarkSharePaid(shareID uint, reference string, paidAt time.Time) error {
+	result := r.db.Model(&dao.PaymentShare{}).
+		Where("id = ? AND status = ?", shareID, dao.PaymentShareStatusPending).
+		Updates(map[string]interface{}{
+			"status":            dao.PaymentShareStatusPaid,
+			"payment_reference": reference,
+			"paid_at":           &paidAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareNotPending
+	}
+	return nil
+}
+
+// CoverPendingShares covers the pending shares and confirms the booking in one transaction
+// The booking is locked first, so a share paid concurrently either lands before (and is not covered)
+// or finds the booking confirmed and is rejected by the service
+func (r *paymentRepository) CoverPendingShares(bookingUUID, reason string) (int64, error) {
+	return r.settlePendingShares(bookingUUID, map[string]interface{}{
+		"status": dao.PaymentShareStatusCovered,
+	}, reason)
+}
+
+// PayPendingShares marks the pending shares as paid and confirms the booking in one transaction
+// Same locking as CoverPendingShares
+func (r *paymentRepository) PayPendingShares(bookingUUID, reference string, paidAt time.Time, reason string) (int64, error) {
+	return r.settlePendingShares(bookingUUID, map[string]interface{}{
+		"status":            dao.PaymentShareStatusPaid,
+		"payment_reference": reference,
+		"paid_at":           &paidAt,
+	}, reason)
+}
+
+// settlePendingShares confirms a booking awaiting payment and applies shareUpdates to its pending shares
+func (r *paymentRepository) settlePendingShares(bookingUUID string, shareUpdates map[string]interface{}, reason string) (int64, error) {
+	var settled int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.Booking{}).
+			Where("booking_uuid = ? AND status = ?", bookingUUID, dao.BookingStatusAwaitingPayment).
+			Update("status", dao.BookingStatusConfirmed)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		result = tx.Model(&dao.PaymentShare{}).
+			Where("booking_uuid = ? AND status = ?", bookingUUID, dao.PaymentShareStatusPending).
+			Updates(shareUpdates)
+		if result.Error != nil {
+			return result.Error
+		}
+		settled = result.RowsAffected
+
+		var booking dao.Booking
+		if err := tx.Where("booking_uuid = ?", bookingUUID).First(&booking).Error; err != nil {
+			return err
+		}
+		return tx.Create(dao.NewBookingStatusHistory(&booking, dao.BookingStatusAwaitingPayment, reason)).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return settled, nil
+}
+
+// FindAwaitingPaymentDueBefore returns the bookings awaiting payment whose deadline passed
+// Used by the payment deadline job and the payment provider job
+func (r *paymentRepository) FindAwaitingPaymentDueBefore(dueBefore time.Time, splitPayment bool, limit int) ([]dao.Booking, error) {
+	var bookings []dao.Booking
+	err := r.db.Where("status = ? AND split_payment = ? AND payment_due_at < ?", dao.BookingStatusAwaitingPayment, splitPayment, dueBefore).
+		Order("payment_due_at ASC").
+		Limit(limit).
+		Find(&bookings).Error
+	if err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+// StartPayment applies pending → awaiting_payment with the current status as optimistic lock
+// The payment gets the confirmed fare; the transition is recorded in booking_status_history
+func (r *paymentRepository) StartPayment(bookingUUID string, fields map[string]interface{}, amount float64, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"status": dao.BookingStatusAwaitingPayment}
+		for column, value := range fields {
+			updates[column] = value
+		}
+
+		result := tx.Model(&dao.Booking{}).
+			Where("booking_uuid = ? AND status = ?", bookingUUID, dao.BookingStatusPending).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		result = tx.Model(&dao.Payment{}).
+			Where("booking_uuid = ? AND status = ?", bookingUUID, dao.PaymentStatusPending).
+			Update("amount", amount)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var booking dao.Booking
+		if err := tx.Where("booking_uuid = ?", bookingUUID).First(&booking).Error; err != nil {
+			return err
+		}
+		return tx.Create(dao.NewBookingStatusHistory(&booking, dao.BookingStatusPending, reason)).Error
+	})
+}
+
+// FindPaymentByBooking finds the provider payment of a booking
+func (r *paymentRepository) FindPaymentByBooking(bookingUUID string) (*dao.Payment, error) {
+	var payment dao.Payment
+	if err := r.db.Where("booking_uuid = ?", bookingUUID).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FindPaymentByProviderID finds a payment by the provider's payment ID
+func (r *paymentRepository) FindPaymentByProviderID(providerPaymentID string) (*dao.Payment, error) {
+	var payment dao.Payment
+	if err := r.db.Where("provider_payment_id = ?", providerPaymentID).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// UpdatePayment updates a payment using its current status as optimistic lock
+func (r *paymentRepository) UpdatePayment(paymentID uint, fromStatus string, fields map[string]interface{}) error {
+	result := r.db.Model(&dao.Payment{}).
+		Where("id = ? AND status = ?", paymentID, fromStatus).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPaymentStatusChanged
+	}
+	return nil
+}
+
+// FindStalledPayments returns the payments the payment provider job has to retry:
+// pending with an amount but no provider ID (authorization not sent) and authorized (capture not done)
+func (r *paymentRepository) FindStalledPayments(updatedBefore time.Time, limit int) ([]dao.Payment, error) {
+	var payments []dao.Payment
+	err := r.db.Where("updated_at < ? AND ((status = ? AND provider_payment_id = '' AND amount > 0) OR status = ?)",
+		updatedBefore, dao.PaymentStatusPending, dao.PaymentStatusAuthorized).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"time"
+
+	"bookings-api/internal/dao"
+
+	"gorm.io/gorm"
+)
+
+// QuarantineFilter selects quarantined messages (empty fields are not filtered)
+type QuarantineFilter struct {
+	Status     string
+	RoutingKey string
+	Page       int
+	Limit      int
+}
+
+// QuarantineRepository defines the data access for messages that failed schema validation
+type QuarantineRepository interface {
+	// Create stores a schema-invalid message
+	Create(msg *dao.QuarantinedMessage) error
+
+	// FindByID returns a quarantined message (gorm.ErrRecordNotFound if it does not exist)
+	FindByID(id uint) (*dao.QuarantinedMessage, error)
+
+	// List returns the messages matching the filter (oldest first) and the total count
+	List(filter QuarantineFilter) ([]dao.QuarantinedMessage, int64, error)
+
+	// CountByStatus returns the number of messages in each status
+	CountByStatus() (map[string]int64, error)
+
+	// RecordAttempt stores the payload and outcome of a failed reprocess attempt
+	RecordAttempt(id uint, payload string, schemaVersion int, validationErrors, lastError string) error
+
+	// Resolve marks a quarantined message as reprocessed or discarded
+	// Returns false if it was already resolved by another request
+	Resolve(id uint, status, payload string, resolvedBy int64, at time.Time) (bool, error)
+}
+
+// quarantineRepository implements QuarantineRepository using GORM
+type quarantineRepository struct {
+	db *gorm.DB
+}
+
+// NewQuarantineRepository creates a new instance of QuarantineRepository
+func NewQuarantineRepository(db *gorm.DB) QuarantineRepository {
+	return &quarantineRepository{db: db}
+}
+
+// Create stores a schema-invalid message
+func (r *quarantineRepository) Create(msg *dao.QuarantinedMessage) error {
+	return r.db.Create(msg).Error
+}
+
+// FindByID returns a quarantined message by its ID
+func (r *quarantineRepository) FindByID(id uint) (*dao.QuarantinedMessage, error) {
+	var msg dao.QuarantinedMessage
+	if err := r.db.First(&msg, id).Error; err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// List returns the messages matching the filter, oldest first
+func (r *quarantineRepository) List(filter QuarantineFilter) ([]dao.QuarantinedMessage, int64, error) {
+	query := r.db.Model(&dao.QuarantinedMessage{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.RoutingKey != "" {
+		query = query.Where("routing_key = ?", filter.RoutingKey)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []dao.QuarantinedMessage
+	err := query.
+		Order("created_at ASC, id ASC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&messages).Error
+	return messages, total, err
+}
+
+// CountByStatus returns the number of messages in each status
+func (r *quarantineRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&dao.QuarantinedMessage{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// RecordAttempt stores the payload and outcome of a failed reprocess attempt
+func (r *quarantineRepository) RecordAttempt(id uint, payload string, schemaVersion int, validationErrors, lastError string) error {
+	return r.db.Model(&dao.QuarantinedMessage{}).
+		Where("id = ? AND status = ?", id, dao.QuarantineStatusQuarantined).
+		Updates(map[string]interface{}{
+			"payload":            payload,
+			"schema_version":     schemaVersion,
+			"validation_errors":  validationErrors,
+			"last_error":         lastError,
+			"reprocess_attempts": gorm.Expr("reprocess_attempts + 1"),
+		}).Error
+}
+
+// Resolve marks a quarantined message as reprocessed or discarded
+// The status condition makes concurrent resolutions of the same message safe
+func (r *quarantineRepository) Resolve(id uint, status, payload string, resolvedBy int64, at time.Time) (bool, error) {
+	updates := map[string]interface{}{
+		"status":      status,
+		"resolved_by": resolvedBy,
+		"resolved_at": at,
+		"last_error":  "",
+	}
+	if status == dao.QuarantineStatusReprocessed {
+		updates["payload"] = payload
+		updates["reprocess_attempts"] = gorm.Expr("reprocess_attempts + 1")
+	}
+
+	result := r.db.Model(&dao.QuarantinedMessage{}).
+		Where("id = ? AND status = ?", id, dao.QuarantineStatusQuarantined).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
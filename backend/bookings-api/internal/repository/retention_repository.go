@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"bookings-api/internal/dao"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetentionRepository defines the data access operations of the retention job
+type RetentionRepository interface {
+	// FindArchivable returns up to limit bookings in the given statuses last updated before the cutoff
+	// Ordered by id so consecutive runs make progress
+	FindArchivable(statuses []string, updatedBefore time.Time, limit int) ([]dao.Booking, error)
+
+	// Archive deletes the bookings (and their status history) and stores the analytics records,
+	// in a single transaction. Bookings that no longer match the statuses/cutoff are kept,
+	// together with their analytics record. Returns the number of bookings deleted.
+	Archive(bookings []dao.Booking, analytics []dao.BookingAnalytics, statuses []string, updatedBefore time.Time) (int64, error)
+
+	// PurgeAnalytics deletes analytics records archived before the cutoff
+	PurgeAnalytics(archivedBefore time.Time) (int64, error)
+}
+
+// retentionRepository implements RetentionRepository using GORM
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionRepository creates a new instance of RetentionRepository
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+// FindArchivable returns the oldest bookings eligible for archival
+func (r *retentionRepository) FindArchivable(statuses []string, updatedBefore time.Time, limit int) ([]dao.Booking, error) {
+	var bookings []dao.Booking
+	err := r.db.Where("status IN ? AND updated_at < ?", statuses, updatedBefore).
+		Order("id ASC").
+		Limit(limit).
+		Find(&bookings).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// Archive deletes the bookings with their history and passengers and stores the anonymized records atomically
+// analytics[i] must be the record of bookings[i] (nil/empty analytics = only delete)
+func (r *retentionRepository) Archive(bookings []dao.Booking, analytics []dao.BookingAnalytics, statuses []string, updatedBefore time.Time) (int64, error) {
+	if len(bookings) == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var records []dao.BookingAnalytics
+		for i, booking := range bookings {
+			// Delete one by one with the eligibility condition: a booking updated since it was
+			// read (should not happen for terminal statuses) is skipped with its record
+			result := tx.Where("id = ? AND status IN ? AND updated_at < ?", booking.ID, statuses, updatedBefore).
+				Delete(&dao.Booking{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			deleted++
+
+			if err := tx.Where("booking_uuid = ?", booking.BookingUUID).
+				Delete(&dao.BookingStatusHistory{}).Error; err != nil {
+				return err
+			}
+
+			// Passenger names and documents are personal data: never kept in the analytics record
+			if err := tx.Where("booking_uuid = ?", booking.BookingUUID).
+				Delete(&dao.BookingPassenger{}).Error; err != nil {
+				return err
+			}
+
+			// Payment shares carry the passenger name too
+			if err := tx.Where("booking_uuid = ?", booking.BookingUUID).
+				Delete(&dao.PaymentShare{}).Error; err != nil {
+				return err
+			}
+
+			if i < len(analytics) {
+				records = append(records, analytics[i])
+			}
+		}
+
+		if len(records) > 0 {
+			if err := tx.Create(&records).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// PurgeAnalytics deletes analytics records archived before the cutoff
+func (r *retentionRepository) PurgeAnalytics(archivedBefore time.Time) (int64, error) {
+	result := r.db.Where("archived_at < ?", archivedBefore).Delete(&dao.BookingAnalytics{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "gorm.io/gorm"
+
+// TxRepositories are repositories bound to a single database transaction
+type TxRepositories struct {
+	Bookings BookingRepository
+	Events   EventRepository
+}
+
+// Transactor runs several repository operations in a single database transaction
+type Transactor interface {
+	// Transaction commits when fn returns nil and rolls everything back otherwise
+	// Repository methods that open their own transaction run as savepoints inside it
+	Transaction(fn func(repos TxRepositories) error) error
+}
+
+// transactor implements Transactor using GORM
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new instance of Transactor
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// Transaction runs fn with the booking and event repositories bound to the same transaction
+func (t *transactor) Transaction(fn func(repos TxRepositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(TxRepositories{
+			Bookings: NewBookingRepository(tx),
+			Events:   NewEventRepository(tx),
+		})
+	})
+}
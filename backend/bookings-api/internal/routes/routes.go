@@ -2,8 +2,10 @@ package routes
 
 import (
 	"bookings-api/internal/controller"
+	"bookings-api/internal/metrics"
 	"bookings-api/internal/middleware"
 	"bookings-api/internal/service"
+	"bookings-api/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,24 +17,73 @@ import (
 //   - Health check routes (for monitoring and load balancers)
 //   - Booking management routes (CRUD operations)
 //   - User-specific routes (user's bookings)
+//   - Trip routes (bookings of a trip, for its driver)
 //
 // Parameters:
 //   - router: The Gin engine instance to register routes on
 //   - healthController: Controller for health check endpoints
 //   - bookingController: Controller for booking management endpoints
+//   - bookingStreamController: Controller for the live booking status stream (SSE)
+//   - loadSheddingController: Controller for the load shedder status/override (admin)
+//   - publisherController: Controller for the event publisher counters (admin)
+//   - topologyController: Controller for the effective RabbitMQ topology (admin)
+//   - dbMetricsController: Controller for the database query metrics (admin)
+//   - quarantineController: Controller for the consumed messages that failed schema validation (admin)
+//   - approvalController: Controller for destructive admin actions that need a second admin (admin)
+//   - paymentController: Controller for the payment shares of split-payment bookings and the provider payments
+//   - paymentWebhookSecret: Shared secret the payment provider sends on its webhooks
 //   - authService: Service for JWT token validation
+//   - loadShedder: Load shedder applied to all routes (503 for low-priority requests under overload)
 //
 // Route structure:
 //   GET  /health              - Service health check (public)
+//   GET  /health/smoke        - Result of the startup smoke test (public, SMOKE_TEST=true)
+//   GET  /metrics             - Prometheus metrics (public, internal network)
 //   GET  /api/v1/bookings     - List all bookings (auth required)
 //   GET  /api/v1/bookings/:id - Get specific booking (auth required)
+//   GET  /api/v1/bookings/:id/state - Saga state and status transitions of a booking (passenger, driver or admin)
+//   GET  /api/v1/bookings/:id/stream - Live status transitions over Server-Sent Events (passenger, driver or admin)
 //   POST /api/v1/bookings     - Create new booking (auth required)
 //   PATCH /api/v1/bookings/:id/cancel - Cancel booking (auth required)
+//   PATCH /api/v1/bookings/:id/seats - Change the seats of a confirmed booking (auth required)
+//   GET  /api/v1/bookings/:id/payment - Payment shares of a split-payment booking, or its provider payment (organizer, driver or admin)
+//   GET  /api/v1/payments/:token - Payment share behind a payment link (public, the token is the credential)
+//   POST /api/v1/payments/:token/paid - Payment provider webhook for a paid share (X-Payment-Webhook-Secret)
+//   POST /api/v1/payments/webhook - Payment provider webhook for authorizations, captures and refunds (X-Payment-Webhook-Secret)
+//   GET  /api/v1/trips/:trip_id/bookings - Bookings of a trip with passenger contact details (driver of the trip)
+//   GET  /api/v1/users/:id/co2-savings - Aggregate CO2 savings of a user (self or admin)
+//   GET  /api/v1/admin/bookings/:id/as-of?ts= - Booking state at a past moment (admin)
+//   GET  /api/v1/admin/trips/:id/policy - Effective country policy for a trip (admin)
+//   GET  /api/v1/admin/load-shedding - Load shedder state and counters (admin)
+//   PUT  /api/v1/admin/load-shedding - Override load shedding mode: auto/on/off (admin)
+//   GET  /api/v1/admin/publisher - Event publish counters and last failed event (admin)
+//   GET  /api/v1/admin/rabbitmq/topology - Effective exchanges, queues, routing keys and DLX settings (admin)
+//   GET  /api/v1/admin/db-metrics - Query duration histograms, slow queries and lock errors (admin)
+//   GET  /api/v1/admin/quarantine - Messages that failed schema validation (admin)
+//   GET  /api/v1/admin/quarantine/:id - Payload and validation errors of a quarantined message (admin)
+//   POST /api/v1/admin/quarantine/:id/reprocess - Re-validate and handle a quarantined message (admin)
+//   POST /api/v1/admin/quarantine/:id/discard - Drop a quarantined message (admin)
+//   POST /api/v1/admin/approvals - Request a force-cancel or manual payment of a booking (admin)
+//   GET  /api/v1/admin/approvals - Approvals, newest first (admin)
+//   GET  /api/v1/admin/approvals/:id - Approval and its audit trail (admin)
+//   POST /api/v1/admin/approvals/:id/approve - Approve and run the action (a different admin than the requester)
+//   POST /api/v1/admin/approvals/:id/reject - Reject a pending approval (admin)
 func SetupRoutes(
 	router *gin.Engine,
 	healthController *controller.HealthController,
 	bookingController *controller.BookingController,
+	bookingStreamController *controller.BookingStreamController,
+	loadSheddingController *controller.LoadSheddingController,
+	publisherController *controller.PublisherController,
+	topologyController *controller.TopologyController,
+	dbMetricsController *controller.DBMetricsController,
+	quarantineController *controller.QuarantineController,
+	paymentController *controller.PaymentController,
+	approvalController *controller.AdminApprovalController,
 	authService service.AuthService,
+	sessionService service.SessionService,
+	paymentWebhookSecret string,
+	loadShedder *middleware.LoadShedder,
 ) {
 	// ============================================================================
 	// MIDDLEWARE REGISTRATION
@@ -41,6 +92,12 @@ func SetupRoutes(
 	// This allows frontend applications to make cross-origin requests
 	router.Use(middleware.CORSMiddleware())
 
+	// Open a server span per request, continuing the incoming traceparent (OpenTelemetry)
+	router.Use(tracing.Middleware())
+
+	// Record the duration of every request by route template (Prometheus, GET /metrics)
+	router.Use(metrics.Middleware())
+
 	// Register error handling middleware globally
 	// This must be registered AFTER routes are defined to catch errors from handlers
 	// The ErrorHandler middleware:
@@ -49,6 +106,11 @@ func SetupRoutes(
 	//   - Returns standardized JSON error responses
 	router.Use(middleware.ErrorHandler())
 
+	// Register load shedding AFTER ErrorHandler so rejected requests get a standard 503 response
+	// Under overload only low-priority requests (list queries) are rejected;
+	// booking creation and cancellation always go through
+	router.Use(loadShedder.Middleware())
+
 	// ============================================================================
 	// PUBLIC ROUTES (No authentication required)
 	// ============================================================================
@@ -58,6 +120,14 @@ func SetupRoutes(
 	// Returns: {"status": "ok", "service": "bookings-api", "port": "8003"}
 	router.GET("/health", healthController.HealthCheck)
 
+	// Startup smoke test (book, confirm and cancel on a sandbox trip) for deployment pipelines
+	// Returns: 200 passed, 202 running, 503 failed, 404 when SMOKE_TEST is not set
+	router.GET("/health/smoke", healthController.SmokeCheck)
+
+	// Prometheus metrics: request durations, RabbitMQ counters, query latencies and DB pool stats
+	// Scraped from the internal network, no authentication
+	router.GET("/metrics", metrics.Handler())
+
 	// ============================================================================
 	// API v1 ROUTES (Authentication required)
 	// ============================================================================
@@ -69,22 +139,67 @@ func SetupRoutes(
 	{
 		// Booking routes - all protected by JWT authentication
 		bookings := v1.Group("/bookings")
-		bookings.Use(middleware.AuthMiddleware(authService)) // JWT authentication
+		bookings.Use(middleware.AuthMiddleware(authService, sessionService)) // JWT authentication
 		{
 			// Booking CRUD endpoints
 			bookings.GET("", bookingController.ListBookings)           // List user's bookings
 			bookings.GET("/:id", bookingController.GetBooking)         // Get specific booking
+			bookings.GET("/:id/state", bookingController.GetBookingState) // Saga state (pending/confirmed/failed/cancelled)
+			bookings.GET("/:id/stream", bookingStreamController.StreamBookingStatus) // Live status transitions (SSE)
 			bookings.POST("", bookingController.CreateBooking)         // Create new booking
 			bookings.PATCH("/:id/cancel", bookingController.CancelBooking) // Cancel booking
+			bookings.PATCH("/:id/seats", bookingController.ModifyBookingSeats) // Change seats (partial release)
+			bookings.GET("/:id/payment", paymentController.GetBookingPayment) // Split payment progress (links for the organizer) or provider payment
+		}
+
+		// Payment routes - payment links of split-payment bookings (no JWT: shared with passengers without an account)
+		// and the payment provider webhooks
+		payments := v1.Group("/payments")
+		{
+			payments.GET("/:token", paymentController.GetShare)                                                                          // Share behind a payment link
+			payments.POST("/:token/paid", middleware.RequirePaymentWebhookSecret(paymentWebhookSecret), paymentController.MarkSharePaid) // Provider webhook
+			payments.POST("/webhook", middleware.RequirePaymentWebhookSecret(paymentWebhookSecret), paymentController.Webhook)            // Provider payment status change
+		}
+
+		// Trip routes - the driver's view of the bookings on their trip
+		trips := v1.Group("/trips")
+		trips.Use(middleware.AuthMiddleware(authService, sessionService)) // JWT authentication
+		{
+			trips.GET("/:trip_id/bookings", bookingController.GetTripBookings) // Passengers and statuses (driver only, ?status=)
+		}
+
+		// User routes - aggregates over a user's bookings
+		users := v1.Group("/users")
+		users.Use(middleware.AuthMiddleware(authService, sessionService)) // JWT authentication
+		{
+			users.GET("/:id/co2-savings", bookingController.GetUserCO2Savings) // Estimated CO2 saved by the user's bookings
 		}
 
 		// Admin routes - protected by JWT + admin role
 		admin := v1.Group("/admin")
-		admin.Use(middleware.AuthMiddleware(authService)) // JWT authentication
-		admin.Use(middleware.RequireAdminRole())          // Admin role required
+		admin.Use(middleware.AuthMiddleware(authService, sessionService)) // JWT authentication
+		admin.Use(middleware.RequireAdminRole())                           // Admin role required
 		{
 			// Admin-only endpoints
 			admin.GET("/bookings", bookingController.GetAllBookings) // Get all bookings with filters
+			admin.GET("/bookings/:id/as-of", bookingController.GetBookingAsOf) // Booking state at a past moment (?ts=RFC3339)
+			admin.GET("/trips/:id/policy", bookingController.GetTripPolicy)    // Effective country policy for a trip
+			admin.GET("/load-shedding", loadSheddingController.GetStatus) // Load shedder state and counters
+			admin.PUT("/load-shedding", loadSheddingController.SetMode)   // Manual override (auto/on/off)
+			admin.GET("/publisher", publisherController.GetStats)         // Publish retries/failures counters
+			admin.GET("/rabbitmq/topology", topologyController.GetTopology) // Effective RabbitMQ topology (config)
+			admin.GET("/db-metrics", dbMetricsController.GetStats)        // Query histograms, slow queries, lock errors
+			admin.GET("/quarantine", quarantineController.ListMessages)                   // Schema-invalid consumed messages
+			admin.GET("/quarantine/:id", quarantineController.GetMessage)                 // Payload and validation errors
+			admin.POST("/quarantine/:id/reprocess", quarantineController.ReprocessMessage) // Re-validate (optional corrected payload) and handle
+			admin.POST("/quarantine/:id/discard", quarantineController.DiscardMessage)     // Drop without processing
+
+			// Destructive actions (force-cancel, mark paid) run only once a second admin approves them
+			admin.POST("/approvals", approvalController.CreateApproval)              // Request an action (pending)
+			admin.GET("/approvals", approvalController.ListApprovals)                // Approvals, newest first
+			admin.GET("/approvals/:id", approvalController.GetApproval)              // Approval and its audit trail
+			admin.POST("/approvals/:id/approve", approvalController.ApproveApproval) // Second admin approves, the action runs
+			admin.POST("/approvals/:id/reject", approvalController.RejectApproval)   // Close without running it
 		}
 	}
 }
@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Event schemas are embedded at build time: schemas/<event_type>.v<version>.json
+// A new version is added as a new file; old versions stay so in-flight messages keep validating
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// DefaultVersion is the schema version of events without a schema_version field
+// (every event published before versioning was introduced)
+const DefaultVersion = 1
+
+var schemaFileName = regexp.MustCompile(`^(.+)\.v(\d+)\.json$`)
+
+// ValidationError describes why an event payload was rejected
+type ValidationError struct {
+	EventType string   `json:"event_type"`
+	Version   int      `json:"schema_version"`
+	Errors    []string `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.Version == 0 {
+		return fmt.Sprintf("%s is not a valid payload: %s", e.EventType, strings.Join(e.Errors, "; "))
+	}
+	return fmt.Sprintf("%s v%d does not match its schema: %s", e.EventType, e.Version, strings.Join(e.Errors, "; "))
+}
+
+// ErrorsJSON returns the violations as a JSON array (as stored in the quarantine table)
+func (e *ValidationError) ErrorsJSON() string {
+	encoded, err := json.Marshal(e.Errors)
+	if err != nil {
+		return "[]"
+	}
+	return string(encoded)
+}
+
+// Registry holds the compiled schemas of every event type and version
+type Registry struct {
+	schemas map[string]map[int]*Schema
+}
+
+// LoadRegistry compiles all embedded schemas
+// Fails if a schema file is misnamed, invalid or uses an unsupported keyword
+func LoadRegistry() (*Registry, error) {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded schemas: %w", err)
+	}
+
+	r := &Registry{schemas: make(map[string]map[int]*Schema)}
+	for _, entry := range entries {
+		match := schemaFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("schema file %s must be named <event_type>.v<version>.json", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[2])
+
+		data, err := schemaFiles.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %s: %w", entry.Name(), err)
+		}
+		compiled, err := Compile(data)
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: %w", entry.Name(), err)
+		}
+
+		if r.schemas[match[1]] == nil {
+			r.schemas[match[1]] = make(map[int]*Schema)
+		}
+		r.schemas[match[1]][version] = compiled
+	}
+	return r, nil
+}
+
+// Has reports whether the event type has at least one schema
+func (r *Registry) Has(eventType string) bool {
+	return len(r.schemas[eventType]) > 0
+}
+
+// Versions returns the known schema versions of every event type
+func (r *Registry) Versions() map[string][]int {
+	versions := make(map[string][]int, len(r.schemas))
+	for eventType, byVersion := range r.schemas {
+		for version := range byVersion {
+			versions[eventType] = append(versions[eventType], version)
+		}
+		sort.Ints(versions[eventType])
+	}
+	return versions
+}
+
+// Validate checks a raw event payload against the schema of its event type and version
+// The version comes from the payload schema_version field (DefaultVersion when absent)
+//
+// Returns the version that was checked and a *ValidationError if the payload is not valid JSON,
+// declares an unknown version or does not match the schema. Event types without schemas are not validated
+func (r *Registry) Validate(eventType string, body []byte) (int, error) {
+	byVersion := r.schemas[eventType]
+	if len(byVersion) == 0 {
+		return 0, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return 0, &ValidationError{
+			EventType: eventType,
+			Errors:    []string{"/: invalid JSON: " + err.Error()},
+		}
+	}
+
+	version := DefaultVersion
+	if obj, ok := doc.(map[string]interface{}); ok {
+		if raw, present := obj["schema_version"]; present {
+			n, isNumber := raw.(float64)
+			if !isNumber || n < 1 || n != float64(int(n)) {
+				return 0, &ValidationError{
+					EventType: eventType,
+					Errors:    []string{"/schema_version: must be a positive integer"},
+				}
+			}
+			version = int(n)
+		}
+	}
+
+	compiled, ok := byVersion[version]
+	if !ok {
+		return version, &ValidationError{
+			EventType: eventType,
+			Version:   version,
+			Errors:    []string{fmt.Sprintf("/schema_version: unknown version %d", version)},
+		}
+	}
+
+	if errs := compiled.Validate(doc); len(errs) > 0 {
+		return version, &ValidationError{
+			EventType: eventType,
+			Version:   version,
+			Errors:    errs,
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func loadTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r, err := LoadRegistry()
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+	return r
+}
+
+func TestLoadRegistry(t *testing.T) {
+	r := loadTestRegistry(t)
+
+	want := map[string][]int{
+		"reservation.confirmed":           {1},
+		"reservation.failed":              {1},
+		"reservation.modification_failed": {1},
+		"trip.cancelled":                  {1},
+	}
+	if got := r.Versions(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Versions() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	r := loadTestRegistry(t)
+
+	tests := []struct {
+		name        string
+		eventType   string
+		body        string
+		wantVersion int
+		wantErrors  []string
+	}{
+		{
+			name:        "valid trip.cancelled as published by trips-api",
+			eventType:   "trip.cancelled",
+			body:        `{"event_id": "e-1", "event_type": "trip.cancelled", "trip_id": "656f1c2a9e3b4d4e5f8a9b0c", "sequence": 4, "driver_id": 7, "status": "cancelled", "available_seats": 3, "reserved_seats": 0, "cancelled_by": 7, "cancellation_reason": "Car broke down", "source_service": "trips-api", "correlation_id": "c-1", "timestamp": "2025-12-15T12:00:00.123456Z"}`,
+			wantVersion: 1,
+		},
+		{
+			name:        "renamed required field",
+			eventType:   "reservation.failed",
+			body:        `{"event_id": "e-2", "event_type": "reservation.failed", "trip_id": "656f1c2a9e3b4d4e5f8a9b0c", "booking_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "reason": "No seats available", "timestamp": "2025-12-15T12:00:00Z"}`,
+			wantVersion: 1,
+			wantErrors:  []string{"/reservation_id: is required"},
+		},
+		{
+			name:        "changed field type",
+			eventType:   "reservation.confirmed",
+			body:        `{"event_id": "e-3", "event_type": "reservation.confirmed", "trip_id": "656f1c2a9e3b4d4e5f8a9b0c", "reservation_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "passenger_id": "12", "driver_id": 7, "seats_reserved": 2, "timestamp": "2025-12-15T12:00:00Z"}`,
+			wantVersion: 1,
+			wantErrors:  []string{"/passenger_id: expected integer, got string"},
+		},
+		{
+			name:        "unknown version",
+			eventType:   "trip.cancelled",
+			body:        `{"schema_version": 2, "event_id": "e-4"}`,
+			wantVersion: 2,
+			wantErrors:  []string{"/schema_version: unknown version 2"},
+		},
+		{
+			name:       "invalid schema_version",
+			eventType:  "trip.cancelled",
+			body:       `{"schema_version": "1"}`,
+			wantErrors: []string{"/schema_version: must be a positive integer"},
+		},
+		{
+			name:      "event type without schema",
+			eventType: "trip.updated",
+			body:      `not json`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := r.Validate(tt.eventType, []byte(tt.body))
+			if version != tt.wantVersion {
+				t.Errorf("Validate() version = %d, want %d", version, tt.wantVersion)
+			}
+
+			if tt.wantErrors == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Errors, tt.wantErrors) {
+				t.Errorf("Validate() errors = %q, want %q", validationErr.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestRegistryValidate_InvalidJSON(t *testing.T) {
+	r := loadTestRegistry(t)
+
+	_, err := r.Validate("trip.cancelled", []byte(`{"event_id": `))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 {
+		t.Fatalf("Validate() error = %v, want one invalid JSON error", err)
+	}
+}
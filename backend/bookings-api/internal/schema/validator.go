@@ -0,0 +1,285 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Schema is the subset of JSON Schema (draft-07) used by the event schemas:
+// type, required, properties, additionalProperties (boolean), items, enum, const,
+// minimum/maximum, minLength/maxLength, pattern, minItems and format (date-time, uuid)
+//
+// Unsupported keywords are rejected when the schema is compiled, so a schema never
+// silently validates less than it says
+type Schema struct {
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	Format               string             `json:"format,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+var supportedKeywords = map[string]bool{
+	"$schema": true, "$id": true, "title": true, "description": true, "type": true, "required": true,
+	"properties": true, "additionalProperties": true, "items": true, "enum": true, "const": true,
+	"minimum": true, "maximum": true, "minLength": true, "maxLength": true, "pattern": true,
+	"minItems": true, "format": true,
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Compile parses a schema document and checks that it only uses supported keywords
+func Compile(data []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid schema JSON: %w", err)
+	}
+	if err := checkKeywords(raw, ""); err != nil {
+		return nil, err
+	}
+
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := s.compile(""); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func checkKeywords(raw interface{}, path string) error {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("schema at %q must be an object", pointer(path))
+	}
+	for key, value := range obj {
+		if !supportedKeywords[key] {
+			return fmt.Errorf("unsupported schema keyword %q at %q", key, pointer(path))
+		}
+		switch key {
+		case "properties":
+			props, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("properties at %q must be an object", pointer(path))
+			}
+			for name, prop := range props {
+				if err := checkKeywords(prop, path+"/"+name); err != nil {
+					return err
+				}
+			}
+		case "items":
+			if err := checkKeywords(value, path+"/items"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) compile(path string) error {
+	switch s.Type {
+	case "", "object", "array", "string", "integer", "number", "boolean", "null":
+	default:
+		return fmt.Errorf("unsupported type %q at %q", s.Type, pointer(path))
+	}
+	switch s.Format {
+	case "", "date-time", "uuid":
+	default:
+		return fmt.Errorf("unsupported format %q at %q", s.Format, pointer(path))
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern at %q: %w", pointer(path), err)
+		}
+		s.pattern = re
+	}
+	for name, prop := range s.Properties {
+		if err := prop.compile(path + "/" + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "/items")
+	}
+	return nil
+}
+
+// Validate checks a decoded JSON document (as produced by json.Unmarshal into interface{})
+// and returns one message per violation, prefixed with the JSON pointer of the offending value
+func (s *Schema) Validate(doc interface{}) []string {
+	var errs []string
+	s.validate(doc, "", &errs)
+	return errs
+}
+
+func (s *Schema) validate(value interface{}, path string, errs *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, pointer(path)+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		fail("expected %s, got %s", s.Type, typeOf(value))
+		return
+	}
+
+	if s.Const != nil && !jsonEqual(value, s.Const) {
+		fail("must be %v", s.Const)
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if jsonEqual(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %v", s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, pointer(path+"/"+name)+": is required")
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, pointer(path+"/"+name)+": is not allowed")
+				}
+				continue
+			}
+			prop.validate(v[name], path+"/"+name, errs)
+		}
+
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s/%d", path, i), errs)
+			}
+		}
+
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match %s", s.Pattern)
+		}
+		switch s.Format {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				fail("must be an RFC 3339 date-time")
+			}
+		case "uuid":
+			if !uuidPattern.MatchString(v) {
+				fail("must be a UUID")
+			}
+		}
+
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be <= %v", *s.Maximum)
+		}
+	}
+}
+
+func hasType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonEqual compares two decoded JSON values (schema literals and document values)
+func jsonEqual(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+// pointer formats a path as a JSON pointer ("/" for the document root)
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
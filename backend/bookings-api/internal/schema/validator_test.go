@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["event_id", "status", "seats"],
+  "additionalProperties": false,
+  "properties": {
+    "event_id": {"type": "string", "format": "uuid"},
+    "status": {"type": "string", "enum": ["confirmed", "failed"]},
+    "seats": {"type": "integer", "minimum": 1, "maximum": 8},
+    "price": {"type": "number"},
+    "timestamp": {"type": "string", "format": "date-time"},
+    "trip": {
+      "type": "object",
+      "required": ["id"],
+      "additionalProperties": false,
+      "properties": {
+        "id": {"type": "string", "pattern": "^[0-9a-f]{24}$"},
+        "driver_id": {"type": "integer"}
+      }
+    },
+    "tags": {"type": "array", "minItems": 1, "items": {"type": "string", "minLength": 2, "maxLength": 5}},
+    "extra": {"type": "object"}
+  }
+}`
+
+func mustCompile(t *testing.T, data string) *Schema {
+	t.Helper()
+	s, err := Compile([]byte(data))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	return s
+}
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var doc interface{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("invalid test document: %v", err)
+	}
+	return doc
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s := mustCompile(t, testSchema)
+
+	tests := []struct {
+		name string
+		doc  string
+		want []string
+	}{
+		{
+			name: "valid",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "confirmed", "seats": 2, "price": 10.5, "timestamp": "2025-12-15T12:00:00Z", "trip": {"id": "656f1c2a9e3b4d4e5f8a9b0c"}, "tags": ["ab"], "extra": {"anything": true}}`,
+			want: nil,
+		},
+		{
+			name: "missing required fields",
+			doc:  `{"status": "confirmed"}`,
+			want: []string{"/event_id: is required", "/seats: is required"},
+		},
+		{
+			name: "type mismatches",
+			doc:  `{"event_id": 12, "status": "confirmed", "seats": 1.5, "price": "10"}`,
+			want: []string{"/event_id: expected string, got integer", "/price: expected number, got string", "/seats: expected integer, got number"},
+		},
+		{
+			name: "null is not a string",
+			doc:  `{"event_id": null, "status": "failed", "seats": 1}`,
+			want: []string{"/event_id: expected string, got null"},
+		},
+		{
+			name: "root must be an object",
+			doc:  `[1, 2]`,
+			want: []string{"/: expected object, got array"},
+		},
+		{
+			name: "enum",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "pending", "seats": 1}`,
+			want: []string{"/status: must be one of [confirmed failed]"},
+		},
+		{
+			name: "minimum and maximum",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "failed", "seats": 9}`,
+			want: []string{"/seats: must be <= 8"},
+		},
+		{
+			name: "formats",
+			doc:  `{"event_id": "not-a-uuid", "status": "failed", "seats": 1, "timestamp": "15/12/2025"}`,
+			want: []string{"/event_id: must be a UUID", "/timestamp: must be an RFC 3339 date-time"},
+		},
+		{
+			name: "nested object",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "failed", "seats": 1, "trip": {"id": "XYZ", "driver_id": "7"}}`,
+			want: []string{"/trip/driver_id: expected integer, got string", "/trip/id: must match ^[0-9a-f]{24}$"},
+		},
+		{
+			name: "nested required",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "failed", "seats": 1, "trip": {}}`,
+			want: []string{"/trip/id: is required"},
+		},
+		{
+			name: "additional properties not allowed",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "failed", "seats": 1, "seat_count": 1, "trip": {"id": "656f1c2a9e3b4d4e5f8a9b0c", "driver": 7}}`,
+			want: []string{"/seat_count: is not allowed", "/trip/driver: is not allowed"},
+		},
+		{
+			name: "array items",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "failed", "seats": 1, "tags": ["a", "abcdef", 3]}`,
+			want: []string{"/tags/0: must be at least 2 characters", "/tags/1: must be at most 5 characters", "/tags/2: expected string, got integer"},
+		},
+		{
+			name: "min items",
+			doc:  `{"event_id": "6f1c2a9e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", "status": "failed", "seats": 1, "tags": []}`,
+			want: []string{"/tags: must have at least 1 items"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Validate(decode(t, tt.doc))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaValidate_AdditionalPropertiesAllowedByDefault(t *testing.T) {
+	s := mustCompile(t, `{"type": "object", "properties": {"id": {"type": "string"}}}`)
+
+	if errs := s.Validate(decode(t, `{"id": "a", "new_field": 1}`)); len(errs) != 0 {
+		t.Errorf("Validate() = %q, want no errors", errs)
+	}
+}
+
+func TestSchemaValidate_Const(t *testing.T) {
+	s := mustCompile(t, `{"type": "object", "properties": {"event_type": {"type": "string", "const": "trip.cancelled"}}}`)
+
+	want := []string{"/event_type: must be trip.cancelled"}
+	if got := s.Validate(decode(t, `{"event_type": "trip.updated"}`)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %q, want %q", got, want)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{"invalid JSON", `{"type": `, "invalid schema JSON"},
+		{"unsupported keyword", `{"type": "object", "oneOf": []}`, `unsupported schema keyword "oneOf" at "/"`},
+		{"unsupported nested keyword", `{"properties": {"trip": {"properties": {"id": {"$ref": "#/x"}}}}}`, `unsupported schema keyword "$ref" at "/trip/id"`},
+		{"unsupported keyword in items", `{"items": {"anyOf": []}}`, `unsupported schema keyword "anyOf" at "/items"`},
+		{"unsupported type", `{"type": "date"}`, `unsupported type "date" at "/"`},
+		{"unsupported format", `{"properties": {"email": {"type": "string", "format": "email"}}}`, `unsupported format "email" at "/email"`},
+		{"invalid pattern", `{"type": "string", "pattern": "("}`, `invalid pattern at "/"`},
+		{"additionalProperties schema", `{"additionalProperties": {"type": "string"}}`, "invalid schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Compile() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
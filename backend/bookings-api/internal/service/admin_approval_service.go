@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// maxApprovalsExpiredPerCall bounds the expirations done lazily by a single request
+const maxApprovalsExpiredPerCall = 100
+
+// AdminApprovalService runs destructive admin actions only after a second admin approves them
+//
+// Flow: an admin requests the action (pending). A different admin approves it before the TTL
+// passes, and the action runs in the same request: executed, or failed if the booking changed
+// meanwhile. Any admin can reject a pending approval (the requester withdrawing it included).
+// Pending approvals past their deadline expire the next time they are read.
+// Every step is written to admin_approval_events in the same transaction as the status change.
+type AdminApprovalService interface {
+	// Request creates a pending approval; the booking must currently be in a status the action applies to
+	Request(ctx context.Context, req domain.CreateAdminApprovalRequest, adminID int64) (*domain.AdminApprovalResponse, error)
+
+	// List returns approvals, newest first (status defaults to "pending")
+	List(filter repository.AdminApprovalFilter) (*domain.AdminApprovalList, error)
+
+	// Get returns an approval with its audit trail
+	Get(id uint) (*domain.AdminApprovalResponse, error)
+
+	// Approve records the second admin's approval and runs the action
+	// Returns the error of the action if it failed (the approval stays as failed)
+	Approve(ctx context.Context, id uint, adminID int64) (*domain.AdminApprovalResponse, error)
+
+	// Reject closes a pending approval without running the action
+	Reject(id uint, adminID int64, note string) (*domain.AdminApprovalResponse, error)
+}
+
+type adminApprovalService struct {
+	repo           repository.AdminApprovalRepository
+	bookingRepo    repository.BookingRepository
+	bookingService BookingService
+	paymentSplit   PaymentSplitService
+	ttl            time.Duration
+}
+
+// NewAdminApprovalService creates a new instance of AdminApprovalService
+// ttl is how long the second admin has to approve (defaults to one hour)
+func NewAdminApprovalService(repo repository.AdminApprovalRepository, bookingRepo repository.BookingRepository, bookingService BookingService, paymentSplit PaymentSplitService, ttl time.Duration) AdminApprovalService {
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &adminApprovalService{
+		repo:           repo,
+		bookingRepo:    bookingRepo,
+		bookingService: bookingService,
+		paymentSplit:   paymentSplit,
+		ttl:            ttl,
+	}
+}
+
+// Request validates the action against the booking's current status and stores it as pending
+// The status is checked again when the action runs, since the booking may change meanwhile
+func (s *adminApprovalService) Request(ctx context.Context, req domain.CreateAdminApprovalRequest, adminID int64) (*domain.AdminApprovalResponse, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	req.PaymentReference = strings.TrimSpace(req.PaymentReference)
+	if req.Reason == "" {
+		return nil, domain.NewAppError("VALIDATION_ERROR", "reason is required", nil)
+	}
+	if req.Action == domain.AdminActionMarkBookingPaid && req.PaymentReference == "" {
+		return nil, domain.NewAppError("VALIDATION_ERROR", "payment_reference is required for mark_booking_paid", nil)
+	}
+
+	booking, err := s.bookingRepo.FindByID(req.BookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBookingNotFound.WithDetails(map[string]interface{}{
+				"booking_id": req.BookingID,
+			})
+		}
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	expected := dao.BookingStatusConfirmed
+	if req.Action == domain.AdminActionMarkBookingPaid {
+		expected = dao.BookingStatusAwaitingPayment
+	}
+	if booking.Status != expected {
+		return nil, domain.ErrAdminActionNotApplicable.WithDetails(map[string]interface{}{
+			"booking_id": req.BookingID,
+			"status":     booking.Status,
+			"expected":   expected,
+		})
+	}
+
+	// An expired leftover must not block a new request for the same action
+	s.expireOverdue(time.Now())
+	existing, err := s.repo.FindPending(req.Action, req.BookingID)
+	if err == nil {
+		return nil, domain.ErrAdminApprovalAlreadyPending.WithDetails(map[string]interface{}{
+			"approval_id": existing.ID,
+		})
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	approval := &dao.AdminApproval{
+		Action:           req.Action,
+		BookingUUID:      req.BookingID,
+		Reason:           req.Reason,
+		PaymentReference: req.PaymentReference,
+		Status:           dao.AdminApprovalStatusPending,
+		RequestedBy:      adminID,
+		ExpiresAt:        time.Now().Add(s.ttl),
+	}
+	if err := s.repo.Create(approval); err != nil {
+		return nil, fmt.Errorf("failed to create admin approval: %w", err)
+	}
+
+	log.Info().
+		Uint("approval_id", approval.ID).
+		Str("action", approval.Action).
+		Str("booking_id", approval.BookingUUID).
+		Int64("requested_by", adminID).
+		Time("expires_at", approval.ExpiresAt).
+		Msg("Admin action waiting for a second approver")
+
+	return s.Get(approval.ID)
+}
+
+// List returns approvals, newest first
+func (s *adminApprovalService) List(filter repository.AdminApprovalFilter) (*domain.AdminApprovalList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Status == "" {
+		filter.Status = dao.AdminApprovalStatusPending
+	}
+
+	s.expireOverdue(time.Now())
+	approvals, total, err := s.repo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AdminApprovalList{
+		Approvals: approvals,
+		Total:     total,
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+	}, nil
+}
+
+// Get returns an approval with its audit trail
+func (s *adminApprovalService) Get(id uint) (*domain.AdminApprovalResponse, error) {
+	approval, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.expireIfOverdue(approval, time.Now()) {
+		if approval, err = s.find(id); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := s.repo.FindEvents(id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.AdminApprovalResponse{AdminApproval: *approval, Events: events}, nil
+}
+
+// Approve moves the approval to approved (so no one else can approve or reject it) and runs the action
+func (s *adminApprovalService) Approve(ctx context.Context, id uint, adminID int64) (*domain.AdminApprovalResponse, error) {
+	approval, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPending(approval); err != nil {
+		return nil, err
+	}
+	if approval.RequestedBy == adminID {
+		return nil, domain.ErrAdminApprovalSelfApproval
+	}
+
+	now := time.Now()
+	approved, err := s.repo.Transition(id, dao.AdminApprovalStatusPending, dao.AdminApprovalStatusApproved, map[string]interface{}{
+		"decided_by": adminID,
+		"decided_at": now,
+	}, &dao.AdminApprovalEvent{Event: dao.AdminApprovalEventApproved, AdminID: adminID})
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return nil, domain.ErrAdminApprovalNotPending
+	}
+
+	actionErr := s.execute(ctx, approval, adminID)
+	if actionErr != nil {
+		log.Error().
+			Err(actionErr).
+			Uint("approval_id", id).
+			Str("action", approval.Action).
+			Str("booking_id", approval.BookingUUID).
+			Msg("Approved admin action failed")
+		if _, err := s.repo.Transition(id, dao.AdminApprovalStatusApproved, dao.AdminApprovalStatusFailed, map[string]interface{}{
+			"last_error": actionErr.Error(),
+		}, &dao.AdminApprovalEvent{Event: dao.AdminApprovalEventFailed, Note: actionErr.Error()}); err != nil {
+			log.Error().Err(err).Uint("approval_id", id).Msg("Failed to record admin action failure")
+		}
+		return nil, actionErr
+	}
+
+	if _, err := s.repo.Transition(id, dao.AdminApprovalStatusApproved, dao.AdminApprovalStatusExecuted, map[string]interface{}{
+		"executed_at": time.Now(),
+	}, &dao.AdminApprovalEvent{Event: dao.AdminApprovalEventExecuted}); err != nil {
+		// The action already ran; the booking history records it even if this update is lost
+		log.Error().Err(err).Uint("approval_id", id).Msg("Admin action executed but failed to record it")
+	}
+
+	log.Info().
+		Uint("approval_id", id).
+		Str("action", approval.Action).
+		Str("booking_id", approval.BookingUUID).
+		Int64("requested_by", approval.RequestedBy).
+		Int64("approved_by", adminID).
+		Msg("✅ Admin action approved and executed")
+
+	return s.Get(id)
+}
+
+// Reject closes a pending approval without running the action
+func (s *adminApprovalService) Reject(id uint, adminID int64, note string) (*domain.AdminApprovalResponse, error) {
+	approval, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkPending(approval); err != nil {
+		return nil, err
+	}
+
+	note = strings.TrimSpace(note)
+	rejected, err := s.repo.Transition(id, dao.AdminApprovalStatusPending, dao.AdminApprovalStatusRejected, map[string]interface{}{
+		"decided_by":    adminID,
+		"decided_at":    time.Now(),
+		"decision_note": note,
+	}, &dao.AdminApprovalEvent{Event: dao.AdminApprovalEventRejected, AdminID: adminID, Note: note})
+	if err != nil {
+		return nil, err
+	}
+	if !rejected {
+		return nil, domain.ErrAdminApprovalNotPending
+	}
+
+	log.Info().
+		Uint("approval_id", id).
+		Str("action", approval.Action).
+		Str("booking_id", approval.BookingUUID).
+		Int64("rejected_by", adminID).
+		Msg("Admin action rejected")
+
+	return s.Get(id)
+}
+
+// execute runs the approved action; the booking history reason names both admins
+func (s *adminApprovalService) execute(ctx context.Context, approval *dao.AdminApproval, approvedBy int64) error {
+	reason := domain.AdminOverrideReason(approval, approvedBy)
+	switch approval.Action {
+	case dao.AdminActionForceCancelBooking:
+		return s.bookingService.ForceCancelBooking(ctx, approval.BookingUUID, reason)
+	case dao.AdminActionMarkBookingPaid:
+		return s.paymentSplit.MarkBookingPaid(ctx, approval.BookingUUID, approval.PaymentReference, reason)
+	default:
+		return fmt.Errorf("unknown admin action %q", approval.Action)
+	}
+}
+
+// checkPending rejects decisions on approvals that are not pending, expiring the overdue ones
+func (s *adminApprovalService) checkPending(approval *dao.AdminApproval) error {
+	if approval.Status != dao.AdminApprovalStatusPending {
+		return domain.ErrAdminApprovalNotPending.WithDetails(map[string]interface{}{
+			"approval_id": approval.ID,
+			"status":      approval.Status,
+		})
+	}
+	if s.expireIfOverdue(approval, time.Now()) {
+		return domain.ErrAdminApprovalExpired.WithDetails(map[string]interface{}{
+			"approval_id": approval.ID,
+			"expires_at":  approval.ExpiresAt,
+		})
+	}
+	return nil
+}
+
+// expireIfOverdue moves a pending approval past its deadline to expired
+// Returns true if the approval is overdue (whether this call or a concurrent one expired it)
+func (s *adminApprovalService) expireIfOverdue(approval *dao.AdminApproval, now time.Time) bool {
+	if approval.Status != dao.AdminApprovalStatusPending || now.Before(approval.ExpiresAt) {
+		return false
+	}
+	if _, err := s.repo.Transition(approval.ID, dao.AdminApprovalStatusPending, dao.AdminApprovalStatusExpired, nil,
+		&dao.AdminApprovalEvent{Event: dao.AdminApprovalEventExpired}); err != nil {
+		log.Error().Err(err).Uint("approval_id", approval.ID).Msg("Failed to expire admin approval")
+	}
+	return true
+}
+
+// expireOverdue expires the pending approvals past their deadline, so listings never show them as pending
+func (s *adminApprovalService) expireOverdue(now time.Time) {
+	approvals, err := s.repo.FindPendingExpiredBefore(now, maxApprovalsExpiredPerCall)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find expired admin approvals")
+		return
+	}
+	for i := range approvals {
+		s.expireIfOverdue(&approvals[i], now)
+	}
+}
+
+// find loads an approval, mapping a missing row to ErrAdminApprovalNotFound
+func (s *adminApprovalService) find(id uint) (*dao.AdminApproval, error) {
+	approval, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrAdminApprovalNotFound
+		}
+		return nil, err
+	}
+	return approval, nil
+}
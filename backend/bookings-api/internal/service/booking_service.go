@@ -4,12 +4,18 @@ import (
 	"bookings-api/internal/clients"
 	"bookings-api/internal/dao"
 	"bookings-api/internal/domain"
+	"bookings-api/internal/events"
+	"bookings-api/internal/policy"
 	"bookings-api/internal/publisher"
 	"bookings-api/internal/repository"
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
@@ -30,25 +36,65 @@ type BookingService interface {
 
 	// CancelBooking cancels a booking (must be passenger or driver)
 	CancelBooking(ctx context.Context, bookingID string, userID int64, reason string) error
+
+	// ForceCancelBooking cancels a confirmed booking without fee on behalf of the admins
+	// (run once a second admin approved it); reason is stored as the cancellation reason
+	ForceCancelBooking(ctx context.Context, bookingID, reason string) error
+
+	// ModifyBookingSeats changes the seats of a confirmed booking (must be passenger)
+	ModifyBookingSeats(ctx context.Context, bookingID string, userID int64, seats int) (*domain.BookingResponse, error)
+
+	// GetBookingState returns the derived saga state of a booking (passenger, driver or admin)
+	GetBookingState(ctx context.Context, bookingID string, userID int64, isAdmin bool) (*domain.BookingStateResponse, error)
+
+	// GetTripBookings lists the bookings of a trip with passenger contact details (must be the trip's driver)
+	GetTripBookings(ctx context.Context, tripID string, userID int64, statusFilter string) (*domain.TripBookingsResponse, error)
+
+	// GetBookingAsOf reconstructs a booking's state at a past moment from its status history (admin only)
+	GetBookingAsOf(ctx context.Context, bookingID string, asOf time.Time) (*domain.BookingAsOfResponse, error)
+
+	// GetEffectivePolicy returns the country policy that applies to a trip (admin only)
+	GetEffectivePolicy(ctx context.Context, tripID string) (*domain.EffectivePolicyResponse, error)
+
+	// GetUserCO2Savings aggregates the estimated CO2 savings of a user's bookings
+	GetUserCO2Savings(ctx context.Context, userID int64) (*domain.CO2SavingsResponse, error)
 }
 
 // bookingService implements BookingService
 type bookingService struct {
 	bookingRepo repository.BookingRepository
 	tripsClient clients.TripsClient
+	usersClient clients.UsersClient
 	publisher   publisher.Publisher
+	policies    policy.Registry
+	seatHolds   SeatHoldService
+	payments    PaymentService
+	credits     CreditsService
+	statusHub   BookingStatusHub
 }
 
 // NewBookingService creates a new BookingService with dependency injection
 func NewBookingService(
 	bookingRepo repository.BookingRepository,
 	tripsClient clients.TripsClient,
+	usersClient clients.UsersClient,
 	pub publisher.Publisher,
+	policies policy.Registry,
+	seatHolds SeatHoldService,
+	payments PaymentService,
+	credits CreditsService,
+	statusHub BookingStatusHub,
 ) BookingService {
 	return &bookingService{
 		bookingRepo: bookingRepo,
 		tripsClient: tripsClient,
+		usersClient: usersClient,
 		publisher:   pub,
+		policies:    policies,
+		seatHolds:   seatHolds,
+		payments:    payments,
+		credits:     credits,
+		statusHub:   statusHub,
 	}
 }
 
@@ -75,7 +121,8 @@ func (s *bookingService) CreateBooking(ctx context.Context, req domain.CreateBoo
 	for _, existingBooking := range existingBookings {
 		if existingBooking.PassengerID == req.PassengerID &&
 			!existingBooking.IsCancelled() &&
-			!existingBooking.IsFailed() {
+			!existingBooking.IsFailed() &&
+			!existingBooking.IsExpired() {
 			log.Warn().
 				Str("trip_id", req.TripID).
 				Int64("passenger_id", req.PassengerID).
@@ -89,26 +136,148 @@ func (s *bookingService) CreateBooking(ctx context.Context, req domain.CreateBoo
 		}
 	}
 
-	// Step 2: Create booking entity in pending state
+	// Step 2: Resolve the country policy from the trip and enforce max seats per booking
+	// If trips-api is unavailable we fall back to the default country policy instead of
+	// failing: the async validation by trips-api remains the source of truth for the trip
+	trip, countryPolicy := s.resolveTripPolicy(ctx, req.TripID)
+	if req.SeatsReserved > countryPolicy.MaxSeatsPerBooking {
+		log.Warn().
+			Str("trip_id", req.TripID).
+			Str("country", countryPolicy.Country).
+			Int("seats_requested", req.SeatsReserved).
+			Int("max_seats", countryPolicy.MaxSeatsPerBooking).
+			Msg("Requested seats exceed country policy")
+		return nil, domain.ErrMaxSeatsExceeded.WithDetails(map[string]interface{}{
+			"country":         countryPolicy.Country,
+			"seats_requested": req.SeatsReserved,
+			"max_seats":       countryPolicy.MaxSeatsPerBooking,
+		})
+	}
+
+	// Passenger details are optional, but when given there must be one per seat
+	passengers, err := buildBookingPassengers(req.Passengers, req.SeatsReserved)
+	if err != nil {
+		log.Warn().
+			Str("trip_id", req.TripID).
+			Int("seats_requested", req.SeatsReserved).
+			Int("passengers", len(req.Passengers)).
+			Msg("Invalid passenger details")
+		return nil, err
+	}
+
+	// Split payment divides the fare per passenger: it needs the passenger of each seat
+	if req.SplitPayment && (req.SeatsReserved < domain.MinSplitPaymentSeats || len(passengers) == 0) {
+		log.Warn().
+			Str("trip_id", req.TripID).
+			Int("seats_requested", req.SeatsReserved).
+			Int("passengers", len(req.Passengers)).
+			Msg("Split payment without a passenger per seat")
+		return nil, domain.ErrSplitPaymentRequiresPassengers.WithDetails(map[string]interface{}{
+			"seats_requested": req.SeatsReserved,
+			"passengers":      len(req.Passengers),
+			"min_seats":       domain.MinSplitPaymentSeats,
+		})
+	}
+
+	// Pickup point must belong to the trip (checked here only when the trip snapshot is available;
+	// trips-api validates it again when processing reservation.created)
+	if req.PickupPointID != "" && trip != nil && !trip.HasPickupPoint(req.PickupPointID) {
+		log.Warn().
+			Str("trip_id", req.TripID).
+			Str("pickup_point_id", req.PickupPointID).
+			Msg("Pickup point does not belong to trip")
+		return nil, domain.ErrInvalidPickupPoint.WithDetails(map[string]interface{}{
+			"trip_id":         req.TripID,
+			"pickup_point_id": req.PickupPointID,
+		})
+	}
+
+	// Step 3: Hold the seats in trips-api (when enabled) so a nearly-full trip is rejected right away
+	// The booking UUID is generated here so trips-api can match the hold with reservation.created
+	// If trips-api cannot be reached the booking continues with the optimistic flow (hold is nil)
+	// Dependent accounts ask for their guardian's approval instead: no seats are held and trips-api
+	// only hears about the booking once the guardian approves it (GuardianApprovalService)
+	bookingUUID := uuid.New().String()
+	var hold *domain.SeatHold
+	var approval *domain.GuardianApproval
+	if req.RequiresGuardianApproval {
+		approval, err = s.usersClient.RequestGuardianApproval(ctx, domain.GuardianApprovalRequest{
+			DependentID: req.PassengerID,
+			Action:      domain.GuardianApprovalActionBooking,
+			ResourceID:  bookingUUID,
+			TripID:      req.TripID,
+			Details:     guardianApprovalDetails(trip, req.SeatsReserved),
+		})
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("trip_id", req.TripID).
+				Int64("passenger_id", req.PassengerID).
+				Msg("Failed to request guardian approval")
+			return nil, err
+		}
+	} else {
+		hold, err = s.seatHolds.Hold(ctx, req.TripID, bookingUUID, req.PassengerID, req.SeatsReserved)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("trip_id", req.TripID).
+				Int("seats_requested", req.SeatsReserved).
+				Msg("trips-api rejected the seat hold")
+			return nil, err
+		}
+	}
+
+	// Step 4: Create booking entity in pending state
 	// All other validations (trip status, seats availability, etc.) will be done asynchronously by trips-api
 	// Total price will be set to 0 initially and updated when trips-api confirms the reservation
 	booking := &dao.Booking{
-		// BookingUUID will be auto-generated by GORM BeforeCreate hook
+		BookingUUID:    bookingUUID,
 		TripID:         req.TripID,
 		PassengerID:    req.PassengerID,
 		SeatsRequested: req.SeatsReserved,
 		TotalPrice:     0, // Will be updated when trips-api confirms with reservation.confirmed event
 		Status:         dao.BookingStatusPending,
+		Country:        countryPolicy.Country,
+		PickupPointID:  req.PickupPointID,
+		SplitPayment:   req.SplitPayment,
 		// CreatedAt and UpdatedAt will be auto-managed by GORM
 	}
+	if hold != nil {
+		booking.SeatHoldID = hold.ID
+	}
+	if approval != nil {
+		booking.Status = dao.BookingStatusAwaitingGuardian
+		booking.GuardianApprovalID = &approval.ID
+	}
+	if trip != nil && !trip.DepartureDatetime.IsZero() {
+		departure := trip.DepartureDatetime
+		booking.DepartureAt = &departure
+	}
+	// Estimate CO2 savings from the trip route (0 if the trip snapshot is unavailable)
+	// Also keep the route for the anonymized analytics record written when the booking is archived
+	if trip != nil {
+		booking.DistanceKm = trip.RouteDistanceKm()
+		booking.CO2SavedKg = domain.EstimateCO2SavedKg(booking.DistanceKm, booking.SeatsRequested)
+		booking.OriginCity = trip.Origin.City
+		booking.DestinationCity = trip.Destination.City
+	}
+
+	// Bookings paid through the payment provider store the payment with the booking
+	// (split-payment bookings are paid through their payment shares instead)
+	var payment *dao.Payment
+	if !req.SplitPayment {
+		payment = s.payments.NewBookingPayment(req.PaymentMethodToken, countryPolicy.Currency)
+	}
 
-	// Step 3: Save to database
-	if err := s.bookingRepo.Create(booking); err != nil {
+	// Step 5: Save to database (the hold is released if the booking could not be stored)
+	if err := s.bookingRepo.Create(booking, passengers, payment); err != nil {
 		log.Error().
 			Err(err).
 			Str("trip_id", req.TripID).
 			Int64("passenger_id", req.PassengerID).
 			Msg("Failed to create booking in database")
+		_, _ = s.seatHolds.Release(ctx, booking)
 		return nil, fmt.Errorf("failed to create booking: %w", err)
 	}
 
@@ -118,18 +287,44 @@ func (s *bookingService) CreateBooking(ctx context.Context, req domain.CreateBoo
 		Int64("passenger_id", booking.PassengerID).
 		Int("seats", booking.SeatsRequested).
 		Float64("total_price", booking.TotalPrice).
-		Msg("✅ Booking created successfully in pending state")
+		Str("status", booking.Status).
+		Msg("✅ Booking created successfully")
 
-	// Step 4: Publish reservation.created event to RabbitMQ for async validation
+	// Bookings awaiting the guardian are published by GuardianApprovalService once approved
+	if booking.IsAwaitingGuardian() {
+		log.Info().
+			Str("booking_id", booking.BookingUUID).
+			Int64("guardian_approval_id", approval.ID).
+			Msg("Booking awaiting guardian approval - reservation.created deferred")
+		response := domain.ToBookingResponse(booking)
+		if len(passengers) > 0 {
+			response.Passengers = domain.ToBookingPassengers(passengers)
+		}
+		return response, nil
+	}
+
+	// Step 6: Publish reservation.created event to RabbitMQ for async validation
 	// trips-api will validate and respond with reservation.confirmed or reservation.failed
 	// IMPORTANT: Eventual consistency pattern - if publish fails, DON'T rollback database
 	// The booking is already saved (source of truth), event is just a notification
-	if err := s.publisher.PublishReservationCreated(
+	err = s.publisher.PublishReservationCreated(
+		ctx,
 		booking.TripID,
 		booking.PassengerID,
 		booking.SeatsRequested,
 		booking.BookingUUID,
-	); err != nil {
+		booking.PickupPointID,
+		booking.SeatHoldID,
+		toReservationPassengers(passengers),
+	)
+	if errors.Is(err, publisher.ErrEventBuffered) {
+		// Not published yet: the booking stays pending and keeps its hold until the buffer is flushed
+		// (if the process stops first, the hold expires and the expiration job expires the booking)
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("event_type", "reservation.created").
+			Msg("⏳ Booking created, reservation.created buffered until RabbitMQ is available - booking stays pending")
+	} else if err != nil {
 		// Log error but DON'T return error to user
 		// Database is source of truth, event publish failure doesn't invalidate booking
 		log.Error().
@@ -139,6 +334,9 @@ func (s *bookingService) CreateBooking(ctx context.Context, req domain.CreateBoo
 			Int("seats_reserved", booking.SeatsRequested).
 			Msg("⚠️  Booking created but failed to publish reservation.created event (eventual consistency)")
 		// Continue and return success - trips-api can sync via reconciliation if needed
+		// Without the event trips-api never confirms the hold: release it now instead of waiting for its TTL
+		// (the booking stays pending until the expiration job expires it)
+		_, _ = s.seatHolds.Release(ctx, booking)
 	} else {
 		log.Info().
 			Str("booking_id", booking.BookingUUID).
@@ -146,9 +344,68 @@ func (s *bookingService) CreateBooking(ctx context.Context, req domain.CreateBoo
 			Msg("✅ Reservation event published successfully - awaiting async validation from trips-api")
 	}
 
-	// Step 5: Return response DTO
+	// Step 7: Return response DTO
 	// Booking is in pending state - will be updated to confirmed/failed by trips-api event
-	return domain.ToBookingResponse(booking), nil
+	response := domain.ToBookingResponse(booking)
+	if len(passengers) > 0 {
+		response.Passengers = domain.ToBookingPassengers(passengers)
+	}
+	return response, nil
+}
+
+// guardianApprovalDetails describes the booking for the guardian (route and departure, when the trip snapshot is available)
+func guardianApprovalDetails(trip *domain.Trip, seats int) string {
+	if trip == nil {
+		return fmt.Sprintf("%d seat(s)", seats)
+	}
+	return fmt.Sprintf("%s → %s, %s, %d seat(s)",
+		trip.Origin.City, trip.Destination.City, trip.DepartureDatetime.Format("2006-01-02 15:04"), seats)
+}
+
+// buildBookingPassengers validates the passenger details of a new booking and numbers the seats
+// Returns nil when no details were given
+func buildBookingPassengers(details []domain.BookingPassenger, seats int) ([]dao.BookingPassenger, error) {
+	if len(details) == 0 {
+		return nil, nil
+	}
+	if len(details) != seats {
+		return nil, domain.ErrPassengerCountMismatch.WithDetails(map[string]interface{}{
+			"seats_reserved": seats,
+			"passengers":     len(details),
+		})
+	}
+
+	passengers := make([]dao.BookingPassenger, 0, len(details))
+	for i, detail := range details {
+		name := strings.TrimSpace(detail.Name)
+		document := strings.TrimSpace(detail.Document)
+		if name == "" || document == "" {
+			return nil, domain.NewAppError("VALIDATION_ERROR", "Every passenger needs a name and a document", map[string]interface{}{
+				"seat_number": i + 1,
+			})
+		}
+		passengers = append(passengers, dao.BookingPassenger{
+			SeatNumber: i + 1,
+			Name:       name,
+			Document:   document,
+		})
+	}
+	return passengers, nil
+}
+
+// toReservationPassengers converts the passengers of a booking to the reservation.created payload
+func toReservationPassengers(passengers []dao.BookingPassenger) []events.ReservationPassenger {
+	if len(passengers) == 0 {
+		return nil
+	}
+	result := make([]events.ReservationPassenger, 0, len(passengers))
+	for _, p := range passengers {
+		result = append(result, events.ReservationPassenger{
+			SeatNumber: p.SeatNumber,
+			Name:       p.Name,
+		})
+	}
+	return result
 }
 
 // GetBooking retrieves a booking by ID
@@ -167,7 +424,17 @@ func (s *bookingService) GetBooking(ctx context.Context, bookingID string) (*dom
 		return nil, fmt.Errorf("failed to get booking: %w", err)
 	}
 
-	return domain.ToBookingResponse(booking), nil
+	passengers, err := s.bookingRepo.FindPassengers(bookingID)
+	if err != nil {
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking passengers")
+		return nil, fmt.Errorf("failed to get booking passengers: %w", err)
+	}
+
+	response := domain.ToBookingResponse(booking)
+	if len(passengers) > 0 {
+		response.Passengers = domain.ToBookingPassengers(passengers)
+	}
+	return response, nil
 }
 
 // GetPassengerBookings retrieves all bookings for a passenger with pagination
@@ -267,8 +534,45 @@ func (s *bookingService) CancelBooking(ctx context.Context, bookingID string, us
 		})
 	}
 
-	// Step 5: Cancel the booking
-	if err := s.bookingRepo.CancelBooking(bookingID, reason); err != nil {
+	// Expired bookings already released their seats (reservation.cancelled published by the expiration job)
+	if booking.IsExpired() {
+		log.Warn().
+			Str("booking_id", bookingID).
+			Str("status", booking.Status).
+			Msg("Cannot cancel - booking expired")
+		return domain.ErrBookingExpired.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"status":     booking.Status,
+		})
+	}
+
+	// Step 5: Calculate the late cancellation fee from the booking's country policy (see quoteCancellation)
+	// Only passengers pay; drivers cancelling bookings on their own trips never charge the passenger
+	quote := s.quoteCancellation(ctx, booking, isPassenger && !isDriver, time.Now())
+
+	// Step 6: Cancel the booking
+	// Bookings awaiting the guardian are cancelled only if still awaiting: if the guardian approval job
+	// already moved them to pending (and published reservation.created) the passenger must retry
+	if booking.IsAwaitingGuardian() {
+		now := time.Now()
+		err := s.bookingRepo.TransitionStatus(bookingID, dao.BookingStatusAwaitingGuardian, dao.BookingStatusCancelled, map[string]interface{}{
+			"cancelled_at":        &now,
+			"cancellation_reason": reason,
+			"cancellation_fee":    quote.Fee,
+		}, reason)
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return domain.ErrBookingModifiedConcurrently.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("booking_id", bookingID).
+				Msg("Failed to cancel booking")
+			return fmt.Errorf("failed to cancel booking: %w", err)
+		}
+	} else if err := s.bookingRepo.CancelBooking(bookingID, reason, quote.Fee); err != nil {
 		log.Error().
 			Err(err).
 			Str("booking_id", bookingID).
@@ -281,16 +585,37 @@ func (s *bookingService) CancelBooking(ctx context.Context, bookingID string, us
 		Int64("user_id", userID).
 		Bool("is_passenger", isPassenger).
 		Bool("is_driver", isDriver).
+		Float64("cancellation_fee", quote.Fee).
+		Float64("refund_amount", quote.RefundAmount).
 		Msg("✅ Booking cancelled successfully")
 
-	// Step 6: Publish reservation.cancelled event to RabbitMQ
+	s.statusHub.Publish(domain.NewBookingStatusEvent(bookingID, booking.Status, domain.BookingStatusCancelled, reason))
+	s.releasePayment(ctx, booking, quote)
+
+	// trips-api never heard of a booking still awaiting the guardian (no hold, no reservation.created):
+	// publishing reservation.cancelled would release seats it never reserved
+	if booking.IsAwaitingGuardian() {
+		return nil
+	}
+
+	// Step 7: Publish reservation.cancelled event to RabbitMQ
 	// IMPORTANT: Eventual consistency pattern - if publish fails, DON'T rollback database
 	// The booking is already cancelled (source of truth), event is just a notification
-	if err := s.publisher.PublishReservationCancelled(
+	err = s.publisher.PublishReservationCancelled(
+		ctx,
 		booking.TripID,
 		booking.SeatsRequested,
 		booking.BookingUUID,
-	); err != nil {
+		quote.Fee,
+		quote.RefundAmount,
+		quote.Currency,
+	)
+	if errors.Is(err, publisher.ErrEventBuffered) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("event_type", "reservation.cancelled").
+			Msg("⏳ Booking cancelled, reservation.cancelled buffered until RabbitMQ is available")
+	} else if err != nil {
 		// Log error but DON'T return error to user
 		// Database is source of truth, event publish failure doesn't invalidate cancellation
 		log.Error().
@@ -310,6 +635,209 @@ func (s *bookingService) CancelBooking(ctx context.Context, bookingID string, us
 	return nil
 }
 
+// ForceCancelBooking cancels a confirmed booking regardless of who booked it (admin override)
+// The passenger gets a full refund: the late cancellation fee only applies to passenger cancellations
+func (s *bookingService) ForceCancelBooking(ctx context.Context, bookingID, reason string) error {
+	booking, err := s.bookingRepo.FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrBookingNotFound.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	if !booking.IsConfirmed() {
+		return domain.ErrAdminActionNotApplicable.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"status":     booking.Status,
+			"expected":   dao.BookingStatusConfirmed,
+		})
+	}
+
+	quote := s.quoteCancellation(ctx, booking, false, time.Now())
+	if err := s.bookingRepo.CancelBooking(bookingID, reason, quote.Fee); err != nil {
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to force-cancel booking")
+		return fmt.Errorf("failed to cancel booking: %w", err)
+	}
+
+	log.Info().
+		Str("booking_id", bookingID).
+		Str("trip_id", booking.TripID).
+		Float64("refund_amount", quote.RefundAmount).
+		Msg("✅ Booking force-cancelled by admins")
+
+	s.statusHub.Publish(domain.NewBookingStatusEvent(bookingID, booking.Status, domain.BookingStatusCancelled, reason))
+	s.releasePayment(ctx, booking, quote)
+
+	// Same eventual consistency as CancelBooking: the booking stays cancelled if the publish fails
+	err = s.publisher.PublishReservationCancelled(
+		ctx,
+		booking.TripID,
+		booking.SeatsRequested,
+		booking.BookingUUID,
+		quote.Fee,
+		quote.RefundAmount,
+		quote.Currency,
+	)
+	if errors.Is(err, publisher.ErrEventBuffered) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("event_type", "reservation.cancelled").
+			Msg("⏳ Booking force-cancelled, reservation.cancelled buffered until RabbitMQ is available")
+	} else if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", booking.TripID).
+			Int("seats_released", booking.SeatsRequested).
+			Msg("⚠️  Booking force-cancelled but failed to publish reservation.cancelled event (eventual consistency)")
+	}
+
+	return nil
+}
+
+// ModifyBookingSeats changes the seats of a confirmed booking (authorization check: must be passenger)
+// Reducing seats releases them on the trip; increasing them is validated asynchronously by
+// trips-api, which publishes reservation.modification_failed if the trip has no seats left
+func (s *bookingService) ModifyBookingSeats(ctx context.Context, bookingID string, userID int64, seats int) (*domain.BookingResponse, error) {
+	log.Info().
+		Str("booking_id", bookingID).
+		Int64("user_id", userID).
+		Int("seats", seats).
+		Msg("Modifying booking seats")
+
+	// Step 1: Get the booking
+	booking, err := s.bookingRepo.FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warn().Str("booking_id", bookingID).Msg("Booking not found")
+			return nil, domain.ErrBookingNotFound.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking")
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	// Step 2: Authorization check - only the passenger can change their seats
+	if booking.PassengerID != userID {
+		log.Warn().
+			Str("booking_id", bookingID).
+			Int64("user_id", userID).
+			Int64("passenger_id", booking.PassengerID).
+			Msg("Unauthorized seat modification attempt")
+		return nil, domain.ErrUnauthorized.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"user_id":    userID,
+		})
+	}
+
+	// Step 3: Only confirmed bookings (price known, seats held by trips-api) before departure
+	if !booking.IsConfirmed() || (booking.DepartureAt != nil && !booking.DepartureAt.After(time.Now())) {
+		log.Warn().
+			Str("booking_id", bookingID).
+			Str("status", booking.Status).
+			Msg("Booking cannot be modified")
+		return nil, domain.ErrBookingNotModifiable.WithDetails(map[string]interface{}{
+			"booking_id":   bookingID,
+			"status":       booking.Status,
+			"departure_at": booking.DepartureAt,
+		})
+	}
+
+	if seats == booking.SeatsRequested {
+		return nil, domain.ErrSeatsUnchanged.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"seats":      seats,
+		})
+	}
+
+	// Passenger details are one per seat and are not edited: those bookings keep their seats
+	passengers, err := s.bookingRepo.FindPassengers(bookingID)
+	if err != nil {
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking passengers")
+		return nil, fmt.Errorf("failed to get booking passengers: %w", err)
+	}
+	if len(passengers) > 0 {
+		return nil, domain.ErrSeatsFixedByPassengers.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"passengers": len(passengers),
+		})
+	}
+
+	// Step 4: Increases must respect the booking's country policy
+	countryPolicy, _ := s.policies.Resolve(booking.Country)
+	if seats > countryPolicy.MaxSeatsPerBooking {
+		return nil, domain.ErrMaxSeatsExceeded.WithDetails(map[string]interface{}{
+			"country":         countryPolicy.Country,
+			"seats_requested": seats,
+			"max_seats":       countryPolicy.MaxSeatsPerBooking,
+		})
+	}
+
+	// Step 5: Recalculate fare and CO2 savings for the new seats and save
+	// The current seats act as an optimistic lock against concurrent modifications
+	previousSeats := booking.SeatsRequested
+	totalPrice := domain.RecalculateTotalPrice(booking.TotalPrice, previousSeats, seats)
+	co2SavedKg := domain.EstimateCO2SavedKg(booking.DistanceKm, seats)
+	reason := fmt.Sprintf("Seats modified from %d to %d", previousSeats, seats)
+
+	if err := s.bookingRepo.UpdateSeats(bookingID, previousSeats, seats, totalPrice, co2SavedKg, reason); err != nil {
+		if errors.Is(err, repository.ErrSeatsChanged) {
+			log.Warn().Str("booking_id", bookingID).Msg("Booking seats changed concurrently")
+			return nil, domain.ErrBookingModifiedConcurrently.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to modify booking seats")
+		return nil, fmt.Errorf("failed to modify booking seats: %w", err)
+	}
+
+	booking.SeatsRequested = seats
+	booking.TotalPrice = totalPrice
+	booking.CO2SavedKg = co2SavedKg
+
+	log.Info().
+		Str("booking_id", bookingID).
+		Int("previous_seats", previousSeats).
+		Int("new_seats", seats).
+		Float64("total_price", totalPrice).
+		Msg("✅ Booking seats modified successfully")
+
+	// Step 6: Publish reservation.modified event to RabbitMQ
+	// IMPORTANT: Eventual consistency pattern - if publish fails, DON'T rollback database
+	err = s.publisher.PublishReservationModified(
+		ctx,
+		booking.TripID,
+		booking.PassengerID,
+		booking.BookingUUID,
+		previousSeats,
+		seats,
+	)
+	if errors.Is(err, publisher.ErrEventBuffered) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("event_type", "reservation.modified").
+			Msg("⏳ Booking modified, reservation.modified buffered until RabbitMQ is available")
+	} else if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", booking.TripID).
+			Int("seats_delta", seats-previousSeats).
+			Msg("⚠️  Booking modified but failed to publish reservation.modified event (eventual consistency)")
+	} else {
+		log.Info().
+			Str("booking_id", booking.BookingUUID).
+			Str("event_type", "reservation.modified").
+			Msg("✅ Reservation modified event published successfully")
+	}
+
+	return domain.ToBookingResponse(booking), nil
+}
+
 // GetAllBookings retrieves all bookings in the system with pagination and filters (admin only)
 func (s *bookingService) GetAllBookings(ctx context.Context, page, limit int, statusFilter, tripIDFilter string, passengerIDFilter int64) ([]*domain.BookingResponse, int64, error) {
 	log.Info().
@@ -342,3 +870,278 @@ func (s *bookingService) GetAllBookings(ctx context.Context, page, limit int, st
 
 	return bookingResponses, total, nil
 }
+
+// GetBookingAsOf reconstructs a booking's state at a past moment (admin only)
+// Used to resolve customer disputes about when a cancellation actually happened
+func (s *bookingService) GetBookingAsOf(ctx context.Context, bookingID string, asOf time.Time) (*domain.BookingAsOfResponse, error) {
+	log.Info().
+		Str("booking_id", bookingID).
+		Time("as_of", asOf).
+		Msg("Reconstructing booking state (admin)")
+
+	// Step 1: Get the current booking (immutable fields + existence check)
+	booking, err := s.bookingRepo.FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warn().Str("booking_id", bookingID).Msg("Booking not found")
+			return nil, domain.ErrBookingNotFound.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking")
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	// Step 2: The booking must have existed at the requested moment
+	if asOf.Before(booking.CreatedAt) {
+		return nil, domain.ErrBookingNotYetCreated.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"created_at": booking.CreatedAt,
+			"as_of":      asOf,
+		})
+	}
+
+	// Step 3: Load every transition up to the requested moment
+	history, err := s.bookingRepo.FindStatusHistory(bookingID, asOf)
+	if err != nil {
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking status history")
+		return nil, fmt.Errorf("failed to get booking status history: %w", err)
+	}
+
+	// Bookings created before the history table existed have no transitions recorded
+	if len(history) == 0 {
+		log.Warn().
+			Str("booking_id", bookingID).
+			Time("as_of", asOf).
+			Msg("No status history available for booking")
+		return nil, domain.ErrStatusHistoryUnavailable.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"as_of":      asOf,
+		})
+	}
+
+	response := domain.ToBookingAsOfResponse(booking, history, asOf)
+
+	log.Info().
+		Str("booking_id", bookingID).
+		Time("as_of", asOf).
+		Str("status_at", response.Booking.Status).
+		Int("transitions", len(history)).
+		Msg("✅ Booking state reconstructed")
+
+	return response, nil
+}
+
+// GetBookingState returns the derived saga state of a booking: current status, whether it is
+// terminal, the allowed next statuses and the status transitions so far
+func (s *bookingService) GetBookingState(ctx context.Context, bookingID string, userID int64, isAdmin bool) (*domain.BookingStateResponse, error) {
+	booking, err := s.bookingRepo.FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warn().Str("booking_id", bookingID).Msg("Booking not found")
+			return nil, domain.ErrBookingNotFound.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking")
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	// Authorization: passenger, driver (known once confirmed) or admin
+	if !isAdmin && booking.PassengerID != userID && booking.DriverID != userID {
+		return nil, domain.ErrUnauthorized.WithMessage("You can only view the state of your own bookings")
+	}
+
+	history, err := s.bookingRepo.FindStatusHistory(bookingID, time.Now())
+	if err != nil {
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking status history")
+		return nil, fmt.Errorf("failed to get booking status history: %w", err)
+	}
+
+	return domain.ToBookingStateResponse(booking, history), nil
+}
+
+// GetTripBookings lists the bookings of a trip for its driver, with the seat passengers of each booking
+// and the contact details of the passengers holding seats
+//
+// The driver is checked against trips-api rather than Booking.DriverID, which is only known
+// once a booking is confirmed. Contact details are fetched in one users-api request and are best
+// effort: if users-api fails the bookings are still listed, flagged with contact_unavailable
+func (s *bookingService) GetTripBookings(ctx context.Context, tripID string, userID int64, statusFilter string) (*domain.TripBookingsResponse, error) {
+	if statusFilter != "" && !domain.IsValidBookingStatus(statusFilter) {
+		return nil, domain.NewAppError("VALIDATION_ERROR", "Invalid status filter", map[string]interface{}{
+			"status": statusFilter,
+		})
+	}
+
+	// Step 1: Authorization - only the trip's driver sees its passengers
+	trip, err := s.tripsClient.GetTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.DriverID != userID {
+		log.Warn().
+			Str("trip_id", tripID).
+			Int64("user_id", userID).
+			Int64("driver_id", trip.DriverID).
+			Msg("Unauthorized trip bookings access attempt")
+		return nil, domain.ErrUnauthorized.WithMessage("You can only view the bookings of your own trips")
+	}
+
+	// Step 2: Bookings of the trip and their seat passengers
+	bookings, err := s.bookingRepo.FindByTripID(tripID)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to get trip bookings")
+		return nil, fmt.Errorf("failed to get trip bookings: %w", err)
+	}
+
+	response := &domain.TripBookingsResponse{
+		TripID:   tripID,
+		Bookings: make([]domain.TripBooking, 0, len(bookings)),
+		Counts:   make(map[string]int),
+	}
+	selected := make([]dao.Booking, 0, len(bookings))
+	uuids := make([]string, 0, len(bookings))
+	for _, booking := range bookings {
+		response.Counts[booking.Status]++
+		if booking.IsConfirmed() {
+			response.SeatsConfirmed += booking.SeatsRequested
+		}
+		if statusFilter != "" && booking.Status != statusFilter {
+			continue
+		}
+		selected = append(selected, booking)
+		uuids = append(uuids, booking.BookingUUID)
+	}
+
+	passengers, err := s.bookingRepo.FindPassengersByBookings(uuids)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to get booking passengers")
+		return nil, fmt.Errorf("failed to get booking passengers: %w", err)
+	}
+
+	// Step 3: Contact details of the passengers holding seats, in a single users-api request
+	// (a trip has far fewer passengers than the users-api batch limit)
+	passengerIDs := make([]int64, 0, len(selected))
+	seen := make(map[int64]bool, len(selected))
+	for _, booking := range selected {
+		if domain.ExposesPassengerContact(booking.Status) && !seen[booking.PassengerID] {
+			seen[booking.PassengerID] = true
+			passengerIDs = append(passengerIDs, booking.PassengerID)
+		}
+	}
+	contacts, err := s.usersClient.GetUserContacts(ctx, passengerIDs)
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Str("trip_id", tripID).
+			Int("passengers", len(passengerIDs)).
+			Msg("Failed to get passenger contacts from users-api")
+		contacts = nil
+	}
+
+	for _, booking := range selected {
+		item := domain.TripBooking{BookingResponse: *domain.ToBookingResponse(&booking)}
+		if p := passengers[booking.BookingUUID]; len(p) > 0 {
+			item.Passengers = domain.ToTripBookingPassengers(p, booking.Status)
+		}
+
+		if domain.ExposesPassengerContact(booking.Status) {
+			item.Contact = contacts[booking.PassengerID]
+			item.ContactUnavailable = item.Contact == nil
+		}
+
+		response.Bookings = append(response.Bookings, item)
+	}
+	response.Total = len(response.Bookings)
+
+	log.Info().
+		Str("trip_id", tripID).
+		Int64("driver_id", userID).
+		Int("returned", response.Total).
+		Int("seats_confirmed", response.SeatsConfirmed).
+		Msg("Retrieved trip bookings for driver")
+
+	return response, nil
+}
+
+// GetEffectivePolicy returns the country policy that applies to a trip (admin only)
+// Unlike CreateBooking, this does not fall back when trips-api fails: admins need the real answer
+func (s *bookingService) GetEffectivePolicy(ctx context.Context, tripID string) (*domain.EffectivePolicyResponse, error) {
+	log.Info().Str("trip_id", tripID).Msg("Resolving effective policy (admin)")
+
+	// Step 1: Get the trip to learn its country
+	trip, err := s.tripsClient.GetTrip(ctx, tripID)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to get trip for policy resolution")
+		return nil, err
+	}
+
+	// Step 2: Resolve the policy from the registry
+	countryPolicy, source := s.policies.Resolve(trip.Origin.Country)
+
+	return &domain.EffectivePolicyResponse{
+		TripID:       tripID,
+		TripCountry:  trip.Origin.Country,
+		ResolvedFrom: source,
+		Policy:       countryPolicy,
+	}, nil
+}
+
+// GetUserCO2Savings aggregates the estimated CO2 savings of a user's bookings
+// Only confirmed and completed bookings count: pending, cancelled and failed bookings saved nothing
+func (s *bookingService) GetUserCO2Savings(ctx context.Context, userID int64) (*domain.CO2SavingsResponse, error) {
+	totals, err := s.bookingRepo.SumCO2Savings(userID, []string{
+		dao.BookingStatusConfirmed,
+		dao.BookingStatusCompleted,
+	})
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to aggregate CO2 savings")
+		return nil, fmt.Errorf("failed to aggregate CO2 savings: %w", err)
+	}
+
+	return &domain.CO2SavingsResponse{
+		UserID:          userID,
+		BookingsCount:   totals.Bookings,
+		SeatsShared:     totals.Seats,
+		TotalDistanceKm: math.Round(totals.DistanceKm*100) / 100,
+		TotalCO2SavedKg: math.Round(totals.CO2SavedKg*100) / 100,
+	}, nil
+}
+
+// resolveTripPolicy fetches the trip and resolves its country policy
+// Falls back to the default country policy (with a nil trip) if trips-api cannot be reached
+func (s *bookingService) resolveTripPolicy(ctx context.Context, tripID string) (*domain.Trip, domain.CountryPolicy) {
+	trip, err := s.tripsClient.GetTrip(ctx, tripID)
+	if err != nil {
+		countryPolicy, _ := s.policies.Resolve("")
+		log.Warn().
+			Err(err).
+			Str("trip_id", tripID).
+			Str("country", countryPolicy.Country).
+			Msg("Could not fetch trip, using default country policy")
+		return nil, countryPolicy
+	}
+
+	countryPolicy, _ := s.policies.Resolve(trip.Origin.Country)
+	return trip, countryPolicy
+}
+
+// releasePayment refunds (or voids) the provider payment of a booking that was just cancelled
+// Within the refund window (no fee) the promotional credits go back to the passenger's balance and
+// only the rest of the fare is refunded; a late cancellation keeps them as part of the fee
+// Like the reservation events, a failure does not undo the cancellation: it is logged for follow-up
+func (s *bookingService) releasePayment(ctx context.Context, booking *dao.Booking, quote domain.CancellationQuote) {
+	if quote.Fee == 0 {
+		s.credits.Release(ctx, booking)
+	}
+
+	refundAmount := domain.CashRefund(booking.TotalPrice, quote.Fee, booking.CreditsApplied)
+	if err := s.payments.ReleasePayment(ctx, booking, refundAmount); err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Float64("refund_amount", refundAmount).
+			Msg("⚠️  Booking cancelled but failed to release its payment")
+	}
+}
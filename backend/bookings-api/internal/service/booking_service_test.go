@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/policy"
+	"bookings-api/internal/publisher"
+	"bookings-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// seatsRepo keeps bookings in memory for the seat modification tests
+type seatsRepo struct {
+	repository.BookingRepository
+	bookings   map[string]dao.Booking
+	passengers map[string][]dao.BookingPassenger
+	reasons    []string
+}
+
+func (r *seatsRepo) FindByID(id string) (*dao.Booking, error) {
+	booking, ok := r.bookings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &booking, nil
+}
+
+func (r *seatsRepo) FindPassengers(bookingUUID string) ([]dao.BookingPassenger, error) {
+	return r.passengers[bookingUUID], nil
+}
+
+func (r *seatsRepo) UpdateSeats(bookingUUID string, expectedSeats, newSeats int, totalPrice, co2SavedKg float64, reason string) error {
+	booking := r.bookings[bookingUUID]
+	if booking.SeatsRequested != expectedSeats {
+		return repository.ErrSeatsChanged
+	}
+	booking.SeatsRequested = newSeats
+	booking.TotalPrice = totalPrice
+	booking.CO2SavedKg = co2SavedKg
+	r.bookings[bookingUUID] = booking
+	r.reasons = append(r.reasons, reason)
+	return nil
+}
+
+// modifiedEvent is a reservation.modified captured by modifiedRecorder
+type modifiedEvent struct {
+	tripID        string
+	reservationID string
+	previousSeats int
+	newSeats      int
+}
+
+type modifiedRecorder struct {
+	publisher.Publisher
+	events []modifiedEvent
+	err    error
+}
+
+func (p *modifiedRecorder) PublishReservationModified(ctx context.Context, tripID string, passengerID int64, reservationID string, previousSeats, newSeats int) error {
+	p.events = append(p.events, modifiedEvent{tripID: tripID, reservationID: reservationID, previousSeats: previousSeats, newSeats: newSeats})
+	return p.err
+}
+
+func newSeatsService(t *testing.T, booking dao.Booking) (*bookingService, *seatsRepo, *modifiedRecorder) {
+	t.Helper()
+	policies, err := policy.NewRegistry("AR", policy.DefaultPolicies())
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	repo := &seatsRepo{
+		bookings:   map[string]dao.Booking{booking.BookingUUID: booking},
+		passengers: map[string][]dao.BookingPassenger{},
+	}
+	pub := &modifiedRecorder{}
+	return &bookingService{bookingRepo: repo, publisher: pub, policies: policies}, repo, pub
+}
+
+func confirmedBooking() dao.Booking {
+	departure := time.Now().Add(48 * time.Hour)
+	return dao.Booking{
+		BookingUUID:    "booking-1",
+		TripID:         "trip-1",
+		PassengerID:    10,
+		SeatsRequested: 2,
+		TotalPrice:     3000,
+		DistanceKm:     300,
+		Country:        "AR",
+		Status:         dao.BookingStatusConfirmed,
+		DepartureAt:    &departure,
+	}
+}
+
+func TestModifyBookingSeats_RecalculatesAndPublishesTheDelta(t *testing.T) {
+	svc, repo, pub := newSeatsService(t, confirmedBooking())
+
+	response, err := svc.ModifyBookingSeats(context.Background(), "booking-1", 10, 3)
+	if err != nil {
+		t.Fatalf("ModifyBookingSeats() error = %v", err)
+	}
+
+	stored := repo.bookings["booking-1"]
+	if stored.SeatsRequested != 3 || stored.TotalPrice != 4500 {
+		t.Errorf("stored booking = {seats %d, total %v}, want {3, 4500}", stored.SeatsRequested, stored.TotalPrice)
+	}
+	if want := domain.EstimateCO2SavedKg(300, 3); stored.CO2SavedKg != want {
+		t.Errorf("co2_saved_kg = %v, want %v", stored.CO2SavedKg, want)
+	}
+	if response.SeatsRequested != 3 || response.TotalPrice != 4500 {
+		t.Errorf("response = {seats %d, total %v}, want {3, 4500}", response.SeatsRequested, response.TotalPrice)
+	}
+	if len(repo.reasons) != 1 || repo.reasons[0] != "Seats modified from 2 to 3" {
+		t.Errorf("status history reasons = %v", repo.reasons)
+	}
+
+	want := []modifiedEvent{{tripID: "trip-1", reservationID: "booking-1", previousSeats: 2, newSeats: 3}}
+	if len(pub.events) != 1 || pub.events[0] != want[0] {
+		t.Errorf("reservation.modified = %+v, want %+v", pub.events, want)
+	}
+}
+
+func TestModifyBookingSeats_PublishFailureKeepsTheChange(t *testing.T) {
+	svc, repo, pub := newSeatsService(t, confirmedBooking())
+	pub.err = publisher.ErrEventBuffered
+
+	if _, err := svc.ModifyBookingSeats(context.Background(), "booking-1", 10, 1); err != nil {
+		t.Fatalf("ModifyBookingSeats() with a buffered event = %v, want nil", err)
+	}
+	if stored := repo.bookings["booking-1"]; stored.SeatsRequested != 1 || stored.TotalPrice != 1500 {
+		t.Errorf("stored booking = {seats %d, total %v}, want {1, 1500}", stored.SeatsRequested, stored.TotalPrice)
+	}
+}
+
+func TestModifyBookingSeats_Rejections(t *testing.T) {
+	departed := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		booking  func(b *dao.Booking)
+		userID   int64
+		seats    int
+		wantCode string
+	}{
+		{name: "not the passenger", userID: 11, seats: 3, wantCode: domain.ErrUnauthorized.Code},
+		{name: "pending booking", booking: func(b *dao.Booking) { b.Status = dao.BookingStatusPending }, userID: 10, seats: 3, wantCode: domain.ErrBookingNotModifiable.Code},
+		{name: "departed trip", booking: func(b *dao.Booking) { b.DepartureAt = &departed }, userID: 10, seats: 3, wantCode: domain.ErrBookingNotModifiable.Code},
+		{name: "same seats", userID: 10, seats: 2, wantCode: domain.ErrSeatsUnchanged.Code},
+		{name: "over the country limit", booking: func(b *dao.Booking) { b.Country = "CL" }, userID: 10, seats: 4, wantCode: domain.ErrMaxSeatsExceeded.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := confirmedBooking()
+			if tt.booking != nil {
+				tt.booking(&booking)
+			}
+			svc, repo, pub := newSeatsService(t, booking)
+
+			_, err := svc.ModifyBookingSeats(context.Background(), "booking-1", tt.userID, tt.seats)
+			if !isAppError(err, tt.wantCode) {
+				t.Fatalf("ModifyBookingSeats() = %v, want %s", err, tt.wantCode)
+			}
+			if repo.bookings["booking-1"].SeatsRequested != booking.SeatsRequested || len(pub.events) != 0 {
+				t.Error("a rejected modification changed the booking or published an event")
+			}
+		})
+	}
+}
+
+func TestModifyBookingSeats_BookingsWithPassengersKeepTheirSeats(t *testing.T) {
+	svc, repo, _ := newSeatsService(t, confirmedBooking())
+	repo.passengers["booking-1"] = []dao.BookingPassenger{{SeatNumber: 1, Name: "Ana"}, {SeatNumber: 2, Name: "Beto"}}
+
+	_, err := svc.ModifyBookingSeats(context.Background(), "booking-1", 10, 3)
+	if !isAppError(err, domain.ErrSeatsFixedByPassengers.Code) {
+		t.Fatalf("ModifyBookingSeats() = %v, want %s", err, domain.ErrSeatsFixedByPassengers.Code)
+	}
+}
+
+func TestModifyBookingSeats_ConcurrentChange(t *testing.T) {
+	svc, repo, pub := newSeatsService(t, confirmedBooking())
+	// Another request changes the seats after this one read the booking
+	svc.bookingRepo = &concurrentSeatsRepo{seatsRepo: repo}
+
+	_, err := svc.ModifyBookingSeats(context.Background(), "booking-1", 10, 3)
+	if !isAppError(err, domain.ErrBookingModifiedConcurrently.Code) {
+		t.Fatalf("ModifyBookingSeats() = %v, want %s", err, domain.ErrBookingModifiedConcurrently.Code)
+	}
+	if len(pub.events) != 0 {
+		t.Error("a lost race published reservation.modified")
+	}
+}
+
+// concurrentSeatsRepo changes the seats between the read and the update of ModifyBookingSeats
+type concurrentSeatsRepo struct {
+	*seatsRepo
+}
+
+func (r *concurrentSeatsRepo) UpdateSeats(bookingUUID string, expectedSeats, newSeats int, totalPrice, co2SavedKg float64, reason string) error {
+	booking := r.bookings[bookingUUID]
+	booking.SeatsRequested = expectedSeats + 1
+	r.bookings[bookingUUID] = booking
+	return r.seatsRepo.UpdateSeats(bookingUUID, expectedSeats, newSeats, totalPrice, co2SavedKg, reason)
+}
+
+func isAppError(err error, code string) bool {
+	var appErr *domain.AppError
+	return errors.As(err, &appErr) && appErr.Code == code
+}
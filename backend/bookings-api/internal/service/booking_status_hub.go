@@ -0,0 +1,110 @@
+package service
+
+import (
+	"sync"
+
+	"bookings-api/internal/domain"
+)
+
+// statusSubscriberBuffer is the number of events a slow stream can lag behind before events are dropped
+// (a dropped event is recovered by the stream's periodic state check)
+const statusSubscriberBuffer = 8
+
+// BookingStatusHub fans out booking status transitions to the live streams open in this instance
+//
+// Transitions applied by another instance (another consumer of the queue, another API replica)
+// never reach this hub: streams poll the booking state on every heartbeat to catch them.
+type BookingStatusHub interface {
+	// Subscribe registers a stream for a booking; the returned function unsubscribes it and must always be called
+	Subscribe(bookingID string) (<-chan domain.BookingStatusEvent, func())
+
+	// Publish sends a transition to every stream of the booking without blocking
+	Publish(event domain.BookingStatusEvent)
+
+	// OpenStream reserves one of the user's live stream slots; the returned function frees it and must always be called
+	// Returns ErrTooManyStreams when the user already has the maximum number of streams open in this instance
+	OpenStream(userID int64) (func(), error)
+}
+
+// bookingStatusHub implements BookingStatusHub
+type bookingStatusHub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan domain.BookingStatusEvent]struct{}
+
+	streamsMu         sync.Mutex
+	streams           map[int64]int // Open streams per user
+	maxStreamsPerUser int           // 0 = unlimited
+}
+
+// NewBookingStatusHub creates a new in-memory BookingStatusHub
+// maxStreamsPerUser caps the live streams a user can keep open at once (0 = unlimited)
+func NewBookingStatusHub(maxStreamsPerUser int) BookingStatusHub {
+	if maxStreamsPerUser < 0 {
+		maxStreamsPerUser = 0
+	}
+	return &bookingStatusHub{
+		subscribers:       make(map[string]map[chan domain.BookingStatusEvent]struct{}),
+		streams:           make(map[int64]int),
+		maxStreamsPerUser: maxStreamsPerUser,
+	}
+}
+
+func (h *bookingStatusHub) Subscribe(bookingID string) (<-chan domain.BookingStatusEvent, func()) {
+	ch := make(chan domain.BookingStatusEvent, statusSubscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[bookingID] == nil {
+		h.subscribers[bookingID] = make(map[chan domain.BookingStatusEvent]struct{})
+	}
+	h.subscribers[bookingID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[bookingID], ch)
+			if len(h.subscribers[bookingID]) == 0 {
+				delete(h.subscribers, bookingID)
+			}
+			h.mu.Unlock()
+		})
+	}
+	return ch, unsubscribe
+}
+
+func (h *bookingStatusHub) Publish(event domain.BookingStatusEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[event.BookingID] {
+		select {
+		case ch <- event:
+		default:
+			// Stream is not reading: drop, its next state check resends the current status
+		}
+	}
+}
+
+func (h *bookingStatusHub) OpenStream(userID int64) (func(), error) {
+	h.streamsMu.Lock()
+	defer h.streamsMu.Unlock()
+
+	if h.maxStreamsPerUser > 0 && h.streams[userID] >= h.maxStreamsPerUser {
+		return nil, domain.ErrTooManyStreams.WithDetails(map[string]int{"max_streams": h.maxStreamsPerUser})
+	}
+	h.streams[userID]++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			h.streamsMu.Lock()
+			h.streams[userID]--
+			if h.streams[userID] <= 0 {
+				delete(h.streams, userID)
+			}
+			h.streamsMu.Unlock()
+		})
+	}
+	return release, nil
+}
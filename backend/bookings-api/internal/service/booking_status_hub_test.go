@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"bookings-api/internal/domain"
+)
+
+func TestBookingStatusHub_PublishReachesTheBookingStreams(t *testing.T) {
+	hub := NewBookingStatusHub(0)
+	first, unsubscribeFirst := hub.Subscribe("booking-1")
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := hub.Subscribe("booking-1")
+	defer unsubscribeSecond()
+	other, unsubscribeOther := hub.Subscribe("booking-2")
+	defer unsubscribeOther()
+
+	hub.Publish(domain.BookingStatusEvent{BookingID: "booking-1", Status: domain.BookingStatusConfirmed})
+
+	for _, events := range []<-chan domain.BookingStatusEvent{first, second} {
+		select {
+		case event := <-events:
+			if event.Status != domain.BookingStatusConfirmed {
+				t.Errorf("status = %q, want %q", event.Status, domain.BookingStatusConfirmed)
+			}
+		default:
+			t.Fatal("a stream of the booking did not receive the transition")
+		}
+	}
+	select {
+	case event := <-other:
+		t.Fatalf("a stream of another booking received %+v", event)
+	default:
+	}
+}
+
+func TestBookingStatusHub_UnsubscribedStreamsReceiveNothing(t *testing.T) {
+	hub := NewBookingStatusHub(0)
+	events, unsubscribe := hub.Subscribe("booking-1")
+	unsubscribe()
+	unsubscribe() // Safe to call twice
+
+	hub.Publish(domain.BookingStatusEvent{BookingID: "booking-1", Status: domain.BookingStatusCancelled})
+
+	select {
+	case event := <-events:
+		t.Fatalf("an unsubscribed stream received %+v", event)
+	default:
+	}
+	if n := len(hub.(*bookingStatusHub).subscribers); n != 0 {
+		t.Errorf("hub keeps %d bookings without streams", n)
+	}
+}
+
+func TestBookingStatusHub_PublishNeverBlocksOnASlowStream(t *testing.T) {
+	hub := NewBookingStatusHub(0)
+	events, unsubscribe := hub.Subscribe("booking-1")
+	defer unsubscribe()
+
+	for i := 0; i < statusSubscriberBuffer+5; i++ {
+		hub.Publish(domain.BookingStatusEvent{BookingID: "booking-1", Status: domain.BookingStatusPending})
+	}
+
+	if got := len(events); got != statusSubscriberBuffer {
+		t.Errorf("buffered events = %d, want %d (the rest are dropped)", got, statusSubscriberBuffer)
+	}
+}
+
+func TestBookingStatusHub_CapsStreamsPerUser(t *testing.T) {
+	hub := NewBookingStatusHub(2)
+
+	releaseFirst, err := hub.OpenStream(7)
+	if err != nil {
+		t.Fatalf("first stream: %v", err)
+	}
+	releaseSecond, err := hub.OpenStream(7)
+	if err != nil {
+		t.Fatalf("second stream: %v", err)
+	}
+	defer releaseSecond()
+
+	_, err = hub.OpenStream(7)
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrTooManyStreams.Code {
+		t.Fatalf("third stream = %v, want %s", err, domain.ErrTooManyStreams.Code)
+	}
+
+	// Another user has their own slots
+	releaseOther, err := hub.OpenStream(8)
+	if err != nil {
+		t.Fatalf("stream of another user: %v", err)
+	}
+	defer releaseOther()
+
+	// Closing a stream frees its slot; releasing twice does not free a second one
+	releaseFirst()
+	releaseFirst()
+	releaseThird, err := hub.OpenStream(7)
+	if err != nil {
+		t.Fatalf("stream after closing one: %v", err)
+	}
+	defer releaseThird()
+	if _, err := hub.OpenStream(7); err == nil {
+		t.Error("a double release freed an extra slot")
+	}
+}
+
+func TestBookingStatusHub_ZeroMeansUnlimited(t *testing.T) {
+	hub := NewBookingStatusHub(0)
+	for i := 0; i < 50; i++ {
+		if _, err := hub.OpenStream(7); err != nil {
+			t.Fatalf("stream %d with no cap: %v", i+1, err)
+		}
+	}
+}
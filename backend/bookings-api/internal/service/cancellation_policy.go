@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// quoteCancellation applies the booking's country policy to a cancellation at cancelledAt
+//
+// The departure is fetched from trips-api, since the driver may have rescheduled the trip
+// after the booking was created. If trips-api cannot be reached, the departure captured at
+// booking creation is used instead; with neither the cancellation is free.
+// Only passengers pay: when chargePassenger is false the full price is refunded.
+func (s *bookingService) quoteCancellation(ctx context.Context, booking *dao.Booking, chargePassenger bool, cancelledAt time.Time) domain.CancellationQuote {
+	countryPolicy, _ := s.policies.Resolve(booking.Country)
+	if !chargePassenger {
+		return countryPolicy.QuoteCancellation(booking.TotalPrice, nil, cancelledAt)
+	}
+
+	return countryPolicy.QuoteCancellation(booking.TotalPrice, s.currentDeparture(ctx, booking), cancelledAt)
+}
+
+// currentDeparture returns the trip's departure according to trips-api,
+// falling back to the departure stored on the booking
+func (s *bookingService) currentDeparture(ctx context.Context, booking *dao.Booking) *time.Time {
+	trip, err := s.tripsClient.GetTrip(ctx, booking.TripID)
+	if err != nil || trip.DepartureDatetime.IsZero() {
+		log.Warn().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", booking.TripID).
+			Bool("has_stored_departure", booking.DepartureAt != nil).
+			Msg("Could not fetch trip departure, using the departure stored on the booking")
+		return booking.DepartureAt
+	}
+
+	departure := trip.DepartureDatetime
+	if booking.DepartureAt != nil && !booking.DepartureAt.Equal(departure) {
+		log.Info().
+			Str("booking_id", booking.BookingUUID).
+			Time("stored_departure", *booking.DepartureAt).
+			Time("current_departure", departure).
+			Msg("Trip departure changed since booking creation, using the current one")
+	}
+	return &departure
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"bookings-api/internal/clients"
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/policy"
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CreditsService applies the passenger's promotional credits (users-api ledger) to the fare of a booking
+//
+// Credits are consumed when trips-api confirms the seats and the payment provider charges only the rest.
+// They are released when the booking is cancelled within the refund window (no cancellation fee);
+// a late cancellation keeps them as part of the fee. Split-payment bookings do not use credits.
+// users-api is idempotent by booking UUID, so retrying either call never consumes or releases twice.
+type CreditsService interface {
+	// Apply consumes up to totalPrice of the passenger's credits for the booking and returns the amount applied
+	// Best effort: returns 0 when credits are disabled or users-api cannot be reached (the full fare is charged)
+	Apply(ctx context.Context, booking *dao.Booking, totalPrice float64) float64
+
+	// Release returns the credits applied to a cancelled booking to the passenger's balance
+	// Best effort: a failure is only logged. Bookings without credits are ignored
+	Release(ctx context.Context, booking *dao.Booking)
+}
+
+// creditsService implements CreditsService
+type creditsService struct {
+	usersClient clients.UsersClient
+	policies    policy.Registry
+	enabled     bool
+}
+
+// NewCreditsService creates a new CreditsService
+// When enabled is false no credits are consumed; credits already applied are still released
+func NewCreditsService(usersClient clients.UsersClient, policies policy.Registry, enabled bool) CreditsService {
+	return &creditsService{usersClient: usersClient, policies: policies, enabled: enabled}
+}
+
+// Apply consumes the credits in the currency of the booking's country policy
+func (s *creditsService) Apply(ctx context.Context, booking *dao.Booking, totalPrice float64) float64 {
+	if !s.enabled || totalPrice <= 0 {
+		return 0
+	}
+
+	countryPolicy, _ := s.policies.Resolve(booking.Country)
+	consumption, err := s.usersClient.ConsumeCredits(ctx, domain.CreditConsumeRequest{
+		UserID:    booking.PassengerID,
+		BookingID: booking.BookingUUID,
+		Amount:    totalPrice,
+		Currency:  countryPolicy.Currency,
+	})
+	if err != nil {
+		// The consumption may have been stored before the error (timeout): release it so the
+		// passenger does not lose credits on a booking charged in full
+		log.Warn().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Int64("passenger_id", booking.PassengerID).
+			Msg("⚠️  Could not apply promotional credits, charging the full fare")
+		s.release(ctx, booking.BookingUUID)
+		return 0
+	}
+
+	if consumption.AppliedAmount > 0 {
+		log.Info().
+			Str("booking_id", booking.BookingUUID).
+			Int64("passenger_id", booking.PassengerID).
+			Float64("total_price", totalPrice).
+			Float64("credits_applied", consumption.AppliedAmount).
+			Float64("credits_balance", consumption.Balance).
+			Bool("replayed", consumption.Replayed).
+			Msg("🎟️  Promotional credits applied to booking")
+	}
+	return consumption.AppliedAmount
+}
+
+func (s *creditsService) Release(ctx context.Context, booking *dao.Booking) {
+	if booking.CreditsApplied <= 0 {
+		return
+	}
+	s.release(ctx, booking.BookingUUID)
+}
+
+// release asks users-api to return the credits of a booking, logging the outcome
+func (s *creditsService) release(ctx context.Context, bookingID string) {
+	release, err := s.usersClient.ReleaseCredits(ctx, bookingID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", bookingID).
+			Msg("⚠️  Failed to release promotional credits of booking")
+		return
+	}
+
+	if release.ReleasedAmount > 0 || release.AppliedAmount > 0 {
+		log.Info().
+			Str("booking_id", bookingID).
+			Float64("credits_applied", release.AppliedAmount).
+			Float64("credits_released", release.ReleasedAmount).
+			Bool("replayed", release.Replayed).
+			Msg("🎟️  Promotional credits released")
+	}
+}
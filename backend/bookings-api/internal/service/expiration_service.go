@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bookings-api/internal/domain"
+	"bookings-api/internal/publisher"
+	"bookings-api/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxExpirationBatchesPerRun bounds the work of a single run; the rest is expired in the next one
+const maxExpirationBatchesPerRun = 10
+
+// ExpirationConfig controls the expiration of bookings stuck in pending
+type ExpirationConfig struct {
+	// PendingTimeout is how long a booking may stay pending before it is expired
+	// 0 disables the job
+	PendingTimeout time.Duration
+
+	// BatchSize is the number of pending bookings read per query
+	BatchSize int
+}
+
+// ExpirationService expires bookings trips-api never confirmed nor rejected (lost events)
+type ExpirationService interface {
+	// RunOnce expires the bookings pending for longer than the timeout (up to maxExpirationBatchesPerRun batches)
+	RunOnce(ctx context.Context) (*domain.ExpirationRunResult, error)
+
+	// Start runs RunOnce periodically until ctx is cancelled (blocking, run in a goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+// expirationService implements ExpirationService
+type expirationService struct {
+	bookingRepo repository.BookingRepository
+	publisher   publisher.Publisher
+	seatHolds   SeatHoldService
+	statusHub   BookingStatusHub
+	cfg         ExpirationConfig
+}
+
+// NewExpirationService creates a new ExpirationService
+func NewExpirationService(bookingRepo repository.BookingRepository, pub publisher.Publisher, seatHolds SeatHoldService, statusHub BookingStatusHub, cfg ExpirationConfig) ExpirationService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &expirationService{bookingRepo: bookingRepo, publisher: pub, seatHolds: seatHolds, statusHub: statusHub, cfg: cfg}
+}
+
+// RunOnce moves every booking pending since before the cutoff to expired
+//
+// For each booking:
+//  1. pending → expired, using the current status as optimistic lock (a reservation.confirmed
+//     or reservation.failed processed meanwhile wins and the booking is skipped)
+//  2. a seat hold that trips-api never confirmed is released directly; otherwise (no hold, hold already
+//     confirmed, or trips-api unreachable) reservation.cancelled is published so trips-api releases the
+//     seats if it had reserved them
+//
+// A late reservation.confirmed for an expired booking is ignored by the consumer (expired is terminal).
+func (s *expirationService) RunOnce(ctx context.Context) (*domain.ExpirationRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.ExpirationRunResult{}
+	if s.cfg.PendingTimeout <= 0 {
+		return result, nil
+	}
+
+	cutoff := startedAt.Add(-s.cfg.PendingTimeout)
+	result.Cutoff = cutoff
+
+	for batch := 0; batch < maxExpirationBatchesPerRun; batch++ {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		bookings, err := s.bookingRepo.FindPendingCreatedBefore(cutoff, s.cfg.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(bookings) == 0 {
+			break
+		}
+
+		for i := range bookings {
+			booking := &bookings[i]
+
+			err := s.bookingRepo.TransitionStatus(booking.BookingUUID, domain.BookingStatusPending, domain.BookingStatusExpired, nil, domain.ExpirationReason)
+			if errors.Is(err, repository.ErrStatusChanged) {
+				result.StatusChanged++
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			result.BookingsExpired++
+			s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, domain.BookingStatusPending, domain.BookingStatusExpired, domain.ExpirationReason))
+
+			pendingSince := booking.CreatedAt
+			if booking.GuardianApprovedAt != nil {
+				pendingSince = *booking.GuardianApprovedAt
+			}
+			if age := startedAt.Sub(pendingSince); age > result.OldestPendingAge {
+				result.OldestPendingAge = age
+			}
+
+			// Releasing a hold trips-api never confirmed returns the seats: there is no reservation to cancel
+			if booking.SeatHoldID != "" {
+				release, err := s.seatHolds.Release(ctx, booking)
+				if err == nil && release != domain.SeatHoldConfirmed {
+					result.HoldsReleased++
+					continue
+				}
+			}
+
+			// Same eventual consistency as a passenger cancellation: the booking stays expired
+			err = s.publisher.PublishReservationCancelled(ctx, booking.TripID, booking.SeatsRequested, booking.BookingUUID, 0, booking.TotalPrice, "")
+			if errors.Is(err, publisher.ErrEventBuffered) {
+				log.Warn().
+					Str("booking_id", booking.BookingUUID).
+					Str("trip_id", booking.TripID).
+					Msg("⏳ Booking expired while pending, reservation.cancelled buffered until RabbitMQ is available")
+				continue
+			}
+			if err != nil {
+				result.PublishFailures++
+				log.Error().
+					Err(err).
+					Str("booking_id", booking.BookingUUID).
+					Str("trip_id", booking.TripID).
+					Int("seats_released", booking.SeatsRequested).
+					Msg("⚠️  Booking expired but failed to publish reservation.cancelled event (eventual consistency)")
+				continue
+			}
+
+			log.Warn().
+				Str("booking_id", booking.BookingUUID).
+				Str("trip_id", booking.TripID).
+				Time("created_at", booking.CreatedAt).
+				Msg("Booking expired while pending, seats released")
+		}
+
+		// Skipped bookings are no longer pending, so the next query returns new ones
+		if len(bookings) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// Start runs the expiration job on every tick until ctx is cancelled
+func (s *expirationService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int64("bookings_expired", result.BookingsExpired).
+				Msg("❌ Expiration job failed")
+		} else if result.BookingsExpired > 0 || result.StatusChanged > 0 {
+			log.Info().
+				Int64("bookings_expired", result.BookingsExpired).
+				Int64("publish_failures", result.PublishFailures).
+				Int64("holds_released", result.HoldsReleased).
+				Int64("status_changed", result.StatusChanged).
+				Dur("oldest_pending_age", result.OldestPendingAge).
+				Str("duration", result.Duration).
+				Msg("⏰ Expiration job expired pending bookings")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Expiration job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
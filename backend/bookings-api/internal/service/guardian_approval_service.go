@@ -0,0 +1,255 @@
+package service
+
+import (
+	"bookings-api/internal/clients"
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/publisher"
+	"bookings-api/internal/repository"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxGuardianApprovalBatchesPerRun bounds the work of a single run; the rest is checked in the next one
+const maxGuardianApprovalBatchesPerRun = 10
+
+// GuardianApprovalService resolves the bookings of dependent accounts once their guardian answers in users-api
+type GuardianApprovalService interface {
+	// RunOnce looks up the approval of every booking awaiting the guardian (up to maxGuardianApprovalBatchesPerRun batches)
+	RunOnce(ctx context.Context) (*domain.GuardianApprovalRunResult, error)
+
+	// Start runs RunOnce periodically until ctx is cancelled (blocking, run in a goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+// guardianApprovalService implements GuardianApprovalService
+type guardianApprovalService struct {
+	bookingRepo repository.BookingRepository
+	usersClient clients.UsersClient
+	publisher   publisher.Publisher
+	statusHub   BookingStatusHub
+	batchSize   int
+}
+
+// NewGuardianApprovalService creates a new GuardianApprovalService
+func NewGuardianApprovalService(bookingRepo repository.BookingRepository, usersClient clients.UsersClient, pub publisher.Publisher, statusHub BookingStatusHub, batchSize int) GuardianApprovalService {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &guardianApprovalService{bookingRepo: bookingRepo, usersClient: usersClient, publisher: pub, statusHub: statusHub, batchSize: batchSize}
+}
+
+// RunOnce applies the guardian's answer to every booking awaiting it
+//
+// For each booking, depending on the approval in users-api:
+//   - approved: awaiting_guardian → pending and reservation.created is published, so trips-api validates
+//     the booking like any other (the pending timeout counts from the approval)
+//   - rejected: awaiting_guardian → cancelled
+//   - expired (or lost in users-api): awaiting_guardian → expired
+//   - pending: left awaiting
+//
+// Every transition uses the current status as optimistic lock: a passenger cancellation processed
+// meanwhile wins and the booking is skipped. Nothing is published for rejected or expired bookings,
+// since trips-api never heard of them.
+func (s *guardianApprovalService) RunOnce(ctx context.Context) (*domain.GuardianApprovalRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.GuardianApprovalRunResult{}
+
+	var afterID uint
+	for batch := 0; batch < maxGuardianApprovalBatchesPerRun; batch++ {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		bookings, err := s.bookingRepo.FindAwaitingGuardianAfterID(afterID, s.batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(bookings) == 0 {
+			break
+		}
+
+		for i := range bookings {
+			booking := &bookings[i]
+			afterID = booking.ID
+			if booking.GuardianApprovalID == nil {
+				continue
+			}
+			result.Checked++
+
+			status, err := s.approvalStatus(ctx, *booking.GuardianApprovalID)
+			if err != nil {
+				result.LookupFailures++
+				log.Warn().
+					Err(err).
+					Str("booking_id", booking.BookingUUID).
+					Int64("guardian_approval_id", *booking.GuardianApprovalID).
+					Msg("Failed to look up guardian approval, retrying in the next run")
+				continue
+			}
+
+			switch status {
+			case domain.GuardianApprovalApproved:
+				if err := s.approve(ctx, booking, result); err != nil {
+					return result, err
+				}
+			case domain.GuardianApprovalRejected:
+				now := time.Now()
+				if err := s.resolve(booking, domain.BookingStatusCancelled, map[string]interface{}{
+					"cancelled_at":        &now,
+					"cancellation_reason": domain.GuardianRejectedReason,
+				}, domain.GuardianRejectedReason, result); err != nil {
+					return result, err
+				}
+			case domain.GuardianApprovalExpired:
+				if err := s.resolve(booking, domain.BookingStatusExpired, nil, domain.GuardianExpiredReason, result); err != nil {
+					return result, err
+				}
+			}
+		}
+
+		if len(bookings) < s.batchSize {
+			break
+		}
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// approvalStatus returns the status of an approval in users-api
+// An approval users-api no longer has can never be answered, so it counts as expired
+func (s *guardianApprovalService) approvalStatus(ctx context.Context, approvalID int64) (string, error) {
+	approval, err := s.usersClient.GetGuardianApproval(ctx, approvalID)
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) && appErr.Code == domain.ErrGuardianApprovalNotFound.Code {
+		return domain.GuardianApprovalExpired, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return approval.Status, nil
+}
+
+// approve moves an approved booking to pending and publishes reservation.created
+// If the publish fails the booking stays pending and the expiration job expires it, as with any lost event
+func (s *guardianApprovalService) approve(ctx context.Context, booking *dao.Booking, result *domain.GuardianApprovalRunResult) error {
+	now := time.Now()
+	err := s.bookingRepo.TransitionStatus(booking.BookingUUID, domain.BookingStatusAwaitingGuardian, domain.BookingStatusPending, map[string]interface{}{
+		"guardian_approved_at": &now,
+	}, domain.GuardianApprovedReason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		result.StatusChanged++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	result.Approved++
+	s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, domain.BookingStatusAwaitingGuardian, domain.BookingStatusPending, domain.GuardianApprovedReason))
+
+	passengers, err := s.bookingRepo.FindPassengers(booking.BookingUUID)
+	if err != nil {
+		return err
+	}
+
+	err = s.publisher.PublishReservationCreated(
+		ctx,
+		booking.TripID,
+		booking.PassengerID,
+		booking.SeatsRequested,
+		booking.BookingUUID,
+		booking.PickupPointID,
+		booking.SeatHoldID,
+		toReservationPassengers(passengers),
+	)
+	if errors.Is(err, publisher.ErrEventBuffered) {
+		// Published once RabbitMQ is back; the booking stays pending meanwhile
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", booking.TripID).
+			Msg("⏳ Guardian approved the booking - reservation.created buffered until RabbitMQ is available")
+		return nil
+	}
+	if err != nil {
+		result.PublishFailures++
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", booking.TripID).
+			Msg("⚠️  Guardian approved the booking but reservation.created could not be published (eventual consistency)")
+		return nil
+	}
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Str("trip_id", booking.TripID).
+		Msg("✅ Guardian approved the booking - reservation.created published")
+	return nil
+}
+
+// resolve moves a booking the guardian did not approve to its final status
+func (s *guardianApprovalService) resolve(booking *dao.Booking, toStatus string, fields map[string]interface{}, reason string, result *domain.GuardianApprovalRunResult) error {
+	err := s.bookingRepo.TransitionStatus(booking.BookingUUID, domain.BookingStatusAwaitingGuardian, toStatus, fields, reason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		result.StatusChanged++
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if toStatus == domain.BookingStatusCancelled {
+		result.Rejected++
+	} else {
+		result.Expired++
+	}
+	s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, domain.BookingStatusAwaitingGuardian, toStatus, reason))
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Str("status", toStatus).
+		Msg("Booking not approved by the guardian")
+	return nil
+}
+
+// Start runs the guardian approval job on every tick until ctx is cancelled
+func (s *guardianApprovalService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int64("checked", result.Checked).
+				Msg("❌ Guardian approval job failed")
+		} else if result.Approved > 0 || result.Rejected > 0 || result.Expired > 0 || result.LookupFailures > 0 {
+			log.Info().
+				Int64("checked", result.Checked).
+				Int64("approved", result.Approved).
+				Int64("rejected", result.Rejected).
+				Int64("expired", result.Expired).
+				Int64("publish_failures", result.PublishFailures).
+				Int64("lookup_failures", result.LookupFailures).
+				Int64("status_changed", result.StatusChanged).
+				Str("duration", result.Duration).
+				Msg("👪 Guardian approval job resolved bookings")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Guardian approval job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
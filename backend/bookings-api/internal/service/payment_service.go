@@ -0,0 +1,723 @@
+package service
+
+import (
+	"bookings-api/internal/clients"
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/publisher"
+	"bookings-api/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// stalledPaymentAge is how long a payment waits before the payment provider job retries its
+// authorization or capture (leaves room for the synchronous attempt still in flight)
+const stalledPaymentAge = 2 * time.Minute
+
+// maxFailureReasonLength matches the size of payments.failure_reason
+const maxFailureReasonLength = 255
+
+// ErrBookingWithoutPayment is returned by StartPayment for bookings created without a provider payment
+// (before PAYMENT_PROVIDER was configured); they are confirmed directly as before
+var ErrBookingWithoutPayment = errors.New("booking has no provider payment")
+
+// PaymentConfig controls the bookings paid through the payment provider
+type PaymentConfig struct {
+	// AuthorizationTimeout is how long a pending authorization (checkout) may take once trips-api
+	// confirms the seats; the booking is cancelled afterwards. 0 disables the deadline
+	AuthorizationTimeout time.Duration
+
+	// BatchSize is the number of payments or bookings read per query by the job
+	BatchSize int
+}
+
+// PaymentService charges bookings through the payment provider (PAYMENT_PROVIDER)
+//
+// Flow: the payment is created with the booking (pending). reservation.confirmed moves the booking to
+// awaiting_payment and asks the provider to authorize the fare. The booking is confirmed only when the
+// authorization succeeds, right away or later through the webhook, and the fare is captured then.
+// A declined (or overdue) authorization cancels the booking and releases its seats in trips-api.
+// Split-payment bookings are not handled here (see PaymentSplitService).
+// Promotional credits applied at confirmation are deducted from the authorized amount (see CreditsService).
+type PaymentService interface {
+	// Enabled reports whether a payment provider is configured
+	Enabled() bool
+
+	// NewBookingPayment builds the payment stored with a new booking; nil when no provider is configured
+	NewBookingPayment(paymentMethodToken, currency string) *dao.Payment
+
+	// StartPayment authorizes the fare of a booking whose seats trips-api just reserved (pending → awaiting_payment)
+	// minus the promotional credits applied to it
+	// Returns repository.ErrStatusChanged if the booking is no longer pending,
+	// or ErrBookingWithoutPayment if it was created without a payment
+	StartPayment(ctx context.Context, booking *dao.Booking, totalPrice, creditsApplied float64, driverID int64) error
+
+	// HandleWebhook applies an asynchronous status change reported by the provider
+	// Repeating a change already applied is a no-op
+	HandleWebhook(ctx context.Context, request domain.PaymentWebhookRequest) (*domain.PaymentResponse, error)
+
+	// ReleasePayment settles the payment of a cancelled booking: nothing is charged if it was not authorized yet,
+	// otherwise the fare is captured and refundAmount returned. Bookings without a payment are ignored
+	ReleasePayment(ctx context.Context, booking *dao.Booking, refundAmount float64) error
+
+	// GetBookingPayment returns the provider payment of a booking (passenger, driver or admin)
+	GetBookingPayment(ctx context.Context, bookingID string, userID int64, isAdmin bool) (*domain.PaymentResponse, error)
+
+	// RunOnce retries authorizations and captures that did not reach the provider
+	// and cancels the bookings whose authorization did not arrive before the deadline
+	RunOnce(ctx context.Context) (*domain.PaymentRunResult, error)
+
+	// Start runs RunOnce periodically until ctx is cancelled (blocking, run in a goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+// paymentService implements PaymentService
+type paymentService struct {
+	provider    clients.PaymentProvider
+	bookingRepo repository.BookingRepository
+	paymentRepo repository.PaymentRepository
+	publisher   publisher.Publisher
+	statusHub   BookingStatusHub
+	credits     CreditsService
+	cfg         PaymentConfig
+}
+
+// NewPaymentService creates a new PaymentService
+// provider may be nil: bookings are then confirmed without a payment
+func NewPaymentService(provider clients.PaymentProvider, bookingRepo repository.BookingRepository, paymentRepo repository.PaymentRepository, pub publisher.Publisher, statusHub BookingStatusHub, credits CreditsService, cfg PaymentConfig) PaymentService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &paymentService{provider: provider, bookingRepo: bookingRepo, paymentRepo: paymentRepo, publisher: pub, statusHub: statusHub, credits: credits, cfg: cfg}
+}
+
+func (s *paymentService) Enabled() bool {
+	return s.provider != nil
+}
+
+func (s *paymentService) NewBookingPayment(paymentMethodToken, currency string) *dao.Payment {
+	if s.provider == nil {
+		return nil
+	}
+	return &dao.Payment{
+		Provider:           s.provider.Name(),
+		PaymentMethodToken: paymentMethodToken,
+		Currency:           currency,
+		Status:             dao.PaymentStatusPending,
+	}
+}
+
+// StartPayment sets the confirmed fare and moves the booking to awaiting_payment in one transaction,
+// then asks the provider for the authorization. Provider errors are not returned: the transition is
+// already stored and the payment provider job retries the authorization
+func (s *paymentService) StartPayment(ctx context.Context, booking *dao.Booking, totalPrice, creditsApplied float64, driverID int64) error {
+	payment, err := s.paymentRepo.FindPaymentByBooking(booking.BookingUUID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrBookingWithoutPayment
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get booking payment: %w", err)
+	}
+
+	fields := map[string]interface{}{
+		"total_price":     totalPrice,
+		"credits_applied": creditsApplied,
+		"driver_id":       driverID,
+	}
+	if s.cfg.AuthorizationTimeout > 0 {
+		fields["payment_due_at"] = time.Now().Add(s.cfg.AuthorizationTimeout)
+	}
+
+	amountDue := domain.AmountDue(totalPrice, creditsApplied)
+	if err := s.paymentRepo.StartPayment(booking.BookingUUID, fields, amountDue, domain.PaymentAuthorizationReason); err != nil {
+		return err
+	}
+	s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, booking.Status, dao.BookingStatusAwaitingPayment, domain.PaymentAuthorizationReason))
+
+	booking.Status = dao.BookingStatusAwaitingPayment
+	booking.TotalPrice = totalPrice
+	booking.CreditsApplied = creditsApplied
+	booking.DriverID = driverID
+	payment.Amount = amountDue
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Float64("total_price", totalPrice).
+		Float64("amount_due", amountDue).
+		Str("provider", payment.Provider).
+		Msg("💳 Booking awaiting payment authorization")
+
+	if err := s.authorize(ctx, booking, payment); err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Msg("⚠️  Payment authorization failed, the payment job will retry it")
+	}
+	return nil
+}
+
+// HandleWebhook applies the status reported by the provider to the payment and its booking
+func (s *paymentService) HandleWebhook(ctx context.Context, request domain.PaymentWebhookRequest) (*domain.PaymentResponse, error) {
+	payment, err := s.paymentRepo.FindPaymentByProviderID(request.PaymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentNotFound.WithDetails(map[string]interface{}{
+				"payment_id": request.PaymentID,
+			})
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	booking, err := s.findBooking(payment.BookingUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Str("payment_id", request.PaymentID).
+		Str("payment_status", payment.Status).
+		Str("webhook_status", request.Status).
+		Msg("Processing payment webhook")
+
+	// Provider retries of a change already applied are acknowledged without changes
+	if payment.Status == request.Status || (request.Status == dao.PaymentStatusAuthorized && payment.Status == dao.PaymentStatusCaptured) {
+		return domain.ToPaymentResponse(payment, booking), nil
+	}
+
+	conflict := domain.ErrPaymentStatusConflict.WithDetails(map[string]interface{}{
+		"payment_status": payment.Status,
+		"webhook_status": request.Status,
+	})
+
+	switch request.Status {
+	case dao.PaymentStatusAuthorized, dao.PaymentStatusDeclined:
+		if payment.Status != dao.PaymentStatusPending {
+			return nil, conflict
+		}
+		err = s.applyAuthorization(ctx, booking, payment, &domain.ProviderPaymentResult{
+			ProviderPaymentID: payment.ProviderPaymentID,
+			Status:            request.Status,
+			DeclineReason:     request.Reason,
+		})
+
+	case dao.PaymentStatusCaptured:
+		// Some checkouts capture right away: the authorization (confirming the booking) is implied
+		switch payment.Status {
+		case dao.PaymentStatusPending:
+			err = s.applyAuthorization(ctx, booking, payment, &domain.ProviderPaymentResult{
+				ProviderPaymentID: payment.ProviderPaymentID,
+				Status:            dao.PaymentStatusCaptured,
+			})
+		case dao.PaymentStatusAuthorized:
+			err = s.markCaptured(payment)
+		default:
+			return nil, conflict
+		}
+
+	case dao.PaymentStatusRefunded:
+		// Refund issued from the provider side (dispute, dashboard): the whole amount is returned
+		if payment.Status != dao.PaymentStatusCaptured {
+			return nil, conflict
+		}
+		err = s.markRefunded(payment, payment.Amount)
+	}
+
+	if errors.Is(err, repository.ErrPaymentStatusChanged) {
+		return nil, domain.ErrPaymentStatusConflict
+	}
+	if err != nil {
+		log.Error().Err(err).Str("booking_id", booking.BookingUUID).Msg("Failed to apply payment webhook")
+		return nil, fmt.Errorf("failed to apply payment webhook: %w", err)
+	}
+
+	// Return the payment and booking as stored after the change
+	if updated, err := s.paymentRepo.FindPaymentByBooking(booking.BookingUUID); err == nil {
+		payment = updated
+	}
+	if updated, err := s.bookingRepo.FindByID(booking.BookingUUID); err == nil {
+		booking = updated
+	}
+	return domain.ToPaymentResponse(payment, booking), nil
+}
+
+// ReleasePayment settles the payment of a booking that was just cancelled
+func (s *paymentService) ReleasePayment(ctx context.Context, booking *dao.Booking, refundAmount float64) error {
+	payment, err := s.paymentRepo.FindPaymentByBooking(booking.BookingUUID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get booking payment: %w", err)
+	}
+	return s.release(ctx, booking, payment, refundAmount)
+}
+
+func (s *paymentService) GetBookingPayment(ctx context.Context, bookingID string, userID int64, isAdmin bool) (*domain.PaymentResponse, error) {
+	booking, err := s.findBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authorization: passenger, driver (known once confirmed) or admin
+	if !isAdmin && booking.PassengerID != userID && booking.DriverID != userID {
+		return nil, domain.ErrUnauthorized.WithMessage("You can only view the payment of your own bookings")
+	}
+
+	payment, err := s.paymentRepo.FindPaymentByBooking(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentNotFound.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking payment")
+		return nil, fmt.Errorf("failed to get booking payment: %w", err)
+	}
+	return domain.ToPaymentResponse(payment, booking), nil
+}
+
+// RunOnce retries the stalled payments, then cancels the overdue authorizations
+func (s *paymentService) RunOnce(ctx context.Context) (*domain.PaymentRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.PaymentRunResult{Cutoff: startedAt}
+	if s.provider == nil {
+		return result, nil
+	}
+
+	// Step 1: Authorizations and captures that did not reach the provider (one batch per run)
+	payments, err := s.paymentRepo.FindStalledPayments(startedAt.Add(-stalledPaymentAge), s.cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+	for i := range payments {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if err := s.retry(ctx, &payments[i], result); err != nil {
+			result.Failed++
+			log.Error().
+				Err(err).
+				Str("booking_id", payments[i].BookingUUID).
+				Str("payment_status", payments[i].Status).
+				Msg("Payment retry failed")
+		}
+	}
+
+	// Step 2: Bookings whose authorization did not arrive in time
+	if s.cfg.AuthorizationTimeout > 0 {
+		for batch := 0; batch < maxPaymentBatchesPerRun; batch++ {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+
+			bookings, err := s.paymentRepo.FindAwaitingPaymentDueBefore(startedAt, false, s.cfg.BatchSize)
+			if err != nil {
+				return result, err
+			}
+			for i := range bookings {
+				expired, err := s.expire(ctx, &bookings[i])
+				if err != nil {
+					return result, err
+				}
+				if expired {
+					result.BookingsExpired++
+				}
+			}
+
+			// Bookings authorized in the meantime stay awaiting until retried, so stop on a short batch
+			if len(bookings) < s.cfg.BatchSize {
+				break
+			}
+		}
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// Start runs the payment provider job on every tick until ctx is cancelled
+func (s *paymentService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int64("bookings_expired", result.BookingsExpired).
+				Msg("❌ Payment provider job failed")
+		} else if result.AuthorizationsRetried > 0 || result.CapturesRetried > 0 || result.BookingsExpired > 0 || result.Failed > 0 {
+			log.Info().
+				Int64("authorizations_retried", result.AuthorizationsRetried).
+				Int64("captures_retried", result.CapturesRetried).
+				Int64("bookings_expired", result.BookingsExpired).
+				Int64("failed", result.Failed).
+				Str("duration", result.Duration).
+				Msg("💳 Payment provider job completed")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Payment provider job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// authorize asks the provider to authorize the fare of a booking awaiting payment
+func (s *paymentService) authorize(ctx context.Context, booking *dao.Booking, payment *dao.Payment) error {
+	payment.Attempts++
+	providerResult, err := s.provider.Authorize(ctx, domain.PaymentAuthorizationRequest{
+		Reference:          booking.BookingUUID,
+		Amount:             payment.Amount,
+		Currency:           payment.Currency,
+		PaymentMethodToken: payment.PaymentMethodToken,
+		Description:        fmt.Sprintf("Trip %s (%d seats)", booking.TripID, booking.SeatsRequested),
+	})
+	if err != nil {
+		// Record the attempt so the job waits stalledPaymentAge before retrying
+		if updateErr := s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusPending, map[string]interface{}{
+			"attempts":       payment.Attempts,
+			"failure_reason": truncateFailureReason(err.Error()),
+		}); updateErr != nil {
+			log.Warn().Err(updateErr).Str("booking_id", booking.BookingUUID).Msg("Failed to record payment attempt")
+		}
+		return err
+	}
+
+	return s.applyAuthorization(ctx, booking, payment, providerResult)
+}
+
+// applyAuthorization stores the provider's answer to an authorization and moves the booking accordingly:
+// authorized confirms it (and captures the fare), declined cancels it, pending waits for the webhook
+func (s *paymentService) applyAuthorization(ctx context.Context, booking *dao.Booking, payment *dao.Payment, providerResult *domain.ProviderPaymentResult) error {
+	fields := map[string]interface{}{
+		"provider_payment_id": providerResult.ProviderPaymentID,
+		"attempts":            payment.Attempts,
+	}
+
+	switch providerResult.Status {
+	case dao.PaymentStatusAuthorized, dao.PaymentStatusCaptured:
+		now := time.Now()
+		fields["status"] = dao.PaymentStatusAuthorized
+		fields["authorized_at"] = &now
+		fields["failure_reason"] = ""
+		if err := s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusPending, fields); err != nil {
+			return err
+		}
+		payment.Status = dao.PaymentStatusAuthorized
+		payment.ProviderPaymentID = providerResult.ProviderPaymentID
+		payment.AuthorizedAt = &now
+
+		log.Info().
+			Str("booking_id", booking.BookingUUID).
+			Str("payment_id", payment.ProviderPaymentID).
+			Float64("amount", payment.Amount).
+			Msg("✅ Payment authorized")
+		return s.settleAuthorized(ctx, booking, payment, providerResult.Status == dao.PaymentStatusCaptured)
+
+	case dao.PaymentStatusDeclined:
+		fields["status"] = dao.PaymentStatusDeclined
+		fields["failure_reason"] = truncateFailureReason(providerResult.DeclineReason)
+		if err := s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusPending, fields); err != nil {
+			return err
+		}
+		payment.Status = dao.PaymentStatusDeclined
+
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("payment_id", providerResult.ProviderPaymentID).
+			Str("decline_reason", providerResult.DeclineReason).
+			Msg("Payment declined, cancelling booking")
+		return s.cancelAwaitingBooking(ctx, booking, domain.PaymentDeclinedReason)
+
+	default:
+		fields["checkout_url"] = providerResult.CheckoutURL
+		if err := s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusPending, fields); err != nil {
+			return err
+		}
+		payment.ProviderPaymentID = providerResult.ProviderPaymentID
+		payment.CheckoutURL = providerResult.CheckoutURL
+
+		log.Info().
+			Str("booking_id", booking.BookingUUID).
+			Str("payment_id", providerResult.ProviderPaymentID).
+			Msg("💳 Payment authorization pending, waiting for the provider webhook")
+		return nil
+	}
+}
+
+// settleAuthorized confirms the booking of an authorized payment and captures the fare
+// (capturedByProvider: the provider already captured it, only the capture is recorded)
+// If the booking was cancelled meanwhile the payment is released instead (full refund)
+func (s *paymentService) settleAuthorized(ctx context.Context, booking *dao.Booking, payment *dao.Payment, capturedByProvider bool) error {
+	capture := s.capture
+	if capturedByProvider {
+		capture = func(ctx context.Context, payment *dao.Payment) error {
+			return s.markCaptured(payment)
+		}
+	}
+
+	err := s.bookingRepo.TransitionStatus(booking.BookingUUID, dao.BookingStatusAwaitingPayment, dao.BookingStatusConfirmed, nil, domain.PaymentAuthorizedReason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		current, findErr := s.findBooking(booking.BookingUUID)
+		if findErr != nil {
+			return findErr
+		}
+		if !current.IsCancelled() {
+			return capture(ctx, payment)
+		}
+		log.Warn().Str("booking_id", booking.BookingUUID).Msg("Booking cancelled before the payment was authorized, refunding it")
+		if err := capture(ctx, payment); err != nil {
+			return err
+		}
+		return s.release(ctx, current, payment, payment.Amount)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to confirm booking: %w", err)
+	}
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Float64("total_price", booking.TotalPrice).
+		Msg("✅ Booking confirmed after payment authorization")
+	s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, dao.BookingStatusAwaitingPayment, dao.BookingStatusConfirmed, domain.PaymentAuthorizedReason))
+	booking.Status = dao.BookingStatusConfirmed
+
+	// The booking stays confirmed if the capture fails: the payment stays authorized and the job retries it
+	if err := capture(ctx, payment); err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Msg("⚠️  Booking confirmed but failed to capture its payment, the payment job will retry it")
+	}
+	return nil
+}
+
+// capture charges an authorized payment; a failure leaves it authorized for the job to retry
+func (s *paymentService) capture(ctx context.Context, payment *dao.Payment) error {
+	if err := s.provider.Capture(ctx, payment.ProviderPaymentID, payment.Amount); err != nil {
+		return fmt.Errorf("failed to capture payment: %w", err)
+	}
+	return s.markCaptured(payment)
+}
+
+// markCaptured records the capture of an authorized payment
+func (s *paymentService) markCaptured(payment *dao.Payment) error {
+	now := time.Now()
+	if err := s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusAuthorized, map[string]interface{}{
+		"status":      dao.PaymentStatusCaptured,
+		"captured_at": &now,
+	}); err != nil {
+		return err
+	}
+	payment.Status = dao.PaymentStatusCaptured
+	payment.CapturedAt = &now
+
+	log.Info().
+		Str("booking_id", payment.BookingUUID).
+		Str("payment_id", payment.ProviderPaymentID).
+		Float64("amount", payment.Amount).
+		Msg("✅ Payment captured")
+	return nil
+}
+
+// markRefunded records the refund of a captured payment
+func (s *paymentService) markRefunded(payment *dao.Payment, amount float64) error {
+	now := time.Now()
+	if err := s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusCaptured, map[string]interface{}{
+		"status":          dao.PaymentStatusRefunded,
+		"amount_refunded": amount,
+		"refunded_at":     &now,
+	}); err != nil {
+		return err
+	}
+	payment.Status = dao.PaymentStatusRefunded
+	payment.AmountRefunded = amount
+	payment.RefundedAt = &now
+
+	log.Info().
+		Str("booking_id", payment.BookingUUID).
+		Str("payment_id", payment.ProviderPaymentID).
+		Float64("amount_refunded", amount).
+		Msg("✅ Payment refunded")
+	return nil
+}
+
+// release settles the payment of a cancelled booking
+// The cancellation fee is the part of the fare that is not refunded
+func (s *paymentService) release(ctx context.Context, booking *dao.Booking, payment *dao.Payment, refundAmount float64) error {
+	refundAmount = math.Min(math.Max(refundAmount, 0), payment.Amount)
+
+	switch payment.Status {
+	case dao.PaymentStatusPending:
+		// Nothing was charged; a checkout still open at the provider expires on its own
+		err := s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusPending, map[string]interface{}{
+			"status": dao.PaymentStatusCancelled,
+		})
+		if errors.Is(err, repository.ErrPaymentStatusChanged) {
+			// Authorized concurrently (webhook): settleAuthorized sees the cancelled booking and refunds it
+			return nil
+		}
+		return err
+
+	case dao.PaymentStatusAuthorized:
+		if err := s.capture(ctx, payment); err != nil {
+			return err
+		}
+		fallthrough
+
+	case dao.PaymentStatusCaptured:
+		if refundAmount <= 0 {
+			return nil
+		}
+		if err := s.provider.Refund(ctx, payment.ProviderPaymentID, refundAmount); err != nil {
+			log.Error().
+				Err(err).
+				Str("booking_id", booking.BookingUUID).
+				Str("payment_id", payment.ProviderPaymentID).
+				Float64("refund_amount", refundAmount).
+				Msg("❌ Failed to refund payment of cancelled booking")
+			return fmt.Errorf("failed to refund payment: %w", err)
+		}
+		return s.markRefunded(payment, refundAmount)
+	}
+
+	return nil
+}
+
+// cancelAwaitingBooking cancels a booking whose payment failed and releases its seats in trips-api
+func (s *paymentService) cancelAwaitingBooking(ctx context.Context, booking *dao.Booking, reason string) error {
+	now := time.Now()
+	err := s.bookingRepo.TransitionStatus(booking.BookingUUID, dao.BookingStatusAwaitingPayment, dao.BookingStatusCancelled, map[string]interface{}{
+		"cancelled_at":        &now,
+		"cancellation_reason": reason,
+	}, reason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		log.Warn().Str("booking_id", booking.BookingUUID).Msg("Booking status changed concurrently, not cancelling after payment failure")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to cancel booking: %w", err)
+	}
+	s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, dao.BookingStatusAwaitingPayment, dao.BookingStatusCancelled, reason))
+	booking.Status = dao.BookingStatusCancelled
+
+	// Nothing was charged: the credits applied at confirmation go back to the passenger
+	s.credits.Release(ctx, booking)
+
+	// Same eventual consistency as a passenger cancellation: the booking stays cancelled if the publish fails
+	err = s.publisher.PublishReservationCancelled(ctx, booking.TripID, booking.SeatsRequested, booking.BookingUUID, 0, 0, "")
+	if errors.Is(err, publisher.ErrEventBuffered) {
+		log.Warn().
+			Str("booking_id", booking.BookingUUID).
+			Str("event_type", "reservation.cancelled").
+			Msg("⏳ Booking cancelled, reservation.cancelled buffered until RabbitMQ is available")
+	} else if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", booking.TripID).
+			Int("seats_released", booking.SeatsRequested).
+			Msg("⚠️  Booking cancelled but failed to publish reservation.cancelled event (eventual consistency)")
+	}
+	return nil
+}
+
+// retry resumes a stalled payment according to the current status of its booking
+func (s *paymentService) retry(ctx context.Context, payment *dao.Payment, result *domain.PaymentRunResult) error {
+	booking, err := s.findBooking(payment.BookingUUID)
+	if err != nil {
+		return err
+	}
+
+	switch payment.Status {
+	case dao.PaymentStatusPending:
+		if !booking.IsAwaitingPayment() {
+			return s.release(ctx, booking, payment, 0)
+		}
+		result.AuthorizationsRetried++
+		return s.authorize(ctx, booking, payment)
+
+	case dao.PaymentStatusAuthorized:
+		result.CapturesRetried++
+		switch {
+		case booking.IsAwaitingPayment():
+			// The authorization was stored but the booking was not confirmed (crash in between)
+			return s.settleAuthorized(ctx, booking, payment, false)
+		case booking.IsCancelled():
+			return s.release(ctx, booking, payment, domain.CashRefund(booking.TotalPrice, booking.CancellationFee, booking.CreditsApplied))
+		default:
+			return s.capture(ctx, payment)
+		}
+	}
+	return nil
+}
+
+// expire cancels a booking whose authorization is still pending after the deadline
+// Returns false if the payment left pending meanwhile (authorized or declined)
+func (s *paymentService) expire(ctx context.Context, booking *dao.Booking) (bool, error) {
+	payment, err := s.paymentRepo.FindPaymentByBooking(booking.BookingUUID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get booking payment: %w", err)
+	}
+	if payment.Status != dao.PaymentStatusPending {
+		return false, nil
+	}
+
+	err = s.paymentRepo.UpdatePayment(payment.ID, dao.PaymentStatusPending, map[string]interface{}{
+		"status":         dao.PaymentStatusCancelled,
+		"failure_reason": domain.PaymentTimeoutReason,
+	})
+	if errors.Is(err, repository.ErrPaymentStatusChanged) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	log.Warn().
+		Str("booking_id", booking.BookingUUID).
+		Time("payment_due_at", *booking.PaymentDueAt).
+		Msg("Payment not authorized before the deadline, cancelling booking")
+	if err := s.cancelAwaitingBooking(ctx, booking, domain.PaymentTimeoutReason); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// findBooking loads a booking, mapping a missing row to ErrBookingNotFound
+func (s *paymentService) findBooking(bookingID string) (*dao.Booking, error) {
+	booking, err := s.bookingRepo.FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBookingNotFound.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking")
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	return booking, nil
+}
+
+// truncateFailureReason keeps a provider message within payments.failure_reason
+func truncateFailureReason(reason string) string {
+	if len(reason) > maxFailureReasonLength {
+		return reason[:maxFailureReasonLength]
+	}
+	return reason
+}
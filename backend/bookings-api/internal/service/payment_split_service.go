@@ -0,0 +1,477 @@
+package service
+
+import (
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/events"
+	"bookings-api/internal/publisher"
+	"bookings-api/internal/repository"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// maxPaymentBatchesPerRun bounds the work of a single run of the payment deadline job
+const maxPaymentBatchesPerRun = 10
+
+// PaymentSplitConfig controls split-payment bookings
+type PaymentSplitConfig struct {
+	// ShareTimeout is how long the passengers have to pay their shares once trips-api confirms the seats
+	// Shares still pending afterwards are covered by the organizer; 0 disables the deadline
+	ShareTimeout time.Duration
+
+	// LinkBaseURL is the payment page; the link of a share is LinkBaseURL + "/" + token
+	LinkBaseURL string
+
+	// BatchSize is the number of overdue bookings read per query
+	BatchSize int
+}
+
+// PaymentSplitService manages the per-passenger payment shares of split-payment bookings
+//
+// Flow: reservation.confirmed moves a split-payment booking to awaiting_payment and creates one
+// share per passenger. Each share is paid through its own link (the payment provider calls
+// MarkSharePaid). The booking is confirmed when no share is left pending: all of them paid, or
+// the unpaid ones covered by the organizer when the deadline passes (RunOnce).
+type PaymentSplitService interface {
+	// StartSplitPayment creates the shares of a booking whose seats trips-api just reserved (pending → awaiting_payment)
+	// Returns repository.ErrStatusChanged if the booking is no longer pending
+	StartSplitPayment(ctx context.Context, booking *dao.Booking, totalPrice float64, driverID int64) error
+
+	// GetBookingPayment returns the payment progress of a booking (organizer, driver or admin)
+	// Only the organizer and admins get the payment links
+	GetBookingPayment(ctx context.Context, bookingID string, userID int64, isAdmin bool) (*domain.BookingPaymentResponse, error)
+
+	// GetShare returns the share behind a payment link
+	GetShare(ctx context.Context, token string) (*domain.PublicPaymentShareResponse, error)
+
+	// MarkSharePaid records the payment of a share (payment provider webhook) and confirms the booking
+	// once no share is left pending. Paying an already paid share again is a no-op
+	MarkSharePaid(ctx context.Context, token, reference string) (*domain.PublicPaymentShareResponse, error)
+
+	// MarkBookingPaid marks every pending share of a booking awaiting payment as paid with reference
+	// and confirms the booking (admin override, run once a second admin approved it)
+	// reason is stored in the booking status history
+	MarkBookingPaid(ctx context.Context, bookingID, reference, reason string) error
+
+	// RunOnce confirms the bookings whose payment deadline passed, the organizer covering the unpaid shares
+	RunOnce(ctx context.Context) (*domain.PaymentSplitRunResult, error)
+
+	// Start runs RunOnce periodically until ctx is cancelled (blocking, run in a goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+// paymentSplitService implements PaymentSplitService
+type paymentSplitService struct {
+	bookingRepo repository.BookingRepository
+	paymentRepo repository.PaymentRepository
+	publisher   publisher.Publisher
+	statusHub   BookingStatusHub
+	cfg         PaymentSplitConfig
+}
+
+// NewPaymentSplitService creates a new PaymentSplitService
+func NewPaymentSplitService(bookingRepo repository.BookingRepository, paymentRepo repository.PaymentRepository, pub publisher.Publisher, statusHub BookingStatusHub, cfg PaymentSplitConfig) PaymentSplitService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	cfg.LinkBaseURL = strings.TrimRight(cfg.LinkBaseURL, "/")
+	return &paymentSplitService{bookingRepo: bookingRepo, paymentRepo: paymentRepo, publisher: pub, statusHub: statusHub, cfg: cfg}
+}
+
+// StartSplitPayment divides the fare in one share per passenger (see domain.SplitFare)
+// and moves the booking to awaiting_payment in the same transaction
+func (s *paymentSplitService) StartSplitPayment(ctx context.Context, booking *dao.Booking, totalPrice float64, driverID int64) error {
+	passengers, err := s.bookingRepo.FindPassengers(booking.BookingUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get booking passengers: %w", err)
+	}
+	if len(passengers) == 0 {
+		// Cannot happen for bookings created through the API (split payment requires passengers)
+		return fmt.Errorf("split-payment booking %s has no passengers", booking.BookingUUID)
+	}
+
+	amounts := domain.SplitFare(totalPrice, len(passengers))
+	shares := make([]dao.PaymentShare, 0, len(passengers))
+	for i, passenger := range passengers {
+		token, err := generatePaymentToken()
+		if err != nil {
+			return err
+		}
+		shares = append(shares, dao.PaymentShare{
+			BookingUUID:   booking.BookingUUID,
+			SeatNumber:    passenger.SeatNumber,
+			PassengerName: passenger.Name,
+			Token:         token,
+			Amount:        amounts[i],
+			Status:        dao.PaymentShareStatusPending,
+		})
+	}
+
+	fields := map[string]interface{}{
+		"total_price": totalPrice,
+		"driver_id":   driverID,
+	}
+	if s.cfg.ShareTimeout > 0 {
+		fields["payment_due_at"] = time.Now().Add(s.cfg.ShareTimeout)
+	}
+
+	if err := s.paymentRepo.StartSplitPayment(booking.BookingUUID, fields, shares, domain.PaymentAwaitingReason); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Int("shares", len(shares)).
+		Float64("total_price", totalPrice).
+		Msg("✅ Booking awaiting payment shares")
+	s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, booking.Status, dao.BookingStatusAwaitingPayment, domain.PaymentAwaitingReason))
+
+	return nil
+}
+
+func (s *paymentSplitService) GetBookingPayment(ctx context.Context, bookingID string, userID int64, isAdmin bool) (*domain.BookingPaymentResponse, error) {
+	booking, err := s.findBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authorization: organizer, driver (known once confirmed) or admin
+	isOrganizer := booking.PassengerID == userID
+	if !isAdmin && !isOrganizer && booking.DriverID != userID {
+		return nil, domain.ErrUnauthorized.WithMessage("You can only view the payment of your own bookings")
+	}
+	if !booking.SplitPayment {
+		return nil, domain.ErrBookingNotSplitPayment.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+		})
+	}
+
+	shares, err := s.paymentRepo.FindSharesByBooking(bookingID)
+	if err != nil {
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get payment shares")
+		return nil, fmt.Errorf("failed to get payment shares: %w", err)
+	}
+
+	var paymentURL func(token string) string
+	if isOrganizer || isAdmin {
+		paymentURL = s.paymentURL
+	}
+	return domain.ToBookingPaymentResponse(booking, shares, paymentURL), nil
+}
+
+func (s *paymentSplitService) GetShare(ctx context.Context, token string) (*domain.PublicPaymentShareResponse, error) {
+	share, booking, err := s.findShare(token)
+	if err != nil {
+		return nil, err
+	}
+	return toPublicPaymentShare(share, booking), nil
+}
+
+// MarkSharePaid records a payment confirmed by the provider
+// Only pending shares of bookings still awaiting payment can be paid
+func (s *paymentSplitService) MarkSharePaid(ctx context.Context, token, reference string) (*domain.PublicPaymentShareResponse, error) {
+	share, booking, err := s.findShare(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Provider retries of the same payment are acknowledged without changes
+	if share.Status == dao.PaymentShareStatusPaid && share.PaymentReference == reference {
+		return toPublicPaymentShare(share, booking), nil
+	}
+	if share.Status != dao.PaymentShareStatusPending || !booking.IsAwaitingPayment() {
+		return nil, domain.ErrPaymentShareNotPayable.WithDetails(map[string]interface{}{
+			"share_status":   share.Status,
+			"booking_status": booking.Status,
+		})
+	}
+
+	paidAt := time.Now()
+	if err := s.paymentRepo.MarkSharePaid(share.ID, reference, paidAt); err != nil {
+		if errors.Is(err, repository.ErrShareNotPending) {
+			return nil, domain.ErrPaymentShareNotPayable
+		}
+		log.Error().Err(err).Str("booking_id", booking.BookingUUID).Int("seat_number", share.SeatNumber).Msg("Failed to mark payment share as paid")
+		return nil, fmt.Errorf("failed to mark payment share as paid: %w", err)
+	}
+	share.Status = dao.PaymentShareStatusPaid
+	share.PaymentReference = reference
+	share.PaidAt = &paidAt
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Int("seat_number", share.SeatNumber).
+		Float64("amount", share.Amount).
+		Str("payment_reference", reference).
+		Msg("✅ Payment share paid")
+
+	shares, err := s.paymentRepo.FindSharesByBooking(booking.BookingUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment shares: %w", err)
+	}
+	progress := paymentProgress(booking, shares)
+	progress.SeatNumber = share.SeatNumber
+	s.publishProgress(ctx, events.EventTypePaymentSharePaid, progress)
+
+	// Last pending share: confirm the booking (a concurrent last payment may have done it already)
+	if progress.SharesPaid+progress.SharesCovered == progress.SharesTotal {
+		err := s.bookingRepo.TransitionStatus(booking.BookingUUID, dao.BookingStatusAwaitingPayment, dao.BookingStatusConfirmed, nil, domain.PaymentPaidReason)
+		switch {
+		case errors.Is(err, repository.ErrStatusChanged):
+			log.Warn().Str("booking_id", booking.BookingUUID).Msg("Booking status changed concurrently, not confirming after last payment")
+		case err != nil:
+			return nil, fmt.Errorf("failed to confirm booking: %w", err)
+		default:
+			s.completed(ctx, booking, progress, domain.PaymentPaidReason)
+			booking.Status = dao.BookingStatusConfirmed
+		}
+	}
+
+	return toPublicPaymentShare(share, booking), nil
+}
+
+// MarkBookingPaid settles a booking awaiting payment whose shares were paid outside the payment provider
+func (s *paymentSplitService) MarkBookingPaid(ctx context.Context, bookingID, reference, reason string) error {
+	booking, err := s.findBooking(bookingID)
+	if err != nil {
+		return err
+	}
+	if !booking.IsAwaitingPayment() {
+		return domain.ErrAdminActionNotApplicable.WithDetails(map[string]interface{}{
+			"booking_id": bookingID,
+			"status":     booking.Status,
+			"expected":   dao.BookingStatusAwaitingPayment,
+		})
+	}
+
+	paid, err := s.paymentRepo.PayPendingShares(booking.BookingUUID, reference, time.Now(), reason)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return domain.ErrBookingModifiedConcurrently
+	}
+	if err != nil {
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to mark booking as paid")
+		return fmt.Errorf("failed to mark booking as paid: %w", err)
+	}
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Int64("shares_paid", paid).
+		Str("payment_reference", reference).
+		Msg("✅ Booking marked as paid manually")
+
+	shares, err := s.paymentRepo.FindSharesByBooking(booking.BookingUUID)
+	if err != nil {
+		return fmt.Errorf("failed to get payment shares: %w", err)
+	}
+	s.completed(ctx, booking, paymentProgress(booking, shares), reason)
+	return nil
+}
+
+// RunOnce confirms every booking awaiting payment whose deadline passed
+// The pending shares are marked covered: the organizer pays them (reported in payment.completed)
+func (s *paymentSplitService) RunOnce(ctx context.Context) (*domain.PaymentSplitRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.PaymentSplitRunResult{Cutoff: startedAt}
+	if s.cfg.ShareTimeout <= 0 {
+		return result, nil
+	}
+
+	for batch := 0; batch < maxPaymentBatchesPerRun; batch++ {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		bookings, err := s.paymentRepo.FindAwaitingPaymentDueBefore(startedAt, true, s.cfg.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(bookings) == 0 {
+			break
+		}
+
+		for i := range bookings {
+			booking := &bookings[i]
+
+			covered, err := s.paymentRepo.CoverPendingShares(booking.BookingUUID, domain.PaymentCoveredReason)
+			if errors.Is(err, repository.ErrStatusChanged) {
+				result.StatusChanged++
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			result.BookingsSettled++
+			result.SharesCovered += covered
+
+			shares, err := s.paymentRepo.FindSharesByBooking(booking.BookingUUID)
+			if err != nil {
+				return result, err
+			}
+			s.completed(ctx, booking, paymentProgress(booking, shares), domain.PaymentCoveredReason)
+		}
+
+		// Settled bookings are no longer awaiting payment, so the next query returns new ones
+		if len(bookings) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// Start runs the payment deadline job on every tick until ctx is cancelled
+func (s *paymentSplitService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int64("bookings_settled", result.BookingsSettled).
+				Msg("❌ Payment deadline job failed")
+		} else if result.BookingsSettled > 0 || result.StatusChanged > 0 {
+			log.Info().
+				Int64("bookings_settled", result.BookingsSettled).
+				Int64("shares_covered", result.SharesCovered).
+				Int64("status_changed", result.StatusChanged).
+				Str("duration", result.Duration).
+				Msg("💳 Payment deadline job confirmed bookings with shares covered by the organizer")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Payment deadline job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// completed notifies the confirmation of a booking awaiting payment (status stream and payment.completed)
+func (s *paymentSplitService) completed(ctx context.Context, booking *dao.Booking, progress events.PaymentProgress, reason string) {
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Int("shares_paid", progress.SharesPaid).
+		Int("shares_covered", progress.SharesCovered).
+		Float64("amount_covered", progress.AmountCovered).
+		Msg("✅ Split-payment booking confirmed")
+	s.statusHub.Publish(domain.NewBookingStatusEvent(booking.BookingUUID, dao.BookingStatusAwaitingPayment, dao.BookingStatusConfirmed, reason))
+	s.publishProgress(ctx, events.EventTypePaymentCompleted, progress)
+}
+
+// publishProgress publishes a payment event; like the reservation events, a failed publish does not undo the payment
+func (s *paymentSplitService) publishProgress(ctx context.Context, eventType string, progress events.PaymentProgress) {
+	err := s.publisher.PublishPaymentProgress(ctx, eventType, progress)
+	if errors.Is(err, publisher.ErrEventBuffered) {
+		log.Warn().
+			Str("booking_id", progress.ReservationID).
+			Str("event_type", eventType).
+			Msg("⏳ Payment recorded, payment event buffered until RabbitMQ is available")
+	} else if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", progress.ReservationID).
+			Str("event_type", eventType).
+			Msg("⚠️  Payment recorded but failed to publish payment event (eventual consistency)")
+	}
+}
+
+// findBooking loads a booking, mapping a missing row to ErrBookingNotFound
+func (s *paymentSplitService) findBooking(bookingID string) (*dao.Booking, error) {
+	booking, err := s.bookingRepo.FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBookingNotFound.WithDetails(map[string]interface{}{
+				"booking_id": bookingID,
+			})
+		}
+		log.Error().Err(err).Str("booking_id", bookingID).Msg("Failed to get booking")
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	return booking, nil
+}
+
+// findShare loads the share behind a payment link and its booking
+func (s *paymentSplitService) findShare(token string) (*dao.PaymentShare, *dao.Booking, error) {
+	share, err := s.paymentRepo.FindShareByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, domain.ErrPaymentShareNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get payment share: %w", err)
+	}
+
+	booking, err := s.findBooking(share.BookingUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return share, booking, nil
+}
+
+// paymentURL builds the payment link of a share
+func (s *paymentSplitService) paymentURL(token string) string {
+	return s.cfg.LinkBaseURL + "/" + token
+}
+
+// paymentProgress summarizes the shares of a booking for the payment events
+func paymentProgress(booking *dao.Booking, shares []dao.PaymentShare) events.PaymentProgress {
+	progress := events.PaymentProgress{
+		ReservationID: booking.BookingUUID,
+		TripID:        booking.TripID,
+		OrganizerID:   booking.PassengerID,
+		SharesTotal:   len(shares),
+		TotalPrice:    booking.TotalPrice,
+	}
+	for _, share := range shares {
+		switch share.Status {
+		case dao.PaymentShareStatusPaid:
+			progress.SharesPaid++
+			progress.AmountPaid += share.Amount
+		case dao.PaymentShareStatusCovered:
+			progress.SharesCovered++
+			progress.AmountCovered += share.Amount
+		}
+	}
+	progress.AmountPaid = math.Round(progress.AmountPaid*100) / 100
+	progress.AmountCovered = math.Round(progress.AmountCovered*100) / 100
+	return progress
+}
+
+// toPublicPaymentShare converts a share to the payment page response
+func toPublicPaymentShare(share *dao.PaymentShare, booking *dao.Booking) *domain.PublicPaymentShareResponse {
+	return &domain.PublicPaymentShareResponse{
+		BookingID:     booking.BookingUUID,
+		TripID:        booking.TripID,
+		SeatNumber:    share.SeatNumber,
+		PassengerName: share.PassengerName,
+		Amount:        share.Amount,
+		Status:        share.Status,
+		Payable:       share.Status == dao.PaymentShareStatusPending && booking.IsAwaitingPayment(),
+		DueAt:         booking.PaymentDueAt,
+	}
+}
+
+// generatePaymentToken generates the random token of a payment link (32 bytes, hex encoded)
+func generatePaymentToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate payment token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
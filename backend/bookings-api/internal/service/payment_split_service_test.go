@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/events"
+	"bookings-api/internal/publisher"
+	"bookings-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// splitStore is an in-memory stand-in for the bookings and payment_shares tables
+// The embedded interfaces are nil: calling a method the tests do not expect panics
+type splitStore struct {
+	repository.BookingRepository
+	repository.PaymentRepository
+
+	bookings   map[string]*dao.Booking
+	passengers map[string][]dao.BookingPassenger
+	shares     []dao.PaymentShare
+}
+
+func newSplitStore(booking *dao.Booking, passengers ...string) *splitStore {
+	stored := *booking
+	store := &splitStore{
+		bookings:   map[string]*dao.Booking{booking.BookingUUID: &stored},
+		passengers: map[string][]dao.BookingPassenger{},
+	}
+	for i, name := range passengers {
+		store.passengers[booking.BookingUUID] = append(store.passengers[booking.BookingUUID], dao.BookingPassenger{
+			BookingUUID: booking.BookingUUID,
+			SeatNumber:  i + 1,
+			Name:        name,
+		})
+	}
+	return store
+}
+
+func (s *splitStore) FindByID(id string) (*dao.Booking, error) {
+	booking, ok := s.bookings[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *booking
+	return &copied, nil
+}
+
+func (s *splitStore) FindPassengers(bookingUUID string) ([]dao.BookingPassenger, error) {
+	return s.passengers[bookingUUID], nil
+}
+
+func (s *splitStore) TransitionStatus(bookingUUID, fromStatus, toStatus string, fields map[string]interface{}, reason string) error {
+	booking := s.bookings[bookingUUID]
+	if booking.Status != fromStatus {
+		return repository.ErrStatusChanged
+	}
+	booking.Status = toStatus
+	return nil
+}
+
+func (s *splitStore) StartSplitPayment(bookingUUID string, fields map[string]interface{}, shares []dao.PaymentShare, reason string) error {
+	booking := s.bookings[bookingUUID]
+	if booking.Status != dao.BookingStatusPending {
+		return repository.ErrStatusChanged
+	}
+	booking.Status = dao.BookingStatusAwaitingPayment
+	booking.TotalPrice = fields["total_price"].(float64)
+	booking.DriverID = fields["driver_id"].(int64)
+	if dueAt, ok := fields["payment_due_at"].(time.Time); ok {
+		booking.PaymentDueAt = &dueAt
+	}
+	for i := range shares {
+		shares[i].ID = uint(len(s.shares) + 1)
+		s.shares = append(s.shares, shares[i])
+	}
+	return nil
+}
+
+func (s *splitStore) FindSharesByBooking(bookingUUID string) ([]dao.PaymentShare, error) {
+	var shares []dao.PaymentShare
+	for _, share := range s.shares {
+		if share.BookingUUID == bookingUUID {
+			shares = append(shares, share)
+		}
+	}
+	return shares, nil
+}
+
+func (s *splitStore) FindShareByToken(token string) (*dao.PaymentShare, error) {
+	for _, share := range s.shares {
+		if share.Token == token {
+			copied := share
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *splitStore) MarkSharePaid(shareID uint, reference string, paidAt time.Time) error {
+	for i := range s.shares {
+		if s.shares[i].ID != shareID {
+			continue
+		}
+		if s.shares[i].Status != dao.PaymentShareStatusPending {
+			return repository.ErrShareNotPending
+		}
+		s.shares[i].Status = dao.PaymentShareStatusPaid
+		s.shares[i].PaymentReference = reference
+		s.shares[i].PaidAt = &paidAt
+		return nil
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (s *splitStore) CoverPendingShares(bookingUUID, reason string) (int64, error) {
+	booking := s.bookings[bookingUUID]
+	if booking.Status != dao.BookingStatusAwaitingPayment {
+		return 0, repository.ErrStatusChanged
+	}
+	var covered int64
+	for i := range s.shares {
+		if s.shares[i].BookingUUID == bookingUUID && s.shares[i].Status == dao.PaymentShareStatusPending {
+			s.shares[i].Status = dao.PaymentShareStatusCovered
+			covered++
+		}
+	}
+	booking.Status = dao.BookingStatusConfirmed
+	return covered, nil
+}
+
+func (s *splitStore) FindAwaitingPaymentDueBefore(dueBefore time.Time, splitPayment bool, limit int) ([]dao.Booking, error) {
+	var due []dao.Booking
+	for _, booking := range s.bookings {
+		if booking.IsAwaitingPayment() && booking.SplitPayment == splitPayment && booking.PaymentDueAt != nil && booking.PaymentDueAt.Before(dueBefore) {
+			due = append(due, *booking)
+		}
+	}
+	return due, nil
+}
+
+// progressRecorder records the payment events instead of publishing them
+type progressRecorder struct {
+	publisher.Publisher
+	published []events.PaymentProgress
+	types     []string
+}
+
+func (p *progressRecorder) PublishPaymentProgress(ctx context.Context, eventType string, progress events.PaymentProgress) error {
+	p.types = append(p.types, eventType)
+	p.published = append(p.published, progress)
+	return nil
+}
+
+func newSplitService(store *splitStore, shareTimeout time.Duration) (PaymentSplitService, *progressRecorder, BookingStatusHub) {
+	pub := &progressRecorder{}
+	hub := NewBookingStatusHub(0)
+	svc := NewPaymentSplitService(store, store, pub, hub, PaymentSplitConfig{
+		ShareTimeout: shareTimeout,
+		LinkBaseURL:  "https://pay.example/",
+	})
+	return svc, pub, hub
+}
+
+// startedBooking returns a split-payment booking already awaiting its shares (100 split among 3 seats)
+func startedBooking(t *testing.T, shareTimeout time.Duration) (*splitStore, PaymentSplitService, *progressRecorder, BookingStatusHub) {
+	t.Helper()
+	booking := &dao.Booking{BookingUUID: "booking-1", TripID: "trip-1", PassengerID: 10, Status: dao.BookingStatusPending, SplitPayment: true}
+	store := newSplitStore(booking, "Ana", "Beto", "Caro")
+	svc, pub, hub := newSplitService(store, shareTimeout)
+	if err := svc.StartSplitPayment(context.Background(), booking, 100, 99); err != nil {
+		t.Fatalf("StartSplitPayment() error = %v", err)
+	}
+	return store, svc, pub, hub
+}
+
+func TestStartSplitPayment_CreatesOneSharePerPassenger(t *testing.T) {
+	booking := &dao.Booking{BookingUUID: "booking-1", TripID: "trip-1", PassengerID: 10, Status: dao.BookingStatusPending, SplitPayment: true}
+	store := newSplitStore(booking, "Ana", "Beto", "Caro")
+	svc, _, hub := newSplitService(store, time.Hour)
+	stream, unsubscribe := hub.Subscribe("booking-1")
+	defer unsubscribe()
+
+	if err := svc.StartSplitPayment(context.Background(), booking, 100, 99); err != nil {
+		t.Fatalf("StartSplitPayment() error = %v", err)
+	}
+
+	wantAmounts := []float64{33.34, 33.33, 33.33}
+	if len(store.shares) != len(wantAmounts) {
+		t.Fatalf("created %d shares, want %d", len(store.shares), len(wantAmounts))
+	}
+	tokens := map[string]bool{}
+	for i, share := range store.shares {
+		if share.Amount != wantAmounts[i] || share.SeatNumber != i+1 || share.Status != dao.PaymentShareStatusPending {
+			t.Errorf("share %d = {seat %d, amount %v, status %s}, want {seat %d, amount %v, pending}", i, share.SeatNumber, share.Amount, share.Status, i+1, wantAmounts[i])
+		}
+		if len(share.Token) != 64 || tokens[share.Token] {
+			t.Errorf("share %d has token %q, want a unique 64-character token", i, share.Token)
+		}
+		tokens[share.Token] = true
+	}
+	if store.shares[1].PassengerName != "Beto" {
+		t.Errorf("passenger name = %q, want it copied from the booking passenger", store.shares[1].PassengerName)
+	}
+
+	stored := store.bookings["booking-1"]
+	if stored.Status != dao.BookingStatusAwaitingPayment || stored.DriverID != 99 || stored.PaymentDueAt == nil {
+		t.Errorf("booking = {status %s, driver %d, due %v}, want awaiting_payment with driver 99 and a deadline", stored.Status, stored.DriverID, stored.PaymentDueAt)
+	}
+
+	select {
+	case event := <-stream:
+		if event.PreviousStatus != dao.BookingStatusPending || event.Status != dao.BookingStatusAwaitingPayment {
+			t.Errorf("status event %s → %s, want pending → awaiting_payment", event.PreviousStatus, event.Status)
+		}
+	default:
+		t.Error("no status event for the move to awaiting_payment")
+	}
+}
+
+func TestStartSplitPayment_WithoutDeadline(t *testing.T) {
+	store, _, _, _ := startedBooking(t, 0)
+	if due := store.bookings["booking-1"].PaymentDueAt; due != nil {
+		t.Errorf("payment_due_at = %v, want none when ShareTimeout is 0", due)
+	}
+}
+
+func TestStartSplitPayment_RequiresPassengers(t *testing.T) {
+	booking := &dao.Booking{BookingUUID: "booking-1", Status: dao.BookingStatusPending, SplitPayment: true}
+	store := newSplitStore(booking)
+	svc, _, _ := newSplitService(store, time.Hour)
+
+	if err := svc.StartSplitPayment(context.Background(), booking, 100, 99); err == nil {
+		t.Fatal("StartSplitPayment() succeeded for a booking without passengers")
+	}
+	if store.bookings["booking-1"].Status != dao.BookingStatusPending {
+		t.Error("a booking without passengers left pending")
+	}
+}
+
+func TestStartSplitPayment_BookingNoLongerPending(t *testing.T) {
+	booking := &dao.Booking{BookingUUID: "booking-1", Status: dao.BookingStatusCancelled, SplitPayment: true}
+	store := newSplitStore(booking, "Ana", "Beto")
+	svc, _, _ := newSplitService(store, time.Hour)
+
+	err := svc.StartSplitPayment(context.Background(), booking, 100, 99)
+	if !errors.Is(err, repository.ErrStatusChanged) {
+		t.Fatalf("StartSplitPayment() on a cancelled booking = %v, want ErrStatusChanged", err)
+	}
+}
+
+func TestMarkSharePaid_ConfirmsAfterTheLastShare(t *testing.T) {
+	store, svc, pub, hub := startedBooking(t, time.Hour)
+	stream, unsubscribe := hub.Subscribe("booking-1")
+	defer unsubscribe()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		share, err := svc.MarkSharePaid(ctx, store.shares[i].Token, "ref-"+store.shares[i].PassengerName)
+		if err != nil {
+			t.Fatalf("MarkSharePaid(share %d) error = %v", i, err)
+		}
+		if share.Status != dao.PaymentShareStatusPaid || share.Payable {
+			t.Errorf("share %d = {status %s, payable %v}, want paid and not payable", i, share.Status, share.Payable)
+		}
+	}
+	if status := store.bookings["booking-1"].Status; status != dao.BookingStatusAwaitingPayment {
+		t.Fatalf("booking status = %s with a share still pending, want awaiting_payment", status)
+	}
+
+	if _, err := svc.MarkSharePaid(ctx, store.shares[2].Token, "ref-Caro"); err != nil {
+		t.Fatalf("MarkSharePaid(last share) error = %v", err)
+	}
+	if status := store.bookings["booking-1"].Status; status != dao.BookingStatusConfirmed {
+		t.Fatalf("booking status = %s after the last share, want confirmed", status)
+	}
+
+	wantTypes := []string{events.EventTypePaymentSharePaid, events.EventTypePaymentSharePaid, events.EventTypePaymentSharePaid, events.EventTypePaymentCompleted}
+	if len(pub.types) != len(wantTypes) {
+		t.Fatalf("published %v, want %v", pub.types, wantTypes)
+	}
+	for i := range wantTypes {
+		if pub.types[i] != wantTypes[i] {
+			t.Fatalf("published %v, want %v", pub.types, wantTypes)
+		}
+	}
+	completed := pub.published[3]
+	if completed.SharesPaid != 3 || completed.AmountPaid != 100 || completed.SharesCovered != 0 {
+		t.Errorf("payment.completed = %+v, want 3 shares paid adding up to 100", completed)
+	}
+	if pub.published[0].SeatNumber != 1 || pub.published[0].SharesPaid != 1 {
+		t.Errorf("first payment.share_paid = %+v, want seat 1 and 1 share paid", pub.published[0])
+	}
+
+	select {
+	case event := <-stream:
+		if event.PreviousStatus != dao.BookingStatusAwaitingPayment || event.Status != dao.BookingStatusConfirmed {
+			t.Errorf("status event %s → %s, want awaiting_payment → confirmed", event.PreviousStatus, event.Status)
+		}
+	default:
+		t.Error("no status event for the confirmation")
+	}
+}
+
+func TestMarkSharePaid_ProviderRetryIsANoOp(t *testing.T) {
+	store, svc, pub, _ := startedBooking(t, time.Hour)
+	ctx := context.Background()
+	token := store.shares[0].Token
+
+	if _, err := svc.MarkSharePaid(ctx, token, "ref-1"); err != nil {
+		t.Fatalf("MarkSharePaid() error = %v", err)
+	}
+	if _, err := svc.MarkSharePaid(ctx, token, "ref-1"); err != nil {
+		t.Fatalf("retry of the same payment = %v, want nil", err)
+	}
+	if len(pub.types) != 1 {
+		t.Errorf("published %d events, want 1 (the retry publishes nothing)", len(pub.types))
+	}
+
+	// A different payment for an already paid share is rejected
+	_, err := svc.MarkSharePaid(ctx, token, "ref-2")
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrPaymentShareNotPayable.Code {
+		t.Errorf("second payment of a paid share = %v, want %s", err, domain.ErrPaymentShareNotPayable.Code)
+	}
+}
+
+func TestMarkSharePaid_BookingNoLongerAwaitingPayment(t *testing.T) {
+	store, svc, _, _ := startedBooking(t, time.Hour)
+	store.bookings["booking-1"].Status = dao.BookingStatusCancelled
+
+	_, err := svc.MarkSharePaid(context.Background(), store.shares[0].Token, "ref-1")
+	var appErr *domain.AppError
+	if !errors.As(err, &appErr) || appErr.Code != domain.ErrPaymentShareNotPayable.Code {
+		t.Fatalf("MarkSharePaid() on a cancelled booking = %v, want %s", err, domain.ErrPaymentShareNotPayable.Code)
+	}
+	if store.shares[0].Status != dao.PaymentShareStatusPending {
+		t.Error("the share of a cancelled booking was marked paid")
+	}
+}
+
+func TestMarkSharePaid_UnknownToken(t *testing.T) {
+	_, svc, _, _ := startedBooking(t, time.Hour)
+
+	_, err := svc.MarkSharePaid(context.Background(), "unknown", "ref-1")
+	if !errors.Is(err, domain.ErrPaymentShareNotFound) {
+		t.Fatalf("MarkSharePaid(unknown token) = %v, want ErrPaymentShareNotFound", err)
+	}
+}
+
+func TestRunOnce_OrganizerCoversUnpaidSharesAfterTheDeadline(t *testing.T) {
+	store, svc, pub, _ := startedBooking(t, time.Hour)
+	ctx := context.Background()
+	if _, err := svc.MarkSharePaid(ctx, store.shares[0].Token, "ref-1"); err != nil {
+		t.Fatalf("MarkSharePaid() error = %v", err)
+	}
+
+	// Before the deadline nothing is settled
+	result, err := svc.RunOnce(ctx)
+	if err != nil || result.BookingsSettled != 0 {
+		t.Fatalf("RunOnce() before the deadline = %+v, %v; want nothing settled", result, err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	store.bookings["booking-1"].PaymentDueAt = &past
+	result, err = svc.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if result.BookingsSettled != 1 || result.SharesCovered != 2 {
+		t.Errorf("RunOnce() = %+v, want 1 booking settled and 2 shares covered", result)
+	}
+	if status := store.bookings["booking-1"].Status; status != dao.BookingStatusConfirmed {
+		t.Errorf("booking status = %s, want confirmed", status)
+	}
+
+	completed := pub.published[len(pub.published)-1]
+	if pub.types[len(pub.types)-1] != events.EventTypePaymentCompleted || completed.AmountPaid != 33.34 || completed.AmountCovered != 66.66 {
+		t.Errorf("last event %s = %+v, want payment.completed with 33.34 paid and 66.66 covered", pub.types[len(pub.types)-1], completed)
+	}
+
+	// A settled booking is not settled again
+	result, err = svc.RunOnce(ctx)
+	if err != nil || result.BookingsSettled != 0 {
+		t.Errorf("second RunOnce() = %+v, %v; want nothing settled", result, err)
+	}
+}
+
+func TestRunOnce_DisabledWithoutShareTimeout(t *testing.T) {
+	store, svc, _, _ := startedBooking(t, 0)
+	past := time.Now().Add(-time.Minute)
+	store.bookings["booking-1"].PaymentDueAt = &past
+
+	result, err := svc.RunOnce(context.Background())
+	if err != nil || result.BookingsSettled != 0 {
+		t.Fatalf("RunOnce() with no ShareTimeout = %+v, %v; want nothing settled", result, err)
+	}
+	if status := store.bookings["booking-1"].Status; status != dao.BookingStatusAwaitingPayment {
+		t.Errorf("booking status = %s, want awaiting_payment", status)
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/repository"
+	"bookings-api/internal/schema"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// EventDispatcher runs the consumer handler of a routing key (implemented by messaging.TripsConsumer)
+type EventDispatcher interface {
+	Dispatch(ctx context.Context, routingKey string, body []byte) error
+
+	// EventType returns the event type (schema name) bound to a routing key in the consumer topology
+	EventType(routingKey string) string
+}
+
+// QuarantineList is a page of quarantined messages with the count of messages per status
+type QuarantineList struct {
+	Messages []dao.QuarantinedMessage `json:"messages"`
+	Total    int64                    `json:"total"`
+	Page     int                      `json:"page"`
+	Limit    int                      `json:"limit"`
+	Counts   map[string]int64         `json:"counts"`
+}
+
+// QuarantineService lets admins inspect, reprocess and discard messages that failed schema validation
+type QuarantineService interface {
+	// List returns quarantined messages, oldest first (status defaults to "quarantined")
+	List(filter repository.QuarantineFilter) (*QuarantineList, error)
+
+	// Get returns a single quarantined message
+	Get(id uint) (*dao.QuarantinedMessage, error)
+
+	// Reprocess validates the stored payload (or the corrected payload, when given) against the
+	// current schemas and, if valid, runs the consumer handler. Invalid payloads stay quarantined
+	Reprocess(ctx context.Context, id uint, payload json.RawMessage, adminID int64) (*dao.QuarantinedMessage, error)
+
+	// Discard drops a quarantined message without processing it
+	Discard(id uint, adminID int64) (*dao.QuarantinedMessage, error)
+}
+
+type quarantineService struct {
+	repo       repository.QuarantineRepository
+	schemas    *schema.Registry
+	dispatcher EventDispatcher
+}
+
+// NewQuarantineService creates a new instance of QuarantineService
+func NewQuarantineService(repo repository.QuarantineRepository, schemas *schema.Registry, dispatcher EventDispatcher) QuarantineService {
+	return &quarantineService{
+		repo:       repo,
+		schemas:    schemas,
+		dispatcher: dispatcher,
+	}
+}
+
+// List returns quarantined messages, oldest first
+func (s *quarantineService) List(filter repository.QuarantineFilter) (*QuarantineList, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Status == "" {
+		filter.Status = dao.QuarantineStatusQuarantined
+	}
+
+	messages, total, err := s.repo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountByStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuarantineList{
+		Messages: messages,
+		Total:    total,
+		Page:     filter.Page,
+		Limit:    filter.Limit,
+		Counts:   counts,
+	}, nil
+}
+
+// Get returns a single quarantined message
+func (s *quarantineService) Get(id uint) (*dao.QuarantinedMessage, error) {
+	msg, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrQuarantinedMessageNotFound
+		}
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Reprocess validates and handles a quarantined message
+func (s *quarantineService) Reprocess(ctx context.Context, id uint, payload json.RawMessage, adminID int64) (*dao.QuarantinedMessage, error) {
+	msg, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Status != dao.QuarantineStatusQuarantined {
+		return nil, domain.ErrQuarantinedMessageResolved
+	}
+
+	body := []byte(msg.Payload)
+	if len(payload) > 0 {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, payload); err != nil {
+			return nil, domain.NewAppError("VALIDATION_ERROR", "payload must be valid JSON", err.Error())
+		}
+		body = compact.Bytes()
+	}
+
+	version, err := s.schemas.Validate(s.dispatcher.EventType(msg.RoutingKey), body)
+	if err != nil {
+		var validationErr *schema.ValidationError
+		if !errors.As(err, &validationErr) {
+			return nil, err
+		}
+		if recErr := s.repo.RecordAttempt(id, string(body), version, validationErr.ErrorsJSON(), err.Error()); recErr != nil {
+			return nil, recErr
+		}
+		return nil, domain.ErrSchemaValidationFailed.WithDetails(validationErr)
+	}
+
+	// Handlers are idempotent (processed_events), so a message handled twice is a no-op
+	if err := s.dispatcher.Dispatch(ctx, msg.RoutingKey, body); err != nil {
+		if recErr := s.repo.RecordAttempt(id, string(body), version, string(msg.ValidationErrors), err.Error()); recErr != nil {
+			log.Error().Err(recErr).Uint("quarantine_id", id).Msg("Failed to record reprocess attempt")
+		}
+		return nil, domain.Wrap(err, "failed to process quarantined message")
+	}
+
+	resolved, err := s.repo.Resolve(id, dao.QuarantineStatusReprocessed, string(body), adminID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, domain.ErrQuarantinedMessageResolved
+	}
+
+	log.Info().
+		Uint("quarantine_id", id).
+		Str("routing_key", msg.RoutingKey).
+		Str("event_id", msg.EventID).
+		Int64("admin_id", adminID).
+		Msg("Quarantined message reprocessed")
+
+	return s.Get(id)
+}
+
+// Discard drops a quarantined message without processing it
+func (s *quarantineService) Discard(id uint, adminID int64) (*dao.QuarantinedMessage, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.repo.Resolve(id, dao.QuarantineStatusDiscarded, "", adminID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, domain.ErrQuarantinedMessageResolved
+	}
+
+	log.Info().
+		Uint("quarantine_id", id).
+		Int64("admin_id", adminID).
+		Msg("Quarantined message discarded")
+
+	return s.Get(id)
+}
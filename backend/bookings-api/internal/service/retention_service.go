@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"bookings-api/internal/repository"
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxRetentionBatchesPerRun bounds the work of a single run; the rest is archived in the next one
+const maxRetentionBatchesPerRun = 20
+
+// RetentionConfig controls booking archival and the anonymized analytics records
+type RetentionConfig struct {
+	// BookingRetention is how long a booking in a terminal status is kept after its last update
+	// 0 disables archival
+	BookingRetention time.Duration
+
+	// AnalyticsEnabled writes an anonymized record for every archived booking
+	AnalyticsEnabled bool
+
+	// AnalyticsRetention is how long anonymized records are kept (0 = forever)
+	AnalyticsRetention time.Duration
+
+	// PriceBuckets are the upper bounds of the price buckets, sorted ascending
+	PriceBuckets []float64
+
+	// BatchSize is the number of bookings archived per transaction
+	BatchSize int
+}
+
+// RetentionService archives old bookings keeping only anonymized analytical data
+type RetentionService interface {
+	// RunOnce archives eligible bookings (up to maxRetentionBatchesPerRun batches) and purges expired analytics
+	RunOnce(ctx context.Context) (*domain.RetentionRunResult, error)
+
+	// Start runs RunOnce periodically until ctx is cancelled (blocking, run in a goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+// retentionService implements RetentionService
+type retentionService struct {
+	repo repository.RetentionRepository
+	cfg  RetentionConfig
+}
+
+// NewRetentionService creates a new RetentionService
+func NewRetentionService(repo repository.RetentionRepository, cfg RetentionConfig) RetentionService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if len(cfg.PriceBuckets) == 0 {
+		cfg.PriceBuckets = domain.DefaultPriceBuckets
+	}
+	return &retentionService{repo: repo, cfg: cfg}
+}
+
+// RunOnce archives bookings in a terminal status older than the retention period
+//
+// For each batch, in a single transaction:
+//  1. The anonymized analytics record is written (if enabled)
+//  2. The booking status history is deleted
+//  3. The booking is deleted
+//
+// Then analytics records older than their own retention period are purged.
+func (s *retentionService) RunOnce(ctx context.Context) (*domain.RetentionRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.RetentionRunResult{}
+
+	if s.cfg.BookingRetention > 0 {
+		cutoff := startedAt.Add(-s.cfg.BookingRetention)
+		result.ArchiveCutoff = cutoff
+
+		for batch := 0; batch < maxRetentionBatchesPerRun; batch++ {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+
+			bookings, err := s.repo.FindArchivable(domain.ArchivableStatuses, cutoff, s.cfg.BatchSize)
+			if err != nil {
+				return result, err
+			}
+			if len(bookings) == 0 {
+				break
+			}
+
+			var analytics []dao.BookingAnalytics
+			if s.cfg.AnalyticsEnabled {
+				analytics = make([]dao.BookingAnalytics, len(bookings))
+				for i := range bookings {
+					analytics[i] = domain.ToBookingAnalytics(&bookings[i], s.cfg.PriceBuckets, startedAt)
+				}
+			}
+
+			archived, err := s.repo.Archive(bookings, analytics, domain.ArchivableStatuses, cutoff)
+			if err != nil {
+				return result, err
+			}
+			result.BookingsArchived += archived
+			if s.cfg.AnalyticsEnabled {
+				result.AnalyticsWritten += archived
+			}
+
+			if len(bookings) < s.cfg.BatchSize {
+				break
+			}
+		}
+	}
+
+	if s.cfg.AnalyticsRetention > 0 {
+		purged, err := s.repo.PurgeAnalytics(startedAt.Add(-s.cfg.AnalyticsRetention))
+		if err != nil {
+			return result, err
+		}
+		result.AnalyticsPurged = purged
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// Start runs the retention job on every tick until ctx is cancelled
+func (s *retentionService) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Int64("bookings_archived", result.BookingsArchived).
+				Msg("❌ Retention job failed")
+		} else if result.BookingsArchived > 0 || result.AnalyticsPurged > 0 {
+			log.Info().
+				Int64("bookings_archived", result.BookingsArchived).
+				Int64("analytics_written", result.AnalyticsWritten).
+				Int64("analytics_purged", result.AnalyticsPurged).
+				Str("duration", result.Duration).
+				Msg("🗄️  Retention job archived bookings")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Retention job stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
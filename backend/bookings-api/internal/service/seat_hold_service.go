@@ -0,0 +1,104 @@
+package service
+
+import (
+	"bookings-api/internal/clients"
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SeatHoldConfig controls the seat holds taken in trips-api before a booking is created
+type SeatHoldConfig struct {
+	// Enabled turns on the hold step; when false bookings use the optimistic flow only
+	Enabled bool
+
+	// TTL is how long trips-api keeps the seats held if reservation.created never confirms the hold
+	TTL time.Duration
+}
+
+// SeatHoldService holds seats synchronously in trips-api so a booking on a nearly-full trip
+// is rejected immediately instead of failing later through reservation.failed
+type SeatHoldService interface {
+	// Hold holds seats for a booking that is about to be created
+	// Returns (nil, nil) when holds are disabled or trips-api cannot be reached (optimistic fallback)
+	// Returns ErrInsufficientSeats / ErrTripNotFound when trips-api rejects the hold
+	Hold(ctx context.Context, tripID, reservationID string, passengerID int64, seats int) (*domain.SeatHold, error)
+
+	// Release releases the hold of a booking that failed, was not published or expired
+	// Bookings without a hold return SeatHoldGone without calling trips-api
+	Release(ctx context.Context, booking *dao.Booking) (domain.SeatHoldRelease, error)
+}
+
+// seatHoldService implements SeatHoldService
+type seatHoldService struct {
+	tripsClient clients.TripsClient
+	cfg         SeatHoldConfig
+}
+
+// NewSeatHoldService creates a new SeatHoldService
+func NewSeatHoldService(tripsClient clients.TripsClient, cfg SeatHoldConfig) SeatHoldService {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 5 * time.Minute
+	}
+	return &seatHoldService{tripsClient: tripsClient, cfg: cfg}
+}
+
+// Hold creates the seat hold in trips-api
+func (s *seatHoldService) Hold(ctx context.Context, tripID, reservationID string, passengerID int64, seats int) (*domain.SeatHold, error) {
+	if !s.cfg.Enabled {
+		return nil, nil
+	}
+
+	hold, err := s.tripsClient.CreateSeatHold(ctx, tripID, domain.SeatHoldRequest{
+		ReservationID: reservationID,
+		PassengerID:   passengerID,
+		Seats:         seats,
+		TTLSeconds:    int(s.cfg.TTL.Seconds()),
+	})
+	if err == nil {
+		return hold, nil
+	}
+
+	var appErr *domain.AppError
+	if errors.As(err, &appErr) && (appErr.Code == domain.ErrInsufficientSeats.Code || appErr.Code == domain.ErrTripNotFound.Code) {
+		return nil, err
+	}
+
+	// trips-api down or without hold support: keep accepting bookings with the optimistic flow
+	log.Warn().
+		Err(err).
+		Str("trip_id", tripID).
+		Str("booking_id", reservationID).
+		Msg("⚠️  Could not hold seats in trips-api, falling back to optimistic booking")
+	return nil, nil
+}
+
+// Release releases the booking's hold in trips-api
+func (s *seatHoldService) Release(ctx context.Context, booking *dao.Booking) (domain.SeatHoldRelease, error) {
+	if booking.SeatHoldID == "" {
+		return domain.SeatHoldGone, nil
+	}
+
+	result, err := s.tripsClient.ReleaseSeatHold(ctx, booking.TripID, booking.SeatHoldID, booking.BookingUUID)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("booking_id", booking.BookingUUID).
+			Str("trip_id", booking.TripID).
+			Str("hold_id", booking.SeatHoldID).
+			Msg("Failed to release seat hold in trips-api")
+		return "", err
+	}
+
+	log.Info().
+		Str("booking_id", booking.BookingUUID).
+		Str("trip_id", booking.TripID).
+		Str("hold_id", booking.SeatHoldID).
+		Str("result", string(result)).
+		Msg("Seat hold released")
+	return result, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"bookings-api/internal/clients"
+	"bookings-api/internal/domain"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxCachedSessions bounds the cache: past it, expired entries are dropped on the next store
+const maxCachedSessions = 10000
+
+// SessionService checks a JWT against the current account state in users-api
+//
+// The HS256 signature alone is not enough: users-api revokes sessions (password reset),
+// deactivates accounts (SCIM) and bans them (admin) while the token stays valid until it expires.
+type SessionService interface {
+	// CheckSession returns nil if the token issued at issuedAt ("iat" claim) is still usable
+	// Errors: ErrSessionRevoked, ErrAccountInactive, ErrAccountSuspended or ErrUsersAPIUnavailable
+	CheckSession(ctx context.Context, userID int64, issuedAt int64) error
+}
+
+type cachedSessionStatus struct {
+	status    *domain.SessionStatus
+	fetchedAt time.Time
+}
+
+type sessionService struct {
+	usersClient clients.UsersClient
+	ttl         time.Duration
+
+	mu    sync.Mutex
+	cache map[int64]cachedSessionStatus
+}
+
+// NewSessionService creates the session check used by the JWT middleware
+// ttl is how long a user's status is reused before users-api is asked again
+func NewSessionService(usersClient clients.UsersClient, ttl time.Duration) SessionService {
+	return &sessionService{
+		usersClient: usersClient,
+		ttl:         ttl,
+		cache:       make(map[int64]cachedSessionStatus),
+	}
+}
+
+// CheckSession checks the token against the (cached) account state
+// When users-api is unavailable the last known status is used; without one the request is rejected
+func (s *sessionService) CheckSession(ctx context.Context, userID int64, issuedAt int64) error {
+	status, err := s.sessionStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return status.CheckToken(issuedAt)
+}
+
+// sessionStatus returns the cached status or fetches it from users-api
+func (s *sessionService) sessionStatus(ctx context.Context, userID int64) (*domain.SessionStatus, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+
+	if ok && now.Sub(cached.fetchedAt) < s.ttl {
+		return cached.status, nil
+	}
+
+	status, err := s.usersClient.GetSessionStatus(ctx, userID)
+	if err != nil {
+		// Deleted account: there is no status to fall back to
+		if errors.Is(err, domain.ErrSessionRevoked) {
+			s.forget(userID)
+			return nil, err
+		}
+		if ok {
+			log.Warn().Err(err).Int64("user_id", userID).Msg("users-api unavailable - using last known session status")
+			return cached.status, nil
+		}
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to check session status")
+		return nil, domain.ErrUsersAPIUnavailable
+	}
+
+	s.store(userID, status, now)
+	return status, nil
+}
+
+func (s *sessionService) store(userID int64, status *domain.SessionStatus, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxCachedSessions {
+		for id, entry := range s.cache {
+			if now.Sub(entry.fetchedAt) >= s.ttl {
+				delete(s.cache, id)
+			}
+		}
+	}
+	s.cache[userID] = cachedSessionStatus{status: status, fetchedAt: now}
+}
+
+func (s *sessionService) forget(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, userID)
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bookings-api/internal/dao"
+	"bookings-api/internal/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SmokeTestConfig configures the startup smoke test (SMOKE_TEST_* environment variables)
+type SmokeTestConfig struct {
+	Enabled            bool
+	TripID             string        // Sandbox trip with free seats, never booked by real passengers
+	PassengerID        int64         // Synthetic passenger that books and cancels
+	PaymentMethodToken string        // Sandbox payment method, used when a payment provider is configured
+	Timeout            time.Duration // Whole run, including the wait for reservation.confirmed
+	PollInterval       time.Duration // How often the booking is re-read while waiting for the confirmation
+}
+
+// SmokeTestService runs an end-to-end check of the environment against the real dependencies:
+// it books a seat on a sandbox trip, waits until trips-api confirms it (and the payment provider
+// authorizes it) and cancels it as the passenger. Deployment pipelines read the outcome from the
+// exit code or from GET /health/smoke.
+type SmokeTestService interface {
+	// Enabled reports whether SMOKE_TEST is set
+	Enabled() bool
+
+	// Run executes the smoke test once and stores its result
+	Run(ctx context.Context) *domain.SmokeTestResult
+
+	// Result returns the last result (disabled, running, passed or failed)
+	Result() *domain.SmokeTestResult
+}
+
+// smokeTestService implements SmokeTestService on top of BookingService
+type smokeTestService struct {
+	bookings  BookingService
+	statusHub BookingStatusHub
+	config    SmokeTestConfig
+
+	mu     sync.RWMutex
+	result *domain.SmokeTestResult
+}
+
+// NewSmokeTestService creates a new SmokeTestService
+func NewSmokeTestService(bookings BookingService, statusHub BookingStatusHub, config SmokeTestConfig) SmokeTestService {
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Minute
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+
+	status := domain.SmokeStatusDisabled
+	if config.Enabled {
+		status = domain.SmokeStatusRunning
+	}
+	return &smokeTestService{
+		bookings:  bookings,
+		statusHub: statusHub,
+		config:    config,
+		result:    &domain.SmokeTestResult{Status: status, Steps: []domain.SmokeStepResult{}},
+	}
+}
+
+func (s *smokeTestService) Enabled() bool {
+	return s.config.Enabled
+}
+
+func (s *smokeTestService) Result() *domain.SmokeTestResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := *s.result
+	result.Steps = append([]domain.SmokeStepResult(nil), s.result.Steps...)
+	return &result
+}
+
+// Run books, waits for the confirmation and cancels; the first failing step ends the run
+// A booking that was created is always cancelled, even when the confirmation never arrived
+func (s *smokeTestService) Run(ctx context.Context) *domain.SmokeTestResult {
+	if !s.config.Enabled {
+		return s.Result()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	s.update(func(r *domain.SmokeTestResult) {
+		*r = domain.SmokeTestResult{
+			Status:    domain.SmokeStatusRunning,
+			TripID:    s.config.TripID,
+			Steps:     []domain.SmokeStepResult{},
+			StartedAt: &startedAt,
+		}
+	})
+
+	log.Info().
+		Str("trip_id", s.config.TripID).
+		Int64("passenger_id", s.config.PassengerID).
+		Dur("timeout", s.config.Timeout).
+		Msg("🧪 Running smoke test")
+
+	passed := s.run(ctx)
+
+	finishedAt := time.Now()
+	s.update(func(r *domain.SmokeTestResult) {
+		r.Status = domain.SmokeStatusFailed
+		if passed {
+			r.Status = domain.SmokeStatusPassed
+		}
+		r.FinishedAt = &finishedAt
+		r.Duration = finishedAt.Sub(startedAt).String()
+	})
+
+	result := s.Result()
+	if passed {
+		log.Info().
+			Str("booking_id", result.BookingID).
+			Str("duration", result.Duration).
+			Msg("✅ Smoke test passed")
+	} else {
+		log.Error().
+			Str("booking_id", result.BookingID).
+			Interface("steps", result.Steps).
+			Msg("❌ Smoke test failed")
+	}
+	return result
+}
+
+// run executes the steps and reports whether all of them passed
+func (s *smokeTestService) run(ctx context.Context) bool {
+	if s.config.TripID == "" || s.config.PassengerID == 0 {
+		s.step(domain.SmokeStepCreateBooking, time.Now(), fmt.Errorf("SMOKE_TEST_TRIP_ID and SMOKE_TEST_PASSENGER_ID are required"))
+		return false
+	}
+
+	// Step 1: create the booking through the same path as POST /api/v1/bookings
+	started := time.Now()
+	booking, err := s.bookings.CreateBooking(ctx, domain.CreateBookingRequest{
+		TripID:             s.config.TripID,
+		PassengerID:        s.config.PassengerID,
+		SeatsReserved:      1,
+		PaymentMethodToken: s.config.PaymentMethodToken,
+	})
+	if err != nil {
+		s.step(domain.SmokeStepCreateBooking, started, err)
+		return false
+	}
+	s.update(func(r *domain.SmokeTestResult) { r.BookingID = booking.ID })
+	s.step(domain.SmokeStepCreateBooking, started, nil)
+
+	// Step 2: wait for trips-api (and the payment provider) to confirm it
+	started = time.Now()
+	confirmErr := s.awaitConfirmation(ctx, booking.ID)
+	s.step(domain.SmokeStepAwaitConfirmation, started, confirmErr)
+
+	// Step 3: cancel it as the passenger (also after a timeout, so the sandbox trip gets its seat back)
+	// A pending booking also publishes reservation.cancelled: trips-api only releases seats for a
+	// reservation its ledger has confirmed, so cancelling one that never took its seat adds none
+	// The run's context may already be expired: the cleanup gets its own deadline
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	// A booking that already ended (failed, expired) holds no seats and cannot be cancelled
+	if confirmErr != nil && s.isFinished(cancelCtx, booking.ID) {
+		return false
+	}
+
+	started = time.Now()
+	cancelErr := s.bookings.CancelBooking(cancelCtx, booking.ID, s.config.PassengerID, domain.SmokeCancellationReason)
+	s.step(domain.SmokeStepCancelBooking, started, cancelErr)
+
+	return confirmErr == nil && cancelErr == nil
+}
+
+// awaitConfirmation waits until the booking is confirmed
+// Transitions applied by this instance arrive through the status hub; the booking is also re-read
+// every PollInterval because another replica may consume reservation.confirmed
+func (s *smokeTestService) awaitConfirmation(ctx context.Context, bookingID string) error {
+	events, unsubscribe := s.statusHub.Subscribe(bookingID)
+	defer unsubscribe()
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		booking, err := s.bookings.GetBooking(ctx, bookingID)
+		if err == nil {
+			switch booking.Status {
+			case dao.BookingStatusConfirmed:
+				return nil
+			case dao.BookingStatusFailed, dao.BookingStatusCancelled, dao.BookingStatusExpired:
+				if booking.CancellationReason != "" {
+					return fmt.Errorf("booking ended as %s: %s", booking.Status, booking.CancellationReason)
+				}
+				return fmt.Errorf("booking ended as %s before being confirmed", booking.Status)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			status := "unknown"
+			if booking != nil {
+				status = booking.Status
+			}
+			return fmt.Errorf("booking not confirmed in time (last status: %s)", status)
+		case <-events:
+		case <-ticker.C:
+		}
+	}
+}
+
+// isFinished reports whether the booking already ended without a confirmation (nothing to cancel)
+func (s *smokeTestService) isFinished(ctx context.Context, bookingID string) bool {
+	booking, err := s.bookings.GetBooking(ctx, bookingID)
+	if err != nil {
+		return false
+	}
+	return booking.Status == dao.BookingStatusFailed || booking.Status == dao.BookingStatusCancelled || booking.Status == dao.BookingStatusExpired
+}
+
+// step records the outcome of a step
+func (s *smokeTestService) step(name string, started time.Time, err error) {
+	step := domain.SmokeStepResult{
+		Name:     name,
+		Passed:   err == nil,
+		Duration: time.Since(started).String(),
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	s.update(func(r *domain.SmokeTestResult) { r.Steps = append(r.Steps, step) })
+}
+
+func (s *smokeTestService) update(apply func(r *domain.SmokeTestResult)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	apply(s.result)
+}
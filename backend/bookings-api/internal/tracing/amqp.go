@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the RabbitMQ instrumentation of the service
+const tracerName = "bookings-api/internal/tracing"
+
+// headerCarrier adapts the headers of an AMQP message to the OpenTelemetry TextMapCarrier
+type headerCarrier amqp.Table
+
+func (h headerCarrier) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h[key] = value
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// StartPublish opens the producer span of a RabbitMQ publish and returns the headers carrying
+// the trace context (traceparent) for the amqp.Publishing
+// The span must be ended with End once the publish (including its retries) finished
+func StartPublish(ctx context.Context, exchange, routingKey string) (context.Context, trace.Span, amqp.Table) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, routingKey+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation", "publish"),
+			attribute.String("messaging.destination.name", exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+		),
+	)
+
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+	return ctx, span, headers
+}
+
+// StartConsume continues the publisher's trace (message headers) with a consumer span
+// A message without traceparent (published before tracing, or by hand) starts a new trace
+func StartConsume(ctx context.Context, queue string, delivery amqp.Delivery) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(delivery.Headers))
+
+	return otel.Tracer(tracerName).Start(ctx, delivery.RoutingKey+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.operation", "process"),
+			attribute.String("messaging.destination.name", delivery.Exchange),
+			attribute.String("messaging.rabbitmq.destination.routing_key", delivery.RoutingKey),
+			attribute.String("messaging.rabbitmq.queue", queue),
+			attribute.String("messaging.message.id", delivery.MessageId),
+		),
+	)
+}
+
+// End ends the span, marking it as failed when err is not nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ServiceName is the service.name of the spans (can be overridden with OTEL_SERVICE_NAME)
+const ServiceName = "bookings-api"
+
+// Init configures the OpenTelemetry SDK of the service
+//
+//   - The W3C propagator (traceparent/tracestate + baggage) is always installed: the trace
+//     context keeps flowing through HTTP and AMQP headers even if this service exports nothing
+//   - Spans are exported over OTLP/HTTP only when OTEL_EXPORTER_OTLP_ENDPOINT (or
+//     OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) is set; the rest of the exporter and sampler
+//     settings are read from the standard OTEL_* variables
+//
+// Returns the shutdown function that flushes the pending spans
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Enabled reports whether spans are being exported (logged at startup)
+func Enabled() bool {
+	_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	return ok
+}
+
+// Middleware opens a server span per request named after the route template (/bookings/:id)
+// and continues the incoming traceparent. /health and /metrics are not traced
+func Middleware() gin.HandlerFunc {
+	return otelgin.Middleware(ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+	}))
+}
+
+// Transport wraps the transport of an HTTP client so every request opens a client span
+// and carries the traceparent header (nil wraps http.DefaultTransport)
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,13 +14,17 @@ import (
 	"search-api/internal/config"
 	"search-api/internal/controllers"
 	"search-api/internal/database"
+	"search-api/internal/domain"
 	"search-api/internal/messaging"
+	"search-api/internal/metrics"
+	"search-api/internal/middleware"
 	"search-api/internal/repository"
 	"search-api/internal/routes"
 	"search-api/internal/service"
+	"search-api/internal/tracing"
 
-	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -39,6 +44,13 @@ func main() {
 	}
 	log.Info().Msg("Configuration loaded successfully")
 
+	// OpenTelemetry: HTTP and RabbitMQ spans (exported over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT is set)
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize OpenTelemetry")
+	}
+	log.Info().Bool("exporting", tracing.Enabled()).Msg("Tracing initialized")
+
 	// Connect to MongoDB
 	db, err := database.ConnectMongoDB(cfg.Mongo.URI, cfg.Mongo.DB)
 	if err != nil {
@@ -51,6 +63,13 @@ func main() {
 	}
 	log.Info().Msg("MongoDB indexes created successfully")
 
+	// Driver boosts shared by sort_by=relevance (Solr) and the relevance ranking strategy
+	rankingWeights := domain.RankingWeights{
+		Responsiveness: cfg.Ranking.ResponsivenessWeight,
+		CompletionRate: cfg.Ranking.CompletionRateWeight,
+		Rating:         cfg.Ranking.RatingWeight,
+	}
+
 	// Connect to Apache Solr with simple client
 	solrClient := clients.NewSolrClient(cfg.Solr.URL, cfg.Solr.Core)
 
@@ -61,39 +80,38 @@ func main() {
 		solrClient = nil // Continue without Solr - graceful degradation
 	} else {
 		log.Info().Msg("Connected to Apache Solr successfully")
-	}
-
-	// Connect to Memcached
-	memcachedClient := memcache.New(cfg.Memcached.Servers...)
-	if err := memcachedClient.Ping(); err != nil {
-		log.Warn().Err(err).Msg("Failed to connect to Memcached (caching disabled)")
-		memcachedClient = nil
-	} else {
-		log.Info().Msg("Connected to Memcached successfully")
+		solrClient.SetRankingWeights(rankingWeights)
 	}
 
 	// Get MongoDB client for health checks
 	mongoClient := getMongoClient(db)
 
-	// Initialize cache using first Memcached server
-	var cacheService cache.Cache
-	if memcachedClient != nil {
-		// Use the first server from the config for cache initialization
-		cacheAddr := cfg.Memcached.Servers[0]
-		var err error
-		cacheService, err = cache.NewMemcachedCache(cacheAddr)
-		if err != nil {
-			log.Warn().Err(err).Msg("Failed to initialize cache service")
-			cacheService = nil
-		} else {
-			log.Info().Msg("Cache service initialized successfully")
-		}
+	// Initialize cache (CACHE_BACKEND=memcached|redis|none)
+	// If the server is unreachable the service keeps running without cache (every lookup is a miss)
+	cacheService, err := cache.New(cache.Config{
+		Backend:          cfg.Cache.Backend,
+		MemcachedServers: cfg.Memcached.Servers,
+		RedisAddr:        cfg.Redis.Addr,
+		RedisPassword:    cfg.Redis.Password,
+		RedisDB:          cfg.Redis.DB,
+	})
+	switch {
+	case err == nil:
+		log.Info().Str("backend", cfg.Cache.Backend).Msg("Cache service initialized successfully")
+	case cfg.Cache.Backend != cache.BackendMemcached && cfg.Cache.Backend != cache.BackendRedis:
+		log.Fatal().Err(err).Msg("Invalid CACHE_BACKEND")
+	default:
+		log.Warn().Err(err).Str("backend", cfg.Cache.Backend).Msg("Failed to connect to cache (caching disabled)")
+		cacheService = cache.NewNoopCache()
 	}
+	defer cacheService.Close()
 
 	// Initialize repositories
 	tripRepo := repository.NewTripRepository(db)
 	eventRepo := repository.NewEventRepository(db)
 	popularRouteRepo := repository.NewPopularRouteRepository(db)
+	slowQueryRepo := repository.NewSlowQueryRepository(db)
+	indexMetadataRepo := repository.NewIndexMetadataRepository(db)
 	log.Info().Msg("Repositories initialized successfully")
 
 	// Initialize HTTP clients
@@ -113,7 +131,16 @@ func main() {
 	})
 	log.Info().Msg("HTTP clients initialized successfully")
 
+	// Admin JWTs are checked against users-api (revoked sessions, deactivated or banned accounts)
+	sessionService := service.NewSessionService(usersClient, time.Duration(cfg.JWT.SessionStatusCacheSeconds)*time.Second)
+
 	// Initialize trip event service
+	badgeThresholds := domain.BadgeThresholds{
+		PriceDropMinPercent: cfg.Badges.PriceDropMinPercent,
+		PriceDropMaxAge:     time.Duration(cfg.Badges.PriceDropMaxAgeHours) * time.Hour,
+		FillingFastMinSeats: cfg.Badges.FillingFastMinSeats,
+		FillingFastWindow:   time.Duration(cfg.Badges.FillingFastWindowHours) * time.Hour,
+	}
 	tripEventService := service.NewTripEventService(
 		tripRepo,
 		eventRepo,
@@ -121,9 +148,29 @@ func main() {
 		usersClient,
 		solrClient,
 		cacheService,
+		time.Duration(cfg.Events.DriverSnapshotMaxAgeSeconds)*time.Second,
+		badgeThresholds,
 	)
 	log.Info().Msg("Trip event service initialized successfully")
 
+	// Ranking strategies selectable with ranking= (new strategies are registered here)
+	rankingStrategies := domain.NewRankingStrategies(rankingWeights)
+
+	// Scorer-based strategies read their weights from a store kept in sync with RANKING_WEIGHTS_FILE
+	scoringWeights := domain.NewScoringWeightsStore(domain.DefaultScoringWeights)
+	weightReloader := service.NewScoringWeightsReloader(
+		scoringWeights,
+		cfg.Ranking.WeightsFile,
+		time.Duration(cfg.Ranking.WeightsReloadSeconds)*time.Second,
+	)
+	if err := weightReloader.Load(); err != nil {
+		log.Fatal().Err(err).Str("path", cfg.Ranking.WeightsFile).Msg("Failed to load RANKING_WEIGHTS_FILE")
+	}
+	rankingStrategies.RegisterScorers(domain.NewScorers(scoringWeights))
+	if _, ok := rankingStrategies.Get(cfg.Ranking.DefaultStrategy); !ok {
+		log.Fatal().Str("strategy", cfg.Ranking.DefaultStrategy).Strs("available", rankingStrategies.Names()).Msg("Unknown RANKING_DEFAULT_STRATEGY")
+	}
+
 	// Initialize search service
 	searchService := service.NewSearchService(
 		tripRepo,
@@ -132,35 +179,110 @@ func main() {
 		solrClient,
 		tripsClient,
 		usersClient,
+		slowQueryRepo,
+		time.Duration(cfg.SlowQuery.ThresholdMs)*time.Millisecond,
+		cfg.Shadow.SamplePercent,
+		cfg.Shadow.MaxInFlight,
+		cfg.ReadThrough.Enabled,
+		time.Duration(cfg.ReadThrough.NegativeTTLSeconds)*time.Second,
+		rankingStrategies,
+		cfg.Ranking.DefaultStrategy,
+		time.Duration(cfg.Cache.StaleWindowSeconds)*time.Second,
 	)
-	log.Info().Msg("Search service initialized successfully")
+	log.Info().Int("shadow_read_sample_percent", cfg.Shadow.SamplePercent).Msg("Search service initialized successfully")
+
+	// Export the search cache counters on GET /metrics
+	prometheus.MustRegister(metrics.NewCacheCollector(searchService.GetCacheStats))
+
+	// Initialize Solr reindexer (admin-triggered bulk rebuild from MongoDB)
+	reindexer := service.NewReindexer(tripRepo, indexMetadataRepo, solrClient, cfg.Reindex.BatchSize, cfg.Reindex.BatchesPerSecond)
+
+	// Compare the schema version of the read model with the one this binary writes
+	// Searches are still served on an outdated index, but applying events to it would mix
+	// documents of both versions: the consumer is not started until cmd/rebuild runs
+	indexService := service.NewIndexMetadataService(indexMetadataRepo, tripRepo)
+	indexMetadata, err := indexService.CheckSchema(ctx)
+	schemaOutdated := errors.Is(err, domain.ErrIndexSchemaOutdated)
+	if err != nil && !schemaOutdated {
+		log.Fatal().Err(err).Msg("Failed to check the search index schema version")
+	}
+	if schemaOutdated {
+		storedVersion := 0
+		if indexMetadata != nil {
+			storedVersion = indexMetadata.SchemaVersion
+		}
+		log.Error().
+			Int("stored_schema_version", storedVersion).
+			Int("expected_schema_version", domain.IndexSchemaVersion).
+			Msg("Search index schema is outdated: not consuming trip events until the read model is rebuilt (go run ./cmd/rebuild) and search-api is restarted")
+	}
 
 	// Initialize RabbitMQ consumer
-	consumer, err := messaging.NewConsumer(cfg.RabbitMQ.URL, cfg.RabbitMQ.QueueName, tripEventService)
+	consumer, err := messaging.NewConsumer(cfg.RabbitMQ.URL, cfg.RabbitMQ.QueueName, tripEventService, indexService)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize RabbitMQ consumer")
 	}
 	log.Info().Msg("RabbitMQ consumer initialized successfully")
 
-	// Start consumer in background
+	// Start consumer in background (events stay queued while the index schema is outdated)
 	consumerCtx, consumerCancel := context.WithCancel(context.Background())
 	defer consumerCancel()
-	go consumer.Start(consumerCtx, cfg.RabbitMQ.URL)
-	log.Info().Msg("RabbitMQ consumer started in background")
+	if !schemaOutdated {
+		go consumer.Start(consumerCtx, cfg.RabbitMQ.URL)
+		indexService.SetConsuming(true)
+		log.Info().Msg("RabbitMQ consumer started in background")
+	}
+
+	// Reload scorer weights when RANKING_WEIGHTS_FILE changes (no-op without a file)
+	go weightReloader.Start(consumerCtx)
+
+	// Mark departed trips as expired and take them out of Solr and the caches
+	expiryService := service.NewTripExpiryService(
+		tripRepo,
+		solrClient,
+		cacheService,
+		time.Duration(cfg.Expiry.IntervalSeconds)*time.Second,
+		cfg.Expiry.BatchSize,
+	)
+	go expiryService.Start(consumerCtx)
+
+	// Expire badges of trips without new events (price drop too old, bookings out of the window)
+	badgeRefreshService := service.NewBadgeRefreshService(
+		tripRepo,
+		cacheService,
+		badgeThresholds,
+		time.Duration(cfg.Badges.RefreshIntervalSeconds)*time.Second,
+		cfg.Badges.RefreshBatchSize,
+	)
+	go badgeRefreshService.Start(consumerCtx)
+
+	// City/province display names returned per location, negotiated with Accept-Language
+	localizations, err := service.LoadLocationLocalizations(cfg.Locale.LocalizationFile, cfg.Locale.DefaultLanguage)
+	if err != nil {
+		log.Fatal().Err(err).Str("path", cfg.Locale.LocalizationFile).Msg("Failed to load LOCATION_LOCALIZATION_FILE")
+	}
 
 	// Initialize controllers
 	healthController := controllers.NewHealthController(
 		mongoClient,
 		solrClient,
-		memcachedClient,
+		cacheService,
+		indexService,
+		consumer,
 		cfg,
 	)
-	searchController := controllers.NewSearchController(searchService)
+	searchController := controllers.NewSearchController(searchService, localizations)
+	adminController := controllers.NewAdminController(searchService, reindexer, weightReloader)
 	log.Info().Msg("Controllers initialized successfully")
 
 	// Setup Gin router
 	router := gin.Default()
-	routes.SetupRoutes(router, healthController, searchController)
+	searchRateLimit := middleware.RateLimit(cacheService, middleware.RateLimitConfig{
+		PerIP:     domain.RateLimit{Requests: cfg.RateLimit.IPRequestsPerMinute, Per: time.Minute, Burst: cfg.RateLimit.IPBurst},
+		PerUser:   domain.RateLimit{Requests: cfg.RateLimit.UserRequestsPerMinute, Per: time.Minute, Burst: cfg.RateLimit.UserBurst},
+		JWTSecret: cfg.JWT.Secret,
+	})
+	routes.SetupRoutes(router, healthController, searchController, adminController, searchRateLimit, middleware.RequireAdmin(cfg.JWT.Secret, sessionService))
 	log.Info().Msg("Routes configured successfully")
 
 	// Configure HTTP server with timeouts
@@ -194,6 +316,9 @@ func main() {
 		log.Error().Err(err).Msg("Failed to close RabbitMQ consumer gracefully")
 	}
 
+	// Cancel a running reindex (what was already sent to Solr is committed)
+	reindexer.Stop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -201,6 +326,11 @@ func main() {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Flush the pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to shut down OpenTelemetry")
+	}
+
 	log.Info().Msg("search-api server exited gracefully")
 }
 
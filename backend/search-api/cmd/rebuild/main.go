@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"search-api/internal/clients"
+	"search-api/internal/config"
+	"search-api/internal/database"
+	"search-api/internal/domain"
+	"search-api/internal/repository"
+	"search-api/internal/service"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// rebuild reconstructs the search read model (MongoDB trips collection + Solr index)
+// from the trips-api event archive, for disaster recovery and schema migrations
+//
+// Usage:
+//
+//	go run ./cmd/rebuild                    # full rebuild from the first archived event
+//	go run ./cmd/rebuild -from-seq 120345   # replay only the events from that sequence on
+//	go run ./cmd/rebuild -dry-run           # fold and verify without writing
+//
+// Prints the report as JSON on stdout and exits with status 1 if the rebuild failed or
+// the resulting count does not match trips-api. While it writes, search-api consumers hold
+// trip events (rebuild fence in index_metadata) and resume them once it finishes. A successful full rebuild stamps the index
+// metadata with the schema version of this binary.
+func main() {
+	fromSequence := flag.Int64("from-seq", 1, "first event archive sequence to replay (1 = full rebuild)")
+	batchSize := flag.Int("batch-size", 0, "events read and trips indexed per batch (default REBUILD_BATCH_SIZE)")
+	dryRun := flag.Bool("dry-run", false, "fold the archive and fetch trips without writing to MongoDB or Solr")
+	flag.Parse()
+
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load config")
+	}
+	if cfg.Rebuild.ArchiveMongoURI == "" {
+		log.Fatal().Msg("TRIPS_ARCHIVE_MONGO_URI is required to read the trips-api event archive")
+	}
+	if *batchSize <= 0 {
+		*batchSize = cfg.Rebuild.BatchSize
+	}
+
+	// Search read model
+	db, err := database.ConnectMongoDB(cfg.Mongo.URI, cfg.Mongo.DB)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to MongoDB")
+	}
+	defer db.Client().Disconnect(context.Background())
+
+	if err := database.CreateIndexes(db); err != nil {
+		log.Fatal().Err(err).Msg("Failed to create MongoDB indexes")
+	}
+
+	// trips-api event archive
+	archiveDB, err := database.ConnectMongoDB(cfg.Rebuild.ArchiveMongoURI, cfg.Rebuild.ArchiveMongoDB)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to the event archive MongoDB")
+	}
+	defer archiveDB.Client().Disconnect(context.Background())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Unlike the API, a rebuild without Solr would leave the index stale: fail instead of degrading
+	solrClient := clients.NewSolrClient(cfg.Solr.URL, cfg.Solr.Core)
+	if err := solrClient.Ping(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Solr ping failed")
+	}
+
+	tripsClient := clients.NewTripsClient(clients.HTTPClientConfig{
+		BaseURL:        cfg.HTTP.TripsAPIURL,
+		Timeout:        time.Duration(cfg.HTTP.Timeout) * time.Second,
+		MaxRetries:     cfg.HTTP.MaxRetries,
+		RetryWaitTime:  1 * time.Second,
+		CircuitBreaker: clients.NewCircuitBreaker(5, 30*time.Second),
+	})
+	usersClient := clients.NewUsersClient(clients.HTTPClientConfig{
+		BaseURL:        cfg.HTTP.UsersAPIURL,
+		Timeout:        time.Duration(cfg.HTTP.Timeout) * time.Second,
+		MaxRetries:     cfg.HTTP.MaxRetries,
+		RetryWaitTime:  1 * time.Second,
+		CircuitBreaker: clients.NewCircuitBreaker(5, 30*time.Second),
+	})
+
+	metadataRepo := repository.NewIndexMetadataRepository(db)
+
+	rebuilder := service.NewRebuilder(
+		repository.NewEventArchiveReader(archiveDB),
+		repository.NewTripRepository(db),
+		metadataRepo,
+		tripsClient,
+		usersClient,
+		solrClient,
+		*batchSize,
+	)
+
+	log.Info().
+		Int64("from_sequence", *fromSequence).
+		Int("batch_size", *batchSize).
+		Bool("dry_run", *dryRun).
+		Msg("Starting search read model rebuild")
+
+	report, err := rebuilder.Run(ctx, service.RebuildOptions{
+		FromSequence: *fromSequence,
+		DryRun:       *dryRun,
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if report != nil {
+		_ = encoder.Encode(report)
+	}
+
+	if err != nil {
+		log.Error().Err(err).Msg("Rebuild aborted")
+		os.Exit(1)
+	}
+	if report.TripsFailed > 0 || report.SolrFailed > 0 || !report.CountsMatch {
+		log.Error().
+			Int64("trips_failed", report.TripsFailed).
+			Int64("solr_failed", report.SolrFailed).
+			Int64("mongo_count", report.MongoCount).
+			Int64("trips_api_count", report.TripsAPICount).
+			Msg("Rebuild finished with errors")
+		os.Exit(1)
+	}
+
+	// A full rebuild writes every document with this binary's schema: search-api instances
+	// waiting on an outdated index start consuming events again after a restart
+	if report.FullRebuild && !report.DryRun {
+		if err := metadataRepo.SetSchemaVersion(ctx, domain.IndexSchemaVersion); err != nil {
+			log.Error().Err(err).Msg("Failed to record the index schema version")
+			os.Exit(1)
+		}
+		if err := metadataRepo.RecordFullReindex(ctx, domain.IndexRebuildSourceRebuild, time.Now()); err != nil {
+			log.Error().Err(err).Msg("Failed to record the full reindex")
+			os.Exit(1)
+		}
+		log.Info().Int("schema_version", domain.IndexSchemaVersion).Msg("Index metadata updated")
+	}
+
+	log.Info().Dur("duration", report.Duration).Msg("Rebuild finished, counts match trips-api")
+}
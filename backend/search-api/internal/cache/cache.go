@@ -3,6 +3,7 @@ package cache
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
@@ -15,3 +16,52 @@ type Cache interface {
 	FlushAll(ctx context.Context) error
 	Close() error
 }
+
+// Pinger lo implementan los backends con un servidor detrás (memcached, redis)
+// El health check lo usa para verificar la conexión; NoopCache no lo implementa
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Updater lo implementan los backends que pueden leer y reescribir una key de forma atómica
+// (memcached con CAS, redis con WATCH), así el valor es consistente entre todas las instancias
+// fn recibe el valor actual (found=false si la key no existe) y retorna el nuevo valor y su TTL;
+// puede ejecutarse más de una vez si otra instancia modificó la key en el medio
+type Updater interface {
+	Update(ctx context.Context, key string, fn func(current string, found bool) (string, time.Duration)) error
+}
+
+// updateAttempts es la cantidad de reintentos de Update ante escrituras concurrentes de la misma key
+const updateAttempts = 5
+
+// Backends de cache seleccionables con CACHE_BACKEND
+const (
+	BackendMemcached = "memcached"
+	BackendRedis     = "redis"
+	BackendNone      = "none"
+)
+
+// Config agrupa la configuración de todos los backends; solo se usa la del backend elegido
+type Config struct {
+	Backend          string
+	MemcachedServers []string
+	RedisAddr        string
+	RedisPassword    string
+	RedisDB          int
+}
+
+// New crea el cache del backend configurado
+// Retorna error si el backend es desconocido o si no se puede conectar con el servidor;
+// el llamador decide si continuar sin cache (NewNoopCache)
+func New(cfg Config) (Cache, error) {
+	switch cfg.Backend {
+	case BackendMemcached:
+		return NewMemcachedCache(cfg.MemcachedServers...)
+	case BackendRedis:
+		return NewRedisCache(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+	case BackendNone:
+		return NewNoopCache(), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q (expected %s, %s or %s)", cfg.Backend, BackendMemcached, BackendRedis, BackendNone)
+	}
+}
@@ -15,9 +15,12 @@ type MemcachedCache struct {
 }
 
 // NewMemcachedCache creates a new MemcachedCache instance
-func NewMemcachedCache(addr string) (*MemcachedCache, error) {
-	// Memcache accepts multiple servers, but we pass only one
-	client := memcache.New(addr)
+// Keys are distributed across all the given servers
+func NewMemcachedCache(servers ...string) (*MemcachedCache, error) {
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no Memcached servers configured")
+	}
+	client := memcache.New(servers...)
 
 	// Configure timeouts
 	client.Timeout = 3 * time.Second
@@ -155,6 +158,45 @@ func (m *MemcachedCache) DeletePattern(ctx context.Context, pattern string) (int
 	return 0, fmt.Errorf("DeletePattern not supported by Memcache (pattern: %s)", pattern)
 }
 
+// Update reescribe la key con CompareAndSwap (Add si no existe), reintentando si otra instancia la modificó
+func (m *MemcachedCache) Update(ctx context.Context, key string, fn func(current string, found bool) (string, time.Duration)) error {
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		item, err := m.client.Get(key)
+		if err != nil && err != memcache.ErrCacheMiss {
+			return fmt.Errorf("error getting key %s: %w", key, err)
+		}
+
+		if err == memcache.ErrCacheMiss {
+			value, ttl := fn("", false)
+			err = m.client.Add(&memcache.Item{Key: key, Value: []byte(value), Expiration: memcachedExpiration(ttl)})
+		} else {
+			value, ttl := fn(string(item.Value), true)
+			item.Value = []byte(value)
+			item.Expiration = memcachedExpiration(ttl)
+			err = m.client.CompareAndSwap(item)
+		}
+
+		switch err {
+		case nil:
+			return nil
+		case memcache.ErrNotStored, memcache.ErrCASConflict, memcache.ErrCacheMiss:
+			continue // Otra instancia escribió (o borró) la key en el medio
+		default:
+			return fmt.Errorf("error updating key %s: %w", key, err)
+		}
+	}
+	return fmt.Errorf("error updating key %s: too many concurrent writes", key)
+}
+
+// memcachedExpiration convierte un TTL a segundos de memcached (mínimo 1: 0 significa sin expiración)
+func memcachedExpiration(ttl time.Duration) int32 {
+	seconds := int32(ttl.Seconds())
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
 // Ping verifica la conexión con Memcache
 func (m *MemcachedCache) Ping(ctx context.Context) error {
 	return m.client.Ping()
@@ -0,0 +1,47 @@
+// internal/cache/noop.go
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// NoopCache implementa Cache sin guardar nada: toda lectura es un miss
+// Se usa con CACHE_BACKEND=none y como fallback cuando el backend configurado no responde al arrancar,
+// así los servicios no necesitan chequear si hay cache
+type NoopCache struct{}
+
+// NewNoopCache crea un cache que no guarda nada
+func NewNoopCache() *NoopCache {
+	return &NoopCache{}
+}
+
+// Get siempre es un miss (string vacío, sin error)
+func (n *NoopCache) Get(ctx context.Context, key string) (string, error) {
+	return "", nil
+}
+
+// Set descarta el valor
+func (n *NoopCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return nil
+}
+
+// Delete no hace nada
+func (n *NoopCache) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+// Exists siempre retorna false
+func (n *NoopCache) Exists(ctx context.Context, key string) (bool, error) {
+	return false, nil
+}
+
+// FlushAll no hace nada
+func (n *NoopCache) FlushAll(ctx context.Context) error {
+	return nil
+}
+
+// Close no hace nada
+func (n *NoopCache) Close() error {
+	return nil
+}
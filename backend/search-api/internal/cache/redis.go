@@ -0,0 +1,128 @@
+// internal/cache/redis.go
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache implements the Cache interface using Redis
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a new RedisCache instance
+func NewRedisCache(addr, password string, db int) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:         addr,
+		Password:     password,
+		DB:           db,
+		DialTimeout:  3 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+		PoolSize:     10,
+	})
+
+	// Verify connection with ping
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return &RedisCache{client: client}, nil
+}
+
+// Get obtiene un valor del cache por su key
+func (r *RedisCache) Get(ctx context.Context, key string) (string, error) {
+	value, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		// Key no existe, retornar string vacío (no es error)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error getting key %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set guarda un valor en el cache con un TTL (0 = sin expiración)
+func (r *RedisCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("error setting key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete elimina una key del cache (si no existe, no es error)
+func (r *RedisCache) Delete(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("error deleting key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Exists verifica si una key existe en el cache
+func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := r.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("error checking existence of key %s: %w", key, err)
+	}
+	return n > 0, nil
+}
+
+// FlushAll elimina todas las keys de la base de Redis configurada
+// ADVERTENCIA: Esta operación es agresiva y afecta TODO el cache
+func (r *RedisCache) FlushAll(ctx context.Context) error {
+	if err := r.client.FlushDB(ctx).Err(); err != nil {
+		return fmt.Errorf("error flushing cache: %w", err)
+	}
+	return nil
+}
+
+// Update reescribe la key dentro de una transacción con WATCH, reintentando si otra instancia la modificó
+func (r *RedisCache) Update(ctx context.Context, key string, fn func(current string, found bool) (string, time.Duration)) error {
+	update := func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, key).Result()
+		found := true
+		if errors.Is(err, redis.Nil) {
+			found = false
+		} else if err != nil {
+			return err
+		}
+
+		value, ttl := fn(current, found)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, value, ttl)
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < updateAttempts; attempt++ {
+		err := r.client.Watch(ctx, update, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue // Otra instancia escribió la key en el medio
+		}
+		if err != nil {
+			return fmt.Errorf("error updating key %s: %w", key, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("error updating key %s: too many concurrent writes", key)
+}
+
+// Ping verifica la conexión con Redis
+func (r *RedisCache) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// Close cierra el pool de conexiones con Redis
+func (r *RedisCache) Close() error {
+	return r.client.Close()
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"search-api/internal/domain"
+	"search-api/internal/tracing"
 
 	"github.com/rs/zerolog/log"
 )
@@ -238,10 +239,11 @@ func ParseStandardResponse(resp *http.Response, target interface{}) error {
 func CreateHTTPClient(timeout time.Duration) *http.Client {
 	return &http.Client{
 		Timeout: timeout,
-		Transport: &http.Transport{
+		// Client spans + traceparent propagation to users-api / trips-api
+		Transport: tracing.Transport(&http.Transport{
 			MaxIdleConns:        100,
 			MaxIdleConnsPerHost: 100,
 			IdleConnTimeout:     90 * time.Second,
-		},
+		}),
 	}
 }
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/url"
 	"search-api/internal/domain"
+	"search-api/internal/tracing"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +20,9 @@ type SolrClient struct {
 	baseURL string
 	core    string
 	client  *http.Client
+
+	// rankingWeights are the driver boosts of the "relevance" sort
+	rankingWeights domain.RankingWeights
 }
 
 // SolrDocument represents a trip document in Solr format
@@ -30,15 +35,22 @@ type SolrDocument struct {
 	DriverRating     []float64 `json:"driver_rating"`
 	DriverTotalTrips []int     `json:"driver_total_trips"`
 
+	// Driver ranking signals (0-1), absent when there is no data yet
+	DriverResponsiveness []float64 `json:"driver_responsiveness,omitempty"`
+	DriverCompletionRate []float64 `json:"driver_completion_rate,omitempty"`
+
 	// Location information
 	OriginCity          []string  `json:"origin_city"`
+	OriginCityID        []string  `json:"origin_city_id,omitempty"`
 	OriginProvince      []string  `json:"origin_province"`
 	OriginLat           []float64 `json:"origin_lat"`
 	OriginLng           []float64 `json:"origin_lng"`
 	DestinationCity     []string  `json:"destination_city"`
+	DestinationCityID   []string  `json:"destination_city_id,omitempty"`
 	DestinationProvince []string  `json:"destination_province"`
 	DestinationLat      []float64 `json:"destination_lat"`
 	DestinationLng      []float64 `json:"destination_lng"`
+	PickupLocations     []string  `json:"pickup_locations,omitempty"` // "lat,lng" of origin + pickup points
 
 	// Trip timing
 	DepartureDatetime        []string `json:"departure_datetime"`
@@ -63,6 +75,7 @@ type SolrDocument struct {
 	// Trip details
 	Status      []string `json:"status"`
 	Description []string `json:"description"`
+	Bookable    []bool   `json:"bookable"`
 
 	// Search-specific fields
 	SearchText      []string  `json:"search_text"`
@@ -80,8 +93,37 @@ type SolrResponse struct {
 		Start    int            `json:"start"`
 		Docs     []SolrDocument `json:"docs"`
 	} `json:"response"`
+	FacetCounts *SolrFacetCounts `json:"facet_counts,omitempty"`
+}
+
+// SolrFacetCounts represents the facet section of a Solr response
+// facet_fields are flat lists alternating value and count: ["Rosario", 12, "Córdoba", 8]
+// facet_ranges hold the same kind of list under "counts" plus the count above the end in "after"
+type SolrFacetCounts struct {
+	FacetQueries map[string]int64          `json:"facet_queries"`
+	FacetFields  map[string][]interface{}  `json:"facet_fields"`
+	FacetRanges  map[string]SolrFacetRange `json:"facet_ranges"`
+}
+
+// SolrFacetRange represents one facet.range of a Solr response
+type SolrFacetRange struct {
+	Counts []interface{} `json:"counts"`
+	After  int64         `json:"after"`
 }
 
+// Facet field/keys used by Search when facets are requested
+const (
+	// destinationCityFacetField is a string copy of destination_city (the text field is tokenized)
+	destinationCityFacetField = "destination_city_facet"
+
+	pricePrefixFacetKey = "price_"
+
+	// priceHistogramFacetKey is the facet.range of the price histogram; it excludes the
+	// price filter (tagged priceFilterTag) so counts cover the whole price range
+	priceHistogramFacetKey = "price_histogram"
+	priceFilterTag         = "price"
+)
+
 // SolrUpdateResponse represents an update/delete response from Solr
 type SolrUpdateResponse struct {
 	ResponseHeader struct {
@@ -101,12 +143,18 @@ func NewSolrClient(baseURL, core string) *SolrClient {
 		Msg("Initializing Solr client")
 
 	return &SolrClient{
-		baseURL: fullURL,
-		core:    core,
-		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL:        fullURL,
+		core:           core,
+		client:         &http.Client{Timeout: 10 * time.Second, Transport: tracing.Transport(nil)},
+		rankingWeights: domain.DefaultRankingWeights,
 	}
 }
 
+// SetRankingWeights configures the driver boosts of the "relevance" sort
+func (s *SolrClient) SetRankingWeights(weights domain.RankingWeights) {
+	s.rankingWeights = weights
+}
+
 // Index adds or updates a trip document in Solr
 func (s *SolrClient) Index(ctx context.Context, trip *domain.SearchTrip) error {
 	if trip == nil {
@@ -161,7 +209,9 @@ func (s *SolrClient) Index(ctx context.Context, trip *domain.SearchTrip) error {
 // Search performs a search query in Solr with filters, using two-phase strategy:
 // 1. Try exact match first
 // 2. If no results and city filters are present, try partial match
-func (s *SolrClient) Search(ctx context.Context, query string, filters map[string]interface{}, page int, limit int, sortBy string, sortOrder string) ([]map[string]interface{}, int, error) {
+// When withFacets is true, the facet counts of the matching trips are returned too (nil otherwise);
+// the same goes for the price histogram with withPriceHistogram
+func (s *SolrClient) Search(ctx context.Context, query string, filters map[string]interface{}, page int, limit int, sort []domain.SortCriterion, withFacets bool, withPriceHistogram bool) ([]map[string]interface{}, int, *domain.SearchFacets, *domain.PriceHistogram, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -179,28 +229,28 @@ func (s *SolrClient) Search(ctx context.Context, query string, filters map[strin
 	}
 
 	// Phase 1: Try exact match first
-	docs, total, err := s.searchWithFilters(ctx, query, filters, page, limit, false, sortBy, sortOrder)
+	docs, total, facets, histogram, err := s.searchWithFilters(ctx, query, filters, page, limit, false, sort, withFacets, withPriceHistogram)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, nil, err
 	}
 
 	// If we have results or no city filters, return immediately
 	if total > 0 || !hasCityFilters {
-		return docs, total, nil
+		return docs, total, facets, histogram, nil
 	}
 
 	// Phase 2: No results with exact match, try partial match on cities
 	log.Debug().Msg("No exact match found in Solr, trying partial match on city names")
-	docs, total, err = s.searchWithFilters(ctx, query, filters, page, limit, true, sortBy, sortOrder)
+	docs, total, facets, histogram, err = s.searchWithFilters(ctx, query, filters, page, limit, true, sort, withFacets, withPriceHistogram)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, nil, err
 	}
 
-	return docs, total, nil
+	return docs, total, facets, histogram, nil
 }
 
 // searchWithFilters performs the actual Solr search with specified match type
-func (s *SolrClient) searchWithFilters(ctx context.Context, query string, filters map[string]interface{}, page int, limit int, usePartialMatch bool, sortBy string, sortOrder string) ([]map[string]interface{}, int, error) {
+func (s *SolrClient) searchWithFilters(ctx context.Context, query string, filters map[string]interface{}, page int, limit int, usePartialMatch bool, sort []domain.SortCriterion, withFacets bool, withPriceHistogram bool) ([]map[string]interface{}, int, *domain.SearchFacets, *domain.PriceHistogram, error) {
 	// Calculate offset
 	start := (page - 1) * limit
 
@@ -219,8 +269,9 @@ func (s *SolrClient) searchWithFilters(ctx context.Context, query string, filter
 	params.Set("rows", fmt.Sprintf("%d", limit))
 
 	// Add sorting
-	if sortField, sortDir := s.buildSortParam(sortBy, sortOrder); sortField != "" {
-		params.Set("sort", fmt.Sprintf("%s %s", sortField, sortDir))
+	sortParam := s.buildSortParam(sort)
+	if sortParam != "" {
+		params.Set("sort", sortParam)
 	}
 
 	// Add filters
@@ -231,29 +282,46 @@ func (s *SolrClient) searchWithFilters(ctx context.Context, query string, filter
 		}
 	}
 
+	// Add facets (counts over all matching documents, not only this page)
+	if withFacets {
+		s.addFacetParams(params)
+	}
+	if withPriceHistogram {
+		s.addPriceHistogramParams(params)
+	}
+
 	// Execute search
 	searchURL := fmt.Sprintf("%s/select?%s", s.baseURL, params.Encode())
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error creating request: %w", err)
+		return nil, 0, nil, nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to execute Solr search")
-		return nil, 0, fmt.Errorf("error executing request: %w", err)
+		return nil, 0, nil, nil, fmt.Errorf("error executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		log.Error().Int("status", resp.StatusCode).Msg("Solr search returned non-OK status")
-		return nil, 0, fmt.Errorf("solr returned status %d", resp.StatusCode)
+		return nil, 0, nil, nil, fmt.Errorf("solr returned status %d", resp.StatusCode)
 	}
 
 	var solrResp SolrResponse
 	if err := json.NewDecoder(resp.Body).Decode(&solrResp); err != nil {
 		log.Error().Err(err).Msg("Failed to decode Solr response")
-		return nil, 0, fmt.Errorf("error decoding response: %w", err)
+		return nil, 0, nil, nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	var facets *domain.SearchFacets
+	if withFacets {
+		facets = s.parseFacets(solrResp.FacetCounts)
+	}
+	var histogram *domain.PriceHistogram
+	if withPriceHistogram {
+		histogram = s.parsePriceHistogram(solrResp.FacetCounts)
 	}
 
 	// Convert SolrDocuments to generic maps
@@ -266,10 +334,110 @@ func (s *SolrClient) searchWithFilters(ctx context.Context, query string, filter
 		Int("num_found", solrResp.Response.NumFound).
 		Int("returned", len(docs)).
 		Bool("partial_match", usePartialMatch).
-		Str("sort", fmt.Sprintf("%s %s", sortBy, sortOrder)).
+		Str("sort", sortParam).
+		Bool("facets", withFacets).
+		Bool("price_histogram", withPriceHistogram).
 		Msg("Solr search completed successfully")
 
-	return docs, solrResp.Response.NumFound, nil
+	return docs, solrResp.Response.NumFound, facets, histogram, nil
+}
+
+// addFacetParams adds the facet parameters: top destination cities,
+// one facet.query per price bucket and one per preference flag
+func (s *SolrClient) addFacetParams(params url.Values) {
+	params.Set("facet", "true")
+	params.Set("facet.mincount", "1")
+	params.Set("facet.limit", fmt.Sprintf("%d", domain.MaxDestinationFacets))
+	params.Add("facet.field", destinationCityFacetField)
+
+	for i, bucket := range domain.NewPriceBuckets() {
+		// Lower bound inclusive, upper bound exclusive: [min TO max}
+		upper := "*]"
+		if bucket.Max != nil {
+			upper = fmt.Sprintf("%f}", *bucket.Max)
+		}
+		params.Add("facet.query", fmt.Sprintf("{!key=%s%d}price_per_seat:[%f TO %s", pricePrefixFacetKey, i, bucket.Min, upper))
+	}
+
+	for _, field := range []string{"pets_allowed", "smoking_allowed", "music_allowed"} {
+		params.Add("facet.query", fmt.Sprintf("{!key=%s}%s:true", field, field))
+	}
+}
+
+// addPriceHistogramParams adds a facet.range over price_per_seat with the histogram buckets
+// The price filter is excluded ({!ex=...}) and empty buckets are kept
+func (s *SolrClient) addPriceHistogramParams(params url.Values) {
+	params.Set("facet", "true")
+	params.Add("facet.range", fmt.Sprintf("{!ex=%s key=%s}price_per_seat", priceFilterTag, priceHistogramFacetKey))
+	params.Set("f.price_per_seat.facet.range.start", "0")
+	params.Set("f.price_per_seat.facet.range.end", fmt.Sprintf("%f", domain.PriceHistogramMax))
+	params.Set("f.price_per_seat.facet.range.gap", fmt.Sprintf("%f", domain.PriceHistogramBucketWidth))
+	params.Set("f.price_per_seat.facet.range.other", "after")
+	params.Set("f.price_per_seat.facet.mincount", "0")
+}
+
+// parsePriceHistogram converts the price histogram facet.range into a PriceHistogram
+func (s *SolrClient) parsePriceHistogram(counts *SolrFacetCounts) *domain.PriceHistogram {
+	histogram := domain.NewPriceHistogram()
+	if counts == nil {
+		return histogram
+	}
+
+	priceRange, ok := counts.FacetRanges[priceHistogramFacetKey]
+	if !ok {
+		return histogram
+	}
+
+	// counts alternate the bucket start (as a string) and its count
+	for i := 0; i+1 < len(priceRange.Counts); i += 2 {
+		start, ok := priceRange.Counts[i].(string)
+		count, isNumber := priceRange.Counts[i+1].(float64)
+		if !ok || !isNumber {
+			continue
+		}
+		min, err := strconv.ParseFloat(start, 64)
+		if err != nil {
+			continue
+		}
+		histogram.Buckets[domain.PriceHistogramIndex(min)].Count += int64(count)
+	}
+	histogram.Buckets[len(histogram.Buckets)-1].Count += priceRange.After
+
+	return histogram
+}
+
+// parseFacets converts the Solr facet_counts section into SearchFacets
+func (s *SolrClient) parseFacets(counts *SolrFacetCounts) *domain.SearchFacets {
+	facets := &domain.SearchFacets{
+		DestinationCities: []domain.FacetCount{},
+		PriceRanges:       domain.NewPriceBuckets(),
+	}
+	if counts == nil {
+		return facets
+	}
+
+	// facet_fields alternate value and count
+	values := counts.FacetFields[destinationCityFacetField]
+	for i := 0; i+1 < len(values); i += 2 {
+		city, ok := values[i].(string)
+		count, isNumber := values[i+1].(float64)
+		if !ok || !isNumber {
+			continue
+		}
+		facets.DestinationCities = append(facets.DestinationCities, domain.FacetCount{Value: city, Count: int64(count)})
+	}
+
+	for i := range facets.PriceRanges {
+		facets.PriceRanges[i].Count = counts.FacetQueries[fmt.Sprintf("%s%d", pricePrefixFacetKey, i)]
+	}
+
+	facets.Preferences = domain.PreferenceFacets{
+		PetsAllowed:    counts.FacetQueries["pets_allowed"],
+		SmokingAllowed: counts.FacetQueries["smoking_allowed"],
+		MusicAllowed:   counts.FacetQueries["music_allowed"],
+	}
+
+	return facets
 }
 
 // Delete removes a trip document from Solr
@@ -314,6 +482,100 @@ func (s *SolrClient) Delete(ctx context.Context, tripID string) error {
 	return nil
 }
 
+// IndexBatch adds or updates several trip documents in a single request
+// The documents are not committed: call Commit once the whole batch run is done
+func (s *SolrClient) IndexBatch(ctx context.Context, trips []*domain.SearchTrip) error {
+	if len(trips) == 0 {
+		return nil
+	}
+
+	docs := make([]SolrDocument, 0, len(trips))
+	for _, trip := range trips {
+		if trip == nil {
+			return fmt.Errorf("trip cannot be nil")
+		}
+		docs = append(docs, s.mapTripToSolrDocument(trip))
+	}
+
+	data, err := json.Marshal(docs)
+	if err != nil {
+		return fmt.Errorf("error marshalling documents: %w", err)
+	}
+
+	if err := s.postUpdate(ctx, "", string(data)); err != nil {
+		log.Error().Err(err).Int("documents", len(docs)).Msg("Solr batch index failed")
+		return err
+	}
+
+	log.Debug().Int("documents", len(docs)).Msg("Trip batch indexed in Solr")
+	return nil
+}
+
+// DeleteBatch removes several trips from the index in a single committed request
+func (s *SolrClient) DeleteBatch(ctx context.Context, tripIDs []string) error {
+	if len(tripIDs) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(map[string][]string{"delete": tripIDs})
+	if err != nil {
+		return fmt.Errorf("error marshalling delete: %w", err)
+	}
+
+	if err := s.postUpdate(ctx, "commit=true", string(data)); err != nil {
+		log.Error().Err(err).Int("documents", len(tripIDs)).Msg("Solr batch delete failed")
+		return err
+	}
+
+	log.Debug().Int("documents", len(tripIDs)).Msg("Trip batch deleted from Solr")
+	return nil
+}
+
+// Commit makes all pending (uncommitted) updates visible to searches
+func (s *SolrClient) Commit(ctx context.Context) error {
+	if err := s.postUpdate(ctx, "commit=true", `{"commit":{}}`); err != nil {
+		log.Error().Err(err).Msg("Solr commit failed")
+		return err
+	}
+	return nil
+}
+
+// postUpdate sends a JSON body to the /update handler and checks the response status
+func (s *SolrClient) postUpdate(ctx context.Context, query string, body string) error {
+	updateURL := fmt.Sprintf("%s/update", s.baseURL)
+	if query != "" {
+		updateURL += "?" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", updateURL, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("solr returned status %d", resp.StatusCode)
+	}
+
+	var updateResp SolrUpdateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&updateResp); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	if updateResp.ResponseHeader.Status != 0 {
+		return fmt.Errorf("solr update failed with status %d", updateResp.ResponseHeader.Status)
+	}
+
+	return nil
+}
+
 // Ping checks if Solr is reachable
 func (s *SolrClient) Ping(ctx context.Context) error {
 	params := url.Values{}
@@ -342,6 +604,120 @@ func (s *SolrClient) Ping(ctx context.Context) error {
 	return nil
 }
 
+// solrMBeansResponse is the /admin/mbeans response requested with json.nl=map:
+// category (CORE, CACHE) -> bean name (searcher, filterCache, ...) -> stats
+type solrMBeansResponse struct {
+	MBeans map[string]map[string]struct {
+		Stats map[string]interface{} `json:"stats"`
+	} `json:"solr-mbeans"`
+}
+
+// WarmStats reads the searcher and cache statistics of the core from the mbeans handler
+func (s *SolrClient) WarmStats(ctx context.Context) (*domain.IndexWarmStats, error) {
+	params := url.Values{}
+	params.Set("stats", "true")
+	params.Add("cat", "CORE")
+	params.Add("cat", "CACHE")
+	params.Set("wt", "json")
+	params.Set("json.nl", "map")
+
+	url := fmt.Sprintf("%s/admin/mbeans?%s", s.baseURL, params.Encode())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating mbeans request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("solr mbeans request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("solr mbeans returned status %d", resp.StatusCode)
+	}
+
+	var mbeans solrMBeansResponse
+	if err := json.NewDecoder(resp.Body).Decode(&mbeans); err != nil {
+		return nil, fmt.Errorf("error decoding mbeans response: %w", err)
+	}
+
+	return parseWarmStats(s.core, &mbeans), nil
+}
+
+// parseWarmStats maps the mbeans stats to IndexWarmStats
+// Stat names are prefixed with their registry path (SEARCHER.searcher.warmupTime,
+// CACHE.searcher.filterCache.hits, ...), so only the last segment is matched
+func parseWarmStats(core string, mbeans *solrMBeansResponse) *domain.IndexWarmStats {
+	stats := &domain.IndexWarmStats{
+		Core:   core,
+		Caches: []domain.SolrCacheStats{},
+	}
+
+	if searcher, ok := mbeans.MBeans["CORE"]["searcher"]; ok {
+		for name, value := range searcher.Stats {
+			switch statName(name) {
+			case "numDocs":
+				stats.NumDocs = statInt(value)
+			case "warmupTime":
+				stats.SearcherWarmupMs = statInt(value)
+			case "openedAt":
+				if str, ok := value.(string); ok {
+					if openedAt, err := time.Parse(time.RFC3339, str); err == nil {
+						stats.SearcherOpenedAt = &openedAt
+					}
+				}
+			}
+		}
+	}
+
+	for cacheName, bean := range mbeans.MBeans["CACHE"] {
+		cache := domain.SolrCacheStats{Name: cacheName}
+		for name, value := range bean.Stats {
+			switch statName(name) {
+			case "size":
+				cache.Size = statInt(value)
+			case "lookups":
+				cache.Lookups = statInt(value)
+			case "hits":
+				cache.Hits = statInt(value)
+			case "hitratio":
+				cache.HitRatio = statFloat(value)
+			case "evictions":
+				cache.Evictions = statInt(value)
+			case "warmupTime":
+				cache.WarmupMs = statInt(value)
+			case "cumulative_hitratio":
+				cache.CumulativeHitRatio = statFloat(value)
+			}
+		}
+		stats.Caches = append(stats.Caches, cache)
+	}
+
+	return stats
+}
+
+// statName returns the last segment of a dotted mbeans stat name
+func statName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// statFloat reads a numeric mbeans stat (numbers, or strings in older Solr versions)
+func statFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
+
+func statInt(value interface{}) int64 {
+	return int64(statFloat(value))
+}
+
 // Helper: mapTripToSolrDocument converts SearchTrip to SolrDocument
 // Only indexes non-empty fields to prevent Solr index pollution
 func (s *SolrClient) mapTripToSolrDocument(trip *domain.SearchTrip) SolrDocument {
@@ -362,11 +738,20 @@ func (s *SolrClient) mapTripToSolrDocument(trip *domain.SearchTrip) SolrDocument
 	if trip.Driver.TotalTrips > 0 {
 		doc.DriverTotalTrips = []int{trip.Driver.TotalTrips}
 	}
+	if trip.Driver.ResponsivenessScore > 0 {
+		doc.DriverResponsiveness = []float64{trip.Driver.ResponsivenessScore}
+	}
+	if trip.Driver.CompletionRate > 0 {
+		doc.DriverCompletionRate = []float64{trip.Driver.CompletionRate}
+	}
 
 	// Origin location (validate non-empty)
 	if trip.Origin.City != "" {
 		doc.OriginCity = []string{trip.Origin.City}
 	}
+	if trip.Origin.CityID != "" {
+		doc.OriginCityID = []string{trip.Origin.CityID}
+	}
 	if trip.Origin.Province != "" {
 		doc.OriginProvince = []string{trip.Origin.Province}
 	}
@@ -376,10 +761,18 @@ func (s *SolrClient) mapTripToSolrDocument(trip *domain.SearchTrip) SolrDocument
 		doc.OriginLng = []float64{trip.Origin.Coordinates.Lng()}
 	}
 
+	// Origin + pickup points as a multi-valued location field ("lat,lng")
+	for _, point := range trip.PickupLocations {
+		doc.PickupLocations = append(doc.PickupLocations, fmt.Sprintf("%f,%f", point.Lat(), point.Lng()))
+	}
+
 	// Destination location (validate non-empty)
 	if trip.Destination.City != "" {
 		doc.DestinationCity = []string{trip.Destination.City}
 	}
+	if trip.Destination.CityID != "" {
+		doc.DestinationCityID = []string{trip.Destination.CityID}
+	}
 	if trip.Destination.Province != "" {
 		doc.DestinationProvince = []string{trip.Destination.Province}
 	}
@@ -430,6 +823,7 @@ func (s *SolrClient) mapTripToSolrDocument(trip *domain.SearchTrip) SolrDocument
 	if trip.Description != "" {
 		doc.Description = []string{trip.Description}
 	}
+	doc.Bookable = []bool{trip.Bookable}
 
 	// Search-specific fields
 	if trip.SearchText != "" {
@@ -469,9 +863,18 @@ func (s *SolrClient) solrDocumentToMap(doc SolrDocument) map[string]interface{}
 	if len(doc.DriverTotalTrips) > 0 {
 		m["driver_total_trips"] = doc.DriverTotalTrips[0]
 	}
+	if len(doc.DriverResponsiveness) > 0 {
+		m["driver_responsiveness"] = doc.DriverResponsiveness[0]
+	}
+	if len(doc.DriverCompletionRate) > 0 {
+		m["driver_completion_rate"] = doc.DriverCompletionRate[0]
+	}
 	if len(doc.OriginCity) > 0 {
 		m["origin_city"] = doc.OriginCity[0]
 	}
+	if len(doc.OriginCityID) > 0 {
+		m["origin_city_id"] = doc.OriginCityID[0]
+	}
 	if len(doc.OriginProvince) > 0 {
 		m["origin_province"] = doc.OriginProvince[0]
 	}
@@ -484,6 +887,9 @@ func (s *SolrClient) solrDocumentToMap(doc SolrDocument) map[string]interface{}
 	if len(doc.DestinationCity) > 0 {
 		m["destination_city"] = doc.DestinationCity[0]
 	}
+	if len(doc.DestinationCityID) > 0 {
+		m["destination_city_id"] = doc.DestinationCityID[0]
+	}
 	if len(doc.DestinationProvince) > 0 {
 		m["destination_province"] = doc.DestinationProvince[0]
 	}
@@ -532,6 +938,9 @@ func (s *SolrClient) solrDocumentToMap(doc SolrDocument) map[string]interface{}
 	if len(doc.Status) > 0 {
 		m["status"] = doc.Status[0]
 	}
+	if len(doc.Bookable) > 0 {
+		m["bookable"] = doc.Bookable[0]
+	}
 	if len(doc.Description) > 0 {
 		m["description"] = doc.Description[0]
 	}
@@ -548,12 +957,32 @@ func (s *SolrClient) solrDocumentToMap(doc SolrDocument) map[string]interface{}
 	return m
 }
 
+// FilterQueries returns the fq parameters Search sends to Solr for the given filters
+// Used for diagnostics (slow query log); does not contact Solr
+func (s *SolrClient) FilterQueries(filters map[string]interface{}, usePartialMatch bool) []string {
+	return s.buildFilterQueries(filters, usePartialMatch)
+}
+
 // Helper: buildFilterQueries converts filter map to Solr filter queries
 // usePartialMatch: if true, city fields will use wildcard matching instead of exact match
 func (s *SolrClient) buildFilterQueries(filters map[string]interface{}, usePartialMatch bool) []string {
 	var fqs []string
 
+	// Sides filtered by city ID are combined into a single fq (see cityFilterQuery)
+	handled := make(map[string]bool)
+	for _, side := range []string{"origin", "destination"} {
+		if fq, ok := s.cityFilterQuery(filters, side, usePartialMatch); ok {
+			fqs = append(fqs, fq)
+			handled[side+"_city_id"] = true
+			handled[side+"_city"] = true
+			handled[side+"_province"] = true
+		}
+	}
+
 	for key, value := range filters {
+		if handled[key] {
+			continue
+		}
 		switch v := value.(type) {
 		case string:
 			if v != "" {
@@ -561,6 +990,12 @@ func (s *SolrClient) buildFilterQueries(filters map[string]interface{}, useParti
 				if usePartialMatch && (key == "origin_city" || key == "destination_city") {
 					// Use wildcard for prefix search (case-insensitive by default in Solr)
 					fqs = append(fqs, fmt.Sprintf(`%s:%s*`, key, strings.ToLower(v)))
+				} else if key == "price_per_seat" {
+					// Range tagged so the price histogram can exclude it
+					fqs = append(fqs, fmt.Sprintf(`{!tag=%s}%s:%s`, priceFilterTag, key, v))
+				} else if key == "departure_datetime" {
+					// Date range, quoting it would turn it into a term query
+					fqs = append(fqs, fmt.Sprintf(`%s:%s`, key, v))
 				} else {
 					// Wrap string values in quotes to handle spaces and special characters
 					fqs = append(fqs, fmt.Sprintf(`%s:"%s"`, key, v))
@@ -572,12 +1007,46 @@ func (s *SolrClient) buildFilterQueries(filters map[string]interface{}, useParti
 			fqs = append(fqs, fmt.Sprintf("%s:%f", key, v))
 		case bool:
 			fqs = append(fqs, fmt.Sprintf("%s:%t", key, v))
+		case []string:
+			// Any of the values: status:("published" OR "closed")
+			if len(v) > 0 {
+				fqs = append(fqs, fmt.Sprintf(`%s:("%s")`, key, strings.Join(v, `" OR "`)))
+			}
 		}
 	}
 
 	return fqs
 }
 
+// cityFilterQuery builds the fq of one side of the trip ("origin" or "destination") when the
+// filters carry its city ID: documents indexed with a catalog city match on the ID, and documents
+// without one (free-text cities, indexed before the catalog) fall back to the city name and province
+// Returns false when the side has no city ID (the name filters are then applied on their own)
+func (s *SolrClient) cityFilterQuery(filters map[string]interface{}, side string, usePartialMatch bool) (string, bool) {
+	cityID, _ := filters[side+"_city_id"].(string)
+	if cityID == "" {
+		return "", false
+	}
+
+	byID := fmt.Sprintf(`%s_city_id:"%s"`, side, cityID)
+	city, _ := filters[side+"_city"].(string)
+	if city == "" {
+		return byID, true
+	}
+
+	byName := []string{"*:*", fmt.Sprintf("-%s_city_id:[* TO *]", side)}
+	if usePartialMatch {
+		byName = append(byName, fmt.Sprintf(`+%s_city:%s*`, side, strings.ToLower(city)))
+	} else {
+		byName = append(byName, fmt.Sprintf(`+%s_city:"%s"`, side, city))
+	}
+	if province, _ := filters[side+"_province"].(string); province != "" {
+		byName = append(byName, fmt.Sprintf(`+%s_province:"%s"`, side, province))
+	}
+
+	return fmt.Sprintf("%s OR (%s)", byID, strings.Join(byName, " ")), true
+}
+
 // Helper: formatSolrDate formats time.Time to ISO 8601 for Solr
 func (s *SolrClient) formatSolrDate(t time.Time) string {
 	if t.IsZero() {
@@ -586,7 +1055,34 @@ func (s *SolrClient) formatSolrDate(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
 }
 
-func (s *SolrClient) buildSortParam(sortBy string, sortOrder string) (string, string) {
+// relevanceSortFunction multiplies the text score by the driver boost (see domain.RankingWeights)
+// Missing signals count as 0, so drivers without data are ranked by text score alone
+func (s *SolrClient) relevanceSortFunction() string {
+	w := s.rankingWeights
+	return fmt.Sprintf(
+		"product(query($q,0),sum(1,mul(%g,def(driver_responsiveness,0)),mul(%g,def(driver_completion_rate,0)),mul(%g,min(div(def(driver_rating,0),5),1))))",
+		w.Responsiveness, w.CompletionRate, w.Rating,
+	)
+}
+
+// buildSortParam builds the compound Solr sort ("field dir,field dir") from the sort criteria
+// A field already sorted by an earlier criterion is skipped; no criteria means no sorting
+func (s *SolrClient) buildSortParam(sort []domain.SortCriterion) string {
+	clauses := make([]string, 0, len(sort))
+	seen := make(map[string]bool)
+	for _, criterion := range sort {
+		solrField, sortDir := s.sortClause(criterion.Field, criterion.Order)
+		if solrField == "" || seen[solrField] {
+			continue
+		}
+		seen[solrField] = true
+		clauses = append(clauses, fmt.Sprintf("%s %s", solrField, sortDir))
+	}
+	return strings.Join(clauses, ",")
+}
+
+// sortClause converts one sort field and order to a Solr sort field and direction
+func (s *SolrClient) sortClause(sortBy string, sortOrder string) (string, string) {
 	// Default sort direction
 	if sortOrder == "" {
 		sortOrder = "asc"
@@ -612,6 +1108,16 @@ func (s *SolrClient) buildSortParam(sortBy string, sortOrder string) (string, st
 	case "popularity":
 		solrField = "popularity_score"
 		sortOrder = "desc"
+	case "relevance":
+		solrField = s.relevanceSortFunction()
+		sortOrder = "desc"
+	// Flexible format (respects sortOrder)
+	case "price":
+		solrField = "price_per_seat"
+	case "departure_time":
+		solrField = "departure_datetime"
+	case "rating":
+		solrField = "driver_rating"
 	case "":
 		// No sorting
 		return "", ""
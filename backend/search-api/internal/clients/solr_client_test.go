@@ -0,0 +1,85 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mbeansBody is a trimmed Solr 9 /admin/mbeans?stats=true&cat=CORE&cat=CACHE&json.nl=map response
+const mbeansBody = `{
+  "responseHeader": {"status": 0, "QTime": 1},
+  "solr-mbeans": {
+    "CORE": {
+      "searcher": {
+        "class": "org.apache.solr.search.SolrIndexSearcher",
+        "stats": {
+          "SEARCHER.searcher.numDocs": 1200,
+          "SEARCHER.searcher.warmupTime": 45,
+          "SEARCHER.searcher.openedAt": "2025-06-01T12:00:00.123Z"
+        }
+      }
+    },
+    "CACHE": {
+      "filterCache": {
+        "class": "org.apache.solr.search.CaffeineCache",
+        "stats": {
+          "CACHE.searcher.filterCache.size": 120,
+          "CACHE.searcher.filterCache.lookups": 900,
+          "CACHE.searcher.filterCache.hits": 810,
+          "CACHE.searcher.filterCache.hitratio": 0.9,
+          "CACHE.searcher.filterCache.evictions": 3,
+          "CACHE.searcher.filterCache.warmupTime": 30,
+          "CACHE.searcher.filterCache.cumulative_hitratio": "0.88"
+        }
+      }
+    }
+  }
+}`
+
+func TestSolrClient_WarmStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/solr/trips/admin/mbeans", r.URL.Path)
+		assert.Equal(t, []string{"CORE", "CACHE"}, r.URL.Query()["cat"])
+		assert.Equal(t, "map", r.URL.Query().Get("json.nl"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(mbeansBody))
+	}))
+	defer server.Close()
+
+	stats, err := NewSolrClient(server.URL+"/solr", "trips").WarmStats(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "trips", stats.Core)
+	assert.Equal(t, int64(1200), stats.NumDocs)
+	assert.Equal(t, int64(45), stats.SearcherWarmupMs)
+	require.NotNil(t, stats.SearcherOpenedAt)
+	assert.True(t, stats.SearcherOpenedAt.Equal(time.Date(2025, 6, 1, 12, 0, 0, 123e6, time.UTC)))
+
+	require.Len(t, stats.Caches, 1)
+	cache := stats.Caches[0]
+	assert.Equal(t, "filterCache", cache.Name)
+	assert.Equal(t, int64(120), cache.Size)
+	assert.Equal(t, int64(900), cache.Lookups)
+	assert.Equal(t, int64(810), cache.Hits)
+	assert.Equal(t, 0.9, cache.HitRatio)
+	assert.Equal(t, int64(3), cache.Evictions)
+	assert.Equal(t, int64(30), cache.WarmupMs)
+	assert.Equal(t, 0.88, cache.CumulativeHitRatio)
+}
+
+func TestSolrClient_WarmStatsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := NewSolrClient(server.URL+"/solr", "trips").WarmStats(context.Background())
+
+	assert.Error(t, err)
+}
@@ -14,6 +14,8 @@ import (
 // TripsClient defines the interface for communicating with trips-api
 type TripsClient interface {
 	GetTrip(ctx context.Context, tripID string) (*domain.Trip, error)
+	CountTrips(ctx context.Context) (int64, error)
+	GetDriverResponseTime(ctx context.Context, driverID int64) (*domain.DriverResponseTime, error)
 }
 
 // tripsHTTPClient implements TripsClient using HTTP
@@ -110,3 +112,88 @@ func (c *tripsHTTPClient) GetTrip(ctx context.Context, tripID string) (*domain.T
 
 	return trip, nil
 }
+
+// CountTrips returns the total number of trips stored in trips-api
+// Endpoint: GET /trips?limit=1 (reads the pagination total)
+func (c *tripsHTTPClient) CountTrips(ctx context.Context) (int64, error) {
+	url := fmt.Sprintf("%s/trips?page=1&limit=1", c.baseURL)
+
+	var total int64
+	err := c.circuitBreaker.Call(func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return domain.WrapError(domain.ErrInvalidResponse, "failed to create HTTP request")
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "search-api/1.0")
+
+		resp, err := DoRequestWithRetry(ctx, c.client, req, c.maxRetries, c.retryWaitTime)
+		if err != nil {
+			return err
+		}
+
+		var page struct {
+			Total int64 `json:"total"`
+		}
+		if err := ParseStandardResponse(resp, &page); err != nil {
+			return err
+		}
+
+		total = page.Total
+		return nil
+	})
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to count trips in trips-api")
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// GetDriverResponseTime fetches the rolling response-time averages of a driver
+// Endpoint: GET /internal/drivers/:id/response-time (internal route, no auth required)
+func (c *tripsHTTPClient) GetDriverResponseTime(ctx context.Context, driverID int64) (*domain.DriverResponseTime, error) {
+	url := fmt.Sprintf("%s/internal/drivers/%d/response-time", c.baseURL, driverID)
+
+	var responseTime *domain.DriverResponseTime
+	err := c.circuitBreaker.Call(func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return domain.WrapError(domain.ErrInvalidResponse, "failed to create HTTP request")
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "search-api/1.0")
+
+		resp, err := DoRequestWithRetry(ctx, c.client, req, c.maxRetries, c.retryWaitTime)
+		if err != nil {
+			return err
+		}
+
+		type responseTimeStats struct {
+			AvgSeconds  float64 `json:"avg_seconds"`
+			SampleCount int     `json:"sample_count"`
+		}
+		var stats struct {
+			Chat responseTimeStats `json:"chat"`
+		}
+		if err := ParseStandardResponse(resp, &stats); err != nil {
+			return err
+		}
+
+		responseTime = &domain.DriverResponseTime{
+			ChatAvgSeconds: stats.Chat.AvgSeconds,
+			ChatSamples:    stats.Chat.SampleCount,
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Warn().Err(err).Int64("driver_id", driverID).Msg("Failed to fetch driver response time from trips-api")
+		return nil, err
+	}
+
+	return responseTime, nil
+}
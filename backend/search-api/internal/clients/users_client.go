@@ -14,6 +14,7 @@ import (
 // UsersClient defines the interface for communicating with users-api
 type UsersClient interface {
 	GetUser(ctx context.Context, userID int64) (*domain.User, error)
+	GetSessionStatus(ctx context.Context, userID int64) (*domain.SessionStatus, error)
 }
 
 // usersHTTPClient implements UsersClient using HTTP
@@ -111,3 +112,42 @@ func (c *usersHTTPClient) GetUser(ctx context.Context, userID int64) (*domain.Us
 
 	return user, nil
 }
+
+// GetSessionStatus fetches whether a user's tokens are still usable from users-api
+// Endpoint: GET /internal/users/:id/session-status (internal route, no auth required)
+// A deleted user returns domain.ErrSessionRevoked
+func (c *usersHTTPClient) GetSessionStatus(ctx context.Context, userID int64) (*domain.SessionStatus, error) {
+	url := fmt.Sprintf("%s/internal/users/%d/session-status", c.baseURL, userID)
+
+	var status *domain.SessionStatus
+	err := c.circuitBreaker.Call(func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return domain.WrapError(domain.ErrInvalidResponse, "failed to create HTTP request")
+		}
+		req.Header.Set("User-Agent", "search-api/1.0")
+
+		resp, err := DoRequestWithRetry(ctx, c.client, req, c.maxRetries, c.retryWaitTime)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return domain.ErrSessionRevoked
+		}
+
+		var statusData domain.SessionStatus
+		if err := ParseStandardResponse(resp, &statusData); err != nil {
+			return err
+		}
+
+		status = &statusData
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
@@ -8,13 +8,27 @@ import (
 )
 
 type Config struct {
-	ServerPort string
-	Mongo      MongoConfig
-	Solr       SolrConfig
-	Memcached  MemcachedConfig
-	RabbitMQ   RabbitMQConfig
-	HTTP       HTTPConfig
-	JWT        JWTConfig
+	ServerPort  string
+	Mongo       MongoConfig
+	Solr        SolrConfig
+	Cache       CacheConfig
+	Memcached   MemcachedConfig
+	Redis       RedisConfig
+	RabbitMQ    RabbitMQConfig
+	HTTP        HTTPConfig
+	JWT         JWTConfig
+	SlowQuery   SlowQueryConfig
+	Events      EventsConfig
+	Shadow      ShadowConfig
+	Reindex     ReindexConfig
+	ReadThrough ReadThroughConfig
+	Rebuild     RebuildConfig
+	Ranking     RankingConfig
+	Badges      BadgesConfig
+	Locale      LocaleConfig
+	Expiry      ExpiryConfig
+	Health      HealthConfig
+	RateLimit   RateLimitConfig
 }
 
 type HTTPConfig struct {
@@ -34,10 +48,23 @@ type SolrConfig struct {
 	Core string
 }
 
+type CacheConfig struct {
+	// Cache backend: memcached, redis or none (every lookup is a miss)
+	Backend string
+	// Seconds an expired search result may still be served while a single request refreshes it
+	StaleWindowSeconds int
+}
+
 type MemcachedConfig struct {
 	Servers []string
 }
 
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
 type RabbitMQConfig struct {
 	URL       string
 	QueueName string
@@ -45,6 +72,110 @@ type RabbitMQConfig struct {
 
 type JWTConfig struct {
 	Secret string
+
+	// Seconds a user's session status (users-api) is reused by the admin JWT check
+	// It is the maximum delay to enforce a session revocation, deactivation or ban
+	SessionStatusCacheSeconds int
+}
+
+type SlowQueryConfig struct {
+	ThresholdMs int // Searches slower than this are recorded in the slow query log
+}
+
+type EventsConfig struct {
+	// Driver snapshots embedded in trip.created older than this are ignored and
+	// the driver is fetched from users-api instead (0 = always fetch)
+	DriverSnapshotMaxAgeSeconds int
+}
+
+type ShadowConfig struct {
+	// Percentage (0-100) of Solr-served searches also run against MongoDB to compare
+	// result sets and ordering (0 = disabled)
+	SamplePercent int
+	// Shadow searches running at once; a sampled search beyond the limit is skipped
+	MaxInFlight int
+}
+
+type ReindexConfig struct {
+	BatchSize        int // Trips sent to Solr per update request
+	BatchesPerSecond int // Max update requests per second during a reindex (0 = unlimited)
+}
+
+type ReadThroughConfig struct {
+	// When GET /trips/:id misses the index, fetch the trip from trips-api/users-api and store it
+	Enabled bool
+	// How long a trip ID that trips-api reported as nonexistent is remembered (0 = not cached)
+	NegativeTTLSeconds int
+}
+
+type RebuildConfig struct {
+	// MongoDB holding the trips-api event archive read by cmd/rebuild (empty = not configured)
+	ArchiveMongoURI string
+	ArchiveMongoDB  string
+	BatchSize       int // Archive events read and trips indexed per batch
+}
+
+type RankingConfig struct {
+	// Driver boosts applied by sort_by=relevance on top of the text score (0 disables a signal)
+	ResponsivenessWeight float64
+	CompletionRateWeight float64
+	RatingWeight         float64
+
+	// Ranking strategy used when a search does not pass ranking= (default, relevance, price_sensitive, eco,
+	// popularity, price_optimized, departure_proximity, hybrid)
+	DefaultStrategy string
+
+	// JSON file with the scorer weights (domain.ScoringWeights), re-read when it changes (empty = defaults)
+	WeightsFile          string
+	WeightsReloadSeconds int
+}
+
+type BadgesConfig struct {
+	// "price dropped": the last price change lowered the price by at least this % and is recent enough
+	PriceDropMinPercent  float64
+	PriceDropMaxAgeHours int
+
+	// "filling fast": at least this many seats booked within the window (and seats still available)
+	FillingFastMinSeats    int
+	FillingFastWindowHours int
+
+	// How often the badges of trips without new events are recomputed, so they expire (0 = disabled)
+	RefreshIntervalSeconds int
+	RefreshBatchSize       int // Trips read per MongoDB query
+}
+
+type LocaleConfig struct {
+	// JSON file with localized city/province display names (domain.LocationLocalizationFile),
+	// read once at startup on top of the built-in names (empty = built-in names only)
+	LocalizationFile string
+	// Language of the display names when Accept-Language matches none of the table
+	DefaultLanguage string
+}
+
+type ExpiryConfig struct {
+	// How often visible trips whose departure already passed are marked as expired and removed
+	// from Solr (0 = disabled; searches still exclude them by departure date)
+	IntervalSeconds int
+	BatchSize       int // Trips expired per MongoDB update / Solr delete request
+}
+
+type HealthConfig struct {
+	// GET /health reports a dependency as degraded when its ping is slower than this (0 = no threshold)
+	MongoLatencyMs int
+	SolrLatencyMs  int
+	CacheLatencyMs int
+	// Consumer lag above this degrades the service: no event processed while messages wait in the
+	// queue, or the last event processed this long after it was published (0 = no threshold)
+	EventLagSeconds int
+}
+
+type RateLimitConfig struct {
+	// Token bucket of the public /api/v1 endpoints per client IP: requests per minute and burst (0 = unlimited)
+	IPRequestsPerMinute int
+	IPBurst             int
+	// Requests with a valid JWT get their own bucket per user instead (0 = limited per IP)
+	UserRequestsPerMinute int
+	UserBurst             int
 }
 
 func LoadConfig() (*Config, error) {
@@ -63,7 +194,8 @@ func LoadConfig() (*Config, error) {
 			QueueName: getEnv("QUEUE_NAME", "search.events"),
 		},
 		JWT: JWTConfig{
-			Secret: mustGetEnv("JWT_SECRET"),
+			Secret:                    mustGetEnv("JWT_SECRET"),
+			SessionStatusCacheSeconds: getEnvInt("SESSION_STATUS_CACHE_SECONDS", 30),
 		},
 
 		// Variables NO CRÍTICAS - Con defaults razonables
@@ -72,15 +204,83 @@ func LoadConfig() (*Config, error) {
 			URL:  getEnv("SOLR_URL", "http://localhost:8983/solr"),
 			Core: getEnv("SOLR_CORE", "carpooling_trips"),
 		},
+		Cache: CacheConfig{
+			Backend:            getEnv("CACHE_BACKEND", "memcached"),
+			StaleWindowSeconds: getEnvInt("CACHE_STALE_WHILE_REVALIDATE_SECONDS", 30),
+		},
 		Memcached: MemcachedConfig{
 			Servers: getEnvSlice("MEMCACHED_SERVERS", []string{"localhost:11211"}),
 		},
+		Redis: RedisConfig{
+			Addr:     getEnv("REDIS_ADDR", "localhost:6379"),
+			Password: getEnv("REDIS_PASSWORD", ""),
+			DB:       getEnvInt("REDIS_DB", 0),
+		},
 		HTTP: HTTPConfig{
 			UsersAPIURL: getEnv("USERS_API_URL", "http://localhost:8001"),
 			TripsAPIURL: getEnv("TRIPS_API_URL", "http://localhost:8002"),
 			Timeout:     getEnvInt("HTTP_TIMEOUT", 5),     // 5 seconds default
 			MaxRetries:  getEnvInt("HTTP_MAX_RETRIES", 3), // 3 retries default
 		},
+		SlowQuery: SlowQueryConfig{
+			ThresholdMs: getEnvInt("SLOW_QUERY_THRESHOLD_MS", 500), // 500ms default
+		},
+		Events: EventsConfig{
+			DriverSnapshotMaxAgeSeconds: getEnvInt("DRIVER_SNAPSHOT_MAX_AGE_SECONDS", 300), // 5 minutes default
+		},
+		Shadow: ShadowConfig{
+			SamplePercent: getEnvInt("SHADOW_READ_SAMPLE_PERCENT", 0), // disabled by default
+			MaxInFlight:   getEnvInt("SHADOW_READ_MAX_IN_FLIGHT", 4),
+		},
+		ReadThrough: ReadThroughConfig{
+			Enabled:            getEnv("TRIP_READ_THROUGH_ENABLED", "true") == "true",
+			NegativeTTLSeconds: getEnvInt("TRIP_READ_THROUGH_NEGATIVE_TTL_SECONDS", 60),
+		},
+		Reindex: ReindexConfig{
+			BatchSize:        getEnvInt("REINDEX_BATCH_SIZE", 500),
+			BatchesPerSecond: getEnvInt("REINDEX_BATCHES_PER_SECOND", 2),
+		},
+		Rebuild: RebuildConfig{
+			ArchiveMongoURI: getEnv("TRIPS_ARCHIVE_MONGO_URI", ""),
+			ArchiveMongoDB:  getEnv("TRIPS_ARCHIVE_MONGO_DB", "carpooling_trips"),
+			BatchSize:       getEnvInt("REBUILD_BATCH_SIZE", 500),
+		},
+		Ranking: RankingConfig{
+			ResponsivenessWeight: getEnvFloat("RANKING_RESPONSIVENESS_WEIGHT", 0.3),
+			CompletionRateWeight: getEnvFloat("RANKING_COMPLETION_RATE_WEIGHT", 0.3),
+			RatingWeight:         getEnvFloat("RANKING_RATING_WEIGHT", 0.2),
+			DefaultStrategy:      getEnv("RANKING_DEFAULT_STRATEGY", "default"),
+			WeightsFile:          getEnv("RANKING_WEIGHTS_FILE", ""),
+			WeightsReloadSeconds: getEnvInt("RANKING_WEIGHTS_RELOAD_SECONDS", 30),
+		},
+		Badges: BadgesConfig{
+			PriceDropMinPercent:    getEnvFloat("BADGE_PRICE_DROP_MIN_PERCENT", 5),
+			PriceDropMaxAgeHours:   getEnvInt("BADGE_PRICE_DROP_MAX_AGE_HOURS", 72),
+			FillingFastMinSeats:    getEnvInt("BADGE_FILLING_FAST_MIN_SEATS", 2),
+			FillingFastWindowHours: getEnvInt("BADGE_FILLING_FAST_WINDOW_HOURS", 24),
+			RefreshIntervalSeconds: getEnvInt("BADGE_REFRESH_INTERVAL_SECONDS", 900), // 15 minutes default
+			RefreshBatchSize:       getEnvInt("BADGE_REFRESH_BATCH_SIZE", 500),
+		},
+		Locale: LocaleConfig{
+			LocalizationFile: getEnv("LOCATION_LOCALIZATION_FILE", ""),
+			DefaultLanguage:  getEnv("LOCATION_DEFAULT_LANGUAGE", "es"),
+		},
+		Health: HealthConfig{
+			MongoLatencyMs:  getEnvInt("HEALTH_MONGO_LATENCY_THRESHOLD_MS", 100),
+			SolrLatencyMs:   getEnvInt("HEALTH_SOLR_LATENCY_THRESHOLD_MS", 250),
+			CacheLatencyMs:  getEnvInt("HEALTH_CACHE_LATENCY_THRESHOLD_MS", 50),
+			EventLagSeconds: getEnvInt("HEALTH_EVENT_LAG_THRESHOLD_SECONDS", 120),
+		},
+		RateLimit: RateLimitConfig{
+			IPRequestsPerMinute:   getEnvInt("RATE_LIMIT_IP_REQUESTS_PER_MINUTE", 60),
+			IPBurst:               getEnvInt("RATE_LIMIT_IP_BURST", 20),
+			UserRequestsPerMinute: getEnvInt("RATE_LIMIT_USER_REQUESTS_PER_MINUTE", 120),
+			UserBurst:             getEnvInt("RATE_LIMIT_USER_BURST", 40),
+		},
+		Expiry: ExpiryConfig{
+			IntervalSeconds: getEnvInt("TRIP_EXPIRY_INTERVAL_SECONDS", 300), // 5 minutes default
+			BatchSize:       getEnvInt("TRIP_EXPIRY_BATCH_SIZE", 500),
+		},
 	}
 
 	return cfg, nil
@@ -112,6 +312,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		var result float64
+		if _, err := fmt.Sscanf(value, "%g", &result); err == nil && result >= 0 {
+			return result
+		}
+	}
+	return defaultValue
+}
+
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		// Split by comma for multiple servers
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"search-api/internal/domain"
+	"search-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminController handles internal diagnostics endpoints
+type AdminController struct {
+	searchService  service.SearchService
+	reindexer      service.Reindexer
+	weightReloader *service.ScoringWeightsReloader
+}
+
+// NewAdminController creates a new AdminController instance
+func NewAdminController(searchService service.SearchService, reindexer service.Reindexer, weightReloader *service.ScoringWeightsReloader) *AdminController {
+	return &AdminController{
+		searchService:  searchService,
+		reindexer:      reindexer,
+		weightReloader: weightReloader,
+	}
+}
+
+// GetSlowQueries handles GET /admin/slow-queries?limit=50&min_duration_ms=1000
+// Returns the most recent searches that exceeded the latency threshold,
+// including the compiled Solr/MongoDB filters, newest first
+func (ac *AdminController) GetSlowQueries(c *gin.Context) {
+	limit := parseInt(c.DefaultQuery("limit", "50"))
+
+	var minDurationMs int64
+	if minStr := c.Query("min_duration_ms"); minStr != "" {
+		val, err := strconv.ParseInt(minStr, 10, 64)
+		if err != nil || val < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "INVALID_QUERY",
+					"message": "min_duration_ms must be a non-negative integer",
+				},
+			})
+			return
+		}
+		minDurationMs = val
+	}
+
+	entries, err := ac.searchService.GetSlowQueries(c.Request.Context(), limit, minDurationMs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"slow_queries": entries,
+			"count":        len(entries),
+		},
+	})
+}
+
+// GetShadowReadStats handles GET /admin/shadow-reads
+// Returns how often Solr and MongoDB disagree for the sampled shadow-read queries
+func (ac *AdminController) GetShadowReadStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ac.searchService.GetShadowReadStats(),
+	})
+}
+
+// GetCacheStats handles GET /admin/cache
+// Returns the cache hits, stale hits, misses and misses coalesced into an in-flight search
+func (ac *AdminController) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ac.searchService.GetCacheStats(),
+	})
+}
+
+// GetIndexWarmStats handles GET /admin/index/warm-stats
+// Returns when the current Solr searcher was opened, its warm-up time and the hit ratios
+// of the Solr caches; 503 if Solr is not available
+func (ac *AdminController) GetIndexWarmStats(c *gin.Context) {
+	stats, err := ac.searchService.GetIndexWarmStats(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// StartReindex handles POST /admin/reindex
+// Starts rebuilding the Solr index from MongoDB in the background (202 Accepted);
+// 409 if a reindex is already running, 503 if Solr is not available
+func (ac *AdminController) StartReindex(c *gin.Context) {
+	status, err := ac.reindexer.Start()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    reindexStatusResponse(status),
+	})
+}
+
+// GetReindexStatus handles GET /admin/reindex/status
+// Returns the progress of the running reindex, or the result of the last one
+func (ac *AdminController) GetReindexStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reindexStatusResponse(ac.reindexer.Status()),
+	})
+}
+
+// GetRankingWeights handles GET /admin/ranking/weights
+// Returns the scorer weights in use by this instance and the file they were loaded from
+func (ac *AdminController) GetRankingWeights(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ac.weightReloader.Status(),
+	})
+}
+
+// reindexStatusView is the reindex status with its derived progress fields
+type reindexStatusView struct {
+	domain.ReindexStatus
+	Processed       int64   `json:"processed"`
+	ProgressPercent float64 `json:"progress_percent"`
+}
+
+func reindexStatusResponse(status domain.ReindexStatus) reindexStatusView {
+	return reindexStatusView{
+		ReindexStatus:   status,
+		Processed:       status.Processed(),
+		ProgressPercent: status.ProgressPercent(),
+	}
+}
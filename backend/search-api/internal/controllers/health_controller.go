@@ -2,33 +2,45 @@ package controllers
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"time"
 
-	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
+	"search-api/internal/cache"
 	"search-api/internal/clients"
 	"search-api/internal/config"
+	"search-api/internal/domain"
+	"search-api/internal/service"
 )
 
+// ConsumerStatsProvider reports the progress of the event consumer (messaging.Consumer)
+type ConsumerStatsProvider interface {
+	Stats(ctx context.Context) domain.ConsumerStats
+}
+
 // HealthController handles health check endpoints
 type HealthController struct {
-	mongoClient     *mongo.Client
-	solrClient      *clients.SolrClient
-	memcachedClient *memcache.Client
-	logger          zerolog.Logger
-	config          *config.Config
+	mongoClient *mongo.Client
+	solrClient  *clients.SolrClient
+	cache       cache.Cache
+	index       service.IndexMetadataService
+	consumer    ConsumerStatsProvider
+	logger      zerolog.Logger
+	config      *config.Config
 }
 
 // ServiceHealthStatus represents the health status of a single service
 type ServiceHealthStatus struct {
-	Status  string `json:"status"`  // "healthy" or "unhealthy"
-	Message string `json:"message"` // Connection status or error message
+	Status      string   `json:"status"`                 // "healthy", "degraded" (slow ping), "unhealthy" or "disabled"
+	Message     string   `json:"message"`                // Connection status or error message
+	LatencyMs   *float64 `json:"latency_ms,omitempty"`   // Measured ping duration (omitted when not pinged)
+	ThresholdMs int      `json:"threshold_ms,omitempty"` // Above it the service is degraded
 }
 
 // HealthCheckResponse represents the complete health check response
@@ -37,21 +49,27 @@ type HealthCheckResponse struct {
 	Service  string                         `json:"service"` // "search-api"
 	Port     string                         `json:"port"`    // "8004"
 	Services map[string]ServiceHealthStatus `json:"services"`
+	Index    *domain.IndexStatus            `json:"index,omitempty"`    // Omitted when the metadata cannot be read
+	Consumer *domain.ConsumerLag            `json:"consumer,omitempty"` // Event consumption lag
 }
 
 // NewHealthController creates a new health controller instance
 func NewHealthController(
 	mongoClient *mongo.Client,
 	solrClient *clients.SolrClient,
-	memcachedClient *memcache.Client,
+	cacheService cache.Cache,
+	indexService service.IndexMetadataService,
+	consumer ConsumerStatsProvider,
 	cfg *config.Config,
 ) *HealthController {
 	return &HealthController{
-		mongoClient:     mongoClient,
-		solrClient:      solrClient,
-		memcachedClient: memcachedClient,
-		logger:          log.Logger,
-		config:          cfg,
+		mongoClient: mongoClient,
+		solrClient:  solrClient,
+		cache:       cacheService,
+		index:       indexService,
+		consumer:    consumer,
+		logger:      log.Logger,
+		config:      cfg,
 	}
 }
 
@@ -73,25 +91,54 @@ func (hc *HealthController) HealthCheck(c *gin.Context) {
 	// Check MongoDB health
 	mongoStatus := hc.checkMongoHealth(ctx)
 	response.Services["mongodb"] = mongoStatus
-	if mongoStatus.Status == "unhealthy" {
+	if !isHealthy(mongoStatus.Status) {
 		allHealthy = false
-		hc.logger.Warn().Msg("MongoDB health check failed")
+		hc.logger.Warn().Str("status", mongoStatus.Status).Msg("MongoDB health check failed")
 	}
 
 	// Check Apache Solr health
 	solrStatus := hc.checkSolrHealth(ctx)
 	response.Services["solr"] = solrStatus
-	if solrStatus.Status == "unhealthy" {
+	if !isHealthy(solrStatus.Status) {
 		allHealthy = false
-		hc.logger.Warn().Msg("Apache Solr health check failed")
+		hc.logger.Warn().Str("status", solrStatus.Status).Msg("Apache Solr health check failed")
 	}
 
-	// Check Memcached health
-	memcachedStatus := hc.checkMemcachedHealth(ctx)
-	response.Services["memcached"] = memcachedStatus
-	if memcachedStatus.Status == "unhealthy" {
+	// Check cache health (reported under the backend name: memcached, redis or none)
+	cacheStatus := hc.checkCacheHealth(ctx)
+	response.Services[hc.config.Cache.Backend] = cacheStatus
+	if !isHealthy(cacheStatus.Status) {
 		allHealthy = false
-		hc.logger.Warn().Msg("Memcached health check failed")
+		hc.logger.Warn().Str("backend", hc.config.Cache.Backend).Str("status", cacheStatus.Status).Msg("Cache health check failed")
+	}
+
+	// Index schema version and last full reindex; an outdated index is not consuming events
+	indexStatus, err := hc.index.Status(ctx)
+	if err != nil {
+		hc.logger.Warn().Err(err).Msg("Failed to read index metadata")
+	} else {
+		response.Index = &indexStatus
+		if !indexStatus.UpToDate || !indexStatus.ConsumingEvents {
+			allHealthy = false
+			hc.logger.Warn().
+				Int("schema_version", indexStatus.SchemaVersion).
+				Int("expected_schema_version", indexStatus.ExpectedSchemaVersion).
+				Bool("consuming_events", indexStatus.ConsumingEvents).
+				Msg("Search index schema outdated or events not consumed")
+		}
+	}
+
+	// Event lag: a stalled or late consumer leaves the index behind trips-api
+	if hc.consumer != nil {
+		lag := domain.NewConsumerLag(hc.consumer.Stats(ctx), time.Now(), time.Duration(hc.config.Health.EventLagSeconds)*time.Second)
+		response.Consumer = &lag
+		if lag.Status != domain.HealthHealthy {
+			allHealthy = false
+			hc.logger.Warn().
+				Str("status", lag.Status).
+				Float64("lag_seconds", lag.LagSeconds).
+				Msg(lag.Message)
+		}
 	}
 
 	// Set overall status
@@ -108,44 +155,44 @@ func (hc *HealthController) HealthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// checkMongoHealth tests MongoDB connectivity
+// checkMongoHealth tests MongoDB connectivity and measures the ping latency
 func (hc *HealthController) checkMongoHealth(ctx context.Context) ServiceHealthStatus {
 	if hc.mongoClient == nil {
 		return ServiceHealthStatus{
-			Status:  "unhealthy",
+			Status:  domain.HealthUnhealthy,
 			Message: "MongoDB client not initialized",
 		}
 	}
 
 	// Ping MongoDB with timeout
+	start := time.Now()
 	err := hc.mongoClient.Ping(ctx, readpref.Primary())
+	latency := time.Since(start)
 	if err != nil {
 		hc.logger.Error().
 			Err(err).
 			Msg("MongoDB ping failed")
 
 		return ServiceHealthStatus{
-			Status:  "unhealthy",
+			Status:  domain.HealthUnhealthy,
 			Message: "Failed to ping MongoDB: " + err.Error(),
 		}
 	}
 
-	return ServiceHealthStatus{
-		Status:  "healthy",
-		Message: "Connected",
-	}
+	return pingedStatus(latency, hc.config.Health.MongoLatencyMs)
 }
 
-// checkSolrHealth tests Apache Solr connectivity
+// checkSolrHealth tests Apache Solr connectivity and measures the ping latency
 func (hc *HealthController) checkSolrHealth(ctx context.Context) ServiceHealthStatus {
 	if hc.solrClient == nil {
 		return ServiceHealthStatus{
-			Status:  "unhealthy",
+			Status:  domain.HealthUnhealthy,
 			Message: "Solr client not initialized",
 		}
 	}
 
 	// Execute ping with timeout handling
+	start := time.Now()
 	done := make(chan error, 1)
 	go func() {
 		err := hc.solrClient.Ping(ctx)
@@ -154,76 +201,94 @@ func (hc *HealthController) checkSolrHealth(ctx context.Context) ServiceHealthSt
 
 	select {
 	case err := <-done:
+		latency := time.Since(start)
 		if err != nil {
 			hc.logger.Error().
 				Err(err).
 				Msg("Solr ping failed")
 
 			return ServiceHealthStatus{
-				Status:  "unhealthy",
+				Status:  domain.HealthUnhealthy,
 				Message: "Failed to connect to Solr: " + err.Error(),
 			}
 		}
 
-		return ServiceHealthStatus{
-			Status:  "healthy",
-			Message: "Connected",
-		}
+		return pingedStatus(latency, hc.config.Health.SolrLatencyMs)
 
 	case <-ctx.Done():
 		return ServiceHealthStatus{
-			Status:  "unhealthy",
+			Status:  domain.HealthUnhealthy,
 			Message: "Solr health check timeout",
 		}
 	}
 }
 
-// checkMemcachedHealth tests Memcached connectivity
-func (hc *HealthController) checkMemcachedHealth(ctx context.Context) ServiceHealthStatus {
-	if hc.memcachedClient == nil {
+// checkCacheHealth tests connectivity with the cache server and measures the ping latency
+// With CACHE_BACKEND=none there is nothing to check; a backend that fell back to
+// the no-op cache at startup has no server to ping and is reported unhealthy
+func (hc *HealthController) checkCacheHealth(ctx context.Context) ServiceHealthStatus {
+	if hc.config.Cache.Backend == cache.BackendNone {
 		return ServiceHealthStatus{
-			Status:  "unhealthy",
-			Message: "Memcached client not initialized",
+			Status:  domain.HealthDisabled,
+			Message: "Cache disabled (CACHE_BACKEND=none)",
 		}
 	}
 
-	// Try to get a test key (it's OK if it doesn't exist)
-	// We're just testing connectivity, not the actual value
-	testKey := "health_check_ping"
+	pinger, ok := hc.cache.(cache.Pinger)
+	if !ok {
+		return ServiceHealthStatus{
+			Status:  domain.HealthUnhealthy,
+			Message: "Cache not connected (running without cache)",
+		}
+	}
 
+	start := time.Now()
 	done := make(chan error, 1)
 	go func() {
-		_, err := hc.memcachedClient.Get(testKey)
-		// ErrCacheMiss is actually a good sign - it means we connected successfully
-		if err == memcache.ErrCacheMiss {
-			done <- nil
-		} else {
-			done <- err
-		}
+		done <- pinger.Ping(ctx)
 	}()
 
 	select {
 	case err := <-done:
+		latency := time.Since(start)
 		if err != nil {
 			hc.logger.Error().
 				Err(err).
-				Msg("Memcached connection failed")
+				Str("backend", hc.config.Cache.Backend).
+				Msg("Cache connection failed")
 
 			return ServiceHealthStatus{
-				Status:  "unhealthy",
-				Message: "Failed to connect to Memcached: " + err.Error(),
+				Status:  domain.HealthUnhealthy,
+				Message: "Failed to connect to " + hc.config.Cache.Backend + ": " + err.Error(),
 			}
 		}
 
-		return ServiceHealthStatus{
-			Status:  "healthy",
-			Message: "Connected",
-		}
+		return pingedStatus(latency, hc.config.Health.CacheLatencyMs)
 
 	case <-ctx.Done():
 		return ServiceHealthStatus{
-			Status:  "unhealthy",
-			Message: "Memcached health check timeout",
+			Status:  domain.HealthUnhealthy,
+			Message: "Cache health check timeout",
 		}
 	}
 }
+
+// pingedStatus reports a successful ping with its latency; slower than thresholdMs (0 = none) is degraded
+func pingedStatus(latency time.Duration, thresholdMs int) ServiceHealthStatus {
+	latencyMs := math.Round(float64(latency.Microseconds())/10) / 100 // Milliseconds with 2 decimals
+	status := ServiceHealthStatus{
+		Status:      domain.LatencyHealth(latency, time.Duration(thresholdMs)*time.Millisecond),
+		Message:     "Connected",
+		LatencyMs:   &latencyMs,
+		ThresholdMs: thresholdMs,
+	}
+	if status.Status == domain.HealthDegraded {
+		status.Message = "Connected, slow ping"
+	}
+	return status
+}
+
+// isHealthy reports whether a dependency status keeps the service "ok" (disabled dependencies do)
+func isHealthy(status string) bool {
+	return status == domain.HealthHealthy || status == domain.HealthDisabled
+}
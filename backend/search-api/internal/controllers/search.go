@@ -14,22 +14,48 @@ import (
 // SearchController handles all search-related endpoints
 type SearchController struct {
 	searchService service.SearchService
+	localizations *domain.LocationLocalizations
 }
 
 // NewSearchController creates a new SearchController instance
-func NewSearchController(searchService service.SearchService) *SearchController {
+// localizations may be nil, which uses the built-in display names only
+func NewSearchController(searchService service.SearchService, localizations *domain.LocationLocalizations) *SearchController {
+	if localizations == nil {
+		localizations = domain.NewLocationLocalizations("", nil)
+	}
 	return &SearchController{
 		searchService: searchService,
+		localizations: localizations,
 	}
 }
 
 // SearchTrips handles GET /api/v1/search/trips
+// City/province filters match canonical names; each location carries a display block in the
+// language negotiated with Accept-Language
 func (sc *SearchController) SearchTrips(c *gin.Context) {
 	// Build query from query parameters
 	query := &domain.SearchQuery{
 		SearchText: c.Query("q"),
 		SortBy:     c.DefaultQuery("sort_by", "earliest"),
 		SortOrder:  c.DefaultQuery("sort_order", "asc"),
+		Ranking:    c.Query("ranking"), // Empty = configured default ranking
+	}
+
+	// Parse compound sort (sort=price:asc,departure_time); it replaces sort_by/sort_order
+	if sortStr := c.Query("sort"); sortStr != "" {
+		sort, err := domain.ParseSort(sortStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "INVALID_QUERY",
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+		query.Sort = sort
+		query.SortBy, query.SortOrder = "", ""
 	}
 
 	// Parse Origin Location
@@ -67,6 +93,11 @@ func (sc *SearchController) SearchTrips(c *gin.Context) {
 			query.MinSeats = val
 		}
 	}
+	if minPrice := c.Query("min_price"); minPrice != "" {
+		if val, err := strconv.ParseFloat(minPrice, 64); err == nil {
+			query.MinPrice = val
+		}
+	}
 	if maxPrice := c.Query("max_price"); maxPrice != "" {
 		if val, err := strconv.ParseFloat(maxPrice, 64); err == nil {
 			query.MaxPrice = val
@@ -82,11 +113,17 @@ func (sc *SearchController) SearchTrips(c *gin.Context) {
 	query.PetsAllowed = parseBoolPtr(c, "pets_allowed")
 	query.SmokingAllowed = parseBoolPtr(c, "smoking_allowed")
 	query.MusicAllowed = parseBoolPtr(c, "music_allowed")
+	query.Bookable = parseBoolPtr(c, "bookable")
+	query.IncludePast = c.Query("include_past") == "true"
 
 	// Parse pagination
 	query.Page = parseInt(c.DefaultQuery("page", "1"))
 	query.Limit = parseInt(c.DefaultQuery("limit", "20"))
 
+	// Optional filter counts for the frontend (facets=true)
+	query.Facets = c.Query("facets") == "true"
+	query.PriceHistogram = c.Query("price_histogram") == "true"
+
 	// Set defaults and validate
 	query.SetDefaults()
 	if err := query.Validate(); err != nil {
@@ -108,15 +145,24 @@ func (sc *SearchController) SearchTrips(c *gin.Context) {
 	}
 
 	// Return response
+	language := sc.displayLanguage(c)
+	data := gin.H{
+		"trips":       sc.localizeTrips(results.Trips, language),
+		"total":       results.Total,
+		"page":        results.Page,
+		"limit":       results.Limit,
+		"total_pages": results.TotalPages,
+	}
+	if results.Facets != nil {
+		data["facets"] = sc.localizeFacets(results.Facets, language)
+	}
+	if results.PriceHistogram != nil {
+		data["price_histogram"] = results.PriceHistogram
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": gin.H{
-			"trips":       results.Trips,
-			"total":       results.Total,
-			"page":        results.Page,
-			"limit":       results.Limit,
-			"total_pages": results.TotalPages,
-		},
+		"data":    data,
 	})
 }
 
@@ -165,7 +211,7 @@ func (sc *SearchController) GetTrip(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"trip": trip,
+			"trip": sc.localizeTrip(trip, sc.displayLanguage(c)),
 		},
 	})
 }
@@ -219,23 +265,85 @@ func (sc *SearchController) GetPopularRoutes(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
-			"routes": routes,
+			"routes": sc.localizeRoutes(routes, sc.displayLanguage(c)),
 		},
 	})
 }
 
 // Helper functions
 
+// displayLanguage negotiates the language of the display names and announces it in the response headers
+func (sc *SearchController) displayLanguage(c *gin.Context) string {
+	language := sc.localizations.NegotiateLanguage(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", language)
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return language
+}
+
+// localizeTrips returns the trips with the display block of their origin and destination
+func (sc *SearchController) localizeTrips(trips []*domain.SearchTrip, language string) []*domain.SearchTrip {
+	localized := make([]*domain.SearchTrip, 0, len(trips))
+	for _, trip := range trips {
+		localized = append(localized, sc.localizeTrip(trip, language))
+	}
+	return localized
+}
+
+// localizeTrip returns a copy of trip with the display block of its origin and destination
+// Results are copied, never modified: a coalesced search shares them with requests in other languages
+func (sc *SearchController) localizeTrip(trip *domain.SearchTrip, language string) *domain.SearchTrip {
+	if trip == nil {
+		return nil
+	}
+	localized := *trip
+	localized.Origin.Display = sc.localizations.Display(trip.Origin, language)
+	localized.Destination.Display = sc.localizations.Display(trip.Destination, language)
+	return &localized
+}
+
+// localizeFacets returns a copy of facets with the display name of each destination city
+// Value stays canonical, so the frontend can send it back as a filter
+func (sc *SearchController) localizeFacets(facets *domain.SearchFacets, language string) *domain.SearchFacets {
+	localized := *facets
+	localized.DestinationCities = make([]domain.FacetCount, len(facets.DestinationCities))
+	for i, city := range facets.DestinationCities {
+		if display := sc.localizations.CityName(city.Value, language); display != city.Value {
+			city.Display = display
+		}
+		localized.DestinationCities[i] = city
+	}
+	return &localized
+}
+
+// localizeRoutes returns copies of the popular routes with the display names of their cities
+func (sc *SearchController) localizeRoutes(routes []*domain.PopularRoute, language string) []*domain.PopularRoute {
+	localized := make([]*domain.PopularRoute, 0, len(routes))
+	for _, route := range routes {
+		copied := *route
+		copied.Display = &domain.RouteDisplay{
+			OriginCity:      sc.localizations.CityName(route.OriginCity, language),
+			DestinationCity: sc.localizations.CityName(route.DestinationCity, language),
+			Language:        language,
+		}
+		localized = append(localized, &copied)
+	}
+	return localized
+}
+
 // parseLocation parses a Location from query parameters
 // Supports formats (with backward compatibility):
 // - NEW: ?origin_city=Córdoba&origin_province=Córdoba
 // - NEW: ?origin_city=Córdoba&origin_province=Córdoba&origin_lat=-31.4&origin_lng=-64.2
+// - NEW: ?origin_city_id=ar-cordoba&origin_city=Córdoba (city name only matches trips indexed without ID)
 // - OLD (deprecated): ?originCity=Córdoba&originProvince=Córdoba&originLat=-31.4&originLng=-64.2
 func parseLocation(c *gin.Context, prefix string) *domain.Location {
 	// Parse city and province - try new format first (snake_case)
 	city := c.Query(prefix + "_city")
 	province := c.Query(prefix + "_province")
 
+	// Canonical city from the trips-api catalog; matched before the name when present
+	cityID := domain.NormalizeCityID(c.Query(prefix + "_city_id"))
+
 	// Fallback to old format (camelCase) for backward compatibility
 	if city == "" {
 		city = c.Query(prefix + "City")
@@ -270,14 +378,15 @@ func parseLocation(c *gin.Context, prefix string) *domain.Location {
 		}
 	}
 
-	// Return nil only if BOTH city and coordinates are missing
-	if city == "" && !hasCoordinates {
+	// Return nil only if city (name or ID) and coordinates are all missing
+	if city == "" && cityID == "" && !hasCoordinates {
 		return nil
 	}
 
 	// Create location with available data
 	location := &domain.Location{
 		City:        city,
+		CityID:      cityID,
 		Province:    province,
 		Address:     "", // Not used in search
 		Coordinates: domain.GeoJSONPoint{Type: "Point", Coordinates: []float64{}}, // Empty by default
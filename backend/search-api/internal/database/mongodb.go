@@ -6,7 +6,10 @@ import (
 	"log"
 	"time"
 
+	"search-api/internal/metrics"
+
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -16,7 +19,8 @@ func ConnectMongoDB(uri, dbName string) (*mongo.Database, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	// The monitor records the duration of every command in the Prometheus metrics
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(metrics.MongoMonitor()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -30,6 +34,69 @@ func ConnectMongoDB(uri, dbName string) (*mongo.Database, error) {
 	return client.Database(dbName), nil
 }
 
+// tripIDIndexName is the name of the trip_id index (the driver's default name, kept from the non-unique version)
+const tripIDIndexName = "trip_id_1"
+
+// ensureUniqueTripID makes trip_id unique in the trips collection
+//
+// Indexes created before it was unique let concurrent writers (read-through and trip.created) insert
+// the same trip twice. On upgrade the duplicates are removed, keeping the most recently updated copy
+// (every copy is a denormalization of the same trip in trips-api), and the old non-unique index is
+// replaced, since MongoDB does not allow two indexes on the same key
+func ensureUniqueTripID(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$trip_id",
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return fmt.Errorf("failed to find duplicated trip_id: %w", err)
+	}
+	var duplicates []struct {
+		TripID string               `bson:"_id"`
+		IDs    []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return fmt.Errorf("failed to decode duplicated trip_id: %w", err)
+	}
+	for _, duplicate := range duplicates {
+		// The first ID is the most recently updated copy
+		result, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": duplicate.IDs[1:]}})
+		if err != nil {
+			return fmt.Errorf("failed to remove duplicates of trip %s: %w", duplicate.TripID, err)
+		}
+		log.Printf("⚠️  Removed %d duplicated documents of trip %s", result.DeletedCount, duplicate.TripID)
+	}
+
+	specs, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list trips indexes: %w", err)
+	}
+	for _, spec := range specs {
+		if spec.Name == tripIDIndexName && (spec.Unique == nil || !*spec.Unique) {
+			if _, err := collection.Indexes().DropOne(ctx, tripIDIndexName); err != nil {
+				return fmt.Errorf("failed to drop non-unique trip_id index: %w", err)
+			}
+		}
+	}
+
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "trip_id", Value: 1}},
+		Options: options.Index().SetName(tripIDIndexName).SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create unique trip_id index: %w", err)
+	}
+	return nil
+}
+
+// slowQueriesCollectionSize is the maximum size of the slow query log (10 MB)
+const slowQueriesCollectionSize = 10 * 1024 * 1024
+
 // CreateIndexes creates all required indexes for the search-api collections
 func CreateIndexes(db *mongo.Database) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -38,6 +105,11 @@ func CreateIndexes(db *mongo.Database) error {
 	// ==================== TRIPS COLLECTION INDEXES ====================
 	tripsCollection := db.Collection("trips")
 
+	// trip_id lookups (events and hydrating Solr results with $in), unique: see ensureUniqueTripID
+	if err := ensureUniqueTripID(ctx, tripsCollection); err != nil {
+		return err
+	}
+
 	tripIndexes := []mongo.IndexModel{
 		// Compound index for status and departure time filtering
 		{
@@ -46,6 +118,13 @@ func CreateIndexes(db *mongo.Database) error {
 				{Key: "departure_datetime", Value: 1},
 			},
 		},
+		// Default searches only return bookable trips, ordered by departure
+		{
+			Keys: bson.D{
+				{Key: "bookable", Value: 1},
+				{Key: "departure_datetime", Value: 1},
+			},
+		},
 		// Compound index for city-to-city route searches
 		{
 			Keys: bson.D{
@@ -53,6 +132,26 @@ func CreateIndexes(db *mongo.Database) error {
 				{Key: "destination.city", Value: 1},
 			},
 		},
+		// Same route index with the case/accent-insensitive collation used by searches
+		{
+			Keys: bson.D{
+				{Key: "origin.city", Value: 1},
+				{Key: "destination.city", Value: 1},
+			},
+			Options: options.Index().
+				SetName("route_search_ci").
+				SetCollation(&options.Collation{Locale: "es", Strength: 1}),
+		},
+		// Route searches by canonical city ID (trips-api city catalog), same collation as the searches
+		{
+			Keys: bson.D{
+				{Key: "origin.city_id", Value: 1},
+				{Key: "destination.city_id", Value: 1},
+			},
+			Options: options.Index().
+				SetName("route_city_id_search_ci").
+				SetCollation(&options.Collation{Locale: "es", Strength: 1}),
+		},
 		// 2dsphere index for geospatial queries on origin coordinates
 		{
 			Keys: bson.D{
@@ -65,9 +164,49 @@ func CreateIndexes(db *mongo.Database) error {
 				{Key: "destination.coordinates", Value: "2dsphere"},
 			},
 		},
+		// 2dsphere index on the multi-valued origin + pickup points field (origin radius searches)
+		{
+			Keys: bson.D{
+				{Key: "pickup_locations", Value: "2dsphere"},
+			},
+		},
+		// Driver trip outcomes (completion rate computed when a driver publishes a trip)
+		{
+			Keys: bson.D{
+				{Key: "driver_id", Value: 1},
+				{Key: "status", Value: 1},
+			},
+		},
+	}
+
+	// Backfill pickup_locations for documents indexed before pickup points existed,
+	// otherwise they would stop matching origin radius searches
+	_, err := tripsCollection.UpdateMany(ctx,
+		bson.M{"pickup_locations": bson.M{"$exists": false}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"pickup_locations": bson.A{"$origin.coordinates"}}}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to backfill trips pickup_locations: %w", err)
+	}
+
+	// Backfill bookable for documents indexed before the flag existed (same rule as domain.IsBookable),
+	// otherwise they would disappear from default searches
+	_, err = tripsCollection.UpdateMany(ctx,
+		bson.M{"bookable": bson.M{"$exists": false}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"bookable": bson.M{"$and": bson.A{
+				bson.M{"$eq": bson.A{"$status", "published"}},
+				bson.M{"$gt": bson.A{"$available_seats", 0}},
+			}}}}},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to backfill trips bookable: %w", err)
 	}
 
-	_, err := tripsCollection.Indexes().CreateMany(ctx, tripIndexes)
+	_, err = tripsCollection.Indexes().CreateMany(ctx, tripIndexes)
 	if err != nil {
 		return fmt.Errorf("failed to create trips indexes: %w", err)
 	}
@@ -118,6 +257,31 @@ func CreateIndexes(db *mongo.Database) error {
 	}
 	log.Println("✅ Popular routes collection indexes created successfully")
 
+	// ==================== SLOW_QUERIES CAPPED COLLECTION ====================
+	// Capped collection: fixed size, insertion order, oldest entries evicted automatically
+	if err := ensureCappedCollection(ctx, db, "slow_queries", slowQueriesCollectionSize); err != nil {
+		return fmt.Errorf("failed to create slow_queries collection: %w", err)
+	}
+	log.Println("✅ Slow queries capped collection ready")
+
 	log.Println("✅ All MongoDB indexes created successfully")
 	return nil
 }
+
+// ensureCappedCollection creates a capped collection if it does not exist yet
+// An existing collection is left untouched (it may have been created with a different size)
+func ensureCappedCollection(ctx context.Context, db *mongo.Database, name string, sizeBytes int64) error {
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": name})
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		return nil
+	}
+
+	opts := options.CreateCollection().
+		SetCapped(true).
+		SetSizeInBytes(sizeBytes)
+
+	return db.CreateCollection(ctx, name, opts)
+}
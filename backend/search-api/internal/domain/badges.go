@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// TripBadges are the highlight flags returned with a trip ("price dropped", "filling fast")
+// They are computed when the trip is indexed, recomputed on every trip event and by the badge refresh
+// job (so they expire on trips without activity), never per query
+type TripBadges struct {
+	PriceDropped        bool    `json:"price_dropped" bson:"price_dropped"`
+	PriceDropPercent    float64 `json:"price_drop_percent,omitempty" bson:"price_drop_percent,omitempty"` // Drop of the last price change, in %
+	FillingFast         bool    `json:"filling_fast" bson:"filling_fast"`
+	SeatsBookedRecently int     `json:"seats_booked_recently,omitempty" bson:"seats_booked_recently,omitempty"` // Seats booked within the velocity window
+}
+
+// SeatBookingSample is a number of seats booked at a given moment, derived from the increase of
+// reserved_seats between trip.updated events (seat holds and total_seats edits do not change it)
+type SeatBookingSample struct {
+	Seats int       `bson:"seats"`
+	At    time.Time `bson:"at"`
+}
+
+// BadgeThresholds configures when a trip gets each badge
+type BadgeThresholds struct {
+	PriceDropMinPercent float64       // Minimum drop of the last price change (0-100)
+	PriceDropMaxAge     time.Duration // The badge is dropped once the price change is older than this
+	FillingFastMinSeats int           // Seats booked within FillingFastWindow needed for the badge
+	FillingFastWindow   time.Duration // Window of the seat velocity
+}
+
+// DefaultBadgeThresholds are used when no thresholds are configured
+var DefaultBadgeThresholds = BadgeThresholds{
+	PriceDropMinPercent: 5,
+	PriceDropMaxAge:     72 * time.Hour,
+	FillingFastMinSeats: 2,
+	FillingFastWindow:   24 * time.Hour,
+}
+
+// TripPriceUpdate is the price carried by a trip.updated event
+// PreviousPricePerSeat and ChangedAt are nil when the price never changed
+type TripPriceUpdate struct {
+	PricePerSeat         float64
+	PreviousPricePerSeat *float64
+	ChangedAt            *time.Time
+}
+
+// ApplyPrice stores the price of a trip.updated event and the price before its last change
+func (t *SearchTrip) ApplyPrice(price TripPriceUpdate) {
+	t.PricePerSeat = price.PricePerSeat
+	t.PreviousPricePerSeat = price.PreviousPricePerSeat
+	t.PriceChangedAt = price.ChangedAt
+}
+
+// RecordSeatsBooked adds a seat velocity sample and discards the ones older than window
+func (t *SearchTrip) RecordSeatsBooked(seats int, at time.Time, window time.Duration) {
+	if seats > 0 {
+		t.RecentBookings = append(t.RecentBookings, SeatBookingSample{Seats: seats, At: at})
+	}
+
+	kept := t.RecentBookings[:0]
+	for _, sample := range t.RecentBookings {
+		if at.Sub(sample.At) <= window {
+			kept = append(kept, sample)
+		}
+	}
+	t.RecentBookings = kept
+}
+
+// HasAny reports whether the trip shows at least one badge
+func (b TripBadges) HasAny() bool {
+	return b.PriceDropped || b.FillingFast
+}
+
+// RefreshBadges recomputes the badges of the trip at now
+func (t *SearchTrip) RefreshBadges(thresholds BadgeThresholds, now time.Time) {
+	var badges TripBadges
+
+	if t.PreviousPricePerSeat != nil && t.PriceChangedAt != nil && *t.PreviousPricePerSeat > t.PricePerSeat &&
+		now.Sub(*t.PriceChangedAt) <= thresholds.PriceDropMaxAge {
+		previous := *t.PreviousPricePerSeat
+		percent := math.Round((previous-t.PricePerSeat)/previous*1000) / 10
+		if percent >= thresholds.PriceDropMinPercent {
+			badges.PriceDropped = true
+			badges.PriceDropPercent = percent
+		}
+	}
+
+	for _, sample := range t.RecentBookings {
+		if now.Sub(sample.At) <= thresholds.FillingFastWindow {
+			badges.SeatsBookedRecently += sample.Seats
+		}
+	}
+	// A full trip is not "filling fast" anymore: nothing is left to book
+	badges.FillingFast = thresholds.FillingFastMinSeats > 0 &&
+		badges.SeatsBookedRecently >= thresholds.FillingFastMinSeats &&
+		t.AvailableSeats > 0 && t.Status == "published"
+
+	t.Badges = badges
+}
+
+// ExpireBadges drops the seat velocity samples outside the window and recomputes the badges at now,
+// for trips without new events; it reports whether the badges changed
+func (t *SearchTrip) ExpireBadges(thresholds BadgeThresholds, now time.Time) bool {
+	before := t.Badges
+	t.RecordSeatsBooked(0, now, thresholds.FillingFastWindow)
+	t.RefreshBadges(thresholds, now)
+	return t.Badges != before
+}
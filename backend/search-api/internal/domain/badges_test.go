@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshBadges_PriceDropped(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-time.Hour)
+	previous := 5000.0
+	trip := &SearchTrip{Status: "published", AvailableSeats: 3}
+	trip.ApplyPrice(TripPriceUpdate{PricePerSeat: 4500, PreviousPricePerSeat: &previous, ChangedAt: &changedAt})
+
+	trip.RefreshBadges(DefaultBadgeThresholds, now)
+	assert.True(t, trip.Badges.PriceDropped)
+	assert.Equal(t, 10.0, trip.Badges.PriceDropPercent)
+
+	// Below the minimum drop
+	trip.RefreshBadges(BadgeThresholds{PriceDropMinPercent: 15, PriceDropMaxAge: 72 * time.Hour}, now)
+	assert.False(t, trip.Badges.PriceDropped)
+	assert.Zero(t, trip.Badges.PriceDropPercent)
+
+	// The change is too old
+	trip.RefreshBadges(DefaultBadgeThresholds, now.Add(DefaultBadgeThresholds.PriceDropMaxAge))
+	assert.False(t, trip.Badges.PriceDropped)
+
+	// A price increase is never a drop
+	higher := 4000.0
+	trip.ApplyPrice(TripPriceUpdate{PricePerSeat: 4500, PreviousPricePerSeat: &higher, ChangedAt: &changedAt})
+	trip.RefreshBadges(DefaultBadgeThresholds, now)
+	assert.False(t, trip.Badges.PriceDropped)
+
+	// Price never changed
+	trip.ApplyPrice(TripPriceUpdate{PricePerSeat: 4500})
+	trip.RefreshBadges(DefaultBadgeThresholds, now)
+	assert.False(t, trip.Badges.PriceDropped)
+}
+
+func TestRefreshBadges_FillingFast(t *testing.T) {
+	now := time.Now()
+	window := DefaultBadgeThresholds.FillingFastWindow
+	trip := &SearchTrip{Status: "published", AvailableSeats: 2}
+
+	trip.RecordSeatsBooked(1, now.Add(-2*time.Hour), window)
+	trip.RefreshBadges(DefaultBadgeThresholds, now)
+	assert.False(t, trip.Badges.FillingFast)
+	assert.Equal(t, 1, trip.Badges.SeatsBookedRecently)
+
+	trip.RecordSeatsBooked(1, now.Add(-time.Hour), window)
+	trip.RefreshBadges(DefaultBadgeThresholds, now)
+	assert.True(t, trip.Badges.FillingFast)
+	assert.Equal(t, 2, trip.Badges.SeatsBookedRecently)
+
+	// Full trips have nothing left to book
+	trip.AvailableSeats = 0
+	trip.RefreshBadges(DefaultBadgeThresholds, now)
+	assert.False(t, trip.Badges.FillingFast)
+
+	// Samples outside the window stop counting
+	trip.AvailableSeats = 2
+	trip.RefreshBadges(DefaultBadgeThresholds, now.Add(window-90*time.Minute))
+	assert.False(t, trip.Badges.FillingFast)
+	assert.Equal(t, 1, trip.Badges.SeatsBookedRecently)
+}
+
+func TestRecordSeatsBooked_PrunesOldSamplesAndIgnoresReleases(t *testing.T) {
+	now := time.Now()
+	trip := &SearchTrip{}
+
+	trip.RecordSeatsBooked(2, now.Add(-30*time.Hour), 24*time.Hour)
+	trip.RecordSeatsBooked(-1, now, 24*time.Hour)
+	assert.Empty(t, trip.RecentBookings, "releases add no sample and the old sample is pruned")
+
+	trip.RecordSeatsBooked(1, now, 24*time.Hour)
+	assert.Equal(t, []SeatBookingSample{{Seats: 1, At: now}}, trip.RecentBookings)
+}
+
+func TestExpireBadges_ClearsBadgesWithoutEvents(t *testing.T) {
+	now := time.Now()
+	changedAt := now.Add(-time.Hour)
+	previous := 5000.0
+	trip := &SearchTrip{Status: "published", AvailableSeats: 2}
+	trip.ApplyPrice(TripPriceUpdate{PricePerSeat: 4500, PreviousPricePerSeat: &previous, ChangedAt: &changedAt})
+	trip.RecordSeatsBooked(2, now.Add(-time.Hour), DefaultBadgeThresholds.FillingFastWindow)
+	trip.RefreshBadges(DefaultBadgeThresholds, now)
+	assert.True(t, trip.Badges.HasAny())
+
+	assert.False(t, trip.ExpireBadges(DefaultBadgeThresholds, now.Add(time.Minute)), "nothing expired yet")
+
+	// A day later the seats fall out of the window; the price drop is still recent
+	later := now.Add(DefaultBadgeThresholds.FillingFastWindow)
+	assert.True(t, trip.ExpireBadges(DefaultBadgeThresholds, later))
+	assert.False(t, trip.Badges.FillingFast)
+	assert.True(t, trip.Badges.PriceDropped)
+	assert.Empty(t, trip.RecentBookings)
+
+	assert.True(t, trip.ExpireBadges(DefaultBadgeThresholds, now.Add(DefaultBadgeThresholds.PriceDropMaxAge)))
+	assert.False(t, trip.Badges.HasAny())
+}
@@ -0,0 +1,15 @@
+package domain
+
+// CacheStats summarizes how search results were served from the cache
+// Requests that miss the cache while an identical query is already running wait for it
+// instead of querying Solr/MongoDB again (coalesced)
+type CacheStats struct {
+	StaleWindowSeconds int     `json:"stale_window_seconds"`
+	Hits               int64   `json:"hits"`             // Served from a fresh entry
+	StaleHits          int64   `json:"stale_hits"`       // Served from an expired entry inside the stale window (refreshed in the background)
+	Misses             int64   `json:"misses"`           // Computed from Solr/MongoDB
+	Coalesced          int64   `json:"coalesced"`        // Misses that waited for an identical in-flight computation
+	Refreshes          int64   `json:"refreshes"`        // Background refreshes of stale entries
+	RefreshFailures    int64   `json:"refresh_failures"` // Background refreshes that errored (the stale entry is kept)
+	HitRate            float64 `json:"hit_rate"`         // (hits + stale hits) / all lookups
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // Driver represents denormalized driver information embedded in SearchTrip
 // This data is fetched from users-api during event processing and stored here for performance
 type Driver struct {
@@ -9,4 +11,80 @@ type Driver struct {
 	PhotoURL   string  `json:"photo_url,omitempty" bson:"photo_url,omitempty"`
 	Rating     float64 `json:"rating" bson:"rating"`
 	TotalTrips int     `json:"total_trips" bson:"total_trips"`
+
+	// Responsiveness and reliability, used by the relevance ranking (0 = no data yet)
+	AvgResponseSeconds  float64 `json:"avg_response_seconds,omitempty" bson:"avg_response_seconds,omitempty"` // Chat response average, from trips-api
+	ResponsivenessScore float64 `json:"responsiveness_score,omitempty" bson:"responsiveness_score,omitempty"` // 0-1, see ResponsivenessScore
+	CompletionRate      float64 `json:"completion_rate,omitempty" bson:"completion_rate,omitempty"`           // 0-1, see CompletionRate
+
+	// Timestamp of the last user.rating_updated event applied, used to discard older events
+	RatingUpdatedAt *time.Time `json:"rating_updated_at,omitempty" bson:"rating_updated_at,omitempty"`
+}
+
+// ApplyResponseTime sets the responsiveness fields from the trips-api response-time averages
+// A nil or empty rt leaves the driver without responsiveness data
+func (d *Driver) ApplyResponseTime(rt *DriverResponseTime) {
+	if rt == nil {
+		return
+	}
+	avg, samples := rt.AvgSeconds()
+	if samples == 0 {
+		return
+	}
+	d.AvgResponseSeconds = avg
+	d.ResponsivenessScore = ResponsivenessScore(avg)
+}
+
+// DriverSnapshot is the driver data embedded by trips-api in trip.created events
+// It lets the consumer denormalize the trip without calling users-api
+type DriverSnapshot struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Rating     float64   `json:"rating"`
+	TotalTrips int       `json:"total_trips"`
+	PhotoURL   string    `json:"photo_url,omitempty"`
+	FetchedAt  time.Time `json:"fetched_at"` // When trips-api read the driver from users-api
+
+	// Response-time averages computed by trips-api (absent until the driver answered once)
+	ResponseTime *DriverResponseTime `json:"response_time,omitempty"`
+}
+
+// DriverResponseTime are the rolling response-time averages of a driver, as published by
+// trips-api in trip.created snapshots and GET /internal/drivers/:id/response-time
+// An average with 0 samples means "no data", not "answers instantly"
+type DriverResponseTime struct {
+	ChatAvgSeconds float64 `json:"chat_avg_seconds"`
+	ChatSamples    int     `json:"chat_samples"`
+}
+
+// AvgSeconds returns the chat response average and its samples (bookings need no driver approval,
+// so chat is the only response trips-api measures)
+func (rt *DriverResponseTime) AvgSeconds() (float64, int) {
+	if rt.ChatSamples == 0 {
+		return 0, 0
+	}
+	return rt.ChatAvgSeconds, rt.ChatSamples
+}
+
+// IsFresh reports whether the snapshot was fetched within maxAge of now
+// A non-positive maxAge disables snapshots (always treated as stale)
+func (s *DriverSnapshot) IsFresh(maxAge time.Duration, now time.Time) bool {
+	if maxAge <= 0 || s.FetchedAt.IsZero() {
+		return false
+	}
+	return now.Sub(s.FetchedAt) <= maxAge
+}
+
+// ToDriver converts the snapshot to the Driver embedded in SearchTrip
+// Email is not part of the snapshot and is left empty
+func (s *DriverSnapshot) ToDriver() Driver {
+	driver := Driver{
+		ID:         s.ID,
+		Name:       s.Name,
+		PhotoURL:   s.PhotoURL,
+		Rating:     s.Rating,
+		TotalTrips: s.TotalTrips,
+	}
+	driver.ApplyResponseTime(s.ResponseTime)
+	return driver
 }
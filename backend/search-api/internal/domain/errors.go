@@ -35,6 +35,22 @@ var (
 		Message: "User not found in users-api",
 	}
 
+	// Session errors (admin JWT checked against users-api)
+	ErrSessionRevoked = &AppError{
+		Code:    "SESSION_REVOKED",
+		Message: "Session was revoked, log in again",
+	}
+
+	ErrAccountInactive = &AppError{
+		Code:    "ACCOUNT_INACTIVE",
+		Message: "Account is deactivated",
+	}
+
+	ErrAccountSuspended = &AppError{
+		Code:    "ACCOUNT_SUSPENDED",
+		Message: "Account is suspended",
+	}
+
 	ErrServiceUnavailable = &AppError{
 		Code:    "SERVICE_UNAVAILABLE",
 		Message: "External service temporarily unavailable",
@@ -66,6 +82,17 @@ var (
 		Message: "Cache service temporarily unavailable",
 	}
 
+	// Admin Errors
+	ErrReindexInProgress = &AppError{
+		Code:    "REINDEX_IN_PROGRESS",
+		Message: "A reindex is already running",
+	}
+
+	ErrIndexSchemaOutdated = &AppError{
+		Code:    "INDEX_SCHEMA_OUTDATED",
+		Message: "The search index was built with an older schema version, run cmd/rebuild before consuming events",
+	}
+
 	// Repository Errors
 	ErrSearchTripNotFound = &AppError{
 		Code:    "SEARCH_TRIP_NOT_FOUND",
@@ -77,6 +104,11 @@ var (
 		Message: "Event has already been processed (duplicate)",
 	}
 
+	ErrStaleEvent = &AppError{
+		Code:    "STALE_EVENT",
+		Message: "A newer event of the trip has already been applied",
+	}
+
 	// Validation Errors
 	ErrInvalidQuery = &AppError{
 		Code:    "INVALID_QUERY",
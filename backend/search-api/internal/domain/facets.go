@@ -0,0 +1,100 @@
+package domain
+
+// MaxDestinationFacets is the number of destination cities returned in the facets
+const MaxDestinationFacets = 10
+
+// PriceFacetBounds are the bucket boundaries for the price facet (price per seat)
+// Buckets are [0, 5000), [5000, 10000), [10000, 20000), [20000, 40000) and [40000, ∞)
+var PriceFacetBounds = []float64{0, 5000, 10000, 20000, 40000}
+
+// SearchFacets contains the filter counts of a search, computed over all matching trips
+// (not only the current page) so the frontend can render counts next to each filter
+type SearchFacets struct {
+	DestinationCities []FacetCount     `json:"destination_cities"`
+	PriceRanges       []PriceBucket    `json:"price_ranges"`
+	Preferences       PreferenceFacets `json:"preferences"`
+}
+
+// FacetCount is the number of trips for a single facet value
+// Value is the canonical name (what filters match); Display is its localized name, when it differs
+type FacetCount struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Count   int64  `json:"count"`
+}
+
+// PriceBucket is the number of trips whose price per seat falls in [Min, Max)
+// Max is nil for the last, open-ended bucket
+type PriceBucket struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+// PreferenceFacets counts the trips that allow each preference
+type PreferenceFacets struct {
+	PetsAllowed    int64 `json:"pets_allowed" bson:"pets_allowed"`
+	SmokingAllowed int64 `json:"smoking_allowed" bson:"smoking_allowed"`
+	MusicAllowed   int64 `json:"music_allowed" bson:"music_allowed"`
+}
+
+// NewPriceBuckets returns the price buckets defined by PriceFacetBounds with zero counts
+func NewPriceBuckets() []PriceBucket {
+	buckets := make([]PriceBucket, len(PriceFacetBounds))
+	for i, min := range PriceFacetBounds {
+		buckets[i].Min = min
+		if i+1 < len(PriceFacetBounds) {
+			max := PriceFacetBounds[i+1]
+			buckets[i].Max = &max
+		}
+	}
+	return buckets
+}
+
+// PriceBucketIndex returns the index of the bucket (see NewPriceBuckets) containing price
+func PriceBucketIndex(price float64) int {
+	for i := len(PriceFacetBounds) - 1; i > 0; i-- {
+		if price >= PriceFacetBounds[i] {
+			return i
+		}
+	}
+	return 0
+}
+
+// Price histogram (price_histogram=true): fixed-width buckets of price per seat for the price slider
+// Buckets are [0, 2500), [2500, 5000), ... [47500, 50000) plus an open-ended [50000, ∞)
+const (
+	PriceHistogramBucketWidth = 2500.0
+	PriceHistogramMax         = 50000.0
+)
+
+// PriceHistogram is the distribution of the price per seat of the trips matching a search
+// Counts ignore the min_price/max_price filters so the slider keeps showing the whole range
+type PriceHistogram struct {
+	BucketWidth float64       `json:"bucket_width"`
+	Buckets     []PriceBucket `json:"buckets"`
+}
+
+// NewPriceHistogram returns the histogram buckets with zero counts
+func NewPriceHistogram() *PriceHistogram {
+	count := int(PriceHistogramMax / PriceHistogramBucketWidth)
+	buckets := make([]PriceBucket, count+1)
+	for i := 0; i < count; i++ {
+		buckets[i].Min = float64(i) * PriceHistogramBucketWidth
+		max := buckets[i].Min + PriceHistogramBucketWidth
+		buckets[i].Max = &max
+	}
+	buckets[count].Min = PriceHistogramMax
+	return &PriceHistogram{BucketWidth: PriceHistogramBucketWidth, Buckets: buckets}
+}
+
+// PriceHistogramIndex returns the index of the histogram bucket containing price
+func PriceHistogramIndex(price float64) int {
+	if price <= 0 {
+		return 0
+	}
+	if price >= PriceHistogramMax {
+		return int(PriceHistogramMax / PriceHistogramBucketWidth)
+	}
+	return int(price / PriceHistogramBucketWidth)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPriceBuckets(t *testing.T) {
+	buckets := NewPriceBuckets()
+
+	require.Len(t, buckets, len(PriceFacetBounds))
+	assert.Equal(t, 0.0, buckets[0].Min)
+	require.NotNil(t, buckets[0].Max)
+	assert.Equal(t, 5000.0, *buckets[0].Max)
+
+	last := buckets[len(buckets)-1]
+	assert.Equal(t, 40000.0, last.Min)
+	assert.Nil(t, last.Max, "last bucket is open-ended")
+}
+
+func TestPriceBucketIndex(t *testing.T) {
+	assert.Equal(t, 0, PriceBucketIndex(0))
+	assert.Equal(t, 0, PriceBucketIndex(4999.99))
+	assert.Equal(t, 1, PriceBucketIndex(5000))
+	assert.Equal(t, 3, PriceBucketIndex(25000))
+	assert.Equal(t, 4, PriceBucketIndex(40000))
+	assert.Equal(t, 4, PriceBucketIndex(1e6))
+	assert.Equal(t, 0, PriceBucketIndex(-10))
+}
+
+func TestNewPriceHistogram(t *testing.T) {
+	histogram := NewPriceHistogram()
+
+	require.Len(t, histogram.Buckets, 21)
+	assert.Equal(t, PriceHistogramBucketWidth, histogram.BucketWidth)
+	require.NotNil(t, histogram.Buckets[1].Max)
+	assert.Equal(t, 2500.0, histogram.Buckets[1].Min)
+	assert.Equal(t, 5000.0, *histogram.Buckets[1].Max)
+
+	last := histogram.Buckets[len(histogram.Buckets)-1]
+	assert.Equal(t, PriceHistogramMax, last.Min)
+	assert.Nil(t, last.Max, "last bucket is open-ended")
+}
+
+func TestPriceHistogramIndex(t *testing.T) {
+	assert.Equal(t, 0, PriceHistogramIndex(0))
+	assert.Equal(t, 0, PriceHistogramIndex(2499.99))
+	assert.Equal(t, 1, PriceHistogramIndex(2500))
+	assert.Equal(t, 19, PriceHistogramIndex(49999))
+	assert.Equal(t, 20, PriceHistogramIndex(50000))
+	assert.Equal(t, 20, PriceHistogramIndex(1e6))
+	assert.Equal(t, 0, PriceHistogramIndex(-10))
+}
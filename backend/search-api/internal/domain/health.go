@@ -0,0 +1,101 @@
+package domain
+
+import "time"
+
+// Statuses of a dependency in GET /health
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded" // Reachable but slower (or further behind) than its threshold
+	HealthUnhealthy = "unhealthy"
+	HealthDisabled  = "disabled"
+)
+
+// LatencyHealth rates a successful ping: degraded when it took longer than threshold (0 = no threshold)
+func LatencyHealth(latency, threshold time.Duration) string {
+	if threshold > 0 && latency > threshold {
+		return HealthDegraded
+	}
+	return HealthHealthy
+}
+
+// ConsumerStats is what the event consumer knows about its progress
+type ConsumerStats struct {
+	Consuming       bool
+	StartedAt       time.Time // When the consumer started (zero if it never did)
+	LastProcessedAt time.Time // Last message acknowledged (zero if none yet)
+	LastEventAt     time.Time // Timestamp set by the publisher on that message (zero if absent)
+
+	// Messages waiting in the queue (ready, not yet delivered); nil when the broker could not be asked
+	PendingMessages *int
+}
+
+// ConsumerLag is the event lag reported by GET /health
+type ConsumerLag struct {
+	Status          string     `json:"status"`
+	Message         string     `json:"message"`
+	PendingMessages *int       `json:"pending_messages,omitempty"`
+	LastProcessedAt *time.Time `json:"last_processed_at,omitempty"`
+
+	// Time since the consumer last made progress while messages are waiting: an estimate of
+	// the age of the oldest unprocessed message (0 with an empty queue)
+	LagSeconds float64 `json:"lag_seconds"`
+
+	// Delay between publishing and processing the last event (publisher clock vs ours)
+	LastEventDelaySeconds *float64 `json:"last_event_delay_seconds,omitempty"`
+	ThresholdSeconds      float64  `json:"threshold_seconds,omitempty"`
+}
+
+// NewConsumerLag computes the lag at now; above threshold (0 = no threshold) the consumer is degraded
+//
+// An idle queue is not lag: without pending messages the time since the last event only means
+// nothing was published. When the queue depth is unknown, the time since the last event is used.
+func NewConsumerLag(stats ConsumerStats, now time.Time, threshold time.Duration) ConsumerLag {
+	lag := ConsumerLag{
+		Status:           HealthHealthy,
+		Message:          "Consuming",
+		PendingMessages:  stats.PendingMessages,
+		ThresholdSeconds: threshold.Seconds(),
+	}
+	if !stats.Consuming {
+		lag.Status = HealthUnhealthy
+		lag.Message = "Not consuming events"
+		return lag
+	}
+
+	progress := stats.StartedAt
+	if !stats.LastProcessedAt.IsZero() {
+		lastProcessed := stats.LastProcessedAt
+		lag.LastProcessedAt = &lastProcessed
+		progress = lastProcessed
+
+		if !stats.LastEventAt.IsZero() {
+			delay := lastProcessed.Sub(stats.LastEventAt).Seconds()
+			if delay < 0 {
+				delay = 0 // Clock skew between publisher and consumer
+			}
+			lag.LastEventDelaySeconds = &delay
+		}
+	}
+
+	if stats.PendingMessages == nil || *stats.PendingMessages > 0 {
+		if !progress.IsZero() && now.After(progress) {
+			lag.LagSeconds = now.Sub(progress).Seconds()
+		}
+	}
+
+	if threshold <= 0 {
+		return lag
+	}
+	switch {
+	case lag.LagSeconds > threshold.Seconds():
+		lag.Status = HealthDegraded
+		lag.Message = "No event processed recently while messages are waiting"
+		if stats.PendingMessages == nil {
+			lag.Message = "No event processed recently (queue depth unknown)"
+		}
+	case lag.LastEventDelaySeconds != nil && *lag.LastEventDelaySeconds > threshold.Seconds():
+		lag.Status = HealthDegraded
+		lag.Message = "Events are processed late"
+	}
+	return lag
+}
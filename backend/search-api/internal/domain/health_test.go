@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestLatencyHealth(t *testing.T) {
+	assert.Equal(t, HealthHealthy, LatencyHealth(20*time.Millisecond, 100*time.Millisecond))
+	assert.Equal(t, HealthDegraded, LatencyHealth(150*time.Millisecond, 100*time.Millisecond))
+	assert.Equal(t, HealthHealthy, LatencyHealth(5*time.Second, 0), "no threshold")
+}
+
+func TestNewConsumerLag(t *testing.T) {
+	now := time.Date(2025, 12, 15, 12, 0, 0, 0, time.UTC)
+	started := now.Add(-time.Hour)
+	threshold := time.Minute
+
+	t.Run("idle queue is not lag", func(t *testing.T) {
+		lag := NewConsumerLag(ConsumerStats{
+			Consuming:       true,
+			StartedAt:       started,
+			LastProcessedAt: now.Add(-30 * time.Minute),
+			PendingMessages: intPtr(0),
+		}, now, threshold)
+		assert.Equal(t, HealthHealthy, lag.Status)
+		assert.Zero(t, lag.LagSeconds)
+		require.NotNil(t, lag.LastProcessedAt)
+	})
+
+	t.Run("stalled with pending messages", func(t *testing.T) {
+		lag := NewConsumerLag(ConsumerStats{
+			Consuming:       true,
+			StartedAt:       started,
+			LastProcessedAt: now.Add(-5 * time.Minute),
+			PendingMessages: intPtr(12),
+		}, now, threshold)
+		assert.Equal(t, HealthDegraded, lag.Status)
+		assert.Equal(t, 300.0, lag.LagSeconds)
+	})
+
+	t.Run("nothing processed since start", func(t *testing.T) {
+		lag := NewConsumerLag(ConsumerStats{
+			Consuming:       true,
+			StartedAt:       now.Add(-10 * time.Second),
+			PendingMessages: intPtr(3),
+		}, now, threshold)
+		assert.Equal(t, HealthHealthy, lag.Status)
+		assert.Equal(t, 10.0, lag.LagSeconds)
+		assert.Nil(t, lag.LastProcessedAt)
+	})
+
+	t.Run("events processed late", func(t *testing.T) {
+		lag := NewConsumerLag(ConsumerStats{
+			Consuming:       true,
+			StartedAt:       started,
+			LastProcessedAt: now.Add(-time.Second),
+			LastEventAt:     now.Add(-3 * time.Minute),
+			PendingMessages: intPtr(1),
+		}, now, threshold)
+		assert.Equal(t, HealthDegraded, lag.Status)
+		require.NotNil(t, lag.LastEventDelaySeconds)
+		assert.Equal(t, 179.0, *lag.LastEventDelaySeconds)
+	})
+
+	t.Run("unknown queue depth uses time since last event", func(t *testing.T) {
+		lag := NewConsumerLag(ConsumerStats{
+			Consuming:       true,
+			StartedAt:       started,
+			LastProcessedAt: now.Add(-2 * time.Minute),
+		}, now, threshold)
+		assert.Equal(t, HealthDegraded, lag.Status)
+		assert.Equal(t, 120.0, lag.LagSeconds)
+	})
+
+	t.Run("not consuming", func(t *testing.T) {
+		lag := NewConsumerLag(ConsumerStats{}, now, threshold)
+		assert.Equal(t, HealthUnhealthy, lag.Status)
+	})
+}
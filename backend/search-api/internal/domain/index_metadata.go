@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// IndexSchemaVersion is the version of the search read model (MongoDB search documents and
+// Solr fields) this binary reads and writes
+// Bump it when a change needs the existing documents to be rebuilt (a new indexed or derived
+// field, a changed analyzer, ...): instances then refuse to consume trip events until
+// cmd/rebuild has rebuilt the read model with the new version
+const IndexSchemaVersion = 1
+
+// RebuildFenceCheckInterval is how often consumers re-read the rebuild fence
+// cmd/rebuild waits twice this long after taking the fence, so in-flight events finish before it writes
+const RebuildFenceCheckInterval = 5 * time.Second
+
+// Sources of a full reindex
+const (
+	IndexRebuildSourceRebuild = "rebuild" // cmd/rebuild: MongoDB and Solr from the trips-api event archive
+	IndexRebuildSourceReindex = "reindex" // POST /admin/reindex: Solr from MongoDB
+)
+
+// IndexMetadata is the build information of the search read model (index_metadata collection)
+type IndexMetadata struct {
+	SchemaVersion         int        `json:"schema_version" bson:"schema_version"`
+	SchemaUpdatedAt       time.Time  `json:"schema_updated_at" bson:"schema_updated_at"`
+	LastFullReindexAt     *time.Time `json:"last_full_reindex_at,omitempty" bson:"last_full_reindex_at,omitempty"`
+	LastFullReindexSource string     `json:"last_full_reindex_source,omitempty" bson:"last_full_reindex_source,omitempty"` // rebuild or reindex
+
+	// Fence held by cmd/rebuild while it rewrites the read model: consumers hold trip events until it is released
+	// (renewed while the rebuild runs, so a crashed rebuild stops fencing once RebuildFenceUntil passes)
+	RebuildFenceOwner string     `json:"rebuild_fence_owner,omitempty" bson:"rebuild_fence_owner,omitempty"`
+	RebuildFenceUntil *time.Time `json:"rebuild_fence_until,omitempty" bson:"rebuild_fence_until,omitempty"`
+}
+
+// RebuildFenced reports whether a rebuild holds an unexpired fence at now
+func (m *IndexMetadata) RebuildFenced(now time.Time) bool {
+	return m != nil && m.RebuildFenceUntil != nil && m.RebuildFenceUntil.After(now)
+}
+
+// IndexStatus is the index metadata reported by GET /health
+type IndexStatus struct {
+	SchemaVersion         int        `json:"schema_version"` // 0 = read model built before the metadata existed
+	ExpectedSchemaVersion int        `json:"expected_schema_version"`
+	UpToDate              bool       `json:"up_to_date"`
+	ConsumingEvents       bool       `json:"consuming_events"`
+	RebuildInProgress     bool       `json:"rebuild_in_progress"` // Trip events are held until cmd/rebuild finishes
+	LastFullReindexAt     *time.Time `json:"last_full_reindex_at,omitempty"`
+	LastFullReindexSource string     `json:"last_full_reindex_source,omitempty"`
+}
+
+// NewIndexStatus builds the status of the stored metadata (nil = never recorded)
+func NewIndexStatus(metadata *IndexMetadata, consuming bool) IndexStatus {
+	status := IndexStatus{
+		ExpectedSchemaVersion: IndexSchemaVersion,
+		ConsumingEvents:       consuming,
+	}
+	if metadata != nil {
+		status.SchemaVersion = metadata.SchemaVersion
+		status.LastFullReindexAt = metadata.LastFullReindexAt
+		status.LastFullReindexSource = metadata.LastFullReindexSource
+		status.RebuildInProgress = metadata.RebuildFenced(time.Now())
+	}
+	status.UpToDate = status.SchemaVersion >= IndexSchemaVersion
+	return status
+}
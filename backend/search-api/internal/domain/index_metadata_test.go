@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuildFencedUntilTheFenceExpires(t *testing.T) {
+	now := time.Date(2025, 12, 15, 10, 0, 0, 0, time.UTC)
+	until := now.Add(time.Minute)
+
+	var missing *IndexMetadata
+	assert.False(t, missing.RebuildFenced(now))
+	assert.False(t, (&IndexMetadata{}).RebuildFenced(now))
+
+	fenced := &IndexMetadata{RebuildFenceOwner: "rebuild", RebuildFenceUntil: &until}
+	assert.True(t, fenced.RebuildFenced(now))
+	assert.False(t, fenced.RebuildFenced(until), "a fence nobody renewed stops fencing")
+}
+
+func TestNewIndexStatusReportsRebuildInProgress(t *testing.T) {
+	until := time.Now().Add(time.Minute)
+
+	status := NewIndexStatus(&IndexMetadata{SchemaVersion: IndexSchemaVersion, RebuildFenceUntil: &until}, true)
+	assert.True(t, status.RebuildInProgress)
+	assert.True(t, status.UpToDate)
+
+	assert.False(t, NewIndexStatus(nil, false).RebuildInProgress)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// IndexWarmStats describes how warm the Solr index is: when the current searcher was opened,
+// how long its warm-up took and how well each Solr cache is being hit since then
+// A new searcher is opened on every commit, so a young searcher with low hit ratios is expected
+// right after a burst of trip events or a reindex
+type IndexWarmStats struct {
+	Core               string           `json:"core"`
+	NumDocs            int64            `json:"num_docs"`
+	SearcherOpenedAt   *time.Time       `json:"searcher_opened_at,omitempty"`
+	SearcherAgeSeconds int64            `json:"searcher_age_seconds"`
+	SearcherWarmupMs   int64            `json:"searcher_warmup_ms"`    // Time the searcher spent warming before serving queries
+	CacheWarmupMs      int64            `json:"cache_warmup_ms"`       // Sum of the autowarm time of every cache
+	Caches             []SolrCacheStats `json:"caches"`                // Sorted by name
+	ColdCaches         []string         `json:"cold_caches,omitempty"` // Caches that had lookups but no hits yet
+}
+
+// SolrCacheStats are the counters of one Solr cache (filterCache, queryResultCache, documentCache, ...)
+// Lookups, hits and the hit ratio cover the current searcher; the cumulative ratio covers every searcher
+type SolrCacheStats struct {
+	Name               string  `json:"name"`
+	Size               int64   `json:"size"`
+	Lookups            int64   `json:"lookups"`
+	Hits               int64   `json:"hits"`
+	HitRatio           float64 `json:"hit_ratio"`
+	Evictions          int64   `json:"evictions"`
+	WarmupMs           int64   `json:"warmup_ms"`
+	CumulativeHitRatio float64 `json:"cumulative_hit_ratio"`
+}
+
+// Summarize fills the derived fields (searcher age, total cache warm-up, cold caches) at now
+func (s *IndexWarmStats) Summarize(now time.Time) {
+	sort.Slice(s.Caches, func(i, j int) bool {
+		return s.Caches[i].Name < s.Caches[j].Name
+	})
+
+	s.SearcherAgeSeconds = 0
+	if s.SearcherOpenedAt != nil && now.After(*s.SearcherOpenedAt) {
+		s.SearcherAgeSeconds = int64(now.Sub(*s.SearcherOpenedAt) / time.Second)
+	}
+
+	s.CacheWarmupMs = 0
+	s.ColdCaches = nil
+	for _, cache := range s.Caches {
+		s.CacheWarmupMs += cache.WarmupMs
+		if cache.Lookups > 0 && cache.Hits == 0 {
+			s.ColdCaches = append(s.ColdCaches, cache.Name)
+		}
+	}
+}
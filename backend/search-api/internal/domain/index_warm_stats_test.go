@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexWarmStats_Summarize(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	openedAt := now.Add(-90 * time.Second)
+	stats := IndexWarmStats{
+		SearcherOpenedAt: &openedAt,
+		Caches: []SolrCacheStats{
+			{Name: "queryResultCache", Lookups: 20, Hits: 0, WarmupMs: 12},
+			{Name: "documentCache", Lookups: 0, Hits: 0},
+			{Name: "filterCache", Lookups: 50, Hits: 40, WarmupMs: 30},
+		},
+	}
+
+	stats.Summarize(now)
+
+	assert.Equal(t, int64(90), stats.SearcherAgeSeconds)
+	assert.Equal(t, int64(42), stats.CacheWarmupMs)
+	assert.Equal(t, []string{"documentCache", "filterCache", "queryResultCache"}, []string{stats.Caches[0].Name, stats.Caches[1].Name, stats.Caches[2].Name})
+	// documentCache had no lookups: unused, not cold
+	assert.Equal(t, []string{"queryResultCache"}, stats.ColdCaches)
+}
+
+func TestIndexWarmStats_SummarizeWithoutSearcher(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	future := now.Add(time.Minute) // Clock skew between Solr and this instance
+	stats := IndexWarmStats{SearcherOpenedAt: &future, SearcherAgeSeconds: 5, CacheWarmupMs: 7, ColdCaches: []string{"old"}}
+
+	stats.Summarize(now)
+
+	assert.Zero(t, stats.SearcherAgeSeconds)
+	assert.Zero(t, stats.CacheWarmupMs)
+	assert.Nil(t, stats.ColdCaches)
+
+	stats.SearcherOpenedAt = nil
+	stats.Summarize(now)
+	assert.Zero(t, stats.SearcherAgeSeconds)
+}
@@ -3,9 +3,13 @@ package domain
 // Location represents a geographical location with city, province, address and coordinates
 type Location struct {
 	City        string       `json:"city" bson:"city" binding:"required"`
+	CityID      string       `json:"city_id,omitempty" bson:"city_id,omitempty"` // Canonical city from the trips-api catalog ("ar-cordoba"); empty for free-text cities
 	Province    string       `json:"province" bson:"province" binding:"required"`
 	Address     string       `json:"address" bson:"address" binding:"required"`
 	Coordinates GeoJSONPoint `json:"coordinates" bson:"coordinates" binding:"required"`
+
+	// Localized names for the response language (computed per request, never stored)
+	Display *LocationDisplay `json:"display,omitempty" bson:"-"`
 }
 
 // GeoJSONPoint represents geographical coordinates in GeoJSON format
@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultDisplayLanguage is the language of the display names when Accept-Language matches none
+const DefaultDisplayLanguage = "es"
+
+// LocationDisplay is the name of a location in the language negotiated with Accept-Language
+// Display only: filters, cache keys and the stored documents always use the canonical City/Province
+type LocationDisplay struct {
+	City     string `json:"city"`
+	Province string `json:"province,omitempty"`
+	Language string `json:"language"`
+}
+
+// RouteDisplay is the localized name of both ends of a popular route
+type RouteDisplay struct {
+	OriginCity      string `json:"origin_city"`
+	DestinationCity string `json:"destination_city"`
+	Language        string `json:"language"`
+}
+
+// LocationNames maps a language code ("es", "en", "pt-BR") to the display name in that language
+type LocationNames map[string]string
+
+// LocationLocalizationFile is the format of LOCATION_LOCALIZATION_FILE
+//
+//	{"cities": {"Cordoba": {"es": "Córdoba", "en": "Cordoba"}}, "provinces": {"CABA": {"es": "Ciudad Autónoma de Buenos Aires"}}}
+//
+// Keys are canonical names as stored in the trips; accents and case do not matter
+type LocationLocalizationFile struct {
+	Cities    map[string]LocationNames `json:"cities"`
+	Provinces map[string]LocationNames `json:"provinces"`
+}
+
+// DefaultLocationLocalizations are the built-in display names (the localization file adds to and overrides them)
+// They restore the accents of names commonly stored without them and expand abbreviations
+var DefaultLocationLocalizations = LocationLocalizationFile{
+	Cities: map[string]LocationNames{
+		"Cordoba":         {"es": "Córdoba", "en": "Cordoba", "pt": "Córdoba"},
+		"Rio Cuarto":      {"es": "Río Cuarto", "en": "Rio Cuarto", "pt": "Rio Cuarto"},
+		"San Nicolas":     {"es": "San Nicolás", "en": "San Nicolas"},
+		"Tucuman":         {"es": "Tucumán", "en": "Tucuman"},
+		"Neuquen":         {"es": "Neuquén", "en": "Neuquen"},
+		"San Martin":      {"es": "San Martín", "en": "San Martin"},
+		"CABA":            {"es": "Ciudad Autónoma de Buenos Aires", "en": "Buenos Aires City", "pt": "Cidade de Buenos Aires"},
+		"Capital Federal": {"es": "Ciudad Autónoma de Buenos Aires", "en": "Buenos Aires City", "pt": "Cidade de Buenos Aires"},
+	},
+	Provinces: map[string]LocationNames{
+		"Cordoba":    {"es": "Córdoba", "en": "Cordoba", "pt": "Córdoba"},
+		"Neuquen":    {"es": "Neuquén", "en": "Neuquen"},
+		"Tucuman":    {"es": "Tucumán", "en": "Tucuman"},
+		"Entre Rios": {"es": "Entre Ríos", "en": "Entre Rios"},
+		"Rio Negro":  {"es": "Río Negro", "en": "Rio Negro"},
+		"CABA":       {"es": "Ciudad Autónoma de Buenos Aires", "en": "Autonomous City of Buenos Aires", "pt": "Cidade Autônoma de Buenos Aires"},
+	},
+}
+
+// LocationLocalizations is the localization table of city and province display names, loaded at startup
+//
+// Entries are keyed by the normalized canonical name (NormalizeText), so "Cordoba" and "Córdoba" in
+// trip documents resolve to the same entry. A name missing from the table is displayed as stored.
+type LocationLocalizations struct {
+	defaultLanguage string
+	cities          map[string]LocationNames
+	provinces       map[string]LocationNames
+	languages       map[string]bool // Languages with at least one name in the table
+}
+
+// NewLocationLocalizations builds the table from the built-in names plus the given overrides (may be nil)
+func NewLocationLocalizations(defaultLanguage string, overrides *LocationLocalizationFile) *LocationLocalizations {
+	if defaultLanguage == "" {
+		defaultLanguage = DefaultDisplayLanguage
+	}
+	l := &LocationLocalizations{
+		defaultLanguage: strings.ToLower(defaultLanguage),
+		cities:          make(map[string]LocationNames),
+		provinces:       make(map[string]LocationNames),
+		languages:       map[string]bool{strings.ToLower(defaultLanguage): true},
+	}
+
+	l.add(DefaultLocationLocalizations)
+	if overrides != nil {
+		l.add(*overrides)
+	}
+	return l
+}
+
+// ParseLocationLocalizations decodes a localization file and builds the table on top of the built-in names
+func ParseLocationLocalizations(data []byte, defaultLanguage string) (*LocationLocalizations, error) {
+	var file LocationLocalizationFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse localization file: %w", err)
+	}
+	for _, names := range []map[string]LocationNames{file.Cities, file.Provinces} {
+		for canonical, byLanguage := range names {
+			if NormalizeText(canonical) == "" {
+				return nil, fmt.Errorf("parse localization file: empty location name")
+			}
+			for language, name := range byLanguage {
+				if strings.TrimSpace(language) == "" || strings.TrimSpace(name) == "" {
+					return nil, fmt.Errorf("parse localization file: %q has an empty language or name", canonical)
+				}
+			}
+		}
+	}
+	return NewLocationLocalizations(defaultLanguage, &file), nil
+}
+
+// add merges names into the table; a later name for the same location and language replaces the earlier one
+func (l *LocationLocalizations) add(file LocationLocalizationFile) {
+	merge := func(table map[string]LocationNames, names map[string]LocationNames) {
+		for canonical, byLanguage := range names {
+			key := NormalizeText(canonical)
+			if table[key] == nil {
+				table[key] = make(LocationNames, len(byLanguage))
+			}
+			for language, name := range byLanguage {
+				language = strings.ToLower(strings.TrimSpace(language))
+				table[key][language] = strings.TrimSpace(name)
+				l.languages[language] = true
+			}
+		}
+	}
+	merge(l.cities, file.Cities)
+	merge(l.provinces, file.Provinces)
+}
+
+// DefaultLanguage returns the language used when Accept-Language matches none of the table
+func (l *LocationLocalizations) DefaultLanguage() string {
+	return l.defaultLanguage
+}
+
+// NegotiateLanguage picks the display language from an Accept-Language header
+// Tags are tried by decreasing q-value, first as sent (pt-BR) and then by base language (pt);
+// the default language is used when none of them has names in the table ("*" included)
+func (l *LocationLocalizations) NegotiateLanguage(acceptLanguage string) string {
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		if l.languages[tag] {
+			return tag
+		}
+		if base := baseLanguageTag(tag); l.languages[base] {
+			return base
+		}
+	}
+	return l.defaultLanguage
+}
+
+// Display returns the display block of a location in language
+func (l *LocationLocalizations) Display(location Location, language string) *LocationDisplay {
+	return &LocationDisplay{
+		City:     l.CityName(location.City, language),
+		Province: l.ProvinceName(location.Province, language),
+		Language: language,
+	}
+}
+
+// CityName returns the display name of a city in language (the canonical name if it is not in the table)
+func (l *LocationLocalizations) CityName(city, language string) string {
+	return l.lookup(l.cities, city, language)
+}
+
+// ProvinceName returns the display name of a province in language (the canonical name if it is not in the table)
+func (l *LocationLocalizations) ProvinceName(province, language string) string {
+	return l.lookup(l.provinces, province, language)
+}
+
+// lookup falls back from language to its base language, then to the default language, then to the canonical name
+func (l *LocationLocalizations) lookup(table map[string]LocationNames, canonical, language string) string {
+	names, ok := table[NormalizeText(canonical)]
+	if !ok {
+		return canonical
+	}
+	for _, candidate := range []string{language, baseLanguageTag(language), l.defaultLanguage} {
+		if name, ok := names[candidate]; ok {
+			return name
+		}
+	}
+	return canonical
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header, lowercased and
+// ordered by decreasing q-value (ties keep the header order); tags with q=0 and "*" are dropped
+// "es-AR,es;q=0.9,en;q=0.8" → [es-ar es en]
+func ParseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				q = parsed
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		result = append(result, t.tag)
+	}
+	return result
+}
+
+// baseLanguageTag drops the region of a language tag (es-ar → es)
+func baseLanguageTag(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, []string{"es-ar", "es", "en"}, ParseAcceptLanguage("es-AR,es;q=0.9,en;q=0.8"))
+	assert.Equal(t, []string{"en", "pt"}, ParseAcceptLanguage("pt;q=0.5, en"))
+	assert.Equal(t, []string{"en"}, ParseAcceptLanguage("fr;q=0, *;q=0.1, en"))
+	assert.Empty(t, ParseAcceptLanguage(""))
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	localizations := NewLocationLocalizations("es", nil)
+
+	assert.Equal(t, "en", localizations.NegotiateLanguage("en-US,en;q=0.9"))
+	assert.Equal(t, "pt", localizations.NegotiateLanguage("pt-BR"))
+	assert.Equal(t, "en", localizations.NegotiateLanguage("fr,en;q=0.5"), "fr has no names")
+	assert.Equal(t, "es", localizations.NegotiateLanguage("fr"))
+	assert.Equal(t, "es", localizations.NegotiateLanguage(""))
+}
+
+func TestLocationDisplay(t *testing.T) {
+	localizations := NewLocationLocalizations("es", nil)
+
+	display := localizations.Display(Location{City: "CORDOBA", Province: "Córdoba"}, "es")
+	assert.Equal(t, &LocationDisplay{City: "Córdoba", Province: "Córdoba", Language: "es"}, display)
+
+	display = localizations.Display(Location{City: "San Nicolas", Province: "Buenos Aires"}, "pt")
+	assert.Equal(t, "San Nicolás", display.City, "falls back to the default language")
+	assert.Equal(t, "Buenos Aires", display.Province, "not in the table")
+	assert.Equal(t, "pt", display.Language)
+}
+
+func TestParseLocationLocalizations(t *testing.T) {
+	localizations, err := ParseLocationLocalizations([]byte(`{
+		"cities": {"Cordoba": {"en": "Cordoba City"}, "Mar del Plata": {"EN": "Mar del Plata", "it": "Mar della Plata"}}
+	}`), "es")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Cordoba City", localizations.CityName("Córdoba", "en"), "file overrides the built-in name")
+	assert.Equal(t, "Córdoba", localizations.CityName("Córdoba", "es"), "other languages keep the built-in name")
+	assert.Equal(t, "Mar del Plata", localizations.CityName("mar del plata", "en"))
+	assert.Equal(t, "it", localizations.NegotiateLanguage("it-IT"), "languages come from the file too")
+
+	_, err = ParseLocationLocalizations([]byte(`{"cities": {"Rosario": {"en": ""}}}`), "es")
+	assert.Error(t, err)
+	_, err = ParseLocationLocalizations([]byte(`not json`), "es")
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// accentFolder maps accented lowercase letters to their ASCII base letter
+// Mirrors the ASCIIFoldingFilter applied to the Solr text fields
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ç", "c",
+)
+
+// accentClasses lists, for each base letter, the character class matching it with any accent
+var accentClasses = map[rune]string{
+	'a': "[aáàâäã]",
+	'e': "[eéèêë]",
+	'i': "[iíìîï]",
+	'o': "[oóòôöõ]",
+	'u': "[uúùûü]",
+	'n': "[nñ]",
+	'c': "[cç]",
+}
+
+// NormalizeText lowercases, removes accents and collapses whitespace
+// "  Córdoba   Capital " and "cordoba capital" normalize to the same value
+func NormalizeText(s string) string {
+	return strings.Join(strings.Fields(accentFolder.Replace(strings.ToLower(s))), " ")
+}
+
+// AccentInsensitivePattern builds a regex (without anchors) that matches s regardless of accents
+// Must be combined with the "i" option for case-insensitivity
+func AccentInsensitivePattern(s string) string {
+	var b strings.Builder
+	for _, r := range NormalizeText(s) {
+		if class, ok := accentClasses[r]; ok {
+			b.WriteString(class)
+			continue
+		}
+		b.WriteString(regexp.QuoteMeta(string(r)))
+	}
+	return b.String()
+}
+
+// NormalizeCityID lowercases and trims a catalog city ID ("AR-Cordoba " → "ar-cordoba")
+// trips-api stores IDs lowercased, so stored values need no normalization
+func NormalizeCityID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
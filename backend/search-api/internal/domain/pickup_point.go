@@ -0,0 +1,117 @@
+package domain
+
+import "math"
+
+// earthRadiusKm is the Earth radius used for $centerSphere queries and distance estimates
+const earthRadiusKm = 6378.1
+
+// PickupPoint represents a driver-defined pickup point stored with the search document
+// Coordinates are stored as GeoJSON so they can be indexed alongside the origin
+type PickupPoint struct {
+	ID                string       `json:"id" bson:"id"`
+	Label             string       `json:"label" bson:"label"`
+	Instructions      string       `json:"instructions,omitempty" bson:"instructions,omitempty"`
+	Coordinates       GeoJSONPoint `json:"coordinates" bson:"coordinates"`
+	TimeOffsetMinutes int          `json:"time_offset_minutes" bson:"time_offset_minutes"`
+}
+
+// TripPickupPoint represents a pickup point as returned by trips-api (simple lat/lng coordinates)
+type TripPickupPoint struct {
+	ID                string            `json:"id"`
+	Label             string            `json:"label"`
+	Instructions      string            `json:"instructions,omitempty"`
+	Coordinates       SimpleCoordinates `json:"coordinates"`
+	TimeOffsetMinutes int               `json:"time_offset_minutes"`
+}
+
+// ToPickupPoint converts a trips-api pickup point to the search-api storage format
+func (p *TripPickupPoint) ToPickupPoint() PickupPoint {
+	return PickupPoint{
+		ID:                p.ID,
+		Label:             p.Label,
+		Instructions:      p.Instructions,
+		Coordinates:       NewGeoJSONPoint(p.Coordinates.Lat, p.Coordinates.Lng),
+		TimeOffsetMinutes: p.TimeOffsetMinutes,
+	}
+}
+
+// ToPickupPoints converts the pickup points of a trips-api trip or event to the storage format
+// Never returns nil, so an empty list clears the stored points
+func ToPickupPoints(points []TripPickupPoint) []PickupPoint {
+	pickupPoints := make([]PickupPoint, 0, len(points))
+	for i := range points {
+		pickupPoints = append(pickupPoints, points[i].ToPickupPoint())
+	}
+	return pickupPoints
+}
+
+// ApplyPickupPoints replaces the trip pickup points and rebuilds the geo field indexed with them
+func (t *SearchTrip) ApplyPickupPoints(points []PickupPoint) {
+	t.PickupPoints = points
+	t.PickupLocations = BuildPickupLocations(t.Origin.Coordinates, points)
+}
+
+// PickupMatch describes which boarding point of a trip matched a geo search
+// IsOrigin is true when the trip origin itself was the closest point (ID and Label are empty then)
+type PickupMatch struct {
+	ID         string  `json:"id,omitempty"`
+	Label      string  `json:"label,omitempty"`
+	IsOrigin   bool    `json:"is_origin"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// BuildPickupLocations returns the multi-valued geo field indexed for origin searches:
+// the trip origin followed by every pickup point
+func BuildPickupLocations(origin GeoJSONPoint, points []PickupPoint) []GeoJSONPoint {
+	locations := make([]GeoJSONPoint, 0, len(points)+1)
+	if len(origin.Coordinates) == 2 {
+		locations = append(locations, origin)
+	}
+	for _, point := range points {
+		if len(point.Coordinates.Coordinates) == 2 {
+			locations = append(locations, point.Coordinates)
+		}
+	}
+	return locations
+}
+
+// NearestBoardingPoint returns the origin or pickup point closest to (lat, lng) within radiusKm
+// Returns nil if none of them is inside the radius
+func (t *SearchTrip) NearestBoardingPoint(lat, lng float64, radiusKm float64) *PickupMatch {
+	var best *PickupMatch
+
+	if len(t.Origin.Coordinates.Coordinates) == 2 {
+		distance := DistanceKm(lat, lng, t.Origin.Coordinates.Lat(), t.Origin.Coordinates.Lng())
+		if distance <= radiusKm {
+			best = &PickupMatch{IsOrigin: true, DistanceKm: distance}
+		}
+	}
+
+	for _, point := range t.PickupPoints {
+		if len(point.Coordinates.Coordinates) != 2 {
+			continue
+		}
+		distance := DistanceKm(lat, lng, point.Coordinates.Lat(), point.Coordinates.Lng())
+		if distance > radiusKm || (best != nil && distance >= best.DistanceKm) {
+			continue
+		}
+		best = &PickupMatch{ID: point.ID, Label: point.Label, DistanceKm: distance}
+	}
+
+	if best != nil {
+		best.DistanceKm = math.Round(best.DistanceKm*100) / 100
+	}
+	return best
+}
+
+// DistanceKm returns the great-circle (haversine) distance between two points in kilometers
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPickupPoints_ReplacesPointsAndLocations(t *testing.T) {
+	trip := &SearchTrip{Origin: Location{Coordinates: NewGeoJSONPoint(-31.41, -64.18)}}
+	trip.ApplyPickupPoints(ToPickupPoints([]TripPickupPoint{
+		{ID: "p1", Label: "Terminal", Coordinates: SimpleCoordinates{Lat: -31.42, Lng: -64.17}, TimeOffsetMinutes: 15},
+	}))
+
+	require.Len(t, trip.PickupPoints, 1)
+	assert.Equal(t, "Terminal", trip.PickupPoints[0].Label)
+	assert.Equal(t, []float64{-64.17, -31.42}, trip.PickupPoints[0].Coordinates.Coordinates)
+	assert.Len(t, trip.PickupLocations, 2, "origin + pickup point")
+
+	// An empty list (driver removed every point) keeps only the origin
+	trip.ApplyPickupPoints(ToPickupPoints(nil))
+	assert.NotNil(t, trip.PickupPoints)
+	assert.Empty(t, trip.PickupPoints)
+	assert.Len(t, trip.PickupLocations, 1)
+}
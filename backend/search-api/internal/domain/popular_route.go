@@ -14,4 +14,7 @@ type PopularRoute struct {
 	DestinationCity string             `json:"destination_city" bson:"destination_city"`
 	SearchCount     int                `json:"search_count" bson:"search_count"`
 	LastSearched    time.Time          `json:"last_searched" bson:"last_searched"`
+
+	// Localized city names for the response language (computed per request, never stored)
+	Display *RouteDisplay `json:"display,omitempty" bson:"-"`
 }
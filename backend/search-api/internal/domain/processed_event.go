@@ -13,6 +13,6 @@ type ProcessedEvent struct {
 	EventID      string             `json:"event_id" bson:"event_id"` // UNIQUE index required
 	EventType    string             `json:"event_type" bson:"event_type"`
 	ProcessedAt  time.Time          `json:"processed_at" bson:"processed_at"`
-	Result       string             `json:"result" bson:"result"` // success, skipped, failed
+	Result       string             `json:"result" bson:"result"` // success, skipped, stale, failed
 	ErrorMessage string             `json:"error_message,omitempty" bson:"error_message,omitempty"`
 }
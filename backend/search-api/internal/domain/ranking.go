@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// ResponsivenessHalfLife is the average response time that gets a responsiveness score of 0.5
+const ResponsivenessHalfLife = time.Hour
+
+// MinTripsForCompletionRate is the number of finished trips (completed + cancelled) needed
+// before a completion rate is computed; newer drivers are neither boosted nor penalized
+const MinTripsForCompletionRate = 3
+
+// RankingWeights are the boosts applied by the "relevance" sort on top of the text score
+//
+//	boost = 1 + Responsiveness*responsiveness_score + CompletionRate*completion_rate + Rating*rating/5
+//
+// Every signal is in [0, 1], so with the defaults a driver can get at most a 1.8x boost.
+// A weight of 0 disables its signal.
+type RankingWeights struct {
+	Responsiveness float64 `json:"responsiveness"`
+	CompletionRate float64 `json:"completion_rate"`
+	Rating         float64 `json:"rating"`
+}
+
+// DefaultRankingWeights are used when no weights are configured
+var DefaultRankingWeights = RankingWeights{
+	Responsiveness: 0.3,
+	CompletionRate: 0.3,
+	Rating:         0.2,
+}
+
+// Boost returns the relevance multiplier of a driver
+func (w RankingWeights) Boost(d Driver) float64 {
+	return 1 +
+		w.Responsiveness*d.ResponsivenessScore +
+		w.CompletionRate*d.CompletionRate +
+		w.Rating*clamp01(d.Rating/5)
+}
+
+// ResponsivenessScore maps an average response time to [0, 1]: answering instantly scores 1,
+// ResponsivenessHalfLife scores 0.5 and slower drivers tend to 0
+func ResponsivenessScore(avgSeconds float64) float64 {
+	if avgSeconds < 0 {
+		avgSeconds = 0
+	}
+	halfLife := ResponsivenessHalfLife.Seconds()
+	return halfLife / (halfLife + avgSeconds)
+}
+
+// CompletionRate is the share of the driver's finished trips that were completed instead of
+// cancelled. Returns 0 (no data) below MinTripsForCompletionRate finished trips
+func CompletionRate(completed, cancelled int64) float64 {
+	finished := completed + cancelled
+	if finished < MinTripsForCompletionRate {
+		return 0
+	}
+	return float64(completed) / float64(finished)
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
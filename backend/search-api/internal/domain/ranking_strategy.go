@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Built-in ranking strategies, selected with the ranking= search parameter
+const (
+	RankingDefault        = "default"         // Backend order (sort_by), no re-ranking
+	RankingRelevance      = "relevance"       // Driver responsiveness, completion rate and rating (see RankingWeights)
+	RankingPriceSensitive = "price_sensitive" // Cheapest first, rating as a tie-breaker signal
+	RankingEco            = "eco"             // Fuller and newer cars first (less CO2 per passenger)
+)
+
+// RankingWindow is the number of top backend results a ranking strategy re-orders.
+// Pages beyond the window keep the backend order
+const RankingWindow = 100
+
+// Weights of the built-in price_sensitive and eco strategies
+const (
+	priceSensitivePriceWeight  = 0.8
+	priceSensitiveRatingWeight = 0.2
+	ecoOccupancyWeight         = 0.7
+	ecoCarAgeWeight            = 0.3
+	ecoCarAgeHalfLifeYears     = 10.0
+)
+
+// RankingStrategy orders search results. New strategies are added by registering
+// them in RankingStrategies; the search service only looks them up by name
+type RankingStrategy interface {
+	// Name is the value of the ranking= parameter that selects the strategy
+	Name() string
+
+	// Rank re-orders trips in place, best first. Trips the strategy considers equal
+	// keep their backend order
+	Rank(trips []*SearchTrip, now time.Time)
+}
+
+// RankingStrategies is the registry of the strategies available to searches
+type RankingStrategies map[string]RankingStrategy
+
+// NewRankingStrategies returns a registry with the built-in strategies
+func NewRankingStrategies(weights RankingWeights) RankingStrategies {
+	strategies := RankingStrategies{}
+	strategies.Register(defaultRanking{})
+	strategies.Register(relevanceRanking{weights: weights})
+	strategies.Register(priceSensitiveRanking{})
+	strategies.Register(ecoRanking{})
+	return strategies
+}
+
+// Register adds a strategy, replacing any strategy with the same name
+func (r RankingStrategies) Register(strategy RankingStrategy) {
+	r[strategy.Name()] = strategy
+}
+
+// Get returns the strategy registered under name
+func (r RankingStrategies) Get(name string) (RankingStrategy, bool) {
+	strategy, ok := r[name]
+	return strategy, ok
+}
+
+// Names returns the registered strategy names, sorted
+func (r RankingStrategies) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rankByScore sorts trips by descending score; ties keep their current order
+func rankByScore(trips []*SearchTrip, score func(*SearchTrip) float64) {
+	scores := make(map[*SearchTrip]float64, len(trips))
+	for _, trip := range trips {
+		scores[trip] = score(trip)
+	}
+	sort.SliceStable(trips, func(i, j int) bool {
+		return scores[trips[i]] > scores[trips[j]]
+	})
+}
+
+// defaultRanking keeps the order of the backend (sort_by / sort_order)
+type defaultRanking struct{}
+
+func (defaultRanking) Name() string { return RankingDefault }
+
+func (defaultRanking) Rank([]*SearchTrip, time.Time) {}
+
+// relevanceRanking orders by the driver boost of the relevance sort.
+// The text score is only known to Solr, so matching quality is kept as the backend order of ties
+type relevanceRanking struct {
+	weights RankingWeights
+}
+
+func (relevanceRanking) Name() string { return RankingRelevance }
+
+func (r relevanceRanking) Rank(trips []*SearchTrip, _ time.Time) {
+	rankByScore(trips, func(trip *SearchTrip) float64 {
+		return r.weights.Boost(trip.Driver)
+	})
+}
+
+// priceSensitiveRanking scores each trip by how close its price is to the cheapest result,
+// with a small share for the driver rating so equally priced trips favor better drivers
+//
+//	score = 0.8 * cheapest/price + 0.2 * rating/5
+type priceSensitiveRanking struct{}
+
+func (priceSensitiveRanking) Name() string { return RankingPriceSensitive }
+
+func (priceSensitiveRanking) Rank(trips []*SearchTrip, _ time.Time) {
+	cheapest := 0.0
+	for _, trip := range trips {
+		if trip.PricePerSeat > 0 && (cheapest == 0 || trip.PricePerSeat < cheapest) {
+			cheapest = trip.PricePerSeat
+		}
+	}
+
+	rankByScore(trips, func(trip *SearchTrip) float64 {
+		priceScore := 1.0 // Free trips (or no priced trip at all) are the cheapest
+		if trip.PricePerSeat > 0 {
+			priceScore = cheapest / trip.PricePerSeat
+		}
+		return priceSensitivePriceWeight*priceScore + priceSensitiveRatingWeight*clamp01(trip.Driver.Rating/5)
+	})
+}
+
+// ecoRanking favors cars that already carry passengers (the emissions are shared) and newer cars
+//
+//	score = 0.7 * reserved/total + 0.3 * 10/(10 + car_age_years)
+//
+// A car without a year gets no age share
+type ecoRanking struct{}
+
+func (ecoRanking) Name() string { return RankingEco }
+
+func (ecoRanking) Rank(trips []*SearchTrip, now time.Time) {
+	rankByScore(trips, func(trip *SearchTrip) float64 {
+		occupancy := 0.0
+		if trip.TotalSeats > 0 {
+			occupancy = clamp01(float64(trip.TotalSeats-trip.AvailableSeats) / float64(trip.TotalSeats))
+		}
+
+		newness := 0.0
+		if trip.Car.Year > 0 {
+			age := float64(now.Year() - trip.Car.Year)
+			if age < 0 {
+				age = 0
+			}
+			newness = ecoCarAgeHalfLifeYears / (ecoCarAgeHalfLifeYears + age)
+		}
+
+		return ecoOccupancyWeight*occupancy + ecoCarAgeWeight*newness
+	})
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rankingNow = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// rankingFixtures returns the same five trips, in backend order, for every strategy test
+func rankingFixtures() []*SearchTrip {
+	return []*SearchTrip{
+		{
+			TripID:         "a",
+			PricePerSeat:   5000,
+			TotalSeats:     4,
+			AvailableSeats: 4,
+			Car:            Car{Year: 2010},
+			Driver:         Driver{Rating: 3, ResponsivenessScore: 0.2, CompletionRate: 0.5},
+		},
+		{
+			TripID:         "b",
+			PricePerSeat:   2500,
+			TotalSeats:     4,
+			AvailableSeats: 1,
+			Car:            Car{Year: 2024},
+			Driver:         Driver{Rating: 4, ResponsivenessScore: 0.5, CompletionRate: 0.9},
+		},
+		{
+			TripID:         "c",
+			PricePerSeat:   2500,
+			TotalSeats:     3,
+			AvailableSeats: 3,
+			Car:            Car{Year: 2020},
+			Driver:         Driver{Rating: 5, ResponsivenessScore: 1, CompletionRate: 1},
+		},
+		{
+			TripID:         "d",
+			PricePerSeat:   10000,
+			TotalSeats:     4,
+			AvailableSeats: 2,
+			Car:            Car{Year: 2016},
+			Driver:         Driver{},
+		},
+		{
+			TripID:         "e",
+			PricePerSeat:   0,
+			TotalSeats:     2,
+			AvailableSeats: 2,
+			Driver:         Driver{Rating: 1},
+		},
+	}
+}
+
+func rankedIDs(t *testing.T, name string) []string {
+	t.Helper()
+
+	strategy, ok := NewRankingStrategies(DefaultRankingWeights).Get(name)
+	require.True(t, ok, "strategy %s is registered", name)
+	assert.Equal(t, name, strategy.Name())
+
+	trips := rankingFixtures()
+	strategy.Rank(trips, rankingNow)
+
+	ids := make([]string, len(trips))
+	for i, trip := range trips {
+		ids[i] = trip.TripID
+	}
+	return ids
+}
+
+func TestDefaultRanking_KeepsBackendOrder(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, rankedIDs(t, RankingDefault))
+}
+
+func TestRelevanceRanking(t *testing.T) {
+	// Boosts: c 1.8, b 1.58, a 1.33, e 1.04, d 1.0
+	assert.Equal(t, []string{"c", "b", "a", "e", "d"}, rankedIDs(t, RankingRelevance))
+}
+
+func TestPriceSensitiveRanking(t *testing.T) {
+	// The free trip (e) scores like the cheapest priced ones (b, c), so the rating decides among them
+	assert.Equal(t, []string{"c", "b", "e", "a", "d"}, rankedIDs(t, RankingPriceSensitive))
+}
+
+func TestEcoRanking(t *testing.T) {
+	// Occupancy: b 0.75, d 0.5, others 0; then newer cars first, e has no car year
+	assert.Equal(t, []string{"b", "d", "c", "a", "e"}, rankedIDs(t, RankingEco))
+}
+
+func TestRankingStrategies_RegisterAndNames(t *testing.T) {
+	strategies := NewRankingStrategies(DefaultRankingWeights)
+	assert.Equal(t, []string{RankingDefault, RankingEco, RankingPriceSensitive, RankingRelevance}, strategies.Names())
+
+	_, ok := strategies.Get("unknown")
+	assert.False(t, ok)
+
+	strategies.Register(reverseRanking{})
+	trips := rankingFixtures()
+	strategy, ok := strategies.Get("reverse")
+	require.True(t, ok)
+	strategy.Rank(trips, rankingNow)
+	assert.Equal(t, "e", trips[0].TripID)
+}
+
+func TestRankByScore_TiesKeepOrder(t *testing.T) {
+	trips := rankingFixtures()
+	rankByScore(trips, func(*SearchTrip) float64 { return 1 })
+	assert.Equal(t, "a", trips[0].TripID)
+	assert.Equal(t, "e", trips[4].TripID)
+}
+
+// reverseRanking shows a strategy added outside the built-in set
+type reverseRanking struct{}
+
+func (reverseRanking) Name() string { return "reverse" }
+
+func (reverseRanking) Rank(trips []*SearchTrip, _ time.Time) {
+	for i, j := 0, len(trips)-1; i < j; i, j = i+1, j-1 {
+		trips[i], trips[j] = trips[j], trips[i]
+	}
+}
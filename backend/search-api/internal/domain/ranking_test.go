@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponsivenessScore(t *testing.T) {
+	assert.Equal(t, 1.0, ResponsivenessScore(0))
+	assert.Equal(t, 0.5, ResponsivenessScore(ResponsivenessHalfLife.Seconds()))
+	assert.InDelta(t, 0.2, ResponsivenessScore(4*ResponsivenessHalfLife.Seconds()), 1e-9)
+	assert.Equal(t, 1.0, ResponsivenessScore(-5), "negative averages are treated as instant")
+}
+
+func TestCompletionRate(t *testing.T) {
+	assert.Equal(t, 0.0, CompletionRate(2, 0), "below the minimum there is no data")
+	assert.Equal(t, 1.0, CompletionRate(3, 0))
+	assert.Equal(t, 0.75, CompletionRate(6, 2))
+	assert.Equal(t, 0.0, CompletionRate(0, 4))
+}
+
+func TestDriverResponseTime_AvgSeconds(t *testing.T) {
+	rt := &DriverResponseTime{ChatAvgSeconds: 60, ChatSamples: 3}
+	avg, samples := rt.AvgSeconds()
+	assert.Equal(t, 60.0, avg)
+	assert.Equal(t, 3, samples)
+
+	avg, samples = (&DriverResponseTime{ChatAvgSeconds: 60}).AvgSeconds()
+	assert.Equal(t, 0.0, avg, "an average without samples is no data")
+	assert.Equal(t, 0, samples)
+
+	avg, samples = (&DriverResponseTime{}).AvgSeconds()
+	assert.Equal(t, 0.0, avg)
+	assert.Equal(t, 0, samples)
+}
+
+func TestDriverSnapshot_ToDriverAppliesResponseTime(t *testing.T) {
+	snapshot := &DriverSnapshot{ID: 7, Rating: 4.5, ResponseTime: &DriverResponseTime{ChatAvgSeconds: 3600, ChatSamples: 5}}
+	driver := snapshot.ToDriver()
+	assert.Equal(t, 3600.0, driver.AvgResponseSeconds)
+	assert.Equal(t, 0.5, driver.ResponsivenessScore)
+
+	// No samples: no responsiveness data
+	snapshot.ResponseTime = &DriverResponseTime{}
+	driver = snapshot.ToDriver()
+	assert.Zero(t, driver.ResponsivenessScore)
+}
+
+func TestRankingWeights_Boost(t *testing.T) {
+	w := RankingWeights{Responsiveness: 0.3, CompletionRate: 0.3, Rating: 0.2}
+
+	assert.Equal(t, 1.0, w.Boost(Driver{}), "drivers without data are not boosted")
+	assert.InDelta(t, 1.8, w.Boost(Driver{ResponsivenessScore: 1, CompletionRate: 1, Rating: 5}), 1e-9)
+	assert.InDelta(t, 1.15+0.16, w.Boost(Driver{ResponsivenessScore: 0.5, CompletionRate: 0, Rating: 4}), 1e-9)
+	assert.Equal(t, 1.0, RankingWeights{}.Boost(Driver{ResponsivenessScore: 1, CompletionRate: 1, Rating: 5}))
+}
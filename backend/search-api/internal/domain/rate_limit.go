@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is a token bucket: Burst requests at once, refilled at Requests per Per
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int // Bucket capacity (0 = Requests)
+}
+
+// Enabled reports whether the limit applies (0 requests = unlimited)
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// Capacity is the number of requests a full bucket allows at once
+func (l RateLimit) Capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// refillRate is the tokens added per second
+func (l RateLimit) refillRate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// TokenBucket is the state of a bucket: tokens left when it was last updated
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// RateDecision is the outcome of taking a token
+type RateDecision struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left after this request
+	RetryAfter time.Duration // Until the next token when the request was rejected
+}
+
+// NewTokenBucket returns a full bucket
+func NewTokenBucket(limit RateLimit, now time.Time) TokenBucket {
+	return TokenBucket{Tokens: limit.Capacity(), UpdatedAt: now}
+}
+
+// Take refills the bucket up to now and takes a token if there is one
+// Returns the new state of the bucket, which must be stored even when the request is rejected
+func (b TokenBucket) Take(limit RateLimit, now time.Time) (TokenBucket, RateDecision) {
+	if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(limit.Capacity(), b.Tokens+elapsed*limit.refillRate())
+	}
+	b.UpdatedAt = now
+
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return b, RateDecision{Allowed: true, Remaining: int(b.Tokens)}
+	}
+
+	missing := (1 - b.Tokens) / limit.refillRate()
+	return b, RateDecision{RetryAfter: time.Duration(math.Ceil(missing * float64(time.Second)))}
+}
+
+// TTL is how long an untouched bucket needs to be kept: after that it is full again
+func (b TokenBucket) TTL(limit RateLimit) time.Duration {
+	missing := limit.Capacity() - b.Tokens
+	return time.Duration(math.Ceil(missing/limit.refillRate())) * time.Second
+}
+
+// Encode serializes the bucket for the shared cache ("tokens:unix_nanos")
+func (b TokenBucket) Encode() string {
+	return strconv.FormatFloat(b.Tokens, 'f', 4, 64) + ":" + strconv.FormatInt(b.UpdatedAt.UnixNano(), 10)
+}
+
+// DecodeTokenBucket parses a bucket stored with Encode
+func DecodeTokenBucket(value string) (TokenBucket, error) {
+	tokensPart, updatedPart, ok := strings.Cut(value, ":")
+	if !ok {
+		return TokenBucket{}, fmt.Errorf("invalid token bucket %q", value)
+	}
+	tokens, err := strconv.ParseFloat(tokensPart, 64)
+	if err != nil {
+		return TokenBucket{}, fmt.Errorf("invalid token bucket %q: %w", value, err)
+	}
+	updated, err := strconv.ParseInt(updatedPart, 10, 64)
+	if err != nil {
+		return TokenBucket{}, fmt.Errorf("invalid token bucket %q: %w", value, err)
+	}
+	return TokenBucket{Tokens: tokens, UpdatedAt: time.Unix(0, updated)}, nil
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket_Take(t *testing.T) {
+	limit := RateLimit{Requests: 60, Per: time.Minute, Burst: 3}
+	now := time.Date(2025, 12, 15, 12, 0, 0, 0, time.UTC)
+	bucket := NewTokenBucket(limit, now)
+
+	for i := 2; i >= 0; i-- {
+		var decision RateDecision
+		bucket, decision = bucket.Take(limit, now)
+		require.True(t, decision.Allowed)
+		assert.Equal(t, i, decision.Remaining)
+	}
+
+	bucket, decision := bucket.Take(limit, now)
+	assert.False(t, decision.Allowed, "burst exhausted")
+	assert.Equal(t, time.Second, decision.RetryAfter)
+
+	bucket, decision = bucket.Take(limit, now.Add(500*time.Millisecond))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+
+	bucket, decision = bucket.Take(limit, now.Add(time.Second))
+	assert.True(t, decision.Allowed, "one token refilled")
+
+	_, decision = bucket.Take(limit, now.Add(time.Hour))
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 2, decision.Remaining, "refill is capped at the burst")
+}
+
+func TestRateLimit_Capacity(t *testing.T) {
+	assert.Equal(t, 30.0, RateLimit{Requests: 30, Per: time.Minute}.Capacity())
+	assert.Equal(t, 5.0, RateLimit{Requests: 30, Per: time.Minute, Burst: 5}.Capacity())
+	assert.False(t, RateLimit{Per: time.Minute}.Enabled())
+}
+
+func TestTokenBucket_TTL(t *testing.T) {
+	limit := RateLimit{Requests: 60, Per: time.Minute, Burst: 10}
+	bucket := TokenBucket{Tokens: 4}
+	assert.Equal(t, 6*time.Second, bucket.TTL(limit))
+}
+
+func TestTokenBucket_EncodeDecode(t *testing.T) {
+	bucket := TokenBucket{Tokens: 2.5, UpdatedAt: time.Unix(0, 1765800000123456789)}
+	decoded, err := DecodeTokenBucket(bucket.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, bucket.Tokens, decoded.Tokens)
+	assert.True(t, bucket.UpdatedAt.Equal(decoded.UpdatedAt))
+
+	_, err = DecodeTokenBucket("garbage")
+	assert.Error(t, err)
+}
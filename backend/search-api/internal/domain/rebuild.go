@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Trip event types read from the trips-api event archive
+const (
+	ArchivedEventTripCreated   = "trip.created"
+	ArchivedEventTripUpdated   = "trip.updated"
+	ArchivedEventTripCancelled = "trip.cancelled"
+	ArchivedEventTripDeleted   = "trip.deleted"
+)
+
+// ArchiveSettleWindow is how long a sequence gap in the event archive may still be filled
+// trips-api allocates the sequence and inserts the event in two steps (bounded by a 5 second
+// timeout), so a later sequence can be visible before an earlier one. Past this window, which
+// also absorbs clock skew between the services, a gap is permanent (a failed insert)
+const ArchiveSettleWindow = 30 * time.Second
+
+// ArchivedEvent is an entry of the trips-api event archive (event_archive collection)
+// Payload is the exact JSON body that was published to trips.events
+type ArchivedEvent struct {
+	Sequence   int64     `bson:"sequence"`
+	EventID    string    `bson:"event_id"`
+	EventType  string    `bson:"event_type"`
+	RoutingKey string    `bson:"routing_key"`
+	TripID     string    `bson:"trip_id"`
+	Payload    string    `bson:"payload"`
+	ArchivedAt time.Time `bson:"archived_at"`
+}
+
+// archivedTripPayload is the subset of a trip.* payload the rebuild needs
+type archivedTripPayload struct {
+	DriverID       int64           `json:"driver_id"`
+	Status         string          `json:"status"`
+	AvailableSeats int             `json:"available_seats"`
+	ReservedSeats  int             `json:"reserved_seats"`
+	Driver         *DriverSnapshot `json:"driver,omitempty"` // Only in trip.created
+}
+
+// RebuildTripState is the state of one trip folded from the archive
+type RebuildTripState struct {
+	TripID         string
+	DriverID       int64
+	Status         string
+	AvailableSeats int
+	ReservedSeats  int
+	DriverSnapshot *DriverSnapshot // From trip.created, nil if not seen or not embedded
+	Deleted        bool
+	LastSequence   int64
+}
+
+// RebuildState folds archived trip events, in sequence order, into the latest state of each trip
+//
+// Folding is deterministic: the same archive range always produces the same state.
+// Events that do not describe a trip (reservation.* responses) are counted and ignored,
+// their effect on seats reaches the archive as the trip.updated that follows them.
+type RebuildState struct {
+	Trips         map[string]*RebuildTripState
+	EventsApplied int64
+	EventsIgnored int64
+	LastSequence  int64
+	StoppedAtGap  bool // The fold stopped before a gap that an in-flight append may still fill
+}
+
+// NewRebuildState creates an empty fold
+func NewRebuildState() *RebuildState {
+	return &RebuildState{Trips: make(map[string]*RebuildTripState)}
+}
+
+// SettledPrefix returns the events of batch (sorted by sequence) that can be folded after
+// lastSequence: all of them, or the ones before the first gap that is not settled yet (the event
+// after it was archived after cutoff). open reports that the batch was cut at such a gap
+func SettledPrefix(batch []ArchivedEvent, lastSequence int64, cutoff time.Time) (settled []ArchivedEvent, open bool) {
+	expected := lastSequence + 1
+	for i, event := range batch {
+		if event.Sequence > expected && event.ArchivedAt.After(cutoff) {
+			return batch[:i], true
+		}
+		expected = event.Sequence + 1
+	}
+	return batch, false
+}
+
+// Apply folds one archived event; events must be applied in strictly increasing sequence order
+func (s *RebuildState) Apply(event ArchivedEvent) error {
+	if event.Sequence <= s.LastSequence {
+		return fmt.Errorf("archived event %s out of order: sequence %d after %d", event.EventID, event.Sequence, s.LastSequence)
+	}
+	s.LastSequence = event.Sequence
+
+	switch event.EventType {
+	case ArchivedEventTripCreated, ArchivedEventTripUpdated, ArchivedEventTripCancelled, ArchivedEventTripDeleted:
+	default:
+		s.EventsIgnored++
+		return nil
+	}
+	if event.TripID == "" {
+		return fmt.Errorf("archived event %s (sequence %d) has no trip_id", event.EventID, event.Sequence)
+	}
+
+	var payload archivedTripPayload
+	if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+		return fmt.Errorf("archived event %s (sequence %d) has an invalid payload: %w", event.EventID, event.Sequence, err)
+	}
+
+	state, ok := s.Trips[event.TripID]
+	if !ok {
+		state = &RebuildTripState{TripID: event.TripID}
+		s.Trips[event.TripID] = state
+	}
+
+	state.DriverID = payload.DriverID
+	state.Status = payload.Status
+	state.AvailableSeats = payload.AvailableSeats
+	state.ReservedSeats = payload.ReservedSeats
+	state.LastSequence = event.Sequence
+
+	switch event.EventType {
+	case ArchivedEventTripCreated:
+		state.DriverSnapshot = payload.Driver
+		state.Deleted = false
+	case ArchivedEventTripDeleted:
+		state.Deleted = true
+	}
+
+	s.EventsApplied++
+	return nil
+}
+
+// LiveTripIDs returns the trips that still exist after the fold, sorted
+func (s *RebuildState) LiveTripIDs() []string {
+	return s.tripIDs(false)
+}
+
+// DeletedTripIDs returns the trips whose last event is trip.deleted, sorted
+func (s *RebuildState) DeletedTripIDs() []string {
+	return s.tripIDs(true)
+}
+
+func (s *RebuildState) tripIDs(deleted bool) []string {
+	ids := make([]string, 0, len(s.Trips))
+	for id, state := range s.Trips {
+		if state.Deleted == deleted {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ApplyTo overrides the availability fields of a SearchTrip built from trips-api with the folded state
+func (t *RebuildTripState) ApplyTo(trip *SearchTrip) {
+	trip.Status = t.Status
+	trip.AvailableSeats = t.AvailableSeats
+	trip.ReservedSeats = t.ReservedSeats
+	trip.Bookable = IsBookable(t.Status, t.AvailableSeats)
+}
+
+// RebuildReport summarizes a read model rebuild from the event archive
+type RebuildReport struct {
+	FromSequence  int64 `json:"from_sequence"`
+	LastSequence  int64 `json:"last_sequence"`
+	StoppedAtGap  bool  `json:"stopped_at_gap"` // Events after last_sequence were left to the consumers
+	FullRebuild   bool  `json:"full_rebuild"`   // from_sequence <= 1: the whole collection/index is replaced
+	DryRun        bool  `json:"dry_run"`
+	EventsApplied int64 `json:"events_applied"`
+	EventsIgnored int64 `json:"events_ignored"`
+
+	TripsRebuilt int64 `json:"trips_rebuilt"`
+	TripsDeleted int64 `json:"trips_deleted"`
+	TripsSkipped int64 `json:"trips_skipped"` // Live in the archive but missing in trips-api
+	TripsFailed  int64 `json:"trips_failed"`
+	SolrFailed   int64 `json:"solr_failed"`
+
+	MongoCount    int64 `json:"mongo_count"`     // Trips in the search collection after the rebuild
+	TripsAPICount int64 `json:"trips_api_count"` // Trips reported by trips-api
+	CountsMatch   bool  `json:"counts_match"`
+
+	Duration time.Duration `json:"duration"`
+}
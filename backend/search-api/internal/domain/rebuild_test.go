@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func archived(seq int64, eventType, tripID, payload string) ArchivedEvent {
+	return ArchivedEvent{
+		Sequence:  seq,
+		EventID:   eventType + "-" + tripID,
+		EventType: eventType,
+		TripID:    tripID,
+		Payload:   payload,
+	}
+}
+
+func TestRebuildStateFoldsTripEvents(t *testing.T) {
+	state := NewRebuildState()
+
+	require.NoError(t, state.Apply(archived(1, "trip.created", "a", `{"driver_id":7,"status":"published","available_seats":3,"driver":{"id":7,"name":"Ana"}}`)))
+	require.NoError(t, state.Apply(archived(2, "trip.created", "b", `{"driver_id":8,"status":"published","available_seats":2}`)))
+	require.NoError(t, state.Apply(archived(3, "reservation.confirmed", "a", `{"seats_reserved":1}`)))
+	require.NoError(t, state.Apply(archived(4, "trip.updated", "a", `{"driver_id":7,"status":"published","available_seats":2,"reserved_seats":1}`)))
+	require.NoError(t, state.Apply(archived(6, "trip.deleted", "b", `{"driver_id":8,"status":"published","available_seats":2}`)))
+
+	assert.Equal(t, []string{"a"}, state.LiveTripIDs())
+	assert.Equal(t, []string{"b"}, state.DeletedTripIDs())
+	assert.Equal(t, int64(4), state.EventsApplied)
+	assert.Equal(t, int64(1), state.EventsIgnored)
+	assert.Equal(t, int64(6), state.LastSequence)
+
+	a := state.Trips["a"]
+	assert.Equal(t, 2, a.AvailableSeats)
+	assert.Equal(t, 1, a.ReservedSeats)
+	require.NotNil(t, a.DriverSnapshot, "snapshot from trip.created is kept across updates")
+	assert.Equal(t, "Ana", a.DriverSnapshot.Name)
+
+	trip := &SearchTrip{Status: "full", AvailableSeats: 0}
+	a.ApplyTo(trip)
+	assert.Equal(t, "published", trip.Status)
+	assert.Equal(t, 2, trip.AvailableSeats)
+}
+
+func TestRebuildStateRejectsOutOfOrderEvents(t *testing.T) {
+	state := NewRebuildState()
+
+	require.NoError(t, state.Apply(archived(5, "trip.created", "a", `{"status":"published"}`)))
+	assert.Error(t, state.Apply(archived(5, "trip.updated", "a", `{"status":"full"}`)))
+	assert.Error(t, state.Apply(archived(3, "trip.updated", "a", `{"status":"full"}`)))
+	assert.Equal(t, "published", state.Trips["a"].Status)
+}
+
+func TestRebuildStateRejectsInvalidPayload(t *testing.T) {
+	state := NewRebuildState()
+
+	assert.Error(t, state.Apply(archived(1, "trip.created", "a", `not-json`)))
+	assert.Error(t, state.Apply(archived(2, "trip.created", "", `{}`)))
+}
+
+func TestSettledPrefix_StopsAtRecentGap(t *testing.T) {
+	now := time.Now()
+	cutoff := now.Add(-ArchiveSettleWindow)
+	at := func(event ArchivedEvent, archivedAt time.Time) ArchivedEvent {
+		event.ArchivedAt = archivedAt
+		return event
+	}
+
+	// Contiguous batch: everything is settled
+	batch := []ArchivedEvent{at(archived(4, "trip.created", "a", `{}`), now), at(archived(5, "trip.updated", "a", `{}`), now)}
+	settled, open := SettledPrefix(batch, 3, cutoff)
+	assert.Len(t, settled, 2)
+	assert.False(t, open)
+
+	// Sequence 5 was allocated but is not visible yet: 6 must wait for it
+	batch = []ArchivedEvent{at(archived(4, "trip.created", "a", `{}`), now), at(archived(6, "trip.updated", "a", `{}`), now)}
+	settled, open = SettledPrefix(batch, 3, cutoff)
+	require.Len(t, settled, 1)
+	assert.Equal(t, int64(4), settled[0].Sequence)
+	assert.True(t, open)
+
+	// A gap right at the start of the batch is also held back
+	settled, open = SettledPrefix(batch[1:], 4, cutoff)
+	assert.Empty(t, settled)
+	assert.True(t, open)
+
+	// An old gap is a failed insert and is skipped
+	old := now.Add(-2 * ArchiveSettleWindow)
+	batch = []ArchivedEvent{at(archived(4, "trip.created", "a", `{}`), old), at(archived(6, "trip.updated", "a", `{}`), old)}
+	settled, open = SettledPrefix(batch, 3, cutoff)
+	assert.Len(t, settled, 2)
+	assert.False(t, open)
+}
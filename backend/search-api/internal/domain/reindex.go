@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Reindex job states
+const (
+	ReindexStateIdle      = "idle" // No reindex has run since the process started
+	ReindexStateRunning   = "running"
+	ReindexStateCompleted = "completed" // Finished (some documents may still have failed, see Failed)
+	ReindexStateFailed    = "failed"    // Aborted before scanning the whole collection
+)
+
+// ReindexStatus is the progress of the bulk MongoDB -> Solr reindex job
+type ReindexStatus struct {
+	State            string     `json:"state"`
+	Total            int64      `json:"total"`   // Trips in MongoDB when the job started
+	Indexed          int64      `json:"indexed"` // Trips successfully sent to Solr
+	Failed           int64      `json:"failed"`  // Trips Solr rejected (also after retrying one by one)
+	BatchSize        int        `json:"batch_size"`
+	BatchesPerSecond int        `json:"batches_per_second"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	FinishedAt       *time.Time `json:"finished_at,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+}
+
+// Processed returns the number of trips already handled (indexed or failed)
+func (s ReindexStatus) Processed() int64 {
+	return s.Indexed + s.Failed
+}
+
+// ProgressPercent returns the processed percentage (0-100)
+func (s ReindexStatus) ProgressPercent() float64 {
+	if s.Total <= 0 {
+		if s.State == ReindexStateCompleted {
+			return 100
+		}
+		return 0
+	}
+	return float64(s.Processed()) * 100 / float64(s.Total)
+}
@@ -0,0 +1,286 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// Scorer-based ranking strategies, selected with the ranking= search parameter
+const (
+	RankingPopularity         = "popularity"          // Occupancy, driver rating and experience, recency
+	RankingPriceOptimized     = "price_optimized"     // Cheapest within the result set first
+	RankingDepartureProximity = "departure_proximity" // Soonest departure first
+	RankingHybrid             = "hybrid"              // Weighted mix of the three above
+)
+
+// Scorer scores a single trip in [0, 1] (higher is better)
+// Scorers are wrapped into ranking strategies with NewScorerRanking
+type Scorer interface {
+	// Name is the value of the ranking= parameter that selects the scorer
+	Name() string
+
+	// Score returns the score of trip within the result set described by sc
+	Score(trip *SearchTrip, sc ScoringContext) float64
+}
+
+// ScoringContext is what a scorer may know about the whole result set
+type ScoringContext struct {
+	Now      time.Time
+	MinPrice float64 // Cheapest priced trip of the result set (0 if none is priced)
+	MaxPrice float64
+}
+
+// NewScoringContext computes the scoring context of a result set
+func NewScoringContext(trips []*SearchTrip, now time.Time) ScoringContext {
+	sc := ScoringContext{Now: now}
+	for _, trip := range trips {
+		if trip.PricePerSeat <= 0 {
+			continue
+		}
+		if sc.MinPrice == 0 || trip.PricePerSeat < sc.MinPrice {
+			sc.MinPrice = trip.PricePerSeat
+		}
+		if trip.PricePerSeat > sc.MaxPrice {
+			sc.MaxPrice = trip.PricePerSeat
+		}
+	}
+	return sc
+}
+
+// PopularityWeights are the shares of each popularity signal (they are normalized by their sum)
+type PopularityWeights struct {
+	Occupancy  float64 `json:"occupancy"`
+	Rating     float64 `json:"rating"`
+	Experience float64 `json:"experience"`
+	Recency    float64 `json:"recency"`
+}
+
+// HybridWeights are the shares of each scorer in the hybrid score (they are normalized by their sum)
+type HybridWeights struct {
+	Popularity         float64 `json:"popularity"`
+	PriceOptimized     float64 `json:"price_optimized"`
+	DepartureProximity float64 `json:"departure_proximity"`
+}
+
+// ScoringWeights are the tunable parameters of the scorers
+type ScoringWeights struct {
+	Popularity PopularityWeights `json:"popularity"`
+	Hybrid     HybridWeights     `json:"hybrid"`
+
+	// A trip departing this many hours from now gets a departure_proximity score of 0.5
+	DepartureHalfLifeHours float64 `json:"departure_half_life_hours"`
+}
+
+// DefaultScoringWeights are used when no weights are configured
+// The popularity shares are the ones of the original popularity_score (40/40/10/10)
+var DefaultScoringWeights = ScoringWeights{
+	Popularity: PopularityWeights{
+		Occupancy:  0.4,
+		Rating:     0.4,
+		Experience: 0.1,
+		Recency:    0.1,
+	},
+	Hybrid: HybridWeights{
+		Popularity:         0.4,
+		PriceOptimized:     0.3,
+		DepartureProximity: 0.3,
+	},
+	DepartureHalfLifeHours: 48,
+}
+
+// Validate checks that the weights can be used by the scorers
+func (w ScoringWeights) Validate() error {
+	popularity := []float64{w.Popularity.Occupancy, w.Popularity.Rating, w.Popularity.Experience, w.Popularity.Recency}
+	hybrid := []float64{w.Hybrid.Popularity, w.Hybrid.PriceOptimized, w.Hybrid.DepartureProximity}
+
+	if sumWeights(popularity) <= 0 || hasNegative(popularity) {
+		return errors.New("popularity weights must be non-negative and not all zero")
+	}
+	if sumWeights(hybrid) <= 0 || hasNegative(hybrid) {
+		return errors.New("hybrid weights must be non-negative and not all zero")
+	}
+	if w.DepartureHalfLifeHours <= 0 {
+		return errors.New("departure_half_life_hours must be positive")
+	}
+	return nil
+}
+
+// ScoringWeightsStore holds the weights used by the scorers
+// Weights can be replaced at runtime; searches in flight keep the weights they started with
+type ScoringWeightsStore struct {
+	mu      sync.RWMutex
+	weights ScoringWeights
+}
+
+// NewScoringWeightsStore creates a store with the given initial weights
+func NewScoringWeightsStore(weights ScoringWeights) *ScoringWeightsStore {
+	return &ScoringWeightsStore{weights: weights}
+}
+
+// Get returns the current weights
+func (s *ScoringWeightsStore) Get() ScoringWeights {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.weights
+}
+
+// Set replaces the weights after validating them
+func (s *ScoringWeightsStore) Set(weights ScoringWeights) error {
+	if err := weights.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.weights = weights
+	s.mu.Unlock()
+	return nil
+}
+
+// PopularityScore scores a trip by occupancy, driver rating, driver experience (trips, capped at 100)
+// and recency (full score for 7 days after creation, then decaying to 0 over 30 days)
+func PopularityScore(trip *SearchTrip, w PopularityWeights, now time.Time) float64 {
+	occupancy := 0.0
+	if trip.TotalSeats > 0 {
+		occupancy = clamp01(float64(trip.TotalSeats-trip.AvailableSeats) / float64(trip.TotalSeats))
+	}
+
+	rating := clamp01(trip.Driver.Rating / 5)
+	experience := clamp01(float64(trip.Driver.TotalTrips) / 100)
+
+	recency := 1.0
+	if daysSinceCreation := now.Sub(trip.CreatedAt).Hours() / 24; daysSinceCreation > 7 {
+		recency = clamp01(1 - (daysSinceCreation-7)/30)
+	}
+
+	return weightedAverage(
+		[]float64{occupancy, rating, experience, recency},
+		[]float64{w.Occupancy, w.Rating, w.Experience, w.Recency},
+	)
+}
+
+// PriceOptimizedScore maps the price of a trip to [0, 1] within the result set:
+// the cheapest trip scores 1 and the most expensive 0. Free trips score 1
+func PriceOptimizedScore(trip *SearchTrip, sc ScoringContext) float64 {
+	if trip.PricePerSeat <= 0 || sc.MaxPrice <= sc.MinPrice {
+		return 1
+	}
+	return clamp01((sc.MaxPrice - trip.PricePerSeat) / (sc.MaxPrice - sc.MinPrice))
+}
+
+// DepartureProximityScore favors trips departing soon: halfLife/(halfLife + hours until departure)
+// Trips that already departed score 0
+func DepartureProximityScore(trip *SearchTrip, halfLifeHours float64, now time.Time) float64 {
+	hours := trip.DepartureDatetime.Sub(now).Hours()
+	if hours < 0 || halfLifeHours <= 0 {
+		return 0
+	}
+	return halfLifeHours / (halfLifeHours + hours)
+}
+
+// popularityScorer, priceOptimizedScorer, departureProximityScorer and hybridScorer read their
+// weights from the store on every search, so tuning them needs no restart
+type popularityScorer struct{ weights *ScoringWeightsStore }
+
+func (popularityScorer) Name() string { return RankingPopularity }
+
+func (s popularityScorer) Score(trip *SearchTrip, sc ScoringContext) float64 {
+	return PopularityScore(trip, s.weights.Get().Popularity, sc.Now)
+}
+
+type priceOptimizedScorer struct{}
+
+func (priceOptimizedScorer) Name() string { return RankingPriceOptimized }
+
+func (priceOptimizedScorer) Score(trip *SearchTrip, sc ScoringContext) float64 {
+	return PriceOptimizedScore(trip, sc)
+}
+
+type departureProximityScorer struct{ weights *ScoringWeightsStore }
+
+func (departureProximityScorer) Name() string { return RankingDepartureProximity }
+
+func (s departureProximityScorer) Score(trip *SearchTrip, sc ScoringContext) float64 {
+	return DepartureProximityScore(trip, s.weights.Get().DepartureHalfLifeHours, sc.Now)
+}
+
+type hybridScorer struct{ weights *ScoringWeightsStore }
+
+func (hybridScorer) Name() string { return RankingHybrid }
+
+func (s hybridScorer) Score(trip *SearchTrip, sc ScoringContext) float64 {
+	w := s.weights.Get()
+	return weightedAverage(
+		[]float64{
+			PopularityScore(trip, w.Popularity, sc.Now),
+			PriceOptimizedScore(trip, sc),
+			DepartureProximityScore(trip, w.DepartureHalfLifeHours, sc.Now),
+		},
+		[]float64{w.Hybrid.Popularity, w.Hybrid.PriceOptimized, w.Hybrid.DepartureProximity},
+	)
+}
+
+// NewScorers returns the built-in scorers, reading their weights from store
+func NewScorers(store *ScoringWeightsStore) []Scorer {
+	return []Scorer{
+		popularityScorer{weights: store},
+		priceOptimizedScorer{},
+		departureProximityScorer{weights: store},
+		hybridScorer{weights: store},
+	}
+}
+
+// scorerRanking is the ranking strategy of a Scorer: best score first
+type scorerRanking struct {
+	scorer Scorer
+}
+
+// NewScorerRanking wraps a scorer into a ranking strategy registered under the scorer name
+func NewScorerRanking(scorer Scorer) RankingStrategy {
+	return scorerRanking{scorer: scorer}
+}
+
+func (r scorerRanking) Name() string { return r.scorer.Name() }
+
+func (r scorerRanking) Rank(trips []*SearchTrip, now time.Time) {
+	sc := NewScoringContext(trips, now)
+	rankByScore(trips, func(trip *SearchTrip) float64 {
+		return r.scorer.Score(trip, sc)
+	})
+}
+
+// RegisterScorers registers one ranking strategy per scorer
+func (r RankingStrategies) RegisterScorers(scorers []Scorer) {
+	for _, scorer := range scorers {
+		r.Register(NewScorerRanking(scorer))
+	}
+}
+
+func weightedAverage(values, weights []float64) float64 {
+	total := sumWeights(weights)
+	if total <= 0 {
+		return 0
+	}
+	score := 0.0
+	for i, value := range values {
+		score += value * weights[i]
+	}
+	return score / total
+}
+
+func sumWeights(weights []float64) float64 {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	return total
+}
+
+func hasNegative(weights []float64) bool {
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) {
+			return true
+		}
+	}
+	return false
+}
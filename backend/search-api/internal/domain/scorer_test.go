@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var scoringNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func scoredIDs(t *testing.T, store *ScoringWeightsStore, name string, trips []*SearchTrip) []string {
+	t.Helper()
+
+	strategies := NewRankingStrategies(DefaultRankingWeights)
+	strategies.RegisterScorers(NewScorers(store))
+	strategy, ok := strategies.Get(name)
+	require.True(t, ok, "strategy %s is registered", name)
+	assert.Equal(t, name, strategy.Name())
+
+	strategy.Rank(trips, scoringNow)
+
+	ids := make([]string, len(trips))
+	for i, trip := range trips {
+		ids[i] = trip.TripID
+	}
+	return ids
+}
+
+func TestPopularityScore_MatchesOriginalShares(t *testing.T) {
+	trip := &SearchTrip{
+		TotalSeats:     4,
+		AvailableSeats: 2,
+		Driver:         Driver{Rating: 4, TotalTrips: 50},
+		CreatedAt:      scoringNow.Add(-24 * time.Hour),
+	}
+
+	// 0.4*0.5 + 0.4*0.8 + 0.1*0.5 + 0.1*1
+	assert.InDelta(t, 0.67, PopularityScore(trip, DefaultScoringWeights.Popularity, scoringNow), 1e-9)
+
+	// Recency decays after 7 days and is gone after 37
+	trip.CreatedAt = scoringNow.Add(-40 * 24 * time.Hour)
+	assert.InDelta(t, 0.57, PopularityScore(trip, DefaultScoringWeights.Popularity, scoringNow), 1e-9)
+}
+
+func TestPriceOptimizedRanking(t *testing.T) {
+	trips := []*SearchTrip{
+		{TripID: "a", PricePerSeat: 3000},
+		{TripID: "b", PricePerSeat: 1000},
+		{TripID: "c", PricePerSeat: 2000},
+	}
+	store := NewScoringWeightsStore(DefaultScoringWeights)
+	assert.Equal(t, []string{"b", "c", "a"}, scoredIDs(t, store, RankingPriceOptimized, trips))
+}
+
+func TestDepartureProximityRanking(t *testing.T) {
+	trips := []*SearchTrip{
+		{TripID: "past", DepartureDatetime: scoringNow.Add(-time.Hour)},
+		{TripID: "later", DepartureDatetime: scoringNow.Add(72 * time.Hour)},
+		{TripID: "soon", DepartureDatetime: scoringNow.Add(2 * time.Hour)},
+	}
+	store := NewScoringWeightsStore(DefaultScoringWeights)
+	assert.Equal(t, []string{"soon", "later", "past"}, scoredIDs(t, store, RankingDepartureProximity, trips))
+}
+
+func TestHybridRanking_FollowsWeights(t *testing.T) {
+	fixtures := func() []*SearchTrip {
+		return []*SearchTrip{
+			// Cheap but departing in a week
+			{TripID: "cheap", PricePerSeat: 1000, DepartureDatetime: scoringNow.Add(7 * 24 * time.Hour)},
+			// Expensive but departing soon
+			{TripID: "soon", PricePerSeat: 3000, DepartureDatetime: scoringNow.Add(time.Hour)},
+		}
+	}
+
+	store := NewScoringWeightsStore(DefaultScoringWeights)
+	priceHeavy := DefaultScoringWeights
+	priceHeavy.Hybrid = HybridWeights{Popularity: 0, PriceOptimized: 1, DepartureProximity: 0.1}
+	require.NoError(t, store.Set(priceHeavy))
+	assert.Equal(t, []string{"cheap", "soon"}, scoredIDs(t, store, RankingHybrid, fixtures()))
+
+	// Same scorer, new weights: no restart needed
+	departureHeavy := DefaultScoringWeights
+	departureHeavy.Hybrid = HybridWeights{Popularity: 0, PriceOptimized: 0.1, DepartureProximity: 1}
+	require.NoError(t, store.Set(departureHeavy))
+	assert.Equal(t, []string{"soon", "cheap"}, scoredIDs(t, store, RankingHybrid, fixtures()))
+}
+
+func TestScoringWeights_Validate(t *testing.T) {
+	assert.NoError(t, DefaultScoringWeights.Validate())
+
+	w := DefaultScoringWeights
+	w.Hybrid = HybridWeights{}
+	assert.Error(t, w.Validate(), "hybrid weights cannot all be zero")
+
+	w = DefaultScoringWeights
+	w.Popularity.Rating = -1
+	assert.Error(t, w.Validate())
+
+	w = DefaultScoringWeights
+	w.DepartureHalfLifeHours = 0
+	assert.Error(t, w.Validate())
+
+	store := NewScoringWeightsStore(DefaultScoringWeights)
+	assert.Error(t, store.Set(w))
+	assert.Equal(t, DefaultScoringWeights, store.Get(), "invalid weights are not stored")
+}
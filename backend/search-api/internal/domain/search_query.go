@@ -4,6 +4,8 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 )
 
@@ -20,42 +22,199 @@ type SearchQuery struct {
 
 	// Other filters - will use Solr
 	MinSeats        int     `json:"min_seats,omitempty"`
+	MinPrice        float64 `json:"min_price,omitempty"`
 	MaxPrice        float64 `json:"max_price,omitempty"`
 	PetsAllowed     *bool   `json:"pets_allowed,omitempty"`
 	SmokingAllowed  *bool   `json:"smoking_allowed,omitempty"`
 	MusicAllowed    *bool   `json:"music_allowed,omitempty"`
 	MinDriverRating float64 `json:"min_driver_rating,omitempty"`
 
+	// Bookable filters on SearchTrip.Bookable; nil means true. false also returns the upcoming
+	// trips that no longer accept reservations (full or closed) so clients can grey them out
+	Bookable *bool `json:"bookable,omitempty"`
+
+	// IncludePast also returns trips that already departed, including the ones expired by the
+	// expiry job; by default only upcoming trips are returned
+	IncludePast bool `json:"include_past,omitempty"`
+
 	// Full-text search
 	SearchText string `json:"search_text,omitempty"`
 
 	// Sorting and pagination
-	SortBy    string `json:"sort_by,omitempty"` // popularity, price_asc, price_desc, date_asc, date_desc
-	SortOrder string `json:"sort_order,omitempty"`
-	Page      int    `json:"page,omitempty"`
-	Limit     int    `json:"limit,omitempty"`
+	SortBy    string          `json:"sort_by,omitempty"` // popularity, price_asc, price_desc, date_asc, date_desc, relevance
+	SortOrder string          `json:"sort_order,omitempty"`
+	Sort      []SortCriterion `json:"sort,omitempty"`    // Compound sort (e.g. price, then departure_time); replaces sort_by/sort_order
+	Ranking   string          `json:"ranking,omitempty"` // Ranking strategy applied on top of sort_by (see RankingStrategy)
+	Page      int             `json:"page,omitempty"`
+	Limit     int             `json:"limit,omitempty"`
+
+	// Facets requests filter counts (destination cities, price ranges, preferences)
+	Facets bool `json:"facets,omitempty"`
+
+	// PriceHistogram requests the price per seat distribution (see PriceHistogram)
+	PriceHistogram bool `json:"price_histogram,omitempty"`
 }
 
 // SearchResponse contains the search results with pagination info
 type SearchResponse struct {
-	Trips      []*SearchTrip `json:"trips"`
-	Total      int64         `json:"total"`
-	Page       int           `json:"page"`
-	Limit      int           `json:"limit"`
-	TotalPages int           `json:"total_pages"`
+	Trips          []*SearchTrip   `json:"trips"`
+	Total          int64           `json:"total"`
+	Page           int             `json:"page"`
+	Limit          int             `json:"limit"`
+	TotalPages     int             `json:"total_pages"`
+	Facets         *SearchFacets   `json:"facets,omitempty"`          // Only when requested with facets=true
+	PriceHistogram *PriceHistogram `json:"price_histogram,omitempty"` // Only when requested with price_histogram=true
+}
+
+// SortCriterion is one key of a compound sort, applied when the previous keys tie
+type SortCriterion struct {
+	Field string `json:"field"`           // One of SortableFields
+	Order string `json:"order,omitempty"` // asc (default) or desc
+}
+
+// MaxSortCriteria bounds the number of keys of a compound sort
+const MaxSortCriteria = 3
+
+// SortableFields are the fields allowed in a compound sort (the shortcuts only work in sort_by)
+var SortableFields = map[string]bool{
+	"price":          true,
+	"departure_time": true,
+	"rating":         true,
+	"popularity":     true,
+}
+
+// ParseSort parses the sort parameter: comma-separated field[:order] items,
+// e.g. "price:asc,departure_time" (cheapest first, then earliest)
+// Fields and orders are checked by Validate
+func ParseSort(raw string) ([]SortCriterion, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var criteria []SortCriterion
+	for _, item := range strings.Split(raw, ",") {
+		field, order, _ := strings.Cut(strings.TrimSpace(item), ":")
+		if field == "" {
+			return nil, fmt.Errorf("invalid sort: empty field in %q", raw)
+		}
+		criteria = append(criteria, SortCriterion{
+			Field: strings.ToLower(field),
+			Order: strings.ToLower(strings.TrimSpace(order)),
+		})
+	}
+	return criteria, nil
+}
+
+// SortCriteria returns the sort keys to apply, in order: Sort when given, otherwise
+// sort_by/sort_order as a single criterion (nil when the query is not sorted)
+func (q *SearchQuery) SortCriteria() []SortCriterion {
+	if len(q.Sort) > 0 {
+		return q.Sort
+	}
+	if q.SortBy == "" {
+		return nil
+	}
+	return []SortCriterion{{Field: q.SortBy, Order: q.SortOrder}}
+}
+
+// Sort shortcuts whose direction is fixed (sort_order is ignored by both backends)
+var fixedDirectionSorts = map[string]bool{
+	"earliest":   true,
+	"cheapest":   true,
+	"best_rated": true,
+	"relevance":  true, // Text score boosted by driver signals, always descending
+}
+
+// Canonical returns a normalized copy of the query where semantically identical
+// searches are equal:
+//   - City, province and free text are lowercased, accent-free and whitespace-collapsed
+//   - City IDs are lowercased and trimmed (catalog IDs are case-insensitive)
+//   - Default page, limit and sort are filled in explicitly
+//   - sort_order is dropped for fixed-direction shortcuts and defaults to "asc" otherwise
+//   - A compound sort replaces sort_by/sort_order and its orders default to "asc"
+//   - Sorting is dropped for geospatial queries (results are ordered by distance)
+//   - Coordinates are dropped when no radius is given (they do not filter) and rounded to 6 decimals
+//   - The departure date is reduced to its day (the filter matches the whole day)
+//
+// Cities still match stored values regardless of case/accents: MongoDB queries use a
+// case/accent-insensitive collation and Solr folds accents on the city fields.
+func (q *SearchQuery) Canonical() SearchQuery {
+	c := *q
+	c.SetDefaults()
+
+	c.Origin, c.OriginRadius = canonicalLocation(q.Origin, q.OriginRadius)
+	c.Destination, c.DestinationRadius = canonicalLocation(q.Destination, q.DestinationRadius)
+	c.SearchText = NormalizeText(q.SearchText)
+
+	switch {
+	case c.IsGeospatial():
+		c.SortBy, c.SortOrder, c.Sort = "", "", nil
+	case len(c.Sort) > 0:
+		c.SortBy, c.SortOrder = "", ""
+		c.Sort = make([]SortCriterion, len(q.Sort))
+		for i, criterion := range q.Sort {
+			if criterion.Order == "" {
+				criterion.Order = "asc"
+			}
+			c.Sort[i] = criterion
+		}
+	case fixedDirectionSorts[c.SortBy]:
+		c.SortOrder = ""
+	case c.SortOrder == "":
+		c.SortOrder = "asc"
+	}
+
+	if q.DepartureDate != nil {
+		day := q.DepartureDate.UTC().Truncate(24 * time.Hour)
+		c.DepartureDate = &day
+	}
+
+	return c
+}
+
+// canonicalLocation normalizes a location filter; nil when it does not filter anything
+func canonicalLocation(loc *Location, radius int) (*Location, int) {
+	if loc == nil {
+		return nil, 0
+	}
+
+	c := Location{
+		City:     NormalizeText(loc.City),
+		CityID:   NormalizeCityID(loc.CityID),
+		Province: NormalizeText(loc.Province),
+	}
+	if len(loc.Coordinates.Coordinates) == 2 && radius > 0 {
+		c.Coordinates = NewGeoJSONPoint(roundCoordinate(loc.Coordinates.Lat()), roundCoordinate(loc.Coordinates.Lng()))
+	} else {
+		radius = 0
+	}
+
+	if c.City == "" && c.CityID == "" && c.Province == "" && radius == 0 {
+		return nil, 0
+	}
+	return &c, radius
+}
+
+// roundCoordinate rounds a coordinate to 6 decimals (~0.1 m)
+func roundCoordinate(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
 }
 
 // Hash generates a deterministic hash for the query (for caching)
-// Same query parameters = same hash
+// Semantically identical queries = same hash (see Canonical)
 func (q *SearchQuery) Hash() string {
-	// Create a normalized copy with only search-relevant fields
+	c := q.Canonical()
+
+	// Only search-relevant fields, in a fixed order
 	// Note: We exclude Address from Location as it doesn't affect search results
 	normalized := struct {
 		OriginCity        string
+		OriginCityID      string
 		OriginProvince    string
 		OriginLat         float64
 		OriginLng         float64
 		DestinationCity   string
+		DestinationCityID string
 		DestinationProv   string
 		DestinationLat    float64
 		DestinationLng    float64
@@ -63,55 +222,71 @@ func (q *SearchQuery) Hash() string {
 		DestinationRadius int
 		DepartureDate     string
 		MinSeats          int
+		MinPrice          float64
 		MaxPrice          float64
 		PetsAllowed       *bool
 		SmokingAllowed    *bool
 		MusicAllowed      *bool
 		MinDriverRating   float64
+		OnlyBookable      bool
+		IncludePast       bool
 		SearchText        string
 		SortBy            string
 		SortOrder         string
+		Sort              []SortCriterion
+		Ranking           string
 		Page              int
 		Limit             int
+		Facets            bool
+		PriceHistogram    bool
 	}{
-		OriginRadius:      q.OriginRadius,
-		DestinationRadius: q.DestinationRadius,
-		MinSeats:          q.MinSeats,
-		MaxPrice:          q.MaxPrice,
-		PetsAllowed:       q.PetsAllowed,
-		SmokingAllowed:    q.SmokingAllowed,
-		MusicAllowed:      q.MusicAllowed,
-		MinDriverRating:   q.MinDriverRating,
-		SearchText:        q.SearchText,
-		SortBy:            q.SortBy,
-		SortOrder:         q.SortOrder,
-		Page:              q.Page,
-		Limit:             q.Limit,
+		OriginRadius:      c.OriginRadius,
+		DestinationRadius: c.DestinationRadius,
+		MinSeats:          c.MinSeats,
+		MinPrice:          c.MinPrice,
+		MaxPrice:          c.MaxPrice,
+		PetsAllowed:       c.PetsAllowed,
+		SmokingAllowed:    c.SmokingAllowed,
+		MusicAllowed:      c.MusicAllowed,
+		MinDriverRating:   c.MinDriverRating,
+		OnlyBookable:      c.OnlyBookable(),
+		IncludePast:       c.IncludePast,
+		SearchText:        c.SearchText,
+		SortBy:            c.SortBy,
+		SortOrder:         c.SortOrder,
+		Sort:              c.Sort,
+		Ranking:           c.Ranking,
+		Page:              c.Page,
+		Limit:             c.Limit,
+		Facets:            c.Facets,
+		PriceHistogram:    c.PriceHistogram,
 	}
 
 	// Extract Origin fields if present
-	if q.Origin != nil {
-		normalized.OriginCity = q.Origin.City
-		normalized.OriginProvince = q.Origin.Province
-		if len(q.Origin.Coordinates.Coordinates) == 2 {
-			normalized.OriginLat = q.Origin.Coordinates.Lat()
-			normalized.OriginLng = q.Origin.Coordinates.Lng()
+	if c.Origin != nil {
+		normalized.OriginCity = c.Origin.City
+		normalized.OriginCityID = c.Origin.CityID
+		normalized.OriginProvince = c.Origin.Province
+		if len(c.Origin.Coordinates.Coordinates) == 2 {
+			normalized.OriginLat = c.Origin.Coordinates.Lat()
+			normalized.OriginLng = c.Origin.Coordinates.Lng()
 		}
 	}
 
 	// Extract Destination fields if present
-	if q.Destination != nil {
-		normalized.DestinationCity = q.Destination.City
-		normalized.DestinationProv = q.Destination.Province
-		if len(q.Destination.Coordinates.Coordinates) == 2 {
-			normalized.DestinationLat = q.Destination.Coordinates.Lat()
-			normalized.DestinationLng = q.Destination.Coordinates.Lng()
+	if c.Destination != nil {
+		normalized.DestinationCity = c.Destination.City
+		normalized.DestinationCityID = c.Destination.CityID
+		normalized.DestinationProv = c.Destination.Province
+		if len(c.Destination.Coordinates.Coordinates) == 2 {
+			normalized.DestinationLat = c.Destination.Coordinates.Lat()
+			normalized.DestinationLng = c.Destination.Coordinates.Lng()
 		}
 	}
 
-	// Format dates consistently (empty string if zero time)
-	if q.DepartureDate != nil {
-		normalized.DepartureDate = q.DepartureDate.UTC().Format(time.RFC3339)
+	// Format dates consistently (empty string if no date filter)
+	if c.DepartureDate != nil {
+		normalized.DepartureDate = c.DepartureDate.Format("2006-01-02")
 	}
 
 	// Convert to JSON for consistent representation
@@ -122,6 +297,33 @@ func (q *SearchQuery) Hash() string {
 	return fmt.Sprintf("%x", hash)
 }
 
+// OnlyBookable reports whether results are limited to bookable trips (the default)
+func (q *SearchQuery) OnlyBookable() bool {
+	return q.Bookable == nil || *q.Bookable
+}
+
+// VisibleStatuses returns the statuses a search with bookable=false may return: the upcoming
+// ones, plus expired when past trips are requested
+func (q *SearchQuery) VisibleStatuses() []string {
+	if !q.IncludePast {
+		return VisibleTripStatuses
+	}
+	return append(append([]string{}, VisibleTripStatuses...), TripStatusExpired)
+}
+
+// DepartureRange returns the departure window of the search as [from, to)
+// Unless past trips are requested, from is never before now; a zero from or to means unbounded
+func (q *SearchQuery) DepartureRange(now time.Time) (from, to time.Time) {
+	if q.DepartureDate != nil {
+		from = q.DepartureDate.Truncate(24 * time.Hour)
+		to = from.Add(24 * time.Hour)
+	}
+	if !q.IncludePast && from.Before(now) {
+		from = now
+	}
+	return from, to
+}
+
 // IsGeospatial returns true if this is a geospatial query with radius
 // Note: User can provide coordinates without radius (for exact city match)
 func (q *SearchQuery) IsGeospatial() bool {
@@ -145,12 +347,12 @@ func (q *SearchQuery) Validate() error {
 
 	// Validate Origin
 	if q.Origin != nil {
-		hasCity := q.Origin.City != ""
+		hasCity := q.Origin.City != "" || q.Origin.CityID != ""
 		hasCoords := len(q.Origin.Coordinates.Coordinates) == 2
 
-		// Must have at least city or coordinates
+		// Must have at least city (name or catalog ID) or coordinates
 		if !hasCity && !hasCoords {
-			return fmt.Errorf("origin must have city or coordinates")
+			return fmt.Errorf("origin must have city, city_id or coordinates")
 		}
 
 		// If has coordinates, validate them
@@ -171,12 +373,12 @@ func (q *SearchQuery) Validate() error {
 
 	// Validate Destination
 	if q.Destination != nil {
-		hasCity := q.Destination.City != ""
+		hasCity := q.Destination.City != "" || q.Destination.CityID != ""
 		hasCoords := len(q.Destination.Coordinates.Coordinates) == 2
 
-		// Must have at least city or coordinates
+		// Must have at least city (name or catalog ID) or coordinates
 		if !hasCity && !hasCoords {
-			return fmt.Errorf("destination must have city or coordinates")
+			return fmt.Errorf("destination must have city, city_id or coordinates")
 		}
 
 		// If has coordinates, validate them
@@ -199,9 +401,15 @@ func (q *SearchQuery) Validate() error {
 	if q.MinSeats < 0 {
 		return fmt.Errorf("min_seats cannot be negative")
 	}
+	if q.MinPrice < 0 {
+		return fmt.Errorf("min_price cannot be negative")
+	}
 	if q.MaxPrice < 0 {
 		return fmt.Errorf("max_price cannot be negative")
 	}
+	if q.MaxPrice > 0 && q.MinPrice > q.MaxPrice {
+		return fmt.Errorf("min_price cannot be greater than max_price")
+	}
 	if q.MinDriverRating < 0 || q.MinDriverRating > 5 {
 		return fmt.Errorf("min_driver_rating must be between 0 and 5")
 	}
@@ -214,6 +422,7 @@ func (q *SearchQuery) Validate() error {
 		"departure_time": true,
 		"rating":         true,
 		"popularity":     true,
+		"relevance":      true, // Boosted by driver responsiveness, completion rate and rating
 		// Backward compatibility shortcuts
 		"earliest":    true,
 		"cheapest":    true,
@@ -229,6 +438,24 @@ func (q *SearchQuery) Validate() error {
 		return fmt.Errorf("invalid sort_order: must be 'asc' or 'desc'")
 	}
 
+	// Validate the compound sort: whitelisted fields, each at most once
+	if len(q.Sort) > MaxSortCriteria {
+		return fmt.Errorf("sort cannot have more than %d fields", MaxSortCriteria)
+	}
+	seen := make(map[string]bool, len(q.Sort))
+	for _, criterion := range q.Sort {
+		if !SortableFields[criterion.Field] {
+			return fmt.Errorf("invalid sort field %q: must be one of price, departure_time, rating, popularity", criterion.Field)
+		}
+		if seen[criterion.Field] {
+			return fmt.Errorf("sort field %q repeated", criterion.Field)
+		}
+		seen[criterion.Field] = true
+		if criterion.Order != "" && !validSortOrders[criterion.Order] {
+			return fmt.Errorf("invalid sort order for %q: must be 'asc' or 'desc'", criterion.Field)
+		}
+	}
+
 	// Validate pagination
 	if q.Page < 0 {
 		return fmt.Errorf("page cannot be negative")
@@ -251,7 +478,7 @@ func (q *SearchQuery) SetDefaults() {
 	if q.Limit == 0 {
 		q.Limit = 20
 	}
-	if q.SortBy == "" {
+	if q.SortBy == "" && len(q.Sort) == 0 {
 		q.SortBy = "popularity" // Default to most popular
 	}
 }
@@ -0,0 +1,375 @@
+package domain
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func timePtr(t time.Time) *time.Time { return &t }
+
+func TestSearchQueryHash_EquivalentQueriesShareKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b SearchQuery
+	}{
+		{
+			name: "city case and accents",
+			a:    SearchQuery{Origin: &Location{City: "Córdoba"}, Destination: &Location{City: "San Martín"}},
+			b:    SearchQuery{Origin: &Location{City: "  CORDOBA "}, Destination: &Location{City: "san  martin"}},
+		},
+		{
+			name: "province case and accents",
+			a:    SearchQuery{Origin: &Location{City: "Neuquén", Province: "Neuquén"}},
+			b:    SearchQuery{Origin: &Location{City: "neuquen", Province: "NEUQUEN"}},
+		},
+		{
+			name: "city id case and spaces",
+			a:    SearchQuery{Origin: &Location{CityID: "ar-cordoba"}},
+			b:    SearchQuery{Origin: &Location{CityID: " AR-Cordoba "}},
+		},
+		{
+			name: "implicit vs explicit defaults",
+			a:    SearchQuery{},
+			b:    SearchQuery{Page: 1, Limit: 20, SortBy: "popularity", SortOrder: "asc"},
+		},
+		{
+			name: "compound sort order defaults to asc",
+			a:    SearchQuery{Sort: []SortCriterion{{Field: "price"}, {Field: "departure_time"}}},
+			b:    SearchQuery{Sort: []SortCriterion{{Field: "price", Order: "asc"}, {Field: "departure_time", Order: "asc"}}},
+		},
+		{
+			name: "compound sort replaces sort_by",
+			a:    SearchQuery{Sort: []SortCriterion{{Field: "price"}}, SortBy: "earliest"},
+			b:    SearchQuery{Sort: []SortCriterion{{Field: "price"}}},
+		},
+		{
+			name: "sort_order ignored by fixed-direction shortcuts",
+			a:    SearchQuery{SortBy: "cheapest", SortOrder: "asc"},
+			b:    SearchQuery{SortBy: "cheapest", SortOrder: "desc"},
+		},
+		{
+			name: "sort ignored by geospatial queries",
+			a: SearchQuery{
+				Origin:       &Location{Coordinates: NewGeoJSONPoint(-31.4201, -64.1888)},
+				OriginRadius: 10,
+				SortBy:       "price",
+				SortOrder:    "desc",
+			},
+			b: SearchQuery{
+				Origin:       &Location{Coordinates: NewGeoJSONPoint(-31.4201, -64.1888)},
+				OriginRadius: 10,
+			},
+		},
+		{
+			name: "coordinates without radius do not filter",
+			a:    SearchQuery{Origin: &Location{City: "Córdoba", Coordinates: NewGeoJSONPoint(-31.4201, -64.1888)}},
+			b:    SearchQuery{Origin: &Location{City: "Córdoba"}},
+		},
+		{
+			name: "coordinates below 6 decimals",
+			a: SearchQuery{
+				Origin:       &Location{Coordinates: NewGeoJSONPoint(-31.42010001, -64.18880004)},
+				OriginRadius: 10,
+			},
+			b: SearchQuery{
+				Origin:       &Location{Coordinates: NewGeoJSONPoint(-31.4201, -64.1888)},
+				OriginRadius: 10,
+			},
+		},
+		{
+			name: "address does not affect the key",
+			a:    SearchQuery{Origin: &Location{City: "Rosario", Address: "Bv. Oroño 100"}},
+			b:    SearchQuery{Origin: &Location{City: "Rosario"}},
+		},
+		{
+			name: "empty location is no location",
+			a:    SearchQuery{Origin: &Location{}},
+			b:    SearchQuery{},
+		},
+		{
+			name: "departure time within the same day",
+			a:    SearchQuery{DepartureDate: timePtr(time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC))},
+			b:    SearchQuery{DepartureDate: timePtr(time.Date(2025, 12, 15, 18, 30, 0, 0, time.UTC))},
+		},
+		{
+			name: "implicit vs explicit bookable",
+			a:    SearchQuery{},
+			b:    SearchQuery{Bookable: boolPtr(true)},
+		},
+		{
+			name: "free text case and accents",
+			a:    SearchQuery{SearchText: "Viaje  Económico"},
+			b:    SearchQuery{SearchText: "viaje economico"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.a.Hash(), tt.b.Hash())
+		})
+	}
+}
+
+func TestSearchQueryHash_DifferentQueriesDifferentKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b SearchQuery
+	}{
+		{
+			name: "different city",
+			a:    SearchQuery{Origin: &Location{City: "Córdoba"}},
+			b:    SearchQuery{Origin: &Location{City: "Rosario"}},
+		},
+		{
+			name: "different city id",
+			a:    SearchQuery{Origin: &Location{CityID: "ar-cordoba", City: "Córdoba"}},
+			b:    SearchQuery{Origin: &Location{CityID: "ar-rosario", City: "Córdoba"}},
+		},
+		{
+			name: "city id vs name only",
+			a:    SearchQuery{Destination: &Location{CityID: "ar-caba", City: "Buenos Aires"}},
+			b:    SearchQuery{Destination: &Location{City: "Buenos Aires"}},
+		},
+		{
+			name: "origin vs destination",
+			a:    SearchQuery{Origin: &Location{City: "Córdoba"}},
+			b:    SearchQuery{Destination: &Location{City: "Córdoba"}},
+		},
+		{
+			name: "different page",
+			a:    SearchQuery{Page: 1},
+			b:    SearchQuery{Page: 2},
+		},
+		{
+			name: "compound sort key order matters",
+			a:    SearchQuery{Sort: []SortCriterion{{Field: "price"}, {Field: "departure_time"}}},
+			b:    SearchQuery{Sort: []SortCriterion{{Field: "departure_time"}, {Field: "price"}}},
+		},
+		{
+			name: "sort_order matters for flexible sorts",
+			a:    SearchQuery{SortBy: "price", SortOrder: "asc"},
+			b:    SearchQuery{SortBy: "price", SortOrder: "desc"},
+		},
+		{
+			name: "unset vs false preference",
+			a:    SearchQuery{},
+			b:    SearchQuery{PetsAllowed: boolPtr(false)},
+		},
+		{
+			name: "non-bookable trips requested",
+			a:    SearchQuery{},
+			b:    SearchQuery{Bookable: boolPtr(false)},
+		},
+		{
+			name: "past trips requested",
+			a:    SearchQuery{},
+			b:    SearchQuery{IncludePast: true},
+		},
+		{
+			name: "different departure day",
+			a:    SearchQuery{DepartureDate: timePtr(time.Date(2025, 12, 15, 10, 0, 0, 0, time.UTC))},
+			b:    SearchQuery{DepartureDate: timePtr(time.Date(2025, 12, 16, 10, 0, 0, 0, time.UTC))},
+		},
+		{
+			name: "facets requested",
+			a:    SearchQuery{Origin: &Location{City: "Córdoba"}},
+			b:    SearchQuery{Origin: &Location{City: "Córdoba"}, Facets: true},
+		},
+		{
+			name: "price histogram requested",
+			a:    SearchQuery{Origin: &Location{City: "Córdoba"}},
+			b:    SearchQuery{Origin: &Location{City: "Córdoba"}, PriceHistogram: true},
+		},
+		{
+			name: "different min price",
+			a:    SearchQuery{MaxPrice: 10000},
+			b:    SearchQuery{MinPrice: 2000, MaxPrice: 10000},
+		},
+		{
+			name: "different radius",
+			a: SearchQuery{
+				Origin:       &Location{Coordinates: NewGeoJSONPoint(-31.4201, -64.1888)},
+				OriginRadius: 10,
+			},
+			b: SearchQuery{
+				Origin:       &Location{Coordinates: NewGeoJSONPoint(-31.4201, -64.1888)},
+				OriginRadius: 20,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotEqual(t, tt.a.Hash(), tt.b.Hash())
+		})
+	}
+}
+
+func TestSearchQueryHash_Stable(t *testing.T) {
+	query := SearchQuery{
+		Origin:        &Location{City: "Córdoba", Province: "Córdoba"},
+		Destination:   &Location{City: "Buenos Aires"},
+		DepartureDate: timePtr(time.Date(2025, 12, 15, 8, 0, 0, 0, time.UTC)),
+		MinSeats:      2,
+		MaxPrice:      15000,
+		PetsAllowed:   boolPtr(true),
+		SortBy:        "earliest",
+	}
+
+	first := query.Hash()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first, query.Hash())
+	}
+	assert.Len(t, first, 64)
+}
+
+func TestSearchQueryCanonical_DoesNotModifyQuery(t *testing.T) {
+	query := SearchQuery{Origin: &Location{City: "Córdoba"}, SortBy: "cheapest", SortOrder: "desc"}
+
+	canonical := query.Canonical()
+
+	assert.Equal(t, "cordoba", canonical.Origin.City)
+	assert.Equal(t, "Córdoba", query.Origin.City)
+	assert.Equal(t, "desc", query.SortOrder)
+	assert.Equal(t, 0, query.Page)
+}
+
+func TestNormalizeText(t *testing.T) {
+	assert.Equal(t, "cordoba capital", NormalizeText("  Córdoba   CAPITAL "))
+	assert.Equal(t, "neuquen", NormalizeText("NEUQUÉN"))
+	assert.Equal(t, "espana", NormalizeText("España"))
+	assert.Equal(t, "", NormalizeText("   "))
+}
+
+func TestAccentInsensitivePattern(t *testing.T) {
+	re := regexp.MustCompile("(?i)^" + AccentInsensitivePattern("cordoba"))
+
+	assert.True(t, re.MatchString("Córdoba"))
+	assert.True(t, re.MatchString("CORDOBA"))
+	assert.True(t, re.MatchString("cordoba capital"))
+	assert.False(t, re.MatchString("Rosario"))
+
+	// Regex metacharacters are escaped
+	assert.Equal(t, `s\.[aáàâäã]\.`, AccentInsensitivePattern("S.A."))
+}
+
+func TestIsBookable(t *testing.T) {
+	tests := []struct {
+		status string
+		seats  int
+		want   bool
+	}{
+		{TripStatusPublished, 2, true},
+		{TripStatusPublished, 0, false},
+		{TripStatusFull, 0, false},
+		{TripStatusClosed, 2, false},
+		{"in_progress", 2, false},
+		{"cancelled", 2, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsBookable(tt.status, tt.seats), "%s with %d seats", tt.status, tt.seats)
+	}
+}
+
+func TestSearchQueryDepartureRange(t *testing.T) {
+	now := time.Date(2025, 12, 15, 14, 30, 0, 0, time.UTC)
+	today := time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)
+	tomorrow := today.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		query    SearchQuery
+		from, to time.Time
+	}{
+		{"no date starts now", SearchQuery{}, now, time.Time{}},
+		{"no date with past trips is unbounded", SearchQuery{IncludePast: true}, time.Time{}, time.Time{}},
+		{"today starts now", SearchQuery{DepartureDate: timePtr(now)}, now, tomorrow},
+		{"today with past trips", SearchQuery{DepartureDate: timePtr(now), IncludePast: true}, today, tomorrow},
+		{"future day", SearchQuery{DepartureDate: timePtr(tomorrow.Add(9 * time.Hour))}, tomorrow, tomorrow.Add(24 * time.Hour)},
+		{"past day is empty", SearchQuery{DepartureDate: timePtr(today.Add(-24 * time.Hour))}, now, today},
+	}
+
+	for _, tt := range tests {
+		from, to := tt.query.DepartureRange(now)
+		assert.True(t, tt.from.Equal(from), "%s: from %s", tt.name, from)
+		assert.True(t, tt.to.Equal(to), "%s: to %s", tt.name, to)
+	}
+}
+
+func TestSearchQueryVisibleStatuses(t *testing.T) {
+	assert.Equal(t, VisibleTripStatuses, (&SearchQuery{}).VisibleStatuses())
+	assert.Equal(t,
+		[]string{TripStatusPublished, TripStatusFull, TripStatusClosed, TripStatusExpired},
+		(&SearchQuery{IncludePast: true}).VisibleStatuses())
+	assert.Len(t, VisibleTripStatuses, 3)
+}
+
+func TestParseSort(t *testing.T) {
+	sort, err := ParseSort("price:asc, Departure_Time")
+	assert.NoError(t, err)
+	assert.Equal(t, []SortCriterion{{Field: "price", Order: "asc"}, {Field: "departure_time"}}, sort)
+
+	sort, err = ParseSort("")
+	assert.NoError(t, err)
+	assert.Nil(t, sort)
+
+	_, err = ParseSort("price,,rating")
+	assert.Error(t, err)
+}
+
+func TestSearchQueryValidate_Sort(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    []SortCriterion
+		wantErr bool
+	}{
+		{"price then departure", []SortCriterion{{Field: "price", Order: "asc"}, {Field: "departure_time"}}, false},
+		{"rating desc", []SortCriterion{{Field: "rating", Order: "desc"}}, false},
+		{"field not whitelisted", []SortCriterion{{Field: "driver_id"}}, true},
+		{"shortcut not allowed", []SortCriterion{{Field: "cheapest"}}, true},
+		{"repeated field", []SortCriterion{{Field: "price"}, {Field: "price", Order: "desc"}}, true},
+		{"invalid order", []SortCriterion{{Field: "price", Order: "up"}}, true},
+		{"too many fields", []SortCriterion{{Field: "price"}, {Field: "departure_time"}, {Field: "rating"}, {Field: "popularity"}}, true},
+	}
+
+	for _, tt := range tests {
+		q := SearchQuery{Sort: tt.sort}
+		q.SetDefaults()
+		err := q.Validate()
+		assert.Equal(t, tt.wantErr, err != nil, "%s: %v", tt.name, err)
+	}
+}
+
+func TestSearchQuerySortCriteria(t *testing.T) {
+	q := SearchQuery{SortBy: "price", SortOrder: "desc"}
+	assert.Equal(t, []SortCriterion{{Field: "price", Order: "desc"}}, q.SortCriteria())
+
+	q.Sort = []SortCriterion{{Field: "price"}, {Field: "departure_time"}}
+	assert.Equal(t, q.Sort, q.SortCriteria())
+
+	assert.Nil(t, (&SearchQuery{}).SortCriteria())
+}
+
+func TestSearchQueryValidate_CityID(t *testing.T) {
+	assert.NoError(t, (&SearchQuery{Origin: &Location{CityID: "ar-cordoba"}}).Validate())
+	assert.NoError(t, (&SearchQuery{Destination: &Location{CityID: "ar-caba", City: "CABA"}}).Validate())
+	assert.Error(t, (&SearchQuery{Origin: &Location{Province: "Córdoba"}}).Validate())
+}
+
+func TestSearchTripApplyCityIDs(t *testing.T) {
+	trip := &SearchTrip{
+		Origin:      Location{City: "Córdoba", CityID: "ar-cordoba"},
+		Destination: Location{City: "CABA"},
+	}
+
+	trip.ApplyCityIDs(TripCityIDs{Origin: "ar-villa-maria", Destination: "AR-CABA"})
+
+	assert.Equal(t, "ar-cordoba", trip.Origin.CityID, "the trip fetched from trips-api wins")
+	assert.Equal(t, "ar-caba", trip.Destination.CityID)
+}
@@ -6,6 +6,27 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Trip statuses search-api reasons about (the rest are stored as received from trips-api)
+const (
+	TripStatusPublished = "published"
+	TripStatusFull      = "full"
+	TripStatusClosed    = "closed"
+
+	// TripStatusExpired is set by search-api itself (not trips-api) on visible trips whose
+	// departure already passed, see service.TripExpiryService
+	TripStatusExpired = "expired"
+)
+
+// VisibleTripStatuses are the statuses of upcoming trips that may appear in search results
+// when non-bookable trips are requested (bookable=false)
+var VisibleTripStatuses = []string{TripStatusPublished, TripStatusFull, TripStatusClosed}
+
+// IsBookable reports whether a trip accepts new reservations: published (trips-api moves it to
+// closed once the booking cutoff passes, and to in_progress at departure) and with free seats
+func IsBookable(status string, availableSeats int) bool {
+	return status == TripStatusPublished && availableSeats > 0
+}
+
 // SearchTrip represents a denormalized trip document stored in MongoDB for search purposes
 // This includes driver information and search-specific fields
 type SearchTrip struct {
@@ -22,6 +43,15 @@ type SearchTrip struct {
 	Origin      Location `json:"origin" bson:"origin"`
 	Destination Location `json:"destination" bson:"destination"`
 
+	// Driver-defined pickup points (optional)
+	PickupPoints []PickupPoint `json:"pickup_points,omitempty" bson:"pickup_points"`
+
+	// Multi-valued geo field (origin + pickup points) used for origin radius searches
+	PickupLocations []GeoJSONPoint `json:"-" bson:"pickup_locations"`
+
+	// Boarding point that matched an origin geo search (computed per query, never stored)
+	MatchedPickupPoint *PickupMatch `json:"matched_pickup_point,omitempty" bson:"-"`
+
 	// Trip timing
 	DepartureDatetime        time.Time `json:"departure_datetime" bson:"departure_datetime"`
 	EstimatedArrivalDatetime time.Time `json:"estimated_arrival_datetime" bson:"estimated_arrival_datetime"`
@@ -30,20 +60,48 @@ type SearchTrip struct {
 	PricePerSeat   float64 `json:"price_per_seat" bson:"price_per_seat"`
 	TotalSeats     int     `json:"total_seats" bson:"total_seats"`
 	AvailableSeats int     `json:"available_seats" bson:"available_seats"`
+	ReservedSeats  int     `json:"reserved_seats" bson:"reserved_seats"` // Confirmed bookings only (holds are not counted)
+
+	// Price before the last change (nil if the price never changed), from trips-api
+	PreviousPricePerSeat *float64   `json:"previous_price_per_seat,omitempty" bson:"previous_price_per_seat,omitempty"`
+	PriceChangedAt       *time.Time `json:"price_changed_at,omitempty" bson:"price_changed_at,omitempty"`
 
 	// Vehicle and preferences
 	Car         Car         `json:"car" bson:"car"`
 	Preferences Preferences `json:"preferences" bson:"preferences"`
 
 	// Trip details
-	Status      string `json:"status" bson:"status"` // published, full, in_progress, completed, cancelled
+	Status      string `json:"status" bson:"status"` // published, full, closed, in_progress, completed, cancelled, expired
 	Description string `json:"description" bson:"description"`
+	// Plain-text extraction of a rich-text description; search_text is built from it when present
+	DescriptionText string `json:"description_text,omitempty" bson:"description_text,omitempty"`
+
+	// Whether the trip accepts reservations right now (see IsBookable); a visible trip may not be
+	// bookable (full or closed before departure) and is shown greyed-out when clients ask for it
+	Bookable bool `json:"bookable" bson:"bookable"`
 
 	// Search-specific fields
 	SearchText      string  `json:"search_text,omitempty" bson:"search_text,omitempty"`           // Concatenated text for backup text search
 	PopularityScore float64 `json:"popularity_score,omitempty" bson:"popularity_score,omitempty"` // For ranking popular trips
 
+	// Badges computed at index time, and the seat velocity samples behind "filling fast"
+	Badges         TripBadges          `json:"badges" bson:"badges"`
+	RecentBookings []SeatBookingSample `json:"-" bson:"recent_bookings,omitempty"`
+
+	// Per-trip sequence of the last trips-api event applied (0 = none known); events with a
+	// sequence lower than or equal to it are stale and ignored
+	LastSequence int64 `json:"-" bson:"last_sequence,omitempty"`
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+
+	// When the expiry job marked the trip as expired (nil otherwise)
+	ExpiredAt *time.Time `json:"expired_at,omitempty" bson:"expired_at,omitempty"`
+}
+
+// SearchableDescription returns the description text to index: the plain-text extraction when
+// trips-api provided one, the raw description otherwise (trips created before rich text)
+func (t *SearchTrip) SearchableDescription() string {
+	return searchableDescription(t.DescriptionText, t.Description)
 }
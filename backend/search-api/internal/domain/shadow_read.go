@@ -0,0 +1,68 @@
+package domain
+
+// ShadowReadStats summarizes the shadow-read comparisons between Solr and MongoDB
+// A sampled percentage of searches runs both paths; only the primary result is served
+type ShadowReadStats struct {
+	SamplePercent    int     `json:"sample_percent"`
+	Compared         int64   `json:"compared"`          // Searches where both paths returned results
+	Failed           int64   `json:"failed"`            // Shadow searches that errored (not counted as compared)
+	Skipped          int64   `json:"skipped"`           // Sampled searches not shadowed because the in-flight limit was reached
+	SetDivergences   int64   `json:"set_divergences"`   // Different result ID sets
+	OrderDivergences int64   `json:"order_divergences"` // Same common IDs but in a different order
+	DivergenceRate   float64 `json:"divergence_rate"`   // (set + order divergences) / compared
+}
+
+// ShadowReadComparison is the outcome of comparing the result IDs of both search paths
+type ShadowReadComparison struct {
+	OnlyInSolr    []string `json:"only_in_solr,omitempty"`
+	OnlyInMongo   []string `json:"only_in_mongo,omitempty"`
+	SetDiverged   bool     `json:"set_diverged"`
+	OrderDiverged bool     `json:"order_diverged"`
+	SolrTotal     int64    `json:"solr_total"`
+	MongoTotal    int64    `json:"mongo_total"`
+}
+
+// Diverged reports whether the result sets or their ordering differ
+func (c ShadowReadComparison) Diverged() bool {
+	return c.SetDiverged || c.OrderDiverged
+}
+
+// CompareResultIDs compares the result IDs returned by Solr and MongoDB for the same query
+// Order is compared only over the IDs present in both lists
+func CompareResultIDs(solrIDs, mongoIDs []string) ShadowReadComparison {
+	inSolr := make(map[string]bool, len(solrIDs))
+	for _, id := range solrIDs {
+		inSolr[id] = true
+	}
+	inMongo := make(map[string]bool, len(mongoIDs))
+	for _, id := range mongoIDs {
+		inMongo[id] = true
+	}
+
+	var cmp ShadowReadComparison
+	var solrCommon, mongoCommon []string
+	for _, id := range solrIDs {
+		if inMongo[id] {
+			solrCommon = append(solrCommon, id)
+		} else {
+			cmp.OnlyInSolr = append(cmp.OnlyInSolr, id)
+		}
+	}
+	for _, id := range mongoIDs {
+		if inSolr[id] {
+			mongoCommon = append(mongoCommon, id)
+		} else {
+			cmp.OnlyInMongo = append(cmp.OnlyInMongo, id)
+		}
+	}
+
+	cmp.SetDiverged = len(cmp.OnlyInSolr) > 0 || len(cmp.OnlyInMongo) > 0
+	for i := range solrCommon {
+		if solrCommon[i] != mongoCommon[i] {
+			cmp.OrderDiverged = true
+			break
+		}
+	}
+
+	return cmp
+}
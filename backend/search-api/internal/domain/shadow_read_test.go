@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareResultIDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		solr, mongo []string
+		set, order  bool
+		onlySolr    []string
+		onlyMongo   []string
+	}{
+		{name: "identical", solr: []string{"a", "b", "c"}, mongo: []string{"a", "b", "c"}},
+		{name: "both empty"},
+		{name: "same set, different order", solr: []string{"a", "b", "c"}, mongo: []string{"c", "b", "a"}, order: true},
+		{name: "extra id on each side", solr: []string{"a", "b"}, mongo: []string{"a", "c"}, set: true, onlySolr: []string{"b"}, onlyMongo: []string{"c"}},
+		// Order is compared only over the common IDs: a missing one does not shift the rest
+		{name: "missing id keeps order", solr: []string{"a", "x", "b"}, mongo: []string{"a", "b"}, set: true, onlySolr: []string{"x"}},
+		{name: "set and order", solr: []string{"a", "b", "x"}, mongo: []string{"b", "a"}, set: true, order: true, onlySolr: []string{"x"}},
+		{name: "one side empty", solr: []string{"a"}, set: true, onlySolr: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmp := CompareResultIDs(tt.solr, tt.mongo)
+			assert.Equal(t, tt.set, cmp.SetDiverged)
+			assert.Equal(t, tt.order, cmp.OrderDiverged)
+			assert.Equal(t, tt.onlySolr, cmp.OnlyInSolr)
+			assert.Equal(t, tt.onlyMongo, cmp.OnlyInMongo)
+			assert.Equal(t, tt.set || tt.order, cmp.Diverged())
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SlowQuery records a search that exceeded the configured latency threshold
+// Stored in the capped collection "slow_queries": the oldest entries are
+// discarded automatically once the collection reaches its size limit
+type SlowQuery struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Source       string             `json:"source" bson:"source"` // solr, mongodb
+	DurationMs   int64              `json:"duration_ms" bson:"duration_ms"`
+	ThresholdMs  int64              `json:"threshold_ms" bson:"threshold_ms"`
+	QueryHash    string             `json:"query_hash" bson:"query_hash"`
+	Query        *SearchQuery       `json:"query" bson:"query"`
+	SolrQuery    string             `json:"solr_query,omitempty" bson:"solr_query,omitempty"`
+	SolrFilters  []string           `json:"solr_filters,omitempty" bson:"solr_filters,omitempty"`   // fq params as sent to Solr
+	MongoFilters []string           `json:"mongo_filters,omitempty" bson:"mongo_filters,omitempty"` // Extended JSON, one per phase (exact, partial)
+	HydrateMs    int64              `json:"hydrate_ms,omitempty" bson:"hydrate_ms,omitempty"`       // Solr searches: time fetching the page from MongoDB
+	ResultsCount int                `json:"results_count" bson:"results_count"`
+	Total        int64              `json:"total" bson:"total"`
+	RecordedAt   time.Time          `json:"recorded_at" bson:"recorded_at"`
+}
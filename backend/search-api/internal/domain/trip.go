@@ -18,6 +18,9 @@ type Trip struct {
 	Origin      TripLocation `json:"origin" bson:"origin"`
 	Destination TripLocation `json:"destination" bson:"destination"`
 
+	// Driver-defined pickup points (optional)
+	PickupPoints []TripPickupPoint `json:"pickup_points,omitempty" bson:"pickup_points,omitempty"`
+
 	// Trip timing
 	DepartureDatetime        time.Time `json:"departure_datetime" bson:"departure_datetime"`
 	EstimatedArrivalDatetime time.Time `json:"estimated_arrival_datetime" bson:"estimated_arrival_datetime"`
@@ -28,6 +31,10 @@ type Trip struct {
 	AvailableSeats int     `json:"available_seats" bson:"available_seats"`
 	ReservedSeats  int     `json:"reserved_seats" bson:"reserved_seats"`
 
+	// Price before the last change (absent if the price never changed)
+	PreviousPricePerSeat *float64   `json:"previous_price_per_seat,omitempty" bson:"previous_price_per_seat,omitempty"`
+	PriceChangedAt       *time.Time `json:"price_changed_at,omitempty" bson:"price_changed_at,omitempty"`
+
 	// Optimistic locking for concurrency control
 	AvailabilityVersion int `json:"availability_version" bson:"availability_version"`
 
@@ -36,8 +43,10 @@ type Trip struct {
 	Preferences Preferences `json:"preferences" bson:"preferences"`
 
 	// Trip details
-	Status      string `json:"status" bson:"status"` // draft, published, full, in_progress, completed, cancelled
+	Status      string `json:"status" bson:"status"` // draft, published, full, closed, in_progress, completed, cancelled
 	Description string `json:"description" bson:"description"`
+	// Plain-text extraction of a sanitized rich-text description (trips-api); empty on older trips
+	DescriptionText string `json:"description_text,omitempty" bson:"description_text,omitempty"`
 
 	// Cancellation info (optional)
 	CancelledAt        *time.Time `json:"cancelled_at,omitempty" bson:"cancelled_at,omitempty"`
@@ -52,6 +61,7 @@ type Trip struct {
 // TripLocation represents location data from trips-api (with simple lat/lng coordinates)
 type TripLocation struct {
 	City        string            `json:"city"`
+	CityID      string            `json:"city_id,omitempty"`
 	Province    string            `json:"province"`
 	Address     string            `json:"address"`
 	Coordinates SimpleCoordinates `json:"coordinates"`
@@ -67,31 +77,61 @@ type SimpleCoordinates struct {
 func (tl *TripLocation) ToLocation() Location {
 	return Location{
 		City:        tl.City,
+		CityID:      tl.CityID,
 		Province:    tl.Province,
 		Address:     tl.Address,
 		Coordinates: NewGeoJSONPoint(tl.Coordinates.Lat, tl.Coordinates.Lng),
 	}
 }
 
+// TripCityIDs are the canonical city IDs (trips-api city catalog) carried by trip.created events
+// Empty for free-text cities and in events from older publishers
+type TripCityIDs struct {
+	Origin      string
+	Destination string
+}
+
+// ApplyCityIDs fills the city IDs missing in the trip with the ones of the event
+// The trip fetched from trips-api wins when both are present
+func (st *SearchTrip) ApplyCityIDs(ids TripCityIDs) {
+	if st.Origin.CityID == "" {
+		st.Origin.CityID = NormalizeCityID(ids.Origin)
+	}
+	if st.Destination.CityID == "" {
+		st.Destination.CityID = NormalizeCityID(ids.Destination)
+	}
+}
+
 // ToSearchTrip converts Trip DTO to SearchTrip with driver information
 // Driver info must be fetched separately from users-api
 // This method converts simple lat/lng coordinates to GeoJSON format required by MongoDB
 func (t *Trip) ToSearchTrip(driver Driver) *SearchTrip {
+	origin := t.Origin.ToLocation()
+
+	pickupPoints := ToPickupPoints(t.PickupPoints)
+
 	return &SearchTrip{
 		TripID:                   t.ID.Hex(),
 		DriverID:                 t.DriverID,
 		Driver:                   driver,
-		Origin:                   t.Origin.ToLocation(),
+		Origin:                   origin,
 		Destination:              t.Destination.ToLocation(),
+		PickupPoints:             pickupPoints,
+		PickupLocations:          BuildPickupLocations(origin.Coordinates, pickupPoints),
 		DepartureDatetime:        t.DepartureDatetime,
 		EstimatedArrivalDatetime: t.EstimatedArrivalDatetime,
 		PricePerSeat:             t.PricePerSeat,
 		TotalSeats:               t.TotalSeats,
 		AvailableSeats:           t.AvailableSeats,
+		ReservedSeats:            t.ReservedSeats,
+		PreviousPricePerSeat:     t.PreviousPricePerSeat,
+		PriceChangedAt:           t.PriceChangedAt,
 		Car:                      t.Car,
 		Preferences:              t.Preferences,
 		Status:                   t.Status,
 		Description:              t.Description,
+		DescriptionText:          t.DescriptionText,
+		Bookable:                 IsBookable(t.Status, t.AvailableSeats),
 		SearchText:               buildSearchText(t, driver),
 		CreatedAt:                t.CreatedAt,
 		UpdatedAt:                t.UpdatedAt,
@@ -107,5 +147,13 @@ func buildSearchText(trip *Trip, driver Driver) string {
 		driver.Name + " " +
 		trip.Car.Brand + " " +
 		trip.Car.Model + " " +
-		trip.Description
+		searchableDescription(trip.DescriptionText, trip.Description)
+}
+
+// searchableDescription prefers the plain-text extraction so HTML or Markdown markup is not indexed
+func searchableDescription(text, description string) string {
+	if text != "" {
+		return text
+	}
+	return description
 }
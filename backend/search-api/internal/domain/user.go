@@ -48,3 +48,27 @@ func (u *User) ToDriver() Driver {
 		TotalTrips: u.TotalTripsAsDriver,
 	}
 }
+
+// SessionStatus is the account state users-api enforces on every request
+// Returned by users-api GET /internal/users/:id/session-status
+type SessionStatus struct {
+	UserID            int64      `json:"user_id"`
+	Active            bool       `json:"active"`
+	Banned            bool       `json:"banned"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at,omitempty"`
+}
+
+// CheckToken returns nil if a token issued at issuedAt (Unix seconds, "iat" claim) is still usable:
+// issued after the last session revocation, of an active account that is not banned
+func (s *SessionStatus) CheckToken(issuedAt int64) error {
+	if s.SessionsRevokedAt != nil && issuedAt < s.SessionsRevokedAt.Unix() {
+		return ErrSessionRevoked
+	}
+	if !s.Active {
+		return ErrAccountInactive
+	}
+	if s.Banned {
+		return ErrAccountSuspended
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionStatus_CheckToken(t *testing.T) {
+	revokedAt := time.Date(2025, 12, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   SessionStatus
+		issuedAt int64
+		want     error
+	}{
+		{"usable", SessionStatus{Active: true}, 0, nil},
+		{"issued after revocation", SessionStatus{Active: true, SessionsRevokedAt: &revokedAt}, revokedAt.Unix(), nil},
+		{"issued before revocation", SessionStatus{Active: true, SessionsRevokedAt: &revokedAt}, revokedAt.Unix() - 1, ErrSessionRevoked},
+		{"token without iat", SessionStatus{Active: true, SessionsRevokedAt: &revokedAt}, 0, ErrSessionRevoked},
+		{"deactivated", SessionStatus{Active: false}, revokedAt.Unix(), ErrAccountInactive},
+		{"banned", SessionStatus{Active: true, Banned: true}, revokedAt.Unix(), ErrAccountSuspended},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.status.CheckToken(tt.issuedAt))
+		})
+	}
+}
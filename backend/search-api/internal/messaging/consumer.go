@@ -4,30 +4,45 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
+	"search-api/internal/domain"
+	"search-api/internal/metrics"
 	"search-api/internal/service"
+	"search-api/internal/tracing"
 
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 )
 
+// RebuildFence reports whether cmd/rebuild is rewriting the read model
+type RebuildFence interface {
+	RebuildInProgress(ctx context.Context) bool
+}
+
 // Consumer handles RabbitMQ message consumption
 type Consumer struct {
 	conn            *amqp091.Connection
 	channel         *amqp091.Channel
 	queueName       string
 	eventService    *service.TripEventService
+	fence           RebuildFence
 	reconnectDelay  time.Duration
 	maxReconnectDelay time.Duration
 	stopChan        chan struct{}
+
+	connMu   sync.Mutex // Guards conn against reconnects while the health check reads it
+	progress consumerProgress
 }
 
 // NewConsumer creates a new RabbitMQ consumer
-func NewConsumer(rabbitmqURL, queueName string, eventService *service.TripEventService) (*Consumer, error) {
+// Trip events are held (not ACKed nor NACKed) while fence reports a rebuild in progress
+func NewConsumer(rabbitmqURL, queueName string, eventService *service.TripEventService, fence RebuildFence) (*Consumer, error) {
 	consumer := &Consumer{
 		queueName:       queueName,
 		eventService:    eventService,
+		fence:           fence,
 		reconnectDelay:  1 * time.Second,
 		maxReconnectDelay: 30 * time.Second,
 		stopChan:        make(chan struct{}),
@@ -107,6 +122,41 @@ func (c *Consumer) connect(rabbitmqURL string) error {
 		Str("routing_key", "trip.*").
 		Msg("Queue bound to exchange successfully")
 
+	// Driver rating changes published by users-api (same queue, so ordering and retries are shared)
+	err = channel.ExchangeDeclare(
+		"users.events", // name
+		"topic",        // type
+		true,           // durable
+		false,          // auto-deleted
+		false,          // internal
+		false,          // no-wait
+		nil,            // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return fmt.Errorf("exchange declaration failed: %w", err)
+	}
+
+	err = channel.QueueBind(
+		c.queueName,           // queue name
+		"user.rating_updated", // routing key
+		"users.events",        // exchange name
+		false,                 // no-wait
+		nil,                   // arguments
+	)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return fmt.Errorf("queue binding failed: %w", err)
+	}
+
+	log.Info().
+		Str("queue", c.queueName).
+		Str("exchange", "users.events").
+		Str("routing_key", "user.rating_updated").
+		Msg("Queue bound to exchange successfully")
+
 	// Set QoS - prefetch 1 message at a time for fair distribution
 	if err := channel.Qos(1, 0, false); err != nil {
 		channel.Close()
@@ -114,8 +164,10 @@ func (c *Consumer) connect(rabbitmqURL string) error {
 		return fmt.Errorf("qos setup failed: %w", err)
 	}
 
+	c.connMu.Lock()
 	c.conn = conn
 	c.channel = channel
+	c.connMu.Unlock()
 
 	log.Info().Str("queue", c.queueName).Msg("Connected to RabbitMQ successfully")
 
@@ -125,6 +177,7 @@ func (c *Consumer) connect(rabbitmqURL string) error {
 // Start begins consuming messages from RabbitMQ
 func (c *Consumer) Start(ctx context.Context, rabbitmqURL string) {
 	log.Info().Msg("Starting RabbitMQ consumer")
+	c.progress.start()
 
 	for {
 		select {
@@ -177,22 +230,58 @@ func (c *Consumer) consume(ctx context.Context) error {
 			if !ok {
 				return fmt.Errorf("message channel closed")
 			}
+			if !c.waitForRebuild(ctx) {
+				return nil
+			}
 			c.handleMessage(ctx, msg)
 		}
 	}
 }
 
+// waitForRebuild holds the delivered message while cmd/rebuild holds the fence, so events are not
+// applied to (nor overwritten by) a read model being rewritten; they are processed once it finishes
+// Returns false if ctx was cancelled while waiting (the unacked message is redelivered)
+func (c *Consumer) waitForRebuild(ctx context.Context) bool {
+	if c.fence == nil || !c.fence.RebuildInProgress(ctx) {
+		return true
+	}
+
+	log.Warn().Msg("Read model rebuild in progress, holding trip events until it finishes")
+	ticker := time.NewTicker(domain.RebuildFenceCheckInterval)
+	defer ticker.Stop()
+
+	for c.fence.RebuildInProgress(ctx) {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	log.Info().Msg("Read model rebuild finished, resuming trip events")
+	return true
+}
+
 // handleMessage processes a single message
 func (c *Consumer) handleMessage(ctx context.Context, msg amqp091.Delivery) {
+	// Continue the trace of the publisher (trips-api / users-api); the HTTP calls made
+	// while processing (users-api, trips-api, Solr) become children of this span
+	ctx, span := tracing.StartConsume(ctx, c.queueName, msg)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
 	// Parse the event type from message body
 	var baseEvent struct {
-		EventID   string `json:"event_id"`
-		EventType string `json:"event_type"`
+		EventID   string    `json:"event_id"`
+		EventType string    `json:"event_type"`
+		Timestamp time.Time `json:"timestamp"`
 	}
 
-	if err := json.Unmarshal(msg.Body, &baseEvent); err != nil {
+	if err = json.Unmarshal(msg.Body, &baseEvent); err != nil {
 		log.Error().Err(err).Bytes("body", msg.Body).Msg("Failed to parse message, NACKing without requeue")
 		msg.Nack(false, false) // Permanent error - bad message format
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeRejected)
+		c.progress.processed(time.Time{})
 		return
 	}
 
@@ -201,8 +290,6 @@ func (c *Consumer) handleMessage(ctx context.Context, msg amqp091.Delivery) {
 		Str("event_type", baseEvent.EventType).
 		Msg("Received message from RabbitMQ")
 
-	var err error
-
 	switch baseEvent.EventType {
 	case "trip.created":
 		err = c.handleTripCreated(ctx, msg.Body)
@@ -212,11 +299,15 @@ func (c *Consumer) handleMessage(ctx context.Context, msg amqp091.Delivery) {
 		err = c.handleTripCancelled(ctx, msg.Body)
 	case "trip.deleted":
 		err = c.handleTripDeleted(ctx, msg.Body)
+	case "user.rating_updated":
+		err = c.handleUserRatingUpdated(ctx, msg.Body)
 	default:
 		log.Warn().
 			Str("event_type", baseEvent.EventType).
 			Msg("Unknown event type, ACKing without processing")
 		msg.Ack(false)
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
+		c.progress.processed(baseEvent.Timestamp)
 		return
 	}
 
@@ -230,6 +321,7 @@ func (c *Consumer) handleMessage(ctx context.Context, msg amqp091.Delivery) {
 				Str("event_type", baseEvent.EventType).
 				Msg("Transient error processing message, NACKing with requeue")
 			msg.Nack(false, true) // Requeue for retry
+			metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeNack)
 		} else {
 			// Permanent error - ACK without processing to avoid blocking queue
 			log.Error().
@@ -238,6 +330,8 @@ func (c *Consumer) handleMessage(ctx context.Context, msg amqp091.Delivery) {
 				Str("event_type", baseEvent.EventType).
 				Msg("Permanent error processing message, ACKing without requeue")
 			msg.Ack(false)
+			metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
+			c.progress.processed(baseEvent.Timestamp)
 		}
 	} else {
 		// Success - ACK
@@ -246,6 +340,8 @@ func (c *Consumer) handleMessage(ctx context.Context, msg amqp091.Delivery) {
 			Str("event_type", baseEvent.EventType).
 			Msg("Message processed successfully, ACKing")
 		msg.Ack(false)
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
+		c.progress.processed(baseEvent.Timestamp)
 	}
 }
 
@@ -256,7 +352,7 @@ func (c *Consumer) handleTripCreated(ctx context.Context, body []byte) error {
 		return fmt.Errorf("unmarshal trip.created failed: %w", err)
 	}
 
-	return c.eventService.HandleTripCreated(ctx, event.EventID, event.TripID, event.DriverID)
+	return c.eventService.HandleTripCreated(ctx, event.EventID, event.TripID, event.DriverID, event.Driver, event.CityIDs(), event.Sequence)
 }
 
 // handleTripUpdated processes trip.updated events
@@ -266,7 +362,7 @@ func (c *Consumer) handleTripUpdated(ctx context.Context, body []byte) error {
 		return fmt.Errorf("unmarshal trip.updated failed: %w", err)
 	}
 
-	return c.eventService.HandleTripUpdated(ctx, event.EventID, event.TripID, event.AvailableSeats, event.ReservedSeats, event.Status)
+	return c.eventService.HandleTripUpdated(ctx, event.EventID, event.TripID, event.AvailableSeats, event.ReservedSeats, event.Status, event.PriceUpdate(), event.PickupPointsUpdate(), event.Timestamp, event.Sequence)
 }
 
 // handleTripCancelled processes trip.cancelled events
@@ -276,7 +372,7 @@ func (c *Consumer) handleTripCancelled(ctx context.Context, body []byte) error {
 		return fmt.Errorf("unmarshal trip.cancelled failed: %w", err)
 	}
 
-	return c.eventService.HandleTripCancelled(ctx, event.EventID, event.TripID, event.CancellationReason)
+	return c.eventService.HandleTripCancelled(ctx, event.EventID, event.TripID, event.CancellationReason, event.Sequence)
 }
 
 // handleTripDeleted processes trip.deleted events
@@ -289,6 +385,16 @@ func (c *Consumer) handleTripDeleted(ctx context.Context, body []byte) error {
 	return c.eventService.HandleTripDeleted(ctx, event.EventID, event.TripID, event.Reason)
 }
 
+// handleUserRatingUpdated processes user.rating_updated events from users-api
+func (c *Consumer) handleUserRatingUpdated(ctx context.Context, body []byte) error {
+	var event UserRatingUpdatedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return fmt.Errorf("unmarshal user.rating_updated failed: %w", err)
+	}
+
+	return c.eventService.HandleUserRatingUpdated(ctx, event.EventID, event.UserID, event.RoleRated, event.AvgDriverRating, event.TotalTripsDriver, event.Timestamp)
+}
+
 // reconnect handles reconnection with exponential backoff
 func (c *Consumer) reconnect(rabbitmqURL string) {
 	delay := c.reconnectDelay
@@ -0,0 +1,90 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"search-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// consumerProgress records when the consumer started and last finished a message (GET /health lag)
+type consumerProgress struct {
+	mu              sync.Mutex
+	startedAt       time.Time
+	lastProcessedAt time.Time
+	lastEventAt     time.Time
+}
+
+func (p *consumerProgress) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.startedAt.IsZero() {
+		p.startedAt = time.Now()
+	}
+}
+
+// processed is called once a message leaves the queue for good (acked or rejected, not requeued)
+// eventAt is the publisher timestamp of the event (zero if the message had none)
+func (p *consumerProgress) processed(eventAt time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastProcessedAt = time.Now()
+	p.lastEventAt = eventAt
+}
+
+// Stats reports the consumer progress and the number of messages waiting in its queue
+func (c *Consumer) Stats(ctx context.Context) domain.ConsumerStats {
+	c.progress.mu.Lock()
+	stats := domain.ConsumerStats{
+		Consuming:       !c.progress.startedAt.IsZero(),
+		StartedAt:       c.progress.startedAt,
+		LastProcessedAt: c.progress.lastProcessedAt,
+		LastEventAt:     c.progress.lastEventAt,
+	}
+	c.progress.mu.Unlock()
+
+	stats.PendingMessages = c.pendingMessages(ctx)
+	return stats
+}
+
+// pendingMessages asks the broker for the ready messages of the queue (nil if it cannot be asked)
+// A separate channel is used: a failed passive declare closes the channel it runs on
+func (c *Consumer) pendingMessages(ctx context.Context) *int {
+	c.connMu.Lock()
+	conn := c.conn
+	c.connMu.Unlock()
+	if conn == nil || conn.IsClosed() {
+		return nil
+	}
+
+	type result struct {
+		messages int
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		channel, err := conn.Channel()
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		defer channel.Close()
+
+		queue, err := channel.QueueDeclarePassive(c.queueName, true, false, false, false, nil)
+		done <- result{messages: queue.Messages, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			log.Warn().Err(r.err).Str("queue", c.queueName).Msg("Failed to inspect queue depth")
+			return nil
+		}
+		return &r.messages
+	case <-ctx.Done():
+		return nil
+	}
+}
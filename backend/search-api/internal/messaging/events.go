@@ -1,6 +1,10 @@
 package messaging
 
-import "time"
+import (
+	"time"
+
+	"search-api/internal/domain"
+)
 
 // TripCreatedEvent represents a trip creation event from trips-api
 type TripCreatedEvent struct {
@@ -10,10 +14,21 @@ type TripCreatedEvent struct {
 	DriverID          int64     `json:"driver_id"`
 	OriginCity        string    `json:"origin_city"`
 	DestinationCity   string    `json:"destination_city"`
+	OriginCityID      string    `json:"origin_city_id,omitempty"`      // Canonical city ID (absent for free-text cities)
+	DestinationCityID string    `json:"destination_city_id,omitempty"` // Canonical city ID (absent for free-text cities)
 	DepartureDatetime time.Time `json:"departure_datetime"`
 	AvailableSeats    int       `json:"available_seats"`
 	Status            string    `json:"status"`
 	Timestamp         time.Time `json:"timestamp"`
+	Sequence          int64     `json:"sequence,omitempty"` // Per-trip sequence (absent in events from older publishers)
+
+	// Driver snapshot embedded by trips-api (absent in events from older publishers)
+	Driver *domain.DriverSnapshot `json:"driver,omitempty"`
+}
+
+// CityIDs returns the canonical city IDs carried by the event (empty when it has none)
+func (e TripCreatedEvent) CityIDs() domain.TripCityIDs {
+	return domain.TripCityIDs{Origin: e.OriginCityID, Destination: e.DestinationCityID}
 }
 
 // TripUpdatedEvent represents a trip update event from trips-api
@@ -25,6 +40,38 @@ type TripUpdatedEvent struct {
 	ReservedSeats  int       `json:"reserved_seats"`
 	Status         string    `json:"status"`
 	Timestamp      time.Time `json:"timestamp"`
+	Sequence       int64     `json:"sequence,omitempty"` // Per-trip sequence (absent in events from older publishers)
+
+	// Price of the trip (absent in events from older publishers); the previous price is only
+	// present once the price changed
+	PricePerSeat         *float64   `json:"price_per_seat,omitempty"`
+	PreviousPricePerSeat *float64   `json:"previous_price_per_seat,omitempty"`
+	PriceChangedAt       *time.Time `json:"price_changed_at,omitempty"`
+
+	// Current pickup points of the trip ([] once the driver removed them all; absent in events
+	// from older publishers)
+	PickupPoints *[]domain.TripPickupPoint `json:"pickup_points,omitempty"`
+}
+
+// PickupPointsUpdate returns the pickup points carried by the event in the storage format,
+// or nil if the event has none (an empty, non-nil slice clears the stored points)
+func (e TripUpdatedEvent) PickupPointsUpdate() []domain.PickupPoint {
+	if e.PickupPoints == nil {
+		return nil
+	}
+	return domain.ToPickupPoints(*e.PickupPoints)
+}
+
+// PriceUpdate returns the price carried by the event, or nil if it has none
+func (e TripUpdatedEvent) PriceUpdate() *domain.TripPriceUpdate {
+	if e.PricePerSeat == nil {
+		return nil
+	}
+	return &domain.TripPriceUpdate{
+		PricePerSeat:         *e.PricePerSeat,
+		PreviousPricePerSeat: e.PreviousPricePerSeat,
+		ChangedAt:            e.PriceChangedAt,
+	}
 }
 
 // TripCancelledEvent represents a trip cancellation event from trips-api
@@ -34,6 +81,7 @@ type TripCancelledEvent struct {
 	TripID             string    `json:"trip_id"`
 	CancellationReason string    `json:"cancellation_reason"`
 	Timestamp          time.Time `json:"timestamp"`
+	Sequence           int64     `json:"sequence,omitempty"` // Per-trip sequence (absent in events from older publishers)
 }
 
 // TripDeletedEvent represents a trip deletion event from trips-api
@@ -45,3 +93,17 @@ type TripDeletedEvent struct {
 	Reason    string    `json:"reason"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+// UserRatingUpdatedEvent is published by users-api on users.events when a user's rating averages change
+type UserRatingUpdatedEvent struct {
+	EventID             string    `json:"event_id"`
+	EventType           string    `json:"event_type"`
+	UserID              int64     `json:"user_id"`
+	RatingID            int64     `json:"rating_id"`
+	RoleRated           string    `json:"role_rated"`
+	AvgDriverRating     float64   `json:"avg_driver_rating"`
+	AvgPassengerRating  float64   `json:"avg_passenger_rating"`
+	TotalTripsDriver    int       `json:"total_trips_driver"`
+	TotalTripsPassenger int       `json:"total_trips_passenger"`
+	Timestamp           time.Time `json:"timestamp"`
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"search-api/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheLookupsDesc = prometheus.NewDesc(
+		"search_cache_lookups_total",
+		"Search result cache lookups by result (hit, stale_hit, miss)",
+		[]string{"result"}, nil,
+	)
+	cacheCoalescedDesc = prometheus.NewDesc(
+		"search_cache_coalesced_total",
+		"Misses that waited for an identical in-flight computation instead of querying again",
+		nil, nil,
+	)
+	cacheRefreshesDesc = prometheus.NewDesc(
+		"search_cache_refreshes_total",
+		"Background refreshes of stale search results by result (ok, error)",
+		[]string{"result"}, nil,
+	)
+	cacheHitRateDesc = prometheus.NewDesc(
+		"search_cache_hit_rate",
+		"(hits + stale hits) / all lookups since startup",
+		nil, nil,
+	)
+)
+
+// CacheCollector exports the search cache counters (the same snapshot as GET /admin/cache)
+type CacheCollector struct {
+	stats func() domain.CacheStats
+}
+
+// NewCacheCollector creates a collector reading the counters from stats on every scrape
+func NewCacheCollector(stats func() domain.CacheStats) *CacheCollector {
+	return &CacheCollector{stats: stats}
+}
+
+// Describe implements prometheus.Collector
+func (c *CacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheLookupsDesc
+	ch <- cacheCoalescedDesc
+	ch <- cacheRefreshesDesc
+	ch <- cacheHitRateDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *CacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(stats.Hits), "hit")
+	ch <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(stats.StaleHits), "stale_hit")
+	ch <- prometheus.MustNewConstMetric(cacheLookupsDesc, prometheus.CounterValue, float64(stats.Misses), "miss")
+	ch <- prometheus.MustNewConstMetric(cacheCoalescedDesc, prometheus.CounterValue, float64(stats.Coalesced))
+	ch <- prometheus.MustNewConstMetric(cacheRefreshesDesc, prometheus.CounterValue, float64(stats.Refreshes-stats.RefreshFailures), "ok")
+	ch <- prometheus.MustNewConstMetric(cacheRefreshesDesc, prometheus.CounterValue, float64(stats.RefreshFailures), "error")
+	ch <- prometheus.MustNewConstMetric(cacheHitRateDesc, prometheus.GaugeValue, stats.HitRate)
+}
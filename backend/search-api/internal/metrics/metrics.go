@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Consume outcomes of a RabbitMQ message
+const (
+	ConsumeAck      = "ack"      // Processed, skipped (unknown type) or permanently failed, and acknowledged
+	ConsumeNack     = "nack"     // Transient failure, requeued
+	ConsumeRejected = "rejected" // Unparseable body, dropped without requeue
+)
+
+// unmatchedRoute groups requests that match no route, so unknown paths do not create series
+const unmatchedRoute = "unmatched"
+
+// Prometheus metrics of the service, exposed on GET /metrics
+// Names are shared by the four services; Prometheus tells them apart by job
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by route",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	rabbitConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_messages_consumed_total",
+		Help: "Messages consumed from RabbitMQ by routing key and outcome",
+	}, []string{"routing_key", "result"})
+
+	dbCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_call_duration_seconds",
+		Help:    "Duration of MongoDB commands by operation",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	dbCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_call_errors_total",
+		Help: "Failed MongoDB commands by operation",
+	}, []string{"operation"})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Requests rejected with 429 by route and limited subject (ip, user)",
+	}, []string{"route", "subject"})
+)
+
+// Middleware records the duration of every request labeled by route template (/api/v1/trips/:id),
+// never the concrete path, so there is no series per trip
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		httpRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// ObserveConsume counts a consumed RabbitMQ message with its outcome (ConsumeAck, ConsumeNack, ConsumeRejected)
+func ObserveConsume(routingKey, outcome string) {
+	rabbitConsumed.WithLabelValues(routingKey, outcome).Inc()
+}
+
+// ObserveRateLimited counts a request rejected by the rate limiter (subject: ip or user)
+func ObserveRateLimited(route, subject string) {
+	rateLimited.WithLabelValues(route, subject).Inc()
+}
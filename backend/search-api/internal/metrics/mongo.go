@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor records the duration of every MongoDB command (find, insert, update, aggregate...)
+// Pass it to the client with options.Client().SetMonitor
+func MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			dbCallDuration.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			dbCallDuration.WithLabelValues(e.CommandName).Observe(e.Duration.Seconds())
+			dbCallErrors.WithLabelValues(e.CommandName).Inc()
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"search-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// adminRole is the users-api role allowed on the /admin endpoints
+const adminRole = "admin"
+
+// SessionChecker checks a token against the account state in users-api
+// (revoked sessions, deactivated or banned accounts), which the signature alone does not reflect
+type SessionChecker interface {
+	CheckSession(ctx context.Context, userID int64, issuedAt int64) error
+}
+
+// RequireAdmin only lets through requests with a valid users-api JWT (HS256) of an admin
+// whose account is still usable. Missing, invalid or revoked tokens get 401,
+// tokens of other roles or of deactivated/banned accounts 403, and 503 when users-api cannot be reached
+func RequireAdmin(jwtSecret string, sessions SessionChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := parseBearerToken(c.GetHeader("Authorization"), jwtSecret)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "UNAUTHORIZED",
+					"message": "A valid Bearer token is required",
+				},
+			})
+			return
+		}
+
+		if role, _ := claims["role"].(string); role != adminRole {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    "FORBIDDEN",
+					"message": "Admin role required",
+				},
+			})
+			return
+		}
+
+		userID, _ := claims["user_id"].(float64)
+		issuedAt, _ := claims["iat"].(float64) // Tokens without iat count as issued at the epoch
+		if err := sessions.CheckSession(c.Request.Context(), int64(userID), int64(issuedAt)); err != nil {
+			status, code := sessionErrorStatus(err)
+			c.AbortWithStatusJSON(status, gin.H{
+				"success": false,
+				"error": gin.H{
+					"code":    code,
+					"message": err.Error(),
+				},
+			})
+			return
+		}
+
+		c.Set("user_id", claims["user_id"])
+		c.Set("role", adminRole)
+		c.Next()
+	}
+}
+
+// sessionErrorStatus maps a SessionChecker error to its HTTP status and error code
+func sessionErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrSessionRevoked):
+		return http.StatusUnauthorized, domain.ErrSessionRevoked.Code
+	case errors.Is(err, domain.ErrAccountInactive):
+		return http.StatusForbidden, domain.ErrAccountInactive.Code
+	case errors.Is(err, domain.ErrAccountSuspended):
+		return http.StatusForbidden, domain.ErrAccountSuspended.Code
+	default:
+		return http.StatusServiceUnavailable, domain.ErrServiceUnavailable.Code
+	}
+}
+
+// parseBearerToken validates a "Bearer <JWT>" header signed by users-api and returns its claims
+func parseBearerToken(header, secret string) (jwt.MapClaims, bool) {
+	tokenString, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || tokenString == "" || secret == "" {
+		return nil, false
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, false
+	}
+	return claims, true
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"search-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tokenWithRole(t *testing.T, secret, role string) string {
+	return adminToken(t, secret, role, 7)
+}
+
+func adminToken(t *testing.T, secret, role string, userID int64) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"role":    role,
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+// fakeSessions fails the session check of the users in errs
+type fakeSessions struct {
+	errs map[int64]error
+}
+
+func (f fakeSessions) CheckSession(_ context.Context, userID int64, _ int64) error {
+	return f.errs[userID]
+}
+
+func TestRequireAdmin(t *testing.T) {
+	router := gin.New()
+	admin := router.Group("/admin")
+	admin.Use(RequireAdmin(testJWTSecret, fakeSessions{errs: map[int64]error{
+		8:  domain.ErrSessionRevoked,
+		9:  domain.ErrAccountSuspended,
+		10: domain.ErrAccountInactive,
+		11: domain.ErrServiceUnavailable,
+	}}))
+	admin.POST("/reindex", func(c *gin.Context) {
+		c.JSON(http.StatusAccepted, gin.H{"success": true})
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"not bearer", "Basic abc", http.StatusUnauthorized},
+		{"wrong secret", "Bearer " + tokenWithRole(t, "other-secret", "admin"), http.StatusUnauthorized},
+		{"user role", "Bearer " + tokenWithRole(t, testJWTSecret, "user"), http.StatusForbidden},
+		{"no role", "Bearer " + signedToken(t, testJWTSecret, 7), http.StatusForbidden},
+		{"admin", "Bearer " + tokenWithRole(t, testJWTSecret, "admin"), http.StatusAccepted},
+		{"revoked session", "Bearer " + adminToken(t, testJWTSecret, "admin", 8), http.StatusUnauthorized},
+		{"banned admin", "Bearer " + adminToken(t, testJWTSecret, "admin", 9), http.StatusForbidden},
+		{"deactivated admin", "Bearer " + adminToken(t, testJWTSecret, "admin", 10), http.StatusForbidden},
+		{"users-api down", "Bearer " + adminToken(t, testJWTSecret, "admin", 11), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/admin/reindex", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestRequireAdmin_NoSecret(t *testing.T) {
+	router := gin.New()
+	router.GET("/admin/cache", RequireAdmin("", fakeSessions{}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/admin/cache", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenWithRole(t, "", "admin"))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -74,6 +74,10 @@ func mapErrorCodeToHTTPStatus(code string) int {
 	case "UNAUTHORIZED":
 		return http.StatusUnauthorized
 
+	// 409 Conflict
+	case "REINDEX_IN_PROGRESS":
+		return http.StatusConflict
+
 	// 503 Service Unavailable - infrastructure errors
 	case "SOLR_UNAVAILABLE", "SERVICE_UNAVAILABLE":
 		return http.StatusServiceUnavailable
@@ -143,6 +143,7 @@ func TestMapErrorCodeToHTTPStatus(t *testing.T) {
 		{"InvalidGeoCoords", "INVALID_GEO_COORDS", http.StatusBadRequest},
 		{"InvalidInput", "INVALID_INPUT", http.StatusBadRequest},
 		{"Unauthorized", "UNAUTHORIZED", http.StatusUnauthorized},
+		{"ReindexInProgress", "REINDEX_IN_PROGRESS", http.StatusConflict},
 		{"SolrUnavailable", "SOLR_UNAVAILABLE", http.StatusServiceUnavailable},
 		{"ServiceUnavailable", "SERVICE_UNAVAILABLE", http.StatusServiceUnavailable},
 		{"UnknownError", "UNKNOWN_ERROR", http.StatusInternalServerError},
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"search-api/internal/cache"
+	"search-api/internal/domain"
+	"search-api/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Subjects a request is rate limited by
+const (
+	rateLimitSubjectIP   = "ip"
+	rateLimitSubjectUser = "user"
+)
+
+// localBucketsSweepSize is the number of in-memory buckets above which full ones are dropped
+const localBucketsSweepSize = 10000
+
+// RateLimitConfig configures RateLimit
+type RateLimitConfig struct {
+	PerIP domain.RateLimit
+	// Applied instead of PerIP to requests with a valid JWT (disabled: limited per IP like everyone)
+	PerUser domain.RateLimit
+	// Secret the users-api JWTs are signed with (HS256)
+	JWTSecret string
+}
+
+// RateLimit limits requests with a token bucket per client IP, or per user when the request
+// carries a valid JWT (an invalid one is limited per IP, the endpoints stay public)
+//
+// Buckets live in the shared cache when its backend supports atomic updates (memcached, redis),
+// so the limit holds across instances. Without one, or while the cache fails, each instance keeps
+// its own buckets in memory. Rejected requests get 429 with Retry-After.
+func RateLimit(store cache.Cache, cfg RateLimitConfig) gin.HandlerFunc {
+	limiter := &rateLimiter{
+		cfg:   cfg,
+		local: make(map[string]domain.TokenBucket),
+	}
+	if updater, ok := store.(cache.Updater); ok {
+		limiter.shared = updater
+	}
+
+	return func(c *gin.Context) {
+		subject, id, limit := limiter.identify(c)
+		if !limit.Enabled() {
+			c.Next()
+			return
+		}
+
+		decision := limiter.take(c.Request.Context(), "ratelimit:"+subject+":"+id, limit, time.Now())
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(int(limit.Capacity())))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		if decision.Allowed {
+			c.Next()
+			return
+		}
+
+		retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		metrics.ObserveRateLimited(c.FullPath(), subject)
+
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "RATE_LIMITED",
+				"message": fmt.Sprintf("Too many requests, retry in %d seconds", retryAfter),
+			},
+		})
+	}
+}
+
+type rateLimiter struct {
+	cfg    RateLimitConfig
+	shared cache.Updater // nil: only in-memory buckets
+
+	mu    sync.Mutex
+	local map[string]domain.TokenBucket
+}
+
+// identify returns who the request is limited as and the limit that applies
+func (l *rateLimiter) identify(c *gin.Context) (subject, id string, limit domain.RateLimit) {
+	if l.cfg.PerUser.Enabled() {
+		if userID, ok := l.userID(c.GetHeader("Authorization")); ok {
+			return rateLimitSubjectUser, userID, l.cfg.PerUser
+		}
+	}
+	return rateLimitSubjectIP, c.ClientIP(), l.cfg.PerIP
+}
+
+// userID extracts the user_id claim of a valid "Bearer <JWT>" header
+func (l *rateLimiter) userID(header string) (string, bool) {
+	claims, ok := parseBearerToken(header, l.cfg.JWTSecret)
+	if !ok {
+		return "", false
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return "", false
+	}
+	return strconv.FormatInt(int64(userID), 10), true
+}
+
+// take takes a token from the shared bucket, falling back to the in-memory one if the cache fails
+func (l *rateLimiter) take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) domain.RateDecision {
+	if l.shared != nil {
+		var decision domain.RateDecision
+		err := l.shared.Update(ctx, key, func(current string, found bool) (string, time.Duration) {
+			bucket := domain.NewTokenBucket(limit, now)
+			if found {
+				if stored, err := domain.DecodeTokenBucket(current); err == nil {
+					bucket = stored
+				}
+			}
+			bucket, decision = bucket.Take(limit, now)
+			return bucket.Encode(), bucket.TTL(limit)
+		})
+		if err == nil {
+			return decision
+		}
+		log.Warn().Err(err).Str("key", key).Msg("Rate limit cache failed, using in-memory bucket")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, found := l.local[key]
+	if !found {
+		bucket = domain.NewTokenBucket(limit, now)
+	}
+	bucket, decision := bucket.Take(limit, now)
+	l.local[key] = bucket
+
+	if len(l.local) > localBucketsSweepSize {
+		l.sweep(now)
+	}
+	return decision
+}
+
+// sweep drops the in-memory buckets that are full again (keeping them changes nothing)
+func (l *rateLimiter) sweep(now time.Time) {
+	for key, bucket := range l.local {
+		limit := l.cfg.PerIP
+		if strings.HasPrefix(key, "ratelimit:"+rateLimitSubjectUser+":") {
+			limit = l.cfg.PerUser
+		}
+		if now.Sub(bucket.UpdatedAt) >= bucket.TTL(limit) {
+			delete(l.local, key)
+		}
+	}
+}
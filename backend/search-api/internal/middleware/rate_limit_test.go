@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"search-api/internal/cache"
+	"search-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret"
+
+func rateLimitedRouter(cfg RateLimitConfig) *gin.Engine {
+	router := gin.New()
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	v1 := router.Group("/api/v1")
+	v1.Use(RateLimit(cache.NewNoopCache(), cfg))
+	v1.GET("/search/trips", func(c *gin.Context) {
+		c.JSON(200, gin.H{"results": []string{}})
+	})
+	return router
+}
+
+func doRequest(router *gin.Engine, path, ip, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", path, nil)
+	req.RemoteAddr = ip + ":1234"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func signedToken(t *testing.T, secret string, userID int64) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestRateLimit_PerIP(t *testing.T) {
+	router := rateLimitedRouter(RateLimitConfig{
+		PerIP: domain.RateLimit{Requests: 60, Per: time.Minute, Burst: 2},
+	})
+
+	assert.Equal(t, 200, doRequest(router, "/api/v1/search/trips", "10.0.0.1", "").Code)
+	w := doRequest(router, "/api/v1/search/trips", "10.0.0.1", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = doRequest(router, "/api/v1/search/trips", "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "RATE_LIMITED")
+
+	assert.Equal(t, 200, doRequest(router, "/api/v1/search/trips", "10.0.0.2", "").Code, "other IPs have their own bucket")
+	assert.Equal(t, 200, doRequest(router, "/health", "10.0.0.1", "").Code, "/health is not limited")
+}
+
+func TestRateLimit_PerUser(t *testing.T) {
+	router := rateLimitedRouter(RateLimitConfig{
+		PerIP:     domain.RateLimit{Requests: 60, Per: time.Minute, Burst: 1},
+		PerUser:   domain.RateLimit{Requests: 60, Per: time.Minute, Burst: 3},
+		JWTSecret: testJWTSecret,
+	})
+	token := signedToken(t, testJWTSecret, 42)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, 200, doRequest(router, "/api/v1/search/trips", "10.0.0.1", token).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, "/api/v1/search/trips", "10.0.0.1", token).Code)
+
+	// The IP bucket was not used by the authenticated requests
+	assert.Equal(t, 200, doRequest(router, "/api/v1/search/trips", "10.0.0.1", "").Code)
+
+	// A token with another signature is limited per IP
+	forged := signedToken(t, "other-secret", 42)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, "/api/v1/search/trips", "10.0.0.1", forged).Code)
+}
+
+func TestRateLimit_Disabled(t *testing.T) {
+	router := rateLimitedRouter(RateLimitConfig{})
+
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, 200, doRequest(router, "/api/v1/search/trips", "10.0.0.1", "").Code)
+	}
+}
@@ -13,9 +13,9 @@ type MockTripRepository struct {
 	FindByTripIDFunc               func(ctx context.Context, tripID string) (*domain.SearchTrip, error)
 	UpdateFunc                     func(ctx context.Context, trip *domain.SearchTrip) error
 	UpdateStatusFunc               func(ctx context.Context, id string, status string) error
-	UpdateStatusByTripIDFunc       func(ctx context.Context, tripID string, status string) error
+	UpdateStatusByTripIDFunc       func(ctx context.Context, tripID string, status string, sequence int64) error
 	UpdateAvailabilityFunc         func(ctx context.Context, id string, availableSeats int) error
-	UpdateAvailabilityByTripIDFunc func(ctx context.Context, tripID string, availableSeats, reservedSeats int, status string) error
+	UpdateAvailabilityByTripIDFunc func(ctx context.Context, tripID string, availableSeats, reservedSeats int, status string, pickupPoints []domain.PickupPoint, pickupLocations []domain.GeoJSONPoint, sequence int64) error
 	DeleteByTripIDFunc             func(ctx context.Context, tripID string) error
 	SearchFunc                     func(ctx context.Context, filters map[string]interface{}, page, limit int) ([]*domain.SearchTrip, int64, error)
 	SearchByLocationFunc           func(ctx context.Context, lat, lng float64, radiusKm int, additionalFilters map[string]interface{}) ([]*domain.SearchTrip, error)
@@ -63,9 +63,9 @@ func (m *MockTripRepository) UpdateStatus(ctx context.Context, id string, status
 }
 
 // UpdateStatusByTripID calls the mocked UpdateStatusByTripIDFunc
-func (m *MockTripRepository) UpdateStatusByTripID(ctx context.Context, tripID string, status string) error {
+func (m *MockTripRepository) UpdateStatusByTripID(ctx context.Context, tripID string, status string, sequence int64) error {
 	if m.UpdateStatusByTripIDFunc != nil {
-		return m.UpdateStatusByTripIDFunc(ctx, tripID, status)
+		return m.UpdateStatusByTripIDFunc(ctx, tripID, status, sequence)
 	}
 	return nil
 }
@@ -79,9 +79,9 @@ func (m *MockTripRepository) UpdateAvailability(ctx context.Context, id string,
 }
 
 // UpdateAvailabilityByTripID calls the mocked UpdateAvailabilityByTripIDFunc
-func (m *MockTripRepository) UpdateAvailabilityByTripID(ctx context.Context, tripID string, availableSeats, reservedSeats int, status string) error {
+func (m *MockTripRepository) UpdateAvailabilityByTripID(ctx context.Context, tripID string, availableSeats, reservedSeats int, status string, pickupPoints []domain.PickupPoint, pickupLocations []domain.GeoJSONPoint, sequence int64) error {
 	if m.UpdateAvailabilityByTripIDFunc != nil {
-		return m.UpdateAvailabilityByTripIDFunc(ctx, tripID, availableSeats, reservedSeats, status)
+		return m.UpdateAvailabilityByTripIDFunc(ctx, tripID, availableSeats, reservedSeats, status, pickupPoints, pickupLocations, sequence)
 	}
 	return nil
 }
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"search-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EventArchiveReader reads the trips-api event archive (read-only, used by the rebuild command)
+type EventArchiveReader interface {
+	FindFromSequence(ctx context.Context, fromSequence int64, limit int) ([]domain.ArchivedEvent, error)
+}
+
+type eventArchiveReader struct {
+	collection *mongo.Collection
+}
+
+// NewEventArchiveReader creates a reader over the event_archive collection of the trips-api database
+func NewEventArchiveReader(tripsDB *mongo.Database) EventArchiveReader {
+	return &eventArchiveReader{
+		collection: tripsDB.Collection("event_archive"),
+	}
+}
+
+// FindFromSequence returns up to limit archived events with sequence >= fromSequence, ordered by sequence
+func (r *eventArchiveReader) FindFromSequence(ctx context.Context, fromSequence int64, limit int) ([]domain.ArchivedEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "sequence", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"sequence": bson.M{"$gte": fromSequence}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event archive: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []domain.ArchivedEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode archived events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"search-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexMetadataID is the _id of the single document of the index_metadata collection
+const indexMetadataID = "search_index"
+
+// IndexMetadataRepository stores the schema version and reindex history of the search read model
+type IndexMetadataRepository interface {
+	// Get returns the stored metadata, nil if it was never recorded
+	Get(ctx context.Context) (*domain.IndexMetadata, error)
+	// InitSchemaVersion records the schema version only if no metadata exists yet (fresh read model)
+	InitSchemaVersion(ctx context.Context, version int) error
+	// SetSchemaVersion records the schema version the read model was rebuilt with
+	SetSchemaVersion(ctx context.Context, version int) error
+	// RecordFullReindex records when the whole index was last rebuilt and by which process
+	RecordFullReindex(ctx context.Context, source string, at time.Time) error
+	// AcquireReindexLease takes (or renews) the lease that lets a single instance run POST /admin/reindex
+	// Returns false while another owner holds a lease that has not expired
+	AcquireReindexLease(ctx context.Context, owner string, until time.Time) (bool, error)
+	// ReleaseReindexLease drops the lease if owner still holds it
+	ReleaseReindexLease(ctx context.Context, owner string) error
+	// AcquireRebuildFence takes (or renews) the fence that holds trip events while cmd/rebuild runs
+	// Returns false while another rebuild holds a fence that has not expired
+	AcquireRebuildFence(ctx context.Context, owner string, until time.Time) (bool, error)
+	// ReleaseRebuildFence drops the fence if owner still holds it
+	ReleaseRebuildFence(ctx context.Context, owner string) error
+}
+
+type indexMetadataRepository struct {
+	collection *mongo.Collection
+}
+
+// NewIndexMetadataRepository creates a new index metadata repository instance
+func NewIndexMetadataRepository(db *mongo.Database) IndexMetadataRepository {
+	return &indexMetadataRepository{
+		collection: db.Collection("index_metadata"),
+	}
+}
+
+func (r *indexMetadataRepository) Get(ctx context.Context) (*domain.IndexMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var metadata domain.IndexMetadata
+	err := r.collection.FindOne(ctx, bson.M{"_id": indexMetadataID}).Decode(&metadata)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &metadata, nil
+}
+
+func (r *indexMetadataRepository) InitSchemaVersion(ctx context.Context, version int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// $setOnInsert: two instances starting on an empty read model do not overwrite each other
+	update := bson.M{"$setOnInsert": bson.M{
+		"schema_version":    version,
+		"schema_updated_at": time.Now(),
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": indexMetadataID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *indexMetadataRepository) SetSchemaVersion(ctx context.Context, version int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"schema_version":    version,
+		"schema_updated_at": time.Now(),
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": indexMetadataID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *indexMetadataRepository) RecordFullReindex(ctx context.Context, source string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{
+		"last_full_reindex_at":     at,
+		"last_full_reindex_source": source,
+	}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": indexMetadataID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *indexMetadataRepository) AcquireReindexLease(ctx context.Context, owner string, until time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Free, expired (the holder died without releasing it) or already ours
+	filter := bson.M{
+		"_id": indexMetadataID,
+		"$or": bson.A{
+			bson.M{"reindex_lease_until": bson.M{"$exists": false}},
+			bson.M{"reindex_lease_until": bson.M{"$lte": time.Now()}},
+			bson.M{"reindex_lease_owner": owner},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"reindex_lease_owner": owner,
+		"reindex_lease_until": until,
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// The document exists but the filter did not match: another instance holds the lease
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0 || result.UpsertedCount > 0, nil
+}
+
+func (r *indexMetadataRepository) ReleaseReindexLease(ctx context.Context, owner string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": indexMetadataID, "reindex_lease_owner": owner}
+	update := bson.M{"$unset": bson.M{"reindex_lease_owner": "", "reindex_lease_until": ""}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *indexMetadataRepository) AcquireRebuildFence(ctx context.Context, owner string, until time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Same rules as the reindex lease: free, expired or already ours
+	filter := bson.M{
+		"_id": indexMetadataID,
+		"$or": bson.A{
+			bson.M{"rebuild_fence_until": bson.M{"$exists": false}},
+			bson.M{"rebuild_fence_until": bson.M{"$lte": time.Now()}},
+			bson.M{"rebuild_fence_owner": owner},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"rebuild_fence_owner": owner,
+		"rebuild_fence_until": until,
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0 || result.UpsertedCount > 0, nil
+}
+
+func (r *indexMetadataRepository) ReleaseRebuildFence(ctx context.Context, owner string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": indexMetadataID, "rebuild_fence_owner": owner}
+	update := bson.M{"$unset": bson.M{"rebuild_fence_owner": "", "rebuild_fence_until": ""}}
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"search-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SlowQueryRepository handles the slow-query log (capped collection)
+type SlowQueryRepository interface {
+	Record(ctx context.Context, entry *domain.SlowQuery) error
+	FindRecent(ctx context.Context, limit int, minDurationMs int64) ([]domain.SlowQuery, error)
+}
+
+type slowQueryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSlowQueryRepository creates a new slow query repository instance
+func NewSlowQueryRepository(db *mongo.Database) SlowQueryRepository {
+	return &slowQueryRepository{
+		collection: db.Collection("slow_queries"),
+	}
+}
+
+// Record appends a slow query entry to the log
+func (r *slowQueryRepository) Record(ctx context.Context, entry *domain.SlowQuery) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if entry.RecordedAt.IsZero() {
+		entry.RecordedAt = time.Now()
+	}
+
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+// FindRecent returns the most recent slow queries, newest first
+// Capped collections preserve insertion order, so $natural descending is the cheapest sort
+func (r *slowQueryRepository) FindRecent(ctx context.Context, limit int, minDurationMs int64) ([]domain.SlowQuery, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if minDurationMs > 0 {
+		filter["duration_ms"] = bson.M{"$gte": minDurationMs}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "$natural", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.SlowQuery
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	// Return empty slice instead of nil
+	if entries == nil {
+		entries = []domain.SlowQuery{}
+	}
+
+	return entries, nil
+}
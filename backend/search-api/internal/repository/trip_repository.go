@@ -18,15 +18,45 @@ type TripRepository interface {
 	Create(ctx context.Context, trip *domain.SearchTrip) error
 	FindByID(ctx context.Context, id string) (*domain.SearchTrip, error)
 	FindByTripID(ctx context.Context, tripID string) (*domain.SearchTrip, error)
+	FindByTripIDs(ctx context.Context, tripIDs []string) ([]*domain.SearchTrip, error)
 	Update(ctx context.Context, trip *domain.SearchTrip) error
 	UpdateStatus(ctx context.Context, id string, status string) error
-	UpdateStatusByTripID(ctx context.Context, tripID string, status string) error
+	// UpdateStatusByTripID and UpdateAvailabilityByTripID only apply when sequence is newer than the
+	// trip's last_sequence (domain.ErrStaleEvent otherwise); sequence 0 always applies.
+	// UpdateAvailabilityByTripID also replaces pickup_points and pickup_locations unless pickupPoints is nil
+	UpdateStatusByTripID(ctx context.Context, tripID string, status string, sequence int64) error
 	UpdateAvailability(ctx context.Context, id string, availableSeats int) error
-	UpdateAvailabilityByTripID(ctx context.Context, tripID string, availableSeats int, reservedSeats int, status string) error
+	UpdateAvailabilityByTripID(ctx context.Context, tripID string, availableSeats int, reservedSeats int, status string, pickupPoints []domain.PickupPoint, pickupLocations []domain.GeoJSONPoint, sequence int64) error
 	DeleteByTripID(ctx context.Context, tripID string) error
-	Search(ctx context.Context, filters map[string]interface{}, page, limit int, sortBy string, sortOrder string) ([]*domain.SearchTrip, int64, error)
+	Search(ctx context.Context, filters map[string]interface{}, page, limit int, sort []domain.SortCriterion) ([]*domain.SearchTrip, int64, error)
+	Facets(ctx context.Context, filters map[string]interface{}) (*domain.SearchFacets, error)
+	PriceHistogram(ctx context.Context, filters map[string]interface{}) (*domain.PriceHistogram, error)
 	SearchByLocation(ctx context.Context, lat, lng float64, radiusKm int, additionalFilters map[string]interface{}) ([]*domain.SearchTrip, error)
 	SearchByRoute(ctx context.Context, originCity, destinationCity string, filters map[string]interface{}) ([]*domain.SearchTrip, error)
+	Count(ctx context.Context) (int64, error)
+	FindBatchAfter(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*domain.SearchTrip, error)
+	UpsertByTripID(ctx context.Context, trip *domain.SearchTrip) error
+	// DeleteByTripIDs deletes the given trips (callers send bounded batches) and returns how many were removed
+	DeleteByTripIDs(ctx context.Context, tripIDs []string) (int64, error)
+	// CountDriverOutcomes counts the driver's trips that ended completed and cancelled
+	CountDriverOutcomes(ctx context.Context, driverID int64) (completed, cancelled int64, err error)
+
+	// UpdateManyByDriverID refreshes the embedded driver rating of every trip of a driver
+	UpdateManyByDriverID(ctx context.Context, driverID int64, rating float64, totalTrips int, ratedAt time.Time) (int64, error)
+	FindByDriverID(ctx context.Context, driverID int64) ([]*domain.SearchTrip, error)
+
+	// UpdatePricingAndBadges stores the price fields, seat velocity samples and badges of a trip
+	UpdatePricingAndBadges(ctx context.Context, trip *domain.SearchTrip) error
+
+	// ExpireDeparted marks up to limit visible trips that departed before cutoff as expired
+	// and returns their trip IDs
+	ExpireDeparted(ctx context.Context, cutoff time.Time, limit int) ([]string, error)
+
+	// FindWithBadges returns up to limit trips showing a badge, ordered by trip_id after afterTripID
+	FindWithBadges(ctx context.Context, afterTripID string, limit int) ([]*domain.SearchTrip, error)
+	// UpdateBadgesIfUnchanged stores the badges and seat velocity samples of trip unless the trip was
+	// updated after it was read; it reports whether they were stored
+	UpdateBadgesIfUnchanged(ctx context.Context, trip *domain.SearchTrip) (bool, error)
 }
 
 type tripRepository struct {
@@ -167,7 +197,9 @@ func (r *tripRepository) UpdateAvailability(ctx context.Context, id string, avai
 }
 
 // Search performs a generic search with filters and pagination
-func (r *tripRepository) Search(ctx context.Context, filters map[string]interface{}, page, limit int, sortBy string, sortOrder string) ([]*domain.SearchTrip, int64, error) {
+// Geospatial filters ($near on pickup_locations or destination.coordinates) run as a $geoNear
+// aggregation instead, see searchNear
+func (r *tripRepository) Search(ctx context.Context, filters map[string]interface{}, page, limit int, sort []domain.SortCriterion) ([]*domain.SearchTrip, int64, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -180,45 +212,26 @@ func (r *tripRepository) Search(ctx context.Context, filters map[string]interfac
 		filter[key] = value
 	}
 
-	// Check if this is a geospatial query by looking for $near operator
-	// MongoDB's CountDocuments doesn't support $near, so we need different approach
-	var total int64
-	var needsPostCount bool
-
-	// Check origin coordinates for $near
-	if originCoords, ok := filter["origin.coordinates"].(bson.M); ok {
-		if _, hasNear := originCoords["$near"]; hasNear {
-			needsPostCount = true
-		}
-	}
-
-	// Check destination coordinates for $near
-	if !needsPostCount {
-		if destCoords, ok := filter["destination.coordinates"].(bson.M); ok {
-			if _, hasNear := destCoords["$near"]; hasNear {
-				needsPostCount = true
-			}
-		}
+	// MongoDB's CountDocuments doesn't support $near, so geospatial queries count inside the aggregation
+	if field, near, ok := extractNear(filter); ok {
+		return r.searchNear(ctx, field, near, filter, skip, limit, sort)
 	}
 
-	// For non-geospatial queries, count normally
-	if !needsPostCount {
-		var err error
-		total, err = r.collection.CountDocuments(ctx, filter)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to count trips: %w", err)
-		}
+	total, err := r.collection.CountDocuments(ctx, filter, options.Count().SetCollation(searchCollation))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count trips: %w", err)
 	}
 
 	// Find documents with pagination
+	// The collation makes city/province equality case and accent insensitive
 	findOptions := options.Find().
 		SetSkip(int64(skip)).
-		SetLimit(int64(limit))
+		SetLimit(int64(limit)).
+		SetCollation(searchCollation)
 
-	// Only apply sorting if sortBy is provided
-	// For geospatial queries with $near, MongoDB automatically sorts by distance
-	if sortBy != "" {
-		sortBson := r.buildSortOptions(sortBy, sortOrder)
+	// Only apply sorting if sort criteria are provided
+	if len(sort) > 0 {
+		sortBson := r.buildSortOptions(sort)
 		findOptions.SetSort(sortBson)
 	}
 
@@ -233,11 +246,6 @@ func (r *tripRepository) Search(ctx context.Context, filters map[string]interfac
 		return nil, 0, fmt.Errorf("failed to decode trips: %w", err)
 	}
 
-	// For geospatial queries, count the results after fetching
-	if needsPostCount {
-		total = int64(len(trips))
-	}
-
 	// Return empty slice instead of nil
 	if trips == nil {
 		trips = []*domain.SearchTrip{}
@@ -246,9 +254,343 @@ func (r *tripRepository) Search(ctx context.Context, filters map[string]interfac
 	return trips, total, nil
 }
 
-// buildSortOptions converts sortBy and sortOrder to MongoDB sort bson.D
-// Supports both flexible format (sortBy + sortOrder) and backward compatible shortcuts
-func (r *tripRepository) buildSortOptions(sortBy string, sortOrder string) bson.D {
+// geoDistanceField receives the distance in meters computed by $geoNear
+// SearchTrip has no such field, so it is dropped when decoding
+const geoDistanceField = "geo_distance_meters"
+
+// nearFields are the fields buildMongoFilters may put a $near on (MongoDB allows only one per query)
+var nearFields = []string{"pickup_locations", "destination.coordinates"}
+
+// extractNear removes the $near condition from filter and returns its field and operator
+func extractNear(filter bson.M) (string, bson.M, bool) {
+	for _, field := range nearFields {
+		condition, ok := filter[field].(bson.M)
+		if !ok {
+			continue
+		}
+		near, ok := condition["$near"].(bson.M)
+		if !ok {
+			continue
+		}
+		delete(filter, field)
+		return field, near, true
+	}
+	return "", nil, false
+}
+
+// searchNear runs a geospatial search as a $geoNear aggregation: the rest of the filter goes in its
+// query, and a $facet returns the requested page together with the real number of matches
+// Without sort criteria the trips stay ordered by distance (nearest first), like $near
+// Reference: https://www.mongodb.com/docs/manual/reference/operator/aggregation/geoNear/
+func (r *tripRepository) searchNear(ctx context.Context, field string, near, filter bson.M, skip, limit int, sort []domain.SortCriterion) ([]*domain.SearchTrip, int64, error) {
+	geoNear := bson.M{
+		"near":          near["$geometry"],
+		"key":           field, // Required: the collection has more than one 2dsphere index
+		"distanceField": geoDistanceField,
+		"query":         filter,
+		"spherical":     true,
+	}
+	if maxDistance, ok := near["$maxDistance"]; ok {
+		geoNear["maxDistance"] = maxDistance
+	}
+
+	page := bson.A{}
+	if len(sort) > 0 {
+		page = append(page, bson.M{"$sort": r.buildSortOptions(sort)})
+	}
+	page = append(page, bson.M{"$skip": skip}, bson.M{"$limit": limit})
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: geoNear}},
+		{{Key: "$facet", Value: bson.M{
+			"trips": page,
+			"total": bson.A{bson.M{"$count": "count"}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetCollation(searchCollation))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search trips near location: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Trips []*domain.SearchTrip `bson:"trips"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	trips := []*domain.SearchTrip{}
+	var total int64
+	if len(results) > 0 {
+		if results[0].Trips != nil {
+			trips = results[0].Trips
+		}
+		if len(results[0].Total) > 0 {
+			total = results[0].Total[0].Count
+		}
+	}
+
+	return trips, total, nil
+}
+
+// Facets computes the filter counts (destination cities, price ranges, preferences) of all
+// trips matching filters with a single $facet aggregation
+// Geospatial filters ($near) are not allowed in $match, so callers must not pass them
+func (r *tripRepository) Facets(ctx context.Context, filters map[string]interface{}) (*domain.SearchFacets, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	for key, value := range filters {
+		filter[key] = value
+	}
+
+	// $bucket needs the inner boundaries plus an upper limit; everything above it goes to "default"
+	boundaries := make([]interface{}, 0, len(domain.PriceFacetBounds))
+	for _, bound := range domain.PriceFacetBounds {
+		boundaries = append(boundaries, bound)
+	}
+	lastBound := domain.PriceFacetBounds[len(domain.PriceFacetBounds)-1]
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: bson.M{
+			"destination_cities": bson.A{
+				bson.M{"$group": bson.M{"_id": "$destination.city", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": domain.MaxDestinationFacets},
+			},
+			"price_ranges": bson.A{
+				bson.M{"$bucket": bson.M{
+					"groupBy":    "$price_per_seat",
+					"boundaries": boundaries,
+					"default":    lastBound,
+					"output":     bson.M{"count": bson.M{"$sum": 1}},
+				}},
+			},
+			"preferences": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":             nil,
+					"pets_allowed":    bson.M{"$sum": bson.M{"$cond": bson.A{"$preferences.pets_allowed", 1, 0}}},
+					"smoking_allowed": bson.M{"$sum": bson.M{"$cond": bson.A{"$preferences.smoking_allowed", 1, 0}}},
+					"music_allowed":   bson.M{"$sum": bson.M{"$cond": bson.A{"$preferences.music_allowed", 1, 0}}},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetCollation(searchCollation))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate facets: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		DestinationCities []struct {
+			City  string `bson:"_id"`
+			Count int64  `bson:"count"`
+		} `bson:"destination_cities"`
+		PriceRanges []struct {
+			Min   float64 `bson:"_id"`
+			Count int64   `bson:"count"`
+		} `bson:"price_ranges"`
+		Preferences []domain.PreferenceFacets `bson:"preferences"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode facets: %w", err)
+	}
+
+	facets := &domain.SearchFacets{
+		DestinationCities: []domain.FacetCount{},
+		PriceRanges:       domain.NewPriceBuckets(),
+	}
+	if len(results) == 0 {
+		return facets, nil
+	}
+
+	for _, city := range results[0].DestinationCities {
+		facets.DestinationCities = append(facets.DestinationCities, domain.FacetCount{Value: city.City, Count: city.Count})
+	}
+	for _, bucket := range results[0].PriceRanges {
+		facets.PriceRanges[domain.PriceBucketIndex(bucket.Min)].Count += bucket.Count
+	}
+	if len(results[0].Preferences) > 0 {
+		facets.Preferences = results[0].Preferences[0]
+	}
+
+	return facets, nil
+}
+
+// PriceHistogram counts the trips matching filters per histogram price bucket with a $bucket aggregation
+// Geospatial filters ($near) are not allowed in $match, so callers must not pass them
+func (r *tripRepository) PriceHistogram(ctx context.Context, filters map[string]interface{}) (*domain.PriceHistogram, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	for key, value := range filters {
+		filter[key] = value
+	}
+
+	histogram := domain.NewPriceHistogram()
+	boundaries := make([]interface{}, 0, len(histogram.Buckets))
+	for _, bucket := range histogram.Buckets {
+		boundaries = append(boundaries, bucket.Min)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$bucket", Value: bson.M{
+			"groupBy":    "$price_per_seat",
+			"boundaries": boundaries,
+			"default":    domain.PriceHistogramMax,
+			"output":     bson.M{"count": bson.M{"$sum": 1}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetCollation(searchCollation))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate price histogram: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Min   float64 `bson:"_id"`
+		Count int64   `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode price histogram: %w", err)
+	}
+
+	for _, bucket := range results {
+		histogram.Buckets[domain.PriceHistogramIndex(bucket.Min)].Count += bucket.Count
+	}
+
+	return histogram, nil
+}
+
+// searchCollation compares strings ignoring case and accents ("Córdoba" == "cordoba"),
+// so queries that share a cache key (see domain.SearchQuery.Canonical) return the same trips
+var searchCollation = &options.Collation{Locale: "es", Strength: 1}
+
+// CountDriverOutcomes counts the completed and cancelled trips of a driver in a single aggregation
+func (r *tripRepository) CountDriverOutcomes(ctx context.Context, driverID int64) (int64, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"driver_id": driverID,
+			"status":    bson.M{"$in": []string{"completed", "cancelled"}},
+		}}},
+		{{Key: "$group", Value: bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count driver trip outcomes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var completed, cancelled int64
+	for cursor.Next(ctx) {
+		var row struct {
+			Status string `bson:"_id"`
+			Count  int64  `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return 0, 0, fmt.Errorf("failed to decode driver trip outcomes: %w", err)
+		}
+		switch row.Status {
+		case "completed":
+			completed = row.Count
+		case "cancelled":
+			cancelled = row.Count
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to count driver trip outcomes: %w", err)
+	}
+
+	return completed, cancelled, nil
+}
+
+// UpdateManyByDriverID sets driver.rating and driver.total_trips on every trip of driverID and
+// returns how many trips changed. Trips whose rating comes from a newer event (driver.rating_updated_at
+// after ratedAt) are left alone, so out-of-order events never roll a rating back
+func (r *tripRepository) UpdateManyByDriverID(ctx context.Context, driverID int64, rating float64, totalTrips int, ratedAt time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"driver_id": driverID,
+		"$or": []bson.M{
+			{"driver.rating_updated_at": bson.M{"$exists": false}},
+			{"driver.rating_updated_at": bson.M{"$lte": ratedAt}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"driver.rating":            rating,
+			"driver.total_trips":       totalTrips,
+			"driver.rating_updated_at": ratedAt,
+			"updated_at":               time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update driver rating: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// FindByDriverID returns every trip of a driver
+func (r *tripRepository) FindByDriverID(ctx context.Context, driverID int64) ([]*domain.SearchTrip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"driver_id": driverID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find driver trips: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []*domain.SearchTrip
+	if err := cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode driver trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+// buildSortOptions converts the sort criteria to a compound MongoDB sort bson.D
+// Each criterion adds its keys in order; a key already sorted by an earlier criterion is skipped
+// (MongoDB rejects repeated keys, e.g. relevance followed by departure_time)
+func (r *tripRepository) buildSortOptions(sort []domain.SortCriterion) bson.D {
+	sortBson := bson.D{}
+	seen := make(map[string]bool)
+	for _, criterion := range sort {
+		for _, key := range sortKeys(criterion.Field, criterion.Order) {
+			if seen[key.Key] {
+				continue
+			}
+			seen[key.Key] = true
+			sortBson = append(sortBson, key)
+		}
+	}
+	return sortBson
+}
+
+// sortKeys converts one sort field and order to its MongoDB sort keys
+// Supports both flexible format (field + order) and backward compatible shortcuts
+func sortKeys(sortBy string, sortOrder string) bson.D {
 	// Determine sort direction: 1 for ascending, -1 for descending
 	direction := 1 // Default to ascending
 	if sortOrder == "desc" {
@@ -263,6 +605,15 @@ func (r *tripRepository) buildSortOptions(sortBy string, sortOrder string) bson.
 		return bson.D{{Key: "price_per_seat", Value: 1}}
 	case "best_rated":
 		return bson.D{{Key: "driver.rating", Value: -1}}
+	case "relevance":
+		// The weighted boost is only computed by Solr; MongoDB approximates it by ordering
+		// on the same driver signals
+		return bson.D{
+			{Key: "driver.responsiveness_score", Value: -1},
+			{Key: "driver.completion_rate", Value: -1},
+			{Key: "driver.rating", Value: -1},
+			{Key: "departure_datetime", Value: 1},
+		}
 	}
 
 	// Handle new flexible format (respects sortOrder parameter)
@@ -295,8 +646,9 @@ func (r *tripRepository) SearchByLocation(ctx context.Context, lat, lng float64,
 	// Build geospatial filter with $near operator
 	// IMPORTANT: MongoDB GeoJSON uses [longitude, latitude] order (lng first!)
 	// The $near operator returns documents from nearest to farthest
+	// pickup_locations holds the origin plus every pickup point, so a trip matches if any of them is in range
 	filter := bson.M{
-		"origin.coordinates": bson.M{
+		"pickup_locations": bson.M{
 			"$near": bson.M{
 				"$geometry": bson.M{
 					"type":        "Point",
@@ -305,8 +657,7 @@ func (r *tripRepository) SearchByLocation(ctx context.Context, lat, lng float64,
 				"$maxDistance": radiusKm * 1000, // Convert km to meters
 			},
 		},
-		"status":          "published",
-		"available_seats": bson.M{"$gte": 1},
+		"bookable": true,
 	}
 
 	// Add additional filters (e.g., departure_datetime, price_per_seat, preferences)
@@ -343,8 +694,7 @@ func (r *tripRepository) SearchByRoute(ctx context.Context, originCity, destinat
 	filter := bson.M{
 		"origin.city":      originCity,
 		"destination.city": destinationCity,
-		"status":           "published",
-		"available_seats":  bson.M{"$gte": 1},
+		"bookable":         true,
 	}
 
 	// Add additional filters
@@ -392,49 +742,144 @@ func (r *tripRepository) FindByTripID(ctx context.Context, tripID string) (*doma
 	return &trip, nil
 }
 
-// UpdateStatusByTripID updates only the status of a trip using trip_id field
-func (r *tripRepository) UpdateStatusByTripID(ctx context.Context, tripID string, status string) error {
+// FindByTripIDs retrieves the trips with the given trip_id values in a single query ($in)
+// The result is in no particular order and silently omits IDs that do not exist
+func (r *tripRepository) FindByTripIDs(ctx context.Context, tripIDs []string) ([]*domain.SearchTrip, error) {
+	if len(tripIDs) == 0 {
+		return []*domain.SearchTrip{}, nil
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	filter := bson.M{"trip_id": tripID}
-	update := bson.M{
-		"$set": bson.M{
-			"status":     status,
-			"updated_at": time.Now(),
-		},
+	cursor, err := r.collection.Find(ctx, bson.M{"trip_id": bson.M{"$in": tripIDs}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trips by trip_id: %w", err)
 	}
+	defer cursor.Close(ctx)
 
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	trips := make([]*domain.SearchTrip, 0, len(tripIDs))
+	if err := cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+// UpdateStatusByTripID updates the status (and the derived bookable flag) of a trip using trip_id field
+// Skipped with domain.ErrStaleEvent when the trip already applied an event with sequence >= sequence
+func (r *tripRepository) UpdateStatusByTripID(ctx context.Context, tripID string, status string, sequence int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Only the status is known: a published trip stays bookable if the stored seats allow it,
+	// so the flag is computed from the document in an update pipeline
+	var bookable interface{} = false
+	if status == domain.TripStatusPublished {
+		bookable = bson.M{"$gt": bson.A{"$available_seats", 0}}
+	}
+	set := bson.M{
+		"status":     status,
+		"bookable":   bookable,
+		"updated_at": time.Now(),
+	}
+
+	filter := sequencedFilter(tripID, sequence, set)
+	result, err := r.collection.UpdateOne(ctx, filter, mongo.Pipeline{{{Key: "$set", Value: set}}})
 	if err != nil {
 		return fmt.Errorf("failed to update trip status: %w", err)
 	}
 
 	if result.MatchedCount == 0 {
-		return domain.ErrSearchTripNotFound
+		return r.unmatchedSequencedUpdate(ctx, tripID, sequence)
 	}
 
 	return nil
 }
 
-// UpdateAvailabilityByTripID updates availability, reserved seats, and status using trip_id field
-func (r *tripRepository) UpdateAvailabilityByTripID(ctx context.Context, tripID string, availableSeats int, reservedSeats int, status string) error {
+// UpdateAvailabilityByTripID updates availability, reserved seats, status and bookable using trip_id field
+// Skipped with domain.ErrStaleEvent when the trip already applied an event with sequence >= sequence
+func (r *tripRepository) UpdateAvailabilityByTripID(ctx context.Context, tripID string, availableSeats int, reservedSeats int, status string, pickupPoints []domain.PickupPoint, pickupLocations []domain.GeoJSONPoint, sequence int64) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
+	set := bson.M{
+		"available_seats": availableSeats,
+		"reserved_seats":  reservedSeats,
+		"status":          status,
+		"bookable":        domain.IsBookable(status, availableSeats),
+		"updated_at":      time.Now(),
+	}
+	// Written with the same sequence check, so a stale event cannot bring back old pickup points
+	if pickupPoints != nil {
+		set["pickup_points"] = pickupPoints
+		set["pickup_locations"] = pickupLocations
+	}
+
+	result, err := r.collection.UpdateOne(ctx, sequencedFilter(tripID, sequence, set), bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to update trip availability: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return r.unmatchedSequencedUpdate(ctx, tripID, sequence)
+	}
+
+	return nil
+}
+
+// sequencedFilter matches the trip only if it has not applied an event with sequence >= sequence,
+// and records sequence as its last_sequence in set. The check and the write are a single UpdateOne,
+// so two consumers applying events of the same trip cannot overwrite a newer state with an older one.
+// Sequence 0 (event without sequence) matches the trip unconditionally
+func sequencedFilter(tripID string, sequence int64, set bson.M) bson.M {
 	filter := bson.M{"trip_id": tripID}
+	if sequence > 0 {
+		// $not also matches documents without last_sequence (created before sequences existed)
+		filter["last_sequence"] = bson.M{"$not": bson.M{"$gte": sequence}}
+		set["last_sequence"] = sequence
+	}
+	return filter
+}
+
+// unmatchedSequencedUpdate tells apart a missing trip from a stale event when a sequenced update matched nothing
+func (r *tripRepository) unmatchedSequencedUpdate(ctx context.Context, tripID string, sequence int64) error {
+	if sequence <= 0 {
+		return domain.ErrSearchTripNotFound
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"trip_id": tripID}, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("failed to check trip existence: %w", err)
+	}
+	if count == 0 {
+		return domain.ErrSearchTripNotFound
+	}
+
+	return domain.ErrStaleEvent
+}
+
+// UpdatePricingAndBadges sets price_per_seat, the previous price, the seat velocity samples and the
+// badges of trip, matched by trip_id
+func (r *tripRepository) UpdatePricingAndBadges(ctx context.Context, trip *domain.SearchTrip) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"trip_id": trip.TripID}
 	update := bson.M{
 		"$set": bson.M{
-			"available_seats": availableSeats,
-			"reserved_seats":  reservedSeats,
-			"status":          status,
-			"updated_at":      time.Now(),
+			"price_per_seat":          trip.PricePerSeat,
+			"previous_price_per_seat": trip.PreviousPricePerSeat,
+			"price_changed_at":        trip.PriceChangedAt,
+			"recent_bookings":         trip.RecentBookings,
+			"badges":                  trip.Badges,
+			"updated_at":              time.Now(),
 		},
 	}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return fmt.Errorf("failed to update trip availability: %w", err)
+		return fmt.Errorf("failed to update trip badges: %w", err)
 	}
 
 	if result.MatchedCount == 0 {
@@ -462,3 +907,188 @@ func (r *tripRepository) DeleteByTripID(ctx context.Context, tripID string) erro
 
 	return nil
 }
+
+// Count returns the total number of trips in the collection
+func (r *tripRepository) Count(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count trips: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindBatchAfter returns up to limit trips with _id greater than afterID, ordered by _id
+// Used to scan the whole collection in batches (keyset pagination, stable under inserts)
+// Pass primitive.NilObjectID to start from the beginning
+func (r *tripRepository) FindBatchAfter(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*domain.SearchTrip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{}
+	if !afterID.IsZero() {
+		filter["_id"] = bson.M{"$gt": afterID}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trip batch: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []*domain.SearchTrip
+	if err := cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trip batch: %w", err)
+	}
+
+	return trips, nil
+}
+
+// UpsertByTripID replaces the document of trip.TripID (keeping its _id) or inserts it if missing
+// Timestamps are stored as given so a rebuild produces the same documents every time
+func (r *tripRepository) UpsertByTripID(ctx context.Context, trip *domain.SearchTrip) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	trip.ID = primitive.NilObjectID
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := r.collection.ReplaceOne(ctx, bson.M{"trip_id": trip.TripID}, trip, opts); err != nil {
+		return fmt.Errorf("failed to upsert trip: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteByTripIDs removes the trips whose trip_id is in tripIDs and returns how many were removed
+func (r *tripRepository) DeleteByTripIDs(ctx context.Context, tripIDs []string) (int64, error) {
+	if len(tripIDs) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"trip_id": bson.M{"$in": tripIDs}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete trips: %w", err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+// ExpireDeparted marks up to limit visible trips that departed before cutoff as expired
+// Expired trips are no longer bookable; a trip whose status changes concurrently is left untouched
+func (r *tripRepository) ExpireDeparted(ctx context.Context, cutoff time.Time, limit int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Served by the (status, departure_datetime) index
+	filter := bson.M{
+		"status":             bson.M{"$in": domain.VisibleTripStatuses},
+		"departure_datetime": bson.M{"$lt": cutoff},
+	}
+	opts := options.Find().
+		SetProjection(bson.M{"trip_id": 1}).
+		SetSort(bson.D{{Key: "departure_datetime", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find departed trips: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var found []struct {
+		TripID string `bson:"trip_id"`
+	}
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, fmt.Errorf("failed to decode departed trips: %w", err)
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	tripIDs := make([]string, 0, len(found))
+	for _, trip := range found {
+		tripIDs = append(tripIDs, trip.TripID)
+	}
+
+	// Same filter again: a trip moved to another status in between (e.g. cancelled) is left alone
+	filter["trip_id"] = bson.M{"$in": tripIDs}
+	now := time.Now()
+	_, err = r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{
+		"status":     domain.TripStatusExpired,
+		"bookable":   false,
+		"expired_at": now,
+		"updated_at": now,
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire departed trips: %w", err)
+	}
+
+	return tripIDs, nil
+}
+
+// FindWithBadges returns up to limit trips with a badge set, ordered by trip_id, starting after
+// afterTripID (empty = from the first one)
+func (r *tripRepository) FindWithBadges(ctx context.Context, afterTripID string, limit int) ([]*domain.SearchTrip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"$or": []bson.M{
+			{"badges.price_dropped": true},
+			{"badges.filling_fast": true},
+		},
+	}
+	if afterTripID != "" {
+		filter["trip_id"] = bson.M{"$gt": afterTripID}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "trip_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trips with badges: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []*domain.SearchTrip
+	if err := cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips with badges: %w", err)
+	}
+
+	return trips, nil
+}
+
+// UpdateBadgesIfUnchanged sets badges and recent_bookings of trip, matched by trip_id and the
+// updated_at it was read with: a trip.updated applied in between already recomputed them
+func (r *tripRepository) UpdateBadgesIfUnchanged(ctx context.Context, trip *domain.SearchTrip) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"trip_id": trip.TripID, "updated_at": trip.UpdatedAt}
+	update := bson.M{
+		"$set": bson.M{
+			"recent_bookings": trip.RecentBookings,
+			"badges":          trip.Badges,
+			"updated_at":      time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh trip badges: %w", err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
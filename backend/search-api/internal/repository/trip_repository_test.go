@@ -210,7 +210,7 @@ func TestTripRepository_Search(t *testing.T) {
 	filters := map[string]interface{}{
 		"status": "published",
 	}
-	trips, total, err := repo.Search(context.Background(), filters, 1, 10, "popularity", "desc")
+	trips, total, err := repo.Search(context.Background(), filters, 1, 10, []domain.SortCriterion{{Field: "popularity", Order: "desc"}})
 	require.NoError(t, err, "Failed to search trips")
 	assert.Equal(t, int64(1), total, "Should find 1 published trip")
 	assert.Len(t, trips, 1, "Should return 1 trip")
@@ -313,20 +313,101 @@ func TestTripRepository_Pagination(t *testing.T) {
 	}
 
 	// Test pagination: page 1, limit 2
-	trips, total, err := repo.Search(context.Background(), map[string]interface{}{}, 1, 2, "", "")
+	trips, total, err := repo.Search(context.Background(), map[string]interface{}{}, 1, 2, nil)
 	require.NoError(t, err, "Failed to search with pagination")
 	assert.Equal(t, int64(5), total, "Total should be 5")
 	assert.Len(t, trips, 2, "Should return 2 trips on page 1")
 
 	// Test pagination: page 2, limit 2
-	trips, total, err = repo.Search(context.Background(), map[string]interface{}{}, 2, 2, "", "")
+	trips, total, err = repo.Search(context.Background(), map[string]interface{}{}, 2, 2, nil)
 	require.NoError(t, err, "Failed to search with pagination")
 	assert.Equal(t, int64(5), total, "Total should still be 5")
 	assert.Len(t, trips, 2, "Should return 2 trips on page 2")
 
 	// Test pagination: page 3, limit 2
-	trips, total, err = repo.Search(context.Background(), map[string]interface{}{}, 3, 2, "", "")
+	trips, total, err = repo.Search(context.Background(), map[string]interface{}{}, 3, 2, nil)
 	require.NoError(t, err, "Failed to search with pagination")
 	assert.Equal(t, int64(5), total, "Total should still be 5")
 	assert.Len(t, trips, 1, "Should return 1 trip on page 3")
 }
+
+func TestTripRepository_Pagination_Geospatial(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	_, err := db.Collection("trips").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "destination.coordinates", Value: "2dsphere"}},
+	})
+	require.NoError(t, err, "Failed to create destination geospatial index")
+
+	repo := NewTripRepository(db)
+
+	// Create 5 trips to La Plata and 1 to Córdoba (out of range)
+	for i := 1; i <= 6; i++ {
+		trip := createTestTrip()
+		trip.TripID = primitive.NewObjectID().Hex()
+		if i == 6 {
+			trip.Destination.City = "Córdoba"
+			trip.Destination.Coordinates = domain.NewGeoJSONPoint(-31.4201, -64.1888)
+		}
+		err := repo.Create(context.Background(), trip)
+		require.NoError(t, err, "Failed to create trip")
+	}
+
+	// $near around La Plata (50km), like buildMongoFilters with a single geospatial filter
+	filters := map[string]interface{}{
+		"destination.coordinates": bson.M{
+			"$near": bson.M{
+				"$geometry": bson.M{
+					"type":        "Point",
+					"coordinates": []float64{-57.9544, -34.9214},
+				},
+				"$maxDistance": 50 * 1000,
+			},
+		},
+	}
+
+	trips, total, err := repo.Search(context.Background(), filters, 1, 2, nil)
+	require.NoError(t, err, "Failed to search near location")
+	assert.Equal(t, int64(5), total, "Total should count every trip in range, not just the page")
+	assert.Len(t, trips, 2, "Should return 2 trips on page 1")
+
+	trips, total, err = repo.Search(context.Background(), filters, 3, 2, nil)
+	require.NoError(t, err, "Failed to search near location")
+	assert.Equal(t, int64(5), total, "Total should still be 5")
+	assert.Len(t, trips, 1, "Should return 1 trip on page 3")
+
+	trips, total, err = repo.Search(context.Background(), filters, 4, 2, nil)
+	require.NoError(t, err, "Failed to search near location")
+	assert.Equal(t, int64(5), total, "Total should still be 5 past the last page")
+	assert.Len(t, trips, 0, "Should return no trips past the last page")
+}
+
+func TestTripRepository_FindByTripIDs(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewTripRepository(db)
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		trip := createTestTrip()
+		trip.TripID = primitive.NewObjectID().Hex()
+		err := repo.Create(context.Background(), trip)
+		require.NoError(t, err, "Failed to create trip")
+		ids = append(ids, trip.TripID)
+	}
+
+	// Unknown IDs are omitted
+	trips, err := repo.FindByTripIDs(context.Background(), []string{ids[2], "missing", ids[0]})
+	require.NoError(t, err, "Failed to find trips by trip_id")
+	require.Len(t, trips, 2, "Should return only the existing trips")
+
+	found := []string{trips[0].TripID, trips[1].TripID}
+	assert.ElementsMatch(t, []string{ids[0], ids[2]}, found)
+
+	// Empty input does not query MongoDB
+	trips, err = repo.FindByTripIDs(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, trips)
+}
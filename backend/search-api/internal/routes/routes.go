@@ -2,7 +2,9 @@ package routes
 
 import (
 	"search-api/internal/controllers"
+	"search-api/internal/metrics"
 	"search-api/internal/middleware"
+	"search-api/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,17 +14,26 @@ func SetupRoutes(
 	router *gin.Engine,
 	healthController *controllers.HealthController,
 	searchController *controllers.SearchController,
+	adminController *controllers.AdminController,
+	searchRateLimit gin.HandlerFunc,
+	adminAuth gin.HandlerFunc,
 ) {
 	// Apply global middlewares
+	router.Use(tracing.Middleware())
 	router.Use(middleware.ErrorHandler())
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.Logger())
+	router.Use(metrics.Middleware())
 
 	// Health check endpoint
 	router.GET("/health", healthController.HealthCheck)
 
-	// API v1 group
+	// Prometheus metrics (scraped from the internal network, no auth)
+	router.GET("/metrics", metrics.Handler())
+
+	// API v1 group (public and scrape-prone: rate limited; /health, /metrics and the authenticated /admin are not)
 	v1 := router.Group("/api/v1")
+	v1.Use(searchRateLimit)
 	{
 		// Search endpoints (all public, no auth required)
 		v1.GET("/search/trips", searchController.SearchTrips)
@@ -33,4 +44,17 @@ func SetupRoutes(
 		// Trip detail endpoint
 		v1.GET("/trips/:id", searchController.GetTrip)
 	}
+
+	// Diagnostics and operations: admins only (users-api JWT with role admin)
+	admin := router.Group("/admin")
+	admin.Use(adminAuth)
+	{
+		admin.GET("/slow-queries", adminController.GetSlowQueries)
+		admin.GET("/shadow-reads", adminController.GetShadowReadStats)
+		admin.GET("/cache", adminController.GetCacheStats)
+		admin.GET("/index/warm-stats", adminController.GetIndexWarmStats)
+		admin.POST("/reindex", adminController.StartReindex)
+		admin.GET("/reindex/status", adminController.GetReindexStatus)
+		admin.GET("/ranking/weights", adminController.GetRankingWeights)
+	}
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"search-api/internal/cache"
+	"search-api/internal/domain"
+	"search-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// BadgeRefreshService recomputes the badges of the trips that show one
+//
+// Badges are otherwise only recomputed on trip events, so a trip without activity kept "price
+// dropped" past the max age and "filling fast" after its bookings left the window. The job only
+// reads trips with a badge set: a trip without badges can only gain one through an event.
+type BadgeRefreshService struct {
+	tripRepo   repository.TripRepository
+	cache      cache.Cache
+	thresholds domain.BadgeThresholds
+	interval   time.Duration
+	batchSize  int
+}
+
+// NewBadgeRefreshService creates the badge refresh job (interval <= 0 = never runs on its own)
+func NewBadgeRefreshService(tripRepo repository.TripRepository, cache cache.Cache, thresholds domain.BadgeThresholds, interval time.Duration, batchSize int) *BadgeRefreshService {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &BadgeRefreshService{
+		tripRepo:   tripRepo,
+		cache:      cache,
+		thresholds: thresholds,
+		interval:   interval,
+		batchSize:  batchSize,
+	}
+}
+
+// Start refreshes the badges every interval until ctx is cancelled
+func (s *BadgeRefreshService) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RunOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("Badge refresh run failed")
+			}
+		}
+	}
+}
+
+// RunOnce recomputes the badges of every trip showing one and returns how many changed
+// A trip updated while the job ran is skipped: its event already recomputed the badges
+func (s *BadgeRefreshService) RunOnce(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	changed := 0
+	after := ""
+
+	for {
+		trips, err := s.tripRepo.FindWithBadges(ctx, after, s.batchSize)
+		if err != nil {
+			return changed, fmt.Errorf("find trips with badges: %w", err)
+		}
+
+		for _, trip := range trips {
+			if !trip.ExpireBadges(s.thresholds, now) {
+				continue
+			}
+			stored, err := s.tripRepo.UpdateBadgesIfUnchanged(ctx, trip)
+			if err != nil {
+				return changed, fmt.Errorf("update badges of trip %s: %w", trip.TripID, err)
+			}
+			if !stored {
+				continue
+			}
+			changed++
+			s.invalidateTrip(ctx, trip.TripID)
+		}
+
+		if len(trips) < s.batchSize {
+			break
+		}
+		after = trips[len(trips)-1].TripID
+	}
+
+	if changed > 0 {
+		log.Info().Int("trips", changed).Msg("Trip badges refreshed")
+	}
+
+	return changed, nil
+}
+
+// invalidateTrip removes the cached GET /trips/:id response of a trip whose badges changed
+// Cached search pages keep the previous badges until their TTL
+func (s *BadgeRefreshService) invalidateTrip(ctx context.Context, tripID string) {
+	if s.cache == nil {
+		return
+	}
+	cacheKey := fmt.Sprintf("trip:%s", tripID)
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		log.Error().Err(err).Str("cache_key", cacheKey).Msg("Failed to delete trip cache (continuing)")
+	}
+}
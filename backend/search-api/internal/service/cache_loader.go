@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"search-api/internal/cache"
+	"search-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// cacheRefreshTimeout bounds a background refresh of a stale entry
+const cacheRefreshTimeout = 30 * time.Second
+
+// cacheEnvelope is what is stored in the cache: the value and the moment it stops being fresh
+// The cache entry itself lives for ttl + staleWindow, so it can still be served while it is refreshed
+type cacheEnvelope struct {
+	FreshUntil time.Time       `json:"fresh_until"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// cacheLoader serves cached values with stampede protection
+//
+//   - fresh entry: returned as is
+//   - expired entry inside the stale window: returned as is, and a single background refresh recomputes it
+//   - miss: computed once per key; concurrent requests for the same key wait for that computation
+//     (singleflight) instead of hitting Solr/MongoDB with identical queries
+//
+// Errors are never cached. Entries written before the envelope format read as misses
+type cacheLoader struct {
+	cache       cache.Cache
+	ttl         time.Duration
+	staleWindow time.Duration
+	group       singleflight.Group
+
+	hits            int64
+	staleHits       int64
+	misses          int64
+	coalesced       int64
+	refreshes       int64
+	refreshFailures int64
+}
+
+// newCacheLoader creates a loader over c (nil means no cache: every lookup computes, still coalesced)
+func newCacheLoader(c cache.Cache, ttl, staleWindow time.Duration) *cacheLoader {
+	if staleWindow < 0 {
+		staleWindow = 0
+	}
+	return &cacheLoader{cache: c, ttl: ttl, staleWindow: staleWindow}
+}
+
+// loadCached returns the value of key, computing it with compute when it is not cached
+// compute runs detached from the caller's cancellation: its result is shared with other requests
+func loadCached[T any](ctx context.Context, l *cacheLoader, key string, compute func(ctx context.Context) (*T, error)) (*T, error) {
+	if cached, freshUntil, ok := getCached[T](ctx, l, key); ok {
+		if time.Now().Before(freshUntil) {
+			atomic.AddInt64(&l.hits, 1)
+			return cached, nil
+		}
+		atomic.AddInt64(&l.staleHits, 1)
+		refreshCached(l, key, compute)
+		return cached, nil
+	}
+
+	atomic.AddInt64(&l.misses, 1)
+	leader := false
+	value, err, shared := l.group.Do(key, func() (interface{}, error) {
+		leader = true
+		return computeAndStore(context.WithoutCancel(ctx), l, key, compute)
+	})
+	if shared && !leader {
+		atomic.AddInt64(&l.coalesced, 1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value.(*T), nil
+}
+
+// refreshCached recomputes a stale entry in the background
+// If a computation for the key is already running (a miss or another refresh) it is not repeated
+func refreshCached[T any](l *cacheLoader, key string, compute func(ctx context.Context) (*T, error)) {
+	l.group.DoChan(key, func() (interface{}, error) {
+		atomic.AddInt64(&l.refreshes, 1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cacheRefreshTimeout)
+		defer cancel()
+
+		value, err := computeAndStore(ctx, l, key, compute)
+		if err != nil {
+			atomic.AddInt64(&l.refreshFailures, 1)
+			log.Warn().Err(err).Str("cache_key", key).Msg("Background refresh of stale cache entry failed")
+		}
+		return value, err
+	})
+}
+
+// computeAndStore runs compute and caches its result
+func computeAndStore[T any](ctx context.Context, l *cacheLoader, key string, compute func(ctx context.Context) (*T, error)) (*T, error) {
+	value, err := compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if value != nil {
+		setCached(ctx, l, key, value)
+	}
+	return value, nil
+}
+
+// getCached reads and decodes an entry; ok is false on a miss or an unreadable entry
+func getCached[T any](ctx context.Context, l *cacheLoader, key string) (*T, time.Time, bool) {
+	if l.cache == nil {
+		return nil, time.Time{}, false
+	}
+
+	raw, err := l.cache.Get(ctx, key)
+	if err != nil || raw == "" {
+		return nil, time.Time{}, false
+	}
+
+	var envelope cacheEnvelope
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil || len(envelope.Data) == 0 {
+		return nil, time.Time{}, false
+	}
+
+	var value T
+	if err := json.Unmarshal(envelope.Data, &value); err != nil {
+		return nil, time.Time{}, false
+	}
+	return &value, envelope.FreshUntil, true
+}
+
+// setCached stores value for ttl, plus the stale window during which it may still be served
+func setCached(ctx context.Context, l *cacheLoader, key string, value interface{}) {
+	if l.cache == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Warn().Err(err).Str("cache_key", key).Msg("Failed to encode cache entry")
+		return
+	}
+	envelope, err := json.Marshal(cacheEnvelope{FreshUntil: time.Now().Add(l.ttl), Data: data})
+	if err != nil {
+		log.Warn().Err(err).Str("cache_key", key).Msg("Failed to encode cache entry")
+		return
+	}
+
+	if err := l.cache.Set(ctx, key, string(envelope), l.ttl+l.staleWindow); err != nil {
+		log.Warn().Err(err).Str("cache_key", key).Msg("Failed to write cache entry")
+	}
+}
+
+// stats returns the current cache counters and hit rate
+func (l *cacheLoader) stats() domain.CacheStats {
+	stats := domain.CacheStats{
+		StaleWindowSeconds: int(l.staleWindow.Seconds()),
+		Hits:               atomic.LoadInt64(&l.hits),
+		StaleHits:          atomic.LoadInt64(&l.staleHits),
+		Misses:             atomic.LoadInt64(&l.misses),
+		Coalesced:          atomic.LoadInt64(&l.coalesced),
+		Refreshes:          atomic.LoadInt64(&l.refreshes),
+		RefreshFailures:    atomic.LoadInt64(&l.refreshFailures),
+	}
+	if lookups := stats.Hits + stats.StaleHits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits+stats.StaleHits) / float64(lookups)
+	}
+	return stats
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"search-api/internal/domain"
+	"search-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// IndexMetadataService checks the schema version of the search read model against the binary
+// and reports the index build information on GET /health
+type IndexMetadataService interface {
+	// CheckSchema runs at startup: an empty read model is stamped with IndexSchemaVersion, a read
+	// model built with an older version returns ErrIndexSchemaOutdated (events must not be consumed)
+	CheckSchema(ctx context.Context) (*domain.IndexMetadata, error)
+
+	// Status returns the stored metadata and whether this instance consumes trip events
+	Status(ctx context.Context) (domain.IndexStatus, error)
+
+	// SetConsuming records whether the RabbitMQ consumer was started
+	SetConsuming(consuming bool)
+
+	// RebuildInProgress reports whether cmd/rebuild holds the rebuild fence (the consumer holds events meanwhile)
+	// The metadata is re-read at most every domain.RebuildFenceCheckInterval
+	RebuildInProgress(ctx context.Context) bool
+}
+
+type indexMetadataService struct {
+	metadataRepo repository.IndexMetadataRepository
+	tripRepo     repository.TripRepository
+	consuming    atomic.Bool
+
+	fenceMu        sync.Mutex
+	fenced         bool
+	fenceCheckedAt time.Time
+}
+
+// NewIndexMetadataService creates a new IndexMetadataService
+func NewIndexMetadataService(metadataRepo repository.IndexMetadataRepository, tripRepo repository.TripRepository) IndexMetadataService {
+	return &indexMetadataService{
+		metadataRepo: metadataRepo,
+		tripRepo:     tripRepo,
+	}
+}
+
+func (s *indexMetadataService) CheckSchema(ctx context.Context) (*domain.IndexMetadata, error) {
+	metadata, err := s.metadataRepo.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index metadata: %w", err)
+	}
+
+	if metadata == nil {
+		// No metadata and no trips: a new deployment, the index will be built by this binary
+		count, err := s.tripRepo.Count(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count trips: %w", err)
+		}
+		if count > 0 {
+			// Read model built before the metadata existed (schema version 0)
+			return nil, domain.ErrIndexSchemaOutdated
+		}
+
+		if err := s.metadataRepo.InitSchemaVersion(ctx, domain.IndexSchemaVersion); err != nil {
+			return nil, fmt.Errorf("failed to initialize index metadata: %w", err)
+		}
+		log.Info().Int("schema_version", domain.IndexSchemaVersion).Msg("Empty search read model, index metadata initialized")
+
+		// Another instance may have initialized it first: re-read what was stored
+		if metadata, err = s.metadataRepo.Get(ctx); err != nil {
+			return nil, fmt.Errorf("failed to read index metadata: %w", err)
+		}
+	}
+
+	if metadata.SchemaVersion < domain.IndexSchemaVersion {
+		return metadata, domain.ErrIndexSchemaOutdated
+	}
+	if metadata.SchemaVersion > domain.IndexSchemaVersion {
+		// Rolled back binary: the newer documents only add fields, keep consuming
+		log.Warn().
+			Int("stored_schema_version", metadata.SchemaVersion).
+			Int("expected_schema_version", domain.IndexSchemaVersion).
+			Msg("Search index was built by a newer version of search-api")
+	}
+	return metadata, nil
+}
+
+func (s *indexMetadataService) Status(ctx context.Context) (domain.IndexStatus, error) {
+	metadata, err := s.metadataRepo.Get(ctx)
+	if err != nil {
+		return domain.IndexStatus{}, err
+	}
+	return domain.NewIndexStatus(metadata, s.consuming.Load()), nil
+}
+
+func (s *indexMetadataService) SetConsuming(consuming bool) {
+	s.consuming.Store(consuming)
+}
+
+func (s *indexMetadataService) RebuildInProgress(ctx context.Context) bool {
+	s.fenceMu.Lock()
+	defer s.fenceMu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.fenceCheckedAt) < domain.RebuildFenceCheckInterval {
+		return s.fenced
+	}
+
+	metadata, err := s.metadataRepo.Get(ctx)
+	if err != nil {
+		// Keep the last known state: MongoDB errors also fail the event itself
+		log.Warn().Err(err).Msg("Failed to read the rebuild fence")
+		return s.fenced
+	}
+	s.fenced = metadata.RebuildFenced(now)
+	s.fenceCheckedAt = now
+	return s.fenced
+}
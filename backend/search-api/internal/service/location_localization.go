@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"os"
+
+	"search-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LoadLocationLocalizations builds the city/province display name table used by the search responses
+// The built-in names are always loaded; path (LOCATION_LOCALIZATION_FILE, empty = built-in only) adds to
+// and overrides them. Unlike the scorer weights, the file is read once at startup.
+func LoadLocationLocalizations(path, defaultLanguage string) (*domain.LocationLocalizations, error) {
+	if path == "" {
+		return domain.NewLocationLocalizations(defaultLanguage, nil), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read localization file: %w", err)
+	}
+
+	localizations, err := domain.ParseLocationLocalizations(data, defaultLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("path", path).
+		Str("default_language", localizations.DefaultLanguage()).
+		Msg("Location localization table loaded")
+	return localizations, nil
+}
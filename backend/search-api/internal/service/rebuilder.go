@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"search-api/internal/clients"
+	"search-api/internal/domain"
+	"search-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rebuildFenceTTL is how long the rebuild fence lasts without a renewal; it is renewed every
+// rebuildFenceTTL/4 while the rebuild runs, so it only expires when cmd/rebuild dies
+const rebuildFenceTTL = 2 * time.Minute
+
+// RebuildOptions configures a read model rebuild from the trips-api event archive
+type RebuildOptions struct {
+	// FromSequence is the first archive sequence to read; <= 1 rebuilds every trip in the archive,
+	// otherwise only the trips touched by the events from that sequence on are rebuilt or deleted
+	FromSequence int64
+	// DryRun folds the archive and fetches the trips but writes nothing to MongoDB or Solr
+	DryRun bool
+}
+
+// Rebuilder reconstructs the search MongoDB collection and the Solr index from the trips event archive
+//
+// The archive is folded in sequence order into the latest state of each trip (status and seats),
+// the static trip data comes from trips-api and the driver from the snapshot embedded in trip.created
+// (users-api only when the event had none). For the same archive and trips the result is always
+// the same documents: timestamps come from trips-api, never from the clock.
+//
+// Only trips the archive (or trips-api) knows are gone are deleted: a document the archive does
+// not mention is left alone and shows up as a count mismatch. While it writes, the rebuild holds
+// the fence in index_metadata so consumers hold trip events instead of racing it.
+type Rebuilder struct {
+	archive      repository.EventArchiveReader
+	tripRepo     repository.TripRepository
+	metadataRepo repository.IndexMetadataRepository
+	tripsClient  clients.TripsClient
+	usersClient  clients.UsersClient
+	solrClient   *clients.SolrClient
+	batchSize    int
+	fenceOwner   string // Identifies this run in the rebuild fence
+}
+
+// NewRebuilder creates a rebuilder that reads and indexes batchSize documents at a time
+// solrClient may be nil (only MongoDB is rebuilt)
+func NewRebuilder(
+	archive repository.EventArchiveReader,
+	tripRepo repository.TripRepository,
+	metadataRepo repository.IndexMetadataRepository,
+	tripsClient clients.TripsClient,
+	usersClient clients.UsersClient,
+	solrClient *clients.SolrClient,
+	batchSize int,
+) *Rebuilder {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &Rebuilder{
+		archive:      archive,
+		tripRepo:     tripRepo,
+		metadataRepo: metadataRepo,
+		tripsClient:  tripsClient,
+		usersClient:  usersClient,
+		solrClient:   solrClient,
+		batchSize:    batchSize,
+		fenceOwner:   primitive.NewObjectID().Hex(),
+	}
+}
+
+// Run executes the rebuild and verifies the resulting count against trips-api
+// A non-nil error means the rebuild was aborted; a report with CountsMatch == false or
+// failed trips means it finished but the read model does not fully match trips-api
+func (r *Rebuilder) Run(ctx context.Context, opts RebuildOptions) (*domain.RebuildReport, error) {
+	startedAt := time.Now()
+	fromSequence := opts.FromSequence
+	if fromSequence < 1 {
+		fromSequence = 1
+	}
+
+	report := &domain.RebuildReport{
+		FromSequence: fromSequence,
+		FullRebuild:  fromSequence == 1,
+		DryRun:       opts.DryRun,
+	}
+
+	// Fence the consumers for the whole run (a dry run writes nothing and needs no fence)
+	if !opts.DryRun {
+		fencedCtx, release, err := r.fence(ctx)
+		if err != nil {
+			return report, err
+		}
+		defer release()
+		ctx = fencedCtx
+	}
+
+	// Step 1: Fold the archive into the latest state of each trip
+	state, err := r.fold(ctx, fromSequence)
+	if err != nil {
+		return report, err
+	}
+	report.LastSequence = state.LastSequence
+	report.StoppedAtGap = state.StoppedAtGap
+	report.EventsApplied = state.EventsApplied
+	report.EventsIgnored = state.EventsIgnored
+
+	log.Info().
+		Int64("from_sequence", fromSequence).
+		Int64("last_sequence", state.LastSequence).
+		Int64("events_applied", state.EventsApplied).
+		Int("live_trips", len(state.LiveTripIDs())).
+		Int("deleted_trips", len(state.DeletedTripIDs())).
+		Msg("Event archive folded")
+
+	// Step 2: Rebuild every live trip
+	rebuiltIDs := make([]string, 0, len(state.Trips))
+	missingIDs := make([]string, 0)
+	pending := make([]*domain.SearchTrip, 0, r.batchSize)
+	for _, tripID := range state.LiveTripIDs() {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+
+		trip, err := r.buildTrip(ctx, state.Trips[tripID])
+		if err != nil {
+			if domain.IsNotFoundError(err) {
+				log.Warn().Err(err).Str("trip_id", tripID).Msg("Trip in the archive no longer exists, removing it")
+				missingIDs = append(missingIDs, tripID)
+				report.TripsSkipped++
+				continue
+			}
+			log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to rebuild trip")
+			report.TripsFailed++
+			continue
+		}
+
+		if !opts.DryRun {
+			if err := r.tripRepo.UpsertByTripID(ctx, trip); err != nil {
+				log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to store rebuilt trip")
+				report.TripsFailed++
+				continue
+			}
+		}
+		rebuiltIDs = append(rebuiltIDs, tripID)
+		report.TripsRebuilt++
+
+		pending = append(pending, trip)
+		if len(pending) == r.batchSize {
+			report.SolrFailed += r.indexBatch(ctx, pending, opts.DryRun)
+			pending = pending[:0]
+		}
+	}
+	report.SolrFailed += r.indexBatch(ctx, pending, opts.DryRun)
+
+	// Step 3: Remove the trips deleted in the archive and the ones trips-api no longer has
+	if err := r.removeTrips(ctx, append(state.DeletedTripIDs(), missingIDs...), report, opts.DryRun); err != nil {
+		return report, err
+	}
+
+	// Step 4: Make the new index visible
+	if !opts.DryRun && r.solrClient != nil {
+		if err := r.solrClient.Commit(ctx); err != nil {
+			return report, fmt.Errorf("solr commit failed: %w", err)
+		}
+	}
+
+	// Step 5: Verify against trips-api
+	if err := r.verify(ctx, report, int64(len(rebuiltIDs))); err != nil {
+		return report, err
+	}
+
+	report.Duration = time.Since(startedAt)
+	return report, nil
+}
+
+// fold reads the archive from fromSequence to the end, batchSize events at a time
+// It stops early at a gap that is not settled (see domain.SettledPrefix): the events after it are
+// recent, so the consumers apply them once the fence is released, and an append still in flight
+// cannot be skipped
+func (r *Rebuilder) fold(ctx context.Context, fromSequence int64) (*domain.RebuildState, error) {
+	state := domain.NewRebuildState()
+	state.LastSequence = fromSequence - 1
+
+	next := fromSequence
+	for {
+		events, err := r.archive.FindFromSequence(ctx, next, r.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(events) == 0 {
+			return state, nil
+		}
+
+		settled, open := domain.SettledPrefix(events, state.LastSequence, time.Now().Add(-domain.ArchiveSettleWindow))
+		for _, event := range settled {
+			if err := state.Apply(event); err != nil {
+				return nil, err
+			}
+		}
+		if open {
+			log.Warn().
+				Int64("last_sequence", state.LastSequence).
+				Msg("Event archive has a recent sequence gap, stopping the fold before it")
+			state.StoppedAtGap = true
+			return state, nil
+		}
+		next = events[len(events)-1].Sequence + 1
+
+		if len(events) < r.batchSize {
+			return state, nil
+		}
+	}
+}
+
+// buildTrip builds the SearchTrip of a live trip: static data from trips-api, status and
+// seats from the archive
+func (r *Rebuilder) buildTrip(ctx context.Context, tripState *domain.RebuildTripState) (*domain.SearchTrip, error) {
+	trip, err := r.tripsClient.GetTrip(ctx, tripState.TripID)
+	if err != nil {
+		return nil, err
+	}
+
+	var driver domain.Driver
+	if tripState.DriverSnapshot != nil && tripState.DriverSnapshot.ID == trip.DriverID {
+		driver = tripState.DriverSnapshot.ToDriver()
+	} else {
+		user, err := r.usersClient.GetUser(ctx, trip.DriverID)
+		if err != nil {
+			return nil, fmt.Errorf("fetch driver %d failed: %w", trip.DriverID, err)
+		}
+		driver = user.ToDriver()
+	}
+
+	searchTrip := trip.ToSearchTrip(driver)
+	tripState.ApplyTo(searchTrip)
+	searchTrip.PopularityScore = 0.0 // Same initial score as trip.created
+
+	return searchTrip, nil
+}
+
+// indexBatch sends rebuilt trips to Solr and returns how many could not be indexed
+func (r *Rebuilder) indexBatch(ctx context.Context, trips []*domain.SearchTrip, dryRun bool) int64 {
+	if dryRun || r.solrClient == nil || len(trips) == 0 {
+		return 0
+	}
+
+	if err := r.solrClient.IndexBatch(ctx, trips); err != nil {
+		log.Error().Err(err).Int("batch_size", len(trips)).Msg("Failed to index rebuilt batch in Solr")
+		return int64(len(trips))
+	}
+	return 0
+}
+
+// removeTrips deletes the given trips from MongoDB and Solr, batchSize trips per request
+// A dry run counts the trips it would delete
+func (r *Rebuilder) removeTrips(ctx context.Context, tripIDs []string, report *domain.RebuildReport, dryRun bool) error {
+	sort.Strings(tripIDs)
+	if dryRun {
+		report.TripsDeleted = int64(len(tripIDs))
+		return nil
+	}
+
+	for start := 0; start < len(tripIDs); start += r.batchSize {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		end := start + r.batchSize
+		if end > len(tripIDs) {
+			end = len(tripIDs)
+		}
+		batch := tripIDs[start:end]
+
+		deleted, err := r.tripRepo.DeleteByTripIDs(ctx, batch)
+		if err != nil {
+			log.Error().Err(err).Int("batch_size", len(batch)).Msg("Failed to delete trips")
+			report.TripsFailed += int64(len(batch))
+			continue
+		}
+		report.TripsDeleted += deleted
+
+		if r.solrClient != nil {
+			if err := r.solrClient.DeleteBatch(ctx, batch); err != nil {
+				report.SolrFailed += int64(len(batch))
+			}
+		}
+	}
+
+	return nil
+}
+
+// fence takes the rebuild fence and keeps renewing it until release is called
+// The returned context is cancelled if the fence is lost (it expired and another rebuild took it)
+// After taking it, waits until every consumer has seen it so no event is applied mid-rebuild
+func (r *Rebuilder) fence(ctx context.Context) (context.Context, func(), error) {
+	acquired, err := r.metadataRepo.AcquireRebuildFence(ctx, r.fenceOwner, time.Now().Add(rebuildFenceTTL))
+	if err != nil {
+		return ctx, nil, fmt.Errorf("failed to take the rebuild fence: %w", err)
+	}
+	if !acquired {
+		return ctx, nil, errors.New("another rebuild is running (rebuild fence held)")
+	}
+
+	fencedCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(rebuildFenceTTL / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-fencedCtx.Done():
+				return
+			case <-ticker.C:
+				acquired, err := r.metadataRepo.AcquireRebuildFence(fencedCtx, r.fenceOwner, time.Now().Add(rebuildFenceTTL))
+				if err != nil {
+					// A transient Mongo error does not stop the rebuild; the next tick renews it again
+					log.Warn().Err(err).Msg("Failed to renew the rebuild fence")
+					continue
+				}
+				if !acquired {
+					cancel(errors.New("rebuild fence taken by another rebuild, aborting"))
+					return
+				}
+			}
+		}
+	}()
+
+	release := func() {
+		close(done)
+		cancel(nil)
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer releaseCancel()
+		if err := r.metadataRepo.ReleaseRebuildFence(releaseCtx, r.fenceOwner); err != nil {
+			log.Error().Err(err).Msg("Failed to release the rebuild fence (it expires on its own)")
+		}
+	}
+
+	log.Info().Dur("wait", 2*domain.RebuildFenceCheckInterval).Msg("Rebuild fence taken, waiting for consumers to hold trip events")
+	select {
+	case <-fencedCtx.Done():
+		release()
+		return ctx, nil, context.Cause(fencedCtx)
+	case <-time.After(2 * domain.RebuildFenceCheckInterval):
+	}
+
+	return fencedCtx, release, nil
+}
+
+// verify compares the trips in the read model with the trips stored in trips-api
+func (r *Rebuilder) verify(ctx context.Context, report *domain.RebuildReport, rebuilt int64) error {
+	tripsAPICount, err := r.tripsClient.CountTrips(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count trips in trips-api: %w", err)
+	}
+	report.TripsAPICount = tripsAPICount
+
+	if report.DryRun {
+		// Nothing was written: compare what a full rebuild would store
+		report.MongoCount = rebuilt
+	} else {
+		mongoCount, err := r.tripRepo.Count(ctx)
+		if err != nil {
+			return err
+		}
+		report.MongoCount = mongoCount
+	}
+
+	report.CountsMatch = report.MongoCount == report.TripsAPICount
+	return nil
+}
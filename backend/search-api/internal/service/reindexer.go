@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"search-api/internal/clients"
+	"search-api/internal/domain"
+	"search-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxConsecutiveFailedBatches aborts the reindex when Solr keeps rejecting whole batches
+// (most likely Solr is down or the schema is broken, so continuing only marks everything as failed)
+const maxConsecutiveFailedBatches = 3
+
+// reindexLeaseTTL is how long the reindex lease lasts without a renewal; it is renewed after every
+// batch, so it only expires when the instance running the reindex dies
+const reindexLeaseTTL = 2 * time.Minute
+
+// Reindexer rebuilds the Solr index from the trips stored in MongoDB
+type Reindexer interface {
+	Start() (domain.ReindexStatus, error)
+	Status() domain.ReindexStatus
+	Stop()
+}
+
+type reindexer struct {
+	tripRepo         repository.TripRepository
+	metadataRepo     repository.IndexMetadataRepository
+	solrClient       *clients.SolrClient
+	batchSize        int
+	batchesPerSecond int
+	leaseOwner       string // Identifies this instance in the reindex lease shared by all replicas
+
+	mu     sync.Mutex
+	status domain.ReindexStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReindexer creates a reindexer that sends batchSize trips per Solr request,
+// at most batchesPerSecond requests per second (0 = no limit)
+// A reindex that completes without failed trips is recorded in the index metadata
+func NewReindexer(tripRepo repository.TripRepository, metadataRepo repository.IndexMetadataRepository, solrClient *clients.SolrClient, batchSize, batchesPerSecond int) Reindexer {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if batchesPerSecond < 0 {
+		batchesPerSecond = 0
+	}
+
+	return &reindexer{
+		tripRepo:         tripRepo,
+		metadataRepo:     metadataRepo,
+		solrClient:       solrClient,
+		batchSize:        batchSize,
+		batchesPerSecond: batchesPerSecond,
+		leaseOwner:       primitive.NewObjectID().Hex(),
+		status: domain.ReindexStatus{
+			State:            domain.ReindexStateIdle,
+			BatchSize:        batchSize,
+			BatchesPerSecond: batchesPerSecond,
+		},
+	}
+}
+
+// Start launches a reindex in the background and returns its initial status
+// Only one reindex can run at a time across all instances: a start while one is running is rejected
+// with ErrReindexInProgress (409), never queued
+func (r *reindexer) Start() (domain.ReindexStatus, error) {
+	if r.solrClient == nil {
+		return domain.ReindexStatus{}, domain.ErrSolrUnavailable
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.status.State == domain.ReindexStateRunning {
+		return r.status, domain.ErrReindexInProgress
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Another replica may be running one: the lease in the index metadata is shared by all of them
+	acquired, err := r.metadataRepo.AcquireReindexLease(ctx, r.leaseOwner, time.Now().Add(reindexLeaseTTL))
+	if err != nil {
+		cancel()
+		return r.status, fmt.Errorf("failed to take the reindex lease: %w", err)
+	}
+	if !acquired {
+		cancel()
+		return r.status, domain.ErrReindexInProgress
+	}
+
+	total, err := r.tripRepo.Count(ctx)
+	if err != nil {
+		cancel()
+		r.releaseLease()
+		return r.status, fmt.Errorf("failed to count trips: %w", err)
+	}
+
+	now := time.Now()
+	r.status = domain.ReindexStatus{
+		State:            domain.ReindexStateRunning,
+		Total:            total,
+		BatchSize:        r.batchSize,
+		BatchesPerSecond: r.batchesPerSecond,
+		StartedAt:        &now,
+	}
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	log.Info().
+		Int64("total", total).
+		Int("batch_size", r.batchSize).
+		Int("batches_per_second", r.batchesPerSecond).
+		Msg("Starting Solr reindex")
+
+	go r.run(ctx, r.done)
+
+	return r.status, nil
+}
+
+// Status returns a snapshot of the current (or last) reindex progress
+func (r *reindexer) Status() domain.ReindexStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// Stop cancels a running reindex and waits for it to finish (used on shutdown)
+func (r *reindexer) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// run scans the trips collection by _id and indexes each batch in Solr
+func (r *reindexer) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	var throttle <-chan time.Time
+	if r.batchesPerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(r.batchesPerSecond))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	afterID := primitive.NilObjectID
+	failedBatches := 0
+
+	for {
+		trips, err := r.tripRepo.FindBatchAfter(ctx, afterID, r.batchSize)
+		if err != nil {
+			r.finish(domain.ReindexStateFailed, err)
+			return
+		}
+		if len(trips) == 0 {
+			break
+		}
+		afterID = trips[len(trips)-1].ID
+
+		// Expired trips are counted as done but not sent to Solr
+		indexable := indexableTrips(trips)
+		indexed, failed := r.indexBatch(ctx, indexable)
+		indexed += int64(len(trips) - len(indexable))
+		r.addProgress(indexed, failed)
+
+		if err := r.renewLease(ctx); err != nil {
+			r.finish(domain.ReindexStateFailed, err)
+			return
+		}
+
+		if indexed == 0 {
+			failedBatches++
+			if failedBatches >= maxConsecutiveFailedBatches {
+				r.finish(domain.ReindexStateFailed, fmt.Errorf("%d consecutive batches failed, aborting", failedBatches))
+				return
+			}
+		} else {
+			failedBatches = 0
+		}
+
+		if len(trips) < r.batchSize {
+			break
+		}
+
+		if throttle != nil {
+			select {
+			case <-ctx.Done():
+			case <-throttle:
+			}
+		}
+		if ctx.Err() != nil {
+			r.finish(domain.ReindexStateFailed, fmt.Errorf("reindex cancelled"))
+			return
+		}
+	}
+
+	r.finish(domain.ReindexStateCompleted, nil)
+}
+
+// indexBatch sends a batch to Solr; when the batch is rejected it retries each trip on its
+// own so a single bad document does not fail the whole batch
+func (r *reindexer) indexBatch(ctx context.Context, trips []*domain.SearchTrip) (indexed, failed int64) {
+	err := r.solrClient.IndexBatch(ctx, trips)
+	if err == nil {
+		return int64(len(trips)), 0
+	}
+	if ctx.Err() != nil {
+		return 0, 0
+	}
+
+	log.Warn().Err(err).Int("batch_size", len(trips)).Msg("Solr rejected reindex batch, retrying trips one by one")
+	r.setLastError(err)
+
+	for _, trip := range trips {
+		if ctx.Err() != nil {
+			return indexed, failed
+		}
+		if err := r.solrClient.IndexBatch(ctx, []*domain.SearchTrip{trip}); err != nil {
+			log.Error().Err(err).Str("trip_id", trip.TripID).Msg("Failed to reindex trip in Solr")
+			r.setLastError(err)
+			failed++
+			continue
+		}
+		indexed++
+	}
+
+	return indexed, failed
+}
+
+// finish commits whatever was indexed and records the final state
+func (r *reindexer) finish(state string, err error) {
+	// Commit even after a failure so the trips already sent become searchable
+	commitCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if commitErr := r.solrClient.Commit(commitCtx); commitErr != nil {
+		state = domain.ReindexStateFailed
+		if err == nil {
+			err = fmt.Errorf("solr commit failed: %w", commitErr)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.status.State = state
+	r.status.FinishedAt = &now
+	if err != nil {
+		r.status.LastError = err.Error()
+	}
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+	r.releaseLease()
+
+	// Only a reindex that sent every trip counts as a full reindex of the index metadata
+	if state == domain.ReindexStateCompleted && r.status.Failed == 0 {
+		if err := r.metadataRepo.RecordFullReindex(commitCtx, domain.IndexRebuildSourceReindex, now); err != nil {
+			log.Error().Err(err).Msg("Failed to record the reindex in the index metadata")
+		}
+	}
+
+	event := log.Info()
+	if state == domain.ReindexStateFailed {
+		event = log.Error().Err(err)
+	}
+	event.
+		Str("state", state).
+		Int64("total", r.status.Total).
+		Int64("indexed", r.status.Indexed).
+		Int64("failed", r.status.Failed).
+		Dur("duration", now.Sub(*r.status.StartedAt)).
+		Msg("Solr reindex finished")
+}
+
+// renewLease extends the reindex lease; losing it (it expired and another instance took it) aborts the reindex
+func (r *reindexer) renewLease(ctx context.Context) error {
+	acquired, err := r.metadataRepo.AcquireReindexLease(ctx, r.leaseOwner, time.Now().Add(reindexLeaseTTL))
+	if err != nil {
+		// A transient Mongo error does not stop the reindex; the next batch renews it again
+		log.Warn().Err(err).Msg("Failed to renew the reindex lease")
+		return nil
+	}
+	if !acquired {
+		return fmt.Errorf("reindex lease taken by another instance, aborting")
+	}
+	return nil
+}
+
+// releaseLease lets other instances start a reindex again
+func (r *reindexer) releaseLease() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := r.metadataRepo.ReleaseReindexLease(ctx, r.leaseOwner); err != nil {
+		log.Error().Err(err).Msg("Failed to release the reindex lease (it expires on its own)")
+	}
+}
+
+func (r *reindexer) addProgress(indexed, failed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.Indexed += indexed
+	r.status.Failed += failed
+}
+
+func (r *reindexer) setLastError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.LastError = err.Error()
+}
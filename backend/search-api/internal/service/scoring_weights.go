@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"search-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ScoringWeightsStatus is the state of the scorer weights reported by GET /admin/ranking/weights
+type ScoringWeightsStatus struct {
+	Weights       domain.ScoringWeights `json:"weights"`
+	Source        string                `json:"source"` // "defaults" or the weights file path
+	LoadedAt      *time.Time            `json:"loaded_at,omitempty"`
+	LastError     string                `json:"last_error,omitempty"` // Last rejected version of the file
+	ReloadSeconds int                   `json:"reload_seconds,omitempty"`
+}
+
+// ScoringWeightsReloader keeps the scorer weights in sync with a JSON weights file
+//
+// The file is re-read when its modification time changes, so weights are tuned by editing the
+// file (e.g. a mounted ConfigMap) without redeploying. An invalid file is logged and ignored:
+// the previous weights stay in use.
+type ScoringWeightsReloader struct {
+	store    *domain.ScoringWeightsStore
+	path     string
+	interval time.Duration
+
+	mu        sync.Mutex
+	modTime   time.Time
+	loadedAt  *time.Time
+	lastError string
+}
+
+// NewScoringWeightsReloader creates a reloader for path (empty = weights are never reloaded)
+func NewScoringWeightsReloader(store *domain.ScoringWeightsStore, path string, interval time.Duration) *ScoringWeightsReloader {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &ScoringWeightsReloader{
+		store:    store,
+		path:     path,
+		interval: interval,
+	}
+}
+
+// Load reads the weights file if it changed since the last load
+func (r *ScoringWeightsReloader) Load() error {
+	if r.path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return r.fail(fmt.Errorf("stat weights file: %w", err))
+	}
+
+	r.mu.Lock()
+	unchanged := info.ModTime().Equal(r.modTime)
+	r.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return r.fail(fmt.Errorf("read weights file: %w", err))
+	}
+
+	// Fields missing from the file keep their default value
+	weights := domain.DefaultScoringWeights
+	if err := json.Unmarshal(data, &weights); err != nil {
+		return r.fail(fmt.Errorf("parse weights file: %w", err))
+	}
+	if err := r.store.Set(weights); err != nil {
+		return r.fail(fmt.Errorf("invalid weights file: %w", err))
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	r.modTime = info.ModTime()
+	r.loadedAt = &now
+	r.lastError = ""
+	r.mu.Unlock()
+
+	log.Info().
+		Str("path", r.path).
+		Interface("weights", weights).
+		Msg("Ranking scorer weights loaded")
+	return nil
+}
+
+// Start re-reads the weights file every interval until ctx is cancelled
+func (r *ScoringWeightsReloader) Start(ctx context.Context) {
+	if r.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Load(); err != nil {
+				log.Warn().Err(err).Str("path", r.path).Msg("Keeping previous ranking scorer weights")
+			}
+		}
+	}
+}
+
+// Status returns the weights in use and where they come from
+func (r *ScoringWeightsReloader) Status() ScoringWeightsStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := ScoringWeightsStatus{
+		Weights:   r.store.Get(),
+		Source:    "defaults",
+		LoadedAt:  r.loadedAt,
+		LastError: r.lastError,
+	}
+	if r.path != "" {
+		status.Source = r.path
+		status.ReloadSeconds = int(r.interval.Seconds())
+	}
+	return status
+}
+
+func (r *ScoringWeightsReloader) fail(err error) error {
+	r.mu.Lock()
+	r.lastError = err.Error()
+	r.mu.Unlock()
+	return err
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -43,6 +43,18 @@ type SearchService interface {
 
 	// InvalidateCache removes cached data for a specific trip
 	InvalidateCache(ctx context.Context, tripID string) error
+
+	// GetSlowQueries returns the most recent searches that exceeded the latency threshold
+	GetSlowQueries(ctx context.Context, limit int, minDurationMs int64) ([]domain.SlowQuery, error)
+
+	// GetShadowReadStats returns the Solr vs MongoDB shadow-read divergence counters
+	GetShadowReadStats() domain.ShadowReadStats
+
+	// GetCacheStats returns the cache hit/miss/coalesced counters of the search paths
+	GetCacheStats() domain.CacheStats
+
+	// GetIndexWarmStats returns the Solr searcher warm-up and cache statistics
+	GetIndexWarmStats(ctx context.Context) (*domain.IndexWarmStats, error)
 }
 
 // searchService implements SearchService
@@ -53,7 +65,27 @@ type searchService struct {
 	solrClient       *clients.SolrClient
 	tripsClient      clients.TripsClient
 	usersClient      clients.UsersClient
+	slowQueryRepo    repository.SlowQueryRepository
+	slowThreshold    time.Duration
+	shadow           *shadowReader
 	cacheTTL         time.Duration
+	loader           *cacheLoader // Cache reads/writes of SearchTrips, SearchByLocation and GetTrip
+
+	// Read-through to trips-api when GetTrip misses the local index
+	readThrough            bool
+	readThroughNegativeTTL time.Duration
+
+	// Ranking strategies selectable with ranking=, and the one used when none is given
+	rankings       domain.RankingStrategies
+	defaultRanking string
+}
+
+// searchTrace collects the compiled filters of a search for the slow query log
+type searchTrace struct {
+	solrQuery       string
+	solrFilters     []string
+	mongoFilters    []string
+	hydrateDuration time.Duration // Fetching the Solr result page from MongoDB
 }
 
 // NewSearchService creates a new SearchService instance
@@ -64,7 +96,18 @@ func NewSearchService(
 	solrClient *clients.SolrClient,
 	tripsClient clients.TripsClient,
 	usersClient clients.UsersClient,
+	slowQueryRepo repository.SlowQueryRepository,
+	slowThreshold time.Duration,
+	shadowSamplePercent int,
+	shadowMaxInFlight int,
+	readThrough bool,
+	readThroughNegativeTTL time.Duration,
+	rankings domain.RankingStrategies,
+	defaultRanking string,
+	cacheStaleWindow time.Duration,
 ) SearchService {
+	cacheTTL := 10 * time.Minute // Default cache TTL
+
 	return &searchService{
 		tripRepo:         tripRepo,
 		popularRouteRepo: popularRouteRepo,
@@ -72,7 +115,17 @@ func NewSearchService(
 		solrClient:       solrClient,
 		tripsClient:      tripsClient,
 		usersClient:      usersClient,
-		cacheTTL:         10 * time.Minute, // Default cache TTL
+		slowQueryRepo:    slowQueryRepo,
+		slowThreshold:    slowThreshold,
+		shadow:           newShadowReader(shadowSamplePercent, shadowMaxInFlight),
+		cacheTTL:         cacheTTL,
+		loader:           newCacheLoader(cache, cacheTTL, cacheStaleWindow),
+
+		readThrough:            readThrough,
+		readThroughNegativeTTL: readThroughNegativeTTL,
+
+		rankings:       rankings,
+		defaultRanking: defaultRanking,
 	}
 }
 
@@ -86,30 +139,53 @@ func (s *searchService) SearchTrips(ctx context.Context, query *domain.SearchQue
 	}
 	query.SetDefaults()
 
+	strategy, err := s.rankingStrategy(query)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate cache key
 	cacheKey := s.buildSearchCacheKey(query)
 
-	// Step 1: Try Cache
-	if cached, err := s.getFromCache(ctx, cacheKey); err == nil && cached != nil {
-		log.Debug().
-			Str("cache_key", cacheKey).
-			Dur("duration_ms", time.Since(startTime)).
-			Msg("Cache hit for search query")
+	// Step 1: Try Cache (concurrent misses for the same query share a single search)
+	response, err := loadCached(ctx, s.loader, cacheKey, func(ctx context.Context) (*domain.SearchResponse, error) {
+		return s.searchTripsUncached(ctx, query, strategy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().
+		Str("cache_key", cacheKey).
+		Dur("duration_ms", time.Since(startTime)).
+		Msg("Search served")
 
-		// Track popular routes asynchronously
-		go s.trackPopularRoute(context.Background(), query)
+	// Track popular routes asynchronously
+	go s.trackPopularRoute(context.Background(), query)
 
-		return cached, nil
-	}
+	return response, nil
+}
+
+// searchTripsUncached runs a search against Solr, falling back to MongoDB (steps 2 and 3)
+func (s *searchService) searchTripsUncached(ctx context.Context, query *domain.SearchQuery, strategy domain.RankingStrategy) (*domain.SearchResponse, error) {
+	startTime := time.Now()
 
+	var err error
 	var trips []*domain.SearchTrip
 	var total int64
-	var err error
+	var facets *domain.SearchFacets
+	var histogram *domain.PriceHistogram
 	var source string
+	trace := &searchTrace{}
+
+	// A ranking strategy re-orders the top RankingWindow results, so the backend returns the
+	// whole window and the requested page is cut after ranking
+	backendQuery := rankingBackendQuery(query, strategy)
 
 	// Step 2: Try Solr (for non-geospatial queries)
-	if !query.IsGeospatial() && s.solrClient != nil {
-		trips, total, err = s.searchWithSolr(ctx, query)
+	// Expired trips are removed from Solr, so searches including past trips go to MongoDB
+	if !query.IsGeospatial() && !query.IncludePast && s.solrClient != nil {
+		trips, total, facets, histogram, err = s.searchWithSolr(ctx, backendQuery, trace)
 		if err == nil {
 			source = "solr"
 		} else {
@@ -119,39 +195,66 @@ func (s *searchService) SearchTrips(ctx context.Context, query *domain.SearchQue
 
 	// Step 3: Fallback to MongoDB (or if geospatial)
 	if trips == nil {
-		trips, total, err = s.searchWithMongoDB(ctx, query)
+		trips, total, err = s.searchWithMongoDB(ctx, backendQuery, trace)
 		if err != nil {
 			log.Error().Err(err).Interface("query", query).Msg("MongoDB search failed")
 			return nil, fmt.Errorf("search failed: %w", err)
 		}
 		source = "mongodb"
+
+		if query.Facets {
+			facets = s.mongoFacets(ctx, query)
+		}
+		if query.PriceHistogram {
+			histogram = s.mongoPriceHistogram(ctx, query)
+		}
 	}
 
-	// Build response
-	response := s.buildSearchResponse(trips, total, query.Page, query.Limit)
+	// Shadow read: compare against MongoDB for a sample of Solr-served queries (background only)
+	s.maybeShadowRead(backendQuery, source, trips, total)
 
-	// Cache the result
-	if err := s.cacheSearchResult(ctx, cacheKey, response); err != nil {
-		log.Warn().Err(err).Msg("Failed to cache search result")
+	if backendQuery != query {
+		strategy.Rank(trips, time.Now())
+		trips = pageOf(trips, query.Page, query.Limit)
 	}
 
-	// Track popular routes asynchronously
-	go s.trackPopularRoute(context.Background(), query)
+	// Build response
+	response := s.buildSearchResponse(trips, total, query.Page, query.Limit)
+	response.Facets = facets
+	response.PriceHistogram = histogram
+
+	elapsed := time.Since(startTime)
 
 	log.Info().
 		Str("source", source).
 		Int("results", len(trips)).
 		Int64("total", total).
-		Dur("duration_ms", time.Since(startTime)).
+		Dur("duration_ms", elapsed).
 		Msg("Search completed")
 
+	// Record slow searches asynchronously
+	if s.slowQueryRepo != nil && s.slowThreshold > 0 && elapsed >= s.slowThreshold {
+		entry := &domain.SlowQuery{
+			Source:       source,
+			DurationMs:   elapsed.Milliseconds(),
+			ThresholdMs:  s.slowThreshold.Milliseconds(),
+			QueryHash:    query.Hash(),
+			Query:        query,
+			SolrQuery:    trace.solrQuery,
+			SolrFilters:  trace.solrFilters,
+			MongoFilters: trace.mongoFilters,
+			HydrateMs:    trace.hydrateDuration.Milliseconds(),
+			ResultsCount: len(trips),
+			Total:        total,
+		}
+		go s.recordSlowQuery(context.Background(), entry)
+	}
+
 	return response, nil
 }
 
 // SearchByLocation performs geospatial search using MongoDB
 func (s *searchService) SearchByLocation(ctx context.Context, lat, lng float64, radiusKm int, filters map[string]interface{}) (*domain.SearchResponse, error) {
-	startTime := time.Now()
-
 	// Validate coordinates
 	if lat < -90 || lat > 90 {
 		return nil, fmt.Errorf("latitude must be between -90 and 90")
@@ -166,14 +269,15 @@ func (s *searchService) SearchByLocation(ctx context.Context, lat, lng float64,
 	// Generate cache key for geospatial query
 	cacheKey := s.buildLocationCacheKey(lat, lng, radiusKm, filters)
 
-	// Try cache first
-	if cached, err := s.getFromCache(ctx, cacheKey); err == nil && cached != nil {
-		log.Debug().
-			Str("cache_key", cacheKey).
-			Dur("duration_ms", time.Since(startTime)).
-			Msg("Cache hit for location search")
-		return cached, nil
-	}
+	// Try cache first (concurrent misses for the same area share a single query)
+	return loadCached(ctx, s.loader, cacheKey, func(ctx context.Context) (*domain.SearchResponse, error) {
+		return s.searchByLocationUncached(ctx, lat, lng, radiusKm, filters)
+	})
+}
+
+// searchByLocationUncached runs a geospatial search against MongoDB
+func (s *searchService) searchByLocationUncached(ctx context.Context, lat, lng float64, radiusKm int, filters map[string]interface{}) (*domain.SearchResponse, error) {
+	startTime := time.Now()
 
 	// MongoDB geospatial search
 	trips, err := s.tripRepo.SearchByLocation(ctx, lat, lng, radiusKm, filters)
@@ -187,6 +291,9 @@ func (s *searchService) SearchByLocation(ctx context.Context, lat, lng float64,
 		return nil, fmt.Errorf("geospatial search failed: %w", err)
 	}
 
+	// Report which boarding point (origin or pickup point) matched each trip
+	annotatePickupMatches(trips, lat, lng, float64(radiusKm))
+
 	// Build response (geospatial doesn't have total count from repo)
 	response := &domain.SearchResponse{
 		Trips:      trips,
@@ -196,11 +303,6 @@ func (s *searchService) SearchByLocation(ctx context.Context, lat, lng float64,
 		TotalPages: 1,
 	}
 
-	// Cache the result
-	if err := s.cacheSearchResult(ctx, cacheKey, response); err != nil {
-		log.Warn().Err(err).Msg("Failed to cache location search result")
-	}
-
 	log.Info().
 		Int("results", len(trips)).
 		Dur("duration_ms", time.Since(startTime)).
@@ -211,17 +313,15 @@ func (s *searchService) SearchByLocation(ctx context.Context, lat, lng float64,
 
 // GetTrip retrieves a single trip with caching
 func (s *searchService) GetTrip(ctx context.Context, tripID string) (*domain.SearchTrip, error) {
-	startTime := time.Now()
+	// Try cache first (concurrent misses for the same trip share a single lookup)
+	return loadCached(ctx, s.loader, s.buildTripCacheKey(tripID), func(ctx context.Context) (*domain.SearchTrip, error) {
+		return s.getTripUncached(ctx, tripID)
+	})
+}
 
-	// Try cache first
-	cacheKey := s.buildTripCacheKey(tripID)
-	if cached, err := s.getTripFromCache(ctx, cacheKey); err == nil && cached != nil {
-		log.Debug().
-			Str("trip_id", tripID).
-			Dur("duration_ms", time.Since(startTime)).
-			Msg("Cache hit for trip")
-		return cached, nil
-	}
+// getTripUncached looks a trip up in MongoDB (by _id or trip_id), reading through to trips-api on a miss
+func (s *searchService) getTripUncached(ctx context.Context, tripID string) (*domain.SearchTrip, error) {
+	startTime := time.Now()
 
 	// Fetch from MongoDB
 	trip, err := s.tripRepo.FindByID(ctx, tripID)
@@ -233,13 +333,31 @@ func (s *searchService) GetTrip(ctx context.Context, tripID string) (*domain.Sea
 		return nil, fmt.Errorf("failed to fetch trip: %w", err)
 	}
 
+	// The ID may also be the trips-api ID (trip_id)
 	if trip == nil {
-		return nil, fmt.Errorf("trip not found")
+		trip, err = s.tripRepo.FindByTripID(ctx, tripID)
+		if err == domain.ErrSearchTripNotFound {
+			trip, err = nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch trip: %w", err)
+		}
+	}
+
+	// Index miss: the trip may exist in trips-api but its event never reached us
+	if trip == nil && s.readThrough {
+		trip, err = s.readThroughTrip(ctx, tripID)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("trip_id", tripID).
+				Msg("Trip read-through failed")
+			return nil, err
+		}
 	}
 
-	// Cache the trip
-	if err := s.cacheTripData(ctx, cacheKey, trip); err != nil {
-		log.Warn().Err(err).Msg("Failed to cache trip")
+	if trip == nil {
+		return nil, fmt.Errorf("trip not found")
 	}
 
 	log.Debug().
@@ -347,6 +465,8 @@ func (s *searchService) DenormalizeTrip(ctx context.Context, tripID string) erro
 		Preferences:              trip.Preferences,
 		Status:                   trip.Status,
 		Description:              trip.Description,
+		DescriptionText:          trip.DescriptionText,
+		Bookable:                 domain.IsBookable(trip.Status, trip.AvailableSeats),
 		CreatedAt:                trip.CreatedAt,
 		UpdatedAt:                trip.UpdatedAt,
 	}
@@ -385,6 +505,54 @@ func (s *searchService) DenormalizeTrip(ctx context.Context, tripID string) erro
 	return nil
 }
 
+// GetSlowQueries returns the most recent entries of the slow query log
+func (s *searchService) GetSlowQueries(ctx context.Context, limit int, minDurationMs int64) ([]domain.SlowQuery, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	entries, err := s.slowQueryRepo.FindRecent(ctx, limit, minDurationMs)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Int("limit", limit).
+			Msg("Failed to fetch slow queries")
+		return nil, fmt.Errorf("failed to fetch slow queries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetShadowReadStats returns the Solr vs MongoDB shadow-read divergence counters
+func (s *searchService) GetShadowReadStats() domain.ShadowReadStats {
+	return s.shadow.stats()
+}
+
+// GetCacheStats returns the cache hit/miss/coalesced counters of the search paths
+func (s *searchService) GetCacheStats() domain.CacheStats {
+	return s.loader.stats()
+}
+
+// GetIndexWarmStats returns the Solr searcher warm-up and cache statistics
+// ErrSolrUnavailable when Solr is disabled or does not answer
+func (s *searchService) GetIndexWarmStats(ctx context.Context) (*domain.IndexWarmStats, error) {
+	if s.solrClient == nil {
+		return nil, domain.ErrSolrUnavailable
+	}
+
+	stats, err := s.solrClient.WarmStats(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read Solr warm statistics")
+		return nil, domain.ErrSolrUnavailable
+	}
+
+	stats.Summarize(time.Now())
+	return stats, nil
+}
+
 // InvalidateCache removes cached data for a specific trip
 func (s *searchService) InvalidateCache(ctx context.Context, tripID string) error {
 	cacheKey := s.buildTripCacheKey(tripID)
@@ -422,58 +590,9 @@ func (s *searchService) buildTripCacheKey(tripID string) string {
 	return fmt.Sprintf("trip:%s", tripID)
 }
 
-// getFromCache retrieves search results from cache
-func (s *searchService) getFromCache(ctx context.Context, cacheKey string) (*domain.SearchResponse, error) {
-	data, err := s.cache.Get(ctx, cacheKey)
-	if err != nil {
-		return nil, err
-	}
-
-	var response domain.SearchResponse
-	if err := json.Unmarshal([]byte(data), &response); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
-}
-
-// getTripFromCache retrieves a single trip from cache
-func (s *searchService) getTripFromCache(ctx context.Context, cacheKey string) (*domain.SearchTrip, error) {
-	data, err := s.cache.Get(ctx, cacheKey)
-	if err != nil {
-		return nil, err
-	}
-
-	var trip domain.SearchTrip
-	if err := json.Unmarshal([]byte(data), &trip); err != nil {
-		return nil, err
-	}
-
-	return &trip, nil
-}
-
-// cacheSearchResult stores search results in cache
-func (s *searchService) cacheSearchResult(ctx context.Context, cacheKey string, response *domain.SearchResponse) error {
-	data, err := json.Marshal(response)
-	if err != nil {
-		return err
-	}
-
-	return s.cache.Set(ctx, cacheKey, string(data), s.cacheTTL)
-}
-
-// cacheTripData stores a single trip in cache
-func (s *searchService) cacheTripData(ctx context.Context, cacheKey string, trip *domain.SearchTrip) error {
-	data, err := json.Marshal(trip)
-	if err != nil {
-		return err
-	}
-
-	return s.cache.Set(ctx, cacheKey, string(data), s.cacheTTL)
-}
-
 // searchWithSolr performs search using Apache Solr
-func (s *searchService) searchWithSolr(ctx context.Context, query *domain.SearchQuery) ([]*domain.SearchTrip, int64, error) {
+// Facets and the price histogram are returned only when the query requests them
+func (s *searchService) searchWithSolr(ctx context.Context, query *domain.SearchQuery, trace *searchTrace) ([]*domain.SearchTrip, int64, *domain.SearchFacets, *domain.PriceHistogram, error) {
 	queryStr := "*:*"
 	if query.SearchText != "" {
 		queryStr = fmt.Sprintf("search_text:%s", query.SearchText)
@@ -481,12 +600,19 @@ func (s *searchService) searchWithSolr(ctx context.Context, query *domain.Search
 
 	// Build filters map (igual que antes)
 	filters := make(map[string]interface{})
-	filters["status"] = "published"
+	if query.OnlyBookable() {
+		filters["bookable"] = true
+	} else {
+		filters["status"] = query.VisibleStatuses()
+	}
 
 	if query.Origin != nil {
 		if query.Origin.City != "" {
 			filters["origin_city"] = query.Origin.City
 		}
+		if query.Origin.CityID != "" {
+			filters["origin_city_id"] = query.Origin.CityID
+		}
 		if query.Origin.Province != "" {
 			filters["origin_province"] = query.Origin.Province
 		}
@@ -496,24 +622,24 @@ func (s *searchService) searchWithSolr(ctx context.Context, query *domain.Search
 		if query.Destination.City != "" {
 			filters["destination_city"] = query.Destination.City
 		}
+		if query.Destination.CityID != "" {
+			filters["destination_city_id"] = query.Destination.CityID
+		}
 		if query.Destination.Province != "" {
 			filters["destination_province"] = query.Destination.Province
 		}
 	}
 
-	if query.DepartureDate != nil {
-		startOfDay := query.DepartureDate.Truncate(24 * time.Hour)
-		endOfDay := startOfDay.Add(24 * time.Hour)
-		filters["departure_datetime"] = fmt.Sprintf("[%s TO %s]",
-			startOfDay.Format(time.RFC3339),
-			endOfDay.Format(time.RFC3339))
+	// Departed trips are excluded even before the expiry job marks them
+	if from, to := query.DepartureRange(time.Now().UTC()); !from.IsZero() || !to.IsZero() {
+		filters["departure_datetime"] = solrDateRange(from, to)
 	}
 
 	if query.MinSeats > 0 {
 		filters["available_seats"] = fmt.Sprintf("[%d TO *]", query.MinSeats)
 	}
-	if query.MaxPrice > 0 {
-		filters["price_per_seat"] = fmt.Sprintf("[* TO %f]", query.MaxPrice)
+	if query.MinPrice > 0 || query.MaxPrice > 0 {
+		filters["price_per_seat"] = solrPriceRange(query.MinPrice, query.MaxPrice)
 	}
 	if query.MinDriverRating > 0 {
 		filters["driver_rating"] = fmt.Sprintf("[%f TO *]", query.MinDriverRating)
@@ -529,10 +655,15 @@ func (s *searchService) searchWithSolr(ctx context.Context, query *domain.Search
 		filters["music_allowed"] = *query.MusicAllowed
 	}
 
+	// Keep the compiled fq params for the slow query log
+	trace.solrQuery = queryStr
+	trace.solrFilters = s.solrClient.FilterQueries(filters, false)
+	sort.Strings(trace.solrFilters)
+
 	// ===== NUEVO: Pasar sorting a Solr =====
-	docs, total, err := s.solrClient.Search(ctx, queryStr, filters, query.Page, query.Limit, query.SortBy, query.SortOrder)
+	docs, total, facets, histogram, err := s.solrClient.Search(ctx, queryStr, filters, query.Page, query.Limit, query.SortCriteria(), query.Facets, query.PriceHistogram)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, nil, err
 	}
 
 	// Extract trip IDs y fetch de MongoDB (igual que antes)
@@ -544,43 +675,172 @@ func (s *searchService) searchWithSolr(ctx context.Context, query *domain.Search
 	}
 
 	if len(tripIDs) == 0 {
-		return []*domain.SearchTrip{}, 0, nil
+		return []*domain.SearchTrip{}, 0, facets, histogram, nil
 	}
 
-	trips := make([]*domain.SearchTrip, 0, len(tripIDs))
-	for _, tripID := range tripIDs {
-		trip, err := s.tripRepo.FindByTripID(ctx, tripID)
-		if err != nil {
-			log.Warn().Err(err).Str("trip_id", tripID).Msg("Failed to fetch trip, skipping")
-			continue
+	// Hydrate the page from MongoDB in a single $in query, keeping Solr's ranking
+	hydrateStart := time.Now()
+	found, err := s.tripRepo.FindByTripIDs(ctx, tripIDs)
+	if err != nil {
+		return nil, 0, nil, nil, fmt.Errorf("failed to fetch trips from MongoDB: %w", err)
+	}
+	trace.hydrateDuration = time.Since(hydrateStart)
+
+	trips := orderByTripIDs(found, tripIDs)
+	if missing := len(tripIDs) - len(trips); missing > 0 {
+		log.Warn().Int("missing", missing).Msg("Trips indexed in Solr not found in MongoDB, skipping")
+	}
+
+	log.Debug().
+		Int("trips", len(trips)).
+		Dur("hydrate_ms", trace.hydrateDuration).
+		Msg("Hydrated Solr results from MongoDB")
+
+	return trips, int64(total), facets, histogram, nil
+}
+
+// rankingStrategy resolves the ranking strategy of the query, filling in the default one
+func (s *searchService) rankingStrategy(query *domain.SearchQuery) (domain.RankingStrategy, error) {
+	if query.Ranking == "" {
+		query.Ranking = s.defaultRanking
+	}
+	if query.Ranking == "" {
+		query.Ranking = domain.RankingDefault
+	}
+
+	strategy, ok := s.rankings.Get(query.Ranking)
+	if !ok {
+		return nil, domain.NewAppError("INVALID_QUERY", fmt.Sprintf("invalid ranking: %s", query.Ranking), map[string]interface{}{
+			"available": s.rankings.Names(),
+		})
+	}
+	return strategy, nil
+}
+
+// rankingBackendQuery returns the query to send to Solr/MongoDB: the first RankingWindow
+// results when the strategy re-orders them, or the query itself for the default ranking
+// and for pages beyond the window (those keep the backend order)
+func rankingBackendQuery(query *domain.SearchQuery, strategy domain.RankingStrategy) *domain.SearchQuery {
+	if strategy.Name() == domain.RankingDefault || query.Page*query.Limit > domain.RankingWindow {
+		return query
+	}
+
+	window := *query
+	window.Page = 1
+	window.Limit = domain.RankingWindow
+	return &window
+}
+
+// pageOf returns the page of an already ranked result window
+func pageOf(trips []*domain.SearchTrip, page, limit int) []*domain.SearchTrip {
+	start := (page - 1) * limit
+	if start >= len(trips) {
+		return []*domain.SearchTrip{}
+	}
+	end := start + limit
+	if end > len(trips) {
+		end = len(trips)
+	}
+	return trips[start:end]
+}
+
+// solrPriceRange builds the price_per_seat range filter; 0 leaves that side open
+func solrPriceRange(minPrice, maxPrice float64) string {
+	lower, upper := "*", "*"
+	if minPrice > 0 {
+		lower = fmt.Sprintf("%f", minPrice)
+	}
+	if maxPrice > 0 {
+		upper = fmt.Sprintf("%f", maxPrice)
+	}
+	return fmt.Sprintf("[%s TO %s]", lower, upper)
+}
+
+// solrDateRange builds a [from TO to} range filter; a zero time leaves that side open
+func solrDateRange(from, to time.Time) string {
+	lower, upper := "*", "*"
+	if !from.IsZero() {
+		lower = from.Format(time.RFC3339)
+	}
+	if !to.IsZero() {
+		upper = to.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("[%s TO %s}", lower, upper)
+}
+
+// orderByTripIDs returns the trips in the order of tripIDs (the Solr ranking)
+// IDs without a trip are skipped
+func orderByTripIDs(trips []*domain.SearchTrip, tripIDs []string) []*domain.SearchTrip {
+	byID := make(map[string]*domain.SearchTrip, len(trips))
+	for _, trip := range trips {
+		byID[trip.TripID] = trip
+	}
+
+	ordered := make([]*domain.SearchTrip, 0, len(tripIDs))
+	for _, id := range tripIDs {
+		if trip, ok := byID[id]; ok {
+			ordered = append(ordered, trip)
 		}
-		trips = append(trips, trip)
+	}
+	return ordered
+}
+
+// mongoFacets computes the facets with MongoDB when the search was not served by Solr
+// Geospatial queries get no facets ($near cannot be used in an aggregation $match);
+// failures are logged and the search is returned without facets
+func (s *searchService) mongoFacets(ctx context.Context, query *domain.SearchQuery) *domain.SearchFacets {
+	if query.IsGeospatial() {
+		return nil
 	}
 
-	return trips, int64(total), nil
+	facets, err := s.tripRepo.Facets(ctx, s.buildMongoFilters(query, false))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to compute search facets in MongoDB")
+		return nil
+	}
+	return facets
+}
+
+// mongoPriceHistogram computes the price histogram with MongoDB when the search was not served by Solr
+// The price filter is dropped so the histogram covers the whole range; like the facets,
+// geospatial queries get no histogram and failures are logged
+func (s *searchService) mongoPriceHistogram(ctx context.Context, query *domain.SearchQuery) *domain.PriceHistogram {
+	if query.IsGeospatial() {
+		return nil
+	}
+
+	filters := s.buildMongoFilters(query, false)
+	delete(filters, "price_per_seat")
+
+	histogram, err := s.tripRepo.PriceHistogram(ctx, filters)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to compute price histogram in MongoDB")
+		return nil
+	}
+	return histogram
 }
 
 // searchWithMongoDB performs search using MongoDB with two-phase strategy:
 // 1. Try exact match first
 // 2. If no results and city filters are present, try partial match
 // searchWithMongoDB con sorting
-func (s *searchService) searchWithMongoDB(ctx context.Context, query *domain.SearchQuery) ([]*domain.SearchTrip, int64, error) {
+func (s *searchService) searchWithMongoDB(ctx context.Context, query *domain.SearchQuery, trace *searchTrace) ([]*domain.SearchTrip, int64, error) {
 	// Phase 1: Exact match
 	filters := s.buildMongoFilters(query, false)
+	trace.addMongoFilters(filters)
 
-	// Determine sorting parameters
-	// MongoDB's $near operator automatically sorts by distance, so we skip sorting for geospatial queries
-	sortBy := query.SortBy
-	sortOrder := query.SortOrder
+	// Determine sorting criteria
+	// Geospatial queries run as a $geoNear aggregation that sorts by distance, so we skip sorting for them
+	sort := query.SortCriteria()
 	if query.IsGeospatial() {
-		sortBy = ""
-		sortOrder = ""
+		sort = nil
 	}
 
-	trips, total, err := s.tripRepo.Search(ctx, filters, query.Page, query.Limit, sortBy, sortOrder)
+	trips, total, err := s.tripRepo.Search(ctx, filters, query.Page, query.Limit, sort)
 	if err != nil {
 		return nil, 0, err
 	}
+	annotateOriginMatches(trips, query)
 
 	// Check if we should try partial match
 	hasOriginCity := query.Origin != nil && query.Origin.City != ""
@@ -595,8 +855,9 @@ func (s *searchService) searchWithMongoDB(ctx context.Context, query *domain.Sea
 	log.Debug().Msg("No exact match, trying partial match on city names")
 
 	filtersPartial := s.buildMongoFilters(query, true)
+	trace.addMongoFilters(filtersPartial)
 
-	trips, total, err = s.tripRepo.Search(ctx, filtersPartial, query.Page, query.Limit, sortBy, sortOrder)
+	trips, total, err = s.tripRepo.Search(ctx, filtersPartial, query.Page, query.Limit, sort)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -604,6 +865,64 @@ func (s *searchService) searchWithMongoDB(ctx context.Context, query *domain.Sea
 	return trips, total, nil
 }
 
+// annotateOriginMatches sets MatchedPickupPoint on each trip when the query has an origin radius
+func annotateOriginMatches(trips []*domain.SearchTrip, query *domain.SearchQuery) {
+	if query.Origin == nil || len(query.Origin.Coordinates.Coordinates) != 2 || query.OriginRadius <= 0 {
+		return
+	}
+	annotatePickupMatches(trips, query.Origin.Coordinates.Lat(), query.Origin.Coordinates.Lng(), float64(query.OriginRadius))
+}
+
+// annotatePickupMatches sets MatchedPickupPoint to the boarding point closest to (lat, lng)
+func annotatePickupMatches(trips []*domain.SearchTrip, lat, lng, radiusKm float64) {
+	for _, trip := range trips {
+		trip.MatchedPickupPoint = trip.NearestBoardingPoint(lat, lng, radiusKm)
+	}
+}
+
+// addCityFilter adds the city filter of one side of the trip ("origin" or "destination")
+//
+// Without a city ID the city name is matched (exact, or prefix regex when usePartialMatch),
+// optionally refined by province. With a city ID, trips indexed with a catalog city match on
+// the ID alone (no near-miss names: "CABA" vs "Buenos Aires"), and trips indexed without one
+// (free-text cities, trips created before the catalog) fall back to the name match.
+// Both conditions of a side go in an $or, so they are ANDed with the other side through $and
+func addCityFilter(filters map[string]interface{}, field string, loc *domain.Location, usePartialMatch bool) {
+	byName := bson.M{}
+	if loc.City != "" {
+		if usePartialMatch {
+			byName[field+".city"] = bson.M{
+				"$regex":   "^" + domain.AccentInsensitivePattern(loc.City),
+				"$options": "i",
+			}
+		} else {
+			byName[field+".city"] = loc.City
+		}
+
+		// Province filter (optional refinement)
+		if loc.Province != "" {
+			byName[field+".province"] = loc.Province
+		}
+	}
+
+	if loc.CityID == "" {
+		for key, value := range byName {
+			filters[key] = value
+		}
+		return
+	}
+
+	byID := bson.M{field + ".city_id": loc.CityID}
+	if len(byName) == 0 {
+		filters[field+".city_id"] = loc.CityID
+		return
+	}
+
+	byName[field+".city_id"] = bson.M{"$exists": false}
+	and, _ := filters["$and"].([]bson.M)
+	filters["$and"] = append(and, bson.M{"$or": []bson.M{byID, byName}})
+}
+
 // buildMongoFilters converts SearchQuery to MongoDB filters
 // usePartialMatch: if true, city filters will use regex for prefix matching (case-insensitive)
 // Note: MongoDB $near and other filters cannot be combined on the same field
@@ -611,8 +930,11 @@ func (s *searchService) searchWithMongoDB(ctx context.Context, query *domain.Sea
 func (s *searchService) buildMongoFilters(query *domain.SearchQuery, usePartialMatch bool) map[string]interface{} {
 	filters := make(map[string]interface{})
 
-	filters["status"] = "published"
-	filters["available_seats"] = map[string]interface{}{"$gte": 1}
+	if query.OnlyBookable() {
+		filters["bookable"] = true
+	} else {
+		filters["status"] = map[string]interface{}{"$in": query.VisibleStatuses()}
+	}
 
 	// Strategy: geospatial PRIORITY, then city filters
 	// Check if Origin has geospatial data
@@ -639,8 +961,9 @@ func (s *searchService) buildMongoFilters(query *domain.SearchQuery, usePartialM
 				// Use $geoWithin when searching BOTH origin and destination
 				// $centerSphere: [center coordinates, radius in radians]
 				// radius in radians = radius in km / Earth radius (6378.1 km)
+				// pickup_locations holds the origin plus every pickup point: any of them may match
 				radiusInRadians := float64(query.OriginRadius) / 6378.1
-				filters["origin.coordinates"] = bson.M{
+				filters["pickup_locations"] = bson.M{
 					"$geoWithin": bson.M{
 						"$centerSphere": []interface{}{
 							[]float64{lng, lat}, // [lng, lat]
@@ -650,7 +973,8 @@ func (s *searchService) buildMongoFilters(query *domain.SearchQuery, usePartialM
 				}
 			} else {
 				// Use $near when only ONE geospatial filter (sorts by distance)
-				filters["origin.coordinates"] = bson.M{
+				// On the multi-valued pickup_locations field the nearest point is used for sorting
+				filters["pickup_locations"] = bson.M{
 					"$near": bson.M{
 						"$geometry": bson.M{
 							"type":        "Point",
@@ -661,21 +985,9 @@ func (s *searchService) buildMongoFilters(query *domain.SearchQuery, usePartialM
 				}
 			}
 		}
-	} else if query.Origin != nil && query.Origin.City != "" {
+	} else if query.Origin != nil && (query.Origin.City != "" || query.Origin.CityID != "") {
 		// Use city filter ONLY if no geospatial filter
-		if usePartialMatch {
-			filters["origin.city"] = bson.M{
-				"$regex":   "^" + regexp.QuoteMeta(query.Origin.City),
-				"$options": "i",
-			}
-		} else {
-			filters["origin.city"] = query.Origin.City
-		}
-
-		// Province filter (optional refinement)
-		if query.Origin.Province != "" {
-			filters["origin.province"] = query.Origin.Province
-		}
+		addCityFilter(filters, "origin", query.Origin, usePartialMatch)
 	}
 
 	if hasDestGeo {
@@ -708,31 +1020,22 @@ func (s *searchService) buildMongoFilters(query *domain.SearchQuery, usePartialM
 				}
 			}
 		}
-	} else if query.Destination != nil && query.Destination.City != "" {
+	} else if query.Destination != nil && (query.Destination.City != "" || query.Destination.CityID != "") {
 		// Use city filter ONLY if no geospatial filter
-		if usePartialMatch {
-			filters["destination.city"] = bson.M{
-				"$regex":   "^" + regexp.QuoteMeta(query.Destination.City),
-				"$options": "i",
-			}
-		} else {
-			filters["destination.city"] = query.Destination.City
-		}
-
-		// Province filter (optional refinement)
-		if query.Destination.Province != "" {
-			filters["destination.province"] = query.Destination.Province
-		}
+		addCityFilter(filters, "destination", query.Destination, usePartialMatch)
 	}
 
-	// Date filter (exact date)
-	if query.DepartureDate != nil {
-		startOfDay := query.DepartureDate.Truncate(24 * time.Hour)
-		endOfDay := startOfDay.Add(24 * time.Hour)
-		filters["departure_datetime"] = bson.M{
-			"$gte": startOfDay,
-			"$lt":  endOfDay,
-		}
+	// Date filter (exact date); departed trips are excluded unless include_past is set
+	from, to := query.DepartureRange(time.Now().UTC())
+	departure := bson.M{}
+	if !from.IsZero() {
+		departure["$gte"] = from
+	}
+	if !to.IsZero() {
+		departure["$lt"] = to
+	}
+	if len(departure) > 0 {
+		filters["departure_datetime"] = departure
 	}
 
 	// Seats filter
@@ -741,8 +1044,15 @@ func (s *searchService) buildMongoFilters(query *domain.SearchQuery, usePartialM
 	}
 
 	// Price filter
-	if query.MaxPrice > 0 {
-		filters["price_per_seat"] = map[string]interface{}{"$lte": query.MaxPrice}
+	if query.MinPrice > 0 || query.MaxPrice > 0 {
+		priceFilter := map[string]interface{}{}
+		if query.MinPrice > 0 {
+			priceFilter["$gte"] = query.MinPrice
+		}
+		if query.MaxPrice > 0 {
+			priceFilter["$lte"] = query.MaxPrice
+		}
+		filters["price_per_seat"] = priceFilter
 	}
 
 	// Preference filters
@@ -764,6 +1074,34 @@ func (s *searchService) buildMongoFilters(query *domain.SearchQuery, usePartialM
 	return filters
 }
 
+// recordSlowQuery stores a slow search in the slow query log
+// Runs in its own goroutine: failures are logged and never affect the search response
+func (s *searchService) recordSlowQuery(ctx context.Context, entry *domain.SlowQuery) {
+	if err := s.slowQueryRepo.Record(ctx, entry); err != nil {
+		log.Warn().
+			Err(err).
+			Str("query_hash", entry.QueryHash).
+			Msg("Failed to record slow query")
+		return
+	}
+
+	log.Warn().
+		Str("source", entry.Source).
+		Int64("duration_ms", entry.DurationMs).
+		Int64("threshold_ms", entry.ThresholdMs).
+		Str("query_hash", entry.QueryHash).
+		Msg("Slow search recorded")
+}
+
+// addMongoFilters stores a compiled MongoDB filter as Extended JSON
+func (t *searchTrace) addMongoFilters(filters map[string]interface{}) {
+	data, err := bson.MarshalExtJSON(filters, false, false)
+	if err != nil {
+		return
+	}
+	t.mongoFilters = append(t.mongoFilters, string(data))
+}
+
 // buildSearchResponse builds a SearchResponse from results
 func (s *searchService) buildSearchResponse(trips []*domain.SearchTrip, total int64, page, limit int) *domain.SearchResponse {
 	totalPages := int(total) / limit
@@ -847,7 +1185,7 @@ func BuildSearchText(trip *domain.SearchTrip) string {
 		trip.Origin.Province,
 		trip.Destination.City,
 		trip.Destination.Province,
-		trip.Description,
+		trip.SearchableDescription(),
 		trip.Driver.Name,
 	}
 
@@ -863,59 +1201,9 @@ func BuildSearchText(trip *domain.SearchTrip) string {
 	return strings.Join(nonEmpty, " ")
 }
 
-// CalculatePopularityScore calculates a popularity score based on:
-// - Number of bookings (approximated by occupied seats)
-// - Driver rating
-// - Trip recency (newer trips get slight boost)
+// CalculatePopularityScore calculates the popularity_score (0-100) stored when a trip is indexed:
+// the popularity scorer with the default weights (occupancy 40%, driver rating 40%,
+// driver experience 10%, recency 10%)
 func CalculatePopularityScore(trip *domain.SearchTrip) float64 {
-	score := 0.0
-
-	// Component 1: Booking popularity (occupied seats)
-	// Weight: 40%
-	occupiedSeats := trip.TotalSeats - trip.AvailableSeats
-	if trip.TotalSeats > 0 {
-		occupancyRate := float64(occupiedSeats) / float64(trip.TotalSeats)
-		score += occupancyRate * 40.0
-	}
-
-	// Component 2: Driver rating (0-5 scale)
-	// Weight: 40%
-	driverScore := (trip.Driver.Rating / 5.0) * 40.0
-	score += driverScore
-
-	// Component 3: Driver experience (total trips)
-	// Weight: 10%
-	// Logarithmic scale: ln(trips + 1) capped at 100 trips = max score
-	experienceScore := 0.0
-	if trip.Driver.TotalTrips > 0 {
-		// ln(101) ≈ 4.615, so we normalize by dividing by 4.615
-		experienceScore = (float64(trip.Driver.TotalTrips) / 100.0) * 10.0
-		if experienceScore > 10.0 {
-			experienceScore = 10.0
-		}
-	}
-	score += experienceScore
-
-	// Component 4: Recency bonus (trips created recently)
-	// Weight: 10%
-	// Trips created in last 7 days get full points, older trips decay linearly
-	daysSinceCreation := time.Since(trip.CreatedAt).Hours() / 24
-	recencyScore := 10.0
-	if daysSinceCreation > 7 {
-		recencyScore = 10.0 * (1.0 - (daysSinceCreation-7.0)/30.0)
-		if recencyScore < 0 {
-			recencyScore = 0
-		}
-	}
-	score += recencyScore
-
-	// Ensure score is within 0-100 range
-	if score < 0 {
-		score = 0
-	}
-	if score > 100 {
-		score = 100
-	}
-
-	return score
+	return domain.PopularityScore(trip, domain.DefaultScoringWeights.Popularity, time.Now()) * 100
 }
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"search-api/internal/clients"
+	"search-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxCachedSessions bounds the cache: past it, expired entries are dropped on the next store
+const maxCachedSessions = 10000
+
+type cachedSessionStatus struct {
+	status    *domain.SessionStatus
+	fetchedAt time.Time
+}
+
+// SessionService checks JWTs against the account state in users-api, caching it per user for ttl
+// When users-api is unavailable the last known status is used; without one the check fails
+type SessionService struct {
+	usersClient clients.UsersClient
+	ttl         time.Duration
+
+	mu    sync.Mutex
+	cache map[int64]cachedSessionStatus
+}
+
+// NewSessionService creates the session check used by the admin JWT middleware
+func NewSessionService(usersClient clients.UsersClient, ttl time.Duration) *SessionService {
+	return &SessionService{
+		usersClient: usersClient,
+		ttl:         ttl,
+		cache:       make(map[int64]cachedSessionStatus),
+	}
+}
+
+// CheckSession returns nil if the token issued at issuedAt ("iat" claim) is still usable
+// Errors: ErrSessionRevoked, ErrAccountInactive, ErrAccountSuspended or ErrServiceUnavailable
+func (s *SessionService) CheckSession(ctx context.Context, userID int64, issuedAt int64) error {
+	status, err := s.sessionStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return status.CheckToken(issuedAt)
+}
+
+// sessionStatus returns the cached status or fetches it from users-api
+func (s *SessionService) sessionStatus(ctx context.Context, userID int64) (*domain.SessionStatus, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+
+	if ok && now.Sub(cached.fetchedAt) < s.ttl {
+		return cached.status, nil
+	}
+
+	status, err := s.usersClient.GetSessionStatus(ctx, userID)
+	if err != nil {
+		// Deleted account: there is no status to fall back to
+		if errors.Is(err, domain.ErrSessionRevoked) {
+			s.forget(userID)
+			return nil, err
+		}
+		if ok {
+			log.Warn().Err(err).Int64("user_id", userID).Msg("users-api unavailable - using last known session status")
+			return cached.status, nil
+		}
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to check session status")
+		return nil, domain.ErrServiceUnavailable
+	}
+
+	s.store(userID, status, now)
+	return status, nil
+}
+
+func (s *SessionService) store(userID int64, status *domain.SessionStatus, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxCachedSessions {
+		for id, entry := range s.cache {
+			if now.Sub(entry.fetchedAt) >= s.ttl {
+				delete(s.cache, id)
+			}
+		}
+	}
+	s.cache[userID] = cachedSessionStatus{status: status, fetchedAt: now}
+}
+
+func (s *SessionService) forget(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, userID)
+}
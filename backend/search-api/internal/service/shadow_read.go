@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"search-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// shadowReadTimeout bounds the background shadow search so it never piles up under load
+const shadowReadTimeout = 10 * time.Second
+
+// defaultShadowMaxInFlight is used when no limit of concurrent shadow searches is configured
+const defaultShadowMaxInFlight = 4
+
+// shadowReader runs the alternate search path for a sample of queries and compares results
+// The shadow search runs in the background and never affects the response served to the user
+type shadowReader struct {
+	samplePercent int
+	slots         chan struct{} // One per shadow search in flight; a sampled search without a free slot is skipped
+
+	compared         int64
+	failed           int64
+	skipped          int64
+	setDivergences   int64
+	orderDivergences int64
+}
+
+// newShadowReader creates a shadow reader sampling samplePercent (0-100) of the eligible searches,
+// with at most maxInFlight shadow searches running at once (<= 0 = default)
+func newShadowReader(samplePercent, maxInFlight int) *shadowReader {
+	if samplePercent < 0 {
+		samplePercent = 0
+	}
+	if samplePercent > 100 {
+		samplePercent = 100
+	}
+	if maxInFlight <= 0 {
+		maxInFlight = defaultShadowMaxInFlight
+	}
+	return &shadowReader{samplePercent: samplePercent, slots: make(chan struct{}, maxInFlight)}
+}
+
+// sample decides whether the current search should be shadowed
+func (r *shadowReader) sample() bool {
+	if r.samplePercent <= 0 {
+		return false
+	}
+	return rand.Intn(100) < r.samplePercent
+}
+
+// acquire takes a slot for a shadow search without waiting; false (and counted as skipped) when
+// maxInFlight shadow searches are already running, so a slow MongoDB cannot pile up goroutines
+func (r *shadowReader) acquire() bool {
+	select {
+	case r.slots <- struct{}{}:
+		return true
+	default:
+		atomic.AddInt64(&r.skipped, 1)
+		return false
+	}
+}
+
+// release frees the slot taken by acquire
+func (r *shadowReader) release() {
+	<-r.slots
+}
+
+// record updates the counters with the outcome of a comparison
+func (r *shadowReader) record(cmp domain.ShadowReadComparison) {
+	atomic.AddInt64(&r.compared, 1)
+	if cmp.SetDiverged {
+		atomic.AddInt64(&r.setDivergences, 1)
+	} else if cmp.OrderDiverged {
+		atomic.AddInt64(&r.orderDivergences, 1)
+	}
+}
+
+// stats returns the current shadow-read counters and divergence rate
+func (r *shadowReader) stats() domain.ShadowReadStats {
+	stats := domain.ShadowReadStats{
+		SamplePercent:    r.samplePercent,
+		Compared:         atomic.LoadInt64(&r.compared),
+		Failed:           atomic.LoadInt64(&r.failed),
+		Skipped:          atomic.LoadInt64(&r.skipped),
+		SetDivergences:   atomic.LoadInt64(&r.setDivergences),
+		OrderDivergences: atomic.LoadInt64(&r.orderDivergences),
+	}
+	if stats.Compared > 0 {
+		stats.DivergenceRate = float64(stats.SetDivergences+stats.OrderDivergences) / float64(stats.Compared)
+	}
+	return stats
+}
+
+// maybeShadowRead runs the other search path in the background for a sample of queries
+// Only text/filter searches served by Solr are eligible (geo searches always use MongoDB,
+// and a MongoDB result after a Solr failure has nothing to compare against)
+func (s *searchService) maybeShadowRead(query *domain.SearchQuery, source string, primary []*domain.SearchTrip, primaryTotal int64) {
+	if source != "solr" || query.IsGeospatial() || !s.shadow.sample() || !s.shadow.acquire() {
+		return
+	}
+
+	// Copy what the goroutine needs: the caller keeps using the query and results
+	shadowQuery := *query
+	solrIDs := tripIDs(primary)
+
+	go func() {
+		defer s.shadow.release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shadowReadTimeout)
+		defer cancel()
+
+		mongoTrips, mongoTotal, err := s.searchWithMongoDB(ctx, &shadowQuery, &searchTrace{})
+		if err != nil {
+			atomic.AddInt64(&s.shadow.failed, 1)
+			log.Warn().Err(err).Str("query_hash", shadowQuery.Hash()).Msg("Shadow read failed")
+			return
+		}
+
+		cmp := domain.CompareResultIDs(solrIDs, tripIDs(mongoTrips))
+		cmp.SolrTotal = primaryTotal
+		cmp.MongoTotal = mongoTotal
+		s.shadow.record(cmp)
+
+		if cmp.Diverged() {
+			log.Warn().
+				Str("query_hash", shadowQuery.Hash()).
+				Interface("query", &shadowQuery).
+				Bool("set_diverged", cmp.SetDiverged).
+				Bool("order_diverged", cmp.OrderDiverged).
+				Strs("only_in_solr", cmp.OnlyInSolr).
+				Strs("only_in_mongo", cmp.OnlyInMongo).
+				Int64("solr_total", cmp.SolrTotal).
+				Int64("mongo_total", cmp.MongoTotal).
+				Msg("Shadow read divergence between Solr and MongoDB")
+		}
+	}()
+}
+
+// tripIDs extracts the trip IDs of a result page, preserving order
+func tripIDs(trips []*domain.SearchTrip) []string {
+	ids := make([]string, 0, len(trips))
+	for _, trip := range trips {
+		ids = append(ids, trip.TripID)
+	}
+	return ids
+}
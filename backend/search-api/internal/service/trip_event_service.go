@@ -21,6 +21,13 @@ type TripEventService struct {
 	usersClient clients.UsersClient
 	solrClient  *clients.SolrClient
 	cache       cache.Cache
+
+	// driverSnapshotMaxAge is how old an embedded driver snapshot can be before
+	// falling back to users-api
+	driverSnapshotMaxAge time.Duration
+
+	// badgeThresholds decide the "price dropped" and "filling fast" badges stored on each trip
+	badgeThresholds domain.BadgeThresholds
 }
 
 // NewTripEventService creates a new TripEventService
@@ -31,19 +38,27 @@ func NewTripEventService(
 	usersClient clients.UsersClient,
 	solrClient *clients.SolrClient,
 	cache cache.Cache,
+	driverSnapshotMaxAge time.Duration,
+	badgeThresholds domain.BadgeThresholds,
 ) *TripEventService {
 	return &TripEventService{
-		tripRepo:    tripRepo,
-		eventRepo:   eventRepo,
-		tripsClient: tripsClient,
-		usersClient: usersClient,
-		solrClient:  solrClient,
-		cache:       cache,
+		tripRepo:             tripRepo,
+		eventRepo:            eventRepo,
+		tripsClient:          tripsClient,
+		usersClient:          usersClient,
+		solrClient:           solrClient,
+		cache:                cache,
+		driverSnapshotMaxAge: driverSnapshotMaxAge,
+		badgeThresholds:      badgeThresholds,
 	}
 }
 
 // HandleTripCreated processes trip.created events
-func (s *TripEventService) HandleTripCreated(ctx context.Context, eventID, tripID string, driverID int64) error {
+// driverSnapshot is the driver embedded in the event; when it is nil, belongs to another
+// driver or is older than driverSnapshotMaxAge, the driver is fetched from users-api instead.
+// cityIDs are the catalog city IDs of the event, used when the trip fetched from trips-api has none.
+// sequence is the per-trip event sequence (0 if the event has none), stored as the trip's last_sequence
+func (s *TripEventService) HandleTripCreated(ctx context.Context, eventID, tripID string, driverID int64, driverSnapshot *domain.DriverSnapshot, cityIDs domain.TripCityIDs, sequence int64) error {
 	log.Info().
 		Str("event_id", eventID).
 		Str("event_type", "trip.created").
@@ -84,8 +99,7 @@ func (s *TripEventService) HandleTripCreated(ctx context.Context, eventID, tripI
 		return fmt.Errorf("fetch trip failed: %w", err)
 	}
 
-	// Fetch driver data from users-api
-	driver, err := s.usersClient.GetUser(ctx, driverID)
+	driver, err := s.resolveDriver(ctx, driverID, driverSnapshot)
 	if err != nil {
 		if domain.IsNotFoundError(err) {
 			// Permanent error - driver doesn't exist
@@ -106,11 +120,17 @@ func (s *TripEventService) HandleTripCreated(ctx context.Context, eventID, tripI
 		return fmt.Errorf("fetch driver failed: %w", err)
 	}
 
+	// Completion rate for the relevance ranking (non-critical)
+	s.enrichDriverMetrics(ctx, &driver)
+
 	// Build denormalized SearchTrip using existing ToSearchTrip method
-	searchTrip := trip.ToSearchTrip(driver.ToDriver())
+	searchTrip := trip.ToSearchTrip(driver)
+	searchTrip.ApplyCityIDs(cityIDs)
 	searchTrip.PopularityScore = 0.0 // Initial popularity score
 	searchTrip.CreatedAt = time.Now()
 	searchTrip.UpdatedAt = time.Now()
+	searchTrip.LastSequence = sequence
+	searchTrip.RefreshBadges(s.badgeThresholds, time.Now())
 
 	// Store in MongoDB
 	if err := s.tripRepo.Create(ctx, searchTrip); err != nil {
@@ -150,8 +170,57 @@ func (s *TripEventService) HandleTripCreated(ctx context.Context, eventID, tripI
 	return nil
 }
 
+// resolveDriver returns the driver to denormalize into the SearchTrip
+// Uses the event snapshot when it is fresh, otherwise fetches the driver from users-api
+// (and its response-time averages from trips-api)
+func (s *TripEventService) resolveDriver(ctx context.Context, driverID int64, snapshot *domain.DriverSnapshot) (domain.Driver, error) {
+	if snapshot != nil && snapshot.ID == driverID && snapshot.IsFresh(s.driverSnapshotMaxAge, time.Now()) {
+		log.Debug().Int64("driver_id", driverID).Msg("Using driver snapshot from event")
+		return snapshot.ToDriver(), nil
+	}
+
+	if snapshot != nil {
+		log.Info().
+			Int64("driver_id", driverID).
+			Time("fetched_at", snapshot.FetchedAt).
+			Msg("Driver snapshot stale or mismatched, fetching driver from users-api")
+	}
+
+	// Fetch driver data from users-api
+	user, err := s.usersClient.GetUser(ctx, driverID)
+	if err != nil {
+		return domain.Driver{}, err
+	}
+	driver := user.ToDriver()
+
+	// Without a snapshot the response-time averages come from trips-api (non-critical)
+	if responseTime, err := s.tripsClient.GetDriverResponseTime(ctx, driverID); err == nil {
+		driver.ApplyResponseTime(responseTime)
+	}
+
+	return driver, nil
+}
+
+// enrichDriverMetrics computes the driver's completion rate (completed vs cancelled trips
+// in the search read model). A failure is logged and leaves the signal empty: the trip is
+// indexed without that boost
+func (s *TripEventService) enrichDriverMetrics(ctx context.Context, driver *domain.Driver) {
+	completed, cancelled, err := s.tripRepo.CountDriverOutcomes(ctx, driver.ID)
+	if err != nil {
+		log.Warn().Err(err).Int64("driver_id", driver.ID).Msg("Failed to compute driver completion rate")
+		return
+	}
+	driver.CompletionRate = domain.CompletionRate(completed, cancelled)
+}
+
 // HandleTripUpdated processes trip.updated events
-func (s *TripEventService) HandleTripUpdated(ctx context.Context, eventID, tripID string, availableSeats, reservedSeats int, status string) error {
+// price is nil for events from publishers that do not include it; pickupPoints is nil when the event
+// has none and otherwise replaces the stored ones (mapped like trip.created does); occurredAt is the
+// event timestamp, used as the moment of the seats booked by this update (seat velocity, from the
+// increase of reserved_seats: seat holds and total_seats edits only move available_seats).
+// Events with a sequence not newer than the trip's last_sequence are stale (redelivered or
+// reordered) and are acknowledged without being applied
+func (s *TripEventService) HandleTripUpdated(ctx context.Context, eventID, tripID string, availableSeats, reservedSeats int, status string, price *domain.TripPriceUpdate, pickupPoints []domain.PickupPoint, occurredAt time.Time, sequence int64) error {
 	log.Info().
 		Str("event_id", eventID).
 		Str("event_type", "trip.updated").
@@ -159,6 +228,7 @@ func (s *TripEventService) HandleTripUpdated(ctx context.Context, eventID, tripI
 		Int("available_seats", availableSeats).
 		Int("reserved_seats", reservedSeats).
 		Str("status", status).
+		Int64("sequence", sequence).
 		Msg("Processing trip.updated event")
 
 	// Check idempotency
@@ -172,8 +242,27 @@ func (s *TripEventService) HandleTripUpdated(ctx context.Context, eventID, tripI
 		return nil
 	}
 
-	// Update availability and status in MongoDB
-	if err := s.tripRepo.UpdateAvailabilityByTripID(ctx, tripID, availableSeats, reservedSeats, status); err != nil {
+	// Seats booked by this update are the increase in reserved seats, so the previous state is read first
+	previous, err := s.tripRepo.FindByTripID(ctx, tripID)
+	if err != nil && !domain.IsNotFoundError(err) {
+		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to fetch trip from MongoDB")
+		return fmt.Errorf("mongodb find failed: %w", err)
+	}
+
+	// Pickup points are stored with the same geo field trip.created builds (origin + points)
+	var pickupLocations []domain.GeoJSONPoint
+	if pickupPoints != nil && previous != nil {
+		previous.ApplyPickupPoints(pickupPoints)
+		pickupLocations = previous.PickupLocations
+	} else {
+		pickupPoints = nil
+	}
+
+	// Update availability, status and pickup points in MongoDB (only if no newer event of the trip was applied)
+	if err := s.tripRepo.UpdateAvailabilityByTripID(ctx, tripID, availableSeats, reservedSeats, status, pickupPoints, pickupLocations, sequence); err != nil {
+		if err == domain.ErrStaleEvent {
+			return s.skipStaleEvent(ctx, eventID, "trip.updated", tripID, sequence)
+		}
 		if domain.IsNotFoundError(err) {
 			// Permanent error - trip doesn't exist
 			log.Warn().Str("trip_id", tripID).Msg("Trip not found in MongoDB, marking event as processed")
@@ -194,6 +283,10 @@ func (s *TripEventService) HandleTripUpdated(ctx context.Context, eventID, tripI
 
 	log.Info().Str("trip_id", tripID).Msg("Trip updated in MongoDB successfully")
 
+	if previous != nil {
+		s.refreshBadges(ctx, previous, availableSeats, reservedSeats, status, price, occurredAt)
+	}
+
 	// Update in Solr (optional - log error but continue)
 	if s.solrClient != nil {
 		searchTrip, err := s.tripRepo.FindByTripID(ctx, tripID)
@@ -238,13 +331,46 @@ func (s *TripEventService) HandleTripUpdated(ctx context.Context, eventID, tripI
 	return nil
 }
 
+// refreshBadges applies a trip.updated event to the stored trip and recomputes its badges
+// Badges are derived data: a failure is logged and the next event of the trip recomputes them
+func (s *TripEventService) refreshBadges(ctx context.Context, trip *domain.SearchTrip, availableSeats, reservedSeats int, status string, price *domain.TripPriceUpdate, occurredAt time.Time) {
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+
+	// Only confirmed bookings count: held seats and total_seats edits leave reserved_seats untouched,
+	// and seats given back (cancellations) do not count against the velocity
+	trip.RecordSeatsBooked(reservedSeats-trip.ReservedSeats, occurredAt, s.badgeThresholds.FillingFastWindow)
+	trip.AvailableSeats = availableSeats
+	trip.ReservedSeats = reservedSeats
+	trip.Status = status
+	if price != nil {
+		trip.ApplyPrice(*price)
+	}
+	trip.RefreshBadges(s.badgeThresholds, time.Now())
+
+	if err := s.tripRepo.UpdatePricingAndBadges(ctx, trip); err != nil {
+		log.Error().Err(err).Str("trip_id", trip.TripID).Msg("Failed to update trip badges in MongoDB (continuing)")
+		return
+	}
+
+	log.Debug().
+		Str("trip_id", trip.TripID).
+		Bool("price_dropped", trip.Badges.PriceDropped).
+		Bool("filling_fast", trip.Badges.FillingFast).
+		Int("seats_booked_recently", trip.Badges.SeatsBookedRecently).
+		Msg("Trip badges refreshed")
+}
+
 // HandleTripCancelled processes trip.cancelled events
-func (s *TripEventService) HandleTripCancelled(ctx context.Context, eventID, tripID, cancellationReason string) error {
+// Like trip.updated, a stale event (sequence not newer than the trip's last_sequence) is not applied
+func (s *TripEventService) HandleTripCancelled(ctx context.Context, eventID, tripID, cancellationReason string, sequence int64) error {
 	log.Info().
 		Str("event_id", eventID).
 		Str("event_type", "trip.cancelled").
 		Str("trip_id", tripID).
 		Str("cancellation_reason", cancellationReason).
+		Int64("sequence", sequence).
 		Msg("Processing trip.cancelled event")
 
 	// Check idempotency
@@ -258,8 +384,11 @@ func (s *TripEventService) HandleTripCancelled(ctx context.Context, eventID, tri
 		return nil
 	}
 
-	// Update status to cancelled in MongoDB
-	if err := s.tripRepo.UpdateStatusByTripID(ctx, tripID, "cancelled"); err != nil {
+	// Update status to cancelled in MongoDB (only if no newer event of the trip was applied)
+	if err := s.tripRepo.UpdateStatusByTripID(ctx, tripID, "cancelled", sequence); err != nil {
+		if err == domain.ErrStaleEvent {
+			return s.skipStaleEvent(ctx, eventID, "trip.cancelled", tripID, sequence)
+		}
 		if domain.IsNotFoundError(err) {
 			// Permanent error - trip doesn't exist
 			log.Warn().Str("trip_id", tripID).Msg("Trip not found in MongoDB, marking event as processed")
@@ -324,6 +453,28 @@ func (s *TripEventService) HandleTripCancelled(ctx context.Context, eventID, tri
 	return nil
 }
 
+// skipStaleEvent acknowledges an event older than the last one applied to the trip
+// It is marked as processed with result "stale" so a redelivery is skipped by the idempotency check
+func (s *TripEventService) skipStaleEvent(ctx context.Context, eventID, eventType, tripID string, sequence int64) error {
+	log.Warn().
+		Str("event_id", eventID).
+		Str("event_type", eventType).
+		Str("trip_id", tripID).
+		Int64("sequence", sequence).
+		Msg("Stale event, a newer event of the trip was already applied; skipping")
+
+	processedEvent := &domain.ProcessedEvent{
+		EventID:     eventID,
+		EventType:   eventType,
+		ProcessedAt: time.Now(),
+		Result:      "stale",
+	}
+	if err := s.eventRepo.MarkEventProcessed(ctx, processedEvent); err != nil {
+		log.Error().Err(err).Str("event_id", eventID).Msg("Failed to mark event as processed")
+	}
+	return nil
+}
+
 // HandleTripDeleted processes trip.deleted events
 func (s *TripEventService) HandleTripDeleted(ctx context.Context, eventID, tripID, reason string) error {
 	log.Info().
@@ -413,3 +564,106 @@ func (s *TripEventService) HandleTripDeleted(ctx context.Context, eventID, tripI
 
 	return nil
 }
+
+// HandleUserRatingUpdated processes user.rating_updated events from users-api
+// Refreshes driver.rating and driver.total_trips on every trip of the driver, re-indexes them in Solr
+// and invalidates their cache. Events that only change the passenger average are skipped, and so are
+// events older than the rating already stored (see TripRepository.UpdateManyByDriverID)
+func (s *TripEventService) HandleUserRatingUpdated(ctx context.Context, eventID string, userID int64, roleRated string, rating float64, totalTrips int, ratedAt time.Time) error {
+	log.Info().
+		Str("event_id", eventID).
+		Str("event_type", "user.rating_updated").
+		Int64("user_id", userID).
+		Str("role_rated", roleRated).
+		Float64("rating", rating).
+		Int("total_trips", totalTrips).
+		Msg("Processing user.rating_updated event")
+
+	// Check idempotency
+	processed, err := s.eventRepo.IsEventProcessed(ctx, eventID)
+	if err != nil {
+		log.Error().Err(err).Str("event_id", eventID).Msg("Failed to check event idempotency")
+		return fmt.Errorf("idempotency check failed: %w", err)
+	}
+	if processed {
+		log.Info().Str("event_id", eventID).Msg("Event already processed, skipping")
+		return nil
+	}
+
+	result := "success"
+	if roleRated == "pasajero" {
+		// Only the passenger average changed: nothing indexed depends on it
+		result = "skipped"
+	} else {
+		if ratedAt.IsZero() {
+			ratedAt = time.Now()
+		}
+
+		updated, err := s.tripRepo.UpdateManyByDriverID(ctx, userID, rating, totalTrips, ratedAt)
+		if err != nil {
+			log.Error().Err(err).Int64("user_id", userID).Msg("Failed to update driver rating in MongoDB")
+			return fmt.Errorf("mongodb update failed: %w", err)
+		}
+
+		log.Info().Int64("user_id", userID).Int64("trips_updated", updated).Msg("Driver rating updated in MongoDB")
+
+		if updated > 0 {
+			s.refreshDriverTrips(ctx, userID)
+		} else {
+			// No trips for this driver, or every trip already has a newer rating
+			result = "skipped"
+		}
+	}
+
+	// Mark event as processed
+	processedEvent := &domain.ProcessedEvent{
+		EventID:     eventID,
+		EventType:   "user.rating_updated",
+		ProcessedAt: time.Now(),
+		Result:      result,
+	}
+	if err := s.eventRepo.MarkEventProcessed(ctx, processedEvent); err != nil {
+		log.Error().Err(err).Str("event_id", eventID).Msg("Failed to mark event as processed")
+		return fmt.Errorf("mark event processed failed: %w", err)
+	}
+
+	log.Info().
+		Str("event_id", eventID).
+		Int64("user_id", userID).
+		Str("result", result).
+		Msg("user.rating_updated event processed successfully")
+
+	return nil
+}
+
+// refreshDriverTrips re-indexes the trips of a driver in Solr and invalidates their cache
+// Both are optional: MongoDB is the source of truth, so failures are logged and skipped
+func (s *TripEventService) refreshDriverTrips(ctx context.Context, driverID int64) {
+	trips, err := s.tripRepo.FindByDriverID(ctx, driverID)
+	if err != nil {
+		log.Error().Err(err).Int64("driver_id", driverID).Msg("Failed to fetch driver trips for Solr update")
+		return
+	}
+
+	if s.solrClient != nil && len(trips) > 0 {
+		// IndexBatch does not commit: a driver may have many trips, so they are committed once
+		err := s.solrClient.IndexBatch(ctx, trips)
+		if err == nil {
+			err = s.solrClient.Commit(ctx)
+		}
+		if err != nil {
+			log.Error().Err(err).Int64("driver_id", driverID).Msg("Failed to re-index driver trips in Solr (continuing)")
+		} else {
+			log.Info().Int64("driver_id", driverID).Int("trips", len(trips)).Msg("Driver trips re-indexed in Solr successfully")
+		}
+	}
+
+	if s.cache != nil {
+		for _, trip := range trips {
+			cacheKey := fmt.Sprintf("trip:%s", trip.TripID)
+			if err := s.cache.Delete(ctx, cacheKey); err != nil {
+				log.Error().Err(err).Str("cache_key", cacheKey).Msg("Failed to invalidate cache (continuing)")
+			}
+		}
+	}
+}
@@ -77,10 +77,12 @@ func TestHandleTripCreated_Success(t *testing.T) {
 		mockUsersClient,
 		mockSolr,
 		&mocks.MockCache{},
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID)
+	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID, nil, domain.TripCityIDs{}, 0)
 
 	// Assert
 	require.NoError(t, err)
@@ -125,10 +127,12 @@ func TestHandleTripCreated_DuplicateEvent_Skipped(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID)
+	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID, nil, domain.TripCityIDs{}, 0)
 
 	// Assert
 	require.NoError(t, err, "Duplicate events should be handled gracefully")
@@ -208,6 +212,8 @@ func TestHandleTripCreated_ConcurrentDuplicates_OnlyOneProcesses(t *testing.T) {
 		mockUsersClient,
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute - Process same event 10 times concurrently
@@ -219,7 +225,7 @@ func TestHandleTripCreated_ConcurrentDuplicates_OnlyOneProcesses(t *testing.T) {
 		wg.Add(1)
 		go func(index int) {
 			defer wg.Done()
-			errors[index] = service.HandleTripCreated(context.Background(), eventID, tripID, driverID)
+			errors[index] = service.HandleTripCreated(context.Background(), eventID, tripID, driverID, nil, domain.TripCityIDs{}, 0)
 		}(i)
 	}
 
@@ -282,10 +288,12 @@ func TestHandleTripCreated_TripNotFound_PermanentError(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID)
+	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID, nil, domain.TripCityIDs{}, 0)
 
 	// Assert
 	assert.ErrorIs(t, err, domain.ErrTripNotFound)
@@ -320,10 +328,12 @@ func TestHandleTripCreated_TripsAPITransientError_Retry(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID)
+	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID, nil, domain.TripCityIDs{}, 0)
 
 	// Assert
 	assert.Error(t, err)
@@ -367,10 +377,12 @@ func TestHandleTripCreated_UserNotFound_PermanentError(t *testing.T) {
 		mockUsersClient,
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID)
+	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID, nil, domain.TripCityIDs{}, 0)
 
 	// Assert
 	assert.ErrorIs(t, err, domain.ErrUserNotFound)
@@ -426,10 +438,12 @@ func TestHandleTripCreated_SolrFailure_DoesNotBlock(t *testing.T) {
 		mockUsersClient,
 		mockSolr,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID)
+	err := service.HandleTripCreated(context.Background(), eventID, tripID, driverID, nil, domain.TripCityIDs{}, 0)
 
 	// Assert - Should succeed despite Solr failure
 	require.NoError(t, err, "Solr failure should not block event processing")
@@ -460,7 +474,7 @@ func TestHandleTripUpdated_Success(t *testing.T) {
 	}
 
 	mockTripRepo := &mocks.MockTripRepository{
-		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string) error {
+		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string, points []domain.PickupPoint, locations []domain.GeoJSONPoint, seq int64) error {
 			assert.Equal(t, tripID, id)
 			assert.Equal(t, availableSeats, avail)
 			assert.Equal(t, reservedSeats, reserved)
@@ -483,10 +497,12 @@ func TestHandleTripUpdated_Success(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		mockCache,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripUpdated(context.Background(), eventID, tripID, availableSeats, reservedSeats, status)
+	err := service.HandleTripUpdated(context.Background(), eventID, tripID, availableSeats, reservedSeats, status, nil, nil, time.Now(), 0)
 
 	// Assert
 	require.NoError(t, err)
@@ -508,7 +524,7 @@ func TestHandleTripUpdated_DuplicateEvent_Skipped(t *testing.T) {
 	}
 
 	mockTripRepo := &mocks.MockTripRepository{
-		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string) error {
+		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string, points []domain.PickupPoint, locations []domain.GeoJSONPoint, seq int64) error {
 			t.Fatal("Should not update for duplicate events")
 			return nil
 		},
@@ -521,10 +537,12 @@ func TestHandleTripUpdated_DuplicateEvent_Skipped(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripUpdated(context.Background(), eventID, tripID, 2, 2, "published")
+	err := service.HandleTripUpdated(context.Background(), eventID, tripID, 2, 2, "published", nil, nil, time.Now(), 0)
 
 	// Assert
 	require.NoError(t, err)
@@ -546,7 +564,7 @@ func TestHandleTripUpdated_TripNotFound_PermanentError(t *testing.T) {
 	}
 
 	mockTripRepo := &mocks.MockTripRepository{
-		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string) error {
+		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string, points []domain.PickupPoint, locations []domain.GeoJSONPoint, seq int64) error {
 			return domain.ErrSearchTripNotFound
 		},
 	}
@@ -558,10 +576,12 @@ func TestHandleTripUpdated_TripNotFound_PermanentError(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripUpdated(context.Background(), eventID, tripID, 2, 2, "published")
+	err := service.HandleTripUpdated(context.Background(), eventID, tripID, 2, 2, "published", nil, nil, time.Now(), 0)
 
 	// Assert
 	assert.ErrorIs(t, err, domain.ErrSearchTripNotFound)
@@ -584,7 +604,7 @@ func TestHandleTripUpdated_CacheInvalidation(t *testing.T) {
 	}
 
 	mockTripRepo := &mocks.MockTripRepository{
-		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string) error {
+		UpdateAvailabilityByTripIDFunc: func(ctx context.Context, id string, avail, reserved int, stat string, points []domain.PickupPoint, locations []domain.GeoJSONPoint, seq int64) error {
 			return nil
 		},
 	}
@@ -604,10 +624,12 @@ func TestHandleTripUpdated_CacheInvalidation(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		mockCache,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripUpdated(context.Background(), eventID, tripID, 2, 2, "published")
+	err := service.HandleTripUpdated(context.Background(), eventID, tripID, 2, 2, "published", nil, nil, time.Now(), 0)
 
 	// Assert
 	require.NoError(t, err)
@@ -637,7 +659,7 @@ func TestHandleTripCancelled_Success(t *testing.T) {
 	}
 
 	mockTripRepo := &mocks.MockTripRepository{
-		UpdateStatusByTripIDFunc: func(ctx context.Context, id string, status string) error {
+		UpdateStatusByTripIDFunc: func(ctx context.Context, id string, status string, seq int64) error {
 			assert.Equal(t, tripID, id)
 			assert.Equal(t, "cancelled", status)
 			return nil
@@ -658,10 +680,12 @@ func TestHandleTripCancelled_Success(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		mockCache,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCancelled(context.Background(), eventID, tripID, reason)
+	err := service.HandleTripCancelled(context.Background(), eventID, tripID, reason, 0)
 
 	// Assert
 	require.NoError(t, err)
@@ -683,7 +707,7 @@ func TestHandleTripCancelled_DuplicateEvent_Skipped(t *testing.T) {
 	}
 
 	mockTripRepo := &mocks.MockTripRepository{
-		UpdateStatusByTripIDFunc: func(ctx context.Context, id string, status string) error {
+		UpdateStatusByTripIDFunc: func(ctx context.Context, id string, status string, seq int64) error {
 			t.Fatal("Should not update for duplicate events")
 			return nil
 		},
@@ -696,10 +720,12 @@ func TestHandleTripCancelled_DuplicateEvent_Skipped(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCancelled(context.Background(), eventID, tripID, "reason")
+	err := service.HandleTripCancelled(context.Background(), eventID, tripID, "reason", 0)
 
 	// Assert
 	require.NoError(t, err)
@@ -721,7 +747,7 @@ func TestHandleTripCancelled_TripNotFound_PermanentError(t *testing.T) {
 	}
 
 	mockTripRepo := &mocks.MockTripRepository{
-		UpdateStatusByTripIDFunc: func(ctx context.Context, id string, status string) error {
+		UpdateStatusByTripIDFunc: func(ctx context.Context, id string, status string, seq int64) error {
 			return domain.ErrSearchTripNotFound
 		},
 	}
@@ -733,10 +759,12 @@ func TestHandleTripCancelled_TripNotFound_PermanentError(t *testing.T) {
 		&mocks.MockUsersClient{},
 		nil,
 		nil,
+		5*time.Minute,
+		domain.DefaultBadgeThresholds,
 	)
 
 	// Execute
-	err := service.HandleTripCancelled(context.Background(), eventID, tripID, "reason")
+	err := service.HandleTripCancelled(context.Background(), eventID, tripID, "reason", 0)
 
 	// Assert
 	assert.ErrorIs(t, err, domain.ErrSearchTripNotFound)
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"search-api/internal/cache"
+	"search-api/internal/clients"
+	"search-api/internal/domain"
+	"search-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TripExpiryService marks visible trips whose departure already passed as expired
+//
+// A trip that departed without search-api seeing it leave a visible status (a lost event, a trip
+// never started) kept matching searches. Searches already exclude them by departure date; the
+// expiry job also takes them out of Solr and the caches, and keeps them in MongoDB so
+// include_past=true can still return them.
+type TripExpiryService struct {
+	tripRepo   repository.TripRepository
+	solrClient *clients.SolrClient
+	cache      cache.Cache
+	interval   time.Duration
+	batchSize  int
+}
+
+// NewTripExpiryService creates the expiry job (interval <= 0 = never runs on its own)
+func NewTripExpiryService(tripRepo repository.TripRepository, solrClient *clients.SolrClient, cache cache.Cache, interval time.Duration, batchSize int) *TripExpiryService {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &TripExpiryService{
+		tripRepo:   tripRepo,
+		solrClient: solrClient,
+		cache:      cache,
+		interval:   interval,
+		batchSize:  batchSize,
+	}
+}
+
+// Start expires departed trips every interval until ctx is cancelled
+func (s *TripExpiryService) Start(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RunOnce(ctx); err != nil {
+				log.Error().Err(err).Msg("Trip expiry run failed")
+			}
+		}
+	}
+}
+
+// RunOnce expires every visible trip that departed before now and returns how many were expired
+// Trips are processed in batches; a failed Solr delete is logged and the trips are left to the next
+// reindex, since searches already exclude them by departure date
+func (s *TripExpiryService) RunOnce(ctx context.Context) (int, error) {
+	cutoff := time.Now().UTC()
+	expired := 0
+
+	for {
+		tripIDs, err := s.tripRepo.ExpireDeparted(ctx, cutoff, s.batchSize)
+		if err != nil {
+			return expired, fmt.Errorf("expire departed trips: %w", err)
+		}
+		if len(tripIDs) == 0 {
+			break
+		}
+		expired += len(tripIDs)
+
+		if s.solrClient != nil {
+			if err := s.solrClient.DeleteBatch(ctx, tripIDs); err != nil {
+				log.Error().Err(err).Int("trips", len(tripIDs)).Msg("Failed to remove expired trips from Solr (continuing)")
+			}
+		}
+		s.invalidateTrips(ctx, tripIDs)
+
+		if len(tripIDs) < s.batchSize {
+			break
+		}
+	}
+
+	if expired > 0 {
+		// Cached search pages may still list the expired trips (no pattern-based deletion)
+		if s.cache != nil {
+			if err := s.cache.FlushAll(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to flush cache after expiring trips (continuing)")
+			}
+		}
+		log.Info().Int("trips", expired).Time("cutoff", cutoff).Msg("Departed trips expired")
+	}
+
+	return expired, nil
+}
+
+// invalidateTrips removes the cached GET /trips/:id responses of the expired trips
+func (s *TripExpiryService) invalidateTrips(ctx context.Context, tripIDs []string) {
+	if s.cache == nil {
+		return
+	}
+	for _, tripID := range tripIDs {
+		cacheKey := fmt.Sprintf("trip:%s", tripID)
+		if err := s.cache.Delete(ctx, cacheKey); err != nil {
+			log.Error().Err(err).Str("cache_key", cacheKey).Msg("Failed to delete trip cache (continuing)")
+		}
+	}
+}
+
+// indexableTrips drops the expired trips, which stay in MongoDB but are kept out of Solr
+func indexableTrips(trips []*domain.SearchTrip) []*domain.SearchTrip {
+	indexable := trips[:0:0]
+	for _, trip := range trips {
+		if trip.Status != domain.TripStatusExpired {
+			indexable = append(indexable, trip)
+		}
+	}
+	return indexable
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"search-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// readThroughTimeout bounds the upstream calls made while serving a GetTrip miss
+const readThroughTimeout = 5 * time.Second
+
+// negativeCacheValue marks a trip ID that trips-api reported as nonexistent
+const negativeCacheValue = "missing"
+
+// buildMissingTripCacheKey builds the negative-cache key for a trip ID
+func (s *searchService) buildMissingTripCacheKey(tripID string) string {
+	return fmt.Sprintf("trip:missing:%s", tripID)
+}
+
+// readThroughTrip serves a trip missing from the local index (e.g. its trip.created event was lost)
+// Fetches it from trips-api and users-api, denormalizes it, stores it in MongoDB/Solr and returns it
+// Returns nil (without error) when the trip does not exist upstream; that answer is
+// negative-cached so repeated requests for bogus IDs do not reach trips-api
+func (s *searchService) readThroughTrip(ctx context.Context, tripID string) (*domain.SearchTrip, error) {
+	missingKey := s.buildMissingTripCacheKey(tripID)
+	if s.cache != nil {
+		if value, err := s.cache.Get(ctx, missingKey); err == nil && value == negativeCacheValue {
+			log.Debug().Str("trip_id", tripID).Msg("Trip negative-cached as missing, skipping read-through")
+			return nil, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readThroughTimeout)
+	defer cancel()
+
+	trip, err := s.tripsClient.GetTrip(ctx, tripID)
+	if err != nil {
+		if domain.IsNotFoundError(err) {
+			s.cacheMissingTrip(ctx, missingKey)
+			return nil, nil
+		}
+		return nil, domain.WrapError(err, "read-through fetch from trips-api failed")
+	}
+
+	user, err := s.usersClient.GetUser(ctx, trip.DriverID)
+	if err != nil {
+		if domain.IsNotFoundError(err) {
+			// Without a driver the trip cannot be denormalized (same as trip.created)
+			log.Warn().Str("trip_id", tripID).Int64("driver_id", trip.DriverID).Msg("Driver not found in users-api during read-through")
+			s.cacheMissingTrip(ctx, missingKey)
+			return nil, nil
+		}
+		return nil, domain.WrapError(err, "read-through fetch from users-api failed")
+	}
+
+	// Build denormalized SearchTrip (same as trip.created)
+	searchTrip := trip.ToSearchTrip(user.ToDriver())
+	searchTrip.PopularityScore = 0.0
+	searchTrip.CreatedAt = time.Now()
+	searchTrip.UpdatedAt = time.Now()
+
+	// Store it so the next request is a local hit
+	// Upsert by trip_id (unique index): a concurrent read-through or trip.created replaces the same
+	// document instead of inserting a second copy, and both hold the current state from trips-api
+	if err := s.tripRepo.UpsertByTripID(ctx, searchTrip); err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to store read-through trip in MongoDB (serving it anyway)")
+		return searchTrip, nil
+	}
+
+	if s.solrClient != nil {
+		if err := s.solrClient.Index(ctx, searchTrip); err != nil {
+			log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to index read-through trip in Solr (continuing)")
+		}
+	}
+
+	log.Warn().
+		Str("trip_id", tripID).
+		Msg("Trip was missing from the search index, restored from trips-api (possible lost trip.created event)")
+
+	return searchTrip, nil
+}
+
+// cacheMissingTrip negative-caches a trip ID that does not exist upstream
+func (s *searchService) cacheMissingTrip(ctx context.Context, key string) {
+	if s.cache == nil || s.readThroughNegativeTTL <= 0 {
+		return
+	}
+	if err := s.cache.Set(ctx, key, negativeCacheValue, s.readThroughNegativeTTL); err != nil {
+		log.Warn().Err(err).Str("cache_key", key).Msg("Failed to negative-cache missing trip")
+	}
+}
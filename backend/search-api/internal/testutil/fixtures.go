@@ -26,6 +26,7 @@ func CreateTestTrip(tripID string) *domain.Trip {
 		Preferences:              CreateTestPreferences(),
 		Status:                   "published",
 		Description:              "Trip to Medellín for business meeting",
+		Bookable:                 true,
 		CreatedAt:                time.Now(),
 		UpdatedAt:                time.Now(),
 	}
@@ -29,6 +29,23 @@ else
   echo "Core $CORE_NAME already exists"
 fi
 
+# Accent-folding text type: "Córdoba", "CORDOBA" and "cordoba" index and query the same
+# (keeps Solr consistent with the canonical cache keys of the search-api)
+curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+  "add-field-type": {
+    "name": "text_folded",
+    "class": "solr.TextField",
+    "positionIncrementGap": "100",
+    "analyzer": {
+      "tokenizer": { "class": "solr.StandardTokenizerFactory" },
+      "filters": [
+        { "class": "solr.LowerCaseFilterFactory" },
+        { "class": "solr.ASCIIFoldingFilterFactory" }
+      ]
+    }
+  }
+}' 2>/dev/null || true
+
 # Define schema fields
 echo "Defining schema fields..."
 
@@ -57,7 +74,7 @@ curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json
 curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
   "add-field": {
     "name": "origin_city",
-    "type": "text_general",
+    "type": "text_folded",
     "indexed": true,
     "stored": true
   }
@@ -66,7 +83,7 @@ curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json
 curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
   "add-field": {
     "name": "origin_province",
-    "type": "text_general",
+    "type": "text_folded",
     "indexed": true,
     "stored": true
   }
@@ -75,7 +92,7 @@ curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json
 curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
   "add-field": {
     "name": "destination_city",
-    "type": "text_general",
+    "type": "text_folded",
     "indexed": true,
     "stored": true
   }
@@ -84,7 +101,7 @@ curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json
 curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
   "add-field": {
     "name": "destination_province",
-    "type": "text_general",
+    "type": "text_folded",
     "indexed": true,
     "stored": true
   }
@@ -109,6 +126,17 @@ curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json
   }
 }' 2>/dev/null || true
 
+# Origin + pickup points (multi-valued, matched with {!geofilt sfield=pickup_locations})
+curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+  "add-field": {
+    "name": "pickup_locations",
+    "type": "location",
+    "indexed": true,
+    "stored": true,
+    "multiValued": true
+  }
+}' 2>/dev/null || true
+
 # Date field for departure
 curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
   "add-field": {
@@ -177,6 +205,16 @@ curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json
   }
 }' 2>/dev/null || true
 
+# Bookable flag (published with free seats), filtered by default
+curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+  "add-field": {
+    "name": "bookable",
+    "type": "boolean",
+    "indexed": true,
+    "stored": true
+  }
+}' 2>/dev/null || true
+
 # Boolean preferences
 curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
   "add-field": {
@@ -205,11 +243,65 @@ curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json
   }
 }' 2>/dev/null || true
 
+# Existing cores: switch city/province fields to the accent-folding type
+# (documents indexed before the change must be reindexed)
+for field in origin_city origin_province destination_city destination_province; do
+  curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+    "replace-field": {
+      "name": "'"${field}"'",
+      "type": "text_folded",
+      "indexed": true,
+      "stored": true
+    }
+  }' 2>/dev/null || true
+done
+
+# Canonical city IDs from the trips-api catalog ("ar-cordoba"), matched exactly
+# Trips with a free-text city have no ID and are matched by name
+for field in origin_city_id destination_city_id; do
+  curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+    "add-field": {
+      "name": "'"${field}"'",
+      "type": "string",
+      "indexed": true,
+      "stored": true
+    }
+  }' 2>/dev/null || true
+done
+
+# Destination city as a single string for faceting (destination_city is tokenized)
+curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+  "add-field": {
+    "name": "destination_city_facet",
+    "type": "string",
+    "indexed": true,
+    "stored": false,
+    "docValues": true
+  }
+}' 2>/dev/null || true
+
+curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+  "add-copy-field": {
+    "source": "destination_city",
+    "dest": "destination_city_facet"
+  }
+}' 2>/dev/null || true
+
 # Full-text search field
 curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
   "add-field": {
     "name": "search_text",
-    "type": "text_general",
+    "type": "text_folded",
+    "indexed": true,
+    "stored": true,
+    "multiValued": false
+  }
+}' 2>/dev/null || true
+
+curl -X POST "${SOLR_URL}/${CORE_NAME}/schema" -H 'Content-type:application/json' -d '{
+  "replace-field": {
+    "name": "search_text",
+    "type": "text_folded",
     "indexed": true,
     "stored": true,
     "multiValued": false
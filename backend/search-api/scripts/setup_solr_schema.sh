@@ -123,6 +123,30 @@ curl -X POST -H 'Content-Type: application/json' \
     }
   }' > /dev/null 2>&1
 
+echo "  Adding field: driver_responsiveness (pfloat)"
+curl -X POST -H 'Content-Type: application/json' \
+  "${SOLR_URL}/${SOLR_CORE}/schema" \
+  -d '{
+    "add-field": {
+      "name": "driver_responsiveness",
+      "type": "pfloat",
+      "stored": true,
+      "indexed": true
+    }
+  }' > /dev/null 2>&1
+
+echo "  Adding field: driver_completion_rate (pfloat)"
+curl -X POST -H 'Content-Type: application/json' \
+  "${SOLR_URL}/${SOLR_CORE}/schema" \
+  -d '{
+    "add-field": {
+      "name": "driver_completion_rate",
+      "type": "pfloat",
+      "stored": true,
+      "indexed": true
+    }
+  }' > /dev/null 2>&1
+
 # Location fields (NO coordinates - only text fields)
 echo "  Adding field: origin_city (string)"
 curl -X POST -H 'Content-Type: application/json' \
@@ -382,6 +406,18 @@ curl -X POST -H 'Content-Type: application/json' \
     }
   }' > /dev/null 2>&1
 
+echo "  Adding field: bookable (boolean)"
+curl -X POST -H 'Content-Type: application/json' \
+  "${SOLR_URL}/${SOLR_CORE}/schema" \
+  -d '{
+    "add-field": {
+      "name": "bookable",
+      "type": "boolean",
+      "stored": true,
+      "indexed": true
+    }
+  }' > /dev/null 2>&1
+
 echo "  Adding field: description (text_general)"
 curl -X POST -H 'Content-Type: application/json' \
   "${SOLR_URL}/${SOLR_CORE}/schema" \
@@ -8,15 +8,20 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	"trips-api/internal/cities"
 	"trips-api/internal/clients"
 	"trips-api/internal/config"
 	"trips-api/internal/controller"
 	"trips-api/internal/database"
+	"trips-api/internal/market"
 	"trips-api/internal/messaging"
+	"trips-api/internal/realtime"
 	"trips-api/internal/middleware"
 	"trips-api/internal/repository"
+	"trips-api/internal/richtext"
 	"trips-api/internal/routes"
 	"trips-api/internal/service"
+	"trips-api/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -35,6 +40,13 @@ func main() {
 	}
 	log.Println("✅ Configuración cargada exitosamente")
 
+	// 🔭 OpenTelemetry: spans de HTTP y RabbitMQ (exporta por OTLP si OTEL_EXPORTER_OTLP_ENDPOINT está definido)
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatalf("Error inicializando OpenTelemetry: %v", err)
+	}
+	log.Printf("✅ Tracing inicializado (exportando spans: %t)", tracing.Enabled())
+
 	// 🗃️ Inicializar capas de la aplicación (Dependency Injection)
 	// Patrón: Repository -> Service -> Controller
 	// Cada capa tiene una responsabilidad específica
@@ -49,29 +61,111 @@ func main() {
 	if err := database.CreateIndexes(db); err != nil {
 		log.Fatalf("Error creando índices: %v", err)
 	}
+	if err := database.MigrateTripEventSequences(db); err != nil {
+		log.Fatalf("Error migrando secuencias de eventos: %v", err)
+	}
 
 	// 🔌 Capa de datos: maneja operaciones con MongoDB
 	tripsRepo := repository.NewTripRepository(db)
 	eventsRepo := repository.NewEventRepository(db)
 	messageRepo := repository.NewMessageRepository(db)
+	vacationRepo := repository.NewVacationRepository(db)
+	recurringTripRepo := repository.NewRecurringTripRepository(db)
+	eventArchiveRepo := repository.NewEventArchiveRepository(db)
+	responseTimeRepo := repository.NewResponseTimeRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	tripReservationRepo := repository.NewTripReservationRepository(db)
+	seatHoldRepo := repository.NewSeatHoldRepository(db)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db)
+	messageTranslationRepo := repository.NewMessageTranslationRepository(db)
+	cityRepo := repository.NewCityRepository(db)
 	log.Println("✅ Repositories initialized")
 
 	// 🌐 Capa de clientes HTTP externos
 	usersClient := clients.NewUsersClient(cfg.UsersAPIURL)
+
+	// bookings-api (opcional): conteo de reservas de GET /trips/mine?include=bookings
+	var bookingsClient clients.BookingsClient
+	if cfg.BookingsAPIURL != "" {
+		bookingsClient = clients.NewBookingsClient(cfg.BookingsAPIURL)
+	}
+
+	// Traducción de los mensajes del chat (opcional): TRANSLATION_PROVIDER=stub|libretranslate
+	translator, err := clients.NewTranslationClient(cfg.Translation.Provider, cfg.Translation.URL, cfg.Translation.APIKey, time.Duration(cfg.Translation.TimeoutSeconds)*time.Second)
+	if err != nil {
+		log.Fatalf("Error configurando la traducción del chat: %v", err)
+	}
 	log.Println("✅ HTTP clients initialized")
 
 	// 📨 Conectar a RabbitMQ
-	publisher, err := messaging.NewPublisher(cfg.RabbitMQ.URL)
+	// Cada evento trip.* / reservation.* publicado se guarda también en el archivo inmutable (event_archive)
+	// y lleva una secuencia por viaje para que los consumers descarten los eventos desordenados
+	publisher, err := messaging.NewPublisher(cfg.RabbitMQ.URL, eventArchiveRepo)
 	if err != nil {
 		log.Fatalf("Error conectando a RabbitMQ: %v", err)
 	}
 	defer publisher.Close()
 	log.Println("✅ RabbitMQ publisher initialized")
 
+	// 📤 Outbox: trip.created se escribe en MongoDB y un relay lo publica con reintentos,
+	// así un viaje creado con RabbitMQ caído llega igual a search-api
+	outboxRelay := messaging.NewOutboxRelay(outboxRepo, publisher, messaging.OutboxRelayConfig{
+		PollInterval: time.Duration(cfg.Outbox.PollIntervalSeconds) * time.Second,
+		MaxAttempts:  cfg.Outbox.MaxAttempts,
+		RetryBase:    time.Duration(cfg.Outbox.RetryBaseSeconds) * time.Second,
+		RetryMax:     time.Duration(cfg.Outbox.RetryMaxSeconds) * time.Second,
+	})
+	publisher = messaging.NewOutboxPublisher(publisher, outboxRepo, outboxRelay)
+
+	// 📡 Hub en tiempo real (SSE / WebSocket): los cambios de estado de los viajes
+	// publicados en RabbitMQ también se replican a los clientes conectados
+	hub := realtime.NewHub(realtime.DefaultHistorySize)
+	publisher = realtime.NewHubPublisher(publisher, hub)
+
+	// 🌎 Límites por mercado (precio máximo, distancia máxima, monedas)
+	markets, err := market.LoadRegistry(cfg.Markets.File, cfg.Markets.DefaultCountry)
+	if err != nil {
+		log.Fatalf("Error cargando mercados: %v", err)
+	}
+	log.Println("✅ Market policies loaded")
+
+	// 🏙️ Catálogo de ciudades: se siembran las que faltan y se carga en memoria (city_id canónicos)
+	seeded, err := cityRepo.Seed(context.Background(), cities.DefaultCities())
+	if err != nil {
+		log.Fatalf("Error sembrando el catálogo de ciudades: %v", err)
+	}
+	catalogCities, err := cityRepo.List(context.Background())
+	if err != nil {
+		log.Fatalf("Error cargando el catálogo de ciudades: %v", err)
+	}
+	catalog, err := cities.NewCatalog(catalogCities)
+	if err != nil {
+		log.Fatalf("Error en el catálogo de ciudades: %v", err)
+	}
+	log.Printf("✅ City catalog loaded (%d cities, %d new)", len(catalogCities), seeded)
+
+	// 📝 Sanitización de las descripciones de los viajes (texto plano, HTML y Markdown)
+	descriptions := richtext.DefaultPipeline()
+
 	// 📦 Capa de servicios: lógica de negocio
 	idempotencyService := service.NewIdempotencyService(eventsRepo)
-	tripService := service.NewTripService(tripsRepo, idempotencyService, usersClient, publisher)
-	chatService := service.NewChatService(messageRepo, tripsRepo, publisher)
+	responseTimeService := service.NewResponseTimeService(responseTimeRepo, messageRepo)
+	seatHoldService := service.NewSeatHoldService(tripsRepo, seatHoldRepo, tripReservationRepo, publisher, service.SeatHoldConfig{
+		DefaultTTL: time.Duration(cfg.SeatHolds.DefaultTTLSeconds) * time.Second,
+		MaxTTL:     time.Duration(cfg.SeatHolds.MaxTTLSeconds) * time.Second,
+	})
+	tripService := service.NewTripService(tripsRepo, vacationRepo, tripReservationRepo, seatHoldService, priceHistoryRepo, idempotencyService, usersClient, responseTimeService, publisher, markets, catalog, cityRepo, descriptions, cfg.SeatDrift.LedgerSince)
+	chatService := service.NewChatService(messageRepo, tripsRepo, tripReservationRepo, publisher, hub, responseTimeService)
+	chatTranslationService := service.NewChatTranslationService(translator, messageTranslationRepo, usersClient)
+	chatExportService := service.NewChatExportService(messageRepo, tripsRepo, tripReservationRepo, time.Duration(cfg.ChatExport.WindowDays)*24*time.Hour)
+	vacationService := service.NewVacationService(vacationRepo, tripsRepo, publisher)
+	driverDashboardService := service.NewDriverDashboardService(tripsRepo, bookingsClient)
+	recurringTripService := service.NewRecurringTripService(recurringTripRepo, tripsRepo, vacationRepo, usersClient, publisher, markets, catalog, cityRepo, descriptions, cfg.Recurring.HorizonDays)
+	seatDriftService := service.NewSeatDriftService(tripsRepo, tripReservationRepo, publisher, service.SeatDriftConfig{
+		AutoRepair:  cfg.SeatDrift.AutoRepair,
+		LedgerSince: cfg.SeatDrift.LedgerSince,
+	})
+	tripLifecycleService := service.NewTripLifecycleService(tripsRepo, publisher)
 	log.Println("✅ Services initialized")
 
 	// 📥 Inicializar RabbitMQ consumer
@@ -99,20 +193,53 @@ func main() {
 		}
 	}()
 
+	// 📤 Iniciar relay del outbox
+	go outboxRelay.Start(consumerCtx)
+
+	// 🏖️ Iniciar worker que reanuda viajes al terminar las vacaciones
+	go vacationService.StartResumeWorker(consumerCtx, time.Minute)
+
+	// 🔁 Iniciar scheduler que materializa los viajes recurrentes
+	go recurringTripService.StartScheduler(consumerCtx, time.Duration(cfg.Recurring.IntervalMinutes)*time.Minute)
+
+	// 🪑 Iniciar chequeo de drift de los contadores de asientos
+	if cfg.SeatDrift.IntervalMinutes > 0 {
+		go seatDriftService.Start(consumerCtx, time.Duration(cfg.SeatDrift.IntervalMinutes)*time.Minute)
+	}
+
+	// ⏳ Iniciar expirador de retenciones de asientos vencidas
+	if cfg.SeatHolds.ExpiryIntervalSeconds > 0 {
+		go seatHoldService.Start(consumerCtx, time.Duration(cfg.SeatHolds.ExpiryIntervalSeconds)*time.Second)
+	}
+
+	// 🚗 Iniciar scheduler de estados: published → in_progress → completed según las fechas del viaje
+	if cfg.Lifecycle.IntervalMinutes > 0 {
+		go tripLifecycleService.Start(consumerCtx, time.Duration(cfg.Lifecycle.IntervalMinutes)*time.Minute)
+	}
+
 	// 🎮 Capa de controladores: HTTP handlers
 	authService := service.NewAuthService(cfg.JWTSecret)
+	sessionService := service.NewSessionService(usersClient, time.Duration(cfg.SessionStatusCacheSeconds)*time.Second)
 	tripController := controller.NewTripController(tripService)
-	chatController := controller.NewChatController(chatService)
+	chatController := controller.NewChatController(chatService, chatTranslationService, chatExportService)
+	vacationController := controller.NewVacationController(vacationService)
+	recurringTripController := controller.NewRecurringTripController(recurringTripService)
+	responseTimeController := controller.NewResponseTimeController(responseTimeService)
+	seatHoldController := controller.NewSeatHoldController(seatHoldService)
+	driverDashboardController := controller.NewDriverDashboardController(driverDashboardService)
+	cityController := controller.NewCityController(catalog)
+	outboxController := controller.NewOutboxController(outboxRelay)
 	log.Println("✅ Controllers initialized")
 
 	// 🌐 Configurar router HTTP con Gin
 	router := gin.Default()
 
 	// 🔐 Crear JWT middleware
-	jwtMiddleware := middleware.AuthMiddleware(authService)
+	jwtMiddleware := middleware.AuthMiddleware(authService, sessionService)
+	internalMiddleware := middleware.RequireInternalSecret(cfg.InternalAPISecret)
 
 	// 🚦 Configurar rutas de la aplicación
-	routes.SetupRoutes(router, tripController, chatController, jwtMiddleware)
+	routes.SetupRoutes(router, tripController, chatController, vacationController, recurringTripController, responseTimeController, seatHoldController, driverDashboardController, cityController, outboxController, jwtMiddleware, internalMiddleware)
 	log.Println("✅ Routes configured")
 
 	// Configuración del server HTTP con timeouts
@@ -166,5 +293,10 @@ func main() {
 		log.Printf("⚠️  Error cerrando RabbitMQ: %v", err)
 	}
 
+	// Exportar los spans pendientes
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("⚠️  Error cerrando OpenTelemetry: %v", err)
+	}
+
 	log.Println("✅ Servidor detenido correctamente")
 }
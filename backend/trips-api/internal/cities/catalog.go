@@ -0,0 +1,302 @@
+package cities
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"trips-api/internal/domain"
+)
+
+// MatchRadiusKm es la distancia máxima entre las coordenadas de la ubicación y el centro
+// de una ciudad del catálogo para aceptarla como la ciudad de la ubicación
+const MatchRadiusKm = 40.0
+
+// maxSuggestions es la cantidad máxima de ciudades sugeridas cuando el nombre es ambiguo
+const maxSuggestions = 5
+
+// Resolution es el resultado de resolver una ubicación contra el catálogo
+type Resolution struct {
+	City        *domain.City  // Ciudad resuelta (nil si no hay una sola candidata)
+	Match       string        // Cómo se resolvió (domain.CityMatch*)
+	Suggestions []domain.City // Candidatas cuando el nombre es ambiguo, la más probable primero
+}
+
+// Ambiguous indica si el nombre coincide con varias ciudades y hay que pedir que se elija una
+func (r Resolution) Ambiguous() bool {
+	return r.City == nil && len(r.Suggestions) > 0
+}
+
+// Catalog resuelve los nombres de ciudades escritos por los conductores a las ciudades canónicas
+//
+// Las ciudades se cargan una sola vez al iniciar (colección cities, sembrada con DefaultCities)
+// y luego son de solo lectura, por lo que el catálogo es seguro para uso concurrente.
+type Catalog interface {
+	// Get devuelve la ciudad con ese ID
+	Get(id string) (domain.City, bool)
+
+	// Resolve busca la ciudad de una ubicación
+	// Orden: city_id → nombre o alias exacto → nombre con errores de tipeo (ver resolve)
+	Resolve(location domain.Location) (Resolution, error)
+
+	// Search devuelve las ciudades cuyo nombre o alias empieza con query (autocompletado),
+	// o las parecidas si ninguna empieza así. country (opcional) filtra por país
+	Search(query, country string, limit int) []domain.City
+}
+
+// catalog implementa Catalog con mapas en memoria
+type catalog struct {
+	cities []domain.City
+	byID   map[string]int
+	byName map[string][]int // nombre/alias normalizado → índices en cities
+}
+
+// NewCatalog crea un catálogo a partir de una lista de ciudades
+// Retorna error si una ciudad no tiene ID, nombre o país válido, o si un ID está duplicado
+// Un mismo alias en varias ciudades es válido: esas ciudades se distinguen por provincia o coordenadas
+func NewCatalog(cities []domain.City) (Catalog, error) {
+	c := &catalog{
+		cities: make([]domain.City, 0, len(cities)),
+		byID:   make(map[string]int, len(cities)),
+		byName: make(map[string][]int),
+	}
+
+	for _, city := range cities {
+		city.ID = strings.ToLower(strings.TrimSpace(city.ID))
+		city.Country = strings.ToUpper(strings.TrimSpace(city.Country))
+		if city.ID == "" || strings.TrimSpace(city.Name) == "" {
+			return nil, fmt.Errorf("city %q: id and name are required", city.ID)
+		}
+		if len(city.Country) != 2 {
+			return nil, fmt.Errorf("city %s: country must be a 2-letter ISO code, got %q", city.ID, city.Country)
+		}
+		if _, exists := c.byID[city.ID]; exists {
+			return nil, fmt.Errorf("duplicate city id %s", city.ID)
+		}
+
+		index := len(c.cities)
+		c.cities = append(c.cities, city)
+		c.byID[city.ID] = index
+
+		for _, name := range append([]string{city.Name}, city.Aliases...) {
+			key := normalizeName(name)
+			if key != "" && !containsIndex(c.byName[key], index) {
+				c.byName[key] = append(c.byName[key], index)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// Get devuelve la ciudad con ese ID (sin distinguir mayúsculas)
+func (c *catalog) Get(id string) (domain.City, bool) {
+	index, ok := c.byID[strings.ToLower(strings.TrimSpace(id))]
+	if !ok {
+		return domain.City{}, false
+	}
+	return c.cities[index], true
+}
+
+// Resolve resuelve la ciudad de una ubicación
+//
+//   - Con city_id: la ciudad tiene que existir en el catálogo (error si no)
+//   - Sin city_id: candidatas por nombre o alias exacto (sin tildes ni mayúsculas) o, si no hay,
+//     por nombre con hasta 1-2 errores de tipeo. Se filtran por país y provincia de la ubicación
+//     cuando eso no las descarta a todas
+//   - Con coordenadas solo se aceptan las candidatas a menos de MatchRadiusKm: una sola resuelve
+//     la ciudad, varias son ambiguas y ninguna deja la ciudad fuera del catálogo (homónimos de
+//     ciudades que no están cargadas)
+//   - Sin coordenadas solo resuelve un nombre exacto con una sola candidata; el resto se sugiere
+func (c *catalog) Resolve(location domain.Location) (Resolution, error) {
+	if location.CityID != "" {
+		city, ok := c.Get(location.CityID)
+		if !ok {
+			return Resolution{}, fmt.Errorf("unknown city_id %q", location.CityID)
+		}
+		return Resolution{City: &city, Match: domain.CityMatchID}, nil
+	}
+
+	key := normalizeName(location.City)
+	if key == "" {
+		return Resolution{Match: domain.CityMatchNone}, nil
+	}
+
+	match := domain.CityMatchExact
+	candidates := c.byName[key]
+	if len(candidates) == 0 {
+		match = domain.CityMatchFuzzy
+		candidates = c.fuzzyMatches(key)
+	}
+	candidates = c.narrow(candidates, location)
+
+	hasCoordinates := location.Coordinates.Lat != 0 || location.Coordinates.Lng != 0
+	if hasCoordinates {
+		nearby := make([]int, 0, len(candidates))
+		for _, index := range candidates {
+			if domain.DistanceKm(location.Coordinates, c.cities[index].Coordinates) <= MatchRadiusKm {
+				nearby = append(nearby, index)
+			}
+		}
+		candidates = nearby
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return Resolution{Match: domain.CityMatchNone}, nil
+	case len(candidates) == 1 && (hasCoordinates || match == domain.CityMatchExact):
+		city := c.cities[candidates[0]]
+		return Resolution{City: &city, Match: match}, nil
+	default:
+		return Resolution{Match: match, Suggestions: c.suggestions(candidates, location)}, nil
+	}
+}
+
+// Search devuelve hasta limit ciudades para autocompletar, ordenadas por nombre
+func (c *catalog) Search(query, country string, limit int) []domain.City {
+	key := normalizeName(query)
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if key == "" {
+		return []domain.City{}
+	}
+
+	var matches []int
+	for name, indexes := range c.byName {
+		if strings.HasPrefix(name, key) {
+			for _, index := range indexes {
+				if !containsIndex(matches, index) {
+					matches = append(matches, index)
+				}
+			}
+		}
+	}
+	if len(matches) == 0 {
+		matches = c.fuzzyMatches(key)
+	}
+
+	result := make([]domain.City, 0, len(matches))
+	for _, index := range matches {
+		if country == "" || c.cities[index].Country == country {
+			result = append(result, c.cities[index])
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// fuzzyMatches devuelve las ciudades con un nombre o alias a pocas ediciones de key
+// Los nombres cortos (siglas) no admiten errores: "caba" no debe coincidir con "cuba"
+func (c *catalog) fuzzyMatches(key string) []int {
+	maxEdits := 2
+	switch length := len([]rune(key)); {
+	case length <= 4:
+		return nil
+	case length <= 8:
+		maxEdits = 1
+	}
+
+	var matches []int
+	for name, indexes := range c.byName {
+		if levenshtein(key, name) <= maxEdits {
+			for _, index := range indexes {
+				if !containsIndex(matches, index) {
+					matches = append(matches, index)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// narrow se queda con las candidatas del país y la provincia de la ubicación,
+// salvo que ese filtro las descarte a todas (la provincia escrita a mano puede no coincidir)
+func (c *catalog) narrow(candidates []int, location domain.Location) []int {
+	if country := strings.ToUpper(strings.TrimSpace(location.Country)); country != "" {
+		candidates = filterOrKeep(candidates, func(index int) bool { return c.cities[index].Country == country })
+	}
+	if province := normalizeName(location.Province); province != "" {
+		candidates = filterOrKeep(candidates, func(index int) bool { return normalizeName(c.cities[index].Province) == province })
+	}
+	return candidates
+}
+
+// suggestions ordena las candidatas (la más cercana a las coordenadas primero) y las recorta a maxSuggestions
+func (c *catalog) suggestions(candidates []int, location domain.Location) []domain.City {
+	result := make([]domain.City, 0, len(candidates))
+	for _, index := range candidates {
+		result = append(result, c.cities[index])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		di := domain.DistanceKm(location.Coordinates, result[i].Coordinates)
+		dj := domain.DistanceKm(location.Coordinates, result[j].Coordinates)
+		if di != dj {
+			return di < dj
+		}
+		return result[i].Name < result[j].Name
+	})
+	if len(result) > maxSuggestions {
+		result = result[:maxSuggestions]
+	}
+	return result
+}
+
+func filterOrKeep(candidates []int, keep func(int) bool) []int {
+	filtered := make([]int, 0, len(candidates))
+	for _, index := range candidates {
+		if keep(index) {
+			filtered = append(filtered, index)
+		}
+	}
+	if len(filtered) == 0 {
+		return candidates
+	}
+	return filtered
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// accentReplacer quita tildes y diéresis para comparar nombres escritos con o sin acentos
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n",
+	".", "", "'", "", "-", " ", ",", " ",
+)
+
+// normalizeName pasa a minúsculas, quita tildes y puntuación y colapsa los espacios
+// "C.A.B.A." → "caba", "San Martín de los Andes" → "san martin de los andes"
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(accentReplacer.Replace(strings.ToLower(name))), " ")
+}
+
+// levenshtein calcula la distancia de edición entre dos textos (por runas)
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,132 @@
+package cities
+
+import (
+	"testing"
+
+	"trips-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	obelisco   = domain.Coordinates{Lat: -34.6037, Lng: -58.3816}
+	tandil     = domain.Coordinates{Lat: -37.3217, Lng: -59.1332}
+	rosario    = domain.Coordinates{Lat: -32.9442, Lng: -60.6505}
+	salto      = domain.Coordinates{Lat: -31.3833, Lng: -57.9667}
+	noLocation = domain.Coordinates{}
+)
+
+func TestResolve(t *testing.T) {
+	c, err := NewCatalog(DefaultCities())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		location      domain.Location
+		expectedCity  string
+		expectedMatch string
+	}{
+		{"city id wins over the name", domain.Location{CityID: "AR-Rosario", City: "Córdoba"}, "ar-rosario", domain.CityMatchID},
+		{"alias", domain.Location{City: "CABA", Coordinates: obelisco}, "ar-caba", domain.CityMatchExact},
+		{"alias with dots", domain.Location{City: "C.A.B.A.", Coordinates: obelisco}, "ar-caba", domain.CityMatchExact},
+		{"canonical name without accents", domain.Location{City: "cordoba", Coordinates: domain.Coordinates{Lat: -31.41, Lng: -64.18}}, "ar-cordoba", domain.CityMatchExact},
+		{"typo confirmed by coordinates", domain.Location{City: "Rosaro", Coordinates: rosario}, "ar-rosario", domain.CityMatchFuzzy},
+		{"exact name without coordinates", domain.Location{City: "Mendoza"}, "ar-mendoza", domain.CityMatchExact},
+		{"homonym away from the catalog city", domain.Location{City: "Buenos Aires", Coordinates: tandil}, "", domain.CityMatchNone},
+		{"homonym of a city in another country", domain.Location{City: "Salto", Country: "AR", Coordinates: domain.Coordinates{Lat: -34.2935, Lng: -60.2555}}, "", domain.CityMatchNone},
+		{"similar names told apart by coordinates", domain.Location{City: "Salto", Coordinates: salto}, "uy-salto", domain.CityMatchExact},
+		{"unknown city", domain.Location{City: "Ciudad Inexistente", Coordinates: obelisco}, "", domain.CityMatchNone},
+		{"empty city", domain.Location{Coordinates: obelisco}, "", domain.CityMatchNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolution, err := c.Resolve(tt.location)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedMatch, resolution.Match)
+			assert.False(t, resolution.Ambiguous())
+			if tt.expectedCity == "" {
+				assert.Nil(t, resolution.City)
+			} else if assert.NotNil(t, resolution.City) {
+				assert.Equal(t, tt.expectedCity, resolution.City.ID)
+			}
+		})
+	}
+}
+
+func TestResolve_UnknownCityID(t *testing.T) {
+	c, err := NewCatalog(DefaultCities())
+	require.NoError(t, err)
+
+	_, err = c.Resolve(domain.Location{CityID: "ar-atlantis", City: "Atlantis"})
+	assert.Error(t, err)
+}
+
+func TestResolve_Ambiguous(t *testing.T) {
+	c, err := NewCatalog([]domain.City{
+		{ID: "ar-san-martin-ba", Name: "San Martín", Province: "Buenos Aires", Country: "AR", Coordinates: domain.Coordinates{Lat: -34.5750, Lng: -58.5372}},
+		{ID: "ar-san-martin-mza", Name: "San Martín", Province: "Mendoza", Country: "AR", Coordinates: domain.Coordinates{Lat: -33.0806, Lng: -68.4681}},
+		{ID: "ar-san-martin-andes", Name: "San Martín de los Andes", Province: "Neuquén", Country: "AR", Coordinates: domain.Coordinates{Lat: -40.1572, Lng: -71.3525}},
+	})
+	require.NoError(t, err)
+
+	// Sin coordenadas, el mismo nombre en dos provincias es ambiguo
+	resolution, err := c.Resolve(domain.Location{City: "San Martin", Coordinates: noLocation})
+	require.NoError(t, err)
+	assert.True(t, resolution.Ambiguous())
+	assert.Len(t, resolution.Suggestions, 2)
+
+	// La provincia desempata
+	resolution, err = c.Resolve(domain.Location{City: "San Martin", Province: "mendoza"})
+	require.NoError(t, err)
+	require.NotNil(t, resolution.City)
+	assert.Equal(t, "ar-san-martin-mza", resolution.City.ID)
+
+	// Un error de tipeo sin coordenadas no se acepta solo: se sugiere
+	resolution, err = c.Resolve(domain.Location{City: "San Martín de los Ande"})
+	require.NoError(t, err)
+	assert.True(t, resolution.Ambiguous())
+	assert.Equal(t, "ar-san-martin-andes", resolution.Suggestions[0].ID)
+}
+
+func TestSearch(t *testing.T) {
+	c, err := NewCatalog(DefaultCities())
+	require.NoError(t, err)
+
+	names := func(cities []domain.City) []string {
+		result := make([]string, 0, len(cities))
+		for _, city := range cities {
+			result = append(result, city.Name)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"Santiago", "Santiago del Estero"}, names(c.Search("santiago", "", 0)))
+	assert.Equal(t, []string{"Santiago"}, names(c.Search("Santiago", "cl", 0)))
+	assert.Equal(t, []string{"Buenos Aires"}, names(c.Search("capital fed", "", 10)))
+	assert.Equal(t, []string{"Paysandú"}, names(c.Search("paisandu", "", 10)), "falls back to similar names")
+	assert.Len(t, c.Search("san", "", 2), 2)
+	assert.Empty(t, c.Search("  ", "", 10))
+}
+
+func TestNewCatalog_Invalid(t *testing.T) {
+	valid := domain.City{ID: "ar-salta", Name: "Salta", Country: "AR"}
+
+	_, err := NewCatalog([]domain.City{valid, valid})
+	assert.Error(t, err, "duplicated id")
+
+	_, err = NewCatalog([]domain.City{{ID: "ar-x", Country: "AR"}})
+	assert.Error(t, err, "city without name")
+
+	_, err = NewCatalog([]domain.City{{ID: "x", Name: "X", Country: "ARG"}})
+	assert.Error(t, err, "invalid country")
+}
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "caba", normalizeName("C.A.B.A."))
+	assert.Equal(t, "san martin de los andes", normalizeName("  San  Martín de los Andes "))
+	assert.Equal(t, "vina del mar", normalizeName("Viña del Mar"))
+	assert.Equal(t, 1, levenshtein("rosaro", "rosario"))
+	assert.Equal(t, 2, levenshtein("rosairo", "rosario"))
+}
@@ -0,0 +1,96 @@
+package cities
+
+import "trips-api/internal/domain"
+
+// DefaultCities devuelve las ciudades con las que se siembra la colección cities al iniciar
+// Solo se insertan las que faltan: los cambios hechos en MongoDB (alias, coordenadas) se conservan
+func DefaultCities() []domain.City {
+	return []domain.City{
+		// Argentina
+		{ID: "ar-caba", Name: "Buenos Aires", Province: "Ciudad Autónoma de Buenos Aires", Country: "AR",
+			Aliases:     []string{"CABA", "Capital Federal", "Ciudad de Buenos Aires", "Ciudad Autónoma de Buenos Aires", "Bs As", "Buenos Aires Capital"},
+			Coordinates: domain.Coordinates{Lat: -34.6037, Lng: -58.3816}},
+		{ID: "ar-la-plata", Name: "La Plata", Province: "Buenos Aires", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -34.9215, Lng: -57.9545}},
+		{ID: "ar-mar-del-plata", Name: "Mar del Plata", Province: "Buenos Aires", Country: "AR",
+			Aliases:     []string{"MDQ", "Mardel"},
+			Coordinates: domain.Coordinates{Lat: -38.0055, Lng: -57.5426}},
+		{ID: "ar-bahia-blanca", Name: "Bahía Blanca", Province: "Buenos Aires", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -38.7196, Lng: -62.2724}},
+		{ID: "ar-tandil", Name: "Tandil", Province: "Buenos Aires", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -37.3217, Lng: -59.1332}},
+		{ID: "ar-cordoba", Name: "Córdoba", Province: "Córdoba", Country: "AR",
+			Aliases:     []string{"Córdoba Capital"},
+			Coordinates: domain.Coordinates{Lat: -31.4201, Lng: -64.1888}},
+		{ID: "ar-villa-carlos-paz", Name: "Villa Carlos Paz", Province: "Córdoba", Country: "AR",
+			Aliases:     []string{"Carlos Paz"},
+			Coordinates: domain.Coordinates{Lat: -31.4241, Lng: -64.4978}},
+		{ID: "ar-rio-cuarto", Name: "Río Cuarto", Province: "Córdoba", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -33.1232, Lng: -64.3493}},
+		{ID: "ar-villa-maria", Name: "Villa María", Province: "Córdoba", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -32.4075, Lng: -63.2402}},
+		{ID: "ar-rosario", Name: "Rosario", Province: "Santa Fe", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -32.9442, Lng: -60.6505}},
+		{ID: "ar-santa-fe", Name: "Santa Fe", Province: "Santa Fe", Country: "AR",
+			Aliases:     []string{"Santa Fe de la Vera Cruz", "Santa Fe Capital"},
+			Coordinates: domain.Coordinates{Lat: -31.6333, Lng: -60.7000}},
+		{ID: "ar-parana", Name: "Paraná", Province: "Entre Ríos", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -31.7413, Lng: -60.5115}},
+		{ID: "ar-mendoza", Name: "Mendoza", Province: "Mendoza", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -32.8895, Lng: -68.8458}},
+		{ID: "ar-san-rafael", Name: "San Rafael", Province: "Mendoza", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -34.6177, Lng: -68.3301}},
+		{ID: "ar-san-juan", Name: "San Juan", Province: "San Juan", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -31.5375, Lng: -68.5364}},
+		{ID: "ar-san-luis", Name: "San Luis", Province: "San Luis", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -33.2950, Lng: -66.3356}},
+		{ID: "ar-tucuman", Name: "San Miguel de Tucumán", Province: "Tucumán", Country: "AR",
+			Aliases:     []string{"Tucumán"},
+			Coordinates: domain.Coordinates{Lat: -26.8083, Lng: -65.2176}},
+		{ID: "ar-salta", Name: "Salta", Province: "Salta", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -24.7821, Lng: -65.4232}},
+		{ID: "ar-jujuy", Name: "San Salvador de Jujuy", Province: "Jujuy", Country: "AR",
+			Aliases:     []string{"Jujuy"},
+			Coordinates: domain.Coordinates{Lat: -24.1858, Lng: -65.2995}},
+		{ID: "ar-santiago-del-estero", Name: "Santiago del Estero", Province: "Santiago del Estero", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -27.7951, Lng: -64.2615}},
+		{ID: "ar-catamarca", Name: "San Fernando del Valle de Catamarca", Province: "Catamarca", Country: "AR",
+			Aliases:     []string{"Catamarca"},
+			Coordinates: domain.Coordinates{Lat: -28.4696, Lng: -65.7852}},
+		{ID: "ar-la-rioja", Name: "La Rioja", Province: "La Rioja", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -29.4131, Lng: -66.8558}},
+		{ID: "ar-neuquen", Name: "Neuquén", Province: "Neuquén", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -38.9516, Lng: -68.0591}},
+		{ID: "ar-bariloche", Name: "San Carlos de Bariloche", Province: "Río Negro", Country: "AR",
+			Aliases:     []string{"Bariloche"},
+			Coordinates: domain.Coordinates{Lat: -41.1335, Lng: -71.3103}},
+		{ID: "ar-corrientes", Name: "Corrientes", Province: "Corrientes", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -27.4692, Lng: -58.8306}},
+		{ID: "ar-resistencia", Name: "Resistencia", Province: "Chaco", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -27.4606, Lng: -58.9839}},
+		{ID: "ar-posadas", Name: "Posadas", Province: "Misiones", Country: "AR",
+			Coordinates: domain.Coordinates{Lat: -27.3621, Lng: -55.9009}},
+
+		// Uruguay
+		{ID: "uy-montevideo", Name: "Montevideo", Province: "Montevideo", Country: "UY",
+			Coordinates: domain.Coordinates{Lat: -34.9011, Lng: -56.1645}},
+		{ID: "uy-punta-del-este", Name: "Punta del Este", Province: "Maldonado", Country: "UY",
+			Coordinates: domain.Coordinates{Lat: -34.9667, Lng: -54.9500}},
+		{ID: "uy-colonia", Name: "Colonia del Sacramento", Province: "Colonia", Country: "UY",
+			Aliases:     []string{"Colonia"},
+			Coordinates: domain.Coordinates{Lat: -34.4626, Lng: -57.8400}},
+		{ID: "uy-salto", Name: "Salto", Province: "Salto", Country: "UY",
+			Coordinates: domain.Coordinates{Lat: -31.3833, Lng: -57.9667}},
+		{ID: "uy-paysandu", Name: "Paysandú", Province: "Paysandú", Country: "UY",
+			Coordinates: domain.Coordinates{Lat: -32.3214, Lng: -58.0756}},
+
+		// Chile
+		{ID: "cl-santiago", Name: "Santiago", Province: "Región Metropolitana", Country: "CL",
+			Aliases:     []string{"Santiago de Chile"},
+			Coordinates: domain.Coordinates{Lat: -33.4489, Lng: -70.6693}},
+		{ID: "cl-valparaiso", Name: "Valparaíso", Province: "Valparaíso", Country: "CL",
+			Coordinates: domain.Coordinates{Lat: -33.0472, Lng: -71.6127}},
+		{ID: "cl-vina-del-mar", Name: "Viña del Mar", Province: "Valparaíso", Country: "CL",
+			Coordinates: domain.Coordinates{Lat: -33.0245, Lng: -71.5518}},
+	}
+}
@@ -0,0 +1,95 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+	"trips-api/internal/domain"
+	"trips-api/internal/tracing"
+)
+
+// BookingsClient define las operaciones para consultar bookings-api
+type BookingsClient interface {
+	// GetTripBookingCounts obtiene el conteo de reservas por estado de un viaje
+	// authToken: JWT del conductor del viaje (format: "Bearer {token}"), bookings-api valida que sea el dueño
+	GetTripBookingCounts(ctx context.Context, tripID string, authToken string) (*domain.TripBookingCounts, error)
+}
+
+type bookingsHTTPClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewBookingsClient crea una nueva instancia del cliente HTTP para bookings-api
+func NewBookingsClient(baseURL string) BookingsClient {
+	return &bookingsHTTPClient{
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Timeout:   5 * time.Second,
+			Transport: tracing.Transport(nil), // Propaga el traceparent a bookings-api
+		},
+	}
+}
+
+// bookingsAPIResponse representa la respuesta estándar de bookings-api
+// Formato: {"success": true, "data": {...}}
+type bookingsAPIResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// GetTripBookingCounts obtiene el resumen de reservas de un viaje desde bookings-api
+//
+// Endpoint: GET {base_url}/api/v1/trips/{tripID}/bookings?status=pending
+// Response: {"success": true, "data": {"trip_id": "...", "counts": {"confirmed": 2}, "seats_confirmed": 3, ...}}
+//
+// counts se calcula antes del filtro de estado: filtrar por pending evita que bookings-api
+// busque en users-api los contactos de los pasajeros confirmados, que acá no se usan
+func (c *bookingsHTTPClient) GetTripBookingCounts(ctx context.Context, tripID string, authToken string) (*domain.TripBookingCounts, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/trips/%s/bookings?status=pending", c.baseURL, url.PathEscape(tripID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call bookings-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("bookings-api returned unexpected status %d", resp.StatusCode)
+	}
+
+	var apiResp bookingsAPIResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if !apiResp.Success {
+		return nil, fmt.Errorf("bookings-api returned success=false")
+	}
+
+	var counts domain.TripBookingCounts
+	if err := json.Unmarshal(apiResp.Data, &counts); err != nil {
+		return nil, fmt.Errorf("failed to parse trip bookings: %w", err)
+	}
+	if counts.Counts == nil {
+		counts.Counts = map[string]int{}
+	}
+
+	return &counts, nil
+}
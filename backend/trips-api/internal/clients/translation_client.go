@@ -0,0 +1,164 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"trips-api/internal/tracing"
+)
+
+// Translation es el resultado de traducir un texto
+type Translation struct {
+	Text           string // Texto traducido
+	SourceLanguage string // Idioma detectado del original ("" si el proveedor no lo informa)
+}
+
+// TranslationClient traduce textos cortos (mensajes del chat) a un idioma destino
+type TranslationClient interface {
+	// Translate traduce text al idioma targetLanguage (código ISO 639-1, ej. "es", "en", "pt")
+	// El idioma del original lo detecta el proveedor
+	Translate(ctx context.Context, text, targetLanguage string) (*Translation, error)
+
+	// Provider identifica al proveedor (se guarda junto a cada traducción cacheada)
+	Provider() string
+}
+
+// NewTranslationClient crea el traductor configurado en TRANSLATION_PROVIDER
+// Retorna nil (traducción deshabilitada) si provider está vacío
+func NewTranslationClient(provider, baseURL, apiKey string, timeout time.Duration) (TranslationClient, error) {
+	switch provider {
+	case "":
+		return nil, nil
+	case "stub":
+		return NewStubTranslationClient(), nil
+	case "libretranslate":
+		if baseURL == "" {
+			return nil, fmt.Errorf("TRANSLATION_API_URL is required for the %s provider", provider)
+		}
+		return NewLibreTranslateClient(baseURL, apiKey, timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown translation provider %q (expected stub or libretranslate)", provider)
+	}
+}
+
+// ==================== STUB ====================
+
+type stubTranslationClient struct{}
+
+// NewStubTranslationClient crea un traductor de prueba que no llama a ningún servicio externo
+// Devuelve el texto original con el idioma destino como prefijo ("[es] hola"), útil en desarrollo
+// y para probar el frontend sin credenciales de un proveedor
+func NewStubTranslationClient() TranslationClient {
+	return &stubTranslationClient{}
+}
+
+func (c *stubTranslationClient) Translate(ctx context.Context, text, targetLanguage string) (*Translation, error) {
+	return &Translation{
+		Text: fmt.Sprintf("[%s] %s", targetLanguage, text),
+	}, nil
+}
+
+func (c *stubTranslationClient) Provider() string {
+	return "stub"
+}
+
+// ==================== LIBRETRANSLATE ====================
+
+type libreTranslateClient struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewLibreTranslateClient crea un cliente HTTP para un proveedor con la API de LibreTranslate
+// (self-hosted o un servicio compatible). apiKey es opcional en las instancias que no la piden
+func NewLibreTranslateClient(baseURL, apiKey string, timeout time.Duration) TranslationClient {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &libreTranslateClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: tracing.Transport(nil),
+		},
+	}
+}
+
+// libreTranslateRequest es el body de POST /translate
+type libreTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// libreTranslateResponse es la respuesta de POST /translate
+type libreTranslateResponse struct {
+	TranslatedText   string `json:"translatedText"`
+	DetectedLanguage *struct {
+		Language string `json:"language"`
+	} `json:"detectedLanguage,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Translate traduce un texto con el proveedor externo
+//
+// Endpoint: POST {base_url}/translate
+// Body: {"q": "hello", "source": "auto", "target": "es", "format": "text"}
+// Response: {"translatedText": "hola", "detectedLanguage": {"language": "en", "confidence": 90}}
+func (c *libreTranslateClient) Translate(ctx context.Context, text, targetLanguage string) (*Translation, error) {
+	payload, err := json.Marshal(libreTranslateRequest{
+		Q:      text,
+		Source: "auto",
+		Target: targetLanguage,
+		Format: "text",
+		APIKey: c.apiKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode translation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/translate", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call translation provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var apiResp libreTranslateResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("failed to parse translation response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("translation provider returned %d: %s", resp.StatusCode, apiResp.Error)
+	}
+
+	translation := &Translation{Text: apiResp.TranslatedText}
+	if apiResp.DetectedLanguage != nil {
+		translation.SourceLanguage = apiResp.DetectedLanguage.Language
+	}
+	return translation, nil
+}
+
+func (c *libreTranslateClient) Provider() string {
+	return "libretranslate"
+}
@@ -8,13 +8,33 @@ import (
 	"net/http"
 	"time"
 	"trips-api/internal/domain"
+	"trips-api/internal/tracing"
 )
 
 // User representa la información básica de un usuario obtenida desde users-api
 type User struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID               int64   `json:"id"`
+	Name             string  `json:"name"`
+	Email            string  `json:"email"`
+	PhotoURL         string  `json:"photo_url,omitempty"`
+	AvgDriverRating  float64 `json:"avg_driver_rating"`
+	TotalTripsDriver int     `json:"total_trips_driver"`
+
+	// Restricciones de la cuenta (cuentas dependientes de users-api); vacío si no tiene
+	Restrictions []string `json:"restrictions,omitempty"`
+}
+
+// RestrictionNoDriving es la restricción de users-api de las cuentas que no pueden publicar viajes
+const RestrictionNoDriving = "no_driving"
+
+// CanDrive indica si el usuario puede publicar viajes como conductor
+func (u *User) CanDrive() bool {
+	for _, restriction := range u.Restrictions {
+		if restriction == RestrictionNoDriving {
+			return false
+		}
+	}
+	return true
 }
 
 // UsersClient define las operaciones para interactuar con users-api
@@ -23,6 +43,28 @@ type UsersClient interface {
 	// authToken: JWT token para autenticación en users-api (format: "Bearer {token}")
 	// Retorna domain.ErrDriverNotFound si el usuario no existe
 	GetUser(ctx context.Context, userID int64, authToken string) (*User, error)
+
+	// GetChatPreferences obtiene las preferencias de traducción del chat de un usuario
+	// (endpoint interno, sin token). Un usuario sin las preferencias cargadas tiene la traducción apagada
+	GetChatPreferences(ctx context.Context, userID int64) (*ChatPreferences, error)
+
+	// GetSessionStatus obtiene el estado de sesión de un usuario (endpoint interno, sin token)
+	// Retorna domain.ErrSessionRevoked si el usuario no existe (cuenta eliminada)
+	GetSessionStatus(ctx context.Context, userID int64) (*SessionStatus, error)
+}
+
+// SessionStatus es el estado de la cuenta que users-api valida en cada request (checkAccountUsable)
+type SessionStatus struct {
+	UserID            int64      `json:"user_id"`
+	Active            bool       `json:"active"`
+	Banned            bool       `json:"banned"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at,omitempty"` // Los JWT con iat anterior ya no valen
+}
+
+// ChatPreferences son las preferencias de chat de un usuario guardadas en users-api (sección "chat" de su perfil de preferencias)
+type ChatPreferences struct {
+	PreferredLanguage string `json:"preferred_language,omitempty"` // Código ISO 639-1 (ej. "es")
+	AutoTranslate     bool   `json:"auto_translate"`               // Traducir los mensajes del chat sin pedirlo
 }
 
 type usersHTTPClient struct {
@@ -35,7 +77,8 @@ func NewUsersClient(baseURL string) UsersClient {
 	return &usersHTTPClient{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 5 * time.Second, // Timeout de 5 segundos para llamadas HTTP
+			Timeout:   5 * time.Second,        // Timeout de 5 segundos para llamadas HTTP
+			Transport: tracing.Transport(nil), // Propaga el traceparent a users-api
 		},
 	}
 }
@@ -130,3 +173,95 @@ func (c *usersHTTPClient) GetUser(ctx context.Context, userID int64, authToken s
 		return nil, fmt.Errorf("users-api returned unexpected status %d: %s", resp.StatusCode, string(body))
 	}
 }
+
+// GetChatPreferences obtiene las preferencias de chat de un usuario desde users-api
+//
+// Endpoint: GET {base_url}/internal/users/{userID}/preferences
+// Response: {"success": true, "data": {"user_id": 123, "chat": {"preferred_language": "es", "auto_translate": true}, ...}}
+//
+// Un usuario que nunca guardó sus preferencias no tiene idioma (traducción automática apagada)
+func (c *usersHTTPClient) GetChatPreferences(ctx context.Context, userID int64) (*ChatPreferences, error) {
+	url := fmt.Sprintf("%s/internal/users/%d/preferences", c.baseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call users-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var apiResp usersAPIResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		var prefs struct {
+			Chat ChatPreferences `json:"chat"`
+		}
+		if err := json.Unmarshal(apiResp.Data, &prefs); err != nil {
+			return nil, fmt.Errorf("failed to parse chat preferences: %w", err)
+		}
+		return &prefs.Chat, nil
+
+	case http.StatusNotFound:
+		return nil, domain.ErrDriverNotFound
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d", resp.StatusCode)
+	}
+}
+
+// GetSessionStatus obtiene el estado de sesión de un usuario desde users-api
+//
+// Endpoint: GET {base_url}/internal/users/{userID}/session-status
+// Response: {"success": true, "data": {"user_id": 123, "active": true, "banned": false, "sessions_revoked_at": "..."}}
+func (c *usersHTTPClient) GetSessionStatus(ctx context.Context, userID int64) (*SessionStatus, error) {
+	url := fmt.Sprintf("%s/internal/users/%d/session-status", c.baseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call users-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var apiResp usersAPIResponse
+		if err := json.Unmarshal(body, &apiResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		var status SessionStatus
+		if err := json.Unmarshal(apiResp.Data, &status); err != nil {
+			return nil, fmt.Errorf("failed to parse session status: %w", err)
+		}
+		return &status, nil
+
+	case http.StatusNotFound:
+		return nil, domain.ErrSessionRevoked
+
+	default:
+		return nil, fmt.Errorf("users-api returned unexpected status %d", resp.StatusCode)
+	}
+}
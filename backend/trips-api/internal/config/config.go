@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +15,25 @@ type Config struct {
 	RabbitMQ    RabbitMQConfig
 	JWTSecret   string
 	UsersAPIURL string
+	Markets     MarketsConfig
+	Recurring   RecurringTripsConfig
+	Outbox      OutboxConfig
+	SeatDrift   SeatDriftConfig
+	SeatHolds   SeatHoldsConfig
+	Lifecycle   TripLifecycleConfig
+	Translation TranslationConfig
+	ChatExport  ChatExportConfig
+
+	// URL de bookings-api para enriquecer GET /trips/mine con include=bookings (vacía lo deshabilita)
+	BookingsAPIURL string
+
+	// Secreto compartido con bookings-api para las rutas entre servicios (retenciones de asientos)
+	// Vacío deshabilita esas rutas
+	InternalAPISecret string
+
+	// Segundos que se reusa el estado de sesión de un usuario (users-api) antes de volver a consultarlo
+	// Es la demora máxima con la que se aplica una revocación de sesiones, baja o suspensión
+	SessionStatusCacheSeconds int
 }
 
 type MongoConfig struct {
@@ -23,6 +45,58 @@ type RabbitMQConfig struct {
 	URL string
 }
 
+// MarketsConfig configura los límites por mercado (precio máximo, distancia, monedas)
+type MarketsConfig struct {
+	File           string // Archivo JSON opcional; si está vacío se usan los mercados por defecto
+	DefaultCountry string // Mercado de los viajes cuyo origen no se puede resolver
+}
+
+// RecurringTripsConfig configura el scheduler que materializa los viajes recurrentes
+type RecurringTripsConfig struct {
+	HorizonDays     int // Días de anticipación con que se crean las instancias
+	IntervalMinutes int // Cada cuántos minutos corre el scheduler
+}
+
+// OutboxConfig configura el relay que publica los eventos del outbox en RabbitMQ
+type OutboxConfig struct {
+	PollIntervalSeconds int // Cada cuántos segundos se revisan los mensajes pendientes
+	MaxAttempts         int // Intentos antes de marcar un mensaje como failed
+	RetryBaseSeconds    int // Espera antes del primer reintento (se duplica en cada uno)
+	RetryMaxSeconds     int // Espera máxima entre reintentos
+}
+
+// SeatDriftConfig configura el chequeo periódico de los contadores de asientos
+type SeatDriftConfig struct {
+	IntervalMinutes int       // Cada cuántos minutos corre el chequeo (0 lo deshabilita)
+	AutoRepair      bool      // Corrige los contadores además de alertar
+	LedgerSince     time.Time // Viajes creados antes no se comparan contra el ledger de reservas (obligatorio con AutoRepair)
+}
+
+// SeatHoldsConfig configura las retenciones de asientos que toma bookings-api antes de crear una reserva
+type SeatHoldsConfig struct {
+	DefaultTTLSeconds     int // Duración de una retención si bookings-api no envía ttl_seconds
+	MaxTTLSeconds         int // Duración máxima aceptada
+	ExpiryIntervalSeconds int // Cada cuántos segundos se liberan las retenciones vencidas (0 lo deshabilita)
+}
+
+// TripLifecycleConfig configura el scheduler que pasa los viajes a in_progress y completed
+type TripLifecycleConfig struct {
+	IntervalMinutes int // Cada cuántos minutos corre el scheduler (0 lo deshabilita)
+}
+
+// TranslationConfig configura la traducción de los mensajes del chat
+type TranslationConfig struct {
+	Provider       string // "" (deshabilitada), "stub" o "libretranslate"
+	URL            string // URL base del proveedor (libretranslate)
+	APIKey         string // API key del proveedor (opcional)
+	TimeoutSeconds int    // Timeout de cada llamada al proveedor
+}
+
+// ChatExportConfig configura la exportación del chat (GET /trips/:id/chat/export)
+type ChatExportConfig struct {
+	WindowDays int // Días después de terminado el viaje durante los que se puede exportar
+}
+
 // LoadConfig carga la configuración desde variables de entorno
 // Usa fail-fast: panic si alguna variable crítica no está definida
 func LoadConfig() (*Config, error) {
@@ -44,6 +118,52 @@ func LoadConfig() (*Config, error) {
 		// Variables NO CRÍTICAS - Con defaults razonables
 		ServerPort:  getEnv("SERVER_PORT", "8002"),
 		UsersAPIURL: getEnv("USERS_API_URL", "http://localhost:8001"),
+		Markets: MarketsConfig{
+			File:           getEnv("MARKETS_FILE", ""),
+			DefaultCountry: getEnv("DEFAULT_MARKET", "AR"),
+		},
+		Recurring: RecurringTripsConfig{
+			HorizonDays:     getEnvInt("RECURRING_TRIPS_HORIZON_DAYS", 7),
+			IntervalMinutes: getEnvInt("RECURRING_TRIPS_INTERVAL_MINUTES", 15),
+		},
+		Outbox: OutboxConfig{
+			PollIntervalSeconds: getEnvInt("OUTBOX_POLL_INTERVAL_SECONDS", 5),
+			MaxAttempts:         getEnvInt("OUTBOX_MAX_ATTEMPTS", 20),
+			RetryBaseSeconds:    getEnvInt("OUTBOX_RETRY_BASE_SECONDS", 2),
+			RetryMaxSeconds:     getEnvInt("OUTBOX_RETRY_MAX_SECONDS", 300),
+		},
+		SeatDrift: SeatDriftConfig{
+			IntervalMinutes: getEnvInt("SEAT_DRIFT_INTERVAL_MINUTES", 15),
+			AutoRepair:      getEnvBool("SEAT_DRIFT_AUTO_REPAIR", false),
+			LedgerSince:     getEnvTime("SEAT_DRIFT_LEDGER_SINCE"),
+		},
+		SeatHolds: SeatHoldsConfig{
+			DefaultTTLSeconds:     getEnvInt("SEAT_HOLD_DEFAULT_TTL_SECONDS", 300),
+			MaxTTLSeconds:         getEnvInt("SEAT_HOLD_MAX_TTL_SECONDS", 900),
+			ExpiryIntervalSeconds: getEnvInt("SEAT_HOLD_EXPIRY_INTERVAL_SECONDS", 30),
+		},
+		Lifecycle: TripLifecycleConfig{
+			IntervalMinutes: getEnvInt("TRIP_LIFECYCLE_INTERVAL_MINUTES", 1),
+		},
+		Translation: TranslationConfig{
+			Provider:       getEnv("TRANSLATION_PROVIDER", ""),
+			URL:            getEnv("TRANSLATION_API_URL", ""),
+			APIKey:         getEnv("TRANSLATION_API_KEY", ""),
+			TimeoutSeconds: getEnvInt("TRANSLATION_TIMEOUT_SECONDS", 5),
+		},
+		ChatExport: ChatExportConfig{
+			WindowDays: getEnvInt("CHAT_EXPORT_WINDOW_DAYS", 30),
+		},
+		BookingsAPIURL:    getEnv("BOOKINGS_API_URL", ""),
+		InternalAPISecret: getEnv("INTERNAL_API_SECRET", ""),
+
+		SessionStatusCacheSeconds: getEnvInt("SESSION_STATUS_CACHE_SECONDS", 30),
+	}
+
+	// Los viajes creados antes del ledger tienen su suma en 0: repararlos contra él los dejaría
+	// como si nadie hubiera reservado, así que la reparación exige la fecha desde la que el ledger es completo
+	if cfg.SeatDrift.AutoRepair && cfg.SeatDrift.LedgerSince.IsZero() {
+		return nil, errors.New("SEAT_DRIFT_AUTO_REPAIR requires SEAT_DRIFT_LEDGER_SINCE (RFC 3339)")
 	}
 
 	return cfg, nil
@@ -57,6 +177,30 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt obtiene variable numérica con fallback (valores inválidos usan el default)
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvBool obtiene variable booleana con fallback (valores inválidos usan el default)
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvTime obtiene una fecha RFC3339 (vacía o inválida retorna el zero value)
+func getEnvTime(key string) time.Time {
+	if value, err := time.Parse(time.RFC3339, os.Getenv(key)); err == nil {
+		return value
+	}
+	return time.Time{}
+}
+
 // mustGetEnv obtiene variable REQUERIDA o hace panic (fail-fast)
 func mustGetEnv(key string) string {
 	value := os.Getenv(key)
@@ -1,23 +1,44 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"trips-api/internal/dao"
+	"trips-api/internal/realtime"
 	"trips-api/internal/service"
 )
 
+// sseHeartbeatInterval is how often a comment line is sent to keep idle SSE connections open
+// (proxies usually close connections without traffic after 30-60 seconds)
+const sseHeartbeatInterval = 15 * time.Second
+
+// sseRetryMs is the reconnection delay suggested to EventSource clients
+const sseRetryMs = 3000
+
 // ChatController handles chat-related HTTP requests
 type ChatController struct {
-	chatService service.ChatService
+	chatService        service.ChatService
+	translationService service.ChatTranslationService
+	exportService      service.ChatExportService
 }
 
 // NewChatController creates a new chat controller instance
-func NewChatController(chatService service.ChatService) *ChatController {
+// translationService may be nil, which disables the translation of the history
+func NewChatController(chatService service.ChatService, translationService service.ChatTranslationService, exportService service.ChatExportService) *ChatController {
 	return &ChatController{
-		chatService: chatService,
+		chatService:        chatService,
+		translationService: translationService,
+		exportService:      exportService,
 	}
 }
 
@@ -108,13 +129,57 @@ func (c *ChatController) SendMessage(ctx *gin.Context) {
 	})
 }
 
-// GetMessages handles GET /trips/:id/messages
-// Retrieves chat messages for a trip
+// GetMessages handles GET /trips/:id/messages?before=<RFC3339>&before_id=<id>&limit=50&translate_to=es
+// (also served as GET /trips/:id/chat/messages)
+// Retrieves a page of chat messages of a trip (oldest first); without before,
+// the most recent page. Older pages are requested with before = next_before and before_id = next_before_id
+//
+// With translate_to, the messages of other participants carry a "translation"; without it,
+// they are translated only if the user turned on auto-translate in users-api
 func (c *ChatController) GetMessages(ctx *gin.Context) {
 	tripID := ctx.Param("id")
 
+	var before time.Time
+	if raw := ctx.Query("before"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "before must be an RFC3339 timestamp",
+			})
+			return
+		}
+		before = parsed
+	}
+
+	var beforeID primitive.ObjectID
+	if raw := ctx.Query("before_id"); raw != "" {
+		parsed, err := primitive.ObjectIDFromHex(raw)
+		if err != nil || before.IsZero() {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "before_id must be a message ID and requires before",
+			})
+			return
+		}
+		beforeID = parsed
+	}
+
+	limit := service.DefaultMessagesPageSize
+	if raw := ctx.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > service.MaxMessagesPageSize {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("limit must be between 1 and %d", service.MaxMessagesPageSize),
+			})
+			return
+		}
+		limit = parsed
+	}
+
 	// Get messages from chat service
-	messages, err := c.chatService.GetMessages(ctx.Request.Context(), tripID)
+	page, err := c.chatService.GetMessages(ctx.Request.Context(), tripID, before, beforeID, limit)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -128,14 +193,392 @@ func (c *ChatController) GetMessages(ctx *gin.Context) {
 		return
 	}
 
+	translatedTo, ok := c.translateMessages(ctx, page.Messages)
+	if !ok {
+		return
+	}
+
 	log.Debug().
 		Str("trip_id", tripID).
-		Int("count", len(messages)).
+		Int("count", len(page.Messages)).
+		Str("translated_to", translatedTo).
 		Msg("Chat messages retrieved successfully")
 
+	response := gin.H{
+		"success":     true,
+		"messages":    page.Messages,
+		"count":       len(page.Messages),
+		"has_more":    page.HasMore,
+		"next_before": page.NextBefore,
+	}
+	if page.NextBeforeID != "" {
+		response["next_before_id"] = page.NextBeforeID
+	}
+	if translatedTo != "" {
+		response["translated_to"] = translatedTo
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// translateMessages applies ?translate_to= (or the user's auto-translate preference) to a page of messages
+// Returns the language used ("" if not translated); writes the error response and returns false on invalid requests
+func (c *ChatController) translateMessages(ctx *gin.Context, messages []*dao.Message) (string, bool) {
+	targetLanguage := ctx.Query("translate_to")
+	if c.translationService == nil {
+		if targetLanguage != "" {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   service.ErrTranslationUnavailable.Error(),
+			})
+			return "", false
+		}
+		return "", true
+	}
+
+	// Anonymous requests cannot have preferences; only an explicit translate_to applies
+	var userID int64
+	if raw, exists := ctx.Get("user_id"); exists {
+		switch id := raw.(type) {
+		case int64:
+			userID = id
+		case float64:
+			userID = int64(id)
+		}
+	}
+	if userID == 0 && targetLanguage == "" {
+		return "", true
+	}
+
+	language, err := c.translationService.TranslateMessages(ctx.Request.Context(), messages, userID, targetLanguage)
+	switch {
+	case errors.Is(err, service.ErrInvalidLanguage):
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return "", false
+	case errors.Is(err, service.ErrTranslationUnavailable):
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return "", false
+	case err != nil:
+		// Translation is best effort: the history is still returned untranslated
+		log.Warn().Err(err).Msg("Failed to translate chat messages")
+		return "", true
+	}
+	return language, true
+}
+
+// GetUnreadCount handles GET /trips/:id/messages/unread-count
+// Counts the messages of other participants the authenticated user has not read
+func (c *ChatController) GetUnreadCount(ctx *gin.Context) {
+	tripID := ctx.Param("id")
+
+	userID, ok := chatUserID(ctx)
+	if !ok {
+		return
+	}
+
+	unread, err := c.chatService.GetUnreadCount(ctx.Request.Context(), tripID, userID)
+	if err != nil {
+		log.Warn().Err(err).Str("trip_id", tripID).Int64("user_id", userID).Msg("Failed to count unread messages")
+		handleServiceError(ctx, err)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"success":  true,
-		"messages": messages,
-		"count":    len(messages),
+		"success": true,
+		"data":    unread,
+	})
+}
+
+// MarkAsRead handles POST /trips/:id/messages/read
+// Optional body {"message_id": "..."}: read up to that message; without it, up to now
+func (c *ChatController) MarkAsRead(ctx *gin.Context) {
+	tripID := ctx.Param("id")
+
+	userID, ok := chatUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		MessageID string `json:"message_id"`
+	}
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "invalid request body",
+			})
+			return
+		}
+	}
+
+	marker, err := c.chatService.MarkAsRead(ctx.Request.Context(), tripID, userID, req.MessageID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMessageID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		log.Warn().Err(err).Str("trip_id", tripID).Int64("user_id", userID).Msg("Failed to mark chat as read")
+		handleServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    marker,
+	})
+}
+
+// ExportChat handles GET /trips/:id/chat/export?format=json|text
+// Downloads the complete chat of the trip (oldest first) for dispute resolution
+// Only for the driver and the passengers, within the export window after the trip ends
+//
+// The export is streamed batch by batch: once the body started, a MongoDB error can only
+// cut the download short (logged), the status code is already sent
+func (c *ChatController) ExportChat(ctx *gin.Context) {
+	tripID := ctx.Param("id")
+
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "text" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "format must be json or text",
+		})
+		return
+	}
+
+	userID, ok := chatUserID(ctx)
+	if !ok {
+		return
+	}
+
+	export, err := c.exportService.StartExport(ctx.Request.Context(), tripID, userID)
+	if err != nil {
+		log.Warn().Err(err).Str("trip_id", tripID).Int64("user_id", userID).Msg("Chat export rejected")
+		handleServiceError(ctx, err)
+		return
+	}
+
+	// Large chats may take longer than the server WriteTimeout
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Debug().Err(err).Msg("Could not clear write deadline for chat export")
+	}
+
+	filename := fmt.Sprintf("trip-%s-chat.json", tripID)
+	contentType := "application/json; charset=utf-8"
+	if format == "text" {
+		filename = fmt.Sprintf("trip-%s-chat.txt", tripID)
+		contentType = "text/plain; charset=utf-8"
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(http.StatusOK)
+
+	var count int
+	if format == "text" {
+		count, err = c.writeTextExport(ctx, export)
+	} else {
+		count, err = c.writeJSONExport(ctx, export)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Int("written", count).Msg("Chat export interrupted")
+		return
+	}
+
+	log.Info().
+		Str("trip_id", tripID).
+		Int64("user_id", userID).
+		Str("format", format).
+		Int("count", count).
+		Msg("Chat exported successfully")
+}
+
+// writeJSONExport writes {"export": {...}, "messages": [...], "count": n} one batch at a time
+func (c *ChatController) writeJSONExport(ctx *gin.Context, export *service.ChatExport) (int, error) {
+	header, err := json.Marshal(export)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := fmt.Fprintf(ctx.Writer, `{"export":%s,"messages":[`, header); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = c.exportService.EachBatch(ctx.Request.Context(), export, func(messages []*dao.Message) error {
+		for _, message := range messages {
+			data, err := json.Marshal(message)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				if _, err := ctx.Writer.WriteString(","); err != nil {
+					return err
+				}
+			}
+			if _, err := ctx.Writer.Write(data); err != nil {
+				return err
+			}
+			count++
+		}
+		ctx.Writer.Flush()
+		return nil
 	})
+	if err != nil {
+		return count, err
+	}
+
+	_, err = fmt.Fprintf(ctx.Writer, `],"count":%d}`, count)
+	return count, err
+}
+
+// writeTextExport writes a header and one line per message: [time] name (#user_id): message
+// Continuation lines of multi-line messages are indented so every message starts with "["
+func (c *ChatController) writeTextExport(ctx *gin.Context, export *service.ChatExport) (int, error) {
+	_, err := fmt.Fprintf(ctx.Writer,
+		"Trip chat export\nTrip: %s (%s, driver #%d)\nExported at: %s by user #%d\n\n",
+		export.TripID, export.TripStatus, export.DriverID, export.ExportedAt.Format(time.RFC3339), export.ExportedBy)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = c.exportService.EachBatch(ctx.Request.Context(), export, func(messages []*dao.Message) error {
+		for _, message := range messages {
+			text := strings.ReplaceAll(message.Message, "\n", "\n    ")
+			if _, err := fmt.Fprintf(ctx.Writer, "[%s] %s (#%d): %s\n",
+				message.CreatedAt.UTC().Format(time.RFC3339), message.UserName, message.UserID, text); err != nil {
+				return err
+			}
+			count++
+		}
+		ctx.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	_, err = fmt.Fprintf(ctx.Writer, "\n%d messages\n", count)
+	return count, err
+}
+
+// chatUserID extracts the authenticated user ID; writes 401 and returns false if missing
+func chatUserID(ctx *gin.Context) (int64, bool) {
+	userID, exists := ctx.Get("user_id")
+	if exists {
+		switch id := userID.(type) {
+		case int64:
+			return id, true
+		case float64:
+			return int64(id), true
+		}
+	}
+
+	ctx.JSON(http.StatusUnauthorized, gin.H{
+		"success": false,
+		"error":   "unauthorized - authentication required",
+	})
+	return 0, false
+}
+
+// StreamChat handles GET /trips/:id/chat/stream
+// Streams new chat messages and trip status changes over Server-Sent Events
+// (alternative for clients that cannot use WebSockets behind proxies)
+//
+//   - Each event carries its ID; clients reconnect sending the Last-Event-ID header
+//     (or ?last_event_id=) and receive the events they missed
+//   - A heartbeat comment is sent every sseHeartbeatInterval
+func (c *ChatController) StreamChat(ctx *gin.Context) {
+	tripID := ctx.Param("id")
+
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	sub, replay, err := c.chatService.Subscribe(ctx.Request.Context(), tripID, lastEventID)
+	if err != nil {
+		log.Warn().Err(err).Str("trip_id", tripID).Msg("Failed to open chat stream")
+		handleServiceError(ctx, err)
+		return
+	}
+	defer c.chatService.Unsubscribe(sub)
+
+	// The server WriteTimeout would otherwise cut long-lived streams
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Debug().Err(err).Msg("Could not clear write deadline for SSE stream")
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no") // Disable buffering in nginx
+	ctx.Status(http.StatusOK)
+
+	fmt.Fprintf(ctx.Writer, "retry: %d\n\n", sseRetryMs)
+
+	// Replay missed events; remember their IDs so live duplicates are skipped
+	sent := make(map[string]bool, len(replay))
+	for _, event := range replay {
+		if err := writeSSEEvent(ctx.Writer, event); err != nil {
+			return
+		}
+		sent[event.ID] = true
+	}
+	ctx.Writer.Flush()
+
+	log.Info().
+		Str("trip_id", tripID).
+		Str("last_event_id", lastEventID).
+		Int("replayed", len(replay)).
+		Msg("Chat SSE stream opened")
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			log.Debug().Str("trip_id", tripID).Msg("Chat SSE stream closed by client")
+			return
+
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Disconnected by the hub (slow consumer); the client reconnects with Last-Event-ID
+				return
+			}
+			if sent[event.ID] {
+				continue
+			}
+			if err := writeSSEEvent(ctx.Writer, event); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// writeSSEEvent writes an event in the text/event-stream format
+func writeSSEEvent(w gin.ResponseWriter, event realtime.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
 }
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"trips-api/internal/cities"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCitySearchLimit y maxCitySearchLimit acotan las ciudades que devuelve GET /cities
+const (
+	defaultCitySearchLimit = 10
+	maxCitySearchLimit     = 50
+)
+
+// CityController define la interfaz del controlador del catálogo de ciudades
+type CityController interface {
+	SearchCities(c *gin.Context)
+}
+
+type cityController struct {
+	catalog cities.Catalog
+}
+
+// NewCityController crea una nueva instancia del controlador del catálogo de ciudades
+func NewCityController(catalog cities.Catalog) CityController {
+	return &cityController{
+		catalog: catalog,
+	}
+}
+
+// SearchCities busca ciudades del catálogo por nombre o alias (autocompletado al publicar un viaje)
+// GET /cities?q=cord&country=AR&limit=10
+// El front envía el id elegido como city_id del origen/destino, así el viaje no depende del texto escrito
+func (ctrl *cityController) SearchCities(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "q es requerido",
+		})
+		return
+	}
+
+	limit := defaultCitySearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > maxCitySearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "limit debe estar entre 1 y " + strconv.Itoa(maxCitySearchLimit),
+			})
+			return
+		}
+		limit = value
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ctrl.catalog.Search(query, c.Query("country"), limit),
+	})
+}
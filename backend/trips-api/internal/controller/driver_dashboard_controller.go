@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"strconv"
+	"trips-api/internal/domain"
+	"trips-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DriverDashboardController define la interfaz del controlador del panel del conductor
+type DriverDashboardController interface {
+	ListMyTrips(c *gin.Context)
+}
+
+type driverDashboardController struct {
+	dashboardService service.DriverDashboardService
+}
+
+// NewDriverDashboardController crea una nueva instancia del controlador del panel del conductor
+func NewDriverDashboardController(dashboardService service.DriverDashboardService) DriverDashboardController {
+	return &driverDashboardController{
+		dashboardService: dashboardService,
+	}
+}
+
+// ListMyTrips lista los viajes del conductor autenticado con sus métricas
+// GET /trips/mine?scope=upcoming|past|all&page=1&limit=10&include=bookings
+// Requiere autenticación (JWT)
+func (ctrl *driverDashboardController) ListMyTrips(c *gin.Context) {
+	// Extraer user_id del contexto (viene del middleware JWT)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	scope := c.DefaultQuery("scope", domain.DriverTripsScopeAll)
+	if !domain.IsValidDriverTripsScope(scope) {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "scope inválido, usar: upcoming, past o all",
+		})
+		return
+	}
+
+	// Paginación (el servicio corrige los valores fuera de rango)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	includeBookings := c.Query("include") == "bookings"
+
+	response, err := ctrl.dashboardService.ListDriverTrips(
+		c.Request.Context(),
+		userID.(int64),
+		scope,
+		page,
+		limit,
+		includeBookings,
+		c.GetHeader("Authorization"),
+	)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
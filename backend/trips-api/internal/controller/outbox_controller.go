@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutboxRequeuer vuelve a encolar los mensajes del outbox que agotaron los reintentos
+// (implementado por messaging.OutboxRelay)
+type OutboxRequeuer interface {
+	RequeueFailed(ctx context.Context) (int64, error)
+}
+
+// OutboxController define la interfaz del controlador de operación del outbox
+type OutboxController interface {
+	RequeueFailed(c *gin.Context)
+}
+
+type outboxController struct {
+	requeuer OutboxRequeuer
+}
+
+// NewOutboxController crea una nueva instancia del controlador del outbox
+func NewOutboxController(requeuer OutboxRequeuer) OutboxController {
+	return &outboxController{
+		requeuer: requeuer,
+	}
+}
+
+// RequeueFailed reencola los mensajes failed para que el relay los publique de nuevo
+// POST /internal/outbox/requeue-failed
+// Ruta interna con el secreto compartido: la usa quien opera el servicio después de resolver la causa
+func (ctrl *outboxController) RequeueFailed(c *gin.Context) {
+	count, err := ctrl.requeuer.RequeueFailed(c.Request.Context())
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"requeued": count,
+		},
+	})
+}
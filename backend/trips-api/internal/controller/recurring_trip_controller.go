@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"trips-api/internal/domain"
+	"trips-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecurringTripController define la interfaz del controlador de viajes recurrentes
+type RecurringTripController interface {
+	CreateRecurringTrip(c *gin.Context)
+	ListRecurringTrips(c *gin.Context)
+	GetRecurringTrip(c *gin.Context)
+	UpdateRecurringTrip(c *gin.Context)
+	DeleteRecurringTrip(c *gin.Context)
+}
+
+type recurringTripController struct {
+	recurringTripService service.RecurringTripService
+}
+
+// NewRecurringTripController crea una nueva instancia del controlador de viajes recurrentes
+func NewRecurringTripController(recurringTripService service.RecurringTripService) RecurringTripController {
+	return &recurringTripController{
+		recurringTripService: recurringTripService,
+	}
+}
+
+// CreateRecurringTrip crea un viaje recurrente para el conductor autenticado
+// POST /trips/recurring
+// Requiere autenticación (JWT)
+func (ctrl *recurringTripController) CreateRecurringTrip(c *gin.Context) {
+	// Extraer user_id del contexto (viene del middleware JWT)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	// Extraer Authorization header para forwarding a users-api
+	authHeader := c.GetHeader("Authorization")
+
+	// Bind request body a CreateRecurringTripRequest
+	var request domain.CreateRecurringTripRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	// Llamar al servicio
+	recurring, err := ctrl.recurringTripService.CreateRecurringTrip(c.Request.Context(), userID.(int64), authHeader, request)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	// Respuesta exitosa
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    recurring,
+	})
+}
+
+// ListRecurringTrips lista los viajes recurrentes del conductor autenticado
+// GET /trips/recurring
+// Requiere autenticación (JWT)
+func (ctrl *recurringTripController) ListRecurringTrips(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	recurringTrips, err := ctrl.recurringTripService.ListRecurringTrips(c.Request.Context(), userID.(int64))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    recurringTrips,
+	})
+}
+
+// GetRecurringTrip obtiene un viaje recurrente del conductor autenticado
+// GET /trips/recurring/:id
+// Requiere autenticación (JWT) y ser el dueño del viaje recurrente
+func (ctrl *recurringTripController) GetRecurringTrip(c *gin.Context) {
+	recurringID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	recurring, err := ctrl.recurringTripService.GetRecurringTrip(c.Request.Context(), recurringID, userID.(int64))
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    recurring,
+	})
+}
+
+// UpdateRecurringTrip actualiza un viaje recurrente (también pausa/reanuda con status)
+// PUT /trips/recurring/:id
+// Requiere autenticación (JWT) y ser el dueño del viaje recurrente
+func (ctrl *recurringTripController) UpdateRecurringTrip(c *gin.Context) {
+	recurringID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	var request domain.UpdateRecurringTripRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	recurring, err := ctrl.recurringTripService.UpdateRecurringTrip(c.Request.Context(), recurringID, userID.(int64), request)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    recurring,
+	})
+}
+
+// DeleteRecurringTrip elimina un viaje recurrente y sus instancias futuras sin reservas
+// DELETE /trips/recurring/:id
+// Requiere autenticación (JWT) y ser el dueño del viaje recurrente
+func (ctrl *recurringTripController) DeleteRecurringTrip(c *gin.Context) {
+	recurringID := c.Param("id")
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	if err := ctrl.recurringTripService.DeleteRecurringTrip(c.Request.Context(), recurringID, userID.(int64)); err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"message": "recurring trip deleted successfully",
+	})
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"trips-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseTimeController define la interfaz del controlador de tiempos de respuesta de conductores
+type ResponseTimeController interface {
+	GetDriverResponseTime(c *gin.Context)
+}
+
+type responseTimeController struct {
+	responseTimeService service.ResponseTimeService
+}
+
+// NewResponseTimeController crea una nueva instancia del controlador de tiempos de respuesta
+func NewResponseTimeController(responseTimeService service.ResponseTimeService) ResponseTimeController {
+	return &responseTimeController{
+		responseTimeService: responseTimeService,
+	}
+}
+
+// GetDriverResponseTime retorna los promedios móviles de respuesta de un conductor
+// GET /internal/drivers/:id/response-time
+// Ruta interna (sin autenticación), para otros servicios
+func (ctrl *responseTimeController) GetDriverResponseTime(c *gin.Context) {
+	driverID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || driverID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "driver id inválido",
+		})
+		return
+	}
+
+	stats, err := ctrl.responseTimeService.GetDriverStats(c.Request.Context(), driverID)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
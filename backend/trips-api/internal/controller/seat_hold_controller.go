@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"net/http"
+	"trips-api/internal/domain"
+	"trips-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeatHoldController define la interfaz del controlador de retenciones de asientos
+type SeatHoldController interface {
+	CreateHold(c *gin.Context)
+	ReleaseHold(c *gin.Context)
+	ConfirmHold(c *gin.Context)
+}
+
+type seatHoldController struct {
+	seatHoldService service.SeatHoldService
+}
+
+// NewSeatHoldController crea una nueva instancia del controlador de retenciones de asientos
+func NewSeatHoldController(seatHoldService service.SeatHoldService) SeatHoldController {
+	return &seatHoldController{
+		seatHoldService: seatHoldService,
+	}
+}
+
+// CreateHold retiene asientos de un viaje para una reserva que bookings-api está por crear
+// POST /trips/:id/holds
+// Ruta entre servicios (secreto compartido, ver middleware.RequireInternalSecret)
+func (ctrl *seatHoldController) CreateHold(c *gin.Context) {
+	var request domain.CreateSeatHoldRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	hold, err := ctrl.seatHoldService.CreateHold(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		handleSeatHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    hold,
+	})
+}
+
+// ReleaseHold libera una retención y devuelve sus asientos al viaje
+// DELETE /trips/:id/holds/:hold_id?reservation_id=
+// 404 si ya se liberó, venció o es de otra reserva, 409 si ya se convirtió en reserva
+func (ctrl *seatHoldController) ReleaseHold(c *gin.Context) {
+	reservationID, ok := holdReservationID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.seatHoldService.ReleaseHold(c.Request.Context(), c.Param("id"), c.Param("hold_id"), reservationID); err != nil {
+		handleSeatHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Seat hold released",
+	})
+}
+
+// ConfirmHold convierte una retención en reserva confirmada
+// POST /trips/:id/holds/:hold_id/confirm?reservation_id=
+// Idempotente: repetirlo devuelve la retención con status confirmed
+func (ctrl *seatHoldController) ConfirmHold(c *gin.Context) {
+	reservationID, ok := holdReservationID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := ctrl.seatHoldService.ConfirmHold(c.Request.Context(), c.Param("id"), c.Param("hold_id"), reservationID)
+	if err != nil {
+		handleSeatHoldError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    hold,
+	})
+}
+
+// holdReservationID lee la reserva dueña de la retención (?reservation_id=); sin ella responde 400
+// Así el hold_id solo no alcanza para liberar o confirmar la retención de otra reserva
+func holdReservationID(c *gin.Context) (string, bool) {
+	reservationID := c.Query("reservation_id")
+	if reservationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "reservation_id query parameter is required",
+		})
+		return "", false
+	}
+	return reservationID, true
+}
+
+// handleSeatHoldError mapea los errores de retenciones: bookings-api distingue falta de asientos (409)
+// de viaje inexistente (404 con body JSON); el resto sigue el mapeo general
+func handleSeatHoldError(c *gin.Context, err error) {
+	if appErr, ok := err.(*domain.AppError); ok {
+		switch appErr.Code {
+		case "NO_SEATS_AVAILABLE", "TRIP_NOT_BOOKABLE", "SEAT_HOLD_CONFIRMED":
+			c.JSON(http.StatusConflict, gin.H{
+				"success": false,
+				"error":   appErr.Message,
+				"code":    appErr.Code,
+			})
+			return
+		case "SEAT_HOLD_NOT_FOUND":
+			c.JSON(http.StatusNotFound, gin.H{
+				"success": false,
+				"error":   appErr.Message,
+				"code":    appErr.Code,
+			})
+			return
+		}
+	}
+
+	handleServiceError(c, err)
+}
@@ -1,11 +1,17 @@
 package controller
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"trips-api/internal/domain"
 	"trips-api/internal/service"
+	"trips-api/internal/tripimport"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,10 +19,12 @@ import (
 // TripController define la interfaz del controlador de viajes
 type TripController interface {
 	CreateTrip(c *gin.Context)
+	ImportTrips(c *gin.Context)
 	GetTrip(c *gin.Context)
 	ListTrips(c *gin.Context)
 	UpdateTrip(c *gin.Context)
 	DeleteTrip(c *gin.Context)
+	GetPriceHistory(c *gin.Context)
 }
 
 type tripController struct {
@@ -34,6 +42,120 @@ func NewTripController(tripService service.TripService) TripController {
 // POST /trips
 // Requiere autenticación (JWT)
 func (ctrl *tripController) CreateTrip(c *gin.Context) {
+	userID, authHeader, ok := driverCredentials(c)
+	if !ok {
+		return
+	}
+
+	// Bind request body a CreateTripRequest
+	var request domain.CreateTripRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	// Llamar al servicio (forward complete Authorization header)
+	trip, err := ctrl.tripService.CreateTrip(c.Request.Context(), userID, authHeader, request)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	// Respuesta exitosa
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    trip,
+	})
+}
+
+// ImportTrips maneja la importación masiva de viajes (empresas con flota)
+// POST /trips/bulk
+// Requiere autenticación (JWT)
+//
+// Acepta un array JSON de viajes con el formato de POST /trips, un CSV en el body (Content-Type: text/csv)
+// o un archivo subido como multipart en el campo "file" (.csv o .json)
+// Responde 201 si se crearon todos los viajes y 207 con el reporte por fila si alguno se rechazó
+func (ctrl *tripController) ImportTrips(c *gin.Context) {
+	userID, authHeader, ok := driverCredentials(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, domain.MaxTripImportBytes)
+
+	rows, err := parseTripImport(c)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"success": false,
+				"error":   "la importación supera el tamaño máximo permitido",
+			})
+			return
+		}
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"code":    domain.ErrInvalidTripImport.Code,
+		})
+		return
+	}
+
+	report, err := ctrl.tripService.ImportTrips(c.Request.Context(), userID, authHeader, rows)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if report.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// parseTripImport lee la importación según el Content-Type: multipart (campo "file"), text/csv o JSON
+func parseTripImport(c *gin.Context) ([]domain.TripImportRow, error) {
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("archivo requerido en el campo file: %w", err)
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		format := tripimport.FormatCSV
+		if strings.EqualFold(filepath.Ext(header.Filename), ".json") {
+			format = tripimport.FormatJSON
+		}
+		return tripimport.Parse(format, file)
+	}
+
+	// El body se lee antes de parsearlo para distinguir el límite de tamaño de un archivo inválido
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch c.ContentType() {
+	case "text/csv", "application/csv":
+		return tripimport.ParseCSV(bytes.NewReader(body))
+	default:
+		return tripimport.ParseJSON(bytes.NewReader(body))
+	}
+}
+
+// driverCredentials obtiene el user_id del JWT y el header Authorization que se reenvía a users-api
+// Si falta alguno responde 401 y retorna ok = false
+func driverCredentials(c *gin.Context) (int64, string, bool) {
 	// Extraer user_id del contexto (viene del middleware JWT)
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -41,7 +163,7 @@ func (ctrl *tripController) CreateTrip(c *gin.Context) {
 			"success": false,
 			"error":   "usuario no autenticado",
 		})
-		return
+		return 0, "", false
 	}
 
 	// Extraer Authorization header para forwarding a users-api
@@ -51,7 +173,7 @@ func (ctrl *tripController) CreateTrip(c *gin.Context) {
 			"success": false,
 			"error":   "token de autenticación requerido",
 		})
-		return
+		return 0, "", false
 	}
 
 	// Validar formato del header (debe ser "Bearer {token}")
@@ -60,51 +182,48 @@ func (ctrl *tripController) CreateTrip(c *gin.Context) {
 			"success": false,
 			"error":   "formato de token inválido",
 		})
-		return
+		return 0, "", false
 	}
 
-	// Bind request body a CreateTripRequest
-	var request domain.CreateTripRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(400, gin.H{
-			"success": false,
-			"error":   "datos inválidos: " + err.Error(),
-		})
-		return
-	}
+	return userID.(int64), authHeader, true
+}
 
-	// Llamar al servicio (forward complete Authorization header)
-	trip, err := ctrl.tripService.CreateTrip(c.Request.Context(), userID.(int64), authHeader, request)
+// GetTrip obtiene un viaje por su ID
+// GET /trips/:id
+// Público (sin autenticación)
+func (ctrl *tripController) GetTrip(c *gin.Context) {
+	// Extraer trip ID del path
+	tripID := c.Param("id")
+
+	// Llamar al servicio
+	trip, err := ctrl.tripService.GetTrip(c.Request.Context(), tripID)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
 	// Respuesta exitosa
-	c.JSON(201, gin.H{
+	c.JSON(200, gin.H{
 		"success": true,
 		"data":    trip,
 	})
 }
 
-// GetTrip obtiene un viaje por su ID
-// GET /trips/:id
-// Público (sin autenticación)
-func (ctrl *tripController) GetTrip(c *gin.Context) {
-	// Extraer trip ID del path
+// GetPriceHistory maneja la obtención del historial de precios de un viaje
+// GET /trips/:id/price-history
+// Público (sin autenticación), como GET /trips/:id
+func (ctrl *tripController) GetPriceHistory(c *gin.Context) {
 	tripID := c.Param("id")
 
-	// Llamar al servicio
-	trip, err := ctrl.tripService.GetTrip(c.Request.Context(), tripID)
+	history, err := ctrl.tripService.GetPriceHistory(c.Request.Context(), tripID)
 	if err != nil {
 		handleServiceError(c, err)
 		return
 	}
 
-	// Respuesta exitosa
 	c.JSON(200, gin.H{
 		"success": true,
-		"data":    trip,
+		"data":    history,
 	})
 }
 
@@ -269,26 +388,47 @@ func handleServiceError(c *gin.Context, err error) {
 	// Type assertion a AppError
 	if appErr, ok := err.(*domain.AppError); ok {
 		switch appErr.Code {
-		case "TRIP_NOT_FOUND", "DRIVER_NOT_FOUND":
+		case "TRIP_NOT_FOUND", "DRIVER_NOT_FOUND", "RECURRING_TRIP_NOT_FOUND":
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
 				"error":   appErr.Message,
 			})
-		case "UNAUTHORIZED":
+		case "UNAUTHORIZED", "DRIVING_NOT_ALLOWED":
 			c.JSON(http.StatusForbidden, gin.H{
 				"success": false,
 				"error":   appErr.Message,
 			})
-		case "PAST_DEPARTURE", "HAS_RESERVATIONS", "NO_SEATS_AVAILABLE":
+		case "PAST_DEPARTURE", "HAS_RESERVATIONS", "NO_SEATS_AVAILABLE", "INVALID_VACATION_RANGE", "VACATION_START_IN_PAST", "INVALID_PICKUP_POINTS", "INVALID_RECURRING_TRIP", "INVALID_BOOKING_CLOSE", "INVALID_DESCRIPTION":
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   appErr.Message,
+			})
+		case "CURRENCY_NOT_ALLOWED", "PRICE_ABOVE_MARKET_CAP", "TRIP_DISTANCE_ABOVE_MARKET_CAP", "UNKNOWN_CITY":
+			// Errores de mercado y de catálogo: se incluye el código y el límite superado o el campo
 			c.JSON(http.StatusBadRequest, gin.H{
 				"success": false,
 				"error":   appErr.Message,
+				"code":    appErr.Code,
+				"details": appErr.Details,
 			})
-		case "OPTIMISTIC_LOCK_FAILED":
+		case "AMBIGUOUS_CITY":
+			// La ciudad coincide con varias del catálogo: details trae las sugerencias para elegir una
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"success": false,
+				"error":   appErr.Message,
+				"code":    appErr.Code,
+				"details": appErr.Details,
+			})
+		case "OPTIMISTIC_LOCK_FAILED", "VACATION_OVERLAP", "DRIVER_ON_VACATION", "CHAT_EXPORT_NOT_AVAILABLE":
 			c.JSON(http.StatusConflict, gin.H{
 				"success": false,
 				"error":   appErr.Message,
 			})
+		case "CHAT_EXPORT_EXPIRED":
+			c.JSON(http.StatusGone, gin.H{
+				"success": false,
+				"error":   appErr.Message,
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"success": false,
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"trips-api/internal/domain"
+	"trips-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VacationController define la interfaz del controlador de vacaciones de conductores
+type VacationController interface {
+	CreateVacation(c *gin.Context)
+}
+
+type vacationController struct {
+	vacationService service.VacationService
+}
+
+// NewVacationController crea una nueva instancia del controlador de vacaciones
+func NewVacationController(vacationService service.VacationService) VacationController {
+	return &vacationController{
+		vacationService: vacationService,
+	}
+}
+
+// CreateVacation activa el modo vacaciones del conductor autenticado
+// POST /drivers/me/vacation
+// Requiere autenticación (JWT)
+func (ctrl *vacationController) CreateVacation(c *gin.Context) {
+	// Extraer user_id del contexto (viene del middleware JWT)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	// Bind request body a CreateVacationRequest
+	var request domain.CreateVacationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	// Llamar al servicio
+	vacation, err := ctrl.vacationService.CreateVacation(c.Request.Context(), userID.(int64), request)
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	// Respuesta exitosa
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    vacation,
+	})
+}
@@ -14,9 +14,46 @@ type Message struct {
 	UserName  string             `bson:"user_name" json:"user_name"`
 	Message   string             `bson:"message" json:"message"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+
+	// Translation is filled in when the history is requested translated (never stored with the message)
+	Translation *MessageTranslation `bson:"-" json:"translation,omitempty"`
 }
 
 // CollectionName returns the MongoDB collection name for messages
 func (Message) CollectionName() string {
 	return "messages"
 }
+
+// ChatReadMarker stores up to which message a user has read the chat of a trip
+// One document per (trip_id, user_id); messages created after LastReadAt are unread
+type ChatReadMarker struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	TripID            string             `bson:"trip_id" json:"trip_id"`
+	UserID            int64              `bson:"user_id" json:"user_id"`
+	LastReadAt        time.Time          `bson:"last_read_at" json:"last_read_at"`
+	LastReadMessageID string             `bson:"last_read_message_id,omitempty" json:"last_read_message_id,omitempty"`
+	UpdatedAt         time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CollectionName returns the MongoDB collection name for chat read markers
+func (ChatReadMarker) CollectionName() string {
+	return "chat_read_markers"
+}
+
+// MessageTranslation caches the translation of a chat message to a language
+// One document per (message_id, language): messages cannot be edited, so a translation never goes stale
+type MessageTranslation struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	MessageID      primitive.ObjectID `bson:"message_id" json:"-"`
+	TripID         string             `bson:"trip_id" json:"-"`
+	Language       string             `bson:"language" json:"language"`
+	Text           string             `bson:"text" json:"text"`
+	SourceLanguage string             `bson:"source_language,omitempty" json:"source_language,omitempty"`
+	Provider       string             `bson:"provider" json:"provider"`
+	CreatedAt      time.Time          `bson:"created_at" json:"-"`
+}
+
+// CollectionName returns the MongoDB collection name for chat message translations
+func (MessageTranslation) CollectionName() string {
+	return "message_translations"
+}
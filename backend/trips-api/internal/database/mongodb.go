@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"time"
+	"trips-api/internal/metrics"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -17,7 +19,8 @@ func ConnectMongoDB(uri, dbName string) (*mongo.Database, error) {
 	defer cancel()
 
 	// Crear cliente de MongoDB
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	// El monitor registra la latencia de cada comando en las métricas de Prometheus
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(metrics.MongoMonitor()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
@@ -32,6 +35,9 @@ func ConnectMongoDB(uri, dbName string) (*mongo.Database, error) {
 	return client.Database(dbName), nil
 }
 
+// outboxSentRetention es cuánto se conserva un mensaje del outbox después de publicarse (índice TTL)
+const outboxSentRetention = 7 * 24 * time.Hour
+
 // CreateIndexes crea todos los índices necesarios para las colecciones
 func CreateIndexes(db *mongo.Database) error {
 	ctx := context.Background()
@@ -44,6 +50,13 @@ func CreateIndexes(db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "driver_id", Value: 1}},
 		},
+		// Índice para el panel del conductor (GET /trips/mine ordena por fecha de salida)
+		{
+			Keys: bson.D{
+				{Key: "driver_id", Value: 1},
+				{Key: "departure_datetime", Value: -1},
+			},
+		},
 		// Índice para filtrar por estado
 		{
 			Keys: bson.D{{Key: "status", Value: 1}},
@@ -52,6 +65,13 @@ func CreateIndexes(db *mongo.Database) error {
 		{
 			Keys: bson.D{{Key: "departure_datetime", Value: 1}},
 		},
+		// Índice para el cierre automático de reservas (scheduler de estados)
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "booking_closes_at", Value: 1},
+			},
+		},
 		// Índice compuesto para búsquedas por ciudad de origen y destino
 		{
 			Keys: bson.D{
@@ -59,6 +79,29 @@ func CreateIndexes(db *mongo.Database) error {
 				{Key: "destination.city", Value: 1},
 			},
 		},
+		// Índice para búsquedas exactas por ruta del catálogo de ciudades (origen:destino)
+		{
+			Keys: bson.D{
+				{Key: "route_id", Value: 1},
+				{Key: "departure_datetime", Value: 1},
+			},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"route_id": bson.M{"$gt": ""}}),
+		},
+		// ÍNDICE ÚNICO PARCIAL: una sola instancia por salida de cada viaje recurrente
+		// Evita duplicados si el scheduler se ejecuta dos veces sobre la misma ventana
+		{
+			Keys: bson.D{
+				{Key: "recurring_trip_id", Value: 1},
+				{Key: "departure_datetime", Value: 1},
+			},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"recurring_trip_id": bson.M{"$exists": true}}),
+		},
+		// Índice multikey para que el expirador encuentre los viajes con retenciones vencidas
+		{
+			Keys: bson.D{{Key: "seat_holds.expires_at", Value: 1}},
+		},
 	}
 
 	_, err := tripsCollection.Indexes().CreateMany(ctx, tripIndexes)
@@ -96,5 +139,294 @@ func CreateIndexes(db *mongo.Database) error {
 
 	log.Println("Processed_events collection indexes created (UNIQUE constraint on event_id)")
 
+	// ==================== DRIVER_VACATIONS COLLECTION INDEXES ====================
+	vacationsCollection := db.Collection("driver_vacations")
+
+	vacationIndexes := []mongo.IndexModel{
+		// Índice compuesto para detectar superposición de vacaciones de un conductor
+		{
+			Keys: bson.D{
+				{Key: "driver_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "start_date", Value: 1},
+			},
+		},
+		// Índice para el worker que reanuda vacaciones terminadas
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "end_date", Value: 1},
+			},
+		},
+	}
+
+	_, err = vacationsCollection.Indexes().CreateMany(ctx, vacationIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create driver_vacations indexes: %w", err)
+	}
+
+	log.Println("✅ Driver_vacations collection indexes created")
+
+	// ==================== RECURRING_TRIPS COLLECTION INDEXES ====================
+	recurringTripsCollection := db.Collection("recurring_trips")
+
+	recurringTripIndexes := []mongo.IndexModel{
+		// Índice para listar los viajes recurrentes de un conductor
+		{
+			Keys: bson.D{{Key: "driver_id", Value: 1}},
+		},
+		// Índice para el scheduler que materializa instancias
+		{
+			Keys: bson.D{{Key: "status", Value: 1}},
+		},
+	}
+
+	_, err = recurringTripsCollection.Indexes().CreateMany(ctx, recurringTripIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring_trips indexes: %w", err)
+	}
+
+	log.Println("✅ Recurring_trips collection indexes created")
+
+	// ==================== EVENT_ARCHIVE COLLECTION INDEXES ====================
+	eventArchiveCollection := db.Collection("event_archive")
+
+	eventArchiveIndexes := []mongo.IndexModel{
+		// Índice UNIQUE en sequence: orden total del archivo para reconstrucciones
+		{
+			Keys:    bson.D{{Key: "sequence", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Índice UNIQUE en event_id: un evento se archiva una sola vez
+		{
+			Keys:    bson.D{{Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Índice para reconstruir el historial de un viaje
+		{
+			Keys: bson.D{{Key: "trip_id", Value: 1}, {Key: "sequence", Value: 1}},
+		},
+	}
+
+	_, err = eventArchiveCollection.Indexes().CreateMany(ctx, eventArchiveIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create event_archive indexes: %w", err)
+	}
+
+	log.Println("✅ Event_archive collection indexes created")
+
+	// ==================== MESSAGES COLLECTION INDEXES ====================
+	messagesCollection := db.Collection("messages")
+
+	messageIndexes := []mongo.IndexModel{
+		// Índice para paginar el historial del chat (?before=) y contar no leídos
+		{
+			Keys: bson.D{{Key: "trip_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+		},
+		// Índice para recorrer el chat por _id (reenvío a clientes realtime y exportación)
+		{
+			Keys: bson.D{{Key: "trip_id", Value: 1}, {Key: "_id", Value: 1}},
+		},
+	}
+
+	_, err = messagesCollection.Indexes().CreateMany(ctx, messageIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create messages indexes: %w", err)
+	}
+
+	log.Println("✅ Messages collection indexes created")
+
+	// ==================== CHAT_READ_MARKERS COLLECTION INDEXES ====================
+	readMarkersCollection := db.Collection("chat_read_markers")
+
+	readMarkerIndexes := []mongo.IndexModel{
+		// Índice UNIQUE: un marcador de lectura por usuario y viaje
+		{
+			Keys:    bson.D{{Key: "trip_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err = readMarkersCollection.Indexes().CreateMany(ctx, readMarkerIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create chat_read_markers indexes: %w", err)
+	}
+
+	log.Println("✅ Chat_read_markers collection indexes created")
+
+	// ==================== MESSAGE_TRANSLATIONS COLLECTION INDEXES ====================
+	messageTranslationsCollection := db.Collection("message_translations")
+
+	messageTranslationIndexes := []mongo.IndexModel{
+		// Índice UNIQUE: una traducción cacheada por mensaje e idioma
+		{
+			Keys:    bson.D{{Key: "message_id", Value: 1}, {Key: "language", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err = messageTranslationsCollection.Indexes().CreateMany(ctx, messageTranslationIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create message_translations indexes: %w", err)
+	}
+
+	log.Println("✅ Message_translations collection indexes created")
+
+	// ==================== DRIVER_RESPONSE_STATS COLLECTION INDEXES ====================
+	responseStatsCollection := db.Collection("driver_response_stats")
+
+	responseStatsIndexes := []mongo.IndexModel{
+		// Índice UNIQUE: un documento de tiempos de respuesta por conductor (upsert)
+		{
+			Keys:    bson.D{{Key: "driver_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+
+	_, err = responseStatsCollection.Indexes().CreateMany(ctx, responseStatsIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create driver_response_stats indexes: %w", err)
+	}
+
+	log.Println("✅ Driver_response_stats collection indexes created")
+
+	// ==================== OUTBOX COLLECTION INDEXES ====================
+	outboxCollection := db.Collection("outbox")
+
+	outboxIndexes := []mongo.IndexModel{
+		// Índice UNIQUE en event_id: un evento se escribe en el outbox una sola vez
+		{
+			Keys:    bson.D{{Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Índice para que el relay tome los mensajes pendientes vencidos
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+		},
+		// TTL: los mensajes publicados se borran a los 7 días (solo los sent tienen sent_at,
+		// los pending y failed quedan hasta publicarse o reencolarse)
+		{
+			Keys:    bson.D{{Key: "sent_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(outboxSentRetention.Seconds())),
+		},
+	}
+
+	_, err = outboxCollection.Indexes().CreateMany(ctx, outboxIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox indexes: %w", err)
+	}
+
+	log.Println("✅ Outbox collection indexes created")
+
+	// ==================== TRIP_RESERVATIONS COLLECTION INDEXES ====================
+	tripReservationsCollection := db.Collection("trip_reservations")
+
+	tripReservationIndexes := []mongo.IndexModel{
+		// Índice UNIQUE en reservation_id: una entrada del ledger por reserva (upsert)
+		{
+			Keys:    bson.D{{Key: "reservation_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// Índice para sumar las reservas confirmadas de cada viaje
+		{
+			Keys: bson.D{{Key: "trip_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+		// Índice para resolver si una retención ya se confirmó (DELETE / confirm de retenciones inactivas)
+		{
+			Keys:    bson.D{{Key: "seat_hold_id", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	}
+
+	_, err = tripReservationsCollection.Indexes().CreateMany(ctx, tripReservationIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create trip_reservations indexes: %w", err)
+	}
+
+	log.Println("✅ Trip_reservations collection indexes created")
+
+	// ==================== TRIP_PRICE_HISTORY COLLECTION INDEXES ====================
+	priceHistoryCollection := db.Collection("trip_price_history")
+
+	priceHistoryIndexes := []mongo.IndexModel{
+		// Índice para listar el historial de un viaje, el cambio más nuevo primero
+		{
+			Keys: bson.D{{Key: "trip_id", Value: 1}, {Key: "changed_at", Value: -1}},
+		},
+	}
+
+	_, err = priceHistoryCollection.Indexes().CreateMany(ctx, priceHistoryIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create trip_price_history indexes: %w", err)
+	}
+
+	log.Println("✅ Trip_price_history collection indexes created")
+
+	// ==================== ROUTES COLLECTION INDEXES ====================
+	// El _id de las ciudades (cities) y de las rutas es el ID del catálogo, no hacen falta otros índices únicos
+	routesCollection := db.Collection("routes")
+
+	routeIndexes := []mongo.IndexModel{
+		// Índice para listar las rutas que salen de una ciudad, las más usadas primero
+		{
+			Keys: bson.D{{Key: "origin_city_id", Value: 1}, {Key: "trip_count", Value: -1}},
+		},
+	}
+
+	_, err = routesCollection.Indexes().CreateMany(ctx, routeIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create routes indexes: %w", err)
+	}
+
+	log.Println("✅ Routes collection indexes created")
+
+	return nil
+}
+
+// MigrateTripEventSequences copia las secuencias de trip_event_sequences (contador por viaje que se
+// asignaba al publicar) a trips.event_sequence, que ahora se incrementa en la misma escritura del viaje
+// Sin esto los consumers que guardaron una secuencia vieja descartarían los eventos nuevos por stale
+// Idempotente: solo completa los viajes que todavía no tienen event_sequence
+func MigrateTripEventSequences(db *mongo.Database) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	cursor, err := db.Collection("trip_event_sequences").Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("failed to read trip_event_sequences: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	trips := db.Collection("trips")
+	migrated := 0
+	for cursor.Next(ctx) {
+		var counter struct {
+			TripID string `bson:"_id"`
+			Seq    int64  `bson:"seq"`
+		}
+		if err := cursor.Decode(&counter); err != nil {
+			return fmt.Errorf("failed to decode trip event sequence: %w", err)
+		}
+		objectID, err := primitive.ObjectIDFromHex(counter.TripID)
+		if err != nil {
+			continue
+		}
+
+		result, err := trips.UpdateOne(ctx,
+			bson.M{"_id": objectID, "event_sequence": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"event_sequence": counter.Seq}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to migrate event sequence of trip %s: %w", counter.TripID, err)
+		}
+		migrated += int(result.ModifiedCount)
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("failed to read trip_event_sequences: %w", err)
+	}
+
+	if migrated > 0 {
+		log.Printf("✅ Migrated event sequence of %d trips", migrated)
+	}
 	return nil
 }
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ArchivedEvent es una entrada del archivo inmutable de eventos publicados (colección event_archive)
+//
+// El archivo es append-only: las entradas nunca se modifican ni se eliminan. Cada una
+// recibe un número de secuencia creciente, por lo que los read models de otros servicios
+// pueden reconstruirse leyendo el archivo en orden desde cualquier secuencia.
+// La secuencia es estrictamente creciente pero puede tener huecos (inserts fallidos). Se asigna
+// antes del insert, así que una secuencia puede ser visible antes que una menor todavía en curso:
+// los lectores solo dan por definitivo un hueco cuando pasó el timeout del Append.
+type ArchivedEvent struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Sequence   int64              `json:"sequence" bson:"sequence"`       // UNIQUE, asignada al archivar
+	EventID    string             `json:"event_id" bson:"event_id"`       // UNIQUE, el mismo event_id publicado
+	EventType  string             `json:"event_type" bson:"event_type"`   // trip.created, reservation.confirmed, etc.
+	RoutingKey string             `json:"routing_key" bson:"routing_key"` // Routing key con la que se publicó
+	Exchange   string             `json:"exchange" bson:"exchange"`       // trips.events
+	TripID     string             `json:"trip_id" bson:"trip_id"`
+	Payload    string             `json:"payload" bson:"payload"` // Body JSON exacto del mensaje publicado
+	ArchivedAt time.Time          `json:"archived_at" bson:"archived_at"`
+}
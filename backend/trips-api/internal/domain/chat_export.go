@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// ChatExportAvailableUntil retorna hasta cuándo los participantes pueden exportar el chat de un viaje
+// La ventana empieza cuando el viaje termina: al llegar (completed) o al cancelarse; false si todavía no terminó
+func ChatExportAvailableUntil(trip *Trip, window time.Duration) (time.Time, bool) {
+	switch trip.Status {
+	case TripStatusCompleted:
+		// El scheduler de estados lo completa al alcanzar estimated_arrival_datetime
+		return trip.EstimatedArrivalDatetime.Add(window), true
+	case TripStatusCancelled:
+		endedAt := trip.UpdatedAt
+		if trip.CancelledAt != nil {
+			endedAt = *trip.CancelledAt
+		}
+		return endedAt.Add(window), true
+	}
+	return time.Time{}, false
+}
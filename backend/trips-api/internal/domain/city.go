@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Orígenes posibles de la resolución de una ciudad contra el catálogo
+const (
+	CityMatchID    = "id"    // El request indicó city_id
+	CityMatchExact = "exact" // El nombre coincide con el nombre canónico o un alias
+	CityMatchFuzzy = "fuzzy" // El nombre coincide con errores de tipeo, confirmado por las coordenadas
+	CityMatchNone  = "none"  // La ciudad no está en el catálogo: se guarda el texto libre, sin city_id
+)
+
+// City es una ciudad del catálogo canónico (colección cities)
+//
+// El ID es estable y legible ("ar-caba", "ar-cordoba") y es lo que viaja en los eventos:
+// los consumidores comparan IDs en vez de nombres escritos a mano ("CABA" vs "Buenos Aires").
+type City struct {
+	ID          string      `json:"id" bson:"_id"`
+	Name        string      `json:"name" bson:"name"`                           // Nombre canónico que se guarda en el viaje
+	Province    string      `json:"province" bson:"province"`                   // Provincia/región canónica
+	Country     string      `json:"country" bson:"country"`                     // ISO alpha-2
+	Aliases     []string    `json:"aliases,omitempty" bson:"aliases,omitempty"` // Otros nombres aceptados (siglas, nombres históricos, sin tildes)
+	Coordinates Coordinates `json:"coordinates" bson:"coordinates"`             // Centro de la ciudad
+}
+
+// CitySuggestion es una ciudad candidata cuando el nombre no alcanza para elegir una sola
+type CitySuggestion struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Province string `json:"province"`
+	Country  string `json:"country"`
+}
+
+// NewCitySuggestion arma la sugerencia de una ciudad del catálogo
+func NewCitySuggestion(city City) CitySuggestion {
+	return CitySuggestion{ID: city.ID, Name: city.Name, Province: city.Province, Country: city.Country}
+}
+
+// InterCityRoute es un par origen-destino del catálogo de rutas (colección routes)
+// Se registra al crear el primer viaje entre las dos ciudades y cuenta los viajes publicados
+type InterCityRoute struct {
+	ID                string    `json:"id" bson:"_id"` // RouteID(origen, destino)
+	OriginCityID      string    `json:"origin_city_id" bson:"origin_city_id"`
+	DestinationCityID string    `json:"destination_city_id" bson:"destination_city_id"`
+	DistanceKm        float64   `json:"distance_km" bson:"distance_km"` // En línea recta entre los centros de las ciudades
+	TripCount         int64     `json:"trip_count" bson:"trip_count"`
+	FirstTripAt       time.Time `json:"first_trip_at" bson:"first_trip_at"`
+	LastTripAt        time.Time `json:"last_trip_at" bson:"last_trip_at"`
+}
+
+// RouteID devuelve el ID de la ruta entre dos ubicaciones, o "" si alguna no tiene city_id
+// La ruta tiene sentido: córdoba→rosario y rosario→córdoba son rutas distintas
+func RouteID(origin, destination Location) string {
+	if origin.CityID == "" || destination.CityID == "" {
+		return ""
+	}
+	return origin.CityID + ":" + destination.CityID
+}
+
+// AmbiguousCityError arma el error de una ciudad que coincide con varias del catálogo,
+// con las candidatas para que el cliente pida elegir una (reenviando su city_id)
+func AmbiguousCityError(field, name string, suggestions []CitySuggestion) *AppError {
+	return &AppError{
+		Code:    ErrAmbiguousCity.Code,
+		Message: fmt.Sprintf("%s city %q matches more than one city, send the city_id of one of the suggestions", field, name),
+		Details: map[string]interface{}{"field": field, "city": name, "suggestions": suggestions},
+	}
+}
+
+// UnknownCityError arma el error de un city_id que no existe en el catálogo
+func UnknownCityError(field, cityID string) *AppError {
+	return &AppError{
+		Code:    ErrUnknownCity.Code,
+		Message: fmt.Sprintf("%s city_id %q is not in the city catalog", field, cityID),
+		Details: map[string]interface{}{"field": field, "city_id": cityID},
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+// Alcance de GET /trips/mine según la fecha de salida
+const (
+	DriverTripsScopeAll      = "all"
+	DriverTripsScopeUpcoming = "upcoming" // Salida en el futuro
+	DriverTripsScopePast     = "past"     // Salida ya ocurrida
+)
+
+// IsValidDriverTripsScope indica si scope es uno de los alcances de GET /trips/mine
+func IsValidDriverTripsScope(scope string) bool {
+	switch scope {
+	case DriverTripsScopeAll, DriverTripsScopeUpcoming, DriverTripsScopePast:
+		return true
+	}
+	return false
+}
+
+// DriverTripStats son las métricas derivadas de un viaje para el panel del conductor
+type DriverTripStats struct {
+	SeatsSold       int     `json:"seats_sold" bson:"seats_sold"`             // reserved_seats (0 si el viaje se canceló)
+	RevenueEstimate float64 `json:"revenue_estimate" bson:"revenue_estimate"` // seats_sold * price_per_seat, en la moneda del viaje
+	Cancellations   int     `json:"cancellations" bson:"cancellations"`       // Reservas confirmadas que luego se cancelaron (ledger)
+	Upcoming        bool    `json:"upcoming" bson:"upcoming"`
+
+	// Conteo de reservas por estado en bookings-api (solo con include=bookings; omitido si falló)
+	Bookings *TripBookingCounts `json:"bookings,omitempty" bson:"-"`
+}
+
+// TripBookingCounts es el resumen de las reservas de un viaje según bookings-api
+type TripBookingCounts struct {
+	Counts         map[string]int `json:"counts"`          // Reservas por estado (pending, confirmed, cancelled, ...)
+	SeatsConfirmed int            `json:"seats_confirmed"` // Asientos de las reservas confirmadas
+}
+
+// DriverTrip es un viaje del conductor con sus métricas
+type DriverTrip struct {
+	Trip  `bson:",inline"`
+	Stats DriverTripStats `json:"stats" bson:"stats"`
+}
+
+// DriverTripsSummary agrega las métricas de todos los viajes del conductor (sin importar el alcance ni la página)
+type DriverTripsSummary struct {
+	TotalTrips        int                `json:"total_trips"`
+	UpcomingTrips     int                `json:"upcoming_trips"`
+	PastTrips         int                `json:"past_trips"`
+	SeatsSold         int                `json:"seats_sold"`
+	Cancellations     int                `json:"cancellations"`
+	RevenueByCurrency map[string]float64 `json:"revenue_by_currency"` // Los viajes pueden estar en distintas monedas
+}
+
+// DriverTripsResponse es la respuesta de GET /trips/mine
+type DriverTripsResponse struct {
+	Trips   []DriverTrip       `json:"trips"`
+	Total   int64              `json:"total"` // Viajes dentro del alcance
+	Page    int                `json:"page"`
+	Limit   int                `json:"limit"`
+	Scope   string             `json:"scope"`
+	Summary DriverTripsSummary `json:"summary"`
+}
@@ -19,6 +19,42 @@ var (
 	ErrNoSeatsAvailable     = &AppError{Code: "NO_SEATS_AVAILABLE", Message: "No seats available"}
 	ErrOptimisticLockFailed = &AppError{Code: "OPTIMISTIC_LOCK_FAILED", Message: "Version conflict"}
 	ErrUnauthorized         = &AppError{Code: "UNAUTHORIZED", Message: "Not authorized"}
+	ErrSessionRevoked       = &AppError{Code: "SESSION_REVOKED", Message: "Session was revoked, log in again"}
+	ErrAccountInactive      = &AppError{Code: "ACCOUNT_INACTIVE", Message: "Account is deactivated"}
+	ErrAccountSuspended     = &AppError{Code: "ACCOUNT_SUSPENDED", Message: "Account is suspended"}
+	ErrDrivingNotAllowed    = &AppError{Code: "DRIVING_NOT_ALLOWED", Message: "Account is not allowed to drive"}
+	ErrUsersAPIUnavailable  = &AppError{Code: "USERS_API_UNAVAILABLE", Message: "Could not verify the session with users-api"}
 	ErrPastDeparture        = &AppError{Code: "PAST_DEPARTURE", Message: "Departure must be in future"}
 	ErrHasReservations      = &AppError{Code: "HAS_RESERVATIONS", Message: "Cannot modify trip with reservations"}
+	ErrInvalidVacationRange = &AppError{Code: "INVALID_VACATION_RANGE", Message: "Vacation end must be after start and in the future"}
+	ErrVacationOverlap      = &AppError{Code: "VACATION_OVERLAP", Message: "Vacation overlaps an existing vacation"}
+	ErrVacationStartInPast  = &AppError{Code: "VACATION_START_IN_PAST", Message: "Vacation start must not be in the past"}
+	ErrDriverOnVacation     = &AppError{Code: "DRIVER_ON_VACATION", Message: "Departure falls within a driver vacation"}
+	ErrInvalidPickupPoints  = &AppError{Code: "INVALID_PICKUP_POINTS", Message: "Invalid pickup points"}
+	ErrInvalidBookingClose  = &AppError{Code: "INVALID_BOOKING_CLOSE", Message: "Invalid booking close"}
+	ErrInvalidDescription   = &AppError{Code: "INVALID_DESCRIPTION", Message: "Invalid description"}
+	ErrInvalidTripImport    = &AppError{Code: "INVALID_TRIP_IMPORT", Message: "Invalid trip import"}
+
+	// Retenciones de asientos (seat holds)
+	ErrSeatHoldNotFound  = &AppError{Code: "SEAT_HOLD_NOT_FOUND", Message: "Seat hold not found, released or expired"}
+	ErrSeatHoldConfirmed = &AppError{Code: "SEAT_HOLD_CONFIRMED", Message: "Seat hold already confirmed as a reservation"}
+	ErrTripNotBookable   = &AppError{Code: "TRIP_NOT_BOOKABLE", Message: "Trip does not accept reservations"}
+
+	// Viajes recurrentes
+	ErrRecurringTripNotFound   = &AppError{Code: "RECURRING_TRIP_NOT_FOUND", Message: "Recurring trip not found"}
+	ErrInvalidRecurringTrip    = &AppError{Code: "INVALID_RECURRING_TRIP", Message: "Invalid recurring trip"}
+	ErrRecurringInstanceExists = &AppError{Code: "RECURRING_INSTANCE_EXISTS", Message: "Trip already materialized for this departure"}
+
+	// Límites por mercado (ver MarketPolicy)
+	ErrCurrencyNotAllowed         = &AppError{Code: "CURRENCY_NOT_ALLOWED", Message: "Currency not allowed in this market"}
+	ErrPriceAboveMarketCap        = &AppError{Code: "PRICE_ABOVE_MARKET_CAP", Message: "Price per seat exceeds the market cap"}
+	ErrTripDistanceAboveMarketCap = &AppError{Code: "TRIP_DISTANCE_ABOVE_MARKET_CAP", Message: "Trip distance exceeds the market cap"}
+
+	// Catálogo de ciudades (ver City)
+	ErrAmbiguousCity = &AppError{Code: "AMBIGUOUS_CITY", Message: "City matches more than one catalog city"}
+	ErrUnknownCity   = &AppError{Code: "UNKNOWN_CITY", Message: "City not found in the catalog"}
+
+	// Exportación del chat (ver ChatExportAvailableUntil)
+	ErrChatExportNotAvailable = &AppError{Code: "CHAT_EXPORT_NOT_AVAILABLE", Message: "Chat export is available once the trip is completed or cancelled"}
+	ErrChatExportExpired      = &AppError{Code: "CHAT_EXPORT_EXPIRED", Message: "Chat export window has closed"}
 )
@@ -2,8 +2,10 @@ package domain
 
 // Location representa una ubicación geográfica con coordenadas
 type Location struct {
+	CityID      string      `json:"city_id,omitempty" bson:"city_id,omitempty"` // ID del catálogo de ciudades; se resuelve desde city si no se envía
 	City        string      `json:"city" bson:"city" binding:"required"`
 	Province    string      `json:"province" bson:"province" binding:"required"`
+	Country     string      `json:"country,omitempty" bson:"country,omitempty"` // ISO alpha-2, opcional (se infiere de la provincia)
 	Address     string      `json:"address" bson:"address" binding:"required"`
 	Coordinates Coordinates `json:"coordinates" bson:"coordinates" binding:"required"`
 }
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// earthRadiusKm es el radio medio de la Tierra usado para calcular distancias
+const earthRadiusKm = 6371.0
+
+// Orígenes posibles del mercado resuelto para un viaje
+const (
+	MarketSourceOriginCountry  = "origin_country"  // El origen es una ciudad del catálogo de un país con mercado configurado
+	MarketSourceOriginProvince = "origin_province" // La provincia del origen pertenece a un mercado
+	MarketSourceDefault        = "default"         // Sin país/provincia conocidos: mercado por defecto
+)
+
+// MarketPolicy representa los límites de publicación de viajes de un mercado (país)
+//
+// Se cargan al iniciar el servicio (archivo JSON o defaults) y se aplican al crear
+// y actualizar viajes. Un límite en 0 significa "sin límite".
+type MarketPolicy struct {
+	// Country es el código ISO 3166-1 alpha-2 del mercado (ej: "AR", "UY")
+	Country string `json:"country"`
+
+	// Provinces son las provincias/regiones del mercado, usadas cuando el origen no declara país
+	Provinces []string `json:"provinces"`
+
+	// Currencies son las monedas aceptadas; la primera es la moneda por defecto
+	Currencies []string `json:"currencies"`
+
+	// MaxPricePerSeat es el precio máximo por asiento (en cualquiera de las monedas del mercado)
+	MaxPricePerSeat float64 `json:"max_price_per_seat"`
+
+	// MaxTripDistanceKm es la distancia máxima en línea recta entre origen y destino
+	MaxTripDistanceKm float64 `json:"max_trip_distance_km"`
+
+	// BookingCloseMinutes es cuántos minutos antes de la salida se cierran las reservas nuevas
+	// de los viajes del mercado; el conductor puede elegir otra anticipación por viaje
+	BookingCloseMinutes int `json:"booking_close_minutes"`
+}
+
+// Validate verifica que la configuración del mercado sea consistente
+func (m MarketPolicy) Validate() error {
+	if len(m.Country) != 2 {
+		return fmt.Errorf("country must be a 2-letter ISO code, got %q", m.Country)
+	}
+	if len(m.Currencies) == 0 {
+		return fmt.Errorf("%s: at least one currency is required", m.Country)
+	}
+	for _, currency := range m.Currencies {
+		if len(currency) != 3 {
+			return fmt.Errorf("%s: currency must be a 3-letter ISO code, got %q", m.Country, currency)
+		}
+	}
+	if m.MaxPricePerSeat < 0 {
+		return fmt.Errorf("%s: max_price_per_seat must be non-negative", m.Country)
+	}
+	if m.MaxTripDistanceKm < 0 {
+		return fmt.Errorf("%s: max_trip_distance_km must be non-negative", m.Country)
+	}
+	if m.BookingCloseMinutes < 0 || m.BookingCloseMinutes > MaxBookingCloseMinutes {
+		return fmt.Errorf("%s: booking_close_minutes must be between 0 and %d", m.Country, MaxBookingCloseMinutes)
+	}
+	return nil
+}
+
+// DefaultCurrency devuelve la moneda por defecto del mercado
+func (m MarketPolicy) DefaultCurrency() string {
+	return m.Currencies[0]
+}
+
+// AllowsCurrency indica si la moneda es aceptada en el mercado (sin distinguir mayúsculas)
+func (m MarketPolicy) AllowsCurrency(currency string) bool {
+	for _, allowed := range m.Currencies {
+		if strings.EqualFold(allowed, currency) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarketChecks selecciona qué límites del mercado se validan
+// Al editar un viaje solo se validan los límites que dependen de lo que cambió: un viaje
+// publicado antes de un límite más estricto sigue siendo editable en lo demás
+type MarketChecks struct {
+	Currency bool // Moneda aceptada por el mercado
+	Price    bool // price_per_seat dentro del máximo
+	Distance bool // Distancia origen-destino dentro del máximo
+}
+
+// AllMarketChecks valida todos los límites (viajes nuevos o que cambian de mercado)
+var AllMarketChecks = MarketChecks{Currency: true, Price: true, Distance: true}
+
+// ValidateTrip aplica todos los límites del mercado a un viaje
+//
+// Validaciones:
+// - La moneda debe estar entre las aceptadas por el mercado
+// - price_per_seat no puede superar MaxPricePerSeat
+// - La distancia origen-destino no puede superar MaxTripDistanceKm
+//
+// Los errores incluyen en Details el mercado y el límite superado.
+func (m MarketPolicy) ValidateTrip(trip *Trip) error {
+	return m.ValidateTripChecks(trip, AllMarketChecks)
+}
+
+// ValidateTripChecks aplica solo los límites del mercado seleccionados en checks
+func (m MarketPolicy) ValidateTripChecks(trip *Trip, checks MarketChecks) error {
+	if checks.Currency && !m.AllowsCurrency(trip.Currency) {
+		return marketError(ErrCurrencyNotAllowed,
+			fmt.Sprintf("currency %s is not allowed in market %s (allowed: %s)", trip.Currency, m.Country, strings.Join(m.Currencies, ", ")),
+			map[string]interface{}{"market": m.Country, "currency": trip.Currency, "allowed_currencies": m.Currencies})
+	}
+
+	if checks.Price && m.MaxPricePerSeat > 0 && trip.PricePerSeat > m.MaxPricePerSeat {
+		return marketError(ErrPriceAboveMarketCap,
+			fmt.Sprintf("price_per_seat exceeds the %s market cap of %.2f %s", m.Country, m.MaxPricePerSeat, trip.Currency),
+			map[string]interface{}{"market": m.Country, "price_per_seat": trip.PricePerSeat, "max_price_per_seat": m.MaxPricePerSeat})
+	}
+
+	if checks.Distance && m.MaxTripDistanceKm > 0 {
+		distance := DistanceKm(trip.Origin.Coordinates, trip.Destination.Coordinates)
+		if distance > m.MaxTripDistanceKm {
+			return marketError(ErrTripDistanceAboveMarketCap,
+				fmt.Sprintf("trip distance of %.0f km exceeds the %s market cap of %.0f km", distance, m.Country, m.MaxTripDistanceKm),
+				map[string]interface{}{"market": m.Country, "distance_km": math.Round(distance), "max_trip_distance_km": m.MaxTripDistanceKm})
+		}
+	}
+
+	return nil
+}
+
+// DistanceKm calcula la distancia en línea recta (haversine) entre dos coordenadas
+func DistanceKm(a, b Coordinates) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := (b.Lat - a.Lat) * math.Pi / 180
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+func marketError(base *AppError, message string, details map[string]interface{}) *AppError {
+	return &AppError{Code: base.Code, Message: message, Details: details}
+}
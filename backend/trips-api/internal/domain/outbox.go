@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de un mensaje del outbox
+const (
+	OutboxStatusPending = "pending" // Esperando ser publicado (o reintentado) por el relay
+	OutboxStatusSent    = "sent"    // Publicado en RabbitMQ
+	OutboxStatusFailed  = "failed"  // Agotó los reintentos: requiere intervención manual
+)
+
+// OutboxMessage es un evento pendiente de publicar (colección outbox)
+//
+// Se escribe en el mismo flujo que el cambio que lo origina (por ejemplo la creación del viaje)
+// y un relay lo publica en RabbitMQ con reintentos. El event_id queda fijo en el payload,
+// así un reintento publica exactamente el mismo evento y los consumers lo deduplican.
+type OutboxMessage struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID       string             `json:"event_id" bson:"event_id"`       // UNIQUE
+	EventType     string             `json:"event_type" bson:"event_type"`   // trip.created
+	RoutingKey    string             `json:"routing_key" bson:"routing_key"` // Routing key con la que se publica
+	TripID        string             `json:"trip_id" bson:"trip_id"`
+	Payload       string             `json:"payload" bson:"payload"` // Body JSON exacto a publicar
+	Status        string             `json:"status" bson:"status"`   // pending, sent, failed
+	Attempts      int                `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" bson:"next_attempt_at"` // También funciona como lease del relay
+	LastError     string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	SentAt        *time.Time         `json:"sent_at,omitempty" bson:"sent_at,omitempty"`
+
+	// Contexto de la traza de la request que escribió el mensaje (traceparent), para que el relay lo continúe
+	TraceContext map[string]string `json:"trace_context,omitempty" bson:"trace_context,omitempty"`
+}
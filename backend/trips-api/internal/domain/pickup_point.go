@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Límites de los puntos de encuentro por viaje
+const (
+	MaxPickupPoints       = 5   // Cantidad máxima de puntos de encuentro por viaje
+	MaxPickupLabelLength  = 80  // Largo máximo del nombre del punto
+	MaxPickupInstructions = 500 // Largo máximo de las instrucciones
+)
+
+// PickupPoint representa un punto de encuentro definido por el conductor
+//
+// Es independiente del origen: el pasajero elige uno al reservar y bookings-api
+// guarda su ID con la reserva. TimeOffsetMinutes indica cuántos minutos después
+// de la salida pasa el conductor por el punto.
+type PickupPoint struct {
+	ID                string      `json:"id" bson:"id"`
+	Label             string      `json:"label" bson:"label"`
+	Instructions      string      `json:"instructions,omitempty" bson:"instructions,omitempty"`
+	Coordinates       Coordinates `json:"coordinates" bson:"coordinates"`
+	TimeOffsetMinutes int         `json:"time_offset_minutes" bson:"time_offset_minutes"`
+}
+
+// PreparePickupPoints valida los puntos de encuentro de un viaje y asigna IDs a los nuevos
+//
+// Validaciones:
+// - Como máximo MaxPickupPoints puntos
+// - Label obligatorio, único (sin distinguir mayúsculas) y de hasta MaxPickupLabelLength caracteres
+// - Instrucciones de hasta MaxPickupInstructions caracteres
+// - Coordenadas dentro de rango
+// - TimeOffsetMinutes entre 0 y la duración del viaje
+//
+// Los puntos que ya tienen un ID de existing lo conservan; el resto recibe un ID nuevo.
+func PreparePickupPoints(points []PickupPoint, existing []PickupPoint, departure, arrival time.Time) ([]PickupPoint, error) {
+	if len(points) > MaxPickupPoints {
+		return nil, invalidPickupPoints(fmt.Sprintf("a trip can have at most %d pickup points", MaxPickupPoints))
+	}
+
+	existingIDs := make(map[string]bool, len(existing))
+	for _, point := range existing {
+		existingIDs[point.ID] = true
+	}
+
+	maxOffset := int(arrival.Sub(departure).Minutes())
+	labels := make(map[string]bool, len(points))
+	prepared := make([]PickupPoint, 0, len(points))
+
+	for i, point := range points {
+		point.Label = strings.TrimSpace(point.Label)
+		point.Instructions = strings.TrimSpace(point.Instructions)
+
+		if point.Label == "" {
+			return nil, invalidPickupPoints(fmt.Sprintf("pickup point %d: label is required", i+1))
+		}
+		if len(point.Label) > MaxPickupLabelLength {
+			return nil, invalidPickupPoints(fmt.Sprintf("pickup point %d: label exceeds %d characters", i+1, MaxPickupLabelLength))
+		}
+		key := strings.ToLower(point.Label)
+		if labels[key] {
+			return nil, invalidPickupPoints(fmt.Sprintf("pickup point %d: duplicated label %q", i+1, point.Label))
+		}
+		labels[key] = true
+
+		if len(point.Instructions) > MaxPickupInstructions {
+			return nil, invalidPickupPoints(fmt.Sprintf("pickup point %d: instructions exceed %d characters", i+1, MaxPickupInstructions))
+		}
+		if point.Coordinates.Lat < -90 || point.Coordinates.Lat > 90 ||
+			point.Coordinates.Lng < -180 || point.Coordinates.Lng > 180 {
+			return nil, invalidPickupPoints(fmt.Sprintf("pickup point %d: coordinates out of range", i+1))
+		}
+		if point.TimeOffsetMinutes < 0 || point.TimeOffsetMinutes > maxOffset {
+			return nil, invalidPickupPoints(fmt.Sprintf("pickup point %d: time_offset_minutes must be between 0 and %d", i+1, maxOffset))
+		}
+
+		if !existingIDs[point.ID] {
+			point.ID = primitive.NewObjectID().Hex()
+		}
+		prepared = append(prepared, point)
+	}
+
+	return prepared, nil
+}
+
+// FindPickupPoint busca un punto de encuentro del viaje por ID
+// Retorna nil si el viaje no tiene un punto con ese ID
+func (t *Trip) FindPickupPoint(id string) *PickupPoint {
+	for i := range t.PickupPoints {
+		if t.PickupPoints[i].ID == id {
+			return &t.PickupPoints[i]
+		}
+	}
+	return nil
+}
+
+func invalidPickupPoints(message string) *AppError {
+	return &AppError{Code: ErrInvalidPickupPoints.Code, Message: message}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Roles de quien cambió el precio de un viaje
+const (
+	PriceChangedByDriver = "driver"
+	PriceChangedByAdmin  = "admin"
+)
+
+// TripPriceChange es un cambio del precio por asiento de un viaje (colección trip_price_history)
+// Solo se insertan documentos: el historial no se edita
+type TripPriceChange struct {
+	ID                   primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TripID               string             `json:"trip_id" bson:"trip_id"`
+	PricePerSeat         float64            `json:"price_per_seat" bson:"price_per_seat"`
+	PreviousPricePerSeat float64            `json:"previous_price_per_seat" bson:"previous_price_per_seat"`
+	Currency             string             `json:"currency" bson:"currency"`
+	ChangedBy            int64              `json:"-" bson:"changed_by"`                    // ID del usuario que cambió el precio (no se expone: la respuesta es pública)
+	ChangedByRole        string             `json:"changed_by_role" bson:"changed_by_role"` // driver o admin
+	ChangedAt            time.Time          `json:"changed_at" bson:"changed_at"`
+}
+
+// TripPriceHistory es la respuesta de GET /trips/:id/price-history
+// Changes está ordenado del cambio más nuevo al más viejo
+type TripPriceHistory struct {
+	TripID              string            `json:"trip_id"`
+	CurrentPricePerSeat float64           `json:"current_price_per_seat"`
+	Currency            string            `json:"currency"`
+	Changes             []TripPriceChange `json:"changes"`
+}
@@ -0,0 +1,246 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados posibles de un viaje recurrente
+const (
+	RecurringTripStatusActive  = "active"  // El scheduler materializa instancias
+	RecurringTripStatusPaused  = "paused"  // No se materializan instancias nuevas
+	RecurringTripStatusDeleted = "deleted" // Eliminado por el conductor (soft delete)
+)
+
+// DefaultRecurringTimezone es la zona horaria usada cuando el conductor no informa una
+const DefaultRecurringTimezone = "America/Argentina/Buenos_Aires"
+
+// Formatos de fecha y hora de los viajes recurrentes
+const (
+	RecurringDateLayout = "2006-01-02" // start_date / end_date
+	RecurringTimeLayout = "15:04"      // departure_time
+)
+
+// RecurringTrip representa un viaje que el conductor repite todas las semanas
+//
+// Es una plantilla: no se puede reservar. El scheduler crea un Trip común por cada
+// ocurrencia (día de la semana + hora de salida en la zona horaria del conductor)
+// con algunos días de anticipación, y publica trip.created por cada uno.
+// MaterializedUntil marca hasta dónde ya se generaron instancias, así un viaje
+// eliminado individualmente por el conductor no se vuelve a crear.
+type RecurringTrip struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	DriverID int64              `json:"driver_id" bson:"driver_id"`
+
+	Origin       Location      `json:"origin" bson:"origin"`
+	Destination  Location      `json:"destination" bson:"destination"`
+	PickupPoints []PickupPoint `json:"pickup_points" bson:"pickup_points"`
+
+	Weekdays        []int      `json:"weekdays" bson:"weekdays"`             // 0 = domingo ... 6 = sábado
+	DepartureTime   string     `json:"departure_time" bson:"departure_time"` // HH:MM en Timezone
+	DurationMinutes int        `json:"duration_minutes" bson:"duration_minutes"`
+	Timezone        string     `json:"timezone" bson:"timezone"` // Zona horaria IANA
+	StartDate       time.Time  `json:"start_date" bson:"start_date"`
+	EndDate         *time.Time `json:"end_date,omitempty" bson:"end_date,omitempty"` // nil = sin fin
+
+	PricePerSeat float64     `json:"price_per_seat" bson:"price_per_seat"`
+	Currency     string      `json:"currency" bson:"currency"`
+	TotalSeats   int         `json:"total_seats" bson:"total_seats"`
+	Car          Car         `json:"car" bson:"car"`
+	Preferences  Preferences `json:"preferences" bson:"preferences"`
+	Description  string      `json:"description" bson:"description"`
+
+	// Formato y texto plano de la descripción (ver Trip); se copian a cada instancia
+	DescriptionFormat string `json:"description_format,omitempty" bson:"description_format,omitempty"`
+	DescriptionText   string `json:"description_text,omitempty" bson:"description_text,omitempty"`
+
+	BookingCloseMinutes *int `json:"booking_close_minutes,omitempty" bson:"booking_close_minutes,omitempty"` // Se copia a cada instancia (nil = la del mercado)
+
+	Status            string     `json:"status" bson:"status"` // active, paused, deleted
+	MaterializedUntil *time.Time `json:"materialized_until,omitempty" bson:"materialized_until,omitempty"`
+	DeletedAt         *time.Time `json:"-" bson:"deleted_at,omitempty"` // Momento del soft delete (nil = vigente)
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// CreateRecurringTripRequest representa la solicitud para crear un viaje recurrente
+type CreateRecurringTripRequest struct {
+	Origin            Location      `json:"origin" binding:"required"`
+	Destination       Location      `json:"destination" binding:"required"`
+	Weekdays          []int         `json:"weekdays" binding:"required,min=1,max=7,dive,min=0,max=6"`
+	DepartureTime     string        `json:"departure_time" binding:"required"` // HH:MM
+	DurationMinutes   int           `json:"duration_minutes" binding:"required,min=1"`
+	Timezone          string        `json:"timezone"`   // Opcional: por defecto DefaultRecurringTimezone
+	StartDate         string        `json:"start_date"` // YYYY-MM-DD, opcional: por defecto hoy
+	EndDate           *string       `json:"end_date"`   // YYYY-MM-DD, opcional
+	PricePerSeat      float64       `json:"price_per_seat" binding:"required,min=0"`
+	Currency          string        `json:"currency"`
+	TotalSeats        int           `json:"total_seats" binding:"required,min=1,max=8"`
+	Car               Car           `json:"car" binding:"required"`
+	Preferences       Preferences   `json:"preferences"`
+	Description       string        `json:"description"`
+	DescriptionFormat string        `json:"description_format"` // Opcional: plain (por defecto), html o markdown
+	PickupPoints      []PickupPoint `json:"pickup_points"`
+
+	BookingCloseMinutes *int `json:"booking_close_minutes"` // Opcional: por defecto la del mercado
+}
+
+// UpdateRecurringTripRequest representa la solicitud para actualizar un viaje recurrente
+// Los cambios aplican a las instancias que se materialicen a partir de ahora;
+// los viajes ya creados se editan individualmente con PUT /trips/:id
+type UpdateRecurringTripRequest struct {
+	Origin            *Location      `json:"origin"`
+	Destination       *Location      `json:"destination"`
+	Weekdays          *[]int         `json:"weekdays"`
+	DepartureTime     *string        `json:"departure_time"`
+	DurationMinutes   *int           `json:"duration_minutes"`
+	Timezone          *string        `json:"timezone"`
+	EndDate           *string        `json:"end_date"` // "" elimina la fecha de fin
+	PricePerSeat      *float64       `json:"price_per_seat"`
+	Currency          *string        `json:"currency"`
+	TotalSeats        *int           `json:"total_seats"`
+	Car               *Car           `json:"car"`
+	Preferences       *Preferences   `json:"preferences"`
+	Description       *string        `json:"description"`
+	DescriptionFormat *string        `json:"description_format"`
+	PickupPoints      *[]PickupPoint `json:"pickup_points"`
+	Status            *string        `json:"status"` // active, paused
+
+	BookingCloseMinutes *int `json:"booking_close_minutes"`
+}
+
+// IsDeleted indica si el conductor eliminó la serie (soft delete)
+func (r *RecurringTrip) IsDeleted() bool {
+	return r.DeletedAt != nil || r.Status == RecurringTripStatusDeleted
+}
+
+// Validate verifica la agenda del viaje recurrente (días, hora, duración, zona horaria y rango de fechas)
+func (r *RecurringTrip) Validate() error {
+	if len(r.Weekdays) == 0 {
+		return invalidRecurringTrip("at least one weekday is required")
+	}
+	seen := make(map[int]bool, len(r.Weekdays))
+	for _, day := range r.Weekdays {
+		if day < 0 || day > 6 {
+			return invalidRecurringTrip("weekdays must be between 0 (sunday) and 6 (saturday)")
+		}
+		if seen[day] {
+			return invalidRecurringTrip(fmt.Sprintf("duplicated weekday %d", day))
+		}
+		seen[day] = true
+	}
+
+	if _, err := time.Parse(RecurringTimeLayout, r.DepartureTime); err != nil {
+		return invalidRecurringTrip("departure_time must have the HH:MM format")
+	}
+	if r.DurationMinutes < 1 {
+		return invalidRecurringTrip("duration_minutes must be positive")
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		return invalidRecurringTrip(fmt.Sprintf("unknown timezone %q", r.Timezone))
+	}
+	if r.EndDate != nil && r.EndDate.Before(r.StartDate) {
+		return invalidRecurringTrip("end_date must not be before start_date")
+	}
+	if r.TotalSeats < 1 || r.TotalSeats > 8 {
+		return invalidRecurringTrip("total_seats must be between 1 and 8")
+	}
+	return nil
+}
+
+// Occurrences devuelve las salidas del viaje recurrente dentro de (after, until]
+// Las salidas se calculan en la zona horaria del conductor (respeta cambios de horario)
+// y se limitan a [start_date, end_date] (fechas inclusivas)
+func (r *RecurringTrip) Occurrences(after, until time.Time) ([]time.Time, error) {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", r.Timezone, err)
+	}
+	clock, err := time.Parse(RecurringTimeLayout, r.DepartureTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid departure_time %q: %w", r.DepartureTime, err)
+	}
+
+	weekdays := make(map[time.Weekday]bool, len(r.Weekdays))
+	for _, day := range r.Weekdays {
+		weekdays[time.Weekday(day)] = true
+	}
+
+	// Recorrer día por día en la zona horaria del conductor
+	first := after.In(loc)
+	if start := r.StartDate.In(loc); start.After(first) {
+		first = start
+	}
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	last := until.In(loc)
+
+	var occurrences []time.Time
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if r.EndDate != nil && day.After(*r.EndDate) {
+			break
+		}
+		if !weekdays[day.Weekday()] {
+			continue
+		}
+		departure := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if departure.Hour() != clock.Hour() || departure.Minute() != clock.Minute() {
+			// La hora no existe ese día (adelanto de horario): Go la resuelve antes del
+			// salto, así que se corre hacia adelante lo que dura el salto (02:30 -> 03:30)
+			_, before := departure.Zone()
+			_, after := departure.Add(24 * time.Hour).Zone()
+			departure = departure.Add(time.Duration(after-before) * time.Second)
+		}
+		if !departure.After(after) || departure.After(until) || departure.Before(r.StartDate) {
+			continue
+		}
+		occurrences = append(occurrences, departure.UTC())
+	}
+
+	return occurrences, nil
+}
+
+// NewInstance construye el Trip de una ocurrencia con los valores iniciales de un viaje publicado
+func (r *RecurringTrip) NewInstance(departure time.Time) *Trip {
+	pickupPoints := make([]PickupPoint, len(r.PickupPoints))
+	copy(pickupPoints, r.PickupPoints)
+
+	return &Trip{
+		DriverID:                 r.DriverID,
+		RecurringTripID:          r.ID.Hex(),
+		Origin:                   r.Origin,
+		Destination:              r.Destination,
+		RouteID:                  RouteID(r.Origin, r.Destination),
+		PickupPoints:             pickupPoints,
+		DepartureDatetime:        departure,
+		EstimatedArrivalDatetime: departure.Add(time.Duration(r.DurationMinutes) * time.Minute),
+		PricePerSeat:             r.PricePerSeat,
+		Currency:                 r.Currency,
+		TotalSeats:               r.TotalSeats,
+		AvailableSeats:           r.TotalSeats,
+		ReservedSeats:            0,
+		AvailabilityVersion:      1,
+		Car:                      r.Car,
+		Preferences:              r.Preferences,
+		Description:              r.Description,
+		DescriptionFormat:        r.DescriptionFormat,
+		DescriptionText:          r.DescriptionText,
+		BookingCloseMinutes:      r.BookingCloseMinutes,
+		Status:                   TripStatusPublished,
+	}
+}
+
+// ParseRecurringDate parsea una fecha YYYY-MM-DD como el inicio del día en la zona horaria indicada
+func ParseRecurringDate(value string, loc *time.Location) (time.Time, error) {
+	date, err := time.ParseInLocation(RecurringDateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, invalidRecurringTrip(fmt.Sprintf("invalid date %q, expected YYYY-MM-DD", value))
+	}
+	return date, nil
+}
+
+func invalidRecurringTrip(message string) *AppError {
+	return &AppError{Code: ErrInvalidRecurringTrip.Code, Message: message}
+}
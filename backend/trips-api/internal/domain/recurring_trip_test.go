@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWeeklyTrip(t *testing.T, timezone, departure string, weekdays ...int) *RecurringTrip {
+	t.Helper()
+	loc, err := time.LoadLocation(timezone)
+	require.NoError(t, err)
+
+	return &RecurringTrip{
+		Weekdays:        weekdays,
+		DepartureTime:   departure,
+		DurationMinutes: 60,
+		Timezone:        timezone,
+		StartDate:       time.Date(2026, 1, 1, 0, 0, 0, 0, loc),
+		TotalSeats:      3,
+		Status:          RecurringTripStatusActive,
+	}
+}
+
+func TestOccurrences_KeepsLocalTimeAcrossDSTStart(t *testing.T) {
+	// En 2026 Nueva York pasa a horario de verano el domingo 8 de marzo
+	recurring := newWeeklyTrip(t, "America/New_York", "08:00", int(time.Sunday))
+
+	occurrences, err := recurring.Occurrences(
+		time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC),  // 08:00 EST (UTC-5)
+		time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC),  // 08:00 EDT (UTC-4)
+		time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC), // 08:00 EDT
+	}, occurrences)
+}
+
+func TestOccurrences_KeepsLocalTimeAcrossDSTEnd(t *testing.T) {
+	// En 2026 Nueva York vuelve al horario estándar el domingo 1 de noviembre
+	recurring := newWeeklyTrip(t, "America/New_York", "18:30", int(time.Saturday), int(time.Sunday))
+
+	occurrences, err := recurring.Occurrences(
+		time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 11, 2, 12, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 10, 31, 22, 30, 0, 0, time.UTC), // 18:30 EDT (UTC-4)
+		time.Date(2026, 11, 1, 23, 30, 0, 0, time.UTC),  // 18:30 EST (UTC-5)
+	}, occurrences)
+}
+
+func TestOccurrences_SkippedLocalTimeStillDeparts(t *testing.T) {
+	// 02:30 no existe el 8 de marzo en Nueva York: la salida se corre a 03:30 EDT
+	recurring := newWeeklyTrip(t, "America/New_York", "02:30", int(time.Sunday))
+
+	occurrences, err := recurring.Occurrences(
+		time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+
+	require.Len(t, occurrences, 1)
+	assert.Equal(t, time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC), occurrences[0])
+}
+
+func TestOccurrences_EndDateIsInclusive(t *testing.T) {
+	recurring := newWeeklyTrip(t, DefaultRecurringTimezone, "18:00", 0, 1, 2, 3, 4, 5, 6)
+	loc, _ := time.LoadLocation(DefaultRecurringTimezone)
+	endDate, err := ParseRecurringDate("2026-05-12", loc)
+	require.NoError(t, err)
+	recurring.EndDate = &endDate
+
+	occurrences, err := recurring.Occurrences(
+		time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+
+	// 18:00 en Buenos Aires (UTC-3) = 21:00 UTC; el 12 de mayo es el último día
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 5, 10, 21, 0, 0, 0, time.UTC),
+		time.Date(2026, 5, 11, 21, 0, 0, 0, time.UTC),
+		time.Date(2026, 5, 12, 21, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestOccurrences_NothingAfterEndDate(t *testing.T) {
+	recurring := newWeeklyTrip(t, DefaultRecurringTimezone, "07:00", int(time.Monday))
+	loc, _ := time.LoadLocation(DefaultRecurringTimezone)
+	endDate, _ := ParseRecurringDate("2026-04-01", loc)
+	recurring.EndDate = &endDate
+
+	occurrences, err := recurring.Occurrences(
+		time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	assert.Empty(t, occurrences)
+}
+
+func TestOccurrences_RespectsStartDate(t *testing.T) {
+	recurring := newWeeklyTrip(t, DefaultRecurringTimezone, "09:00", int(time.Wednesday))
+	loc, _ := time.LoadLocation(DefaultRecurringTimezone)
+	recurring.StartDate = time.Date(2026, 7, 8, 0, 0, 0, 0, loc)
+
+	occurrences, err := recurring.Occurrences(
+		time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 7, 16, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2026, 7, 8, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestValidate_RejectsEndDateBeforeStartDate(t *testing.T) {
+	recurring := newWeeklyTrip(t, DefaultRecurringTimezone, "09:00", int(time.Monday))
+	endDate := recurring.StartDate.AddDate(0, 0, -1)
+	recurring.EndDate = &endDate
+
+	assert.Error(t, recurring.Validate())
+}
+
+func TestIsDeleted(t *testing.T) {
+	recurring := newWeeklyTrip(t, DefaultRecurringTimezone, "09:00", int(time.Monday))
+	assert.False(t, recurring.IsDeleted())
+
+	deletedAt := time.Now()
+	recurring.DeletedAt = &deletedAt
+	assert.True(t, recurring.IsDeleted(), "deleted_at set")
+
+	recurring.DeletedAt = nil
+	recurring.Status = RecurringTripStatusDeleted
+	assert.True(t, recurring.IsDeleted(), "legacy series deleted before deleted_at existed")
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tipos de respuesta del conductor que se miden
+// Las reservas se confirman sin aprobación del conductor: solo se mide el chat
+const (
+	ResponseKindChat = "chat" // Respuesta a mensajes de pasajeros en el chat del viaje
+)
+
+// ResponseTimeWindow es la cantidad de respuestas recientes sobre las que se calcula el promedio móvil
+const ResponseTimeWindow = 20
+
+// MaxTrackedResponseDelay es la demora máxima que se registra como respuesta
+// Un mensaje del conductor días después no responde al pasajero, retoma la conversación
+const MaxTrackedResponseDelay = 7 * 24 * time.Hour
+
+// ResponseTimeStats es el promedio móvil de un tipo de respuesta del conductor
+type ResponseTimeStats struct {
+	Samples        []float64  `json:"-" bson:"samples"`                       // Últimos ResponseTimeWindow tiempos de respuesta (segundos)
+	AvgSeconds     float64    `json:"avg_seconds" bson:"avg_seconds"`         // Promedio de Samples
+	SampleCount    int        `json:"sample_count" bson:"sample_count"`       // Respuestas incluidas en el promedio
+	TotalResponses int64      `json:"total_responses" bson:"total_responses"` // Respuestas registradas desde siempre
+	LastResponseAt *time.Time `json:"last_response_at,omitempty" bson:"last_response_at,omitempty"`
+}
+
+// DriverResponseStats son los tiempos de respuesta de un conductor (un documento por conductor)
+//
+// Se actualiza con cada respuesta en el chat: el primer mensaje del conductor después de mensajes
+// de pasajeros sin responder
+type DriverResponseStats struct {
+	ID        primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	DriverID  int64              `json:"driver_id" bson:"driver_id"`
+	Chat      ResponseTimeStats  `json:"chat" bson:"chat"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
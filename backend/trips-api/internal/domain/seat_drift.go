@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// Invariantes de asientos que valida el chequeo de drift
+const (
+	SeatDriftAvailableMismatch = "available_mismatch" // available_seats + reserved_seats + held_seats != total_seats
+	SeatDriftLedgerMismatch    = "ledger_mismatch"    // reserved_seats != suma de las reservas confirmadas del ledger
+	SeatDriftHoldMismatch      = "hold_mismatch"      // held_seats != suma de las retenciones activas (seat_holds)
+)
+
+// SeatDrift describe un viaje cuyos contadores de asientos no cumplen las invariantes
+type SeatDrift struct {
+	TripID         string    `json:"trip_id"`
+	DriverID       int64     `json:"driver_id"`
+	Status         string    `json:"status"`
+	TotalSeats     int       `json:"total_seats"`
+	ReservedSeats  int       `json:"reserved_seats"`
+	AvailableSeats int       `json:"available_seats"`
+	HeldSeats      int       `json:"held_seats"`
+	LedgerSeats    *int      `json:"ledger_reserved_seats,omitempty"` // nil si el viaje es anterior al ledger
+	Reasons        []string  `json:"reasons"`
+	Repaired       bool      `json:"repaired"`
+	DetectedAt     time.Time `json:"detected_at"`
+}
+
+// SeatDriftRunResult resume una corrida del chequeo de drift de asientos
+type SeatDriftRunResult struct {
+	TripsChecked   int64  `json:"trips_checked"`
+	DriftsDetected int64  `json:"drifts_detected"`
+	Repaired       int64  `json:"repaired"`
+	RepairFailures int64  `json:"repair_failures"` // Conflicto de versión o contadores imposibles de reparar
+	Duration       string `json:"duration"`
+}
@@ -0,0 +1,101 @@
+package domain
+
+import "time"
+
+// SeatHold es una retención temporal de asientos tomada por bookings-api antes de crear una reserva
+// Mientras está activa sus asientos salen de available_seats y se cuentan en held_seats del viaje.
+// Termina de una de tres formas: se confirma (reservation.created o POST .../confirm, los asientos pasan
+// a reserved_seats), se libera (DELETE) o vence (el expirador la libera al pasar ExpiresAt)
+type SeatHold struct {
+	HoldID        string    `json:"hold_id" bson:"hold_id"`
+	ReservationID string    `json:"reservation_id" bson:"reservation_id"` // UUID de la reserva en bookings-api
+	PassengerID   int64     `json:"passenger_id" bson:"passenger_id"`
+	Seats         int       `json:"seats" bson:"seats"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// CreateSeatHoldRequest representa el body de POST /trips/:id/holds (lo envía bookings-api)
+type CreateSeatHoldRequest struct {
+	ReservationID string `json:"reservation_id" binding:"required"`
+	PassengerID   int64  `json:"passenger_id" binding:"required,min=1"`
+	Seats         int    `json:"seats" binding:"required,min=1,max=8"`
+	TTLSeconds    int    `json:"ttl_seconds" binding:"min=0"` // Opcional: 0 usa la duración por defecto; se limita al máximo configurado
+}
+
+// SeatHoldResponse es la retención que devuelven los endpoints de retenciones
+type SeatHoldResponse struct {
+	HoldID         string    `json:"hold_id"`
+	TripID         string    `json:"trip_id"`
+	ReservationID  string    `json:"reservation_id"`
+	Seats          int       `json:"seats"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	Status         string    `json:"status"`                    // held o confirmed
+	AvailableSeats *int      `json:"available_seats,omitempty"` // Asientos libres del viaje después de la operación
+}
+
+// Estados de una retención en las respuestas
+const (
+	SeatHoldStatusHeld      = "held"
+	SeatHoldStatusConfirmed = "confirmed"
+)
+
+// NewSeatHoldResponse arma la respuesta de una retención activa de tripID
+func NewSeatHoldResponse(tripID string, hold *SeatHold, status string) *SeatHoldResponse {
+	return &SeatHoldResponse{
+		HoldID:        hold.HoldID,
+		TripID:        tripID,
+		ReservationID: hold.ReservationID,
+		Seats:         hold.Seats,
+		ExpiresAt:     hold.ExpiresAt,
+		Status:        status,
+	}
+}
+
+// FindSeatHold busca una retención activa del viaje por su ID (nil si no existe, ya se liberó o se confirmó)
+func (t *Trip) FindSeatHold(holdID string) *SeatHold {
+	for i := range t.SeatHolds {
+		if t.SeatHolds[i].HoldID == holdID {
+			return &t.SeatHolds[i]
+		}
+	}
+	return nil
+}
+
+// FindSeatHoldByReservation busca la retención activa de una reserva (nil si no tiene)
+func (t *Trip) FindSeatHoldByReservation(reservationID string) *SeatHold {
+	for i := range t.SeatHolds {
+		if t.SeatHolds[i].ReservationID == reservationID {
+			return &t.SeatHolds[i]
+		}
+	}
+	return nil
+}
+
+// HasConfirmedSeatHold indica si la retención holdID se confirmó en este viaje
+// Distingue una retención confirmada de una liberada o vencida, que también desaparecen de SeatHolds
+func (t *Trip) HasConfirmedSeatHold(holdID string) bool {
+	for _, id := range t.ConfirmedSeatHoldIDs {
+		if id == holdID {
+			return true
+		}
+	}
+	return false
+}
+
+// SeatHoldsTotal suma los asientos de las retenciones activas (debe coincidir con held_seats)
+func (t *Trip) SeatHoldsTotal() int {
+	total := 0
+	for _, hold := range t.SeatHolds {
+		total += hold.Seats
+	}
+	return total
+}
+
+// SeatHoldExpiryRunResult resume una corrida del expirador de retenciones
+type SeatHoldExpiryRunResult struct {
+	TripsChecked    int64  `json:"trips_checked"`
+	HoldsExpired    int64  `json:"holds_expired"`
+	ReleaseFailures int64  `json:"release_failures"`
+	Duration        string `json:"duration"`
+}
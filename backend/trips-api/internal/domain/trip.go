@@ -10,24 +10,45 @@ import (
 type Trip struct {
 	ID                       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	DriverID                 int64              `json:"driver_id" bson:"driver_id"`
+	RecurringTripID          string             `json:"recurring_trip_id,omitempty" bson:"recurring_trip_id,omitempty"` // Serie que generó el viaje (vacío si se creó a mano)
 
 	Origin                   Location `json:"origin" bson:"origin"`
 	Destination              Location `json:"destination" bson:"destination"`
+	PickupPoints             []PickupPoint `json:"pickup_points" bson:"pickup_points"` // Puntos de encuentro elegibles al reservar
+	RouteID                  string   `json:"route_id,omitempty" bson:"route_id"` // "origen:destino" en IDs del catálogo (vacío si alguna ciudad no está en el catálogo)
 
 	DepartureDatetime        time.Time `json:"departure_datetime" bson:"departure_datetime"`
 	EstimatedArrivalDatetime time.Time `json:"estimated_arrival_datetime" bson:"estimated_arrival_datetime"`
+	BookingCloseMinutes      *int       `json:"booking_close_minutes,omitempty" bson:"booking_close_minutes,omitempty"` // Anticipación del cierre elegida por el conductor (nil = la del mercado)
+	BookingClosesAt          *time.Time `json:"booking_closes_at,omitempty" bson:"booking_closes_at"`                    // Desde cuándo no acepta reservas nuevas (nil = hasta la salida)
 
 	PricePerSeat             float64     `json:"price_per_seat" bson:"price_per_seat"`
+	PreviousPricePerSeat     *float64    `json:"previous_price_per_seat,omitempty" bson:"previous_price_per_seat,omitempty"` // Precio antes del último cambio (nil si nunca cambió)
+	PriceChangedAt           *time.Time  `json:"price_changed_at,omitempty" bson:"price_changed_at,omitempty"`
+	Currency                 string      `json:"currency" bson:"currency"` // Moneda del precio (aceptada por el mercado)
+	Market                   string      `json:"market" bson:"market"`     // Mercado (país) resuelto desde el origen
 	TotalSeats               int         `json:"total_seats" bson:"total_seats"`
 	ReservedSeats            int         `json:"reserved_seats" bson:"reserved_seats"`
 	AvailableSeats           int         `json:"available_seats" bson:"available_seats"`
+	HeldSeats                int         `json:"held_seats" bson:"held_seats"` // Retenidos por seat holds activos (available + reserved + held = total)
+	SeatHolds                []SeatHold  `json:"-" bson:"seat_holds,omitempty"` // Retenciones activas; no se exponen (tienen datos de pasajeros)
+	ConfirmedSeatHoldIDs     []string    `json:"-" bson:"confirmed_seat_hold_ids,omitempty"` // Retenciones confirmadas (se registran en la misma escritura que mueve los asientos)
 	AvailabilityVersion      int         `json:"availability_version" bson:"availability_version"` // For optimistic locking
 
+	// Secuencia de eventos: se incrementa en la misma escritura que cambia el viaje y la llevan los eventos trip.*
+	// (ver repository.tripRepository); 1 al crear, ausente en los viajes que no cambiaron desde antes de este campo
+	EventSequence int64 `json:"-" bson:"event_sequence,omitempty"`
+
 	Car         Car         `json:"car" bson:"car"`
 	Preferences Preferences `json:"preferences" bson:"preferences"`
 
-	Status      string `json:"status" bson:"status"` // draft, published, full, in_progress, completed, cancelled
-	Description string `json:"description" bson:"description"`
+	Status      string `json:"status" bson:"status"` // draft, published, paused, closed, full, in_progress, completed, cancelled
+	Description string `json:"description" bson:"description"` // Sanitizada según DescriptionFormat
+
+	// Formato de la descripción y su texto plano (eventos y search_text de search-api)
+	// Vacíos en los viajes creados antes de la sanitización
+	DescriptionFormat string `json:"description_format,omitempty" bson:"description_format,omitempty"`
+	DescriptionText   string `json:"description_text,omitempty" bson:"description_text,omitempty"`
 
 	CancelledAt        *time.Time `json:"cancelled_at,omitempty" bson:"cancelled_at,omitempty"`
 	CancelledBy        *int64     `json:"cancelled_by,omitempty" bson:"cancelled_by,omitempty"`
@@ -44,10 +65,14 @@ type CreateTripRequest struct {
 	DepartureDatetime        string      `json:"departure_datetime" binding:"required"`        // RFC3339 format
 	EstimatedArrivalDatetime string      `json:"estimated_arrival_datetime" binding:"required"` // RFC3339 format
 	PricePerSeat             float64     `json:"price_per_seat" binding:"required,min=0"`
+	Currency                 string      `json:"currency"` // Opcional: por defecto la moneda principal del mercado
 	TotalSeats               int         `json:"total_seats" binding:"required,min=1,max=8"`
 	Car                      Car         `json:"car" binding:"required"`
 	Preferences              Preferences `json:"preferences"`
 	Description              string      `json:"description"`
+	DescriptionFormat        string      `json:"description_format"` // Opcional: plain (por defecto), html o markdown
+	PickupPoints             []PickupPoint `json:"pickup_points"` // Opcional, máximo MaxPickupPoints
+	BookingCloseMinutes      *int        `json:"booking_close_minutes"` // Opcional: minutos antes de la salida en que se cierran las reservas (0 = hasta la salida)
 }
 
 // UpdateTripRequest representa la solicitud para actualizar un viaje existente
@@ -57,9 +82,13 @@ type UpdateTripRequest struct {
 	DepartureDatetime        *string      `json:"departure_datetime"`        // RFC3339 format
 	EstimatedArrivalDatetime *string      `json:"estimated_arrival_datetime"` // RFC3339 format
 	PricePerSeat             *float64     `json:"price_per_seat"`
+	Currency                 *string      `json:"currency"`
 	TotalSeats               *int         `json:"total_seats"`
 	Car                      *Car         `json:"car"`
 	Preferences              *Preferences `json:"preferences"`
 	Description              *string      `json:"description"`
+	DescriptionFormat        *string      `json:"description_format"` // Sin description se vuelve a sanitizar la actual con el nuevo formato
+	PickupPoints             *[]PickupPoint `json:"pickup_points"` // Reemplaza la lista completa; los IDs existentes se conservan
+	BookingCloseMinutes      *int         `json:"booking_close_minutes"`
 }
 
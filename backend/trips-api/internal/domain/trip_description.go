@@ -0,0 +1,31 @@
+package domain
+
+import "fmt"
+
+// Formatos de la descripción de un viaje (description_format)
+const (
+	DescriptionFormatPlain    = "plain"    // Texto plano: se descarta cualquier etiqueta HTML
+	DescriptionFormatHTML     = "html"     // HTML con etiquetas de una lista permitida (ver richtext.HTMLSanitizer)
+	DescriptionFormatMarkdown = "markdown" // Markdown sin HTML embebido ni links inseguros
+)
+
+// Límites de la descripción de un viaje
+const (
+	MaxDescriptionInputBytes = 20000 // Tamaño máximo del texto enriquecido recibido (antes de sanitizar)
+	MaxDescriptionTextLength = 2000  // Largo máximo en caracteres del texto plano extraído
+)
+
+// TripDescription es una descripción ya sanitizada junto con su texto plano
+//
+// Content es lo que se guarda y se muestra (en el formato indicado); Text es el texto
+// plano que viaja en los eventos y que search-api indexa en search_text.
+type TripDescription struct {
+	Content string
+	Format  string
+	Text    string
+}
+
+// InvalidDescription arma el error de una descripción rechazada (formato desconocido o demasiado larga)
+func InvalidDescription(format string, args ...interface{}) *AppError {
+	return &AppError{Code: ErrInvalidDescription.Code, Message: fmt.Sprintf(format, args...)}
+}
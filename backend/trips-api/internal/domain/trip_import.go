@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Límites de una importación masiva de viajes (POST /trips/bulk)
+const (
+	MaxTripImportRows  = 100     // Viajes por importación
+	MaxTripImportBytes = 4 << 20 // Tamaño máximo del body o del archivo subido (4 MiB)
+)
+
+// TripImportRow es un viaje leído de una importación masiva
+// Err es el error de lectura o de formato de la fila (campo faltante, número inválido): la fila no se crea
+type TripImportRow struct {
+	Row     int // 1 = primer viaje (el encabezado del CSV no cuenta)
+	Request CreateTripRequest
+	Err     error
+}
+
+// TripImportResult es el resultado de una fila de la importación
+type TripImportResult struct {
+	Row     int         `json:"row"`
+	Success bool        `json:"success"`
+	TripID  string      `json:"trip_id,omitempty"`
+	Status  string      `json:"status,omitempty"` // published, o closed si ya salió de la ventana de reservas
+	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"`    // Código del error (PAST_DEPARTURE, UNKNOWN_CITY, ...), si tiene
+	Details interface{} `json:"details,omitempty"` // Detalles del error (límite del mercado, sugerencias de ciudad)
+}
+
+// TripImportReport es el reporte por fila de una importación, en el orden del archivo
+type TripImportReport struct {
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Results []TripImportResult `json:"results"`
+}
+
+// NewTripImportReport crea el reporte vacío de una importación de total filas
+func NewTripImportReport(total int) *TripImportReport {
+	return &TripImportReport{Total: total, Results: make([]TripImportResult, 0, total)}
+}
+
+// AddCreated registra una fila creada
+func (r *TripImportReport) AddCreated(row int, trip *Trip) {
+	r.Created++
+	r.Results = append(r.Results, TripImportResult{Row: row, Success: true, TripID: trip.ID.Hex(), Status: trip.Status})
+}
+
+// AddFailed registra una fila rechazada; los AppError conservan su código y sus detalles
+func (r *TripImportReport) AddFailed(row int, err error) {
+	r.Failed++
+	result := TripImportResult{Row: row, Error: err.Error()}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		result.Code = appErr.Code
+		result.Details = appErr.Details
+	}
+	r.Results = append(r.Results, result)
+}
+
+// InvalidTripImportError arma el error de una importación que no se puede leer (no se crea ningún viaje)
+func InvalidTripImportError(format string, args ...interface{}) *AppError {
+	return &AppError{Code: ErrInvalidTripImport.Code, Message: fmt.Sprintf(format, args...)}
+}
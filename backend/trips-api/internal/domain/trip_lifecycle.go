@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Estados del ciclo de vida de un viaje después de publicado (los asigna el scheduler de estados)
+const (
+	TripStatusClosed     = "closed"      // Cerrado a reservas nuevas antes de la salida (booking_closes_at alcanzada)
+	TripStatusInProgress = "in_progress" // Salió (departure_datetime alcanzada) y todavía no llegó
+	TripStatusCompleted  = "completed"   // Llegó (estimated_arrival_datetime alcanzada)
+	TripStatusCancelled  = "cancelled"   // Cancelado por el conductor (cancelled_at)
+)
+
+// MaxBookingCloseMinutes es la anticipación máxima con la que se cierran las reservas de un viaje (48 horas)
+const MaxBookingCloseMinutes = 48 * 60
+
+// AcceptsReservations indica si un viaje en este estado puede recibir reservas nuevas o más asientos
+// Solo los viajes publicados: pausados, cerrados, en curso, completados y cancelados rechazan la reserva
+func AcceptsReservations(status string) bool {
+	return status == TripStatusPublished
+}
+
+// ReservationRejectionReason es el motivo de reservation.failed para un viaje que no acepta reservas
+func ReservationRejectionReason(status string) string {
+	switch status {
+	case TripStatusPaused:
+		return "Trip is paused"
+	case TripStatusClosed:
+		return "Trip is closed for new reservations"
+	case TripStatusInProgress:
+		return "Trip already departed"
+	case TripStatusCompleted:
+		return "Trip is completed"
+	case "cancelled":
+		return "Trip is cancelled"
+	}
+	return "Trip is not accepting reservations"
+}
+
+// SeatIncreaseRejection devuelve el motivo por el que no se puede sumar delta asientos a una reserva
+// del viaje ("" si se puede). Achicar una reserva nunca se rechaza: libera asientos en cualquier estado
+func SeatIncreaseRejection(status string, availableSeats, delta int) string {
+	switch {
+	case delta <= 0:
+		return ""
+	case !AcceptsReservations(status):
+		return ReservationRejectionReason(status)
+	case availableSeats < delta:
+		return "No seats available"
+	}
+	return ""
+}
+
+// TripLifecycleRunResult resume una corrida del scheduler de estados
+type TripLifecycleRunResult struct {
+	Closed    int64  `json:"closed"`    // published → closed
+	Started   int64  `json:"started"`   // published / closed → in_progress
+	Completed int64  `json:"completed"` // in_progress → completed
+	Skipped   int64  `json:"skipped"`   // Viajes que cambiaron de estado mientras tanto
+	Duration  string `json:"duration"`
+}
+
+// ValidateBookingCloseMinutes verifica la anticipación del cierre elegida por el conductor (nil = la del mercado)
+func ValidateBookingCloseMinutes(minutes *int) error {
+	if minutes != nil && (*minutes < 0 || *minutes > MaxBookingCloseMinutes) {
+		return &AppError{
+			Code:    ErrInvalidBookingClose.Code,
+			Message: fmt.Sprintf("booking_close_minutes must be between 0 and %d", MaxBookingCloseMinutes),
+		}
+	}
+	return nil
+}
+
+// ApplyBookingClose calcula booking_closes_at con la anticipación del conductor o, si no eligió
+// ninguna, con la del mercado. Con 0 minutos el viaje acepta reservas hasta la salida
+func (t *Trip) ApplyBookingClose(marketMinutes int) {
+	minutes := marketMinutes
+	if t.BookingCloseMinutes != nil {
+		minutes = *t.BookingCloseMinutes
+	}
+	if minutes <= 0 {
+		t.BookingClosesAt = nil
+		return
+	}
+
+	closesAt := t.DepartureDatetime.Add(-time.Duration(minutes) * time.Minute)
+	t.BookingClosesAt = &closesAt
+}
+
+// SyncBookingClose alinea published / closed con booking_closes_at después de editar el viaje:
+// lo cierra si el nuevo cierre ya pasó y lo reabre si el conductor lo postergó.
+// Los demás estados (pausado, en curso, etc.) no se tocan
+func (t *Trip) SyncBookingClose(now time.Time) {
+	closed := t.BookingClosesAt != nil && !t.BookingClosesAt.After(now)
+
+	switch {
+	case t.Status == TripStatusPublished && closed:
+		t.Status = TripStatusClosed
+	case t.Status == TripStatusClosed && !closed:
+		t.Status = TripStatusPublished
+	}
+}
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeatIncreaseRejection(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		availableSeats int
+		delta          int
+		want           string
+	}{
+		{name: "aumento con asientos libres", status: TripStatusPublished, availableSeats: 3, delta: 2, want: ""},
+		{name: "aumento que usa el último asiento", status: TripStatusPublished, availableSeats: 1, delta: 1, want: ""},
+		{name: "aumento sin asientos suficientes", status: TripStatusPublished, availableSeats: 1, delta: 2, want: "No seats available"},
+		{name: "aumento en viaje pausado", status: TripStatusPaused, availableSeats: 3, delta: 1, want: "Trip is paused"},
+		{name: "aumento en viaje cerrado", status: TripStatusClosed, availableSeats: 3, delta: 1, want: "Trip is closed for new reservations"},
+		{name: "aumento en viaje en curso", status: TripStatusInProgress, availableSeats: 3, delta: 1, want: "Trip already departed"},
+		{name: "aumento en viaje cancelado", status: "cancelled", availableSeats: 3, delta: 1, want: "Trip is cancelled"},
+		{name: "reducción en viaje cerrado", status: TripStatusClosed, availableSeats: 0, delta: -1, want: ""},
+		{name: "reducción en viaje en curso", status: TripStatusInProgress, availableSeats: 0, delta: -2, want: ""},
+		{name: "sin cambio", status: TripStatusPaused, availableSeats: 0, delta: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SeatIncreaseRejection(tt.status, tt.availableSeats, tt.delta))
+		})
+	}
+}
+
+func TestReservationRejectionReason_UnknownStatus(t *testing.T) {
+	assert.Equal(t, "Trip is not accepting reservations", ReservationRejectionReason("draft"))
+	assert.True(t, AcceptsReservations(TripStatusPublished))
+	assert.False(t, AcceptsReservations(TripStatusCompleted))
+}
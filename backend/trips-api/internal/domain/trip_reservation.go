@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de una reserva en el ledger local
+const (
+	TripReservationConfirmed = "confirmed" // Sus asientos cuentan en reserved_seats
+	TripReservationCancelled = "cancelled" // Cancelada o expirada en bookings-api
+)
+
+// TripReservation es la copia local de una reserva confirmada por el trips-api (ledger)
+// Permite validar que reserved_seats coincida con la suma de las reservas confirmadas
+type TripReservation struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ReservationID string             `json:"reservation_id" bson:"reservation_id"` // UNIQUE index
+	TripID        string             `json:"trip_id" bson:"trip_id"`
+	PassengerID   int64              `json:"passenger_id" bson:"passenger_id"`
+	Seats         int                `json:"seats" bson:"seats"`
+	Status        string             `json:"status" bson:"status"`
+	SeatHoldID    string             `json:"seat_hold_id,omitempty" bson:"seat_hold_id,omitempty"` // Retención confirmada por esta reserva (vacío si no usó una)
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
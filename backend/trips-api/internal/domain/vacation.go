@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados posibles de un período de vacaciones
+const (
+	VacationStatusActive   = "active"   // Los viajes de la ventana están pausados
+	VacationStatusFinished = "finished" // La ventana terminó y los viajes fueron reanudados
+)
+
+// Estados de viaje involucrados en el modo vacaciones
+const (
+	TripStatusPublished = "published"
+	TripStatusPaused    = "paused"
+)
+
+// DriverVacation representa un período en el que el conductor no ofrece viajes
+//
+// Mientras la vacación está activa:
+// - Los viajes publicados con salida dentro de [start_date, end_date] quedan en status "paused"
+// - No se pueden crear viajes nuevos con salida dentro de la ventana
+//
+// Al terminar la ventana, los viajes pausados por esta vacación vuelven a "published".
+type DriverVacation struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	DriverID      int64              `json:"driver_id" bson:"driver_id"`
+	StartDate     time.Time          `json:"start_date" bson:"start_date"`
+	EndDate       time.Time          `json:"end_date" bson:"end_date"`
+	Status        string             `json:"status" bson:"status"`                   // active, finished
+	PausedTripIDs []string           `json:"paused_trip_ids" bson:"paused_trip_ids"` // Viajes pausados por esta vacación
+	ResumedAt     *time.Time         `json:"resumed_at,omitempty" bson:"resumed_at,omitempty"`
+	CreatedAt     time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CreateVacationRequest representa la solicitud para activar el modo vacaciones
+type CreateVacationRequest struct {
+	StartDate string `json:"start_date" binding:"required"` // RFC3339 format
+	EndDate   string `json:"end_date" binding:"required"`   // RFC3339 format
+}
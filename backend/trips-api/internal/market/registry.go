@@ -0,0 +1,157 @@
+package market
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"trips-api/internal/domain"
+)
+
+// Registry resuelve el mercado (país) de un viaje a partir de su origen
+//
+// Los mercados se cargan una sola vez al iniciar (archivo JSON o defaults)
+// y luego son de solo lectura, por lo que el registry es seguro para uso concurrente.
+type Registry interface {
+	// Resolve devuelve el mercado del origen y cómo se resolvió
+	// Orden: país del origen → provincia del origen → mercado por defecto
+	Resolve(origin domain.Location) (domain.MarketPolicy, string)
+}
+
+// registry implementa Registry con mapas en memoria
+type registry struct {
+	markets        map[string]domain.MarketPolicy
+	provinces      map[string]string // provincia normalizada → país
+	defaultCountry string
+}
+
+// NewRegistry crea un registry a partir de una lista de mercados
+// Retorna error si un mercado es inválido o está duplicado, si una provincia aparece
+// en más de un mercado, o si el país por defecto no tiene mercado
+func NewRegistry(defaultCountry string, markets []domain.MarketPolicy) (Registry, error) {
+	r := &registry{
+		markets:        make(map[string]domain.MarketPolicy, len(markets)),
+		provinces:      make(map[string]string),
+		defaultCountry: normalizeCountry(defaultCountry),
+	}
+
+	for _, m := range markets {
+		m.Country = normalizeCountry(m.Country)
+		for i := range m.Currencies {
+			m.Currencies[i] = strings.ToUpper(strings.TrimSpace(m.Currencies[i]))
+		}
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid market: %w", err)
+		}
+		if _, exists := r.markets[m.Country]; exists {
+			return nil, fmt.Errorf("duplicate market for country %s", m.Country)
+		}
+		r.markets[m.Country] = m
+
+		for _, province := range m.Provinces {
+			key := normalizeProvince(province)
+			if other, exists := r.provinces[key]; exists {
+				return nil, fmt.Errorf("province %q belongs to markets %s and %s", province, other, m.Country)
+			}
+			r.provinces[key] = m.Country
+		}
+	}
+
+	if _, ok := r.markets[r.defaultCountry]; !ok {
+		return nil, fmt.Errorf("no market defined for default country %q", r.defaultCountry)
+	}
+
+	return r, nil
+}
+
+// LoadRegistry construye el registry desde un archivo JSON, o desde DefaultMarkets si path está vacío
+//
+// Formato del archivo: un array JSON de domain.MarketPolicy, ej:
+//
+//	[{"country": "AR", "provinces": ["Buenos Aires", "Córdoba"], "currencies": ["ARS"],
+//	  "max_price_per_seat": 100000, "max_trip_distance_km": 1500}]
+func LoadRegistry(path, defaultCountry string) (Registry, error) {
+	if path == "" {
+		return NewRegistry(defaultCountry, DefaultMarkets())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read markets file: %w", err)
+	}
+
+	var markets []domain.MarketPolicy
+	if err := json.Unmarshal(data, &markets); err != nil {
+		return nil, fmt.Errorf("failed to parse markets file: %w", err)
+	}
+
+	return NewRegistry(defaultCountry, markets)
+}
+
+// DefaultMarkets devuelve los mercados usados cuando no se configura un archivo
+func DefaultMarkets() []domain.MarketPolicy {
+	return []domain.MarketPolicy{
+		{
+			Country: "AR",
+			Provinces: []string{
+				"Buenos Aires", "CABA", "Ciudad Autónoma de Buenos Aires", "Catamarca", "Chaco",
+				"Chubut", "Córdoba", "Corrientes", "Entre Ríos", "Formosa", "Jujuy", "La Pampa",
+				"La Rioja", "Mendoza", "Misiones", "Neuquén", "Río Negro", "Salta", "San Juan",
+				"San Luis", "Santa Cruz", "Santa Fe", "Santiago del Estero", "Tierra del Fuego", "Tucumán",
+			},
+			Currencies:        []string{"ARS"},
+			MaxPricePerSeat:   150000,
+			MaxTripDistanceKm: 1500,
+		},
+		{
+			Country: "UY",
+			// Río Negro se omite: coincide con la provincia argentina (usar country: "UY")
+			Provinces: []string{
+				"Artigas", "Canelones", "Cerro Largo", "Colonia", "Durazno", "Flores", "Florida",
+				"Lavalleja", "Maldonado", "Montevideo", "Paysandú", "Rivera", "Rocha", "Salto",
+				"San José", "Soriano", "Tacuarembó", "Treinta y Tres",
+			},
+			Currencies:        []string{"UYU", "USD"},
+			MaxPricePerSeat:   6000,
+			MaxTripDistanceKm: 700,
+		},
+		{
+			Country: "CL",
+			Provinces: []string{
+				"Arica y Parinacota", "Tarapacá", "Antofagasta", "Atacama", "Coquimbo", "Valparaíso",
+				"Metropolitana", "Región Metropolitana", "O'Higgins", "Maule", "Ñuble", "Biobío",
+				"La Araucanía", "Los Ríos", "Los Lagos", "Aysén", "Magallanes",
+			},
+			Currencies:        []string{"CLP"},
+			MaxPricePerSeat:   120000,
+			MaxTripDistanceKm: 2000,
+		},
+	}
+}
+
+// Resolve devuelve el mercado del origen, con fallback al mercado por defecto
+func (r *registry) Resolve(origin domain.Location) (domain.MarketPolicy, string) {
+	if m, ok := r.markets[normalizeCountry(origin.Country)]; ok {
+		return m, domain.MarketSourceOriginCountry
+	}
+	if country, ok := r.provinces[normalizeProvince(origin.Province)]; ok {
+		return r.markets[country], domain.MarketSourceOriginProvince
+	}
+	return r.markets[r.defaultCountry], domain.MarketSourceDefault
+}
+
+// normalizeCountry pasa a mayúsculas y recorta un código de país
+func normalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+// accentReplacer quita tildes para comparar provincias escritas con o sin acentos
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u",
+)
+
+// normalizeProvince pasa a minúsculas, recorta y quita tildes de una provincia
+func normalizeProvince(province string) string {
+	return accentReplacer.Replace(strings.ToLower(strings.TrimSpace(province)))
+}
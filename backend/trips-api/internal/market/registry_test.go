@@ -0,0 +1,133 @@
+package market
+
+import (
+	"testing"
+	"time"
+
+	"trips-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	r, err := LoadRegistry("", "AR")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		origin         domain.Location
+		expectedMarket string
+		expectedSource string
+	}{
+		{"explicit country", domain.Location{Country: "uy", Province: "Córdoba"}, "UY", domain.MarketSourceOriginCountry},
+		{"province with accents", domain.Location{Province: "Córdoba"}, "AR", domain.MarketSourceOriginProvince},
+		{"province without accents", domain.Location{Province: "paysandu"}, "UY", domain.MarketSourceOriginProvince},
+		{"unknown country falls back to province", domain.Location{Country: "BR", Province: "Valparaíso"}, "CL", domain.MarketSourceOriginProvince},
+		{"unknown location", domain.Location{Province: "Somewhere"}, "AR", domain.MarketSourceDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, source := r.Resolve(tt.origin)
+			assert.Equal(t, tt.expectedMarket, m.Country)
+			assert.Equal(t, tt.expectedSource, source)
+		})
+	}
+}
+
+func TestNewRegistry_Invalid(t *testing.T) {
+	valid := domain.MarketPolicy{Country: "AR", Provinces: []string{"Salta"}, Currencies: []string{"ARS"}}
+
+	_, err := NewRegistry("UY", []domain.MarketPolicy{valid})
+	assert.Error(t, err, "default country without market")
+
+	_, err = NewRegistry("AR", []domain.MarketPolicy{valid, valid})
+	assert.Error(t, err, "duplicated market")
+
+	_, err = NewRegistry("AR", []domain.MarketPolicy{valid, {Country: "CL", Provinces: []string{"salta"}, Currencies: []string{"CLP"}}})
+	assert.Error(t, err, "province in two markets")
+
+	_, err = NewRegistry("AR", []domain.MarketPolicy{{Country: "AR"}})
+	assert.Error(t, err, "market without currencies")
+
+	_, err = NewRegistry("AR", []domain.MarketPolicy{{Country: "AR", Currencies: []string{"ARS"}, BookingCloseMinutes: -30}})
+	assert.Error(t, err, "negative booking close")
+}
+
+func TestValidateTrip(t *testing.T) {
+	m := domain.MarketPolicy{Country: "AR", Currencies: []string{"ARS"}, MaxPricePerSeat: 1000, MaxTripDistanceKm: 500}
+
+	buenosAires := domain.Coordinates{Lat: -34.6037, Lng: -58.3816}
+	rosario := domain.Coordinates{Lat: -32.9442, Lng: -60.6505} // ~280 km
+	mendoza := domain.Coordinates{Lat: -32.8895, Lng: -68.8458} // ~980 km
+
+	trip := func(price float64, currency string, destination domain.Coordinates) *domain.Trip {
+		return &domain.Trip{
+			PricePerSeat: price,
+			Currency:     currency,
+			Origin:       domain.Location{Coordinates: buenosAires},
+			Destination:  domain.Location{Coordinates: destination},
+		}
+	}
+
+	assert.NoError(t, m.ValidateTrip(trip(1000, "ars", rosario)))
+
+	err := m.ValidateTrip(trip(1000, "USD", rosario))
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrCurrencyNotAllowed.Code, err.(*domain.AppError).Code)
+
+	err = m.ValidateTrip(trip(1500, "ARS", rosario))
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrPriceAboveMarketCap.Code, err.(*domain.AppError).Code)
+
+	err = m.ValidateTrip(trip(500, "ARS", mendoza))
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTripDistanceAboveMarketCap.Code, err.(*domain.AppError).Code)
+}
+
+func TestValidateTripChecks(t *testing.T) {
+	m := domain.MarketPolicy{Country: "AR", Currencies: []string{"ARS"}, MaxPricePerSeat: 1000, MaxTripDistanceKm: 500}
+
+	// Viaje publicado antes de los límites: precio, moneda y distancia fuera del mercado
+	legacy := &domain.Trip{
+		PricePerSeat: 1500,
+		Currency:     "USD",
+		Origin:       domain.Location{Coordinates: domain.Coordinates{Lat: -34.6037, Lng: -58.3816}},
+		Destination:  domain.Location{Coordinates: domain.Coordinates{Lat: -32.8895, Lng: -68.8458}},
+	}
+
+	assert.NoError(t, m.ValidateTripChecks(legacy, domain.MarketChecks{}), "edit that touches no market field")
+
+	err := m.ValidateTripChecks(legacy, domain.MarketChecks{Price: true})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrPriceAboveMarketCap.Code, err.(*domain.AppError).Code)
+
+	err = m.ValidateTripChecks(legacy, domain.MarketChecks{Distance: true})
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrTripDistanceAboveMarketCap.Code, err.(*domain.AppError).Code)
+
+	err = m.ValidateTripChecks(legacy, domain.AllMarketChecks)
+	require.Error(t, err)
+	assert.Equal(t, domain.ErrCurrencyNotAllowed.Code, err.(*domain.AppError).Code)
+}
+
+func TestApplyBookingClose(t *testing.T) {
+	departure := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	driverMinutes := 0
+
+	trip := &domain.Trip{DepartureDatetime: departure, Status: domain.TripStatusPublished}
+	trip.ApplyBookingClose(60)
+	require.NotNil(t, trip.BookingClosesAt)
+	assert.Equal(t, departure.Add(-time.Hour), *trip.BookingClosesAt, "market default")
+
+	trip.SyncBookingClose(departure.Add(-30 * time.Minute))
+	assert.Equal(t, domain.TripStatusClosed, trip.Status, "closing time passed")
+
+	trip.BookingCloseMinutes = &driverMinutes
+	trip.ApplyBookingClose(60)
+	assert.Nil(t, trip.BookingClosesAt, "driver keeps reservations open until departure")
+
+	trip.SyncBookingClose(departure.Add(-30 * time.Minute))
+	assert.Equal(t, domain.TripStatusPublished, trip.Status, "reopened")
+}
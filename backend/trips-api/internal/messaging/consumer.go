@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"trips-api/internal/metrics"
+	"trips-api/internal/tracing"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
@@ -13,7 +15,7 @@ const (
 	// Inbound exchange/queue configuration (from bookings-api)
 	consumerExchange = "bookings.events"    // Topic exchange (must match bookings-api publisher)
 	consumerQueue    = "trips.reservations" // Durable queue
-	bindingKey       = "reservation.*"      // Matches reservation.created, reservation.cancelled
+	bindingKey       = "reservation.*"      // Matches reservation.created, reservation.cancelled, reservation.modified
 
 	// Consumer settings
 	prefetchCount = 10                   // Process 10 messages concurrently
@@ -25,11 +27,13 @@ const (
 type TripServiceInterface interface {
 	ProcessReservationCreated(ctx context.Context, event ReservationCreatedEvent) error
 	ProcessReservationCancelled(ctx context.Context, event ReservationCancelledEvent) error
+	ProcessReservationModified(ctx context.Context, event ReservationModifiedEvent) error
 }
 
 // IdempotencyServiceInterface define los métodos necesarios del idempotency service
 type IdempotencyServiceInterface interface {
 	CheckAndMarkEvent(ctx context.Context, eventID, eventType string) (shouldProcess bool, err error)
+	ReleaseEvent(ctx context.Context, eventID string) error
 }
 
 // ReservationConsumer define la interfaz para consumir eventos de reservas
@@ -175,8 +179,11 @@ func (c *reservationConsumer) Start(ctx context.Context) error {
 				return fmt.Errorf("deliveries channel closed")
 			}
 
-			// Procesar mensaje
-			err := c.handleDelivery(ctx, delivery)
+			// Procesar mensaje continuando la traza del publisher (bookings-api)
+			msgCtx, span := tracing.StartConsume(ctx, consumerQueue, delivery)
+			err := c.handleDelivery(msgCtx, delivery)
+			metrics.ObserveConsume(delivery.RoutingKey, err)
+			tracing.End(span, err)
 			if err != nil {
 				// Error de sistema - NACK con requeue
 				log.Error().
@@ -230,17 +237,35 @@ func (c *reservationConsumer) handleDelivery(ctx context.Context, delivery amqp.
 		return nil // ACK - skip processing
 	}
 
-	// Rutear al handler apropiado según el tipo de evento
-	switch baseEvent.EventType {
+	// Si el handler falla (NACK) se libera la marca de idempotencia, así la reentrega se procesa
+	if err := c.routeEvent(ctx, baseEvent.EventType, delivery.Body); err != nil {
+		if releaseErr := c.idempotencyService.ReleaseEvent(ctx, baseEvent.EventID); releaseErr != nil {
+			log.Error().
+				Err(releaseErr).
+				Str("event_id", baseEvent.EventID).
+				Str("event_type", baseEvent.EventType).
+				Msg("Failed to release event, its redelivery will be skipped")
+		}
+		return err
+	}
+	return nil
+}
+
+// routeEvent rutea un evento al handler de su tipo
+func (c *reservationConsumer) routeEvent(ctx context.Context, eventType string, body []byte) error {
+	switch eventType {
 	case "reservation.created":
-		return c.handleReservationCreated(ctx, delivery.Body)
+		return c.handleReservationCreated(ctx, body)
 
 	case "reservation.cancelled":
-		return c.handleReservationCancelled(ctx, delivery.Body)
+		return c.handleReservationCancelled(ctx, body)
+
+	case "reservation.modified":
+		return c.handleReservationModified(ctx, body)
 
 	default:
 		log.Warn().
-			Str("event_type", baseEvent.EventType).
+			Str("event_type", eventType).
 			Msg("Unknown event type, ignoring")
 		return nil // ACK - tipo desconocido
 	}
@@ -312,6 +337,41 @@ func (c *reservationConsumer) handleReservationCancelled(ctx context.Context, bo
 	return nil
 }
 
+// handleReservationModified procesa eventos de cambio parcial de asientos
+func (c *reservationConsumer) handleReservationModified(ctx context.Context, body []byte) error {
+	var event ReservationModifiedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Error().Err(err).Msg("Failed to unmarshal reservation.modified event")
+		return nil // ACK - JSON inválido
+	}
+
+	log.Info().
+		Str("event_id", event.EventID).
+		Str("trip_id", event.TripID).
+		Str("reservation_id", event.ReservationID).
+		Int("previous_seats", event.PreviousSeats).
+		Int("new_seats", event.NewSeats).
+		Int("seats_delta", event.SeatsDelta).
+		Msg("Processing reservation.modified event")
+
+	// Delegar al servicio de negocio
+	err := c.tripService.ProcessReservationModified(ctx, event)
+	if err != nil {
+		// Error de sistema - NACK
+		log.Error().
+			Err(err).
+			Str("event_id", event.EventID).
+			Msg("Failed to process reservation.modified")
+		return err
+	}
+
+	log.Info().
+		Str("event_id", event.EventID).
+		Str("trip_id", event.TripID).
+		Msg("Successfully processed reservation.modified event")
+	return nil
+}
+
 // Close cierra el canal y la conexión de RabbitMQ
 func (c *reservationConsumer) Close() error {
 	if c.channel != nil {
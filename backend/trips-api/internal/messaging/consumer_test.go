@@ -0,0 +1,100 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTripService registra los reservation.modified que recibe el consumer
+type fakeTripService struct {
+	TripServiceInterface
+	modified []ReservationModifiedEvent
+	err      error
+}
+
+func (s *fakeTripService) ProcessReservationModified(ctx context.Context, event ReservationModifiedEvent) error {
+	s.modified = append(s.modified, event)
+	return s.err
+}
+
+// fakeIdempotency es una tabla processed_events en memoria
+type fakeIdempotency struct {
+	processed map[string]bool
+	released  []string
+}
+
+func (f *fakeIdempotency) CheckAndMarkEvent(ctx context.Context, eventID, eventType string) (bool, error) {
+	if f.processed[eventID] {
+		return false, nil
+	}
+	f.processed[eventID] = true
+	return true, nil
+}
+
+func (f *fakeIdempotency) ReleaseEvent(ctx context.Context, eventID string) error {
+	delete(f.processed, eventID)
+	f.released = append(f.released, eventID)
+	return nil
+}
+
+func newTestConsumer() (*reservationConsumer, *fakeTripService, *fakeIdempotency) {
+	trips := &fakeTripService{}
+	idempotency := &fakeIdempotency{processed: map[string]bool{}}
+	return &reservationConsumer{tripService: trips, idempotencyService: idempotency}, trips, idempotency
+}
+
+func modifiedDelivery(eventID string) amqp.Delivery {
+	return amqp.Delivery{
+		RoutingKey: "reservation.modified",
+		Body: []byte(`{"event_id": "` + eventID + `", "event_type": "reservation.modified", "trip_id": "656f1c2a9e3b4d4e5f8a9b0c",
+			"passenger_id": 7, "reservation_id": "booking-1", "previous_seats": 2, "new_seats": 3, "seats_delta": 1}`),
+	}
+}
+
+func TestHandleDelivery_RoutesReservationModified(t *testing.T) {
+	consumer, trips, _ := newTestConsumer()
+
+	require.NoError(t, consumer.handleDelivery(context.Background(), modifiedDelivery("e-1")))
+
+	require.Len(t, trips.modified, 1)
+	event := trips.modified[0]
+	assert.Equal(t, "booking-1", event.ReservationID)
+	assert.Equal(t, 2, event.PreviousSeats)
+	assert.Equal(t, 3, event.NewSeats)
+	assert.Equal(t, 1, event.SeatsDelta)
+}
+
+func TestHandleDelivery_SkipsRedeliveredModification(t *testing.T) {
+	consumer, trips, _ := newTestConsumer()
+
+	require.NoError(t, consumer.handleDelivery(context.Background(), modifiedDelivery("e-1")))
+	require.NoError(t, consumer.handleDelivery(context.Background(), modifiedDelivery("e-1")))
+
+	assert.Len(t, trips.modified, 1, "la reentrega no vuelve a aplicar el delta")
+}
+
+func TestHandleDelivery_FailedModificationIsRetried(t *testing.T) {
+	consumer, trips, idempotency := newTestConsumer()
+	trips.err = errors.New("failed to update reservation ledger")
+
+	err := consumer.handleDelivery(context.Background(), modifiedDelivery("e-1"))
+	require.Error(t, err, "error de sistema: NACK")
+	assert.Equal(t, []string{"e-1"}, idempotency.released, "se libera la marca para procesar la reentrega")
+
+	trips.err = nil
+	require.NoError(t, consumer.handleDelivery(context.Background(), modifiedDelivery("e-1")))
+	assert.Len(t, trips.modified, 2)
+}
+
+func TestHandleDelivery_InvalidModificationIsAcked(t *testing.T) {
+	consumer, trips, _ := newTestConsumer()
+
+	err := consumer.handleDelivery(context.Background(), amqp.Delivery{Body: []byte(`{"event_id": "e-1", "event_type": "reservation.modified", "seats_delta": "one"}`)})
+	assert.NoError(t, err, "un payload inválido no se puede reprocesar: ACK")
+	assert.Empty(t, trips.modified)
+}
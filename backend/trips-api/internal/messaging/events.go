@@ -1,6 +1,9 @@
 package messaging
 
-import "time"
+import (
+	"time"
+	"trips-api/internal/domain"
+)
 
 // TripEvent representa el evento base para eventos de viajes
 // Usado para trip.created y trip.updated
@@ -8,6 +11,7 @@ type TripEvent struct {
 	EventID        string    `json:"event_id"`         // UUID v4 - CRÍTICO para idempotencia
 	EventType      string    `json:"event_type"`       // trip.created, trip.updated, trip.cancelled
 	TripID         string    `json:"trip_id"`          // MongoDB ObjectID como string
+	Sequence       int64     `json:"sequence,omitempty"` // Secuencia del viaje escrita junto al estado que lleva el evento (domain.Trip.EventSequence)
 	DriverID       int64     `json:"driver_id"`        // ID del conductor
 	Status         string    `json:"status"`           // Estado actual del viaje
 	AvailableSeats int       `json:"available_seats"`  // Asientos disponibles
@@ -15,6 +19,61 @@ type TripEvent struct {
 	Timestamp      time.Time `json:"timestamp"`        // Timestamp del evento
 	SourceService  string    `json:"source_service"`   // Siempre "trips-api"
 	CorrelationID  string    `json:"correlation_id"`   // ID para tracing de requests
+
+	// Ciudades canónicas del catálogo y ruta "origen:destino" (solo en trip.created y trip.updated)
+	// Omitidos si la ciudad no está en el catálogo: los consumidores comparan por nombre
+	OriginCityID      string `json:"origin_city_id,omitempty"`
+	DestinationCityID string `json:"destination_city_id,omitempty"`
+	RouteID           string `json:"route_id,omitempty"`
+}
+
+// DriverSnapshot contiene los datos del conductor que search-api necesita para desnormalizar
+// Se obtiene de users-api una sola vez al crear el viaje, así los consumidores no
+// necesitan volver a consultar users-api por cada trip.created
+type DriverSnapshot struct {
+	ID         int64     `json:"id"`          // ID del conductor
+	Name       string    `json:"name"`        // Nombre del conductor
+	Rating     float64   `json:"rating"`      // Rating promedio como conductor
+	TotalTrips int       `json:"total_trips"` // Viajes realizados como conductor
+	PhotoURL   string    `json:"photo_url,omitempty"`
+	FetchedAt  time.Time `json:"fetched_at"` // Momento en que se leyó de users-api (frescura del snapshot)
+
+	// Tiempos de respuesta del conductor (omitido si todavía no respondió nunca)
+	ResponseTime *DriverResponseTime `json:"response_time,omitempty"`
+}
+
+// DriverResponseTime son los promedios móviles de respuesta del conductor, calculados en trips-api
+// Un promedio con 0 muestras significa "sin datos", no "responde al instante"
+type DriverResponseTime struct {
+	ChatAvgSeconds float64   `json:"chat_avg_seconds"` // Demora promedio en responder mensajes de pasajeros
+	ChatSamples    int       `json:"chat_samples"`     // Respuestas incluidas en el promedio de chat
+	UpdatedAt      time.Time `json:"updated_at"`       // Última actualización de los promedios
+}
+
+// TripCreatedEvent representa el evento de creación de viaje
+// Extiende TripEvent con el snapshot del conductor (omitido si no estaba disponible)
+// y el texto plano de la descripción (nunca el HTML/Markdown que escribió el conductor)
+type TripCreatedEvent struct {
+	TripEvent
+	Driver          *DriverSnapshot      `json:"driver,omitempty"` // Snapshot del conductor al momento de publicar
+	DescriptionText string               `json:"description_text,omitempty"`
+	PickupPoints    []domain.PickupPoint `json:"pickup_points,omitempty"` // Puntos de encuentro del viaje
+}
+
+// TripUpdatedEvent representa el evento de actualización de viaje
+// Extiende TripEvent con el precio actual y el anterior al último cambio, para que search-api
+// y el front puedan mostrar "bajó de precio" sin consultar el historial
+type TripUpdatedEvent struct {
+	TripEvent
+	PricePerSeat         float64    `json:"price_per_seat"`
+	Currency             string     `json:"currency"`
+	PreviousPricePerSeat *float64   `json:"previous_price_per_seat,omitempty"` // Omitido si el precio nunca cambió
+	PriceChangedAt       *time.Time `json:"price_changed_at,omitempty"`
+	DescriptionText      string     `json:"description_text,omitempty"` // Texto plano de la descripción sanitizada
+
+	// Puntos de encuentro actuales del viaje: siempre presente ([] si el conductor los quitó todos),
+	// así los consumidores distinguen "sin puntos" de un evento de un publisher anterior
+	PickupPoints []domain.PickupPoint `json:"pickup_points"`
 }
 
 // TripCancelledEvent representa el evento de cancelación de viaje
@@ -45,6 +104,8 @@ type ReservationCreatedEvent struct {
 	PassengerID   int64     `json:"passenger_id"`    // ID del pasajero
 	SeatsReserved int       `json:"seats_reserved"`  // Número de asientos a reservar
 	ReservationID string    `json:"reservation_id"`  // UUID de bookings-api
+	PickupPointID string    `json:"pickup_point_id,omitempty"` // Punto de encuentro elegido (opcional)
+	SeatHoldID    string    `json:"seat_hold_id,omitempty"`    // Retención de asientos tomada antes de crear la reserva (opcional)
 	Timestamp     time.Time `json:"timestamp"`       // Timestamp del evento
 }
 
@@ -58,6 +119,19 @@ type ReservationCancelledEvent struct {
 	Timestamp     time.Time `json:"timestamp"`       // Timestamp del evento
 }
 
+// ReservationModifiedEvent representa un cambio parcial de asientos de una reserva confirmada (incoming from bookings-api)
+type ReservationModifiedEvent struct {
+	EventID       string    `json:"event_id"`       // UUID v4 - CRÍTICO para idempotencia
+	EventType     string    `json:"event_type"`     // "reservation.modified"
+	TripID        string    `json:"trip_id"`        // MongoDB ObjectID como string
+	PassengerID   int64     `json:"passenger_id"`   // ID del pasajero
+	ReservationID string    `json:"reservation_id"` // UUID de bookings-api
+	PreviousSeats int       `json:"previous_seats"` // Asientos antes del cambio
+	NewSeats      int       `json:"new_seats"`      // Asientos después del cambio
+	SeatsDelta    int       `json:"seats_delta"`    // NewSeats - PreviousSeats (positivo = pide más asientos)
+	Timestamp     time.Time `json:"timestamp"`      // Timestamp del evento
+}
+
 // ============================================================================
 // OUTGOING COMPENSATING EVENTS
 // ============================================================================
@@ -90,3 +164,39 @@ type ReservationConfirmedEvent struct {
 	CorrelationID  string    `json:"correlation_id"`  // Para tracing de requests
 	Timestamp      time.Time `json:"timestamp"`       // Timestamp del evento
 }
+
+// ReservationModificationFailedEvent representa un evento de compensación cuando no se puede
+// satisfacer un aumento de asientos (bookings-api revierte la reserva a PreviousSeats)
+type ReservationModificationFailedEvent struct {
+	EventID             string    `json:"event_id"`              // Nuevo UUID v4
+	EventType           string    `json:"event_type"`            // "reservation.modification_failed"
+	ModificationEventID string    `json:"modification_event_id"` // event_id del reservation.modified rechazado
+	ReservationID       string    `json:"reservation_id"`        // UUID de la reserva
+	TripID              string    `json:"trip_id"`               // MongoDB ObjectID como string
+	PreviousSeats       int       `json:"previous_seats"`        // Asientos antes del cambio rechazado
+	RequestedSeats      int       `json:"requested_seats"`       // Asientos que pidió el pasajero
+	Reason              string    `json:"reason"`                // "No seats available" | "Trip is paused" | ...
+	AvailableSeats      int       `json:"available_seats"`       // Cantidad actual de asientos disponibles
+	SourceService       string    `json:"source_service"`        // "trips-api"
+	CorrelationID       string    `json:"correlation_id"`        // Para tracing de requests
+	Timestamp           time.Time `json:"timestamp"`             // Timestamp del evento
+}
+
+// SeatDriftAlertEvent se publica cuando el chequeo de invariantes encuentra un viaje
+// cuyos contadores de asientos no cuadran (routing key alert.seat_drift, para alertas/métricas)
+type SeatDriftAlertEvent struct {
+	EventID        string    `json:"event_id"`                        // UUID v4
+	EventType      string    `json:"event_type"`                      // "alert.seat_drift"
+	TripID         string    `json:"trip_id"`                         // MongoDB ObjectID como string
+	DriverID       int64     `json:"driver_id"`                       // ID del conductor
+	Status         string    `json:"status"`                          // Estado actual del viaje
+	TotalSeats     int       `json:"total_seats"`                     // Asientos totales del viaje
+	ReservedSeats  int       `json:"reserved_seats"`                  // reserved_seats al detectar el drift
+	AvailableSeats int       `json:"available_seats"`                 // available_seats al detectar el drift
+	HeldSeats      int       `json:"held_seats"`                      // held_seats al detectar el drift
+	LedgerSeats    *int      `json:"ledger_reserved_seats,omitempty"` // Suma de reservas confirmadas del ledger
+	Reasons        []string  `json:"reasons"`                         // available_mismatch | ledger_mismatch | hold_mismatch
+	Repaired       bool      `json:"repaired"`                        // true si el modo auto-repair corrigió los contadores
+	SourceService  string    `json:"source_service"`                  // "trips-api"
+	Timestamp      time.Time `json:"timestamp"`                       // Timestamp del evento
+}
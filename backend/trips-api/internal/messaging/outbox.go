@@ -0,0 +1,286 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"trips-api/internal/domain"
+	"trips-api/internal/metrics"
+	"trips-api/internal/tracing"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutboxStore persiste los eventos pendientes de publicar (implementado en repository)
+type OutboxStore interface {
+	Insert(ctx context.Context, message *domain.OutboxMessage) error
+	InsertMany(ctx context.Context, messages []*domain.OutboxMessage) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxMessage, error)
+	MarkSent(ctx context.Context, id primitive.ObjectID, sentAt time.Time) error
+	MarkRetry(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, lastError string) error
+	CountFailed(ctx context.Context) (int64, error)
+	RequeueFailed(ctx context.Context, now time.Time) (int64, error)
+}
+
+// OutboxRelayConfig configura la publicación de los mensajes del outbox
+type OutboxRelayConfig struct {
+	PollInterval time.Duration // Cada cuánto se revisan los mensajes pendientes (además de los avisos)
+	MaxAttempts  int           // Intentos antes de marcar el mensaje como failed
+	RetryBase    time.Duration // Espera antes del primer reintento (se duplica en cada uno)
+	RetryMax     time.Duration // Espera máxima entre reintentos
+	Lease        time.Duration // Tiempo que un mensaje tomado queda reservado para este relay
+}
+
+// OutboxRelay publica en RabbitMQ los mensajes pendientes del outbox, con reintentos
+type OutboxRelay struct {
+	store     OutboxStore
+	publisher Publisher
+	cfg       OutboxRelayConfig
+	notify    chan struct{}
+}
+
+// NewOutboxRelay crea el relay; publisher debe ser el publisher de RabbitMQ (no el decorado con el outbox)
+func NewOutboxRelay(store OutboxStore, publisher Publisher, cfg OutboxRelayConfig) *OutboxRelay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 20
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 2 * time.Second
+	}
+	if cfg.RetryMax < cfg.RetryBase {
+		cfg.RetryMax = cfg.RetryBase
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 30 * time.Second
+	}
+
+	return &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		cfg:       cfg,
+		notify:    make(chan struct{}, 1),
+	}
+}
+
+// Notify despierta al relay para publicar un mensaje recién escrito sin esperar al próximo poll
+func (r *OutboxRelay) Notify() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Start publica los mensajes pendientes hasta que se cancele ctx (bloqueante, correr en una goroutine)
+// Al arrancar publica lo que quedó pendiente antes de un reinicio o de una caída de RabbitMQ
+func (r *OutboxRelay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.drain(ctx)
+		r.reportFailed(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		case <-r.notify:
+		}
+	}
+}
+
+// drain publica todos los mensajes vencidos
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		message, err := r.store.ClaimDue(ctx, time.Now(), r.cfg.Lease)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to read outbox")
+			return
+		}
+		if message == nil {
+			return
+		}
+		r.relay(ctx, message)
+	}
+}
+
+// reportFailed publica en métricas cuántos mensajes agotaron los reintentos (outbox_failed_messages)
+func (r *OutboxRelay) reportFailed(ctx context.Context) {
+	count, err := r.store.CountFailed(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to count failed outbox messages")
+		return
+	}
+	metrics.SetOutboxFailed(count)
+}
+
+// RequeueFailed vuelve a encolar los mensajes que agotaron los reintentos (por ejemplo después
+// de una caída larga de RabbitMQ) y despierta al relay. Retorna cuántos se reencolaron
+func (r *OutboxRelay) RequeueFailed(ctx context.Context) (int64, error) {
+	count, err := r.store.RequeueFailed(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	log.Info().Int64("messages", count).Msg("Failed outbox messages requeued")
+	r.Notify()
+	return count, nil
+}
+
+// relay publica un mensaje y registra el resultado
+func (r *OutboxRelay) relay(ctx context.Context, message *domain.OutboxMessage) {
+	// El publish continúa la traza de la request que escribió el mensaje
+	publishCtx := tracing.ExtractMap(ctx, message.TraceContext)
+	err := r.publisher.PublishMessage(publishCtx, message.RoutingKey, []byte(message.Payload))
+	if err == nil {
+		if err := r.store.MarkSent(ctx, message.ID, time.Now()); err != nil {
+			// El lease vence y se vuelve a publicar: los consumers deduplican por event_id
+			log.Error().Err(err).Str("event_id", message.EventID).Msg("Outbox message published but not marked as sent")
+			return
+		}
+		log.Info().
+			Str("event_id", message.EventID).
+			Str("routing_key", message.RoutingKey).
+			Str("trip_id", message.TripID).
+			Int("attempts", message.Attempts).
+			Msg("Outbox message published")
+		return
+	}
+
+	if message.Attempts >= r.cfg.MaxAttempts {
+		log.Error().
+			Err(err).
+			Str("event_id", message.EventID).
+			Str("routing_key", message.RoutingKey).
+			Str("trip_id", message.TripID).
+			Int("attempts", message.Attempts).
+			Msg("Outbox message exhausted its retries, marking as failed")
+		if err := r.store.MarkFailed(ctx, message.ID, err.Error()); err != nil {
+			log.Error().Err(err).Str("event_id", message.EventID).Msg("Failed to mark outbox message as failed")
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(r.backoff(message.Attempts))
+	log.Warn().
+		Err(err).
+		Str("event_id", message.EventID).
+		Str("routing_key", message.RoutingKey).
+		Int("attempts", message.Attempts).
+		Time("next_attempt_at", nextAttemptAt).
+		Msg("Failed to publish outbox message, will retry")
+	if err := r.store.MarkRetry(ctx, message.ID, nextAttemptAt, err.Error()); err != nil {
+		log.Error().Err(err).Str("event_id", message.EventID).Msg("Failed to reschedule outbox message")
+	}
+}
+
+// backoff retorna la espera antes del siguiente intento: RetryBase * 2^(attempts-1), hasta RetryMax
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	wait := r.cfg.RetryBase
+	for i := 1; i < attempts && wait < r.cfg.RetryMax; i++ {
+		wait *= 2
+	}
+	if wait > r.cfg.RetryMax {
+		wait = r.cfg.RetryMax
+	}
+	return wait
+}
+
+// outboxPublisher decora el publisher para que trip.created se escriba en el outbox
+// en lugar de publicarse directo (fire-and-forget)
+type outboxPublisher struct {
+	Publisher
+	store OutboxStore
+	relay *OutboxRelay
+}
+
+// NewOutboxPublisher envuelve un publisher para que PublishTripCreated pase por el outbox
+// El resto de los eventos se publica igual que antes
+func NewOutboxPublisher(inner Publisher, store OutboxStore, relay *OutboxRelay) Publisher {
+	return &outboxPublisher{
+		Publisher: inner,
+		store:     store,
+		relay:     relay,
+	}
+}
+
+// PublishTripCreated escribe el evento en el outbox y avisa al relay para publicarlo de inmediato
+// Si no se puede escribir en el outbox se publica directo (comportamiento anterior)
+func (p *outboxPublisher) PublishTripCreated(ctx context.Context, trip *domain.Trip, driver *DriverSnapshot) {
+	message, ok := p.tripCreatedMessage(ctx, trip, driver)
+	if !ok {
+		return
+	}
+
+	if err := p.store.Insert(ctx, message); err != nil {
+		log.Error().
+			Err(err).
+			Str("event_id", message.EventID).
+			Str("trip_id", message.TripID).
+			Msg("Failed to write trip.created to outbox, publishing directly")
+		p.publishDirectly(ctx, message)
+		return
+	}
+
+	p.relay.Notify()
+}
+
+// PublishTripsCreated escribe los trip.created del lote en el outbox con una sola escritura
+// y avisa al relay una vez; si la escritura falla se publican directo, como PublishTripCreated
+func (p *outboxPublisher) PublishTripsCreated(ctx context.Context, trips []*domain.Trip, driver *DriverSnapshot) {
+	messages := make([]*domain.OutboxMessage, 0, len(trips))
+	for _, trip := range trips {
+		if message, ok := p.tripCreatedMessage(ctx, trip, driver); ok {
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) == 0 {
+		return
+	}
+
+	if err := p.store.InsertMany(ctx, messages); err != nil {
+		log.Error().
+			Err(err).
+			Int("events", len(messages)).
+			Msg("Failed to write trip.created batch to outbox, publishing directly")
+		for _, message := range messages {
+			p.publishDirectly(ctx, message)
+		}
+		return
+	}
+
+	p.relay.Notify()
+}
+
+// tripCreatedMessage arma el mensaje del outbox de un trip.created
+func (p *outboxPublisher) tripCreatedMessage(ctx context.Context, trip *domain.Trip, driver *DriverSnapshot) (*domain.OutboxMessage, bool) {
+	event := newTripCreatedEvent(ctx, trip, driver)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", event.TripID).Msg("Failed to marshal trip.created for outbox")
+		return nil, false
+	}
+
+	return &domain.OutboxMessage{
+		EventID:    event.EventID,
+		EventType:  event.EventType,
+		RoutingKey: routingKeyTripCreated,
+		TripID:     event.TripID,
+		Payload:    string(body),
+
+		TraceContext: tracing.InjectMap(ctx),
+	}, true
+}
+
+// publishDirectly publica un mensaje que no se pudo escribir en el outbox (comportamiento anterior al outbox)
+func (p *outboxPublisher) publishDirectly(ctx context.Context, message *domain.OutboxMessage) {
+	if err := p.Publisher.PublishMessage(ctx, message.RoutingKey, []byte(message.Payload)); err != nil {
+		log.Error().Err(err).Str("event_id", message.EventID).RawJSON("event", []byte(message.Payload)).Msg("Failed to publish event to RabbitMQ")
+	}
+}
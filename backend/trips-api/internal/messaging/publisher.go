@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"time"
 	"trips-api/internal/domain"
+	"trips-api/internal/metrics"
+	"trips-api/internal/tracing"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/google/uuid"
@@ -24,30 +26,58 @@ const (
 	routingKeyReservationFailed    = "reservation.failed"
 	routingKeyReservationConfirmed = "reservation.confirmed"
 
+	routingKeyReservationModificationFailed = "reservation.modification_failed"
+
+	// Alertas operativas (no usan trip.* para no llegar a los consumers de search-api)
+	routingKeySeatDriftAlert = "alert.seat_drift"
+
 	// Source service identifier
 	sourceService = "trips-api"
 )
 
 // Publisher define la interfaz para publicar eventos de viajes a RabbitMQ
 type Publisher interface {
-	PublishTripCreated(ctx context.Context, trip *domain.Trip)
+	PublishTripCreated(ctx context.Context, trip *domain.Trip, driver *DriverSnapshot)
+	// PublishTripsCreated publica un trip.created por viaje, todos del mismo conductor (importaciones masivas)
+	PublishTripsCreated(ctx context.Context, trips []*domain.Trip, driver *DriverSnapshot)
 	PublishTripUpdated(ctx context.Context, trip *domain.Trip)
 	PublishTripCancelled(ctx context.Context, trip *domain.Trip, cancelledBy int64, reason string)
 	PublishTripDeleted(ctx context.Context, trip *domain.Trip, deletedBy int64, reason string)
 	PublishReservationFailure(ctx context.Context, reservationID, tripID, reason string, availableSeats int)
 	PublishReservationConfirmation(ctx context.Context, reservationID, tripID string, passengerID, driverID int64, seatsReserved int, totalPrice float64, availableSeats int)
-	PublishChatMessage(tripID string, userID int64, message string) error
+	PublishReservationModificationFailure(ctx context.Context, modified ReservationModifiedEvent, reason string, availableSeats int)
+	PublishSeatDriftAlert(ctx context.Context, drift *domain.SeatDrift)
+	// PublishChatMessage publica chat.message con los participantes del viaje (users-api solo notifica menciones a ellos)
+	PublishChatMessage(tripID string, userID int64, message string, driverID int64, passengerIDs []int64) error
+	// PublishMessage publica un evento ya serializado (lo usa el relay del outbox) y retorna el error
+	PublishMessage(ctx context.Context, routingKey string, body []byte) error
 	Close() error
 }
 
+// EventArchive persiste los eventos publicados en el archivo inmutable (implementado en repository)
+type EventArchive interface {
+	Append(ctx context.Context, event *domain.ArchivedEvent) error
+}
+
+// Secuencia de los eventos trip.*
+//
+// Cada trip.created/updated/cancelled/deleted lleva en "sequence" el domain.Trip.EventSequence
+// del estado que describe. La secuencia se incrementa en la misma escritura que cambia el viaje
+// (no al publicar), así dos escrituras concurrentes publican en el orden en que se aplicaron aunque
+// los eventos salgan en otro orden. RabbitMQ no garantiza el orden entre reintentos, redeliveries
+// ni consumers concurrentes, así que los consumers que mantienen estado del viaje guardan la última
+// secuencia aplicada y descartan los eventos con una secuencia menor o igual
+
 type publisher struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
+	archive EventArchive
 }
 
 // NewPublisher crea una nueva instancia del publisher de RabbitMQ
 // Establece conexión y declara el exchange necesario
-func NewPublisher(rabbitURL string) (Publisher, error) {
+// archive puede ser nil (los eventos no se archivan)
+func NewPublisher(rabbitURL string, archive EventArchive) (Publisher, error) {
 	// Conectar a RabbitMQ
 	conn, err := amqp.Dial(rabbitURL)
 	if err != nil {
@@ -85,40 +115,79 @@ func NewPublisher(rabbitURL string) (Publisher, error) {
 	return &publisher{
 		conn:    conn,
 		channel: ch,
+		archive: archive,
 	}, nil
 }
 
 // PublishTripCreated publica un evento trip.created
-func (p *publisher) PublishTripCreated(ctx context.Context, trip *domain.Trip) {
-	event := TripEvent{
-		EventID:        uuid.New().String(),
-		EventType:      routingKeyTripCreated,
-		TripID:         trip.ID.Hex(),
-		DriverID:       trip.DriverID,
-		Status:         trip.Status,
-		AvailableSeats: trip.AvailableSeats,
-		ReservedSeats:  trip.ReservedSeats,
-		Timestamp:      time.Now(),
-		SourceService:  sourceService,
-		CorrelationID:  getCorrelationID(ctx),
+// driver puede ser nil: en ese caso search-api obtiene el conductor desde users-api
+func (p *publisher) PublishTripCreated(ctx context.Context, trip *domain.Trip, driver *DriverSnapshot) {
+	p.publish(ctx, routingKeyTripCreated, newTripCreatedEvent(ctx, trip, driver))
+}
+
+// PublishTripsCreated publica los trip.created de un lote de viajes, uno por viaje
+func (p *publisher) PublishTripsCreated(ctx context.Context, trips []*domain.Trip, driver *DriverSnapshot) {
+	for _, trip := range trips {
+		p.PublishTripCreated(ctx, trip, driver)
 	}
+}
 
-	p.publish(ctx, routingKeyTripCreated, event)
+// newTripCreatedEvent arma el evento trip.created (compartido con el outbox)
+func newTripCreatedEvent(ctx context.Context, trip *domain.Trip, driver *DriverSnapshot) TripCreatedEvent {
+	return TripCreatedEvent{
+		TripEvent: TripEvent{
+			EventID:        uuid.New().String(),
+			EventType:      routingKeyTripCreated,
+			TripID:         trip.ID.Hex(),
+			Sequence:       trip.EventSequence,
+			DriverID:       trip.DriverID,
+			Status:         trip.Status,
+			AvailableSeats: trip.AvailableSeats,
+			ReservedSeats:  trip.ReservedSeats,
+			Timestamp:      time.Now(),
+			SourceService:  sourceService,
+			CorrelationID:  getCorrelationID(ctx),
+
+			OriginCityID:      trip.Origin.CityID,
+			DestinationCityID: trip.Destination.CityID,
+			RouteID:           trip.RouteID,
+		},
+		Driver:          driver,
+		DescriptionText: trip.DescriptionText,
+		PickupPoints:    trip.PickupPoints,
+	}
 }
 
 // PublishTripUpdated publica un evento trip.updated
+// Incluye el precio anterior al último cambio (ver domain.Trip.PreviousPricePerSeat)
 func (p *publisher) PublishTripUpdated(ctx context.Context, trip *domain.Trip) {
-	event := TripEvent{
-		EventID:        uuid.New().String(),
-		EventType:      routingKeyTripUpdated,
-		TripID:         trip.ID.Hex(),
-		DriverID:       trip.DriverID,
-		Status:         trip.Status,
-		AvailableSeats: trip.AvailableSeats,
-		ReservedSeats:  trip.ReservedSeats,
-		Timestamp:      time.Now(),
-		SourceService:  sourceService,
-		CorrelationID:  getCorrelationID(ctx),
+	event := TripUpdatedEvent{
+		TripEvent: TripEvent{
+			EventID:        uuid.New().String(),
+			EventType:      routingKeyTripUpdated,
+			TripID:         trip.ID.Hex(),
+			Sequence:       trip.EventSequence,
+			DriverID:       trip.DriverID,
+			Status:         trip.Status,
+			AvailableSeats: trip.AvailableSeats,
+			ReservedSeats:  trip.ReservedSeats,
+			Timestamp:      time.Now(),
+			SourceService:  sourceService,
+			CorrelationID:  getCorrelationID(ctx),
+
+			OriginCityID:      trip.Origin.CityID,
+			DestinationCityID: trip.Destination.CityID,
+			RouteID:           trip.RouteID,
+		},
+		PricePerSeat:         trip.PricePerSeat,
+		Currency:             trip.Currency,
+		PreviousPricePerSeat: trip.PreviousPricePerSeat,
+		PriceChangedAt:       trip.PriceChangedAt,
+		DescriptionText:      trip.DescriptionText,
+		PickupPoints:         trip.PickupPoints,
+	}
+	if event.PickupPoints == nil {
+		event.PickupPoints = []domain.PickupPoint{}
 	}
 
 	p.publish(ctx, routingKeyTripUpdated, event)
@@ -131,6 +200,7 @@ func (p *publisher) PublishTripCancelled(ctx context.Context, trip *domain.Trip,
 			EventID:        uuid.New().String(),
 			EventType:      routingKeyTripCancelled,
 			TripID:         trip.ID.Hex(),
+			Sequence:       trip.EventSequence,
 			DriverID:       trip.DriverID,
 			Status:         trip.Status,
 			AvailableSeats: trip.AvailableSeats,
@@ -153,6 +223,7 @@ func (p *publisher) PublishTripDeleted(ctx context.Context, trip *domain.Trip, d
 			EventID:        uuid.New().String(),
 			EventType:      routingKeyTripDeleted,
 			TripID:         trip.ID.Hex(),
+			Sequence:       trip.EventSequence + 1, // El documento ya no existe: la secuencia sigue a la del último estado leído
 			DriverID:       trip.DriverID,
 			Status:         trip.Status,
 			AvailableSeats: trip.AvailableSeats,
@@ -205,6 +276,49 @@ func (p *publisher) PublishReservationConfirmation(ctx context.Context, reservat
 	p.publish(ctx, routingKeyReservationConfirmed, event)
 }
 
+// PublishReservationModificationFailure publica un evento de compensación cuando no se puede
+// aplicar el aumento de asientos pedido en un reservation.modified
+func (p *publisher) PublishReservationModificationFailure(ctx context.Context, modified ReservationModifiedEvent, reason string, availableSeats int) {
+	event := ReservationModificationFailedEvent{
+		EventID:             uuid.New().String(),
+		EventType:           routingKeyReservationModificationFailed,
+		ModificationEventID: modified.EventID,
+		ReservationID:       modified.ReservationID,
+		TripID:              modified.TripID,
+		PreviousSeats:       modified.PreviousSeats,
+		RequestedSeats:      modified.NewSeats,
+		Reason:              reason,
+		AvailableSeats:      availableSeats,
+		SourceService:       sourceService,
+		CorrelationID:       getCorrelationID(ctx),
+		Timestamp:           time.Now(),
+	}
+
+	p.publish(ctx, routingKeyReservationModificationFailed, event)
+}
+
+// PublishSeatDriftAlert publica una alerta cuando los contadores de asientos de un viaje no cuadran
+func (p *publisher) PublishSeatDriftAlert(ctx context.Context, drift *domain.SeatDrift) {
+	event := SeatDriftAlertEvent{
+		EventID:        uuid.New().String(),
+		EventType:      routingKeySeatDriftAlert,
+		TripID:         drift.TripID,
+		DriverID:       drift.DriverID,
+		Status:         drift.Status,
+		TotalSeats:     drift.TotalSeats,
+		ReservedSeats:  drift.ReservedSeats,
+		AvailableSeats: drift.AvailableSeats,
+		HeldSeats:      drift.HeldSeats,
+		LedgerSeats:    drift.LedgerSeats,
+		Reasons:        drift.Reasons,
+		Repaired:       drift.Repaired,
+		SourceService:  sourceService,
+		Timestamp:      drift.DetectedAt,
+	}
+
+	p.publish(ctx, routingKeySeatDriftAlert, event)
+}
+
 // publish es el método interno que serializa y publica eventos a RabbitMQ
 // Implementa estrategia fire-and-forget: registra errores pero no los propaga
 func (p *publisher) publish(ctx context.Context, routingKey string, event interface{}) {
@@ -218,53 +332,118 @@ func (p *publisher) publish(ctx context.Context, routingKey string, event interf
 		return
 	}
 
+	if err := p.PublishMessage(ctx, routingKey, body); err != nil {
+		// Fire-and-forget: registrar error pero no fallar la operación
+		log.Error().
+			Err(err).
+			Str("routing_key", routingKey).
+			Str("exchange", exchangeName).
+			RawJSON("event", body).
+			Msg("Failed to publish event to RabbitMQ")
+		return
+	}
+
+	// Log exitoso
+	log.Info().
+		Str("routing_key", routingKey).
+		Str("exchange", exchangeName).
+		RawJSON("event", body).
+		Msg("Event published successfully to RabbitMQ")
+}
+
+// PublishMessage archiva y publica un evento ya serializado, retornando el error de publicación
+// El archivo es idempotente por event_id, así que reintentar el mismo body no duplica entradas
+func (p *publisher) PublishMessage(ctx context.Context, routingKey string, body []byte) error {
+	// Archivar antes de publicar: si el broker falla el evento igual queda en el archivo,
+	// que es la fuente de las reconstrucciones de los read models
+	p.archiveEvent(ctx, routingKey, body)
+
+	// El traceparent viaja en los headers para que el consumer continúe la traza
+	ctx, span, headers := tracing.StartPublish(ctx, exchangeName, routingKey)
+
 	// Publicar mensaje con confirmación de contexto
-	err = p.channel.PublishWithContext(
+	err := p.channel.PublishWithContext(
 		ctx,
 		exchangeName, // exchange
 		routingKey,   // routing key
 		false,        // mandatory
 		false,        // immediate
 		amqp.Publishing{
+			Headers:      headers,
 			DeliveryMode: amqp.Persistent, // 2 = persistent (sobrevive a reinicio)
 			ContentType:  "application/json",
 			Body:         body,
 			Timestamp:    time.Now(),
 		},
 	)
+	metrics.ObservePublish(routingKey, err)
+	tracing.End(span, err)
+	return err
+}
 
-	if err != nil {
-		// Fire-and-forget: registrar error pero no fallar la operación
+// archiveEvent agrega el evento al archivo inmutable con su body JSON exacto
+// Un error al archivar se registra pero no impide la publicación (fire-and-forget)
+func (p *publisher) archiveEvent(ctx context.Context, routingKey string, body []byte) {
+	if p.archive == nil {
+		return
+	}
+
+	var envelope struct {
+		EventID   string `json:"event_id"`
+		EventType string `json:"event_type"`
+		TripID    string `json:"trip_id"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
 		log.Error().
 			Err(err).
 			Str("routing_key", routingKey).
-			Str("exchange", exchangeName).
+			Msg("Failed to read event envelope for archive")
+		return
+	}
+
+	archived := &domain.ArchivedEvent{
+		EventID:    envelope.EventID,
+		EventType:  envelope.EventType,
+		RoutingKey: routingKey,
+		Exchange:   exchangeName,
+		TripID:     envelope.TripID,
+		Payload:    string(body),
+	}
+	if err := p.archive.Append(ctx, archived); err != nil {
+		log.Error().
+			Err(err).
+			Str("routing_key", routingKey).
+			Str("event_id", envelope.EventID).
 			RawJSON("event", body).
-			Msg("Failed to publish event to RabbitMQ")
+			Msg("Failed to archive event")
 		return
 	}
 
-	// Log exitoso
-	log.Info().
-		Str("routing_key", routingKey).
-		Str("exchange", exchangeName).
-		RawJSON("event", body).
-		Msg("Event published successfully to RabbitMQ")
+	log.Debug().
+		Str("event_id", envelope.EventID).
+		Int64("sequence", archived.Sequence).
+		Msg("Event archived")
 }
 
 // PublishChatMessage publishes a chat message event to RabbitMQ
 // Used for analytics, notifications, and other async processing
-func (p *publisher) PublishChatMessage(tripID string, userID int64, message string) error {
+// driver_id and passenger_ids (confirmed reservations) are the participants a mention can notify
+func (p *publisher) PublishChatMessage(tripID string, userID int64, message string, driverID int64, passengerIDs []int64) error {
 	ctx := context.Background()
 
+	if passengerIDs == nil {
+		passengerIDs = []int64{}
+	}
 	event := map[string]interface{}{
-		"event_id":   uuid.New().String(),
-		"event_type": "chat.message",
-		"trip_id":    tripID,
-		"user_id":    userID,
-		"message":    message,
-		"timestamp":  time.Now(),
-		"source":     sourceService,
+		"event_id":      uuid.New().String(),
+		"event_type":    "chat.message",
+		"trip_id":       tripID,
+		"user_id":       userID,
+		"message":       message,
+		"driver_id":     driverID,
+		"passenger_ids": passengerIDs,
+		"timestamp":     time.Now(),
+		"source":        sourceService,
 	}
 
 	body, err := json.Marshal(event)
@@ -273,6 +452,7 @@ func (p *publisher) PublishChatMessage(tripID string, userID int64, message stri
 		return err
 	}
 
+	ctx, span, headers := tracing.StartPublish(ctx, exchangeName, "chat.message")
 	err = p.channel.PublishWithContext(
 		ctx,
 		exchangeName,  // exchange
@@ -280,6 +460,7 @@ func (p *publisher) PublishChatMessage(tripID string, userID int64, message stri
 		false,         // mandatory
 		false,         // immediate
 		amqp.Publishing{
+			Headers:       headers,
 			ContentType:   "application/json",
 			Body:          body,
 			DeliveryMode:  amqp.Persistent,
@@ -287,6 +468,8 @@ func (p *publisher) PublishChatMessage(tripID string, userID int64, message stri
 			CorrelationId: getCorrelationID(ctx),
 		},
 	)
+	metrics.ObservePublish("chat.message", err)
+	tracing.End(span, err)
 
 	if err != nil {
 		log.Error().
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Métricas Prometheus del servicio, expuestas en GET /metrics
+// Los nombres son los mismos en los cuatro servicios: Prometheus los distingue por el job
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duración de las requests HTTP por ruta",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	rabbitPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_messages_published_total",
+		Help: "Eventos publicados en RabbitMQ por routing key y resultado",
+	}, []string{"routing_key", "result"})
+
+	rabbitConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_messages_consumed_total",
+		Help: "Eventos consumidos de RabbitMQ por routing key y resultado (ack/nack)",
+	}, []string{"routing_key", "result"})
+
+	dbCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_call_duration_seconds",
+		Help:    "Latencia de los comandos de MongoDB por operación",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	dbCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_call_errors_total",
+		Help: "Comandos de MongoDB fallidos por operación",
+	}, []string{"operation"})
+
+	outboxFailed = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_failed_messages",
+		Help: "Mensajes del outbox que agotaron los reintentos y esperan ser reencolados",
+	})
+)
+
+// unmatchedRoute agrupa las requests que no coinciden con ninguna ruta (evita una serie por path)
+const unmatchedRoute = "unmatched"
+
+// Middleware registra la duración de cada request con el template de la ruta (/trips/:id),
+// nunca el path concreto, para no crear una serie por viaje
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		httpRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler expone las métricas en formato Prometheus (promhttp)
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// ObservePublish cuenta un evento publicado en RabbitMQ (result: ok / error)
+func ObservePublish(routingKey string, err error) {
+	rabbitPublished.WithLabelValues(routingKey, result(err, "ok", "error")).Inc()
+}
+
+// ObserveConsume cuenta un evento consumido de RabbitMQ (result: ack / nack)
+func ObserveConsume(routingKey string, err error) {
+	rabbitConsumed.WithLabelValues(routingKey, result(err, "ack", "nack")).Inc()
+}
+
+// SetOutboxFailed registra cuántos mensajes del outbox están en failed (alertar si es > 0)
+func SetOutboxFailed(count int64) {
+	outboxFailed.Set(float64(count))
+}
+
+func result(err error, ok, failed string) string {
+	if err != nil {
+		return failed
+	}
+	return ok
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"errors"
+	"net/http"
 	"strings"
+	"trips-api/internal/domain"
 	"trips-api/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -9,7 +12,8 @@ import (
 )
 
 // AuthMiddleware valida el token JWT y extrae los claims al contexto
-func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+// Además del token valida la cuenta en users-api (sesiones revocadas, desactivada o suspendida)
+func AuthMiddleware(authService service.AuthService, sessionService service.SessionService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Obtener el header Authorization
 		authHeader := c.GetHeader("Authorization")
@@ -56,6 +60,28 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 			if name, ok := claims["name"].(string); ok {
 				c.Set("user_name", name)
 			}
+			// Restricciones de cuentas dependientes (claim restrictions de users-api, ver RejectRestriction)
+			if restrictions, ok := claims["restrictions"].([]interface{}); ok {
+				values := make([]string, 0, len(restrictions))
+				for _, restriction := range restrictions {
+					if value, ok := restriction.(string); ok {
+						values = append(values, value)
+					}
+				}
+				c.Set("restrictions", values)
+			}
+
+			// Los tokens emitidos antes de agregar el claim iat cuentan como emitidos en el epoch
+			issuedAt, _ := claims["iat"].(float64)
+			if err := sessionService.CheckSession(c.Request.Context(), c.GetInt64("user_id"), int64(issuedAt)); err != nil {
+				status, message := sessionErrorResponse(err)
+				c.JSON(status, gin.H{
+					"success": false,
+					"error":   message,
+				})
+				c.Abort()
+				return
+			}
 		} else {
 			c.JSON(401, gin.H{
 				"success": false,
@@ -68,3 +94,17 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// sessionErrorResponse traduce el error de SessionService al status y mensaje que usa users-api
+func sessionErrorResponse(err error) (int, string) {
+	switch {
+	case errors.Is(err, domain.ErrSessionRevoked):
+		return http.StatusUnauthorized, "la sesión fue cerrada, inicia sesión nuevamente"
+	case errors.Is(err, domain.ErrAccountInactive):
+		return http.StatusForbidden, "la cuenta está desactivada"
+	case errors.Is(err, domain.ErrAccountSuspended):
+		return http.StatusForbidden, "la cuenta está suspendida"
+	default:
+		return http.StatusServiceUnavailable, "no se pudo verificar la sesión, intenta nuevamente"
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InternalSecretHeader es el header con el secreto compartido de las llamadas entre servicios
+const InternalSecretHeader = "X-Internal-Secret"
+
+// RequireInternalSecret valida que la llamada venga de otro servicio (bookings-api) con el secreto compartido
+// Sin secreto configurado las rutas quedan deshabilitadas (503): el puerto está publicado y no hay gateway delante
+func RequireInternalSecret(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if secret == "" {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"success": false,
+				"error":   "rutas internas no configuradas (INTERNAL_API_SECRET)",
+			})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader(InternalSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "secreto interno inválido",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
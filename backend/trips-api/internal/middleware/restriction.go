@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RestrictionNoDriving es la restricción de las cuentas dependientes que no pueden publicar ni editar viajes
+const RestrictionNoDriving = "no_driving"
+
+// RejectRestriction rechaza con 403 a los usuarios cuyo token trae la restricción dada (claim restrictions)
+// Debe ir después de AuthMiddleware
+func RejectRestriction(restriction, message string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, value := range c.GetStringSlice("restrictions") {
+			if value == restriction {
+				c.JSON(http.StatusForbidden, gin.H{
+					"success": false,
+					"error":   message,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
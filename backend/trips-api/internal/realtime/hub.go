@@ -0,0 +1,191 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Tipos de eventos en tiempo real
+const (
+	EventChatMessage = "chat.message" // Nuevo mensaje en el chat del viaje
+	EventTripStatus  = "trip.status"  // Cambio de estado / disponibilidad del viaje
+)
+
+// Configuración por defecto del hub
+const (
+	DefaultHistorySize   = 100              // Eventos recientes guardados por viaje para reconexión
+	DefaultHistoryTTL    = 10 * time.Minute // Tiempo que se conserva el historial de un viaje sin actividad
+	subscriberBufferSize = 32               // Eventos pendientes por suscriptor antes de desconectarlo
+)
+
+// Event es un evento en tiempo real de un viaje
+//
+// El ID es un ObjectID hexadecimal (ordenable por tiempo), de modo que los clientes
+// pueden reconectarse enviando el último ID recibido (Last-Event-ID).
+type Event struct {
+	ID     string      `json:"id"`
+	Type   string      `json:"type"`
+	TripID string      `json:"trip_id"`
+	Data   interface{} `json:"data"`
+}
+
+// Subscription es la suscripción de un cliente (SSE o WebSocket) a los eventos de un viaje
+// El canal Events se cierra al desuscribirse o si el cliente no consume a tiempo
+type Subscription struct {
+	TripID string
+	Events chan Event
+}
+
+// tripHistory guarda los últimos eventos de un viaje
+type tripHistory struct {
+	events       []Event
+	lastActivity time.Time
+}
+
+// Hub es el pub/sub interno por viaje compartido por los transportes en tiempo real
+// (SSE y WebSocket). Es en memoria: cada instancia del servicio tiene su propio hub.
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*Subscription]struct{}
+	history     map[string]*tripHistory
+	historySize int
+	historyTTL  time.Duration
+	lastPrune   time.Time
+}
+
+// NewHub crea un nuevo hub que guarda hasta historySize eventos por viaje
+func NewHub(historySize int) *Hub {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Hub{
+		subscribers: make(map[string]map[*Subscription]struct{}),
+		history:     make(map[string]*tripHistory),
+		historySize: historySize,
+		historyTTL:  DefaultHistoryTTL,
+	}
+}
+
+// Subscribe registra un nuevo suscriptor a los eventos de un viaje
+func (h *Hub) Subscribe(tripID string) *Subscription {
+	sub := &Subscription{
+		TripID: tripID,
+		Events: make(chan Event, subscriberBufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[tripID] == nil {
+		h.subscribers[tripID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[tripID][sub] = struct{}{}
+
+	return sub
+}
+
+// Unsubscribe elimina un suscriptor y cierra su canal (es seguro llamarlo más de una vez)
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.removeLocked(sub)
+}
+
+// Publish envía un evento a todos los suscriptores del viaje y lo guarda en el historial
+//
+// El envío nunca bloquea: si un suscriptor tiene el buffer lleno se lo desconecta
+// y el cliente debe reconectarse con Last-Event-ID.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	hist := h.history[event.TripID]
+	if hist == nil {
+		hist = &tripHistory{}
+		h.history[event.TripID] = hist
+	}
+	hist.events = append(hist.events, event)
+	if len(hist.events) > h.historySize {
+		hist.events = hist.events[len(hist.events)-h.historySize:]
+	}
+	hist.lastActivity = now
+
+	for sub := range h.subscribers[event.TripID] {
+		select {
+		case sub.Events <- event:
+		default:
+			log.Warn().
+				Str("trip_id", event.TripID).
+				Msg("Realtime subscriber too slow, disconnecting")
+			h.removeLocked(sub)
+		}
+	}
+
+	h.pruneLocked(now)
+}
+
+// EventsSince devuelve los eventos del historial de un viaje posteriores a lastEventID
+// Con lastEventID vacío devuelve nil (no hay nada que reenviar)
+func (h *Hub) EventsSince(tripID, lastEventID string) []Event {
+	if lastEventID == "" {
+		return nil
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	hist := h.history[tripID]
+	if hist == nil {
+		return nil
+	}
+
+	var events []Event
+	for _, event := range hist.events {
+		// Los IDs son ObjectIDs en hexadecimal: el orden lexicográfico respeta el orden temporal
+		if event.ID > lastEventID {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// SubscriberCount devuelve la cantidad de suscriptores activos de un viaje
+func (h *Hub) SubscriberCount(tripID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subscribers[tripID])
+}
+
+// removeLocked elimina un suscriptor; requiere h.mu tomado
+func (h *Hub) removeLocked(sub *Subscription) {
+	subs := h.subscribers[sub.TripID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.Events)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.TripID)
+	}
+}
+
+// pruneLocked descarta (como mucho una vez por minuto) el historial de viajes sin actividad reciente
+// Requiere h.mu tomado
+func (h *Hub) pruneLocked(now time.Time) {
+	if now.Sub(h.lastPrune) < time.Minute {
+		return
+	}
+	h.lastPrune = now
+
+	for tripID, hist := range h.history {
+		if now.Sub(hist.lastActivity) > h.historyTTL && len(h.subscribers[tripID]) == 0 {
+			delete(h.history, tripID)
+		}
+	}
+}
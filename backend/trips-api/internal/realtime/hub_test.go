@@ -0,0 +1,126 @@
+package realtime
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func event(id, tripID string) Event {
+	return Event{ID: id, Type: EventChatMessage, TripID: tripID, Data: id}
+}
+
+func TestHub_SubscribeAndUnsubscribe(t *testing.T) {
+	hub := NewHub(10)
+
+	first := hub.Subscribe("trip-1")
+	second := hub.Subscribe("trip-1")
+	assert.Equal(t, 2, hub.SubscriberCount("trip-1"))
+	assert.Equal(t, 0, hub.SubscriberCount("trip-2"))
+
+	hub.Unsubscribe(first)
+	assert.Equal(t, 1, hub.SubscriberCount("trip-1"))
+	_, open := <-first.Events
+	assert.False(t, open, "el canal se cierra al desuscribirse")
+
+	// Desuscribir dos veces no entra en pánico por cerrar un canal cerrado
+	assert.NotPanics(t, func() { hub.Unsubscribe(first) })
+
+	hub.Unsubscribe(second)
+	assert.Equal(t, 0, hub.SubscriberCount("trip-1"))
+}
+
+func TestHub_PublishReachesOnlyTheTripSubscribers(t *testing.T) {
+	hub := NewHub(10)
+	a1 := hub.Subscribe("trip-a")
+	a2 := hub.Subscribe("trip-a")
+	b := hub.Subscribe("trip-b")
+
+	hub.Publish(event("0001", "trip-a"))
+
+	for _, sub := range []*Subscription{a1, a2} {
+		select {
+		case got := <-sub.Events:
+			assert.Equal(t, "0001", got.ID)
+		default:
+			t.Fatal("un suscriptor del viaje no recibió el evento")
+		}
+	}
+	select {
+	case got := <-b.Events:
+		t.Fatalf("el suscriptor de otro viaje recibió %+v", got)
+	default:
+	}
+}
+
+func TestHub_PublishAfterUnsubscribeIsNotDelivered(t *testing.T) {
+	hub := NewHub(10)
+	sub := hub.Subscribe("trip-1")
+	hub.Unsubscribe(sub)
+
+	assert.NotPanics(t, func() { hub.Publish(event("0001", "trip-1")) })
+	_, open := <-sub.Events
+	assert.False(t, open)
+}
+
+func TestHub_DisconnectsSlowSubscribers(t *testing.T) {
+	hub := NewHub(100)
+	slow := hub.Subscribe("trip-1")
+	fast := hub.Subscribe("trip-1")
+
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		hub.Publish(event(fmt.Sprintf("%04d", i), "trip-1"))
+		<-fast.Events
+	}
+
+	assert.Equal(t, 1, hub.SubscriberCount("trip-1"), "solo queda el suscriptor que consume")
+
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	assert.Equal(t, subscriberBufferSize, received, "el lento recibe lo que entraba en su buffer y se le cierra el canal")
+}
+
+func TestHub_EventsSinceReplaysTheHistory(t *testing.T) {
+	hub := NewHub(3)
+	for i := 1; i <= 5; i++ {
+		hub.Publish(event(fmt.Sprintf("%04d", i), "trip-1"))
+	}
+	hub.Publish(event("0009", "trip-2"))
+
+	assert.Nil(t, hub.EventsSince("trip-1", ""), "sin Last-Event-ID no se reenvía nada")
+
+	// El historial guarda solo los últimos 3 eventos del viaje
+	replayed := hub.EventsSince("trip-1", "0000")
+	require.Len(t, replayed, 3)
+	assert.Equal(t, "0003", replayed[0].ID)
+
+	replayed = hub.EventsSince("trip-1", "0004")
+	require.Len(t, replayed, 1)
+	assert.Equal(t, "0005", replayed[0].ID)
+
+	assert.Empty(t, hub.EventsSince("trip-1", "0005"))
+	assert.Nil(t, hub.EventsSince("trip-3", "0000"))
+}
+
+func TestHub_PrunesIdleHistoryWithoutSubscribers(t *testing.T) {
+	hub := NewHub(10)
+	hub.Publish(event("0001", "idle"))
+	hub.Publish(event("0001", "watched"))
+	hub.Subscribe("watched")
+
+	hub.mu.Lock()
+	for _, hist := range hub.history {
+		hist.lastActivity = time.Now().Add(-2 * DefaultHistoryTTL)
+	}
+	hub.lastPrune = time.Time{}
+	hub.pruneLocked(time.Now())
+	hub.mu.Unlock()
+
+	assert.Nil(t, hub.EventsSince("idle", "0000"), "historial de un viaje inactivo descartado")
+	assert.Len(t, hub.EventsSince("watched", "0000"), 1, "un viaje con suscriptores conserva el historial")
+}
@@ -0,0 +1,66 @@
+package realtime
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"trips-api/internal/domain"
+	"trips-api/internal/messaging"
+)
+
+// TripStatusData es el payload de los eventos trip.status
+type TripStatusData struct {
+	Status             string `json:"status"`
+	AvailableSeats     int    `json:"available_seats"`
+	ReservedSeats      int    `json:"reserved_seats"`
+	CancellationReason string `json:"cancellation_reason,omitempty"`
+}
+
+// hubPublisher decora el publisher de RabbitMQ para replicar los cambios de estado
+// de los viajes en el hub en tiempo real (clientes SSE / WebSocket)
+type hubPublisher struct {
+	messaging.Publisher
+	hub *Hub
+}
+
+// NewHubPublisher envuelve un publisher para que cada trip.updated / trip.cancelled /
+// trip.deleted también se publique como evento trip.status en el hub
+func NewHubPublisher(inner messaging.Publisher, hub *Hub) messaging.Publisher {
+	return &hubPublisher{
+		Publisher: inner,
+		hub:       hub,
+	}
+}
+
+// PublishTripUpdated publica el evento en RabbitMQ y el nuevo estado en el hub
+func (p *hubPublisher) PublishTripUpdated(ctx context.Context, trip *domain.Trip) {
+	p.Publisher.PublishTripUpdated(ctx, trip)
+	p.publishStatus(trip)
+}
+
+// PublishTripCancelled publica el evento en RabbitMQ y el nuevo estado en el hub
+func (p *hubPublisher) PublishTripCancelled(ctx context.Context, trip *domain.Trip, cancelledBy int64, reason string) {
+	p.Publisher.PublishTripCancelled(ctx, trip, cancelledBy, reason)
+	p.publishStatus(trip)
+}
+
+// PublishTripDeleted publica el evento en RabbitMQ y el nuevo estado en el hub
+func (p *hubPublisher) PublishTripDeleted(ctx context.Context, trip *domain.Trip, deletedBy int64, reason string) {
+	p.Publisher.PublishTripDeleted(ctx, trip, deletedBy, reason)
+	p.publishStatus(trip)
+}
+
+func (p *hubPublisher) publishStatus(trip *domain.Trip) {
+	p.hub.Publish(Event{
+		ID:     primitive.NewObjectID().Hex(),
+		Type:   EventTripStatus,
+		TripID: trip.ID.Hex(),
+		Data: TripStatusData{
+			Status:             trip.Status,
+			AvailableSeats:     trip.AvailableSeats,
+			ReservedSeats:      trip.ReservedSeats,
+			CancellationReason: trip.CancellationReason,
+		},
+	})
+}
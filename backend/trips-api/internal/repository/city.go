@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CityRepository define las operaciones de acceso a datos del catálogo de ciudades y rutas
+type CityRepository interface {
+	// Seed inserta las ciudades que todavía no existen (por ID) y retorna cuántas insertó
+	// Las ciudades existentes no se modifican: los cambios hechos en MongoDB se conservan
+	Seed(ctx context.Context, cities []domain.City) (int64, error)
+
+	// List retorna todas las ciudades del catálogo
+	List(ctx context.Context) ([]domain.City, error)
+
+	// RecordRoute registra un viaje en la ruta origen-destino, creándola si es el primero
+	RecordRoute(ctx context.Context, route domain.InterCityRoute) error
+}
+
+type cityRepository struct {
+	cities *mongo.Collection
+	routes *mongo.Collection
+}
+
+// NewCityRepository crea una nueva instancia del repositorio del catálogo de ciudades
+func NewCityRepository(db *mongo.Database) CityRepository {
+	return &cityRepository{
+		cities: db.Collection("cities"),
+		routes: db.Collection("routes"),
+	}
+}
+
+// Seed usa upserts con $setOnInsert en un solo bulk write
+func (r *cityRepository) Seed(ctx context.Context, cities []domain.City) (int64, error) {
+	if len(cities) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, 0, len(cities))
+	for _, city := range cities {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": city.ID}).
+			SetUpdate(bson.M{"$setOnInsert": city}).
+			SetUpsert(true))
+	}
+
+	result, err := r.cities.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, fmt.Errorf("failed to seed cities: %w", err)
+	}
+
+	return result.UpsertedCount, nil
+}
+
+// List retorna las ciudades ordenadas por ID
+func (r *cityRepository) List(ctx context.Context) ([]domain.City, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.cities.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cities: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	cities := []domain.City{}
+	if err := cursor.All(ctx, &cities); err != nil {
+		return nil, fmt.Errorf("failed to decode cities: %w", err)
+	}
+
+	return cities, nil
+}
+
+// RecordRoute incrementa trip_count y last_trip_at de la ruta en una sola operación atómica
+func (r *cityRepository) RecordRoute(ctx context.Context, route domain.InterCityRoute) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"origin_city_id":      route.OriginCityID,
+			"destination_city_id": route.DestinationCityID,
+			"distance_km":         route.DistanceKm,
+			"first_trip_at":       route.LastTripAt,
+		},
+		"$inc": bson.M{"trip_count": 1},
+		// $max: un viaje recurrente materializado tarde no mueve hacia atrás el último viaje
+		"$max": bson.M{"last_trip_at": route.LastTripAt},
+	}
+
+	_, err := r.routes.UpdateOne(ctx, bson.M{"_id": route.ID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to record route: %w", err)
+	}
+
+	return nil
+}
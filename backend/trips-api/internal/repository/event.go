@@ -14,6 +14,7 @@ import (
 type EventRepository interface {
 	IsEventProcessed(ctx context.Context, eventID string) (bool, error)
 	MarkEventProcessed(ctx context.Context, event *domain.ProcessedEvent) error
+	UnmarkEventProcessed(ctx context.Context, eventID string) error
 }
 
 type eventRepository struct {
@@ -74,3 +75,16 @@ func (r *eventRepository) MarkEventProcessed(ctx context.Context, event *domain.
 
 	return nil
 }
+
+// UnmarkEventProcessed borra la marca de un evento cuyo procesamiento falló
+// Sin esto la reentrega de un mensaje con NACK se saltaría como "ya procesado"
+func (r *eventRepository) UnmarkEventProcessed(ctx context.Context, eventID string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"event_id": eventID}); err != nil {
+		return fmt.Errorf("failed to unmark event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// eventArchiveCounterID es el _id del documento de counters que lleva la última secuencia asignada
+const eventArchiveCounterID = "event_archive"
+
+// EventArchiveRepository define las operaciones sobre el archivo inmutable de eventos
+// No expone Update ni Delete: el archivo es append-only
+type EventArchiveRepository interface {
+	Append(ctx context.Context, event *domain.ArchivedEvent) error
+	FindFromSequence(ctx context.Context, fromSequence int64, limit int64) ([]domain.ArchivedEvent, error)
+	LastSequence(ctx context.Context) (int64, error)
+}
+
+type eventArchiveRepository struct {
+	collection *mongo.Collection
+	counters   *mongo.Collection
+}
+
+// NewEventArchiveRepository crea una nueva instancia del repositorio del archivo de eventos
+func NewEventArchiveRepository(db *mongo.Database) EventArchiveRepository {
+	return &eventArchiveRepository{
+		collection: db.Collection("event_archive"),
+		counters:   db.Collection("counters"),
+	}
+}
+
+// Append asigna la siguiente secuencia (incremento atómico en counters) e inserta el evento
+// Si el event_id ya está archivado no se inserta de nuevo (índice UNIQUE)
+// Asignación e insert no son atómicos: el timeout de 5 segundos acota cuánto puede quedar abierto
+// un hueco antes de que el evento sea visible (los lectores esperan ese margen)
+func (r *eventArchiveRepository) Append(ctx context.Context, event *domain.ArchivedEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sequence, err := r.nextSequence(ctx)
+	if err != nil {
+		return err
+	}
+
+	event.ID = primitive.NewObjectID()
+	event.Sequence = sequence
+	event.ArchivedAt = time.Now()
+
+	_, err = r.collection.InsertOne(ctx, event)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to archive event %s: %w", event.EventID, err)
+	}
+
+	return nil
+}
+
+// FindFromSequence retorna hasta limit eventos con secuencia >= fromSequence, ordenados por secuencia
+func (r *eventArchiveRepository) FindFromSequence(ctx context.Context, fromSequence int64, limit int64) ([]domain.ArchivedEvent, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"sequence": bson.M{"$gte": fromSequence}}
+	opts := options.Find().SetSort(bson.D{{Key: "sequence", Value: 1}}).SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event archive: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []domain.ArchivedEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode archived events: %w", err)
+	}
+
+	return events, nil
+}
+
+// LastSequence retorna la última secuencia asignada (0 si el archivo está vacío)
+func (r *eventArchiveRepository) LastSequence(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := r.counters.FindOne(ctx, bson.M{"_id": eventArchiveCounterID}).Decode(&counter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read event archive sequence: %w", err)
+	}
+
+	return counter.Seq, nil
+}
+
+// nextSequence incrementa atómicamente el contador y retorna el nuevo valor
+func (r *eventArchiveRepository) nextSequence(ctx context.Context) (int64, error) {
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := r.counters.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": eventArchiveCounterID},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		opts,
+	).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate event archive sequence: %w", err)
+	}
+
+	return counter.Seq, nil
+}
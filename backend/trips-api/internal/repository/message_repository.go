@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
@@ -15,6 +16,24 @@ import (
 type MessageRepository interface {
 	Create(ctx context.Context, message *dao.Message) error
 	FindByTripID(ctx context.Context, tripID string, limit int) ([]*dao.Message, error)
+	FindAfter(ctx context.Context, tripID string, afterID primitive.ObjectID, limit int) ([]*dao.Message, error)
+	// FindBefore returns up to limit messages before the (before, beforeID) cursor, oldest first
+	// (a zero time means "from the most recent message"; a zero beforeID compares only created_at)
+	FindBefore(ctx context.Context, tripID string, before time.Time, beforeID primitive.ObjectID, limit int) ([]*dao.Message, error)
+	FindByID(ctx context.Context, tripID string, id primitive.ObjectID) (*dao.Message, error)
+	// FindLatest returns the most recent message of a trip (nil if the chat is empty)
+	FindLatest(ctx context.Context, tripID string) (*dao.Message, error)
+	// FindRange returns up to limit messages with afterID < _id <= untilID, in _id order
+	// (a zero afterID means "from the first message")
+	FindRange(ctx context.Context, tripID string, afterID, untilID primitive.ObjectID, limit int) ([]*dao.Message, error)
+	// FindFirstUnanswered returns the oldest message of other users sent after the responder's
+	// previous message and before reply (nil if reply does not answer anyone)
+	FindFirstUnanswered(ctx context.Context, tripID string, responderID int64, reply *dao.Message) (*dao.Message, error)
+	// CountUnread counts the messages of a trip created after readAt, excluding the user's own
+	CountUnread(ctx context.Context, tripID string, userID int64, readAt time.Time) (int64, error)
+	FindReadMarker(ctx context.Context, tripID string, userID int64) (*dao.ChatReadMarker, error)
+	// MarkRead moves the user's read marker forward to readAt (never backwards)
+	MarkRead(ctx context.Context, tripID string, userID int64, readAt time.Time, messageID string) (*dao.ChatReadMarker, error)
 }
 
 type mongoMessageRepository struct {
@@ -30,6 +49,10 @@ func NewMessageRepository(db *mongo.Database) MessageRepository {
 func (r *mongoMessageRepository) Create(ctx context.Context, message *dao.Message) error {
 	collection := r.db.Collection(dao.Message{}.CollectionName())
 	message.CreatedAt = time.Now()
+	// Assign the ID up front so callers (realtime hub, logs) can reference the message
+	if message.ID.IsZero() {
+		message.ID = primitive.NewObjectID()
+	}
 	_, err := collection.InsertOne(ctx, message)
 	return err
 }
@@ -61,3 +84,242 @@ func (r *mongoMessageRepository) FindByTripID(ctx context.Context, tripID string
 
 	return messages, nil
 }
+
+// FindAfter retrieves the messages of a trip created after the given message ID (oldest first)
+// Used to replay missed messages when a realtime client reconnects with Last-Event-ID
+func (r *mongoMessageRepository) FindAfter(ctx context.Context, tripID string, afterID primitive.ObjectID, limit int) ([]*dao.Message, error) {
+	collection := r.db.Collection(dao.Message{}.CollectionName())
+
+	filter := bson.M{
+		"trip_id": tripID,
+		"_id":     bson.M{"$gt": afterID},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}). // Oldest first
+		SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var messages []*dao.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// FindBefore retrieves a page of messages of a trip before a (created_at, _id) cursor (oldest first)
+// The cursor follows the sort order, so messages sharing the boundary millisecond are neither skipped nor repeated
+func (r *mongoMessageRepository) FindBefore(ctx context.Context, tripID string, before time.Time, beforeID primitive.ObjectID, limit int) ([]*dao.Message, error) {
+	collection := r.db.Collection(dao.Message{}.CollectionName())
+
+	filter := bson.M{"trip_id": tripID}
+	switch {
+	case before.IsZero():
+	case beforeID.IsZero():
+		filter["created_at"] = bson.M{"$lt": before}
+	default:
+		filter["$or"] = bson.A{
+			bson.M{"created_at": bson.M{"$lt": before}},
+			bson.M{"created_at": before, "_id": bson.M{"$lt": beforeID}},
+		}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}). // Most recent first
+		SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var messages []*dao.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+
+	// Reverse to show oldest first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+// FindByID retrieves a message of a trip (nil if it does not exist)
+func (r *mongoMessageRepository) FindByID(ctx context.Context, tripID string, id primitive.ObjectID) (*dao.Message, error) {
+	collection := r.db.Collection(dao.Message{}.CollectionName())
+
+	var message dao.Message
+	err := collection.FindOne(ctx, bson.M{"_id": id, "trip_id": tripID}).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// FindLatest retrieves the message of a trip with the highest _id (nil if there are none)
+func (r *mongoMessageRepository) FindLatest(ctx context.Context, tripID string) (*dao.Message, error) {
+	collection := r.db.Collection(dao.Message{}.CollectionName())
+
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	var message dao.Message
+	err := collection.FindOne(ctx, bson.M{"trip_id": tripID}, opts).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// FindRange retrieves a batch of the messages of a trip between two message IDs (oldest first)
+// Used to iterate the whole chat up to a fixed message without skipping or repeating messages
+func (r *mongoMessageRepository) FindRange(ctx context.Context, tripID string, afterID, untilID primitive.ObjectID, limit int) ([]*dao.Message, error) {
+	collection := r.db.Collection(dao.Message{}.CollectionName())
+
+	idRange := bson.M{"$lte": untilID}
+	if !afterID.IsZero() {
+		idRange["$gt"] = afterID
+	}
+	filter := bson.M{
+		"trip_id": tripID,
+		"_id":     idRange,
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var messages []*dao.Message
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// FindFirstUnanswered finds the message a reply answers: the first message of other users
+// since the responder last wrote in the chat
+func (r *mongoMessageRepository) FindFirstUnanswered(ctx context.Context, tripID string, responderID int64, reply *dao.Message) (*dao.Message, error) {
+	collection := r.db.Collection(dao.Message{}.CollectionName())
+
+	// Previous message of the responder (before the reply)
+	previousFilter := bson.M{
+		"trip_id":    tripID,
+		"user_id":    responderID,
+		"_id":        bson.M{"$ne": reply.ID},
+		"created_at": bson.M{"$lte": reply.CreatedAt},
+	}
+	previousOpts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	createdAt := bson.M{"$lte": reply.CreatedAt}
+	var previous dao.Message
+	err := collection.FindOne(ctx, previousFilter, previousOpts).Decode(&previous)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+	if err == nil {
+		createdAt["$gt"] = previous.CreatedAt
+	}
+
+	filter := bson.M{
+		"trip_id":    tripID,
+		"user_id":    bson.M{"$ne": responderID},
+		"created_at": createdAt,
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	var message dao.Message
+	err = collection.FindOne(ctx, filter, opts).Decode(&message)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
+
+// CountUnread counts the messages of other users created after readAt
+func (r *mongoMessageRepository) CountUnread(ctx context.Context, tripID string, userID int64, readAt time.Time) (int64, error) {
+	collection := r.db.Collection(dao.Message{}.CollectionName())
+
+	filter := bson.M{
+		"trip_id": tripID,
+		"user_id": bson.M{"$ne": userID},
+	}
+	if !readAt.IsZero() {
+		filter["created_at"] = bson.M{"$gt": readAt}
+	}
+
+	return collection.CountDocuments(ctx, filter)
+}
+
+// FindReadMarker retrieves the read marker of a user in a trip chat (nil if never read)
+func (r *mongoMessageRepository) FindReadMarker(ctx context.Context, tripID string, userID int64) (*dao.ChatReadMarker, error) {
+	collection := r.db.Collection(dao.ChatReadMarker{}.CollectionName())
+
+	var marker dao.ChatReadMarker
+	err := collection.FindOne(ctx, bson.M{"trip_id": tripID, "user_id": userID}).Decode(&marker)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &marker, nil
+}
+
+// MarkRead upserts the read marker; $max keeps it from moving backwards when
+// an older mark-as-read request arrives after a newer one
+func (r *mongoMessageRepository) MarkRead(ctx context.Context, tripID string, userID int64, readAt time.Time, messageID string) (*dao.ChatReadMarker, error) {
+	collection := r.db.Collection(dao.ChatReadMarker{}.CollectionName())
+
+	filter := bson.M{"trip_id": tripID, "user_id": userID}
+	update := bson.M{
+		"$max": bson.M{"last_read_at": readAt},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var marker dao.ChatReadMarker
+	if err := collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&marker); err != nil {
+		return nil, err
+	}
+
+	// Only record the message ID if this request actually moved the marker
+	if messageID != "" && marker.LastReadAt.Equal(readAt) && marker.LastReadMessageID != messageID {
+		_, err := collection.UpdateOne(ctx,
+			bson.M{"_id": marker.ID, "last_read_at": readAt},
+			bson.M{"$set": bson.M{"last_read_message_id": messageID}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		marker.LastReadMessageID = messageID
+	}
+
+	return &marker, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"trips-api/internal/dao"
+)
+
+// MessageTranslationRepository defines the data access for cached chat message translations
+type MessageTranslationRepository interface {
+	// FindByMessages returns the cached translations of the given messages to language, keyed by message ID
+	FindByMessages(ctx context.Context, messageIDs []primitive.ObjectID, language string) (map[primitive.ObjectID]*dao.MessageTranslation, error)
+	// Save stores a translation; saving the same (message, language) twice keeps the first one
+	Save(ctx context.Context, translation *dao.MessageTranslation) error
+}
+
+type mongoMessageTranslationRepository struct {
+	db *mongo.Database
+}
+
+// NewMessageTranslationRepository creates a new MongoDB message translation repository
+func NewMessageTranslationRepository(db *mongo.Database) MessageTranslationRepository {
+	return &mongoMessageTranslationRepository{db: db}
+}
+
+// FindByMessages returns the cached translations of a page of messages in one query
+func (r *mongoMessageTranslationRepository) FindByMessages(ctx context.Context, messageIDs []primitive.ObjectID, language string) (map[primitive.ObjectID]*dao.MessageTranslation, error) {
+	translations := make(map[primitive.ObjectID]*dao.MessageTranslation, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return translations, nil
+	}
+
+	collection := r.db.Collection(dao.MessageTranslation{}.CollectionName())
+	filter := bson.M{
+		"message_id": bson.M{"$in": messageIDs},
+		"language":   language,
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []*dao.MessageTranslation
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	for _, translation := range found {
+		translations[translation.MessageID] = translation
+	}
+	return translations, nil
+}
+
+// Save upserts a translation with $setOnInsert, so concurrent requests translating the same
+// message do not fail on the unique (message_id, language) index
+func (r *mongoMessageTranslationRepository) Save(ctx context.Context, translation *dao.MessageTranslation) error {
+	collection := r.db.Collection(dao.MessageTranslation{}.CollectionName())
+	if translation.CreatedAt.IsZero() {
+		translation.CreatedAt = time.Now()
+	}
+
+	filter := bson.M{"message_id": translation.MessageID, "language": translation.Language}
+	update := bson.M{"$setOnInsert": bson.M{
+		"message_id":      translation.MessageID,
+		"trip_id":         translation.TripID,
+		"language":        translation.Language,
+		"text":            translation.Text,
+		"source_language": translation.SourceLanguage,
+		"provider":        translation.Provider,
+		"created_at":      translation.CreatedAt,
+	}}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
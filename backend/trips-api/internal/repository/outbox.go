@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutboxRepository define las operaciones sobre el outbox de eventos pendientes de publicar
+type OutboxRepository interface {
+	Insert(ctx context.Context, message *domain.OutboxMessage) error
+	InsertMany(ctx context.Context, messages []*domain.OutboxMessage) error
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxMessage, error)
+	MarkSent(ctx context.Context, id primitive.ObjectID, sentAt time.Time) error
+	MarkRetry(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, lastError string) error
+	CountPending(ctx context.Context) (int64, error)
+	CountFailed(ctx context.Context) (int64, error)
+	RequeueFailed(ctx context.Context, now time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOutboxRepository crea una nueva instancia del repositorio del outbox
+func NewOutboxRepository(db *mongo.Database) OutboxRepository {
+	return &outboxRepository{
+		collection: db.Collection("outbox"),
+	}
+}
+
+// Insert guarda un mensaje pendiente listo para publicar de inmediato
+// Si el event_id ya existe no se inserta de nuevo (índice UNIQUE)
+func (r *outboxRepository) Insert(ctx context.Context, message *domain.OutboxMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	message.ID = primitive.NewObjectID()
+	message.Status = domain.OutboxStatusPending
+	message.CreatedAt = now
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = now
+	}
+
+	_, err := r.collection.InsertOne(ctx, message)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to insert outbox message %s: %w", message.EventID, err)
+	}
+
+	return nil
+}
+
+// InsertMany guarda varios mensajes pendientes con una sola escritura (inserción no ordenada)
+// Los event_id que ya existen se ignoran, igual que en Insert
+func (r *outboxRepository) InsertMany(ctx context.Context, messages []*domain.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	documents := make([]interface{}, len(messages))
+	for i, message := range messages {
+		message.ID = primitive.NewObjectID()
+		message.Status = domain.OutboxStatusPending
+		message.CreatedAt = now
+		if message.NextAttemptAt.IsZero() {
+			message.NextAttemptAt = now
+		}
+		documents[i] = message
+	}
+
+	_, err := r.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil && onlyDuplicateKeyErrors(bulkErr.WriteErrors) {
+			return nil
+		}
+		return fmt.Errorf("failed to insert %d outbox messages: %w", len(messages), err)
+	}
+
+	return nil
+}
+
+// onlyDuplicateKeyErrors indica si todos los errores de una escritura múltiple son de índice UNIQUE
+func onlyDuplicateKeyErrors(writeErrors []mongo.BulkWriteError) bool {
+	for _, writeErr := range writeErrors {
+		if !mongo.IsDuplicateKeyError(writeErr.WriteError) {
+			return false
+		}
+	}
+	return len(writeErrors) > 0
+}
+
+// ClaimDue toma el mensaje pendiente más antiguo cuyo próximo intento ya venció y corre su
+// next_attempt_at al final del lease: otro relay (otra réplica) no lo toma mientras se publica.
+// Incrementa attempts. Retorna nil si no hay mensajes pendientes.
+func (r *outboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*domain.OutboxMessage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status":          domain.OutboxStatusPending,
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"next_attempt_at": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var message domain.OutboxMessage
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim outbox message: %w", err)
+	}
+
+	return &message, nil
+}
+
+// MarkSent marca el mensaje como publicado
+func (r *outboxRepository) MarkSent(ctx context.Context, id primitive.ObjectID, sentAt time.Time) error {
+	return r.update(ctx, id, bson.M{
+		"status":  domain.OutboxStatusSent,
+		"sent_at": sentAt,
+	})
+}
+
+// MarkRetry reprograma el mensaje para un nuevo intento
+func (r *outboxRepository) MarkRetry(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time, lastError string) error {
+	return r.update(ctx, id, bson.M{
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	})
+}
+
+// MarkFailed marca el mensaje como fallido definitivamente (agotó los reintentos)
+func (r *outboxRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, lastError string) error {
+	return r.update(ctx, id, bson.M{
+		"status":     domain.OutboxStatusFailed,
+		"last_error": lastError,
+	})
+}
+
+// CountPending retorna la cantidad de mensajes pendientes de publicar
+func (r *outboxRepository) CountPending(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": domain.OutboxStatusPending})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending outbox messages: %w", err)
+	}
+	return count, nil
+}
+
+// CountFailed retorna la cantidad de mensajes que agotaron los reintentos
+func (r *outboxRepository) CountFailed(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"status": domain.OutboxStatusFailed})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count failed outbox messages: %w", err)
+	}
+	return count, nil
+}
+
+// RequeueFailed vuelve a pending los mensajes failed con los intentos en cero, para que el relay
+// los publique de inmediato. last_error se conserva hasta el próximo intento
+func (r *outboxRepository) RequeueFailed(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"status": domain.OutboxStatusFailed},
+		bson.M{"$set": bson.M{
+			"status":          domain.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue failed outbox messages: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *outboxRepository) update(ctx context.Context, id primitive.ObjectID, set bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to update outbox message %s: %w", id.Hex(), err)
+	}
+	return nil
+}
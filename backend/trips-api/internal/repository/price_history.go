@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PriceHistoryRepository define las operaciones sobre el historial de precios de los viajes
+type PriceHistoryRepository interface {
+	Insert(ctx context.Context, change *domain.TripPriceChange) error
+	FindByTrip(ctx context.Context, tripID string, limit int) ([]domain.TripPriceChange, error)
+}
+
+type priceHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPriceHistoryRepository crea una nueva instancia del repositorio del historial de precios
+func NewPriceHistoryRepository(db *mongo.Database) PriceHistoryRepository {
+	return &priceHistoryRepository{
+		collection: db.Collection("trip_price_history"),
+	}
+}
+
+// Insert agrega un cambio de precio al historial
+func (r *priceHistoryRepository) Insert(ctx context.Context, change *domain.TripPriceChange) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if change.ID.IsZero() {
+		change.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, change); err != nil {
+		return fmt.Errorf("failed to insert price change for trip %s: %w", change.TripID, err)
+	}
+	return nil
+}
+
+// FindByTrip retorna los últimos cambios de precio del viaje, el más nuevo primero
+func (r *priceHistoryRepository) FindByTrip(ctx context.Context, tripID string, limit int) ([]domain.TripPriceChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "changed_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"trip_id": tripID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price history: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	changes := make([]domain.TripPriceChange, 0)
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, fmt.Errorf("failed to decode price history: %w", err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RecurringTripRepository define las operaciones de acceso a datos para viajes recurrentes
+type RecurringTripRepository interface {
+	Create(ctx context.Context, recurring *domain.RecurringTrip) error
+	FindByID(ctx context.Context, id string) (*domain.RecurringTrip, error)
+	FindByDriver(ctx context.Context, driverID int64) ([]domain.RecurringTrip, error)
+	FindActive(ctx context.Context) ([]domain.RecurringTrip, error)
+	Update(ctx context.Context, recurring *domain.RecurringTrip) error
+	SoftDelete(ctx context.Context, id primitive.ObjectID) error
+	SetMaterializedUntil(ctx context.Context, id primitive.ObjectID, until time.Time) error
+}
+
+// notDeleted filtra las series eliminadas por el conductor
+// Se chequean deleted_at y status: una serie eliminada nunca vuelve a aparecer en Get/List
+func notDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+	if _, ok := filter["status"]; !ok {
+		filter["status"] = bson.M{"$ne": domain.RecurringTripStatusDeleted}
+	}
+	return filter
+}
+
+type recurringTripRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRecurringTripRepository crea una nueva instancia del repositorio de viajes recurrentes
+func NewRecurringTripRepository(db *mongo.Database) RecurringTripRepository {
+	return &recurringTripRepository{
+		collection: db.Collection("recurring_trips"),
+	}
+}
+
+// Create inserta un nuevo viaje recurrente
+func (r *recurringTripRepository) Create(ctx context.Context, recurring *domain.RecurringTrip) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if recurring.ID.IsZero() {
+		recurring.ID = primitive.NewObjectID()
+	}
+
+	now := time.Now()
+	recurring.CreatedAt = now
+	recurring.UpdatedAt = now
+
+	if recurring.PickupPoints == nil {
+		recurring.PickupPoints = []domain.PickupPoint{}
+	}
+
+	_, err := r.collection.InsertOne(ctx, recurring)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring trip: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID busca un viaje recurrente por su ID (los eliminados se tratan como inexistentes)
+func (r *recurringTripRepository) FindByID(ctx context.Context, id string) (*domain.RecurringTrip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrRecurringTripNotFound
+	}
+
+	filter := notDeleted(bson.M{"_id": objectID})
+
+	var recurring domain.RecurringTrip
+	err = r.collection.FindOne(ctx, filter).Decode(&recurring)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrRecurringTripNotFound
+		}
+		return nil, fmt.Errorf("failed to find recurring trip: %w", err)
+	}
+
+	return &recurring, nil
+}
+
+// FindByDriver lista los viajes recurrentes (no eliminados) de un conductor
+func (r *recurringTripRepository) FindByDriver(ctx context.Context, driverID int64) ([]domain.RecurringTrip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := notDeleted(bson.M{"driver_id": driverID})
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recurring trips: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	recurringTrips := []domain.RecurringTrip{}
+	if err = cursor.All(ctx, &recurringTrips); err != nil {
+		return nil, fmt.Errorf("failed to decode recurring trips: %w", err)
+	}
+
+	return recurringTrips, nil
+}
+
+// FindActive lista todos los viajes recurrentes activos
+// Usado por el scheduler que materializa instancias
+func (r *recurringTripRepository) FindActive(ctx context.Context) ([]domain.RecurringTrip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"status": domain.RecurringTripStatusActive}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active recurring trips: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var recurringTrips []domain.RecurringTrip
+	if err = cursor.All(ctx, &recurringTrips); err != nil {
+		return nil, fmt.Errorf("failed to decode recurring trips: %w", err)
+	}
+
+	return recurringTrips, nil
+}
+
+// Update reemplaza la plantilla de un viaje recurrente
+// materialized_until no se modifica: lo maneja solo el scheduler (SetMaterializedUntil)
+// Una serie eliminada no se actualiza: así una edición concurrente no la revive
+func (r *recurringTripRepository) Update(ctx context.Context, recurring *domain.RecurringTrip) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	recurring.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"origin":           recurring.Origin,
+			"destination":      recurring.Destination,
+			"pickup_points":    recurring.PickupPoints,
+			"weekdays":         recurring.Weekdays,
+			"departure_time":   recurring.DepartureTime,
+			"duration_minutes": recurring.DurationMinutes,
+			"timezone":         recurring.Timezone,
+			"end_date":         recurring.EndDate,
+			"price_per_seat":   recurring.PricePerSeat,
+			"currency":         recurring.Currency,
+			"total_seats":      recurring.TotalSeats,
+			"car":              recurring.Car,
+			"preferences":      recurring.Preferences,
+			"description":      recurring.Description,
+			"status":           recurring.Status,
+			"updated_at":       recurring.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": recurring.ID}), update)
+	if err != nil {
+		return fmt.Errorf("failed to update recurring trip: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrRecurringTripNotFound
+	}
+
+	return nil
+}
+
+// SoftDelete marca la serie como eliminada (status deleted + deleted_at)
+func (r *recurringTripRepository) SoftDelete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"status":     domain.RecurringTripStatusDeleted,
+			"deleted_at": now,
+			"updated_at": now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, notDeleted(bson.M{"_id": id}), update)
+	if err != nil {
+		return fmt.Errorf("failed to delete recurring trip: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrRecurringTripNotFound
+	}
+
+	return nil
+}
+
+// SetMaterializedUntil registra hasta qué momento ya se generaron instancias
+func (r *recurringTripRepository) SetMaterializedUntil(ctx context.Context, id primitive.ObjectID, until time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"materialized_until": until,
+			"updated_at":         time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to set materialized_until: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ResponseTimeRepository define las operaciones de acceso a datos para los tiempos de respuesta de conductores
+type ResponseTimeRepository interface {
+	// RecordResponse agrega una respuesta al promedio móvil del tipo indicado (chat)
+	RecordResponse(ctx context.Context, driverID int64, kind string, seconds float64, respondedAt time.Time) (*domain.DriverResponseStats, error)
+
+	// FindByDriver retorna los tiempos de respuesta del conductor (nil, nil si nunca respondió)
+	FindByDriver(ctx context.Context, driverID int64) (*domain.DriverResponseStats, error)
+}
+
+type responseTimeRepository struct {
+	collection *mongo.Collection
+}
+
+// NewResponseTimeRepository crea una nueva instancia del repositorio de tiempos de respuesta
+func NewResponseTimeRepository(db *mongo.Database) ResponseTimeRepository {
+	return &responseTimeRepository{
+		collection: db.Collection("driver_response_stats"),
+	}
+}
+
+// RecordResponse agrega la respuesta y recalcula el promedio en una sola operación atómica
+// Se usa un update con pipeline para que dos respuestas concurrentes no pisen sus muestras
+func (r *responseTimeRepository) RecordResponse(ctx context.Context, driverID int64, kind string, seconds float64, respondedAt time.Time) (*domain.DriverResponseStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	samples := "$" + kind + ".samples"
+	pipeline := mongo.Pipeline{
+		// 1. Agregar la muestra conservando solo las últimas ResponseTimeWindow
+		{{Key: "$set", Value: bson.D{
+			{Key: kind + ".samples", Value: bson.D{{Key: "$slice", Value: bson.A{
+				bson.D{{Key: "$concatArrays", Value: bson.A{
+					bson.D{{Key: "$ifNull", Value: bson.A{samples, bson.A{}}}},
+					bson.A{seconds},
+				}}},
+				-domain.ResponseTimeWindow,
+			}}}},
+			{Key: kind + ".total_responses", Value: bson.D{{Key: "$add", Value: bson.A{
+				bson.D{{Key: "$ifNull", Value: bson.A{"$" + kind + ".total_responses", 0}}},
+				1,
+			}}}},
+			// $max: un evento atrasado no mueve hacia atrás la última respuesta
+			{Key: kind + ".last_response_at", Value: bson.D{{Key: "$max", Value: bson.A{"$" + kind + ".last_response_at", respondedAt}}}},
+			{Key: "updated_at", Value: time.Now()},
+		}}},
+		// 2. Recalcular el promedio sobre las muestras resultantes
+		{{Key: "$set", Value: bson.D{
+			{Key: kind + ".avg_seconds", Value: bson.D{{Key: "$avg", Value: samples}}},
+			{Key: kind + ".sample_count", Value: bson.D{{Key: "$size", Value: samples}}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var stats domain.DriverResponseStats
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"driver_id": driverID}, pipeline, opts).Decode(&stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record %s response time: %w", kind, err)
+	}
+
+	return &stats, nil
+}
+
+// FindByDriver busca los tiempos de respuesta de un conductor
+func (r *responseTimeRepository) FindByDriver(ctx context.Context, driverID int64) (*domain.DriverResponseStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var stats domain.DriverResponseStats
+	err := r.collection.FindOne(ctx, bson.M{"driver_id": driverID}).Decode(&stats)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find response time stats: %w", err)
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SeatHoldRepository define las operaciones sobre las retenciones de asientos (sub-documento seat_holds del viaje)
+// Cada operación es un único update atómico sobre el viaje: mueve los asientos entre available_seats,
+// held_seats y reserved_seats e incrementa availability_version, así los updates con optimistic locking
+// (reservation.*, reparación de drift) detectan el cambio
+type SeatHoldRepository interface {
+	Create(ctx context.Context, tripID string, hold *domain.SeatHold) error
+	Release(ctx context.Context, tripID string, hold *domain.SeatHold) error
+	Confirm(ctx context.Context, tripID string, hold *domain.SeatHold) error
+	FindTripsWithExpiredHolds(ctx context.Context, before time.Time, limit int) ([]domain.Trip, error)
+}
+
+type seatHoldRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSeatHoldRepository crea una nueva instancia del repositorio de retenciones (opera sobre la colección trips)
+func NewSeatHoldRepository(db *mongo.Database) SeatHoldRepository {
+	return &seatHoldRepository{
+		collection: db.Collection("trips"),
+	}
+}
+
+// Create agrega la retención y descuenta sus asientos de available_seats
+// Retorna ErrNoSeatsAvailable si el viaje no existe, ya no está publicado o no tiene los asientos libres
+func (r *seatHoldRepository) Create(ctx context.Context, tripID string, hold *domain.SeatHold) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(tripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID format: %w", err)
+	}
+
+	// Una sola retención activa por reserva: un reintento de bookings-api no retiene dos veces
+	filter := bson.M{
+		"_id":                       objectID,
+		"status":                    domain.TripStatusPublished,
+		"available_seats":           bson.M{"$gte": hold.Seats},
+		"seat_holds.reservation_id": bson.M{"$ne": hold.ReservationID},
+	}
+	update := bson.M{
+		"$push": bson.M{"seat_holds": hold},
+		"$inc": bson.M{
+			"available_seats":      -hold.Seats,
+			"held_seats":           hold.Seats,
+			"availability_version": 1,
+			"event_sequence":       1,
+		},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to create seat hold: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrNoSeatsAvailable
+	}
+
+	return nil
+}
+
+// Release quita la retención y devuelve sus asientos a available_seats (liberación o vencimiento)
+// Retorna ErrSeatHoldNotFound si la retención ya no está activa (otra operación la liberó o confirmó antes)
+func (r *seatHoldRepository) Release(ctx context.Context, tripID string, hold *domain.SeatHold) error {
+	return r.remove(ctx, tripID, hold, "available_seats", nil)
+}
+
+// Confirm quita la retención y pasa sus asientos de held_seats a reserved_seats
+// Retorna ErrSeatHoldNotFound si la retención ya no está activa (se liberó o venció antes)
+func (r *seatHoldRepository) Confirm(ctx context.Context, tripID string, hold *domain.SeatHold) error {
+	return r.remove(ctx, tripID, hold, "reserved_seats", bson.M{"confirmed_seat_hold_ids": hold.HoldID})
+}
+
+// remove saca la retención del viaje y suma sus asientos al contador destination
+// El filtro por hold_id hace que solo una de liberar / confirmar / vencer gane ante operaciones concurrentes.
+// addToSet (opcional) se aplica en la misma escritura: la confirmación deja ahí constancia de la retención
+func (r *seatHoldRepository) remove(ctx context.Context, tripID string, hold *domain.SeatHold, destination string, addToSet bson.M) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(tripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID format: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":                objectID,
+		"seat_holds.hold_id": hold.HoldID,
+	}
+	update := bson.M{
+		"$pull": bson.M{"seat_holds": bson.M{"hold_id": hold.HoldID}},
+		"$inc": bson.M{
+			destination:            hold.Seats,
+			"held_seats":           -hold.Seats,
+			"availability_version": 1,
+			"event_sequence":       1,
+		},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+	if len(addToSet) > 0 {
+		update["$addToSet"] = addToSet
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update seat hold %s: %w", hold.HoldID, err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSeatHoldNotFound
+	}
+
+	return nil
+}
+
+// FindTripsWithExpiredHolds busca hasta limit viajes con al menos una retención vencida antes de before
+func (r *seatHoldRepository) FindTripsWithExpiredHolds(ctx context.Context, before time.Time, limit int) ([]domain.Trip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"seat_holds.expires_at": bson.M{"$lte": before},
+	}
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trips with expired seat holds: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []domain.Trip
+	if err = cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	return trips, nil
+}
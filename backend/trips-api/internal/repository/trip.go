@@ -22,6 +22,16 @@ type TripRepository interface {
 	UpdateAvailability(ctx context.Context, tripID string, seatsDelta int, expectedVersion int) error
 	Cancel(ctx context.Context, id string, cancelledBy int64, reason string) error
 	UpdateLastActivity(ctx context.Context, tripID string, timestamp time.Time) error
+	FindByDriverInWindow(ctx context.Context, driverID int64, status string, from, to time.Time) ([]domain.Trip, error)
+	TransitionStatus(ctx context.Context, id string, fromStatus, toStatus string) error
+	CancelFromStatus(ctx context.Context, id string, fromStatus string, cancelledBy int64, reason string) error
+	FindUpcomingByRecurringTrip(ctx context.Context, recurringTripID string, from time.Time) ([]domain.Trip, error)
+	FindActiveAfterID(ctx context.Context, afterID string, limit int) ([]domain.Trip, error)
+	RepairSeats(ctx context.Context, id string, reservedSeats, heldSeats, availableSeats, expectedVersion int) error
+	FindBookingClosedBefore(ctx context.Context, status string, before time.Time, limit int) ([]domain.Trip, error)
+	FindDepartedBefore(ctx context.Context, status string, before time.Time, limit int) ([]domain.Trip, error)
+	FindArrivedBefore(ctx context.Context, status string, before time.Time, limit int) ([]domain.Trip, error)
+	AggregateDriverTrips(ctx context.Context, driverID int64, scope string, now time.Time, page, limit int) ([]domain.DriverTrip, int64, *domain.DriverTripsSummary, error)
 }
 
 type tripRepository struct {
@@ -55,8 +65,15 @@ func (r *tripRepository) Create(ctx context.Context, trip *domain.Trip) error {
 		trip.AvailabilityVersion = 1
 	}
 
+	// Primera secuencia de eventos del viaje (la de trip.created)
+	trip.EventSequence = 1
+
 	_, err := r.collection.InsertOne(ctx, trip)
 	if err != nil {
+		// Único índice único además de _id: (recurring_trip_id, departure_datetime)
+		if mongo.IsDuplicateKeyError(err) && trip.RecurringTripID != "" {
+			return domain.ErrRecurringInstanceExists
+		}
 		return fmt.Errorf("failed to create trip: %w", err)
 	}
 
@@ -147,19 +164,31 @@ func (r *tripRepository) Update(ctx context.Context, id string, trip *domain.Tri
 	// Actualizar updated_at
 	trip.UpdatedAt = time.Now()
 
+	// event_sequence no va en el $set (omitempty con 0): se incrementa en la misma escritura
+	// y se devuelve en trip.EventSequence para el evento trip.updated
+	trip.EventSequence = 0
+
 	// Usar $set para actualizar solo los campos proporcionados
 	update := bson.M{
 		"$set": trip,
+		"$inc": bson.M{"event_sequence": 1},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"event_sequence": 1})
+
+	var updated struct {
+		EventSequence int64 `bson:"event_sequence"`
+	}
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&updated)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.ErrTripNotFound
+		}
 		return fmt.Errorf("failed to update trip: %w", err)
 	}
-
-	if result.MatchedCount == 0 {
-		return domain.ErrTripNotFound
-	}
+	trip.EventSequence = updated.EventSequence
 
 	return nil
 }
@@ -204,11 +233,15 @@ func (r *tripRepository) UpdateAvailability(ctx context.Context, tripID string,
 	// 1. El trip existe
 	// 2. La versión coincide (expectedVersion)
 	// 3. Hay suficientes asientos disponibles
+	// 4. Para tomar asientos, el viaje sigue publicado (el scheduler de estados puede haberlo pasado a in_progress)
 	filter := bson.M{
 		"_id":                  objectID,
 		"availability_version": expectedVersion,
 		"available_seats":      bson.M{"$gte": -seatsDelta}, // Si seatsDelta es negativo (reserva), available_seats debe ser >= abs(seatsDelta)
 	}
+	if seatsDelta < 0 {
+		filter["status"] = domain.TripStatusPublished
+	}
 
 	// Update atómico que incrementa/decrementa asientos y actualiza la versión
 	update := bson.M{
@@ -216,6 +249,7 @@ func (r *tripRepository) UpdateAvailability(ctx context.Context, tripID string,
 			"available_seats":      seatsDelta,      // +N para cancelaciones, -N para reservas
 			"reserved_seats":       -seatsDelta,     // -N para cancelaciones, +N para reservas
 			"availability_version": 1,               // Siempre incrementar la versión
+			"event_sequence":       1,               // Secuencia de eventos del viaje
 		},
 		"$set": bson.M{
 			"updated_at": time.Now(),
@@ -258,6 +292,7 @@ func (r *tripRepository) Cancel(ctx context.Context, id string, cancelledBy int6
 			"cancellation_reason": reason,
 			"updated_at":          now,
 		},
+		"$inc": bson.M{"event_sequence": 1},
 	}
 
 	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
@@ -297,3 +332,379 @@ func (r *tripRepository) UpdateLastActivity(ctx context.Context, tripID string,
 
 	return nil
 }
+
+// FindByDriverInWindow busca los viajes de un conductor con un estado dado
+// cuya fecha de salida cae dentro de [from, to]
+func (r *tripRepository) FindByDriverInWindow(ctx context.Context, driverID int64, status string, from, to time.Time) ([]domain.Trip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"driver_id": driverID,
+		"status":    status,
+		"departure_datetime": bson.M{
+			"$gte": from,
+			"$lte": to,
+		},
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "departure_datetime", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trips in window: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []domain.Trip
+	if err = cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+// TransitionStatus cambia el estado de un viaje solo si su estado actual es fromStatus
+// Retorna ErrTripNotFound si el viaje no existe o ya no está en fromStatus
+func (r *tripRepository) TransitionStatus(ctx context.Context, id string, fromStatus, toStatus string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID format: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":    objectID,
+		"status": fromStatus,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     toStatus,
+			"updated_at": time.Now(),
+		},
+		"$inc": bson.M{"event_sequence": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update trip status: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrTripNotFound
+	}
+
+	return nil
+}
+
+// CancelFromStatus cancela un viaje solo si su estado actual es fromStatus (como Cancel, con cancelled_at/by/reason)
+// Retorna ErrTripNotFound si el viaje no existe o ya no está en fromStatus
+func (r *tripRepository) CancelFromStatus(ctx context.Context, id string, fromStatus string, cancelledBy int64, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID format: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":    objectID,
+		"status": fromStatus,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":              domain.TripStatusCancelled,
+			"cancelled_at":        &now,
+			"cancelled_by":        &cancelledBy,
+			"cancellation_reason": reason,
+			"updated_at":          now,
+		},
+		"$inc": bson.M{"event_sequence": 1},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to cancel trip: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrTripNotFound
+	}
+
+	return nil
+}
+
+// FindUpcomingByRecurringTrip busca los viajes generados por un viaje recurrente
+// cuya fecha de salida es posterior a from
+func (r *tripRepository) FindUpcomingByRecurringTrip(ctx context.Context, recurringTripID string, from time.Time) ([]domain.Trip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"recurring_trip_id":  recurringTripID,
+		"departure_datetime": bson.M{"$gt": from},
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "departure_datetime", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recurring trip instances: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []domain.Trip
+	if err = cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+// FindActiveAfterID lista los viajes no cancelados ni completados con _id mayor a afterID, ordenados por _id
+// afterID vacío empieza desde el principio (paginación por cursor para recorrer todos los viajes)
+func (r *tripRepository) FindActiveAfterID(ctx context.Context, afterID string, limit int) ([]domain.Trip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status": bson.M{"$nin": []string{"cancelled", "completed"}},
+	}
+	if afterID != "" {
+		objectID, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trip ID format: %w", err)
+		}
+		filter["_id"] = bson.M{"$gt": objectID}
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find active trips: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []domain.Trip
+	if err = cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+// RepairSeats sobrescribe los contadores de asientos con optimistic locking (reparación de drift)
+// Retorna ErrOptimisticLockFailed si la disponibilidad cambió desde la lectura
+func (r *tripRepository) RepairSeats(ctx context.Context, id string, reservedSeats, heldSeats, availableSeats, expectedVersion int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID format: %w", err)
+	}
+
+	filter := bson.M{
+		"_id":                  objectID,
+		"availability_version": expectedVersion,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"reserved_seats":  reservedSeats,
+			"held_seats":      heldSeats,
+			"available_seats": availableSeats,
+			"updated_at":      time.Now(),
+		},
+		"$inc": bson.M{
+			"availability_version": 1,
+			"event_sequence":       1,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to repair seats: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrOptimisticLockFailed
+	}
+
+	return nil
+}
+
+// FindBookingClosedBefore busca hasta limit viajes con el estado dado cuyo cierre de reservas es anterior
+// o igual a before, los más viejos primero (los viajes sin cierre, booking_closes_at null, no aparecen)
+func (r *tripRepository) FindBookingClosedBefore(ctx context.Context, status string, before time.Time, limit int) ([]domain.Trip, error) {
+	return r.findDueBefore(ctx, status, "booking_closes_at", before, limit)
+}
+
+// FindDepartedBefore busca hasta limit viajes con el estado dado cuya salida es anterior o igual a before,
+// los más viejos primero
+func (r *tripRepository) FindDepartedBefore(ctx context.Context, status string, before time.Time, limit int) ([]domain.Trip, error) {
+	return r.findDueBefore(ctx, status, "departure_datetime", before, limit)
+}
+
+// FindArrivedBefore busca hasta limit viajes con el estado dado cuya llegada estimada es anterior o igual
+// a before, los más viejos primero
+func (r *tripRepository) FindArrivedBefore(ctx context.Context, status string, before time.Time, limit int) ([]domain.Trip, error) {
+	return r.findDueBefore(ctx, status, "estimated_arrival_datetime", before, limit)
+}
+
+// findDueBefore busca los viajes con el estado dado cuyo campo de fecha field es <= before
+func (r *tripRepository) findDueBefore(ctx context.Context, status, field string, before time.Time, limit int) ([]domain.Trip, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status": status,
+		field:    bson.M{"$lte": before},
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: field, Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trips due for status transition: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var trips []domain.Trip
+	if err = cursor.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	return trips, nil
+}
+
+// AggregateDriverTrips devuelve una página de los viajes del conductor con sus métricas
+// (asientos vendidos, ingreso estimado, cancelaciones del ledger) y el resumen de todos sus viajes
+//
+// Una sola agregación: las métricas se calculan por viaje, las cancelaciones salen de un $lookup
+// a trip_reservations y un $facet separa la página, el total del alcance y el resumen.
+// Los próximos viajes se ordenan por salida ascendente; los pasados (y "all"), del más reciente al más viejo.
+func (r *tripRepository) AggregateDriverTrips(ctx context.Context, driverID int64, scope string, now time.Time, page, limit int) ([]domain.DriverTrip, int64, *domain.DriverTripsSummary, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	scopeMatch := bson.M{}
+	sortDirection := -1
+	switch scope {
+	case domain.DriverTripsScopeUpcoming:
+		scopeMatch["stats.upcoming"] = true
+		sortDirection = 1
+	case domain.DriverTripsScopePast:
+		scopeMatch["stats.upcoming"] = false
+	}
+
+	// Un viaje cancelado no vende asientos aunque conserve reserved_seats
+	seatsSold := bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{"$status", "cancelled"}},
+		0,
+		"$reserved_seats",
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"driver_id": driverID}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "trip_reservations",
+			"let":  bson.M{"trip_id": bson.M{"$toString": "$_id"}},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$trip_id", "$$trip_id"}},
+					bson.M{"$eq": bson.A{"$status", domain.TripReservationCancelled}},
+				}}}},
+				bson.M{"$count": "count"},
+			},
+			"as": "cancelled_reservations",
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"stats.seats_sold":    seatsSold,
+			"stats.cancellations": bson.M{"$ifNull": bson.A{bson.M{"$first": "$cancelled_reservations.count"}, 0}},
+			"stats.upcoming":      bson.M{"$gt": bson.A{"$departure_datetime", now}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"stats.revenue_estimate": bson.M{"$multiply": bson.A{"$stats.seats_sold", "$price_per_seat"}},
+		}}},
+		{{Key: "$project", Value: bson.M{"cancelled_reservations": 0}}},
+		{{Key: "$facet", Value: bson.M{
+			"trips": bson.A{
+				bson.M{"$match": scopeMatch},
+				bson.M{"$sort": bson.D{{Key: "departure_datetime", Value: sortDirection}, {Key: "_id", Value: 1}}},
+				bson.M{"$skip": int64((page - 1) * limit)},
+				bson.M{"$limit": int64(limit)},
+			},
+			"total": bson.A{
+				bson.M{"$match": scopeMatch},
+				bson.M{"$count": "count"},
+			},
+			"summary": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":           "$currency",
+					"trips":         bson.M{"$sum": 1},
+					"upcoming":      bson.M{"$sum": bson.M{"$cond": bson.A{"$stats.upcoming", 1, 0}}},
+					"seats_sold":    bson.M{"$sum": "$stats.seats_sold"},
+					"cancellations": bson.M{"$sum": "$stats.cancellations"},
+					"revenue":       bson.M{"$sum": "$stats.revenue_estimate"},
+				}},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to aggregate driver trips: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Trips []domain.DriverTrip `bson:"trips"`
+		Total []struct {
+			Count int64 `bson:"count"`
+		} `bson:"total"`
+		Summary []struct {
+			Currency      string  `bson:"_id"`
+			Trips         int     `bson:"trips"`
+			Upcoming      int     `bson:"upcoming"`
+			SeatsSold     int     `bson:"seats_sold"`
+			Cancellations int     `bson:"cancellations"`
+			Revenue       float64 `bson:"revenue"`
+		} `bson:"summary"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to decode driver trips: %w", err)
+	}
+
+	trips := []domain.DriverTrip{}
+	var total int64
+	summary := &domain.DriverTripsSummary{RevenueByCurrency: make(map[string]float64)}
+	if len(results) > 0 {
+		if results[0].Trips != nil {
+			trips = results[0].Trips
+		}
+		if len(results[0].Total) > 0 {
+			total = results[0].Total[0].Count
+		}
+		for _, row := range results[0].Summary {
+			summary.TotalTrips += row.Trips
+			summary.UpcomingTrips += row.Upcoming
+			summary.SeatsSold += row.SeatsSold
+			summary.Cancellations += row.Cancellations
+			summary.RevenueByCurrency[row.Currency] += row.Revenue
+		}
+		summary.PastTrips = summary.TotalTrips - summary.UpcomingTrips
+	}
+
+	return trips, total, summary, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TripReservationRepository define las operaciones sobre el ledger local de reservas
+type TripReservationRepository interface {
+	Confirm(ctx context.Context, reservation *domain.TripReservation) error
+	Cancel(ctx context.Context, reservationID string) (bool, error)
+	SetSeats(ctx context.Context, reservationID string, seats int) error
+	SumConfirmedSeats(ctx context.Context, tripIDs []string) (map[string]int, error)
+	FindBySeatHold(ctx context.Context, holdID string) (*domain.TripReservation, error)
+	HasPassenger(ctx context.Context, tripID string, passengerID int64) (bool, error)
+	FindConfirmedPassengerIDs(ctx context.Context, tripID string) ([]int64, error)
+}
+
+type tripReservationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTripReservationRepository crea una nueva instancia del repositorio del ledger de reservas
+func NewTripReservationRepository(db *mongo.Database) TripReservationRepository {
+	return &tripReservationRepository{
+		collection: db.Collection("trip_reservations"),
+	}
+}
+
+// Confirm registra una reserva confirmada (upsert por reservation_id, idempotente ante reentregas)
+func (r *tripReservationRepository) Confirm(ctx context.Context, reservation *domain.TripReservation) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"trip_id":      reservation.TripID,
+			"passenger_id": reservation.PassengerID,
+			"seats":        reservation.Seats,
+			"status":       domain.TripReservationConfirmed,
+			"updated_at":   now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}
+	if reservation.SeatHoldID != "" {
+		update["$set"].(bson.M)["seat_hold_id"] = reservation.SeatHoldID
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"reservation_id": reservation.ReservationID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to confirm reservation %s: %w", reservation.ReservationID, err)
+	}
+
+	return nil
+}
+
+// Cancel marca cancelada una reserva confirmada del ledger
+// Retorna wasConfirmed=false si la reserva no estaba confirmada (nunca tomó asientos, ya se canceló o
+// no está en el ledger): en ese caso no hay asientos que liberar
+func (r *tripReservationRepository) Cancel(ctx context.Context, reservationID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"reservation_id": reservationID,
+		"status":         domain.TripReservationConfirmed,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     domain.TripReservationCancelled,
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel reservation %s: %w", reservationID, err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// SetSeats fija los asientos de una reserva confirmada (reservation.modified)
+// Es un valor absoluto y no un delta, así reaplicarlo ante una reentrega no cambia el resultado
+func (r *tripReservationRepository) SetSeats(ctx context.Context, reservationID string, seats int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"reservation_id": reservationID,
+		"status":         domain.TripReservationConfirmed,
+	}
+	update := bson.M{
+		"$set": bson.M{"seats": seats, "updated_at": time.Now()},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to adjust reservation %s: %w", reservationID, err)
+	}
+
+	return nil
+}
+
+// SumConfirmedSeats retorna, por viaje, la suma de asientos de las reservas confirmadas
+// Los viajes sin reservas en el ledger no aparecen en el mapa
+func (r *tripReservationRepository) SumConfirmedSeats(ctx context.Context, tripIDs []string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"trip_id": bson.M{"$in": tripIDs},
+			"status":  domain.TripReservationConfirmed,
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$trip_id",
+			"seats": bson.M{"$sum": "$seats"},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum confirmed seats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		TripID string `bson:"_id"`
+		Seats  int    `bson:"seats"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode confirmed seats: %w", err)
+	}
+
+	sums := make(map[string]int, len(rows))
+	for _, row := range rows {
+		sums[row.TripID] = row.Seats
+	}
+
+	return sums, nil
+}
+
+// FindBySeatHold busca la reserva que confirmó una retención de asientos
+// Retorna (nil, nil) si ninguna reserva la confirmó
+func (r *tripReservationRepository) FindBySeatHold(ctx context.Context, holdID string) (*domain.TripReservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var reservation domain.TripReservation
+	err := r.collection.FindOne(ctx, bson.M{"seat_hold_id": holdID}).Decode(&reservation)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reservation by seat hold %s: %w", holdID, err)
+	}
+
+	return &reservation, nil
+}
+
+// HasPassenger indica si el pasajero tiene o tuvo una reserva en el viaje (confirmada o cancelada)
+func (r *tripReservationRepository) HasPassenger(ctx context.Context, tripID string, passengerID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx,
+		bson.M{"trip_id": tripID, "passenger_id": passengerID},
+		options.Count().SetLimit(1),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to find passenger %d in trip %s: %w", passengerID, tripID, err)
+	}
+
+	return count > 0, nil
+}
+
+// FindConfirmedPassengerIDs retorna los pasajeros con una reserva confirmada en el viaje (sin repetir)
+func (r *tripReservationRepository) FindConfirmedPassengerIDs(ctx context.Context, tripID string) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	values, err := r.collection.Distinct(ctx, "passenger_id", bson.M{
+		"trip_id": tripID,
+		"status":  domain.TripReservationConfirmed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find confirmed passengers of trip %s: %w", tripID, err)
+	}
+
+	passengerIDs := make([]int64, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(int64); ok {
+			passengerIDs = append(passengerIDs, id)
+		}
+	}
+
+	return passengerIDs, nil
+}
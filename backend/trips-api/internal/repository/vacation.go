@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// VacationRepository define las operaciones de acceso a datos para vacaciones de conductores
+type VacationRepository interface {
+	Create(ctx context.Context, vacation *domain.DriverVacation) error
+	FindOverlapping(ctx context.Context, driverID int64, start, end time.Time) (*domain.DriverVacation, error)
+	FindEndedActive(ctx context.Context, now time.Time) ([]domain.DriverVacation, error)
+	SetPausedTrips(ctx context.Context, id primitive.ObjectID, tripIDs []string) error
+	MarkFinished(ctx context.Context, id primitive.ObjectID, resumedAt time.Time) error
+}
+
+type vacationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewVacationRepository crea una nueva instancia del repositorio de vacaciones
+func NewVacationRepository(db *mongo.Database) VacationRepository {
+	return &vacationRepository{
+		collection: db.Collection("driver_vacations"),
+	}
+}
+
+// Create inserta un nuevo período de vacaciones
+func (r *vacationRepository) Create(ctx context.Context, vacation *domain.DriverVacation) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if vacation.ID.IsZero() {
+		vacation.ID = primitive.NewObjectID()
+	}
+
+	now := time.Now()
+	vacation.CreatedAt = now
+	vacation.UpdatedAt = now
+
+	if vacation.PausedTripIDs == nil {
+		vacation.PausedTripIDs = []string{}
+	}
+
+	_, err := r.collection.InsertOne(ctx, vacation)
+	if err != nil {
+		return fmt.Errorf("failed to create vacation: %w", err)
+	}
+
+	return nil
+}
+
+// FindOverlapping busca una vacación activa del conductor que se superponga con [start, end]
+// Retorna nil, nil si no hay superposición
+func (r *vacationRepository) FindOverlapping(ctx context.Context, driverID int64, start, end time.Time) (*domain.DriverVacation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Dos rangos se superponen si cada uno empieza antes de que termine el otro
+	filter := bson.M{
+		"driver_id":  driverID,
+		"status":     domain.VacationStatusActive,
+		"start_date": bson.M{"$lte": end},
+		"end_date":   bson.M{"$gte": start},
+	}
+
+	var vacation domain.DriverVacation
+	err := r.collection.FindOne(ctx, filter).Decode(&vacation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find overlapping vacation: %w", err)
+	}
+
+	return &vacation, nil
+}
+
+// FindEndedActive busca vacaciones activas cuya ventana ya terminó
+// Usado por el worker que reanuda los viajes pausados
+func (r *vacationRepository) FindEndedActive(ctx context.Context, now time.Time) ([]domain.DriverVacation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status":   domain.VacationStatusActive,
+		"end_date": bson.M{"$lt": now},
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "end_date", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find ended vacations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var vacations []domain.DriverVacation
+	if err = cursor.All(ctx, &vacations); err != nil {
+		return nil, fmt.Errorf("failed to decode vacations: %w", err)
+	}
+
+	return vacations, nil
+}
+
+// SetPausedTrips registra los viajes que fueron pausados por una vacación
+func (r *vacationRepository) SetPausedTrips(ctx context.Context, id primitive.ObjectID, tripIDs []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"paused_trip_ids": tripIDs,
+			"updated_at":      time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to set paused trips: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFinished marca una vacación como terminada
+func (r *vacationRepository) MarkFinished(ctx context.Context, id primitive.ObjectID, resumedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     domain.VacationStatusFinished,
+			"resumed_at": &resumedAt,
+			"updated_at": resumedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to mark vacation as finished: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package richtext
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags son las etiquetas que HTMLSanitizer conserva (sin atributos, salvo href en <a>)
+var allowedTags = map[string]bool{
+	"p": true, "br": true, "b": true, "strong": true, "i": true, "em": true, "u": true, "s": true,
+	"ul": true, "ol": true, "li": true, "blockquote": true, "a": true,
+}
+
+// droppedTags se descartan junto con todo su contenido; el resto de las etiquetas
+// no permitidas se descartan conservando su texto
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true, "object": true,
+	"embed": true, "noscript": true, "noembed": true, "template": true, "svg": true, "math": true,
+	"head": true, "title": true, "textarea": true, "select": true, "xmp": true,
+}
+
+// blockTags separan líneas en el texto plano extraído
+var blockTags = map[string]bool{
+	"p": true, "br": true, "div": true, "li": true, "ul": true, "ol": true, "blockquote": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "hr": true, "pre": true,
+}
+
+// allowedURLSchemes son los esquemas aceptados en los links (nunca javascript:, data:, vbscript:)
+var allowedURLSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// HTMLSanitizer conserva las etiquetas de allowedTags y escapa todo el texto
+// Los links solo mantienen href con un esquema permitido y se marcan rel="nofollow noopener noreferrer"
+type HTMLSanitizer struct{}
+
+func (HTMLSanitizer) Sanitize(input string) (string, string) {
+	var content strings.Builder
+	var text textBuilder
+	var open []string // Etiquetas permitidas abiertas, para cerrarlas bien anidadas
+	skip := 0         // Profundidad dentro de droppedTags
+
+	z := html.NewTokenizer(strings.NewReader(input))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			// Fin del input (o HTML truncado): cerrar lo que quedó abierto
+			for i := len(open) - 1; i >= 0; i-- {
+				content.WriteString("</" + open[i] + ">")
+			}
+			return content.String(), text.String()
+
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			data := string(z.Text())
+			content.WriteString(html.EscapeString(data))
+			text.WriteText(data)
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			name := token.Data
+			if droppedTags[name] {
+				if tt == html.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			if blockTags[name] {
+				text.Break()
+			}
+			if !allowedTags[name] {
+				continue
+			}
+			if name == "br" {
+				content.WriteString("<br>")
+				continue
+			}
+
+			content.WriteString(openTag(token))
+			if tt == html.SelfClosingTagToken {
+				content.WriteString("</" + name + ">")
+			} else {
+				open = append(open, name)
+			}
+
+		case html.EndTagToken:
+			name := z.Token().Data
+			if droppedTags[name] {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if skip > 0 {
+				continue
+			}
+			if blockTags[name] {
+				text.Break()
+			}
+			if !allowedTags[name] {
+				continue
+			}
+
+			// Un cierre sin apertura se descarta; uno que cierra etiquetas anidadas sin cerrar, las cierra
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != name {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					content.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+
+		// Comentarios y doctype se descartan
+	}
+}
+
+// openTag escribe la etiqueta de apertura sin atributos, salvo el href seguro de los links
+func openTag(token html.Token) string {
+	if token.Data != "a" {
+		return "<" + token.Data + ">"
+	}
+	for _, attr := range token.Attr {
+		if attr.Key == "href" && safeURL(attr.Val) {
+			return `<a href="` + html.EscapeString(strings.TrimSpace(attr.Val)) + `" rel="nofollow noopener noreferrer">`
+		}
+	}
+	return "<a>"
+}
+
+// safeURL acepta solo URLs absolutas con un esquema de allowedURLSchemes
+// url.Parse rechaza los caracteres de control ("java\tscript:")
+func safeURL(raw string) bool {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	return allowedURLSchemes[strings.ToLower(u.Scheme)]
+}
+
+// PlainSanitizer descarta todas las etiquetas: el contenido guardado es el mismo texto plano
+type PlainSanitizer struct{}
+
+func (PlainSanitizer) Sanitize(input string) (string, string) {
+	_, text := HTMLSanitizer{}.Sanitize(input)
+	return text, text
+}
+
+// textBuilder arma el texto plano: separa los bloques en líneas y normaliza los espacios
+type textBuilder struct {
+	b            strings.Builder
+	pendingBreak bool
+}
+
+// WriteText agrega texto (ya sin escapar)
+func (t *textBuilder) WriteText(s string) {
+	if t.pendingBreak && t.b.Len() > 0 {
+		t.b.WriteString("\n")
+	}
+	t.pendingBreak = false
+	t.b.WriteString(s)
+}
+
+// Break hace que el próximo texto empiece en otra línea
+func (t *textBuilder) Break() {
+	t.pendingBreak = true
+}
+
+func (t *textBuilder) String() string {
+	return normalizeText(t.b.String())
+}
+
+// normalizeText colapsa los espacios de cada línea y descarta las líneas vacías
+func normalizeText(s string) string {
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
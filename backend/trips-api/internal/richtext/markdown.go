@@ -0,0 +1,93 @@
+package richtext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+var (
+	// Destinos de links e imágenes en línea: [texto](destino) y ![alt](destino)
+	// El destino puede tener paréntesis balanceados, como en CommonMark
+	markdownInlineLink = regexp.MustCompile(`(\]\(\s*<?)((?:[^()\s<>]|\([^()\s]*\))*)`)
+	// Definiciones de links por referencia: [id]: destino
+	markdownReferenceLink = regexp.MustCompile(`(?m)^(\s{0,3}\[[^\]]+\]:\s*<?)(\S*?)(>?(\s|$))`)
+
+	// Sintaxis que se quita al extraer el texto plano
+	markdownImage        = regexp.MustCompile(`!\[([^\]]*)\]\((?:[^()]|\([^()]*\))*\)`)
+	markdownLink         = regexp.MustCompile(`\[([^\]]*)\]\((?:[^()]|\([^()]*\))*\)`)
+	markdownLinkRef      = regexp.MustCompile(`\[([^\]]*)\](\[[^\]]*\])?`)
+	markdownReferenceDef = regexp.MustCompile(`(?m)^\s{0,3}\[[^\]]+\]:.*$`)
+	markdownFence        = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	markdownRule         = regexp.MustCompile(`(?m)^\s*([-*_]\s*){3,}$`)
+	markdownLinePrefix   = regexp.MustCompile(`(?m)^\s{0,3}(#{1,6}|>|[-*+]|\d+[.)])\s+`)
+	markdownEmphasis     = regexp.MustCompile("[*_~`]+")
+)
+
+// MarkdownSanitizer guarda el Markdown sin HTML embebido (se descartan las etiquetas y el contenido
+// de script, style, etc.) y reemplaza por "#" los destinos de links con un esquema no permitido
+// El texto se conserva tal cual se escribió (con sus entidades): el frontend lo renderiza escapando HTML
+type MarkdownSanitizer struct{}
+
+func (MarkdownSanitizer) Sanitize(input string) (string, string) {
+	var content strings.Builder
+	skip := 0 // Profundidad dentro de droppedTags
+
+	z := xhtml.NewTokenizer(strings.NewReader(input))
+	for tt := z.Next(); tt != xhtml.ErrorToken; tt = z.Next() {
+		switch tt {
+		case xhtml.TextToken:
+			if skip == 0 {
+				content.Write(z.Raw())
+			}
+		case xhtml.StartTagToken:
+			if name, _ := z.TagName(); droppedTags[string(name)] {
+				skip++
+			}
+		case xhtml.EndTagToken:
+			if name, _ := z.TagName(); droppedTags[string(name)] && skip > 0 {
+				skip--
+			}
+		}
+	}
+
+	sanitized := markdownInlineLink.ReplaceAllStringFunc(content.String(), func(match string) string {
+		parts := markdownInlineLink.FindStringSubmatch(match)
+		return parts[1] + safeMarkdownDestination(parts[2])
+	})
+	sanitized = markdownReferenceLink.ReplaceAllStringFunc(sanitized, func(match string) string {
+		parts := markdownReferenceLink.FindStringSubmatch(match)
+		return parts[1] + safeMarkdownDestination(parts[2]) + parts[3]
+	})
+
+	return sanitized, markdownText(sanitized)
+}
+
+// safeMarkdownDestination devuelve el destino si es relativo o tiene un esquema permitido, "#" si no
+// Las entidades se decodifican antes de mirar el esquema, como hacen los renderers ("javascript&#58;")
+func safeMarkdownDestination(destination string) string {
+	decoded := strings.TrimSpace(html.UnescapeString(destination))
+	end := strings.IndexAny(decoded, "/?#")
+	if end < 0 {
+		end = len(decoded)
+	}
+	if !strings.Contains(decoded[:end], ":") || safeURL(decoded) {
+		return destination
+	}
+	return "#"
+}
+
+// markdownText quita la sintaxis de Markdown y decodifica las entidades
+func markdownText(markdown string) string {
+	text := markdownImage.ReplaceAllString(markdown, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownReferenceDef.ReplaceAllString(text, "")
+	text = markdownLinkRef.ReplaceAllString(text, "$1")
+	text = markdownFence.ReplaceAllString(text, "")
+	text = markdownRule.ReplaceAllString(text, "")
+	text = markdownLinePrefix.ReplaceAllString(text, "")
+	text = markdownEmphasis.ReplaceAllString(text, "")
+	return normalizeText(html.UnescapeString(text))
+}
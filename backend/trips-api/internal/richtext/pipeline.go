@@ -0,0 +1,87 @@
+package richtext
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"trips-api/internal/domain"
+)
+
+// Sanitizer limpia una descripción escrita en un formato
+type Sanitizer interface {
+	// Sanitize devuelve el contenido seguro para guardar y mostrar, y su texto plano
+	Sanitize(input string) (content, text string)
+}
+
+// Pipeline sanitiza las descripciones de los viajes según su formato
+//
+// Los sanitizers se registran al iniciar y luego son de solo lectura,
+// por lo que el pipeline es seguro para uso concurrente.
+type Pipeline interface {
+	// Process valida el tamaño de input, lo sanitiza con el sanitizer del formato
+	// (vacío = plain) y extrae su texto plano, que tampoco puede superar MaxDescriptionTextLength
+	Process(format, input string) (domain.TripDescription, error)
+}
+
+// pipeline implementa Pipeline con un sanitizer por formato
+type pipeline struct {
+	sanitizers map[string]Sanitizer
+}
+
+// NewPipeline crea un pipeline con los sanitizers indicados (formato → sanitizer)
+func NewPipeline(sanitizers map[string]Sanitizer) Pipeline {
+	p := &pipeline{sanitizers: make(map[string]Sanitizer, len(sanitizers))}
+	for format, sanitizer := range sanitizers {
+		p.sanitizers[normalizeFormat(format)] = sanitizer
+	}
+	return p
+}
+
+// DefaultPipeline crea el pipeline con los formatos soportados: plain, html y markdown
+func DefaultPipeline() Pipeline {
+	return NewPipeline(map[string]Sanitizer{
+		domain.DescriptionFormatPlain:    PlainSanitizer{},
+		domain.DescriptionFormatHTML:     HTMLSanitizer{},
+		domain.DescriptionFormatMarkdown: MarkdownSanitizer{},
+	})
+}
+
+func (p *pipeline) Process(format, input string) (domain.TripDescription, error) {
+	format = normalizeFormat(format)
+	sanitizer, ok := p.sanitizers[format]
+	if !ok {
+		return domain.TripDescription{}, domain.InvalidDescription("unsupported description_format %q, use one of: %s", format, strings.Join(p.formats(), ", "))
+	}
+
+	if len(input) > domain.MaxDescriptionInputBytes {
+		return domain.TripDescription{}, domain.InvalidDescription("description exceeds %d bytes", domain.MaxDescriptionInputBytes)
+	}
+	input = strings.ToValidUTF8(input, "")
+
+	content, text := sanitizer.Sanitize(input)
+	if length := utf8.RuneCountInString(text); length > domain.MaxDescriptionTextLength {
+		return domain.TripDescription{}, domain.InvalidDescription("description text has %d characters, max %d", length, domain.MaxDescriptionTextLength)
+	}
+
+	return domain.TripDescription{Content: content, Format: format, Text: text}, nil
+}
+
+// formats lista los formatos registrados, ordenados (para los mensajes de error)
+func (p *pipeline) formats() []string {
+	formats := make([]string, 0, len(p.sanitizers))
+	for format := range p.sanitizers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// normalizeFormat pasa el formato a minúsculas; vacío es texto plano
+func normalizeFormat(format string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		return domain.DescriptionFormatPlain
+	}
+	return format
+}
@@ -0,0 +1,95 @@
+package richtext
+
+import (
+	"strings"
+	"testing"
+
+	"trips-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLSanitizer(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedContent string
+		expectedText    string
+	}{
+		{"allowed tags", "<p>Salgo <b>puntual</b></p><p>Sin <em>mascotas</em></p>", "<p>Salgo <b>puntual</b></p><p>Sin <em>mascotas</em></p>", "Salgo puntual\nSin mascotas"},
+		{"script with content", `Hola<script>alert("x")</script> chau`, "Hola chau", "Hola chau"},
+		{"attributes dropped", `<p onclick="steal()" style="color:red">Hola</p>`, "<p>Hola</p>", "Hola"},
+		{"unknown tags keep text", "<div><span>Ruta 2</span></div>", "Ruta 2", "Ruta 2"},
+		{"safe link", `<a href="https://maps.example.com/x" target="_blank">mapa</a>`, `<a href="https://maps.example.com/x" rel="nofollow noopener noreferrer">mapa</a>`, "mapa"},
+		{"javascript link", `<a href="javascript:alert(1)">mapa</a>`, "<a>mapa</a>", "mapa"},
+		{"obfuscated javascript link", "<a href=\"java\tscript:alert(1)\">mapa</a>", "<a>mapa</a>", "mapa"},
+		{"unclosed tags", "<p><b>Hola", "<p><b>Hola</b></p>", "Hola"},
+		{"stray end tag", "Hola</b> chau", "Hola chau", "Hola chau"},
+		{"text is escaped", "Precio &lt; 500 & <i>charla</i>", "Precio &lt; 500 &amp; <i>charla</i>", "Precio < 500 & charla"},
+		{"event handler in svg", `<svg onload="alert(1)"><circle/></svg>Ok`, "Ok", "Ok"},
+		{"comments dropped", "Hola<!-- <script>x</script> --> chau", "Hola chau", "Hola chau"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, text := HTMLSanitizer{}.Sanitize(tt.input)
+			assert.Equal(t, tt.expectedContent, content)
+			assert.Equal(t, tt.expectedText, text)
+		})
+	}
+}
+
+func TestMarkdownSanitizer(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expectedContent string
+		expectedText    string
+	}{
+		{"markdown kept", "## Viaje\n\n- **Puntual**\n- Sin _mascotas_", "## Viaje\n\n- **Puntual**\n- Sin _mascotas_", "Viaje\nPuntual\nSin mascotas"},
+		{"embedded html dropped", "Hola <img src=x onerror=alert(1)> <script>alert(1)</script>chau", "Hola  chau", "Hola chau"},
+		{"safe link", "[mapa](https://maps.example.com)", "[mapa](https://maps.example.com)", "mapa"},
+		{"javascript link", "[mapa](javascript:alert(1))", "[mapa](#)", "mapa"},
+		{"entity encoded javascript link", "[mapa](javascript&#58;alert(1))", "[mapa](#)", "mapa"},
+		{"reference link", "[mapa]: javascript:alert(1)\n\nVer [mapa]", "[mapa]: #\n\nVer [mapa]", "Ver mapa"},
+		{"relative link", "[detalles](/trips/123)", "[detalles](/trips/123)", "detalles"},
+		{"entities kept", "Precio &lt;script&gt;", "Precio &lt;script&gt;", "Precio <script>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, text := MarkdownSanitizer{}.Sanitize(tt.input)
+			assert.Equal(t, tt.expectedContent, content)
+			assert.Equal(t, tt.expectedText, text)
+		})
+	}
+}
+
+func TestPipeline_Process(t *testing.T) {
+	p := DefaultPipeline()
+
+	description, err := p.Process("", "<b>Hola</b>   mundo")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DescriptionFormatPlain, description.Format)
+	assert.Equal(t, "Hola mundo", description.Content, "plain drops every tag")
+	assert.Equal(t, "Hola mundo", description.Text)
+
+	description, err = p.Process("HTML", "<p>Hola</p>")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DescriptionFormatHTML, description.Format)
+	assert.Equal(t, "<p>Hola</p>", description.Content)
+
+	_, err = p.Process("rtf", "Hola")
+	assert.ErrorContains(t, err, "unsupported description_format")
+
+	_, err = p.Process("plain", strings.Repeat("a", domain.MaxDescriptionInputBytes+1))
+	assert.ErrorContains(t, err, "exceeds")
+
+	_, err = p.Process("plain", strings.Repeat("a", domain.MaxDescriptionTextLength+1))
+	assert.ErrorContains(t, err, "characters")
+
+	// Las etiquetas no cuentan para el largo del texto
+	_, err = p.Process("html", strings.Repeat("<b>a</b>", domain.MaxDescriptionTextLength))
+	assert.NoError(t, err)
+}
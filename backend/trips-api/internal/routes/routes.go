@@ -4,31 +4,94 @@ import (
 	"net/http"
 	"time"
 	"trips-api/internal/controller"
+	"trips-api/internal/metrics"
+	"trips-api/internal/middleware"
+	"trips-api/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes configura todas las rutas de la aplicación
-func SetupRoutes(router *gin.Engine, tripController controller.TripController, chatController *controller.ChatController, jwtMiddleware gin.HandlerFunc) {
+func SetupRoutes(router *gin.Engine, tripController controller.TripController, chatController *controller.ChatController, vacationController controller.VacationController, recurringTripController controller.RecurringTripController, responseTimeController controller.ResponseTimeController, seatHoldController controller.SeatHoldController, driverDashboardController controller.DriverDashboardController, cityController controller.CityController, outboxController controller.OutboxController, jwtMiddleware, internalMiddleware gin.HandlerFunc) {
+	// Span por request, continuando el traceparent entrante (OpenTelemetry)
+	router.Use(tracing.Middleware())
+
+	// Duración de cada request por ruta (Prometheus)
+	router.Use(metrics.Middleware())
+
 	// Health check endpoint
 	router.GET("/health", healthCheck)
 
+	// Métricas Prometheus (scrapeadas desde la red interna, sin autenticación)
+	router.GET("/metrics", metrics.Handler())
+
 	// Rutas públicas de trips (sin autenticación)
 	router.GET("/trips", tripController.ListTrips)
 	router.GET("/trips/:id", tripController.GetTrip)
+	router.GET("/trips/:id/price-history", tripController.GetPriceHistory)
+
+	// Catálogo de ciudades (sin autenticación): autocompletado y city_id para publicar viajes
+	router.GET("/cities", cityController.SearchCities)
+
+	// Retenciones de asientos (entre servicios, las llama bookings-api antes de crear la reserva)
+	// Requieren el secreto compartido: el puerto está publicado y sin él cualquiera podría agotar los asientos
+	holds := router.Group("/trips/:id/holds")
+	holds.Use(internalMiddleware)
+	{
+		holds.POST("", seatHoldController.CreateHold)
+		holds.DELETE("/:hold_id", seatHoldController.ReleaseHold)       // ?reservation_id= de la reserva dueña
+		holds.POST("/:hold_id/confirm", seatHoldController.ConfirmHold) // ?reservation_id= de la reserva dueña
+	}
 
 	// Rutas protegidas de trips (requieren autenticación)
 	protected := router.Group("/trips")
 	protected.Use(jwtMiddleware)
 	{
-		protected.POST("", tripController.CreateTrip)
-		protected.PUT("/:id", tripController.UpdateTrip)
-		protected.PATCH("/:id", tripController.UpdateTrip)
+		// Las cuentas dependientes (menores) no pueden publicar ni editar viajes
+		noDriving := middleware.RejectRestriction(middleware.RestrictionNoDriving, "la cuenta no puede publicar viajes como conductor")
+
+		protected.POST("", noDriving, tripController.CreateTrip)
+		protected.POST("/bulk", noDriving, tripController.ImportTrips) // Importación masiva (JSON o CSV), reporte por fila
+		protected.PUT("/:id", noDriving, tripController.UpdateTrip)
+		protected.PATCH("/:id", noDriving, tripController.UpdateTrip)
 		protected.DELETE("/:id", tripController.DeleteTrip)
 
+		// Panel del conductor autenticado: sus viajes con métricas (ruta estática, prioridad sobre /:id)
+		protected.GET("/mine", driverDashboardController.ListMyTrips)
+
+		// Viajes recurrentes del conductor autenticado (agenda semanal)
+		// Las rutas estáticas /recurring tienen prioridad sobre /:id
+		protected.POST("/recurring", noDriving, recurringTripController.CreateRecurringTrip)
+		protected.GET("/recurring", recurringTripController.ListRecurringTrips)
+		protected.GET("/recurring/:id", recurringTripController.GetRecurringTrip)
+		protected.PUT("/recurring/:id", noDriving, recurringTripController.UpdateRecurringTrip)
+		protected.DELETE("/recurring/:id", recurringTripController.DeleteRecurringTrip)
+
 		// Chat routes (protected - requires authentication)
 		protected.POST("/:id/messages", chatController.SendMessage)
-		protected.GET("/:id/messages", chatController.GetMessages)
+		protected.GET("/:id/messages", chatController.GetMessages) // Paginado: ?before=<RFC3339>&limit=50
+		protected.GET("/:id/messages/unread-count", chatController.GetUnreadCount)
+		protected.POST("/:id/messages/read", chatController.MarkAsRead)
+		protected.GET("/:id/chat/stream", chatController.StreamChat)    // Server-Sent Events (alternativa a WebSocket)
+		protected.GET("/:id/chat/messages", chatController.GetMessages) // Igual que /:id/messages; ?translate_to=es traduce los mensajes
+		protected.GET("/:id/chat/export", chatController.ExportChat)    // Chat completo (?format=json|text) para disputas, solo participantes
+	}
+
+	// Rutas protegidas del conductor autenticado
+	drivers := router.Group("/drivers")
+	drivers.Use(jwtMiddleware)
+	{
+		drivers.POST("/me/vacation", vacationController.CreateVacation)
+	}
+
+	// Rutas internas (sin autenticación, para comunicación entre servicios)
+	internal := router.Group("/internal")
+	{
+		// Tiempo de respuesta del conductor en el chat
+		internal.GET("/drivers/:id/response-time", responseTimeController.GetDriverResponseTime)
+
+		// Reencolar los trip.created que agotaron los reintentos del outbox (requiere el secreto compartido)
+		internal.POST("/outbox/requeue-failed", internalMiddleware, outboxController.RequeueFailed)
 	}
 }
 
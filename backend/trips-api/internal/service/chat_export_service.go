@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"trips-api/internal/dao"
+	"trips-api/internal/domain"
+	"trips-api/internal/repository"
+)
+
+// chatExportBatchSize is how many messages are read from MongoDB per batch of an export
+const chatExportBatchSize = 500
+
+// DefaultChatExportWindow is how long after the trip ends its chat can be exported
+const DefaultChatExportWindow = 30 * 24 * time.Hour
+
+// ChatExportService exports the complete chat of a trip for dispute resolution
+type ChatExportService interface {
+	// StartExport checks that the user may export the chat and fixes the snapshot of the export
+	StartExport(ctx context.Context, tripID string, userID int64) (*ChatExport, error)
+	// EachBatch calls fn with the messages of the snapshot in batches, oldest first
+	// Messages sent after the export started are never included
+	EachBatch(ctx context.Context, export *ChatExport, fn func(messages []*dao.Message) error) error
+}
+
+// ChatExport describes an export: who requested it and which messages it covers
+type ChatExport struct {
+	TripID         string     `json:"trip_id"`
+	TripStatus     string     `json:"trip_status"`
+	DriverID       int64      `json:"driver_id"`
+	ExportedBy     int64      `json:"exported_by"`
+	ExportedAt     time.Time  `json:"exported_at"`
+	AvailableUntil time.Time  `json:"available_until"`
+	LastMessageID  string     `json:"last_message_id,omitempty"` // Last message included (empty chat: none)
+	LastMessageAt  *time.Time `json:"last_message_at,omitempty"`
+
+	untilID primitive.ObjectID
+}
+
+type chatExportService struct {
+	messageRepo     repository.MessageRepository
+	tripRepo        repository.TripRepository
+	reservationRepo repository.TripReservationRepository
+	window          time.Duration
+}
+
+// NewChatExportService creates a new chat export service instance
+// window <= 0 uses DefaultChatExportWindow
+func NewChatExportService(
+	messageRepo repository.MessageRepository,
+	tripRepo repository.TripRepository,
+	reservationRepo repository.TripReservationRepository,
+	window time.Duration,
+) ChatExportService {
+	if window <= 0 {
+		window = DefaultChatExportWindow
+	}
+	return &chatExportService{
+		messageRepo:     messageRepo,
+		tripRepo:        tripRepo,
+		reservationRepo: reservationRepo,
+		window:          window,
+	}
+}
+
+// StartExport validates the request and records the last message of the chat as the snapshot
+//
+//   - Only the driver and the passengers with a reservation (confirmed or cancelled) can export
+//   - The trip must be completed or cancelled, and the export window must still be open
+func (s *chatExportService) StartExport(ctx context.Context, tripID string, userID int64) (*ChatExport, error) {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if trip.DriverID != userID {
+		isPassenger, err := s.reservationRepo.HasPassenger(ctx, tripID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isPassenger {
+			return nil, domain.ErrUnauthorized
+		}
+	}
+
+	availableUntil, ended := domain.ChatExportAvailableUntil(trip, s.window)
+	if !ended {
+		return nil, domain.ErrChatExportNotAvailable
+	}
+	now := time.Now().UTC()
+	if now.After(availableUntil) {
+		return nil, domain.ErrChatExportExpired
+	}
+
+	export := &ChatExport{
+		TripID:         tripID,
+		TripStatus:     trip.Status,
+		DriverID:       trip.DriverID,
+		ExportedBy:     userID,
+		ExportedAt:     now,
+		AvailableUntil: availableUntil,
+	}
+
+	// The snapshot is the latest message right now: iterating by _id up to it gives the same
+	// complete, ordered history even if messages keep arriving during the export
+	latest, err := s.messageRepo.FindLatest(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		export.untilID = latest.ID
+		export.LastMessageID = latest.ID.Hex()
+		export.LastMessageAt = &latest.CreatedAt
+	}
+
+	log.Info().
+		Str("trip_id", tripID).
+		Int64("user_id", userID).
+		Str("last_message_id", export.LastMessageID).
+		Msg("Chat export started")
+
+	return export, nil
+}
+
+// EachBatch iterates the snapshot with a cursor on _id (each batch starts after the last ID read)
+func (s *chatExportService) EachBatch(ctx context.Context, export *ChatExport, fn func(messages []*dao.Message) error) error {
+	if export.untilID.IsZero() {
+		return nil
+	}
+
+	afterID := primitive.NilObjectID
+	for {
+		messages, err := s.messageRepo.FindRange(ctx, export.TripID, afterID, export.untilID, chatExportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		if err := fn(messages); err != nil {
+			return err
+		}
+
+		afterID = messages[len(messages)-1].ID
+		if len(messages) < chatExportBatchSize || afterID == export.untilID {
+			return nil
+		}
+	}
+}
@@ -3,38 +3,91 @@ package service
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"trips-api/internal/dao"
+	"trips-api/internal/domain"
 	"trips-api/internal/messaging"
+	"trips-api/internal/realtime"
 	"trips-api/internal/repository"
 )
 
 // ChatService defines the interface for chat operations
 type ChatService interface {
 	SendMessage(ctx context.Context, tripID string, userID int64, userName, message string) (*dao.Message, error)
-	GetMessages(ctx context.Context, tripID string) ([]*dao.Message, error)
+	// GetMessages returns a page of messages before the (before, beforeID) cursor (zero = latest), oldest first
+	GetMessages(ctx context.Context, tripID string, before time.Time, beforeID primitive.ObjectID, limit int) (*MessagePage, error)
+	// GetUnreadCount counts the messages of other participants the user has not read yet
+	// Returns domain.ErrUnauthorized if the user is not the driver or a passenger of the trip
+	GetUnreadCount(ctx context.Context, tripID string, userID int64) (*UnreadCount, error)
+	// MarkAsRead marks the chat as read up to a message (empty = everything sent so far)
+	// Returns domain.ErrUnauthorized if the user is not the driver or a passenger of the trip
+	MarkAsRead(ctx context.Context, tripID string, userID int64, messageID string) (*dao.ChatReadMarker, error)
+	// Subscribe streams new messages and status changes of a trip (shared by SSE and WebSocket)
+	// Events missed since lastEventID (if any) are returned for replay
+	Subscribe(ctx context.Context, tripID, lastEventID string) (*realtime.Subscription, []realtime.Event, error)
+	Unsubscribe(sub *realtime.Subscription)
+}
+
+// maxReplayMessages caps the messages replayed to a reconnecting realtime client
+const maxReplayMessages = 100
+
+// Chat history page sizes
+const (
+	DefaultMessagesPageSize = 50
+	MaxMessagesPageSize     = 100
+)
+
+// ErrInvalidMessageID is returned by MarkAsRead when the message does not belong to the trip
+var ErrInvalidMessageID = errors.New("message not found in this trip")
+
+// MessagePage is a page of the chat history of a trip
+// To load older messages, request the next page with before = NextBefore and before_id = NextBeforeID
+type MessagePage struct {
+	Messages     []*dao.Message `json:"messages"` // Oldest first
+	HasMore      bool           `json:"has_more"`
+	NextBefore   *time.Time     `json:"next_before,omitempty"`
+	NextBeforeID string         `json:"next_before_id,omitempty"`
+}
+
+// UnreadCount is the number of unread messages of a user in a trip chat
+type UnreadCount struct {
+	TripID     string     `json:"trip_id"`
+	Unread     int64      `json:"unread"`
+	LastReadAt *time.Time `json:"last_read_at,omitempty"` // nil if the user never read the chat
 }
 
 type chatService struct {
-	messageRepo repository.MessageRepository
-	tripRepo    repository.TripRepository
-	publisher   messaging.Publisher
+	messageRepo     repository.MessageRepository
+	tripRepo        repository.TripRepository
+	reservationRepo repository.TripReservationRepository
+	publisher       messaging.Publisher
+	hub             *realtime.Hub
+	responseTimes   ResponseTimeService
 }
 
 // NewChatService creates a new chat service instance
+// responseTimes may be nil, which disables driver response-time tracking
 func NewChatService(
 	messageRepo repository.MessageRepository,
 	tripRepo repository.TripRepository,
+	reservationRepo repository.TripReservationRepository,
 	publisher messaging.Publisher,
+	hub *realtime.Hub,
+	responseTimes ResponseTimeService,
 ) ChatService {
 	return &chatService{
-		messageRepo: messageRepo,
-		tripRepo:    tripRepo,
-		publisher:   publisher,
+		messageRepo:     messageRepo,
+		tripRepo:        tripRepo,
+		reservationRepo: reservationRepo,
+		publisher:       publisher,
+		hub:             hub,
+		responseTimes:   responseTimes,
 	}
 }
 
@@ -99,13 +152,17 @@ func (s *chatService) SendMessage(ctx context.Context, tripID string, userID int
 	// ────────────────────────────────────────────────────────────────
 	// GOROUTINE 2: Verify trip exists and is active
 	// ────────────────────────────────────────────────────────────────
+	// The trip is kept to know whether the sender is the driver (response-time tracking)
+	// It is only read after all goroutines are done
+	var trip *domain.Trip
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		log.Debug().Msg("🔍 Goroutine 2: Verifying trip exists")
 
 		start := time.Now()
-		_, err := s.tripRepo.FindByID(ctx, tripID)
+		var err error
+		trip, err = s.tripRepo.FindByID(ctx, tripID)
 		duration := time.Since(start)
 
 		if err != nil {
@@ -118,26 +175,28 @@ func (s *chatService) SendMessage(ctx context.Context, tripID string, userID int
 	}()
 
 	// ────────────────────────────────────────────────────────────────
-	// GOROUTINE 3: Publish event to RabbitMQ (for notifications, analytics, etc.)
+	// GOROUTINE 3: Load the confirmed passengers (mentions in the chat.message event only notify participants)
 	// ────────────────────────────────────────────────────────────────
+	// Read only after all goroutines are done; nil if the lookup failed
+	var passengerIDs []int64
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		log.Debug().Msg("📡 Goroutine 3: Publishing message event to RabbitMQ")
+		log.Debug().Msg("👥 Goroutine 3: Loading confirmed passengers")
 
 		start := time.Now()
-		err := s.publisher.PublishChatMessage(tripID, userID, message)
+		ids, err := s.reservationRepo.FindConfirmedPassengerIDs(ctx, tripID)
 		duration := time.Since(start)
 
 		if err != nil {
-			// Don't fail the request if event publishing fails (eventual consistency)
-			log.Warn().Err(err).Msg("⚠️  Failed to publish chat event (non-critical)")
+			log.Warn().Err(err).Msg("⚠️  Failed to load confirmed passengers (non-critical)")
 		} else {
-			log.Debug().Dur("duration", duration).Msg("✅ Chat event published successfully")
+			passengerIDs = ids
+			log.Debug().Dur("duration", duration).Msg("✅ Confirmed passengers loaded successfully")
 		}
 
 		// Non-critical, don't propagate error
-		results <- Result{Name: "publish_event", Error: nil, Duration: duration}
+		results <- Result{Name: "load_participants", Error: nil, Duration: duration}
 	}()
 
 	// ────────────────────────────────────────────────────────────────
@@ -199,6 +258,20 @@ func (s *chatService) SendMessage(ctx context.Context, tripID string, userID int
 		return nil, criticalErrors[0]
 	}
 
+	// Deliver to realtime subscribers (SSE / WebSocket) only once the message is persisted
+	s.hub.Publish(chatMessageEvent(msg))
+
+	// Publish the event for notifications and analytics (non-critical: eventual consistency)
+	// Without the passengers only the driver can be notified of a mention
+	if err := s.publisher.PublishChatMessage(tripID, userID, message, trip.DriverID, passengerIDs); err != nil {
+		log.Warn().Err(err).Msg("⚠️  Failed to publish chat event (non-critical)")
+	}
+
+	// A driver message answering passengers updates the driver's response time (non-critical)
+	if s.responseTimes != nil {
+		s.responseTimes.TrackChatMessage(ctx, trip, msg)
+	}
+
 	log.Info().
 		Str("message_id", msg.ID.Hex()).
 		Str("trip_id", tripID).
@@ -208,19 +281,185 @@ func (s *chatService) SendMessage(ctx context.Context, tripID string, userID int
 	return msg, nil
 }
 
-// GetMessages retrieves messages for a trip
-func (s *chatService) GetMessages(ctx context.Context, tripID string) ([]*dao.Message, error) {
-	// Get last 50 messages
-	messages, err := s.messageRepo.FindByTripID(ctx, tripID, 50)
+// GetMessages retrieves a page of the chat history of a trip
+// One extra message is requested to know whether older messages remain
+func (s *chatService) GetMessages(ctx context.Context, tripID string, before time.Time, beforeID primitive.ObjectID, limit int) (*MessagePage, error) {
+	if limit <= 0 {
+		limit = DefaultMessagesPageSize
+	}
+	if limit > MaxMessagesPageSize {
+		limit = MaxMessagesPageSize
+	}
+
+	messages, err := s.messageRepo.FindBefore(ctx, tripID, before, beforeID, limit+1)
 	if err != nil {
 		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to get messages")
 		return nil, err
 	}
 
+	page := &MessagePage{Messages: messages}
+	if len(messages) > limit {
+		// Oldest first: the extra message is the first one
+		page.Messages = messages[1:]
+		page.HasMore = true
+	}
+	if page.HasMore && len(page.Messages) > 0 {
+		nextBefore := page.Messages[0].CreatedAt
+		page.NextBefore = &nextBefore
+		page.NextBeforeID = page.Messages[0].ID.Hex()
+	}
+	if page.Messages == nil {
+		page.Messages = []*dao.Message{}
+	}
+
 	log.Debug().
 		Str("trip_id", tripID).
-		Int("count", len(messages)).
+		Int("count", len(page.Messages)).
+		Bool("has_more", page.HasMore).
 		Msg("Retrieved chat messages")
 
-	return messages, nil
+	return page, nil
+}
+
+// GetUnreadCount counts the messages sent by others after the user's read marker
+func (s *chatService) GetUnreadCount(ctx context.Context, tripID string, userID int64) (*UnreadCount, error) {
+	if err := s.checkParticipant(ctx, tripID, userID); err != nil {
+		return nil, err
+	}
+
+	marker, err := s.messageRepo.FindReadMarker(ctx, tripID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UnreadCount{TripID: tripID}
+	var readAt time.Time
+	if marker != nil {
+		readAt = marker.LastReadAt
+		result.LastReadAt = &marker.LastReadAt
+	}
+
+	result.Unread, err = s.messageRepo.CountUnread(ctx, tripID, userID, readAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MarkAsRead moves the user's read marker forward
+//
+//   - messageID set: read up to that message (must belong to the trip)
+//   - messageID empty: read everything sent until now
+//
+// The marker never moves backwards, so reading an old message on one device
+// does not bring back unread messages already read on another.
+func (s *chatService) MarkAsRead(ctx context.Context, tripID string, userID int64, messageID string) (*dao.ChatReadMarker, error) {
+	if err := s.checkParticipant(ctx, tripID, userID); err != nil {
+		return nil, err
+	}
+
+	// MongoDB stores dates with millisecond precision
+	readAt := time.Now().UTC().Truncate(time.Millisecond)
+	if messageID != "" {
+		id, err := primitive.ObjectIDFromHex(messageID)
+		if err != nil {
+			return nil, ErrInvalidMessageID
+		}
+		message, err := s.messageRepo.FindByID(ctx, tripID, id)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil {
+			return nil, ErrInvalidMessageID
+		}
+		readAt = message.CreatedAt.UTC().Truncate(time.Millisecond)
+	}
+
+	marker, err := s.messageRepo.MarkRead(ctx, tripID, userID, readAt, messageID)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Int64("user_id", userID).Msg("Failed to mark chat as read")
+		return nil, err
+	}
+
+	return marker, nil
+}
+
+// checkParticipant verifies the trip exists and the user is its driver or one of its passengers
+// (a reservation confirmed or cancelled, as for the chat export): read markers of outsiders are never created
+func (s *chatService) checkParticipant(ctx context.Context, tripID string, userID int64) error {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+	if trip.DriverID == userID {
+		return nil
+	}
+
+	isPassenger, err := s.reservationRepo.HasPassenger(ctx, tripID, userID)
+	if err != nil {
+		return err
+	}
+	if !isPassenger {
+		return domain.ErrUnauthorized
+	}
+	return nil
+}
+
+// Subscribe registers a realtime subscriber for a trip and collects the events it missed
+//
+// Replay on reconnection (lastEventID = last event ID received by the client):
+//   - Chat messages are read from MongoDB (durable)
+//   - Status changes come from the hub's in-memory history (best effort)
+func (s *chatService) Subscribe(ctx context.Context, tripID, lastEventID string) (*realtime.Subscription, []realtime.Event, error) {
+	if _, err := s.tripRepo.FindByID(ctx, tripID); err != nil {
+		return nil, nil, err
+	}
+
+	// Subscribe before reading the backlog so no event falls between both
+	sub := s.hub.Subscribe(tripID)
+	if lastEventID == "" {
+		return sub, nil, nil
+	}
+
+	afterID, err := primitive.ObjectIDFromHex(lastEventID)
+	if err != nil {
+		// Unknown ID format: nothing to replay, the client just continues live
+		log.Warn().Str("last_event_id", lastEventID).Msg("Invalid Last-Event-ID, skipping replay")
+		return sub, nil, nil
+	}
+
+	messages, err := s.messageRepo.FindAfter(ctx, tripID, afterID, maxReplayMessages)
+	if err != nil {
+		s.hub.Unsubscribe(sub)
+		return nil, nil, err
+	}
+
+	replay := make([]realtime.Event, 0, len(messages))
+	for _, msg := range messages {
+		replay = append(replay, chatMessageEvent(msg))
+	}
+	for _, event := range s.hub.EventsSince(tripID, lastEventID) {
+		if event.Type != realtime.EventChatMessage {
+			replay = append(replay, event)
+		}
+	}
+	sort.Slice(replay, func(i, j int) bool { return replay[i].ID < replay[j].ID })
+
+	return sub, replay, nil
+}
+
+// Unsubscribe removes a realtime subscriber
+func (s *chatService) Unsubscribe(sub *realtime.Subscription) {
+	s.hub.Unsubscribe(sub)
+}
+
+// chatMessageEvent builds the realtime event for a chat message (event ID = message ID)
+func chatMessageEvent(msg *dao.Message) realtime.Event {
+	return realtime.Event{
+		ID:     msg.ID.Hex(),
+		Type:   realtime.EventChatMessage,
+		TripID: msg.TripID,
+		Data:   msg,
+	}
 }
@@ -8,9 +8,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"trips-api/internal/dao"
 	"trips-api/internal/domain"
+	"trips-api/internal/realtime"
 )
 
 // Mock repositories for testing
@@ -32,6 +34,59 @@ func (m *MockMessageRepository) FindByTripID(ctx context.Context, tripID string,
 	return args.Get(0).([]*dao.Message), args.Error(1)
 }
 
+func (m *MockMessageRepository) FindAfter(ctx context.Context, tripID string, afterID primitive.ObjectID, limit int) ([]*dao.Message, error) {
+	args := m.Called(ctx, tripID, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dao.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindBefore(ctx context.Context, tripID string, before time.Time, beforeID primitive.ObjectID, limit int) ([]*dao.Message, error) {
+	args := m.Called(ctx, tripID, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dao.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindByID(ctx context.Context, tripID string, id primitive.ObjectID) (*dao.Message, error) {
+	args := m.Called(ctx, tripID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dao.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindFirstUnanswered(ctx context.Context, tripID string, responderID int64, reply *dao.Message) (*dao.Message, error) {
+	args := m.Called(ctx, tripID, responderID, reply)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dao.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountUnread(ctx context.Context, tripID string, userID int64, readAt time.Time) (int64, error) {
+	args := m.Called(ctx, tripID, userID, readAt)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) FindReadMarker(ctx context.Context, tripID string, userID int64) (*dao.ChatReadMarker, error) {
+	args := m.Called(ctx, tripID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dao.ChatReadMarker), args.Error(1)
+}
+
+func (m *MockMessageRepository) MarkRead(ctx context.Context, tripID string, userID int64, readAt time.Time, messageID string) (*dao.ChatReadMarker, error) {
+	args := m.Called(ctx, tripID, userID, readAt, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dao.ChatReadMarker), args.Error(1)
+}
+
 type MockTripRepositoryForChat struct {
 	mock.Mock
 }
@@ -83,7 +138,7 @@ type MockPublisherForChat struct {
 	mock.Mock
 }
 
-func (m *MockPublisherForChat) PublishChatMessage(tripID string, userID int64, message string) error {
+func (m *MockPublisherForChat) PublishChatMessage(tripID string, userID int64, message string, driverID int64, passengerIDs []int64) error {
 	args := m.Called(tripID, userID, message)
 	return args.Error(0)
 }
@@ -106,7 +161,7 @@ func TestSendMessage_Success(t *testing.T) {
 	mockPublisher.On("PublishChatMessage", "trip-123", int64(1), "Hello").Return(nil)
 	mockTripRepo.On("UpdateLastActivity", mock.Anything, "trip-123", mock.Anything).Return(nil)
 
-	service := NewChatService(mockMessageRepo, mockTripRepo, mockPublisher)
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
 
 	// Act
 	message, err := service.SendMessage(context.Background(), "trip-123", 1, "Test User", "Hello")
@@ -132,7 +187,7 @@ func TestSendMessage_EmptyMessage(t *testing.T) {
 	mockTripRepo := new(MockTripRepositoryForChat)
 	mockPublisher := new(MockPublisherForChat)
 
-	service := NewChatService(mockMessageRepo, mockTripRepo, mockPublisher)
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
 
 	// Act
 	message, err := service.SendMessage(context.Background(), "trip-123", 1, "Test User", "")
@@ -160,7 +215,7 @@ func TestSendMessage_TripNotFound(t *testing.T) {
 	mockPublisher.On("PublishChatMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mockTripRepo.On("UpdateLastActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	service := NewChatService(mockMessageRepo, mockTripRepo, mockPublisher)
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
 
 	// Act
 	message, err := service.SendMessage(context.Background(), "invalid-trip", 1, "Test User", "Hello")
@@ -187,7 +242,7 @@ func TestSendMessage_DatabaseError(t *testing.T) {
 	mockPublisher.On("PublishChatMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	mockTripRepo.On("UpdateLastActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	service := NewChatService(mockMessageRepo, mockTripRepo, mockPublisher)
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
 
 	// Act
 	message, err := service.SendMessage(context.Background(), "trip-123", 1, "Test User", "Hello")
@@ -214,7 +269,7 @@ func TestSendMessage_NonCriticalFailureDoesNotBreak(t *testing.T) {
 	mockPublisher.On("PublishChatMessage", "trip-123", int64(1), "Hello").Return(errors.New("rabbitmq down"))
 	mockTripRepo.On("UpdateLastActivity", mock.Anything, "trip-123", mock.Anything).Return(errors.New("update failed"))
 
-	service := NewChatService(mockMessageRepo, mockTripRepo, mockPublisher)
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
 
 	// Act
 	message, err := service.SendMessage(context.Background(), "trip-123", 1, "Test User", "Hello")
@@ -242,19 +297,56 @@ func TestGetMessages_Success(t *testing.T) {
 		{TripID: "trip-123", UserID: 2, UserName: "User 2", Message: "Hi there"},
 	}
 
-	mockMessageRepo.On("FindByTripID", mock.Anything, "trip-123", 50).Return(expectedMessages, nil)
+	// One extra message is requested to detect older pages
+	mockMessageRepo.On("FindBefore", mock.Anything, "trip-123", time.Time{}, 51).Return(expectedMessages, nil)
+
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
+
+	// Act
+	page, err := service.GetMessages(context.Background(), "trip-123", time.Time{}, primitive.NilObjectID, 50)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, page)
+	assert.Len(t, page.Messages, 2)
+	assert.False(t, page.HasMore)
+	assert.Nil(t, page.NextBefore)
+	assert.Equal(t, "Hello", page.Messages[0].Message)
+	assert.Equal(t, "Hi there", page.Messages[1].Message)
+
+	mockMessageRepo.AssertExpectations(t)
+}
+
+// TestGetMessages_HasMore verifies that the extra message is dropped and next_before points to the page start
+func TestGetMessages_HasMore(t *testing.T) {
+	// Arrange
+	mockMessageRepo := new(MockMessageRepository)
+	mockTripRepo := new(MockTripRepositoryForChat)
+	mockPublisher := new(MockPublisherForChat)
+
+	before := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	base := before.Add(-time.Hour)
+	olderMessages := []*dao.Message{
+		{TripID: "trip-123", Message: "extra", CreatedAt: base},
+		{TripID: "trip-123", Message: "first", CreatedAt: base.Add(time.Minute)},
+		{TripID: "trip-123", Message: "second", CreatedAt: base.Add(2 * time.Minute)},
+	}
+
+	mockMessageRepo.On("FindBefore", mock.Anything, "trip-123", before, 3).Return(olderMessages, nil)
 
-	service := NewChatService(mockMessageRepo, mockTripRepo, mockPublisher)
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
 
 	// Act
-	messages, err := service.GetMessages(context.Background(), "trip-123")
+	page, err := service.GetMessages(context.Background(), "trip-123", before, primitive.NilObjectID, 2)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, messages)
-	assert.Len(t, messages, 2)
-	assert.Equal(t, "Hello", messages[0].Message)
-	assert.Equal(t, "Hi there", messages[1].Message)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Messages, 2)
+	assert.Equal(t, "first", page.Messages[0].Message)
+	if assert.NotNil(t, page.NextBefore) {
+		assert.Equal(t, base.Add(time.Minute), *page.NextBefore)
+	}
 
 	mockMessageRepo.AssertExpectations(t)
 }
@@ -266,17 +358,18 @@ func TestGetMessages_Empty(t *testing.T) {
 	mockTripRepo := new(MockTripRepositoryForChat)
 	mockPublisher := new(MockPublisherForChat)
 
-	mockMessageRepo.On("FindByTripID", mock.Anything, "trip-123", 50).Return([]*dao.Message{}, nil)
+	mockMessageRepo.On("FindBefore", mock.Anything, "trip-123", time.Time{}, 51).Return([]*dao.Message{}, nil)
 
-	service := NewChatService(mockMessageRepo, mockTripRepo, mockPublisher)
+	service := NewChatService(mockMessageRepo, mockTripRepo, nil, mockPublisher, realtime.NewHub(realtime.DefaultHistorySize), nil)
 
 	// Act
-	messages, err := service.GetMessages(context.Background(), "trip-123")
+	page, err := service.GetMessages(context.Background(), "trip-123", time.Time{}, primitive.NilObjectID, 50)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.NotNil(t, messages)
-	assert.Len(t, messages, 0)
+	assert.NotNil(t, page)
+	assert.Len(t, page.Messages, 0)
+	assert.False(t, page.HasMore)
 
 	mockMessageRepo.AssertExpectations(t)
 }
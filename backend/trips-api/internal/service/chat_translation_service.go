@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"trips-api/internal/clients"
+	"trips-api/internal/dao"
+	"trips-api/internal/repository"
+)
+
+// maxConcurrentTranslations caps the provider calls made in parallel for one page of messages
+const maxConcurrentTranslations = 4
+
+// ErrTranslationUnavailable is returned when a translation is requested but no provider is configured
+var ErrTranslationUnavailable = errors.New("chat translation is not enabled")
+
+// ErrInvalidLanguage is returned when translate_to is not a language code
+var ErrInvalidLanguage = errors.New("translate_to must be a language code like es, en or pt-BR")
+
+// languageCodePattern accepts ISO 639-1/639-3 codes with an optional region (es, en, pt-BR, zh-Hant)
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,4})?$`)
+
+// ChatTranslationService translates chat messages on demand
+//
+// Translations are cached per message and language (message_translations), so each message
+// is sent to the provider at most once per language. Translation is best effort: a message
+// the provider fails to translate is returned as is.
+type ChatTranslationService interface {
+	// TranslateMessages sets the Translation of the messages written by other users
+	//
+	//   - targetLanguage set: translate to that language (ErrTranslationUnavailable if there is no provider)
+	//   - targetLanguage empty: translate to the user's preferred language if auto-translate is on in users-api
+	//
+	// Returns the language the messages were translated to ("" if they were not translated)
+	TranslateMessages(ctx context.Context, messages []*dao.Message, userID int64, targetLanguage string) (string, error)
+}
+
+type chatTranslationService struct {
+	translator      clients.TranslationClient
+	translationRepo repository.MessageTranslationRepository
+	usersClient     clients.UsersClient
+}
+
+// NewChatTranslationService creates a new chat translation service instance
+// translator may be nil, which disables translation (auto-translate is then ignored)
+func NewChatTranslationService(translator clients.TranslationClient, translationRepo repository.MessageTranslationRepository, usersClient clients.UsersClient) ChatTranslationService {
+	return &chatTranslationService{
+		translator:      translator,
+		translationRepo: translationRepo,
+		usersClient:     usersClient,
+	}
+}
+
+// TranslateMessages translates a page of messages, reading cached translations first
+func (s *chatTranslationService) TranslateMessages(ctx context.Context, messages []*dao.Message, userID int64, targetLanguage string) (string, error) {
+	language, err := s.resolveLanguage(ctx, userID, targetLanguage)
+	if err != nil || language == "" {
+		return "", err
+	}
+
+	// The user's own messages are never translated
+	pending := make([]*dao.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.UserID != userID && strings.TrimSpace(msg.Message) != "" {
+			pending = append(pending, msg)
+		}
+	}
+	if len(pending) == 0 {
+		return language, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(pending))
+	for _, msg := range pending {
+		ids = append(ids, msg.ID)
+	}
+	cached, err := s.translationRepo.FindByMessages(ctx, ids, language)
+	if err != nil {
+		// The cache is an optimization: translate everything again rather than failing the history
+		log.Warn().Err(err).Str("language", language).Msg("Failed to read cached translations")
+		cached = nil
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxConcurrentTranslations)
+	for _, msg := range pending {
+		if translation, ok := cached[msg.ID]; ok {
+			setTranslation(msg, translation)
+			continue
+		}
+
+		wg.Add(1)
+		go func(msg *dao.Message) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			translation, err := s.translate(ctx, msg, language)
+			if err != nil {
+				log.Warn().Err(err).Str("message_id", msg.ID.Hex()).Str("language", language).Msg("Failed to translate chat message")
+				return
+			}
+			setTranslation(msg, translation)
+		}(msg)
+	}
+	wg.Wait()
+
+	return language, nil
+}
+
+// resolveLanguage picks the target language: the requested one, or the user's auto-translate preference
+func (s *chatTranslationService) resolveLanguage(ctx context.Context, userID int64, targetLanguage string) (string, error) {
+	if targetLanguage != "" {
+		if !languageCodePattern.MatchString(targetLanguage) {
+			return "", ErrInvalidLanguage
+		}
+		if s.translator == nil {
+			return "", ErrTranslationUnavailable
+		}
+		return targetLanguage, nil
+	}
+
+	if s.translator == nil || s.usersClient == nil {
+		return "", nil
+	}
+
+	prefs, err := s.usersClient.GetChatPreferences(ctx, userID)
+	if err != nil {
+		// Without the preference the history is returned untranslated, as if auto-translate were off
+		log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to get chat preferences from users-api")
+		return "", nil
+	}
+	if !prefs.AutoTranslate || !languageCodePattern.MatchString(prefs.PreferredLanguage) {
+		return "", nil
+	}
+	return prefs.PreferredLanguage, nil
+}
+
+// translate calls the provider and caches the result
+func (s *chatTranslationService) translate(ctx context.Context, msg *dao.Message, language string) (*dao.MessageTranslation, error) {
+	result, err := s.translator.Translate(ctx, msg.Message, language)
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &dao.MessageTranslation{
+		MessageID:      msg.ID,
+		TripID:         msg.TripID,
+		Language:       language,
+		Text:           result.Text,
+		SourceLanguage: result.SourceLanguage,
+		Provider:       s.translator.Provider(),
+	}
+	if err := s.translationRepo.Save(ctx, translation); err != nil {
+		// Not cached: the next request translates it again
+		log.Warn().Err(err).Str("message_id", msg.ID.Hex()).Msg("Failed to cache chat message translation")
+	}
+	return translation, nil
+}
+
+// setTranslation attaches a translation unless the message was already written in the target language
+func setTranslation(msg *dao.Message, translation *dao.MessageTranslation) {
+	if translation.SourceLanguage != "" && strings.EqualFold(baseLanguage(translation.SourceLanguage), baseLanguage(translation.Language)) {
+		return
+	}
+	msg.Translation = translation
+}
+
+// baseLanguage drops the region of a language code (pt-BR → pt)
+func baseLanguage(code string) string {
+	if i := strings.IndexByte(code, '-'); i >= 0 {
+		return code[:i]
+	}
+	return code
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"math"
+	"trips-api/internal/cities"
+	"trips-api/internal/domain"
+	"trips-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// resolveTripCities resuelve origen y destino contra el catálogo de ciudades
+// Compartido con los viajes recurrentes (plantillas); la ruta se calcula con domain.RouteID
+func resolveTripCities(catalog cities.Catalog, origin, destination *domain.Location) error {
+	if err := resolveCity(catalog, "origin", origin); err != nil {
+		return err
+	}
+	return resolveCity(catalog, "destination", destination)
+}
+
+// resolveCity completa city_id y reemplaza ciudad, provincia y país por los canónicos del catálogo
+//
+// Errores:
+// - UNKNOWN_CITY si el request envió un city_id que no existe
+// - AMBIGUOUS_CITY si el nombre coincide con varias ciudades (Details con las sugerencias)
+//
+// Una ciudad que no está en el catálogo se guarda como texto libre, sin city_id.
+func resolveCity(catalog cities.Catalog, field string, location *domain.Location) error {
+	resolution, err := catalog.Resolve(*location)
+	if err != nil {
+		return domain.UnknownCityError(field, location.CityID)
+	}
+
+	if resolution.Ambiguous() {
+		suggestions := make([]domain.CitySuggestion, 0, len(resolution.Suggestions))
+		for _, city := range resolution.Suggestions {
+			suggestions = append(suggestions, domain.NewCitySuggestion(city))
+		}
+		return domain.AmbiguousCityError(field, location.City, suggestions)
+	}
+
+	if resolution.City == nil {
+		location.CityID = ""
+		log.Debug().Str("field", field).Str("city", location.City).Msg("City not in catalog, stored as free text")
+		return nil
+	}
+
+	if resolution.Match == domain.CityMatchFuzzy {
+		log.Info().
+			Str("field", field).
+			Str("city", location.City).
+			Str("city_id", resolution.City.ID).
+			Msg("City resolved by fuzzy match")
+	}
+
+	location.CityID = resolution.City.ID
+	location.City = resolution.City.Name
+	location.Province = resolution.City.Province
+	location.Country = resolution.City.Country
+	return nil
+}
+
+// recordRoute suma el viaje al catálogo de rutas (no crítico: solo se loguea si falla)
+func recordRoute(ctx context.Context, catalog cities.Catalog, cityRepo repository.CityRepository, trip *domain.Trip) {
+	if trip.RouteID == "" {
+		return
+	}
+
+	origin, _ := catalog.Get(trip.Origin.CityID)
+	destination, _ := catalog.Get(trip.Destination.CityID)
+	route := domain.InterCityRoute{
+		ID:                trip.RouteID,
+		OriginCityID:      trip.Origin.CityID,
+		DestinationCityID: trip.Destination.CityID,
+		DistanceKm:        math.Round(domain.DistanceKm(origin.Coordinates, destination.Coordinates)),
+		LastTripAt:        trip.CreatedAt,
+	}
+
+	if err := cityRepo.RecordRoute(ctx, route); err != nil {
+		log.Warn().Err(err).Str("trip_id", trip.ID.Hex()).Str("route_id", trip.RouteID).Msg("Failed to record trip route")
+	}
+}
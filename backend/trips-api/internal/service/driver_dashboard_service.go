@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+	"trips-api/internal/clients"
+	"trips-api/internal/domain"
+	"trips-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DriverDashboardService define las operaciones del panel del conductor
+type DriverDashboardService interface {
+	// ListDriverTrips lista los viajes del conductor con sus métricas y el resumen de todos sus viajes
+	// includeBookings agrega el conteo de reservas por estado de bookings-api a cada viaje de la página
+	// authToken: JWT del conductor, reenviado a bookings-api (format: "Bearer {token}")
+	ListDriverTrips(ctx context.Context, driverID int64, scope string, page, limit int, includeBookings bool, authToken string) (*domain.DriverTripsResponse, error)
+}
+
+type driverDashboardService struct {
+	tripRepo       repository.TripRepository
+	bookingsClient clients.BookingsClient
+}
+
+// NewDriverDashboardService crea una nueva instancia del servicio del panel del conductor
+// bookingsClient puede ser nil: include=bookings se ignora
+func NewDriverDashboardService(tripRepo repository.TripRepository, bookingsClient clients.BookingsClient) DriverDashboardService {
+	return &driverDashboardService{
+		tripRepo:       tripRepo,
+		bookingsClient: bookingsClient,
+	}
+}
+
+// ListDriverTrips implementa GET /trips/mine
+//
+// Las métricas salen de trips-api (reserved_seats y el ledger de reservas), así que el panel
+// funciona aunque bookings-api no responda: el enriquecimiento es best effort y un viaje cuyo
+// conteo falla se devuelve sin "bookings"
+func (s *driverDashboardService) ListDriverTrips(ctx context.Context, driverID int64, scope string, page, limit int, includeBookings bool, authToken string) (*domain.DriverTripsResponse, error) {
+	if scope == "" {
+		scope = domain.DriverTripsScopeAll
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 50 {
+		limit = 50 // Cada viaje puede implicar una llamada a bookings-api
+	}
+
+	trips, total, summary, err := s.tripRepo.AggregateDriverTrips(ctx, driverID, scope, time.Now(), page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if includeBookings && s.bookingsClient != nil {
+		s.enrichWithBookings(ctx, trips, authToken)
+	}
+
+	return &domain.DriverTripsResponse{
+		Trips:   trips,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+		Scope:   scope,
+		Summary: *summary,
+	}, nil
+}
+
+// enrichWithBookings consulta bookings-api en paralelo, un request por viaje de la página
+func (s *driverDashboardService) enrichWithBookings(ctx context.Context, trips []domain.DriverTrip, authToken string) {
+	var wg sync.WaitGroup
+	for i := range trips {
+		wg.Add(1)
+		go func(trip *domain.DriverTrip) {
+			defer wg.Done()
+
+			counts, err := s.bookingsClient.GetTripBookingCounts(ctx, trip.ID.Hex(), authToken)
+			if err != nil {
+				log.Warn().
+					Err(err).
+					Str("trip_id", trip.ID.Hex()).
+					Msg("Failed to get trip bookings from bookings-api")
+				return
+			}
+			trip.Stats.Bookings = counts
+		}(&trips[i])
+	}
+	wg.Wait()
+}
@@ -19,6 +19,10 @@ type IdempotencyService interface {
 	// - shouldProcess=false: el evento YA fue procesado, se debe saltar (idempotencia)
 	// - error: solo si hay un error real de sistema
 	CheckAndMarkEvent(ctx context.Context, eventID, eventType string) (shouldProcess bool, err error)
+
+	// ReleaseEvent quita la marca de un evento que falló (NACK), para que su reentrega se procese
+	// Los handlers solo retornan error antes de aplicar cambios no idempotentes, así que reprocesar es seguro
+	ReleaseEvent(ctx context.Context, eventID string) error
 }
 
 type idempotencyService struct {
@@ -89,3 +93,11 @@ func (s *idempotencyService) CheckAndMarkEvent(ctx context.Context, eventID, eve
 	// Evento marcado exitosamente como procesado, se debe procesar
 	return true, nil
 }
+
+// ReleaseEvent implementa la liberación de la marca de idempotencia de un evento fallido
+func (s *idempotencyService) ReleaseEvent(ctx context.Context, eventID string) error {
+	if err := s.eventRepo.UnmarkEventProcessed(ctx, eventID); err != nil {
+		return fmt.Errorf("failed to release event: %w", err)
+	}
+	return nil
+}
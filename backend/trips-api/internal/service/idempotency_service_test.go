@@ -26,6 +26,11 @@ func (m *MockEventRepository) MarkEventProcessed(ctx context.Context, event *dom
 	return args.Error(0)
 }
 
+func (m *MockEventRepository) UnmarkEventProcessed(ctx context.Context, eventID string) error {
+	args := m.Called(ctx, eventID)
+	return args.Error(0)
+}
+
 // TestCheckAndMarkEvent_FirstEventProcessed tests that a new event is marked and should be processed
 func TestCheckAndMarkEvent_FirstEventProcessed(t *testing.T) {
 	// Arrange
@@ -0,0 +1,537 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"trips-api/internal/cities"
+	"trips-api/internal/clients"
+	"trips-api/internal/domain"
+	"trips-api/internal/market"
+	"trips-api/internal/messaging"
+	"trips-api/internal/repository"
+	"trips-api/internal/richtext"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RecurringTripService define las operaciones de los viajes recurrentes (agenda semanal)
+type RecurringTripService interface {
+	// CreateRecurringTrip registra la plantilla y materializa de inmediato las instancias del horizonte
+	CreateRecurringTrip(ctx context.Context, driverID int64, authToken string, request domain.CreateRecurringTripRequest) (*domain.RecurringTrip, error)
+
+	// GetRecurringTrip obtiene un viaje recurrente del conductor
+	GetRecurringTrip(ctx context.Context, id string, driverID int64) (*domain.RecurringTrip, error)
+
+	// ListRecurringTrips lista los viajes recurrentes del conductor
+	ListRecurringTrips(ctx context.Context, driverID int64) ([]domain.RecurringTrip, error)
+
+	// UpdateRecurringTrip modifica la plantilla; aplica a las instancias que se materialicen desde ahora
+	UpdateRecurringTrip(ctx context.Context, id string, driverID int64, request domain.UpdateRecurringTripRequest) (*domain.RecurringTrip, error)
+
+	// DeleteRecurringTrip elimina la plantilla y las instancias futuras sin reservas
+	DeleteRecurringTrip(ctx context.Context, id string, driverID int64) error
+
+	// MaterializeDue crea las instancias de todos los viajes recurrentes activos dentro del horizonte
+	// Retorna la cantidad de viajes creados
+	MaterializeDue(ctx context.Context) (int, error)
+
+	// StartScheduler ejecuta MaterializeDue periódicamente hasta que ctx se cancele
+	StartScheduler(ctx context.Context, interval time.Duration)
+}
+
+type recurringTripService struct {
+	recurringRepo repository.RecurringTripRepository
+	tripRepo      repository.TripRepository
+	vacationRepo  repository.VacationRepository
+	usersClient   clients.UsersClient
+	publisher     messaging.Publisher
+	markets       market.Registry
+	catalog       cities.Catalog
+	cityRepo      repository.CityRepository
+	descriptions  richtext.Pipeline
+	horizon       time.Duration
+}
+
+// NewRecurringTripService crea una nueva instancia del servicio de viajes recurrentes
+// horizonDays: cuántos días hacia adelante se materializan instancias
+func NewRecurringTripService(
+	recurringRepo repository.RecurringTripRepository,
+	tripRepo repository.TripRepository,
+	vacationRepo repository.VacationRepository,
+	usersClient clients.UsersClient,
+	publisher messaging.Publisher,
+	markets market.Registry,
+	catalog cities.Catalog,
+	cityRepo repository.CityRepository,
+	descriptions richtext.Pipeline,
+	horizonDays int,
+) RecurringTripService {
+	if horizonDays < 1 {
+		horizonDays = 7
+	}
+
+	return &recurringTripService{
+		recurringRepo: recurringRepo,
+		tripRepo:      tripRepo,
+		vacationRepo:  vacationRepo,
+		usersClient:   usersClient,
+		publisher:     publisher,
+		markets:       markets,
+		catalog:       catalog,
+		cityRepo:      cityRepo,
+		descriptions:  descriptions,
+		horizon:       time.Duration(horizonDays) * 24 * time.Hour,
+	}
+}
+
+// CreateRecurringTrip implementa la creación de un viaje recurrente
+//
+// Validaciones:
+// - Agenda: días de la semana, hora HH:MM, duración, zona horaria y rango de fechas
+// - Puntos de encuentro, descripción, ciudades del catálogo y límites del mercado (igual que un viaje común)
+// - El conductor existe en users-api
+func (s *recurringTripService) CreateRecurringTrip(ctx context.Context, driverID int64, authToken string, request domain.CreateRecurringTripRequest) (*domain.RecurringTrip, error) {
+	timezone := strings.TrimSpace(request.Timezone)
+	if timezone == "" {
+		timezone = domain.DefaultRecurringTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, &domain.AppError{Code: domain.ErrInvalidRecurringTrip.Code, Message: fmt.Sprintf("unknown timezone %q", timezone)}
+	}
+
+	// start_date por defecto: hoy en la zona horaria del conductor
+	now := time.Now().In(loc)
+	startDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if request.StartDate != "" {
+		if startDate, err = domain.ParseRecurringDate(request.StartDate, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	var endDate *time.Time
+	if request.EndDate != nil && *request.EndDate != "" {
+		date, err := domain.ParseRecurringDate(*request.EndDate, loc)
+		if err != nil {
+			return nil, err
+		}
+		endDate = &date
+	}
+
+	description, err := s.descriptions.Process(request.DescriptionFormat, request.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	recurring := &domain.RecurringTrip{
+		DriverID:          driverID,
+		Origin:            request.Origin,
+		Destination:       request.Destination,
+		PickupPoints:      request.PickupPoints,
+		Weekdays:          request.Weekdays,
+		DepartureTime:     request.DepartureTime,
+		DurationMinutes:   request.DurationMinutes,
+		Timezone:          timezone,
+		StartDate:         startDate,
+		EndDate:           endDate,
+		PricePerSeat:      request.PricePerSeat,
+		Currency:          request.Currency,
+		TotalSeats:        request.TotalSeats,
+		Car:               request.Car,
+		Preferences:       request.Preferences,
+		Description:       description.Content,
+		DescriptionFormat: description.Format,
+		DescriptionText:   description.Text,
+		Status:            domain.RecurringTripStatusActive,
+
+		BookingCloseMinutes: request.BookingCloseMinutes,
+	}
+
+	if err := s.validateTemplate(recurring, nil); err != nil {
+		return nil, err
+	}
+
+	// Verificar que el driver existe en users-api (forward auth token) y que puede conducir
+	driver, err := s.usersClient.GetUser(ctx, driverID, authToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate driver: %w", err)
+	}
+	if !driver.CanDrive() {
+		return nil, domain.ErrDrivingNotAllowed
+	}
+
+	if err := s.recurringRepo.Create(ctx, recurring); err != nil {
+		log.Error().Err(err).Int64("driver_id", driverID).Msg("Failed to create recurring trip")
+		return nil, fmt.Errorf("failed to create recurring trip: %w", err)
+	}
+
+	log.Info().
+		Str("recurring_trip_id", recurring.ID.Hex()).
+		Int64("driver_id", driverID).
+		Ints("weekdays", recurring.Weekdays).
+		Str("departure_time", recurring.DepartureTime).
+		Msg("Recurring trip created")
+
+	// Materializar ya las instancias del horizonte: el conductor las ve sin esperar al scheduler
+	// Si falla, el scheduler las crea en el próximo ciclo
+	if _, err := s.materialize(ctx, recurring, time.Now()); err != nil {
+		log.Error().Err(err).Str("recurring_trip_id", recurring.ID.Hex()).Msg("Failed to materialize recurring trip instances")
+	}
+
+	return recurring, nil
+}
+
+// GetRecurringTrip obtiene un viaje recurrente verificando que pertenezca al conductor
+func (s *recurringTripService) GetRecurringTrip(ctx context.Context, id string, driverID int64) (*domain.RecurringTrip, error) {
+	recurring, err := s.recurringRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if recurring.IsDeleted() {
+		return nil, domain.ErrRecurringTripNotFound
+	}
+	if recurring.DriverID != driverID {
+		return nil, domain.ErrUnauthorized
+	}
+	return recurring, nil
+}
+
+// ListRecurringTrips lista los viajes recurrentes del conductor
+func (s *recurringTripService) ListRecurringTrips(ctx context.Context, driverID int64) ([]domain.RecurringTrip, error) {
+	recurringTrips, err := s.recurringRepo.FindByDriver(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring trips: %w", err)
+	}
+
+	active := recurringTrips[:0]
+	for _, recurring := range recurringTrips {
+		if !recurring.IsDeleted() {
+			active = append(active, recurring)
+		}
+	}
+	return active, nil
+}
+
+// UpdateRecurringTrip implementa la actualización parcial de un viaje recurrente
+//
+// Los viajes ya materializados no se modifican (pueden tener reservas); el conductor
+// los edita individualmente con PUT /trips/:id
+func (s *recurringTripService) UpdateRecurringTrip(ctx context.Context, id string, driverID int64, request domain.UpdateRecurringTripRequest) (*domain.RecurringTrip, error) {
+	recurring, err := s.GetRecurringTrip(ctx, id, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Timezone != nil {
+		recurring.Timezone = strings.TrimSpace(*request.Timezone)
+	}
+	loc, err := time.LoadLocation(recurring.Timezone)
+	if err != nil {
+		return nil, &domain.AppError{Code: domain.ErrInvalidRecurringTrip.Code, Message: fmt.Sprintf("unknown timezone %q", recurring.Timezone)}
+	}
+
+	if request.Origin != nil {
+		recurring.Origin = *request.Origin
+	}
+	if request.Destination != nil {
+		recurring.Destination = *request.Destination
+	}
+	if request.Weekdays != nil {
+		recurring.Weekdays = *request.Weekdays
+	}
+	if request.DepartureTime != nil {
+		recurring.DepartureTime = *request.DepartureTime
+	}
+	if request.DurationMinutes != nil {
+		recurring.DurationMinutes = *request.DurationMinutes
+	}
+	if request.EndDate != nil {
+		if *request.EndDate == "" {
+			recurring.EndDate = nil
+		} else {
+			date, err := domain.ParseRecurringDate(*request.EndDate, loc)
+			if err != nil {
+				return nil, err
+			}
+			recurring.EndDate = &date
+		}
+	}
+	if request.PricePerSeat != nil {
+		recurring.PricePerSeat = *request.PricePerSeat
+	}
+	if request.Currency != nil {
+		recurring.Currency = *request.Currency
+	}
+	if request.TotalSeats != nil {
+		recurring.TotalSeats = *request.TotalSeats
+	}
+	if request.Car != nil {
+		recurring.Car = *request.Car
+	}
+	if request.Preferences != nil {
+		recurring.Preferences = *request.Preferences
+	}
+	if request.Description != nil || request.DescriptionFormat != nil {
+		description, err := updateDescription(s.descriptions, recurring.DescriptionFormat, recurring.Description, request.DescriptionFormat, request.Description)
+		if err != nil {
+			return nil, err
+		}
+		recurring.Description = description.Content
+		recurring.DescriptionFormat = description.Format
+		recurring.DescriptionText = description.Text
+	}
+	if request.BookingCloseMinutes != nil {
+		recurring.BookingCloseMinutes = request.BookingCloseMinutes
+	}
+
+	existingPickupPoints := recurring.PickupPoints
+	if request.PickupPoints != nil {
+		recurring.PickupPoints = *request.PickupPoints
+	}
+
+	if request.Status != nil {
+		switch *request.Status {
+		case domain.RecurringTripStatusActive, domain.RecurringTripStatusPaused:
+			recurring.Status = *request.Status
+		default:
+			return nil, &domain.AppError{Code: domain.ErrInvalidRecurringTrip.Code, Message: "status must be active or paused"}
+		}
+	}
+
+	if err := s.validateTemplate(recurring, existingPickupPoints); err != nil {
+		return nil, err
+	}
+
+	if err := s.recurringRepo.Update(ctx, recurring); err != nil {
+		return nil, fmt.Errorf("failed to update recurring trip: %w", err)
+	}
+
+	log.Info().
+		Str("recurring_trip_id", recurring.ID.Hex()).
+		Int64("driver_id", driverID).
+		Str("status", recurring.Status).
+		Msg("Recurring trip updated")
+
+	// Reactivado o con agenda nueva: completar el horizonte sin esperar al scheduler
+	if recurring.Status == domain.RecurringTripStatusActive {
+		if _, err := s.materialize(ctx, recurring, time.Now()); err != nil {
+			log.Error().Err(err).Str("recurring_trip_id", recurring.ID.Hex()).Msg("Failed to materialize recurring trip instances")
+		}
+	}
+
+	return recurring, nil
+}
+
+// DeleteRecurringTrip elimina un viaje recurrente (soft delete)
+//
+// Las instancias futuras sin reservas se eliminan y se publica trip.deleted por cada una.
+// Las que ya tienen pasajeros se conservan: el conductor decide si las cancela.
+func (s *recurringTripService) DeleteRecurringTrip(ctx context.Context, id string, driverID int64) error {
+	recurring, err := s.GetRecurringTrip(ctx, id, driverID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.recurringRepo.SoftDelete(ctx, recurring.ID); err != nil {
+		return err
+	}
+
+	trips, err := s.tripRepo.FindUpcomingByRecurringTrip(ctx, id, time.Now())
+	if err != nil {
+		// No fallar: la serie ya no genera instancias; las existentes se pueden borrar a mano
+		log.Error().Err(err).Str("recurring_trip_id", id).Msg("Failed to find recurring trip instances")
+		return nil
+	}
+
+	deleted, kept := 0, 0
+	for i := range trips {
+		trip := &trips[i]
+		if trip.ReservedSeats > 0 || trip.HeldSeats > 0 || (trip.Status != domain.TripStatusPublished && trip.Status != domain.TripStatusPaused) {
+			kept++
+			continue
+		}
+
+		// Publicar evento trip.deleted ANTES de eliminar (fire-and-forget)
+		s.publisher.PublishTripDeleted(ctx, trip, driverID, "Recurring trip deleted by owner")
+
+		if err := s.tripRepo.Delete(ctx, trip.ID.Hex()); err != nil {
+			log.Error().Err(err).Str("trip_id", trip.ID.Hex()).Msg("Failed to delete recurring trip instance")
+			kept++
+			continue
+		}
+		deleted++
+	}
+
+	log.Info().
+		Str("recurring_trip_id", id).
+		Int64("driver_id", driverID).
+		Int("deleted_instances", deleted).
+		Int("kept_instances", kept).
+		Msg("Recurring trip deleted")
+
+	return nil
+}
+
+// MaterializeDue recorre los viajes recurrentes activos y crea las instancias pendientes
+// Un error en una serie no detiene al resto; se reintenta en el próximo ciclo
+func (s *recurringTripService) MaterializeDue(ctx context.Context) (int, error) {
+	recurringTrips, err := s.recurringRepo.FindActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	created := 0
+	for i := range recurringTrips {
+		n, err := s.materialize(ctx, &recurringTrips[i], now)
+		if err != nil {
+			log.Error().Err(err).Str("recurring_trip_id", recurringTrips[i].ID.Hex()).Msg("Failed to materialize recurring trip instances")
+		}
+		created += n
+	}
+
+	return created, nil
+}
+
+// StartScheduler materializa periódicamente las instancias de los viajes recurrentes
+// Bloquea hasta que ctx se cancele, debe ejecutarse en una goroutine
+func (s *recurringTripService) StartScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Ejecutar inmediatamente al iniciar para cubrir el tiempo que el servicio estuvo apagado
+		if created, err := s.MaterializeDue(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to materialize recurring trips")
+		} else if created > 0 {
+			log.Info().Int("created_trips", created).Msg("Recurring trips materialized")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Recurring trips scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// materialize crea un Trip por cada ocurrencia entre materialized_until (o now) y now + horizonte
+//
+//   - Las ocurrencias dentro de una vacación del conductor o rechazadas por el mercado se omiten
+//   - Las que ya existen (índice único recurring_trip_id + departure_datetime) se omiten
+//   - Publica trip.created por cada viaje creado, sin snapshot del conductor: el scheduler
+//     no tiene el token del conductor y search-api lo obtiene de users-api
+//   - Ante un error de base de datos avanza materialized_until solo hasta la última
+//     ocurrencia procesada, así el próximo ciclo reintenta desde ahí
+func (s *recurringTripService) materialize(ctx context.Context, recurring *domain.RecurringTrip, now time.Time) (int, error) {
+	after := now
+	if recurring.MaterializedUntil != nil && recurring.MaterializedUntil.After(after) {
+		after = *recurring.MaterializedUntil
+	}
+	until := now.Add(s.horizon)
+	if !until.After(after) {
+		return 0, nil
+	}
+
+	occurrences, err := recurring.Occurrences(after, until)
+	if err != nil {
+		return 0, err
+	}
+
+	recurringID := recurring.ID.Hex()
+	reached := until
+	created := 0
+	var materializeErr error
+
+	for _, departure := range occurrences {
+		trip := recurring.NewInstance(departure)
+
+		vacation, err := s.vacationRepo.FindOverlapping(ctx, recurring.DriverID, trip.DepartureDatetime, trip.EstimatedArrivalDatetime)
+		if err != nil {
+			reached, materializeErr = departure.Add(-time.Nanosecond), fmt.Errorf("failed to check driver vacations: %w", err)
+			break
+		}
+		if vacation != nil {
+			log.Info().Str("recurring_trip_id", recurringID).Time("departure", departure).Msg("Skipping recurring trip instance: driver on vacation")
+			continue
+		}
+
+		if err := applyMarketPolicy(s.markets, trip, domain.AllMarketChecks); err != nil {
+			log.Warn().Err(err).Str("recurring_trip_id", recurringID).Time("departure", departure).Msg("Skipping recurring trip instance rejected by market policy")
+			continue
+		}
+
+		if err := s.tripRepo.Create(ctx, trip); err != nil {
+			if errors.Is(err, domain.ErrRecurringInstanceExists) {
+				continue
+			}
+			reached, materializeErr = departure.Add(-time.Nanosecond), err
+			break
+		}
+		created++
+
+		log.Info().
+			Str("trip_id", trip.ID.Hex()).
+			Str("recurring_trip_id", recurringID).
+			Int64("driver_id", recurring.DriverID).
+			Time("departure", departure).
+			Msg("Recurring trip instance created")
+
+		recordRoute(ctx, s.catalog, s.cityRepo, trip)
+
+		// Publicar evento trip.created (fire-and-forget)
+		s.publisher.PublishTripCreated(ctx, trip, nil)
+	}
+
+	if reached.After(after) {
+		if err := s.recurringRepo.SetMaterializedUntil(ctx, recurring.ID, reached); err != nil {
+			// Las instancias duplicadas las evita el índice único; solo se repite trabajo
+			log.Error().Err(err).Str("recurring_trip_id", recurringID).Msg("Failed to record materialized_until")
+		} else {
+			recurring.MaterializedUntil = &reached
+		}
+	}
+
+	return created, materializeErr
+}
+
+// validateTemplate valida la plantilla como si fuera un viaje: agenda, cierre de reservas, puntos de encuentro, ciudades y mercado
+// Normaliza la moneda (mayúsculas / moneda por defecto del mercado), resuelve las ciudades del catálogo
+// (las instancias heredan el city_id) y asigna IDs a los puntos nuevos
+func (s *recurringTripService) validateTemplate(recurring *domain.RecurringTrip, existingPickupPoints []domain.PickupPoint) error {
+	if err := recurring.Validate(); err != nil {
+		return err
+	}
+	if err := domain.ValidateBookingCloseMinutes(recurring.BookingCloseMinutes); err != nil {
+		return err
+	}
+
+	// Los puntos de encuentro son relativos a la salida: se validan contra una salida de referencia
+	reference := time.Now()
+	duration := time.Duration(recurring.DurationMinutes) * time.Minute
+	pickupPoints, err := domain.PreparePickupPoints(recurring.PickupPoints, existingPickupPoints, reference, reference.Add(duration))
+	if err != nil {
+		return err
+	}
+	recurring.PickupPoints = pickupPoints
+
+	if err := resolveTripCities(s.catalog, &recurring.Origin, &recurring.Destination); err != nil {
+		return err
+	}
+
+	sample := recurring.NewInstance(reference)
+	if err := applyMarketPolicy(s.markets, sample, domain.AllMarketChecks); err != nil {
+		return err
+	}
+	recurring.Currency = sample.Currency
+
+	return nil
+}
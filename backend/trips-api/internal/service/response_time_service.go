@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"time"
+	"trips-api/internal/dao"
+	"trips-api/internal/domain"
+	"trips-api/internal/messaging"
+	"trips-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ResponseTimeService define las operaciones de seguimiento del tiempo de respuesta de los conductores
+type ResponseTimeService interface {
+	// TrackChatMessage registra el tiempo de respuesta si el mensaje es del conductor y responde a un pasajero
+	// No retorna error: el seguimiento nunca hace fallar el envío del mensaje
+	TrackChatMessage(ctx context.Context, trip *domain.Trip, msg *dao.Message)
+
+	// GetDriverStats retorna los tiempos de respuesta del conductor (vacíos si nunca respondió)
+	GetDriverStats(ctx context.Context, driverID int64) (*domain.DriverResponseStats, error)
+
+	// GetDriverSnapshot retorna los promedios para el snapshot del conductor en trip.created
+	// (nil si el conductor todavía no tiene respuestas registradas)
+	GetDriverSnapshot(ctx context.Context, driverID int64) (*messaging.DriverResponseTime, error)
+}
+
+type responseTimeService struct {
+	responseTimeRepo repository.ResponseTimeRepository
+	messageRepo      repository.MessageRepository
+}
+
+// NewResponseTimeService crea una nueva instancia del servicio de tiempos de respuesta
+func NewResponseTimeService(
+	responseTimeRepo repository.ResponseTimeRepository,
+	messageRepo repository.MessageRepository,
+) ResponseTimeService {
+	return &responseTimeService{
+		responseTimeRepo: responseTimeRepo,
+		messageRepo:      messageRepo,
+	}
+}
+
+// TrackChatMessage mide la respuesta del conductor a los mensajes de pasajeros
+//
+// El tiempo de respuesta es la demora entre el primer mensaje de un pasajero que el conductor
+// todavía no había respondido y el mensaje del conductor. Los mensajes siguientes del conductor
+// no cuentan hasta que un pasajero vuelva a escribir.
+func (s *responseTimeService) TrackChatMessage(ctx context.Context, trip *domain.Trip, msg *dao.Message) {
+	if trip == nil || msg.UserID != trip.DriverID {
+		return
+	}
+
+	question, err := s.messageRepo.FindFirstUnanswered(ctx, msg.TripID, trip.DriverID, msg)
+	if err != nil {
+		log.Warn().Err(err).Str("trip_id", msg.TripID).Msg("Failed to find unanswered chat message (non-critical)")
+		return
+	}
+	if question == nil {
+		return
+	}
+
+	if err := s.record(ctx, trip.DriverID, domain.ResponseKindChat, question.CreatedAt, msg.CreatedAt); err != nil {
+		log.Warn().Err(err).Int64("driver_id", trip.DriverID).Msg("Failed to record chat response time (non-critical)")
+	}
+}
+
+// record agrega una respuesta al promedio móvil, descartando demoras inválidas
+func (s *responseTimeService) record(ctx context.Context, driverID int64, kind string, requestedAt, respondedAt time.Time) error {
+	delay := respondedAt.Sub(requestedAt)
+	if delay < 0 || delay > domain.MaxTrackedResponseDelay {
+		log.Debug().
+			Int64("driver_id", driverID).
+			Str("kind", kind).
+			Dur("delay", delay).
+			Msg("Response delay out of range, not tracked")
+		return nil
+	}
+
+	stats, err := s.responseTimeRepo.RecordResponse(ctx, driverID, kind, delay.Seconds(), respondedAt)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().
+		Int64("driver_id", driverID).
+		Str("kind", kind).
+		Float64("response_seconds", delay.Seconds()).
+		Float64("chat_avg_seconds", stats.Chat.AvgSeconds).
+		Msg("Driver response time recorded")
+
+	return nil
+}
+
+// GetDriverStats busca los tiempos de respuesta del conductor
+func (s *responseTimeService) GetDriverStats(ctx context.Context, driverID int64) (*domain.DriverResponseStats, error) {
+	stats, err := s.responseTimeRepo.FindByDriver(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return &domain.DriverResponseStats{DriverID: driverID}, nil
+	}
+	return stats, nil
+}
+
+// GetDriverSnapshot resume los tiempos de respuesta para los consumidores de trip.created
+func (s *responseTimeService) GetDriverSnapshot(ctx context.Context, driverID int64) (*messaging.DriverResponseTime, error) {
+	stats, err := s.responseTimeRepo.FindByDriver(ctx, driverID)
+	if err != nil || stats == nil {
+		return nil, err
+	}
+
+	return &messaging.DriverResponseTime{
+		ChatAvgSeconds: stats.Chat.AvgSeconds,
+		ChatSamples:    stats.Chat.SampleCount,
+		UpdatedAt:      stats.UpdatedAt,
+	}, nil
+}
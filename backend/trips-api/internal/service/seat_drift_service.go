@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"time"
+	"trips-api/internal/domain"
+	"trips-api/internal/messaging"
+	"trips-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// seatDriftSettleWindow excluye los viajes modificados hace muy poco: un reservation.* en curso
+// actualiza el viaje antes que el ledger y daría un falso ledger_mismatch
+const seatDriftSettleWindow = time.Minute
+
+// SeatDriftConfig configura el chequeo de invariantes de asientos
+type SeatDriftConfig struct {
+	// AutoRepair corrige los contadores del viaje además de alertar
+	AutoRepair bool
+
+	// LedgerSince excluye del chequeo contra el ledger a los viajes creados antes de esta fecha
+	// (sus reservas anteriores no están en el ledger). Cero chequea todos los viajes, solo para alertar:
+	// sin LedgerSince la reparación automática queda deshabilitada
+	LedgerSince time.Time
+
+	// BatchSize es la cantidad de viajes leídos por consulta
+	BatchSize int
+}
+
+// SeatDriftService valida periódicamente los contadores de asientos de los viajes activos:
+//   - available_seats + reserved_seats + held_seats == total_seats
+//   - reserved_seats == suma de las reservas confirmadas del ledger local (trip_reservations)
+//   - held_seats == suma de las retenciones activas del viaje (seat_holds)
+type SeatDriftService interface {
+	// RunOnce revisa todos los viajes activos y alerta (y repara, si está habilitado) los que tienen drift
+	RunOnce(ctx context.Context) (*domain.SeatDriftRunResult, error)
+
+	// Start ejecuta RunOnce periódicamente hasta que ctx se cancele (bloqueante, correr en una goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type seatDriftService struct {
+	tripRepo        repository.TripRepository
+	reservationRepo repository.TripReservationRepository
+	publisher       messaging.Publisher
+	cfg             SeatDriftConfig
+}
+
+// NewSeatDriftService crea una nueva instancia del chequeo de drift de asientos
+func NewSeatDriftService(
+	tripRepo repository.TripRepository,
+	reservationRepo repository.TripReservationRepository,
+	publisher messaging.Publisher,
+	cfg SeatDriftConfig,
+) SeatDriftService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 200
+	}
+	if cfg.AutoRepair && cfg.LedgerSince.IsZero() {
+		log.Error().Msg("Seat drift auto repair requires LedgerSince - only alerting")
+		cfg.AutoRepair = false
+	}
+	return &seatDriftService{
+		tripRepo:        tripRepo,
+		reservationRepo: reservationRepo,
+		publisher:       publisher,
+		cfg:             cfg,
+	}
+}
+
+// RunOnce recorre los viajes activos por páginas de BatchSize
+//
+// Por cada viaje con drift:
+//  1. Loguea el drift y publica alert.seat_drift (para alertas / métricas)
+//  2. Con AutoRepair: reserved_seats pasa a ser la suma del ledger (o se mantiene si el viaje no se
+//     chequea contra el ledger), held_seats la suma de seat_holds y
+//     available_seats = total_seats - reserved_seats - held_seats, con optimistic locking.
+//     Si la reparación se aplica se publica trip.updated para que search-api vea los asientos correctos
+func (s *seatDriftService) RunOnce(ctx context.Context) (*domain.SeatDriftRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.SeatDriftRunResult{}
+
+	afterID := ""
+	for {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		trips, err := s.tripRepo.FindActiveAfterID(ctx, afterID, s.cfg.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(trips) == 0 {
+			break
+		}
+		afterID = trips[len(trips)-1].ID.Hex()
+
+		tripIDs := make([]string, len(trips))
+		for i := range trips {
+			tripIDs[i] = trips[i].ID.Hex()
+		}
+		ledger, err := s.reservationRepo.SumConfirmedSeats(ctx, tripIDs)
+		if err != nil {
+			return result, err
+		}
+
+		for i := range trips {
+			trip := &trips[i]
+			if startedAt.Sub(trip.UpdatedAt) < seatDriftSettleWindow {
+				continue
+			}
+			result.TripsChecked++
+
+			drift := s.check(trip, ledger)
+			if drift == nil {
+				continue
+			}
+			result.DriftsDetected++
+
+			if s.cfg.AutoRepair {
+				if s.repair(ctx, trip, drift) {
+					result.Repaired++
+				} else {
+					result.RepairFailures++
+				}
+			}
+
+			log.Warn().
+				Str("trip_id", drift.TripID).
+				Strs("reasons", drift.Reasons).
+				Int("total_seats", drift.TotalSeats).
+				Int("reserved_seats", drift.ReservedSeats).
+				Int("available_seats", drift.AvailableSeats).
+				Int("held_seats", drift.HeldSeats).
+				Interface("ledger_reserved_seats", drift.LedgerSeats).
+				Bool("repaired", drift.Repaired).
+				Msg("Seat count drift detected")
+
+			s.publisher.PublishSeatDriftAlert(ctx, drift)
+		}
+
+		if len(trips) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// check valida las invariantes de un viaje; retorna nil si los contadores cuadran
+func (s *seatDriftService) check(trip *domain.Trip, ledger map[string]int) *domain.SeatDrift {
+	drift := &domain.SeatDrift{
+		TripID:         trip.ID.Hex(),
+		DriverID:       trip.DriverID,
+		Status:         trip.Status,
+		TotalSeats:     trip.TotalSeats,
+		ReservedSeats:  trip.ReservedSeats,
+		AvailableSeats: trip.AvailableSeats,
+		HeldSeats:      trip.HeldSeats,
+		DetectedAt:     time.Now(),
+	}
+
+	if trip.AvailableSeats+trip.ReservedSeats+trip.HeldSeats != trip.TotalSeats {
+		drift.Reasons = append(drift.Reasons, domain.SeatDriftAvailableMismatch)
+	}
+
+	if trip.HeldSeats != trip.SeatHoldsTotal() {
+		drift.Reasons = append(drift.Reasons, domain.SeatDriftHoldMismatch)
+	}
+
+	if !trip.CreatedAt.Before(s.cfg.LedgerSince) {
+		ledgerSeats := ledger[drift.TripID]
+		drift.LedgerSeats = &ledgerSeats
+		if trip.ReservedSeats != ledgerSeats {
+			drift.Reasons = append(drift.Reasons, domain.SeatDriftLedgerMismatch)
+		}
+	}
+
+	if len(drift.Reasons) == 0 {
+		return nil
+	}
+	return drift
+}
+
+// repair corrige los contadores del viaje; retorna false si no se pudo aplicar
+func (s *seatDriftService) repair(ctx context.Context, trip *domain.Trip, drift *domain.SeatDrift) bool {
+	reserved := trip.ReservedSeats
+	if drift.LedgerSeats != nil {
+		reserved = *drift.LedgerSeats
+	}
+
+	// Las retenciones activas son la fuente de verdad de held_seats
+	held := trip.SeatHoldsTotal()
+
+	// Más reservas que asientos (o contadores negativos) no tiene una reparación automática segura
+	if reserved < 0 || reserved+held > trip.TotalSeats {
+		log.Error().
+			Str("trip_id", drift.TripID).
+			Int("total_seats", trip.TotalSeats).
+			Int("reserved_seats", reserved).
+			Int("held_seats", held).
+			Msg("Seat count drift cannot be repaired automatically")
+		return false
+	}
+	available := trip.TotalSeats - reserved - held
+
+	err := s.tripRepo.RepairSeats(ctx, drift.TripID, reserved, held, available, trip.AvailabilityVersion)
+	if err == domain.ErrOptimisticLockFailed {
+		// El viaje cambió mientras tanto: se vuelve a evaluar en la próxima corrida
+		log.Info().Str("trip_id", drift.TripID).Msg("Trip changed while repairing seat drift - skipping")
+		return false
+	}
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", drift.TripID).Msg("Failed to repair seat drift")
+		return false
+	}
+
+	drift.Repaired = true
+
+	// Se relee el viaje: trip.updated lleva los contadores reparados y la secuencia de esta escritura
+	repaired, err := s.tripRepo.FindByID(ctx, drift.TripID)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", drift.TripID).Msg("Failed to fetch repaired trip")
+		return true
+	}
+	s.publisher.PublishTripUpdated(ctx, repaired)
+
+	return true
+}
+
+// Start ejecuta el chequeo en cada tick hasta que ctx se cancele
+func (s *seatDriftService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Seat drift check failed")
+		} else if result.DriftsDetected > 0 {
+			log.Warn().
+				Int64("trips_checked", result.TripsChecked).
+				Int64("drifts_detected", result.DriftsDetected).
+				Int64("repaired", result.Repaired).
+				Int64("repair_failures", result.RepairFailures).
+				Str("duration", result.Duration).
+				Msg("Seat drift check found drifted trips")
+		} else {
+			log.Debug().
+				Int64("trips_checked", result.TripsChecked).
+				Str("duration", result.Duration).
+				Msg("Seat drift check finished")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Seat drift checker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
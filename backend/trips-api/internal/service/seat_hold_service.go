@@ -0,0 +1,473 @@
+package service
+
+import (
+	"context"
+	"time"
+	"trips-api/internal/domain"
+	"trips-api/internal/messaging"
+	"trips-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// seatHoldExpiryBatchSize es la cantidad de viajes con retenciones vencidas leídos por consulta
+const seatHoldExpiryBatchSize = 200
+
+// SeatHoldConfig configura las retenciones de asientos
+type SeatHoldConfig struct {
+	// DefaultTTL es la duración de una retención cuando bookings-api no envía ttl_seconds
+	DefaultTTL time.Duration
+
+	// MaxTTL limita el ttl_seconds pedido, para que una retención olvidada no bloquee asientos por horas
+	MaxTTL time.Duration
+}
+
+// SeatHoldService administra las retenciones de asientos del flujo de reserva en dos pasos:
+// bookings-api retiene los asientos (el frontend muestra "asiento retenido por 5 minutos" mientras
+// el pasajero paga) y después confirma la retención con reservation.created o la libera
+type SeatHoldService interface {
+	// CreateHold retiene asientos de un viaje publicado para una reserva que bookings-api está por crear
+	// Idempotente por reservation_id: si la reserva ya tiene una retención activa se devuelve esa
+	// Retorna ErrNoSeatsAvailable si no hay asientos libres y ErrTripNotBookable si el viaje no acepta reservas
+	CreateHold(ctx context.Context, tripID string, request domain.CreateSeatHoldRequest) (*domain.SeatHoldResponse, error)
+
+	// ReleaseHold devuelve los asientos retenidos al viaje
+	// reservationID es la reserva que tomó la retención: la de otra reserva se trata como inexistente
+	// Retorna ErrSeatHoldNotFound si ya se liberó, venció o es de otra reserva y ErrSeatHoldConfirmed si ya se convirtió en reserva
+	ReleaseHold(ctx context.Context, tripID, holdID, reservationID string) error
+
+	// ConfirmHold convierte la retención de reservationID en una reserva confirmada (held_seats → reserved_seats)
+	// Idempotente: confirmar una retención ya confirmada la devuelve con status confirmed
+	ConfirmHold(ctx context.Context, tripID, holdID, reservationID string) (*domain.SeatHoldResponse, error)
+
+	// ConfirmForReservation confirma la retención que trae un reservation.created (sin publicar trip.updated)
+	// Retorna ErrSeatHoldConfirmed si esa reserva ya la confirmó y ErrSeatHoldNotFound si la retención
+	// venció, se liberó o no corresponde a la reserva (la reserva sigue el flujo sin retención)
+	ConfirmForReservation(ctx context.Context, trip *domain.Trip, holdID, reservationID string, seats int) error
+
+	// ExpireOnce libera las retenciones vencidas
+	ExpireOnce(ctx context.Context) (*domain.SeatHoldExpiryRunResult, error)
+
+	// Start ejecuta ExpireOnce periódicamente hasta que ctx se cancele (bloqueante, correr en una goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type seatHoldService struct {
+	tripRepo        repository.TripRepository
+	holdRepo        repository.SeatHoldRepository
+	reservationRepo repository.TripReservationRepository
+	publisher       messaging.Publisher
+	cfg             SeatHoldConfig
+}
+
+// NewSeatHoldService crea una nueva instancia del servicio de retenciones de asientos
+func NewSeatHoldService(
+	tripRepo repository.TripRepository,
+	holdRepo repository.SeatHoldRepository,
+	reservationRepo repository.TripReservationRepository,
+	publisher messaging.Publisher,
+	cfg SeatHoldConfig,
+) SeatHoldService {
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = 5 * time.Minute
+	}
+	if cfg.MaxTTL < cfg.DefaultTTL {
+		cfg.MaxTTL = cfg.DefaultTTL
+	}
+	return &seatHoldService{
+		tripRepo:        tripRepo,
+		holdRepo:        holdRepo,
+		reservationRepo: reservationRepo,
+		publisher:       publisher,
+		cfg:             cfg,
+	}
+}
+
+// CreateHold descuenta los asientos de available_seats en un único update atómico y publica trip.updated
+func (s *seatHoldService) CreateHold(ctx context.Context, tripID string, request domain.CreateSeatHoldRequest) (*domain.SeatHoldResponse, error) {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reintento de bookings-api: la reserva ya tiene su retención
+	if existing := trip.FindSeatHoldByReservation(request.ReservationID); existing != nil {
+		response := domain.NewSeatHoldResponse(tripID, existing, domain.SeatHoldStatusHeld)
+		response.AvailableSeats = &trip.AvailableSeats
+		return response, nil
+	}
+
+	if !domain.AcceptsReservations(trip.Status) {
+		return nil, domain.ErrTripNotBookable
+	}
+	if trip.AvailableSeats < request.Seats {
+		return nil, domain.ErrNoSeatsAvailable
+	}
+
+	now := time.Now()
+	hold := &domain.SeatHold{
+		HoldID:        primitive.NewObjectID().Hex(),
+		ReservationID: request.ReservationID,
+		PassengerID:   request.PassengerID,
+		Seats:         request.Seats,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(s.ttl(request.TTLSeconds)),
+	}
+
+	// El update falla si entre la lectura y la escritura se tomaron los asientos, cambió el estado
+	// o un reintento concurrente de la misma reserva ya retuvo
+	if err := s.holdRepo.Create(ctx, tripID, hold); err != nil {
+		if err == domain.ErrNoSeatsAvailable {
+			if current, findErr := s.tripRepo.FindByID(ctx, tripID); findErr == nil {
+				if existing := current.FindSeatHoldByReservation(request.ReservationID); existing != nil {
+					return domain.NewSeatHoldResponse(tripID, existing, domain.SeatHoldStatusHeld), nil
+				}
+			}
+		}
+		return nil, err
+	}
+
+	response := domain.NewSeatHoldResponse(tripID, hold, domain.SeatHoldStatusHeld)
+	if updatedTrip := s.publishTripUpdated(ctx, tripID); updatedTrip != nil {
+		response.AvailableSeats = &updatedTrip.AvailableSeats
+	}
+
+	log.Info().
+		Str("trip_id", tripID).
+		Str("hold_id", hold.HoldID).
+		Str("reservation_id", hold.ReservationID).
+		Int("seats", hold.Seats).
+		Time("expires_at", hold.ExpiresAt).
+		Msg("Seats held")
+
+	return response, nil
+}
+
+// ReleaseHold devuelve los asientos al viaje y publica trip.updated
+func (s *seatHoldService) ReleaseHold(ctx context.Context, tripID, holdID, reservationID string) error {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return err
+	}
+
+	hold := trip.FindSeatHold(holdID)
+	if hold == nil {
+		return s.inactiveHoldError(ctx, tripID, holdID, reservationID)
+	}
+	if hold.ReservationID != reservationID {
+		return domain.ErrSeatHoldNotFound
+	}
+
+	if err := s.holdRepo.Release(ctx, tripID, hold); err != nil {
+		if err == domain.ErrSeatHoldNotFound {
+			// Otra operación ganó entre la lectura y el update (confirmación o vencimiento)
+			return s.inactiveHoldError(ctx, tripID, holdID, reservationID)
+		}
+		return err
+	}
+
+	s.publishTripUpdated(ctx, tripID)
+
+	log.Info().
+		Str("trip_id", tripID).
+		Str("hold_id", holdID).
+		Str("reservation_id", hold.ReservationID).
+		Int("seats", hold.Seats).
+		Msg("Seat hold released")
+
+	return nil
+}
+
+// ConfirmHold confirma una retención por API (bookings-api antes de publicar reservation.created)
+func (s *seatHoldService) ConfirmHold(ctx context.Context, tripID, holdID, reservationID string) (*domain.SeatHoldResponse, error) {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	hold := trip.FindSeatHold(holdID)
+	if hold == nil {
+		return s.confirmedHoldResponse(ctx, tripID, holdID, reservationID)
+	}
+	if hold.ReservationID != reservationID {
+		return nil, domain.ErrSeatHoldNotFound
+	}
+
+	if err := s.confirm(ctx, tripID, hold); err != nil {
+		if err == domain.ErrSeatHoldNotFound {
+			return s.confirmedHoldResponse(ctx, tripID, holdID, reservationID)
+		}
+		return nil, err
+	}
+
+	response := domain.NewSeatHoldResponse(tripID, hold, domain.SeatHoldStatusConfirmed)
+	if updatedTrip := s.publishTripUpdated(ctx, tripID); updatedTrip != nil {
+		response.AvailableSeats = &updatedTrip.AvailableSeats
+	}
+	return response, nil
+}
+
+// ConfirmForReservation confirma la retención de un reservation.created
+func (s *seatHoldService) ConfirmForReservation(ctx context.Context, trip *domain.Trip, holdID, reservationID string, seats int) error {
+	tripID := trip.ID.Hex()
+
+	hold := trip.FindSeatHold(holdID)
+	if hold == nil {
+		return s.inactiveHoldError(ctx, tripID, holdID, reservationID)
+	}
+
+	// Una retención de otra reserva o de otra cantidad de asientos no se usa: se libera y la reserva
+	// se valida como si no tuviera retención
+	if hold.ReservationID != reservationID || hold.Seats != seats {
+		log.Warn().
+			Str("trip_id", tripID).
+			Str("hold_id", holdID).
+			Str("reservation_id", reservationID).
+			Str("hold_reservation_id", hold.ReservationID).
+			Int("seats_reserved", seats).
+			Int("seats_held", hold.Seats).
+			Msg("Seat hold does not match the reservation - releasing it")
+		if err := s.holdRepo.Release(ctx, tripID, hold); err != nil && err != domain.ErrSeatHoldNotFound {
+			return err
+		}
+		return domain.ErrSeatHoldNotFound
+	}
+
+	if err := s.confirm(ctx, tripID, hold); err != nil {
+		if err == domain.ErrSeatHoldNotFound {
+			return s.inactiveHoldError(ctx, tripID, holdID, reservationID)
+		}
+		return err
+	}
+	return nil
+}
+
+// confirm pasa los asientos a reserved_seats y registra la reserva en el ledger con su retención
+//
+// Igual que reserveSeats, el ledger se escribe antes de mover los asientos: si falla, la confirmación falla
+// con la retención intacta y el reintento (reentrega o nuevo POST) la confirma. Si la retención ya no está
+// al mover los asientos, se deshace la entrada del ledger salvo que la haya confirmado una confirmación
+// concurrente de la misma reserva (queda registrada en el viaje, ver HasConfirmedSeatHold)
+func (s *seatHoldService) confirm(ctx context.Context, tripID string, hold *domain.SeatHold) error {
+	err := s.reservationRepo.Confirm(ctx, &domain.TripReservation{
+		ReservationID: hold.ReservationID,
+		TripID:        tripID,
+		PassengerID:   hold.PassengerID,
+		Seats:         hold.Seats,
+		SeatHoldID:    hold.HoldID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.holdRepo.Confirm(ctx, tripID, hold); err != nil {
+		if err == domain.ErrSeatHoldNotFound {
+			confirmed, findErr := s.holdConfirmedOnTrip(ctx, tripID, hold.HoldID)
+			if findErr != nil {
+				return findErr
+			}
+			if confirmed {
+				return domain.ErrSeatHoldNotFound
+			}
+		}
+		// La retención se liberó, venció o la escritura falló: sus asientos no pasaron a reserved_seats
+		if _, cancelErr := s.reservationRepo.Cancel(ctx, hold.ReservationID); cancelErr != nil {
+			log.Error().
+				Err(cancelErr).
+				Str("reservation_id", hold.ReservationID).
+				Str("hold_id", hold.HoldID).
+				Msg("Failed to roll back reservation ledger after seat hold confirm failed")
+			return cancelErr
+		}
+		return err
+	}
+
+	log.Info().
+		Str("trip_id", tripID).
+		Str("hold_id", hold.HoldID).
+		Str("reservation_id", hold.ReservationID).
+		Int("seats", hold.Seats).
+		Msg("Seat hold confirmed")
+
+	return nil
+}
+
+// inactiveHoldError distingue una retención ya confirmada (ledger y viaje) de una liberada o vencida
+// Con reservationID solo cuenta como confirmada si la confirmó esa reserva
+func (s *seatHoldService) inactiveHoldError(ctx context.Context, tripID, holdID, reservationID string) error {
+	reservation, err := s.reservationRepo.FindBySeatHold(ctx, holdID)
+	if err != nil {
+		return err
+	}
+	if reservation == nil || reservation.Status != domain.TripReservationConfirmed ||
+		(reservationID != "" && reservation.ReservationID != reservationID) {
+		return domain.ErrSeatHoldNotFound
+	}
+
+	confirmed, err := s.holdConfirmedOnTrip(ctx, tripID, holdID)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return domain.ErrSeatHoldNotFound
+	}
+	return domain.ErrSeatHoldConfirmed
+}
+
+// holdConfirmedOnTrip relee el viaje para saber si la retención se confirmó
+// El ledger se escribe antes de mover los asientos, así que una entrada confirmada sola no alcanza:
+// la que dejó una confirmación en curso o fallida no tiene la retención registrada en el viaje
+func (s *seatHoldService) holdConfirmedOnTrip(ctx context.Context, tripID, holdID string) (bool, error) {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return false, err
+	}
+	return trip.HasConfirmedSeatHold(holdID), nil
+}
+
+// confirmedHoldResponse responde un ConfirmHold repetido con la reserva del ledger (solo a la reserva dueña)
+func (s *seatHoldService) confirmedHoldResponse(ctx context.Context, tripID, holdID, reservationID string) (*domain.SeatHoldResponse, error) {
+	reservation, err := s.reservationRepo.FindBySeatHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil || reservation.Status != domain.TripReservationConfirmed || reservation.TripID != tripID ||
+		reservation.ReservationID != reservationID {
+		return nil, domain.ErrSeatHoldNotFound
+	}
+
+	confirmed, err := s.holdConfirmedOnTrip(ctx, tripID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if !confirmed {
+		return nil, domain.ErrSeatHoldNotFound
+	}
+
+	return &domain.SeatHoldResponse{
+		HoldID:        holdID,
+		TripID:        tripID,
+		ReservationID: reservation.ReservationID,
+		Seats:         reservation.Seats,
+		Status:        domain.SeatHoldStatusConfirmed,
+	}, nil
+}
+
+// ExpireOnce recorre los viajes con retenciones vencidas por páginas y las libera
+// Cada retención se libera con el mismo update condicional que DELETE, así una confirmación
+// concurrente gana o pierde entera
+func (s *seatHoldService) ExpireOnce(ctx context.Context) (*domain.SeatHoldExpiryRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.SeatHoldExpiryRunResult{}
+
+	for {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		trips, err := s.holdRepo.FindTripsWithExpiredHolds(ctx, startedAt, seatHoldExpiryBatchSize)
+		if err != nil {
+			return result, err
+		}
+
+		expiredInBatch := int64(0)
+		for i := range trips {
+			trip := &trips[i]
+			tripID := trip.ID.Hex()
+			result.TripsChecked++
+
+			released := 0
+			for j := range trip.SeatHolds {
+				hold := &trip.SeatHolds[j]
+				if hold.ExpiresAt.After(startedAt) {
+					continue
+				}
+
+				err := s.holdRepo.Release(ctx, tripID, hold)
+				if err == domain.ErrSeatHoldNotFound {
+					continue // Confirmada o liberada mientras tanto
+				}
+				if err != nil {
+					result.ReleaseFailures++
+					log.Error().Err(err).Str("trip_id", tripID).Str("hold_id", hold.HoldID).Msg("Failed to release expired seat hold")
+					continue
+				}
+
+				released++
+				log.Info().
+					Str("trip_id", tripID).
+					Str("hold_id", hold.HoldID).
+					Str("reservation_id", hold.ReservationID).
+					Int("seats", hold.Seats).
+					Time("expires_at", hold.ExpiresAt).
+					Msg("Expired seat hold released")
+			}
+
+			if released > 0 {
+				result.HoldsExpired += int64(released)
+				expiredInBatch += int64(released)
+				s.publishTripUpdated(ctx, tripID)
+			}
+		}
+
+		// Sin avances la próxima página devolvería los mismos viajes: se reintenta en la próxima corrida
+		if len(trips) < seatHoldExpiryBatchSize || expiredInBatch == 0 {
+			break
+		}
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// Start ejecuta el expirador en cada tick hasta que ctx se cancele
+func (s *seatHoldService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.ExpireOnce(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Seat hold expiry failed")
+		} else if result.HoldsExpired > 0 || result.ReleaseFailures > 0 {
+			log.Info().
+				Int64("trips_checked", result.TripsChecked).
+				Int64("holds_expired", result.HoldsExpired).
+				Int64("release_failures", result.ReleaseFailures).
+				Str("duration", result.Duration).
+				Msg("Seat hold expiry finished")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Seat hold expirer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ttl resuelve la duración pedida (segundos) con el default y el máximo configurados
+func (s *seatHoldService) ttl(seconds int) time.Duration {
+	if seconds <= 0 {
+		return s.cfg.DefaultTTL
+	}
+	ttl := time.Duration(seconds) * time.Second
+	if ttl > s.cfg.MaxTTL {
+		return s.cfg.MaxTTL
+	}
+	return ttl
+}
+
+// publishTripUpdated relee el viaje y publica trip.updated (search-api muestra los asientos libres correctos)
+// Retorna nil si no se pudo leer; los asientos ya se actualizaron igual
+func (s *seatHoldService) publishTripUpdated(ctx context.Context, tripID string) *domain.Trip {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to fetch updated trip")
+		return nil
+	}
+	s.publisher.PublishTripUpdated(ctx, trip)
+	return trip
+}
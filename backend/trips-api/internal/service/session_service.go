@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"trips-api/internal/clients"
+	"trips-api/internal/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxCachedSessions limita la memoria del cache: al superarlo se descartan las entradas vencidas
+const maxCachedSessions = 10000
+
+// SessionService valida un JWT contra el estado actual de la cuenta en users-api
+//
+// La firma HS256 sola no alcanza: users-api revoca sesiones (restablecimiento de contraseña),
+// desactiva cuentas (SCIM) y las suspende (admin) sin que el token deje de ser válido.
+// Aplica las mismas reglas que checkAccountUsable de users-api.
+type SessionService interface {
+	// CheckSession retorna nil si el token emitido en issuedAt (claim iat) sigue siendo usable
+	// Errores: ErrSessionRevoked, ErrAccountInactive, ErrAccountSuspended o ErrUsersAPIUnavailable
+	CheckSession(ctx context.Context, userID int64, issuedAt int64) error
+}
+
+type cachedSessionStatus struct {
+	status    *clients.SessionStatus
+	fetchedAt time.Time
+}
+
+type sessionService struct {
+	usersClient clients.UsersClient
+	ttl         time.Duration
+
+	mu    sync.Mutex
+	cache map[int64]cachedSessionStatus
+}
+
+// NewSessionService crea el servicio de validación de sesiones
+// ttl es cuánto se reusa el estado de un usuario antes de volver a consultar users-api
+func NewSessionService(usersClient clients.UsersClient, ttl time.Duration) SessionService {
+	return &sessionService{
+		usersClient: usersClient,
+		ttl:         ttl,
+		cache:       make(map[int64]cachedSessionStatus),
+	}
+}
+
+// CheckSession valida el token contra el estado de la cuenta (cacheado hasta ttl)
+// Si users-api no responde se usa el último estado conocido; sin él la request se rechaza
+func (s *sessionService) CheckSession(ctx context.Context, userID int64, issuedAt int64) error {
+	status, err := s.sessionStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if status.SessionsRevokedAt != nil && issuedAt < status.SessionsRevokedAt.Unix() {
+		return domain.ErrSessionRevoked
+	}
+	if !status.Active {
+		return domain.ErrAccountInactive
+	}
+	if status.Banned {
+		return domain.ErrAccountSuspended
+	}
+	return nil
+}
+
+// sessionStatus devuelve el estado cacheado o lo consulta en users-api
+func (s *sessionService) sessionStatus(ctx context.Context, userID int64) (*clients.SessionStatus, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	s.mu.Unlock()
+
+	if ok && now.Sub(cached.fetchedAt) < s.ttl {
+		return cached.status, nil
+	}
+
+	status, err := s.usersClient.GetSessionStatus(ctx, userID)
+	if err != nil {
+		// Cuenta eliminada: no hay estado que reusar
+		if errors.Is(err, domain.ErrSessionRevoked) {
+			s.forget(userID)
+			return nil, err
+		}
+		if ok {
+			log.Warn().Err(err).Int64("user_id", userID).Msg("users-api unavailable - using last known session status")
+			return cached.status, nil
+		}
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to check session status")
+		return nil, domain.ErrUsersAPIUnavailable
+	}
+
+	s.store(userID, status, now)
+	return status, nil
+}
+
+func (s *sessionService) store(userID int64, status *clients.SessionStatus, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.cache) >= maxCachedSessions {
+		for id, entry := range s.cache {
+			if now.Sub(entry.fetchedAt) >= s.ttl {
+				delete(s.cache, id)
+			}
+		}
+	}
+	s.cache[userID] = cachedSessionStatus{status: status, fetchedAt: now}
+}
+
+func (s *sessionService) forget(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, userID)
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"time"
+	"trips-api/internal/domain"
+	"trips-api/internal/messaging"
+	"trips-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// tripLifecycleBatchSize es la cantidad de viajes leídos por consulta en cada etapa
+const tripLifecycleBatchSize = 200
+
+// TripLifecycleService mueve los viajes por su ciclo de vida según sus fechas:
+//   - published → closed cuando llega booking_closes_at (cierre de reservas nuevas antes de la salida)
+//   - published / closed → in_progress cuando llega departure_datetime
+//   - in_progress → completed cuando llega estimated_arrival_datetime
+//
+// Cada transición publica trip.updated (search-api deja de mostrar el viaje y el hub avisa a los clientes).
+// Un viaje cerrado sigue visible en GET /trips/:id para los pasajeros que ya tienen reserva.
+// Los viajes pausados o cancelados no se tocan
+type TripLifecycleService interface {
+	// RunOnce aplica todas las transiciones vencidas
+	RunOnce(ctx context.Context) (*domain.TripLifecycleRunResult, error)
+
+	// Start ejecuta RunOnce periódicamente hasta que ctx se cancele (bloqueante, correr en una goroutine)
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type tripLifecycleService struct {
+	tripRepo  repository.TripRepository
+	publisher messaging.Publisher
+}
+
+// NewTripLifecycleService crea una nueva instancia del scheduler de estados de los viajes
+func NewTripLifecycleService(tripRepo repository.TripRepository, publisher messaging.Publisher) TripLifecycleService {
+	return &tripLifecycleService{
+		tripRepo:  tripRepo,
+		publisher: publisher,
+	}
+}
+
+// RunOnce cierra las reservas vencidas, inicia los viajes que ya salieron y después completa los que ya llegaron,
+// así un viaje que salió y llegó con el servicio apagado pasa por todos los estados en la misma corrida
+func (s *tripLifecycleService) RunOnce(ctx context.Context) (*domain.TripLifecycleRunResult, error) {
+	startedAt := time.Now()
+	result := &domain.TripLifecycleRunResult{}
+
+	closed, skipped, err := s.transitionDue(ctx, s.tripRepo.FindBookingClosedBefore, domain.TripStatusPublished, domain.TripStatusClosed, startedAt)
+	result.Closed += closed
+	result.Skipped += skipped
+	if err != nil {
+		return result, err
+	}
+
+	for _, fromStatus := range []string{domain.TripStatusClosed, domain.TripStatusPublished} {
+		started, skipped, err := s.transitionDue(ctx, s.tripRepo.FindDepartedBefore, fromStatus, domain.TripStatusInProgress, startedAt)
+		result.Started += started
+		result.Skipped += skipped
+		if err != nil {
+			return result, err
+		}
+	}
+
+	completed, skipped, err := s.transitionDue(ctx, s.tripRepo.FindArrivedBefore, domain.TripStatusInProgress, domain.TripStatusCompleted, startedAt)
+	result.Completed += completed
+	result.Skipped += skipped
+	if err != nil {
+		return result, err
+	}
+
+	result.Duration = time.Since(startedAt).String()
+	return result, nil
+}
+
+// transitionDue pasa de fromStatus a toStatus todos los viajes que devuelve findDue, por páginas
+// Retorna cuántos cambiaron y cuántos se omitieron por haber cambiado de estado mientras tanto
+func (s *tripLifecycleService) transitionDue(
+	ctx context.Context,
+	findDue func(ctx context.Context, status string, before time.Time, limit int) ([]domain.Trip, error),
+	fromStatus, toStatus string,
+	now time.Time,
+) (int64, int64, error) {
+	var changed, skipped int64
+
+	for {
+		if ctx.Err() != nil {
+			return changed, skipped, ctx.Err()
+		}
+
+		trips, err := findDue(ctx, fromStatus, now, tripLifecycleBatchSize)
+		if err != nil {
+			return changed, skipped, err
+		}
+
+		batchChanged := 0
+		for i := range trips {
+			trip := &trips[i]
+			tripID := trip.ID.Hex()
+
+			// Transición condicional: si el viaje cambió de estado mientras tanto (cancelado, pausado), se omite
+			if err := s.tripRepo.TransitionStatus(ctx, tripID, fromStatus, toStatus); err != nil {
+				log.Warn().
+					Err(err).
+					Str("trip_id", tripID).
+					Str("from_status", fromStatus).
+					Str("to_status", toStatus).
+					Msg("Skipping trip lifecycle transition")
+				skipped++
+				continue
+			}
+
+			changed++
+			batchChanged++
+
+			log.Info().
+				Str("trip_id", tripID).
+				Str("from_status", fromStatus).
+				Str("to_status", toStatus).
+				Msg("Trip lifecycle transition")
+
+			// Publicar evento trip.updated (fire-and-forget) con el viaje releído: lleva la secuencia de esta escritura
+			if updated, err := s.tripRepo.FindByID(ctx, tripID); err == nil {
+				s.publisher.PublishTripUpdated(ctx, updated)
+			} else {
+				log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to fetch trip after lifecycle transition")
+			}
+		}
+
+		// Los viajes que cambiaron ya no aparecen en la próxima consulta; si ninguno cambió,
+		// la misma página volvería a salir y se reintenta en la próxima corrida
+		if len(trips) < tripLifecycleBatchSize || batchChanged == 0 {
+			return changed, skipped, nil
+		}
+	}
+}
+
+// Start ejecuta el scheduler en cada tick hasta que ctx se cancele
+func (s *tripLifecycleService) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Ejecutar inmediatamente al iniciar para cubrir los viajes que salieron con el servicio apagado
+		result, err := s.RunOnce(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Trip lifecycle run failed")
+		} else if result.Closed > 0 || result.Started > 0 || result.Completed > 0 {
+			log.Info().
+				Int64("closed", result.Closed).
+				Int64("started", result.Started).
+				Int64("completed", result.Completed).
+				Int64("skipped", result.Skipped).
+				Str("duration", result.Duration).
+				Msg("Trip lifecycle run finished")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Trip lifecycle scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
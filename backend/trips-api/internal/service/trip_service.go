@@ -3,11 +3,16 @@ package service
 import (
 	"context"
 	"fmt"
+	"math/rand"
+	"strings"
 	"time"
+	"trips-api/internal/cities"
 	"trips-api/internal/clients"
 	"trips-api/internal/domain"
+	"trips-api/internal/market"
 	"trips-api/internal/messaging"
 	"trips-api/internal/repository"
+	"trips-api/internal/richtext"
 
 	"github.com/rs/zerolog/log"
 )
@@ -18,6 +23,10 @@ type TripService interface {
 	// authToken: JWT token for validating driver against users-api (format: "Bearer {token}")
 	CreateTrip(ctx context.Context, driverID int64, authToken string, request domain.CreateTripRequest) (*domain.Trip, error)
 
+	// ImportTrips crea los viajes de una importación masiva con las mismas validaciones que CreateTrip
+	// Retorna el reporte por fila; solo retorna error si el conductor no se pudo validar (no se crea ningún viaje)
+	ImportTrips(ctx context.Context, driverID int64, authToken string, rows []domain.TripImportRow) (*domain.TripImportReport, error)
+
 	// GetTrip obtiene un viaje por su ID
 	GetTrip(ctx context.Context, tripID string) (*domain.Trip, error)
 
@@ -30,6 +39,9 @@ type TripService interface {
 	// DeleteTrip elimina un viaje (solo el dueño o admin)
 	DeleteTrip(ctx context.Context, tripID string, userID int64, userRole string) error
 
+	// GetPriceHistory obtiene el precio actual de un viaje y sus cambios, el más nuevo primero
+	GetPriceHistory(ctx context.Context, tripID string) (*domain.TripPriceHistory, error)
+
 	// ProcessReservationCreated maneja eventos reservation.created
 	// Retorna error solo para fallos de sistema (triggers NACK)
 	// Retorna nil para fallos de negocio (manejados con evento de compensación)
@@ -37,27 +49,80 @@ type TripService interface {
 
 	// ProcessReservationCancelled maneja eventos reservation.cancelled
 	ProcessReservationCancelled(ctx context.Context, event messaging.ReservationCancelledEvent) error
+
+	// ProcessReservationModified maneja eventos reservation.modified (cambio parcial de asientos)
+	// Un aumento que no se puede satisfacer se compensa con reservation.modification_failed
+	ProcessReservationModified(ctx context.Context, event messaging.ReservationModifiedEvent) error
 }
 
+// maxPriceHistoryEntries es la cantidad máxima de cambios de precio que devuelve GetPriceHistory
+const maxPriceHistoryEntries = 100
+
+// maxModificationAttempts es la cantidad de intentos ante conflictos de versión al aplicar
+// un reservation.modified (se relee el viaje antes de cada intento)
+const maxModificationAttempts = 3
+
+// maxReservationAttempts es la cantidad de intentos ante conflictos de versión al descontar los asientos
+// de un reservation.created: un conflicto con una cancelación concurrente no debe rechazar la reserva
+const maxReservationAttempts = 5
+
+// reservationRetryBaseDelay es la espera base entre intentos de un reservation.created (ver waitBeforeRetry)
+const reservationRetryBaseDelay = 20 * time.Millisecond
+
+// tripImportEventBatch es la cantidad de trip.created que se publican juntos al terminar una importación masiva
+const tripImportEventBatch = 25
+
 type tripService struct {
 	tripRepo           repository.TripRepository
+	vacationRepo       repository.VacationRepository
+	reservationRepo    repository.TripReservationRepository
+	seatHolds          SeatHoldService
+	priceHistoryRepo   repository.PriceHistoryRepository
 	idempotencyService IdempotencyService
 	usersClient        clients.UsersClient
+	responseTimes      ResponseTimeService
 	publisher          messaging.Publisher
+	markets            market.Registry
+	catalog            cities.Catalog
+	cityRepo           repository.CityRepository
+	descriptions       richtext.Pipeline
+
+	// ledgerSince es la fecha desde la que el ledger de reservas está completo (ver ProcessReservationCancelled)
+	ledgerSince time.Time
 }
 
 // NewTripService crea una nueva instancia del servicio de viajes
 func NewTripService(
 	tripRepo repository.TripRepository,
+	vacationRepo repository.VacationRepository,
+	reservationRepo repository.TripReservationRepository,
+	seatHolds SeatHoldService,
+	priceHistoryRepo repository.PriceHistoryRepository,
 	idempotencyService IdempotencyService,
 	usersClient clients.UsersClient,
+	responseTimes ResponseTimeService,
 	publisher messaging.Publisher,
+	markets market.Registry,
+	catalog cities.Catalog,
+	cityRepo repository.CityRepository,
+	descriptions richtext.Pipeline,
+	ledgerSince time.Time,
 ) TripService {
 	return &tripService{
 		tripRepo:           tripRepo,
+		vacationRepo:       vacationRepo,
+		reservationRepo:    reservationRepo,
+		seatHolds:          seatHolds,
+		priceHistoryRepo:   priceHistoryRepo,
 		idempotencyService: idempotencyService,
 		usersClient:        usersClient,
+		responseTimes:      responseTimes,
 		publisher:          publisher,
+		markets:            markets,
+		catalog:            catalog,
+		cityRepo:           cityRepo,
+		descriptions:       descriptions,
+		ledgerSince:        ledgerSince,
 	}
 }
 
@@ -66,12 +131,17 @@ func NewTripService(
 // Validaciones:
 // - departure_datetime debe ser en el futuro
 // - total_seats debe estar entre 1-8
+// - el viaje no puede superponerse con una vacación activa del conductor
+// - booking_close_minutes (opcional) entre 0 y MaxBookingCloseMinutes
+// - descripción sanitizada según su formato y dentro de los límites de largo
+// - origen y destino resueltos contra el catálogo de ciudades (city_id o nombre ambiguo)
+// - moneda, precio y distancia dentro de los límites del mercado del origen
 // - driver_id debe existir (llamada a users-api)
 //
 // Valores iniciales:
 // - available_seats = total_seats
 // - reserved_seats = 0
-// - status = "published"
+// - status = "published" ("closed" si la salida ya está dentro de la anticipación de cierre)
 // - availability_version = 1
 //
 // Ejemplo de uso:
@@ -84,6 +154,92 @@ func NewTripService(
 //	    return c.JSON(500, gin.H{"error": err.Error()})
 //	}
 func (s *tripService) CreateTrip(ctx context.Context, driverID int64, authToken string, request domain.CreateTripRequest) (*domain.Trip, error) {
+	trip, err := s.prepareTrip(ctx, driverID, request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validación 12: Verificar que el driver existe en users-api (forward auth token)
+	// La respuesta se reutiliza como snapshot del conductor en el evento trip.created
+	driver, err := s.usersClient.GetUser(ctx, driverID, authToken)
+	if err != nil {
+		// Si es ErrDriverNotFound, mantener ese error específico
+		return nil, fmt.Errorf("failed to validate driver: %w", err)
+	}
+	// Las cuentas dependientes (menores) no pueden conducir
+	if !driver.CanDrive() {
+		return nil, domain.ErrDrivingNotAllowed
+	}
+
+	if err := s.insertTrip(ctx, trip); err != nil {
+		return nil, err
+	}
+
+	// Publicar evento trip.created con snapshot del conductor (fire-and-forget)
+	s.publisher.PublishTripCreated(ctx, trip, s.driverSnapshot(ctx, driverID, driver))
+
+	return trip, nil
+}
+
+// ImportTrips implementa la importación masiva de viajes (POST /trips/bulk)
+//
+//   - El conductor se valida una sola vez contra users-api, antes de crear cualquier viaje
+//   - Cada fila pasa por las validaciones 1 a 11 de CreateTrip y se crea por separado:
+//     una fila rechazada no impide crear las demás
+//   - Los trip.created se publican al final, en lotes de tripImportEventBatch, con el mismo snapshot del conductor
+func (s *tripService) ImportTrips(ctx context.Context, driverID int64, authToken string, rows []domain.TripImportRow) (*domain.TripImportReport, error) {
+	driver, err := s.usersClient.GetUser(ctx, driverID, authToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate driver: %w", err)
+	}
+	if !driver.CanDrive() {
+		return nil, domain.ErrDrivingNotAllowed
+	}
+
+	report := domain.NewTripImportReport(len(rows))
+	created := make([]*domain.Trip, 0, len(rows))
+	for _, row := range rows {
+		if row.Err != nil {
+			report.AddFailed(row.Row, row.Err)
+			continue
+		}
+
+		trip, err := s.prepareTrip(ctx, driverID, row.Request)
+		if err == nil {
+			err = s.insertTrip(ctx, trip)
+		}
+		if err != nil {
+			report.AddFailed(row.Row, err)
+			continue
+		}
+
+		report.AddCreated(row.Row, trip)
+		created = append(created, trip)
+	}
+
+	log.Info().
+		Int64("driver_id", driverID).
+		Int("total", report.Total).
+		Int("created", report.Created).
+		Int("failed", report.Failed).
+		Msg("Trip import processed")
+
+	if len(created) == 0 {
+		return report, nil
+	}
+
+	snapshot := s.driverSnapshot(ctx, driverID, driver)
+	for start := 0; start < len(created); start += tripImportEventBatch {
+		end := min(start+tripImportEventBatch, len(created))
+		s.publisher.PublishTripsCreated(ctx, created[start:end], snapshot)
+	}
+
+	return report, nil
+}
+
+// prepareTrip aplica las validaciones 1 a 11 de CreateTrip y arma el viaje con sus valores iniciales
+// (compartido con ImportTrips)
+func (s *tripService) prepareTrip(ctx context.Context, driverID int64, request domain.CreateTripRequest) (*domain.Trip, error) {
 	// Validación 1: Parsear fecha de salida
 	departureTime, err := time.Parse(time.RFC3339, request.DepartureDatetime)
 	if err != nil {
@@ -111,25 +267,56 @@ func (s *tripService) CreateTrip(ctx context.Context, driverID int64, authToken
 		return nil, fmt.Errorf("total_seats must be between 1 and 8")
 	}
 
-	// Validación 6: Verificar que el driver existe en users-api (forward auth token)
-	_, err = s.usersClient.GetUser(ctx, driverID, authToken)
+	// Validación 6: El viaje no puede caer dentro de una vacación del conductor
+	vacation, err := s.vacationRepo.FindOverlapping(ctx, driverID, departureTime, arrivalTime)
 	if err != nil {
-		// Si es ErrDriverNotFound, mantener ese error específico
-		return nil, fmt.Errorf("failed to validate driver: %w", err)
+		return nil, fmt.Errorf("failed to check driver vacations: %w", err)
+	}
+	if vacation != nil {
+		return nil, domain.ErrDriverOnVacation
+	}
+
+	// Validación 7: Puntos de encuentro (opcionales)
+	pickupPoints, err := domain.PreparePickupPoints(request.PickupPoints, nil, departureTime, arrivalTime)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validación 8: Anticipación del cierre de reservas (opcional, por defecto la del mercado)
+	if err := domain.ValidateBookingCloseMinutes(request.BookingCloseMinutes); err != nil {
+		return nil, err
+	}
+
+	// Validación 9: Descripción (se guarda sanitizada, con su texto plano)
+	description, err := s.descriptions.Process(request.DescriptionFormat, request.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validación 10: Origen y destino se resuelven a las ciudades canónicas del catálogo
+	origin, destination := request.Origin, request.Destination
+	if err := resolveTripCities(s.catalog, &origin, &destination); err != nil {
+		return nil, err
 	}
 
 	// Construir el trip con valores iniciales
 	trip := &domain.Trip{
 		DriverID:                 driverID,
-		Origin:                   request.Origin,
-		Destination:              request.Destination,
+		Origin:                   origin,
+		Destination:              destination,
+		RouteID:                  domain.RouteID(origin, destination),
 		DepartureDatetime:        departureTime,
 		EstimatedArrivalDatetime: arrivalTime,
 		PricePerSeat:             request.PricePerSeat,
+		Currency:                 request.Currency,
 		TotalSeats:               request.TotalSeats,
 		Car:                      request.Car,
 		Preferences:              request.Preferences,
-		Description:              request.Description,
+		Description:              description.Content,
+		DescriptionFormat:        description.Format,
+		DescriptionText:          description.Text,
+		PickupPoints:             pickupPoints,
+		BookingCloseMinutes:      request.BookingCloseMinutes,
 
 		// Valores iniciales CRÍTICOS
 		AvailableSeats:      request.TotalSeats, // Todos los asientos disponibles inicialmente
@@ -138,18 +325,102 @@ func (s *tripService) CreateTrip(ctx context.Context, driverID int64, authToken
 		AvailabilityVersion: 1,                  // Versión inicial para optimistic locking
 	}
 
-	// Crear el trip en la base de datos
+	// Validación 11: Límites del mercado (moneda, precio por asiento, distancia) y cierre de reservas
+	if err := s.applyMarketPolicy(trip, domain.AllMarketChecks); err != nil {
+		return nil, err
+	}
+
+	// Un viaje que sale antes de su anticipación de cierre se crea ya cerrado a reservas
+	trip.SyncBookingClose(time.Now())
+
+	return trip, nil
+}
+
+// insertTrip crea el viaje en la base de datos y lo suma al catálogo de rutas
+func (s *tripService) insertTrip(ctx context.Context, trip *domain.Trip) error {
 	if err := s.tripRepo.Create(ctx, trip); err != nil {
-		log.Error().Err(err).Int64("driver_id", driverID).Msg("Failed to create trip")
-		return nil, fmt.Errorf("failed to create trip: %w", err)
+		log.Error().Err(err).Int64("driver_id", trip.DriverID).Msg("Failed to create trip")
+		return fmt.Errorf("failed to create trip: %w", err)
 	}
 
-	log.Info().Str("trip_id", trip.ID.Hex()).Int64("driver_id", driverID).Msg("Trip created")
+	log.Info().Str("trip_id", trip.ID.Hex()).Int64("driver_id", trip.DriverID).Str("route_id", trip.RouteID).Msg("Trip created")
 
-	// Publicar evento trip.created (fire-and-forget)
-	s.publisher.PublishTripCreated(ctx, trip)
+	// Sumar el viaje al catálogo de rutas (no crítico)
+	recordRoute(ctx, s.catalog, s.cityRepo, trip)
+	return nil
+}
 
-	return trip, nil
+// driverSnapshot arma el snapshot del conductor de los eventos trip.created desde la respuesta de users-api
+func (s *tripService) driverSnapshot(ctx context.Context, driverID int64, driver *clients.User) *messaging.DriverSnapshot {
+	// Tiempos de respuesta del conductor para el snapshot (no crítico: se omiten si fallan)
+	responseTime, err := s.responseTimes.GetDriverSnapshot(ctx, driverID)
+	if err != nil {
+		log.Warn().Err(err).Int64("driver_id", driverID).Msg("Failed to load driver response time for snapshot")
+	}
+
+	return &messaging.DriverSnapshot{
+		ID:           driver.ID,
+		Name:         driver.Name,
+		Rating:       driver.AvgDriverRating,
+		TotalTrips:   driver.TotalTripsDriver,
+		PhotoURL:     driver.PhotoURL,
+		FetchedAt:    time.Now(),
+		ResponseTime: responseTime,
+	}
+}
+
+// applyMarketPolicy resuelve el mercado del viaje desde su origen, completa la moneda
+// por defecto (viajes nuevos o anteriores a los mercados), valida los límites del mercado
+// seleccionados en checks y calcula el cierre de reservas (booking_closes_at)
+func (s *tripService) applyMarketPolicy(trip *domain.Trip, checks domain.MarketChecks) error {
+	return applyMarketPolicy(s.markets, trip, checks)
+}
+
+// applyMarketPolicy aplica la política de mercado a un viaje (compartido con los viajes recurrentes)
+//
+// El mercado lo decide el servidor: el país del origen solo cuenta si viene del catálogo de
+// ciudades (city_id resuelto); el que envía el cliente para una ciudad de texto libre se ignora
+// y se resuelve por provincia. Si el viaje cambia de mercado se validan todos los límites
+func applyMarketPolicy(markets market.Registry, trip *domain.Trip, checks domain.MarketChecks) error {
+	origin := trip.Origin
+	if origin.CityID == "" {
+		origin.Country = ""
+	}
+	policy, source := markets.Resolve(origin)
+
+	if trip.Market != "" && trip.Market != policy.Country {
+		checks = domain.AllMarketChecks
+	}
+	trip.Market = policy.Country
+	trip.Currency = strings.ToUpper(strings.TrimSpace(trip.Currency))
+	if trip.Currency == "" {
+		trip.Currency = policy.DefaultCurrency()
+	}
+
+	if err := policy.ValidateTripChecks(trip, checks); err != nil {
+		log.Warn().
+			Err(err).
+			Str("market", policy.Country).
+			Str("market_source", source).
+			Int64("driver_id", trip.DriverID).
+			Msg("Trip rejected by market policy")
+		return err
+	}
+
+	trip.ApplyBookingClose(policy.BookingCloseMinutes)
+	return nil
+}
+
+// updateDescription vuelve a sanitizar la descripción con los valores nuevos (nil conserva el actual)
+// Compartido con los viajes recurrentes
+func updateDescription(descriptions richtext.Pipeline, currentFormat, currentContent string, format, content *string) (domain.TripDescription, error) {
+	if format != nil {
+		currentFormat = *format
+	}
+	if content != nil {
+		currentContent = *content
+	}
+	return descriptions.Process(currentFormat, currentContent)
 }
 
 // GetTrip obtiene un viaje por su ID
@@ -186,9 +457,11 @@ func (s *tripService) ListTrips(ctx context.Context, filters map[string]interfac
 //
 // Validaciones:
 // - Solo el dueño puede actualizar (userID == driver_id)
-// - No se puede actualizar si reserved_seats > 0
+// - No se puede actualizar si reserved_seats > 0 o hay asientos retenidos (held_seats > 0)
 // - No se puede cambiar total_seats a menos que reserved_seats
 // - Las fechas deben ser válidas si se proporcionan
+// - Si cambian las fechas, el viaje no puede caer dentro de una vacación del conductor
+// - El viaje resultante debe respetar los límites de su mercado
 func (s *tripService) UpdateTrip(ctx context.Context, tripID string, userID int64, userRole string, request domain.UpdateTripRequest) (*domain.Trip, error) {
 	// Obtener el trip actual
 	trip, err := s.tripRepo.FindByID(ctx, tripID)
@@ -201,19 +474,29 @@ func (s *tripService) UpdateTrip(ctx context.Context, tripID string, userID int6
 		return nil, domain.ErrUnauthorized
 	}
 
-	// Validación 2: No se puede actualizar si hay reservas
-	if trip.ReservedSeats > 0 {
+	// Validación 2: No se puede actualizar si hay reservas (ni retenciones, que están por convertirse en reservas)
+	if trip.ReservedSeats > 0 || trip.HeldSeats > 0 {
 		return nil, domain.ErrHasReservations
 	}
 
 	// Aplicar actualizaciones opcionales
+	// Las ubicaciones nuevas se resuelven contra el catálogo de ciudades (igual que al crear)
 	if request.Origin != nil {
-		trip.Origin = *request.Origin
+		origin := *request.Origin
+		if err := resolveCity(s.catalog, "origin", &origin); err != nil {
+			return nil, err
+		}
+		trip.Origin = origin
 	}
 
 	if request.Destination != nil {
-		trip.Destination = *request.Destination
+		destination := *request.Destination
+		if err := resolveCity(s.catalog, "destination", &destination); err != nil {
+			return nil, err
+		}
+		trip.Destination = destination
 	}
+	trip.RouteID = domain.RouteID(trip.Origin, trip.Destination)
 
 	if request.DepartureDatetime != nil {
 		departureTime, err := time.Parse(time.RFC3339, *request.DepartureDatetime)
@@ -237,6 +520,18 @@ func (s *tripService) UpdateTrip(ctx context.Context, tripID string, userID int6
 		trip.EstimatedArrivalDatetime = arrivalTime
 	}
 
+	// Las nuevas fechas no pueden caer dentro de una vacación del conductor (igual que al crear)
+	if request.DepartureDatetime != nil || request.EstimatedArrivalDatetime != nil {
+		vacation, err := s.vacationRepo.FindOverlapping(ctx, trip.DriverID, trip.DepartureDatetime, trip.EstimatedArrivalDatetime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check driver vacations: %w", err)
+		}
+		if vacation != nil {
+			return nil, domain.ErrDriverOnVacation
+		}
+	}
+
+	previousPrice, previousCurrency := trip.PricePerSeat, trip.Currency
 	if request.PricePerSeat != nil {
 		if *request.PricePerSeat < 0 {
 			return nil, fmt.Errorf("price_per_seat must be non-negative")
@@ -244,6 +539,32 @@ func (s *tripService) UpdateTrip(ctx context.Context, tripID string, userID int6
 		trip.PricePerSeat = *request.PricePerSeat
 	}
 
+	if request.Currency != nil {
+		trip.Currency = *request.Currency
+	}
+
+	// Un cambio de moneda también cambia el precio (aunque el número sea el mismo)
+	var priceChange *domain.TripPriceChange
+	if trip.PricePerSeat != previousPrice || trip.Currency != previousCurrency {
+		changedAt := time.Now()
+		trip.PreviousPricePerSeat = &previousPrice
+		trip.PriceChangedAt = &changedAt
+
+		changedByRole := domain.PriceChangedByDriver
+		if userRole == "admin" {
+			changedByRole = domain.PriceChangedByAdmin
+		}
+		priceChange = &domain.TripPriceChange{
+			TripID:               tripID,
+			PricePerSeat:         trip.PricePerSeat,
+			PreviousPricePerSeat: previousPrice,
+			Currency:             trip.Currency,
+			ChangedBy:            userID,
+			ChangedByRole:        changedByRole,
+			ChangedAt:            changedAt,
+		}
+	}
+
 	if request.TotalSeats != nil {
 		// Validación 3: No se puede reducir total_seats por debajo de reserved_seats
 		if *request.TotalSeats < trip.ReservedSeats {
@@ -267,9 +588,45 @@ func (s *tripService) UpdateTrip(ctx context.Context, tripID string, userID int6
 		trip.Preferences = *request.Preferences
 	}
 
-	if request.Description != nil {
-		trip.Description = *request.Description
+	if request.Description != nil || request.DescriptionFormat != nil {
+		description, err := updateDescription(s.descriptions, trip.DescriptionFormat, trip.Description, request.DescriptionFormat, request.Description)
+		if err != nil {
+			return nil, err
+		}
+		trip.Description = description.Content
+		trip.DescriptionFormat = description.Format
+		trip.DescriptionText = description.Text
+	}
+
+	if request.BookingCloseMinutes != nil {
+		if err := domain.ValidateBookingCloseMinutes(request.BookingCloseMinutes); err != nil {
+			return nil, err
+		}
+		trip.BookingCloseMinutes = request.BookingCloseMinutes
+	}
+
+	// Los puntos de encuentro se validan contra las fechas ya actualizadas
+	if request.PickupPoints != nil {
+		pickupPoints, err := domain.PreparePickupPoints(*request.PickupPoints, trip.PickupPoints, trip.DepartureDatetime, trip.EstimatedArrivalDatetime)
+		if err != nil {
+			return nil, err
+		}
+		trip.PickupPoints = pickupPoints
+	}
+
+	// El mercado se resuelve de nuevo (el origen puede haber cambiado), pero solo se validan los
+	// límites que dependen de lo que se editó: un viaje anterior a un límite sigue siendo editable
+	marketChecks := domain.MarketChecks{
+		Currency: request.Currency != nil,
+		Price:    request.PricePerSeat != nil || request.Currency != nil,
+		Distance: request.Origin != nil || request.Destination != nil,
 	}
+	if err := s.applyMarketPolicy(trip, marketChecks); err != nil {
+		return nil, err
+	}
+
+	// Un cambio de salida o de anticipación puede cerrar o reabrir las reservas
+	trip.SyncBookingClose(time.Now())
 
 	// Actualizar en la base de datos
 	if err := s.tripRepo.Update(ctx, tripID, trip); err != nil {
@@ -279,12 +636,39 @@ func (s *tripService) UpdateTrip(ctx context.Context, tripID string, userID int6
 
 	log.Info().Str("trip_id", tripID).Int64("user_id", userID).Msg("Trip updated")
 
+	// El viaje ya guarda el último cambio (previous_price_per_seat); si falla el historial solo se loguea
+	if priceChange != nil {
+		if err := s.priceHistoryRepo.Insert(ctx, priceChange); err != nil {
+			log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to record price change")
+		}
+	}
+
 	// Publicar evento trip.updated (fire-and-forget)
 	s.publisher.PublishTripUpdated(ctx, trip)
 
 	return trip, nil
 }
 
+// GetPriceHistory obtiene el precio actual del viaje y sus últimos cambios (público, como GetTrip)
+func (s *tripService) GetPriceHistory(ctx context.Context, tripID string) (*domain.TripPriceHistory, error) {
+	trip, err := s.tripRepo.FindByID(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.priceHistoryRepo.FindByTrip(ctx, tripID, maxPriceHistoryEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TripPriceHistory{
+		TripID:              tripID,
+		CurrentPricePerSeat: trip.PricePerSeat,
+		Currency:            trip.Currency,
+		Changes:             changes,
+	}, nil
+}
+
 // DeleteTrip elimina un viaje (solo el dueño)
 //
 // Validaciones:
@@ -334,63 +718,89 @@ func (s *tripService) ProcessReservationCreated(ctx context.Context, event messa
 		return fmt.Errorf("failed to fetch trip: %w", err) // System error - NACK
 	}
 
-	// 2. Attempt to reserve seats with optimistic locking
-	// seatsDelta is NEGATIVE to decrease available_seats
-	err = s.tripRepo.UpdateAvailability(ctx, event.TripID, -event.SeatsReserved, trip.AvailabilityVersion)
+	// Pickup point chosen by the passenger must belong to the trip
+	if event.PickupPointID != "" && trip.FindPickupPoint(event.PickupPointID) == nil {
+		log.Warn().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Str("pickup_point_id", event.PickupPointID).
+			Msg("Unknown pickup point - publishing reservation.failed")
 
-	if err == domain.ErrOptimisticLockFailed {
-		// No seats available OR version conflict - publish compensation event
+		s.publisher.PublishReservationFailure(
+			ctx,
+			event.ReservationID,
+			event.TripID,
+			"Invalid pickup point",
+			trip.AvailableSeats,
+		)
+		return nil // ACK - failure handled
+	}
+
+	// Only published trips take reservations (paused by driver vacation, in progress, completed
+	// or cancelled trips do not) - reject reservation with compensation event
+	if !domain.AcceptsReservations(trip.Status) {
+		reason := domain.ReservationRejectionReason(trip.Status)
 		log.Warn().
 			Str("trip_id", event.TripID).
 			Str("reservation_id", event.ReservationID).
-			Int("seats_requested", event.SeatsReserved).
-			Int("available_seats", trip.AvailableSeats).
-			Int("expected_version", trip.AvailabilityVersion).
-			Msg("Failed to reserve seats - publishing reservation.failed")
+			Str("status", trip.Status).
+			Msg(reason + " - publishing reservation.failed")
 
-		// Publish compensating event
 		s.publisher.PublishReservationFailure(
 			ctx,
 			event.ReservationID,
 			event.TripID,
-			"No seats available or version conflict",
+			reason,
 			trip.AvailableSeats,
 		)
 		return nil // ACK - failure handled
 	}
 
-	if err != nil {
-		return fmt.Errorf("failed to update availability: %w", err) // System error - NACK
+	// 2. Reservation made over a seat hold: the seats left available_seats when the hold was taken,
+	// confirming it moves them to reserved_seats (and records the ledger entry)
+	seatsTaken := false
+	if event.SeatHoldID != "" {
+		seatsTaken, trip, err = s.confirmSeatHold(ctx, trip, event)
+		if err != nil {
+			return err // System error - NACK
+		}
+	}
+	if !seatsTaken {
+		if handled, err := s.reserveSeats(ctx, trip, event); handled || err != nil {
+			return err
+		}
 	}
 
-	// 3. Success - fetch updated trip and publish events
+	// 4. Success - fetch updated trip and publish events
 	updatedTrip, err := s.tripRepo.FindByID(ctx, event.TripID)
 	if err != nil {
 		log.Error().Err(err).Str("trip_id", event.TripID).Msg("Failed to fetch updated trip")
 		// Don't fail - seats already updated
-	} else {
-		// Calculate total price for the reservation
-		totalPrice := updatedTrip.PricePerSeat * float64(event.SeatsReserved)
+		return nil
+	}
 
-		// Publish reservation.confirmed event back to bookings-api
-		s.publisher.PublishReservationConfirmation(
-			ctx,
-			event.ReservationID,
-			event.TripID,
-			event.PassengerID,
-			updatedTrip.DriverID,
-			event.SeatsReserved,
-			totalPrice,
-			updatedTrip.AvailableSeats,
-		)
+	// Calculate total price for the reservation
+	totalPrice := updatedTrip.PricePerSeat * float64(event.SeatsReserved)
 
-		// Publish trip.updated event for other consumers
-		s.publisher.PublishTripUpdated(ctx, updatedTrip)
-	}
+	// Publish reservation.confirmed event back to bookings-api
+	s.publisher.PublishReservationConfirmation(
+		ctx,
+		event.ReservationID,
+		event.TripID,
+		event.PassengerID,
+		updatedTrip.DriverID,
+		event.SeatsReserved,
+		totalPrice,
+		updatedTrip.AvailableSeats,
+	)
+
+	// Publish trip.updated event for other consumers
+	s.publisher.PublishTripUpdated(ctx, updatedTrip)
 
 	log.Info().
 		Str("trip_id", event.TripID).
 		Str("reservation_id", event.ReservationID).
+		Str("seat_hold_id", event.SeatHoldID).
 		Int64("passenger_id", event.PassengerID).
 		Int("seats_reserved", event.SeatsReserved).
 		Int("available_seats", updatedTrip.AvailableSeats).
@@ -400,6 +810,130 @@ func (s *tripService) ProcessReservationCreated(ctx context.Context, event messa
 	return nil // ACK
 }
 
+// confirmSeatHold confirma la retención de un reservation.created
+// Retorna seatsTaken=false si la retención venció, se liberó o no corresponde a la reserva: la reserva
+// se valida entonces contra los asientos libres, con el viaje releído (la retención cambió su versión)
+func (s *tripService) confirmSeatHold(ctx context.Context, trip *domain.Trip, event messaging.ReservationCreatedEvent) (bool, *domain.Trip, error) {
+	err := s.seatHolds.ConfirmForReservation(ctx, trip, event.SeatHoldID, event.ReservationID, event.SeatsReserved)
+	switch err {
+	case nil, domain.ErrSeatHoldConfirmed:
+		// ErrSeatHoldConfirmed: bookings-api ya la confirmó con POST .../confirm (o es una reentrega)
+		return true, trip, nil
+	case domain.ErrSeatHoldNotFound:
+		log.Warn().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Str("seat_hold_id", event.SeatHoldID).
+			Msg("Seat hold expired or released - reserving seats without it")
+		current, err := s.tripRepo.FindByID(ctx, event.TripID)
+		if err != nil {
+			return false, trip, fmt.Errorf("failed to fetch trip: %w", err)
+		}
+		return false, current, nil
+	default:
+		return false, trip, fmt.Errorf("failed to confirm seat hold: %w", err)
+	}
+}
+
+// reserveSeats descuenta los asientos de una reserva sin retención con optimistic locking
+// Retorna handled=true si la reserva se rechazó con reservation.failed (ACK sin confirmación)
+//
+// UpdateAvailability no distingue un conflicto de versión de la falta de asientos: ante un fallo se
+// relee el viaje y se rechaza solo si ya no acepta la reserva. Un conflicto (otra reserva o una
+// cancelación concurrente) se reintenta hasta maxReservationAttempts veces con espera y jitter.
+//
+// La reserva se registra en el ledger local ANTES de tocar los asientos: si la escritura falla el
+// evento se reentrega (NACK) sin haber cambiado el viaje, y Confirm es idempotente ante la reentrega
+func (s *tripService) reserveSeats(ctx context.Context, trip *domain.Trip, event messaging.ReservationCreatedEvent) (bool, error) {
+	err := s.reservationRepo.Confirm(ctx, &domain.TripReservation{
+		ReservationID: event.ReservationID,
+		TripID:        event.TripID,
+		PassengerID:   event.PassengerID,
+		Seats:         event.SeatsReserved,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update reservation ledger: %w", err) // System error - NACK
+	}
+
+	// reject deshace la entrada del ledger (la reserva no tomó asientos) y publica reservation.failed
+	reject := func(reason string, availableSeats int) (bool, error) {
+		if _, err := s.reservationRepo.Cancel(ctx, event.ReservationID); err != nil {
+			return false, fmt.Errorf("failed to update reservation ledger: %w", err) // System error - NACK
+		}
+
+		log.Warn().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Int("seats_requested", event.SeatsReserved).
+			Int("available_seats", availableSeats).
+			Str("reason", reason).
+			Msg("Failed to reserve seats - publishing reservation.failed")
+
+		// Publish compensating event
+		s.publisher.PublishReservationFailure(ctx, event.ReservationID, event.TripID, reason, availableSeats)
+		return true, nil // ACK - failure handled
+	}
+
+	for attempt := 1; ; attempt++ {
+		// 2. Attempt to reserve seats with optimistic locking
+		// seatsDelta is NEGATIVE to decrease available_seats
+		err := s.tripRepo.UpdateAvailability(ctx, event.TripID, -event.SeatsReserved, trip.AvailabilityVersion)
+		if err == nil {
+			break
+		}
+		if err != domain.ErrOptimisticLockFailed {
+			return false, fmt.Errorf("failed to update availability: %w", err) // System error - NACK
+		}
+
+		// No seats available OR version conflict: re-fetch the trip to tell them apart
+		trip, err = s.tripRepo.FindByID(ctx, event.TripID)
+		if err != nil {
+			if err == domain.ErrTripNotFound {
+				return reject("Trip not found", 0)
+			}
+			return false, fmt.Errorf("failed to fetch trip: %w", err) // System error - NACK
+		}
+		if !domain.AcceptsReservations(trip.Status) {
+			return reject(domain.ReservationRejectionReason(trip.Status), trip.AvailableSeats)
+		}
+		if trip.AvailableSeats < event.SeatsReserved {
+			return reject("No seats available", trip.AvailableSeats)
+		}
+		if attempt == maxReservationAttempts {
+			return reject("Version conflict", trip.AvailableSeats)
+		}
+
+		log.Debug().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Int("attempt", attempt).
+			Int("expected_version", trip.AvailabilityVersion).
+			Msg("Version conflict applying reservation.created - retrying")
+
+		if err := waitBeforeRetry(ctx, attempt); err != nil {
+			return false, err // Context cancelled (shutdown) - NACK, the event is redelivered
+		}
+	}
+
+	return false, nil
+}
+
+// waitBeforeRetry espera antes del siguiente intento tras un conflicto de versión
+// La espera crece con el intento y suma un jitter aleatorio para que los consumidores
+// que chocaron no vuelvan a intentar al mismo tiempo
+func waitBeforeRetry(ctx context.Context, attempt int) error {
+	delay := time.Duration(attempt)*reservationRetryBaseDelay + time.Duration(rand.Int63n(int64(reservationRetryBaseDelay)))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // ProcessReservationCancelled maneja eventos de reservation.cancelled
 // Libera asientos previamente reservados
 func (s *tripService) ProcessReservationCancelled(ctx context.Context, event messaging.ReservationCancelledEvent) error {
@@ -416,7 +950,27 @@ func (s *tripService) ProcessReservationCancelled(ctx context.Context, event mes
 		return fmt.Errorf("failed to fetch trip: %w", err) // System error - NACK
 	}
 
-	// 2. Release seats with optimistic locking
+	// 2. The reservation no longer counts in the ledger, even if the seats are not released below
+	// (a lost optimistic lock leaves a drift that the seat drift checker reports)
+	// Written before the seats: if it fails the event is redelivered without having touched the trip
+	wasConfirmed, err := s.reservationRepo.Cancel(ctx, event.ReservationID)
+	if err != nil {
+		return fmt.Errorf("failed to update reservation ledger: %w", err) // System error - NACK
+	}
+
+	// Only a reservation that took seats releases them: bookings-api also cancels bookings that were
+	// rejected or never processed here (expired pending bookings), and a redelivery finds it already
+	// cancelled. Trips created before the ledger existed have no entries, so they keep releasing
+	if !wasConfirmed && !trip.CreatedAt.Before(s.ledgerSince) {
+		log.Info().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Int("seats_released", event.SeatsReleased).
+			Msg("Reservation never took seats - nothing to release")
+		return nil // ACK
+	}
+
+	// 3. Release seats with optimistic locking
 	// seatsDelta is POSITIVE to increase available_seats
 	err = s.tripRepo.UpdateAvailability(ctx, event.TripID, event.SeatsReleased, trip.AvailabilityVersion)
 
@@ -435,7 +989,7 @@ func (s *tripService) ProcessReservationCancelled(ctx context.Context, event mes
 		return fmt.Errorf("failed to release seats: %w", err) // System error - NACK
 	}
 
-	// 3. Success - fetch updated trip and publish trip.updated event
+	// 4. Success - fetch updated trip and publish trip.updated event
 	updatedTrip, err := s.tripRepo.FindByID(ctx, event.TripID)
 	if err != nil {
 		log.Error().Err(err).Str("trip_id", event.TripID).Msg("Failed to fetch updated trip")
@@ -453,3 +1007,120 @@ func (s *tripService) ProcessReservationCancelled(ctx context.Context, event mes
 
 	return nil // ACK
 }
+
+// ProcessReservationModified maneja eventos de reservation.modified
+// Aplica el delta de asientos con optimistic locking, reintentando ante conflictos de versión:
+// - delta positivo: el pasajero pide más asientos (puede fallar → reservation.modification_failed)
+// - delta negativo: el pasajero libera asientos (siempre se aplica)
+//
+// El ledger se actualiza ANTES que el viaje (con los asientos absolutos, idempotente): si la escritura
+// falla el evento se reentrega (NACK) sin haber aplicado el delta. Un aumento rechazado lo deshace
+func (s *tripService) ProcessReservationModified(ctx context.Context, event messaging.ReservationModifiedEvent) error {
+	delta := event.SeatsDelta
+	if delta == 0 {
+		log.Warn().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Msg("reservation.modified without seat delta - ignoring")
+		return nil // ACK - nada que aplicar
+	}
+
+	if err := s.reservationRepo.SetSeats(ctx, event.ReservationID, event.NewSeats); err != nil {
+		return fmt.Errorf("failed to update reservation ledger: %w", err) // System error - NACK
+	}
+
+	// rejectIncrease vuelve el ledger a los asientos anteriores y publica reservation.modification_failed
+	rejectIncrease := func(reason string, availableSeats int) error {
+		if err := s.reservationRepo.SetSeats(ctx, event.ReservationID, event.PreviousSeats); err != nil {
+			return fmt.Errorf("failed to update reservation ledger: %w", err) // System error - NACK
+		}
+		s.publisher.PublishReservationModificationFailure(ctx, event, reason, availableSeats)
+		return nil // ACK - failure handled
+	}
+
+	var trip *domain.Trip
+	var err error
+	for attempt := 1; attempt <= maxModificationAttempts; attempt++ {
+		// 1. Fetch trip to get current availability_version (se relee en cada intento)
+		trip, err = s.tripRepo.FindByID(ctx, event.TripID)
+		if err != nil {
+			if err == domain.ErrTripNotFound {
+				log.Warn().
+					Str("trip_id", event.TripID).
+					Str("reservation_id", event.ReservationID).
+					Msg("Trip not found for reservation modification")
+				if delta > 0 {
+					return rejectIncrease("Trip not found", 0)
+				}
+				return nil // ACK - trip not found
+			}
+			return fmt.Errorf("failed to fetch trip: %w", err) // System error - NACK
+		}
+
+		// 2. Validaciones de negocio (solo para aumentos)
+		if reason := domain.SeatIncreaseRejection(trip.Status, trip.AvailableSeats, delta); reason != "" {
+			log.Warn().
+				Str("trip_id", event.TripID).
+				Str("reservation_id", event.ReservationID).
+				Int("seats_delta", delta).
+				Int("available_seats", trip.AvailableSeats).
+				Str("reason", reason).
+				Msg("Cannot apply seat increase - publishing reservation.modification_failed")
+
+			return rejectIncrease(reason, trip.AvailableSeats)
+		}
+
+		// 3. Aplicar delta con optimistic locking
+		// seatsDelta es NEGATIVO para tomar asientos y POSITIVO para liberarlos
+		err = s.tripRepo.UpdateAvailability(ctx, event.TripID, -delta, trip.AvailabilityVersion)
+		if err != domain.ErrOptimisticLockFailed {
+			break
+		}
+
+		log.Debug().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Int("attempt", attempt).
+			Int("expected_version", trip.AvailabilityVersion).
+			Msg("Version conflict applying reservation.modified - retrying")
+	}
+
+	if err == domain.ErrOptimisticLockFailed {
+		log.Warn().
+			Str("trip_id", event.TripID).
+			Str("reservation_id", event.ReservationID).
+			Int("seats_delta", delta).
+			Int("attempts", maxModificationAttempts).
+			Msg("Optimistic lock failed on reservation modification")
+
+		if delta > 0 {
+			// Compensar para que bookings-api vuelva a los asientos anteriores
+			return rejectIncrease("Version conflict", trip.AvailableSeats)
+		}
+		// La reserva igual se achicó en bookings-api: el ledger ya lo refleja y el drift queda a la vista
+		return nil // ACK - eventual consistency (igual que en cancelaciones)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to update availability: %w", err) // System error - NACK
+	}
+
+	// 4. Success - fetch updated trip and publish trip.updated event
+	updatedTrip, err := s.tripRepo.FindByID(ctx, event.TripID)
+	if err != nil {
+		log.Error().Err(err).Str("trip_id", event.TripID).Msg("Failed to fetch updated trip")
+		return nil // Don't fail - seats already updated
+	}
+	s.publisher.PublishTripUpdated(ctx, updatedTrip)
+
+	log.Info().
+		Str("trip_id", event.TripID).
+		Str("reservation_id", event.ReservationID).
+		Int("previous_seats", event.PreviousSeats).
+		Int("new_seats", event.NewSeats).
+		Int("available_seats", updatedTrip.AvailableSeats).
+		Int("reserved_seats", updatedTrip.ReservedSeats).
+		Msg("Reservation modified successfully")
+
+	return nil // ACK
+}
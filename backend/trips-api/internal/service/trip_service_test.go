@@ -69,13 +69,29 @@ func (m *MockUsersClient) GetUser(ctx context.Context, userID int64) (*clients.U
 	return args.Get(0).(*clients.User), args.Error(1)
 }
 
+func (m *MockUsersClient) GetChatPreferences(ctx context.Context, userID int64) (*clients.ChatPreferences, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*clients.ChatPreferences), args.Error(1)
+}
+
+func (m *MockUsersClient) GetSessionStatus(ctx context.Context, userID int64) (*clients.SessionStatus, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*clients.SessionStatus), args.Error(1)
+}
+
 // MockPublisher is a mock implementation of Publisher
 type MockPublisher struct {
 	mock.Mock
 }
 
-func (m *MockPublisher) PublishTripCreated(ctx context.Context, trip *domain.Trip) {
-	m.Called(ctx, trip)
+func (m *MockPublisher) PublishTripCreated(ctx context.Context, trip *domain.Trip, driver *messaging.DriverSnapshot) {
+	m.Called(ctx, trip, driver)
 }
 
 func (m *MockPublisher) PublishTripUpdated(ctx context.Context, trip *domain.Trip) {
@@ -111,7 +127,13 @@ func TestCreateTrip_Success(t *testing.T) {
 	mockPublisher := new(MockPublisher)
 
 	// Mock: Driver exists
-	mockUsersClient.On("GetUser", ctx, driverID).Return(&clients.User{ID: driverID}, nil)
+	mockUsersClient.On("GetUser", ctx, driverID).Return(&clients.User{
+		ID:               driverID,
+		Name:             "Juan",
+		PhotoURL:         "https://example.com/juan.jpg",
+		AvgDriverRating:  4.8,
+		TotalTripsDriver: 12,
+	}, nil)
 
 	// Mock: Create trip succeeds
 	mockRepo.On("Create", ctx, mock.MatchedBy(func(trip *domain.Trip) bool {
@@ -122,8 +144,16 @@ func TestCreateTrip_Success(t *testing.T) {
 			trip.AvailabilityVersion == 1
 	})).Return(nil)
 
-	// Mock: Publish event succeeds (void method)
-	mockPublisher.On("PublishTripCreated", ctx, mock.AnythingOfType("*domain.Trip"))
+	// Mock: Publish event succeeds (void method) with the driver snapshot taken from users-api
+	mockPublisher.On("PublishTripCreated", ctx, mock.AnythingOfType("*domain.Trip"), mock.MatchedBy(func(driver *messaging.DriverSnapshot) bool {
+		return driver != nil &&
+			driver.ID == driverID &&
+			driver.Name == "Juan" &&
+			driver.PhotoURL == "https://example.com/juan.jpg" &&
+			driver.Rating == 4.8 &&
+			driver.TotalTrips == 12 &&
+			!driver.FetchedAt.IsZero()
+	}))
 
 	idempotencyService := NewIdempotencyService(mockIdempotency)
 	service := NewTripService(mockRepo, idempotencyService, mockUsersClient, mockPublisher)
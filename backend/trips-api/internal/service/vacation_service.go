@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"trips-api/internal/domain"
+	"trips-api/internal/messaging"
+	"trips-api/internal/repository"
+
+	"github.com/rs/zerolog/log"
+)
+
+// vacationStartGrace tolera el desfasaje de reloj de un start_date "ahora" enviado por el cliente
+const vacationStartGrace = time.Minute
+
+// vacationDepartedReason es el motivo de trip.cancelled para los viajes que salían durante la vacación
+const vacationDepartedReason = "Departure passed while the trip was paused by a driver vacation"
+
+// VacationService define las operaciones del modo vacaciones del conductor
+type VacationService interface {
+	// CreateVacation registra una vacación y pausa los viajes publicados dentro de la ventana
+	CreateVacation(ctx context.Context, driverID int64, request domain.CreateVacationRequest) (*domain.DriverVacation, error)
+
+	// ResumeEndedVacations reanuda los viajes de las vacaciones cuya ventana ya terminó
+	// Retorna la cantidad de vacaciones cerradas
+	ResumeEndedVacations(ctx context.Context) (int, error)
+
+	// StartResumeWorker ejecuta ResumeEndedVacations periódicamente hasta que ctx se cancele
+	StartResumeWorker(ctx context.Context, interval time.Duration)
+}
+
+type vacationService struct {
+	vacationRepo repository.VacationRepository
+	tripRepo     repository.TripRepository
+	publisher    messaging.Publisher
+}
+
+// NewVacationService crea una nueva instancia del servicio de vacaciones
+func NewVacationService(
+	vacationRepo repository.VacationRepository,
+	tripRepo repository.TripRepository,
+	publisher messaging.Publisher,
+) VacationService {
+	return &vacationService{
+		vacationRepo: vacationRepo,
+		tripRepo:     tripRepo,
+		publisher:    publisher,
+	}
+}
+
+// CreateVacation implementa la activación del modo vacaciones
+//
+// Validaciones:
+// - start_date y end_date en formato RFC3339
+// - start_date no puede estar en el pasado (los viajes que ya salieron no se pausan)
+// - end_date debe ser posterior a start_date y estar en el futuro
+// - No puede superponerse con otra vacación activa del mismo conductor
+//
+// Acciones:
+// - Pausa (status "paused") todos los viajes "published" con salida dentro de la ventana
+// - Publica trip.updated por cada viaje pausado para que search-api lo oculte
+func (s *vacationService) CreateVacation(ctx context.Context, driverID int64, request domain.CreateVacationRequest) (*domain.DriverVacation, error) {
+	// Validación 1: Parsear fechas
+	startDate, err := time.Parse(time.RFC3339, request.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start_date format: %w", err)
+	}
+
+	endDate, err := time.Parse(time.RFC3339, request.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end_date format: %w", err)
+	}
+
+	// Validación 2: El rango debe ser válido, empezar ahora o después y terminar en el futuro
+	now := time.Now()
+	if startDate.Before(now.Add(-vacationStartGrace)) {
+		return nil, domain.ErrVacationStartInPast
+	}
+	if !endDate.After(startDate) || endDate.Before(now) {
+		return nil, domain.ErrInvalidVacationRange
+	}
+
+	// Validación 3: No superponer vacaciones del mismo conductor
+	existing, err := s.vacationRepo.FindOverlapping(ctx, driverID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check vacation overlap: %w", err)
+	}
+	if existing != nil {
+		return nil, domain.ErrVacationOverlap
+	}
+
+	// Registrar la vacación ANTES de pausar: desde este momento CreateTrip
+	// rechaza viajes nuevos dentro de la ventana
+	vacation := &domain.DriverVacation{
+		DriverID:  driverID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Status:    domain.VacationStatusActive,
+	}
+
+	if err := s.vacationRepo.Create(ctx, vacation); err != nil {
+		log.Error().Err(err).Int64("driver_id", driverID).Msg("Failed to create vacation")
+		return nil, fmt.Errorf("failed to create vacation: %w", err)
+	}
+
+	// Pausar los viajes publicados dentro de la ventana
+	trips, err := s.tripRepo.FindByDriverInWindow(ctx, driverID, domain.TripStatusPublished, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find trips to pause: %w", err)
+	}
+
+	pausedTripIDs := s.transitionTrips(ctx, trips, domain.TripStatusPublished, domain.TripStatusPaused)
+
+	if err := s.vacationRepo.SetPausedTrips(ctx, vacation.ID, pausedTripIDs); err != nil {
+		// No fallar: los viajes ya están pausados y se reanudan por ventana, no por esta lista
+		log.Error().Err(err).Str("vacation_id", vacation.ID.Hex()).Msg("Failed to record paused trips")
+	}
+	vacation.PausedTripIDs = pausedTripIDs
+
+	log.Info().
+		Str("vacation_id", vacation.ID.Hex()).
+		Int64("driver_id", driverID).
+		Time("start_date", startDate).
+		Time("end_date", endDate).
+		Int("paused_trips", len(pausedTripIDs)).
+		Msg("Driver vacation created")
+
+	return vacation, nil
+}
+
+// ResumeEndedVacations busca vacaciones activas ya terminadas y vuelve a publicar sus viajes
+//
+// Los viajes a reanudar se buscan por ventana (driver_id + status "paused" + fecha de salida)
+// en lugar de usar paused_trip_ids, así un viaje pausado nunca queda huérfano aunque
+// SetPausedTrips haya fallado.
+//
+// Un viaje cuya salida ya pasó no se vuelve a publicar: no se realizó (el conductor estaba de vacaciones),
+// así que se cancela y se publica trip.cancelled para que bookings-api cancele sus reservas.
+func (s *vacationService) ResumeEndedVacations(ctx context.Context) (int, error) {
+	now := time.Now()
+	vacations, err := s.vacationRepo.FindEndedActive(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+	for _, vacation := range vacations {
+		trips, err := s.tripRepo.FindByDriverInWindow(ctx, vacation.DriverID, domain.TripStatusPaused, vacation.StartDate, vacation.EndDate)
+		if err != nil {
+			log.Error().Err(err).Str("vacation_id", vacation.ID.Hex()).Msg("Failed to find paused trips")
+			continue // Reintentar en el próximo ciclo
+		}
+
+		var upcoming, departed []domain.Trip
+		for _, trip := range trips {
+			if trip.DepartureDatetime.After(now) {
+				upcoming = append(upcoming, trip)
+			} else {
+				departed = append(departed, trip)
+			}
+		}
+
+		resumed := s.transitionTrips(ctx, upcoming, domain.TripStatusPaused, domain.TripStatusPublished)
+		cancelled := s.cancelDepartedTrips(ctx, departed, vacation.DriverID)
+
+		if err := s.vacationRepo.MarkFinished(ctx, vacation.ID, time.Now()); err != nil {
+			log.Error().Err(err).Str("vacation_id", vacation.ID.Hex()).Msg("Failed to mark vacation as finished")
+			continue
+		}
+		finished++
+
+		log.Info().
+			Str("vacation_id", vacation.ID.Hex()).
+			Int64("driver_id", vacation.DriverID).
+			Int("resumed_trips", len(resumed)).
+			Int("cancelled_trips", len(cancelled)).
+			Msg("Driver vacation finished - trips resumed")
+	}
+
+	return finished, nil
+}
+
+// StartResumeWorker revisa periódicamente las vacaciones terminadas
+// Bloquea hasta que ctx se cancele, debe ejecutarse en una goroutine
+func (s *vacationService) StartResumeWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Ejecutar inmediatamente al iniciar para cubrir vacaciones que terminaron con el servicio apagado
+		if _, err := s.ResumeEndedVacations(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to resume ended vacations")
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Vacation resume worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelDepartedTrips cancela los viajes pausados cuya salida ya pasó y publica trip.cancelled por cada uno
+// Retorna los IDs de los viajes cancelados
+func (s *vacationService) cancelDepartedTrips(ctx context.Context, trips []domain.Trip, driverID int64) []string {
+	cancelled := make([]string, 0, len(trips))
+
+	for i := range trips {
+		trip := &trips[i]
+		tripID := trip.ID.Hex()
+
+		// Condicional: si el conductor lo reactivó o canceló mientras tanto, se omite
+		if err := s.tripRepo.CancelFromStatus(ctx, tripID, domain.TripStatusPaused, driverID, vacationDepartedReason); err != nil {
+			log.Warn().
+				Err(err).
+				Str("trip_id", tripID).
+				Msg("Skipping cancellation of departed paused trip")
+			continue
+		}
+
+		cancelled = append(cancelled, tripID)
+
+		// Se relee el viaje: el evento lleva la secuencia de esta escritura
+		updated, err := s.tripRepo.FindByID(ctx, tripID)
+		if err != nil {
+			log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to fetch cancelled trip")
+			continue
+		}
+		s.publisher.PublishTripCancelled(ctx, updated, driverID, vacationDepartedReason)
+	}
+
+	return cancelled
+}
+
+// transitionTrips cambia el estado de cada viaje y publica trip.updated por cada cambio exitoso
+// Retorna los IDs de los viajes que efectivamente cambiaron de estado
+func (s *vacationService) transitionTrips(ctx context.Context, trips []domain.Trip, fromStatus, toStatus string) []string {
+	changed := make([]string, 0, len(trips))
+
+	for i := range trips {
+		trip := &trips[i]
+		tripID := trip.ID.Hex()
+
+		// Transición condicional: si el viaje cambió de estado mientras tanto, se omite
+		if err := s.tripRepo.TransitionStatus(ctx, tripID, fromStatus, toStatus); err != nil {
+			log.Warn().
+				Err(err).
+				Str("trip_id", tripID).
+				Str("from_status", fromStatus).
+				Str("to_status", toStatus).
+				Msg("Skipping trip status transition")
+			continue
+		}
+
+		changed = append(changed, tripID)
+
+		// Publicar evento trip.updated (fire-and-forget) con el viaje releído: lleva la secuencia de esta escritura
+		updated, err := s.tripRepo.FindByID(ctx, tripID)
+		if err != nil {
+			log.Error().Err(err).Str("trip_id", tripID).Msg("Failed to fetch trip after status transition")
+			continue
+		}
+		s.publisher.PublishTripUpdated(ctx, updated)
+	}
+
+	return changed
+}
@@ -0,0 +1,285 @@
+// Package tripimport lee las importaciones masivas de viajes (POST /trips/bulk): un array JSON
+// de CreateTripRequest o un CSV con una columna por campo
+//
+// Los errores de una fila (campo faltante, número inválido) quedan en la fila y no cortan la
+// lectura; solo un archivo ilegible, vacío o con más de domain.MaxTripImportRows viajes
+// rechaza la importación completa
+package tripimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+
+	"trips-api/internal/domain"
+)
+
+// Formatos de importación
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Parse lee una importación en el formato indicado
+func Parse(format string, r io.Reader) ([]domain.TripImportRow, error) {
+	switch format {
+	case FormatJSON:
+		return ParseJSON(r)
+	case FormatCSV:
+		return ParseCSV(r)
+	default:
+		return nil, domain.InvalidTripImportError("unsupported import format %q (json or csv)", format)
+	}
+}
+
+// ParseJSON lee un array JSON de viajes con el mismo formato que POST /trips
+// Cada elemento se decodifica por separado, así un viaje con un tipo inválido no invalida al resto
+func ParseJSON(r io.Reader) ([]domain.TripImportRow, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, domain.InvalidTripImportError("body must be a JSON array of trips: %v", err)
+	}
+	if err := checkRowCount(len(items)); err != nil {
+		return nil, err
+	}
+
+	rows := make([]domain.TripImportRow, len(items))
+	for i, item := range items {
+		rows[i].Row = i + 1
+		if err := json.Unmarshal(item, &rows[i].Request); err != nil {
+			rows[i].Err = fmt.Errorf("datos inválidos: %w", err)
+			continue
+		}
+		rows[i].Err = validate(&rows[i].Request)
+	}
+	return rows, nil
+}
+
+// ParseCSV lee un CSV con encabezado; las columnas pueden venir en cualquier orden (ver columns)
+// Acepta coma o punto y coma como separador (Excel en español exporta con punto y coma)
+// Los puntos de encuentro no tienen columnas: los viajes que los necesitan se importan en JSON
+func ParseCSV(r io.Reader) ([]domain.TripImportRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, domain.InvalidTripImportError("failed to read CSV: %v", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // BOM de UTF-8
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1 // Una fila con otra cantidad de columnas es un error de esa fila
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, domain.InvalidTripImportError("CSV is empty")
+	}
+	if err != nil {
+		return nil, domain.InvalidTripImportError("invalid CSV header: %v", err)
+	}
+	index, err := headerIndex(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []domain.TripImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, domain.InvalidTripImportError("invalid CSV: %v", err)
+		}
+		if len(rows) == domain.MaxTripImportRows {
+			return nil, checkRowCount(domain.MaxTripImportRows + 1)
+		}
+
+		row := domain.TripImportRow{Row: len(rows) + 1}
+		if len(record) != len(header) {
+			row.Err = fmt.Errorf("la fila tiene %d columnas y el encabezado %d", len(record), len(header))
+		} else {
+			row.Err = fillRequest(&row.Request, record, index)
+			if row.Err == nil {
+				row.Err = validate(&row.Request)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if err := checkRowCount(len(rows)); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// checkRowCount rechaza importaciones vacías o con más de MaxTripImportRows viajes
+func checkRowCount(n int) error {
+	if n == 0 {
+		return domain.InvalidTripImportError("import has no trips")
+	}
+	if n > domain.MaxTripImportRows {
+		return domain.InvalidTripImportError("import has more than %d trips, split it in several uploads", domain.MaxTripImportRows)
+	}
+	return nil
+}
+
+// validate aplica las mismas reglas de binding que POST /trips (campos requeridos, asientos 1-8, etc.)
+func validate(request *domain.CreateTripRequest) error {
+	if err := binding.Validator.ValidateStruct(request); err != nil {
+		return fmt.Errorf("datos inválidos: %w", err)
+	}
+	return nil
+}
+
+// detectDelimiter elige punto y coma si el encabezado lo usa y no tiene comas
+func detectDelimiter(data []byte) rune {
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+	if bytes.IndexByte(firstLine, ';') >= 0 && bytes.IndexByte(firstLine, ',') < 0 {
+		return ';'
+	}
+	return ','
+}
+
+// column es una columna del CSV: set asigna el valor (ya sin espacios) a la solicitud
+type column struct {
+	required bool
+	set      func(request *domain.CreateTripRequest, value string) error
+}
+
+// columns son las columnas aceptadas en el CSV; las requeridas son los campos requeridos de POST /trips
+var columns = map[string]column{
+	"origin_city_id":  {set: func(r *domain.CreateTripRequest, v string) error { r.Origin.CityID = v; return nil }},
+	"origin_city":     {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Origin.City = v; return nil }},
+	"origin_province": {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Origin.Province = v; return nil }},
+	"origin_country":  {set: func(r *domain.CreateTripRequest, v string) error { r.Origin.Country = v; return nil }},
+	"origin_address":  {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Origin.Address = v; return nil }},
+	"origin_lat":      {required: true, set: floatColumn(func(r *domain.CreateTripRequest) *float64 { return &r.Origin.Coordinates.Lat })},
+	"origin_lng":      {required: true, set: floatColumn(func(r *domain.CreateTripRequest) *float64 { return &r.Origin.Coordinates.Lng })},
+
+	"destination_city_id":  {set: func(r *domain.CreateTripRequest, v string) error { r.Destination.CityID = v; return nil }},
+	"destination_city":     {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Destination.City = v; return nil }},
+	"destination_province": {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Destination.Province = v; return nil }},
+	"destination_country":  {set: func(r *domain.CreateTripRequest, v string) error { r.Destination.Country = v; return nil }},
+	"destination_address":  {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Destination.Address = v; return nil }},
+	"destination_lat":      {required: true, set: floatColumn(func(r *domain.CreateTripRequest) *float64 { return &r.Destination.Coordinates.Lat })},
+	"destination_lng":      {required: true, set: floatColumn(func(r *domain.CreateTripRequest) *float64 { return &r.Destination.Coordinates.Lng })},
+
+	"departure_datetime":         {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.DepartureDatetime = v; return nil }},
+	"estimated_arrival_datetime": {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.EstimatedArrivalDatetime = v; return nil }},
+	"price_per_seat":             {required: true, set: floatColumn(func(r *domain.CreateTripRequest) *float64 { return &r.PricePerSeat })},
+	"currency":                   {set: func(r *domain.CreateTripRequest, v string) error { r.Currency = v; return nil }},
+	"total_seats":                {required: true, set: intColumn(func(r *domain.CreateTripRequest) *int { return &r.TotalSeats })},
+
+	"car_brand": {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Car.Brand = v; return nil }},
+	"car_model": {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Car.Model = v; return nil }},
+	"car_year":  {required: true, set: intColumn(func(r *domain.CreateTripRequest) *int { return &r.Car.Year })},
+	"car_color": {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Car.Color = v; return nil }},
+	"car_plate": {required: true, set: func(r *domain.CreateTripRequest, v string) error { r.Car.Plate = v; return nil }},
+
+	"pets_allowed":    {set: boolColumn(func(r *domain.CreateTripRequest) *bool { return &r.Preferences.PetsAllowed })},
+	"smoking_allowed": {set: boolColumn(func(r *domain.CreateTripRequest) *bool { return &r.Preferences.SmokingAllowed })},
+	"music_allowed":   {set: boolColumn(func(r *domain.CreateTripRequest) *bool { return &r.Preferences.MusicAllowed })},
+
+	"description":        {set: func(r *domain.CreateTripRequest, v string) error { r.Description = v; return nil }},
+	"description_format": {set: func(r *domain.CreateTripRequest, v string) error { r.DescriptionFormat = v; return nil }},
+	"booking_close_minutes": {set: func(r *domain.CreateTripRequest, v string) error {
+		minutes, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("no es un número entero: %q", v)
+		}
+		r.BookingCloseMinutes = &minutes
+		return nil
+	}},
+}
+
+// headerIndex mapea cada columna del encabezado a su posición
+// Una columna desconocida o repetida (típicamente un error de tipeo) y una requerida que falta invalidan el archivo
+func headerIndex(header []string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; !ok {
+			return nil, domain.InvalidTripImportError("unknown CSV column %q", name)
+		}
+		if _, repeated := index[name]; repeated {
+			return nil, domain.InvalidTripImportError("CSV column %q appears more than once", name)
+		}
+		index[name] = i
+	}
+
+	var missing []string
+	for name, col := range columns {
+		if _, ok := index[name]; col.required && !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, domain.InvalidTripImportError("missing required CSV columns: %s", strings.Join(missing, ", "))
+	}
+	return index, nil
+}
+
+// fillRequest arma la solicitud de una fila; las celdas vacías dejan el campo sin valor
+// (las requeridas las rechaza después validate, igual que un campo ausente en JSON)
+func fillRequest(request *domain.CreateTripRequest, record []string, index map[string]int) error {
+	for name, i := range index {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		if err := columns[name].set(request, value); err != nil {
+			return fmt.Errorf("columna %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func floatColumn(field func(*domain.CreateTripRequest) *float64) func(*domain.CreateTripRequest, string) error {
+	return func(r *domain.CreateTripRequest, v string) error {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("no es un número: %q", v)
+		}
+		*field(r) = n
+		return nil
+	}
+}
+
+func intColumn(field func(*domain.CreateTripRequest) *int) func(*domain.CreateTripRequest, string) error {
+	return func(r *domain.CreateTripRequest, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("no es un número entero: %q", v)
+		}
+		*field(r) = n
+		return nil
+	}
+}
+
+// boolColumn acepta true/false, 1/0 y si/no
+func boolColumn(field func(*domain.CreateTripRequest) *bool) func(*domain.CreateTripRequest, string) error {
+	return func(r *domain.CreateTripRequest, v string) error {
+		switch strings.ToLower(v) {
+		case "true", "1", "si", "sí", "yes":
+			*field(r) = true
+		case "false", "0", "no":
+			*field(r) = false
+		default:
+			return fmt.Errorf("no es un booleano (true/false, si/no): %q", v)
+		}
+		return nil
+	}
+}
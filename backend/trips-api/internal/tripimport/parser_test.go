@@ -0,0 +1,133 @@
+package tripimport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"trips-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const csvHeader = "origin_city,origin_province,origin_address,origin_lat,origin_lng," +
+	"destination_city,destination_province,destination_address,destination_lat,destination_lng," +
+	"departure_datetime,estimated_arrival_datetime,price_per_seat,total_seats," +
+	"car_brand,car_model,car_year,car_color,car_plate,pets_allowed,booking_close_minutes"
+
+const csvRow = "Córdoba,Córdoba,Av. Colón 100,-31.4,-64.18," +
+	"Rosario,Santa Fe,Bv. Oroño 50,-32.95,-60.65," +
+	"2030-01-10T08:00:00Z,2030-01-10T13:00:00Z,5000,3," +
+	"Toyota,Etios,2020,Blanco,AB123CD,si,30"
+
+func assertImportError(t *testing.T, err error) {
+	t.Helper()
+	var appErr *domain.AppError
+	require.True(t, errors.As(err, &appErr), "expected AppError, got %v", err)
+	assert.Equal(t, domain.ErrInvalidTripImport.Code, appErr.Code)
+}
+
+func TestParseCSV(t *testing.T) {
+	input := csvHeader + "\n" +
+		csvRow + "\n" +
+		strings.Replace(csvRow, ",5000,", ",cinco mil,", 1) + "\n" +
+		strings.Replace(csvRow, ",3,Toyota", ",9,Toyota", 1) + "\n" +
+		"Córdoba,Córdoba\n"
+
+	rows, err := ParseCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	ok := rows[0]
+	require.NoError(t, ok.Err)
+	assert.Equal(t, 1, ok.Row)
+	assert.Equal(t, "Córdoba", ok.Request.Origin.City)
+	assert.Equal(t, -60.65, ok.Request.Destination.Coordinates.Lng)
+	assert.Equal(t, 5000.0, ok.Request.PricePerSeat)
+	assert.Equal(t, 3, ok.Request.TotalSeats)
+	assert.Equal(t, 2020, ok.Request.Car.Year)
+	assert.True(t, ok.Request.Preferences.PetsAllowed)
+	require.NotNil(t, ok.Request.BookingCloseMinutes)
+	assert.Equal(t, 30, *ok.Request.BookingCloseMinutes)
+
+	assert.ErrorContains(t, rows[1].Err, "price_per_seat")
+	assert.ErrorContains(t, rows[2].Err, "TotalSeats", "binding rules of POST /trips")
+	assert.ErrorContains(t, rows[3].Err, "columnas")
+	assert.Equal(t, 4, rows[3].Row)
+}
+
+func TestParseCSV_SemicolonAndBOM(t *testing.T) {
+	input := "\xef\xbb\xbf" + strings.ReplaceAll(csvHeader, ",", ";") + "\r\n" +
+		strings.ReplaceAll(csvRow, ",", ";") + "\r\n"
+
+	rows, err := ParseCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.NoError(t, rows[0].Err)
+	assert.Equal(t, "Rosario", rows[0].Request.Destination.City)
+}
+
+func TestParseCSV_InvalidFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"header only", csvHeader + "\n"},
+		{"unknown column", csvHeader + ",seats\n" + csvRow + ",3\n"},
+		{"missing required column", strings.Replace(csvHeader, ",car_plate", "", 1) + "\n"},
+		{"repeated column", csvHeader + ",currency,currency\n"},
+		{"too many rows", csvHeader + "\n" + strings.Repeat(csvRow+"\n", domain.MaxTripImportRows+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.input))
+			assertImportError(t, err)
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	valid := `{
+		"origin": {"city": "Córdoba", "province": "Córdoba", "address": "Av. Colón 100", "coordinates": {"lat": -31.4, "lng": -64.18}},
+		"destination": {"city": "Rosario", "province": "Santa Fe", "address": "Bv. Oroño 50", "coordinates": {"lat": -32.95, "lng": -60.65}},
+		"departure_datetime": "2030-01-10T08:00:00Z",
+		"estimated_arrival_datetime": "2030-01-10T13:00:00Z",
+		"price_per_seat": 5000,
+		"total_seats": 3,
+		"car": {"brand": "Toyota", "model": "Etios", "year": 2020, "color": "Blanco", "plate": "AB123CD"}
+	}`
+	input := fmt.Sprintf(`[%s, {"total_seats": "three"}, {"price_per_seat": 10}]`, valid)
+
+	rows, err := ParseJSON(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	require.NoError(t, rows[0].Err)
+	assert.Equal(t, "Rosario", rows[0].Request.Destination.City)
+	assert.Error(t, rows[1].Err, "wrong type")
+	assert.Error(t, rows[2].Err, "missing required fields")
+	assert.Equal(t, 3, rows[2].Row)
+}
+
+func TestParseJSON_InvalidBody(t *testing.T) {
+	for name, input := range map[string]string{
+		"object":   `{"trips": []}`,
+		"empty":    `[]`,
+		"not json": `origin,destination`,
+		"too many": "[" + strings.TrimSuffix(strings.Repeat("{},", domain.MaxTripImportRows+1), ",") + "]",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseJSON(strings.NewReader(input))
+			assertImportError(t, err)
+		})
+	}
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	_, err := Parse("xlsx", strings.NewReader(""))
+	assertImportError(t, err)
+}
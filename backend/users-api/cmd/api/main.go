@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
+	"time"
 	"users-api/internal/config"
 	"users-api/internal/controller"
 	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/messaging"
+	"users-api/internal/metrics"
 	"users-api/internal/repository"
 	"users-api/internal/routes"
 	"users-api/internal/service"
+	"users-api/internal/tracing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -21,6 +29,14 @@ func main() {
 		log.Fatalf("Error cargando configuración: %v", err)
 	}
 
+	// OpenTelemetry: spans de HTTP y RabbitMQ (exporta por OTLP si OTEL_EXPORTER_OTLP_ENDPOINT está definido)
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Fatalf("Error inicializando OpenTelemetry: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+	log.Printf("Tracing inicializado (exportando spans: %t)", tracing.Enabled())
+
 	// 2. Conectar a MySQL usando GORM
 	db, err := gorm.Open(mysql.Open(cfg.DatabaseURL), &gorm.Config{})
 	if err != nil {
@@ -29,8 +45,23 @@ func main() {
 
 	log.Println("Conexión a la base de datos establecida")
 
+	// Métricas de la base de datos en GET /metrics: latencia por sentencia y estado del pool de conexiones
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		log.Fatalf("Error registrando el plugin de métricas: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Error accediendo al pool de conexiones: %v", err)
+	}
+	prometheus.MustRegister(collectors.NewDBStatsCollector(sqlDB, "users"))
+
 	// 3. Auto-migrar los modelos (crear tablas si no existen)
-	err = db.AutoMigrate(&dao.UserDAO{}, &dao.RatingDAO{})
+	err = db.AutoMigrate(&dao.UserDAO{}, &dao.RatingDAO{}, &dao.RatingEditDAO{}, &dao.NotificationDAO{},
+		&dao.PartnerDAO{}, &dao.OrganizationDAO{}, &dao.OrganizationMemberDAO{},
+		&dao.GuardianApprovalDAO{}, &dao.GuardianAuditLogDAO{}, &dao.VerificationTokenDAO{}, &dao.PasswordResetTokenDAO{}, &dao.UserPermissionOverrideDAO{},
+		&dao.ContactShareTokenDAO{}, &dao.PartnerAuthorizationDAO{}, &dao.PartnerWebhookDAO{}, &dao.WebhookDeliveryDAO{},
+		&dao.CreditGrantDAO{}, &dao.CreditLedgerEntryDAO{}, &dao.CreditConsumptionDAO{},
+		&dao.UserPreferencesDAO{}, &dao.JobDAO{})
 	if err != nil {
 		log.Fatalf("Error en auto-migración: %v", err)
 	}
@@ -40,25 +71,122 @@ func main() {
 	// 4. Inicializar repositorios
 	userRepo := repository.NewUserRepository(db)
 	ratingRepo := repository.NewRatingRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	provisioningRepo := repository.NewProvisioningRepository(db)
+	guardianRepo := repository.NewGuardianRepository(db)
+	verificationTokenRepo := repository.NewVerificationTokenRepository(db)
+	passwordResetTokenRepo := repository.NewPasswordResetTokenRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	contactShareRepo := repository.NewContactShareRepository(db)
+	partnerWebhookRepo := repository.NewPartnerWebhookRepository(db)
+	creditRepo := repository.NewCreditRepository(db)
+	preferencesRepo := repository.NewPreferencesRepository(db)
+	jobRepo := repository.NewJobRepository(db)
 
-	// 5. Inicializar servicios
+	// 5. Inicializar publisher de eventos de usuario (opcional: sin RabbitMQ no se publican)
+	var lifecyclePublisher service.LifecycleEventPublisher
+	var ratingPublisher service.RatingEventPublisher
+	var guardianPublisher service.GuardianEventPublisher
+	var creditPublisher service.CreditEventPublisher
+	if cfg.RabbitMQURL != "" {
+		publisher, err := messaging.NewLifecyclePublisher(cfg.RabbitMQURL)
+		if err != nil {
+			log.Printf("No se pudo iniciar el publisher de eventos de usuario: %v", err)
+		} else {
+			defer publisher.Close()
+			lifecyclePublisher = publisher
+			ratingPublisher = publisher
+			guardianPublisher = publisher
+			creditPublisher = publisher
+		}
+	}
+
+	// 6. Inicializar servicios
 	emailService := service.NewEmailService(cfg)
-	authService := service.NewAuthService(userRepo, emailService, cfg.JWTSecret)
-	userService := service.NewUserService(userRepo, emailService)
-	ratingService := service.NewRatingService(ratingRepo, userRepo)
+	lifecycleService := service.NewLifecycleService(userRepo, lifecyclePublisher, cfg.InactivityEventsPerRun)
+	permissionService := service.NewPermissionService(permissionRepo, userRepo)
+	var oauthProviders []service.OAuthProvider
+	if cfg.GoogleClientID != "" && cfg.GoogleClientSecret != "" {
+		oauthProviders = append(oauthProviders, service.NewGoogleOAuthProvider(cfg.GoogleClientID, cfg.GoogleClientSecret, cfg.GoogleRedirectURL, 10*time.Second))
+	} else {
+		log.Println("GOOGLE_CLIENT_ID/GOOGLE_CLIENT_SECRET no configurados, login con Google deshabilitado")
+	}
+	authService := service.NewAuthService(userRepo, verificationTokenRepo, passwordResetTokenRepo, permissionService, emailService, lifecycleService, cfg.JWTSecret, oauthProviders...)
+	partnerWebhookService := service.NewPartnerWebhookService(partnerWebhookRepo, provisioningRepo, time.Duration(cfg.WebhookTimeoutSeconds)*time.Second, cfg.WebhookMaxAttempts, cfg.WebhookAllowPrivateNetworks)
+	userService := service.NewUserService(userRepo, verificationTokenRepo, emailService, partnerWebhookService)
+	ratingService := service.NewRatingService(ratingRepo, userRepo, ratingPublisher)
+	notificationService := service.NewNotificationService(notificationRepo, userRepo, preferencesRepo)
+	securityService := service.NewSecurityService(userRepo)
+	partnerService := service.NewPartnerService(provisioningRepo)
+	scimService := service.NewSCIMService(userRepo, provisioningRepo, passwordResetTokenRepo, emailService, partnerWebhookService)
+	guardianService := service.NewGuardianService(guardianRepo, userRepo, verificationTokenRepo, emailService, notificationService, guardianPublisher)
+	contactShareService := service.NewContactShareService(contactShareRepo, userRepo, time.Duration(cfg.ContactShareTTLHours)*time.Hour)
+	preferencesService := service.NewPreferencesService(preferencesRepo, userRepo)
+	creditService := service.NewCreditService(creditRepo, userRepo, notificationService, creditPublisher, cfg.CreditExpiryBatchSize)
+	jobService := service.NewJobService(jobRepo, cfg.JobWorkers, time.Duration(cfg.JobPollIntervalSeconds)*time.Second, time.Duration(cfg.JobLeaseSeconds)*time.Second, cfg.JobMaxAttempts)
 
-	// 6. Inicializar controladores
+	// 6.1 Iniciar consumer de notificaciones (opcional: la API funciona sin RabbitMQ)
+	if cfg.RabbitMQURL != "" {
+		consumer, err := messaging.NewNotificationConsumer(cfg.RabbitMQURL, notificationService, contactShareService)
+		if err != nil {
+			log.Printf("No se pudo iniciar el consumer de notificaciones: %v", err)
+		} else {
+			defer consumer.Close()
+			go func() {
+				if err := consumer.Start(context.Background()); err != nil {
+					log.Printf("Consumer de notificaciones detenido: %v", err)
+				}
+			}()
+		}
+	} else {
+		log.Println("RABBITMQ_URL no configurada, notificaciones desde eventos deshabilitadas")
+	}
+
+	// 6.2 Iniciar job de usuarios inactivos (campaña de regreso)
+	if lifecyclePublisher != nil {
+		go lifecycleService.StartInactivityJob(context.Background(), time.Duration(cfg.InactivityJobIntervalMinutes)*time.Minute)
+	}
+
+	// 6.3 Iniciar dispatcher de webhooks de partners (entregas y reintentos)
+	go partnerWebhookService.StartDispatcher(context.Background(), time.Duration(cfg.WebhookDispatchIntervalSeconds)*time.Second)
+
+	// 6.4 Iniciar job de vencimiento de créditos (avisos credit.expiring y vencimiento de saldos)
+	go creditService.StartExpiryJob(context.Background(), time.Duration(cfg.CreditExpiryJobIntervalMinutes)*time.Minute)
+
+	// 6.5 Iniciar la cola de jobs en segundo plano (workers y programaciones)
+	// Los subsistemas registran sus handlers y programaciones antes de Start
+	if err := jobService.Schedule("jobs-cleanup", "0 3 * * *", domain.JobTypeCleanup, domain.JobCleanupPayload{RetentionDays: cfg.JobRetentionDays}); err != nil {
+		log.Fatalf("Error programando la limpieza de jobs: %v", err)
+	}
+	go jobService.Start(context.Background())
+
+	// 7. Inicializar controladores
 	authController := controller.NewAuthController(authService)
 	userController := controller.NewUserController(userService)
 	ratingController := controller.NewRatingController(ratingService)
+	notificationController := controller.NewNotificationController(notificationService)
+	securityController := controller.NewSecurityController(securityService)
+	preferencesController := controller.NewPreferencesController(lifecycleService, preferencesService)
+	scimController := controller.NewSCIMController(scimService)
+	partnerController := controller.NewPartnerController(partnerService)
+	guardianController := controller.NewGuardianController(guardianService)
+	permissionController := controller.NewPermissionController(permissionService)
+	contactShareController := controller.NewContactShareController(contactShareService)
+	partnerWebhookController := controller.NewPartnerWebhookController(partnerWebhookService)
+	creditController := controller.NewCreditController(creditService)
+	jobController := controller.NewJobController(jobService)
 
-	// 7. Crear router Gin
+	// 8. Crear router Gin
 	router := gin.Default()
+	// Sin esto gin confía en cualquier X-Forwarded-For y un cliente elige su propia IP (y su clave de rate limit)
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES inválido: %v", err)
+	}
 
-	// 8. Configurar rutas
-	routes.SetupRoutes(router, authController, userController, ratingController, authService, userRepo)
+	// 9. Configurar rutas
+	routes.SetupRoutes(router, authController, userController, ratingController, notificationController, securityController, preferencesController, scimController, partnerController, guardianController, permissionController, contactShareController, partnerWebhookController, creditController, jobController, authService, partnerService, userRepo, cfg.PublicRateLimitPerMinute, cfg.PasswordResetIPLimitPerHour, cfg.PasswordResetEmailLimitPerHour)
 
-	// 9. Iniciar servidor
+	// 10. Iniciar servidor
 	port := ":" + cfg.ServerPort
 	log.Printf("Servidor iniciado en el puerto %s", port)
 	if err := router.Run(port); err != nil {
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +17,56 @@ type Config struct {
 	SMTPFrom     string
 	SMTPPassword string
 	AppURL       string
+	RabbitMQURL  string
+
+	// Tiempo máximo en segundos de una sesión SMTP completa (conexión + envío)
+	SMTPTimeoutSeconds int
+
+	// Requests por minuto y por IP permitidas en las rutas públicas /public
+	PublicRateLimitPerMinute int
+
+	// Proxies (IPs o CIDRs) cuyo X-Forwarded-For / X-Real-IP se acepta para obtener la IP del cliente
+	// Vacío: no se confía en ningún proxy y la IP es la de la conexión (los rate limiters no se pueden evadir con headers)
+	TrustedProxies []string
+
+	// Restablecimiento de contraseña: requests por hora por IP (/forgot-password y /reset-password)
+	// y por email (/forgot-password)
+	PasswordResetIPLimitPerHour    int
+	PasswordResetEmailLimitPerHour int
+
+	// Job que publica user.inactive_30d: cada cuántos minutos corre y máximo de eventos por ejecución
+	InactivityJobIntervalMinutes int
+	InactivityEventsPerRun       int
+
+	// Vigencia en horas de los tokens de contacto compartido entre conductor y pasajero
+	ContactShareTTLHours int
+
+	// Webhooks de partners: timeout de cada envío, intentos antes de marcar la entrega como fallida
+	// y cada cuántos segundos el dispatcher busca reintentos vencidos
+	WebhookTimeoutSeconds          int
+	WebhookMaxAttempts             int
+	WebhookDispatchIntervalSeconds int
+	// Permite webhooks http y a la red interna (localhost, IPs privadas); solo para desarrollo
+	WebhookAllowPrivateNetworks bool
+
+	// Job de vencimiento de créditos: cada cuántos minutos corre y máximo de créditos avisados/vencidos por ejecución
+	CreditExpiryJobIntervalMinutes int
+	CreditExpiryBatchSize          int
+
+	// Cola de jobs en segundo plano: jobs en paralelo por réplica, cada cuántos segundos se buscan jobs
+	// vencidos, tiempo máximo en segundos de un intento, intentos por defecto antes de marcar un job
+	// como fallido y días que se conservan los jobs terminados con éxito
+	JobWorkers             int
+	JobPollIntervalSeconds int
+	JobLeaseSeconds        int
+	JobMaxAttempts         int
+	JobRetentionDays       int
+
+	// Login con Google (OAuth2): sin client ID y secret el proveedor queda deshabilitado
+	// GoogleRedirectURL es el callback registrado en Google Cloud (GET /auth/google/callback)
+	GoogleClientID     string
+	GoogleClientSecret string
+	GoogleRedirectURL  string
 }
 
 func LoadConfig() (*Config, error) {
@@ -34,6 +86,41 @@ func LoadConfig() (*Config, error) {
 		SMTPHost:   getEnv("SMTP_HOST", "smtp.gmail.com"),
 		SMTPPort:   getEnv("SMTP_PORT", "587"),
 		SMTPFrom:   getEnv("SMTP_FROM", "matiasjbocco@gmail.com"),
+
+		SMTPTimeoutSeconds: getEnvInt("SMTP_TIMEOUT_SECONDS", 30),
+
+		// Opcional: sin RABBITMQ_URL no se generan notificaciones desde eventos
+		RabbitMQURL: getEnv("RABBITMQ_URL", ""),
+
+		PublicRateLimitPerMinute: getEnvInt("PUBLIC_RATE_LIMIT_PER_MINUTE", 60),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
+		PasswordResetIPLimitPerHour:    getEnvInt("PASSWORD_RESET_IP_LIMIT_PER_HOUR", 20),
+		PasswordResetEmailLimitPerHour: getEnvInt("PASSWORD_RESET_EMAIL_LIMIT_PER_HOUR", 5),
+
+		InactivityJobIntervalMinutes: getEnvInt("INACTIVITY_JOB_INTERVAL_MINUTES", 60),
+		InactivityEventsPerRun:       getEnvInt("INACTIVITY_EVENTS_PER_RUN", 200),
+
+		ContactShareTTLHours: getEnvInt("CONTACT_SHARE_TTL_HOURS", 168),
+
+		WebhookTimeoutSeconds:          getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+		WebhookMaxAttempts:             getEnvInt("WEBHOOK_MAX_ATTEMPTS", 10),
+		WebhookDispatchIntervalSeconds: getEnvInt("WEBHOOK_DISPATCH_INTERVAL_SECONDS", 15),
+		WebhookAllowPrivateNetworks:    getEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false") == "true",
+
+		CreditExpiryJobIntervalMinutes: getEnvInt("CREDIT_EXPIRY_JOB_INTERVAL_MINUTES", 60),
+		CreditExpiryBatchSize:          getEnvInt("CREDIT_EXPIRY_BATCH_SIZE", 200),
+
+		JobWorkers:             getEnvInt("JOB_WORKERS", 4),
+		JobPollIntervalSeconds: getEnvInt("JOB_POLL_INTERVAL_SECONDS", 5),
+		JobLeaseSeconds:        getEnvInt("JOB_LEASE_SECONDS", 300),
+		JobMaxAttempts:         getEnvInt("JOB_MAX_ATTEMPTS", 5),
+		JobRetentionDays:       getEnvInt("JOB_RETENTION_DAYS", 14),
+
+		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+		GoogleRedirectURL:  getEnv("GOOGLE_REDIRECT_URL", "http://localhost:8001/auth/google/callback"),
 	}, nil
 }
 
@@ -62,6 +149,25 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvInt obtiene variable numérica con fallback (valores inválidos usan el default)
+// getEnvList lee una lista separada por comas (nil si la variable no está o está vacía)
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 // mustGetEnv obtiene variable REQUERIDA o hace panic (fail-fast)
 func mustGetEnv(key string) string {
 	value := os.Getenv(key)
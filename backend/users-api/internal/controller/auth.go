@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"errors"
+	"net/http"
 	"users-api/internal/domain"
 	"users-api/internal/service"
 
@@ -16,6 +18,8 @@ type AuthController interface {
 	RequestPasswordReset(c *gin.Context)
 	ResetPassword(c *gin.Context)
 	ChangePassword(c *gin.Context)
+	OAuthRedirect(c *gin.Context)
+	OAuthCallback(c *gin.Context)
 }
 
 type authController struct {
@@ -40,6 +44,11 @@ func (ctrl *authController) Register(c *gin.Context) {
 		return
 	}
 
+	// Sin locale en el body, país, moneda y unidad de distancia se infieren del navegador
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
 	user, err := ctrl.authService.Register(req)
 	if err != nil {
 		// Si el email ya existe, retornar 409 Conflict
@@ -92,7 +101,8 @@ func (ctrl *authController) Login(c *gin.Context) {
 }
 
 // VerifyEmail verifica el email del usuario
-// GET /verify-email?token=xxx
+// 400 token inválido, 409 token ya usado, 410 token vencido
+// GET /auth/verify?token=xxx (también GET /verify-email?token=xxx)
 func (ctrl *authController) VerifyEmail(c *gin.Context) {
 	token := c.Query("token")
 	if token == "" {
@@ -104,7 +114,7 @@ func (ctrl *authController) VerifyEmail(c *gin.Context) {
 	}
 
 	if err := ctrl.authService.VerifyEmail(token); err != nil {
-		c.JSON(400, gin.H{
+		c.JSON(verificationErrorStatus(err), gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
@@ -117,7 +127,23 @@ func (ctrl *authController) VerifyEmail(c *gin.Context) {
 	})
 }
 
+// verificationErrorStatus traduce los errores de la verificación de email a códigos HTTP
+// (el resto de los errores siguen respondiendo 400)
+func verificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrVerificationTokenExpired):
+		return 410
+	case errors.Is(err, domain.ErrVerificationTokenUsed):
+		return 409
+	case errors.Is(err, domain.ErrVerificationResendThrottle):
+		return 429
+	default:
+		return 400
+	}
+}
+
 // ResendVerificationEmail reenvía el email de verificación
+// Máximo 3 reenvíos por hora por usuario (429 al superarlo)
 // POST /resend-verification
 func (ctrl *authController) ResendVerificationEmail(c *gin.Context) {
 	var req domain.ResendVerificationRequest
@@ -131,7 +157,7 @@ func (ctrl *authController) ResendVerificationEmail(c *gin.Context) {
 	}
 
 	if err := ctrl.authService.ResendVerificationEmail(req.Email); err != nil {
-		c.JSON(400, gin.H{
+		c.JSON(verificationErrorStatus(err), gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
@@ -145,11 +171,10 @@ func (ctrl *authController) ResendVerificationEmail(c *gin.Context) {
 }
 
 // RequestPasswordReset solicita el restablecimiento de contraseña
+// La respuesta es la misma exista o no el email (también al superar los 3 emails por hora del usuario)
 // POST /forgot-password
 func (ctrl *authController) RequestPasswordReset(c *gin.Context) {
-	var req struct {
-		Email string `json:"email" binding:"required,email"`
-	}
+	var req domain.ForgotPasswordRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(400, gin.H{
@@ -159,7 +184,7 @@ func (ctrl *authController) RequestPasswordReset(c *gin.Context) {
 		return
 	}
 
-	if err := ctrl.authService.RequestPasswordReset(req.Email); err != nil {
+	if err := ctrl.authService.RequestPasswordReset(req.Email, c.ClientIP()); err != nil {
 		c.JSON(500, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -173,7 +198,7 @@ func (ctrl *authController) RequestPasswordReset(c *gin.Context) {
 	})
 }
 
-// ResetPassword restablece la contraseña usando el token
+// ResetPassword restablece la contraseña usando el token y cierra todas las sesiones del usuario
 // POST /reset-password
 func (ctrl *authController) ResetPassword(c *gin.Context) {
 	var req domain.ResetPasswordRequest
@@ -187,7 +212,7 @@ func (ctrl *authController) ResetPassword(c *gin.Context) {
 	}
 
 	if err := ctrl.authService.ResetPassword(req.Token, req.NewPassword); err != nil {
-		c.JSON(400, gin.H{
+		c.JSON(passwordResetErrorStatus(err), gin.H{
 			"success": false,
 			"error":   err.Error(),
 		})
@@ -200,6 +225,19 @@ func (ctrl *authController) ResetPassword(c *gin.Context) {
 	})
 }
 
+// passwordResetErrorStatus traduce los errores del restablecimiento de contraseña a códigos HTTP
+// (el resto de los errores siguen respondiendo 400)
+func passwordResetErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPasswordResetTokenExpired):
+		return 410
+	case errors.Is(err, domain.ErrPasswordResetTokenUsed):
+		return 409
+	default:
+		return 400
+	}
+}
+
 // ChangePassword cambia la contraseña del usuario autenticado
 // POST /change-password (requiere autenticación)
 func (ctrl *authController) ChangePassword(c *gin.Context) {
@@ -236,3 +274,85 @@ func (ctrl *authController) ChangePassword(c *gin.Context) {
 		"data":    gin.H{"message": "contraseña cambiada exitosamente"},
 	})
 }
+
+// oauthStateCookie guarda el state entre la redirección al proveedor y el callback
+const oauthStateCookie = "oauth_state"
+
+// OAuthRedirect inicia el login social: guarda el state en una cookie y redirige al proveedor
+// 404 si el proveedor no existe o no está configurado
+// GET /auth/:provider (por ejemplo /auth/google)
+func (ctrl *authController) OAuthRedirect(c *gin.Context) {
+	provider := c.Param("provider")
+
+	authURL, state, err := ctrl.authService.OAuthStart(provider)
+	if err != nil {
+		c.JSON(oauthErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// SameSite=Lax: la cookie viaja en la redirección de vuelta desde el proveedor (GET de nivel superior)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, int(domain.OAuthStateTTL.Seconds()), "/auth/"+provider, "", isSecureRequest(c), true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OAuthCallback recibe al usuario de vuelta del proveedor, canjea el código y responde igual que POST /login
+// 400 state o código inválidos, 401 cuenta desactivada o suspendida, 403 email no verificado por el proveedor,
+// 409 el email ya está vinculado a otra cuenta del proveedor
+// GET /auth/:provider/callback?code=xxx&state=xxx
+func (ctrl *authController) OAuthCallback(c *gin.Context) {
+	provider := c.Param("provider")
+
+	// El state se usa una sola vez
+	expectedState, _ := c.Cookie(oauthStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, "/auth/"+provider, "", isSecureRequest(c), true)
+
+	// El usuario canceló o el proveedor rechazó el login (error=access_denied, etc.)
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "login con " + provider + " cancelado: " + providerErr,
+		})
+		return
+	}
+
+	response, err := ctrl.authService.OAuthLogin(c.Request.Context(), provider, c.Query("code"), c.Query("state"), expectedState)
+	if err != nil {
+		c.JSON(oauthErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// oauthErrorStatus traduce los errores del login social a códigos HTTP
+// (el resto, como cuenta desactivada o suspendida, responde 401 igual que POST /login)
+func oauthErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrOAuthProviderUnknown):
+		return 404
+	case errors.Is(err, domain.ErrOAuthStateInvalid), errors.Is(err, domain.ErrOAuthCodeInvalid):
+		return 400
+	case errors.Is(err, domain.ErrOAuthEmailNotVerified):
+		return 403
+	case errors.Is(err, domain.ErrOAuthAccountLinked):
+		return 409
+	default:
+		return 401
+	}
+}
+
+// isSecureRequest indica si la request llegó por HTTPS (directo o detrás de un proxy)
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
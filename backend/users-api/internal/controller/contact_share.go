@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"errors"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContactShareController define la interfaz del controlador de contacto compartido
+type ContactShareController interface {
+	GetSharedContact(c *gin.Context)
+	ListMyContactShares(c *gin.Context)
+}
+
+type contactShareController struct {
+	contactShareService service.ContactShareService
+}
+
+// NewContactShareController crea una nueva instancia del controlador de contacto compartido
+func NewContactShareController(contactShareService service.ContactShareService) ContactShareController {
+	return &contactShareController{contactShareService: contactShareService}
+}
+
+// GetSharedContact devuelve el nombre y teléfono del otro usuario de la reserva
+// 404 si el token no existe o no es del usuario, 410 si expiró o fue revocado
+// GET /contacts/shared/:token
+func (ctrl *contactShareController) GetSharedContact(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	contact, err := ctrl.contactShareService.GetSharedContact(userID.(int64), c.Param("token"))
+	if err != nil {
+		c.JSON(contactShareErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    contact,
+	})
+}
+
+// ListMyContactShares lista los tokens de contacto vigentes del usuario autenticado
+// GET /users/me/contact-shares?trip_id=
+func (ctrl *contactShareController) ListMyContactShares(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	shares, err := ctrl.contactShareService.ListMyContactShares(userID.(int64), c.Query("trip_id"))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    shares,
+	})
+}
+
+// contactShareErrorStatus traduce los errores de los tokens de contacto a códigos HTTP
+func contactShareErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrContactShareNotFound):
+		return 404
+	case errors.Is(err, domain.ErrContactShareExpired), errors.Is(err, domain.ErrContactShareRevoked):
+		return 410
+	default:
+		return 500
+	}
+}
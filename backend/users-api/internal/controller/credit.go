@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreditController define la interfaz del controlador de créditos promocionales
+type CreditController interface {
+	GetMyCredits(c *gin.Context)
+	GetMyLedger(c *gin.Context)
+
+	// Solo admin
+	GrantCredit(c *gin.Context)
+	GetUserCredits(c *gin.Context)
+
+	// Interna (llamada desde bookings-api)
+	ConsumeCredits(c *gin.Context)
+	ReleaseCredits(c *gin.Context)
+}
+
+type creditController struct {
+	creditService service.CreditService
+}
+
+// NewCreditController crea una nueva instancia del controlador de créditos
+func NewCreditController(creditService service.CreditService) CreditController {
+	return &creditController{creditService: creditService}
+}
+
+// creditErrorStatus traduce los errores del servicio a códigos HTTP (500 para los no esperados)
+func creditErrorStatus(err error) int {
+	switch err.Error() {
+	case "indicar expires_at o valid_days", "indicar expires_at o valid_days, no ambos",
+		"el vencimiento debe estar entre ahora y un año", "monto inválido":
+		return 400
+	case "usuario no encontrado", "la reserva no aplicó créditos":
+		return 404
+	case "la reserva ya aplicó créditos de otro usuario":
+		return 409
+	default:
+		return 500
+	}
+}
+
+// GetMyCredits obtiene el saldo por moneda y los créditos vigentes del usuario autenticado
+// GET /users/me/credits
+func (ctrl *creditController) GetMyCredits(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	summary, err := ctrl.creditService.GetCredits(userID.(int64))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// GetMyLedger obtiene los movimientos de créditos del usuario autenticado
+// GET /users/me/credits/ledger?page=1&limit=20
+func (ctrl *creditController) GetMyLedger(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	entries, total, err := ctrl.creditService.ListLedger(userID.(int64), page, limit)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data": gin.H{
+			"entries": entries,
+			"total":   total,
+			"page":    page,
+			"limit":   limit,
+		},
+	})
+}
+
+// GrantCredit otorga un crédito promocional a un usuario
+// POST /admin/users/:id/credits
+func (ctrl *creditController) GrantCredit(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	var req domain.GrantCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	grant, err := ctrl.creditService.GrantCredit(adminID.(int64), userID, req)
+	if err != nil {
+		c.JSON(creditErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    grant,
+	})
+}
+
+// GetUserCredits obtiene el saldo y los créditos vigentes de cualquier usuario (soporte)
+// GET /admin/users/:id/credits
+func (ctrl *creditController) GetUserCredits(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	summary, err := ctrl.creditService.GetCredits(userID)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// ConsumeCredits aplica el saldo del usuario a la tarifa de una reserva
+// Responde 201 si el consumo es nuevo y 200 si la reserva ya había aplicado créditos (replayed)
+// POST /internal/credits/consume
+func (ctrl *creditController) ConsumeCredits(c *gin.Context) {
+	var req domain.ConsumeCreditsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	consumption, created, err := ctrl.creditService.ConsumeCredits(req)
+	if err != nil {
+		c.JSON(creditErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	status := 200
+	if created {
+		status = 201
+	}
+	c.JSON(status, gin.H{
+		"success": true,
+		"data":    consumption,
+	})
+}
+
+// ReleaseCredits devuelve los créditos aplicados a una reserva cancelada
+// Responde 201 si la devolución es nueva y 200 si ya se había devuelto (replayed)
+// POST /internal/credits/release
+func (ctrl *creditController) ReleaseCredits(c *gin.Context) {
+	var req domain.ReleaseCreditsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	release, created, err := ctrl.creditService.ReleaseCredits(req)
+	if err != nil {
+		c.JSON(creditErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	status := 200
+	if created {
+		status = 201
+	}
+	c.JSON(status, gin.H{
+		"success": true,
+		"data":    release,
+	})
+}
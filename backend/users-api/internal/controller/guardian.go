@@ -0,0 +1,382 @@
+package controller
+
+import (
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GuardianController define la interfaz del controlador de cuentas dependientes (tutores y menores)
+type GuardianController interface {
+	CreateDependent(c *gin.Context)
+	ListDependents(c *gin.Context)
+	UpdateDependent(c *gin.Context)
+	DeactivateDependent(c *gin.Context)
+	ListApprovals(c *gin.Context)
+	DecideApproval(c *gin.Context)
+	GetMyAuditLog(c *gin.Context)
+
+	// Solo admin
+	GetAuditLogs(c *gin.Context)
+
+	// Internas (llamadas desde otros servicios)
+	RequestApproval(c *gin.Context)
+	GetApproval(c *gin.Context)
+}
+
+type guardianController struct {
+	guardianService service.GuardianService
+}
+
+// NewGuardianController crea una nueva instancia del controlador de cuentas dependientes
+func NewGuardianController(guardianService service.GuardianService) GuardianController {
+	return &guardianController{guardianService: guardianService}
+}
+
+// guardianErrorStatus traduce los errores del servicio a códigos HTTP (500 para los no esperados)
+func guardianErrorStatus(err error) int {
+	switch err.Error() {
+	case "formato de fecha inválido, usar YYYY-MM-DD", "el dependiente debe ser menor de edad", "estado inválido",
+		"el usuario no es una cuenta dependiente":
+		return 400
+	case "solo un usuario mayor de edad puede ser tutor":
+		return 403
+	case "usuario no encontrado", "dependiente no encontrado", "solicitud de aprobación no encontrada":
+		return 404
+	case "el email ya está registrado", "la solicitud de aprobación ya fue resuelta", "la solicitud de aprobación expiró":
+		return 409
+	default:
+		return 500
+	}
+}
+
+// CreateDependent crea la cuenta de un menor a cargo del usuario autenticado
+// POST /users/me/dependents
+func (ctrl *guardianController) CreateDependent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	var req domain.CreateDependentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	dependent, err := ctrl.guardianService.CreateDependent(userID.(int64), req)
+	if err != nil {
+		c.JSON(guardianErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    dependent,
+	})
+}
+
+// ListDependents lista las cuentas dependientes del usuario autenticado
+// GET /users/me/dependents
+func (ctrl *guardianController) ListDependents(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	dependents, err := ctrl.guardianService.ListDependents(userID.(int64))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    dependents,
+	})
+}
+
+// UpdateDependent actualiza el perfil de un dependiente del usuario autenticado
+// PUT /users/me/dependents/:id
+func (ctrl *guardianController) UpdateDependent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	dependentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	var req domain.UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	dependent, err := ctrl.guardianService.UpdateDependent(userID.(int64), dependentID, req)
+	if err != nil {
+		c.JSON(guardianErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    dependent,
+	})
+}
+
+// DeactivateDependent desactiva la cuenta de un dependiente del usuario autenticado
+// DELETE /users/me/dependents/:id
+func (ctrl *guardianController) DeactivateDependent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	dependentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	if err := ctrl.guardianService.DeactivateDependent(userID.(int64), dependentID); err != nil {
+		c.JSON(guardianErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"message": "cuenta dependiente desactivada"},
+	})
+}
+
+// ListApprovals lista las solicitudes de aprobación de los dependientes del usuario autenticado
+// GET /users/me/guardian/approvals?status=pending
+func (ctrl *guardianController) ListApprovals(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	approvals, err := ctrl.guardianService.ListApprovals(userID.(int64), c.Query("status"))
+	if err != nil {
+		c.JSON(guardianErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    approvals,
+	})
+}
+
+// DecideApproval aprueba o rechaza una solicitud de un dependiente (callback de aprobación del tutor)
+// POST /users/me/guardian/approvals/:id/decision
+func (ctrl *guardianController) DecideApproval(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	approvalID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	var req domain.GuardianApprovalDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	approval, err := ctrl.guardianService.DecideApproval(c.Request.Context(), userID.(int64), approvalID, req)
+	if err != nil {
+		c.JSON(guardianErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// GetMyAuditLog lista las acciones del usuario autenticado como tutor
+// GET /users/me/guardian/audit?dependent_id=&page=1&limit=20
+func (ctrl *guardianController) GetMyAuditLog(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	ctrl.listAuditLogs(c, userID.(int64))
+}
+
+// GetAuditLogs lista la auditoría de todos los tutores (solo admin)
+// GET /admin/guardian-audit?guardian_id=&dependent_id=&page=1&limit=20
+func (ctrl *guardianController) GetAuditLogs(c *gin.Context) {
+	guardianID, _ := strconv.ParseInt(c.Query("guardian_id"), 10, 64)
+	ctrl.listAuditLogs(c, guardianID)
+}
+
+// listAuditLogs responde la auditoría paginada del tutor (0: todos), filtrada por dependent_id
+func (ctrl *guardianController) listAuditLogs(c *gin.Context, guardianID int64) {
+	dependentID, _ := strconv.ParseInt(c.Query("dependent_id"), 10, 64)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	logs, total, err := ctrl.guardianService.ListAuditLogs(guardianID, dependentID, page, limit)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data": gin.H{
+			"entries": logs,
+			"total":   total,
+			"page":    page,
+			"limit":   limit,
+		},
+	})
+}
+
+// RequestApproval registra una acción de un dependiente que necesita aprobación del tutor
+// Responde 201 si la solicitud es nueva y 200 si ya existía para (action, resource_id)
+// POST /internal/guardian-approvals
+func (ctrl *guardianController) RequestApproval(c *gin.Context) {
+	var req domain.CreateGuardianApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	approval, created, err := ctrl.guardianService.RequestApproval(req)
+	if err != nil {
+		c.JSON(guardianErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	status := 200
+	if created {
+		status = 201
+	}
+	c.JSON(status, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
+
+// GetApproval obtiene el estado de una solicitud de aprobación
+// GET /internal/guardian-approvals/:id
+func (ctrl *guardianController) GetApproval(c *gin.Context) {
+	approvalID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	approval, err := ctrl.guardianService.GetApproval(approvalID)
+	if err != nil {
+		c.JSON(guardianErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    approval,
+	})
+}
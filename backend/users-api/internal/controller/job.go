@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobController define los endpoints de administración de la cola de jobs
+type JobController interface {
+	ListJobs(c *gin.Context)
+	RequeueJob(c *gin.Context)
+}
+
+type jobController struct {
+	jobService service.JobService
+}
+
+// NewJobController crea una nueva instancia del controlador de jobs
+func NewJobController(jobService service.JobService) JobController {
+	return &jobController{jobService: jobService}
+}
+
+// ListJobs lista los jobs de la cola (admin)
+// GET /admin/jobs?status=failed&type=jobs.cleanup&page=1&limit=20
+func (ctrl *jobController) ListJobs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	response, err := ctrl.jobService.ListJobs(c.Query("status"), c.Query("type"), page, limit)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// RequeueJob vuelve a encolar un job fallido (admin)
+// POST /admin/jobs/:id/requeue
+func (ctrl *jobController) RequeueJob(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	job, err := ctrl.jobService.RequeueJob(id)
+	if err != nil {
+		c.JSON(jobErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(202, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// jobErrorStatus traduce los errores de la cola de jobs a códigos HTTP
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrJobNotFound):
+		return 404
+	case errors.Is(err, domain.ErrJobInvalidStatus):
+		return 400
+	case errors.Is(err, domain.ErrJobNotRequeuable):
+		return 409
+	default:
+		return 500
+	}
+}
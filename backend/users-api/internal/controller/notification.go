@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationController define la interfaz del controlador de notificaciones
+type NotificationController interface {
+	GetMyNotifications(c *gin.Context)
+	GetUnreadCount(c *gin.Context)
+	MarkAsRead(c *gin.Context)
+	MarkAllAsRead(c *gin.Context)
+	DeleteNotification(c *gin.Context)
+	SendSystemNotification(c *gin.Context)
+}
+
+type notificationController struct {
+	notificationService service.NotificationService
+}
+
+// NewNotificationController crea una nueva instancia del controlador de notificaciones
+func NewNotificationController(notificationService service.NotificationService) NotificationController {
+	return &notificationController{notificationService: notificationService}
+}
+
+// GetMyNotifications obtiene las notificaciones del usuario autenticado
+// GET /users/me/notifications?page=1&limit=20&unread=true
+func (ctrl *notificationController) GetMyNotifications(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	// Obtener parámetros de paginación y filtro
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, total, err := ctrl.notificationService.ListNotifications(userID.(int64), unreadOnly, page, limit)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data": gin.H{
+			"notifications": notifications,
+			"total":         total,
+			"page":          page,
+			"limit":         limit,
+		},
+	})
+}
+
+// GetUnreadCount obtiene la cantidad de notificaciones no leídas (badge de la app)
+// GET /users/me/notifications/unread-count
+func (ctrl *notificationController) GetUnreadCount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	count, err := ctrl.notificationService.GetUnreadCount(userID.(int64))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"unread_count": count},
+	})
+}
+
+// MarkAsRead marca una notificación como leída
+// PATCH /users/me/notifications/:id/read
+func (ctrl *notificationController) MarkAsRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	notification, err := ctrl.notificationService.MarkAsRead(userID.(int64), notificationID)
+	if err != nil {
+		if err.Error() == "notificación no encontrada" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    notification,
+	})
+}
+
+// MarkAllAsRead marca todas las notificaciones del usuario como leídas
+// POST /users/me/notifications/read-all
+func (ctrl *notificationController) MarkAllAsRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	updated, err := ctrl.notificationService.MarkAllAsRead(userID.(int64))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"updated": updated},
+	})
+}
+
+// DeleteNotification elimina una notificación del usuario autenticado
+// DELETE /users/me/notifications/:id
+func (ctrl *notificationController) DeleteNotification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	if err := ctrl.notificationService.DeleteNotification(userID.(int64), notificationID); err != nil {
+		if err.Error() == "notificación no encontrada" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"message": "notificación eliminada exitosamente"},
+	})
+}
+
+// SendSystemNotification envía un mensaje de sistema a un usuario
+// POST /admin/users/:id/notifications
+func (ctrl *notificationController) SendSystemNotification(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	var req domain.CreateSystemNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	notification, err := ctrl.notificationService.SendSystemNotification(userID, req)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    notification,
+	})
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PartnerController define la interfaz del controlador de partners corporativos (solo admin)
+type PartnerController interface {
+	CreatePartner(c *gin.Context)
+	ListPartners(c *gin.Context)
+	RevokePartner(c *gin.Context)
+	UpdateVerifiedDomains(c *gin.Context)
+}
+
+type partnerController struct {
+	partnerService service.PartnerService
+}
+
+// NewPartnerController crea una nueva instancia del controlador de partners
+func NewPartnerController(partnerService service.PartnerService) PartnerController {
+	return &partnerController{partnerService: partnerService}
+}
+
+// CreatePartner da de alta un partner y retorna su API key (solo se muestra en esta respuesta)
+// POST /admin/partners
+func (ctrl *partnerController) CreatePartner(c *gin.Context) {
+	var req domain.CreatePartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := ctrl.partnerService.CreatePartner(req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidEmailDomain) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		if err.Error() == "ya existe un partner con ese nombre" {
+			c.JSON(409, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ListPartners lista los partners (sin API keys)
+// GET /admin/partners
+func (ctrl *partnerController) ListPartners(c *gin.Context) {
+	partners, err := ctrl.partnerService.ListPartners()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    partners,
+	})
+}
+
+// RevokePartner revoca la API key de un partner
+// DELETE /admin/partners/:id
+func (ctrl *partnerController) RevokePartner(c *gin.Context) {
+	partnerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	if err := ctrl.partnerService.RevokePartner(partnerID); err != nil {
+		if err.Error() == "partner no encontrado" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"message": "API key del partner revocada"},
+	})
+}
+
+// UpdateVerifiedDomains reemplaza los dominios de email verificados de un partner
+// PUT /admin/partners/:id/domains
+func (ctrl *partnerController) UpdateVerifiedDomains(c *gin.Context) {
+	partnerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	var req domain.UpdatePartnerDomainsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	partner, err := ctrl.partnerService.UpdateVerifiedDomains(partnerID, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidEmailDomain) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		if err.Error() == "partner no encontrado" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    partner,
+	})
+}
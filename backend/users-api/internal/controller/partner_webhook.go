@@ -0,0 +1,344 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PartnerWebhookController define la interfaz del controlador de webhooks de partners
+// (API de partners con API key y autorizaciones del usuario autenticado)
+type PartnerWebhookController interface {
+	CreateWebhook(c *gin.Context)
+	ListWebhooks(c *gin.Context)
+	UpdateWebhook(c *gin.Context)
+	RotateSecret(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	RetryDelivery(c *gin.Context)
+
+	ListAuthorizedPartners(c *gin.Context)
+	AuthorizePartner(c *gin.Context)
+	RevokePartnerAuthorization(c *gin.Context)
+}
+
+type partnerWebhookController struct {
+	webhookService service.PartnerWebhookService
+}
+
+// NewPartnerWebhookController crea una nueva instancia del controlador de webhooks de partners
+func NewPartnerWebhookController(webhookService service.PartnerWebhookService) PartnerWebhookController {
+	return &partnerWebhookController{webhookService: webhookService}
+}
+
+// ==================== API DE PARTNERS ====================
+
+// CreateWebhook suscribe una URL del partner; el secreto de firma solo se muestra en esta respuesta
+// POST /partner/v1/webhooks
+func (ctrl *partnerWebhookController) CreateWebhook(c *gin.Context) {
+	var req domain.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	response, err := ctrl.webhookService.CreateWebhook(c.GetInt64("partner_id"), req)
+	if err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(201, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// ListWebhooks lista los webhooks del partner (sin secretos)
+// GET /partner/v1/webhooks
+func (ctrl *partnerWebhookController) ListWebhooks(c *gin.Context) {
+	webhooks, err := ctrl.webhookService.ListWebhooks(c.GetInt64("partner_id"))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    webhooks,
+	})
+}
+
+// UpdateWebhook cambia la URL o los eventos, o pausa/reanuda el webhook
+// PATCH /partner/v1/webhooks/:id
+func (ctrl *partnerWebhookController) UpdateWebhook(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	webhook, err := ctrl.webhookService.UpdateWebhook(c.GetInt64("partner_id"), webhookID, req)
+	if err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    webhook,
+	})
+}
+
+// RotateSecret genera un nuevo secreto de firma (solo se muestra en esta respuesta)
+// POST /partner/v1/webhooks/:id/rotate-secret
+func (ctrl *partnerWebhookController) RotateSecret(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	response, err := ctrl.webhookService.RotateSecret(c.GetInt64("partner_id"), webhookID)
+	if err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// DeleteWebhook borra el webhook y su log de entregas
+// DELETE /partner/v1/webhooks/:id
+func (ctrl *partnerWebhookController) DeleteWebhook(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.webhookService.DeleteWebhook(c.GetInt64("partner_id"), webhookID); err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"message": "webhook eliminado"},
+	})
+}
+
+// ListDeliveries lista el log de entregas del webhook
+// GET /partner/v1/webhooks/:id/deliveries?status=failed&page=1&limit=20
+func (ctrl *partnerWebhookController) ListDeliveries(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != domain.WebhookDeliveryPending && status != domain.WebhookDeliverySucceeded && status != domain.WebhookDeliveryFailed {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "status inválido, usar: pending, succeeded o failed",
+		})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	response, err := ctrl.webhookService.ListDeliveries(c.GetInt64("partner_id"), webhookID, status, page, limit)
+	if err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// RetryDelivery vuelve a encolar una entrega fallida
+// POST /partner/v1/webhooks/:id/deliveries/:delivery_id/retry
+func (ctrl *partnerWebhookController) RetryDelivery(c *gin.Context) {
+	webhookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := parseIDParam(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := ctrl.webhookService.RetryDelivery(c.GetInt64("partner_id"), webhookID, deliveryID)
+	if err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(202, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
+
+// ==================== AUTORIZACIONES DEL USUARIO ====================
+
+// ListAuthorizedPartners lista los partners que reciben los eventos del usuario autenticado
+// GET /users/me/partner-authorizations
+func (ctrl *partnerWebhookController) ListAuthorizedPartners(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	partners, err := ctrl.webhookService.ListAuthorizedPartners(userID.(int64))
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    partners,
+	})
+}
+
+// AuthorizePartner autoriza a un partner a recibir los eventos del usuario autenticado
+// PUT /users/me/partner-authorizations/:partner_id
+func (ctrl *partnerWebhookController) AuthorizePartner(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+	partnerID, ok := parseIDParam(c, "partner_id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.webhookService.AuthorizePartner(userID.(int64), partnerID); err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"message": "partner autorizado"},
+	})
+}
+
+// RevokePartnerAuthorization deja de enviar los eventos del usuario al partner
+// DELETE /users/me/partner-authorizations/:partner_id
+func (ctrl *partnerWebhookController) RevokePartnerAuthorization(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+	partnerID, ok := parseIDParam(c, "partner_id")
+	if !ok {
+		return
+	}
+
+	if err := ctrl.webhookService.RevokePartnerAuthorization(userID.(int64), partnerID); err != nil {
+		c.JSON(partnerWebhookErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    gin.H{"message": "autorización revocada"},
+	})
+}
+
+// parseIDParam lee un ID numérico de la ruta; responde 400 si es inválido
+func parseIDParam(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// partnerWebhookErrorStatus traduce los errores de webhooks y autorizaciones a códigos HTTP
+func partnerWebhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrWebhookNotFound), errors.Is(err, domain.ErrWebhookDeliveryNotFound),
+		errors.Is(err, domain.ErrPartnerNotFound), errors.Is(err, domain.ErrPartnerNotAuthorized):
+		return 404
+	case errors.Is(err, domain.ErrWebhookInvalidURL), errors.Is(err, domain.ErrWebhookInvalidEvent):
+		return 400
+	case errors.Is(err, domain.ErrWebhookLimitReached), errors.Is(err, domain.ErrWebhookDeliveryNotRetry):
+		return 409
+	default:
+		return 500
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"strconv"
+	"strings"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionController define la interfaz del controlador de permisos (requiere admin:permissions)
+type PermissionController interface {
+	GetRegistry(c *gin.Context)
+	GetUserPermissions(c *gin.Context)
+	UpdateUserPermissions(c *gin.Context)
+}
+
+type permissionController struct {
+	permissionService service.PermissionService
+}
+
+// NewPermissionController crea una nueva instancia del controlador de permisos
+func NewPermissionController(permissionService service.PermissionService) PermissionController {
+	return &permissionController{permissionService: permissionService}
+}
+
+// permissionErrorStatus traduce los errores del servicio a códigos HTTP (500 para los no esperados)
+func permissionErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "usuario no encontrado":
+		return 404
+	case strings.HasPrefix(msg, "permiso desconocido: "), strings.HasPrefix(msg, "permiso repetido: "):
+		return 400
+	case msg == "no puedes quitarte el permiso admin:permissions":
+		return 409
+	default:
+		return 500
+	}
+}
+
+// GetRegistry retorna los permisos registrados y los permisos por defecto de cada rol
+// GET /admin/permissions
+func (ctrl *permissionController) GetRegistry(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    ctrl.permissionService.GetRegistry(),
+	})
+}
+
+// GetUserPermissions retorna los permisos de un usuario (rol, individuales y efectivos)
+// GET /admin/users/:id/permissions
+func (ctrl *permissionController) GetUserPermissions(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	permissions, err := ctrl.permissionService.GetUserPermissions(userID)
+	if err != nil {
+		c.JSON(permissionErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    permissions,
+	})
+}
+
+// UpdateUserPermissions reemplaza los permisos otorgados y revocados individualmente a un usuario
+// Se aplican desde el próximo login del usuario
+// PUT /admin/users/:id/permissions
+func (ctrl *permissionController) UpdateUserPermissions(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	var req domain.PermissionOverridesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	permissions, err := ctrl.permissionService.UpdateUserPermissions(userID, adminID.(int64), req)
+	if err != nil {
+		c.JSON(permissionErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    permissions,
+	})
+}
+
+// hasPermission indica si el usuario autenticado tiene el permiso (lo carga AuthMiddleware)
+func hasPermission(c *gin.Context, permission string) bool {
+	permissions, _ := c.Get("permissions")
+	set, ok := permissions.(domain.PermissionSet)
+	return ok && set.Has(permission)
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferencesController define la interfaz del controlador de preferencias del usuario
+type PreferencesController interface {
+	GetMyNotificationPreferences(c *gin.Context)
+	UpdateMyNotificationPreferences(c *gin.Context)
+
+	// Perfil de preferencias: filtros de búsqueda por defecto y notificaciones in-app
+	GetMyPreferences(c *gin.Context)
+	UpdateMyPreferences(c *gin.Context)
+
+	// Interna (llamada desde search-api)
+	GetUserPreferences(c *gin.Context)
+}
+
+type preferencesController struct {
+	lifecycleService   service.LifecycleService
+	preferencesService service.PreferencesService
+}
+
+// NewPreferencesController crea una nueva instancia del controlador de preferencias
+func NewPreferencesController(lifecycleService service.LifecycleService, preferencesService service.PreferencesService) PreferencesController {
+	return &preferencesController{
+		lifecycleService:   lifecycleService,
+		preferencesService: preferencesService,
+	}
+}
+
+// GetMyNotificationPreferences obtiene las preferencias de notificación del usuario autenticado
+// GET /users/me/notification-preferences
+func (ctrl *preferencesController) GetMyNotificationPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	preferences, err := ctrl.lifecycleService.GetNotificationPreferences(userID.(int64))
+	if err != nil {
+		respondPreferencesError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    preferences,
+	})
+}
+
+// UpdateMyNotificationPreferences actualiza las preferencias de notificación del usuario autenticado
+// PUT /users/me/notification-preferences
+func (ctrl *preferencesController) UpdateMyNotificationPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	var req domain.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	preferences, err := ctrl.lifecycleService.UpdateNotificationPreferences(userID.(int64), req)
+	if err != nil {
+		respondPreferencesError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    preferences,
+	})
+}
+
+// GetMyPreferences obtiene el perfil de preferencias del usuario autenticado
+// GET /users/me/preferences
+func (ctrl *preferencesController) GetMyPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	preferences, err := ctrl.preferencesService.GetPreferences(userID.(int64))
+	if err != nil {
+		respondPreferencesError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    preferences,
+	})
+}
+
+// UpdateMyPreferences reemplaza el perfil de preferencias del usuario autenticado
+// PUT /users/me/preferences
+func (ctrl *preferencesController) UpdateMyPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	var req domain.UpdateUserPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	preferences, err := ctrl.preferencesService.UpdatePreferences(userID.(int64), req)
+	if err != nil {
+		respondPreferencesError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    preferences,
+	})
+}
+
+// GetUserPreferences obtiene el perfil de preferencias de un usuario (para precargar filtros en search-api)
+// GET /internal/users/:id/preferences
+func (ctrl *preferencesController) GetUserPreferences(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID de usuario inválido",
+		})
+		return
+	}
+
+	preferences, err := ctrl.preferencesService.GetPreferences(userID)
+	if err != nil {
+		respondPreferencesError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    preferences,
+	})
+}
+
+func respondPreferencesError(c *gin.Context, err error) {
+	status := 500
+	switch err.Error() {
+	case "usuario no encontrado":
+		status = 404
+	case "max_price debe ser mayor a 0", "preferred_language inválido", "auto_translate requiere preferred_language":
+		status = 400
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
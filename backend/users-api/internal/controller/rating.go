@@ -12,6 +12,9 @@ import (
 type RatingController interface {
 	CreateRating(c *gin.Context)
 	GetUserRatings(c *gin.Context)
+	GetDriverProfile(c *gin.Context)
+	UpdateRating(c *gin.Context)
+	GetRatingHistory(c *gin.Context)
 }
 
 type ratingController struct {
@@ -37,7 +40,7 @@ func (ctrl *ratingController) CreateRating(c *gin.Context) {
 	}
 
 	// Crear calificación
-	if err := ctrl.ratingService.CreateRating(req); err != nil {
+	if err := ctrl.ratingService.CreateRating(c.Request.Context(), req); err != nil {
 		if err.Error() == "ya existe una calificación para este viaje" {
 			c.JSON(400, gin.H{
 				"success": false,
@@ -96,3 +99,122 @@ func (ctrl *ratingController) GetUserRatings(c *gin.Context) {
 		},
 	})
 }
+
+// GetDriverProfile obtiene el perfil de conductor con sus estadísticas de calificación
+// GET /users/:id/driver-profile (y /internal/users/:id/driver-profile para otros servicios)
+func (ctrl *ratingController) GetDriverProfile(c *gin.Context) {
+	idParam := c.Param("id")
+	userID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	profile, err := ctrl.ratingService.GetDriverProfile(userID)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// UpdateRating edita una calificación del usuario autenticado
+// PUT /ratings/:id
+func (ctrl *ratingController) UpdateRating(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	ratingID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	var req domain.UpdateRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	rating, err := ctrl.ratingService.UpdateRating(c.Request.Context(), ratingID, userID.(int64), req)
+	if err != nil {
+		respondRatingError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    rating,
+	})
+}
+
+// GetRatingHistory obtiene una calificación con sus versiones anteriores (solo admin)
+// GET /admin/ratings/:id/history
+func (ctrl *ratingController) GetRatingHistory(c *gin.Context) {
+	ratingID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	history, err := ctrl.ratingService.GetRatingHistory(ratingID)
+	if err != nil {
+		respondRatingError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    history,
+	})
+}
+
+func respondRatingError(c *gin.Context, err error) {
+	status := 500
+	switch err.Error() {
+	case "calificación no encontrada":
+		status = 404
+	case "solo el autor puede editar la calificación":
+		status = 403
+	case "el plazo para editar la calificación venció",
+		"se alcanzó el máximo de ediciones de la calificación",
+		"la calificación fue editada por otra solicitud":
+		status = 409
+	}
+	c.JSON(status, gin.H{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,270 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scimContentType es el media type de SCIM 2.0 (RFC 7644 3.1)
+const scimContentType = "application/scim+json"
+
+// SCIMController define la interfaz del controlador de provisión SCIM (/scim/v2)
+type SCIMController interface {
+	ListUsers(c *gin.Context)
+	GetUser(c *gin.Context)
+	CreateUser(c *gin.Context)
+	PatchUser(c *gin.Context)
+	DeleteUser(c *gin.Context)
+
+	ListGroups(c *gin.Context)
+	GetGroup(c *gin.Context)
+	CreateGroup(c *gin.Context)
+	PatchGroup(c *gin.Context)
+	DeleteGroup(c *gin.Context)
+}
+
+type scimController struct {
+	scimService service.SCIMService
+}
+
+// NewSCIMController crea una nueva instancia del controlador SCIM
+func NewSCIMController(scimService service.SCIMService) SCIMController {
+	return &scimController{scimService: scimService}
+}
+
+// ListUsers lista los usuarios provisionados por el partner
+// GET /scim/v2/Users?filter=userName eq "ana@empresa.com"&startIndex=1&count=100
+func (ctrl *scimController) ListUsers(c *gin.Context) {
+	startIndex, count, ok := scimPagination(c)
+	if !ok {
+		return
+	}
+
+	response, err := ctrl.scimService.ListUsers(c.GetInt64("partner_id"), c.Query("filter"), startIndex, count)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, 200, response)
+}
+
+// GetUser obtiene un usuario provisionado con sus grupos
+// GET /scim/v2/Users/:id
+func (ctrl *scimController) GetUser(c *gin.Context) {
+	userID, ok := scimResourceID(c)
+	if !ok {
+		return
+	}
+
+	user, err := ctrl.scimService.GetUser(c.GetInt64("partner_id"), userID)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, 200, user)
+}
+
+// CreateUser provisiona un usuario
+// POST /scim/v2/Users
+func (ctrl *scimController) CreateUser(c *gin.Context) {
+	var req domain.SCIMUser
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeSCIMError(c, 400, "invalidSyntax", "datos inválidos: "+err.Error())
+		return
+	}
+
+	user, err := ctrl.scimService.CreateUser(c.GetInt64("partner_id"), req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Header("Location", user.Meta.Location)
+	respondSCIM(c, 201, user)
+}
+
+// PatchUser modifica un usuario (activar/desactivar, nombre, externalId)
+// PATCH /scim/v2/Users/:id
+func (ctrl *scimController) PatchUser(c *gin.Context) {
+	userID, ok := scimResourceID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeSCIMError(c, 400, "invalidSyntax", "datos inválidos: "+err.Error())
+		return
+	}
+
+	user, err := ctrl.scimService.PatchUser(c.GetInt64("partner_id"), userID, req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, 200, user)
+}
+
+// DeleteUser desactiva un usuario (la cuenta no se borra)
+// DELETE /scim/v2/Users/:id
+func (ctrl *scimController) DeleteUser(c *gin.Context) {
+	userID, ok := scimResourceID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.scimService.DeactivateUser(c.GetInt64("partner_id"), userID); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Status(204)
+}
+
+// ListGroups lista las organizaciones del partner
+// GET /scim/v2/Groups?filter=displayName eq "Ventas"&startIndex=1&count=100
+func (ctrl *scimController) ListGroups(c *gin.Context) {
+	startIndex, count, ok := scimPagination(c)
+	if !ok {
+		return
+	}
+
+	response, err := ctrl.scimService.ListGroups(c.GetInt64("partner_id"), c.Query("filter"), startIndex, count)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, 200, response)
+}
+
+// GetGroup obtiene una organización con sus miembros
+// GET /scim/v2/Groups/:id
+func (ctrl *scimController) GetGroup(c *gin.Context) {
+	groupID, ok := scimResourceID(c)
+	if !ok {
+		return
+	}
+
+	group, err := ctrl.scimService.GetGroup(c.GetInt64("partner_id"), groupID)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, 200, group)
+}
+
+// CreateGroup crea una organización
+// POST /scim/v2/Groups
+func (ctrl *scimController) CreateGroup(c *gin.Context) {
+	var req domain.SCIMGroup
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeSCIMError(c, 400, "invalidSyntax", "datos inválidos: "+err.Error())
+		return
+	}
+
+	group, err := ctrl.scimService.CreateGroup(c.GetInt64("partner_id"), req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Header("Location", group.Meta.Location)
+	respondSCIM(c, 201, group)
+}
+
+// PatchGroup modifica una organización (miembros, displayName)
+// PATCH /scim/v2/Groups/:id
+func (ctrl *scimController) PatchGroup(c *gin.Context) {
+	groupID, ok := scimResourceID(c)
+	if !ok {
+		return
+	}
+
+	var req domain.SCIMPatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeSCIMError(c, 400, "invalidSyntax", "datos inválidos: "+err.Error())
+		return
+	}
+
+	group, err := ctrl.scimService.PatchGroup(c.GetInt64("partner_id"), groupID, req)
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIM(c, 200, group)
+}
+
+// DeleteGroup borra una organización y sus membresías
+// DELETE /scim/v2/Groups/:id
+func (ctrl *scimController) DeleteGroup(c *gin.Context) {
+	groupID, ok := scimResourceID(c)
+	if !ok {
+		return
+	}
+
+	if err := ctrl.scimService.DeleteGroup(c.GetInt64("partner_id"), groupID); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Status(204)
+}
+
+// scimPagination lee startIndex y count (por defecto 1 y domain.SCIMDefaultCount)
+func scimPagination(c *gin.Context) (int, int, bool) {
+	startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil {
+		writeSCIMError(c, 400, "invalidValue", "startIndex inválido")
+		return 0, 0, false
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(domain.SCIMDefaultCount)))
+	if err != nil {
+		writeSCIMError(c, 400, "invalidValue", "count inválido")
+		return 0, 0, false
+	}
+	return startIndex, count, true
+}
+
+// scimResourceID lee el ID del recurso; un ID no numérico no puede existir (404)
+func scimResourceID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		writeSCIMError(c, 404, "", "recurso no encontrado")
+		return 0, false
+	}
+	return id, true
+}
+
+// respondSCIMError mapea los errores del servicio al formato de error de SCIM
+func respondSCIMError(c *gin.Context, err error) {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, domain.ErrInvalidSCIMFilter):
+		writeSCIMError(c, 400, "invalidFilter", msg)
+	case msg == "usuario no encontrado", msg == "grupo no encontrado":
+		writeSCIMError(c, 404, "", msg)
+	case msg == "el email ya está registrado", msg == "el grupo ya existe":
+		writeSCIMError(c, 409, "uniqueness", msg)
+	case strings.HasPrefix(msg, "valor inválido: "):
+		writeSCIMError(c, 400, "invalidValue", msg)
+	case strings.HasPrefix(msg, "operación no soportada: "):
+		writeSCIMError(c, 400, "invalidPath", msg)
+	default:
+		writeSCIMError(c, 500, "", msg)
+	}
+}
+
+func writeSCIMError(c *gin.Context, status int, scimType, detail string) {
+	respondSCIM(c, status, domain.SCIMError{
+		Schemas:  []string{domain.SCIMSchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func respondSCIM(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(status, body)
+}
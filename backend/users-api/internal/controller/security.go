@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"log"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityController define la interfaz del controlador del score de seguridad
+type SecurityController interface {
+	GetMySecurityScore(c *gin.Context)
+	GetScoreDistribution(c *gin.Context)
+}
+
+type securityController struct {
+	securityService service.SecurityService
+}
+
+// NewSecurityController crea una nueva instancia del controlador de seguridad
+func NewSecurityController(securityService service.SecurityService) SecurityController {
+	return &securityController{securityService: securityService}
+}
+
+// GetMySecurityScore obtiene el score de seguridad del usuario autenticado con recomendaciones
+// GET /users/me/security/score
+func (ctrl *securityController) GetMySecurityScore(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	score, err := ctrl.securityService.GetSecurityScore(userID.(int64))
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    score,
+	})
+}
+
+// GetScoreDistribution obtiene la distribución de scores de todas las cuentas (solo admin)
+// GET /admin/security/score-distribution
+func (ctrl *securityController) GetScoreDistribution(c *gin.Context) {
+	dist, err := ctrl.securityService.GetScoreDistribution()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	log.Printf("Distribución de score de seguridad: usuarios=%d promedio=%.1f niveles=%v adopción=%v",
+		dist.TotalUsers, dist.AverageScore, dist.Levels, dist.Adoption)
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    dist,
+	})
+}
@@ -1,7 +1,12 @@
 package controller
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"strconv"
+	"strings"
 	"users-api/internal/domain"
 	"users-api/internal/service"
 
@@ -12,10 +17,15 @@ import (
 type UserController interface {
 	GetAllUsers(c *gin.Context)
 	GetUserByID(c *gin.Context)
+	GetUsersByIDs(c *gin.Context)
+	GetPublicProfile(c *gin.Context)
+	GetSessionStatus(c *gin.Context)
 	GetMe(c *gin.Context)
 	UpdateUser(c *gin.Context)
 	DeleteUser(c *gin.Context)
 	ForceReauthentication(c *gin.Context)
+	BanUser(c *gin.Context)
+	UnbanUser(c *gin.Context)
 }
 
 type userController struct {
@@ -33,9 +43,23 @@ func (ctrl *userController) GetAllUsers(c *gin.Context) {
 	// Parsear parámetros de query
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	role := c.Query("role")       // filtro opcional: "user" o "admin"
+	role := c.Query("role")       // filtro opcional: "user", "admin" o "dependent"
 	search := c.Query("search")   // búsqueda por email o nombre
 
+	// filtro opcional por suspensión: "true" (solo suspendidos) o "false" (solo no suspendidos)
+	var banned *bool
+	if value := c.Query("banned"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"error":   "banned debe ser true o false",
+			})
+			return
+		}
+		banned = &parsed
+	}
+
 	if page < 1 {
 		page = 1
 	}
@@ -44,7 +68,7 @@ func (ctrl *userController) GetAllUsers(c *gin.Context) {
 	}
 
 	// Obtener usuarios con paginación
-	users, total, err := ctrl.userService.GetAllUsers(page, limit, role, search)
+	users, total, err := ctrl.userService.GetAllUsers(page, limit, role, search, banned)
 	if err != nil {
 		c.JSON(500, gin.H{
 			"success": false,
@@ -104,6 +128,143 @@ func (ctrl *userController) GetUserByID(c *gin.Context) {
 	})
 }
 
+// maxUsersPerBatch es la cantidad máxima de IDs de GET /internal/users
+const maxUsersPerBatch = 100
+
+// GetUsersByIDs obtiene varios usuarios en una sola request (llamado desde bookings-api)
+// GET /internal/users?ids=1,2,3 - los que no existen se omiten de la lista
+func (ctrl *userController) GetUsersByIDs(c *gin.Context) {
+	var ids []int64
+	for _, part := range strings.Split(c.Query("ids"), ",") {
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			c.JSON(400, gin.H{
+				"success": false,
+				"error":   "ID inválido: " + part,
+			})
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 || len(ids) > maxUsersPerBatch {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "se requieren entre 1 y " + strconv.Itoa(maxUsersPerBatch) + " IDs en ids",
+		})
+		return
+	}
+
+	users, err := ctrl.userService.GetUsersByIDs(ids)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    users,
+	})
+}
+
+// GetSessionStatus obtiene el estado de sesión de un usuario (llamado desde el middleware JWT de los otros servicios)
+// GET /internal/users/:id/session-status
+func (ctrl *userController) GetSessionStatus(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	status, err := ctrl.userService.GetSessionStatus(id)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
+
+// Cache del perfil público: los datos cambian poco y la ruta no requiere autenticación
+const publicProfileCacheControl = "public, max-age=600, stale-while-revalidate=3600"
+
+// GetPublicProfile obtiene el perfil público mínimo de un usuario (sin autenticación)
+// GET /public/users/:id
+func (ctrl *userController) GetPublicProfile(c *gin.Context) {
+	// Extraer ID del path
+	idParam := c.Param("id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	// Obtener perfil público (whitelist de campos)
+	profile, err := ctrl.userService.GetPublicProfile(id)
+	if err != nil {
+		if err.Error() == "usuario no encontrado" {
+			c.JSON(404, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	// ETag calculado sobre el perfil: permite responder 304 sin reenviar el cuerpo
+	body, err := json.Marshal(profile)
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("Cache-Control", publicProfileCacheControl)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(304)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    profile,
+	})
+}
+
 // GetMe obtiene el perfil del usuario autenticado
 // GET /users/me
 func (ctrl *userController) GetMe(c *gin.Context) {
@@ -157,14 +318,8 @@ func (ctrl *userController) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	// Extraer role del contexto
-	authRole, roleExists := c.Get("role")
-	if !roleExists {
-		authRole = "user" // default
-	}
-
-	// Validar que el usuario puede actualizar: es admin O es su propio perfil
-	if authRole != "admin" && authUserID.(int64) != id {
+	// Validar que el usuario puede actualizar: tiene admin:users O es su propio perfil
+	if !hasPermission(c, domain.PermissionAdminUsers) && authUserID.(int64) != id {
 		c.JSON(403, gin.H{
 			"success": false,
 			"error":   "no tienes permiso para actualizar este perfil",
@@ -192,6 +347,13 @@ func (ctrl *userController) UpdateUser(c *gin.Context) {
 			})
 			return
 		}
+		if isRegionalSettingsError(err) {
+			c.JSON(400, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
 		c.JSON(500, gin.H{
 			"success": false,
 			"error":   err.Error(),
@@ -229,14 +391,8 @@ func (ctrl *userController) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	// Extraer role del contexto
-	authRole, roleExists := c.Get("role")
-	if !roleExists {
-		authRole = "user" // default
-	}
-
-	// Validar que el usuario puede eliminar: es admin O es su propio perfil
-	if authRole != "admin" && authUserID.(int64) != id {
+	// Validar que el usuario puede eliminar: tiene admin:users O es su propio perfil
+	if !hasPermission(c, domain.PermissionAdminUsers) && authUserID.(int64) != id {
 		c.JSON(403, gin.H{
 			"success": false,
 			"error":   "no tienes permiso para eliminar este perfil",
@@ -301,3 +457,96 @@ func (ctrl *userController) ForceReauthentication(c *gin.Context) {
 		"data":    gin.H{"message": "email de verificación enviado exitosamente"},
 	})
 }
+
+// BanUser suspende la cuenta de un usuario
+// POST /admin/users/:id/ban (solo admin)
+func (ctrl *userController) BanUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "usuario no autenticado",
+		})
+		return
+	}
+
+	var req domain.BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "datos inválidos: " + err.Error(),
+		})
+		return
+	}
+
+	user, err := ctrl.userService.BanUser(adminID.(int64), id, req.Reason)
+	if err != nil {
+		c.JSON(banErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    user,
+	})
+}
+
+// UnbanUser levanta la suspensión de la cuenta de un usuario
+// POST /admin/users/:id/unban (solo admin)
+func (ctrl *userController) UnbanUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "ID inválido",
+		})
+		return
+	}
+
+	user, err := ctrl.userService.UnbanUser(id)
+	if err != nil {
+		c.JSON(banErrorStatus(err), gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    user,
+	})
+}
+
+// banErrorStatus mapea los errores de la suspensión de cuentas a status codes HTTP
+func banErrorStatus(err error) int {
+	switch err.Error() {
+	case "usuario no encontrado":
+		return 404
+	case "no puedes suspender tu propia cuenta":
+		return 400
+	case "el usuario ya está suspendido", "el usuario no está suspendido":
+		return 409
+	default:
+		return 500
+	}
+}
+
+// isRegionalSettingsError indica si el error es un país, moneda o unidad de distancia inválidos
+func isRegionalSettingsError(err error) bool {
+	return errors.Is(err, domain.ErrUnsupportedCountry) ||
+		errors.Is(err, domain.ErrUnsupportedCurrency) ||
+		errors.Is(err, domain.ErrUnsupportedDistanceUnit)
+}
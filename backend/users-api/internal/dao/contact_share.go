@@ -0,0 +1,26 @@
+package dao
+
+import "time"
+
+// ContactShareTokenDAO representa un token que permite a un usuario ver el teléfono de otro (tabla contact_share_tokens)
+// Se emite uno por cada lado de una reserva confirmada: el conductor ve al pasajero y el pasajero al conductor.
+// El token se guarda en claro porque GET /users/me/contact-shares lo devuelve; por sí solo no alcanza:
+// GET /contacts/shared/:token exige el JWT del usuario al que se emitió (viewer_id)
+type ContactShareTokenDAO struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	Token         string     `gorm:"type:char(64);uniqueIndex;not null;column:token"`
+	TripID        string     `gorm:"type:varchar(24);not null;index;column:trip_id"`
+	BookingID     string     `gorm:"type:varchar(36);not null;uniqueIndex:idx_contact_share_booking_viewer,priority:1;column:booking_id"`
+	ViewerID      int64      `gorm:"not null;uniqueIndex:idx_contact_share_booking_viewer,priority:2;index;column:viewer_id"` // Quién puede usar el token
+	ContactUserID int64      `gorm:"not null;column:contact_user_id"`                                                         // De quién es el teléfono
+	ContactRole   string     `gorm:"type:enum('driver','passenger');not null;column:contact_role"`
+	ExpiresAt     time.Time  `gorm:"not null;column:expires_at"`
+	RevokedAt     *time.Time `gorm:"column:revoked_at"` // NULL: vigente hasta expires_at
+	RevokeReason  string     `gorm:"type:varchar(32);column:revoke_reason"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (ContactShareTokenDAO) TableName() string {
+	return "contact_share_tokens"
+}
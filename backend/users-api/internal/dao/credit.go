@@ -0,0 +1,62 @@
+package dao
+
+import "time"
+
+// CreditGrantDAO representa un crédito promocional otorgado a un usuario (tabla credit_grants)
+// remaining es el saldo sin usar; los consumos usan primero los créditos que vencen antes
+type CreditGrantDAO struct {
+	ID                 int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID             int64      `gorm:"not null;index:idx_credit_grants_user_currency,priority:1;column:user_id"`
+	Currency           string     `gorm:"type:char(3);not null;index:idx_credit_grants_user_currency,priority:2;column:currency"`
+	Amount             float64    `gorm:"type:decimal(12,2);not null;column:amount"`
+	Remaining          float64    `gorm:"type:decimal(12,2);not null;column:remaining"`
+	Reason             string     `gorm:"type:varchar(255);not null;column:reason"`
+	GrantedBy          int64      `gorm:"not null;column:granted_by"` // Admin que otorgó el crédito
+	ExpiresAt          time.Time  `gorm:"not null;index;column:expires_at"`
+	ExpiringNotifiedAt *time.Time `gorm:"column:expiring_notified_at"` // Cuándo se publicó credit.expiring
+	ExpiredAt          *time.Time `gorm:"column:expired_at"`           // NULL: vigente (o vencido y todavía no procesado por el job)
+	CreatedAt          time.Time  `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (CreditGrantDAO) TableName() string {
+	return "credit_grants"
+}
+
+// CreditLedgerEntryDAO es un movimiento de créditos (tabla credit_ledger_entries)
+// Solo se insertan filas: el saldo de cada crédito es la suma de sus movimientos
+type CreditLedgerEntryDAO struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID    int64     `gorm:"not null;index:idx_credit_ledger_user_created,priority:1;column:user_id"`
+	GrantID   int64     `gorm:"not null;index;column:grant_id"`
+	Type      string    `gorm:"type:enum('grant','consume','expire','release');not null;column:type"`
+	Amount    float64   `gorm:"type:decimal(12,2);not null;column:amount"` // Negativo en consume y expire
+	Currency  string    `gorm:"type:char(3);not null;column:currency"`
+	BookingID *string   `gorm:"type:varchar(64);index;column:booking_id"` // Solo en consume y release
+	Reason    string    `gorm:"type:varchar(255);column:reason"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_credit_ledger_user_created,priority:2;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (CreditLedgerEntryDAO) TableName() string {
+	return "credit_ledger_entries"
+}
+
+// CreditConsumptionDAO registra la aplicación de créditos a una reserva (tabla credit_consumptions)
+// Única por booking_id: es la clave de idempotencia del consumo y de su devolución
+type CreditConsumptionDAO struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	BookingID      string     `gorm:"type:varchar(64);uniqueIndex;not null;column:booking_id"`
+	UserID         int64      `gorm:"not null;index;column:user_id"`
+	Currency       string     `gorm:"type:char(3);not null;column:currency"`
+	Requested      float64    `gorm:"type:decimal(12,2);not null;column:requested"`
+	AppliedAmount  float64    `gorm:"type:decimal(12,2);not null;column:applied_amount"`
+	ReleasedAmount float64    `gorm:"type:decimal(12,2);not null;default:0;column:released_amount"`
+	ReleasedAt     *time.Time `gorm:"column:released_at"` // NULL: los créditos siguen aplicados a la reserva
+	CreatedAt      time.Time  `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (CreditConsumptionDAO) TableName() string {
+	return "credit_consumptions"
+}
@@ -0,0 +1,41 @@
+package dao
+
+import "time"
+
+// GuardianApprovalDAO representa una acción de un dependiente que espera la aprobación de su tutor
+// (tabla guardian_approvals). Única por (action, resource_id) para que la solicitud sea idempotente
+type GuardianApprovalDAO struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	GuardianID   int64      `gorm:"not null;index:idx_guardian_approvals_guardian_status,priority:1;column:guardian_id"`
+	DependentID  int64      `gorm:"not null;index;column:dependent_id"`
+	Action       string     `gorm:"type:varchar(32);not null;uniqueIndex:idx_guardian_approvals_resource,priority:1;column:action"`
+	ResourceID   string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_guardian_approvals_resource,priority:2;column:resource_id"`
+	TripID       string     `gorm:"type:varchar(24);column:trip_id"`
+	Details      string     `gorm:"type:varchar(500);column:details"`
+	Status       string     `gorm:"type:enum('pending','approved','rejected');default:'pending';not null;index:idx_guardian_approvals_guardian_status,priority:2;column:status"`
+	DecisionNote string     `gorm:"type:varchar(255);column:decision_note"`
+	DecidedAt    *time.Time `gorm:"column:decided_at"`
+	ExpiresAt    time.Time  `gorm:"not null;column:expires_at"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (GuardianApprovalDAO) TableName() string {
+	return "guardian_approvals"
+}
+
+// GuardianAuditLogDAO registra una acción de un tutor sobre un dependiente (tabla guardian_audit_logs)
+// Solo se insertan filas: la auditoría no se edita ni se borra
+type GuardianAuditLogDAO struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	GuardianID  int64     `gorm:"not null;index;column:guardian_id"`
+	DependentID int64     `gorm:"not null;index;column:dependent_id"`
+	Action      string    `gorm:"type:varchar(32);not null;column:action"`
+	Details     string    `gorm:"type:text;column:details"` // JSON con los datos de la acción (campos cambiados, solicitud resuelta)
+	CreatedAt   time.Time `gorm:"autoCreateTime;index;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (GuardianAuditLogDAO) TableName() string {
+	return "guardian_audit_logs"
+}
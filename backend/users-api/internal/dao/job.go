@@ -0,0 +1,28 @@
+package dao
+
+import "time"
+
+// JobDAO representa un job de la cola persistente (tabla jobs)
+//
+// run_at es el próximo intento de un job pending y el fin del lease de uno running: los workers
+// toman los jobs pending o running con run_at vencido (un running vencido es de un worker caído)
+type JobDAO struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	Type        string     `gorm:"type:varchar(64);not null;index:idx_jobs_type_status,priority:1;column:type"`
+	Payload     string     `gorm:"type:text;not null;column:payload"` // JSON que recibe el handler
+	Status      string     `gorm:"type:enum('pending','running','succeeded','failed');default:'pending';not null;index:idx_jobs_due,priority:1;index:idx_jobs_type_status,priority:2;column:status"`
+	Attempts    int        `gorm:"default:0;not null;column:attempts"`
+	MaxAttempts int        `gorm:"not null;column:max_attempts"`
+	RunAt       time.Time  `gorm:"not null;index:idx_jobs_due,priority:2;column:run_at"`
+	LeasedBy    string     `gorm:"type:varchar(128);column:leased_by"` // Worker que tomó el último intento
+	LastError   string     `gorm:"type:varchar(1000);column:last_error"`
+	UniqueKey   *string    `gorm:"type:varchar(191);uniqueIndex;column:unique_key"` // NULL: sin deduplicación
+	FinishedAt  *time.Time `gorm:"index;column:finished_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;column:created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (JobDAO) TableName() string {
+	return "jobs"
+}
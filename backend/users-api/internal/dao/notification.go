@@ -0,0 +1,22 @@
+package dao
+
+import "time"
+
+// NotificationDAO representa la estructura de datos para la tabla notifications en MySQL
+type NotificationDAO struct {
+	ID        int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID    int64      `gorm:"not null;index:idx_notifications_user_read,priority:1;uniqueIndex:idx_notifications_event_user,priority:2;column:user_id"`
+	Type      string     `gorm:"type:enum('booking_update','chat_mention','system','guardian_approval');not null;column:type"`
+	Title     string     `gorm:"type:varchar(255);not null;column:title"`
+	Message   string     `gorm:"type:text;column:message"`
+	TripID    string     `gorm:"type:varchar(24);column:trip_id"`
+	BookingID string     `gorm:"type:varchar(36);column:booking_id"`
+	EventID   *string    `gorm:"type:varchar(36);uniqueIndex:idx_notifications_event_user,priority:1;column:event_id"` // NULL para mensajes de sistema
+	ReadAt    *time.Time `gorm:"index:idx_notifications_user_read,priority:2;column:read_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (NotificationDAO) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"strings"
+	"time"
+)
+
+// PartnerDAO representa un partner corporativo que provisiona empleados vía SCIM (tabla partners)
+// La API key se guarda solo hasheada (SHA-256): el valor en claro se muestra una única vez al crearla
+type PartnerDAO struct {
+	ID           int64  `gorm:"primaryKey;autoIncrement;column:id"`
+	Name         string `gorm:"type:varchar(100);unique;not null;column:name"`
+	APIKeyHash   string `gorm:"type:char(64);uniqueIndex;not null;column:api_key_hash"`
+	APIKeyPrefix string `gorm:"type:varchar(16);not null;column:api_key_prefix"` // Permite identificar la key sin exponerla
+	Active       bool   `gorm:"default:true;not null;column:active"`             // false: key revocada
+	// VerifiedDomains son los dominios de email del partner (separados por coma), verificados por un admin
+	// Los usuarios provisionados vía SCIM deben tener un email de alguno de estos dominios
+	VerifiedDomains string     `gorm:"type:varchar(1000);not null;default:'';column:verified_domains"`
+	LastUsedAt      *time.Time `gorm:"column:last_used_at"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime;column:created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (PartnerDAO) TableName() string {
+	return "partners"
+}
+
+// Domains retorna los dominios verificados del partner
+func (p *PartnerDAO) Domains() []string {
+	if p.VerifiedDomains == "" {
+		return []string{}
+	}
+	return strings.Split(p.VerifiedDomains, ",")
+}
+
+// OrganizationDAO representa una organización de un partner (Group de SCIM, ej. una sede o un área)
+type OrganizationDAO struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	PartnerID   int64     `gorm:"not null;uniqueIndex:idx_organizations_partner_name,priority:1;column:partner_id"`
+	DisplayName string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_organizations_partner_name,priority:2;column:display_name"`
+	ExternalID  *string   `gorm:"type:varchar(255);column:external_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;column:created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (OrganizationDAO) TableName() string {
+	return "organizations"
+}
+
+// OrganizationMemberDAO representa la pertenencia de un usuario a una organización
+type OrganizationMemberDAO struct {
+	OrganizationID int64     `gorm:"primaryKey;autoIncrement:false;column:organization_id"`
+	UserID         int64     `gorm:"primaryKey;autoIncrement:false;index;column:user_id"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (OrganizationMemberDAO) TableName() string {
+	return "organization_members"
+}
@@ -0,0 +1,60 @@
+package dao
+
+import "time"
+
+// PartnerAuthorizationDAO registra que un usuario autorizó a un partner a recibir sus eventos (tabla partner_authorizations)
+// Los usuarios provisionados por SCIM quedan autorizados para el partner que los creó; el usuario puede revocarlo
+type PartnerAuthorizationDAO struct {
+	UserID    int64     `gorm:"primaryKey;autoIncrement:false;column:user_id"`
+	PartnerID int64     `gorm:"primaryKey;autoIncrement:false;index;column:partner_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (PartnerAuthorizationDAO) TableName() string {
+	return "partner_authorizations"
+}
+
+// PartnerWebhookDAO es una URL del partner suscripta a eventos de usuario (tabla partner_webhooks)
+// El secreto se guarda en claro porque hace falta para firmar cada entrega (HMAC-SHA256);
+// la API solo lo muestra al crear el webhook o al rotarlo
+type PartnerWebhookDAO struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	PartnerID int64     `gorm:"not null;index;column:partner_id"`
+	URL       string    `gorm:"type:varchar(500);not null;column:url"`
+	Events    string    `gorm:"type:varchar(255);not null;column:events"` // Tipos de evento separados por coma (FIND_IN_SET)
+	Secret    string    `gorm:"type:varchar(100);not null;column:secret"`
+	Active    bool      `gorm:"default:true;not null;column:active"` // false: pausado por el partner, no genera entregas
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (PartnerWebhookDAO) TableName() string {
+	return "partner_webhooks"
+}
+
+// WebhookDeliveryDAO es una entrega de un evento a un webhook y su registro de intentos (tabla webhook_deliveries)
+// El payload se guarda al encolar: los reintentos envían exactamente el mismo cuerpo
+type WebhookDeliveryDAO struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	WebhookID      int64      `gorm:"not null;index:idx_webhook_deliveries_webhook_created,priority:1;column:webhook_id"`
+	PartnerID      int64      `gorm:"not null;index:idx_webhook_deliveries_partner_user,priority:1;column:partner_id"`
+	UserID         int64      `gorm:"not null;index:idx_webhook_deliveries_partner_user,priority:2;column:user_id"`
+	EventID        string     `gorm:"type:varchar(64);not null;index;column:event_id"`
+	EventType      string     `gorm:"type:varchar(50);not null;column:event_type"`
+	Payload        string     `gorm:"type:text;not null;column:payload"`
+	Status         string     `gorm:"type:enum('pending','succeeded','failed');default:'pending';not null;index:idx_webhook_deliveries_due,priority:1;column:status"`
+	Attempts       int        `gorm:"default:0;not null;column:attempts"`
+	LastStatusCode *int       `gorm:"column:last_status_code"` // NULL: sin respuesta HTTP (timeout, DNS, conexión rechazada)
+	LastError      string     `gorm:"type:varchar(500);column:last_error"`
+	NextAttemptAt  time.Time  `gorm:"not null;index:idx_webhook_deliveries_due,priority:2;column:next_attempt_at"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime;index:idx_webhook_deliveries_webhook_created,priority:2;column:created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (WebhookDeliveryDAO) TableName() string {
+	return "webhook_deliveries"
+}
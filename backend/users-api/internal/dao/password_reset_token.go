@@ -0,0 +1,20 @@
+package dao
+
+import "time"
+
+// PasswordResetTokenDAO representa un token para restablecer la contraseña (tabla password_reset_tokens)
+// Igual que los tokens de verificación: solo se guarda el hash (SHA-256), vence y se usa una sola vez
+type PasswordResetTokenDAO struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID      int64      `gorm:"not null;index:idx_password_reset_tokens_user_created,priority:1;column:user_id"`
+	TokenHash   string     `gorm:"type:char(64);uniqueIndex;not null;column:token_hash"`
+	RequestedIP string     `gorm:"type:varchar(45);column:requested_ip"` // Vacío: emitido por SCIM (alta de un usuario provisionado)
+	ExpiresAt   time.Time  `gorm:"not null;column:expires_at"`
+	UsedAt      *time.Time `gorm:"column:used_at"` // NULL: todavía no se usó (también se marca al usarse otro token del usuario)
+	CreatedAt   time.Time  `gorm:"autoCreateTime;index:idx_password_reset_tokens_user_created,priority:2;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (PasswordResetTokenDAO) TableName() string {
+	return "password_reset_tokens"
+}
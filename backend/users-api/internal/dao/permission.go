@@ -0,0 +1,19 @@
+package dao
+
+import "time"
+
+// UserPermissionOverrideDAO otorga o revoca un permiso a un usuario puntual, por encima de los de su rol
+// (tabla user_permission_overrides). Único por (user_id, permission)
+type UserPermissionOverrideDAO struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID     int64     `gorm:"not null;uniqueIndex:idx_user_permission_overrides_user_permission,priority:1;column:user_id"`
+	Permission string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_user_permission_overrides_user_permission,priority:2;column:permission"`
+	Granted    bool      `gorm:"not null;column:granted"` // false: el permiso se revoca aunque el rol lo tenga
+	CreatedBy  int64     `gorm:"not null;column:created_by"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (UserPermissionOverrideDAO) TableName() string {
+	return "user_permission_overrides"
+}
@@ -4,17 +4,36 @@ import "time"
 
 // RatingDAO representa la estructura de datos para la tabla ratings en MySQL
 type RatingDAO struct {
-	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
-	RaterID     int64     `gorm:"not null;index;column:rater_id"`
-	RatedUserID int64     `gorm:"not null;index;column:rated_user_id"`
-	TripID      string    `gorm:"type:varchar(24);not null;index;column:trip_id"`
-	RoleRated   string    `gorm:"type:enum('conductor','pasajero');not null;column:role_rated"`
-	Score       int       `gorm:"not null;column:score"`
-	Comment     string    `gorm:"type:text;column:comment"`
-	CreatedAt   time.Time `gorm:"autoCreateTime;column:created_at"`
+	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	RaterID     int64      `gorm:"not null;index;column:rater_id"`
+	RatedUserID int64      `gorm:"not null;index;column:rated_user_id"`
+	TripID      string     `gorm:"type:varchar(24);not null;index;column:trip_id"`
+	RoleRated   string     `gorm:"type:enum('conductor','pasajero');not null;column:role_rated"`
+	Score       int        `gorm:"not null;column:score"`
+	Comment     string     `gorm:"type:text;column:comment"`
+	EditCount   int        `gorm:"not null;default:0;column:edit_count"`
+	EditedAt    *time.Time `gorm:"column:edited_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime;column:created_at"`
 }
 
 // TableName especifica el nombre de la tabla en la base de datos
 func (RatingDAO) TableName() string {
 	return "ratings"
 }
+
+// RatingEditDAO guarda una versión anterior de una calificación editada (tabla rating_edits)
+// Version es la versión reemplazada: 0 la original, 1 la primera edición
+type RatingEditDAO struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	RatingID  int64     `gorm:"not null;uniqueIndex:idx_rating_edits_rating_version;column:rating_id"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_rating_edits_rating_version;column:version"`
+	Score     int       `gorm:"not null;column:score"`
+	Comment   string    `gorm:"type:text;column:comment"`
+	EditedBy  int64     `gorm:"not null;column:edited_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at"` // Cuándo fue reemplazada
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (RatingEditDAO) TableName() string {
+	return "rating_edits"
+}
@@ -13,7 +13,7 @@ type UserDAO struct {
 	Name         string `gorm:"type:varchar(100);not null;column:name"`
 	Lastname     string `gorm:"type:varchar(100);not null;column:lastname"`
 	PasswordHash string `gorm:"type:varchar(255);not null;column:password_hash"`
-	Role         string `gorm:"type:enum('user','admin');default:'user';not null;column:role"`
+	Role         string `gorm:"type:enum('user','admin','dependent');default:'user';not null;column:role"`
 	Phone        string `gorm:"type:varchar(20);not null;column:phone"`
 	Street       string `gorm:"type:varchar(255);not null;column:street"`
 	Number       int    `gorm:"not null;column:number"`
@@ -24,10 +24,54 @@ type UserDAO struct {
 	TotalTripsPassenger   int        `gorm:"default:0;column:total_trips_passenger"`
 	TotalTripsDriver      int        `gorm:"default:0;column:total_trips_driver"`
 	Birthdate             time.Time  `gorm:"not null;column:birthdate"`
+
+	// Factores de seguridad de la cuenta (score de seguridad)
+	PhoneVerified     bool       `gorm:"default:false;not null;column:phone_verified"`
+	TwoFactorEnabled  bool       `gorm:"default:false;not null;column:two_factor_enabled"`
+	PasskeyCount      int        `gorm:"default:0;not null;column:passkey_count"`
+	PasswordChangedAt *time.Time `gorm:"column:password_changed_at"` // NULL: nunca se cambió desde el registro
+	SessionsRevokedAt *time.Time `gorm:"column:sessions_revoked_at"` // Los JWT emitidos antes (claim iat) dejan de valer; NULL: nunca
+
+	// Última revocación de un permiso: los JWT emitidos antes todavía lo llevan en "perms" y dejan de valer; NULL: nunca
+	PermissionsRevokedAt *time.Time `gorm:"column:permissions_revoked_at"`
+
+	// Actividad y campañas de marketing (eventos user.registered / user.inactive_30d)
+	MarketingEmails    bool       `gorm:"default:false;not null;column:marketing_emails"` // Opt-in: sin consentimiento hasta que el usuario lo active
+	LastLoginAt        *time.Time `gorm:"column:last_login_at;index"` // NULL: nunca inició sesión
+	InactiveNotifiedAt *time.Time `gorm:"column:inactive_notified_at"` // Último user.inactive_30d publicado
+
+	// Provisión SCIM por partners corporativos
+	Active     bool    `gorm:"default:true;not null;column:active"`   // false: cuenta desactivada, no puede iniciar sesión
+	PartnerID  *int64  `gorm:"column:partner_id;index"`               // Partner que provisionó la cuenta (NULL: registro normal)
+	ExternalID *string `gorm:"type:varchar(255);column:external_id"` // ID del empleado en el sistema del partner
+
+	// Suspensión de la cuenta por un admin (distinta de Active: SCIM no la levanta)
+	BannedAt  *time.Time `gorm:"column:banned_at;index"`           // NULL: cuenta no suspendida
+	BannedBy  *int64     `gorm:"column:banned_by"`                 // Admin que suspendió la cuenta
+	BanReason string     `gorm:"type:varchar(500);column:ban_reason"`
+
+	// Cuentas de menores gestionadas por un tutor (role dependent)
+	GuardianID *int64 `gorm:"column:guardian_id;index"` // Tutor que creó la cuenta (NULL: cuenta propia)
+
+	// Configuración regional: país (ISO 3166-1 alpha-2), moneda preferida (ISO 4217) y unidad de distancia
+	// Se infieren del locale al registrarse; trips-api y search-api las usan para precios y distancias
+	Country      string `gorm:"type:varchar(2);default:'AR';not null;column:country"`
+	Currency     string `gorm:"type:varchar(3);default:'ARS';not null;column:currency"`
+	DistanceUnit string `gorm:"type:enum('km','mi');default:'km';not null;column:distance_unit"`
+
+	// Login social (OAuth2): proveedor vinculado y ID del usuario en el proveedor (NULL: solo contraseña)
+	Provider   *string `gorm:"type:varchar(20);column:provider;uniqueIndex:idx_users_provider"`
+	ProviderID *string `gorm:"type:varchar(255);column:provider_id;uniqueIndex:idx_users_provider"`
+
 	CreatedAt             time.Time  `gorm:"autoCreateTime;column:created_at"`
 	UpdatedAt             time.Time  `gorm:"autoUpdateTime;column:updated_at"`
 }
 
+// IsBanned indica si un admin suspendió la cuenta
+func (u *UserDAO) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
 // TableName especifica el nombre de la tabla en la base de datos
 func (UserDAO) TableName() string {
 	return "users"
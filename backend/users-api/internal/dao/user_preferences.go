@@ -0,0 +1,31 @@
+package dao
+
+import "time"
+
+// UserPreferencesDAO representa el perfil de preferencias del usuario (tabla user_preferences)
+// Una fila por usuario; sin fila se usan los valores por defecto (sin filtros, notificaciones activas)
+type UserPreferencesDAO struct {
+	UserID int64 `gorm:"primaryKey;autoIncrement:false;column:user_id"`
+
+	// Filtros de búsqueda por defecto (NULL: sin preferencia, el filtro no se aplica)
+	PetsAllowed    *bool    `gorm:"column:pets_allowed"`
+	SmokingAllowed *bool    `gorm:"column:smoking_allowed"`
+	MusicAllowed   *bool    `gorm:"column:music_allowed"`
+	MaxPrice       *float64 `gorm:"type:decimal(12,2);column:max_price"`
+
+	// Notificaciones in-app por tipo
+	// Sin default en la columna: GORM omite los false al insertar y se aplicaría el default
+	NotifyBookingUpdates bool `gorm:"not null;column:notify_booking_updates"`
+	NotifyChatMentions   bool `gorm:"not null;column:notify_chat_mentions"`
+
+	// Chat: idioma del usuario y traducción automática de los mensajes (la aplica trips-api)
+	PreferredLanguage string `gorm:"size:10;column:preferred_language"`
+	ChatAutoTranslate bool   `gorm:"not null;column:chat_auto_translate"`
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (UserPreferencesDAO) TableName() string {
+	return "user_preferences"
+}
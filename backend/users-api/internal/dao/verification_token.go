@@ -0,0 +1,20 @@
+package dao
+
+import "time"
+
+// VerificationTokenDAO representa un token de verificación de email enviado a un usuario (tabla verification_tokens)
+// El token se guarda solo hasheado (SHA-256): el valor en claro viaja únicamente en el email
+type VerificationTokenDAO struct {
+	ID        int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID    int64      `gorm:"not null;index:idx_verification_tokens_user_created,priority:1;column:user_id"`
+	TokenHash string     `gorm:"type:char(64);uniqueIndex;not null;column:token_hash"`
+	Purpose   string     `gorm:"type:enum('register','resend','reauth');not null;column:purpose"` // Por qué se envió (los reenvíos tienen límite por hora)
+	ExpiresAt time.Time  `gorm:"not null;column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"` // NULL: todavía no se usó (un token se usa una sola vez)
+	CreatedAt time.Time  `gorm:"autoCreateTime;index:idx_verification_tokens_user_created,priority:2;column:created_at"`
+}
+
+// TableName especifica el nombre de la tabla en la base de datos
+func (VerificationTokenDAO) TableName() string {
+	return "verification_tokens"
+}
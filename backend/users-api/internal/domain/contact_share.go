@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Rol en el viaje del usuario cuyo teléfono se comparte
+const (
+	ContactRoleDriver    = "driver"
+	ContactRolePassenger = "passenger"
+)
+
+// Motivos por los que se revoca un token de contacto antes de que expire
+const (
+	ContactShareRevokedTripCompleted    = "trip_completed"
+	ContactShareRevokedTripCancelled    = "trip_cancelled"
+	ContactShareRevokedBookingCancelled = "booking_cancelled"
+)
+
+// Errores de los tokens de contacto (el controller los traduce a códigos HTTP)
+var (
+	ErrContactShareNotFound = errors.New("token de contacto no encontrado")
+	ErrContactShareExpired  = errors.New("el token de contacto expiró")
+	ErrContactShareRevoked  = errors.New("el token de contacto fue revocado")
+)
+
+// MatchedBooking son los datos de una reserva confirmada (evento reservation.confirmed)
+// A partir de ella conductor y pasajero pueden ver el teléfono del otro
+type MatchedBooking struct {
+	TripID      string
+	BookingID   string
+	DriverID    int64
+	PassengerID int64
+}
+
+// ContactShareDTO es un token de contacto vigente del usuario autenticado
+type ContactShareDTO struct {
+	Token         string    `json:"token"`
+	TripID        string    `json:"trip_id"`
+	BookingID     string    `json:"booking_id"`
+	ContactUserID int64     `json:"contact_user_id"`
+	ContactRole   string    `json:"contact_role"` // Rol del otro usuario en el viaje: driver o passenger
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// SharedContactDTO es el contacto que devuelve GET /contacts/shared/:token
+type SharedContactDTO struct {
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	Lastname  string    `json:"lastname"`
+	Phone     string    `json:"phone"`
+	Role      string    `json:"role"` // Rol del usuario en el viaje: driver o passenger
+	TripID    string    `json:"trip_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Movimientos del ledger de créditos promocionales (tabla credit_ledger_entries)
+// grant y release suman saldo; consume y expire lo restan (amount negativo)
+const (
+	CreditEntryGrant   = "grant"
+	CreditEntryConsume = "consume"
+	CreditEntryExpire  = "expire"
+	CreditEntryRelease = "release" // Devolución de un consumo al cancelarse la reserva
+)
+
+// Eventos de créditos (exchange users.events), para avisar al usuario por email/push
+const (
+	EventTypeCreditExpiring = "credit.expiring" // Un crédito vence dentro de CreditExpiryNoticeWindow
+	EventTypeCreditExpired  = "credit.expired"  // Un crédito venció con saldo sin usar
+)
+
+// CreditExpiryNoticeWindow es la anticipación con la que se publica credit.expiring
+const CreditExpiryNoticeWindow = 72 * time.Hour
+
+// MaxCreditValidity es la vigencia máxima de un crédito otorgado
+const MaxCreditValidity = 366 * 24 * time.Hour
+
+// RoundCreditAmount redondea un monto a centavos (los montos se guardan como decimal(12,2))
+func RoundCreditAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// GrantCreditRequest representa los datos para otorgar un crédito promocional a un usuario (admin)
+// La vigencia se indica con expires_at o con valid_days (uno de los dos)
+type GrantCreditRequest struct {
+	Amount    float64    `json:"amount" binding:"required,gt=0"`
+	Currency  string     `json:"currency" binding:"required,len=3,uppercase"` // ISO 4217, ej: ARS
+	ExpiresAt *time.Time `json:"expires_at"`
+	ValidDays int        `json:"valid_days" binding:"omitempty,min=1,max=366"`
+	Reason    string     `json:"reason" binding:"required,max=255"` // Ej: "Campaña de regreso", "Compensación viaje cancelado"
+}
+
+// ConsumeCreditsRequest es el pedido de bookings-api al calcular la tarifa de una reserva
+// Es idempotente por booking_id: repetirlo retorna la misma aplicación sin consumir de nuevo
+type ConsumeCreditsRequest struct {
+	UserID    int64   `json:"user_id" binding:"required"`
+	BookingID string  `json:"booking_id" binding:"required,max=64"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"` // Máximo a aplicar (la tarifa de la reserva)
+	Currency  string  `json:"currency" binding:"required,len=3,uppercase"`
+}
+
+// CreditConsumptionDTO es el resultado de aplicar créditos a una reserva
+// applied_amount puede ser menor al pedido (o 0) si el saldo no alcanza
+type CreditConsumptionDTO struct {
+	BookingID     string    `json:"booking_id"`
+	UserID        int64     `json:"user_id"`
+	Currency      string    `json:"currency"`
+	Requested     float64   `json:"requested_amount"`
+	AppliedAmount float64   `json:"applied_amount"`
+	Balance       float64   `json:"balance"` // Saldo restante en la moneda después de aplicar
+	Replayed      bool      `json:"replayed"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ReleaseCreditsRequest es el pedido de bookings-api al cancelarse una reserva dentro de la ventana de reembolso
+// Es idempotente por booking_id: repetirlo retorna la devolución original sin devolver de nuevo
+type ReleaseCreditsRequest struct {
+	BookingID string `json:"booking_id" binding:"required,max=64"`
+}
+
+// CreditReleaseDTO es el resultado de devolver los créditos aplicados a una reserva
+// released_amount puede ser menor a applied_amount si alguno de los créditos ya venció
+type CreditReleaseDTO struct {
+	BookingID      string    `json:"booking_id"`
+	UserID         int64     `json:"user_id"`
+	Currency       string    `json:"currency"`
+	AppliedAmount  float64   `json:"applied_amount"`
+	ReleasedAmount float64   `json:"released_amount"`
+	Replayed       bool      `json:"replayed"`
+	ReleasedAt     time.Time `json:"released_at"`
+}
+
+// CreditGrantDTO representa un crédito otorgado y su saldo sin usar
+type CreditGrantDTO struct {
+	ID        int64      `json:"id"`
+	Amount    float64    `json:"amount"`
+	Remaining float64    `json:"remaining"`
+	Currency  string     `json:"currency"`
+	Reason    string     `json:"reason"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	ExpiredAt *time.Time `json:"expired_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreditBalanceDTO es el saldo disponible del usuario en una moneda
+type CreditBalanceDTO struct {
+	Currency         string     `json:"currency"`
+	Amount           float64    `json:"amount"`
+	NextExpiresAt    *time.Time `json:"next_expires_at,omitempty"`    // Vencimiento del crédito que vence primero
+	NextExpiryAmount float64    `json:"next_expiry_amount,omitempty"` // Saldo que vence en next_expires_at
+}
+
+// CreditsSummaryDTO es la respuesta de GET /users/me/credits
+type CreditsSummaryDTO struct {
+	Balances []CreditBalanceDTO `json:"balances"`
+	Grants   []CreditGrantDTO   `json:"grants"` // Créditos vigentes con saldo, los que vencen primero al principio
+}
+
+// CreditLedgerEntryDTO representa un movimiento del ledger
+type CreditLedgerEntryDTO struct {
+	ID        int64     `json:"id"`
+	GrantID   int64     `json:"grant_id"`
+	Type      string    `json:"type"`
+	Amount    float64   `json:"amount"` // Positivo en grant y release, negativo en consume y expire
+	Currency  string    `json:"currency"`
+	BookingID string    `json:"booking_id,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreditEvent es el payload de credit.expiring y credit.expired
+type CreditEvent struct {
+	EventID       string    `json:"event_id"` // Determinístico: <event_type>:<grant_id>
+	EventType     string    `json:"event_type"`
+	UserID        int64     `json:"user_id"`
+	GrantID       int64     `json:"grant_id"`
+	Amount        float64   `json:"amount"` // Saldo que vence (o venció)
+	Currency      string    `json:"currency"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Timestamp     time.Time `json:"timestamp"`
+	SourceService string    `json:"source_service"`
+}
+
+// NewCreditEventID arma el ID de credit.expiring / credit.expired (uno por crédito)
+func NewCreditEventID(eventType string, grantID int64) string {
+	return fmt.Sprintf("%s:%d", eventType, grantID)
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule es una expresión cron de 5 campos: minuto hora día-del-mes mes día-de-la-semana
+//
+// Cada campo acepta "*", un valor ("5"), un rango ("1-5"), un paso ("*/15", "0-30/10") o una
+// lista de los anteriores ("0,30"). El día de la semana va de 0 (domingo) a 6; 7 también es domingo.
+// Como en cron, si se restringen el día del mes y el de la semana alcanza con que coincida uno.
+// También se aceptan @hourly, @daily, @weekly y @monthly. Las fechas se calculan en UTC.
+type CronSchedule struct {
+	expr       string
+	minutes    uint64
+	hours      uint64
+	daysOfMon  uint64
+	months     uint64
+	daysOfWeek uint64
+	anyDom     bool // Día del mes sin restringir ("*")
+	anyDow     bool // Día de la semana sin restringir ("*")
+}
+
+// cronDescriptors son los atajos aceptados por ParseCronSchedule
+var cronDescriptors = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// cronSearchYears limita la búsqueda de la próxima ejecución (una fecha imposible como 30 de febrero no tiene)
+const cronSearchYears = 5
+
+// ParseCronSchedule interpreta una expresión cron (ver CronSchedule)
+func ParseCronSchedule(expr string) (CronSchedule, error) {
+	spec := strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[spec]; ok {
+		spec = descriptor
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return CronSchedule{}, fmt.Errorf("expresión cron %q: se esperan 5 campos", expr)
+	}
+
+	schedule := CronSchedule{
+		expr:   expr,
+		anyDom: fields[2] == "*",
+		anyDow: fields[4] == "*",
+	}
+	ranges := []struct {
+		target   *uint64
+		min, max int
+	}{
+		{&schedule.minutes, 0, 59},
+		{&schedule.hours, 0, 23},
+		{&schedule.daysOfMon, 1, 31},
+		{&schedule.months, 1, 12},
+		{&schedule.daysOfWeek, 0, 7},
+	}
+	for i, r := range ranges {
+		bits, err := parseCronField(fields[i], r.min, r.max)
+		if err != nil {
+			return CronSchedule{}, fmt.Errorf("expresión cron %q: %w", expr, err)
+		}
+		*r.target = bits
+	}
+
+	// 7 es domingo, igual que 0
+	if schedule.daysOfWeek&(1<<7) != 0 {
+		schedule.daysOfWeek |= 1
+	}
+
+	return schedule, nil
+}
+
+// String devuelve la expresión original
+func (c CronSchedule) String() string {
+	return c.expr
+}
+
+// Next devuelve la primera ejecución estrictamente posterior a after (en UTC)
+// Retorna el tiempo cero si no hay ninguna en los próximos años
+func (c CronSchedule) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (c CronSchedule) matchesDay(t time.Time) bool {
+	dom := c.daysOfMon&(1<<uint(t.Day())) != 0
+	dow := c.daysOfWeek&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDom && c.anyDow:
+		return true
+	case c.anyDom:
+		return dow
+	case c.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseCronField convierte un campo en un bitset con los valores permitidos
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			parsed, err := strconv.Atoi(part[i+1:])
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("paso inválido en %q", part)
+			}
+			step = parsed
+		}
+
+		from, to := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err1, err2 error
+			from, err1 = strconv.Atoi(bounds[0])
+			to, err2 = strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("rango inválido %q", rangePart)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("valor inválido %q", rangePart)
+			}
+			from, to = value, value
+			if step > 1 {
+				to = max // "5/15" = desde 5 cada 15
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return 0, fmt.Errorf("%q fuera del rango %d-%d", part, min, max)
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RoleDependent es el rol de las cuentas de menores creadas y gestionadas por un tutor
+const RoleDependent = "dependent"
+
+// AdultAge es la edad a partir de la cual un usuario puede ser tutor (y deja de poder ser dependiente)
+const AdultAge = 18
+
+// Restricciones de una cuenta dependiente
+// Viajan en el JWT (claim "restrictions", junto a "guardian_id") y en GET /internal/users/:id para que
+// trips-api y bookings-api las apliquen sin consultar users-api en cada request
+const (
+	RestrictionNoDriving               = "no_driving"                // No puede publicar viajes como conductor
+	RestrictionBookingRequiresApproval = "booking_requires_approval" // Sus reservas esperan la aprobación del tutor
+)
+
+// DependentRestrictions son las restricciones que se aplican a toda cuenta dependiente
+var DependentRestrictions = []string{RestrictionNoDriving, RestrictionBookingRequiresApproval}
+
+// Acciones de un dependiente que requieren aprobación del tutor
+const GuardianApprovalActionBooking = "booking"
+
+// Estados de una solicitud de aprobación
+// expired no se persiste: es una solicitud pending cuyo expires_at ya pasó
+const (
+	GuardianApprovalPending  = "pending"
+	GuardianApprovalApproved = "approved"
+	GuardianApprovalRejected = "rejected"
+	GuardianApprovalExpired  = "expired"
+)
+
+// GuardianApprovalTTL es el plazo que tiene el tutor para resolver una solicitud
+const GuardianApprovalTTL = 24 * time.Hour
+
+// Acciones de un tutor que quedan registradas en la auditoría (guardian_audit_logs)
+const (
+	GuardianAuditDependentCreated     = "dependent.created"
+	GuardianAuditDependentUpdated     = "dependent.updated"
+	GuardianAuditDependentDeactivated = "dependent.deactivated"
+	GuardianAuditApprovalApproved     = "approval.approved"
+	GuardianAuditApprovalRejected     = "approval.rejected"
+)
+
+// EventTypeGuardianApprovalDecided se publica en users.events cuando el tutor resuelve una solicitud
+const EventTypeGuardianApprovalDecided = "guardian.approval_decided"
+
+// AgeAt calcula la edad en años cumplidos a la fecha at
+func AgeAt(birthdate, at time.Time) int {
+	age := at.Year() - birthdate.Year()
+	if at.Month() < birthdate.Month() || (at.Month() == birthdate.Month() && at.Day() < birthdate.Day()) {
+		age--
+	}
+	return age
+}
+
+// CreateDependentRequest representa los datos para que un tutor cree la cuenta de un menor
+// Teléfono y dirección se copian del tutor
+type CreateDependentRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required,min=8"`
+	Name      string `json:"name" binding:"required"`
+	Lastname  string `json:"lastname" binding:"required"`
+	PhotoURL  string `json:"photo_url"`
+	Sex       string `json:"sex" binding:"required,oneof=hombre mujer otro"`
+	Birthdate string `json:"birthdate" binding:"required"` // Format: YYYY-MM-DD
+}
+
+// CreateGuardianApprovalRequest es la solicitud de aprobación que registra otro servicio
+// (ej: bookings-api al crear la reserva de un dependiente)
+// Es idempotente por (action, resource_id): repetirla retorna la solicitud existente
+type CreateGuardianApprovalRequest struct {
+	DependentID int64  `json:"dependent_id" binding:"required"`
+	Action      string `json:"action" binding:"required,oneof=booking"`
+	ResourceID  string `json:"resource_id" binding:"required,max=64"` // Ej: booking_id
+	TripID      string `json:"trip_id" binding:"max=24"`
+	Details     string `json:"details" binding:"max=500"` // Texto que ve el tutor (origen, destino, fecha)
+}
+
+// GuardianApprovalDecisionRequest es la respuesta del tutor a una solicitud
+type GuardianApprovalDecisionRequest struct {
+	Approve *bool  `json:"approve" binding:"required"`
+	Note    string `json:"note" binding:"max=255"`
+}
+
+// GuardianApprovalDTO representa una solicitud de aprobación
+type GuardianApprovalDTO struct {
+	ID           int64      `json:"id"`
+	GuardianID   int64      `json:"guardian_id"`
+	DependentID  int64      `json:"dependent_id"`
+	Action       string     `json:"action"`
+	ResourceID   string     `json:"resource_id"`
+	TripID       string     `json:"trip_id,omitempty"`
+	Details      string     `json:"details,omitempty"`
+	Status       string     `json:"status"`
+	DecisionNote string     `json:"decision_note,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// GuardianAuditLogDTO representa una acción de un tutor sobre un dependiente
+type GuardianAuditLogDTO struct {
+	ID          int64     `json:"id"`
+	GuardianID  int64     `json:"guardian_id"`
+	DependentID int64     `json:"dependent_id"`
+	Action      string    `json:"action"`
+	Details     string    `json:"details,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GuardianApprovalDecidedEvent es el payload de guardian.approval_decided
+// Lo consume el servicio que pidió la aprobación (ej: bookings-api confirma o rechaza la reserva)
+type GuardianApprovalDecidedEvent struct {
+	EventID       string    `json:"event_id"` // Determinístico: guardian.approval_decided:<approval_id>
+	EventType     string    `json:"event_type"`
+	ApprovalID    int64     `json:"approval_id"`
+	GuardianID    int64     `json:"guardian_id"`
+	DependentID   int64     `json:"dependent_id"`
+	Action        string    `json:"action"`
+	ResourceID    string    `json:"resource_id"`
+	TripID        string    `json:"trip_id,omitempty"`
+	Status        string    `json:"status"` // approved o rejected
+	Timestamp     time.Time `json:"timestamp"`
+	SourceService string    `json:"source_service"`
+}
+
+// NewGuardianApprovalDecidedEventID genera el event_id de la decisión (una sola por solicitud)
+func NewGuardianApprovalDecidedEventID(approvalID int64) string {
+	return fmt.Sprintf("%s:%d", EventTypeGuardianApprovalDecided, approvalID)
+}
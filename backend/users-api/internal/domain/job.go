@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Estados de un job de la cola persistente (tabla jobs)
+const (
+	JobStatusPending   = "pending"   // Esperando su run_at (primer intento o reintento)
+	JobStatusRunning   = "running"   // Tomado por un worker hasta run_at (lease); si vence, otro worker lo retoma
+	JobStatusSucceeded = "succeeded" // El handler terminó sin error
+	JobStatusFailed    = "failed"    // Se agotaron los intentos; un admin puede reencolarlo
+)
+
+// JobStatuses son los estados válidos para filtrar el listado de jobs
+var JobStatuses = []string{JobStatusPending, JobStatusRunning, JobStatusSucceeded, JobStatusFailed}
+
+// Jobs propios del framework
+const (
+	JobTypeCleanup = "jobs.cleanup" // Borra los jobs terminados con éxito más viejos que la retención
+)
+
+// Errores de la cola de jobs (el controller los traduce a códigos HTTP)
+var (
+	ErrJobNotFound      = errors.New("job no encontrado")
+	ErrJobNotRequeuable = errors.New("solo se pueden reencolar jobs fallidos")
+	ErrJobInvalidStatus = errors.New("estado inválido, usar: pending, running, succeeded o failed")
+)
+
+// EnqueueJobOptions son las opciones al encolar un job; los valores cero usan los defaults
+type EnqueueJobOptions struct {
+	RunAt       time.Time // Cuándo correr el job (cero: ahora)
+	MaxAttempts int       // Intentos antes de marcarlo fallido (0: el default de la cola)
+
+	// UniqueKey evita encolar dos veces el mismo trabajo (p. ej. una ejecución programada
+	// encolada por varias instancias): si ya existe un job con la misma clave no se encola otro
+	UniqueKey string
+}
+
+// JobDTO representa un job de la cola
+type JobDTO struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"` // Próximo intento (pending) o fin del lease (running)
+	LeasedBy    string          `json:"leased_by,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+	UniqueKey   string          `json:"unique_key,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// JobListResponse es una página del listado de jobs
+type JobListResponse struct {
+	Jobs  []*JobDTO `json:"jobs"`
+	Total int64     `json:"total"`
+	Page  int       `json:"page"`
+	Limit int       `json:"limit"`
+}
+
+// JobCleanupPayload es el payload de jobs.cleanup
+type JobCleanupPayload struct {
+	RetentionDays int `json:"retention_days"`
+}
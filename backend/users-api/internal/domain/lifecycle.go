@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Eventos de ciclo de vida del usuario (exchange users.events), pensados para campañas de marketing
+const (
+	EventTypeUserRegistered  = "user.registered"   // Campaña de bienvenida
+	EventTypeUserInactive30d = "user.inactive_30d" // Campaña de regreso
+)
+
+// InactivityThreshold es el tiempo sin iniciar sesión a partir del cual un usuario se considera inactivo
+const InactivityThreshold = 30 * 24 * time.Hour
+
+// UserLifecycleEvent es el payload de user.registered y user.inactive_30d
+//
+// Solo lleva los datos mínimos para la campaña: sin apellido, teléfono, dirección
+// ni fecha de nacimiento. Se publica únicamente si el usuario acepta emails de
+// marketing al momento de publicar.
+type UserLifecycleEvent struct {
+	EventID       string     `json:"event_id"` // Determinístico: el consumidor puede deduplicar
+	EventType     string     `json:"event_type"`
+	UserID        int64      `json:"user_id"`
+	FirstName     string     `json:"first_name"`
+	Email         string     `json:"email"`
+	RegisteredAt  time.Time  `json:"registered_at"`
+	LastActiveAt  *time.Time `json:"last_active_at,omitempty"` // Solo en user.inactive_30d
+	Timestamp     time.Time  `json:"timestamp"`
+	SourceService string     `json:"source_service"`
+}
+
+// NewUserRegisteredEventID arma el ID de user.registered (uno por usuario)
+func NewUserRegisteredEventID(userID int64) string {
+	return fmt.Sprintf("%s:%d", EventTypeUserRegistered, userID)
+}
+
+// NewUserInactiveEventID arma el ID de user.inactive_30d (uno por período de inactividad)
+func NewUserInactiveEventID(userID int64, lastActiveAt time.Time) string {
+	return fmt.Sprintf("%s:%d:%d", EventTypeUserInactive30d, userID, lastActiveAt.Unix())
+}
+
+// NotificationPreferencesDTO representa las preferencias de notificación del usuario
+type NotificationPreferencesDTO struct {
+	MarketingEmails bool `json:"marketing_emails"` // Campañas de bienvenida / regreso
+}
+
+// UpdateNotificationPreferencesRequest representa los cambios en las preferencias de notificación
+type UpdateNotificationPreferencesRequest struct {
+	MarketingEmails *bool `json:"marketing_emails" binding:"required"`
+}
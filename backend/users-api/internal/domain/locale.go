@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// Unidades de distancia del perfil (users.distance_unit)
+const (
+	DistanceUnitKilometers = "km"
+	DistanceUnitMiles      = "mi"
+)
+
+// DefaultCountry es el país de los usuarios cuyo locale no indica uno soportado
+const DefaultCountry = "AR"
+
+// CountryDefaults son la moneda y la unidad de distancia por defecto de un país
+type CountryDefaults struct {
+	Currency     string
+	DistanceUnit string
+}
+
+// supportedCountries son los países soportados (ISO 3166-1 alpha-2) y sus valores por defecto
+var supportedCountries = map[string]CountryDefaults{
+	"AR": {Currency: "ARS", DistanceUnit: DistanceUnitKilometers},
+	"BO": {Currency: "BOB", DistanceUnit: DistanceUnitKilometers},
+	"BR": {Currency: "BRL", DistanceUnit: DistanceUnitKilometers},
+	"CL": {Currency: "CLP", DistanceUnit: DistanceUnitKilometers},
+	"CO": {Currency: "COP", DistanceUnit: DistanceUnitKilometers},
+	"EC": {Currency: "USD", DistanceUnit: DistanceUnitKilometers},
+	"ES": {Currency: "EUR", DistanceUnit: DistanceUnitKilometers},
+	"GB": {Currency: "GBP", DistanceUnit: DistanceUnitMiles},
+	"MX": {Currency: "MXN", DistanceUnit: DistanceUnitKilometers},
+	"PE": {Currency: "PEN", DistanceUnit: DistanceUnitKilometers},
+	"PY": {Currency: "PYG", DistanceUnit: DistanceUnitKilometers},
+	"US": {Currency: "USD", DistanceUnit: DistanceUnitMiles},
+	"UY": {Currency: "UYU", DistanceUnit: DistanceUnitKilometers},
+}
+
+// languageCountries es el país de un locale sin región soportada ("pt" → BR); el resto usa DefaultCountry
+var languageCountries = map[string]string{
+	"pt": "BR",
+}
+
+// Errores de la configuración regional del perfil (el controller los traduce a 400)
+var (
+	ErrUnsupportedCountry      = errors.New("país no soportado, usar: " + strings.Join(SupportedCountries(), ", "))
+	ErrUnsupportedCurrency     = errors.New("moneda no soportada, usar: " + strings.Join(SupportedCurrencies(), ", "))
+	ErrUnsupportedDistanceUnit = errors.New("unidad de distancia inválida, usar: km o mi")
+)
+
+// DefaultsForCountry retorna la moneda y la unidad de distancia de un país soportado
+func DefaultsForCountry(country string) (CountryDefaults, bool) {
+	defaults, ok := supportedCountries[strings.ToUpper(country)]
+	return defaults, ok
+}
+
+// SupportedCountries retorna los códigos de país soportados, ordenados
+func SupportedCountries() []string {
+	countries := make([]string, 0, len(supportedCountries))
+	for country := range supportedCountries {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// SupportedCurrencies retorna las monedas de los países soportados (ISO 4217), ordenadas y sin repetir
+func SupportedCurrencies() []string {
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, defaults := range supportedCountries {
+		if !seen[defaults.Currency] {
+			seen[defaults.Currency] = true
+			currencies = append(currencies, defaults.Currency)
+		}
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// IsSupportedCurrency indica si la moneda es la de algún país soportado
+func IsSupportedCurrency(currency string) bool {
+	for _, defaults := range supportedCountries {
+		if defaults.Currency == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDistanceUnit indica si la unidad de distancia es válida
+func IsDistanceUnit(unit string) bool {
+	return unit == DistanceUnitKilometers || unit == DistanceUnitMiles
+}
+
+// CountryFromLocale infiere el país de un locale o de un header Accept-Language ("es-AR,es;q=0.9,en;q=0.8")
+// Se usa la primera región soportada en el orden en que vienen los tags; sin ninguna, el idioma
+// del primer tag (languageCountries) y si no DefaultCountry
+func CountryFromLocale(locale string) string {
+	var firstLanguage string
+	for _, part := range strings.Split(locale, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		subtags := strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' })
+		if firstLanguage == "" {
+			firstLanguage = strings.ToLower(subtags[0])
+		}
+		for _, subtag := range subtags[1:] {
+			if _, ok := DefaultsForCountry(subtag); ok && len(subtag) == 2 {
+				return strings.ToUpper(subtag)
+			}
+		}
+	}
+
+	if country, ok := languageCountries[firstLanguage]; ok {
+		return country
+	}
+	return DefaultCountry
+}
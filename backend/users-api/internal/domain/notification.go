@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Tipos de notificación in-app
+const (
+	NotificationTypeBookingUpdate = "booking_update" // Cambios de estado de reservas
+	NotificationTypeChatMention   = "chat_mention"   // Menciones en el chat de un viaje
+	NotificationTypeSystem        = "system"         // Mensajes enviados por un administrador
+
+	NotificationTypeGuardianApproval = "guardian_approval" // Solicitudes de aprobación de un dependiente a su tutor
+	NotificationTypeCredit           = "credit"            // Créditos promocionales por vencer o vencidos
+)
+
+// NotificationDTO representa una notificación in-app en el dominio de negocio
+type NotificationDTO struct {
+	ID        int64      `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message,omitempty"`
+	TripID    string     `json:"trip_id,omitempty"`
+	BookingID string     `json:"booking_id,omitempty"`
+	IsRead    bool       `json:"is_read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewNotification contiene los datos para crear una notificación
+// EventID identifica el evento de RabbitMQ que la originó (vacío para mensajes de sistema)
+type NewNotification struct {
+	UserID    int64
+	Type      string
+	Title     string
+	Message   string
+	TripID    string
+	BookingID string
+	EventID   string
+}
+
+// CreateSystemNotificationRequest representa los datos para enviar un mensaje de sistema a un usuario
+type CreateSystemNotificationRequest struct {
+	Title   string `json:"title" binding:"required,max=255"`
+	Message string `json:"message" binding:"required"`
+}
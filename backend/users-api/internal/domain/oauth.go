@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Proveedores de login social soportados (columna users.provider)
+const (
+	OAuthProviderGoogle = "google"
+)
+
+// OAuthStateTTL es la vigencia del state de GET /auth/:provider: el usuario tiene ese tiempo para
+// volver del proveedor al callback
+const OAuthStateTTL = 10 * time.Minute
+
+// OAuthIdentity es el usuario que informa el proveedor después de canjear el código
+type OAuthIdentity struct {
+	Provider      string
+	ProviderID    string // ID estable del usuario en el proveedor (sub de Google)
+	Email         string
+	EmailVerified bool // El proveedor confirmó que el usuario es dueño del email
+	Name          string
+	Lastname      string
+	PhotoURL      string
+}
+
+// Errores del login social (el controller los traduce a códigos HTTP)
+var (
+	ErrOAuthProviderUnknown  = errors.New("proveedor de login no soportado")
+	ErrOAuthStateInvalid     = errors.New("state inválido o vencido, vuelve a iniciar sesión")
+	ErrOAuthCodeInvalid      = errors.New("no se pudo validar el login con el proveedor")
+	ErrOAuthEmailNotVerified = errors.New("el proveedor no confirmó el email de la cuenta")
+	ErrOAuthAccountLinked    = errors.New("el email ya está vinculado a otra cuenta del proveedor")
+)
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Eventos del ciclo de vida del usuario que un partner puede suscribir
+const (
+	WebhookEventUserCreated = "user.created"
+	WebhookEventUserUpdated = "user.updated"
+	WebhookEventUserDeleted = "user.deleted"
+)
+
+// WebhookEventTypes son los eventos válidos para una suscripción
+var WebhookEventTypes = []string{WebhookEventUserCreated, WebhookEventUserUpdated, WebhookEventUserDeleted}
+
+// Estados de una entrega de webhook
+const (
+	WebhookDeliveryPending   = "pending"   // Pendiente de enviar o esperando un reintento
+	WebhookDeliverySucceeded = "succeeded" // El partner respondió 2xx
+	WebhookDeliveryFailed    = "failed"    // Se agotaron los intentos (o se revocó la autorización)
+)
+
+// Headers de las entregas de webhook
+// La firma es HMAC-SHA256 con el secreto del webhook sobre "<timestamp>.<body>": t=<timestamp>,v1=<hex>
+const (
+	WebhookHeaderSignature = "X-CarPooling-Signature"
+	WebhookHeaderEvent     = "X-CarPooling-Event"
+	WebhookHeaderDelivery  = "X-CarPooling-Delivery"
+	WebhookHeaderTimestamp = "X-CarPooling-Timestamp"
+)
+
+// Errores de los webhooks de partners (el controller los traduce a códigos HTTP)
+var (
+	ErrWebhookNotFound         = errors.New("webhook no encontrado")
+	ErrWebhookDeliveryNotFound = errors.New("entrega no encontrada")
+	ErrWebhookInvalidURL       = errors.New("la URL del webhook debe ser https y apuntar a un host público")
+	ErrWebhookInvalidEvent     = errors.New("tipo de evento inválido, usar: user.created, user.updated o user.deleted")
+	ErrWebhookLimitReached     = errors.New("se alcanzó el máximo de webhooks del partner")
+	ErrWebhookDeliveryNotRetry = errors.New("solo se pueden reintentar entregas fallidas")
+	ErrPartnerNotFound         = errors.New("partner no encontrado")
+	ErrPartnerNotAuthorized    = errors.New("el partner no está autorizado")
+)
+
+// CreateWebhookRequest es la suscripción de una URL del partner a eventos de usuario
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url,max=500"`
+	Events []string `json:"events" binding:"required,min=1,max=3,dive,required"`
+}
+
+// UpdateWebhookRequest modifica una suscripción; los campos omitidos no cambian
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" binding:"omitempty,url,max=500"`
+	Events []string `json:"events" binding:"omitempty,min=1,max=3,dive,required"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookDTO es un webhook del partner sin su secreto
+type WebhookDTO struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookWithSecretDTO incluye el secreto de firma en claro: solo al crear el webhook o rotar el secreto
+type WebhookWithSecretDTO struct {
+	Webhook *WebhookDTO `json:"webhook"`
+	Secret  string      `json:"secret"`
+}
+
+// WebhookDeliveryDTO es una entrega del log de un webhook
+type WebhookDeliveryDTO struct {
+	ID             int64      `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	UserID         int64      `json:"user_id"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"` // Solo mientras está pendiente
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookDeliveryListResponse es una página del log de entregas
+type WebhookDeliveryListResponse struct {
+	Deliveries []*WebhookDeliveryDTO `json:"deliveries"`
+	Total      int64                 `json:"total"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+}
+
+// AuthorizedPartnerDTO es un partner autorizado por el usuario a recibir sus eventos
+type AuthorizedPartnerDTO struct {
+	PartnerID    int64     `json:"partner_id"`
+	Name         string    `json:"name"`
+	AuthorizedAt time.Time `json:"authorized_at"`
+}
+
+// WebhookEvent es el cuerpo que recibe el partner
+// El mismo event_id se repite en los reintentos: el partner lo usa para descartar duplicados
+type WebhookEvent struct {
+	EventID    string           `json:"event_id"`
+	EventType  string           `json:"event_type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       WebhookEventUser `json:"data"`
+}
+
+// WebhookEventUser son los datos del usuario en el evento
+// user.deleted solo incluye los identificadores; external_id solo se envía al partner que provisionó al usuario
+type WebhookEventUser struct {
+	UserID     int64   `json:"user_id"`
+	ExternalID *string `json:"external_id,omitempty"`
+	Email      string  `json:"email,omitempty"`
+	Name       string  `json:"name,omitempty"`
+	Lastname   string  `json:"lastname,omitempty"`
+	Active     *bool   `json:"active,omitempty"`
+}
+
+// WebhookRunResult resume una corrida del dispatcher de webhooks
+type WebhookRunResult struct {
+	Delivered int `json:"delivered"`
+	Retrying  int `json:"retrying"` // Fallaron y quedaron programadas para otro intento
+	Failed    int `json:"failed"`   // Agotaron los intentos
+}
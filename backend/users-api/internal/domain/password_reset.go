@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// PasswordResetTokenTTL es la vigencia del link de POST /forgot-password
+const PasswordResetTokenTTL = time.Hour
+
+// Límite de emails de restablecimiento por usuario (además del rate limiting por IP y por email de la ruta)
+// Al superarlo la request responde igual que siempre, para no revelar si el email existe
+const (
+	MaxPasswordResetRequests = 3
+	PasswordResetWindow      = time.Hour
+)
+
+// Errores del restablecimiento de contraseña (el controller los traduce a códigos HTTP)
+var (
+	ErrPasswordResetTokenInvalid = errors.New("token de reset inválido")
+	ErrPasswordResetTokenExpired = errors.New("el token de reset expiró, solicita un nuevo email para restablecer tu contraseña")
+	ErrPasswordResetTokenUsed    = errors.New("el token de reset ya fue usado")
+)
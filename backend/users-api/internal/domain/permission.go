@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"encoding/base64"
+	"sort"
+)
+
+// Permisos de la plataforma
+// Los servicios validan permisos (no roles): un rol es solo un conjunto de permisos por defecto
+const (
+	PermissionTripsCreate      = "trips:create"        // Publicar viajes como conductor
+	PermissionBookingsCreate   = "bookings:create"     // Reservar asientos
+	PermissionRatingsCreate    = "ratings:create"      // Calificar a otros usuarios
+	PermissionRatingsModerate  = "ratings:moderate"    // Ver el historial de ediciones de cualquier calificación
+	PermissionDependentsManage = "dependents:manage"   // Ser tutor de cuentas dependientes
+	PermissionAccountDelete    = "account:delete"      // Borrar la propia cuenta
+	PermissionAdminUsers       = "admin:users"         // Listar, editar, borrar, suspender y forzar re-autenticación de cualquier usuario
+	PermissionAdminNotify      = "admin:notifications" // Enviar mensajes de sistema
+	PermissionAdminSecurity    = "admin:security"      // Ver la distribución de scores de seguridad
+	PermissionAdminPartners    = "admin:partners"      // Gestionar partners y sus API keys SCIM
+	PermissionAdminGuardians   = "admin:guardians"     // Ver la auditoría de tutores
+	PermissionAdminPermissions = "admin:permissions"   // Gestionar los permisos de los usuarios
+	PermissionAdminCredits     = "admin:credits"       // Otorgar créditos promocionales y ver el saldo de cualquier usuario
+	PermissionAdminJobs        = "admin:jobs"          // Ver la cola de jobs y reencolar jobs fallidos
+)
+
+// PermissionRegistry es el registro de permisos; la posición de cada permiso es su bit en el claim "perms" del JWT
+// Solo se agregan permisos al final: cambiar el orden cambiaría el significado de los tokens ya emitidos
+var PermissionRegistry = []string{
+	PermissionTripsCreate,
+	PermissionBookingsCreate,
+	PermissionRatingsCreate,
+	PermissionRatingsModerate,
+	PermissionDependentsManage,
+	PermissionAccountDelete,
+	PermissionAdminUsers,
+	PermissionAdminNotify,
+	PermissionAdminSecurity,
+	PermissionAdminPartners,
+	PermissionAdminGuardians,
+	PermissionAdminPermissions,
+	PermissionAdminCredits,
+	PermissionAdminJobs,
+}
+
+// RolePermissions son los permisos por defecto de cada rol
+// Un dependiente no puede conducir, ser tutor ni borrar su cuenta (ver DependentRestrictions)
+var RolePermissions = map[string][]string{
+	"user": {
+		PermissionTripsCreate,
+		PermissionBookingsCreate,
+		PermissionRatingsCreate,
+		PermissionDependentsManage,
+		PermissionAccountDelete,
+	},
+	RoleDependent: {
+		PermissionBookingsCreate,
+		PermissionRatingsCreate,
+	},
+	"admin": PermissionRegistry,
+}
+
+// PermissionSet es un conjunto de permisos
+type PermissionSet map[string]bool
+
+// IsKnownPermission indica si el permiso está en el registro
+func IsKnownPermission(permission string) bool {
+	return permissionBit(permission) >= 0
+}
+
+// EffectivePermissions calcula los permisos de un usuario: los de su rol más los otorgados,
+// menos los revocados (una revocación gana sobre el rol y sobre un otorgamiento)
+func EffectivePermissions(role string, granted, revoked []string) PermissionSet {
+	set := PermissionSet{}
+	for _, permission := range RolePermissions[role] {
+		set[permission] = true
+	}
+	for _, permission := range granted {
+		if IsKnownPermission(permission) {
+			set[permission] = true
+		}
+	}
+	for _, permission := range revoked {
+		delete(set, permission)
+	}
+	return set
+}
+
+// Has indica si el conjunto contiene el permiso
+func (s PermissionSet) Has(permission string) bool {
+	return s[permission]
+}
+
+// List retorna los permisos ordenados alfabéticamente
+func (s PermissionSet) List() []string {
+	list := make([]string, 0, len(s))
+	for permission := range s {
+		list = append(list, permission)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// EncodePermissions codifica el conjunto como bitmap (bit i = PermissionRegistry[i]) en base64url sin padding
+// Ej: los permisos de "user" ocupan 3 caracteres en el JWT en lugar de una lista de strings
+func EncodePermissions(set PermissionSet) string {
+	bits := make([]byte, (len(PermissionRegistry)+7)/8)
+	for permission := range set {
+		if i := permissionBit(permission); i >= 0 {
+			bits[i/8] |= 1 << (i % 8)
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(bits)
+}
+
+// DecodePermissions decodifica el claim "perms"; los bits que no están en el registro se ignoran
+// (tokens emitidos por una versión más nueva del registro)
+func DecodePermissions(encoded string) (PermissionSet, error) {
+	bits, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	set := PermissionSet{}
+	for i, permission := range PermissionRegistry {
+		if i/8 < len(bits) && bits[i/8]&(1<<(i%8)) != 0 {
+			set[permission] = true
+		}
+	}
+	return set, nil
+}
+
+// PermissionOverridesRequest reemplaza los permisos individuales de un usuario
+type PermissionOverridesRequest struct {
+	Grant  []string `json:"grant"`
+	Revoke []string `json:"revoke"`
+}
+
+// PermissionDefinition describe un permiso del registro
+type PermissionDefinition struct {
+	Name string `json:"name"`
+	Bit  int    `json:"bit"`
+}
+
+// PermissionRegistryDTO es el registro de permisos con los permisos de cada rol
+type PermissionRegistryDTO struct {
+	Permissions []PermissionDefinition `json:"permissions"`
+	Roles       map[string][]string    `json:"roles"`
+}
+
+// UserPermissionsDTO son los permisos de un usuario
+// Una revocación se aplica en la próxima request (el JWT emitido antes deja de valer);
+// un permiso otorgado se aplica desde el próximo login
+type UserPermissionsDTO struct {
+	UserID    int64    `json:"user_id"`
+	Role      string   `json:"role"`
+	Granted   []string `json:"granted"`
+	Revoked   []string `json:"revoked"`
+	Effective []string `json:"effective"`
+}
+
+func permissionBit(permission string) int {
+	for i, registered := range PermissionRegistry {
+		if registered == permission {
+			return i
+		}
+	}
+	return -1
+}
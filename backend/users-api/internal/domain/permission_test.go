@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodePermissions_RoundTrip(t *testing.T) {
+	for role := range RolePermissions {
+		set := EffectivePermissions(role, nil, nil)
+
+		decoded, err := DecodePermissions(EncodePermissions(set))
+		require.NoError(t, err, role)
+		assert.Equal(t, set, decoded, role)
+	}
+}
+
+func TestEncodePermissions_Bitmap(t *testing.T) {
+	// Bits 0-2 y 4-5 del primer byte (trips:create ... account:delete sin ratings:moderate)
+	assert.Equal(t, "NwA", EncodePermissions(EffectivePermissions("user", nil, nil)))
+	assert.Equal(t, "AAA", EncodePermissions(PermissionSet{}))
+
+	// Un permiso fuera del registro no ocupa ningún bit
+	assert.Equal(t, "AQA", EncodePermissions(PermissionSet{PermissionTripsCreate: true, "unknown:perm": true}))
+
+	// El bit 8 es el primero del segundo byte
+	assert.Equal(t, "AAE", EncodePermissions(PermissionSet{PermissionRegistry[8]: true}))
+}
+
+func TestDecodePermissions_IgnoresUnknownBitsAndShortBitmaps(t *testing.T) {
+	// Bits más allá del registro (token de una versión más nueva) se ignoran
+	set, err := DecodePermissions("____")
+	require.NoError(t, err)
+	assert.Len(t, set, len(PermissionRegistry))
+
+	// Un bitmap más corto que el registro: los permisos que faltan no están
+	set, err = DecodePermissions("AQ")
+	require.NoError(t, err)
+	assert.Equal(t, PermissionSet{PermissionTripsCreate: true}, set)
+
+	_, err = DecodePermissions("no es base64!")
+	assert.Error(t, err)
+}
+
+func TestEffectivePermissions_RevocationWins(t *testing.T) {
+	set := EffectivePermissions("user", []string{PermissionAdminPartners, "unknown:perm"}, []string{PermissionTripsCreate, PermissionAdminPartners})
+
+	assert.False(t, set.Has(PermissionTripsCreate))
+	assert.False(t, set.Has(PermissionAdminPartners))
+	assert.False(t, set.Has("unknown:perm"))
+	assert.True(t, set.Has(PermissionBookingsCreate))
+}
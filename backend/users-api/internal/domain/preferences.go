@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"regexp"
+	"time"
+)
+
+// LanguageCodePattern valida el idioma preferido: código ISO 639-1 con región opcional ("es", "pt-BR")
+var LanguageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,4})?$`)
+
+// SearchFiltersDTO son los filtros de búsqueda por defecto del usuario
+// nil significa sin preferencia: el frontend y search-api no aplican ese filtro
+type SearchFiltersDTO struct {
+	PetsAllowed    *bool    `json:"pets_allowed"`
+	SmokingAllowed *bool    `json:"smoking_allowed"`
+	MusicAllowed   *bool    `json:"music_allowed"`
+	MaxPrice       *float64 `json:"max_price"`
+}
+
+// NotificationSettingsDTO indica qué notificaciones in-app quiere recibir el usuario
+type NotificationSettingsDTO struct {
+	BookingUpdates bool `json:"booking_updates"`
+	ChatMentions   bool `json:"chat_mentions"`
+}
+
+// Allows indica si el usuario quiere recibir notificaciones de ese tipo
+// Solo se pueden desactivar booking_update y chat_mention; el resto se envía siempre
+func (n NotificationSettingsDTO) Allows(notificationType string) bool {
+	switch notificationType {
+	case NotificationTypeBookingUpdate:
+		return n.BookingUpdates
+	case NotificationTypeChatMention:
+		return n.ChatMentions
+	default:
+		return true
+	}
+}
+
+// ChatSettingsDTO son las preferencias del chat de viaje
+// trips-api las lee para traducir los mensajes sin que el usuario lo pida cada vez
+type ChatSettingsDTO struct {
+	PreferredLanguage string `json:"preferred_language"` // Vacío: sin idioma preferido
+	AutoTranslate     bool   `json:"auto_translate"`
+}
+
+// UserPreferencesDTO representa el perfil de preferencias del usuario
+type UserPreferencesDTO struct {
+	UserID        int64                   `json:"user_id"`
+	SearchFilters SearchFiltersDTO        `json:"search_filters"`
+	Notifications NotificationSettingsDTO `json:"notifications"`
+	Chat          ChatSettingsDTO         `json:"chat"`
+	UpdatedAt     *time.Time              `json:"updated_at"` // nil mientras el usuario no guardó sus preferencias
+}
+
+// DefaultUserPreferences devuelve las preferencias de un usuario que nunca las guardó
+func DefaultUserPreferences(userID int64) *UserPreferencesDTO {
+	return &UserPreferencesDTO{
+		UserID:        userID,
+		Notifications: NotificationSettingsDTO{BookingUpdates: true, ChatMentions: true},
+	}
+}
+
+// UpdateNotificationSettingsRequest representa los cambios en las notificaciones (nil: activa)
+type UpdateNotificationSettingsRequest struct {
+	BookingUpdates *bool `json:"booking_updates"`
+	ChatMentions   *bool `json:"chat_mentions"`
+}
+
+// UpdateUserPreferencesRequest reemplaza el perfil de preferencias completo (PUT)
+// Un filtro omitido queda sin preferencia, una notificación omitida queda activa y el chat omitido
+// queda sin idioma ni traducción automática
+type UpdateUserPreferencesRequest struct {
+	SearchFilters SearchFiltersDTO                  `json:"search_filters"`
+	Notifications UpdateNotificationSettingsRequest `json:"notifications"`
+	Chat          ChatSettingsDTO                   `json:"chat"`
+}
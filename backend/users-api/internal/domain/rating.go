@@ -1,17 +1,22 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // RatingDTO representa una calificación en el dominio de negocio
 type RatingDTO struct {
-	ID          int64     `json:"id"`
-	RaterID     int64     `json:"rater_id"`
-	RatedUserID int64     `json:"rated_user_id"`
-	TripID      string    `json:"trip_id"`
-	RoleRated   string    `json:"role_rated"`
-	Score       int       `json:"score"`
-	Comment     string    `json:"comment,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID          int64      `json:"id"`
+	RaterID     int64      `json:"rater_id"`
+	RatedUserID int64      `json:"rated_user_id"`
+	TripID      string     `json:"trip_id"`
+	RoleRated   string     `json:"role_rated"`
+	Score       int        `json:"score"`
+	Comment     string     `json:"comment,omitempty"`
+	EditCount   int        `json:"edit_count"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
 
 // CreateRatingRequest representa los datos necesarios para crear una calificación
@@ -23,3 +28,113 @@ type CreateRatingRequest struct {
 	Score       int    `json:"score" binding:"required,min=1,max=5"`
 	Comment     string `json:"comment"`
 }
+
+// DriverProfileCommentsLimit es la cantidad de comentarios recientes incluidos en el perfil de conductor
+const DriverProfileCommentsLimit = 5
+
+// DriverCommentDTO es un comentario recibido como conductor (sin datos de quien calificó)
+type DriverCommentDTO struct {
+	Score     int       `json:"score"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DriverProfileDTO reúne en una sola respuesta lo que otros servicios desnormalizan del conductor
+// (GET /users/:id/driver-profile). Las estadísticas se calculan sobre las calificaciones como conductor
+type DriverProfileDTO struct {
+	ID             int64              `json:"id"`
+	Name           string             `json:"name"`
+	PhotoURL       string             `json:"photo_url,omitempty"`
+	Rating         float64            `json:"rating"`       // Promedio de calificaciones como conductor
+	RatingCount    int                `json:"rating_count"` // Calificaciones recibidas como conductor
+	TotalTrips     int                `json:"total_trips"`  // Viajes realizados como conductor
+	RecentComments []DriverCommentDTO `json:"recent_comments"`
+}
+
+// Cuota de ediciones de una calificación por su autor
+const (
+	RatingEditWindow = 72 * time.Hour // Desde la creación de la calificación
+	MaxRatingEdits   = 2
+)
+
+// Eventos de calificaciones publicados en users.events
+const (
+	EventTypeRatingUpdated     = "rating.updated"      // Al editar una calificación
+	EventTypeUserRatingUpdated = "user.rating_updated" // Al cambiar los promedios de un usuario (calificación creada o editada)
+)
+
+// UpdateRatingRequest representa la edición de una calificación por su autor
+// Comment nil mantiene el comentario actual, "" lo borra
+type UpdateRatingRequest struct {
+	Score   int     `json:"score" binding:"required,min=1,max=5"`
+	Comment *string `json:"comment"`
+}
+
+// RatingEditDTO representa una versión anterior de una calificación
+type RatingEditDTO struct {
+	Version    int       `json:"version"` // 0 = la original
+	Score      int       `json:"score"`
+	Comment    string    `json:"comment,omitempty"`
+	EditedBy   int64     `json:"edited_by"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+// RatingHistoryDTO es la calificación actual con sus versiones anteriores (solo admin)
+type RatingHistoryDTO struct {
+	Rating RatingDTO       `json:"rating"`
+	Edits  []RatingEditDTO `json:"edits"` // Ordenadas de la más antigua a la más reciente
+}
+
+// RatingUpdatedEvent es el payload de rating.updated
+//
+// Lleva los promedios recalculados del usuario calificado para que los servicios
+// que los tienen desnormalizados (ej: driver.rating en search-api) los actualicen
+// sin volver a consultar users-api.
+type RatingUpdatedEvent struct {
+	EventID             string    `json:"event_id"` // Determinístico: rating.updated:<rating_id>:<edit_count>
+	EventType           string    `json:"event_type"`
+	RatingID            int64     `json:"rating_id"`
+	RaterID             int64     `json:"rater_id"`
+	RatedUserID         int64     `json:"rated_user_id"`
+	TripID              string    `json:"trip_id"`
+	RoleRated           string    `json:"role_rated"`
+	PreviousScore       int       `json:"previous_score"`
+	Score               int       `json:"score"`
+	EditCount           int       `json:"edit_count"`
+	AvgDriverRating     float64   `json:"avg_driver_rating"`
+	AvgPassengerRating  float64   `json:"avg_passenger_rating"`
+	TotalTripsDriver    int       `json:"total_trips_driver"`
+	TotalTripsPassenger int       `json:"total_trips_passenger"`
+	Timestamp           time.Time `json:"timestamp"`
+	SourceService       string    `json:"source_service"`
+}
+
+// NewRatingUpdatedEventID arma el ID de rating.updated (uno por edición)
+func NewRatingUpdatedEventID(ratingID int64, editCount int) string {
+	return fmt.Sprintf("%s:%d:%d", EventTypeRatingUpdated, ratingID, editCount)
+}
+
+// UserRatingUpdatedEvent es el payload de user.rating_updated
+//
+// Lleva los promedios y totales del usuario después de cada calificación creada o editada,
+// para que search-api actualice los datos del conductor indexados sin consultar users-api.
+// Timestamp es el momento del recálculo: un consumidor descarta los eventos más viejos
+// que el último aplicado (pueden llegar desordenados).
+type UserRatingUpdatedEvent struct {
+	EventID             string    `json:"event_id"` // Determinístico: user.rating_updated:<user_id>:<rating_id>:<edit_count>
+	EventType           string    `json:"event_type"`
+	UserID              int64     `json:"user_id"`
+	RatingID            int64     `json:"rating_id"`  // Calificación que disparó el recálculo
+	RoleRated           string    `json:"role_rated"` // Rol cuyo promedio cambió: conductor o pasajero
+	AvgDriverRating     float64   `json:"avg_driver_rating"`
+	AvgPassengerRating  float64   `json:"avg_passenger_rating"`
+	TotalTripsDriver    int       `json:"total_trips_driver"`
+	TotalTripsPassenger int       `json:"total_trips_passenger"`
+	Timestamp           time.Time `json:"timestamp"`
+	SourceService       string    `json:"source_service"`
+}
+
+// NewUserRatingUpdatedEventID arma el ID de user.rating_updated (uno por calificación creada o editada)
+func NewUserRatingUpdatedEventID(userID, ratingID int64, editCount int) string {
+	return fmt.Sprintf("%s:%d:%d:%d", EventTypeUserRatingUpdated, userID, ratingID, editCount)
+}
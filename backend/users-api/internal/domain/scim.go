@@ -0,0 +1,310 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Esquemas SCIM 2.0 (RFC 7643 / RFC 7644) usados por la API de provisión /scim/v2
+const (
+	SCIMSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Paginación de los listados SCIM (startIndex empieza en 1)
+const (
+	SCIMDefaultCount = 100
+	SCIMMaxCount     = 200
+)
+
+// SCIMUser es un usuario en formato SCIM (subconjunto del esquema core)
+// userName es el email del usuario en CarPooling
+type SCIMUser struct {
+	Schemas      []string          `json:"schemas"`
+	ID           string            `json:"id,omitempty"`
+	ExternalID   string            `json:"externalId,omitempty"`
+	UserName     string            `json:"userName"`
+	Name         SCIMName          `json:"name"`
+	Emails       []SCIMMultiValued `json:"emails,omitempty"`
+	PhoneNumbers []SCIMMultiValued `json:"phoneNumbers,omitempty"`
+	Active       *bool             `json:"active,omitempty"` // Ausente al crear: true
+	Groups       []SCIMMemberRef   `json:"groups,omitempty"` // Solo lectura
+	Meta         *SCIMMeta         `json:"meta,omitempty"`
+}
+
+// SCIMName es el nombre de un usuario SCIM
+type SCIMName struct {
+	GivenName  string `json:"givenName"`
+	FamilyName string `json:"familyName"`
+}
+
+// SCIMMultiValued es un atributo multivaluado (emails, phoneNumbers)
+type SCIMMultiValued struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMemberRef referencia a un miembro de un grupo (o a un grupo desde un usuario)
+type SCIMMemberRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// SCIMGroup es una organización del partner en formato SCIM
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	DisplayName string          `json:"displayName"`
+	Members     []SCIMMemberRef `json:"members"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+// SCIMMeta son los metadatos de un recurso SCIM
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// SCIMListResponse es la respuesta de un listado SCIM
+type SCIMListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// SCIMPatchRequest es el body de PATCH /Users/:id y PATCH /Groups/:id
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations" binding:"required,min=1"`
+}
+
+// SCIMPatchOperation es una operación de un PATCH SCIM (op: add, remove o replace)
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// SCIMError es el formato de error de SCIM (status va como string según el RFC)
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// SCIMListQuery son los parámetros de un listado SCIM ya validados
+type SCIMListQuery struct {
+	Filter     []SCIMFilterCondition
+	StartIndex int
+	Count      int
+}
+
+// SCIMFilterCondition es una comparación de un filtro SCIM (attr op "valor")
+// Attribute queda en minúsculas: los nombres de atributos SCIM no distinguen mayúsculas
+type SCIMFilterCondition struct {
+	Attribute string
+	Operator  string // eq, sw (empieza con) o co (contiene)
+	Value     string
+}
+
+// ErrInvalidSCIMFilter se devuelve ante un filtro con sintaxis o atributos no soportados
+var ErrInvalidSCIMFilter = errors.New("filtro inválido")
+
+// ParseSCIMFilter interpreta el subconjunto de filtros SCIM soportado:
+//
+//	attr op valor [and attr op valor ...]
+//
+// con op eq, sw o co, y valor entre comillas dobles o true/false.
+// allowed son los atributos filtrables (en minúsculas). No soporta or, not ni paréntesis.
+func ParseSCIMFilter(filter string, allowed map[string]bool) ([]SCIMFilterCondition, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	tokens, err := tokenizeSCIMFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []SCIMFilterCondition
+	for i := 0; i < len(tokens); {
+		if len(tokens)-i < 3 {
+			return nil, ErrInvalidSCIMFilter
+		}
+
+		attribute := strings.ToLower(tokens[i].text)
+		operator := strings.ToLower(tokens[i+1].text)
+		value := tokens[i+2]
+		if tokens[i].quoted || tokens[i+1].quoted || !allowed[attribute] {
+			return nil, ErrInvalidSCIMFilter
+		}
+		if operator != "eq" && operator != "sw" && operator != "co" {
+			return nil, ErrInvalidSCIMFilter
+		}
+		if !value.quoted && value.text != "true" && value.text != "false" {
+			return nil, ErrInvalidSCIMFilter
+		}
+		conditions = append(conditions, SCIMFilterCondition{Attribute: attribute, Operator: operator, Value: value.text})
+
+		i += 3
+		if i < len(tokens) {
+			if tokens[i].quoted || !strings.EqualFold(tokens[i].text, "and") {
+				return nil, ErrInvalidSCIMFilter
+			}
+			i++
+			if i == len(tokens) {
+				return nil, ErrInvalidSCIMFilter
+			}
+		}
+	}
+
+	return conditions, nil
+}
+
+type scimFilterToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeSCIMFilter separa el filtro en palabras y strings entre comillas (con escapes \" y \\)
+func tokenizeSCIMFilter(filter string) ([]scimFilterToken, error) {
+	var tokens []scimFilterToken
+	runes := []rune(filter)
+
+	for i := 0; i < len(runes); {
+		switch {
+		case runes[i] == ' ':
+			i++
+		case runes[i] == '"':
+			var value strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					value.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, ErrInvalidSCIMFilter
+			}
+			tokens = append(tokens, scimFilterToken{text: value.String(), quoted: true})
+		case runes[i] == '(' || runes[i] == ')':
+			return nil, ErrInvalidSCIMFilter
+		default:
+			start := i
+			for i < len(runes) && runes[i] != ' ' && runes[i] != '"' {
+				i++
+			}
+			tokens = append(tokens, scimFilterToken{text: string(runes[start:i])})
+		}
+	}
+
+	return tokens, nil
+}
+
+// CreatePartnerRequest representa el alta de un partner corporativo (solo admin)
+// VerifiedDomains son los dominios de email que el admin verificó como propios del partner
+type CreatePartnerRequest struct {
+	Name            string   `json:"name" binding:"required,max=100"`
+	VerifiedDomains []string `json:"verified_domains" binding:"max=20"`
+}
+
+// UpdatePartnerDomainsRequest reemplaza los dominios verificados de un partner (solo admin)
+type UpdatePartnerDomainsRequest struct {
+	VerifiedDomains []string `json:"verified_domains" binding:"required,max=20"`
+}
+
+// ErrInvalidEmailDomain indica un dominio mal formado o de un proveedor de email público
+var ErrInvalidEmailDomain = errors.New("dominio de email inválido")
+
+// publicEmailDomains no pueden verificarse como dominio de un partner: cualquiera puede tener una casilla ahí
+var publicEmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"hotmail.com":    true,
+	"outlook.com":    true,
+	"live.com":       true,
+	"yahoo.com":      true,
+	"icloud.com":     true,
+	"proton.me":      true,
+	"protonmail.com": true,
+}
+
+// NormalizeEmailDomains valida los dominios verificados de un partner y los retorna en minúsculas y sin duplicados
+func NormalizeEmailDomains(domains []string) ([]string, error) {
+	seen := make(map[string]bool, len(domains))
+	result := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d == "" || !strings.Contains(d, ".") || strings.ContainsAny(d, "@, ") ||
+			strings.HasPrefix(d, ".") || strings.HasSuffix(d, ".") || publicEmailDomains[d] {
+			return nil, ErrInvalidEmailDomain
+		}
+		if !seen[d] {
+			seen[d] = true
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// EmailDomain retorna el dominio de un email en minúsculas ("" si no tiene @)
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// EmailInDomains indica si el email pertenece a alguno de los dominios (o a un subdominio de ellos)
+func EmailInDomains(email string, domains []string) bool {
+	domain := EmailDomain(email)
+	if domain == "" {
+		return false
+	}
+	for _, d := range domains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// PartnerDTO representa un partner sin su API key
+type PartnerDTO struct {
+	ID              int64      `json:"id"`
+	Name            string     `json:"name"`
+	APIKeyPrefix    string     `json:"api_key_prefix"`
+	Active          bool       `json:"active"`
+	VerifiedDomains []string   `json:"verified_domains"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// CreatePartnerResponse incluye la API key en claro: se muestra una única vez
+type CreatePartnerResponse struct {
+	Partner *PartnerDTO `json:"partner"`
+	APIKey  string      `json:"api_key"`
+}
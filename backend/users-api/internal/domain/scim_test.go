@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmailDomains(t *testing.T) {
+	domains, err := NormalizeEmailDomains([]string{" Acme.com ", "@acme.com", "ventas.acme.com.ar"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"acme.com", "ventas.acme.com.ar"}, domains)
+}
+
+func TestNormalizeEmailDomains_Empty(t *testing.T) {
+	domains, err := NormalizeEmailDomains(nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, domains)
+}
+
+func TestNormalizeEmailDomains_Invalid(t *testing.T) {
+	for _, d := range []string{"", "localhost", "acme.com,evil.com", "user@acme.com", ".acme.com", "acme.", "Gmail.com", "outlook.com"} {
+		_, err := NormalizeEmailDomains([]string{d})
+		assert.ErrorIs(t, err, ErrInvalidEmailDomain, d)
+	}
+}
+
+func TestEmailInDomains(t *testing.T) {
+	domains := []string{"acme.com"}
+
+	assert.True(t, EmailInDomains("ana@acme.com", domains))
+	assert.True(t, EmailInDomains("Ana@ACME.com", domains))
+	assert.True(t, EmailInDomains("ana@ventas.acme.com", domains))
+	assert.False(t, EmailInDomains("ana@notacme.com", domains))
+	assert.False(t, EmailInDomains("ana@acme.com.evil.io", domains))
+	assert.False(t, EmailInDomains("ana@gmail.com", domains))
+	assert.False(t, EmailInDomains("ana", domains))
+	assert.False(t, EmailInDomains("ana@acme.com", nil))
+}
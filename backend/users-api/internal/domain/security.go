@@ -0,0 +1,136 @@
+package domain
+
+import "time"
+
+// MaxPasswordAgeDays es la antigüedad máxima de la contraseña para considerarla reciente
+const MaxPasswordAgeDays = 180
+
+// Factores de seguridad de una cuenta
+// La verificación en dos pasos, las passkeys y el teléfono verificado no cuentan todavía: no tienen flujo
+// que los active y siempre restarían puntos. Se suman acá cuando esos flujos escriban sus columnas
+const (
+	SecurityFactorRecentPassword = "recent_password"
+	SecurityFactorEmailVerified  = "email_verified"
+)
+
+// Niveles del score de seguridad
+const (
+	SecurityLevelLow    = "low"    // score < 40
+	SecurityLevelMedium = "medium" // 40 <= score < 75
+	SecurityLevelHigh   = "high"   // score >= 75
+)
+
+// securityFactorWeights son los puntos que aporta cada factor (suman 100)
+var securityFactorWeights = []struct {
+	factor string
+	points int
+	action string
+}{
+	{SecurityFactorRecentPassword, 50, "Tu contraseña tiene más de 180 días: cambiala por una nueva"},
+	{SecurityFactorEmailVerified, 50, "Verificá tu email para poder recuperar tu cuenta"},
+}
+
+// SecurityFactors son los datos de la cuenta usados para calcular el score
+type SecurityFactors struct {
+	PasswordChangedAt time.Time // Si nunca se cambió, la fecha de registro
+	EmailVerified     bool
+}
+
+// SecurityFactorResult indica si un factor está cumplido y cuántos puntos aporta
+type SecurityFactorResult struct {
+	Factor  string `json:"factor"`
+	Enabled bool   `json:"enabled"`
+	Points  int    `json:"points"`
+	Max     int    `json:"max"`
+}
+
+// SecurityRecommendation es una acción concreta para subir el score
+type SecurityRecommendation struct {
+	Factor  string `json:"factor"`
+	Message string `json:"message"`
+	Points  int    `json:"points"` // Puntos que se ganan al completarla
+}
+
+// SecurityScoreDTO es el score de seguridad de una cuenta (0-100) con sus recomendaciones
+type SecurityScoreDTO struct {
+	Score           int                      `json:"score"`
+	Level           string                   `json:"level"`
+	PasswordAgeDays int                      `json:"password_age_days"`
+	Factors         []SecurityFactorResult   `json:"factors"`
+	Recommendations []SecurityRecommendation `json:"recommendations"`
+}
+
+// SecurityScoreDistribution resume los scores de todas las cuentas (adopción de factores)
+type SecurityScoreDistribution struct {
+	TotalUsers   int64            `json:"total_users"`
+	AverageScore float64          `json:"average_score"`
+	Buckets      map[string]int64 `json:"buckets"`  // "0-19", "20-39", "40-59", "60-79", "80-100"
+	Levels       map[string]int64 `json:"levels"`   // low, medium, high
+	Adoption     map[string]int64 `json:"adoption"` // cuentas con cada factor cumplido
+}
+
+// ComputeSecurityScore calcula el score de seguridad de una cuenta
+// Las recomendaciones se ordenan por puntos (las de mayor impacto primero)
+func ComputeSecurityScore(factors SecurityFactors, now time.Time) SecurityScoreDTO {
+	passwordAgeDays := int(now.Sub(factors.PasswordChangedAt).Hours() / 24)
+	if passwordAgeDays < 0 {
+		passwordAgeDays = 0
+	}
+
+	enabled := map[string]bool{
+		SecurityFactorRecentPassword: passwordAgeDays <= MaxPasswordAgeDays,
+		SecurityFactorEmailVerified:  factors.EmailVerified,
+	}
+
+	result := SecurityScoreDTO{
+		PasswordAgeDays: passwordAgeDays,
+		Factors:         make([]SecurityFactorResult, 0, len(securityFactorWeights)),
+		Recommendations: []SecurityRecommendation{},
+	}
+
+	for _, w := range securityFactorWeights {
+		factor := SecurityFactorResult{Factor: w.factor, Enabled: enabled[w.factor], Max: w.points}
+		if factor.Enabled {
+			factor.Points = w.points
+			result.Score += w.points
+		} else {
+			result.Recommendations = append(result.Recommendations, SecurityRecommendation{
+				Factor:  w.factor,
+				Message: w.action,
+				Points:  w.points,
+			})
+		}
+		result.Factors = append(result.Factors, factor)
+	}
+
+	result.Level = SecurityLevel(result.Score)
+	return result
+}
+
+// SecurityLevel devuelve el nivel correspondiente a un score
+func SecurityLevel(score int) string {
+	switch {
+	case score >= 75:
+		return SecurityLevelHigh
+	case score >= 40:
+		return SecurityLevelMedium
+	default:
+		return SecurityLevelLow
+	}
+}
+
+// SecurityScoreBucket devuelve el rango del histograma al que pertenece un score
+func SecurityScoreBucket(score int) string {
+	switch {
+	case score >= 80:
+		return "80-100"
+	case score >= 60:
+		return "60-79"
+	case score >= 40:
+		return "40-59"
+	case score >= 20:
+		return "20-39"
+	default:
+		return "0-19"
+	}
+}
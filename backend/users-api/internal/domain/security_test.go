@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeSecurityScore_AllFactors(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	score := ComputeSecurityScore(SecurityFactors{
+		PasswordChangedAt: now.AddDate(0, 0, -10),
+		EmailVerified:     true,
+	}, now)
+
+	assert.Equal(t, 100, score.Score)
+	assert.Equal(t, SecurityLevelHigh, score.Level)
+	assert.Equal(t, 10, score.PasswordAgeDays)
+	assert.Empty(t, score.Recommendations)
+	for _, factor := range score.Factors {
+		assert.True(t, factor.Enabled, factor.Factor)
+		assert.Equal(t, factor.Max, factor.Points, factor.Factor)
+	}
+}
+
+func TestComputeSecurityScore_NoFactors(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	score := ComputeSecurityScore(SecurityFactors{
+		PasswordChangedAt: now.AddDate(0, 0, -(MaxPasswordAgeDays + 1)),
+	}, now)
+
+	assert.Equal(t, 0, score.Score)
+	assert.Equal(t, SecurityLevelLow, score.Level)
+	assert.Len(t, score.Recommendations, 2)
+	for _, factor := range score.Factors {
+		assert.False(t, factor.Enabled, factor.Factor)
+		assert.Zero(t, factor.Points, factor.Factor)
+	}
+}
+
+func TestComputeSecurityScore_PasswordAgeBoundary(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	atLimit := ComputeSecurityScore(SecurityFactors{PasswordChangedAt: now.AddDate(0, 0, -MaxPasswordAgeDays)}, now)
+	assert.Equal(t, 50, atLimit.Score)
+	assert.Equal(t, SecurityLevelMedium, atLimit.Level)
+	assert.Equal(t, SecurityFactorEmailVerified, atLimit.Recommendations[0].Factor)
+
+	overLimit := ComputeSecurityScore(SecurityFactors{PasswordChangedAt: now.AddDate(0, 0, -MaxPasswordAgeDays-1), EmailVerified: true}, now)
+	assert.Equal(t, 50, overLimit.Score)
+	assert.Equal(t, SecurityFactorRecentPassword, overLimit.Recommendations[0].Factor)
+}
+
+func TestComputeSecurityScore_FuturePasswordChange(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Relojes desfasados entre instancias: una fecha futura cuenta como recién cambiada
+	score := ComputeSecurityScore(SecurityFactors{PasswordChangedAt: now.Add(time.Hour)}, now)
+
+	assert.Equal(t, 0, score.PasswordAgeDays)
+	assert.Equal(t, 50, score.Score)
+}
+
+func TestComputeSecurityScore_WeightsSumToHundred(t *testing.T) {
+	total := 0
+	for _, w := range securityFactorWeights {
+		total += w.points
+	}
+	assert.Equal(t, 100, total)
+}
+
+func TestSecurityLevelAndBucket(t *testing.T) {
+	assert.Equal(t, SecurityLevelLow, SecurityLevel(39))
+	assert.Equal(t, SecurityLevelMedium, SecurityLevel(40))
+	assert.Equal(t, SecurityLevelMedium, SecurityLevel(74))
+	assert.Equal(t, SecurityLevelHigh, SecurityLevel(75))
+
+	assert.Equal(t, "0-19", SecurityScoreBucket(0))
+	assert.Equal(t, "20-39", SecurityScoreBucket(20))
+	assert.Equal(t, "40-59", SecurityScoreBucket(50))
+	assert.Equal(t, "60-79", SecurityScoreBucket(79))
+	assert.Equal(t, "80-100", SecurityScoreBucket(100))
+}
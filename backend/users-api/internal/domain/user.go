@@ -4,24 +4,32 @@ import "time"
 
 // UserDTO representa un usuario en el dominio de negocio
 type UserDTO struct {
-	ID                  int64     `json:"id"`
-	Email               string    `json:"email"`
-	EmailVerified       bool      `json:"email_verified"`
-	Name                string    `json:"name"`
-	Lastname            string    `json:"lastname"`
-	Role                string    `json:"role"`
-	Phone               string    `json:"phone"`
-	Street              string    `json:"street"`
-	Number              int       `json:"number"`
-	PhotoURL            string    `json:"photo_url,omitempty"`
-	Sex                 string    `json:"sex"`
-	AvgDriverRating     float64   `json:"avg_driver_rating"`
-	AvgPassengerRating  float64   `json:"avg_passenger_rating"`
-	TotalTripsPassenger int       `json:"total_trips_passenger"`
-	TotalTripsDriver    int       `json:"total_trips_driver"`
-	Birthdate           time.Time `json:"birthdate"`
-	CreatedAt           time.Time `json:"created_at"`
-	UpdatedAt           time.Time `json:"updated_at"`
+	ID                  int64      `json:"id"`
+	Email               string     `json:"email"`
+	EmailVerified       bool       `json:"email_verified"`
+	Name                string     `json:"name"`
+	Lastname            string     `json:"lastname"`
+	Role                string     `json:"role"`
+	Phone               string     `json:"phone"`
+	Street              string     `json:"street"`
+	Number              int        `json:"number"`
+	PhotoURL            string     `json:"photo_url,omitempty"`
+	Sex                 string     `json:"sex"`
+	AvgDriverRating     float64    `json:"avg_driver_rating"`
+	AvgPassengerRating  float64    `json:"avg_passenger_rating"`
+	TotalTripsPassenger int        `json:"total_trips_passenger"`
+	TotalTripsDriver    int        `json:"total_trips_driver"`
+	Birthdate           time.Time  `json:"birthdate"`
+	Country             string     `json:"country"`                // ISO 3166-1 alpha-2
+	Currency            string     `json:"currency"`               // ISO 4217, moneda en la que ver precios
+	DistanceUnit        string     `json:"distance_unit"`          // "km" o "mi"
+	GuardianID          *int64     `json:"guardian_id,omitempty"`  // Solo cuentas dependientes
+	Restrictions        []string   `json:"restrictions,omitempty"` // Ver DependentRestrictions
+	Banned              bool       `json:"banned"`
+	BannedAt            *time.Time `json:"banned_at,omitempty"`
+	BanReason           string     `json:"ban_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 // CreateUserRequest representa los datos necesarios para crear un usuario
@@ -36,6 +44,16 @@ type CreateUserRequest struct {
 	PhotoURL  string `json:"photo_url"`
 	Sex       string `json:"sex" binding:"required,oneof=hombre mujer otro"`
 	Birthdate string `json:"birthdate" binding:"required"` // Format: YYYY-MM-DD
+
+	// Opcionales: locale del usuario ("es-AR"; sin él se usa el header Accept-Language) del que se
+	// infieren país, moneda y unidad de distancia, salvo los que se envíen explícitamente
+	Locale       string `json:"locale"`
+	Country      string `json:"country"`
+	Currency     string `json:"currency"`
+	DistanceUnit string `json:"distance_unit"`
+
+	// Opcional: consentimiento explícito para las campañas de marketing (sin él no se envían)
+	MarketingEmails bool `json:"marketing_emails"`
 }
 
 // UpdateUserRequest representa los datos que se pueden actualizar de un usuario
@@ -46,6 +64,16 @@ type UpdateUserRequest struct {
 	Street   *string `json:"street"`
 	Number   *int    `json:"number"`
 	PhotoURL *string `json:"photo_url"`
+
+	// Configuración regional; al cambiar el país, la moneda y la unidad que no se envíen pasan a las del país nuevo
+	Country      *string `json:"country"`
+	Currency     *string `json:"currency"`
+	DistanceUnit *string `json:"distance_unit"`
+}
+
+// BanUserRequest representa la suspensión de una cuenta por un admin
+type BanUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
 }
 
 // LoginRequest representa las credenciales de login
@@ -56,8 +84,9 @@ type LoginRequest struct {
 
 // LoginResponse representa la respuesta al login
 type LoginResponse struct {
-	Token string   `json:"token"`
-	User  *UserDTO `json:"user"`
+	Token       string   `json:"token"`
+	User        *UserDTO `json:"user"`
+	Permissions []string `json:"permissions"` // Los mismos que viajan codificados en el claim "perms"
 }
 
 // ChangePasswordRequest representa la solicitud para cambiar contraseña
@@ -66,6 +95,11 @@ type ChangePasswordRequest struct {
 	NewPassword     string `json:"new_password" binding:"required,min=8"`
 }
 
+// ForgotPasswordRequest representa la solicitud del email para restablecer contraseña
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
 // ResetPasswordRequest representa la solicitud para restablecer contraseña
 type ResetPasswordRequest struct {
 	Token       string `json:"token" binding:"required"`
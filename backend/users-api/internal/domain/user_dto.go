@@ -1 +1,36 @@
 package domain
+
+import "time"
+
+// PublicProfileDTO es el perfil público mínimo de un usuario (GET /public/users/:id)
+//
+// Whitelist estricta: solo estos campos se exponen sin autenticación. Nunca agregar
+// email, teléfono, dirección, apellido, fecha de nacimiento ni rol.
+type PublicProfileDTO struct {
+	ID                 int64   `json:"id"`
+	FirstName          string  `json:"first_name"`
+	PhotoURL           string  `json:"photo_url,omitempty"`
+	AvgDriverRating    float64 `json:"avg_driver_rating"`
+	AvgPassengerRating float64 `json:"avg_passenger_rating"`
+	MemberSince        string  `json:"member_since"` // Formato YYYY-MM (sin día ni hora)
+}
+
+// MemberSinceLayout es el formato de PublicProfileDTO.MemberSince
+const MemberSinceLayout = "2006-01"
+
+// FormatMemberSince reduce la fecha de alta a año y mes
+func FormatMemberSince(createdAt time.Time) string {
+	return createdAt.UTC().Format(MemberSinceLayout)
+}
+
+// SessionStatusDTO es el estado de sesión de un usuario (GET /internal/users/:id/session-status)
+//
+// Lo consultan trips-api, bookings-api y search-api en su middleware JWT para aplicar las mismas
+// reglas que checkAccountUsable: un token con iat anterior a SessionsRevokedAt ya no vale,
+// y una cuenta desactivada o suspendida no puede operar aunque su token siga vigente.
+type SessionStatusDTO struct {
+	UserID            int64      `json:"user_id"`
+	Active            bool       `json:"active"`
+	Banned            bool       `json:"banned"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at,omitempty"`
+}
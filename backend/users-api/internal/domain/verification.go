@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// VerificationTokenTTL es la vigencia de un token de verificación de email
+const VerificationTokenTTL = 24 * time.Hour
+
+// Límite de reenvíos del email de verificación por usuario
+// Los emails del registro y de la re-autenticación forzada por un admin no cuentan
+const (
+	MaxVerificationResends   = 3
+	VerificationResendWindow = time.Hour
+)
+
+// Motivos por los que se emite un token de verificación
+const (
+	VerificationPurposeRegister = "register"
+	VerificationPurposeResend   = "resend"
+	VerificationPurposeReauth   = "reauth"
+)
+
+// Errores de la verificación de email (el controller los traduce a códigos HTTP)
+var (
+	ErrVerificationTokenInvalid   = errors.New("token de verificación inválido")
+	ErrVerificationTokenExpired   = errors.New("el token de verificación expiró, solicita un nuevo email de verificación")
+	ErrVerificationTokenUsed      = errors.New("el token de verificación ya fue usado")
+	ErrVerificationResendThrottle = errors.New("alcanzaste el límite de reenvíos del email de verificación, intenta más tarde")
+)
@@ -0,0 +1,352 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"users-api/internal/domain"
+	"users-api/internal/metrics"
+	"users-api/internal/service"
+	"users-api/internal/tracing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	tripsExchangeName    = "trips.events"
+	bookingsExchangeName = "bookings.events"
+	exchangeType         = "topic"
+	queueName            = "users.notifications"
+	prefetchCount        = 10
+
+	routingKeyReservationCreated   = "reservation.created"
+	routingKeyReservationConfirmed = "reservation.confirmed"
+	routingKeyChatMessage          = "chat.message"
+	routingKeyReservationCancelled = "reservation.cancelled"
+	routingKeyTripUpdated          = "trip.updated"
+	routingKeyTripCancelled        = "trip.cancelled"
+	routingKeyTripDeleted          = "trip.deleted"
+
+	// Estados de trip.updated que cierran el viaje (ver trips-api)
+	tripStatusCompleted = "completed"
+	tripStatusCancelled = "cancelled"
+)
+
+// mentionPattern detecta menciones a usuarios en el chat con el formato @<user_id>
+var mentionPattern = regexp.MustCompile(`@(\d+)\b`)
+
+// NotificationConsumer consume eventos de reservas y viajes para generar notificaciones in-app
+// y emitir/revocar los tokens de contacto compartido entre conductor y pasajero
+type NotificationConsumer struct {
+	conn                *amqp.Connection
+	channel             *amqp.Channel
+	notificationService service.NotificationService
+	contactShareService service.ContactShareService
+}
+
+// binding representa el binding de la cola a un exchange por routing key
+type binding struct {
+	exchange   string
+	routingKey string
+}
+
+// NewNotificationConsumer crea un nuevo consumer de RabbitMQ para notificaciones
+// Declara ambos exchanges (idempotente) y bindea la cola users.notifications
+func NewNotificationConsumer(rabbitMQURL string, notificationService service.NotificationService, contactShareService service.ContactShareService) (*NotificationConsumer, error) {
+	conn, err := amqp.Dial(rabbitMQURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	// Declarar exchanges (idempotente - no falla si ya existen)
+	for _, exchange := range []string{tripsExchangeName, bookingsExchangeName} {
+		if err := channel.ExchangeDeclare(exchange, exchangeType, true, false, false, false, nil); err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("failed to declare exchange %s: %w", exchange, err)
+		}
+	}
+
+	// Declarar cola durable
+	queue, err := channel.QueueDeclare(queueName, true, false, false, false, nil)
+	if err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	bindings := []binding{
+		{exchange: bookingsExchangeName, routingKey: routingKeyReservationCreated},
+		{exchange: tripsExchangeName, routingKey: routingKeyReservationConfirmed},
+		{exchange: tripsExchangeName, routingKey: routingKeyChatMessage},
+		{exchange: bookingsExchangeName, routingKey: routingKeyReservationCancelled},
+		{exchange: tripsExchangeName, routingKey: routingKeyTripUpdated},
+		{exchange: tripsExchangeName, routingKey: routingKeyTripCancelled},
+		{exchange: tripsExchangeName, routingKey: routingKeyTripDeleted},
+	}
+	for _, b := range bindings {
+		if err := channel.QueueBind(queue.Name, b.routingKey, b.exchange, false, nil); err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("failed to bind queue for %s: %w", b.routingKey, err)
+		}
+	}
+
+	// Limitar mensajes sin ACK en vuelo
+	if err := channel.Qos(prefetchCount, 0, false); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to set QoS: %w", err)
+	}
+
+	log.Printf("Consumer de notificaciones inicializado (cola %s)", queueName)
+
+	return &NotificationConsumer{
+		conn:                conn,
+		channel:             channel,
+		notificationService: notificationService,
+		contactShareService: contactShareService,
+	}, nil
+}
+
+// Start comienza a consumir mensajes
+// Bloquea hasta que el contexto se cancele o se cierre el canal
+func (c *NotificationConsumer) Start(ctx context.Context) error {
+	messages, err := c.channel.Consume(
+		queueName, // queue
+		"",        // consumer tag (auto-generado)
+		false,     // auto-ack (ACK manual para confiabilidad)
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start consuming: %w", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Consumer de notificaciones detenido")
+			return nil
+
+		case msg, ok := <-messages:
+			if !ok {
+				return fmt.Errorf("message channel closed")
+			}
+			c.handleMessage(ctx, msg)
+		}
+	}
+}
+
+// handleMessage procesa un mensaje y decide ACK/NACK
+// Errores de parseo son permanentes (ACK); errores de base de datos se reintentan (NACK con requeue)
+// El span de consumidor continúa la traza del publisher (header traceparent)
+func (c *NotificationConsumer) handleMessage(ctx context.Context, msg amqp.Delivery) {
+	_, span := tracing.StartConsume(ctx, queueName, msg)
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	switch msg.RoutingKey {
+	case routingKeyReservationCreated:
+		err = c.handleReservationCreated(msg.Body)
+	case routingKeyReservationConfirmed:
+		err = c.handleReservationConfirmed(msg.Body)
+	case routingKeyChatMessage:
+		err = c.handleChatMessage(msg.Body)
+	case routingKeyReservationCancelled:
+		err = c.handleReservationCancelled(msg.Body)
+	case routingKeyTripUpdated, routingKeyTripCancelled, routingKeyTripDeleted:
+		err = c.handleTripStatus(msg.RoutingKey, msg.Body)
+	default:
+		log.Printf("Routing key desconocida %s, ACK sin procesar", msg.RoutingKey)
+		msg.Ack(false)
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
+		return
+	}
+
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+			log.Printf("Mensaje inválido en %s, descartando: %v", msg.RoutingKey, err)
+			msg.Ack(false)
+			metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeDiscarded)
+			return
+		}
+
+		log.Printf("Error procesando %s, reintentando: %v", msg.RoutingKey, err)
+		msg.Nack(false, true)
+		metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeNack)
+		return
+	}
+
+	msg.Ack(false)
+	metrics.ObserveConsume(msg.RoutingKey, metrics.ConsumeAck)
+}
+
+// handleReservationCreated avisa al pasajero que su reserva fue recibida
+func (c *NotificationConsumer) handleReservationCreated(body []byte) error {
+	var event ReservationCreatedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+
+	return c.notificationService.Notify(domain.NewNotification{
+		UserID:    event.PassengerID,
+		Type:      domain.NotificationTypeBookingUpdate,
+		Title:     "Reserva recibida",
+		Message:   fmt.Sprintf("Tu reserva de %d asiento(s) está pendiente de confirmación.", event.SeatsReserved),
+		TripID:    event.TripID,
+		BookingID: event.ReservationID,
+		EventID:   event.EventID,
+	})
+}
+
+// handleReservationConfirmed emite los tokens de contacto de la reserva,
+// avisa al pasajero de la confirmación y al conductor de la nueva reserva
+func (c *NotificationConsumer) handleReservationConfirmed(body []byte) error {
+	var event ReservationConfirmedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+
+	// Primero los tokens: la emisión es idempotente, así un reintento no los duplica
+	if err := c.contactShareService.IssueForBooking(domain.MatchedBooking{
+		TripID:      event.TripID,
+		BookingID:   event.ReservationID,
+		DriverID:    event.DriverID,
+		PassengerID: event.PassengerID,
+	}); err != nil {
+		return err
+	}
+
+	if err := c.notificationService.Notify(domain.NewNotification{
+		UserID:    event.PassengerID,
+		Type:      domain.NotificationTypeBookingUpdate,
+		Title:     "Reserva confirmada",
+		Message:   fmt.Sprintf("Tu reserva de %d asiento(s) fue confirmada.", event.SeatsReserved),
+		TripID:    event.TripID,
+		BookingID: event.ReservationID,
+		EventID:   event.EventID,
+	}); err != nil {
+		return err
+	}
+
+	return c.notificationService.Notify(domain.NewNotification{
+		UserID:    event.DriverID,
+		Type:      domain.NotificationTypeBookingUpdate,
+		Title:     "Nueva reserva en tu viaje",
+		Message:   fmt.Sprintf("Un pasajero reservó %d asiento(s) en tu viaje.", event.SeatsReserved),
+		TripID:    event.TripID,
+		BookingID: event.ReservationID,
+		EventID:   event.EventID,
+	})
+}
+
+// handleReservationCancelled revoca los tokens de contacto de la reserva cancelada
+func (c *NotificationConsumer) handleReservationCancelled(body []byte) error {
+	var event ReservationCancelledEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+
+	return c.contactShareService.RevokeForBooking(event.ReservationID, domain.ContactShareRevokedBookingCancelled)
+}
+
+// handleTripStatus revoca los tokens de contacto del viaje cuando se completa, se cancela o se elimina
+// trip.updated con cualquier otro estado se ignora
+func (c *NotificationConsumer) handleTripStatus(routingKey string, body []byte) error {
+	var event TripStatusEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+
+	reason := domain.ContactShareRevokedTripCancelled
+	if routingKey == routingKeyTripUpdated {
+		switch event.Status {
+		case tripStatusCompleted:
+			reason = domain.ContactShareRevokedTripCompleted
+		case tripStatusCancelled:
+		default:
+			return nil
+		}
+	}
+
+	return c.contactShareService.RevokeForTrip(event.TripID, reason)
+}
+
+// handleChatMessage notifica a los participantes del viaje mencionados con @<user_id> en el chat
+// Solo el conductor y los pasajeros confirmados que trae el evento: mencionar cualquier ID no le
+// manda el mensaje a usuarios ajenos al viaje
+func (c *NotificationConsumer) handleChatMessage(body []byte) error {
+	var event ChatMessageEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return err
+	}
+
+	participants := make(map[int64]bool, len(event.PassengerIDs)+1)
+	if event.DriverID != 0 {
+		participants[event.DriverID] = true
+	}
+	for _, passengerID := range event.PassengerIDs {
+		participants[passengerID] = true
+	}
+
+	for _, userID := range mentionedUserIDs(event.Message) {
+		// El autor no se notifica a sí mismo
+		if userID == event.UserID || !participants[userID] {
+			continue
+		}
+
+		if err := c.notificationService.Notify(domain.NewNotification{
+			UserID:  userID,
+			Type:    domain.NotificationTypeChatMention,
+			Title:   "Te mencionaron en el chat del viaje",
+			Message: event.Message,
+			TripID:  event.TripID,
+			EventID: event.EventID,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mentionedUserIDs extrae los IDs mencionados en un mensaje, sin duplicados
+func mentionedUserIDs(message string) []int64 {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, match := range mentionPattern.FindAllStringSubmatch(message, -1) {
+		id, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Close cierra el canal y la conexión
+func (c *NotificationConsumer) Close() error {
+	if c.channel != nil {
+		c.channel.Close()
+	}
+	if c.conn != nil {
+		return c.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package messaging
+
+import "time"
+
+// ReservationCreatedEvent representa una reserva creada (publicado por bookings-api en bookings.events)
+type ReservationCreatedEvent struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	TripID        string    `json:"trip_id"`
+	PassengerID   int64     `json:"passenger_id"`
+	SeatsReserved int       `json:"seats_reserved"`
+	ReservationID string    `json:"reservation_id"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// ReservationConfirmedEvent representa una reserva confirmada (publicado por trips-api en trips.events)
+type ReservationConfirmedEvent struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	ReservationID string    `json:"reservation_id"`
+	TripID        string    `json:"trip_id"`
+	PassengerID   int64     `json:"passenger_id"`
+	DriverID      int64     `json:"driver_id"`
+	SeatsReserved int       `json:"seats_reserved"`
+	TotalPrice    float64   `json:"total_price"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// ChatMessageEvent representa un mensaje enviado al chat de un viaje (publicado por trips-api en trips.events)
+type ChatMessageEvent struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	TripID    string `json:"trip_id"`
+	UserID    int64  `json:"user_id"` // Autor del mensaje
+	Message   string `json:"message"`
+	// Participantes del viaje: conductor y pasajeros con reserva confirmada (solo ellos reciben menciones)
+	DriverID     int64     `json:"driver_id"`
+	PassengerIDs []int64   `json:"passenger_ids"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// TripStatusEvent cubre trip.updated, trip.cancelled y trip.deleted (publicados por trips-api en trips.events)
+// Solo se leen los campos necesarios para revocar los tokens de contacto
+type TripStatusEvent struct {
+	EventID   string    `json:"event_id"`
+	EventType string    `json:"event_type"`
+	TripID    string    `json:"trip_id"`
+	Status    string    `json:"status"` // Solo viene en trip.updated
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ReservationCancelledEvent representa una reserva cancelada (publicado por bookings-api en bookings.events)
+type ReservationCancelledEvent struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	TripID        string    `json:"trip_id"`
+	ReservationID string    `json:"reservation_id"`
+	Timestamp     time.Time `json:"timestamp"`
+}
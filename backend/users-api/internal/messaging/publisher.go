@@ -0,0 +1,154 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"users-api/internal/domain"
+	"users-api/internal/metrics"
+	"users-api/internal/tracing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	usersExchangeName = "users.events"
+	sourceService     = "users-api"
+	publishTimeout    = 5 * time.Second
+)
+
+// LifecyclePublisher publica los eventos de usuario (ciclo de vida, calificaciones, aprobaciones de tutores y vencimiento de créditos) en el exchange users.events
+type LifecyclePublisher struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+	mu      sync.Mutex // amqp.Channel no es seguro para publicar desde varias goroutines
+}
+
+// NewLifecyclePublisher conecta a RabbitMQ y declara el exchange users.events (idempotente)
+func NewLifecyclePublisher(rabbitMQURL string) (*LifecyclePublisher, error) {
+	conn, err := amqp.Dial(rabbitMQURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	if err := channel.ExchangeDeclare(usersExchangeName, exchangeType, true, false, false, false, nil); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", usersExchangeName, err)
+	}
+
+	log.Printf("Publisher de eventos de usuario inicializado (exchange %s)", usersExchangeName)
+
+	return &LifecyclePublisher{
+		conn:    conn,
+		channel: channel,
+	}, nil
+}
+
+// PublishUserLifecycle publica el evento usando su event_type como routing key
+func (p *LifecyclePublisher) PublishUserLifecycle(ctx context.Context, event domain.UserLifecycleEvent) error {
+	event.SourceService = sourceService
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", event.EventType, err)
+	}
+
+	return p.publish(ctx, event.EventType, event.EventID, event.Timestamp, body)
+}
+
+// PublishRatingUpdated publica rating.updated (routing key rating.updated)
+func (p *LifecyclePublisher) PublishRatingUpdated(ctx context.Context, event domain.RatingUpdatedEvent) error {
+	event.SourceService = sourceService
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", event.EventType, err)
+	}
+
+	return p.publish(ctx, event.EventType, event.EventID, event.Timestamp, body)
+}
+
+// PublishUserRatingUpdated publica user.rating_updated (routing key user.rating_updated)
+func (p *LifecyclePublisher) PublishUserRatingUpdated(ctx context.Context, event domain.UserRatingUpdatedEvent) error {
+	event.SourceService = sourceService
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", event.EventType, err)
+	}
+
+	return p.publish(ctx, event.EventType, event.EventID, event.Timestamp, body)
+}
+
+// PublishGuardianApprovalDecided publica guardian.approval_decided (routing key guardian.approval_decided)
+func (p *LifecyclePublisher) PublishGuardianApprovalDecided(ctx context.Context, event domain.GuardianApprovalDecidedEvent) error {
+	event.SourceService = sourceService
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", event.EventType, err)
+	}
+
+	return p.publish(ctx, event.EventType, event.EventID, event.Timestamp, body)
+}
+
+// PublishCreditEvent publica credit.expiring o credit.expired (routing key = event_type)
+func (p *LifecyclePublisher) PublishCreditEvent(ctx context.Context, event domain.CreditEvent) error {
+	event.SourceService = sourceService
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", event.EventType, err)
+	}
+
+	return p.publish(ctx, event.EventType, event.EventID, event.Timestamp, body)
+}
+
+// publish envía el mensaje persistente a users.events con la routing key dada
+// El traceparent de ctx viaja en los headers para que los consumers continúen la traza
+func (p *LifecyclePublisher) publish(ctx context.Context, routingKey, eventID string, timestamp time.Time, body []byte) error {
+	_, span, headers := tracing.StartPublish(ctx, usersExchangeName, routingKey)
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	err := p.channel.PublishWithContext(ctx, usersExchangeName, routingKey, false, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    eventID,
+		Timestamp:    timestamp,
+		Body:         body,
+	})
+	metrics.ObservePublish(routingKey, err)
+	tracing.End(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to publish %s: %w", routingKey, err)
+	}
+
+	return nil
+}
+
+// Close cierra el canal y la conexión
+func (p *LifecyclePublisher) Close() error {
+	if p.channel != nil {
+		p.channel.Close()
+	}
+	if p.conn != nil {
+		return p.conn.Close()
+	}
+	return nil
+}
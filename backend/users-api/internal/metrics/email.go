@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Motivos de fallo de un envío de email (label reason de email_delivery_failures_total)
+const (
+	EmailFailureSMTPTimeout    = "smtp_timeout"     // El servidor SMTP no respondió dentro de SMTP_TIMEOUT_SECONDS
+	EmailFailureInvalidAddress = "invalid_address"  // Dirección mal formada o rechazada por el servidor (501/550/553)
+	EmailFailureProvider4xx    = "provider_4xx"     // Rechazo transitorio del proveedor (ej. 421, 450, 452)
+	EmailFailureProvider5xx    = "provider_5xx"     // Rechazo permanente del proveedor (ej. 535 credenciales, 554)
+	EmailFailureConnection     = "connection_error" // No se pudo conectar / TLS falló
+	EmailFailureOther          = "other"
+)
+
+var (
+	emailDeliveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "email_delivery_duration_seconds",
+		Help:    "Duración de la entrega de emails al servidor SMTP por tipo de email y resultado",
+		Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 20, 30, 60},
+	}, []string{"email", "result"})
+
+	emailDeliveryFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "email_delivery_failures_total",
+		Help: "Emails que no se pudieron entregar por tipo de email y motivo",
+	}, []string{"email", "reason"})
+
+	emailQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "email_queue_depth",
+		Help: "Emails pendientes de entrega (envíos en curso en segundo plano)",
+	})
+)
+
+// EmailQueued suma un email pendiente; llamar a EmailDelivered al terminar el envío
+func EmailQueued() {
+	emailQueueDepth.Inc()
+}
+
+// EmailDelivered registra el resultado de un envío encolado con EmailQueued
+// reason vacío indica éxito; si no, uno de los EmailFailure*
+func EmailDelivered(email string, duration time.Duration, reason string) {
+	emailQueueDepth.Dec()
+
+	result := "ok"
+	if reason != "" {
+		result = "error"
+		emailDeliveryFailures.WithLabelValues(email, reason).Inc()
+	}
+	emailDeliveryDuration.WithLabelValues(email, result).Observe(duration.Seconds())
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// gormStartKey es la clave de la instancia del statement que guarda el inicio de la query
+const gormStartKey = "metrics:started_at"
+
+var (
+	dbCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_call_duration_seconds",
+		Help:    "Duración de las sentencias ejecutadas con GORM por operación y tabla",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	dbCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_call_errors_total",
+		Help: "Sentencias fallidas (sin contar record not found) por operación y tabla",
+	}, []string{"operation", "table"})
+)
+
+// GormPlugin mide cada sentencia ejecutada con GORM (create, query, update, delete, row, raw)
+// Se registra con db.Use(metrics.GormPlugin{})
+type GormPlugin struct{}
+
+// Name implementa gorm.Plugin
+func (GormPlugin) Name() string {
+	return "prometheus_metrics"
+}
+
+// Initialize implementa gorm.Plugin registrando callbacks antes/después de cada procesador
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("gorm:create").Register, callbacks.Create().After("gorm:create").Register},
+		{"query", callbacks.Query().Before("gorm:query").Register, callbacks.Query().After("gorm:query").Register},
+		{"update", callbacks.Update().Before("gorm:update").Register, callbacks.Update().After("gorm:update").Register},
+		{"delete", callbacks.Delete().Before("gorm:delete").Register, callbacks.Delete().After("gorm:delete").Register},
+		{"row", callbacks.Row().Before("gorm:row").Register, callbacks.Row().After("gorm:row").Register},
+		{"raw", callbacks.Raw().Before("gorm:raw").Register, callbacks.Raw().After("gorm:raw").Register},
+	}
+
+	for _, p := range processors {
+		operation := p.operation
+		if err := p.before("metrics:before_"+operation, func(tx *gorm.DB) {
+			tx.InstanceSet(gormStartKey, time.Now())
+		}); err != nil {
+			return err
+		}
+		if err := p.after("metrics:after_"+operation, func(tx *gorm.DB) {
+			observeGormCall(tx, operation)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// observeGormCall registra la duración de la sentencia y cuenta los errores
+func observeGormCall(tx *gorm.DB, operation string) {
+	value, ok := tx.InstanceGet(gormStartKey)
+	if !ok {
+		return
+	}
+	startedAt, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+
+	table := tx.Statement.Table
+	if table == "" {
+		table = "unknown"
+	}
+
+	dbCallDuration.WithLabelValues(operation, table).Observe(time.Since(startedAt).Seconds())
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		dbCallErrors.WithLabelValues(operation, table).Inc()
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Resultados de un intento de job (label result de jobs_processed_total)
+const (
+	JobSucceeded = "succeeded" // El handler terminó sin error
+	JobRetry     = "retry"     // Falló y se reprogramó con backoff
+	JobFailed    = "failed"    // Falló y agotó los intentos (o no tiene handler registrado)
+)
+
+var (
+	jobsProcessed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobs_processed_total",
+		Help: "Intentos de jobs de la cola persistente por tipo y resultado",
+	}, []string{"type", "result"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "job_duration_seconds",
+		Help:    "Duración de los intentos de jobs por tipo",
+		Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"type"})
+
+	jobsByStatus = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jobs",
+		Help: "Jobs en la tabla jobs por estado",
+	}, []string{"status"})
+)
+
+// ObserveJob registra un intento de job con su resultado (JobSucceeded, JobRetry, JobFailed)
+func ObserveJob(jobType, result string, duration time.Duration) {
+	jobsProcessed.WithLabelValues(jobType, result).Inc()
+	jobDuration.WithLabelValues(jobType).Observe(duration.Seconds())
+}
+
+// SetJobsByStatus actualiza la cantidad de jobs por estado (los estados sin jobs quedan en 0)
+func SetJobsByStatus(statuses []string, counts map[string]int64) {
+	for _, status := range statuses {
+		jobsByStatus.WithLabelValues(status).Set(float64(counts[status]))
+	}
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Resultados del consumo de un mensaje de RabbitMQ
+const (
+	ConsumeAck       = "ack"       // Procesado (o routing key desconocida) y confirmado
+	ConsumeNack      = "nack"      // Error transitorio, reencolado
+	ConsumeDiscarded = "discarded" // Payload inválido, confirmado sin procesar
+)
+
+// unmatchedRoute agrupa las requests que no coinciden con ninguna ruta (evita una serie por path)
+const unmatchedRoute = "unmatched"
+
+// Métricas Prometheus del servicio, expuestas en GET /metrics
+// Los nombres son los mismos en los cuatro servicios: Prometheus los distingue por el job
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duración de las requests HTTP por ruta",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	rabbitPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_messages_published_total",
+		Help: "Eventos publicados en RabbitMQ por routing key y resultado",
+	}, []string{"routing_key", "result"})
+
+	rabbitConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbitmq_messages_consumed_total",
+		Help: "Mensajes consumidos de RabbitMQ por routing key y resultado",
+	}, []string{"routing_key", "result"})
+)
+
+// Middleware registra la duración de cada request con el template de la ruta (/users/:id),
+// nunca el path concreto, para no crear una serie por usuario
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		httpRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler expone las métricas en formato Prometheus (promhttp)
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// ObservePublish cuenta un evento publicado en RabbitMQ (result: ok / error)
+func ObservePublish(routingKey string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	rabbitPublished.WithLabelValues(routingKey, result).Inc()
+}
+
+// ObserveConsume cuenta un mensaje consumido con su resultado (ConsumeAck, ConsumeNack, ConsumeDiscarded)
+func ObserveConsume(routingKey, outcome string) {
+	rabbitConsumed.WithLabelValues(routingKey, outcome).Inc()
+}
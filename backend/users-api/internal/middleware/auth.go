@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"strings"
+	"users-api/internal/dao"
 	"users-api/internal/repository"
 	"users-api/internal/service"
 
@@ -53,6 +54,11 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 			c.Set("user_id", int64(claims["user_id"].(float64)))
 			c.Set("email", claims["email"].(string))
 			c.Set("role", claims["role"].(string))
+			c.Set("permissions", permissionsFromClaims(claims))
+
+			// Los tokens emitidos antes de agregar el claim iat cuentan como emitidos en el epoch
+			issuedAt, _ := claims["iat"].(float64)
+			c.Set("token_issued_at", int64(issuedAt))
 		} else {
 			c.JSON(401, gin.H{
 				"success": false,
@@ -102,6 +108,90 @@ func RequireVerifiedEmail(userRepo repository.UserRepository) gin.HandlerFunc {
 			return
 		}
 
+		if !checkAccountUsable(c, user) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireActiveAccount valida que la cuenta del usuario autenticado no esté desactivada ni suspendida,
+// sin exigir el email verificado (rutas admin). Este middleware debe usarse DESPUÉS de AuthMiddleware
+func RequireActiveAccount(userRepo repository.UserRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(401, gin.H{
+				"success": false,
+				"error":   "no autenticado",
+			})
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.FindByID(userID.(int64))
+		if err != nil {
+			c.JSON(401, gin.H{
+				"success": false,
+				"error":   "usuario no encontrado",
+			})
+			c.Abort()
+			return
+		}
+
+		if !checkAccountUsable(c, user) {
+			return
+		}
+
 		c.Next()
 	}
 }
+
+// checkAccountUsable corta la request con 403 si la cuenta está desactivada o suspendida,
+// y con 401 si el JWT se emitió antes de que se revocaran las sesiones (restablecimiento de contraseña)
+// o antes de que se le revocara un permiso (el claim "perms" todavía lo incluye)
+// El JWT emitido antes sigue siendo válido hasta expirar, por eso se consulta la base en cada request
+func checkAccountUsable(c *gin.Context, user *dao.UserDAO) bool {
+	// Sesiones revocadas: el token tiene que ser posterior a sessions_revoked_at
+	if user.SessionsRevokedAt != nil && c.GetInt64("token_issued_at") < user.SessionsRevokedAt.Unix() {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "la sesión fue cerrada, inicia sesión nuevamente",
+		})
+		c.Abort()
+		return false
+	}
+
+	// Permisos revocados: el token tiene que ser posterior a permissions_revoked_at
+	if user.PermissionsRevokedAt != nil && c.GetInt64("token_issued_at") < user.PermissionsRevokedAt.Unix() {
+		c.JSON(401, gin.H{
+			"success": false,
+			"error":   "tus permisos cambiaron, inicia sesión nuevamente",
+		})
+		c.Abort()
+		return false
+	}
+
+	// Cuentas desactivadas por un partner (SCIM)
+	if !user.Active {
+		c.JSON(403, gin.H{
+			"success": false,
+			"error":   "la cuenta está desactivada",
+		})
+		c.Abort()
+		return false
+	}
+
+	// Cuentas suspendidas por un admin
+	if user.IsBanned() {
+		c.JSON(403, gin.H{
+			"success": false,
+			"error":   "la cuenta está suspendida",
+		})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"users-api/internal/domain"
+	"users-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePartnerAPIKey valida la API key de un partner corporativo (Authorization: Bearer <api_key>)
+// y guarda partner_id en el contexto. Los errores usan el formato de error de SCIM
+func RequirePartnerAPIKey(partnerService service.PartnerService) gin.HandlerFunc {
+	return requirePartnerAPIKey(partnerService, abortSCIMUnauthorized)
+}
+
+// RequirePartnerAPIKeyJSON es RequirePartnerAPIKey con errores en el formato JSON del resto de la API
+// (API de partners fuera de SCIM, ej. /partner/v1/webhooks)
+func RequirePartnerAPIKeyJSON(partnerService service.PartnerService) gin.HandlerFunc {
+	return requirePartnerAPIKey(partnerService, func(c *gin.Context, detail string) {
+		c.AbortWithStatusJSON(401, gin.H{
+			"success": false,
+			"error":   detail,
+		})
+	})
+}
+
+func requirePartnerAPIKey(partnerService service.PartnerService, abort func(c *gin.Context, detail string)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		parts := strings.Split(authHeader, " ")
+		if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+			abort(c, "API key requerida, usar: Bearer API_KEY")
+			return
+		}
+
+		partner, err := partnerService.Authenticate(parts[1])
+		if err != nil {
+			abort(c, "API key inválida o revocada")
+			return
+		}
+
+		c.Set("partner_id", partner.ID)
+		c.Next()
+	}
+}
+
+func abortSCIMUnauthorized(c *gin.Context, detail string) {
+	c.Header("Content-Type", "application/scim+json")
+	c.AbortWithStatusJSON(401, domain.SCIMError{
+		Schemas: []string{domain.SCIMSchemaError},
+		Status:  strconv.Itoa(401),
+		Detail:  detail,
+	})
+}
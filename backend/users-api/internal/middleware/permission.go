@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"users-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Este archivo (junto con domain/permission.go) es autocontenido para que los demás servicios
+// lo copien y validen los permisos del JWT de la misma forma que users-api
+
+// permissionsFromClaims obtiene los permisos del claim "perms"
+// Los tokens emitidos antes de los permisos no lo tienen: se usan los permisos por defecto del rol
+func permissionsFromClaims(claims jwt.MapClaims) domain.PermissionSet {
+	if encoded, ok := claims["perms"].(string); ok {
+		if permissions, err := domain.DecodePermissions(encoded); err == nil {
+			return permissions
+		}
+		return domain.PermissionSet{}
+	}
+
+	role, _ := claims["role"].(string)
+	return domain.EffectivePermissions(role, nil, nil)
+}
+
+// HasPermission indica si el usuario autenticado tiene el permiso (false sin AuthMiddleware)
+func HasPermission(c *gin.Context, permission string) bool {
+	permissions, ok := c.Get("permissions")
+	if !ok {
+		return false
+	}
+	set, ok := permissions.(domain.PermissionSet)
+	return ok && set.Has(permission)
+}
+
+// RequirePermission valida que el usuario autenticado tenga el permiso
+// Este middleware debe usarse DESPUÉS de AuthMiddleware
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("permissions"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "permisos no encontrados en el token",
+			})
+			c.Abort()
+			return
+		}
+
+		if !HasPermission(c, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "acceso denegado - se requiere el permiso " + permission,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
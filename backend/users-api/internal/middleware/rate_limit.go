@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keyWindow cuenta las requests de una clave (IP, email) dentro de la ventana actual
+type keyWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit limita la cantidad de requests por IP y por minuto (ventana fija, en memoria)
+//
+// Pensado para rutas públicas sin autenticación. Al superar el límite responde
+// 429 con el header Retry-After (segundos hasta que empiece la próxima ventana).
+// El estado es por instancia: con varias réplicas el límite efectivo se multiplica.
+func RateLimit(requestsPerMinute int) gin.HandlerFunc {
+	return RateLimitByKey(requestsPerMinute, time.Minute, ClientIPKey)
+}
+
+// RateLimitByKey limita la cantidad de requests por clave dentro de una ventana fija (en memoria)
+//
+// Las requests cuya clave es vacía no se cuentan. Cada llamada crea contadores
+// independientes, así que dos rutas con el mismo límite no comparten el cupo.
+func RateLimitByKey(limit int, window time.Duration, key func(c *gin.Context) string) gin.HandlerFunc {
+	var (
+		mu        sync.Mutex
+		windows   = make(map[string]*keyWindow)
+		lastSweep = time.Now()
+	)
+
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		k := key(c)
+		if k == "" {
+			c.Next()
+			return
+		}
+		now := time.Now()
+
+		mu.Lock()
+		// Limpiar ventanas vencidas para que el mapa no crezca indefinidamente
+		if now.Sub(lastSweep) > window {
+			for key, w := range windows {
+				if now.Sub(w.start) >= window {
+					delete(windows, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		w, ok := windows[k]
+		if !ok || now.Sub(w.start) >= window {
+			w = &keyWindow{start: now}
+			windows[k] = w
+		}
+		w.count++
+		exceeded := w.count > limit
+		retryAfter := int(w.start.Add(window).Sub(now).Seconds()) + 1
+		mu.Unlock()
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		if exceeded {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(429, gin.H{
+				"success": false,
+				"error":   "demasiadas solicitudes, intente nuevamente más tarde",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// ClientIPKey usa la IP del cliente como clave del rate limiter
+// X-Forwarded-For solo cuenta si la conexión viene de un proxy de TRUSTED_PROXIES (router.SetTrustedProxies
+// en main): si no, un cliente rotando el header tendría un cupo nuevo en cada request
+func ClientIPKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// JSONEmailKey usa el campo "email" del body JSON (en minúsculas) como clave del rate limiter
+// El body se vuelve a dejar disponible para el controller; sin email la request no se cuenta
+// (el controller responde 400 de todas formas)
+func JSONEmailKey(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
+	if err != nil {
+		return ""
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(req.Email))
+}
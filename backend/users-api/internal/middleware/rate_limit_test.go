@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newForgotPasswordRouter arma /forgot-password con el límite por IP como en routes.SetupRoutes
+func newForgotPasswordRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies(trustedProxies))
+	router.POST("/forgot-password", RateLimitByKey(2, time.Hour, ClientIPKey), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func forgotPassword(router *gin.Engine, remoteAddr, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodPost, "/forgot-password", strings.NewReader(`{"email":"a@b.com"}`))
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestClientIPKey_IgnoresForwardedForFromUntrustedClient(t *testing.T) {
+	router := newForgotPasswordRouter(t, nil)
+
+	// Un cliente que rota X-Forwarded-For sigue contando contra su IP real
+	assert.Equal(t, http.StatusOK, forgotPassword(router, "203.0.113.7:5000", "1.1.1.1"))
+	assert.Equal(t, http.StatusOK, forgotPassword(router, "203.0.113.7:5000", "2.2.2.2"))
+	assert.Equal(t, http.StatusTooManyRequests, forgotPassword(router, "203.0.113.7:5000", "3.3.3.3"))
+}
+
+func TestClientIPKey_UsesForwardedForFromTrustedProxy(t *testing.T) {
+	router := newForgotPasswordRouter(t, []string{"10.0.0.0/8"})
+
+	// Detrás del proxy cada cliente tiene su propio cupo
+	assert.Equal(t, http.StatusOK, forgotPassword(router, "10.0.0.2:5000", "198.51.100.1"))
+	assert.Equal(t, http.StatusOK, forgotPassword(router, "10.0.0.2:5000", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, forgotPassword(router, "10.0.0.2:5000", "198.51.100.1"))
+	assert.Equal(t, http.StatusOK, forgotPassword(router, "10.0.0.2:5000", "198.51.100.2"))
+}
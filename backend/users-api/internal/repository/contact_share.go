@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContactShareRepository define el acceso a datos de los tokens de contacto compartido
+type ContactShareRepository interface {
+	// Create inserta un token; retorna false si la reserva ya tenía token para ese usuario (evento reentregado)
+	Create(token *dao.ContactShareTokenDAO) (bool, error)
+	FindByToken(token string) (*dao.ContactShareTokenDAO, error)
+
+	// FindActiveByViewer lista los tokens vigentes (no revocados ni expirados) que puede usar el usuario
+	// tripID vacío no filtra por viaje
+	FindActiveByViewer(viewerID int64, tripID string, now time.Time) ([]dao.ContactShareTokenDAO, error)
+
+	// RevokeByTrip y RevokeByBooking revocan los tokens todavía vigentes y retornan cuántos revocaron
+	RevokeByTrip(tripID, reason string, now time.Time) (int64, error)
+	RevokeByBooking(bookingID, reason string, now time.Time) (int64, error)
+}
+
+type contactShareRepository struct {
+	db *gorm.DB
+}
+
+// NewContactShareRepository crea una nueva instancia del repositorio de tokens de contacto
+func NewContactShareRepository(db *gorm.DB) ContactShareRepository {
+	return &contactShareRepository{db: db}
+}
+
+func (r *contactShareRepository) Create(token *dao.ContactShareTokenDAO) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *contactShareRepository) FindByToken(token string) (*dao.ContactShareTokenDAO, error) {
+	var share dao.ContactShareTokenDAO
+	if err := r.db.Where("token = ?", token).First(&share).Error; err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (r *contactShareRepository) FindActiveByViewer(viewerID int64, tripID string, now time.Time) ([]dao.ContactShareTokenDAO, error) {
+	query := r.db.Where("viewer_id = ? AND revoked_at IS NULL AND expires_at > ?", viewerID, now)
+	if tripID != "" {
+		query = query.Where("trip_id = ?", tripID)
+	}
+
+	var shares []dao.ContactShareTokenDAO
+	if err := query.Order("created_at DESC").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (r *contactShareRepository) RevokeByTrip(tripID, reason string, now time.Time) (int64, error) {
+	return r.revoke(r.db.Where("trip_id = ?", tripID), reason, now)
+}
+
+func (r *contactShareRepository) RevokeByBooking(bookingID, reason string, now time.Time) (int64, error) {
+	return r.revoke(r.db.Where("booking_id = ?", bookingID), reason, now)
+}
+
+// revoke marca como revocados los tokens vigentes que matchean query (los ya revocados conservan su motivo)
+func (r *contactShareRepository) revoke(query *gorm.DB, reason string, now time.Time) (int64, error) {
+	result := query.Model(&dao.ContactShareTokenDAO{}).
+		Where("revoked_at IS NULL").
+		Updates(map[string]interface{}{
+			"revoked_at":    now,
+			"revoke_reason": reason,
+		})
+	return result.RowsAffected, result.Error
+}
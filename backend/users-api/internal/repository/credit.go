@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreditRepository define el acceso a datos de los créditos promocionales: los créditos otorgados,
+// el ledger de movimientos y los consumos por reserva
+// Cada cambio de saldo guarda su movimiento del ledger en la misma transacción
+type CreditRepository interface {
+	// Créditos y saldo
+	CreateGrant(grant *dao.CreditGrantDAO) error
+	FindActiveGrants(userID int64, now time.Time) ([]dao.CreditGrantDAO, error)
+	FindLedger(userID int64, offset, limit int) ([]dao.CreditLedgerEntryDAO, int64, error)
+
+	// Consume aplica hasta consumption.Requested del saldo vigente en consumption.Currency a la reserva
+	// Si la reserva ya tenía un consumo no se modifica nada, se carga el existente y retorna false
+	Consume(consumption *dao.CreditConsumptionDAO, now time.Time) (bool, error)
+
+	// Release devuelve a sus créditos lo que la reserva consumió y retorna el consumo actualizado
+	// Retorna false si ya se había devuelto y gorm.ErrRecordNotFound si la reserva no aplicó créditos
+	Release(bookingID string, now time.Time) (*dao.CreditConsumptionDAO, bool, error)
+
+	// Vencimiento
+	FindExpiringToNotify(now, until time.Time, limit int) ([]dao.CreditGrantDAO, error)
+	MarkExpiringNotified(grantID int64, now time.Time) error
+	FindExpired(now time.Time, limit int) ([]dao.CreditGrantDAO, error)
+
+	// Expire deja en 0 el saldo de un crédito vencido y retorna el monto que venció
+	// (0 si otro proceso ya lo venció o se consumió entero)
+	Expire(grantID int64, now time.Time) (float64, error)
+}
+
+type creditRepository struct {
+	db *gorm.DB
+}
+
+// NewCreditRepository crea una nueva instancia del repositorio de créditos
+func NewCreditRepository(db *gorm.DB) CreditRepository {
+	return &creditRepository{db: db}
+}
+
+// ==================== CRÉDITOS Y SALDO ====================
+
+// CreateGrant crea el crédito y su movimiento grant
+func (r *creditRepository) CreateGrant(grant *dao.CreditGrantDAO) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(grant).Error; err != nil {
+			return err
+		}
+		return tx.Create(&dao.CreditLedgerEntryDAO{
+			UserID:   grant.UserID,
+			GrantID:  grant.ID,
+			Type:     domain.CreditEntryGrant,
+			Amount:   grant.Amount,
+			Currency: grant.Currency,
+			Reason:   grant.Reason,
+		}).Error
+	})
+}
+
+// FindActiveGrants lista los créditos vigentes con saldo, los que vencen primero al principio
+func (r *creditRepository) FindActiveGrants(userID int64, now time.Time) ([]dao.CreditGrantDAO, error) {
+	var grants []dao.CreditGrantDAO
+	err := activeGrants(r.db, now).
+		Where("user_id = ?", userID).
+		Order("expires_at ASC, id ASC").
+		Find(&grants).Error
+	return grants, err
+}
+
+// FindLedger lista los movimientos del usuario, los más nuevos primero
+func (r *creditRepository) FindLedger(userID int64, offset, limit int) ([]dao.CreditLedgerEntryDAO, int64, error) {
+	query := r.db.Model(&dao.CreditLedgerEntryDAO{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []dao.CreditLedgerEntryDAO
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error
+	return entries, total, err
+}
+
+// Consume inserta el consumo (único por booking_id, así dos requests de la misma reserva no consumen
+// dos veces) y descuenta el saldo de los créditos que vencen primero, bloqueándolos hasta el commit
+func (r *creditRepository) Consume(consumption *dao.CreditConsumptionDAO, now time.Time) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(consumption)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Where("booking_id = ?", consumption.BookingID).First(consumption).Error
+		}
+		created = true
+
+		var grants []dao.CreditGrantDAO
+		err := activeGrants(tx, now).
+			Where("user_id = ? AND currency = ?", consumption.UserID, consumption.Currency).
+			Order("expires_at ASC, id ASC").
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Find(&grants).Error
+		if err != nil {
+			return err
+		}
+
+		pending := consumption.Requested
+		applied := 0.0
+		bookingID := consumption.BookingID
+		for _, grant := range grants {
+			if pending <= 0 {
+				break
+			}
+			amount := grant.Remaining
+			if amount > pending {
+				amount = pending
+			}
+
+			remaining := domain.RoundCreditAmount(grant.Remaining - amount)
+			if err := tx.Model(&dao.CreditGrantDAO{}).Where("id = ?", grant.ID).Update("remaining", remaining).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&dao.CreditLedgerEntryDAO{
+				UserID:    consumption.UserID,
+				GrantID:   grant.ID,
+				Type:      domain.CreditEntryConsume,
+				Amount:    -amount,
+				Currency:  consumption.Currency,
+				BookingID: &bookingID,
+			}).Error; err != nil {
+				return err
+			}
+
+			pending = domain.RoundCreditAmount(pending - amount)
+			applied = domain.RoundCreditAmount(applied + amount)
+		}
+
+		consumption.AppliedAmount = applied
+		return tx.Model(&dao.CreditConsumptionDAO{}).Where("id = ?", consumption.ID).Update("applied_amount", applied).Error
+	})
+	return created, err
+}
+
+// Release bloquea el consumo (dos devoluciones de la misma reserva se serializan) y suma a cada crédito
+// lo que se le consumió, con un movimiento release por crédito. Los créditos que vencieron mientras la
+// reserva estaba activa no se reactivan: esa parte no se devuelve
+func (r *creditRepository) Release(bookingID string, now time.Time) (*dao.CreditConsumptionDAO, bool, error) {
+	var consumption dao.CreditConsumptionDAO
+	released := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("booking_id = ?", bookingID).
+			First(&consumption).Error
+		if err != nil {
+			return err
+		}
+		if consumption.ReleasedAt != nil {
+			return nil
+		}
+		released = true
+
+		var entries []dao.CreditLedgerEntryDAO
+		err = tx.Where("booking_id = ? AND type = ?", bookingID, domain.CreditEntryConsume).
+			Order("id ASC").
+			Find(&entries).Error
+		if err != nil {
+			return err
+		}
+
+		total := 0.0
+		for _, entry := range entries {
+			var grant dao.CreditGrantDAO
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND expired_at IS NULL AND expires_at > ?", entry.GrantID, now).
+				First(&grant).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			amount := -entry.Amount
+			remaining := domain.RoundCreditAmount(grant.Remaining + amount)
+			if err := tx.Model(&dao.CreditGrantDAO{}).Where("id = ?", grant.ID).Update("remaining", remaining).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&dao.CreditLedgerEntryDAO{
+				UserID:    consumption.UserID,
+				GrantID:   grant.ID,
+				Type:      domain.CreditEntryRelease,
+				Amount:    amount,
+				Currency:  consumption.Currency,
+				BookingID: &consumption.BookingID,
+			}).Error; err != nil {
+				return err
+			}
+			total = domain.RoundCreditAmount(total + amount)
+		}
+
+		consumption.ReleasedAmount = total
+		consumption.ReleasedAt = &now
+		return tx.Model(&dao.CreditConsumptionDAO{}).Where("id = ?", consumption.ID).Updates(map[string]interface{}{
+			"released_amount": total,
+			"released_at":     now,
+		}).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &consumption, released, nil
+}
+
+// ==================== VENCIMIENTO ====================
+
+// FindExpiringToNotify lista los créditos con saldo que vencen antes de until y todavía no se avisaron
+func (r *creditRepository) FindExpiringToNotify(now, until time.Time, limit int) ([]dao.CreditGrantDAO, error) {
+	var grants []dao.CreditGrantDAO
+	err := activeGrants(r.db, now).
+		Where("expires_at <= ? AND expiring_notified_at IS NULL", until).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&grants).Error
+	return grants, err
+}
+
+func (r *creditRepository) MarkExpiringNotified(grantID int64, now time.Time) error {
+	return r.db.Model(&dao.CreditGrantDAO{}).Where("id = ?", grantID).Update("expiring_notified_at", now).Error
+}
+
+// FindExpired lista los créditos vencidos que todavía tienen saldo, los más viejos primero
+func (r *creditRepository) FindExpired(now time.Time, limit int) ([]dao.CreditGrantDAO, error) {
+	var grants []dao.CreditGrantDAO
+	err := r.db.Where("remaining > 0 AND expired_at IS NULL AND expires_at <= ?", now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&grants).Error
+	return grants, err
+}
+
+// Expire bloquea el crédito (un consumo en curso termina antes), deja el saldo en 0 y guarda el movimiento expire
+func (r *creditRepository) Expire(grantID int64, now time.Time) (float64, error) {
+	expired := 0.0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var grant dao.CreditGrantDAO
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND remaining > 0 AND expired_at IS NULL", grantID).
+			First(&grant).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&dao.CreditGrantDAO{}).Where("id = ?", grant.ID).Updates(map[string]interface{}{
+			"remaining":  0,
+			"expired_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&dao.CreditLedgerEntryDAO{
+			UserID:   grant.UserID,
+			GrantID:  grant.ID,
+			Type:     domain.CreditEntryExpire,
+			Amount:   -grant.Remaining,
+			Currency: grant.Currency,
+		}).Error; err != nil {
+			return err
+		}
+
+		expired = grant.Remaining
+		return nil
+	})
+	return expired, err
+}
+
+// activeGrants filtra los créditos vigentes (sin vencer) con saldo
+func activeGrants(db *gorm.DB, now time.Time) *gorm.DB {
+	return db.Model(&dao.CreditGrantDAO{}).Where("remaining > 0 AND expired_at IS NULL AND expires_at > ?", now)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GuardianRepository define el acceso a datos de las cuentas dependientes: los dependientes de
+// un tutor, las solicitudes de aprobación y la auditoría de las acciones del tutor
+// Cada escritura del tutor guarda su registro de auditoría en la misma transacción
+type GuardianRepository interface {
+	// Dependientes
+	CreateDependent(user *dao.UserDAO, audit *dao.GuardianAuditLogDAO) error
+	FindDependents(guardianID int64) ([]*dao.UserDAO, error)
+	FindDependent(guardianID, dependentID int64) (*dao.UserDAO, error)
+	UpdateDependent(user *dao.UserDAO, audit *dao.GuardianAuditLogDAO) error
+	DeactivateDependent(dependentID int64, audit *dao.GuardianAuditLogDAO) error
+
+	// Solicitudes de aprobación
+	CreateApproval(approval *dao.GuardianApprovalDAO) (bool, error)
+	FindApprovalByID(id int64) (*dao.GuardianApprovalDAO, error)
+	FindApprovals(guardianID int64, status string, now time.Time) ([]dao.GuardianApprovalDAO, error)
+	DecideApproval(approval *dao.GuardianApprovalDAO, audit *dao.GuardianAuditLogDAO) (bool, error)
+
+	// Auditoría
+	FindAuditLogs(guardianID, dependentID int64, offset, limit int) ([]dao.GuardianAuditLogDAO, int64, error)
+}
+
+type guardianRepository struct {
+	db *gorm.DB
+}
+
+// NewGuardianRepository crea una nueva instancia del repositorio de cuentas dependientes
+func NewGuardianRepository(db *gorm.DB) GuardianRepository {
+	return &guardianRepository{db: db}
+}
+
+// ==================== DEPENDIENTES ====================
+
+// CreateDependent crea la cuenta del dependiente y registra la acción del tutor
+// audit.DependentID se completa con el ID generado
+func (r *guardianRepository) CreateDependent(user *dao.UserDAO, audit *dao.GuardianAuditLogDAO) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		audit.DependentID = user.ID
+		return tx.Create(audit).Error
+	})
+}
+
+func (r *guardianRepository) FindDependents(guardianID int64) ([]*dao.UserDAO, error) {
+	var users []*dao.UserDAO
+	err := r.db.Where("guardian_id = ? AND role = ?", guardianID, domain.RoleDependent).
+		Order("id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+func (r *guardianRepository) FindDependent(guardianID, dependentID int64) (*dao.UserDAO, error) {
+	var user dao.UserDAO
+	err := r.db.Where("id = ? AND guardian_id = ? AND role = ?", dependentID, guardianID, domain.RoleDependent).
+		First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *guardianRepository) UpdateDependent(user *dao.UserDAO, audit *dao.GuardianAuditLogDAO) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+func (r *guardianRepository) DeactivateDependent(dependentID int64, audit *dao.GuardianAuditLogDAO) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&dao.UserDAO{}).Where("id = ?", dependentID).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(audit).Error
+	})
+}
+
+// ==================== APROBACIONES ====================
+
+// CreateApproval inserta la solicitud; si ya existía una para (action, resource_id) no se modifica,
+// se carga la existente en approval y retorna false
+func (r *guardianRepository) CreateApproval(approval *dao.GuardianApprovalDAO) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(approval)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	err := r.db.Where("action = ? AND resource_id = ?", approval.Action, approval.ResourceID).First(approval).Error
+	return false, err
+}
+
+func (r *guardianRepository) FindApprovalByID(id int64) (*dao.GuardianApprovalDAO, error) {
+	var approval dao.GuardianApprovalDAO
+	if err := r.db.First(&approval, id).Error; err != nil {
+		return nil, err
+	}
+	return &approval, nil
+}
+
+// FindApprovals lista las solicitudes del tutor, las más nuevas primero
+// status pending excluye las vencidas y expired retorna solo esas (ver domain.GuardianApprovalExpired)
+func (r *guardianRepository) FindApprovals(guardianID int64, status string, now time.Time) ([]dao.GuardianApprovalDAO, error) {
+	query := r.db.Where("guardian_id = ?", guardianID)
+	switch status {
+	case "":
+	case domain.GuardianApprovalPending:
+		query = query.Where("status = ? AND expires_at > ?", domain.GuardianApprovalPending, now)
+	case domain.GuardianApprovalExpired:
+		query = query.Where("status = ? AND expires_at <= ?", domain.GuardianApprovalPending, now)
+	default:
+		query = query.Where("status = ?", status)
+	}
+
+	var approvals []dao.GuardianApprovalDAO
+	err := query.Order("created_at DESC").Limit(100).Find(&approvals).Error
+	return approvals, err
+}
+
+// DecideApproval guarda la decisión y la auditoría en una transacción
+// La actualización es condicional sobre status pending y expires_at: si la solicitud ya se resolvió
+// o venció no se modifica nada y retorna false
+func (r *guardianRepository) DecideApproval(approval *dao.GuardianApprovalDAO, audit *dao.GuardianAuditLogDAO) (bool, error) {
+	decided := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.GuardianApprovalDAO{}).
+			Where("id = ? AND status = ? AND expires_at > ?", approval.ID, domain.GuardianApprovalPending, *approval.DecidedAt).
+			Updates(map[string]interface{}{
+				"status":        approval.Status,
+				"decision_note": approval.DecisionNote,
+				"decided_at":    *approval.DecidedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(audit).Error; err != nil {
+			return err
+		}
+		decided = true
+		return nil
+	})
+	return decided, err
+}
+
+// ==================== AUDITORÍA ====================
+
+// FindAuditLogs lista la auditoría, las acciones más nuevas primero
+// guardianID y dependentID en 0 no filtran
+func (r *guardianRepository) FindAuditLogs(guardianID, dependentID int64, offset, limit int) ([]dao.GuardianAuditLogDAO, int64, error) {
+	query := r.db.Model(&dao.GuardianAuditLogDAO{})
+	if guardianID != 0 {
+		query = query.Where("guardian_id = ?", guardianID)
+	}
+	if dependentID != 0 {
+		query = query.Where("dependent_id = ?", dependentID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []dao.GuardianAuditLogDAO
+	err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&logs).Error
+	return logs, total, err
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepository define las operaciones de acceso a datos de la cola de jobs
+type JobRepository interface {
+	// Create encola un job; retorna false si ya existía uno con la misma unique_key
+	Create(job *dao.JobDAO) (bool, error)
+	FindByID(id int64) (*dao.JobDAO, error)
+	FindJobs(status, jobType string, offset, limit int) ([]dao.JobDAO, int64, error)
+	CountByStatus() (map[string]int64, error)
+
+	// FindDueJobs lista los jobs pending o running con run_at vencido, los más viejos primero
+	FindDueJobs(now time.Time, limit int) ([]dao.JobDAO, error)
+
+	// Claim toma un job para un worker (status running, run_at = leaseUntil, attempts + 1)
+	// Solo lo toma si sigue como se leyó (mismo status y run_at): retorna false si otro worker se adelantó
+	Claim(job *dao.JobDAO, workerID string, leaseUntil time.Time) (bool, error)
+
+	// Finish guarda el resultado de un intento si el job sigue tomado por el worker en ese intento
+	// (si el lease venció y otro worker lo retomó, el resultado se descarta)
+	Finish(job *dao.JobDAO, workerID string, attempt int) (bool, error)
+
+	// Requeue vuelve un job fallido a pending con los intentos en cero
+	Requeue(id int64, runAt time.Time) (bool, error)
+
+	// DeleteFinished borra hasta limit jobs terminados con éxito antes de before
+	DeleteFinished(before time.Time, limit int) (int64, error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository crea una nueva instancia del repositorio de jobs
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(job *dao.JobDAO) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(job)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *jobRepository) FindByID(id int64) (*dao.JobDAO, error) {
+	var job dao.JobDAO
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) FindJobs(status, jobType string, offset, limit int) ([]dao.JobDAO, int64, error) {
+	query := r.db.Model(&dao.JobDAO{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if jobType != "" {
+		query = query.Where("type = ?", jobType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []dao.JobDAO
+	err := query.Order("updated_at DESC, id DESC").Offset(offset).Limit(limit).Find(&jobs).Error
+	return jobs, total, err
+}
+
+func (r *jobRepository) CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&dao.JobDAO{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *jobRepository) FindDueJobs(now time.Time, limit int) ([]dao.JobDAO, error) {
+	var jobs []dao.JobDAO
+	err := r.db.Where("status IN ? AND run_at <= ?", []string{domain.JobStatusPending, domain.JobStatusRunning}, now).
+		Order("run_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *jobRepository) Claim(job *dao.JobDAO, workerID string, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&dao.JobDAO{}).
+		Where("id = ? AND status = ? AND run_at = ?", job.ID, job.Status, job.RunAt).
+		Updates(map[string]interface{}{
+			"status":    domain.JobStatusRunning,
+			"run_at":    leaseUntil,
+			"leased_by": workerID,
+			"attempts":  gorm.Expr("attempts + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *jobRepository) Finish(job *dao.JobDAO, workerID string, attempt int) (bool, error) {
+	result := r.db.Model(&dao.JobDAO{}).
+		Where("id = ? AND status = ? AND leased_by = ? AND attempts = ?", job.ID, domain.JobStatusRunning, workerID, attempt).
+		Updates(map[string]interface{}{
+			"status":      job.Status,
+			"run_at":      job.RunAt,
+			"last_error":  job.LastError,
+			"finished_at": job.FinishedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *jobRepository) Requeue(id int64, runAt time.Time) (bool, error) {
+	result := r.db.Model(&dao.JobDAO{}).
+		Where("id = ? AND status = ?", id, domain.JobStatusFailed).
+		Updates(map[string]interface{}{
+			"status":      domain.JobStatusPending,
+			"attempts":    0,
+			"run_at":      runAt,
+			"finished_at": nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *jobRepository) DeleteFinished(before time.Time, limit int) (int64, error) {
+	result := r.db.
+		Where("status = ? AND finished_at < ?", domain.JobStatusSucceeded, before).
+		Limit(limit).
+		Delete(&dao.JobDAO{})
+	return result.RowsAffected, result.Error
+}
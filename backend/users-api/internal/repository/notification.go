@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository define las operaciones de acceso a datos para notificaciones
+type NotificationRepository interface {
+	Create(notification *dao.NotificationDAO) (bool, error)
+	FindByUserID(userID int64, unreadOnly bool, limit, offset int) ([]dao.NotificationDAO, int64, error)
+	FindByIDAndUserID(id, userID int64) (*dao.NotificationDAO, error)
+	CountUnread(userID int64) (int64, error)
+	MarkRead(id int64, readAt time.Time) error
+	MarkAllRead(userID int64, readAt time.Time) (int64, error)
+	Delete(id, userID int64) (bool, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository crea una nueva instancia del repositorio de notificaciones
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create inserta una notificación
+// Retorna false si ya existía una notificación del mismo evento para el usuario (evento reentregado)
+func (r *notificationRepository) Create(notification *dao.NotificationDAO) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *notificationRepository) FindByUserID(userID int64, unreadOnly bool, limit, offset int) ([]dao.NotificationDAO, int64, error) {
+	query := r.db.Model(&dao.NotificationDAO{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []dao.NotificationDAO
+	err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *notificationRepository) FindByIDAndUserID(id, userID int64) (*dao.NotificationDAO, error) {
+	var notification dao.NotificationDAO
+	err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *notificationRepository) CountUnread(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&dao.NotificationDAO{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(id int64, readAt time.Time) error {
+	return r.db.Model(&dao.NotificationDAO{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", readAt).Error
+}
+
+// MarkAllRead marca como leídas todas las notificaciones pendientes del usuario
+// Retorna la cantidad de notificaciones actualizadas
+func (r *notificationRepository) MarkAllRead(userID int64, readAt time.Time) (int64, error) {
+	result := r.db.Model(&dao.NotificationDAO{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt)
+	return result.RowsAffected, result.Error
+}
+
+// Delete elimina una notificación del usuario
+// Retorna false si no existe o pertenece a otro usuario
+func (r *notificationRepository) Delete(id, userID int64) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&dao.NotificationDAO{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuthorizedPartnerRow es un partner autorizado por el usuario con la fecha de la autorización
+type AuthorizedPartnerRow struct {
+	PartnerID    int64     `gorm:"column:partner_id"`
+	Name         string    `gorm:"column:name"`
+	AuthorizedAt time.Time `gorm:"column:authorized_at"`
+}
+
+// PartnerWebhookRepository define el acceso a datos de los webhooks de partners:
+// autorizaciones de usuarios, suscripciones y log de entregas
+type PartnerWebhookRepository interface {
+	// Autorizaciones (qué partners reciben los eventos de cada usuario)
+	CreateAuthorization(userID, partnerID int64) error
+	DeleteAuthorization(userID, partnerID int64) (bool, error)
+	DeleteUserAuthorizations(userID int64) error
+	FindAuthorizedPartners(userID int64) ([]AuthorizedPartnerRow, error)
+
+	// Suscripciones
+	CreateWebhook(webhook *dao.PartnerWebhookDAO) error
+	FindWebhooks(partnerID int64) ([]dao.PartnerWebhookDAO, error)
+	FindWebhook(partnerID, id int64) (*dao.PartnerWebhookDAO, error)
+	CountWebhooks(partnerID int64) (int64, error)
+	UpdateWebhook(webhook *dao.PartnerWebhookDAO) error
+	DeleteWebhook(id int64) error
+
+	// FindSubscribedWebhooks lista los webhooks activos suscriptos a eventType de los partners
+	// activos que el usuario autorizó
+	FindSubscribedWebhooks(userID int64, eventType string) ([]dao.PartnerWebhookDAO, error)
+
+	// Entregas
+	CreateDeliveries(deliveries []dao.WebhookDeliveryDAO) error
+	FindDeliveries(webhookID int64, status string, offset, limit int) ([]dao.WebhookDeliveryDAO, int64, error)
+	FindDelivery(webhookID, id int64) (*dao.WebhookDeliveryDAO, error)
+	FindDueDeliveries(now time.Time, limit int) ([]dao.WebhookDeliveryDAO, error)
+
+	// ClaimDelivery posterga next_attempt_at hasta leaseUntil si nadie la tomó antes (varias réplicas del dispatcher)
+	ClaimDelivery(id int64, nextAttemptAt, leaseUntil time.Time) (bool, error)
+	UpdateDelivery(delivery *dao.WebhookDeliveryDAO) error
+
+	// FailPendingDeliveries marca como fallidas las entregas pendientes de un usuario a un partner (autorización revocada)
+	FailPendingDeliveries(partnerID, userID int64, reason string) (int64, error)
+}
+
+type partnerWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewPartnerWebhookRepository crea una nueva instancia del repositorio de webhooks de partners
+func NewPartnerWebhookRepository(db *gorm.DB) PartnerWebhookRepository {
+	return &partnerWebhookRepository{db: db}
+}
+
+// ==================== AUTORIZACIONES ====================
+
+// CreateAuthorization es idempotente: autorizar dos veces conserva la fecha original
+func (r *partnerWebhookRepository) CreateAuthorization(userID, partnerID int64) error {
+	authorization := &dao.PartnerAuthorizationDAO{UserID: userID, PartnerID: partnerID}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(authorization).Error
+}
+
+func (r *partnerWebhookRepository) DeleteAuthorization(userID, partnerID int64) (bool, error) {
+	result := r.db.Where("user_id = ? AND partner_id = ?", userID, partnerID).Delete(&dao.PartnerAuthorizationDAO{})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *partnerWebhookRepository) DeleteUserAuthorizations(userID int64) error {
+	return r.db.Where("user_id = ?", userID).Delete(&dao.PartnerAuthorizationDAO{}).Error
+}
+
+func (r *partnerWebhookRepository) FindAuthorizedPartners(userID int64) ([]AuthorizedPartnerRow, error) {
+	var rows []AuthorizedPartnerRow
+	err := r.db.Table("partner_authorizations AS a").
+		Select("a.partner_id, p.name, a.created_at AS authorized_at").
+		Joins("JOIN partners AS p ON p.id = a.partner_id").
+		Where("a.user_id = ?", userID).
+		Order("a.created_at DESC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// ==================== SUSCRIPCIONES ====================
+
+func (r *partnerWebhookRepository) CreateWebhook(webhook *dao.PartnerWebhookDAO) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *partnerWebhookRepository) FindWebhooks(partnerID int64) ([]dao.PartnerWebhookDAO, error) {
+	var webhooks []dao.PartnerWebhookDAO
+	err := r.db.Where("partner_id = ?", partnerID).Order("id ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *partnerWebhookRepository) FindWebhook(partnerID, id int64) (*dao.PartnerWebhookDAO, error) {
+	var webhook dao.PartnerWebhookDAO
+	if err := r.db.Where("id = ? AND partner_id = ?", id, partnerID).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *partnerWebhookRepository) CountWebhooks(partnerID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&dao.PartnerWebhookDAO{}).Where("partner_id = ?", partnerID).Count(&count).Error
+	return count, err
+}
+
+func (r *partnerWebhookRepository) UpdateWebhook(webhook *dao.PartnerWebhookDAO) error {
+	return r.db.Save(webhook).Error
+}
+
+// DeleteWebhook borra el webhook y su log de entregas en una transacción
+func (r *partnerWebhookRepository) DeleteWebhook(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&dao.WebhookDeliveryDAO{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&dao.PartnerWebhookDAO{}).Error
+	})
+}
+
+func (r *partnerWebhookRepository) FindSubscribedWebhooks(userID int64, eventType string) ([]dao.PartnerWebhookDAO, error) {
+	var webhooks []dao.PartnerWebhookDAO
+	err := r.db.Table("partner_webhooks AS w").
+		Select("w.*").
+		Joins("JOIN partner_authorizations AS a ON a.partner_id = w.partner_id").
+		Joins("JOIN partners AS p ON p.id = w.partner_id").
+		Where("a.user_id = ? AND w.active = ? AND p.active = ?", userID, true, true).
+		Where("FIND_IN_SET(?, w.events) > 0", eventType).
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// ==================== ENTREGAS ====================
+
+func (r *partnerWebhookRepository) CreateDeliveries(deliveries []dao.WebhookDeliveryDAO) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+// FindDeliveries lista el log de entregas del webhook, las más recientes primero; status vacío no filtra
+func (r *partnerWebhookRepository) FindDeliveries(webhookID int64, status string, offset, limit int) ([]dao.WebhookDeliveryDAO, int64, error) {
+	query := r.db.Model(&dao.WebhookDeliveryDAO{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []dao.WebhookDeliveryDAO
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+func (r *partnerWebhookRepository) FindDelivery(webhookID, id int64) (*dao.WebhookDeliveryDAO, error) {
+	var delivery dao.WebhookDeliveryDAO
+	if err := r.db.Where("id = ? AND webhook_id = ?", id, webhookID).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindDueDeliveries lista las entregas pendientes cuyo próximo intento ya venció, las más viejas primero
+func (r *partnerWebhookRepository) FindDueDeliveries(now time.Time, limit int) ([]dao.WebhookDeliveryDAO, error) {
+	var deliveries []dao.WebhookDeliveryDAO
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", "pending", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *partnerWebhookRepository) ClaimDelivery(id int64, nextAttemptAt, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&dao.WebhookDeliveryDAO{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", id, "pending", nextAttemptAt).
+		UpdateColumn("next_attempt_at", leaseUntil)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *partnerWebhookRepository) UpdateDelivery(delivery *dao.WebhookDeliveryDAO) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *partnerWebhookRepository) FailPendingDeliveries(partnerID, userID int64, reason string) (int64, error) {
+	result := r.db.Model(&dao.WebhookDeliveryDAO{}).
+		Where("partner_id = ? AND user_id = ? AND status = ?", partnerID, userID, "pending").
+		Updates(map[string]interface{}{
+			"status":     "failed",
+			"last_error": reason,
+		})
+	return result.RowsAffected, result.Error
+}
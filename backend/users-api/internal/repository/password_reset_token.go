@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetTokenRepository define el acceso a datos de los tokens de restablecimiento de contraseña
+type PasswordResetTokenRepository interface {
+	Create(token *dao.PasswordResetTokenDAO) error
+	FindByHash(tokenHash string) (*dao.PasswordResetTokenDAO, error)
+	CountSince(userID int64, since time.Time) (int64, error)
+
+	// Consume marca el token como usado, cambia la contraseña y revoca las sesiones del usuario en una transacción
+	// Retorna false si el token ya se había usado (otra request lo consumió primero)
+	Consume(token *dao.PasswordResetTokenDAO, passwordHash string, now time.Time) (bool, error)
+
+	// ConsumeLegacy hace lo mismo con un token emitido antes de la tabla (columna users.password_reset_token)
+	ConsumeLegacy(userID int64, legacyToken, passwordHash string, now time.Time) (bool, error)
+}
+
+type passwordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetTokenRepository crea una nueva instancia del repositorio de tokens de restablecimiento
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+func (r *passwordResetTokenRepository) Create(token *dao.PasswordResetTokenDAO) error {
+	return r.db.Create(token).Error
+}
+
+func (r *passwordResetTokenRepository) FindByHash(tokenHash string) (*dao.PasswordResetTokenDAO, error) {
+	var token dao.PasswordResetTokenDAO
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CountSince cuenta los tokens emitidos al usuario desde since
+func (r *passwordResetTokenRepository) CountSince(userID int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&dao.PasswordResetTokenDAO{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// Consume usa el token (UPDATE condicionado a used_at IS NULL, así dos requests no lo usan a la vez),
+// guarda la nueva contraseña, revoca las sesiones abiertas (sessions_revoked_at), limpia el token
+// heredado de la columna users.password_reset_token e invalida los demás tokens pendientes del usuario
+func (r *passwordResetTokenRepository) Consume(token *dao.PasswordResetTokenDAO, passwordHash string, now time.Time) (bool, error) {
+	consumed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.PasswordResetTokenDAO{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		consumed = true
+
+		if err := tx.Model(&dao.UserDAO{}).
+			Where("id = ?", token.UserID).
+			Updates(resetPasswordColumns(passwordHash, now)).Error; err != nil {
+			return err
+		}
+
+		return invalidatePendingResetTokens(tx, token.UserID, now)
+	})
+	return consumed, err
+}
+
+// ConsumeLegacy condiciona el UPDATE al token de la columna users.password_reset_token (un solo uso)
+func (r *passwordResetTokenRepository) ConsumeLegacy(userID int64, legacyToken, passwordHash string, now time.Time) (bool, error) {
+	consumed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.UserDAO{}).
+			Where("id = ? AND password_reset_token = ?", userID, legacyToken).
+			Updates(resetPasswordColumns(passwordHash, now))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		consumed = true
+
+		return invalidatePendingResetTokens(tx, userID, now)
+	})
+	return consumed, err
+}
+
+// resetPasswordColumns son las columnas de users que cambian al restablecer la contraseña
+// sessions_revoked_at se guarda sin fracción de segundo: el claim iat del JWT tiene precisión de segundos
+// y un login inmediatamente posterior al reset no debe quedar revocado
+func resetPasswordColumns(passwordHash string, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"password_hash":          passwordHash,
+		"password_changed_at":    now,
+		"sessions_revoked_at":    now.Truncate(time.Second),
+		"password_reset_token":   nil,
+		"password_reset_expires": nil,
+	}
+}
+
+// invalidatePendingResetTokens marca como usados los demás tokens pendientes del usuario
+func invalidatePendingResetTokens(tx *gorm.DB, userID int64, now time.Time) error {
+	return tx.Model(&dao.PasswordResetTokenDAO{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now).Error
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+)
+
+// PermissionRepository define el acceso a datos de los permisos individuales de los usuarios
+type PermissionRepository interface {
+	FindOverrides(userID int64) ([]dao.UserPermissionOverrideDAO, error)
+	ReplaceOverrides(userID int64, overrides []dao.UserPermissionOverrideDAO, revokedAt *time.Time) error
+}
+
+type permissionRepository struct {
+	db *gorm.DB
+}
+
+// NewPermissionRepository crea una nueva instancia del repositorio de permisos
+func NewPermissionRepository(db *gorm.DB) PermissionRepository {
+	return &permissionRepository{db: db}
+}
+
+func (r *permissionRepository) FindOverrides(userID int64) ([]dao.UserPermissionOverrideDAO, error) {
+	var overrides []dao.UserPermissionOverrideDAO
+	err := r.db.Where("user_id = ?", userID).Order("permission ASC").Find(&overrides).Error
+	return overrides, err
+}
+
+// ReplaceOverrides reemplaza todos los permisos individuales del usuario en una transacción
+// Con revokedAt (el usuario perdió algún permiso) también guarda permissions_revoked_at en la misma transacción
+func (r *permissionRepository) ReplaceOverrides(userID int64, overrides []dao.UserPermissionOverrideDAO, revokedAt *time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&dao.UserPermissionOverrideDAO{}).Error; err != nil {
+			return err
+		}
+		if revokedAt != nil {
+			if err := tx.Model(&dao.UserDAO{}).Where("id = ?", userID).Update("permissions_revoked_at", *revokedAt).Error; err != nil {
+				return err
+			}
+		}
+		if len(overrides) == 0 {
+			return nil
+		}
+		return tx.Create(&overrides).Error
+	})
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreferencesRepository define las operaciones de acceso a datos para las preferencias de usuario
+type PreferencesRepository interface {
+	FindByUserID(userID int64) (*dao.UserPreferencesDAO, error)
+	Upsert(preferences *dao.UserPreferencesDAO) error
+}
+
+type preferencesRepository struct {
+	db *gorm.DB
+}
+
+// NewPreferencesRepository crea una nueva instancia del repositorio de preferencias
+func NewPreferencesRepository(db *gorm.DB) PreferencesRepository {
+	return &preferencesRepository{db: db}
+}
+
+// FindByUserID retorna gorm.ErrRecordNotFound si el usuario nunca guardó sus preferencias
+func (r *preferencesRepository) FindByUserID(userID int64) (*dao.UserPreferencesDAO, error) {
+	var preferences dao.UserPreferencesDAO
+	if err := r.db.Where("user_id = ?", userID).First(&preferences).Error; err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// Upsert crea la fila del usuario o reemplaza todas sus columnas
+func (r *preferencesRepository) Upsert(preferences *dao.UserPreferencesDAO) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		UpdateAll: true,
+	}).Create(preferences).Error
+}
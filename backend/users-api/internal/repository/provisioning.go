@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"strings"
+	"time"
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FilterCondition es una condición de un filtro SCIM ya traducida a una columna
+// Operator: eq, sw (empieza con) o co (contiene)
+type FilterCondition struct {
+	Column   string
+	Operator string
+	Value    interface{}
+}
+
+// ProvisioningRepository define el acceso a datos de la provisión SCIM: partners,
+// usuarios provisionados y organizaciones con sus miembros
+type ProvisioningRepository interface {
+	// Partners y API keys
+	CreatePartner(partner *dao.PartnerDAO) error
+	FindAllPartners() ([]dao.PartnerDAO, error)
+	FindPartnerByID(id int64) (*dao.PartnerDAO, error)
+	FindPartnerByName(name string) (*dao.PartnerDAO, error)
+	FindActivePartnerByKeyHash(keyHash string) (*dao.PartnerDAO, error)
+	RevokePartner(id int64) error
+	UpdatePartnerDomains(id int64, domains string) error
+	TouchPartner(id int64, at time.Time) error
+
+	// Usuarios provisionados por un partner
+	FindProvisionedUsers(partnerID int64, conditions []FilterCondition, offset, limit int) ([]*dao.UserDAO, int64, error)
+	FindProvisionedUser(partnerID, userID int64) (*dao.UserDAO, error)
+	FindProvisionedUsersByIDs(partnerID int64, userIDs []int64) ([]*dao.UserDAO, error)
+	UpdateUserActive(userID int64, active bool) error
+
+	// Organizaciones (grupos SCIM) y miembros
+	CreateOrganization(organization *dao.OrganizationDAO, memberIDs []int64) error
+	FindOrganizations(partnerID int64, conditions []FilterCondition, offset, limit int) ([]dao.OrganizationDAO, int64, error)
+	FindOrganization(partnerID, id int64) (*dao.OrganizationDAO, error)
+	UpdateOrganization(organization *dao.OrganizationDAO) error
+	DeleteOrganization(id int64) error
+	FindMembers(organizationIDs []int64) ([]dao.OrganizationMemberDAO, error)
+	FindUserOrganizations(partnerID, userID int64) ([]dao.OrganizationDAO, error)
+	AddMembers(organizationID int64, userIDs []int64) error
+	RemoveMembers(organizationID int64, userIDs []int64) error
+	ReplaceMembers(organizationID int64, userIDs []int64) error
+}
+
+type provisioningRepository struct {
+	db *gorm.DB
+}
+
+// NewProvisioningRepository crea una nueva instancia del repositorio de provisión SCIM
+func NewProvisioningRepository(db *gorm.DB) ProvisioningRepository {
+	return &provisioningRepository{db: db}
+}
+
+// ==================== PARTNERS ====================
+
+func (r *provisioningRepository) CreatePartner(partner *dao.PartnerDAO) error {
+	return r.db.Create(partner).Error
+}
+
+func (r *provisioningRepository) FindAllPartners() ([]dao.PartnerDAO, error) {
+	var partners []dao.PartnerDAO
+	err := r.db.Order("created_at DESC").Find(&partners).Error
+	return partners, err
+}
+
+func (r *provisioningRepository) FindPartnerByID(id int64) (*dao.PartnerDAO, error) {
+	var partner dao.PartnerDAO
+	if err := r.db.Where("id = ?", id).First(&partner).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+func (r *provisioningRepository) FindPartnerByName(name string) (*dao.PartnerDAO, error) {
+	var partner dao.PartnerDAO
+	if err := r.db.Where("name = ?", name).First(&partner).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+func (r *provisioningRepository) FindActivePartnerByKeyHash(keyHash string) (*dao.PartnerDAO, error) {
+	var partner dao.PartnerDAO
+	if err := r.db.Where("api_key_hash = ? AND active = ?", keyHash, true).First(&partner).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+func (r *provisioningRepository) RevokePartner(id int64) error {
+	return r.db.Model(&dao.PartnerDAO{}).Where("id = ?", id).Update("active", false).Error
+}
+
+func (r *provisioningRepository) UpdatePartnerDomains(id int64, domains string) error {
+	return r.db.Model(&dao.PartnerDAO{}).Where("id = ?", id).Update("verified_domains", domains).Error
+}
+
+func (r *provisioningRepository) TouchPartner(id int64, at time.Time) error {
+	return r.db.Model(&dao.PartnerDAO{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+// ==================== USUARIOS PROVISIONADOS ====================
+
+// FindProvisionedUsers lista los usuarios del partner que cumplen todas las condiciones, por ID ascendente
+// (orden estable para paginar con startIndex)
+func (r *provisioningRepository) FindProvisionedUsers(partnerID int64, conditions []FilterCondition, offset, limit int) ([]*dao.UserDAO, int64, error) {
+	query := applyFilterConditions(r.db.Model(&dao.UserDAO{}).Where("partner_id = ?", partnerID), conditions)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*dao.UserDAO
+	if limit == 0 {
+		return users, total, nil
+	}
+
+	err := query.Order("id ASC").Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+func (r *provisioningRepository) FindProvisionedUser(partnerID, userID int64) (*dao.UserDAO, error) {
+	var user dao.UserDAO
+	if err := r.db.Where("id = ? AND partner_id = ?", userID, partnerID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *provisioningRepository) FindProvisionedUsersByIDs(partnerID int64, userIDs []int64) ([]*dao.UserDAO, error) {
+	var users []*dao.UserDAO
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ? AND partner_id = ?", userIDs, partnerID).Find(&users).Error
+	return users, err
+}
+
+func (r *provisioningRepository) UpdateUserActive(userID int64, active bool) error {
+	return r.db.Model(&dao.UserDAO{}).Where("id = ?", userID).Update("active", active).Error
+}
+
+// ==================== ORGANIZACIONES ====================
+
+// CreateOrganization crea la organización con sus miembros iniciales en una transacción
+func (r *provisioningRepository) CreateOrganization(organization *dao.OrganizationDAO, memberIDs []int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		return insertMembers(tx, organization.ID, memberIDs)
+	})
+}
+
+func (r *provisioningRepository) FindOrganizations(partnerID int64, conditions []FilterCondition, offset, limit int) ([]dao.OrganizationDAO, int64, error) {
+	query := applyFilterConditions(r.db.Model(&dao.OrganizationDAO{}).Where("partner_id = ?", partnerID), conditions)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var organizations []dao.OrganizationDAO
+	if limit == 0 {
+		return organizations, total, nil
+	}
+
+	err := query.Order("id ASC").Limit(limit).Offset(offset).Find(&organizations).Error
+	return organizations, total, err
+}
+
+func (r *provisioningRepository) FindOrganization(partnerID, id int64) (*dao.OrganizationDAO, error) {
+	var organization dao.OrganizationDAO
+	if err := r.db.Where("id = ? AND partner_id = ?", id, partnerID).First(&organization).Error; err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (r *provisioningRepository) UpdateOrganization(organization *dao.OrganizationDAO) error {
+	return r.db.Save(organization).Error
+}
+
+// DeleteOrganization borra la organización y sus membresías (los usuarios no se tocan)
+func (r *provisioningRepository) DeleteOrganization(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ?", id).Delete(&dao.OrganizationMemberDAO{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&dao.OrganizationDAO{}).Error
+	})
+}
+
+func (r *provisioningRepository) FindMembers(organizationIDs []int64) ([]dao.OrganizationMemberDAO, error) {
+	var members []dao.OrganizationMemberDAO
+	if len(organizationIDs) == 0 {
+		return members, nil
+	}
+	err := r.db.Where("organization_id IN ?", organizationIDs).Order("user_id ASC").Find(&members).Error
+	return members, err
+}
+
+func (r *provisioningRepository) FindUserOrganizations(partnerID, userID int64) ([]dao.OrganizationDAO, error) {
+	var organizations []dao.OrganizationDAO
+	err := r.db.
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ? AND organizations.partner_id = ?", userID, partnerID).
+		Order("organizations.id ASC").
+		Find(&organizations).Error
+	return organizations, err
+}
+
+func (r *provisioningRepository) AddMembers(organizationID int64, userIDs []int64) error {
+	return insertMembers(r.db, organizationID, userIDs)
+}
+
+func (r *provisioningRepository) RemoveMembers(organizationID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return r.db.Where("organization_id = ? AND user_id IN ?", organizationID, userIDs).
+		Delete(&dao.OrganizationMemberDAO{}).Error
+}
+
+// ReplaceMembers reemplaza todos los miembros de la organización en una transacción
+func (r *provisioningRepository) ReplaceMembers(organizationID int64, userIDs []int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ?", organizationID).Delete(&dao.OrganizationMemberDAO{}).Error; err != nil {
+			return err
+		}
+		return insertMembers(tx, organizationID, userIDs)
+	})
+}
+
+// insertMembers agrega miembros ignorando los que ya pertenecían (add es idempotente en SCIM)
+func insertMembers(db *gorm.DB, organizationID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	members := make([]dao.OrganizationMemberDAO, len(userIDs))
+	for i, userID := range userIDs {
+		members[i] = dao.OrganizationMemberDAO{OrganizationID: organizationID, UserID: userID}
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error
+}
+
+// applyFilterConditions agrega las condiciones del filtro SCIM a la query (todas con AND)
+// Las columnas vienen de una whitelist del servicio, nunca del request
+func applyFilterConditions(query *gorm.DB, conditions []FilterCondition) *gorm.DB {
+	for _, condition := range conditions {
+		switch condition.Operator {
+		case "sw":
+			query = query.Where(condition.Column+" LIKE ?", escapeLike(condition.Value)+"%")
+		case "co":
+			query = query.Where(condition.Column+" LIKE ?", "%"+escapeLike(condition.Value)+"%")
+		default:
+			query = query.Where(condition.Column+" = ?", condition.Value)
+		}
+	}
+	return query
+}
+
+// escapeLike escapa los comodines de LIKE para que el valor se compare literalmente
+func escapeLike(value interface{}) string {
+	s, _ := value.(string)
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
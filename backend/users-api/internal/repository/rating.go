@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"time"
 	"users-api/internal/dao"
 
 	"gorm.io/gorm"
@@ -10,8 +11,12 @@ import (
 type RatingRepository interface {
 	Create(rating *dao.RatingDAO) error
 	FindByRatedUserID(userID int64, limit, offset int) ([]dao.RatingDAO, error)
+	FindRecentComments(userID int64, roleRated string, limit int) ([]dao.RatingDAO, error)
 	CalculateAverages(userID int64) (avgDriver, avgPassenger float64, totalDriver, totalPassenger int, err error)
 	ExistsRating(raterID int64, tripID string, ratedUserID int64) (bool, error)
+	FindByID(id int64) (*dao.RatingDAO, error)
+	UpdateWithHistory(rating *dao.RatingDAO, previous *dao.RatingEditDAO) (bool, error)
+	FindEdits(ratingID int64) ([]dao.RatingEditDAO, error)
 }
 
 type ratingRepository struct {
@@ -37,6 +42,16 @@ func (r *ratingRepository) FindByRatedUserID(userID int64, limit, offset int) ([
 	return ratings, err
 }
 
+// FindRecentComments obtiene las últimas calificaciones con comentario recibidas en un rol
+func (r *ratingRepository) FindRecentComments(userID int64, roleRated string, limit int) ([]dao.RatingDAO, error) {
+	var ratings []dao.RatingDAO
+	err := r.db.Where("rated_user_id = ? AND role_rated = ? AND comment <> ''", userID, roleRated).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&ratings).Error
+	return ratings, err
+}
+
 // CalculateAverages calcula los promedios de calificaciones por rol
 func (r *ratingRepository) CalculateAverages(userID int64) (avgDriver, avgPassenger float64, totalDriver, totalPassenger int, err error) {
 	// Calcular promedio y total para conductor
@@ -73,3 +88,53 @@ func (r *ratingRepository) ExistsRating(raterID int64, tripID string, ratedUserI
 		Count(&count).Error
 	return count > 0, err
 }
+
+func (r *ratingRepository) FindByID(id int64) (*dao.RatingDAO, error) {
+	var rating dao.RatingDAO
+	if err := r.db.First(&rating, id).Error; err != nil {
+		return nil, err
+	}
+	return &rating, nil
+}
+
+// UpdateWithHistory guarda la versión anterior y aplica la edición en una transacción
+// La actualización es condicional sobre edit_count: si otra edición se aplicó antes
+// (previous.Version ya no es la actual) no se modifica nada y retorna false
+func (r *ratingRepository) UpdateWithHistory(rating *dao.RatingDAO, previous *dao.RatingEditDAO) (bool, error) {
+	updated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		editedAt := time.Now()
+		result := tx.Model(&dao.RatingDAO{}).
+			Where("id = ? AND edit_count = ?", rating.ID, previous.Version).
+			Updates(map[string]interface{}{
+				"score":      rating.Score,
+				"comment":    rating.Comment,
+				"edit_count": previous.Version + 1,
+				"edited_at":  editedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(previous).Error; err != nil {
+			return err
+		}
+
+		rating.EditCount = previous.Version + 1
+		rating.EditedAt = &editedAt
+		updated = true
+		return nil
+	})
+	return updated, err
+}
+
+func (r *ratingRepository) FindEdits(ratingID int64) ([]dao.RatingEditDAO, error) {
+	var edits []dao.RatingEditDAO
+	err := r.db.Where("rating_id = ?", ratingID).
+		Order("version ASC").
+		Find(&edits).Error
+	return edits, err
+}
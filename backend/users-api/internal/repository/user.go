@@ -10,8 +10,9 @@ import (
 // UserRepository define las operaciones de acceso a datos para usuarios
 type UserRepository interface {
 	Create(user *dao.UserDAO) error
-	FindAllWithPagination(page, limit int, roleFilter, search string) ([]*dao.UserDAO, int64, error)
+	FindAllWithPagination(page, limit int, roleFilter, search string, bannedFilter *bool) ([]*dao.UserDAO, int64, error)
 	FindByID(id int64) (*dao.UserDAO, error)
+	FindByIDs(ids []int64) ([]*dao.UserDAO, error)
 	FindByEmail(email string) (*dao.UserDAO, error)
 	Update(user *dao.UserDAO) error
 	UpdateRatings(userID int64, avgDriverRating, avgPassengerRating float64, totalTripsDriver, totalTripsPassenger int) error
@@ -24,6 +25,15 @@ type UserRepository interface {
 	SavePasswordResetToken(userID int64, token string, expiresAt time.Time) error
 	ClearPasswordResetToken(userID int64) error
 	UnverifyEmail(userID int64, email string) error
+	FindSecurityFactorsBatch(afterID int64, limit int) ([]*dao.UserDAO, error)
+	UpdateLastLogin(userID int64, at time.Time) error
+	UpdateMarketingEmails(userID int64, enabled bool) error
+	FindInactivePendingNotice(cutoff time.Time, limit int) ([]*dao.UserDAO, error)
+	MarkInactiveNotified(userID int64, at time.Time) error
+	UpdateBan(userID int64, bannedAt *time.Time, bannedBy *int64, reason string) error
+	FindByProvider(provider, providerID string) (*dao.UserDAO, error)
+	LinkProvider(userID int64, provider, providerID string) (bool, error)
+	LinkProviderResettingPassword(userID int64, provider, providerID, passwordHash string, now time.Time) (bool, error)
 }
 
 type userRepository struct {
@@ -39,7 +49,7 @@ func (r *userRepository) Create(user *dao.UserDAO) error {
 	return r.db.Create(user).Error
 }
 
-func (r *userRepository) FindAllWithPagination(page, limit int, roleFilter, search string) ([]*dao.UserDAO, int64, error) {
+func (r *userRepository) FindAllWithPagination(page, limit int, roleFilter, search string, bannedFilter *bool) ([]*dao.UserDAO, int64, error) {
 	var users []*dao.UserDAO
 	var total int64
 
@@ -50,6 +60,15 @@ func (r *userRepository) FindAllWithPagination(page, limit int, roleFilter, sear
 		query = query.Where("role = ?", roleFilter)
 	}
 
+	// Filtro por suspensión (nil: todos)
+	if bannedFilter != nil {
+		if *bannedFilter {
+			query = query.Where("banned_at IS NOT NULL")
+		} else {
+			query = query.Where("banned_at IS NULL")
+		}
+	}
+
 	// Búsqueda por email o nombre
 	if search != "" {
 		query = query.Where("email LIKE ? OR name LIKE ? OR lastname LIKE ?",
@@ -85,6 +104,16 @@ func (r *userRepository) FindByID(id int64) (*dao.UserDAO, error) {
 	return &user, nil
 }
 
+// FindByIDs obtiene los usuarios de ids en una sola consulta; los que no existen no se incluyen
+func (r *userRepository) FindByIDs(ids []int64) ([]*dao.UserDAO, error) {
+	var users []*dao.UserDAO
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("id ASC").Find(&users).Error
+	return users, err
+}
+
 func (r *userRepository) FindByEmail(email string) (*dao.UserDAO, error) {
 	var user dao.UserDAO
 	err := r.db.Where("email = ?", email).First(&user).Error
@@ -116,7 +145,10 @@ func (r *userRepository) Delete(id int64) error {
 func (r *userRepository) UpdatePassword(userID int64, newPasswordHash string) error {
 	return r.db.Model(&dao.UserDAO{}).
 		Where("id = ?", userID).
-		Update("password_hash", newPasswordHash).Error
+		Updates(map[string]interface{}{
+			"password_hash":       newPasswordHash,
+			"password_changed_at": time.Now(),
+		}).Error
 }
 
 func (r *userRepository) UpdateEmailVerified(userID int64, verified bool) error {
@@ -171,3 +203,113 @@ func (r *userRepository) UnverifyEmail(userID int64, email string) error {
 		Where("id = ?", userID).
 		Update("email_verified", false).Error
 }
+
+// FindSecurityFactorsBatch obtiene las columnas del score de seguridad de hasta limit usuarios
+// con ID mayor a afterID (paginación por keyset para recorrer toda la tabla)
+func (r *userRepository) FindSecurityFactorsBatch(afterID int64, limit int) ([]*dao.UserDAO, error) {
+	var users []*dao.UserDAO
+	err := r.db.
+		Select("id", "email_verified", "password_changed_at", "created_at").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) UpdateLastLogin(userID int64, at time.Time) error {
+	return r.db.Model(&dao.UserDAO{}).
+		Where("id = ?", userID).
+		UpdateColumn("last_login_at", at).Error
+}
+
+func (r *userRepository) UpdateMarketingEmails(userID int64, enabled bool) error {
+	return r.db.Model(&dao.UserDAO{}).
+		Where("id = ?", userID).
+		Update("marketing_emails", enabled).Error
+}
+
+// FindInactivePendingNotice obtiene hasta limit usuarios verificados sin actividad desde cutoff
+// (último login, o registro si nunca iniciaron sesión) que todavía no recibieron
+// user.inactive_30d por este período de inactividad
+// Excluye las cuentas suspendidas por un admin y las desactivadas por SCIM: no pueden volver a iniciar sesión
+func (r *userRepository) FindInactivePendingNotice(cutoff time.Time, limit int) ([]*dao.UserDAO, error) {
+	var users []*dao.UserDAO
+	err := r.db.
+		Where("email_verified = ?", true).
+		Where("active = ? AND banned_at IS NULL", true).
+		Where("COALESCE(last_login_at, created_at) <= ?", cutoff).
+		Where("inactive_notified_at IS NULL OR inactive_notified_at < COALESCE(last_login_at, created_at)").
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) MarkInactiveNotified(userID int64, at time.Time) error {
+	return r.db.Model(&dao.UserDAO{}).
+		Where("id = ?", userID).
+		UpdateColumn("inactive_notified_at", at).Error
+}
+
+// FindByProvider busca el usuario vinculado a una cuenta del proveedor de login social
+func (r *userRepository) FindByProvider(provider, providerID string) (*dao.UserDAO, error) {
+	var user dao.UserDAO
+	err := r.db.Where("provider = ? AND provider_id = ?", provider, providerID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// LinkProvider vincula la cuenta del proveedor a un usuario con el email ya verificado
+// El UPDATE es condicional: retorna false si el usuario ya tenía otro proveedor vinculado o su email no está verificado
+func (r *userRepository) LinkProvider(userID int64, provider, providerID string) (bool, error) {
+	result := r.db.Model(&dao.UserDAO{}).
+		Where("id = ? AND provider IS NULL AND email_verified = ?", userID, true).
+		Updates(map[string]interface{}{
+			"provider":    provider,
+			"provider_id": providerID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// LinkProviderResettingPassword vincula la cuenta del proveedor a un usuario con el email sin verificar
+// Quien registró la cuenta pudo no ser el dueño del email: se reemplaza la contraseña, se revocan las sesiones
+// abiertas y se invalidan los tokens de verificación y de restablecimiento pendientes, todo en una transacción
+// El UPDATE es condicional: retorna false si el usuario ya tenía proveedor o verificó el email mientras tanto
+func (r *userRepository) LinkProviderResettingPassword(userID int64, provider, providerID, passwordHash string, now time.Time) (bool, error) {
+	linked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		columns := resetPasswordColumns(passwordHash, now)
+		columns["provider"] = provider
+		columns["provider_id"] = providerID
+		columns["email_verified"] = true
+		columns["email_verification_token"] = nil
+
+		result := tx.Model(&dao.UserDAO{}).
+			Where("id = ? AND provider IS NULL AND email_verified = ?", userID, false).
+			Updates(columns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		linked = true
+
+		return invalidatePendingResetTokens(tx, userID, now)
+	})
+	return linked, err
+}
+
+// UpdateBan suspende la cuenta (bannedAt no nil) o levanta la suspensión (bannedAt nil, limpia autor y motivo)
+func (r *userRepository) UpdateBan(userID int64, bannedAt *time.Time, bannedBy *int64, reason string) error {
+	return r.db.Model(&dao.UserDAO{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"banned_at":  bannedAt,
+			"banned_by":  bannedBy,
+			"ban_reason": reason,
+		}).Error
+}
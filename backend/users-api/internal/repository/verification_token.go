@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+	"users-api/internal/dao"
+
+	"gorm.io/gorm"
+)
+
+// VerificationTokenRepository define el acceso a datos de los tokens de verificación de email
+type VerificationTokenRepository interface {
+	Create(token *dao.VerificationTokenDAO) error
+	FindByHash(tokenHash string) (*dao.VerificationTokenDAO, error)
+	CountSince(userID int64, purpose string, since time.Time) (int64, error)
+
+	// Consume marca el token como usado y verifica el email del usuario en una transacción
+	// Retorna false si el token ya se había usado (otra request lo consumió primero)
+	Consume(token *dao.VerificationTokenDAO, now time.Time) (bool, error)
+}
+
+type verificationTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationTokenRepository crea una nueva instancia del repositorio de tokens de verificación
+func NewVerificationTokenRepository(db *gorm.DB) VerificationTokenRepository {
+	return &verificationTokenRepository{db: db}
+}
+
+func (r *verificationTokenRepository) Create(token *dao.VerificationTokenDAO) error {
+	return r.db.Create(token).Error
+}
+
+func (r *verificationTokenRepository) FindByHash(tokenHash string) (*dao.VerificationTokenDAO, error) {
+	var token dao.VerificationTokenDAO
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CountSince cuenta los tokens emitidos al usuario con ese motivo desde since
+func (r *verificationTokenRepository) CountSince(userID int64, purpose string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&dao.VerificationTokenDAO{}).
+		Where("user_id = ? AND purpose = ? AND created_at >= ?", userID, purpose, since).
+		Count(&count).Error
+	return count, err
+}
+
+// Consume usa el token (UPDATE condicionado a used_at IS NULL, así dos requests no lo usan a la vez),
+// verifica el email, limpia el token heredado de la columna users.email_verification_token
+// e invalida los demás tokens pendientes del usuario
+func (r *verificationTokenRepository) Consume(token *dao.VerificationTokenDAO, now time.Time) (bool, error) {
+	consumed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&dao.VerificationTokenDAO{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		consumed = true
+
+		if err := tx.Model(&dao.UserDAO{}).
+			Where("id = ?", token.UserID).
+			Updates(map[string]interface{}{
+				"email_verified":           true,
+				"email_verification_token": nil,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&dao.VerificationTokenDAO{}).
+			Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", now).Error
+	})
+	return consumed, err
+}
@@ -1,10 +1,14 @@
 package routes
 
 import (
+	"time"
 	"users-api/internal/controller"
+	"users-api/internal/domain"
+	"users-api/internal/metrics"
 	"users-api/internal/middleware"
 	"users-api/internal/repository"
 	"users-api/internal/service"
+	"users-api/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,12 +19,29 @@ func SetupRoutes(
 	authController controller.AuthController,
 	userController controller.UserController,
 	ratingController controller.RatingController,
+	notificationController controller.NotificationController,
+	securityController controller.SecurityController,
+	preferencesController controller.PreferencesController,
+	scimController controller.SCIMController,
+	partnerController controller.PartnerController,
+	guardianController controller.GuardianController,
+	permissionController controller.PermissionController,
+	contactShareController controller.ContactShareController,
+	partnerWebhookController controller.PartnerWebhookController,
+	creditController controller.CreditController,
+	jobController controller.JobController,
 	authService service.AuthService,
+	partnerService service.PartnerService,
 	userRepo repository.UserRepository,
+	publicRateLimitPerMinute int,
+	passwordResetIPLimitPerHour int,
+	passwordResetEmailLimitPerHour int,
 ) {
 	// Middleware globales
+	router.Use(tracing.Middleware())
 	router.Use(middleware.ErrorHandler())
 	router.Use(middleware.CORSMiddleware())
+	router.Use(metrics.Middleware())
 
 	// ==================== HEALTH CHECK ====================
 	router.GET("/health", func(c *gin.Context) {
@@ -30,17 +51,41 @@ func SetupRoutes(
 		})
 	})
 
+	// ==================== MÉTRICAS ====================
+	// Prometheus (scrapeadas desde la red interna, sin autenticación)
+	router.GET("/metrics", metrics.Handler())
+
 	// ==================== RUTAS PÚBLICAS (sin autenticación) ====================
 
 	// Registro y Login
 	router.POST("/users", authController.Register)
 	router.POST("/login", authController.Login)
 
-	// Verificación de email y recuperación de contraseña
-	router.GET("/verify-email", authController.VerifyEmail)
+	// Login social (OAuth2): redirección al proveedor y callback (emite el mismo JWT que /login)
+	router.GET("/auth/:provider", authController.OAuthRedirect)
+	router.GET("/auth/:provider/callback", authController.OAuthCallback)
+
+	// Verificación de email
+	router.GET("/auth/verify", authController.VerifyEmail)
+	router.GET("/verify-email", authController.VerifyEmail) // Ruta anterior (la usa el frontend)
 	router.POST("/resend-verification", authController.ResendVerificationEmail)
-	router.POST("/forgot-password", authController.RequestPasswordReset)
-	router.POST("/reset-password", authController.ResetPassword)
+
+	// Restablecimiento de contraseña con rate limiting por IP y por email (además del límite por usuario del servicio)
+	router.POST("/forgot-password",
+		middleware.RateLimitByKey(passwordResetIPLimitPerHour, time.Hour, middleware.ClientIPKey),
+		middleware.RateLimitByKey(passwordResetEmailLimitPerHour, time.Hour, middleware.JSONEmailKey),
+		authController.RequestPasswordReset)
+	router.POST("/reset-password",
+		middleware.RateLimitByKey(passwordResetIPLimitPerHour, time.Hour, middleware.ClientIPKey),
+		authController.ResetPassword)
+
+	// Perfil público mínimo (whitelist de campos, cacheable y con rate limiting por IP)
+	// No reemplaza a GET /users/:id ni a /internal/users/:id, que devuelven el usuario completo
+	public := router.Group("/public")
+	public.Use(middleware.RateLimit(publicRateLimitPerMinute))
+	{
+		public.GET("/users/:id", userController.GetPublicProfile)
+	}
 
 	// ==================== RUTAS PROTEGIDAS (requieren JWT + Email verificado) ====================
 
@@ -52,24 +97,141 @@ func SetupRoutes(
 		protected.GET("/users/me", userController.GetMe)
 		protected.GET("/users/:id", userController.GetUserByID)
 		protected.PUT("/users/:id", userController.UpdateUser)
-		protected.DELETE("/users/:id", userController.DeleteUser)
+		protected.DELETE("/users/:id", middleware.RequirePermission(domain.PermissionAccountDelete), userController.DeleteUser)
 
 		// Calificaciones de usuario
 		protected.GET("/users/:id/ratings", ratingController.GetUserRatings)
 
+		// Perfil de conductor: nombre, foto, viajes y estadísticas de calificación en una sola llamada
+		protected.GET("/users/:id/driver-profile", ratingController.GetDriverProfile)
+
+		// Edición de una calificación por su autor (72 horas, máximo 2 ediciones)
+		protected.PUT("/ratings/:id", ratingController.UpdateRating)
+
+		// Notificaciones in-app del usuario autenticado
+		protected.GET("/users/me/notifications", notificationController.GetMyNotifications)
+		protected.GET("/users/me/notifications/unread-count", notificationController.GetUnreadCount)
+		protected.POST("/users/me/notifications/read-all", notificationController.MarkAllAsRead)
+		protected.PATCH("/users/me/notifications/:id/read", notificationController.MarkAsRead)
+		protected.DELETE("/users/me/notifications/:id", notificationController.DeleteNotification)
+
+		// Score de seguridad de la cuenta con recomendaciones
+		protected.GET("/users/me/security/score", securityController.GetMySecurityScore)
+
+		// Preferencias de notificación (consentimiento para campañas de marketing)
+		protected.GET("/users/me/notification-preferences", preferencesController.GetMyNotificationPreferences)
+		protected.PUT("/users/me/notification-preferences", preferencesController.UpdateMyNotificationPreferences)
+
+		// Perfil de preferencias: filtros de búsqueda por defecto y notificaciones in-app por tipo
+		protected.GET("/users/me/preferences", preferencesController.GetMyPreferences)
+		protected.PUT("/users/me/preferences", preferencesController.UpdateMyPreferences)
+
 		// Cambio de contraseña
 		protected.POST("/change-password", authController.ChangePassword)
+
+		// Cuentas dependientes (menores a cargo de un tutor); un dependiente no tiene dependents:manage
+		protected.GET("/users/me/dependents", middleware.RequirePermission(domain.PermissionDependentsManage), guardianController.ListDependents)
+		protected.POST("/users/me/dependents", middleware.RequirePermission(domain.PermissionDependentsManage), guardianController.CreateDependent)
+		protected.PUT("/users/me/dependents/:id", middleware.RequirePermission(domain.PermissionDependentsManage), guardianController.UpdateDependent)
+		protected.DELETE("/users/me/dependents/:id", middleware.RequirePermission(domain.PermissionDependentsManage), guardianController.DeactivateDependent)
+
+		// Aprobaciones pedidas por los dependientes (reservas) y auditoría de las acciones del tutor
+		protected.GET("/users/me/guardian/approvals", middleware.RequirePermission(domain.PermissionDependentsManage), guardianController.ListApprovals)
+		protected.POST("/users/me/guardian/approvals/:id/decision", middleware.RequirePermission(domain.PermissionDependentsManage), guardianController.DecideApproval)
+		protected.GET("/users/me/guardian/audit", middleware.RequirePermission(domain.PermissionDependentsManage), guardianController.GetMyAuditLog)
+
+		// Contacto compartido entre conductor y pasajero de una reserva confirmada (tokens temporales)
+		protected.GET("/users/me/contact-shares", contactShareController.ListMyContactShares)
+		protected.GET("/contacts/shared/:token", contactShareController.GetSharedContact)
+
+		// Partners autorizados a recibir los eventos de la cuenta (webhooks user.created/updated/deleted)
+		protected.GET("/users/me/partner-authorizations", partnerWebhookController.ListAuthorizedPartners)
+		protected.PUT("/users/me/partner-authorizations/:partner_id", partnerWebhookController.AuthorizePartner)
+		protected.DELETE("/users/me/partner-authorizations/:partner_id", partnerWebhookController.RevokePartnerAuthorization)
+
+		// Créditos promocionales: saldo por moneda, créditos vigentes y movimientos
+		protected.GET("/users/me/credits", creditController.GetMyCredits)
+		protected.GET("/users/me/credits/ledger", creditController.GetMyLedger)
 	}
 
-	// ==================== RUTAS ADMIN (requieren JWT + rol admin) ====================
+	// ==================== RUTAS ADMIN (requieren JWT + cuenta activa + el permiso de cada ruta) ====================
 
 	admin := router.Group("/admin")
 	admin.Use(middleware.AuthMiddleware(authService))
-	admin.Use(middleware.RequireAdminRole())
+	admin.Use(middleware.RequireActiveAccount(userRepo))
 	{
-		// Gestión de usuarios (solo admin)
-		admin.GET("/users", userController.GetAllUsers)
-		admin.POST("/users/:id/force-reauth", userController.ForceReauthentication)
+		// Gestión de usuarios (listado paginado con filtros ?role=&search=&banned=)
+		admin.GET("/users", middleware.RequirePermission(domain.PermissionAdminUsers), userController.GetAllUsers)
+		admin.POST("/users/:id/force-reauth", middleware.RequirePermission(domain.PermissionAdminUsers), userController.ForceReauthentication)
+
+		// Suspensión de cuentas: la cuenta no puede iniciar sesión y sus JWT vigentes dejan de funcionar
+		admin.POST("/users/:id/ban", middleware.RequirePermission(domain.PermissionAdminUsers), userController.BanUser)
+		admin.POST("/users/:id/unban", middleware.RequirePermission(domain.PermissionAdminUsers), userController.UnbanUser)
+
+		// Mensajes de sistema (notificación in-app)
+		admin.POST("/users/:id/notifications", middleware.RequirePermission(domain.PermissionAdminNotify), notificationController.SendSystemNotification)
+
+		// Distribución de scores de seguridad (adopción de 2FA, passkeys, etc.)
+		admin.GET("/security/score-distribution", middleware.RequirePermission(domain.PermissionAdminSecurity), securityController.GetScoreDistribution)
+
+		// Historial de ediciones de una calificación
+		admin.GET("/ratings/:id/history", middleware.RequirePermission(domain.PermissionRatingsModerate), ratingController.GetRatingHistory)
+
+		// Partners corporativos y sus API keys de provisión SCIM
+		admin.POST("/partners", middleware.RequirePermission(domain.PermissionAdminPartners), partnerController.CreatePartner)
+		admin.GET("/partners", middleware.RequirePermission(domain.PermissionAdminPartners), partnerController.ListPartners)
+		admin.DELETE("/partners/:id", middleware.RequirePermission(domain.PermissionAdminPartners), partnerController.RevokePartner)
+		admin.PUT("/partners/:id/domains", middleware.RequirePermission(domain.PermissionAdminPartners), partnerController.UpdateVerifiedDomains)
+
+		// Auditoría de las acciones de tutores sobre cuentas dependientes
+		admin.GET("/guardian-audit", middleware.RequirePermission(domain.PermissionAdminGuardians), guardianController.GetAuditLogs)
+
+		// Registro de permisos y permisos individuales de cada usuario
+		admin.GET("/permissions", middleware.RequirePermission(domain.PermissionAdminPermissions), permissionController.GetRegistry)
+		admin.GET("/users/:id/permissions", middleware.RequirePermission(domain.PermissionAdminPermissions), permissionController.GetUserPermissions)
+		admin.PUT("/users/:id/permissions", middleware.RequirePermission(domain.PermissionAdminPermissions), permissionController.UpdateUserPermissions)
+
+		// Créditos promocionales con vencimiento
+		admin.POST("/users/:id/credits", middleware.RequirePermission(domain.PermissionAdminCredits), creditController.GrantCredit)
+		admin.GET("/users/:id/credits", middleware.RequirePermission(domain.PermissionAdminCredits), creditController.GetUserCredits)
+
+		// Cola de jobs en segundo plano
+		admin.GET("/jobs", middleware.RequirePermission(domain.PermissionAdminJobs), jobController.ListJobs)
+		admin.POST("/jobs/:id/requeue", middleware.RequirePermission(domain.PermissionAdminJobs), jobController.RequeueJob)
+	}
+
+	// ==================== PROVISIÓN SCIM (requieren API key de partner) ====================
+
+	// Subconjunto de SCIM 2.0 para que los partners sincronicen empleados y organizaciones
+	scim := router.Group("/scim/v2")
+	scim.Use(middleware.RequirePartnerAPIKey(partnerService))
+	{
+		scim.GET("/Users", scimController.ListUsers)
+		scim.POST("/Users", scimController.CreateUser)
+		scim.GET("/Users/:id", scimController.GetUser)
+		scim.PATCH("/Users/:id", scimController.PatchUser)
+		scim.DELETE("/Users/:id", scimController.DeleteUser)
+
+		scim.GET("/Groups", scimController.ListGroups)
+		scim.POST("/Groups", scimController.CreateGroup)
+		scim.GET("/Groups/:id", scimController.GetGroup)
+		scim.PATCH("/Groups/:id", scimController.PatchGroup)
+		scim.DELETE("/Groups/:id", scimController.DeleteGroup)
+	}
+
+	// ==================== API DE PARTNERS (requieren API key de partner) ====================
+
+	// Webhooks sobre el ciclo de vida de los usuarios que autorizaron al partner (firmados con HMAC-SHA256)
+	partner := router.Group("/partner/v1")
+	partner.Use(middleware.RequirePartnerAPIKeyJSON(partnerService))
+	{
+		partner.GET("/webhooks", partnerWebhookController.ListWebhooks)
+		partner.POST("/webhooks", partnerWebhookController.CreateWebhook)
+		partner.PATCH("/webhooks/:id", partnerWebhookController.UpdateWebhook)
+		partner.DELETE("/webhooks/:id", partnerWebhookController.DeleteWebhook)
+		partner.POST("/webhooks/:id/rotate-secret", partnerWebhookController.RotateSecret)
+		partner.GET("/webhooks/:id/deliveries", partnerWebhookController.ListDeliveries)
+		partner.POST("/webhooks/:id/deliveries/:delivery_id/retry", partnerWebhookController.RetryDelivery)
 	}
 
 	// ==================== RUTAS INTERNAS (sin autenticación, para comunicación entre servicios) ====================
@@ -79,7 +241,27 @@ func SetupRoutes(
 		// Obtener usuario (llamado desde search-api y otros servicios)
 		internal.GET("/users/:id", userController.GetUserByID)
 
+		// Obtener varios usuarios en una request, ?ids=1,2,3 (llamado desde bookings-api)
+		internal.GET("/users", userController.GetUsersByIDs)
+
+		// Revocación de sesiones, desactivación y suspensión (middleware JWT de trips-api, bookings-api y search-api)
+		internal.GET("/users/:id/session-status", userController.GetSessionStatus)
+
+		// Perfil de conductor para desnormalizar (llamado desde search-api)
+		internal.GET("/users/:id/driver-profile", ratingController.GetDriverProfile)
+
+		// Filtros de búsqueda por defecto del usuario (llamado desde search-api)
+		internal.GET("/users/:id/preferences", preferencesController.GetUserPreferences)
+
 		// Crear calificación (llamado desde trips-api)
 		internal.POST("/ratings", ratingController.CreateRating)
+
+		// Aprobación del tutor para acciones de un dependiente (llamado desde bookings-api)
+		internal.POST("/guardian-approvals", guardianController.RequestApproval)
+		internal.GET("/guardian-approvals/:id", guardianController.GetApproval)
+
+		// Aplicar créditos a la tarifa de una reserva, idempotente por booking_id (llamado desde bookings-api)
+		internal.POST("/credits/consume", creditController.ConsumeCredits)
+		internal.POST("/credits/release", creditController.ReleaseCredits)
 	}
 }
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
+	"log"
+	"strings"
 	"time"
 	"users-api/internal/dao"
 	"users-api/internal/domain"
@@ -27,23 +31,42 @@ type AuthService interface {
 	ResendVerificationEmail(email string) error
 
 	// Gestión de contraseña
-	RequestPasswordReset(email string) error
+	RequestPasswordReset(email, requestedIP string) error
 	ResetPassword(token, newPassword string) error
 	ChangePassword(userID int64, currentPassword, newPassword string) error
+
+	// Login social (OAuth2)
+	OAuthStart(provider string) (authURL, state string, err error)
+	OAuthLogin(ctx context.Context, provider, code, state, expectedState string) (*domain.LoginResponse, error)
 }
 
 type authService struct {
-	userRepo     repository.UserRepository
-	emailService EmailService
-	jwtSecret    string
+	userRepo         repository.UserRepository
+	tokenRepo        repository.VerificationTokenRepository
+	resetTokenRepo   repository.PasswordResetTokenRepository
+	permissions      PermissionService
+	emailService     EmailService
+	lifecycleService LifecycleService
+	jwtSecret        string
+	oauthProviders   map[string]OAuthProvider
 }
 
 // NewAuthService crea una nueva instancia del servicio de autenticación
-func NewAuthService(userRepo repository.UserRepository, emailService EmailService, jwtSecret string) AuthService {
+// oauthProviders son los proveedores de login social habilitados (ninguno: solo contraseña)
+func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.VerificationTokenRepository, resetTokenRepo repository.PasswordResetTokenRepository, permissions PermissionService, emailService EmailService, lifecycleService LifecycleService, jwtSecret string, oauthProviders ...OAuthProvider) AuthService {
+	providers := make(map[string]OAuthProvider, len(oauthProviders))
+	for _, provider := range oauthProviders {
+		providers[provider.Name()] = provider
+	}
 	return &authService{
-		userRepo:     userRepo,
-		emailService: emailService,
-		jwtSecret:    jwtSecret,
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		resetTokenRepo:   resetTokenRepo,
+		permissions:      permissions,
+		emailService:     emailService,
+		lifecycleService: lifecycleService,
+		jwtSecret:        jwtSecret,
+		oauthProviders:   providers,
 	}
 }
 
@@ -72,33 +95,42 @@ func (s *authService) Register(req domain.CreateUserRequest) (*domain.UserDTO, e
 		return nil, errors.New("formato de fecha inválido, usar YYYY-MM-DD")
 	}
 
-	// Generar token de verificación
-	verificationToken, err := s.emailService.GenerateToken()
+	// País, moneda y unidad de distancia: los enviados o los inferidos del locale
+	regional, err := registrationRegionalSettings(req)
 	if err != nil {
 		return nil, err
 	}
 
 	// Crear el usuario
 	userDAO := &dao.UserDAO{
-		Email:                  req.Email,
-		EmailVerified:          false,
-		EmailVerificationToken: &verificationToken,
-		Name:                   req.Name,
-		Lastname:               req.Lastname,
-		PasswordHash:           string(hashedPassword),
-		Role:                   "user",
-		Phone:                  req.Phone,
-		Street:                 req.Street,
-		Number:                 req.Number,
-		PhotoURL:               req.PhotoURL,
-		Sex:                    req.Sex,
-		Birthdate:              birthdate,
+		Email:           req.Email,
+		EmailVerified:   false,
+		Name:            req.Name,
+		Lastname:        req.Lastname,
+		PasswordHash:    string(hashedPassword),
+		Role:            "user",
+		Phone:           req.Phone,
+		Street:          req.Street,
+		Number:          req.Number,
+		PhotoURL:        req.PhotoURL,
+		Sex:             req.Sex,
+		Birthdate:       birthdate,
+		Country:         regional.Country,
+		Currency:        regional.Currency,
+		DistanceUnit:    regional.DistanceUnit,
+		MarketingEmails: req.MarketingEmails,
 	}
 
 	if err := s.userRepo.Create(userDAO); err != nil {
 		return nil, err
 	}
 
+	// Generar token de verificación (tabla verification_tokens, vence en 24 horas)
+	verificationToken, err := issueVerificationToken(s.tokenRepo, s.emailService, userDAO.ID, domain.VerificationPurposeRegister)
+	if err != nil {
+		return nil, err
+	}
+
 	// Enviar email de verificación de forma asíncrona con manejo de errores
 	go func() {
 		if err := s.emailService.SendVerificationEmail(req.Email, verificationToken); err != nil {
@@ -108,6 +140,9 @@ func (s *authService) Register(req domain.CreateUserRequest) (*domain.UserDTO, e
 		}
 	}()
 
+	// Evento user.registered para la campaña de bienvenida (asíncrono)
+	s.lifecycleService.UserRegistered(userDAO.ID)
+
 	// Convertir a DTO y retornar
 	return s.convertToDTO(userDAO), nil
 }
@@ -134,31 +169,258 @@ func (s *authService) Login(req domain.LoginRequest) (*domain.LoginResponse, err
 		return nil, errors.New("debes verificar tu correo electrónico antes de iniciar sesión. Revisa tu bandeja de entrada")
 	}
 
+	if err := checkCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	return s.loginResponse(user)
+}
+
+// checkCanLogin rechaza las cuentas que no pueden iniciar sesión con ningún método
+func checkCanLogin(user *dao.UserDAO) error {
+	// Las cuentas desactivadas por un partner (SCIM) no pueden iniciar sesión
+	if !user.Active {
+		return errors.New("la cuenta está desactivada")
+	}
+
+	// Tampoco las suspendidas por un admin
+	if user.IsBanned() {
+		return errors.New("la cuenta está suspendida")
+	}
+	return nil
+}
+
+// loginResponse emite el JWT del usuario y registra el login (común a contraseña y login social)
+func (s *authService) loginResponse(user *dao.UserDAO) (*domain.LoginResponse, error) {
 	// Generar JWT (incluir nombre completo para chat y otras funciones)
 	fullName := user.Name + " " + user.Lastname
-	token, err := s.GenerateJWT(user.ID, user.Email, user.Role, fullName)
+	claims := jwtClaims(user.ID, user.Email, user.Role, fullName)
+
+	// Permisos del rol más los individuales del usuario (bitmap compacto, ver domain.EncodePermissions)
+	permissions, err := s.permissions.EffectivePermissions(user)
 	if err != nil {
 		return nil, err
 	}
+	claims["perms"] = domain.EncodePermissions(permissions)
+
+	// Cuentas dependientes: el tutor y las restricciones viajan en el token para que
+	// trips-api y bookings-api las apliquen (sin conducir, reservas con aprobación del tutor)
+	if user.Role == domain.RoleDependent && user.GuardianID != nil {
+		claims["guardian_id"] = *user.GuardianID
+		claims["restrictions"] = domain.DependentRestrictions
+	}
+
+	token, err := s.signJWT(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// Registrar la actividad (base del evento user.inactive_30d); no bloquea el login
+	if err := s.userRepo.UpdateLastLogin(user.ID, time.Now()); err != nil {
+		log.Printf("Error registrando último login (user_id=%d): %v", user.ID, err)
+	}
 
 	return &domain.LoginResponse{
-		Token: token,
-		User:  s.convertToDTO(user),
+		Token:       token,
+		User:        s.convertToDTO(user),
+		Permissions: permissions.List(),
 	}, nil
 }
 
+// ==================== LOGIN SOCIAL (OAuth2) ====================
+
+// OAuthStart genera el state y la URL del proveedor a la que se redirige al usuario
+func (s *authService) OAuthStart(provider string) (string, string, error) {
+	p, ok := s.oauthProviders[provider]
+	if !ok {
+		return "", "", domain.ErrOAuthProviderUnknown
+	}
+
+	state, err := generateOAuthState()
+	if err != nil {
+		return "", "", err
+	}
+	return p.AuthCodeURL(state), state, nil
+}
+
+// OAuthLogin valida el state, canjea el código y emite el mismo JWT que el login con contraseña
+//
+//   - Una cuenta del proveedor ya vinculada inicia sesión con su usuario
+//   - Si no, se vincula al usuario con el mismo email (solo si el proveedor lo verificó)
+//   - Sin usuario con ese email se crea uno, con el email verificado y una contraseña aleatoria
+//     (puede elegir una con /forgot-password)
+func (s *authService) OAuthLogin(ctx context.Context, provider, code, state, expectedState string) (*domain.LoginResponse, error) {
+	p, ok := s.oauthProviders[provider]
+	if !ok {
+		return nil, domain.ErrOAuthProviderUnknown
+	}
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(expectedState)) != 1 {
+		return nil, domain.ErrOAuthStateInvalid
+	}
+	if code == "" {
+		return nil, domain.ErrOAuthCodeInvalid
+	}
+
+	identity, err := p.Exchange(ctx, code)
+	if err != nil {
+		log.Printf("Error en el login con %s: %v", provider, err)
+		return nil, domain.ErrOAuthCodeInvalid
+	}
+	if identity.ProviderID == "" {
+		return nil, domain.ErrOAuthCodeInvalid
+	}
+
+	user, err := s.userRepo.FindByProvider(provider, identity.ProviderID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if user, err = s.linkOrCreateOAuthUser(identity); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkCanLogin(user); err != nil {
+		return nil, err
+	}
+
+	return s.loginResponse(user)
+}
+
+// linkOrCreateOAuthUser vincula la cuenta del proveedor al usuario con su email o crea uno nuevo
+func (s *authService) linkOrCreateOAuthUser(identity *domain.OAuthIdentity) (*dao.UserDAO, error) {
+	// Vincular por email sin verificación del proveedor permitiría tomar cuentas ajenas
+	email := strings.TrimSpace(identity.Email)
+	if email == "" || !identity.EmailVerified {
+		return nil, domain.ErrOAuthEmailNotVerified
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err == nil {
+		return s.linkOAuthUser(user, identity)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Contraseña aleatoria, como en la provisión SCIM: la columna es obligatoria y nadie la conoce
+	hashedPassword, err := s.randomPasswordHash()
+	if err != nil {
+		return nil, err
+	}
+
+	// photo_url es varchar(255): una URL más larga se descarta
+	photoURL := identity.PhotoURL
+	if len(photoURL) > 255 {
+		photoURL = ""
+	}
+
+	defaults, _ := domain.DefaultsForCountry(domain.DefaultCountry)
+	user = &dao.UserDAO{
+		Email:         email,
+		EmailVerified: true,
+		Name:          strings.TrimSpace(identity.Name),
+		Lastname:      strings.TrimSpace(identity.Lastname),
+		PasswordHash:  hashedPassword,
+		Role:          "user",
+		PhotoURL:      photoURL,
+		Sex:           "otro",
+		Birthdate:     provisionedBirthdate, // El proveedor tampoco informa la fecha de nacimiento
+		Country:       domain.DefaultCountry,
+		Currency:      defaults.Currency,
+		DistanceUnit:  defaults.DistanceUnit,
+		Active:        true,
+		Provider:      &identity.Provider,
+		ProviderID:    &identity.ProviderID,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	// Evento user.registered para la campaña de bienvenida (asíncrono)
+	s.lifecycleService.UserRegistered(user.ID)
+
+	return user, nil
+}
+
+// linkOAuthUser vincula la cuenta del proveedor al usuario que ya tiene su email
+//
+//   - Email verificado: se vincula sin tocar la cuenta
+//   - Email sin verificar: la cuenta pudo registrarla otra persona con el email de la víctima antes de que
+//     ella inicie sesión con el proveedor (pre-hijacking). El proveedor acredita que el email es de quien
+//     inicia sesión, así que se vincula descartando la contraseña y las sesiones abiertas de la cuenta
+func (s *authService) linkOAuthUser(user *dao.UserDAO, identity *domain.OAuthIdentity) (*dao.UserDAO, error) {
+	var linked bool
+	var err error
+	if user.EmailVerified {
+		linked, err = s.userRepo.LinkProvider(user.ID, identity.Provider, identity.ProviderID)
+	} else {
+		var hashedPassword string
+		if hashedPassword, err = s.randomPasswordHash(); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		linked, err = s.userRepo.LinkProviderResettingPassword(user.ID, identity.Provider, identity.ProviderID, hashedPassword, now)
+		if linked {
+			revokedAt := now.Truncate(time.Second)
+			user.PasswordHash = hashedPassword
+			user.PasswordChangedAt = &now
+			user.SessionsRevokedAt = &revokedAt
+			user.EmailVerified = true
+			log.Printf("Email sin verificar: contraseña reemplazada y sesiones revocadas al vincular %s (user_id=%d)", identity.Provider, user.ID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !linked {
+		return nil, domain.ErrOAuthAccountLinked
+	}
+	log.Printf("Cuenta de %s vinculada (user_id=%d)", identity.Provider, user.ID)
+
+	user.Provider = &identity.Provider
+	user.ProviderID = &identity.ProviderID
+	return user, nil
+}
+
+// randomPasswordHash genera el hash de una contraseña aleatoria que nadie conoce (se elige otra con /forgot-password)
+func (s *authService) randomPasswordHash() (string, error) {
+	randomPassword, err := s.emailService.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), 10)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
 // ==================== JWT ====================
 
 // GenerateJWT genera un token JWT con 24 horas de expiración
 func (s *authService) GenerateJWT(userID int64, email, role, name string) (string, error) {
-	claims := jwt.MapClaims{
+	return s.signJWT(jwtClaims(userID, email, role, name))
+}
+
+// jwtClaims arma los claims comunes a todos los tokens
+// "perms" lleva los permisos por defecto del rol; Login los reemplaza por los efectivos del usuario
+// "iat" permite revocar los tokens emitidos antes de un restablecimiento de contraseña (sessions_revoked_at)
+func jwtClaims(userID int64, email, role, name string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
 		"user_id": userID,
 		"email":   email,
 		"role":    role,
 		"name":    name,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(), // 24 horas
+		"perms":   domain.EncodePermissions(domain.EffectivePermissions(role, nil, nil)),
+		"iat":     now.Unix(),
+		"exp":     now.Add(24 * time.Hour).Unix(), // 24 horas
 	}
+}
 
+// signJWT firma los claims con HS256
+func (s *authService) signJWT(claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(s.jwtSecret))
 }
@@ -186,12 +448,46 @@ func (s *authService) ValidateToken(tokenString string) (*jwt.Token, error) {
 // ==================== VERIFICACIÓN DE EMAIL ====================
 
 // VerifyEmail verifica el email de un usuario usando el token
+// Cada token se puede usar una sola vez y vence a las 24 horas de emitido
 func (s *authService) VerifyEmail(token string) error {
-	// Buscar usuario por token de verificación
+	if token == "" {
+		return domain.ErrVerificationTokenInvalid
+	}
+
+	stored, err := s.tokenRepo.FindByHash(hashVerificationToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.verifyLegacyToken(token)
+		}
+		return err
+	}
+
+	if stored.UsedAt != nil {
+		return domain.ErrVerificationTokenUsed
+	}
+	now := time.Now()
+	if now.After(stored.ExpiresAt) {
+		return domain.ErrVerificationTokenExpired
+	}
+
+	// El UPDATE es condicional: si dos requests llegan con el mismo token, solo una lo usa
+	consumed, err := s.tokenRepo.Consume(stored, now)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return domain.ErrVerificationTokenUsed
+	}
+	return nil
+}
+
+// verifyLegacyToken acepta los tokens emitidos antes de la tabla verification_tokens
+// (columna users.email_verification_token, sin vencimiento)
+func (s *authService) verifyLegacyToken(token string) error {
 	user, err := s.userRepo.FindByEmailVerificationToken(token)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("token de verificación inválido")
+			return domain.ErrVerificationTokenInvalid
 		}
 		return err
 	}
@@ -221,14 +517,18 @@ func (s *authService) ResendVerificationEmail(email string) error {
 		return errors.New("el email ya está verificado")
 	}
 
-	// Generar nuevo token
-	token, err := s.emailService.GenerateToken()
+	// Máximo 3 reenvíos por hora por usuario
+	sent, err := s.tokenRepo.CountSince(user.ID, domain.VerificationPurposeResend, time.Now().Add(-domain.VerificationResendWindow))
 	if err != nil {
 		return err
 	}
+	if sent >= domain.MaxVerificationResends {
+		return domain.ErrVerificationResendThrottle
+	}
 
-	// Guardar nuevo token
-	if err := s.userRepo.SaveEmailVerificationToken(user.ID, token); err != nil {
+	// Generar nuevo token (los anteriores siguen valiendo hasta que venzan o se verifique el email)
+	token, err := issueVerificationToken(s.tokenRepo, s.emailService, user.ID, domain.VerificationPurposeResend)
+	if err != nil {
 		return err
 	}
 
@@ -246,7 +546,8 @@ func (s *authService) ResendVerificationEmail(email string) error {
 // ==================== GESTIÓN DE CONTRASEÑA ====================
 
 // RequestPasswordReset envía un email con el token para restablecer contraseña
-func (s *authService) RequestPasswordReset(email string) error {
+// Cada token se guarda hasheado en password_reset_tokens, vence a la hora y se usa una sola vez
+func (s *authService) RequestPasswordReset(email, requestedIP string) error {
 	// Buscar usuario por email
 	user, err := s.userRepo.FindByEmail(email)
 	if err != nil {
@@ -255,15 +556,19 @@ func (s *authService) RequestPasswordReset(email string) error {
 		return nil
 	}
 
-	// Generar token de reset
-	token, err := s.emailService.GenerateToken()
+	// Máximo 3 emails por hora por usuario; al superarlo la respuesta es la misma (no revela que el email existe)
+	sent, err := s.resetTokenRepo.CountSince(user.ID, time.Now().Add(-domain.PasswordResetWindow))
 	if err != nil {
 		return err
 	}
+	if sent >= domain.MaxPasswordResetRequests {
+		log.Printf("Restablecimiento de contraseña limitado (user_id=%d, ip=%s)", user.ID, requestedIP)
+		return nil
+	}
 
-	// Guardar token con expiración de 1 hora
-	expiresAt := time.Now().Add(1 * time.Hour)
-	if err := s.userRepo.SavePasswordResetToken(user.ID, token, expiresAt); err != nil {
+	// Generar token de reset (los anteriores siguen valiendo hasta que venzan o se use alguno)
+	token, err := issuePasswordResetToken(s.resetTokenRepo, s.emailService, user.ID, domain.PasswordResetTokenTTL, requestedIP)
+	if err != nil {
 		return err
 	}
 
@@ -279,34 +584,85 @@ func (s *authService) RequestPasswordReset(email string) error {
 }
 
 // ResetPassword cambia la contraseña usando el token de reset
+// Al cambiarla se invalidan los demás tokens pendientes y se revocan todas las sesiones abiertas
 func (s *authService) ResetPassword(token, newPassword string) error {
-	// Buscar usuario por token de reset (valida que no esté expirado)
-	user, err := s.userRepo.FindByPasswordResetToken(token)
+	if token == "" {
+		return domain.ErrPasswordResetTokenInvalid
+	}
+
+	stored, err := s.resetTokenRepo.FindByHash(hashVerificationToken(token))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("token de reset inválido o expirado")
+			return s.resetLegacyPassword(token, newPassword)
 		}
 		return err
 	}
 
-	// Validar longitud mínima de contraseña
-	if len(newPassword) < 8 {
-		return errors.New("la contraseña debe tener al menos 8 caracteres")
+	if stored.UsedAt != nil {
+		return domain.ErrPasswordResetTokenUsed
+	}
+	now := time.Now()
+	if now.After(stored.ExpiresAt) {
+		return domain.ErrPasswordResetTokenExpired
 	}
 
-	// Hashear nueva contraseña con bcrypt cost 10
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
+	hashedPassword, err := hashNewPassword(newPassword)
 	if err != nil {
 		return err
 	}
 
-	// Actualizar contraseña
-	if err := s.userRepo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+	// El UPDATE es condicional: si dos requests llegan con el mismo token, solo una cambia la contraseña
+	consumed, err := s.resetTokenRepo.Consume(stored, hashedPassword, now)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return domain.ErrPasswordResetTokenUsed
+	}
+
+	log.Printf("Contraseña restablecida, sesiones revocadas (user_id=%d)", stored.UserID)
+	return nil
+}
+
+// resetLegacyPassword acepta los tokens emitidos antes de la tabla password_reset_tokens
+// (columna users.password_reset_token, la consulta ya descarta los vencidos)
+func (s *authService) resetLegacyPassword(token, newPassword string) error {
+	user, err := s.userRepo.FindByPasswordResetToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrPasswordResetTokenInvalid
+		}
+		return err
+	}
+
+	hashedPassword, err := hashNewPassword(newPassword)
+	if err != nil {
 		return err
 	}
 
-	// Limpiar token de reset
-	return s.userRepo.ClearPasswordResetToken(user.ID)
+	consumed, err := s.resetTokenRepo.ConsumeLegacy(user.ID, token, hashedPassword, time.Now())
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return domain.ErrPasswordResetTokenUsed
+	}
+
+	log.Printf("Contraseña restablecida, sesiones revocadas (user_id=%d)", user.ID)
+	return nil
+}
+
+// hashNewPassword valida la longitud mínima de la contraseña y la hashea con bcrypt cost 10
+func hashNewPassword(newPassword string) (string, error) {
+	if len(newPassword) < 8 {
+		return "", errors.New("la contraseña debe tener al menos 8 caracteres")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), 10)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
 }
 
 // ChangePassword permite al usuario cambiar su contraseña estando autenticado
@@ -359,6 +715,11 @@ func (s *authService) convertToDTO(userDAO *dao.UserDAO) *domain.UserDTO {
 		TotalTripsPassenger: userDAO.TotalTripsPassenger,
 		TotalTripsDriver:    userDAO.TotalTripsDriver,
 		Birthdate:           userDAO.Birthdate,
+		Country:             userDAO.Country,
+		Currency:            userDAO.Currency,
+		DistanceUnit:        userDAO.DistanceUnit,
+		GuardianID:          userDAO.GuardianID,
+		Restrictions:        userRestrictions(userDAO),
 		CreatedAt:           userDAO.CreatedAt,
 		UpdatedAt:           userDAO.UpdatedAt,
 	}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ContactShareService define las operaciones de los tokens de contacto compartido
+//
+// Al confirmarse una reserva se emite un token para el conductor (ve el teléfono del pasajero)
+// y otro para el pasajero (ve el teléfono del conductor). Los tokens vencen a las ttl horas y
+// se revocan antes si el viaje termina o se cancela, o si se cancela la reserva
+type ContactShareService interface {
+	// IssueForBooking emite los dos tokens de una reserva confirmada (idempotente por reserva y usuario)
+	IssueForBooking(booking domain.MatchedBooking) error
+
+	// RevokeForTrip revoca los tokens de todas las reservas del viaje
+	RevokeForTrip(tripID, reason string) error
+
+	// RevokeForBooking revoca los tokens de una reserva
+	RevokeForBooking(bookingID, reason string) error
+
+	// GetSharedContact retorna el contacto al que da acceso el token, solo para el usuario al que se emitió
+	GetSharedContact(viewerID int64, token string) (*domain.SharedContactDTO, error)
+
+	// ListMyContactShares lista los tokens vigentes del usuario (tripID vacío no filtra por viaje)
+	ListMyContactShares(viewerID int64, tripID string) ([]domain.ContactShareDTO, error)
+}
+
+type contactShareService struct {
+	contactShareRepo repository.ContactShareRepository
+	userRepo         repository.UserRepository
+	ttl              time.Duration
+}
+
+// NewContactShareService crea una nueva instancia del servicio de tokens de contacto
+func NewContactShareService(contactShareRepo repository.ContactShareRepository, userRepo repository.UserRepository, ttl time.Duration) ContactShareService {
+	return &contactShareService{
+		contactShareRepo: contactShareRepo,
+		userRepo:         userRepo,
+		ttl:              ttl,
+	}
+}
+
+func (s *contactShareService) IssueForBooking(booking domain.MatchedBooking) error {
+	expiresAt := time.Now().Add(s.ttl)
+
+	sides := []struct {
+		viewerID      int64
+		contactUserID int64
+		contactRole   string
+	}{
+		{viewerID: booking.DriverID, contactUserID: booking.PassengerID, contactRole: domain.ContactRolePassenger},
+		{viewerID: booking.PassengerID, contactUserID: booking.DriverID, contactRole: domain.ContactRoleDriver},
+	}
+
+	for _, side := range sides {
+		token, err := generateContactShareToken()
+		if err != nil {
+			return err
+		}
+
+		created, err := s.contactShareRepo.Create(&dao.ContactShareTokenDAO{
+			Token:         token,
+			TripID:        booking.TripID,
+			BookingID:     booking.BookingID,
+			ViewerID:      side.viewerID,
+			ContactUserID: side.contactUserID,
+			ContactRole:   side.contactRole,
+			ExpiresAt:     expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+		if !created {
+			log.Printf("Token de contacto ya emitido (booking_id=%s, viewer_id=%d)", booking.BookingID, side.viewerID)
+		}
+	}
+
+	return nil
+}
+
+func (s *contactShareService) RevokeForTrip(tripID, reason string) error {
+	revoked, err := s.contactShareRepo.RevokeByTrip(tripID, reason, time.Now())
+	if err != nil {
+		return err
+	}
+	if revoked > 0 {
+		log.Printf("Tokens de contacto revocados (trip_id=%s, motivo=%s, cantidad=%d)", tripID, reason, revoked)
+	}
+	return nil
+}
+
+func (s *contactShareService) RevokeForBooking(bookingID, reason string) error {
+	revoked, err := s.contactShareRepo.RevokeByBooking(bookingID, reason, time.Now())
+	if err != nil {
+		return err
+	}
+	if revoked > 0 {
+		log.Printf("Tokens de contacto revocados (booking_id=%s, motivo=%s, cantidad=%d)", bookingID, reason, revoked)
+	}
+	return nil
+}
+
+// GetSharedContact valida el token y lee el teléfono actual del otro usuario
+// Un token de otro usuario responde igual que uno inexistente, así no se puede sondear
+func (s *contactShareService) GetSharedContact(viewerID int64, token string) (*domain.SharedContactDTO, error) {
+	share, err := s.contactShareRepo.FindByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrContactShareNotFound
+		}
+		return nil, err
+	}
+	if share.ViewerID != viewerID {
+		return nil, domain.ErrContactShareNotFound
+	}
+	if share.RevokedAt != nil {
+		return nil, domain.ErrContactShareRevoked
+	}
+	if !time.Now().Before(share.ExpiresAt) {
+		return nil, domain.ErrContactShareExpired
+	}
+
+	contact, err := s.userRepo.FindByID(share.ContactUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrContactShareNotFound
+		}
+		return nil, err
+	}
+
+	return &domain.SharedContactDTO{
+		UserID:    contact.ID,
+		Name:      contact.Name,
+		Lastname:  contact.Lastname,
+		Phone:     contact.Phone,
+		Role:      share.ContactRole,
+		TripID:    share.TripID,
+		ExpiresAt: share.ExpiresAt,
+	}, nil
+}
+
+func (s *contactShareService) ListMyContactShares(viewerID int64, tripID string) ([]domain.ContactShareDTO, error) {
+	shares, err := s.contactShareRepo.FindActiveByViewer(viewerID, tripID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]domain.ContactShareDTO, 0, len(shares))
+	for _, share := range shares {
+		dtos = append(dtos, domain.ContactShareDTO{
+			Token:         share.Token,
+			TripID:        share.TripID,
+			BookingID:     share.BookingID,
+			ContactUserID: share.ContactUserID,
+			ContactRole:   share.ContactRole,
+			ExpiresAt:     share.ExpiresAt,
+		})
+	}
+	return dtos, nil
+}
+
+// generateContactShareToken genera un token aleatorio de 32 bytes en hexadecimal (64 caracteres)
+func generateContactShareToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// CreditEventPublisher publica los avisos de vencimiento de créditos (implementado en messaging)
+type CreditEventPublisher interface {
+	PublishCreditEvent(ctx context.Context, event domain.CreditEvent) error
+}
+
+// CreditService define los créditos promocionales con vencimiento: otorgamiento (admin), saldo y
+// ledger del usuario, consumo idempotente por reserva (bookings-api) y vencimiento programado
+type CreditService interface {
+	GrantCredit(adminID, userID int64, req domain.GrantCreditRequest) (*domain.CreditGrantDTO, error)
+	GetCredits(userID int64) (*domain.CreditsSummaryDTO, error)
+	ListLedger(userID int64, page, limit int) ([]domain.CreditLedgerEntryDTO, int64, error)
+
+	// ConsumeCredits aplica el saldo del usuario a la tarifa de una reserva
+	// Retorna true si el consumo es nuevo y false si la reserva ya había aplicado créditos
+	ConsumeCredits(req domain.ConsumeCreditsRequest) (*domain.CreditConsumptionDTO, bool, error)
+
+	// ReleaseCredits devuelve al usuario los créditos aplicados a una reserva cancelada
+	// Retorna true si la devolución es nueva y false si ya se había devuelto
+	ReleaseCredits(req domain.ReleaseCreditsRequest) (*domain.CreditReleaseDTO, bool, error)
+
+	// ProcessExpirations avisa los créditos que vencen pronto y vence los créditos vencidos
+	// Retorna la cantidad de avisos y de créditos vencidos
+	ProcessExpirations() (int, int, error)
+
+	// StartExpiryJob ejecuta ProcessExpirations periódicamente hasta que ctx se cancele
+	StartExpiryJob(ctx context.Context, interval time.Duration)
+}
+
+type creditService struct {
+	creditRepo          repository.CreditRepository
+	userRepo            repository.UserRepository
+	notificationService NotificationService
+	publisher           CreditEventPublisher
+	batchSize           int
+}
+
+// NewCreditService crea una nueva instancia del servicio de créditos
+// publisher puede ser nil (sin RabbitMQ los avisos solo llegan como notificación in-app)
+// batchSize limita los créditos avisados y vencidos por ejecución del job
+func NewCreditService(creditRepo repository.CreditRepository, userRepo repository.UserRepository, notificationService NotificationService, publisher CreditEventPublisher, batchSize int) CreditService {
+	if batchSize <= 0 {
+		batchSize = 200
+	}
+	return &creditService{
+		creditRepo:          creditRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		publisher:           publisher,
+		batchSize:           batchSize,
+	}
+}
+
+// ==================== OTORGAMIENTO Y SALDO ====================
+
+// GrantCredit otorga un crédito al usuario con la vigencia indicada (expires_at o valid_days)
+func (s *creditService) GrantCredit(adminID, userID int64, req domain.GrantCreditRequest) (*domain.CreditGrantDTO, error) {
+	if _, err := s.findUser(userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var expiresAt time.Time
+	switch {
+	case req.ExpiresAt != nil && req.ValidDays > 0:
+		return nil, errors.New("indicar expires_at o valid_days, no ambos")
+	case req.ExpiresAt != nil:
+		expiresAt = *req.ExpiresAt
+	case req.ValidDays > 0:
+		expiresAt = now.AddDate(0, 0, req.ValidDays)
+	default:
+		return nil, errors.New("indicar expires_at o valid_days")
+	}
+	if !expiresAt.After(now) || expiresAt.Sub(now) > domain.MaxCreditValidity {
+		return nil, errors.New("el vencimiento debe estar entre ahora y un año")
+	}
+
+	amount := domain.RoundCreditAmount(req.Amount)
+	if amount <= 0 {
+		return nil, errors.New("monto inválido")
+	}
+
+	grant := &dao.CreditGrantDAO{
+		UserID:    userID,
+		Currency:  req.Currency,
+		Amount:    amount,
+		Remaining: amount,
+		Reason:    req.Reason,
+		GrantedBy: adminID,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.creditRepo.CreateGrant(grant); err != nil {
+		return nil, err
+	}
+
+	dto := convertCreditGrant(grant)
+	return &dto, nil
+}
+
+// GetCredits obtiene el saldo por moneda y los créditos vigentes del usuario
+func (s *creditService) GetCredits(userID int64) (*domain.CreditsSummaryDTO, error) {
+	grants, err := s.creditRepo.FindActiveGrants(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.CreditsSummaryDTO{
+		Balances: creditBalances(grants),
+		Grants:   make([]domain.CreditGrantDTO, len(grants)),
+	}
+	for i := range grants {
+		summary.Grants[i] = convertCreditGrant(&grants[i])
+	}
+	return summary, nil
+}
+
+// ListLedger obtiene los movimientos de créditos del usuario con paginación (más recientes primero)
+func (s *creditService) ListLedger(userID int64, page, limit int) ([]domain.CreditLedgerEntryDTO, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	entries, total, err := s.creditRepo.FindLedger(userID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dtos := make([]domain.CreditLedgerEntryDTO, len(entries))
+	for i, entry := range entries {
+		dtos[i] = domain.CreditLedgerEntryDTO{
+			ID:        entry.ID,
+			GrantID:   entry.GrantID,
+			Type:      entry.Type,
+			Amount:    entry.Amount,
+			Currency:  entry.Currency,
+			Reason:    entry.Reason,
+			CreatedAt: entry.CreatedAt,
+		}
+		if entry.BookingID != nil {
+			dtos[i].BookingID = *entry.BookingID
+		}
+	}
+	return dtos, total, nil
+}
+
+// ==================== CONSUMO ====================
+
+// ConsumeCredits aplica hasta req.Amount del saldo vigente en req.Currency, usando primero los
+// créditos que vencen antes. Repetir el pedido con el mismo booking_id retorna la aplicación original
+// (replayed) aunque cambie el monto; un booking_id ya usado por otro usuario es un conflicto
+func (s *creditService) ConsumeCredits(req domain.ConsumeCreditsRequest) (*domain.CreditConsumptionDTO, bool, error) {
+	if _, err := s.findUser(req.UserID); err != nil {
+		return nil, false, err
+	}
+
+	consumption := &dao.CreditConsumptionDAO{
+		BookingID: req.BookingID,
+		UserID:    req.UserID,
+		Currency:  req.Currency,
+		Requested: domain.RoundCreditAmount(req.Amount),
+	}
+	now := time.Now()
+	created, err := s.creditRepo.Consume(consumption, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if consumption.UserID != req.UserID {
+		return nil, false, errors.New("la reserva ya aplicó créditos de otro usuario")
+	}
+
+	grants, err := s.creditRepo.FindActiveGrants(req.UserID, now)
+	if err != nil {
+		return nil, false, err
+	}
+	balance := 0.0
+	for _, grant := range grants {
+		if grant.Currency == consumption.Currency {
+			balance += grant.Remaining
+		}
+	}
+
+	return &domain.CreditConsumptionDTO{
+		BookingID:     consumption.BookingID,
+		UserID:        consumption.UserID,
+		Currency:      consumption.Currency,
+		Requested:     consumption.Requested,
+		AppliedAmount: consumption.AppliedAmount,
+		Balance:       domain.RoundCreditAmount(balance),
+		Replayed:      !created,
+		CreatedAt:     consumption.CreatedAt,
+	}, created, nil
+}
+
+// ReleaseCredits devuelve lo consumido por la reserva a los créditos de los que salió, salvo la
+// parte de créditos que ya vencieron. Repetir el pedido retorna la devolución original (replayed)
+func (s *creditService) ReleaseCredits(req domain.ReleaseCreditsRequest) (*domain.CreditReleaseDTO, bool, error) {
+	consumption, released, err := s.creditRepo.Release(req.BookingID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, errors.New("la reserva no aplicó créditos")
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &domain.CreditReleaseDTO{
+		BookingID:      consumption.BookingID,
+		UserID:         consumption.UserID,
+		Currency:       consumption.Currency,
+		AppliedAmount:  consumption.AppliedAmount,
+		ReleasedAmount: consumption.ReleasedAmount,
+		Replayed:       !released,
+		ReleasedAt:     *consumption.ReleasedAt,
+	}, released, nil
+}
+
+// ==================== VENCIMIENTO ====================
+
+// ProcessExpirations avisa (notificación in-app + credit.expiring) los créditos que vencen dentro de
+// domain.CreditExpiryNoticeWindow y vence los créditos vencidos con saldo (movimiento expire,
+// notificación in-app + credit.expired)
+//
+//   - Cada crédito recibe un solo aviso de vencimiento próximo (expiring_notified_at)
+//   - El vencimiento se guarda antes de publicar: si la publicación falla el saldo vence igual
+//     y el error solo se loguea (la notificación in-app ya quedó guardada)
+//   - Como máximo batchSize créditos de cada tipo por ejecución; el resto queda para la próxima
+func (s *creditService) ProcessExpirations() (int, int, error) {
+	now := time.Now()
+
+	expiring, err := s.creditRepo.FindExpiringToNotify(now, now.Add(domain.CreditExpiryNoticeWindow), s.batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	notified := 0
+	for _, grant := range expiring {
+		s.notifyCredit(domain.EventTypeCreditExpiring, grant, grant.Remaining, now)
+		if err := s.creditRepo.MarkExpiringNotified(grant.ID, now); err != nil {
+			return notified, 0, err
+		}
+		notified++
+	}
+
+	expired, err := s.creditRepo.FindExpired(now, s.batchSize)
+	if err != nil {
+		return notified, 0, err
+	}
+
+	expiredCount := 0
+	for _, grant := range expired {
+		amount, err := s.creditRepo.Expire(grant.ID, now)
+		if err != nil {
+			return notified, expiredCount, err
+		}
+		if amount <= 0 {
+			continue
+		}
+		s.notifyCredit(domain.EventTypeCreditExpired, grant, amount, now)
+		expiredCount++
+	}
+
+	return notified, expiredCount, nil
+}
+
+// StartExpiryJob revisa periódicamente los créditos por vencer y vencidos
+// Bloquea hasta que ctx se cancele, debe ejecutarse en una goroutine
+func (s *creditService) StartExpiryJob(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		notified, expired, err := s.ProcessExpirations()
+		if err != nil {
+			log.Printf("Error procesando vencimientos de créditos: %v", err)
+		} else if notified > 0 || expired > 0 {
+			log.Printf("Créditos avisados por vencer: %d, vencidos: %d", notified, expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Job de vencimiento de créditos detenido")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyCredit guarda la notificación in-app y publica el evento de un crédito por vencer o vencido
+// Los dos usan el mismo event_id, así un aviso repetido no genera duplicados
+func (s *creditService) notifyCredit(eventType string, grant dao.CreditGrantDAO, amount float64, now time.Time) {
+	eventID := domain.NewCreditEventID(eventType, grant.ID)
+
+	title := "Tu crédito está por vencer"
+	message := fmt.Sprintf("Tenés %.2f %s de crédito que vence el %s. Usalo en tu próxima reserva.",
+		amount, grant.Currency, grant.ExpiresAt.Format("02/01/2006"))
+	if eventType == domain.EventTypeCreditExpired {
+		title = "Tu crédito venció"
+		message = fmt.Sprintf("Vencieron %.2f %s de crédito sin usar.", amount, grant.Currency)
+	}
+
+	if err := s.notificationService.Notify(domain.NewNotification{
+		UserID:  grant.UserID,
+		Type:    domain.NotificationTypeCredit,
+		Title:   title,
+		Message: message,
+		EventID: eventID,
+	}); err != nil {
+		log.Printf("Error guardando la notificación %s (grant_id=%d): %v", eventType, grant.ID, err)
+	}
+
+	if s.publisher == nil {
+		return
+	}
+	event := domain.CreditEvent{
+		EventID:   eventID,
+		EventType: eventType,
+		UserID:    grant.UserID,
+		GrantID:   grant.ID,
+		Amount:    amount,
+		Currency:  grant.Currency,
+		ExpiresAt: grant.ExpiresAt,
+		Timestamp: now,
+	}
+	if err := s.publisher.PublishCreditEvent(context.Background(), event); err != nil {
+		log.Printf("Error publicando %s (grant_id=%d): %v", eventType, grant.ID, err)
+	}
+}
+
+// findUser valida que el usuario existe
+func (s *creditService) findUser(userID int64) (*dao.UserDAO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// creditBalances suma el saldo por moneda; grants viene ordenado por vencimiento, así el primero
+// de cada moneda es el próximo en vencer
+func creditBalances(grants []dao.CreditGrantDAO) []domain.CreditBalanceDTO {
+	balances := []domain.CreditBalanceDTO{}
+	index := map[string]int{}
+	for _, grant := range grants {
+		i, ok := index[grant.Currency]
+		if !ok {
+			expiresAt := grant.ExpiresAt
+			balances = append(balances, domain.CreditBalanceDTO{Currency: grant.Currency, NextExpiresAt: &expiresAt})
+			i = len(balances) - 1
+			index[grant.Currency] = i
+		}
+		balances[i].Amount = domain.RoundCreditAmount(balances[i].Amount + grant.Remaining)
+		if grant.ExpiresAt.Equal(*balances[i].NextExpiresAt) {
+			balances[i].NextExpiryAmount = domain.RoundCreditAmount(balances[i].NextExpiryAmount + grant.Remaining)
+		}
+	}
+	return balances
+}
+
+// convertCreditGrant convierte el DAO en el DTO de respuesta
+func convertCreditGrant(grant *dao.CreditGrantDAO) domain.CreditGrantDTO {
+	return domain.CreditGrantDTO{
+		ID:        grant.ID,
+		Amount:    grant.Amount,
+		Remaining: grant.Remaining,
+		Currency:  grant.Currency,
+		Reason:    grant.Reason,
+		ExpiresAt: grant.ExpiresAt,
+		ExpiredAt: grant.ExpiredAt,
+		CreatedAt: grant.CreatedAt,
+	}
+}
@@ -1,14 +1,32 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
+	"os"
+	"time"
 	"users-api/internal/config"
+	"users-api/internal/metrics"
 )
 
+// Tipos de email (label email de las métricas de entrega)
+const (
+	emailKindVerification  = "verification"
+	emailKindPasswordReset = "password_reset"
+)
+
+// defaultSMTPTimeout acota toda la sesión SMTP cuando SMTP_TIMEOUT_SECONDS no es válido
+const defaultSMTPTimeout = 30 * time.Second
+
 // EmailService define las operaciones para envío de correos electrónicos
 type EmailService interface {
 	SendVerificationEmail(toEmail, token string) error
@@ -45,7 +63,7 @@ func (s *emailService) SendVerificationEmail(toEmail, token string) error {
 		<p>Este enlace es válido por 24 horas.</p>
 	`, verificationURL)
 
-	return s.sendEmail(toEmail, subject, body)
+	return s.sendEmail(emailKindVerification, toEmail, subject, body)
 }
 
 func (s *emailService) SendPasswordResetEmail(toEmail, token string) error {
@@ -56,23 +74,53 @@ func (s *emailService) SendPasswordResetEmail(toEmail, token string) error {
 		<h2>Restablecer Contraseña</h2>
 		<p>Has solicitado restablecer tu contraseña. Haz clic en el siguiente enlace:</p>
 		<a href="%s">Restablecer Contraseña</a>
-		<p>Este enlace es válido por 1 hora.</p>
+		<p>Este enlace es válido por 1 hora y se puede usar una sola vez. Al cambiar la contraseña se cerrarán todas tus sesiones abiertas.</p>
 		<p>Si no solicitaste este cambio, ignora este correo.</p>
 	`, resetURL)
 
-	return s.sendEmail(toEmail, subject, body)
+	return s.sendEmail(emailKindPasswordReset, toEmail, subject, body)
 }
 
-func (s *emailService) sendEmail(to, subject, body string) error {
+// sendEmail entrega el email al servidor SMTP y registra la latencia y el motivo de fallo en las métricas
+func (s *emailService) sendEmail(kind, to, subject, body string) error {
+	metrics.EmailQueued()
+	startedAt := time.Now()
+
+	err := s.deliver(to, subject, body)
+	metrics.EmailDelivered(kind, time.Since(startedAt), emailFailureReason(err))
+
+	if err != nil {
+		log.Printf("[EMAIL ERROR] Fallo al enviar email a %s: %v", to, err)
+		return err
+	}
+
+	log.Printf("[EMAIL SUCCESS] Email enviado exitosamente a %s", to)
+	return nil
+}
+
+// deliver envía el mensaje con una sesión SMTP acotada por SMTP_TIMEOUT_SECONDS
+// Mismo protocolo que smtp.SendMail (STARTTLS y AUTH si el servidor los ofrece), que no tiene timeout:
+// un servidor colgado dejaría la goroutine del envío bloqueada para siempre
+func (s *emailService) deliver(to, subject, body string) error {
 	// Configuración SMTP
 	from := s.config.SMTPFrom
 	password := s.config.SMTPPassword
 	smtpHost := s.config.SMTPHost
 	smtpPort := s.config.SMTPPort
 
+	timeout := time.Duration(s.config.SMTPTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+
 	// Log de intento de envío
 	log.Printf("[EMAIL] Intentando enviar email a %s (Subject: %s)", to, subject)
 
+	// Una dirección mal formada no llega al servidor
+	if _, err := mail.ParseAddress(to); err != nil {
+		return &invalidAddressError{address: to, err: err}
+	}
+
 	// Mensaje
 	msg := []byte(fmt.Sprintf("From: %s\r\n"+
 		"To: %s\r\n"+
@@ -82,18 +130,104 @@ func (s *emailService) sendEmail(to, subject, body string) error {
 		"\r\n"+
 		"%s\r\n", from, to, subject, body))
 
+	addr := net.JoinHostPort(smtpHost, smtpPort)
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, smtpHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: smtpHost}); err != nil {
+			return err
+		}
+	}
 	// Autenticación
-	auth := smtp.PlainAuth("", from, password, smtpHost)
+	if ok, _ := client.Extension("AUTH"); ok {
+		if err := client.Auth(smtp.PlainAuth("", from, password, smtpHost)); err != nil {
+			return err
+		}
+	}
 
 	// Enviar email
-	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-	err := smtp.SendMail(addr, auth, from, []string{to}, msg)
-
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
 	if err != nil {
-		log.Printf("[EMAIL ERROR] Fallo al enviar email a %s: %v", to, err)
 		return err
 	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
 
-	log.Printf("[EMAIL SUCCESS] Email enviado exitosamente a %s", to)
-	return nil
+// invalidAddressError indica una dirección de destino mal formada (no se intentó el envío)
+type invalidAddressError struct {
+	address string
+	err     error
+}
+
+func (e *invalidAddressError) Error() string {
+	return fmt.Sprintf("dirección de email inválida %q: %v", e.address, e.err)
+}
+
+func (e *invalidAddressError) Unwrap() error {
+	return e.err
+}
+
+// emailFailureReason clasifica el error de un envío para las métricas ("" si no hubo error)
+func emailFailureReason(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var invalidAddr *invalidAddressError
+	if errors.As(err, &invalidAddr) {
+		return metrics.EmailFailureInvalidAddress
+	}
+
+	var netErr net.Error
+	if errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return metrics.EmailFailureSMTPTimeout
+	}
+
+	// Respuestas del servidor SMTP: 501/550/553 rechazan la dirección, el resto se agrupa por clase
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		switch {
+		case protoErr.Code == 501 || protoErr.Code == 550 || protoErr.Code == 553:
+			return metrics.EmailFailureInvalidAddress
+		case protoErr.Code >= 400 && protoErr.Code < 500:
+			return metrics.EmailFailureProvider4xx
+		case protoErr.Code >= 500:
+			return metrics.EmailFailureProvider5xx
+		}
+	}
+
+	var opErr *net.OpError
+	var tlsErr tls.RecordHeaderError
+	if errors.As(err, &opErr) || errors.As(err, &tlsErr) {
+		return metrics.EmailFailureConnection
+	}
+
+	return metrics.EmailFailureOther
 }
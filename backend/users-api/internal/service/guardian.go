@@ -0,0 +1,479 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// GuardianEventPublisher publica la decisión del tutor para el servicio que pidió la aprobación
+type GuardianEventPublisher interface {
+	PublishGuardianApprovalDecided(ctx context.Context, event domain.GuardianApprovalDecidedEvent) error
+}
+
+// GuardianService define la gestión de cuentas dependientes (menores) por parte de su tutor
+//
+//   - El tutor crea, edita y desactiva las cuentas de sus dependientes
+//   - Las reservas de un dependiente esperan la aprobación del tutor: el servicio que las crea
+//     registra la solicitud (RequestApproval) y recibe la decisión por guardian.approval_decided
+//   - Cada acción del tutor queda en la auditoría (guardian_audit_logs)
+type GuardianService interface {
+	CreateDependent(guardianID int64, req domain.CreateDependentRequest) (*domain.UserDTO, error)
+	ListDependents(guardianID int64) ([]*domain.UserDTO, error)
+	UpdateDependent(guardianID, dependentID int64, req domain.UpdateUserRequest) (*domain.UserDTO, error)
+	DeactivateDependent(guardianID, dependentID int64) error
+
+	// RequestApproval registra una acción de un dependiente que necesita aprobación (uso interno)
+	RequestApproval(req domain.CreateGuardianApprovalRequest) (*domain.GuardianApprovalDTO, bool, error)
+	GetApproval(id int64) (*domain.GuardianApprovalDTO, error)
+	ListApprovals(guardianID int64, status string) ([]*domain.GuardianApprovalDTO, error)
+	DecideApproval(ctx context.Context, guardianID, approvalID int64, req domain.GuardianApprovalDecisionRequest) (*domain.GuardianApprovalDTO, error)
+
+	// ListAuditLogs lista la auditoría; guardianID y dependentID en 0 no filtran
+	ListAuditLogs(guardianID, dependentID int64, page, limit int) ([]*domain.GuardianAuditLogDTO, int64, error)
+}
+
+type guardianService struct {
+	guardianRepo        repository.GuardianRepository
+	userRepo            repository.UserRepository
+	tokenRepo           repository.VerificationTokenRepository
+	emailService        EmailService
+	notificationService NotificationService
+	publisher           GuardianEventPublisher
+}
+
+// NewGuardianService crea una nueva instancia del servicio de cuentas dependientes
+// publisher puede ser nil (sin RabbitMQ la decisión solo se consulta con GET /internal/guardian-approvals/:id)
+func NewGuardianService(guardianRepo repository.GuardianRepository, userRepo repository.UserRepository, tokenRepo repository.VerificationTokenRepository, emailService EmailService, notificationService NotificationService, publisher GuardianEventPublisher) GuardianService {
+	return &guardianService{
+		guardianRepo:        guardianRepo,
+		userRepo:            userRepo,
+		tokenRepo:           tokenRepo,
+		emailService:        emailService,
+		notificationService: notificationService,
+		publisher:           publisher,
+	}
+}
+
+// ==================== DEPENDIENTES ====================
+
+// CreateDependent crea la cuenta de un menor a cargo del tutor
+// El email se verifica como en el registro (el menor no puede iniciar sesión hasta confirmarlo)
+// y teléfono y dirección son los del tutor
+func (s *guardianService) CreateDependent(guardianID int64, req domain.CreateDependentRequest) (*domain.UserDTO, error) {
+	guardian, err := s.findGuardian(guardianID)
+	if err != nil {
+		return nil, err
+	}
+
+	birthdate, err := time.Parse("2006-01-02", req.Birthdate)
+	if err != nil {
+		return nil, errors.New("formato de fecha inválido, usar YYYY-MM-DD")
+	}
+	if domain.AgeAt(birthdate, time.Now()) >= domain.AdultAge {
+		return nil, errors.New("el dependiente debe ser menor de edad")
+	}
+
+	_, err = s.userRepo.FindByEmail(req.Email)
+	if err == nil {
+		return nil, errors.New("el email ya está registrado")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
+	if err != nil {
+		return nil, err
+	}
+
+	dependent := &dao.UserDAO{
+		Email:           req.Email,
+		EmailVerified:   false,
+		Name:            req.Name,
+		Lastname:        req.Lastname,
+		PasswordHash:    string(hashedPassword),
+		Role:            domain.RoleDependent,
+		Phone:           guardian.Phone,
+		Street:          guardian.Street,
+		Number:          guardian.Number,
+		PhotoURL:        req.PhotoURL,
+		Sex:             req.Sex,
+		Birthdate:       birthdate,
+		MarketingEmails: false, // Sin campañas de marketing para menores
+		Active:          true,
+		GuardianID:      &guardianID,
+	}
+
+	audit := newGuardianAuditLog(guardianID, 0, domain.GuardianAuditDependentCreated, map[string]interface{}{
+		"email":     req.Email,
+		"birthdate": req.Birthdate,
+	})
+	if err := s.guardianRepo.CreateDependent(dependent, audit); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Cuenta dependiente creada (guardian_id=%d, dependent_id=%d)", guardianID, dependent.ID)
+
+	// Mismo token de verificación que el registro (vence en 24 horas, se puede pedir reenvío)
+	verificationToken, err := issueVerificationToken(s.tokenRepo, s.emailService, dependent.ID, domain.VerificationPurposeRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		if err := s.emailService.SendVerificationEmail(req.Email, verificationToken); err != nil {
+			// El error ya está logueado en emailService; el tutor o el menor pueden pedir el reenvío
+			return
+		}
+	}()
+
+	return toUserDTO(dependent), nil
+}
+
+// ListDependents lista las cuentas dependientes del tutor (incluye las desactivadas)
+func (s *guardianService) ListDependents(guardianID int64) ([]*domain.UserDTO, error) {
+	dependents, err := s.guardianRepo.FindDependents(guardianID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.UserDTO, len(dependents))
+	for i, dependent := range dependents {
+		result[i] = toUserDTO(dependent)
+	}
+	return result, nil
+}
+
+// UpdateDependent actualiza el perfil de un dependiente; la auditoría guarda los campos cambiados
+func (s *guardianService) UpdateDependent(guardianID, dependentID int64, req domain.UpdateUserRequest) (*domain.UserDTO, error) {
+	dependent, err := s.findDependent(guardianID, dependentID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if req.Name != nil && *req.Name != dependent.Name {
+		dependent.Name = *req.Name
+		changes["name"] = *req.Name
+	}
+	if req.Lastname != nil && *req.Lastname != dependent.Lastname {
+		dependent.Lastname = *req.Lastname
+		changes["lastname"] = *req.Lastname
+	}
+	if req.Phone != nil && *req.Phone != dependent.Phone {
+		dependent.Phone = *req.Phone
+		changes["phone"] = *req.Phone
+	}
+	if req.Street != nil && *req.Street != dependent.Street {
+		dependent.Street = *req.Street
+		changes["street"] = *req.Street
+	}
+	if req.Number != nil && *req.Number != dependent.Number {
+		dependent.Number = *req.Number
+		changes["number"] = *req.Number
+	}
+	if req.PhotoURL != nil && *req.PhotoURL != dependent.PhotoURL {
+		dependent.PhotoURL = *req.PhotoURL
+		changes["photo_url"] = *req.PhotoURL
+	}
+
+	// Sin cambios no se escribe nada (tampoco la auditoría)
+	if len(changes) == 0 {
+		return toUserDTO(dependent), nil
+	}
+
+	audit := newGuardianAuditLog(guardianID, dependentID, domain.GuardianAuditDependentUpdated, changes)
+	if err := s.guardianRepo.UpdateDependent(dependent, audit); err != nil {
+		return nil, err
+	}
+
+	return toUserDTO(dependent), nil
+}
+
+// DeactivateDependent desactiva la cuenta: el dependiente no puede volver a iniciar sesión
+// La cuenta no se borra para conservar reservas, calificaciones y auditoría
+func (s *guardianService) DeactivateDependent(guardianID, dependentID int64) error {
+	dependent, err := s.findDependent(guardianID, dependentID)
+	if err != nil {
+		return err
+	}
+	if !dependent.Active {
+		return nil
+	}
+
+	audit := newGuardianAuditLog(guardianID, dependentID, domain.GuardianAuditDependentDeactivated, nil)
+	return s.guardianRepo.DeactivateDependent(dependentID, audit)
+}
+
+// ==================== APROBACIONES ====================
+
+// RequestApproval registra la solicitud y notifica al tutor (notificación in-app)
+// Retorna false si ya existía una solicitud para (action, resource_id): no se notifica de nuevo
+func (s *guardianService) RequestApproval(req domain.CreateGuardianApprovalRequest) (*domain.GuardianApprovalDTO, bool, error) {
+	dependent, err := s.userRepo.FindByID(req.DependentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, errors.New("usuario no encontrado")
+		}
+		return nil, false, err
+	}
+	if dependent.Role != domain.RoleDependent || dependent.GuardianID == nil {
+		return nil, false, errors.New("el usuario no es una cuenta dependiente")
+	}
+
+	approval := &dao.GuardianApprovalDAO{
+		GuardianID:  *dependent.GuardianID,
+		DependentID: dependent.ID,
+		Action:      req.Action,
+		ResourceID:  req.ResourceID,
+		TripID:      req.TripID,
+		Details:     req.Details,
+		Status:      domain.GuardianApprovalPending,
+		ExpiresAt:   time.Now().Add(domain.GuardianApprovalTTL),
+	}
+	created, err := s.guardianRepo.CreateApproval(approval)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if created {
+		s.notifyGuardian(approval, dependent)
+	}
+
+	return toGuardianApprovalDTO(approval, time.Now()), created, nil
+}
+
+// GetApproval obtiene una solicitud (el servicio que la creó consulta así su estado)
+func (s *guardianService) GetApproval(id int64) (*domain.GuardianApprovalDTO, error) {
+	approval, err := s.guardianRepo.FindApprovalByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("solicitud de aprobación no encontrada")
+		}
+		return nil, err
+	}
+	return toGuardianApprovalDTO(approval, time.Now()), nil
+}
+
+// ListApprovals lista las solicitudes dirigidas al tutor, opcionalmente filtradas por estado
+func (s *guardianService) ListApprovals(guardianID int64, status string) ([]*domain.GuardianApprovalDTO, error) {
+	switch status {
+	case "", domain.GuardianApprovalPending, domain.GuardianApprovalApproved, domain.GuardianApprovalRejected, domain.GuardianApprovalExpired:
+	default:
+		return nil, errors.New("estado inválido")
+	}
+
+	now := time.Now()
+	approvals, err := s.guardianRepo.FindApprovals(guardianID, status, now)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.GuardianApprovalDTO, len(approvals))
+	for i := range approvals {
+		result[i] = toGuardianApprovalDTO(&approvals[i], now)
+	}
+	return result, nil
+}
+
+// DecideApproval aprueba o rechaza una solicitud del dependiente y publica guardian.approval_decided
+// Solo el tutor del dependiente puede resolverla, una única vez y antes de que venza
+func (s *guardianService) DecideApproval(ctx context.Context, guardianID, approvalID int64, req domain.GuardianApprovalDecisionRequest) (*domain.GuardianApprovalDTO, error) {
+	approval, err := s.guardianRepo.FindApprovalByID(approvalID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("solicitud de aprobación no encontrada")
+		}
+		return nil, err
+	}
+	// Otro usuario no puede saber si la solicitud existe
+	if approval.GuardianID != guardianID {
+		return nil, errors.New("solicitud de aprobación no encontrada")
+	}
+
+	now := time.Now()
+	if approval.Status != domain.GuardianApprovalPending {
+		return nil, errors.New("la solicitud de aprobación ya fue resuelta")
+	}
+	if !now.Before(approval.ExpiresAt) {
+		return nil, errors.New("la solicitud de aprobación expiró")
+	}
+
+	auditAction := domain.GuardianAuditApprovalRejected
+	approval.Status = domain.GuardianApprovalRejected
+	if *req.Approve {
+		auditAction = domain.GuardianAuditApprovalApproved
+		approval.Status = domain.GuardianApprovalApproved
+	}
+	approval.DecisionNote = req.Note
+	approval.DecidedAt = &now
+
+	audit := newGuardianAuditLog(guardianID, approval.DependentID, auditAction, map[string]interface{}{
+		"approval_id": approval.ID,
+		"action":      approval.Action,
+		"resource_id": approval.ResourceID,
+		"note":        req.Note,
+	})
+	decided, err := s.guardianRepo.DecideApproval(approval, audit)
+	if err != nil {
+		return nil, err
+	}
+	if !decided {
+		// Resuelta (o vencida) entre la lectura y la escritura
+		return nil, errors.New("la solicitud de aprobación ya fue resuelta")
+	}
+
+	s.publishApprovalDecided(ctx, approval)
+
+	return toGuardianApprovalDTO(approval, now), nil
+}
+
+// ==================== AUDITORÍA ====================
+
+// ListAuditLogs lista la auditoría de las acciones de tutores, las más nuevas primero
+func (s *guardianService) ListAuditLogs(guardianID, dependentID int64, page, limit int) ([]*domain.GuardianAuditLogDTO, int64, error) {
+	logs, total, err := s.guardianRepo.FindAuditLogs(guardianID, dependentID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]*domain.GuardianAuditLogDTO, len(logs))
+	for i, entry := range logs {
+		result[i] = &domain.GuardianAuditLogDTO{
+			ID:          entry.ID,
+			GuardianID:  entry.GuardianID,
+			DependentID: entry.DependentID,
+			Action:      entry.Action,
+			Details:     entry.Details,
+			CreatedAt:   entry.CreatedAt,
+		}
+	}
+	return result, total, nil
+}
+
+// ==================== HELPERS ====================
+
+// findGuardian valida que el usuario pueda ser tutor: adulto y sin tutor propio
+func (s *guardianService) findGuardian(guardianID int64) (*dao.UserDAO, error) {
+	guardian, err := s.userRepo.FindByID(guardianID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+	if guardian.Role == domain.RoleDependent || domain.AgeAt(guardian.Birthdate, time.Now()) < domain.AdultAge {
+		return nil, errors.New("solo un usuario mayor de edad puede ser tutor")
+	}
+	return guardian, nil
+}
+
+// findDependent busca un dependiente del tutor; los de otro tutor se reportan como inexistentes
+func (s *guardianService) findDependent(guardianID, dependentID int64) (*dao.UserDAO, error) {
+	dependent, err := s.guardianRepo.FindDependent(guardianID, dependentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("dependiente no encontrado")
+		}
+		return nil, err
+	}
+	return dependent, nil
+}
+
+// notifyGuardian avisa al tutor que tiene una solicitud pendiente; un error no rechaza la solicitud
+func (s *guardianService) notifyGuardian(approval *dao.GuardianApprovalDAO, dependent *dao.UserDAO) {
+	if s.notificationService == nil {
+		return
+	}
+
+	message := fmt.Sprintf("%s %s necesita tu aprobación para una reserva.", dependent.Name, dependent.Lastname)
+	if approval.Details != "" {
+		message += " " + approval.Details
+	}
+
+	notification := domain.NewNotification{
+		UserID:  approval.GuardianID,
+		Type:    domain.NotificationTypeGuardianApproval,
+		Title:   "Solicitud de aprobación pendiente",
+		Message: message,
+		TripID:  approval.TripID,
+		EventID: fmt.Sprintf("guardian_approval:%d", approval.ID),
+	}
+	if approval.Action == domain.GuardianApprovalActionBooking {
+		notification.BookingID = approval.ResourceID
+	}
+
+	if err := s.notificationService.Notify(notification); err != nil {
+		log.Printf("Error notificando solicitud de aprobación al tutor (approval_id=%d): %v", approval.ID, err)
+	}
+}
+
+// publishApprovalDecided publica guardian.approval_decided; sin publisher la decisión se consulta por HTTP
+func (s *guardianService) publishApprovalDecided(ctx context.Context, approval *dao.GuardianApprovalDAO) {
+	if s.publisher == nil {
+		return
+	}
+
+	event := domain.GuardianApprovalDecidedEvent{
+		EventID:     domain.NewGuardianApprovalDecidedEventID(approval.ID),
+		EventType:   domain.EventTypeGuardianApprovalDecided,
+		ApprovalID:  approval.ID,
+		GuardianID:  approval.GuardianID,
+		DependentID: approval.DependentID,
+		Action:      approval.Action,
+		ResourceID:  approval.ResourceID,
+		TripID:      approval.TripID,
+		Status:      approval.Status,
+		Timestamp:   *approval.DecidedAt,
+	}
+	if err := s.publisher.PublishGuardianApprovalDecided(ctx, event); err != nil {
+		log.Printf("Error publicando guardian.approval_decided (approval_id=%d): %v", approval.ID, err)
+	}
+}
+
+// newGuardianAuditLog arma el registro de auditoría con los detalles serializados en JSON
+func newGuardianAuditLog(guardianID, dependentID int64, action string, details map[string]interface{}) *dao.GuardianAuditLogDAO {
+	entry := &dao.GuardianAuditLogDAO{
+		GuardianID:  guardianID,
+		DependentID: dependentID,
+		Action:      action,
+	}
+	if len(details) > 0 {
+		if data, err := json.Marshal(details); err == nil {
+			entry.Details = string(data)
+		}
+	}
+	return entry
+}
+
+func toGuardianApprovalDTO(approval *dao.GuardianApprovalDAO, now time.Time) *domain.GuardianApprovalDTO {
+	status := approval.Status
+	if status == domain.GuardianApprovalPending && !now.Before(approval.ExpiresAt) {
+		status = domain.GuardianApprovalExpired
+	}
+
+	return &domain.GuardianApprovalDTO{
+		ID:           approval.ID,
+		GuardianID:   approval.GuardianID,
+		DependentID:  approval.DependentID,
+		Action:       approval.Action,
+		ResourceID:   approval.ResourceID,
+		TripID:       approval.TripID,
+		Details:      approval.Details,
+		Status:       status,
+		DecisionNote: approval.DecisionNote,
+		DecidedAt:    approval.DecidedAt,
+		ExpiresAt:    approval.ExpiresAt,
+		CreatedAt:    approval.CreatedAt,
+	}
+}
@@ -0,0 +1,520 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/metrics"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// Backoff de los reintentos: jobRetryBase * 2^(intento-1), como máximo jobRetryMax
+const (
+	jobRetryBase = 30 * time.Second
+	jobRetryMax  = time.Hour
+)
+
+// jobLeaseGrace es el margen del lease sobre el timeout del handler, para que el worker registre
+// el resultado antes de que otro worker pueda retomar el job
+const jobLeaseGrace = 30 * time.Second
+
+// jobMetricsInterval es cada cuánto se actualiza el gauge de jobs por estado
+const jobMetricsInterval = time.Minute
+
+// jobCleanupBatch es la cantidad de jobs borrados por sentencia en jobs.cleanup
+const jobCleanupBatch = 1000
+
+// jobErrorLimit es el largo máximo de last_error (columna varchar(1000))
+const jobErrorLimit = 1000
+
+// JobHandler procesa el payload de un job; un error reintenta el job con backoff hasta agotar los intentos
+// ctx se cancela al vencer el lease o al detener el servicio: el handler debe respetarlo y ser idempotente
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// JobService es la cola persistente de trabajos en segundo plano (tabla jobs), compartida por
+// todos los subsistemas de users-api. Varias réplicas pueden procesar la misma cola: cada job
+// se toma con un lease y un job cuyo worker se cayó se retoma cuando el lease vence
+type JobService interface {
+	// RegisterHandler registra el handler de un tipo de job; llamar antes de Start
+	RegisterHandler(jobType string, handler JobHandler)
+
+	// Enqueue encola un job con payload serializado a JSON
+	// Con opts.UniqueKey no se encola si ya existe un job con la misma clave (no es un error)
+	Enqueue(jobType string, payload interface{}, opts domain.EnqueueJobOptions) error
+
+	// Schedule encola jobType con payload en cada ejecución de la expresión cron spec (ver domain.CronSchedule)
+	// name identifica la programación: cada ejecución se encola una sola vez aunque haya varias réplicas
+	Schedule(name, spec, jobType string, payload interface{}) error
+
+	// API de administración
+	ListJobs(status, jobType string, page, limit int) (*domain.JobListResponse, error)
+	RequeueJob(id int64) (*domain.JobDTO, error)
+
+	// Start ejecuta los workers y las programaciones hasta que ctx se cancele
+	Start(ctx context.Context)
+}
+
+// jobSchedule es una programación registrada con Schedule
+type jobSchedule struct {
+	name    string
+	cron    domain.CronSchedule
+	jobType string
+	payload []byte
+	next    time.Time
+}
+
+type jobService struct {
+	jobRepo      repository.JobRepository
+	workers      int
+	pollInterval time.Duration
+	lease        time.Duration
+	maxAttempts  int
+	workerID     string
+
+	mu        sync.RWMutex
+	handlers  map[string]JobHandler
+	schedules []*jobSchedule
+
+	// wake despierta al loop cuando se encolan jobs o se libera un worker (buffer 1: las señales se combinan)
+	wake chan struct{}
+}
+
+// NewJobService crea una nueva instancia de la cola de jobs con el handler de jobs.cleanup registrado
+// workers es la cantidad de jobs en paralelo por réplica, lease el tiempo máximo de un intento y
+// maxAttempts los intentos por defecto antes de marcar un job como fallido
+func NewJobService(jobRepo repository.JobRepository, workers int, pollInterval, lease time.Duration, maxAttempts int) JobService {
+	if workers <= 0 {
+		workers = 4
+	}
+	if pollInterval <= 0 {
+		pollInterval = 5 * time.Second
+	}
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "users-api"
+	}
+
+	s := &jobService{
+		jobRepo:      jobRepo,
+		workers:      workers,
+		pollInterval: pollInterval,
+		lease:        lease,
+		maxAttempts:  maxAttempts,
+		workerID:     fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		handlers:     map[string]JobHandler{},
+		wake:         make(chan struct{}, 1),
+	}
+	s.RegisterHandler(domain.JobTypeCleanup, s.cleanup)
+	return s
+}
+
+// RegisterHandler registra (o reemplaza) el handler de jobType
+func (s *jobService) RegisterHandler(jobType string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = handler
+}
+
+// Enqueue serializa el payload y crea el job pending
+func (s *jobService) Enqueue(jobType string, payload interface{}, opts domain.EnqueueJobOptions) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error serializando el payload de %s: %w", jobType, err)
+	}
+	return s.enqueue(jobType, data, opts)
+}
+
+func (s *jobService) enqueue(jobType string, payload []byte, opts domain.EnqueueJobOptions) error {
+	now := time.Now()
+	job := &dao.JobDAO{
+		Type:        jobType,
+		Payload:     string(payload),
+		Status:      domain.JobStatusPending,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = s.maxAttempts
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	if opts.UniqueKey != "" {
+		uniqueKey := opts.UniqueKey
+		job.UniqueKey = &uniqueKey
+	}
+
+	created, err := s.jobRepo.Create(job)
+	if err != nil {
+		return err
+	}
+	if created && !job.RunAt.After(now) {
+		s.notify()
+	}
+	return nil
+}
+
+// Schedule valida la expresión y registra la programación; la primera ejecución es la siguiente a Start
+func (s *jobService) Schedule(name, spec, jobType string, payload interface{}) error {
+	cron, err := domain.ParseCronSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("programación %s: %w", name, err)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error serializando el payload de %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules = append(s.schedules, &jobSchedule{
+		name:    name,
+		cron:    cron,
+		jobType: jobType,
+		payload: data,
+	})
+	return nil
+}
+
+// ==================== ADMINISTRACIÓN ====================
+
+// ListJobs lista los jobs con paginación, los actualizados más recientemente primero
+// status y jobType vacíos no filtran
+func (s *jobService) ListJobs(status, jobType string, page, limit int) (*domain.JobListResponse, error) {
+	if status != "" && !isJobStatus(status) {
+		return nil, domain.ErrJobInvalidStatus
+	}
+
+	jobs, total, err := s.jobRepo.FindJobs(status, jobType, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.JobDTO, len(jobs))
+	for i := range jobs {
+		result[i] = toJobDTO(&jobs[i])
+	}
+	return &domain.JobListResponse{
+		Jobs:  result,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}, nil
+}
+
+// RequeueJob vuelve a encolar un job fallido con todos sus intentos disponibles (mismo payload)
+func (s *jobService) RequeueJob(id int64) (*domain.JobDTO, error) {
+	job, err := s.jobRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrJobNotFound
+		}
+		return nil, err
+	}
+	if job.Status != domain.JobStatusFailed {
+		return nil, domain.ErrJobNotRequeuable
+	}
+
+	requeued, err := s.jobRepo.Requeue(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !requeued {
+		// Otro admin lo reencoló entre la lectura y la actualización
+		return nil, domain.ErrJobNotRequeuable
+	}
+
+	job, err = s.jobRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notify()
+	return toJobDTO(job), nil
+}
+
+// ==================== WORKERS ====================
+
+// Start toma los jobs vencidos cada pollInterval (o al encolar jobs / liberarse un worker) y los
+// ejecuta en hasta workers goroutines; también encola las ejecuciones programadas que vencieron
+// Bloquea hasta que ctx se cancele y los jobs en curso terminen, debe ejecutarse en una goroutine
+func (s *jobService) Start(ctx context.Context) {
+	s.mu.Lock()
+	now := time.Now()
+	for _, schedule := range s.schedules {
+		schedule.next = schedule.cron.Next(now)
+	}
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	slots := make(chan struct{}, s.workers)
+	var wg sync.WaitGroup
+	var lastMetrics time.Time
+
+	log.Printf("Cola de jobs iniciada (worker %s, %d workers)", s.workerID, s.workers)
+	for {
+		s.enqueueScheduled(time.Now())
+		s.dispatch(ctx, slots, &wg)
+
+		if time.Since(lastMetrics) >= jobMetricsInterval {
+			s.refreshMetrics()
+			lastMetrics = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Println("Cola de jobs detenida")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// enqueueScheduled encola las ejecuciones programadas vencidas. La unique_key por ejecución evita
+// duplicados entre réplicas; si el servicio estuvo caído solo se encola la última ejecución perdida
+func (s *jobService) enqueueScheduled(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, schedule := range s.schedules {
+		if schedule.next.IsZero() || now.Before(schedule.next) {
+			continue
+		}
+
+		opts := domain.EnqueueJobOptions{
+			RunAt:     schedule.next,
+			UniqueKey: fmt.Sprintf("schedule:%s:%d", schedule.name, schedule.next.Unix()),
+		}
+		if err := s.enqueue(schedule.jobType, schedule.payload, opts); err != nil {
+			// Se reintenta en la próxima vuelta: next no avanza
+			log.Printf("Error encolando la programación %s: %v", schedule.name, err)
+			continue
+		}
+		schedule.next = schedule.cron.Next(now)
+	}
+}
+
+// dispatch toma tantos jobs vencidos como workers libres haya y los ejecuta en segundo plano
+// Cada job se reserva antes de ejecutarlo para que dos workers (o réplicas) no lo ejecuten a la vez
+func (s *jobService) dispatch(ctx context.Context, slots chan struct{}, wg *sync.WaitGroup) {
+	free := cap(slots) - len(slots)
+	if free == 0 || ctx.Err() != nil {
+		return
+	}
+
+	jobs, err := s.jobRepo.FindDueJobs(time.Now(), free)
+	if err != nil {
+		log.Printf("Error buscando jobs vencidos: %v", err)
+		return
+	}
+
+	for i := range jobs {
+		job := jobs[i]
+
+		claimed, err := s.jobRepo.Claim(&job, s.workerID, time.Now().Add(s.lease+jobLeaseGrace))
+		if err != nil {
+			log.Printf("Error tomando el job %d: %v", job.ID, err)
+			return
+		}
+		if !claimed {
+			continue
+		}
+		job.Attempts++
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+				s.notify()
+			}()
+			s.run(ctx, &job)
+		}()
+	}
+}
+
+// run ejecuta un intento del job y registra el resultado
+// Un job que supera sus intentos fue tomado de un worker caído con el último intento: se marca fallido sin ejecutarlo
+func (s *jobService) run(ctx context.Context, job *dao.JobDAO) {
+	start := time.Now()
+	attempt := job.Attempts
+
+	var err error
+	if attempt > job.MaxAttempts {
+		err = errors.New("el worker no terminó el último intento (lease vencido)")
+	} else {
+		s.mu.RLock()
+		handler, ok := s.handlers[job.Type]
+		s.mu.RUnlock()
+
+		if ok {
+			runCtx, cancel := context.WithTimeout(ctx, s.lease)
+			err = runJobHandler(runCtx, handler, json.RawMessage(job.Payload))
+			cancel()
+		} else {
+			err = fmt.Errorf("no hay handler registrado para %s", job.Type)
+		}
+	}
+
+	now := time.Now()
+	result := metrics.JobSucceeded
+	switch {
+	case err == nil:
+		job.Status = domain.JobStatusSucceeded
+		job.LastError = ""
+		job.FinishedAt = &now
+	case attempt >= job.MaxAttempts:
+		result = metrics.JobFailed
+		job.Status = domain.JobStatusFailed
+		job.LastError = truncateJobError(err.Error())
+		job.FinishedAt = &now
+	default:
+		result = metrics.JobRetry
+		job.Status = domain.JobStatusPending
+		job.LastError = truncateJobError(err.Error())
+		job.RunAt = now.Add(jobBackoff(attempt))
+	}
+	metrics.ObserveJob(job.Type, result, now.Sub(start))
+
+	if err != nil {
+		log.Printf("Job %d (%s) intento %d/%d falló: %v", job.ID, job.Type, attempt, job.MaxAttempts, err)
+	}
+
+	finished, err := s.jobRepo.Finish(job, s.workerID, attempt)
+	if err != nil {
+		log.Printf("Error registrando el resultado del job %d: %v", job.ID, err)
+	} else if !finished {
+		log.Printf("El lease del job %d venció antes de terminar: el resultado se descarta", job.ID)
+	}
+}
+
+// runJobHandler ejecuta el handler convirtiendo un panic en error para no tirar abajo el worker
+func runJobHandler(ctx context.Context, handler JobHandler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+// refreshMetrics actualiza el gauge de jobs por estado
+func (s *jobService) refreshMetrics() {
+	counts, err := s.jobRepo.CountByStatus()
+	if err != nil {
+		log.Printf("Error contando jobs por estado: %v", err)
+		return
+	}
+	metrics.SetJobsByStatus(domain.JobStatuses, counts)
+}
+
+// cleanup es el handler de jobs.cleanup: borra de a lotes los jobs terminados con éxito hace más de
+// retention_days. Los fallidos se conservan para que un admin los revise o reencole
+func (s *jobService) cleanup(ctx context.Context, payload json.RawMessage) error {
+	var p domain.JobCleanupPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("payload inválido: %w", err)
+	}
+	if p.RetentionDays <= 0 {
+		return errors.New("retention_days debe ser mayor a 0")
+	}
+
+	before := time.Now().AddDate(0, 0, -p.RetentionDays)
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := s.jobRepo.DeleteFinished(before, jobCleanupBatch)
+		if err != nil {
+			return err
+		}
+		total += deleted
+		if deleted < jobCleanupBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Limpieza de jobs: %d jobs terminados borrados", total)
+	}
+	return ctx.Err()
+}
+
+// notify despierta al loop de Start sin bloquear
+func (s *jobService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ==================== HELPERS ====================
+
+// jobBackoff es la espera antes del siguiente intento: 30s, 1m, 2m, ... hasta 1h
+func jobBackoff(attempts int) time.Duration {
+	delay := jobRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= jobRetryMax {
+			return jobRetryMax
+		}
+	}
+	return delay
+}
+
+func isJobStatus(status string) bool {
+	for _, s := range domain.JobStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateJobError(message string) string {
+	runes := []rune(message)
+	if len(runes) > jobErrorLimit {
+		return string(runes[:jobErrorLimit])
+	}
+	return message
+}
+
+func toJobDTO(job *dao.JobDAO) *domain.JobDTO {
+	dto := &domain.JobDTO{
+		ID:          job.ID,
+		Type:        job.Type,
+		Payload:     json.RawMessage(job.Payload),
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt,
+		LeasedBy:    job.LeasedBy,
+		LastError:   job.LastError,
+		FinishedAt:  job.FinishedAt,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	if job.UniqueKey != nil {
+		dto.UniqueKey = *job.UniqueKey
+	}
+	return dto
+}
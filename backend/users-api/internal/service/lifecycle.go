@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// LifecycleEventPublisher publica eventos de ciclo de vida del usuario (implementado en messaging)
+type LifecycleEventPublisher interface {
+	PublishUserLifecycle(ctx context.Context, event domain.UserLifecycleEvent) error
+}
+
+// LifecycleService define los hooks de campañas de marketing (bienvenida y regreso)
+type LifecycleService interface {
+	// UserRegistered publica user.registered para un usuario recién creado
+	UserRegistered(userID int64)
+
+	// PublishInactiveUsers publica user.inactive_30d para hasta maxPerRun usuarios inactivos
+	// Retorna la cantidad de eventos publicados
+	PublishInactiveUsers() (int, error)
+
+	// StartInactivityJob ejecuta PublishInactiveUsers periódicamente hasta que ctx se cancele
+	StartInactivityJob(ctx context.Context, interval time.Duration)
+
+	// GetNotificationPreferences obtiene las preferencias de notificación del usuario
+	GetNotificationPreferences(userID int64) (*domain.NotificationPreferencesDTO, error)
+
+	// UpdateNotificationPreferences actualiza las preferencias de notificación del usuario
+	UpdateNotificationPreferences(userID int64, req domain.UpdateNotificationPreferencesRequest) (*domain.NotificationPreferencesDTO, error)
+}
+
+type lifecycleService struct {
+	userRepo  repository.UserRepository
+	publisher LifecycleEventPublisher
+	maxPerRun int
+}
+
+// NewLifecycleService crea una nueva instancia del servicio de ciclo de vida
+// publisher puede ser nil (sin RabbitMQ no se publican eventos)
+// maxPerRun limita los user.inactive_30d publicados por ejecución del job
+func NewLifecycleService(userRepo repository.UserRepository, publisher LifecycleEventPublisher, maxPerRun int) LifecycleService {
+	if maxPerRun <= 0 {
+		maxPerRun = 200
+	}
+	return &lifecycleService{
+		userRepo:  userRepo,
+		publisher: publisher,
+		maxPerRun: maxPerRun,
+	}
+}
+
+// UserRegistered publica user.registered de forma asíncrona (no demora ni hace fallar el registro)
+// Las preferencias se leen al publicar, no al registrarse
+func (s *lifecycleService) UserRegistered(userID int64) {
+	if s.publisher == nil {
+		return
+	}
+
+	go func() {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			log.Printf("No se pudo leer el usuario %d para user.registered: %v", userID, err)
+			return
+		}
+		if !user.MarketingEmails {
+			return
+		}
+
+		event := newLifecycleEvent(user, domain.EventTypeUserRegistered, domain.NewUserRegisteredEventID(user.ID))
+		if err := s.publisher.PublishUserLifecycle(context.Background(), event); err != nil {
+			log.Printf("Error publicando user.registered (user_id=%d): %v", userID, err)
+		}
+	}()
+}
+
+// PublishInactiveUsers busca usuarios sin iniciar sesión hace 30 días y publica user.inactive_30d
+//
+//   - Cada usuario recibe un solo evento por período de inactividad (inactive_notified_at)
+//   - Los que no aceptan emails de marketing se marcan igual, sin publicar: si luego los
+//     aceptan, reciben el evento en su próximo período de inactividad
+//   - Como máximo maxPerRun eventos por ejecución para no saturar al servicio de marketing;
+//     el resto queda para la próxima ejecución
+func (s *lifecycleService) PublishInactiveUsers() (int, error) {
+	if s.publisher == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	users, err := s.userRepo.FindInactivePendingNotice(now.Add(-domain.InactivityThreshold), s.maxPerRun)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, user := range users {
+		if user.MarketingEmails {
+			lastActiveAt := lastActivity(user)
+			event := newLifecycleEvent(user, domain.EventTypeUserInactive30d, domain.NewUserInactiveEventID(user.ID, lastActiveAt))
+			event.LastActiveAt = &lastActiveAt
+
+			if err := s.publisher.PublishUserLifecycle(context.Background(), event); err != nil {
+				// Sin marcar: se reintenta en la próxima ejecución
+				return published, err
+			}
+			published++
+		}
+
+		if err := s.userRepo.MarkInactiveNotified(user.ID, now); err != nil {
+			return published, err
+		}
+	}
+
+	return published, nil
+}
+
+// StartInactivityJob revisa periódicamente los usuarios inactivos
+// Bloquea hasta que ctx se cancele, debe ejecutarse en una goroutine
+func (s *lifecycleService) StartInactivityJob(ctx context.Context, interval time.Duration) {
+	if s.publisher == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := s.PublishInactiveUsers()
+		if err != nil {
+			log.Printf("Error publicando user.inactive_30d: %v", err)
+		} else if published > 0 {
+			log.Printf("Eventos user.inactive_30d publicados: %d", published)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Job de usuarios inactivos detenido")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetNotificationPreferences obtiene las preferencias de notificación del usuario
+func (s *lifecycleService) GetNotificationPreferences(userID int64) (*domain.NotificationPreferencesDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	return &domain.NotificationPreferencesDTO{MarketingEmails: user.MarketingEmails}, nil
+}
+
+// UpdateNotificationPreferences actualiza las preferencias de notificación del usuario
+// Aplica a los eventos que se publiquen desde ahora
+func (s *lifecycleService) UpdateNotificationPreferences(userID int64, req domain.UpdateNotificationPreferencesRequest) (*domain.NotificationPreferencesDTO, error) {
+	if _, err := s.GetNotificationPreferences(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateMarketingEmails(userID, *req.MarketingEmails); err != nil {
+		return nil, err
+	}
+
+	return &domain.NotificationPreferencesDTO{MarketingEmails: *req.MarketingEmails}, nil
+}
+
+// newLifecycleEvent arma el payload con los datos mínimos del usuario
+func newLifecycleEvent(user *dao.UserDAO, eventType, eventID string) domain.UserLifecycleEvent {
+	return domain.UserLifecycleEvent{
+		EventID:      eventID,
+		EventType:    eventType,
+		UserID:       user.ID,
+		FirstName:    user.Name,
+		Email:        user.Email,
+		RegisteredAt: user.CreatedAt,
+		Timestamp:    time.Now(),
+	}
+}
+
+// lastActivity retorna el último login del usuario, o su registro si nunca inició sesión
+func lastActivity(user *dao.UserDAO) time.Time {
+	if user.LastLoginAt != nil {
+		return *user.LastLoginAt
+	}
+	return user.CreatedAt
+}
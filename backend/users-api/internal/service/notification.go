@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// NotificationService define las operaciones de notificaciones in-app
+type NotificationService interface {
+	Notify(notification domain.NewNotification) error
+	SendSystemNotification(userID int64, req domain.CreateSystemNotificationRequest) (*domain.NotificationDTO, error)
+	ListNotifications(userID int64, unreadOnly bool, page, limit int) ([]domain.NotificationDTO, int64, error)
+	GetUnreadCount(userID int64) (int64, error)
+	MarkAsRead(userID, notificationID int64) (*domain.NotificationDTO, error)
+	MarkAllAsRead(userID int64) (int64, error)
+	DeleteNotification(userID, notificationID int64) error
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	preferencesRepo  repository.PreferencesRepository
+}
+
+// NewNotificationService crea una nueva instancia del servicio de notificaciones
+func NewNotificationService(notificationRepo repository.NotificationRepository, userRepo repository.UserRepository, preferencesRepo repository.PreferencesRepository) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		preferencesRepo:  preferencesRepo,
+	}
+}
+
+// Notify guarda una notificación originada por un evento
+// Los eventos reentregados por RabbitMQ no generan duplicados (único por event_id + user_id)
+// Las notificaciones de un tipo que el usuario desactivó en sus preferencias se descartan
+func (s *notificationService) Notify(notification domain.NewNotification) error {
+	allowed, err := s.allows(notification.UserID, notification.Type)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Printf("Notificación %s descartada por las preferencias del usuario (event_id=%s, user_id=%d)", notification.Type, notification.EventID, notification.UserID)
+		return nil
+	}
+
+	notificationDAO := &dao.NotificationDAO{
+		UserID:    notification.UserID,
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		TripID:    notification.TripID,
+		BookingID: notification.BookingID,
+	}
+	if notification.EventID != "" {
+		eventID := notification.EventID
+		notificationDAO.EventID = &eventID
+	}
+
+	created, err := s.notificationRepo.Create(notificationDAO)
+	if err != nil {
+		return err
+	}
+	if !created {
+		log.Printf("Notificación duplicada ignorada (event_id=%s, user_id=%d)", notification.EventID, notification.UserID)
+	}
+
+	return nil
+}
+
+// allows consulta las preferencias de notificación del usuario (sin preferencias guardadas, todas activas)
+func (s *notificationService) allows(userID int64, notificationType string) (bool, error) {
+	preferences, err := s.preferencesRepo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	return domain.NotificationSettingsDTO{
+		BookingUpdates: preferences.NotifyBookingUpdates,
+		ChatMentions:   preferences.NotifyChatMentions,
+	}.Allows(notificationType), nil
+}
+
+// SendSystemNotification envía un mensaje de sistema a un usuario
+func (s *notificationService) SendSystemNotification(userID int64, req domain.CreateSystemNotificationRequest) (*domain.NotificationDTO, error) {
+	// 1. Validar que el usuario existe
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	// 2. Crear la notificación
+	notificationDAO := &dao.NotificationDAO{
+		UserID:  userID,
+		Type:    domain.NotificationTypeSystem,
+		Title:   req.Title,
+		Message: req.Message,
+	}
+
+	if _, err := s.notificationRepo.Create(notificationDAO); err != nil {
+		return nil, err
+	}
+
+	dto := s.convertToDTO(notificationDAO)
+	return &dto, nil
+}
+
+// ListNotifications obtiene las notificaciones de un usuario con paginación (más recientes primero)
+func (s *notificationService) ListNotifications(userID int64, unreadOnly bool, page, limit int) ([]domain.NotificationDTO, int64, error) {
+	// Validar parámetros de paginación
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	notifications, total, err := s.notificationRepo.FindByUserID(userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	notificationDTOs := make([]domain.NotificationDTO, len(notifications))
+	for i := range notifications {
+		notificationDTOs[i] = s.convertToDTO(&notifications[i])
+	}
+
+	return notificationDTOs, total, nil
+}
+
+// GetUnreadCount obtiene la cantidad de notificaciones no leídas (badge de la app)
+func (s *notificationService) GetUnreadCount(userID int64) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkAsRead marca una notificación como leída
+// Es idempotente: marcar una notificación ya leída conserva la fecha de lectura original
+func (s *notificationService) MarkAsRead(userID, notificationID int64) (*domain.NotificationDTO, error) {
+	notification, err := s.notificationRepo.FindByIDAndUserID(notificationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("notificación no encontrada")
+		}
+		return nil, err
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.notificationRepo.MarkRead(notification.ID, now); err != nil {
+			return nil, err
+		}
+		notification.ReadAt = &now
+	}
+
+	dto := s.convertToDTO(notification)
+	return &dto, nil
+}
+
+// MarkAllAsRead marca todas las notificaciones del usuario como leídas
+func (s *notificationService) MarkAllAsRead(userID int64) (int64, error) {
+	return s.notificationRepo.MarkAllRead(userID, time.Now())
+}
+
+// DeleteNotification elimina una notificación del usuario
+func (s *notificationService) DeleteNotification(userID, notificationID int64) error {
+	deleted, err := s.notificationRepo.Delete(notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("notificación no encontrada")
+	}
+	return nil
+}
+
+// convertToDTO convierte un NotificationDAO a NotificationDTO
+func (s *notificationService) convertToDTO(notificationDAO *dao.NotificationDAO) domain.NotificationDTO {
+	return domain.NotificationDTO{
+		ID:        notificationDAO.ID,
+		Type:      notificationDAO.Type,
+		Title:     notificationDAO.Title,
+		Message:   notificationDAO.Message,
+		TripID:    notificationDAO.TripID,
+		BookingID: notificationDAO.BookingID,
+		IsRead:    notificationDAO.ReadAt != nil,
+		ReadAt:    notificationDAO.ReadAt,
+		CreatedAt: notificationDAO.CreatedAt,
+	}
+}
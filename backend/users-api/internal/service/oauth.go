@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"users-api/internal/domain"
+)
+
+// OAuthProvider es un proveedor de login social (OAuth2 authorization code)
+// Para sumar un proveedor alcanza con implementarla y pasarla a NewAuthService
+type OAuthProvider interface {
+	// Name es el nombre del proveedor en la ruta (/auth/:provider) y en la columna users.provider
+	Name() string
+	// AuthCodeURL es la URL del proveedor a la que se redirige al usuario para iniciar sesión
+	AuthCodeURL(state string) string
+	// Exchange canjea el código del callback y obtiene el usuario del proveedor
+	Exchange(ctx context.Context, code string) (*domain.OAuthIdentity, error)
+}
+
+// generateOAuthState genera el state que protege el callback contra CSRF
+func generateOAuthState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ==================== GOOGLE ====================
+
+// Endpoints OAuth2 / OpenID Connect de Google
+const (
+	googleAuthURL     = "https://accounts.google.com/o/oauth2/v2/auth"
+	googleTokenURL    = "https://oauth2.googleapis.com/token"
+	googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+)
+
+type googleOAuthProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	httpClient   *http.Client
+}
+
+// NewGoogleOAuthProvider crea el proveedor de login con Google
+// redirectURL es el callback registrado en Google Cloud (GET /auth/google/callback de esta API)
+func NewGoogleOAuthProvider(clientID, clientSecret, redirectURL string, timeout time.Duration) OAuthProvider {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &googleOAuthProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		httpClient:   &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *googleOAuthProvider) Name() string {
+	return domain.OAuthProviderGoogle
+}
+
+// AuthCodeURL pide los scopes openid, email y profile (sin acceso offline: no se guardan tokens de Google)
+func (p *googleOAuthProvider) AuthCodeURL(state string) string {
+	params := url.Values{
+		"client_id":     {p.clientID},
+		"redirect_uri":  {p.redirectURL},
+		"response_type": {"code"},
+		"scope":         {"openid email profile"},
+		"state":         {state},
+		"prompt":        {"select_account"},
+	}
+	return googleAuthURL + "?" + params.Encode()
+}
+
+// Exchange canjea el código por un access token y con él lee el perfil del usuario
+// El perfil se pide por TLS directamente a Google, así que no hace falta validar la firma del id_token
+func (p *googleOAuthProvider) Exchange(ctx context.Context, code string) (*domain.OAuthIdentity, error) {
+	form := url.Values{
+		"code":          {code},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"redirect_uri":  {p.redirectURL},
+		"grant_type":    {"authorization_code"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("canje del código: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("canje del código: respuesta sin access_token")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, googleUserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	var profile struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+		Picture       string `json:"picture"`
+	}
+	if err := p.doJSON(req, &profile); err != nil {
+		return nil, fmt.Errorf("perfil del usuario: %w", err)
+	}
+
+	givenName := profile.GivenName
+	if givenName == "" {
+		givenName = profile.Name
+	}
+	return &domain.OAuthIdentity{
+		Provider:      domain.OAuthProviderGoogle,
+		ProviderID:    profile.Sub,
+		Email:         profile.Email,
+		EmailVerified: profile.EmailVerified,
+		Name:          givenName,
+		Lastname:      profile.FamilyName,
+		PhotoURL:      profile.Picture,
+	}, nil
+}
+
+// doJSON ejecuta la request y decodifica la respuesta; cualquier respuesta que no sea 2xx es un error
+func (p *googleOAuthProvider) doJSON(req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("respuesta HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// partnerAPIKeyPrefix identifica las API keys de partners (facilita detectarlas si se filtran)
+const partnerAPIKeyPrefix = "cpk_"
+
+// partnerTouchInterval evita escribir last_used_at en cada request SCIM
+const partnerTouchInterval = 5 * time.Minute
+
+// PartnerService define la gestión de partners corporativos y sus API keys
+type PartnerService interface {
+	CreatePartner(req domain.CreatePartnerRequest) (*domain.CreatePartnerResponse, error)
+	ListPartners() ([]*domain.PartnerDTO, error)
+	RevokePartner(id int64) error
+	UpdateVerifiedDomains(id int64, req domain.UpdatePartnerDomainsRequest) (*domain.PartnerDTO, error)
+
+	// Authenticate valida una API key y retorna el partner activo al que pertenece
+	Authenticate(apiKey string) (*dao.PartnerDAO, error)
+}
+
+type partnerService struct {
+	provisioningRepo repository.ProvisioningRepository
+}
+
+// NewPartnerService crea una nueva instancia del servicio de partners
+func NewPartnerService(provisioningRepo repository.ProvisioningRepository) PartnerService {
+	return &partnerService{provisioningRepo: provisioningRepo}
+}
+
+// CreatePartner da de alta un partner y genera su API key (se retorna en claro una única vez)
+func (s *partnerService) CreatePartner(req domain.CreatePartnerRequest) (*domain.CreatePartnerResponse, error) {
+	domains, err := domain.NormalizeEmailDomains(req.VerifiedDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verificar que el nombre no exista
+	_, err = s.provisioningRepo.FindPartnerByName(req.Name)
+	if err == nil {
+		return nil, errors.New("ya existe un partner con ese nombre")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	apiKey := partnerAPIKeyPrefix + hex.EncodeToString(b)
+
+	partner := &dao.PartnerDAO{
+		Name:            req.Name,
+		APIKeyHash:      hashAPIKey(apiKey),
+		APIKeyPrefix:    apiKey[:len(partnerAPIKeyPrefix)+8],
+		Active:          true,
+		VerifiedDomains: strings.Join(domains, ","),
+	}
+	if err := s.provisioningRepo.CreatePartner(partner); err != nil {
+		return nil, err
+	}
+
+	return &domain.CreatePartnerResponse{
+		Partner: toPartnerDTO(partner),
+		APIKey:  apiKey,
+	}, nil
+}
+
+// ListPartners lista los partners sin sus API keys
+func (s *partnerService) ListPartners() ([]*domain.PartnerDTO, error) {
+	partners, err := s.provisioningRepo.FindAllPartners()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.PartnerDTO, len(partners))
+	for i := range partners {
+		result[i] = toPartnerDTO(&partners[i])
+	}
+	return result, nil
+}
+
+// RevokePartner revoca la API key del partner; los usuarios provisionados no se modifican
+func (s *partnerService) RevokePartner(id int64) error {
+	if _, err := s.provisioningRepo.FindPartnerByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("partner no encontrado")
+		}
+		return err
+	}
+	return s.provisioningRepo.RevokePartner(id)
+}
+
+// UpdateVerifiedDomains reemplaza los dominios de email del partner; los usuarios ya provisionados no se modifican
+func (s *partnerService) UpdateVerifiedDomains(id int64, req domain.UpdatePartnerDomainsRequest) (*domain.PartnerDTO, error) {
+	domains, err := domain.NormalizeEmailDomains(req.VerifiedDomains)
+	if err != nil {
+		return nil, err
+	}
+
+	partner, err := s.provisioningRepo.FindPartnerByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("partner no encontrado")
+		}
+		return nil, err
+	}
+
+	partner.VerifiedDomains = strings.Join(domains, ",")
+	if err := s.provisioningRepo.UpdatePartnerDomains(id, partner.VerifiedDomains); err != nil {
+		return nil, err
+	}
+	return toPartnerDTO(partner), nil
+}
+
+// Authenticate busca el partner por el hash de la API key
+func (s *partnerService) Authenticate(apiKey string) (*dao.PartnerDAO, error) {
+	partner, err := s.provisioningRepo.FindActivePartnerByKeyHash(hashAPIKey(apiKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("API key inválida o revocada")
+		}
+		return nil, err
+	}
+
+	// Registrar el uso (como máximo una escritura cada partnerTouchInterval); un error no rechaza la request
+	now := time.Now()
+	if partner.LastUsedAt == nil || now.Sub(*partner.LastUsedAt) > partnerTouchInterval {
+		if err := s.provisioningRepo.TouchPartner(partner.ID, now); err != nil {
+			log.Printf("Error registrando uso de la API key (partner_id=%d): %v", partner.ID, err)
+		}
+	}
+
+	return partner, nil
+}
+
+// hashAPIKey hashea la API key con SHA-256: son valores aleatorios de 256 bits, no hace falta bcrypt
+// y el hash permite buscar el partner con un índice
+func hashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func toPartnerDTO(partner *dao.PartnerDAO) *domain.PartnerDTO {
+	return &domain.PartnerDTO{
+		ID:              partner.ID,
+		Name:            partner.Name,
+		APIKeyPrefix:    partner.APIKeyPrefix,
+		Active:          partner.Active,
+		VerifiedDomains: partner.Domains(),
+		LastUsedAt:      partner.LastUsedAt,
+		CreatedAt:       partner.CreatedAt,
+	}
+}
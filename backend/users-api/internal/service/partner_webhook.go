@@ -0,0 +1,701 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// webhookSecretPrefix identifica los secretos de firma de webhooks
+const webhookSecretPrefix = "whsec_"
+
+// maxWebhooksPerPartner limita las suscripciones de un partner
+const maxWebhooksPerPartner = 10
+
+// Backoff de los reintentos: webhookRetryBase * 2^(intento-1), como máximo webhookRetryMax
+const (
+	webhookRetryBase = time.Minute
+	webhookRetryMax  = 6 * time.Hour
+)
+
+// webhookDispatchBatch es la cantidad máxima de entregas por corrida del dispatcher
+const webhookDispatchBatch = 100
+
+// webhookResponseLimit es lo que se lee de la respuesta del partner para el log de errores
+const webhookResponseLimit = 200
+
+// PartnerWebhookService define los webhooks de partners sobre el ciclo de vida del usuario
+// (user.created, user.updated, user.deleted). Un partner solo recibe eventos de los usuarios
+// que lo autorizaron; los usuarios provisionados por SCIM lo autorizan al crearse
+type PartnerWebhookService interface {
+	// API de partners (autenticada con la API key)
+	CreateWebhook(partnerID int64, req domain.CreateWebhookRequest) (*domain.WebhookWithSecretDTO, error)
+	ListWebhooks(partnerID int64) ([]*domain.WebhookDTO, error)
+	UpdateWebhook(partnerID, id int64, req domain.UpdateWebhookRequest) (*domain.WebhookDTO, error)
+	RotateSecret(partnerID, id int64) (*domain.WebhookWithSecretDTO, error)
+	DeleteWebhook(partnerID, id int64) error
+	ListDeliveries(partnerID, webhookID int64, status string, page, limit int) (*domain.WebhookDeliveryListResponse, error)
+	RetryDelivery(partnerID, webhookID, deliveryID int64) (*domain.WebhookDeliveryDTO, error)
+
+	// Autorizaciones del usuario autenticado
+	ListAuthorizedPartners(userID int64) ([]*domain.AuthorizedPartnerDTO, error)
+	AuthorizePartner(userID, partnerID int64) error
+	RevokePartnerAuthorization(userID, partnerID int64) error
+
+	// Hooks del ciclo de vida: encolan las entregas; un error se loguea y no hace fallar la operación
+	UserProvisioned(partnerID int64, user *dao.UserDAO)
+	UserUpdated(user *dao.UserDAO)
+	UserDeleted(user *dao.UserDAO)
+
+	// DeliverDue envía las entregas pendientes cuyo próximo intento venció
+	DeliverDue(ctx context.Context) (*domain.WebhookRunResult, error)
+
+	// StartDispatcher ejecuta DeliverDue periódicamente (y al encolar eventos) hasta que ctx se cancele
+	StartDispatcher(ctx context.Context, interval time.Duration)
+}
+
+type partnerWebhookService struct {
+	webhookRepo      repository.PartnerWebhookRepository
+	provisioningRepo repository.ProvisioningRepository
+	httpClient       *http.Client
+	maxAttempts      int
+	allowPrivate     bool // Acepta URLs http y de la red interna (solo desarrollo, WEBHOOK_ALLOW_PRIVATE_NETWORKS)
+
+	// wake despierta al dispatcher cuando se encolan entregas (buffer 1: las señales se combinan)
+	wake chan struct{}
+}
+
+// NewPartnerWebhookService crea una nueva instancia del servicio de webhooks de partners
+// maxAttempts es la cantidad de envíos antes de marcar una entrega como fallida
+// allowPrivate permite webhooks a localhost y a la red interna; en producción debe ser false (SSRF)
+func NewPartnerWebhookService(webhookRepo repository.PartnerWebhookRepository, provisioningRepo repository.ProvisioningRepository, timeout time.Duration, maxAttempts int, allowPrivate bool) PartnerWebhookService {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+	return &partnerWebhookService{
+		webhookRepo:      webhookRepo,
+		provisioningRepo: provisioningRepo,
+		httpClient:       newWebhookHTTPClient(timeout, allowPrivate),
+		maxAttempts:      maxAttempts,
+		allowPrivate:     allowPrivate,
+		wake:             make(chan struct{}, 1),
+	}
+}
+
+// ==================== API DE PARTNERS ====================
+
+// CreateWebhook suscribe una URL del partner; el secreto de firma se retorna en claro una única vez
+func (s *partnerWebhookService) CreateWebhook(partnerID int64, req domain.CreateWebhookRequest) (*domain.WebhookWithSecretDTO, error) {
+	if err := s.validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.webhookRepo.CountWebhooks(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWebhooksPerPartner {
+		return nil, domain.ErrWebhookLimitReached
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &dao.PartnerWebhookDAO{
+		PartnerID: partnerID,
+		URL:       req.URL,
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+	}
+	if err := s.webhookRepo.CreateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	return &domain.WebhookWithSecretDTO{Webhook: toWebhookDTO(webhook), Secret: secret}, nil
+}
+
+// ListWebhooks lista los webhooks del partner sin sus secretos
+func (s *partnerWebhookService) ListWebhooks(partnerID int64) ([]*domain.WebhookDTO, error) {
+	webhooks, err := s.webhookRepo.FindWebhooks(partnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.WebhookDTO, len(webhooks))
+	for i := range webhooks {
+		result[i] = toWebhookDTO(&webhooks[i])
+	}
+	return result, nil
+}
+
+// UpdateWebhook cambia la URL, los eventos o pausa/reanuda el webhook
+// Las entregas ya encoladas se envían a la URL vigente al momento del envío
+func (s *partnerWebhookService) UpdateWebhook(partnerID, id int64, req domain.UpdateWebhookRequest) (*domain.WebhookDTO, error) {
+	webhook, err := s.findWebhook(partnerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := s.validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		events, err := normalizeWebhookEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.webhookRepo.UpdateWebhook(webhook); err != nil {
+		return nil, err
+	}
+	return toWebhookDTO(webhook), nil
+}
+
+// RotateSecret genera un nuevo secreto de firma; el anterior deja de valer de inmediato
+func (s *partnerWebhookService) RotateSecret(partnerID, id int64) (*domain.WebhookWithSecretDTO, error) {
+	webhook, err := s.findWebhook(partnerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+	if err := s.webhookRepo.UpdateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	return &domain.WebhookWithSecretDTO{Webhook: toWebhookDTO(webhook), Secret: secret}, nil
+}
+
+// DeleteWebhook borra el webhook y su log de entregas (las pendientes no se envían)
+func (s *partnerWebhookService) DeleteWebhook(partnerID, id int64) error {
+	if _, err := s.findWebhook(partnerID, id); err != nil {
+		return err
+	}
+	return s.webhookRepo.DeleteWebhook(id)
+}
+
+// ListDeliveries lista el log de entregas del webhook con paginación; status vacío no filtra
+func (s *partnerWebhookService) ListDeliveries(partnerID, webhookID int64, status string, page, limit int) (*domain.WebhookDeliveryListResponse, error) {
+	if _, err := s.findWebhook(partnerID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.webhookRepo.FindDeliveries(webhookID, status, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.WebhookDeliveryDTO, len(deliveries))
+	for i := range deliveries {
+		result[i] = toWebhookDeliveryDTO(&deliveries[i])
+	}
+	return &domain.WebhookDeliveryListResponse{
+		Deliveries: result,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
+
+// RetryDelivery vuelve a encolar una entrega fallida con todos sus intentos disponibles (mismo event_id y payload)
+func (s *partnerWebhookService) RetryDelivery(partnerID, webhookID, deliveryID int64) (*domain.WebhookDeliveryDTO, error) {
+	if _, err := s.findWebhook(partnerID, webhookID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.webhookRepo.FindDelivery(webhookID, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	if delivery.Status != domain.WebhookDeliveryFailed {
+		return nil, domain.ErrWebhookDeliveryNotRetry
+	}
+
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	s.notifyDispatcher()
+	return toWebhookDeliveryDTO(delivery), nil
+}
+
+func (s *partnerWebhookService) findWebhook(partnerID, id int64) (*dao.PartnerWebhookDAO, error) {
+	webhook, err := s.webhookRepo.FindWebhook(partnerID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// ==================== AUTORIZACIONES ====================
+
+// ListAuthorizedPartners lista los partners que reciben los eventos del usuario
+func (s *partnerWebhookService) ListAuthorizedPartners(userID int64) ([]*domain.AuthorizedPartnerDTO, error) {
+	rows, err := s.webhookRepo.FindAuthorizedPartners(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.AuthorizedPartnerDTO, len(rows))
+	for i, row := range rows {
+		result[i] = &domain.AuthorizedPartnerDTO{
+			PartnerID:    row.PartnerID,
+			Name:         row.Name,
+			AuthorizedAt: row.AuthorizedAt,
+		}
+	}
+	return result, nil
+}
+
+// AuthorizePartner autoriza a un partner activo a recibir los eventos del usuario
+func (s *partnerWebhookService) AuthorizePartner(userID, partnerID int64) error {
+	partner, err := s.provisioningRepo.FindPartnerByID(partnerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrPartnerNotFound
+		}
+		return err
+	}
+	if !partner.Active {
+		return domain.ErrPartnerNotFound
+	}
+	return s.webhookRepo.CreateAuthorization(userID, partnerID)
+}
+
+// RevokePartnerAuthorization deja de enviar eventos del usuario al partner,
+// incluidas las entregas que todavía estaban pendientes
+func (s *partnerWebhookService) RevokePartnerAuthorization(userID, partnerID int64) error {
+	deleted, err := s.webhookRepo.DeleteAuthorization(userID, partnerID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain.ErrPartnerNotAuthorized
+	}
+
+	if _, err := s.webhookRepo.FailPendingDeliveries(partnerID, userID, "autorización revocada por el usuario"); err != nil {
+		log.Printf("Error cancelando entregas pendientes (partner_id=%d, user_id=%d): %v", partnerID, userID, err)
+	}
+	return nil
+}
+
+// ==================== HOOKS DEL CICLO DE VIDA ====================
+
+// UserProvisioned autoriza al partner que provisionó al usuario y encola user.created
+func (s *partnerWebhookService) UserProvisioned(partnerID int64, user *dao.UserDAO) {
+	if err := s.webhookRepo.CreateAuthorization(user.ID, partnerID); err != nil {
+		log.Printf("Error autorizando al partner %d para el usuario %d: %v", partnerID, user.ID, err)
+		return
+	}
+	s.enqueue(domain.WebhookEventUserCreated, user)
+}
+
+// UserUpdated encola user.updated
+func (s *partnerWebhookService) UserUpdated(user *dao.UserDAO) {
+	s.enqueue(domain.WebhookEventUserUpdated, user)
+}
+
+// UserDeleted encola user.deleted y borra las autorizaciones del usuario (se llama después de borrarlo)
+func (s *partnerWebhookService) UserDeleted(user *dao.UserDAO) {
+	s.enqueue(domain.WebhookEventUserDeleted, user)
+	if err := s.webhookRepo.DeleteUserAuthorizations(user.ID); err != nil {
+		log.Printf("Error borrando autorizaciones del usuario %d: %v", user.ID, err)
+	}
+}
+
+// enqueue crea una entrega por cada webhook suscripto a eventType de los partners autorizados por el usuario
+// Todas comparten el event_id; el payload se arma por partner (external_id solo para quien lo provisionó)
+func (s *partnerWebhookService) enqueue(eventType string, user *dao.UserDAO) {
+	webhooks, err := s.webhookRepo.FindSubscribedWebhooks(user.ID, eventType)
+	if err != nil {
+		log.Printf("Error buscando webhooks para %s (user_id=%d): %v", eventType, user.ID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	eventID, err := generateWebhookEventID()
+	if err != nil {
+		log.Printf("Error generando event_id para %s (user_id=%d): %v", eventType, user.ID, err)
+		return
+	}
+
+	now := time.Now()
+	deliveries := make([]dao.WebhookDeliveryDAO, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		payload, err := json.Marshal(domain.WebhookEvent{
+			EventID:    eventID,
+			EventType:  eventType,
+			OccurredAt: now.UTC(),
+			Data:       toWebhookEventUser(eventType, user, webhook.PartnerID),
+		})
+		if err != nil {
+			log.Printf("Error serializando %s (user_id=%d): %v", eventType, user.ID, err)
+			return
+		}
+
+		deliveries = append(deliveries, dao.WebhookDeliveryDAO{
+			WebhookID:     webhook.ID,
+			PartnerID:     webhook.PartnerID,
+			UserID:        user.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+
+	if err := s.webhookRepo.CreateDeliveries(deliveries); err != nil {
+		log.Printf("Error encolando %s (user_id=%d): %v", eventType, user.ID, err)
+		return
+	}
+	s.notifyDispatcher()
+}
+
+// notifyDispatcher despierta al dispatcher sin bloquear
+func (s *partnerWebhookService) notifyDispatcher() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ==================== DISPATCHER ====================
+
+// DeliverDue envía las entregas vencidas de a una; cada entrega se reserva antes de enviarla
+// para que dos réplicas no la envíen a la vez
+func (s *partnerWebhookService) DeliverDue(ctx context.Context) (*domain.WebhookRunResult, error) {
+	result := &domain.WebhookRunResult{}
+
+	deliveries, err := s.webhookRepo.FindDueDeliveries(time.Now(), webhookDispatchBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := map[int64]*dao.PartnerWebhookDAO{}
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+		delivery := &deliveries[i]
+
+		claimed, err := s.webhookRepo.ClaimDelivery(delivery.ID, delivery.NextAttemptAt, time.Now().Add(s.httpClient.Timeout+time.Minute))
+		if err != nil {
+			return result, err
+		}
+		if !claimed {
+			continue
+		}
+
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = s.webhookRepo.FindWebhook(delivery.PartnerID, delivery.WebhookID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return result, err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		switch s.attempt(ctx, webhook, delivery) {
+		case domain.WebhookDeliverySucceeded:
+			result.Delivered++
+		case domain.WebhookDeliveryFailed:
+			result.Failed++
+		default:
+			result.Retrying++
+		}
+	}
+
+	return result, nil
+}
+
+// attempt envía la entrega y registra el resultado; retorna el nuevo estado
+// Un webhook pausado o borrado consume el intento sin enviar
+func (s *partnerWebhookService) attempt(ctx context.Context, webhook *dao.PartnerWebhookDAO, delivery *dao.WebhookDeliveryDAO) string {
+	now := time.Now()
+	delivery.Attempts++
+
+	var statusCode int
+	var sendErr error
+	switch {
+	case webhook == nil:
+		sendErr = errors.New("el webhook fue borrado")
+	case !webhook.Active:
+		sendErr = errors.New("el webhook está pausado")
+	default:
+		statusCode, sendErr = s.send(ctx, webhook, delivery, now)
+	}
+
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	} else {
+		delivery.LastStatusCode = nil
+	}
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.maxAttempts || webhook == nil:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = truncateWebhookError(sendErr.Error())
+	default:
+		delivery.LastError = truncateWebhookError(sendErr.Error())
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		log.Printf("Error registrando la entrega %d: %v", delivery.ID, err)
+	}
+	return delivery.Status
+}
+
+// send hace el POST firmado; cualquier respuesta que no sea 2xx es un error reintentable
+func (s *partnerWebhookService) send(ctx context.Context, webhook *dao.PartnerWebhookDAO, delivery *dao.WebhookDeliveryDAO, now time.Time) (int, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CarPooling-Webhooks/1.0")
+	req.Header.Set(domain.WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(domain.WebhookHeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(domain.WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(domain.WebhookHeaderSignature, "t="+timestamp+",v1="+signWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return resp.StatusCode, fmt.Errorf("respuesta HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, fmt.Errorf("respuesta HTTP %d: %s", resp.StatusCode, body)
+}
+
+// StartDispatcher envía las entregas pendientes cada interval o cuando se encolan nuevas
+// Bloquea hasta que ctx se cancele, debe ejecutarse en una goroutine
+func (s *partnerWebhookService) StartDispatcher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := s.DeliverDue(ctx)
+		if err != nil {
+			log.Printf("Error enviando webhooks de partners: %v", err)
+		} else if result.Delivered+result.Retrying+result.Failed > 0 {
+			log.Printf("Webhooks de partners: %d entregados, %d a reintentar, %d fallidos", result.Delivered, result.Retrying, result.Failed)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("Dispatcher de webhooks de partners detenido")
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// ==================== HELPERS ====================
+
+// signWebhookPayload calcula HMAC-SHA256(secret, "<timestamp>.<payload>") en hex
+// El partner recalcula la firma con el mismo secreto y descarta timestamps viejos (replay)
+func signWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff es la espera antes del siguiente intento: 1m, 2m, 4m, ... hasta 6h
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryMax {
+			return webhookRetryMax
+		}
+	}
+	return delay
+}
+
+// validateWebhookURL exige https y un host público (ni localhost ni una IP interna)
+// Con allowPrivate (desarrollo) también acepta http y hosts internos
+func (s *partnerWebhookService) validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return domain.ErrWebhookInvalidURL
+	}
+	if s.allowPrivate {
+		if parsed.Scheme == "https" || parsed.Scheme == "http" {
+			return nil
+		}
+		return domain.ErrWebhookInvalidURL
+	}
+	if parsed.Scheme != "https" || isBlockedWebhookHost(parsed.Hostname()) {
+		return domain.ErrWebhookInvalidURL
+	}
+	return nil
+}
+
+// normalizeWebhookEvents valida los eventos y los guarda sin duplicados, separados por coma
+func normalizeWebhookEvents(events []string) (string, error) {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		valid := false
+		for _, eventType := range domain.WebhookEventTypes {
+			if event == eventType {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", domain.ErrWebhookInvalidEvent
+		}
+		if !seen[event] {
+			seen[event] = true
+			normalized = append(normalized, event)
+		}
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
+
+func generateWebhookEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
+
+func truncateWebhookError(message string) string {
+	if len(message) > 500 {
+		return message[:500]
+	}
+	return message
+}
+
+// toWebhookEventUser arma los datos del usuario para el partner que recibe el evento
+func toWebhookEventUser(eventType string, user *dao.UserDAO, partnerID int64) domain.WebhookEventUser {
+	data := domain.WebhookEventUser{UserID: user.ID}
+	if user.PartnerID != nil && *user.PartnerID == partnerID {
+		data.ExternalID = user.ExternalID
+	}
+	if eventType == domain.WebhookEventUserDeleted {
+		return data
+	}
+
+	active := user.Active
+	data.Email = user.Email
+	data.Name = user.Name
+	data.Lastname = user.Lastname
+	data.Active = &active
+	return data
+}
+
+func toWebhookDTO(webhook *dao.PartnerWebhookDAO) *domain.WebhookDTO {
+	return &domain.WebhookDTO{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    strings.Split(webhook.Events, ","),
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+func toWebhookDeliveryDTO(delivery *dao.WebhookDeliveryDAO) *domain.WebhookDeliveryDTO {
+	dto := &domain.WebhookDeliveryDTO{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		UserID:         delivery.UserID,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.Status == domain.WebhookDeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		dto.NextAttemptAt = &nextAttemptAt
+	}
+	return dto
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/repository"
+)
+
+// issuePasswordResetToken genera un token de restablecimiento de contraseña y guarda su hash
+// Retorna el token en claro para enviarlo por email; requestedIP vacío indica que no lo pidió el usuario (SCIM)
+func issuePasswordResetToken(resetTokenRepo repository.PasswordResetTokenRepository, emailService EmailService, userID int64, ttl time.Duration, requestedIP string) (string, error) {
+	token, err := emailService.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := resetTokenRepo.Create(&dao.PasswordResetTokenDAO{
+		UserID:      userID,
+		TokenHash:   hashVerificationToken(token), // Mismo hash SHA-256 que los tokens de verificación
+		RequestedIP: requestedIP,
+		ExpiresAt:   time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
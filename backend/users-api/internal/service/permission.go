@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// PermissionService define la gestión de permisos: el registro, los permisos por rol
+// y los permisos individuales (otorgados o revocados) de cada usuario
+type PermissionService interface {
+	GetRegistry() *domain.PermissionRegistryDTO
+	GetUserPermissions(userID int64) (*domain.UserPermissionsDTO, error)
+	UpdateUserPermissions(userID, adminID int64, req domain.PermissionOverridesRequest) (*domain.UserPermissionsDTO, error)
+
+	// EffectivePermissions calcula los permisos que viajan en el JWT del usuario
+	EffectivePermissions(user *dao.UserDAO) (domain.PermissionSet, error)
+}
+
+type permissionService struct {
+	permissionRepo repository.PermissionRepository
+	userRepo       repository.UserRepository
+}
+
+// NewPermissionService crea una nueva instancia del servicio de permisos
+func NewPermissionService(permissionRepo repository.PermissionRepository, userRepo repository.UserRepository) PermissionService {
+	return &permissionService{
+		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// GetRegistry retorna los permisos registrados (con su bit en el claim "perms") y los permisos de cada rol
+func (s *permissionService) GetRegistry() *domain.PermissionRegistryDTO {
+	permissions := make([]domain.PermissionDefinition, len(domain.PermissionRegistry))
+	for i, name := range domain.PermissionRegistry {
+		permissions[i] = domain.PermissionDefinition{Name: name, Bit: i}
+	}
+
+	return &domain.PermissionRegistryDTO{
+		Permissions: permissions,
+		Roles:       domain.RolePermissions,
+	}
+}
+
+// GetUserPermissions retorna los permisos de un usuario
+func (s *permissionService) GetUserPermissions(userID int64) (*domain.UserPermissionsDTO, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := s.permissionRepo.FindOverrides(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toUserPermissionsDTO(user, overrides), nil
+}
+
+// UpdateUserPermissions reemplaza los permisos individuales de un usuario
+// Un admin no puede revocarse admin:permissions a sí mismo (quedaría sin forma de deshacerlo)
+func (s *permissionService) UpdateUserPermissions(userID, adminID int64, req domain.PermissionOverridesRequest) (*domain.UserPermissionsDTO, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make([]dao.UserPermissionOverrideDAO, 0, len(req.Grant)+len(req.Revoke))
+	seen := map[string]bool{}
+	add := func(permission string, granted bool) error {
+		if !domain.IsKnownPermission(permission) {
+			return errors.New("permiso desconocido: " + permission)
+		}
+		if seen[permission] {
+			return errors.New("permiso repetido: " + permission)
+		}
+		seen[permission] = true
+		overrides = append(overrides, dao.UserPermissionOverrideDAO{
+			UserID:     userID,
+			Permission: permission,
+			Granted:    granted,
+			CreatedBy:  adminID,
+		})
+		return nil
+	}
+	for _, permission := range req.Grant {
+		if err := add(permission, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, permission := range req.Revoke {
+		if err := add(permission, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if userID == adminID && !effectiveFromOverrides(user.Role, overrides).Has(domain.PermissionAdminPermissions) {
+		return nil, errors.New("no puedes quitarte el permiso admin:permissions")
+	}
+
+	// Si el usuario pierde algún permiso, los JWT ya emitidos (que lo llevan en "perms") dejan de valer
+	// en la próxima request; un permiso otorgado se aplica desde el próximo login
+	previous, err := s.permissionRepo.FindOverrides(userID)
+	if err != nil {
+		return nil, err
+	}
+	var revokedAt *time.Time
+	if lostPermission(effectiveFromOverrides(user.Role, previous), effectiveFromOverrides(user.Role, overrides)) {
+		// Sin fracción de segundo: el claim iat del JWT tiene precisión de segundos
+		now := time.Now().Truncate(time.Second)
+		revokedAt = &now
+	}
+
+	if err := s.permissionRepo.ReplaceOverrides(userID, overrides, revokedAt); err != nil {
+		return nil, err
+	}
+
+	return toUserPermissionsDTO(user, overrides), nil
+}
+
+// EffectivePermissions calcula los permisos del usuario (rol + permisos individuales)
+func (s *permissionService) EffectivePermissions(user *dao.UserDAO) (domain.PermissionSet, error) {
+	overrides, err := s.permissionRepo.FindOverrides(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveFromOverrides(user.Role, overrides), nil
+}
+
+// lostPermission indica si after no tiene algún permiso de before
+func lostPermission(before, after domain.PermissionSet) bool {
+	for permission := range before {
+		if !after.Has(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *permissionService) findUser(userID int64) (*dao.UserDAO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// splitOverrides separa los permisos individuales en otorgados y revocados
+func splitOverrides(overrides []dao.UserPermissionOverrideDAO) (granted, revoked []string) {
+	granted, revoked = []string{}, []string{}
+	for _, override := range overrides {
+		if override.Granted {
+			granted = append(granted, override.Permission)
+		} else {
+			revoked = append(revoked, override.Permission)
+		}
+	}
+	return granted, revoked
+}
+
+func effectiveFromOverrides(role string, overrides []dao.UserPermissionOverrideDAO) domain.PermissionSet {
+	granted, revoked := splitOverrides(overrides)
+	return domain.EffectivePermissions(role, granted, revoked)
+}
+
+func toUserPermissionsDTO(user *dao.UserDAO, overrides []dao.UserPermissionOverrideDAO) *domain.UserPermissionsDTO {
+	granted, revoked := splitOverrides(overrides)
+	return &domain.UserPermissionsDTO{
+		UserID:    user.ID,
+		Role:      user.Role,
+		Granted:   granted,
+		Revoked:   revoked,
+		Effective: domain.EffectivePermissions(user.Role, granted, revoked).List(),
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// PreferencesService define el perfil de preferencias del usuario: filtros de búsqueda por defecto
+// (los usan el frontend y search-api para precargar la búsqueda) y notificaciones in-app por tipo
+type PreferencesService interface {
+	// GetPreferences devuelve las preferencias del usuario (las por defecto si nunca las guardó)
+	GetPreferences(userID int64) (*domain.UserPreferencesDTO, error)
+
+	// UpdatePreferences reemplaza las preferencias del usuario
+	UpdatePreferences(userID int64, req domain.UpdateUserPreferencesRequest) (*domain.UserPreferencesDTO, error)
+}
+
+type preferencesService struct {
+	preferencesRepo repository.PreferencesRepository
+	userRepo        repository.UserRepository
+}
+
+// NewPreferencesService crea una nueva instancia del servicio de preferencias
+func NewPreferencesService(preferencesRepo repository.PreferencesRepository, userRepo repository.UserRepository) PreferencesService {
+	return &preferencesService{
+		preferencesRepo: preferencesRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// GetPreferences devuelve las preferencias del usuario
+func (s *preferencesService) GetPreferences(userID int64) (*domain.UserPreferencesDTO, error) {
+	if err := s.checkUser(userID); err != nil {
+		return nil, err
+	}
+
+	preferences, err := s.preferencesRepo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.DefaultUserPreferences(userID), nil
+		}
+		return nil, err
+	}
+
+	return toUserPreferencesDTO(preferences), nil
+}
+
+// UpdatePreferences reemplaza las preferencias del usuario (PUT: lo omitido vuelve al valor por defecto)
+func (s *preferencesService) UpdatePreferences(userID int64, req domain.UpdateUserPreferencesRequest) (*domain.UserPreferencesDTO, error) {
+	if req.SearchFilters.MaxPrice != nil && *req.SearchFilters.MaxPrice <= 0 {
+		return nil, errors.New("max_price debe ser mayor a 0")
+	}
+	if req.Chat.PreferredLanguage != "" && !domain.LanguageCodePattern.MatchString(req.Chat.PreferredLanguage) {
+		return nil, errors.New("preferred_language inválido")
+	}
+	if req.Chat.AutoTranslate && req.Chat.PreferredLanguage == "" {
+		return nil, errors.New("auto_translate requiere preferred_language")
+	}
+
+	if err := s.checkUser(userID); err != nil {
+		return nil, err
+	}
+
+	preferences := &dao.UserPreferencesDAO{
+		UserID:               userID,
+		PetsAllowed:          req.SearchFilters.PetsAllowed,
+		SmokingAllowed:       req.SearchFilters.SmokingAllowed,
+		MusicAllowed:         req.SearchFilters.MusicAllowed,
+		MaxPrice:             req.SearchFilters.MaxPrice,
+		NotifyBookingUpdates: req.Notifications.BookingUpdates == nil || *req.Notifications.BookingUpdates,
+		NotifyChatMentions:   req.Notifications.ChatMentions == nil || *req.Notifications.ChatMentions,
+		PreferredLanguage:    req.Chat.PreferredLanguage,
+		ChatAutoTranslate:    req.Chat.AutoTranslate,
+	}
+	if err := s.preferencesRepo.Upsert(preferences); err != nil {
+		return nil, err
+	}
+
+	return toUserPreferencesDTO(preferences), nil
+}
+
+func (s *preferencesService) checkUser(userID int64) error {
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("usuario no encontrado")
+		}
+		return err
+	}
+	return nil
+}
+
+func toUserPreferencesDTO(preferences *dao.UserPreferencesDAO) *domain.UserPreferencesDTO {
+	updatedAt := preferences.UpdatedAt
+	return &domain.UserPreferencesDTO{
+		UserID: preferences.UserID,
+		SearchFilters: domain.SearchFiltersDTO{
+			PetsAllowed:    preferences.PetsAllowed,
+			SmokingAllowed: preferences.SmokingAllowed,
+			MusicAllowed:   preferences.MusicAllowed,
+			MaxPrice:       preferences.MaxPrice,
+		},
+		Notifications: domain.NotificationSettingsDTO{
+			BookingUpdates: preferences.NotifyBookingUpdates,
+			ChatMentions:   preferences.NotifyChatMentions,
+		},
+		Chat: domain.ChatSettingsDTO{
+			PreferredLanguage: preferences.PreferredLanguage,
+			AutoTranslate:     preferences.ChatAutoTranslate,
+		},
+		UpdatedAt: &updatedAt,
+	}
+}
@@ -1,33 +1,56 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log"
+	"time"
 	"users-api/internal/dao"
 	"users-api/internal/domain"
 	"users-api/internal/repository"
+
+	"gorm.io/gorm"
 )
 
+// RatingEventPublisher publica los eventos de calificaciones (implementado en messaging)
+type RatingEventPublisher interface {
+	PublishRatingUpdated(ctx context.Context, event domain.RatingUpdatedEvent) error
+	PublishUserRatingUpdated(ctx context.Context, event domain.UserRatingUpdatedEvent) error
+}
+
 // RatingService define las operaciones de calificaciones
 type RatingService interface {
-	CreateRating(req domain.CreateRatingRequest) error
+	CreateRating(ctx context.Context, req domain.CreateRatingRequest) error
 	GetUserRatings(userID int64, page, limit int) ([]domain.RatingDTO, int, error)
+
+	// GetDriverProfile obtiene nombre, foto, viajes y estadísticas de calificación como conductor en una sola llamada
+	GetDriverProfile(userID int64) (*domain.DriverProfileDTO, error)
+
+	// UpdateRating edita una calificación de su autor (dentro de RatingEditWindow, hasta MaxRatingEdits veces)
+	UpdateRating(ctx context.Context, ratingID, authorID int64, req domain.UpdateRatingRequest) (*domain.RatingDTO, error)
+
+	// GetRatingHistory obtiene la calificación con sus versiones anteriores (solo admin)
+	GetRatingHistory(ratingID int64) (*domain.RatingHistoryDTO, error)
 }
 
 type ratingService struct {
 	ratingRepo repository.RatingRepository
 	userRepo   repository.UserRepository
+	publisher  RatingEventPublisher
 }
 
 // NewRatingService crea una nueva instancia del servicio de calificaciones
-func NewRatingService(ratingRepo repository.RatingRepository, userRepo repository.UserRepository) RatingService {
+// publisher puede ser nil (sin RabbitMQ no se publican rating.updated ni user.rating_updated)
+func NewRatingService(ratingRepo repository.RatingRepository, userRepo repository.UserRepository, publisher RatingEventPublisher) RatingService {
 	return &ratingService{
 		ratingRepo: ratingRepo,
 		userRepo:   userRepo,
+		publisher:  publisher,
 	}
 }
 
 // CreateRating crea una nueva calificación y actualiza los promedios del usuario
-func (s *ratingService) CreateRating(req domain.CreateRatingRequest) error {
+func (s *ratingService) CreateRating(ctx context.Context, req domain.CreateRatingRequest) error {
 	// 1. Validar que no existe un rating duplicado
 	exists, err := s.ratingRepo.ExistsRating(req.RaterID, req.TripID, req.RatedUserID)
 	if err != nil {
@@ -51,20 +74,231 @@ func (s *ratingService) CreateRating(req domain.CreateRatingRequest) error {
 		return err
 	}
 
-	// 3. Calcular nuevos promedios del usuario calificado
-	avgDriver, avgPassenger, totalDriver, totalPassenger, err := s.ratingRepo.CalculateAverages(req.RatedUserID)
+	// 3. Recalcular y actualizar los promedios del usuario calificado
+	averages, err := s.updateAverages(req.RatedUserID)
 	if err != nil {
 		return err
 	}
 
-	// 4. Actualizar los promedios del usuario
-	if err := s.userRepo.UpdateRatings(req.RatedUserID, avgDriver, avgPassenger, totalDriver, totalPassenger); err != nil {
-		return err
-	}
+	// 4. Notificar los promedios nuevos (driver.rating en search-api)
+	s.publishUserRatingUpdated(ctx, ratingDAO, averages)
 
 	return nil
 }
 
+// UpdateRating edita la calificación y recalcula los promedios del usuario calificado
+//
+//   - Solo el autor (rater_id) puede editarla, dentro de las 72 horas desde su creación
+//     y como máximo 2 veces
+//   - La versión reemplazada se guarda en rating_edits
+//   - Publica rating.updated con los promedios nuevos para refrescar las copias desnormalizadas
+func (s *ratingService) UpdateRating(ctx context.Context, ratingID, authorID int64, req domain.UpdateRatingRequest) (*domain.RatingDTO, error) {
+	// 1. Validar autor y cuota de ediciones
+	rating, err := s.ratingRepo.FindByID(ratingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calificación no encontrada")
+		}
+		return nil, err
+	}
+	if rating.RaterID != authorID {
+		return nil, errors.New("solo el autor puede editar la calificación")
+	}
+	if time.Since(rating.CreatedAt) > domain.RatingEditWindow {
+		return nil, errors.New("el plazo para editar la calificación venció")
+	}
+	if rating.EditCount >= domain.MaxRatingEdits {
+		return nil, errors.New("se alcanzó el máximo de ediciones de la calificación")
+	}
+
+	// 2. Guardar la versión actual en el historial y aplicar la edición
+	previous := &dao.RatingEditDAO{
+		RatingID: rating.ID,
+		Version:  rating.EditCount,
+		Score:    rating.Score,
+		Comment:  rating.Comment,
+		EditedBy: authorID,
+	}
+	rating.Score = req.Score
+	if req.Comment != nil {
+		rating.Comment = *req.Comment
+	}
+
+	updated, err := s.ratingRepo.UpdateWithHistory(rating, previous)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		// Otra edición se aplicó entre la lectura y la actualización
+		return nil, errors.New("la calificación fue editada por otra solicitud")
+	}
+
+	// 3. Recalcular y actualizar los promedios del usuario calificado
+	averages, err := s.updateAverages(rating.RatedUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. Notificar a los servicios con copias desnormalizadas
+	s.publishRatingUpdated(ctx, rating, previous.Score, averages)
+	s.publishUserRatingUpdated(ctx, rating, averages)
+
+	ratingDTO := s.convertToDTO(rating)
+	return &ratingDTO, nil
+}
+
+// GetRatingHistory obtiene la calificación con sus versiones anteriores
+func (s *ratingService) GetRatingHistory(ratingID int64) (*domain.RatingHistoryDTO, error) {
+	rating, err := s.ratingRepo.FindByID(ratingID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("calificación no encontrada")
+		}
+		return nil, err
+	}
+
+	edits, err := s.ratingRepo.FindEdits(ratingID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &domain.RatingHistoryDTO{
+		Rating: s.convertToDTO(rating),
+		Edits:  make([]domain.RatingEditDTO, len(edits)),
+	}
+	for i, edit := range edits {
+		history.Edits[i] = domain.RatingEditDTO{
+			Version:    edit.Version,
+			Score:      edit.Score,
+			Comment:    edit.Comment,
+			EditedBy:   edit.EditedBy,
+			ReplacedAt: edit.CreatedAt,
+		}
+	}
+
+	return history, nil
+}
+
+// ratingAverages son los promedios recalculados de un usuario
+type ratingAverages struct {
+	avgDriver      float64
+	avgPassenger   float64
+	totalDriver    int
+	totalPassenger int
+}
+
+// updateAverages recalcula los promedios del usuario a partir de sus calificaciones y los guarda
+func (s *ratingService) updateAverages(userID int64) (*ratingAverages, error) {
+	avgDriver, avgPassenger, totalDriver, totalPassenger, err := s.ratingRepo.CalculateAverages(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateRatings(userID, avgDriver, avgPassenger, totalDriver, totalPassenger); err != nil {
+		return nil, err
+	}
+
+	return &ratingAverages{
+		avgDriver:      avgDriver,
+		avgPassenger:   avgPassenger,
+		totalDriver:    totalDriver,
+		totalPassenger: totalPassenger,
+	}, nil
+}
+
+// publishRatingUpdated publica rating.updated; un error se registra sin revertir la edición
+func (s *ratingService) publishRatingUpdated(ctx context.Context, rating *dao.RatingDAO, previousScore int, averages *ratingAverages) {
+	if s.publisher == nil {
+		return
+	}
+
+	event := domain.RatingUpdatedEvent{
+		EventID:             domain.NewRatingUpdatedEventID(rating.ID, rating.EditCount),
+		EventType:           domain.EventTypeRatingUpdated,
+		RatingID:            rating.ID,
+		RaterID:             rating.RaterID,
+		RatedUserID:         rating.RatedUserID,
+		TripID:              rating.TripID,
+		RoleRated:           rating.RoleRated,
+		PreviousScore:       previousScore,
+		Score:               rating.Score,
+		EditCount:           rating.EditCount,
+		AvgDriverRating:     averages.avgDriver,
+		AvgPassengerRating:  averages.avgPassenger,
+		TotalTripsDriver:    averages.totalDriver,
+		TotalTripsPassenger: averages.totalPassenger,
+		Timestamp:           time.Now(),
+	}
+	if err := s.publisher.PublishRatingUpdated(ctx, event); err != nil {
+		log.Printf("Error publicando rating.updated (rating_id=%d): %v", rating.ID, err)
+	}
+}
+
+// publishUserRatingUpdated publica user.rating_updated con los promedios del usuario calificado
+// Un error se registra sin revertir la calificación
+func (s *ratingService) publishUserRatingUpdated(ctx context.Context, rating *dao.RatingDAO, averages *ratingAverages) {
+	if s.publisher == nil {
+		return
+	}
+
+	event := domain.UserRatingUpdatedEvent{
+		EventID:             domain.NewUserRatingUpdatedEventID(rating.RatedUserID, rating.ID, rating.EditCount),
+		EventType:           domain.EventTypeUserRatingUpdated,
+		UserID:              rating.RatedUserID,
+		RatingID:            rating.ID,
+		RoleRated:           rating.RoleRated,
+		AvgDriverRating:     averages.avgDriver,
+		AvgPassengerRating:  averages.avgPassenger,
+		TotalTripsDriver:    averages.totalDriver,
+		TotalTripsPassenger: averages.totalPassenger,
+		Timestamp:           time.Now(),
+	}
+	if err := s.publisher.PublishUserRatingUpdated(ctx, event); err != nil {
+		log.Printf("Error publicando user.rating_updated (user_id=%d): %v", rating.RatedUserID, err)
+	}
+}
+
+// GetDriverProfile arma el perfil de conductor que desnormalizan otros servicios (ej: search-api)
+// El promedio y la cantidad se calculan sobre las calificaciones, no sobre los valores guardados en el usuario
+func (s *ratingService) GetDriverProfile(userID int64) (*domain.DriverProfileDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	avgDriver, _, totalDriver, _, err := s.ratingRepo.CalculateAverages(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := s.ratingRepo.FindRecentComments(userID, "conductor", domain.DriverProfileCommentsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]domain.DriverCommentDTO, len(ratings))
+	for i, rating := range ratings {
+		comments[i] = domain.DriverCommentDTO{
+			Score:     rating.Score,
+			Comment:   rating.Comment,
+			CreatedAt: rating.CreatedAt,
+		}
+	}
+
+	return &domain.DriverProfileDTO{
+		ID:             user.ID,
+		Name:           user.Name,
+		PhotoURL:       user.PhotoURL,
+		Rating:         avgDriver,
+		RatingCount:    totalDriver,
+		TotalTrips:     user.TotalTripsDriver,
+		RecentComments: comments,
+	}, nil
+}
+
 // GetUserRatings obtiene las calificaciones de un usuario con paginación
 func (s *ratingService) GetUserRatings(userID int64, page, limit int) ([]domain.RatingDTO, int, error) {
 	// Validar parámetros de paginación
@@ -107,6 +341,8 @@ func (s *ratingService) convertToDTO(ratingDAO *dao.RatingDAO) domain.RatingDTO
 		RoleRated:   ratingDAO.RoleRated,
 		Score:       ratingDAO.Score,
 		Comment:     ratingDAO.Comment,
+		EditCount:   ratingDAO.EditCount,
+		EditedAt:    ratingDAO.EditedAt,
 		CreatedAt:   ratingDAO.CreatedAt,
 	}
 }
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"strings"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+)
+
+// regionalSettings es la configuración regional de un usuario: país, moneda y unidad de distancia
+type regionalSettings struct {
+	Country      string
+	Currency     string
+	DistanceUnit string
+}
+
+// registrationRegionalSettings resuelve la configuración regional del registro: el país enviado o
+// el inferido del locale, y la moneda y unidad enviadas o las del país
+func registrationRegionalSettings(req domain.CreateUserRequest) (regionalSettings, error) {
+	country := domain.CountryFromLocale(req.Locale)
+	if req.Country != "" {
+		country = strings.ToUpper(strings.TrimSpace(req.Country))
+	}
+	defaults, ok := domain.DefaultsForCountry(country)
+	if !ok {
+		return regionalSettings{}, domain.ErrUnsupportedCountry
+	}
+
+	settings := regionalSettings{Country: country, Currency: defaults.Currency, DistanceUnit: defaults.DistanceUnit}
+	if req.Currency != "" {
+		settings.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+		if !domain.IsSupportedCurrency(settings.Currency) {
+			return regionalSettings{}, domain.ErrUnsupportedCurrency
+		}
+	}
+	if req.DistanceUnit != "" {
+		settings.DistanceUnit = strings.ToLower(strings.TrimSpace(req.DistanceUnit))
+		if !domain.IsDistanceUnit(settings.DistanceUnit) {
+			return regionalSettings{}, domain.ErrUnsupportedDistanceUnit
+		}
+	}
+	return settings, nil
+}
+
+// applyRegionalSettings aplica los cambios de configuración regional de una actualización del perfil
+// Al cambiar el país, la moneda y la unidad que no se envían pasan a las del país nuevo
+// Valida todo antes de modificar el usuario
+func applyRegionalSettings(user *dao.UserDAO, country, currency, distanceUnit *string) error {
+	settings := regionalSettings{Country: user.Country, Currency: user.Currency, DistanceUnit: user.DistanceUnit}
+
+	if country != nil {
+		newCountry := strings.ToUpper(strings.TrimSpace(*country))
+		defaults, ok := domain.DefaultsForCountry(newCountry)
+		if !ok {
+			return domain.ErrUnsupportedCountry
+		}
+		if newCountry != settings.Country {
+			settings = regionalSettings{Country: newCountry, Currency: defaults.Currency, DistanceUnit: defaults.DistanceUnit}
+		}
+	}
+	if currency != nil {
+		settings.Currency = strings.ToUpper(strings.TrimSpace(*currency))
+		if !domain.IsSupportedCurrency(settings.Currency) {
+			return domain.ErrUnsupportedCurrency
+		}
+	}
+	if distanceUnit != nil {
+		settings.DistanceUnit = strings.ToLower(strings.TrimSpace(*distanceUnit))
+		if !domain.IsDistanceUnit(settings.DistanceUnit) {
+			return domain.ErrUnsupportedDistanceUnit
+		}
+	}
+
+	user.Country = settings.Country
+	user.Currency = settings.Currency
+	user.DistanceUnit = settings.DistanceUnit
+	return nil
+}
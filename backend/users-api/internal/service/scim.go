@@ -0,0 +1,737 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// provisionedPasswordSetupTTL es la validez del link para que un usuario provisionado elija su contraseña
+const provisionedPasswordSetupTTL = 72 * time.Hour
+
+// provisionedBirthdate se guarda en las cuentas provisionadas: SCIM no informa la fecha de nacimiento
+var provisionedBirthdate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Atributos SCIM filtrables (en minúsculas) y su columna
+var (
+	scimUserFilterColumns = map[string]string{
+		"username":        "email",
+		"emails.value":    "email",
+		"externalid":      "external_id",
+		"active":          "active",
+		"name.givenname":  "name",
+		"name.familyname": "lastname",
+	}
+	scimGroupFilterColumns = map[string]string{
+		"displayname": "display_name",
+		"externalid":  "external_id",
+	}
+)
+
+// SCIMService define la provisión de usuarios y organizaciones de partners corporativos (subconjunto de SCIM 2.0)
+// Cada partner solo ve y modifica los usuarios y organizaciones que provisionó
+type SCIMService interface {
+	ListUsers(partnerID int64, filter string, startIndex, count int) (*domain.SCIMListResponse, error)
+	GetUser(partnerID, userID int64) (*domain.SCIMUser, error)
+	CreateUser(partnerID int64, req domain.SCIMUser) (*domain.SCIMUser, error)
+	PatchUser(partnerID, userID int64, req domain.SCIMPatchRequest) (*domain.SCIMUser, error)
+	DeactivateUser(partnerID, userID int64) error
+
+	ListGroups(partnerID int64, filter string, startIndex, count int) (*domain.SCIMListResponse, error)
+	GetGroup(partnerID, groupID int64) (*domain.SCIMGroup, error)
+	CreateGroup(partnerID int64, req domain.SCIMGroup) (*domain.SCIMGroup, error)
+	PatchGroup(partnerID, groupID int64, req domain.SCIMPatchRequest) (*domain.SCIMGroup, error)
+	DeleteGroup(partnerID, groupID int64) error
+}
+
+type scimService struct {
+	userRepo         repository.UserRepository
+	provisioningRepo repository.ProvisioningRepository
+	resetTokenRepo   repository.PasswordResetTokenRepository
+	emailService     EmailService
+	webhooks         PartnerWebhookService
+}
+
+// NewSCIMService crea una nueva instancia del servicio de provisión SCIM
+// webhooks autoriza al partner sobre los usuarios que provisiona y le notifica sus cambios
+func NewSCIMService(userRepo repository.UserRepository, provisioningRepo repository.ProvisioningRepository, resetTokenRepo repository.PasswordResetTokenRepository, emailService EmailService, webhooks PartnerWebhookService) SCIMService {
+	return &scimService{
+		userRepo:         userRepo,
+		provisioningRepo: provisioningRepo,
+		resetTokenRepo:   resetTokenRepo,
+		emailService:     emailService,
+		webhooks:         webhooks,
+	}
+}
+
+// ==================== USUARIOS ====================
+
+// ListUsers lista los usuarios del partner con filtro y paginación SCIM
+func (s *scimService) ListUsers(partnerID int64, filter string, startIndex, count int) (*domain.SCIMListResponse, error) {
+	query, conditions, err := parseSCIMListQuery(filter, startIndex, count, scimUserFilterColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.provisioningRepo.FindProvisionedUsers(partnerID, conditions, query.StartIndex-1, query.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*domain.SCIMUser, len(users))
+	for i, user := range users {
+		resources[i] = toSCIMUser(user, nil)
+	}
+	return newSCIMListResponse(total, query.StartIndex, len(resources), resources), nil
+}
+
+// GetUser retorna un usuario del partner con sus organizaciones
+func (s *scimService) GetUser(partnerID, userID int64) (*domain.SCIMUser, error) {
+	user, err := s.findUser(partnerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toSCIMUserWithGroups(partnerID, user)
+}
+
+// CreateUser provisiona un empleado del partner
+//
+// La cuenta se crea con el email verificado (lo valida el partner) y una contraseña aleatoria;
+// el usuario recibe un email para elegir su contraseña (válido provisionedPasswordSetupTTL)
+func (s *scimService) CreateUser(partnerID int64, req domain.SCIMUser) (*domain.SCIMUser, error) {
+	email := strings.TrimSpace(req.UserName)
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		return nil, scimInvalidValue("userName debe ser un email válido")
+	}
+	if strings.TrimSpace(req.Name.GivenName) == "" {
+		return nil, scimInvalidValue("name.givenName es requerido")
+	}
+
+	// El email se marca verificado sin confirmación: solo se aceptan emails de los dominios verificados del partner
+	partner, err := s.provisioningRepo.FindPartnerByID(partnerID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.EmailInDomains(email, partner.Domains()) {
+		return nil, scimInvalidValue("userName debe pertenecer a un dominio verificado del partner")
+	}
+
+	// Verificar si el email ya existe (de un registro normal o de otro partner)
+	_, err = s.userRepo.FindByEmail(email)
+	if err == nil {
+		return nil, errors.New("el email ya está registrado")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	randomPassword, err := s.emailService.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), 10)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &dao.UserDAO{
+		Email:         email,
+		EmailVerified: true,
+		Name:          strings.TrimSpace(req.Name.GivenName),
+		Lastname:      strings.TrimSpace(req.Name.FamilyName),
+		PasswordHash:  string(hashedPassword),
+		Role:          "user",
+		Phone:         primaryPhone(req.PhoneNumbers),
+		Sex:           "otro",
+		Birthdate:     provisionedBirthdate,
+		Active:        true,
+		PartnerID:     &partnerID,
+		ExternalID:    optionalString(req.ExternalID),
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	// active tiene default true en la base: una cuenta creada inactiva se actualiza después
+	if req.Active != nil && !*req.Active {
+		if err := s.provisioningRepo.UpdateUserActive(user.ID, false); err != nil {
+			return nil, err
+		}
+		user.Active = false
+	}
+
+	if user.Active {
+		s.sendPasswordSetupEmail(user)
+	}
+
+	s.webhooks.UserProvisioned(partnerID, user)
+
+	return toSCIMUser(user, nil), nil
+}
+
+// PatchUser aplica un PATCH SCIM: replace/add de active, externalId, name.givenName y name.familyName
+// (con path o como objeto sin path, como envían Azure AD y Okta)
+func (s *scimService) PatchUser(partnerID, userID int64, req domain.SCIMPatchRequest) (*domain.SCIMUser, error) {
+	user, err := s.findUser(partnerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wasActive := user.Active
+	for _, op := range req.Operations {
+		operation := strings.ToLower(op.Op)
+		if operation != "replace" && operation != "add" {
+			return nil, scimUnsupportedOperation(op.Op + " en usuarios")
+		}
+
+		values := map[string]json.RawMessage{}
+		if op.Path == "" {
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return nil, scimInvalidValue("value debe ser un objeto cuando no hay path")
+			}
+			// name puede venir como objeto anidado
+			if name, ok := values["name"]; ok {
+				var nested map[string]json.RawMessage
+				if err := json.Unmarshal(name, &nested); err != nil {
+					return nil, scimInvalidValue("name debe ser un objeto")
+				}
+				for key, value := range nested {
+					values["name."+key] = value
+				}
+				delete(values, "name")
+			}
+		} else {
+			values[op.Path] = op.Value
+		}
+
+		for path, value := range values {
+			if err := applyUserPatch(user, path, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	// Reactivación: el usuario puede no haber elegido nunca su contraseña
+	if !wasActive && user.Active {
+		s.sendPasswordSetupEmail(user)
+	}
+
+	s.webhooks.UserUpdated(user)
+
+	return s.toSCIMUserWithGroups(partnerID, user)
+}
+
+// DeactivateUser desactiva la cuenta (DELETE /Users/:id): no se borra para conservar viajes y calificaciones
+func (s *scimService) DeactivateUser(partnerID, userID int64) error {
+	user, err := s.findUser(partnerID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.provisioningRepo.UpdateUserActive(userID, false); err != nil {
+		return err
+	}
+
+	if user.Active {
+		user.Active = false
+		s.webhooks.UserUpdated(user)
+	}
+	return nil
+}
+
+func (s *scimService) findUser(partnerID, userID int64) (*dao.UserDAO, error) {
+	user, err := s.provisioningRepo.FindProvisionedUser(partnerID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *scimService) toSCIMUserWithGroups(partnerID int64, user *dao.UserDAO) (*domain.SCIMUser, error) {
+	organizations, err := s.provisioningRepo.FindUserOrganizations(partnerID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return toSCIMUser(user, organizations), nil
+}
+
+// sendPasswordSetupEmail envía el link para elegir contraseña (reutiliza el flujo de reset)
+func (s *scimService) sendPasswordSetupEmail(user *dao.UserDAO) {
+	token, err := issuePasswordResetToken(s.resetTokenRepo, s.emailService, user.ID, provisionedPasswordSetupTTL, "")
+	if err != nil {
+		return
+	}
+
+	go func() {
+		if err := s.emailService.SendPasswordResetEmail(user.Email, token); err != nil {
+			// El error ya está logueado en emailService; el usuario puede usar /forgot-password
+			return
+		}
+	}()
+}
+
+// applyUserPatch aplica un atributo de un PATCH al usuario
+func applyUserPatch(user *dao.UserDAO, path string, value json.RawMessage) error {
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := parsePatchBool(value)
+		if err != nil {
+			return scimInvalidValue("active debe ser booleano")
+		}
+		user.Active = active
+	case "externalid":
+		var externalID string
+		if err := json.Unmarshal(value, &externalID); err != nil {
+			return scimInvalidValue("externalId debe ser un string")
+		}
+		user.ExternalID = optionalString(externalID)
+	case "name.givenname":
+		var givenName string
+		if err := json.Unmarshal(value, &givenName); err != nil || strings.TrimSpace(givenName) == "" {
+			return scimInvalidValue("name.givenName debe ser un string no vacío")
+		}
+		user.Name = strings.TrimSpace(givenName)
+	case "name.familyname":
+		var familyName string
+		if err := json.Unmarshal(value, &familyName); err != nil {
+			return scimInvalidValue("name.familyName debe ser un string")
+		}
+		user.Lastname = strings.TrimSpace(familyName)
+	default:
+		return scimUnsupportedOperation("path " + path)
+	}
+	return nil
+}
+
+// parsePatchBool acepta true/false y también "True"/"False" como string (Azure AD)
+func parsePatchBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
+
+// ==================== GRUPOS (ORGANIZACIONES) ====================
+
+// ListGroups lista las organizaciones del partner con filtro y paginación SCIM
+func (s *scimService) ListGroups(partnerID int64, filter string, startIndex, count int) (*domain.SCIMListResponse, error) {
+	query, conditions, err := parseSCIMListQuery(filter, startIndex, count, scimGroupFilterColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations, total, err := s.provisioningRepo.FindOrganizations(partnerID, conditions, query.StartIndex-1, query.Count)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(organizations))
+	for i, organization := range organizations {
+		ids[i] = organization.ID
+	}
+	members, err := s.provisioningRepo.FindMembers(ids)
+	if err != nil {
+		return nil, err
+	}
+	membersByOrganization := map[int64][]dao.OrganizationMemberDAO{}
+	for _, member := range members {
+		membersByOrganization[member.OrganizationID] = append(membersByOrganization[member.OrganizationID], member)
+	}
+
+	resources := make([]*domain.SCIMGroup, len(organizations))
+	for i := range organizations {
+		resources[i] = toSCIMGroup(&organizations[i], membersByOrganization[organizations[i].ID], nil)
+	}
+	return newSCIMListResponse(total, query.StartIndex, len(resources), resources), nil
+}
+
+// GetGroup retorna una organización con sus miembros
+func (s *scimService) GetGroup(partnerID, groupID int64) (*domain.SCIMGroup, error) {
+	organization, err := s.findGroup(partnerID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toSCIMGroupWithMembers(partnerID, organization)
+}
+
+// CreateGroup crea una organización; los miembros deben ser usuarios provisionados por el partner
+func (s *scimService) CreateGroup(partnerID int64, req domain.SCIMGroup) (*domain.SCIMGroup, error) {
+	displayName := strings.TrimSpace(req.DisplayName)
+	if displayName == "" {
+		return nil, scimInvalidValue("displayName es requerido")
+	}
+	if err := s.ensureGroupNameAvailable(partnerID, displayName, 0); err != nil {
+		return nil, err
+	}
+
+	memberIDs, err := s.resolveMembers(partnerID, req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	organization := &dao.OrganizationDAO{
+		PartnerID:   partnerID,
+		DisplayName: displayName,
+		ExternalID:  optionalString(req.ExternalID),
+	}
+	if err := s.provisioningRepo.CreateOrganization(organization, memberIDs); err != nil {
+		return nil, err
+	}
+
+	return s.toSCIMGroupWithMembers(partnerID, organization)
+}
+
+// PatchGroup aplica un PATCH SCIM a una organización:
+//   - add / remove / replace de members (remove acepta path members[value eq "id"])
+//   - replace de displayName y externalId
+func (s *scimService) PatchGroup(partnerID, groupID int64, req domain.SCIMPatchRequest) (*domain.SCIMGroup, error) {
+	organization, err := s.findGroup(partnerID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := false
+	for _, op := range req.Operations {
+		operation := strings.ToLower(op.Op)
+		path := strings.TrimSpace(op.Path)
+
+		switch {
+		case strings.EqualFold(path, "members"):
+			var refs []domain.SCIMMemberRef
+			if len(op.Value) > 0 {
+				if err := json.Unmarshal(op.Value, &refs); err != nil {
+					return nil, scimInvalidValue("members debe ser una lista de {\"value\": id}")
+				}
+			}
+			if err := s.patchMembers(partnerID, organization.ID, operation, refs); err != nil {
+				return nil, err
+			}
+
+		case strings.HasPrefix(strings.ToLower(path), "members[") && operation == "remove":
+			conditions, err := domain.ParseSCIMFilter(strings.TrimSuffix(path[len("members["):], "]"), map[string]bool{"value": true})
+			if err != nil || len(conditions) != 1 || conditions[0].Operator != "eq" {
+				return nil, domain.ErrInvalidSCIMFilter
+			}
+			if err := s.patchMembers(partnerID, organization.ID, operation, []domain.SCIMMemberRef{{Value: conditions[0].Value}}); err != nil {
+				return nil, err
+			}
+
+		case operation == "replace" || operation == "add":
+			values := map[string]json.RawMessage{}
+			if path == "" {
+				if err := json.Unmarshal(op.Value, &values); err != nil {
+					return nil, scimInvalidValue("value debe ser un objeto cuando no hay path")
+				}
+			} else {
+				values[path] = op.Value
+			}
+			for key, value := range values {
+				var text string
+				if err := json.Unmarshal(value, &text); err != nil {
+					return nil, scimInvalidValue(key + " debe ser un string")
+				}
+				switch strings.ToLower(key) {
+				case "displayname":
+					if strings.TrimSpace(text) == "" {
+						return nil, scimInvalidValue("displayName no puede estar vacío")
+					}
+					organization.DisplayName = strings.TrimSpace(text)
+					renamed = true
+				case "externalid":
+					organization.ExternalID = optionalString(text)
+				default:
+					return nil, scimUnsupportedOperation("path " + key)
+				}
+			}
+
+		default:
+			return nil, scimUnsupportedOperation(op.Op + " " + path)
+		}
+	}
+
+	if renamed {
+		if err := s.ensureGroupNameAvailable(partnerID, organization.DisplayName, organization.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.provisioningRepo.UpdateOrganization(organization); err != nil {
+		return nil, err
+	}
+
+	return s.toSCIMGroupWithMembers(partnerID, organization)
+}
+
+// DeleteGroup borra la organización y sus membresías; los usuarios no se modifican
+func (s *scimService) DeleteGroup(partnerID, groupID int64) error {
+	if _, err := s.findGroup(partnerID, groupID); err != nil {
+		return err
+	}
+	return s.provisioningRepo.DeleteOrganization(groupID)
+}
+
+func (s *scimService) patchMembers(partnerID, organizationID int64, operation string, refs []domain.SCIMMemberRef) error {
+	memberIDs, err := s.resolveMembers(partnerID, refs)
+	if err != nil {
+		return err
+	}
+
+	switch operation {
+	case "add":
+		return s.provisioningRepo.AddMembers(organizationID, memberIDs)
+	case "remove":
+		// remove de members sin value elimina todos los miembros (RFC 7644 3.5.2.2)
+		if len(refs) == 0 {
+			return s.provisioningRepo.ReplaceMembers(organizationID, nil)
+		}
+		return s.provisioningRepo.RemoveMembers(organizationID, memberIDs)
+	case "replace":
+		return s.provisioningRepo.ReplaceMembers(organizationID, memberIDs)
+	default:
+		return scimUnsupportedOperation(operation + " members")
+	}
+}
+
+// resolveMembers convierte las referencias SCIM en IDs, validando que sean usuarios del partner
+func (s *scimService) resolveMembers(partnerID int64, refs []domain.SCIMMemberRef) ([]int64, error) {
+	seen := map[int64]bool{}
+	ids := make([]int64, 0, len(refs))
+	for _, ref := range refs {
+		id, err := strconv.ParseInt(ref.Value, 10, 64)
+		if err != nil {
+			return nil, scimInvalidValue("miembro inválido: " + ref.Value)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	users, err := s.provisioningRepo.FindProvisionedUsersByIDs(partnerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != len(ids) {
+		return nil, scimInvalidValue("los miembros deben ser usuarios provisionados por el partner")
+	}
+	return ids, nil
+}
+
+func (s *scimService) ensureGroupNameAvailable(partnerID int64, displayName string, excludeID int64) error {
+	existing, _, err := s.provisioningRepo.FindOrganizations(partnerID, []repository.FilterCondition{
+		{Column: "display_name", Operator: "eq", Value: displayName},
+	}, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && existing[0].ID != excludeID {
+		return errors.New("el grupo ya existe")
+	}
+	return nil
+}
+
+func (s *scimService) findGroup(partnerID, groupID int64) (*dao.OrganizationDAO, error) {
+	organization, err := s.provisioningRepo.FindOrganization(partnerID, groupID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("grupo no encontrado")
+		}
+		return nil, err
+	}
+	return organization, nil
+}
+
+// toSCIMGroupWithMembers arma el grupo con los nombres de sus miembros (display)
+func (s *scimService) toSCIMGroupWithMembers(partnerID int64, organization *dao.OrganizationDAO) (*domain.SCIMGroup, error) {
+	members, err := s.provisioningRepo.FindMembers([]int64{organization.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(members))
+	for i, member := range members {
+		ids[i] = member.UserID
+	}
+	users, err := s.provisioningRepo.FindProvisionedUsersByIDs(partnerID, ids)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(users))
+	for _, user := range users {
+		names[user.ID] = strings.TrimSpace(user.Name + " " + user.Lastname)
+	}
+
+	return toSCIMGroup(organization, members, names), nil
+}
+
+// ==================== CONVERSIONES Y ERRORES ====================
+
+// parseSCIMListQuery valida el filtro y normaliza la paginación (startIndex >= 1, 0 <= count <= SCIMMaxCount)
+func parseSCIMListQuery(filter string, startIndex, count int, columns map[string]string) (*domain.SCIMListQuery, []repository.FilterCondition, error) {
+	allowed := make(map[string]bool, len(columns))
+	for attribute := range columns {
+		allowed[attribute] = true
+	}
+
+	parsed, err := domain.ParseSCIMFilter(filter, allowed)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conditions := make([]repository.FilterCondition, 0, len(parsed))
+	for _, condition := range parsed {
+		var value interface{} = condition.Value
+		if condition.Attribute == "active" {
+			active, err := strconv.ParseBool(condition.Value)
+			if err != nil || condition.Operator != "eq" {
+				return nil, nil, domain.ErrInvalidSCIMFilter
+			}
+			value = active
+		}
+		conditions = append(conditions, repository.FilterCondition{
+			Column:   columns[condition.Attribute],
+			Operator: condition.Operator,
+			Value:    value,
+		})
+	}
+
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > domain.SCIMMaxCount {
+		count = domain.SCIMMaxCount
+	}
+
+	return &domain.SCIMListQuery{Filter: parsed, StartIndex: startIndex, Count: count}, conditions, nil
+}
+
+func newSCIMListResponse(total int64, startIndex, itemsPerPage int, resources interface{}) *domain.SCIMListResponse {
+	return &domain.SCIMListResponse{
+		Schemas:      []string{domain.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: itemsPerPage,
+		Resources:    resources,
+	}
+}
+
+func toSCIMUser(user *dao.UserDAO, organizations []dao.OrganizationDAO) *domain.SCIMUser {
+	id := strconv.FormatInt(user.ID, 10)
+	active := user.Active
+
+	scimUser := &domain.SCIMUser{
+		Schemas:  []string{domain.SCIMSchemaUser},
+		ID:       id,
+		UserName: user.Email,
+		Name:     domain.SCIMName{GivenName: user.Name, FamilyName: user.Lastname},
+		Emails:   []domain.SCIMMultiValued{{Value: user.Email, Type: "work", Primary: true}},
+		Active:   &active,
+		Meta: &domain.SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     "/scim/v2/Users/" + id,
+		},
+	}
+	if user.ExternalID != nil {
+		scimUser.ExternalID = *user.ExternalID
+	}
+	if user.Phone != "" {
+		scimUser.PhoneNumbers = []domain.SCIMMultiValued{{Value: user.Phone, Type: "work", Primary: true}}
+	}
+	for _, organization := range organizations {
+		scimUser.Groups = append(scimUser.Groups, domain.SCIMMemberRef{
+			Value:   strconv.FormatInt(organization.ID, 10),
+			Display: organization.DisplayName,
+		})
+	}
+	return scimUser
+}
+
+// toSCIMGroup convierte una organización; names (opcional) completa el display de cada miembro
+func toSCIMGroup(organization *dao.OrganizationDAO, members []dao.OrganizationMemberDAO, names map[int64]string) *domain.SCIMGroup {
+	id := strconv.FormatInt(organization.ID, 10)
+
+	group := &domain.SCIMGroup{
+		Schemas:     []string{domain.SCIMSchemaGroup},
+		ID:          id,
+		DisplayName: organization.DisplayName,
+		Members:     make([]domain.SCIMMemberRef, len(members)),
+		Meta: &domain.SCIMMeta{
+			ResourceType: "Group",
+			Created:      organization.CreatedAt,
+			LastModified: organization.UpdatedAt,
+			Location:     "/scim/v2/Groups/" + id,
+		},
+	}
+	if organization.ExternalID != nil {
+		group.ExternalID = *organization.ExternalID
+	}
+	for i, member := range members {
+		group.Members[i] = domain.SCIMMemberRef{Value: strconv.FormatInt(member.UserID, 10), Display: names[member.UserID]}
+	}
+	return group
+}
+
+// primaryPhone retorna el teléfono principal (o el primero), recortado al largo de la columna
+func primaryPhone(phones []domain.SCIMMultiValued) string {
+	if len(phones) == 0 {
+		return ""
+	}
+	phone := phones[0].Value
+	for _, p := range phones {
+		if p.Primary {
+			phone = p.Value
+			break
+		}
+	}
+	if len(phone) > 20 {
+		phone = phone[:20]
+	}
+	return phone
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// Los errores SCIM se distinguen por prefijo en el controlador (scimType invalidValue / invalidPath)
+const (
+	scimInvalidValuePrefix       = "valor inválido: "
+	scimUnsupportedOperationText = "operación no soportada: "
+)
+
+func scimInvalidValue(detail string) error {
+	return errors.New(scimInvalidValuePrefix + detail)
+}
+
+func scimUnsupportedOperation(detail string) error {
+	return fmt.Errorf("%s%s", scimUnsupportedOperationText, detail)
+}
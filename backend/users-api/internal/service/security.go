@@ -0,0 +1,105 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// securityScanBatchSize es la cantidad de usuarios leídos por consulta al calcular la distribución
+const securityScanBatchSize = 500
+
+// SecurityService define las operaciones del score de seguridad de las cuentas
+type SecurityService interface {
+	GetSecurityScore(userID int64) (*domain.SecurityScoreDTO, error)
+	GetScoreDistribution() (*domain.SecurityScoreDistribution, error)
+}
+
+type securityService struct {
+	userRepo repository.UserRepository
+}
+
+// NewSecurityService crea una nueva instancia del servicio de seguridad
+func NewSecurityService(userRepo repository.UserRepository) SecurityService {
+	return &securityService{userRepo: userRepo}
+}
+
+// GetSecurityScore calcula el score de seguridad del usuario con sus recomendaciones
+func (s *securityService) GetSecurityScore(userID int64) (*domain.SecurityScoreDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	score := domain.ComputeSecurityScore(securityFactors(user), time.Now())
+	return &score, nil
+}
+
+// GetScoreDistribution recorre todas las cuentas y resume sus scores
+// (histograma, niveles y adopción de cada factor)
+func (s *securityService) GetScoreDistribution() (*domain.SecurityScoreDistribution, error) {
+	dist := &domain.SecurityScoreDistribution{
+		Buckets:  map[string]int64{"0-19": 0, "20-39": 0, "40-59": 0, "60-79": 0, "80-100": 0},
+		Levels:   map[string]int64{domain.SecurityLevelLow: 0, domain.SecurityLevelMedium: 0, domain.SecurityLevelHigh: 0},
+		Adoption: map[string]int64{},
+	}
+
+	now := time.Now()
+	var totalScore int64
+	var lastID int64
+
+	for {
+		users, err := s.userRepo.FindSecurityFactorsBatch(lastID, securityScanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			score := domain.ComputeSecurityScore(securityFactors(user), now)
+
+			dist.TotalUsers++
+			totalScore += int64(score.Score)
+			dist.Buckets[domain.SecurityScoreBucket(score.Score)]++
+			dist.Levels[score.Level]++
+			for _, factor := range score.Factors {
+				if factor.Enabled {
+					dist.Adoption[factor.Factor]++
+				} else if _, ok := dist.Adoption[factor.Factor]; !ok {
+					dist.Adoption[factor.Factor] = 0
+				}
+			}
+		}
+
+		if len(users) < securityScanBatchSize {
+			break
+		}
+		lastID = users[len(users)-1].ID
+	}
+
+	if dist.TotalUsers > 0 {
+		dist.AverageScore = float64(totalScore) / float64(dist.TotalUsers)
+	}
+
+	return dist, nil
+}
+
+// securityFactors extrae los factores de seguridad de un usuario
+// Si la contraseña nunca se cambió se toma la fecha de registro
+func securityFactors(user *dao.UserDAO) domain.SecurityFactors {
+	passwordChangedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		passwordChangedAt = *user.PasswordChangedAt
+	}
+
+	return domain.SecurityFactors{
+		PasswordChangedAt: passwordChangedAt,
+		EmailVerified:     user.EmailVerified,
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"time"
 	"users-api/internal/dao"
 	"users-api/internal/domain"
 	"users-api/internal/repository"
@@ -11,30 +12,40 @@ import (
 
 // UserService define las operaciones de gestión de usuarios
 type UserService interface {
-	GetAllUsers(page, limit int, roleFilter, search string) ([]*domain.UserDTO, int64, error)
+	GetAllUsers(page, limit int, roleFilter, search string, bannedFilter *bool) ([]*domain.UserDTO, int64, error)
 	GetUserByID(id int64) (*domain.UserDTO, error)
+	GetUsersByIDs(ids []int64) ([]*domain.UserDTO, error)
+	GetPublicProfile(id int64) (*domain.PublicProfileDTO, error)
+	GetSessionStatus(id int64) (*domain.SessionStatusDTO, error)
 	GetUserProfile(id int64) (*domain.UserDTO, error)
 	UpdateUser(id int64, req domain.UpdateUserRequest) (*domain.UserDTO, error)
 	DeleteUser(id int64) error
 	ForceReauthentication(id int64) error
+	BanUser(adminID, id int64, reason string) (*domain.UserDTO, error)
+	UnbanUser(id int64) (*domain.UserDTO, error)
 }
 
 type userService struct {
 	userRepo     repository.UserRepository
+	tokenRepo    repository.VerificationTokenRepository
 	emailService EmailService
+	webhooks     PartnerWebhookService
 }
 
 // NewUserService crea una nueva instancia del servicio de usuarios
-func NewUserService(userRepo repository.UserRepository, emailService EmailService) UserService {
+// webhooks notifica a los partners autorizados los cambios y bajas de cuentas
+func NewUserService(userRepo repository.UserRepository, tokenRepo repository.VerificationTokenRepository, emailService EmailService, webhooks PartnerWebhookService) UserService {
 	return &userService{
 		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
 		emailService: emailService,
+		webhooks:     webhooks,
 	}
 }
 
 // GetAllUsers obtiene todos los usuarios con paginación y filtros (solo admin)
-func (s *userService) GetAllUsers(page, limit int, roleFilter, search string) ([]*domain.UserDTO, int64, error) {
-	users, total, err := s.userRepo.FindAllWithPagination(page, limit, roleFilter, search)
+func (s *userService) GetAllUsers(page, limit int, roleFilter, search string, bannedFilter *bool) ([]*domain.UserDTO, int64, error) {
+	users, total, err := s.userRepo.FindAllWithPagination(page, limit, roleFilter, search, bannedFilter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -61,6 +72,60 @@ func (s *userService) GetUserByID(id int64) (*domain.UserDTO, error) {
 	return s.convertToDTO(user), nil
 }
 
+// GetUsersByIDs obtiene varios usuarios en una sola consulta (los que no existen se omiten)
+func (s *userService) GetUsersByIDs(ids []int64) ([]*domain.UserDTO, error) {
+	users, err := s.userRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.UserDTO, 0, len(users))
+	for _, user := range users {
+		result = append(result, s.convertToDTO(user))
+	}
+	return result, nil
+}
+
+// GetPublicProfile obtiene el perfil público (whitelist de campos) de un usuario
+// Se construye directamente desde el DAO para no depender de los campos de UserDTO
+func (s *userService) GetPublicProfile(id int64) (*domain.PublicProfileDTO, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	return &domain.PublicProfileDTO{
+		ID:                 user.ID,
+		FirstName:          user.Name,
+		PhotoURL:           user.PhotoURL,
+		AvgDriverRating:    user.AvgDriverRating,
+		AvgPassengerRating: user.AvgPassengerRating,
+		MemberSince:        domain.FormatMemberSince(user.CreatedAt),
+	}, nil
+}
+
+// GetSessionStatus obtiene lo necesario para que otros servicios validen un JWT contra la cuenta
+// (revocación de sesiones, desactivación y suspensión)
+func (s *userService) GetSessionStatus(id int64) (*domain.SessionStatusDTO, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	return &domain.SessionStatusDTO{
+		UserID:            user.ID,
+		Active:            user.Active,
+		Banned:            user.IsBanned(),
+		SessionsRevokedAt: user.SessionsRevokedAt,
+	}, nil
+}
+
 // GetUserProfile es un alias de GetUserByID usado para /users/me
 func (s *userService) GetUserProfile(id int64) (*domain.UserDTO, error) {
 	return s.GetUserByID(id)
@@ -96,19 +161,24 @@ func (s *userService) UpdateUser(id int64, req domain.UpdateUserRequest) (*domai
 	if req.PhotoURL != nil {
 		user.PhotoURL = *req.PhotoURL
 	}
+	if err := applyRegionalSettings(user, req.Country, req.Currency, req.DistanceUnit); err != nil {
+		return nil, err
+	}
 
 	// Guardar cambios
 	if err := s.userRepo.Update(user); err != nil {
 		return nil, err
 	}
 
+	s.webhooks.UserUpdated(user)
+
 	return s.convertToDTO(user), nil
 }
 
 // DeleteUser elimina un usuario
 func (s *userService) DeleteUser(id int64) error {
 	// Verificar que el usuario existe
-	_, err := s.userRepo.FindByID(id)
+	user, err := s.userRepo.FindByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("usuario no encontrado")
@@ -116,7 +186,12 @@ func (s *userService) DeleteUser(id int64) error {
 		return err
 	}
 
-	return s.userRepo.Delete(id)
+	if err := s.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.webhooks.UserDeleted(user)
+	return nil
 }
 
 // ForceReauthentication desverifica el email y reenvía el email de verificación
@@ -130,18 +205,13 @@ func (s *userService) ForceReauthentication(id int64) error {
 		return err
 	}
 
-	// Generar nuevo token de verificación
-	token, err := s.emailService.GenerateToken()
-	if err != nil {
-		return err
-	}
-
-	// Guardar nuevo token y desverificar email
-	if err := s.userRepo.SaveEmailVerificationToken(id, token); err != nil {
+	// Desverificar email y generar nuevo token (no cuenta para el límite de reenvíos)
+	if err := s.userRepo.UnverifyEmail(id, user.Email); err != nil {
 		return err
 	}
 
-	if err := s.userRepo.UnverifyEmail(id, user.Email); err != nil {
+	token, err := issueVerificationToken(s.tokenRepo, s.emailService, id, domain.VerificationPurposeReauth)
+	if err != nil {
 		return err
 	}
 
@@ -157,7 +227,68 @@ func (s *userService) ForceReauthentication(id int64) error {
 }
 
 // convertToDTO convierte un UserDAO a UserDTO
+// BanUser suspende la cuenta de un usuario (solo admin)
+// La cuenta no puede iniciar sesión y sus JWT vigentes dejan de funcionar en la próxima request
+// (los middlewares leen el usuario de la base en cada request)
+func (s *userService) BanUser(adminID, id int64, reason string) (*domain.UserDTO, error) {
+	if adminID == id {
+		return nil, errors.New("no puedes suspender tu propia cuenta")
+	}
+
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	// Se conserva la suspensión original (fecha, autor y motivo)
+	if user.IsBanned() {
+		return nil, errors.New("el usuario ya está suspendido")
+	}
+
+	now := time.Now()
+	if err := s.userRepo.UpdateBan(id, &now, &adminID, reason); err != nil {
+		return nil, err
+	}
+
+	user.BannedAt = &now
+	user.BannedBy = &adminID
+	user.BanReason = reason
+	return s.convertToDTO(user), nil
+}
+
+// UnbanUser levanta la suspensión de la cuenta de un usuario (solo admin)
+func (s *userService) UnbanUser(id int64) (*domain.UserDTO, error) {
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("usuario no encontrado")
+		}
+		return nil, err
+	}
+
+	if !user.IsBanned() {
+		return nil, errors.New("el usuario no está suspendido")
+	}
+
+	if err := s.userRepo.UpdateBan(id, nil, nil, ""); err != nil {
+		return nil, err
+	}
+
+	user.BannedAt = nil
+	user.BannedBy = nil
+	user.BanReason = ""
+	return s.convertToDTO(user), nil
+}
+
 func (s *userService) convertToDTO(userDAO *dao.UserDAO) *domain.UserDTO {
+	return toUserDTO(userDAO)
+}
+
+// toUserDTO convierte un UserDAO a UserDTO (compartido con el servicio de cuentas dependientes)
+func toUserDTO(userDAO *dao.UserDAO) *domain.UserDTO {
 	return &domain.UserDTO{
 		ID:                  userDAO.ID,
 		Email:               userDAO.Email,
@@ -175,7 +306,23 @@ func (s *userService) convertToDTO(userDAO *dao.UserDAO) *domain.UserDTO {
 		TotalTripsPassenger: userDAO.TotalTripsPassenger,
 		TotalTripsDriver:    userDAO.TotalTripsDriver,
 		Birthdate:           userDAO.Birthdate,
+		Country:             userDAO.Country,
+		Currency:            userDAO.Currency,
+		DistanceUnit:        userDAO.DistanceUnit,
+		GuardianID:          userDAO.GuardianID,
+		Restrictions:        userRestrictions(userDAO),
+		Banned:              userDAO.IsBanned(),
+		BannedAt:            userDAO.BannedAt,
+		BanReason:           userDAO.BanReason,
 		CreatedAt:           userDAO.CreatedAt,
 		UpdatedAt:           userDAO.UpdatedAt,
 	}
 }
+
+// userRestrictions retorna las restricciones de la cuenta (nil si no es dependiente)
+func userRestrictions(userDAO *dao.UserDAO) []string {
+	if userDAO.Role != domain.RoleDependent {
+		return nil
+	}
+	return domain.DependentRestrictions
+}
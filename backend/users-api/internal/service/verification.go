@@ -0,0 +1,37 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+	"users-api/internal/dao"
+	"users-api/internal/domain"
+	"users-api/internal/repository"
+)
+
+// issueVerificationToken genera un token de verificación para el usuario y guarda su hash
+// Retorna el token en claro para enviarlo por email
+func issueVerificationToken(tokenRepo repository.VerificationTokenRepository, emailService EmailService, userID int64, purpose string) (string, error) {
+	token, err := emailService.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+
+	if err := tokenRepo.Create(&dao.VerificationTokenDAO{
+		UserID:    userID,
+		TokenHash: hashVerificationToken(token),
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(domain.VerificationTokenTTL),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// hashVerificationToken hashea el token con SHA-256 (igual que las API keys de partners):
+// un dump de la tabla no alcanza para verificar cuentas ajenas
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
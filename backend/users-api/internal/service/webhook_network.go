@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// errWebhookBlockedAddress es el error de un envío a una dirección interna (lo registra el log de entregas)
+var errWebhookBlockedAddress = errors.New("la URL del webhook resuelve a una dirección interna")
+
+// webhookBlockedNetworks son rangos que no cubren los métodos de net.IP: CGNAT y la red "this host"
+var webhookBlockedNetworks = []*net.IPNet{
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("0.0.0.0/8"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isBlockedWebhookIP indica si la IP es de la red interna: loopback (la propia API, con sus rutas /internal
+// sin autenticación), redes privadas, link-local (metadata de la nube, 169.254.169.254) o no enrutable
+func isBlockedWebhookIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range webhookBlockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlockedWebhookHost rechaza al registrar la URL los hosts que ya se sabe que son internos
+// (localhost o una IP literal); los nombres que resuelven a una IP interna los corta el dialer al enviar
+func isBlockedWebhookHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return isBlockedWebhookIP(ip)
+	}
+	return false
+}
+
+// webhookDialControl se ejecuta con la IP ya resuelta, justo antes de conectar: así un DNS que cambia
+// entre la validación y el envío (DNS rebinding) no llega a la red interna
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isBlockedWebhookIP(ip) {
+		return fmt.Errorf("%w (%s)", errWebhookBlockedAddress, host)
+	}
+	return nil
+}
+
+// newWebhookHTTPClient crea el cliente de los envíos de webhooks
+// Con allowPrivate (solo desarrollo) se puede enviar a la red interna; si no, el dialer la bloquea
+func newWebhookHTTPClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = webhookDialControl
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil, // Un proxy del entorno haría que el dialer solo vea su IP
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        20,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// No seguir redirects: la URL registrada es la única que recibe el payload firmado
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
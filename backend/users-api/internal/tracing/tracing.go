@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ServiceName es el service.name de los spans del servicio (se puede pisar con OTEL_SERVICE_NAME)
+const ServiceName = "users-api"
+
+// Init configura el SDK de OpenTelemetry del servicio
+//
+//   - El propagador W3C (traceparent/tracestate + baggage) se instala siempre: el contexto de la
+//     traza viaja en los headers HTTP y AMQP aunque este servicio no exporte spans
+//   - Los spans se exportan por OTLP/HTTP solo si OTEL_EXPORTER_OTLP_ENDPOINT (o
+//     OTEL_EXPORTER_OTLP_TRACES_ENDPOINT) está definido; el resto de la configuración del
+//     exporter y del sampler se lee de las variables OTEL_* estándar
+//
+// Retorna la función de shutdown que hace flush de los spans pendientes
+func Init(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Enabled indica si los spans se exportan (sirve para loguearlo al arrancar)
+func Enabled() bool {
+	_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	return ok
+}
+
+// Middleware abre un span de servidor por request con el template de la ruta (/users/:id)
+// y continúa la traza del traceparent entrante. /health y /metrics no generan spans
+func Middleware() gin.HandlerFunc {
+	return otelgin.Middleware(ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health" && r.URL.Path != "/metrics"
+	}))
+}